@@ -0,0 +1,19 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/bmstu-itstech/tjudge/pkg/alerts"
+)
+
+func main() {
+	output := flag.String("output", "alerts.yaml", "path to the generated Prometheus rule file")
+	flag.Parse()
+
+	if err := alerts.WritePrometheusAlertRules(*output); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Alert rules written to %s\n", *output)
+}
@@ -11,22 +11,31 @@ import (
 
 	"github.com/bmstu-itstech/tjudge/internal/api"
 	"github.com/bmstu-itstech/tjudge/internal/api/handlers"
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/config"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/internal/domain/auth"
+	"github.com/bmstu-itstech/tjudge/internal/domain/bracket"
 	"github.com/bmstu-itstech/tjudge/internal/domain/game"
+	"github.com/bmstu-itstech/tjudge/internal/domain/notification"
 	"github.com/bmstu-itstech/tjudge/internal/domain/team"
 	"github.com/bmstu-itstech/tjudge/internal/domain/tournament"
+	"github.com/bmstu-itstech/tjudge/internal/domain/webhook"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/queue"
 	"github.com/bmstu-itstech/tjudge/internal/websocket"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
 	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/bmstu-itstech/tjudge/pkg/tracing"
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
+// auditBufferSize записей журнала действий, ожидающих записи в БД
+const auditBufferSize = 1024
+
 // matchSchedulerAdapter адаптер для tournament.Service.ScheduleNewProgramMatches
 type matchSchedulerAdapter struct {
 	tournamentService *tournament.Service
@@ -43,6 +52,14 @@ func (a *matchSchedulerAdapter) ScheduleNewProgramMatches(ctx context.Context, t
 	return a.tournamentService.ScheduleNewProgramMatches(ctx, req, a.programRepo)
 }
 
+func (a *matchSchedulerAdapter) ScheduleCalibrationMatches(ctx context.Context, tournamentID, gameID, programID uuid.UUID) error {
+	return a.tournamentService.ScheduleCalibrationMatches(ctx, tournamentID, gameID, programID, a.programRepo)
+}
+
+func (a *matchSchedulerAdapter) ScheduleValidationMatch(ctx context.Context, tournamentID, gameID, programID uuid.UUID) (*domain.Match, error) {
+	return a.tournamentService.ScheduleValidationMatch(ctx, tournamentID, gameID, programID, a.programRepo)
+}
+
 func main() {
 	// Загружаем конфигурацию
 	cfg, err := config.Load()
@@ -68,6 +85,15 @@ func main() {
 		zap.String("env", "production"),
 	)
 
+	// Инициализируем трассировку
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceNameOr("tjudge-api"),
+	})
+	if err != nil {
+		log.Fatal("Failed to setup tracing", zap.Error(err))
+	}
+
 	// Инициализируем метрики
 	m := metrics.New()
 
@@ -127,10 +153,14 @@ func main() {
 
 	// Запускаем hub в отдельной горутине
 	go wsHub.Run(ctx)
+	// События турниров от worker (автозавершение раундов) пересылаются в hub
+	go cache.NewTournamentEvents(redisCache).Forward(ctx, wsHub)
 
 	// Инициализируем сервисы
 	jwtManager := auth.NewJWTManager(cfg.JWT.Secret, cfg.JWT.AccessTTL, cfg.JWT.RefreshTTL)
 	authService := auth.NewService(userRepo, jwtManager, tokenBlacklist, log)
+	authService.SetImpersonation(cfg.JWT.ImpersonationEnabled, cfg.JWT.ImpersonationTTL)
+	authService.SetAPIKeys(db.NewAPIKeyRepository(database), cache.NewAPIKeyCache(redisCache))
 
 	tournamentService := tournament.NewService(
 		tournamentRepo,
@@ -143,9 +173,86 @@ func main() {
 		distributedLock, // distributed lock
 		log,
 	)
+	tournamentService.SetPurgeRetention(cfg.Storage.PurgeRetention)
+	tournamentService.SetProgramLookup(programRepo)
+	tournamentService.SetActiveIDsCache(tournamentCache)
+	tournamentService.SetParticipantImporter(tournamentRepo)
+	if cfg.Database.CrossGameCheck {
+		tournamentService.SetCrossGameChecker(tournamentRepo)
+	}
+
+	// Сетки турниров на выбывание
+	bracketService := bracket.NewService(
+		db.NewBracketRepository(database),
+		tournamentRepo,
+		matchRepo,
+		queueManager,
+		tournamentCache,
+		log,
+	)
+	tournamentService.SetBracket(bracketService)
+
+	// Уведомления: подписки доступны всегда, события пишутся в outbox только при настроенном SMTP
+	notificationService := notification.NewService(db.NewNotificationRepository(database), userRepo, log)
+	notificationService.SetBaseURL(cfg.Server.BaseURL)
+	if cfg.Notifications.Enabled() {
+		tournamentService.AddNotifier(notificationService)
+		bracketService.AddNotifier(notificationService)
+	}
+
+	// Webhook'и турниров: события пишутся в outbox, доставляет их dispatcher в worker'е
+	webhookRepo := db.NewWebhookRepository(database)
+	webhookService := webhook.NewService(webhookRepo, tournamentRepo, log)
+	tournamentService.AddNotifier(webhookService)
+	bracketService.AddNotifier(webhookService)
+
+	// Автостарт турниров по запланированному StartTime
+	tournamentScheduler := tournament.NewTournamentScheduler(
+		tournamentRepo,
+		tournamentService,
+		wsHub,
+		distributedLock,
+		time.Minute,
+		log,
+	)
+	tournamentScheduler.Start()
+
+	// Запуск раундов игр в начале их окон времени
+	windowScheduler := tournament.NewWindowScheduler(
+		gameRepo,
+		tournamentService,
+		wsHub,
+		distributedLock,
+		30*time.Second,
+		log,
+	)
+	windowScheduler.Start()
+
+	// Принудительное завершение турниров, исчерпавших бюджет времени
+	budgetEnforcer := tournament.NewBudgetEnforcer(
+		tournamentRepo,
+		tournamentService,
+		wsHub,
+		distributedLock,
+		30*time.Second,
+		cfg.Server.TimeBudgetWarning,
+		log,
+	)
+	budgetEnforcer.Start()
+
+	// Рассылка leaderboard_update при изменении таблиц лидеров активных турниров
+	leaderboardPoller := websocket.NewLeaderboardPoller(
+		tournamentService,
+		leaderboardCache,
+		wsHub,
+		websocket.DefaultLeaderboardPollInterval,
+		log,
+	)
+	leaderboardPoller.Start(ctx)
 
 	gameService := game.NewService(gameRepo, log)
-	teamService := team.NewService(teamRepo, tournamentRepo, log)
+	gameService.SetDefaultSandboxProfile(domain.SandboxProfile(cfg.Executor.SandboxProfile))
+	teamService := team.NewService(teamRepo, tournamentRepo, distributedLock, log)
 
 	// Создаём адаптеры для репозиториев (для game handler)
 	// tournamentRepo уже реализует GetLeaderboardByGameType
@@ -160,20 +267,64 @@ func main() {
 	// Инициализируем handlers
 	authHandler := handlers.NewAuthHandler(authService, log)
 	tournamentHandler := handlers.NewTournamentHandler(tournamentService, log)
-	programHandler := handlers.NewProgramHandler(programRepo, tournamentRepo, matchScheduler, log)
+	tournamentHandler.SetMatchExporter(matchRepo, programRepo)
+	tournamentHandler.SetStatsSource(tournamentRepo)
+	tournamentHandler.SetBracketReader(bracketService)
+	tournamentHandler.SetHeadToHead(matchRepo)
+	// Одна проверка доступа к приватным турнирам для всех публичных маршрутов и WebSocket
+	tournamentAccess := handlers.NewTournamentAccess(tournamentService, log)
+	tournamentAccess.SetMembership(teamRepo)
+	tournamentHandler.SetAccess(tournamentAccess)
+	programHandler := handlers.NewProgramHandler(programRepo, tournamentRepo, matchScheduler, rateLimiter, log)
 	programHandler.SetGameLookup(gameService)
 	programHandler.SetMatchChecker(matchRepo)
 	programHandler.SetRoundChecker(gameRepo)
+	programHandler.SetRankLookup(leaderboardCache)
+	programHandler.SetStatsLookup(matchRepo)
+	programHandler.SetMatchHistory(matchRepo, programRepo)
+	programHandler.SetReferenceBots(programRepo)
+	programHandler.SetValidationBots(gameRepo)
+	programHandler.SetUploadCooldown(cfg.Storage.UploadCooldown, tournamentService)
+	programHandler.SetVersionQuota(cfg.Storage.MaxVersions, cfg.Storage.PruneVersions)
+	programHandler.SetLanguageVersions(cfg.Executor.LanguageVersions)
 	matchHandler := handlers.NewMatchHandlerFull(matchRepo, matchCache, programRepo, queueManager, log)
+	matchHandler.SetProgramInfo(programRepo)
+	matchHandler.SetHeadToHead(matchRepo)
+	matchHandler.SetLiveFeed(cache.NewLiveMatchFeed(redisCache))
+	matchHandler.SetTestMatches(tournamentService, rateLimiter, cfg.RateLimit.TestMatchesPerHour)
+	matchHandler.SetResultAdjuster(tournamentService)
+	matchHandler.SetTournamentAccess(tournamentAccess)
 	gameHandler := handlers.NewGameHandlerWithRepos(gameService, tournamentRepo, matchRepo, tournamentRepo, log)
 	gameHandler.SetProgramRepo(programRepo)
 	gameHandler.SetTournamentGameStatusRepo(gameRepo)
 	gameHandler.SetRatingRepo(ratingRepo)
 	gameHandler.SetMatchResetRepo(matchRepo)
+	gameHandler.SetRoundsRepo(matchRepo)
+	gameHandler.SetRoundProgressCache(cache.NewRoundProgressCache(redisCache))
+	gameHandler.SetTournamentAccess(tournamentAccess)
 	teamHandler := handlers.NewTeamHandler(teamService, cfg.Server.BaseURL, log)
+	teamHandler.SetTournamentAccess(tournamentAccess)
 	wsHandler := handlers.NewWebSocketHandler(wsHub, log)
+	wsHandler.SetAllowedOrigins(cfg.CORS.WebSocketAllowedOrigins())
+	wsHandler.SetTournamentAccess(tournamentAccess)
 	systemHandler := handlers.NewSystemHandler(log)
 
+	// Refresher в API не запускается периодически: только принудительное обновление от админа
+	leaderboardRefresher := db.NewLeaderboardRefresher(database, cfg.Worker.LeaderboardRefreshInterval, log)
+	leaderboardRefresher.SetLock(distributedLock)
+	systemHandler.SetLeaderboardRefresher(leaderboardRefresher)
+	systemHandler.SetCrossGameStatsRebuilder(tournamentRepo)
+	systemHandler.SetQueryExplainer(database)
+	tournamentService.SetLeaderboardRefresher(leaderboardRefresher)
+
+	auditLogRepo := db.NewAuditLogRepository(database)
+	// Запись журнала не задерживает ответ: буфер сбрасывается в БД в фоне и при остановке
+	auditBuffer := middleware.NewAuditBuffer(auditLogRepo, auditBufferSize, log)
+	auditBuffer.Start()
+	auditHandler := handlers.NewAuditHandler(auditLogRepo, log)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, log)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, tournamentRepo, log)
+
 	// Создаём API сервер
 	apiServer := api.NewServer(
 		authHandler,
@@ -184,6 +335,10 @@ func main() {
 		teamHandler,
 		wsHandler,
 		systemHandler,
+		auditHandler,
+		notificationHandler,
+		webhookHandler,
+		auditBuffer,
 		authService,
 		rateLimiter,
 		cfg.CORS,
@@ -255,8 +410,22 @@ func main() {
 		}
 	}
 
+	// Дописываем журнал действий после завершения последних запросов
+	auditBuffer.Stop()
+
+	// Останавливаем автостарт турниров
+	tournamentScheduler.Stop()
+	windowScheduler.Stop()
+	budgetEnforcer.Stop()
+	leaderboardPoller.Stop()
+
 	// Останавливаем WebSocket hub
 	cancel()
 
+	// Отправляем оставшиеся спаны
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error("Failed to flush traces", zap.Error(err))
+	}
+
 	log.Info("Servers stopped gracefully")
 }
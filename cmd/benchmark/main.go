@@ -3,8 +3,12 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"os/exec"
 	"regexp"
@@ -22,6 +26,15 @@ type BenchmarkResult struct {
 	Iterations int64
 }
 
+// ScoredResult is a benchmark result rated against its standard
+type ScoredResult struct {
+	BenchmarkResult
+	Category string
+	Standard *BenchmarkStandard // nil if no standard is defined
+	Ratio    float64
+	Rating   Rating
+}
+
 // BenchmarkStandard defines expected performance standards
 type BenchmarkStandard struct {
 	Name         string
@@ -280,13 +293,59 @@ var Standards = map[string]BenchmarkStandard{
 	},
 }
 
+// Output formats
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// errUsage is returned for invalid command line arguments
+var errUsage = errors.New("invalid usage")
+
+// errBenchmarksFailed is returned when a benchmark is rated POOR or CRITICAL in JSON mode
+var errBenchmarksFailed = errors.New("benchmarks failed")
+
 func main() {
-	runBenchmarks := flag.Bool("run", false, "Run benchmarks before interpreting")
-	pattern := flag.String("bench", ".", "Benchmark pattern to run")
-	verbose := flag.Bool("v", false, "Verbose output")
-	showStandards := flag.Bool("standards", false, "Show only standards table")
-	noColor := flag.Bool("no-color", false, "Disable colored output")
-	flag.Parse()
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if err != nil && !errors.Is(err, errBenchmarksFailed) {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	os.Exit(exitCode(err))
+}
+
+// exitCode maps the result of run to the process exit code
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// run interprets benchmark results. In JSON mode it returns errBenchmarksFailed
+// when the report did not pass, so CI can fail the build on the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("benchmark", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	runBenchmarks := flags.Bool("run", false, "Run benchmarks before interpreting")
+	pattern := flags.String("bench", ".", "Benchmark pattern to run")
+	verbose := flags.Bool("v", false, "Verbose output")
+	showStandards := flags.Bool("standards", false, "Show only standards table")
+	noColor := flags.Bool("no-color", false, "Disable colored output")
+	outputFormat := flags.String("output", outputText, "Output format: text or json")
+	outputFile := flags.String("output-file", "", "Write the JSON report to a file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *outputFormat != outputText && *outputFormat != outputJSON {
+		return fmt.Errorf("%w: unknown output format %q", errUsage, *outputFormat)
+	}
 
 	if *noColor {
 		disableColors()
@@ -294,16 +353,22 @@ func main() {
 
 	if *showStandards {
 		printStandardsTable()
-		return
+		return nil
+	}
+
+	// In JSON mode stdout carries only the report
+	status := stdout
+	if *outputFormat == outputJSON {
+		status = stderr
 	}
 
 	var output []byte
 	var err error
 
 	if *runBenchmarks {
-		fmt.Println(colorCyan + "Running benchmarks..." + colorReset)
-		fmt.Println("Note: Only standalone benchmarks (no DB/Redis required)")
-		fmt.Println()
+		fmt.Fprintln(status, colorCyan+"Running benchmarks..."+colorReset)
+		fmt.Fprintln(status, "Note: Only standalone benchmarks (no DB/Redis required)")
+		fmt.Fprintln(status)
 
 		// Run only benchmarks that don't require external services
 		// Exclude: API (needs full server), Queue (needs Redis), DB (needs Postgres)
@@ -325,31 +390,31 @@ func main() {
 		cmd := exec.Command("go", args...)
 		cmd.Dir = findProjectRoot()
 
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
+		var cmdStdout, cmdStderr bytes.Buffer
+		cmd.Stdout = &cmdStdout
+		cmd.Stderr = &cmdStderr
 
 		err = cmd.Run()
-		output = stdout.Bytes()
+		output = cmdStdout.Bytes()
 
 		// Print output in real-time for debugging
 		if *verbose {
-			fmt.Println(string(output))
+			fmt.Fprintln(status, string(output))
 		}
 
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%sWarning: Some benchmarks may have been skipped (DB/Redis not running?)%s\n", colorYellow, colorReset)
+			fmt.Fprintf(stderr, "%sWarning: Some benchmarks may have been skipped (DB/Redis not running?)%s\n", colorYellow, colorReset)
 			if *verbose {
-				fmt.Fprintf(os.Stderr, "%s\n", stderr.String())
+				fmt.Fprintf(stderr, "%s\n", cmdStderr.String())
 			}
 		}
 	} else {
 		// Read from stdin
-		fmt.Println(colorCyan + "Reading benchmark results from stdin..." + colorReset)
-		fmt.Println("(Run with -run flag to execute benchmarks automatically)")
-		fmt.Println()
+		fmt.Fprintln(status, colorCyan+"Reading benchmark results from stdin..."+colorReset)
+		fmt.Fprintln(status, "(Run with -run flag to execute benchmarks automatically)")
+		fmt.Fprintln(status)
 
-		scanner := bufio.NewScanner(os.Stdin)
+		scanner := bufio.NewScanner(stdin)
 		var lines []string
 		for scanner.Scan() {
 			lines = append(lines, scanner.Text())
@@ -357,19 +422,89 @@ func main() {
 		output = []byte(strings.Join(lines, "\n"))
 	}
 
-	results := parseBenchmarkOutput(string(output))
+	results := scoreResults(parseBenchmarkOutput(string(output)))
 
 	if len(results) == 0 {
-		fmt.Println(colorYellow + "No benchmark results found." + colorReset)
-		fmt.Println()
-		fmt.Println("Usage:")
-		fmt.Println("  go run ./cmd/benchmark -run              # Run and interpret benchmarks")
-		fmt.Println("  go run ./cmd/benchmark -standards        # Show expected standards")
-		fmt.Println("  go test -bench=. ./... | go run ./cmd/benchmark  # Pipe results")
-		return
+		fmt.Fprintln(status, colorYellow+"No benchmark results found."+colorReset)
+		fmt.Fprintln(status)
+		fmt.Fprintln(status, "Usage:")
+		fmt.Fprintln(status, "  go run ./cmd/benchmark -run              # Run and interpret benchmarks")
+		fmt.Fprintln(status, "  go run ./cmd/benchmark -standards        # Show expected standards")
+		fmt.Fprintln(status, "  go run ./cmd/benchmark -run -output json # Machine-readable report for CI")
+		fmt.Fprintln(status, "  go test -bench=. ./... | go run ./cmd/benchmark  # Pipe results")
+		if *outputFormat == outputText {
+			return nil
+		}
+	}
+
+	if *outputFormat == outputJSON {
+		return writeJSONReport(results, *outputFile, stdout)
 	}
 
-	printResults(results)
+	printResults(stdout, results)
+	return nil
+}
+
+// writeJSONReport writes the report to path (stdout if empty).
+// Returns errBenchmarksFailed if the report did not pass
+func writeJSONReport(results []ScoredResult, path string, stdout io.Writer) error {
+	report := newJSONReport(results)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path == "" {
+		if _, err := stdout.Write(data); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	} else if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if !report.Passed {
+		return errBenchmarksFailed
+	}
+	return nil
+}
+
+// JSONReport is the machine-readable benchmark report
+type JSONReport struct {
+	Passed  bool         `json:"passed"` // false if any benchmark is POOR or CRITICAL
+	Results []JSONResult `json:"results"`
+}
+
+// JSONResult is a single benchmark in the JSON report
+type JSONResult struct {
+	Name     string  `json:"name"`
+	NsOp     int64   `json:"ns_op"`
+	BytesOp  int64   `json:"bytes_op,omitempty"`
+	AllocsOp int64   `json:"allocs_op,omitempty"`
+	Ratio    float64 `json:"ratio,omitempty"`  // Omitted if no standard is defined
+	Rating   Rating  `json:"rating,omitempty"` // Omitted if no standard is defined
+	Category string  `json:"category"`
+}
+
+// newJSONReport builds the JSON report from scored results
+func newJSONReport(results []ScoredResult) JSONReport {
+	report := JSONReport{Passed: true, Results: make([]JSONResult, 0, len(results))}
+	for _, result := range results {
+		report.Results = append(report.Results, JSONResult{
+			Name:     result.Name,
+			NsOp:     result.NsOp,
+			BytesOp:  result.BytesOp,
+			AllocsOp: result.AllocsOp,
+			Ratio:    math.Round(result.Ratio*100) / 100,
+			Rating:   result.Rating,
+			Category: result.Category,
+		})
+		if result.Rating == RatingPoor || result.Rating == RatingCritical {
+			report.Passed = false
+		}
+	}
+	return report
 }
 
 func disableColors() {
@@ -418,6 +553,23 @@ func parseBenchmarkOutput(output string) []BenchmarkResult {
 	return results
 }
 
+// scoreResults rates parsed results against Standards. Benchmarks without
+// a standard get the "Other" category and no rating
+func scoreResults(results []BenchmarkResult) []ScoredResult {
+	scored := make([]ScoredResult, 0, len(results))
+	for _, result := range results {
+		entry := ScoredResult{BenchmarkResult: result, Category: "Other"}
+		if std, exists := Standards[result.Name]; exists {
+			entry.Category = std.Category
+			entry.Standard = &std
+			entry.Ratio = float64(result.NsOp) / float64(std.ExpectedNsOp)
+			entry.Rating = getRating(entry.Ratio)
+		}
+		scored = append(scored, entry)
+	}
+	return scored
+}
+
 func getRating(ratio float64) Rating {
 	switch {
 	case ratio <= 0.5:
@@ -467,15 +619,15 @@ func getRatingSymbol(rating Rating) string {
 	}
 }
 
-func printResults(results []BenchmarkResult) {
-	fmt.Println()
-	fmt.Println(colorBold + "================================================================================" + colorReset)
-	fmt.Println(colorBold + "                    BENCHMARK RESULTS INTERPRETATION" + colorReset)
-	fmt.Println(colorBold + "================================================================================" + colorReset)
-	fmt.Println()
+func printResults(w io.Writer, results []ScoredResult) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, colorBold+"================================================================================"+colorReset)
+	fmt.Fprintln(w, colorBold+"                    BENCHMARK RESULTS INTERPRETATION"+colorReset)
+	fmt.Fprintln(w, colorBold+"================================================================================"+colorReset)
+	fmt.Fprintln(w)
 
 	// Group by category
-	categories := map[string][]BenchmarkResult{
+	categories := map[string][]ScoredResult{
 		"API":    {},
 		"Worker": {},
 		"Queue":  {},
@@ -484,11 +636,7 @@ func printResults(results []BenchmarkResult) {
 	}
 
 	for _, result := range results {
-		if std, exists := Standards[result.Name]; exists {
-			categories[std.Category] = append(categories[std.Category], result)
-		} else {
-			categories["Other"] = append(categories["Other"], result)
-		}
+		categories[result.Category] = append(categories[result.Category], result)
 	}
 
 	ratings := map[Rating]int{}
@@ -499,83 +647,82 @@ func printResults(results []BenchmarkResult) {
 			continue
 		}
 
-		fmt.Printf("%s### %s Benchmarks ###%s\n\n", colorBold+colorCyan, category, colorReset)
+		fmt.Fprintf(w, "%s### %s Benchmarks ###%s\n\n", colorBold+colorCyan, category, colorReset)
 
 		for _, result := range catResults {
-			std, exists := Standards[result.Name]
-			if !exists {
-				fmt.Printf("  %s: %s (no standard defined)\n\n", result.Name, formatDuration(result.NsOp))
+			std := result.Standard
+			if std == nil {
+				fmt.Fprintf(w, "  %s: %s (no standard defined)\n\n", result.Name, formatDuration(result.NsOp))
 				continue
 			}
 
-			ratio := float64(result.NsOp) / float64(std.ExpectedNsOp)
-			rating := getRating(ratio)
+			ratio, rating := result.Ratio, result.Rating
 			ratings[rating]++
 			color := getRatingColor(rating)
 			symbol := getRatingSymbol(rating)
 
-			fmt.Printf("  %s%s%s %s\n", color, symbol, colorReset, result.Name)
-			fmt.Printf("      %s\n", std.Description)
-			fmt.Printf("      Actual:   %s%s%s\n", colorBold, formatDuration(result.NsOp), colorReset)
-			fmt.Printf("      Expected: %s\n", formatDuration(std.ExpectedNsOp))
-			fmt.Printf("      Ratio:    %s%.2fx%s", color, ratio, colorReset)
+			fmt.Fprintf(w, "  %s%s%s %s\n", color, symbol, colorReset, result.Name)
+			fmt.Fprintf(w, "      %s\n", std.Description)
+			fmt.Fprintf(w, "      Actual:   %s%s%s\n", colorBold, formatDuration(result.NsOp), colorReset)
+			fmt.Fprintf(w, "      Expected: %s\n", formatDuration(std.ExpectedNsOp))
+			fmt.Fprintf(w, "      Ratio:    %s%.2fx%s", color, ratio, colorReset)
 			if ratio <= 1.0 {
-				fmt.Printf(" (within budget)")
+				fmt.Fprintf(w, " (within budget)")
 			} else if ratio <= 2.0 {
-				fmt.Printf(" (slightly over)")
+				fmt.Fprintf(w, " (slightly over)")
 			} else {
-				fmt.Printf(" (needs attention)")
+				fmt.Fprintf(w, " (needs attention)")
 			}
-			fmt.Println()
+			fmt.Fprintln(w)
 
 			if result.BytesOp > 0 {
-				fmt.Printf("      Memory:   %d B/op, %d allocs/op\n", result.BytesOp, result.AllocsOp)
+				fmt.Fprintf(w, "      Memory:   %d B/op, %d allocs/op\n", result.BytesOp, result.AllocsOp)
 			}
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
 	}
 
 	// Summary
-	fmt.Println(colorBold + "================================================================================" + colorReset)
-	fmt.Println(colorBold + "                              SUMMARY" + colorReset)
-	fmt.Println(colorBold + "================================================================================" + colorReset)
-	fmt.Println()
+	fmt.Fprintln(w, colorBold+"================================================================================"+colorReset)
+	fmt.Fprintln(w, colorBold+"                              SUMMARY"+colorReset)
+	fmt.Fprintln(w, colorBold+"================================================================================"+colorReset)
+	fmt.Fprintln(w)
 
 	total := len(results)
-	fmt.Printf("  Total benchmarks analyzed: %d\n\n", total)
+	fmt.Fprintf(w, "  Total benchmarks analyzed: %d\n\n", total)
 
-	fmt.Printf("  %s+++%s Excellent (< 0.5x expected): %d\n", colorGreen, colorReset, ratings[RatingExcellent])
-	fmt.Printf("  %s++ %s Good      (0.5-1.0x):        %d\n", colorGreen, colorReset, ratings[RatingGood])
-	fmt.Printf("  %s+  %s Acceptable (1.0-2.0x):       %d\n", colorYellow, colorReset, ratings[RatingAcceptable])
-	fmt.Printf("  %s-  %s Poor      (2.0-5.0x):        %d\n", colorYellow, colorReset, ratings[RatingPoor])
-	fmt.Printf("  %s---%s Critical  (> 5.0x):          %d\n", colorRed, colorReset, ratings[RatingCritical])
-	fmt.Println()
+	fmt.Fprintf(w, "  %s+++%s Excellent (< 0.5x expected): %d\n", colorGreen, colorReset, ratings[RatingExcellent])
+	fmt.Fprintf(w, "  %s++ %s Good      (0.5-1.0x):        %d\n", colorGreen, colorReset, ratings[RatingGood])
+	fmt.Fprintf(w, "  %s+  %s Acceptable (1.0-2.0x):       %d\n", colorYellow, colorReset, ratings[RatingAcceptable])
+	fmt.Fprintf(w, "  %s-  %s Poor      (2.0-5.0x):        %d\n", colorYellow, colorReset, ratings[RatingPoor])
+	fmt.Fprintf(w, "  %s---%s Critical  (> 5.0x):          %d\n", colorRed, colorReset, ratings[RatingCritical])
+	fmt.Fprintln(w)
 
 	// Recommendations
 	if ratings[RatingCritical] > 0 {
-		fmt.Printf("  %s!!! CRITICAL:%s Some benchmarks are >5x slower than expected.\n", colorRed, colorReset)
-		fmt.Println("      Immediate investigation required!")
-		fmt.Println()
+		fmt.Fprintf(w, "  %s!!! CRITICAL:%s Some benchmarks are >5x slower than expected.\n", colorRed, colorReset)
+		fmt.Fprintln(w, "      Immediate investigation required!")
+		fmt.Fprintln(w)
 	}
 
 	if ratings[RatingPoor] > 0 {
-		fmt.Printf("  %s!!! WARNING:%s Some benchmarks are 2-5x slower than expected.\n", colorYellow, colorReset)
-		fmt.Println("      Consider profiling and optimization.")
-		fmt.Println()
+		fmt.Fprintf(w, "  %s!!! WARNING:%s Some benchmarks are 2-5x slower than expected.\n", colorYellow, colorReset)
+		fmt.Fprintln(w, "      Consider profiling and optimization.")
+		fmt.Fprintln(w)
 	}
 
 	goodPercentage := float64(ratings[RatingExcellent]+ratings[RatingGood]) / float64(total) * 100
 	if goodPercentage >= 80 {
-		fmt.Printf("  %sPerformance Status: HEALTHY%s (%.0f%% within expectations)\n", colorGreen, colorReset, goodPercentage)
+		fmt.Fprintf(w, "  %sPerformance Status: HEALTHY%s (%.0f%% within expectations)\n", colorGreen, colorReset, goodPercentage)
 	} else if goodPercentage >= 50 {
-		fmt.Printf("  %sPerformance Status: ACCEPTABLE%s (%.0f%% within expectations)\n", colorYellow, colorReset, goodPercentage)
+		fmt.Fprintf(w, "  %sPerformance Status: ACCEPTABLE%s (%.0f%% within expectations)\n", colorYellow, colorReset, goodPercentage)
 	} else {
-		fmt.Printf("  %sPerformance Status: NEEDS ATTENTION%s (%.0f%% within expectations)\n", colorRed, colorReset, goodPercentage)
+		fmt.Fprintf(w, "  %sPerformance Status: NEEDS ATTENTION%s (%.0f%% within expectations)\n", colorRed, colorReset, goodPercentage)
 	}
 
-	fmt.Println()
-	fmt.Println(colorBold + "================================================================================" + colorReset)
-	fmt.Println()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, colorBold+"================================================================================"+colorReset)
+	fmt.Fprintln(w)
 }
 
 func printStandardsTable() {
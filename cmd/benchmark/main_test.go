@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// benchmarkOutput is `go test -bench` output: health is within budget,
+// JSON parsing is 3x slower than expected, the last benchmark has no standard
+const benchmarkOutput = `goos: linux
+goarch: amd64
+BenchmarkHealthEndpoint-8    	   28000	     42000 ns/op	    1024 B/op	      12 allocs/op
+BenchmarkJSONParsing-8       	  100000	     30000 ns/op
+BenchmarkSomethingNew-8      	    5000	    250000 ns/op
+PASS
+`
+
+func TestScoreResults(t *testing.T) {
+	results := scoreResults(parseBenchmarkOutput(benchmarkOutput))
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "API", results[0].Category)
+	assert.InDelta(t, 0.84, results[0].Ratio, 0.001)
+	assert.Equal(t, RatingGood, results[0].Rating)
+	assert.Equal(t, int64(1024), results[0].BytesOp)
+
+	assert.Equal(t, RatingPoor, results[1].Rating)
+
+	assert.Equal(t, "Other", results[2].Category)
+	assert.Nil(t, results[2].Standard)
+	assert.Empty(t, results[2].Rating)
+}
+
+func TestRun_JSONOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	input := "BenchmarkHealthEndpoint-8 28000 42000 ns/op\n"
+
+	err := run([]string{"-output", "json"}, strings.NewReader(input), &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode(err))
+
+	// stdout carries only the report
+	var report JSONReport
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.True(t, report.Passed)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, JSONResult{Name: "BenchmarkHealthEndpoint", NsOp: 42000, Ratio: 0.84, Rating: RatingGood, Category: "API"}, report.Results[0])
+	assert.Contains(t, stderr.String(), "Reading benchmark results")
+}
+
+func TestRun_JSONOutputFailsOnPoorResults(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	err := run([]string{"-output", "json"}, strings.NewReader(benchmarkOutput), &stdout, &stderr)
+	assert.ErrorIs(t, err, errBenchmarksFailed)
+	assert.Equal(t, 1, exitCode(err))
+
+	var report map[string]interface{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.Equal(t, false, report["passed"])
+	results := report["results"].([]interface{})
+	require.Len(t, results, 3)
+	assert.Equal(t, "POOR", results[1].(map[string]interface{})["rating"])
+	_, rated := results[2].(map[string]interface{})["rating"]
+	assert.False(t, rated, "benchmarks without a standard are not rated")
+}
+
+func TestRun_JSONOutputFile(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	path := filepath.Join(t.TempDir(), "report.json")
+	input := "BenchmarkUUIDGeneration-8 1000000 9000 ns/op\n"
+
+	err := run([]string{"-output", "json", "-output-file", path}, strings.NewReader(input), &stdout, &stderr)
+	assert.ErrorIs(t, err, errBenchmarksFailed)
+	assert.Empty(t, stdout.String())
+
+	data, readErr := os.ReadFile(path)
+	require.NoError(t, readErr)
+	var report JSONReport
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.False(t, report.Passed)
+	assert.Equal(t, RatingCritical, report.Results[0].Rating)
+}
+
+func TestRun_TextOutputDoesNotFail(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	err := run([]string{"-no-color"}, strings.NewReader(benchmarkOutput), &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "BENCHMARK RESULTS INTERPRETATION")
+	assert.Contains(t, stdout.String(), "BenchmarkSomethingNew: 250.00µs (no standard defined)")
+}
+
+func TestRun_UnknownOutputFormat(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	err := run([]string{"-output", "xml"}, strings.NewReader(""), &stdout, &stderr)
+	assert.ErrorIs(t, err, errUsage)
+	assert.Equal(t, 2, exitCode(err))
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/file"
+)
+
+// plannedMigration миграция, которую выполнила бы команда
+type plannedMigration struct {
+	Version  uint
+	Filename string
+	SQL      string
+}
+
+// planMigrations читает из dir миграции, которые выполнила бы команда при текущей версии current
+// (nil - миграции не применялись): для up - не применённые по возрастанию версий,
+// для down - применённые в обратном порядке
+func planMigrations(dir, command string, current *uint) ([]plannedMigration, error) {
+	driver, err := (&file.File{}).Open("file://" + dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+	defer driver.Close()
+
+	filenames, err := migrationFilenames(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []uint
+	if command == "up" {
+		versions, err = pendingVersions(driver, current)
+	} else {
+		versions, err = appliedVersions(driver, current)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	direction := source.Up
+	read := driver.ReadUp
+	if command == "down" {
+		direction = source.Down
+		read = driver.ReadDown
+	}
+
+	plan := make([]plannedMigration, 0, len(versions))
+	for _, version := range versions {
+		body, _, err := read(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %d: %w", version, err)
+		}
+		sql, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %d: %w", version, err)
+		}
+		plan = append(plan, plannedMigration{
+			Version:  version,
+			Filename: filenames[direction][version],
+			SQL:      string(sql),
+		})
+	}
+
+	return plan, nil
+}
+
+// pendingVersions возвращает версии после current по возрастанию
+func pendingVersions(driver source.Driver, current *uint) ([]uint, error) {
+	var (
+		version uint
+		err     error
+	)
+	if current == nil {
+		version, err = driver.First()
+	} else {
+		version, err = driver.Next(*current)
+	}
+
+	var versions []uint
+	for err == nil {
+		versions = append(versions, version)
+		version, err = driver.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	return versions, nil
+}
+
+// appliedVersions возвращает версии от current до первой по убыванию
+func appliedVersions(driver source.Driver, current *uint) ([]uint, error) {
+	if current == nil {
+		return nil, nil
+	}
+
+	// Версия, которой нет среди файлов, не может быть откачена
+	if _, _, err := driver.ReadDown(*current); err != nil {
+		return nil, fmt.Errorf("migration %d not found: %w", *current, err)
+	}
+
+	versions := []uint{*current}
+	version, err := driver.Prev(*current)
+	for err == nil {
+		versions = append(versions, version)
+		version, err = driver.Prev(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	return versions, nil
+}
+
+// migrationFilenames возвращает имена файлов миграций по направлению и версии
+func migrationFilenames(dir string) (map[source.Direction]map[uint]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	filenames := map[source.Direction]map[uint]string{
+		source.Up:   {},
+		source.Down: {},
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m, err := source.Parse(entry.Name())
+		if err != nil {
+			continue
+		}
+		if names, ok := filenames[m.Direction]; ok {
+			names[m.Version] = entry.Name()
+		}
+	}
+	return filenames, nil
+}
+
+// printPlan печатает SQL миграций с заголовком версии и имени файла
+func printPlan(w io.Writer, plan []plannedMigration) error {
+	if len(plan) == 0 {
+		_, err := fmt.Fprintln(w, "-- No pending migrations")
+		return err
+	}
+
+	for _, migration := range plan {
+		if _, err := fmt.Fprintf(w, "-- Version %d: %s\n%s\n", migration.Version, migration.Filename, migration.SQL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
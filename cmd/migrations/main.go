@@ -1,7 +1,10 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 
@@ -11,76 +14,173 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
+// migrationsDir каталог с файлами миграций
+const migrationsDir = "migrations"
+
+// migrator операции migrate.Migrate, используемые командами
+type migrator interface {
+	Up() error
+	Down() error
+	Force(version int) error
+	Version() (version uint, dirty bool, err error)
+	Close() (source error, database error)
+}
+
+// errUsage возвращается при неверных аргументах командной строки
+var errUsage = errors.New("invalid usage")
+
 func main() {
-	if len(os.Args) < 2 {
-		printUsage()
-		os.Exit(1)
+	if err := run(os.Args[1:], os.Stdout, connect); err != nil {
+		if errors.Is(err, errUsage) {
+			printUsage()
+		}
+		log.Fatal(err)
 	}
+}
 
-	// Загружаем конфигурацию
+// connect загружает конфигурацию и подключается к БД
+func connect() (migrator, error) {
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Создаём экземпляр migrate
-	m, err := migrate.New(
-		"file://migrations",
-		cfg.Database.DSNURL(),
-	)
+	m, err := migrate.New("file://"+migrationsDir, cfg.Database.DSNURL())
 	if err != nil {
-		log.Fatalf("Failed to create migrate instance: %v", err)
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
 	}
-	defer m.Close()
+	return m, nil
+}
 
-	command := os.Args[1]
+// run выполняет команду. Флаги можно указывать как до, так и после команды
+func run(args []string, stdout io.Writer, connect func() (migrator, error)) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	dryRun := flags.Bool("dry-run", false, "print pending SQL without applying it")
+	from := flags.Int("from", -1, "current version for --dry-run (default: read from the database)")
+	dir := flags.String("path", migrationsDir, "migrations directory for --dry-run")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if flags.NArg() < 1 {
+		return fmt.Errorf("%w: command required", errUsage)
+	}
+	command := flags.Arg(0)
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	params := flags.Args()
+
+	if *dryRun {
+		return runDryRun(command, *dir, *from, stdout, connect)
+	}
+
+	m, err := connect()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
 
 	switch command {
 	case "up":
 		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-			log.Fatalf("Failed to apply migrations: %v", err)
+			return fmt.Errorf("failed to apply migrations: %w", err)
 		}
-		fmt.Println("Migrations applied successfully")
+		fmt.Fprintln(stdout, "Migrations applied successfully")
 
 	case "down":
 		if err := m.Down(); err != nil && err != migrate.ErrNoChange {
-			log.Fatalf("Failed to rollback migrations: %v", err)
+			return fmt.Errorf("failed to rollback migrations: %w", err)
 		}
-		fmt.Println("Migrations rolled back successfully")
+		fmt.Fprintln(stdout, "Migrations rolled back successfully")
 
 	case "force":
-		if len(os.Args) < 3 {
-			log.Fatal("Version number required for force command")
+		if len(params) < 1 {
+			return fmt.Errorf("%w: version number required for force command", errUsage)
 		}
 		var version int
-		if _, err := fmt.Sscanf(os.Args[2], "%d", &version); err != nil {
-			log.Fatalf("Invalid version number: %v", err)
+		if _, err := fmt.Sscanf(params[0], "%d", &version); err != nil {
+			return fmt.Errorf("invalid version number: %w", err)
 		}
 		if err := m.Force(version); err != nil {
-			log.Fatalf("Failed to force version: %v", err)
+			return fmt.Errorf("failed to force version: %w", err)
 		}
-		fmt.Printf("Forced version to %d\n", version)
+		fmt.Fprintf(stdout, "Forced version to %d\n", version)
 
 	case "version":
 		version, dirty, err := m.Version()
 		if err != nil {
-			log.Fatalf("Failed to get version: %v", err)
+			return fmt.Errorf("failed to get version: %w", err)
 		}
-		fmt.Printf("Current version: %d (dirty: %t)\n", version, dirty)
+		fmt.Fprintf(stdout, "Current version: %d (dirty: %t)\n", version, dirty)
 
 	default:
-		fmt.Printf("Unknown command: %s\n", command)
-		printUsage()
-		os.Exit(1)
+		return fmt.Errorf("%w: unknown command: %s", errUsage, command)
+	}
+
+	return nil
+}
+
+// runDryRun печатает SQL, который выполнила бы команда up или down.
+// Если версия не задана флагом --from, она читается из БД; в БД ничего не изменяется
+func runDryRun(command, dir string, from int, stdout io.Writer, connect func() (migrator, error)) error {
+	if command != "up" && command != "down" {
+		return fmt.Errorf("%w: --dry-run supports only up and down", errUsage)
+	}
+
+	current, err := dryRunVersion(from, connect)
+	if err != nil {
+		return err
 	}
+
+	plan, err := planMigrations(dir, command, current)
+	if err != nil {
+		return err
+	}
+	return printPlan(stdout, plan)
+}
+
+// dryRunVersion возвращает текущую версию схемы (nil - миграции не применялись)
+func dryRunVersion(from int, connect func() (migrator, error)) (*uint, error) {
+	if from >= 0 {
+		if from == 0 {
+			return nil, nil
+		}
+		version := uint(from)
+		return &version, nil
+	}
+
+	m, err := connect()
+	if err != nil {
+		return nil, err
+	}
+	defer m.Close()
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version: %w", err)
+	}
+	if dirty {
+		return nil, fmt.Errorf("database is dirty at version %d, fix it with force first", version)
+	}
+	return &version, nil
 }
 
 func printUsage() {
-	fmt.Println("Usage: migrate <command>")
+	fmt.Println("Usage: migrate [--dry-run [--from N] [--path DIR]] <command>")
 	fmt.Println("")
 	fmt.Println("Commands:")
 	fmt.Println("  up      - Apply all pending migrations")
 	fmt.Println("  down    - Rollback all migrations")
 	fmt.Println("  force N - Force database version to N")
 	fmt.Println("  version - Show current migration version")
+	fmt.Println("")
+	fmt.Println("Flags:")
+	fmt.Println("  --dry-run - Print SQL of up/down without applying it")
+	fmt.Println("  --from N  - Current version for --dry-run, 0 - empty database (default: read from the database)")
+	fmt.Println("  --path    - Migrations directory for --dry-run (default: migrations)")
 }
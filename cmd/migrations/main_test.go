@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readOnlyMigrator reports a fixed version and fails the test on any write
+type readOnlyMigrator struct {
+	t       *testing.T
+	version uint
+	dirty   bool
+	err     error
+}
+
+func (m *readOnlyMigrator) Up() error {
+	m.t.Error("dry-run must not apply migrations")
+	return nil
+}
+
+func (m *readOnlyMigrator) Down() error {
+	m.t.Error("dry-run must not roll back migrations")
+	return nil
+}
+
+func (m *readOnlyMigrator) Force(int) error {
+	m.t.Error("dry-run must not force the version")
+	return nil
+}
+
+func (m *readOnlyMigrator) Version() (uint, bool, error) {
+	return m.version, m.dirty, m.err
+}
+
+func (m *readOnlyMigrator) Close() (error, error) {
+	return nil, nil
+}
+
+// writeMigrations creates three migrations in a temporary directory
+func writeMigrations(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"000001_create_users.up.sql":     "CREATE TABLE users ();",
+		"000001_create_users.down.sql":   "DROP TABLE users;",
+		"000002_create_games.up.sql":     "CREATE TABLE games ();",
+		"000002_create_games.down.sql":   "DROP TABLE games;",
+		"000003_add_round_flag.up.sql":   "ALTER TABLE games ADD COLUMN round_active BOOLEAN;",
+		"000003_add_round_flag.down.sql": "ALTER TABLE games DROP COLUMN round_active;",
+	}
+	for name, sql := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(sql), 0644))
+	}
+	return dir
+}
+
+func noConnect(t *testing.T) func() (migrator, error) {
+	return func() (migrator, error) {
+		t.Error("dry-run with --from must not connect to the database")
+		return nil, nil
+	}
+}
+
+func TestRun_DryRun(t *testing.T) {
+	dir := writeMigrations(t)
+
+	t.Run("up prints pending migrations without connecting", func(t *testing.T) {
+		var out bytes.Buffer
+		err := run([]string{"--dry-run", "--from", "1", "--path", dir, "up"}, &out, noConnect(t))
+		require.NoError(t, err)
+
+		output := out.String()
+		assert.NotContains(t, output, "CREATE TABLE users")
+		assert.Contains(t, output, "-- Version 2: 000002_create_games.up.sql\nCREATE TABLE games ();")
+		assert.Contains(t, output, "-- Version 3: 000003_add_round_flag.up.sql")
+		assert.Less(t, strings.Index(output, "Version 2"), strings.Index(output, "Version 3"))
+	})
+
+	t.Run("down prints applied migrations in reverse order", func(t *testing.T) {
+		var out bytes.Buffer
+		// Flags may follow the command
+		err := run([]string{"down", "--dry-run", "--from", "2", "--path", dir}, &out, noConnect(t))
+		require.NoError(t, err)
+
+		output := out.String()
+		assert.Contains(t, output, "-- Version 2: 000002_create_games.down.sql\nDROP TABLE games;")
+		assert.NotContains(t, output, "round_active")
+		assert.Less(t, strings.Index(output, "Version 2"), strings.Index(output, "Version 1"))
+	})
+
+	t.Run("empty database gets every migration", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, run([]string{"--dry-run", "--from", "0", "--path", dir, "up"}, &out, noConnect(t)))
+		assert.Equal(t, 3, strings.Count(out.String(), "-- Version"))
+	})
+
+	t.Run("version is read from the database without writing", func(t *testing.T) {
+		var out bytes.Buffer
+		connect := func() (migrator, error) { return &readOnlyMigrator{t: t, version: 2}, nil }
+
+		require.NoError(t, run([]string{"--dry-run", "--path", dir, "up"}, &out, connect))
+		assert.Equal(t, 1, strings.Count(out.String(), "-- Version"))
+		assert.Contains(t, out.String(), "000003_add_round_flag.up.sql")
+	})
+
+	t.Run("fresh database has no version", func(t *testing.T) {
+		var out bytes.Buffer
+		connect := func() (migrator, error) { return &readOnlyMigrator{t: t, err: migrate.ErrNilVersion}, nil }
+
+		require.NoError(t, run([]string{"--dry-run", "--path", dir, "down"}, &out, connect))
+		assert.Equal(t, "-- No pending migrations\n", out.String())
+	})
+
+	t.Run("dirty database is reported", func(t *testing.T) {
+		var out bytes.Buffer
+		connect := func() (migrator, error) { return &readOnlyMigrator{t: t, version: 2, dirty: true}, nil }
+
+		err := run([]string{"--dry-run", "--path", dir, "up"}, &out, connect)
+		assert.ErrorContains(t, err, "dirty")
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("only up and down are supported", func(t *testing.T) {
+		err := run([]string{"--dry-run", "--from", "1", "force", "1"}, &bytes.Buffer{}, noConnect(t))
+		assert.ErrorIs(t, err, errUsage)
+	})
+}
+
+func TestRun_Usage(t *testing.T) {
+	assert.ErrorIs(t, run(nil, &bytes.Buffer{}, noConnect(t)), errUsage)
+	assert.ErrorIs(t, run([]string{"--unknown"}, &bytes.Buffer{}, noConnect(t)), errUsage)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bmstu-itstech/tjudge/internal/config"
+	"github.com/bmstu-itstech/tjudge/internal/domain/tournament"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/queue"
+	"github.com/bmstu-itstech/tjudge/internal/seed"
+	"github.com/bmstu-itstech/tjudge/internal/websocket"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+func main() {
+	teams := flag.Int("teams", 4, "number of demo users and teams per tournament")
+	games := flag.Int("games", len(seed.BuiltinGames), "number of built-in games in demo tournaments")
+	password := flag.String("password", seed.DefaultPassword, "password for newly created demo users")
+	enqueue := flag.Bool("enqueue", false, "enqueue a round of matches in the active demo tournament (requires Redis)")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	log, err := logger.NewWithOptions(logger.Options{
+		Level:  cfg.Logging.Level,
+		Format: cfg.Logging.Format,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = log.Sync() }()
+
+	m := metrics.New()
+
+	database, err := db.New(&cfg.Database, log, m)
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer database.Close()
+
+	seeder := seed.NewSeeder(database, log)
+
+	// Redis нужен только для постановки матчей в очередь
+	if *enqueue {
+		redisCache, err := cache.New(&cfg.Redis, log, m)
+		if err != nil {
+			log.Fatal("Failed to connect to Redis", zap.Error(err))
+		}
+		defer redisCache.Close()
+
+		seeder.SetMatchRunner(tournament.NewService(
+			db.NewTournamentRepository(database),
+			db.NewMatchRepository(database),
+			queue.NewQueueManager(redisCache, log, m),
+			db.NewGameRepository(database),
+			cache.NewTournamentCache(redisCache),
+			cache.NewLeaderboardCache(redisCache),
+			websocket.NewNoopBroadcaster(),
+			cache.NewDistributedLock(redisCache),
+			log,
+		))
+	}
+
+	result, err := seeder.Run(context.Background(), seed.Options{
+		Teams:       *teams,
+		Games:       *games,
+		Password:    *password,
+		ProgramsDir: cfg.Storage.ProgramsPath,
+		Enqueue:     *enqueue,
+	})
+	if err != nil {
+		log.Fatal("Failed to seed demo data", zap.Error(err))
+	}
+
+	printResult(os.Stdout, result)
+}
+
+// printResult выводит учётные данные и созданные турниры
+func printResult(w io.Writer, result *seed.Result) {
+	fmt.Fprintln(w, "Demo users:")
+	for _, c := range result.Credentials {
+		password := c.Password
+		if password == "" {
+			password = "(already existed, password unchanged)"
+		}
+		fmt.Fprintf(w, "  %-16s %-6s %s\n", c.Username, c.Role, password)
+	}
+
+	fmt.Fprintln(w, "Demo tournaments:")
+	for _, t := range result.Tournaments {
+		fmt.Fprintf(w, "  %-8s %-8s %s (%s)\n", t.Code, t.Status, t.Name, t.ID)
+	}
+
+	fmt.Fprintf(w, "Programs created: %d\n", result.ProgramsCreated)
+	fmt.Fprintf(w, "Matches enqueued: %d\n", result.MatchesEnqueued)
+}
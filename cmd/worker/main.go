@@ -5,21 +5,25 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"os/exec"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/config"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/domain/bracket"
+	"github.com/bmstu-itstech/tjudge/internal/domain/notification"
 	"github.com/bmstu-itstech/tjudge/internal/domain/rating"
+	"github.com/bmstu-itstech/tjudge/internal/domain/webhook"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/executor"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/notify"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/queue"
 	"github.com/bmstu-itstech/tjudge/internal/worker"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
 	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/bmstu-itstech/tjudge/pkg/tracing"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
@@ -49,6 +53,15 @@ func main() {
 		zap.Int("max_workers", cfg.Worker.MaxWorkers),
 	)
 
+	// Инициализируем трассировку
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceNameOr("tjudge-worker"),
+	})
+	if err != nil {
+		log.Fatal("Failed to setup tracing", zap.Error(err))
+	}
+
 	// Инициализируем метрики
 	m := metrics.New()
 
@@ -85,6 +98,8 @@ func main() {
 	matchRepo := db.NewMatchRepository(database)
 	ratingRepo := db.NewRatingRepository(database)
 	programRepo := db.NewProgramRepository(database)
+	gameRepo := db.NewGameRepository(database)
+	tournamentRepo := db.NewTournamentRepository(database)
 
 	// Инициализируем кэши с метриками
 	matchCache := cache.NewMatchCache(redisCache).WithMetrics(m)
@@ -92,24 +107,41 @@ func main() {
 
 	// Инициализируем queue manager
 	queueManager := queue.NewQueueManager(redisCache, log, m)
+	queueManager.SetPriorityAging(cfg.Worker.PriorityAging)
+
+	// Периодически обновляем метрики размеров очередей
+	samplerCtx, stopSampler := context.WithCancel(context.Background())
+	go queueManager.RunMetricsSampler(samplerCtx, 15*time.Second)
 
 	// Инициализируем rating service
 	ratingService := rating.NewService(ratingRepo, leaderboardCache, log)
 
-	// Проверяем наличие образа tjudge-cli
-	checkTJudgeCLIImage(log)
-
 	// Инициализируем executor с путём к программам
 	exec, err := executor.NewExecutor(cfg.Executor, cfg.Storage.ProgramsPath, cfg.Storage.HostProgramsPath, log)
 	if err != nil {
 		log.Fatal("Failed to create executor", zap.Error(err))
 	}
 	defer exec.Close()
+	exec.SetMetrics(m)
+
+	// Загружаем образ tjudge-cli заранее, чтобы первый матч не ждал docker pull
+	if !cfg.Executor.SkipWarmup {
+		warmupCtx, warmupCancel := context.WithTimeout(context.Background(), cfg.Executor.WarmupTimeout)
+		err := exec.WarmUp(warmupCtx)
+		warmupCancel()
+		if err != nil {
+			if !cfg.Executor.WarmupWarnOnly {
+				log.Fatal("Executor warmup failed", zap.Error(err))
+			}
+			log.Warn("Executor warmup failed, matches will fail until the image is available", zap.Error(err))
+		}
+	}
 
 	log.Info("Executor initialized",
 		zap.Int64("cpu_quota", cfg.Executor.CPUQuota),
 		zap.Int64("memory_limit", cfg.Executor.MemoryLimit),
 		zap.Duration("timeout", cfg.Executor.Timeout),
+		zap.Int("warm_pool_size", cfg.Executor.WarmPoolSize),
 	)
 
 	// Инициализируем processor
@@ -122,9 +154,68 @@ func main() {
 		matchCache,
 		log,
 	)
+	processor.SetGameRepository(gameRepo)
+	processor.SetRoundTracker(gameRepo)
+	processor.SetRetryPolicy(worker.RetryPolicy{
+		Attempts:   cfg.Executor.RetryAttempts,
+		Backoff:    cfg.Executor.RetryBackoff,
+		MaxBackoff: cfg.Executor.RetryMaxBackoff,
+	})
+	builder := executor.NewBuilder(exec.Compilers(), log)
+	builder.SetMetrics(m)
+	processor.SetBuilder(builder)
+	processor.SetMetrics(m)
+	processor.SetLiveFeed(cache.NewLiveMatchFeed(redisCache))
+	processor.SetEventPublisher(cache.NewTournamentEvents(redisCache))
+	processor.SetTimingStore(matchRepo)
+
+	// Пакетная запись результатов матчей
+	var resultBuffer *worker.ResultBuffer
+	if cfg.Worker.ResultBatchSize > 1 {
+		resultBuffer = worker.NewResultBuffer(matchRepo, cfg.Worker.ResultBatchSize, cfg.Worker.ResultBatchMaxAge, log)
+		resultBuffer.SetMetrics(m)
+		processor.SetResultBuffer(resultBuffer)
+	}
+	bracketService := bracket.NewService(
+		db.NewBracketRepository(database),
+		tournamentRepo,
+		matchRepo,
+		queueManager,
+		cache.NewTournamentCache(redisCache),
+		log,
+	)
+	processor.SetBracketAdvancer(bracketService)
+
+	// Уведомления: события пишутся в outbox, dispatcher доставляет их с повторами:
+	// webhook'и турниров - всегда, email - при настроенном SMTP
+	notificationRepo := db.NewNotificationRepository(database)
+	dispatcher := notify.NewDispatcher(notificationRepo, notify.Config{
+		PollInterval: cfg.Notifications.PollInterval,
+		BatchSize:    cfg.Notifications.BatchSize,
+		MaxAttempts:  cfg.Notifications.MaxAttempts,
+		RetryDelay:   cfg.Notifications.RetryDelay,
+		MaxDelay:     cfg.Notifications.MaxDelay,
+	}, log)
+
+	webhookRepo := db.NewWebhookRepository(database)
+	webhookService := webhook.NewService(webhookRepo, tournamentRepo, log)
+	processor.SetRoundNotifier(webhookService)
+	bracketService.AddNotifier(webhookService)
+	dispatcher.SetSender(domain.NotificationChannelWebhook, notify.NewWebhookSender(webhookRepo, cfg.Notifications.AllowPrivateWebhooks))
+
+	if cfg.Notifications.Enabled() {
+		notificationService := notification.NewService(notificationRepo, db.NewUserRepository(database), log)
+		notificationService.SetBaseURL(cfg.Server.BaseURL)
+		processor.SetNotifier(notificationService)
+		bracketService.AddNotifier(notificationService)
+		dispatcher.SetSender(domain.NotificationChannelEmail, notify.NewSMTPSender(cfg.Notifications.SMTP))
+	}
+	dispatcher.Start()
 
-	// Инициализируем leaderboard refresher (обновляет materialized views каждые 30 секунд)
-	leaderboardRefresher := db.NewLeaderboardRefresher(database, 30*time.Second, log)
+	// Инициализируем leaderboard refresher (обновляет materialized views с периодом из конфига).
+	// Блокировка исключает одновременное обновление несколькими worker'ами и ручное из API
+	leaderboardRefresher := db.NewLeaderboardRefresher(database, cfg.Worker.LeaderboardRefreshInterval, log)
+	leaderboardRefresher.SetLock(cache.NewDistributedLock(redisCache))
 	leaderboardRefresher.Start()
 	log.Info("Leaderboard refresher started")
 
@@ -136,6 +227,10 @@ func main() {
 		log,
 		m,
 	)
+	// При недоступности Docker daemon пул встаёт на паузу до успешного ping
+	pool.SetDockerProber(exec)
+	// Матчи игры выполняются только в её окне времени, если оно задано
+	pool.SetGameWindows(gameRepo)
 
 	// Инициализируем recovery service и восстанавливаем застрявшие матчи
 	recoveryService := worker.NewRecoveryService(
@@ -146,8 +241,12 @@ func main() {
 			StuckDuration:    30 * time.Second, // Матч считается застрявшим после 30 секунд
 			BatchSize:        1000,
 			PeriodicInterval: 30 * time.Second, // Проверка каждые 30 секунд
+			ReconcileMinAge:  time.Minute,      // Pending матч старше минуты должен быть в очереди
 		},
 	)
+	recoveryService.SetMetrics(m)
+
+	recoveryService.SetContainerCleaner(exec)
 
 	// Запускаем восстановление при старте
 	if err := recoveryService.RecoverOnStartup(context.Background()); err != nil {
@@ -155,9 +254,35 @@ func main() {
 		// Продолжаем работу, это не критическая ошибка
 	}
 
+	// Запускаем контейнеры пула после удаления оставшихся от прошлого запуска
+	if !cfg.Executor.SkipWarmup {
+		prewarmCtx, prewarmCancel := context.WithTimeout(context.Background(), cfg.Executor.WarmupTimeout)
+		if err := exec.Prewarm(prewarmCtx); err != nil {
+			log.Warn("Failed to prewarm executor containers, they will be started on demand", zap.Error(err))
+		}
+		prewarmCancel()
+	}
+
 	// Запускаем периодическое восстановление
 	recoveryService.Start()
 
+	// Повышение приоритета долго ожидающих матчей (0 - выключено)
+	var escalationService *worker.PriorityEscalationService
+	if cfg.Worker.PriorityEscalationAge > 0 {
+		escalationService = worker.NewPriorityEscalationService(
+			matchRepo,
+			queueManager,
+			log,
+			worker.PriorityEscalationConfig{
+				MaxAge:   cfg.Worker.PriorityEscalationAge,
+				Interval: 5 * time.Minute,
+			},
+		)
+		escalationService.SetMetrics(m)
+		escalationService.SetLock(cache.NewDistributedLock(redisCache))
+		escalationService.Start()
+	}
+
 	// Запускаем worker pool
 	pool.Start()
 	log.Info("Worker pool started",
@@ -205,15 +330,31 @@ func main() {
 	// Останавливаем recovery service
 	recoveryService.Stop()
 
+	// Останавливаем повышение приоритетов
+	if escalationService != nil {
+		escalationService.Stop()
+	}
+
 	// Останавливаем leaderboard refresher
 	leaderboardRefresher.Stop()
 
+	// Останавливаем доставку уведомлений
+	dispatcher.Stop()
+
+	// Останавливаем обновление метрик очередей
+	stopSampler()
+
 	// Останавливаем worker pool
 	pool.Stop()
 
 	// Ждём завершения worker pool
 	pool.Wait()
 
+	// Сохраняем результаты, оставшиеся в буфере
+	if resultBuffer != nil {
+		resultBuffer.Close()
+	}
+
 	// Останавливаем metrics сервер
 	if metricsSrv != nil {
 		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -223,144 +364,14 @@ func main() {
 		}
 	}
 
-	log.Info("Worker pool stopped gracefully",
-		zap.Int64("total_matches_processed", pool.GetMatchesProcessed()),
-	)
-}
-
-// checkTJudgeCLIImage проверяет наличие Docker образа tjudge-cli и пытается его собрать
-func checkTJudgeCLIImage(log *logger.Logger) {
-	const imageName = "tjudge-cli:latest"
-
-	// Проверяем наличие образа
-	if imageExists(imageName, log) {
-		log.Info("Docker image tjudge-cli verified",
-			zap.String("image", imageName),
-		)
-		return
+	// Отправляем оставшиеся спаны
+	tracingCtx, tracingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer tracingCancel()
+	if err := shutdownTracing(tracingCtx); err != nil {
+		log.Error("Failed to flush traces", zap.Error(err))
 	}
 
-	log.Warn("Docker image tjudge-cli:latest not found, attempting to build...",
-		zap.String("image", imageName),
-	)
-
-	// Пытаемся собрать образ через docker compose
-	if tryBuildWithCompose(log) {
-		if imageExists(imageName, log) {
-			log.Info("Docker image tjudge-cli built successfully",
-				zap.String("image", imageName),
-			)
-			return
-		}
-	}
-
-	// Пытаемся собрать напрямую через docker build
-	if tryBuildDirectly(log) {
-		if imageExists(imageName, log) {
-			log.Info("Docker image tjudge-cli built successfully",
-				zap.String("image", imageName),
-			)
-			return
-		}
-	}
-
-	log.Error("Failed to build tjudge-cli image!",
-		zap.String("image", imageName),
-		zap.String("hint", "Run 'docker compose build tjudge-cli' manually or check docker/tjudge/Dockerfile"),
+	log.Info("Worker pool stopped gracefully",
+		zap.Int64("total_matches_processed", pool.GetMatchesProcessed()),
 	)
-	log.Warn("Worker will fail to execute matches without tjudge-cli image")
-}
-
-// imageExists проверяет существование Docker образа
-func imageExists(imageName string, log *logger.Logger) bool {
-	cmd := exec.Command("docker", "images", "-q", imageName)
-	output, err := cmd.Output()
-	if err != nil {
-		log.Debug("Failed to check image existence",
-			zap.Error(err),
-			zap.String("image", imageName),
-		)
-		return false
-	}
-	return strings.TrimSpace(string(output)) != ""
-}
-
-// tryBuildWithCompose пытается собрать образ через docker compose
-func tryBuildWithCompose(log *logger.Logger) bool {
-	log.Info("Trying to build tjudge-cli with docker compose...")
-
-	// Проверяем возможные пути к docker-compose.yml
-	composePaths := []string{
-		"docker-compose.yml",
-		"../docker-compose.yml",
-		"../../docker-compose.yml",
-		"/app/docker-compose.yml",
-	}
-
-	for _, path := range composePaths {
-		if _, err := os.Stat(path); err == nil {
-			cmd := exec.Command("docker", "compose", "-f", path, "build", "tjudge-cli")
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-
-			log.Info("Running docker compose build",
-				zap.String("compose_file", path),
-			)
-
-			if err := cmd.Run(); err != nil {
-				log.Debug("Docker compose build failed",
-					zap.Error(err),
-					zap.String("path", path),
-				)
-				continue
-			}
-			return true
-		}
-	}
-
-	return false
-}
-
-// tryBuildDirectly пытается собрать образ напрямую через docker build
-func tryBuildDirectly(log *logger.Logger) bool {
-	log.Info("Trying to build tjudge-cli with docker build...")
-
-	// Проверяем возможные пути к Dockerfile
-	dockerfilePaths := []struct {
-		dockerfile string
-		context    string
-	}{
-		{"docker/tjudge/Dockerfile", "."},
-		{"../docker/tjudge/Dockerfile", ".."},
-		{"../../docker/tjudge/Dockerfile", "../.."},
-		{"/app/docker/tjudge/Dockerfile", "/app"},
-	}
-
-	for _, paths := range dockerfilePaths {
-		if _, err := os.Stat(paths.dockerfile); err == nil {
-			cmd := exec.Command("docker", "build",
-				"-t", "tjudge-cli:latest",
-				"-f", paths.dockerfile,
-				paths.context,
-			)
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-
-			log.Info("Running docker build",
-				zap.String("dockerfile", paths.dockerfile),
-				zap.String("context", paths.context),
-			)
-
-			if err := cmd.Run(); err != nil {
-				log.Debug("Docker build failed",
-					zap.Error(err),
-					zap.String("dockerfile", paths.dockerfile),
-				)
-				continue
-			}
-			return true
-		}
-	}
-
-	return false
 }
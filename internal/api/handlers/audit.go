@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAuditLogLimit = 100
+	maxAuditLogLimit     = 500
+)
+
+// AuditLogReader интерфейс для чтения журнала действий администраторов
+type AuditLogReader interface {
+	List(ctx context.Context, filter domain.AuditLogFilter) ([]*domain.AuditLogEntry, error)
+}
+
+// AuditHandler обрабатывает запросы к журналу действий
+type AuditHandler struct {
+	auditLog AuditLogReader
+	log      *logger.Logger
+}
+
+// NewAuditHandler создаёт новый audit handler
+func NewAuditHandler(auditLog AuditLogReader, log *logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditLog: auditLog,
+		log:      log,
+	}
+}
+
+// List возвращает записи журнала, новые первыми. Если страница заполнена,
+// курсор следующей страницы возвращается в заголовке X-Next-Cursor
+// GET /api/v1/admin/audit-log?user_id=&action=&resource=&id=&after=&cursor=&limit=
+// GET /api/v1/admin/audit?entity_id=&actor=&limit=
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditLogFilter(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	entries, err := h.auditLog.List(r.Context(), filter)
+	if err != nil {
+		h.log.LogError("Failed to list audit log", err)
+		writeError(w, err)
+		return
+	}
+
+	if len(entries) == filter.Limit {
+		last := entries[len(entries)-1]
+		w.Header().Set("X-Next-Cursor", encodeAuditLogCursor(domain.AuditLogCursor{CreatedAt: last.CreatedAt, ID: last.ID}))
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// parseAuditLogFilter читает фильтры журнала. after - время в RFC 3339,
+// resource и id - тип и ID объекта действия, entity_id - ID объекта любого типа,
+// actor - синоним user_id, cursor - значение X-Next-Cursor предыдущей страницы
+func parseAuditLogFilter(r *http.Request) (domain.AuditLogFilter, error) {
+	query := r.URL.Query()
+	filter := domain.AuditLogFilter{
+		Action:     query.Get("action"),
+		TargetType: query.Get("resource"),
+		TargetID:   query.Get("id"),
+		Limit:      defaultAuditLogLimit,
+	}
+
+	if filter.TargetID != "" && filter.TargetType == "" {
+		return filter, errors.ErrInvalidInput.WithMessage("id requires resource")
+	}
+	if entityID := query.Get("entity_id"); entityID != "" {
+		if filter.TargetID != "" && filter.TargetID != entityID {
+			return filter, errors.ErrInvalidInput.WithMessage("id and entity_id differ")
+		}
+		filter.TargetID = entityID
+	}
+
+	userID := query.Get("user_id")
+	if actor := query.Get("actor"); actor != "" {
+		if userID != "" && userID != actor {
+			return filter, errors.ErrInvalidInput.WithMessage("user_id and actor differ")
+		}
+		userID = actor
+	}
+	if raw := userID; raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, errors.ErrInvalidInput.WithMessage("invalid user_id")
+		}
+		filter.UserID = &id
+	}
+
+	if raw := query.Get("after"); raw != "" {
+		after, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.ErrInvalidInput.WithMessage("after must be an RFC 3339 timestamp")
+		}
+		filter.After = &after
+	}
+
+	if raw := query.Get("cursor"); raw != "" {
+		cursor, err := decodeAuditLogCursor(raw)
+		if err != nil {
+			return filter, errors.ErrInvalidInput.WithMessage("invalid cursor")
+		}
+		filter.Before = cursor
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxAuditLogLimit {
+			return filter, errors.ErrInvalidInput.WithMessage(fmt.Sprintf("limit must be between 1 and %d", maxAuditLogLimit))
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// encodeAuditLogCursor кодирует позицию записи в base64(json)
+func encodeAuditLogCursor(cursor domain.AuditLogCursor) string {
+	// Маршалинг структуры из времени и числа не может завершиться ошибкой
+	data, _ := json.Marshal(cursor)
+	return base64.URLEncoding.EncodeToString(data)
+}
+
+// decodeAuditLogCursor декодирует курсор, выданный encodeAuditLogCursor
+func decodeAuditLogCursor(encoded string) (*domain.AuditLogCursor, error) {
+	data, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+
+	var cursor domain.AuditLogCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cursor: %w", err)
+	}
+	if cursor.ID <= 0 || cursor.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("cursor has no position")
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuditLog struct {
+	filter  domain.AuditLogFilter
+	entries []*domain.AuditLogEntry
+}
+
+func (f *fakeAuditLog) List(ctx context.Context, filter domain.AuditLogFilter) ([]*domain.AuditLogEntry, error) {
+	f.filter = filter
+	if f.entries == nil {
+		return []*domain.AuditLogEntry{}, nil
+	}
+	return f.entries, nil
+}
+
+func TestAuditHandler_List(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	t.Run("passes filters", func(t *testing.T) {
+		auditLog := &fakeAuditLog{}
+		handler := NewAuditHandler(auditLog, log)
+		userID := uuid.New()
+
+		req := httptest.NewRequest(http.MethodGet,
+			"/api/v1/admin/audit-log?user_id="+userID.String()+"&action=POST+/api/v1/admin/impersonate/{userId}&after=2025-01-01T00:00:00Z", nil)
+		w := httptest.NewRecorder()
+		handler.List(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, userID, *auditLog.filter.UserID)
+		assert.Equal(t, "POST /api/v1/admin/impersonate/{userId}", auditLog.filter.Action)
+		assert.True(t, auditLog.filter.After.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, defaultAuditLogLimit, auditLog.filter.Limit)
+	})
+
+	t.Run("filters by resource", func(t *testing.T) {
+		auditLog := &fakeAuditLog{}
+		handler := NewAuditHandler(auditLog, log)
+		tournamentID := uuid.New().String()
+
+		w := httptest.NewRecorder()
+		handler.List(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?resource=tournament&id="+tournamentID, nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "tournament", auditLog.filter.TargetType)
+		assert.Equal(t, tournamentID, auditLog.filter.TargetID)
+	})
+
+	t.Run("entity_id and actor", func(t *testing.T) {
+		auditLog := &fakeAuditLog{}
+		handler := NewAuditHandler(auditLog, log)
+		actor, entityID := uuid.New(), uuid.New().String()
+
+		w := httptest.NewRecorder()
+		handler.List(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?entity_id="+entityID+"&actor="+actor.String()+"&limit=10", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, actor, *auditLog.filter.UserID)
+		assert.Empty(t, auditLog.filter.TargetType)
+		assert.Equal(t, entityID, auditLog.filter.TargetID)
+		assert.Equal(t, 10, auditLog.filter.Limit)
+	})
+
+	t.Run("next cursor", func(t *testing.T) {
+		createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+		auditLog := &fakeAuditLog{entries: []*domain.AuditLogEntry{
+			{ID: 9, CreatedAt: createdAt.Add(time.Minute)},
+			{ID: 7, CreatedAt: createdAt},
+		}}
+		handler := NewAuditHandler(auditLog, log)
+
+		w := httptest.NewRecorder()
+		handler.List(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?limit=2", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		cursor := w.Header().Get("X-Next-Cursor")
+		require.NotEmpty(t, cursor)
+
+		// The cursor points past the last returned entry
+		w = httptest.NewRecorder()
+		handler.List(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?limit=2&cursor="+cursor, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotNil(t, auditLog.filter.Before)
+		assert.Equal(t, int64(7), auditLog.filter.Before.ID)
+		assert.True(t, auditLog.filter.Before.CreatedAt.Equal(createdAt))
+
+		// A partial page has no next cursor
+		w = httptest.NewRecorder()
+		handler.List(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?limit=3", nil))
+		assert.Empty(t, w.Header().Get("X-Next-Cursor"))
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		for _, query := range []string{"user_id=nope", "actor=nope", "after=yesterday", "limit=1000", "id=" + uuid.New().String(), "cursor=nope"} {
+			handler := NewAuditHandler(&fakeAuditLog{}, log)
+
+			w := httptest.NewRecorder()
+			handler.List(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit-log?"+query, nil))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
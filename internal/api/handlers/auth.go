@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/internal/domain/auth"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +24,11 @@ type AuthService interface {
 	GetUserFromToken(ctx context.Context, token string) (*domain.User, error)
 	ValidateToken(token string) (*auth.Claims, error)
 	UpdateProfile(ctx context.Context, userID string, req *auth.UpdateProfileRequest) (*domain.User, error)
+	Impersonate(ctx context.Context, adminID, userID uuid.UUID) (*auth.ImpersonationResponse, error)
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID) error
+	CreateAPIKey(ctx context.Context, userID uuid.UUID, req *auth.CreateAPIKeyRequest) (*auth.CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error
 }
 
 // AuthHandler обрабатывает запросы аутентификации
@@ -183,20 +191,11 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 // UpdateProfile обновляет профиль пользователя
 // PUT /api/v1/auth/profile
 func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
-	// Извлекаем токен из заголовка
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" || len(authHeader) < 8 || authHeader[:7] != "Bearer " {
-		writeError(w, errors.ErrUnauthorized)
-		return
-	}
-
-	token := authHeader[7:] // Remove "Bearer "
-
-	// Валидируем токен и получаем user ID
-	claims, err := h.authService.ValidateToken(token)
+	// Email и пароль меняет только сам пользователь: утёкший API ключ или админ
+	// в сессии от имени пользователя не должны получить контроль над аккаунтом
+	userID, err := h.requireSessionUser(r, "profile cannot be changed")
 	if err != nil {
-		h.log.LogError("Invalid token", err)
-		writeError(w, errors.ErrUnauthorized)
+		writeError(w, err)
 		return
 	}
 
@@ -208,7 +207,7 @@ func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Обновляем профиль
-	user, err := h.authService.UpdateProfile(r.Context(), claims.UserID.String(), &req)
+	user, err := h.authService.UpdateProfile(r.Context(), userID.String(), &req)
 	if err != nil {
 		h.log.LogError("Failed to update profile", err)
 		writeError(w, err)
@@ -221,3 +220,176 @@ func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, user)
 }
+
+// Impersonate выпускает админу короткоживущий токен от имени пользователя
+// POST /api/v1/admin/impersonate/:userId
+func (h *AuthHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid user ID"))
+		return
+	}
+
+	resp, err := h.authService.Impersonate(r.Context(), adminID, userID)
+	if err != nil {
+		h.log.LogError("Failed to impersonate user", err,
+			zap.String("admin_id", adminID.String()),
+			zap.String("user_id", userID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// RevokeAllSessions отзывает все сессии текущего пользователя, включая текущую
+// POST /api/v1/auth/revoke-all
+func (h *AuthHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Админ в сессии от имени пользователя не должен разлогинивать его
+	if _, ok := middleware.GetImpersonatedBy(r.Context()); ok {
+		writeError(w, errors.ErrForbidden.WithMessage("sessions cannot be revoked while impersonating"))
+		return
+	}
+
+	if err := h.authService.RevokeAllSessions(r.Context(), userID); err != nil {
+		h.log.LogError("Failed to revoke sessions", err, zap.String("user_id", userID.String()))
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeUserSessions отзывает все сессии пользователя по запросу админа
+// POST /api/v1/admin/users/:id/revoke-sessions
+func (h *AuthHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid user ID"))
+		return
+	}
+
+	if err := h.authService.RevokeAllSessions(r.Context(), userID); err != nil {
+		h.log.LogError("Failed to revoke user sessions", err,
+			zap.String("admin_id", adminID.String()),
+			zap.String("user_id", userID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	h.log.Info("User sessions revoked by admin",
+		zap.Bool("audit", true),
+		zap.String("admin_id", adminID.String()),
+		zap.String("user_id", userID.String()),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateAPIKey выпускает API ключ текущему пользователю. Ключ возвращается только в этом ответе
+// POST /api/v1/auth/api-keys
+func (h *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.requireSessionUser(r, "api keys cannot be managed")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req auth.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	key, err := h.authService.CreateAPIKey(r.Context(), userID, &req)
+	if err != nil {
+		h.log.LogError("Failed to create api key", err, zap.String("user_id", userID.String()))
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, key)
+}
+
+// ListAPIKeys возвращает действующие API ключи текущего пользователя
+// GET /api/v1/auth/api-keys
+func (h *AuthHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.requireSessionUser(r, "api keys cannot be managed")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	keys, err := h.authService.ListAPIKeys(r.Context(), userID)
+	if err != nil {
+		h.log.LogError("Failed to list api keys", err, zap.String("user_id", userID.String()))
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// RevokeAPIKey отзывает API ключ текущего пользователя
+// DELETE /api/v1/auth/api-keys/:id
+func (h *AuthHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.requireSessionUser(r, "api keys cannot be managed")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	keyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid api key ID"))
+		return
+	}
+
+	if err := h.authService.RevokeAPIKey(r.Context(), userID, keyID); err != nil {
+		h.log.LogError("Failed to revoke api key", err,
+			zap.String("user_id", userID.String()),
+			zap.String("key_id", keyID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireSessionUser возвращает пользователя, вошедшего по паролю. Ключами и профилем нельзя управлять
+// по API ключу и от имени пользователя: иначе утёкший ключ или impersonation дают бессрочный доступ.
+// denied - начало сообщения об отказе, например "api keys cannot be managed"
+func (h *AuthHandler) requireSessionUser(r *http.Request, denied string) (uuid.UUID, error) {
+	userID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if _, ok := middleware.GetAPIKeyScope(r.Context()); ok {
+		return uuid.Nil, errors.ErrForbidden.WithMessage(denied + " with an api key")
+	}
+	if _, ok := middleware.GetImpersonatedBy(r.Context()); ok {
+		return uuid.Nil, errors.ErrForbidden.WithMessage(denied + " while impersonating")
+	}
+	return userID, nil
+}
@@ -8,10 +8,12 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/internal/domain/auth"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -76,6 +78,40 @@ func (m *MockAuthService) ValidateToken(token string) (*auth.Claims, error) {
 	return args.Get(0).(*auth.Claims), args.Error(1)
 }
 
+func (m *MockAuthService) Impersonate(ctx context.Context, adminID, userID uuid.UUID) (*auth.ImpersonationResponse, error) {
+	args := m.Called(ctx, adminID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.ImpersonationResponse), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) CreateAPIKey(ctx context.Context, userID uuid.UUID, req *auth.CreateAPIKeyRequest) (*auth.CreatedAPIKey, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.CreatedAPIKey), args.Error(1)
+}
+
+func (m *MockAuthService) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	args := m.Called(ctx, userID, keyID)
+	return args.Error(0)
+}
+
 func TestAuthHandler_Register(t *testing.T) {
 	log, _ := logger.New("error", "json")
 
@@ -495,3 +531,273 @@ func TestAuthHandler_Me(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 }
+
+func TestAuthHandler_Impersonate(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(adminID uuid.UUID, userID string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/impersonate/"+userID, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("userId", userID)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, adminID)
+		return req.WithContext(ctx)
+	}
+
+	t.Run("issues token", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+		adminID, userID := uuid.New(), uuid.New()
+
+		mockService.On("Impersonate", mock.Anything, adminID, userID).Return(&auth.ImpersonationResponse{
+			AccessToken:    "impersonation-token",
+			ImpersonatedBy: adminID,
+			User:           &domain.User{ID: userID},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		handler.Impersonate(w, newRequest(adminID, userID.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "impersonation-token", response["access_token"])
+		assert.NotContains(t, response, "refresh_token")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("feature disabled", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+		adminID, userID := uuid.New(), uuid.New()
+
+		mockService.On("Impersonate", mock.Anything, adminID, userID).
+			Return(nil, errors.ErrForbidden.WithMessage("impersonation is disabled"))
+
+		w := httptest.NewRecorder()
+		handler.Impersonate(w, newRequest(adminID, userID.String()))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.Impersonate(w, newRequest(uuid.New(), "not-a-uuid"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Impersonate", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthHandler_UpdateProfile_RequiresSessionUser(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(ctx context.Context) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/auth/profile", bytes.NewBufferString(`{"password":"NewPassword123!"}`))
+		return req.WithContext(ctx)
+	}
+	userCtx := context.WithValue(context.Background(), middleware.UserIDKey, uuid.New())
+
+	t.Run("forbidden while impersonating", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.UpdateProfile(w, newRequest(context.WithValue(userCtx, middleware.ImpersonatedByKey, uuid.New())))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("forbidden with a read-write api key", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.UpdateProfile(w, newRequest(context.WithValue(userCtx, middleware.APIKeyScopeKey, domain.APIKeyScopeReadWrite)))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("session user updates own profile", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+		userID := userCtx.Value(middleware.UserIDKey).(uuid.UUID)
+		mockService.On("UpdateProfile", mock.Anything, userID.String(), mock.Anything).Return(&domain.User{ID: userID}, nil)
+
+		w := httptest.NewRecorder()
+		handler.UpdateProfile(w, newRequest(userCtx))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestAuthHandler_RevokeAllSessions(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	t.Run("revokes own sessions", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+		userID := uuid.New()
+		mockService.On("RevokeAllSessions", mock.Anything, userID).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/revoke-all", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+		w := httptest.NewRecorder()
+
+		handler.RevokeAllSessions(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("forbidden while impersonating", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/revoke-all", nil)
+		ctx := context.WithValue(req.Context(), middleware.UserIDKey, uuid.New())
+		ctx = context.WithValue(ctx, middleware.ImpersonatedByKey, uuid.New())
+		w := httptest.NewRecorder()
+
+		handler.RevokeAllSessions(w, req.WithContext(ctx))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "RevokeAllSessions", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthHandler_RevokeUserSessions(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(userID string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/"+userID+"/revoke-sessions", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", userID)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, uuid.New())
+		return req.WithContext(ctx)
+	}
+
+	t.Run("revokes user sessions", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+		userID := uuid.New()
+		mockService.On("RevokeAllSessions", mock.Anything, userID).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.RevokeUserSessions(w, newRequest(userID.String()))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+		userID := uuid.New()
+		mockService.On("RevokeAllSessions", mock.Anything, userID).Return(errors.ErrNotFound.WithMessage("user not found"))
+
+		w := httptest.NewRecorder()
+		handler.RevokeUserSessions(w, newRequest(userID.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.RevokeUserSessions(w, newRequest("not-a-uuid"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "RevokeAllSessions", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthHandler_CreateAPIKey(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(ctx context.Context) *http.Request {
+		body := []byte(`{"name":"ci","scope":"read"}`)
+		return httptest.NewRequest(http.MethodPost, "/api/v1/auth/api-keys", bytes.NewReader(body)).WithContext(ctx)
+	}
+
+	t.Run("returns the key once", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+		userID := uuid.New()
+		created := &auth.CreatedAPIKey{
+			APIKey: &domain.APIKey{ID: uuid.New(), UserID: userID, Name: "ci", KeyHash: "hash", Prefix: "tjk_abcdefgh", Scope: domain.APIKeyScopeRead},
+			Key:    "tjk_abcdefgh-secret",
+		}
+		mockService.On("CreateAPIKey", mock.Anything, userID, &auth.CreateAPIKeyRequest{Name: "ci", Scope: domain.APIKeyScopeRead}).Return(created, nil)
+
+		w := httptest.NewRecorder()
+		handler.CreateAPIKey(w, newRequest(context.WithValue(context.Background(), middleware.UserIDKey, userID)))
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "tjk_abcdefgh-secret", resp["key"])
+		assert.Equal(t, "read", resp["scope"])
+		assert.NotContains(t, resp, "key_hash")
+	})
+
+	t.Run("forbidden with an api key", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+		ctx := context.WithValue(context.Background(), middleware.UserIDKey, uuid.New())
+		ctx = context.WithValue(ctx, middleware.APIKeyScopeKey, domain.APIKeyScopeReadWrite)
+
+		w := httptest.NewRecorder()
+		handler.CreateAPIKey(w, newRequest(ctx))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthHandler_RevokeAPIKey(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(keyID string, userID uuid.UUID) *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/auth/api-keys/"+keyID, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", keyID)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+		return req.WithContext(ctx)
+	}
+
+	t.Run("revokes own key", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+		userID, keyID := uuid.New(), uuid.New()
+		mockService.On("RevokeAPIKey", mock.Anything, userID, keyID).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.RevokeAPIKey(w, newRequest(keyID.String(), userID))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("key of another user", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		handler := NewAuthHandler(mockService, log)
+		userID, keyID := uuid.New(), uuid.New()
+		mockService.On("RevokeAPIKey", mock.Anything, userID, keyID).Return(errors.ErrNotFound.WithMessage("api key not found"))
+
+		w := httptest.NewRecorder()
+		handler.RevokeAPIKey(w, newRequest(keyID.String(), userID))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -28,7 +28,7 @@ func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	// Сериализуем в буфер
 	if err := json.NewEncoder(buf).Encode(v); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = w.Write([]byte(`{"error":"failed to encode response"}`))
+		_, _ = w.Write([]byte(`{"error":{"code":"internal","message":"failed to encode response"}}`))
 		return
 	}
 
@@ -40,13 +40,8 @@ func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	_, _ = buf.WriteTo(w)
 }
 
-// writeError пишет ошибку в ответ
+// writeError пишет ошибку в ответ: {"error": {"code": "not_found", "message": "..."}}
 func writeError(w http.ResponseWriter, err error) {
 	appErr := errors.ToAppError(err)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(appErr.Code)
-
-	// Используем статический JSON для простых ошибок (избегаем аллокаций)
-	response := []byte(`{"error":"` + appErr.Message + `"}`)
-	_, _ = w.Write(response)
+	writeJSON(w, appErr.Code, appErr.ToResponse())
 }
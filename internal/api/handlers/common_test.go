@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// viewer describes who sends a request; a nil user is an anonymous request
+type viewer struct {
+	name   string
+	userID *uuid.UUID
+	role   domain.Role
+}
+
+// urlParams are the chi URL parameters of a routed request
+type urlParams map[string]string
+
+// newRouteRequest builds a request the way chi hands it to a handler: URL parameters in the
+// route context and, for a logged-in viewer, the user and role set by the auth middleware
+func newRouteRequest(method, target, body string, params urlParams, v viewer) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+
+	rctx := chi.NewRouteContext()
+	for key, value := range params {
+		rctx.URLParams.Add(key, value)
+	}
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	if v.userID != nil {
+		ctx = context.WithValue(ctx, middleware.UserIDKey, *v.userID)
+		ctx = context.WithValue(ctx, middleware.RoleKey, v.role)
+	}
+	return req.WithContext(ctx)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
@@ -57,6 +58,7 @@ type TournamentGameStatusRepository interface {
 	GetActiveGame(ctx context.Context, tournamentID uuid.UUID) (*domain.TournamentGame, error)
 	ResetGameRound(ctx context.Context, tournamentID, gameID uuid.UUID) error
 	DeactivateAllGames(ctx context.Context, tournamentID uuid.UUID) error
+	SetGameWindow(ctx context.Context, tournamentID, gameID uuid.UUID, start, end *time.Time) error
 }
 
 // GameRatingRepository интерфейс для сброса рейтингов
@@ -80,6 +82,9 @@ type GameHandler struct {
 	tournamentGameStatusRepo TournamentGameStatusRepository
 	ratingRepo               GameRatingRepository
 	matchResetRepo           GameMatchResetRepository
+	roundsRepo               GameRoundsRepository
+	roundProgressCache       GameRoundProgressCache
+	tournamentAccess         *TournamentAccess
 	log                      *logger.Logger
 }
 
@@ -123,6 +128,11 @@ func (h *GameHandler) SetRatingRepo(repo GameRatingRepository) {
 	h.ratingRepo = repo
 }
 
+// SetTournamentAccess включает проверку доступа к приватным турнирам на публичных маршрутах
+func (h *GameHandler) SetTournamentAccess(access *TournamentAccess) {
+	h.tournamentAccess = access
+}
+
 // SetMatchResetRepo устанавливает репозиторий для удаления матчей
 func (h *GameHandler) SetMatchResetRepo(repo GameMatchResetRepository) {
 	h.matchResetRepo = repo
@@ -285,6 +295,11 @@ func (h *GameHandler) GetTournamentGames(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := checkTournamentAccess(r, h.tournamentAccess, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	games, err := h.gameService.GetByTournamentID(r.Context(), tournamentID)
 	if err != nil {
 		h.log.LogError("Failed to get tournament games", err)
@@ -410,6 +425,11 @@ func (h *GameHandler) GetGameLeaderboard(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := checkTournamentAccess(r, h.tournamentAccess, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	// Проверяем наличие репозитория
 	if h.leaderboardRepo == nil {
 		writeError(w, errors.ErrInternal.WithMessage("leaderboard repository not configured"))
@@ -464,6 +484,11 @@ func (h *GameHandler) GetGameMatches(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := checkTournamentAccess(r, h.tournamentAccess, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	// Проверяем наличие репозитория
 	if h.matchRepo == nil {
 		writeError(w, errors.ErrInternal.WithMessage("match repository not configured"))
@@ -566,8 +591,12 @@ type TournamentGameWithDetails struct {
 	GameDisplayName  string    `json:"game_display_name"`
 	IsActive         bool      `json:"is_active"`
 	RoundCompleted   bool      `json:"round_completed"`
+	RoundActive      bool      `json:"round_active"`
 	RoundCompletedAt *string   `json:"round_completed_at,omitempty"`
 	CurrentRound     int       `json:"current_round"`
+	WindowStart      *string   `json:"window_start,omitempty"`
+	WindowEnd        *string   `json:"window_end,omitempty"`
+	WindowOpen       bool      `json:"window_open"`
 }
 
 // GetTournamentGamesWithStatus получает игры турнира с их статусом раундов
@@ -580,6 +609,11 @@ func (h *GameHandler) GetTournamentGamesWithStatus(w http.ResponseWriter, r *htt
 		return
 	}
 
+	if err := checkTournamentAccess(r, h.tournamentAccess, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	// Проверяем наличие репозитория
 	if h.tournamentGameStatusRepo == nil {
 		writeError(w, errors.ErrInternal.WithMessage("tournament game status repository not configured"))
@@ -597,6 +631,7 @@ func (h *GameHandler) GetTournamentGamesWithStatus(w http.ResponseWriter, r *htt
 	}
 
 	// Обогащаем данными об играх
+	now := time.Now()
 	result := make([]TournamentGameWithDetails, 0, len(tournamentGames))
 	for _, tg := range tournamentGames {
 		g, err := h.gameService.GetByID(r.Context(), tg.GameID)
@@ -614,12 +649,20 @@ func (h *GameHandler) GetTournamentGamesWithStatus(w http.ResponseWriter, r *htt
 			GameDisplayName: g.DisplayName,
 			IsActive:        tg.IsActive,
 			RoundCompleted:  tg.RoundCompleted,
+			RoundActive:     tg.RoundActive,
 			CurrentRound:    tg.CurrentRound,
+			WindowOpen:      tg.WindowOpen(now),
 		}
 		if tg.RoundCompletedAt != nil {
 			formatted := tg.RoundCompletedAt.Format("2006-01-02T15:04:05Z07:00")
 			item.RoundCompletedAt = &formatted
 		}
+		if tg.HasWindow() {
+			start := tg.WindowStart.Format("2006-01-02T15:04:05Z07:00")
+			end := tg.WindowEnd.Format("2006-01-02T15:04:05Z07:00")
+			item.WindowStart = &start
+			item.WindowEnd = &end
+		}
 		result = append(result, item)
 	}
 
@@ -667,6 +710,67 @@ func (h *GameHandler) MarkGameRoundCompleted(w http.ResponseWriter, r *http.Requ
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// SetGameWindowRequest запрос на установку окна времени игры (оба поля null - снять окно)
+type SetGameWindowRequest struct {
+	WindowStart *time.Time `json:"window_start"`
+	WindowEnd   *time.Time `json:"window_end"`
+}
+
+// SetGameWindow задаёт окно времени, в которое выполняются матчи игры турнира.
+// В начале окна раунд игры запускается автоматически
+// PUT /api/v1/tournaments/{id}/games/{gameId}/window
+func (h *GameHandler) SetGameWindow(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameId"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid game ID"))
+		return
+	}
+
+	if h.tournamentGameStatusRepo == nil {
+		writeError(w, errors.ErrInternal.WithMessage("tournament game status repository not configured"))
+		return
+	}
+
+	var req SetGameWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Info("Invalid request body", zap.Error(err))
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid request body"))
+		return
+	}
+
+	if (req.WindowStart == nil) != (req.WindowEnd == nil) {
+		writeError(w, errors.ErrValidation.WithMessage("window_start and window_end must be set together"))
+		return
+	}
+	if req.WindowStart != nil && !req.WindowEnd.After(*req.WindowStart) {
+		writeError(w, errors.ErrValidation.WithMessage("window_end must be after window_start"))
+		return
+	}
+
+	if err := h.tournamentGameStatusRepo.SetGameWindow(r.Context(), tournamentID, gameID, req.WindowStart, req.WindowEnd); err != nil {
+		h.log.LogError("Failed to set game window", err,
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("game_id", gameID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	h.log.Info("Game window set",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.String("game_id", gameID.String()),
+		zap.Bool("cleared", req.WindowStart == nil),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // SetActiveGameRequest запрос на установку активной игры
 type SetActiveGameRequest struct {
 	GameID uuid.UUID `json:"game_id"`
@@ -755,6 +859,11 @@ func (h *GameHandler) GetActiveGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := checkTournamentAccess(r, h.tournamentAccess, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	// Проверяем наличие репозитория
 	if h.tournamentGameStatusRepo == nil {
 		writeError(w, errors.ErrInternal.WithMessage("tournament game status repository not configured"))
@@ -793,6 +902,7 @@ func (h *GameHandler) GetActiveGame(w http.ResponseWriter, r *http.Request) {
 		GameDisplayName: g.DisplayName,
 		IsActive:        activeGame.IsActive,
 		RoundCompleted:  activeGame.RoundCompleted,
+		RoundActive:     activeGame.RoundActive,
 		CurrentRound:    activeGame.CurrentRound,
 	}
 	if activeGame.RoundCompletedAt != nil {
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GameRoundsRepository интерфейс для получения раундов турнира со сводкой по статусам матчей
+type GameRoundsRepository interface {
+	GetMatchesByRounds(ctx context.Context, tournamentID uuid.UUID) ([]*domain.MatchRound, error)
+}
+
+// GameRoundProgressCache интерфейс кэша прогресса раунда: Get возвращает nil при промахе
+type GameRoundProgressCache interface {
+	Get(ctx context.Context, tournamentID, gameID uuid.UUID) (*domain.GameRoundProgress, error)
+	Set(ctx context.Context, progress *domain.GameRoundProgress) error
+}
+
+// SetRoundsRepo устанавливает репозиторий раундов для прогресса игры
+func (h *GameHandler) SetRoundsRepo(repo GameRoundsRepository) {
+	h.roundsRepo = repo
+}
+
+// SetRoundProgressCache включает кэширование прогресса раунда (nil - без кэша)
+func (h *GameHandler) SetRoundProgressCache(cache GameRoundProgressCache) {
+	h.roundProgressCache = cache
+}
+
+// GetGameRoundProgress возвращает количество матчей по статусам в текущем раунде игры,
+// чтобы показывать полосу прогресса по каждой игре турнира
+// GET /api/v1/tournaments/{id}/games/{gameId}/progress
+func (h *GameHandler) GetGameRoundProgress(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameId"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid game ID"))
+		return
+	}
+
+	// Проверяем наличие репозитория
+	if h.roundsRepo == nil {
+		writeError(w, errors.ErrInternal.WithMessage("rounds repository not configured"))
+		return
+	}
+
+	// Турнир и игра проверяются до кэша: иначе кэш отдавал бы прогресс приватных турниров
+	// и отвечал на несуществующие ID
+	if err := h.checkTournamentGame(r, tournamentID, gameID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	// Зрители опрашивают прогресс часто: ошибка кэша не мешает ответить из БД
+	if h.roundProgressCache != nil {
+		cached, err := h.roundProgressCache.Get(ctx, tournamentID, gameID)
+		if err == nil && cached != nil {
+			writeJSON(w, http.StatusOK, cached)
+			return
+		}
+	}
+
+	// Получаем игру для её имени (game_type)
+	g, err := h.gameService.GetByID(ctx, gameID)
+	if err != nil {
+		h.log.LogError("Failed to get game", err)
+		writeError(w, err)
+		return
+	}
+
+	rounds, err := h.roundsRepo.GetMatchesByRounds(ctx, tournamentID)
+	if err != nil {
+		h.log.LogError("Failed to get rounds", err,
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("game_id", gameID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	progress := currentRoundProgress(rounds, g.Name)
+	progress.TournamentID = tournamentID
+	progress.GameID = gameID
+
+	if h.roundProgressCache != nil {
+		if err := h.roundProgressCache.Set(ctx, progress); err != nil {
+			h.log.LogError("Failed to cache round progress", err,
+				zap.String("tournament_id", tournamentID.String()),
+				zap.String("game_id", gameID.String()),
+			)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, progress)
+}
+
+// checkTournamentGame проверяет, что турнир существует и виден пользователю, а игра добавлена в него
+func (h *GameHandler) checkTournamentGame(r *http.Request, tournamentID, gameID uuid.UUID) error {
+	ctx := r.Context()
+
+	if h.tournamentAccess != nil {
+		if _, err := h.tournamentAccess.Authorize(ctx, tournamentID); err != nil {
+			return err
+		}
+	} else if h.tournamentRepo != nil {
+		if _, err := h.tournamentRepo.GetByID(ctx, tournamentID); err != nil {
+			return err
+		}
+	}
+
+	if h.tournamentGameStatusRepo == nil {
+		return errors.ErrInternal.WithMessage("tournament game status repository not configured")
+	}
+	games, err := h.tournamentGameStatusRepo.GetTournamentGames(ctx, tournamentID)
+	if err != nil {
+		h.log.LogError("Failed to get tournament games", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		return err
+	}
+	for _, g := range games {
+		if g.GameID == gameID {
+			return nil
+		}
+	}
+
+	return errors.ErrNotFound.WithMessage("game not found in tournament")
+}
+
+// currentRoundProgress собирает прогресс раунда игры с наибольшим номером
+func currentRoundProgress(rounds []*domain.MatchRound, gameType string) *domain.GameRoundProgress {
+	progress := &domain.GameRoundProgress{GameType: gameType}
+
+	var current *domain.MatchRound
+	for _, round := range rounds {
+		if round.GameType != gameType {
+			continue
+		}
+		if current == nil || round.RoundNumber > current.RoundNumber {
+			current = round
+		}
+	}
+	if current == nil {
+		return progress
+	}
+
+	progress.RoundNumber = current.RoundNumber
+	progress.TotalMatches = current.TotalMatches
+	progress.CompletedCount = current.CompletedCount
+	progress.PendingCount = current.PendingCount
+	progress.RunningCount = current.RunningCount
+	progress.FailedCount = current.FailedCount
+	if current.TotalMatches > 0 {
+		finished := float64(current.CompletedCount+current.FailedCount) / float64(current.TotalMatches)
+		progress.Percent = math.Round(finished*1000) / 10
+	}
+
+	return progress
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubGameLookup implements only GameService.GetByID
+type stubGameLookup struct {
+	GameService
+	game *domain.Game
+}
+
+func (s *stubGameLookup) GetByID(context.Context, uuid.UUID) (*domain.Game, error) {
+	return s.game, nil
+}
+
+type stubRoundsRepo struct {
+	rounds []*domain.MatchRound
+	calls  int
+}
+
+func (s *stubRoundsRepo) GetMatchesByRounds(context.Context, uuid.UUID) ([]*domain.MatchRound, error) {
+	s.calls++
+	return s.rounds, nil
+}
+
+// stubTournamentGames implements only TournamentGameStatusRepository.GetTournamentGames
+type stubTournamentGames struct {
+	TournamentGameStatusRepository
+	gameIDs []uuid.UUID
+}
+
+func (s *stubTournamentGames) GetTournamentGames(_ context.Context, tournamentID uuid.UUID) ([]*domain.TournamentGame, error) {
+	games := make([]*domain.TournamentGame, 0, len(s.gameIDs))
+	for _, id := range s.gameIDs {
+		games = append(games, &domain.TournamentGame{TournamentID: tournamentID, GameID: id})
+	}
+	return games, nil
+}
+
+type memoryRoundProgressCache struct {
+	entries map[uuid.UUID]*domain.GameRoundProgress
+}
+
+func (c *memoryRoundProgressCache) Get(_ context.Context, _, gameID uuid.UUID) (*domain.GameRoundProgress, error) {
+	return c.entries[gameID], nil
+}
+
+func (c *memoryRoundProgressCache) Set(_ context.Context, progress *domain.GameRoundProgress) error {
+	c.entries[progress.GameID] = progress
+	return nil
+}
+
+func newGameProgressRequest(tournamentID, gameID uuid.UUID) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments/"+tournamentID.String()+"/games/"+gameID.String()+"/progress", nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", tournamentID.String())
+	rctx.URLParams.Add("gameId", gameID.String())
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestGameHandler_GetGameRoundProgress(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+	game := &domain.Game{ID: uuid.New(), Name: "dilemma"}
+
+	rounds := &stubRoundsRepo{rounds: []*domain.MatchRound{
+		{RoundNumber: 3, GameType: "tug_of_war", TotalMatches: 10, CompletedCount: 10},
+		{RoundNumber: 2, GameType: "dilemma", TotalMatches: 8, CompletedCount: 3, PendingCount: 2, RunningCount: 1, FailedCount: 2},
+		{RoundNumber: 1, GameType: "dilemma", TotalMatches: 8, CompletedCount: 8},
+	}}
+	progressCache := &memoryRoundProgressCache{entries: map[uuid.UUID]*domain.GameRoundProgress{}}
+
+	handler := NewGameHandler(&stubGameLookup{game: game}, log)
+	handler.SetRoundsRepo(rounds)
+	handler.SetRoundProgressCache(progressCache)
+	handler.SetTournamentGameStatusRepo(&stubTournamentGames{gameIDs: []uuid.UUID{game.ID}})
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		handler.GetGameRoundProgress(w, newGameProgressRequest(tournamentID, game.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var progress domain.GameRoundProgress
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &progress))
+		assert.Equal(t, 2, progress.RoundNumber, "latest round of this game only")
+		assert.Equal(t, 8, progress.TotalMatches)
+		assert.Equal(t, 3, progress.CompletedCount)
+		assert.Equal(t, 2, progress.PendingCount)
+		assert.Equal(t, 1, progress.RunningCount)
+		assert.Equal(t, 2, progress.FailedCount)
+		assert.Equal(t, 62.5, progress.Percent)
+	}
+	assert.Equal(t, 1, rounds.calls, "second poll is served from cache")
+
+	t.Run("game without rounds", func(t *testing.T) {
+		newGame := &domain.Game{ID: uuid.New(), Name: "new_game"}
+		handler := NewGameHandler(&stubGameLookup{game: newGame}, log)
+		handler.SetRoundsRepo(rounds)
+		handler.SetTournamentGameStatusRepo(&stubTournamentGames{gameIDs: []uuid.UUID{newGame.ID}})
+
+		w := httptest.NewRecorder()
+		handler.GetGameRoundProgress(w, newGameProgressRequest(tournamentID, newGame.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var progress domain.GameRoundProgress
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &progress))
+		assert.Zero(t, progress.RoundNumber)
+		assert.Zero(t, progress.TotalMatches)
+		assert.Zero(t, progress.Percent)
+	})
+
+	t.Run("checks tournament and game before the cache", func(t *testing.T) {
+		privateID, unknownID := uuid.New(), uuid.New()
+		outsiderID := uuid.New()
+
+		service := new(MockTournamentService)
+		service.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID, Visibility: domain.VisibilityPublic}, nil)
+		service.On("GetByID", mock.Anything, privateID).Return(&domain.Tournament{ID: privateID, Visibility: domain.VisibilityPrivate}, nil)
+		service.On("GetByID", mock.Anything, unknownID).Return(nil, errors.ErrNotFound.WithMessage("tournament not found"))
+		membership := new(MockTournamentMembership)
+		membership.On("IsUserInAnyTeamInTournament", mock.Anything, privateID, outsiderID).Return(false, nil)
+		access := NewTournamentAccess(service, log)
+		access.SetMembership(membership)
+
+		// Every request would hit a cached entry if the checks ran after the cache
+		cached := &memoryRoundProgressCache{entries: map[uuid.UUID]*domain.GameRoundProgress{
+			game.ID: {TournamentID: privateID, GameID: game.ID, RoundNumber: 7},
+		}}
+		handler := NewGameHandler(&stubGameLookup{game: game}, log)
+		handler.SetRoundsRepo(&stubRoundsRepo{})
+		handler.SetRoundProgressCache(cached)
+		handler.SetTournamentGameStatusRepo(&stubTournamentGames{gameIDs: []uuid.UUID{game.ID}})
+		handler.SetTournamentAccess(access)
+
+		tests := []struct {
+			name         string
+			tournamentID uuid.UUID
+			gameID       uuid.UUID
+		}{
+			{"private tournament for a non-member", privateID, game.ID},
+			{"unknown tournament", unknownID, game.ID},
+			{"game outside the tournament", tournamentID, uuid.New()},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := newGameProgressRequest(tt.tournamentID, tt.gameID)
+				ctx := context.WithValue(req.Context(), middleware.UserIDKey, outsiderID)
+				ctx = context.WithValue(ctx, middleware.RoleKey, domain.RoleUser)
+
+				w := httptest.NewRecorder()
+				handler.GetGameRoundProgress(w, req.WithContext(ctx))
+
+				assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+			})
+		}
+	})
+}
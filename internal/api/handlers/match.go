@@ -2,15 +2,21 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/domain/tournament"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/queue"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -20,8 +26,11 @@ import (
 type MatchRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Match, error)
 	List(ctx context.Context, filter domain.MatchFilter) ([]*domain.Match, error)
+	CountWithFilter(ctx context.Context, filter domain.MatchFilter) (int, error)
+	ListWithCursor(ctx context.Context, filter domain.MatchFilter, pageReq *pagination.PageRequest) ([]*domain.Match, bool, error)
 	GetStatistics(ctx context.Context, tournamentID *uuid.UUID) (*db.MatchStatistics, error)
 	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Match, error)
+	UpdatePriority(ctx context.Context, id uuid.UUID, priority domain.MatchPriority) error
 }
 
 // MatchQueueManager интерфейс для работы с очередью матчей
@@ -29,6 +38,8 @@ type MatchQueueManager interface {
 	GetStats(ctx context.Context) (*queue.QueueStats, error)
 	Clear(ctx context.Context) error
 	PurgeInvalidMatches(ctx context.Context, validator func(matchID string) bool) (int64, error)
+	Reprioritize(ctx context.Context, matchID uuid.UUID, newPriority domain.MatchPriority) error
+	GetPeekByPriority(ctx context.Context, priority domain.MatchPriority, n int) ([]*domain.Match, error)
 }
 
 // MatchCache интерфейс для кэширования матчей
@@ -37,6 +48,7 @@ type MatchCache interface {
 	Set(ctx context.Context, matchID uuid.UUID, result *domain.MatchResult) error
 	GetMatch(ctx context.Context, matchID uuid.UUID) (*domain.Match, error)
 	SetMatch(ctx context.Context, match *domain.Match) error
+	Delete(ctx context.Context, matchID uuid.UUID) error
 }
 
 // MatchProgramLookup интерфейс для получения владельца программы
@@ -44,13 +56,44 @@ type MatchProgramLookup interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Program, error)
 }
 
+// TestMatchCreator интерфейс для создания тестовых матчей
+type TestMatchCreator interface {
+	CreateTestMatch(ctx context.Context, req *tournament.TestMatchRequest) (*domain.Match, error)
+}
+
+// TestMatchLimiter интерфейс для ограничения числа тестовых матчей пользователя
+type TestMatchLimiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+}
+
+// MatchResultAdjuster интерфейс ручной правки результатов матчей
+type MatchResultAdjuster interface {
+	AdjustMatchResult(ctx context.Context, matchID, adminID uuid.UUID, req *tournament.AdjustMatchResultRequest) (*domain.Match, error)
+	AnnulMatchResult(ctx context.Context, matchID, adminID uuid.UUID, req *tournament.AnnulMatchResultRequest) (*domain.Match, error)
+	GetMatchResultAdjustments(ctx context.Context, matchID uuid.UUID) ([]*domain.MatchResultAdjustment, error)
+}
+
+// HeadToHeadLookup интерфейс для получения личных встреч двух программ
+type HeadToHeadLookup interface {
+	GetHeadToHead(ctx context.Context, a, b uuid.UUID, tournamentID *uuid.UUID) (*domain.HeadToHeadRecord, error)
+}
+
 // MatchHandler обрабатывает запросы матчей
 type MatchHandler struct {
 	matchRepo     MatchRepository
 	matchCache    MatchCache
 	programLookup MatchProgramLookup
 	queueManager  MatchQueueManager
+	programInfo   ProgramInfoLookup
+	headToHead    HeadToHeadLookup
+	results       MatchResultAdjuster
+	live          MatchLiveFeed
+	access        *TournamentAccess
 	log           *logger.Logger
+
+	testMatches      TestMatchCreator
+	testMatchLimiter TestMatchLimiter
+	testMatchLimit   int
 }
 
 // NewMatchHandler создаёт новый match handler
@@ -83,6 +126,35 @@ func NewMatchHandlerFull(matchRepo MatchRepository, matchCache MatchCache, progr
 	}
 }
 
+// SetTestMatches включает тестовые матчи с лимитом limitPerHour на пользователя.
+// Без limiter или при limitPerHour <= 0 число тестовых матчей не ограничивается
+func (h *MatchHandler) SetTestMatches(creator TestMatchCreator, limiter TestMatchLimiter, limitPerHour int) {
+	h.testMatches = creator
+	h.testMatchLimiter = limiter
+	h.testMatchLimit = limitPerHour
+}
+
+// SetProgramInfo включает названия программ и команд в ответе GET /matches/{id}
+func (h *MatchHandler) SetProgramInfo(programInfo ProgramInfoLookup) {
+	h.programInfo = programInfo
+}
+
+// SetHeadToHead включает GET /matches/head-to-head
+func (h *MatchHandler) SetHeadToHead(headToHead HeadToHeadLookup) {
+	h.headToHead = headToHead
+}
+
+// SetTournamentAccess включает проверку доступа к приватным турнирам: их матчи видны
+// только тем, кому виден сам турнир
+func (h *MatchHandler) SetTournamentAccess(access *TournamentAccess) {
+	h.access = access
+}
+
+// SetResultAdjuster включает ручную правку и аннулирование результатов матчей
+func (h *MatchHandler) SetResultAdjuster(results MatchResultAdjuster) {
+	h.results = results
+}
+
 // filterMatchError фильтрует сообщение об ошибке матча в зависимости от прав пользователя
 // Если пользователь владеет программой, которая вызвала ошибку, или является админом - показываем полную ошибку
 // Иначе показываем "Программа оппонента завершилась с ошибкой"
@@ -144,8 +216,17 @@ func (h *MatchHandler) filterMatchesErrors(ctx context.Context, matches []*domai
 	return matches
 }
 
+// matchDetail матч с названиями программ и команд участников
+type matchDetail struct {
+	*domain.Match
+	Program1Name string  `json:"program1_name,omitempty"`
+	Team1Name    *string `json:"team1_name,omitempty"`
+	Program2Name string  `json:"program2_name,omitempty"`
+	Team2Name    *string `json:"team2_name,omitempty"`
+}
+
 // Get обрабатывает получение матча
-// GET /api/v1/matches/:id
+// GET /api/v1/matches/{id}
 func (h *MatchHandler) Get(w http.ResponseWriter, r *http.Request) {
 	// Извлекаем ID из URL
 	idStr := chi.URLParam(r, "id")
@@ -155,22 +236,18 @@ func (h *MatchHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Проверяем кэш результата
-	cachedResult, err := h.matchCache.Get(r.Context(), id)
-	if err == nil && cachedResult != nil {
-		h.log.Info("Match result from cache",
-			zap.String("match_id", id.String()),
-		)
-		writeJSON(w, http.StatusOK, cachedResult)
+	match, err := h.getMatch(r.Context(), id)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			h.log.LogError("Failed to get match", err,
+				zap.String("match_id", id.String()),
+			)
+		}
+		writeError(w, err)
 		return
 	}
 
-	// Получаем матч из БД
-	match, err := h.matchRepo.GetByID(r.Context(), id)
-	if err != nil {
-		h.log.LogError("Failed to get match", err,
-			zap.String("match_id", id.String()),
-		)
+	if err := h.authorizeMatch(r, match); err != nil {
 		writeError(w, err)
 		return
 	}
@@ -181,11 +258,69 @@ func (h *MatchHandler) Get(w http.ResponseWriter, r *http.Request) {
 	isAdmin := userRole == domain.RoleAdmin
 	match = h.filterMatchError(r.Context(), match, userID, isAdmin)
 
-	writeJSON(w, http.StatusOK, match)
+	writeJSON(w, http.StatusOK, h.withNames(r.Context(), match))
+}
+
+// authorizeMatch проверяет доступ к турниру матча: матч скрытого приватного турнира
+// не отличается от несуществующего
+func (h *MatchHandler) authorizeMatch(r *http.Request, match *domain.Match) error {
+	err := checkTournamentAccess(r, h.access, match.TournamentID)
+	if errors.IsNotFound(err) {
+		return errors.ErrNotFound.WithMessage("match not found")
+	}
+	return err
+}
+
+// getMatch возвращает матч из кэша или БД. Кэшируются только завершённые матчи:
+// под тем же ключом worker хранит результат, а у активных матчей меняется статус
+func (h *MatchHandler) getMatch(ctx context.Context, id uuid.UUID) (*domain.Match, error) {
+	cached, err := h.matchCache.GetMatch(ctx, id)
+	if err == nil && cached != nil && cached.ID == id && cached.Status == domain.MatchCompleted {
+		return cached, nil
+	}
+
+	match, err := h.matchRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if match.Status == domain.MatchCompleted {
+		if err := h.matchCache.SetMatch(ctx, match); err != nil {
+			h.log.LogError("Failed to cache match", err,
+				zap.String("match_id", id.String()),
+			)
+		}
+	}
+	return match, nil
+}
+
+// withNames дополняет матч названиями программ и команд. Без них матч возвращается как есть
+func (h *MatchHandler) withNames(ctx context.Context, match *domain.Match) *matchDetail {
+	detail := &matchDetail{Match: match}
+	if h.programInfo == nil {
+		return detail
+	}
+
+	infos, err := h.programInfo.GetInfoByIDs(ctx, []uuid.UUID{match.Program1ID, match.Program2ID})
+	if err != nil {
+		h.log.Warn("Failed to get program names for match", zap.Error(err),
+			zap.String("match_id", match.ID.String()),
+		)
+		return detail
+	}
+
+	if info, ok := infos[match.Program1ID]; ok {
+		detail.Program1Name, detail.Team1Name = info.ProgramName, info.TeamName
+	}
+	if info, ok := infos[match.Program2ID]; ok {
+		detail.Program2Name, detail.Team2Name = info.ProgramName, info.TeamName
+	}
+	return detail
 }
 
 // List обрабатывает получение списка матчей
-// GET /api/v1/matches
+// С include_total=true ответ limit/offset - объект {items, total, limit, offset}
+// GET /api/v1/matches?limit=&offset= или ?first=&after= / ?last=&before=
 func (h *MatchHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Получаем параметры фильтрации
 	filter := domain.MatchFilter{}
@@ -197,7 +332,19 @@ func (h *MatchHandler) List(w http.ResponseWriter, r *http.Request) {
 			writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
 			return
 		}
+		if err := checkTournamentAccess(r, h.access, id); err != nil {
+			writeError(w, err)
+			return
+		}
 		filter.TournamentID = &id
+	} else if h.access != nil {
+		// Без турнира в фильтре скрываем матчи приватных турниров, недоступных пользователю
+		if role, _ := r.Context().Value(middleware.RoleKey).(domain.Role); role != domain.RoleAdmin {
+			filter.HidePrivate = true
+			if userID, ok := middleware.GetUserID(r.Context()); ok {
+				filter.ViewerID = &userID
+			}
+		}
 	}
 
 	// Program ID filter
@@ -218,6 +365,15 @@ func (h *MatchHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Game type filter
 	filter.GameType = r.URL.Query().Get("game_type")
 
+	// Keyset пагинация (first/after или last/before) вместо offset
+	if pageReq, ok, err := parseKeysetPageRequest(r); err != nil {
+		writeError(w, err)
+		return
+	} else if ok {
+		h.listWithCursor(w, r, filter, pageReq)
+		return
+	}
+
 	// Pagination
 	limit := 50
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
@@ -249,9 +405,103 @@ func (h *MatchHandler) List(w http.ResponseWriter, r *http.Request) {
 	isAdmin := userRole == domain.RoleAdmin
 	matches = h.filterMatchesErrors(r.Context(), matches, userID, isAdmin)
 
+	// Общее число матчей запрашивается явно: по умолчанию ответ остаётся массивом
+	if includeTotal(r) {
+		total, err := h.matchRepo.CountWithFilter(r.Context(), filter)
+		if err != nil {
+			h.log.LogError("Failed to count matches", err)
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, pagination.NewOffsetPage(matches, total, limit, offset))
+		return
+	}
+
 	writeJSON(w, http.StatusOK, matches)
 }
 
+// listWithCursor отдаёт страницу матчей с курсорами (round_number, id)
+func (h *MatchHandler) listWithCursor(w http.ResponseWriter, r *http.Request, filter domain.MatchFilter, pageReq *pagination.PageRequest) {
+	matches, hasMore, err := h.matchRepo.ListWithCursor(r.Context(), filter, pageReq)
+	if err != nil {
+		h.log.LogError("Failed to get matches page", err)
+		writeError(w, err)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	userRole, _ := r.Context().Value(middleware.RoleKey).(domain.Role)
+	matches = h.filterMatchesErrors(r.Context(), matches, userID, userRole == domain.RoleAdmin)
+
+	writeJSON(w, http.StatusOK, pagination.NewKeysetPage(matches, db.MatchKeysetCursor, pageReq, hasMore))
+}
+
+// includeTotal проверяет, запрошено ли общее число элементов списка (include_total=true)
+func includeTotal(r *http.Request) bool {
+	return r.URL.Query().Get("include_total") == "true"
+}
+
+// parseKeysetPageRequest читает параметры first/after/last/before с keyset курсором (round_number, id).
+// ok = false, если ни один из них не передан
+func parseKeysetPageRequest(r *http.Request) (*pagination.PageRequest, bool, error) {
+	pageReq, ok, err := parsePageRequest(r)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if _, err := pageReq.GetKeysetCursor(); err != nil {
+		return nil, false, errors.ErrInvalidInput.WithMessage("invalid cursor")
+	}
+
+	return pageReq, true, nil
+}
+
+// parsePageRequest читает и валидирует параметры first/after/last/before без разбора курсора.
+// ok = false, если ни один из них не передан
+func parsePageRequest(r *http.Request) (*pagination.PageRequest, bool, error) {
+	query := r.URL.Query()
+	pageReq := &pagination.PageRequest{}
+	ok := false
+
+	for _, param := range []struct {
+		name string
+		dst  **int
+	}{{"first", &pageReq.First}, {"last", &pageReq.Last}} {
+		if value := query.Get(param.name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, false, errors.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s", param.name))
+			}
+			*param.dst = &n
+			ok = true
+		}
+	}
+
+	if after := query.Get("after"); after != "" {
+		pageReq.After = &after
+		ok = true
+	}
+	if before := query.Get("before"); before != "" {
+		pageReq.Before = &before
+		ok = true
+	}
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	// before без last означает страницу назад размера по умолчанию
+	if pageReq.Before != nil && pageReq.Last == nil && pageReq.First == nil {
+		last := pageReq.GetLimit()
+		pageReq.Last = &last
+	}
+
+	if err := pageReq.Validate(); err != nil {
+		return nil, false, errors.ErrInvalidInput.WithMessage(err.Error())
+	}
+
+	return pageReq, true, nil
+}
+
 // GetStatistics обрабатывает получение статистики матчей
 // GET /api/v1/matches/statistics
 func (h *MatchHandler) GetStatistics(w http.ResponseWriter, r *http.Request) {
@@ -263,6 +513,10 @@ func (h *MatchHandler) GetStatistics(w http.ResponseWriter, r *http.Request) {
 			writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
 			return
 		}
+		if err := checkTournamentAccess(r, h.access, id); err != nil {
+			writeError(w, err)
+			return
+		}
 		tournamentID = &id
 	}
 
@@ -277,6 +531,62 @@ func (h *MatchHandler) GetStatistics(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, stats)
 }
 
+// HeadToHead возвращает счёт личных встреч программ a и b (со стороны a) и их последние матчи
+// GET /api/v1/matches/head-to-head?a=<id>&b=<id>&tournament=<id>
+func (h *MatchHandler) HeadToHead(w http.ResponseWriter, r *http.Request) {
+	if h.headToHead == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("head-to-head is not available"))
+		return
+	}
+
+	a, err := uuid.Parse(r.URL.Query().Get("a"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid program a"))
+		return
+	}
+	b, err := uuid.Parse(r.URL.Query().Get("b"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid program b"))
+		return
+	}
+	if a == b {
+		writeError(w, errors.ErrInvalidInput.WithMessage("programs a and b must differ"))
+		return
+	}
+
+	// Турнир необязателен: без него учитываются все турниры
+	var tournamentID *uuid.UUID
+	if tournamentIDStr := r.URL.Query().Get("tournament"); tournamentIDStr != "" {
+		id, err := uuid.Parse(tournamentIDStr)
+		if err != nil {
+			writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+			return
+		}
+		if err := checkTournamentAccess(r, h.access, id); err != nil {
+			writeError(w, err)
+			return
+		}
+		tournamentID = &id
+	}
+
+	record, err := h.headToHead.GetHeadToHead(r.Context(), a, b, tournamentID)
+	if err != nil {
+		h.log.LogError("Failed to get head-to-head record", err,
+			zap.String("program_a", a.String()),
+			zap.String("program_b", b.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	// Фильтруем сообщения об ошибках в зависимости от прав пользователя
+	userID, _ := r.Context().Value(middleware.UserIDKey).(uuid.UUID)
+	userRole, _ := r.Context().Value(middleware.RoleKey).(domain.Role)
+	record.Matches = h.filterMatchesErrors(r.Context(), record.Matches, userID, userRole == domain.RoleAdmin)
+
+	writeJSON(w, http.StatusOK, record)
+}
+
 // GetQueueStats возвращает статистику очереди матчей (только для админов)
 // GET /api/v1/matches/queue/stats
 func (h *MatchHandler) GetQueueStats(w http.ResponseWriter, r *http.Request) {
@@ -295,6 +605,79 @@ func (h *MatchHandler) GetQueueStats(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, stats)
 }
 
+// defaultPeekSize число матчей, возвращаемых PeekQueue без параметра n
+const defaultPeekSize = 10
+
+// QueuedMatch матч из очереди и его текущий статус в БД
+type QueuedMatch struct {
+	*domain.Match
+	// CurrentStatus статус матча в БД; отсутствует, если матча в БД уже нет
+	CurrentStatus *domain.MatchStatus `json:"current_status,omitempty"`
+}
+
+// PeekQueue показывает ближайшие матчи очереди приоритета, не извлекая их (только для админов)
+// GET /api/v1/admin/queue/peek?priority=high&n=10
+func (h *MatchHandler) PeekQueue(w http.ResponseWriter, r *http.Request) {
+	if h.queueManager == nil {
+		writeError(w, errors.ErrInternal.WithMessage("queue manager not configured"))
+		return
+	}
+
+	priority := domain.MatchPriority(r.URL.Query().Get("priority"))
+	switch priority {
+	case domain.PriorityHigh, domain.PriorityMedium, domain.PriorityLow:
+	default:
+		writeError(w, errors.ErrValidation.WithMessage("priority must be one of: high, medium, low"))
+		return
+	}
+
+	n := defaultPeekSize
+	if nStr := r.URL.Query().Get("n"); nStr != "" {
+		parsed, err := strconv.Atoi(nStr)
+		if err != nil || parsed < 1 {
+			writeError(w, errors.ErrInvalidInput.WithMessage("n must be a positive integer"))
+			return
+		}
+		n = parsed
+	}
+
+	matches, err := h.queueManager.GetPeekByPriority(r.Context(), priority, n)
+	if err != nil {
+		h.log.LogError("Failed to peek queue", err, zap.String("priority", string(priority)))
+		writeError(w, err)
+		return
+	}
+
+	// Payload в очереди - снимок на момент постановки, актуальный статус берём из БД
+	ids := make([]uuid.UUID, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+	current, err := h.matchRepo.GetByIDs(r.Context(), ids)
+	if err != nil {
+		h.log.LogError("Failed to get queued matches", err)
+		writeError(w, err)
+		return
+	}
+	statuses := make(map[uuid.UUID]domain.MatchStatus, len(current))
+	for _, m := range current {
+		statuses[m.ID] = m.Status
+	}
+
+	queued := make([]QueuedMatch, len(matches))
+	for i, m := range matches {
+		queued[i] = QueuedMatch{Match: m}
+		if status, ok := statuses[m.ID]; ok {
+			queued[i].CurrentStatus = &status
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"priority": priority,
+		"matches":  queued,
+	})
+}
+
 // ClearQueue очищает все очереди матчей (только для админов)
 // POST /api/v1/matches/queue/clear
 func (h *MatchHandler) ClearQueue(w http.ResponseWriter, r *http.Request) {
@@ -351,3 +734,219 @@ func (h *MatchHandler) PurgeInvalidMatches(w http.ResponseWriter, r *http.Reques
 		"purged_count": purged,
 	})
 }
+
+// ReprioritizeRequest запрос на изменение приоритета матча
+type ReprioritizeRequest struct {
+	Priority domain.MatchPriority `json:"priority"`
+}
+
+// Reprioritize меняет приоритет ожидающего матча (только для админов)
+// POST /api/v1/matches/:id/priority
+func (h *MatchHandler) Reprioritize(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid match ID"))
+		return
+	}
+
+	var req ReprioritizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid request body"))
+		return
+	}
+
+	switch req.Priority {
+	case domain.PriorityHigh, domain.PriorityMedium, domain.PriorityLow:
+	default:
+		writeError(w, errors.ErrValidation.WithMessage("priority must be one of: high, medium, low"))
+		return
+	}
+
+	// Сначала БД: восстановление очереди берёт приоритет оттуда
+	if err := h.matchRepo.UpdatePriority(r.Context(), id, req.Priority); err != nil {
+		h.log.LogError("Failed to update match priority", err,
+			zap.String("match_id", id.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	if h.queueManager != nil {
+		if err := h.queueManager.Reprioritize(r.Context(), id, req.Priority); err != nil {
+			if !stderrors.Is(err, queue.ErrMatchNotQueued) {
+				h.log.LogError("Failed to reprioritize queued match", err,
+					zap.String("match_id", id.String()),
+				)
+				writeError(w, err)
+				return
+			}
+			// Матч ещё не в очереди: его поставят с новым приоритетом из БД
+			h.log.Warn("Match not found in queue, priority updated in DB only",
+				zap.String("match_id", id.String()),
+			)
+		}
+	}
+
+	h.log.Info("Match reprioritized by admin",
+		zap.String("match_id", id.String()),
+		zap.String("priority", string(req.Priority)),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"match_id": id,
+		"priority": req.Priority,
+	})
+}
+
+// AdjustResult заменяет результат матча без перезапуска (только для админов)
+// PUT /api/v1/matches/:id/result
+func (h *MatchHandler) AdjustResult(w http.ResponseWriter, r *http.Request) {
+	id, adminID, ok := h.resultAdjustmentTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req tournament.AdjustMatchResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid request body"))
+		return
+	}
+
+	match, err := h.results.AdjustMatchResult(r.Context(), id, adminID, &req)
+	if err != nil {
+		h.log.LogError("Failed to adjust match result", err,
+			zap.String("match_id", id.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	h.invalidateMatch(r.Context(), id)
+	writeJSON(w, http.StatusOK, match)
+}
+
+// AnnulResult сбрасывает результат матча в pending, при requeue ставит матч в очередь (только для админов)
+// DELETE /api/v1/matches/:id/result
+func (h *MatchHandler) AnnulResult(w http.ResponseWriter, r *http.Request) {
+	id, adminID, ok := h.resultAdjustmentTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req tournament.AnnulMatchResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid request body"))
+		return
+	}
+
+	match, err := h.results.AnnulMatchResult(r.Context(), id, adminID, &req)
+	if err != nil {
+		h.log.LogError("Failed to annul match result", err,
+			zap.String("match_id", id.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	h.invalidateMatch(r.Context(), id)
+	writeJSON(w, http.StatusOK, match)
+}
+
+// ResultAdjustments возвращает историю ручных правок результата матча (только для админов)
+// GET /api/v1/matches/:id/result/adjustments
+func (h *MatchHandler) ResultAdjustments(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid match ID"))
+		return
+	}
+
+	if h.results == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("match result adjustments are not available"))
+		return
+	}
+
+	adjustments, err := h.results.GetMatchResultAdjustments(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, adjustments)
+}
+
+// resultAdjustmentTarget разбирает ID матча и администратора для правки результата.
+// При ошибке ответ уже записан
+func (h *MatchHandler) resultAdjustmentTarget(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	adminID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid match ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if h.results == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("match result adjustments are not available"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, adminID, true
+}
+
+// invalidateMatch удаляет матч из кэша: GET /matches/{id} отдаёт завершённые матчи из кэша
+func (h *MatchHandler) invalidateMatch(ctx context.Context, id uuid.UUID) {
+	if err := h.matchCache.Delete(ctx, id); err != nil {
+		h.log.LogError("Failed to invalidate match cache", err,
+			zap.String("match_id", id.String()),
+		)
+	}
+}
+
+// CreateTestMatch запускает матч двух программ пользователя вне очереди турнира.
+// Результат не влияет на рейтинги; статус и лог матча доступны по возвращённому ID
+// POST /api/v1/matches/test
+func (h *MatchHandler) CreateTestMatch(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if h.testMatches == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("test matches are not available"))
+		return
+	}
+
+	var req tournament.TestMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid request body"))
+		return
+	}
+	req.UserID = userID
+
+	if h.testMatchLimiter != nil && h.testMatchLimit > 0 {
+		allowed, err := h.testMatchLimiter.Allow(r.Context(), fmt.Sprintf("ratelimit:test_match:%s", userID), h.testMatchLimit, time.Hour)
+		if err != nil {
+			// Как и общий rate limiting, при недоступности Redis пропускаем запрос
+			h.log.LogError("Test match rate limit check failed", err, zap.String("user_id", userID.String()))
+		} else if !allowed {
+			writeError(w, errors.ErrRateLimitExceeded.WithMessage(
+				fmt.Sprintf("test match limit exceeded: %d per hour", h.testMatchLimit)))
+			return
+		}
+	}
+
+	match, err := h.testMatches.CreateTestMatch(r.Context(), &req)
+	if err != nil {
+		h.log.LogError("Failed to create test match", err, zap.String("user_id", userID.String()))
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, match)
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// liveHeartbeatInterval период комментариев-пингов, которые не дают прокси закрыть поток
+	liveHeartbeatInterval = 15 * time.Second
+	// liveBufferSize сколько событий ждут отправки медленному зрителю, прежде чем поток закрывается
+	liveBufferSize = 256
+)
+
+// MatchLiveFeed трансляция идущих матчей
+type MatchLiveFeed interface {
+	Subscribe(ctx context.Context, matchID uuid.UUID, buffer int) (<-chan *domain.MatchEvent, func(), error)
+	Log(ctx context.Context, matchID uuid.UUID) ([]*domain.MatchEvent, error)
+}
+
+// SetLiveFeed включает GET /matches/{id}/live
+func (h *MatchHandler) SetLiveFeed(live MatchLiveFeed) {
+	h.live = live
+}
+
+// Live транслирует матч через Server-Sent Events: сначала журнал уже сыгранного,
+// затем события по мере выполнения. После сохранения результата отправляется событие
+// result с матчем и поток закрывается. Для завершённого матча повторяется его журнал.
+// Смотреть матч могут админы и владельцы его программ
+// GET /api/v1/matches/{id}/live
+func (h *MatchHandler) Live(w http.ResponseWriter, r *http.Request) {
+	if h.live == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("live matches are not available"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid match ID"))
+		return
+	}
+
+	ctx := r.Context()
+	userID, ok := ctx.Value(middleware.UserIDKey).(uuid.UUID)
+	if !ok {
+		writeError(w, errors.ErrUnauthorized)
+		return
+	}
+	userRole, _ := ctx.Value(middleware.RoleKey).(domain.Role)
+	isAdmin := userRole == domain.RoleAdmin
+
+	match, err := h.matchRepo.GetByID(ctx, id)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			h.log.LogError("Failed to get match", err, zap.String("match_id", id.String()))
+		}
+		writeError(w, err)
+		return
+	}
+
+	if err := h.authorizeMatch(r, match); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if !isAdmin && !h.ownsMatchProgram(ctx, match, userID) {
+		writeError(w, errors.ErrForbidden.WithMessage("only participants can watch this match"))
+		return
+	}
+
+	// Подписка оформляется до чтения журнала: события между ними придут в обоих
+	// и отбрасываются по номеру
+	finished := match.Status == domain.MatchCompleted || match.Status == domain.MatchFailed
+	var events <-chan *domain.MatchEvent
+	if !finished {
+		var unsubscribe func()
+		events, unsubscribe, err = h.live.Subscribe(ctx, id, liveBufferSize)
+		if err != nil {
+			h.log.LogError("Failed to subscribe to match events", err, zap.String("match_id", id.String()))
+			writeError(w, errors.ErrServiceUnavailable.WithMessage("live matches are not available"))
+			return
+		}
+		defer unsubscribe()
+	}
+
+	replay, err := h.live.Log(ctx, id)
+	if err != nil {
+		h.log.LogError("Failed to read match log", err, zap.String("match_id", id.String()))
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("live matches are not available"))
+		return
+	}
+
+	// Поток живёт дольше WriteTimeout сервера
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// После переподключения EventSource присылает номер последнего полученного события
+	var lastSeq int64
+	if value := r.Header.Get("Last-Event-ID"); value != "" {
+		lastSeq, _ = strconv.ParseInt(value, 10, 64)
+	}
+
+	stream := &liveStream{w: w, rc: rc}
+	send := func(event *domain.MatchEvent) (ended bool) {
+		if event.Seq <= lastSeq {
+			return false
+		}
+		lastSeq = event.Seq
+		stream.event(strconv.FormatInt(event.Seq, 10), string(event.Type), event)
+		return event.Type == domain.MatchEventEnd
+	}
+
+	for _, event := range replay {
+		if send(event) {
+			finished = true
+		}
+	}
+	if finished {
+		h.sendLiveResult(ctx, stream, id, userID, isAdmin)
+		return
+	}
+	if err := stream.flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(liveHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			stream.comment("ping")
+		case event, ok := <-events:
+			// Подписка закрыта: зритель не успевал читать или Redis недоступен.
+			// EventSource переподключится и получит пропущенное из журнала
+			if !ok {
+				return
+			}
+			if send(event) {
+				h.sendLiveResult(ctx, stream, id, userID, isAdmin)
+				return
+			}
+		}
+		if err := stream.flush(); err != nil {
+			return
+		}
+	}
+}
+
+// sendLiveResult отправляет итоговый матч событием result
+func (h *MatchHandler) sendLiveResult(ctx context.Context, stream *liveStream, id, userID uuid.UUID, isAdmin bool) {
+	match, err := h.matchRepo.GetByID(ctx, id)
+	if err != nil {
+		h.log.LogError("Failed to get match", err, zap.String("match_id", id.String()))
+		return
+	}
+	match = h.filterMatchError(ctx, match, userID, isAdmin)
+	stream.event("", "result", h.withNames(ctx, match))
+	_ = stream.flush()
+}
+
+// ownsMatchProgram проверяет, что пользователь владеет одной из программ матча
+func (h *MatchHandler) ownsMatchProgram(ctx context.Context, match *domain.Match, userID uuid.UUID) bool {
+	if h.programLookup == nil {
+		return false
+	}
+	for _, programID := range []uuid.UUID{match.Program1ID, match.Program2ID} {
+		program, err := h.programLookup.GetByID(ctx, programID)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				h.log.Warn("Failed to get program for live access check", zap.Error(err))
+			}
+			continue
+		}
+		if program.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// liveStream пишет события в формате Server-Sent Events
+type liveStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s *liveStream) event(id, name string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(s.w, "id: %s\n", id)
+	}
+	fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, payload)
+}
+
+func (s *liveStream) comment(text string) {
+	fmt.Fprintf(s.w, ": %s\n\n", text)
+}
+
+func (s *liveStream) flush() error {
+	return s.rc.Flush()
+}
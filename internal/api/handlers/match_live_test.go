@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLiveFeed serves a fixed log and a channel of live events
+type fakeLiveFeed struct {
+	log        []*domain.MatchEvent
+	events     chan *domain.MatchEvent
+	subscribed bool
+}
+
+func (f *fakeLiveFeed) Subscribe(_ context.Context, _ uuid.UUID, _ int) (<-chan *domain.MatchEvent, func(), error) {
+	f.subscribed = true
+	return f.events, func() {}, nil
+}
+
+func (f *fakeLiveFeed) Log(_ context.Context, _ uuid.UUID) ([]*domain.MatchEvent, error) {
+	return f.log, nil
+}
+
+func TestMatchHandler_Live(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	ownerID := uuid.New()
+	program1ID, program2ID := uuid.New(), uuid.New()
+
+	newMatch := func(status domain.MatchStatus) *domain.Match {
+		return &domain.Match{ID: uuid.New(), Program1ID: program1ID, Program2ID: program2ID, Status: status}
+	}
+
+	newHandler := func(match *domain.Match, live *fakeLiveFeed) *MatchHandler {
+		repo := new(MockMatchRepository)
+		repo.On("GetByID", mock.Anything, match.ID).Return(match, nil)
+		programs := new(MockProgramRepository)
+		programs.On("GetByID", mock.Anything, program1ID).Return(&domain.Program{ID: program1ID, UserID: uuid.New()}, nil)
+		programs.On("GetByID", mock.Anything, program2ID).Return(&domain.Program{ID: program2ID, UserID: ownerID}, nil)
+
+		handler := NewMatchHandlerWithProgramLookup(repo, new(MockMatchCache), programs, log)
+		handler.SetLiveFeed(live)
+		return handler
+	}
+
+	newRequest := func(matchID uuid.UUID, userID uuid.UUID, role domain.Role) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+matchID.String()+"/live", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", matchID.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		if userID != uuid.Nil {
+			ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+			ctx = context.WithValue(ctx, middleware.RoleKey, role)
+		}
+		return req.WithContext(ctx)
+	}
+
+	t.Run("finished match is replayed from the log", func(t *testing.T) {
+		match := newMatch(domain.MatchCompleted)
+		live := &fakeLiveFeed{log: []*domain.MatchEvent{
+			{Seq: 1, Type: domain.MatchEventStart},
+			{Seq: 2, Type: domain.MatchEventOutput, Line: "1: C D"},
+			{Seq: 3, Type: domain.MatchEventEnd},
+		}}
+		handler := newHandler(match, live)
+
+		w := httptest.NewRecorder()
+		handler.Live(w, newRequest(match.ID, ownerID, domain.RoleUser))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.False(t, live.subscribed, "finished match must not subscribe")
+
+		body := w.Body.String()
+		assert.Contains(t, body, "id: 2\nevent: output\ndata: ")
+		assert.Contains(t, body, `"line":"1: C D"`)
+		assert.Contains(t, body, "event: result\n")
+		assert.Less(t, strings.Index(body, "event: end"), strings.Index(body, "event: result"))
+	})
+
+	t.Run("running match streams live events after the log", func(t *testing.T) {
+		match := newMatch(domain.MatchRunning)
+		live := &fakeLiveFeed{
+			log: []*domain.MatchEvent{
+				{Seq: 1, Type: domain.MatchEventStart},
+				{Seq: 2, Type: domain.MatchEventOutput, Line: "1: C D"},
+			},
+			events: make(chan *domain.MatchEvent, 3),
+		}
+		// Seq 2 was published between subscribing and reading the log
+		live.events <- &domain.MatchEvent{Seq: 2, Type: domain.MatchEventOutput, Line: "1: C D"}
+		live.events <- &domain.MatchEvent{Seq: 3, Type: domain.MatchEventOutput, Line: "2: D D"}
+		live.events <- &domain.MatchEvent{Seq: 4, Type: domain.MatchEventEnd}
+		handler := newHandler(match, live)
+
+		w := httptest.NewRecorder()
+		handler.Live(w, newRequest(match.ID, uuid.New(), domain.RoleAdmin))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Equal(t, 1, strings.Count(body, `"line":"1: C D"`), "duplicate event must be skipped")
+		assert.Contains(t, body, `"line":"2: D D"`)
+		assert.Contains(t, body, "event: result\n")
+	})
+
+	t.Run("reconnect resumes after Last-Event-ID", func(t *testing.T) {
+		match := newMatch(domain.MatchCompleted)
+		live := &fakeLiveFeed{log: []*domain.MatchEvent{
+			{Seq: 1, Type: domain.MatchEventStart},
+			{Seq: 2, Type: domain.MatchEventOutput, Line: "1: C D"},
+			{Seq: 3, Type: domain.MatchEventEnd},
+		}}
+		handler := newHandler(match, live)
+
+		req := newRequest(match.ID, ownerID, domain.RoleUser)
+		req.Header.Set("Last-Event-ID", "2")
+		w := httptest.NewRecorder()
+		handler.Live(w, req)
+
+		body := w.Body.String()
+		assert.NotContains(t, body, "event: output")
+		assert.Contains(t, body, "id: 3\nevent: end")
+	})
+
+	t.Run("access", func(t *testing.T) {
+		match := newMatch(domain.MatchRunning)
+
+		w := httptest.NewRecorder()
+		newHandler(match, &fakeLiveFeed{}).Live(w, newRequest(match.ID, uuid.New(), domain.RoleUser))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = httptest.NewRecorder()
+		newHandler(match, &fakeLiveFeed{}).Live(w, newRequest(match.ID, uuid.Nil, ""))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = httptest.NewRecorder()
+		NewMatchHandler(new(MockMatchRepository), new(MockMatchCache), log).Live(w, newRequest(match.ID, ownerID, domain.RoleUser))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
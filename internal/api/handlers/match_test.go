@@ -5,12 +5,18 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/domain/tournament"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/queue"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -39,6 +45,19 @@ func (m *MockMatchRepository) List(ctx context.Context, filter domain.MatchFilte
 	return args.Get(0).([]*domain.Match), args.Error(1)
 }
 
+func (m *MockMatchRepository) CountWithFilter(ctx context.Context, filter domain.MatchFilter) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMatchRepository) ListWithCursor(ctx context.Context, filter domain.MatchFilter, pageReq *pagination.PageRequest) ([]*domain.Match, bool, error) {
+	args := m.Called(ctx, filter, pageReq)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Match), args.Bool(1), args.Error(2)
+}
+
 func (m *MockMatchRepository) GetStatistics(ctx context.Context, tournamentID *uuid.UUID) (*db.MatchStatistics, error) {
 	args := m.Called(ctx, tournamentID)
 	if args.Get(0) == nil {
@@ -55,6 +74,47 @@ func (m *MockMatchRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]
 	return args.Get(0).([]*domain.Match), args.Error(1)
 }
 
+func (m *MockMatchRepository) UpdatePriority(ctx context.Context, id uuid.UUID, priority domain.MatchPriority) error {
+	args := m.Called(ctx, id, priority)
+	return args.Error(0)
+}
+
+// MockMatchQueueManager mocks the match queue manager
+type MockMatchQueueManager struct {
+	mock.Mock
+}
+
+func (m *MockMatchQueueManager) GetStats(ctx context.Context) (*queue.QueueStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*queue.QueueStats), args.Error(1)
+}
+
+func (m *MockMatchQueueManager) Clear(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockMatchQueueManager) PurgeInvalidMatches(ctx context.Context, validator func(matchID string) bool) (int64, error) {
+	args := m.Called(ctx, validator)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMatchQueueManager) Reprioritize(ctx context.Context, matchID uuid.UUID, newPriority domain.MatchPriority) error {
+	args := m.Called(ctx, matchID, newPriority)
+	return args.Error(0)
+}
+
+func (m *MockMatchQueueManager) GetPeekByPriority(ctx context.Context, priority domain.MatchPriority, n int) ([]*domain.Match, error) {
+	args := m.Called(ctx, priority, n)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Match), args.Error(1)
+}
+
 // MockMatchCache mocks the match cache
 type MockMatchCache struct {
 	mock.Mock
@@ -86,46 +146,76 @@ func (m *MockMatchCache) SetMatch(ctx context.Context, match *domain.Match) erro
 	return args.Error(0)
 }
 
+func (m *MockMatchCache) Delete(ctx context.Context, matchID uuid.UUID) error {
+	args := m.Called(ctx, matchID)
+	return args.Error(0)
+}
+
+func newMatchRequest(matchID string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+matchID, nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", matchID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
 func TestMatchHandler_Get(t *testing.T) {
 	log, _ := logger.New("error", "json")
 
-	t.Run("successfully get match from cache", func(t *testing.T) {
+	t.Run("successfully get completed match from cache", func(t *testing.T) {
 		mockRepo := new(MockMatchRepository)
 		mockCache := new(MockMatchCache)
 		handler := NewMatchHandler(mockRepo, mockCache, log)
 
 		matchID := uuid.New()
-		cachedResult := &domain.MatchResult{
-			MatchID: matchID,
-			Score1:  2,
-			Score2:  1,
-			Winner:  1,
+		score1, score2, winner := 2, 1, 1
+		cachedMatch := &domain.Match{
+			ID:     matchID,
+			Status: domain.MatchCompleted,
+			Score1: &score1,
+			Score2: &score2,
+			Winner: &winner,
 		}
 
-		mockCache.On("Get", mock.Anything, matchID).Return(cachedResult, nil)
-
-		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+matchID.String(), nil)
-
-		rctx := chi.NewRouteContext()
-		rctx.URLParams.Add("id", matchID.String())
-		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		mockCache.On("GetMatch", mock.Anything, matchID).Return(cachedMatch, nil)
 
 		w := httptest.NewRecorder()
-
-		handler.Get(w, req)
+		handler.Get(w, newMatchRequest(matchID.String()))
 
 		assert.Equal(t, http.StatusOK, w.Code)
 
-		var response domain.MatchResult
+		var response domain.Match
 		err := json.NewDecoder(w.Body).Decode(&response)
 		require.NoError(t, err)
-		assert.Equal(t, cachedResult.MatchID, response.MatchID)
+		assert.Equal(t, matchID, response.ID)
+		assert.Equal(t, 2, *response.Score1)
 
 		mockCache.AssertExpectations(t)
 		// Repository should not be called if cache hit
 		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
 	})
 
+	t.Run("cached result without match fields is ignored", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		mockCache := new(MockMatchCache)
+		handler := NewMatchHandler(mockRepo, mockCache, log)
+
+		matchID := uuid.New()
+		dbMatch := &domain.Match{ID: matchID, GameType: "chess", Status: domain.MatchCompleted}
+
+		// The worker caches a MatchResult under the same key: it decodes without an ID
+		mockCache.On("GetMatch", mock.Anything, matchID).Return(&domain.Match{}, nil)
+		mockRepo.On("GetByID", mock.Anything, matchID).Return(dbMatch, nil)
+		mockCache.On("SetMatch", mock.Anything, dbMatch).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.Get(w, newMatchRequest(matchID.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockCache.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("successfully get match from database on cache miss", func(t *testing.T) {
 		mockRepo := new(MockMatchRepository)
 		mockCache := new(MockMatchCache)
@@ -141,18 +231,11 @@ func TestMatchHandler_Get(t *testing.T) {
 			Status:       domain.MatchRunning,
 		}
 
-		mockCache.On("Get", mock.Anything, matchID).Return(nil, nil)
+		mockCache.On("GetMatch", mock.Anything, matchID).Return(nil, nil)
 		mockRepo.On("GetByID", mock.Anything, matchID).Return(dbMatch, nil)
 
-		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+matchID.String(), nil)
-
-		rctx := chi.NewRouteContext()
-		rctx.URLParams.Add("id", matchID.String())
-		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
-
 		w := httptest.NewRecorder()
-
-		handler.Get(w, req)
+		handler.Get(w, newMatchRequest(matchID.String()))
 
 		assert.Equal(t, http.StatusOK, w.Code)
 
@@ -163,22 +246,50 @@ func TestMatchHandler_Get(t *testing.T) {
 
 		mockCache.AssertExpectations(t)
 		mockRepo.AssertExpectations(t)
+		// Running matches change status, they are not cached
+		mockCache.AssertNotCalled(t, "SetMatch", mock.Anything, mock.Anything)
 	})
 
-	t.Run("invalid UUID", func(t *testing.T) {
+	t.Run("populates program and team names", func(t *testing.T) {
 		mockRepo := new(MockMatchRepository)
 		mockCache := new(MockMatchCache)
+		programs := new(MockProgramInfoLookup)
 		handler := NewMatchHandler(mockRepo, mockCache, log)
+		handler.SetProgramInfo(programs)
 
-		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches/invalid-uuid", nil)
+		matchID, p1, p2 := uuid.New(), uuid.New(), uuid.New()
+		teamName := "Team A"
+		dbMatch := &domain.Match{ID: matchID, Program1ID: p1, Program2ID: p2, GameType: "chess", Status: domain.MatchPending}
 
-		rctx := chi.NewRouteContext()
-		rctx.URLParams.Add("id", "invalid-uuid")
-		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		mockCache.On("GetMatch", mock.Anything, matchID).Return(nil, nil)
+		mockRepo.On("GetByID", mock.Anything, matchID).Return(dbMatch, nil)
+		programs.On("GetInfoByIDs", mock.Anything, []uuid.UUID{p1, p2}).Return(map[uuid.UUID]*domain.ProgramInfo{
+			p1: {ProgramID: p1, ProgramName: "bot1", TeamName: &teamName},
+			p2: {ProgramID: p2, ProgramName: "bot2"},
+		}, nil)
 
 		w := httptest.NewRecorder()
+		handler.Get(w, newMatchRequest(matchID.String()))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, matchID.String(), response["id"])
+		assert.Equal(t, "bot1", response["program1_name"])
+		assert.Equal(t, "Team A", response["team1_name"])
+		assert.Equal(t, "bot2", response["program2_name"])
+		assert.NotContains(t, response, "team2_name")
+		programs.AssertExpectations(t)
+	})
 
-		handler.Get(w, req)
+	t.Run("invalid UUID", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		mockCache := new(MockMatchCache)
+		handler := NewMatchHandler(mockRepo, mockCache, log)
+
+		w := httptest.NewRecorder()
+		handler.Get(w, newMatchRequest("invalid-uuid"))
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
@@ -190,18 +301,11 @@ func TestMatchHandler_Get(t *testing.T) {
 
 		matchID := uuid.New()
 
-		mockCache.On("Get", mock.Anything, matchID).Return(nil, nil)
+		mockCache.On("GetMatch", mock.Anything, matchID).Return(nil, nil)
 		mockRepo.On("GetByID", mock.Anything, matchID).Return(nil, errors.ErrNotFound.WithMessage("match not found"))
 
-		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+matchID.String(), nil)
-
-		rctx := chi.NewRouteContext()
-		rctx.URLParams.Add("id", matchID.String())
-		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
-
 		w := httptest.NewRecorder()
-
-		handler.Get(w, req)
+		handler.Get(w, newMatchRequest(matchID.String()))
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
 
@@ -256,6 +360,34 @@ func TestMatchHandler_List(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("include_total wraps page with total", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		handler := NewMatchHandler(mockRepo, new(MockMatchCache), log)
+
+		matches := []*domain.Match{{ID: uuid.New(), GameType: "chess", Status: domain.MatchCompleted}}
+		mockRepo.On("List", mock.Anything, mock.MatchedBy(func(filter domain.MatchFilter) bool {
+			return filter.GameType == "chess" && filter.Limit == 10 && filter.Offset == 20
+		})).Return(matches, nil)
+		mockRepo.On("CountWithFilter", mock.Anything, mock.MatchedBy(func(filter domain.MatchFilter) bool {
+			return filter.GameType == "chess"
+		})).Return(21, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches?game_type=chess&limit=10&offset=20&include_total=true", nil)
+		w := httptest.NewRecorder()
+
+		handler.List(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page pagination.OffsetPage[domain.Match]
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, 21, page.Total)
+		assert.Equal(t, 10, page.Limit)
+		assert.Equal(t, 20, page.Offset)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("list with tournament_id filter", func(t *testing.T) {
 		mockRepo := new(MockMatchRepository)
 		mockCache := new(MockMatchCache)
@@ -460,3 +592,478 @@ func TestMatchHandler_GetStatistics(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+type MockHeadToHeadLookup struct {
+	mock.Mock
+}
+
+func (m *MockHeadToHeadLookup) GetHeadToHead(ctx context.Context, a, b uuid.UUID, tournamentID *uuid.UUID) (*domain.HeadToHeadRecord, error) {
+	args := m.Called(ctx, a, b, tournamentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.HeadToHeadRecord), args.Error(1)
+}
+
+func TestMatchHandler_HeadToHead(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	a, b := uuid.New(), uuid.New()
+
+	t.Run("returns record for a tournament", func(t *testing.T) {
+		lookup := new(MockHeadToHeadLookup)
+		handler := NewMatchHandler(new(MockMatchRepository), new(MockMatchCache), log)
+		handler.SetHeadToHead(lookup)
+
+		tournamentID := uuid.New()
+		winner := 2
+		record := &domain.HeadToHeadRecord{
+			ProgramA:     a,
+			ProgramB:     b,
+			TournamentID: &tournamentID,
+			Wins:         2,
+			Losses:       1,
+			Draws:        1,
+			Matches: []*domain.Match{
+				{ID: uuid.New(), Program1ID: b, Program2ID: a, Status: domain.MatchCompleted, Winner: &winner},
+			},
+		}
+		lookup.On("GetHeadToHead", mock.Anything, a, b, &tournamentID).Return(record, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches/head-to-head?a="+a.String()+"&b="+b.String()+"&tournament="+tournamentID.String(), nil)
+		w := httptest.NewRecorder()
+		handler.HeadToHead(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response domain.HeadToHeadRecord
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, int64(2), response.Wins)
+		assert.Equal(t, int64(1), response.Losses)
+		assert.Equal(t, int64(1), response.Draws)
+		assert.Len(t, response.Matches, 1)
+		lookup.AssertExpectations(t)
+	})
+
+	t.Run("tournament is optional", func(t *testing.T) {
+		lookup := new(MockHeadToHeadLookup)
+		handler := NewMatchHandler(new(MockMatchRepository), new(MockMatchCache), log)
+		handler.SetHeadToHead(lookup)
+
+		lookup.On("GetHeadToHead", mock.Anything, a, b, (*uuid.UUID)(nil)).
+			Return(&domain.HeadToHeadRecord{ProgramA: a, ProgramB: b, Matches: []*domain.Match{}}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches/head-to-head?a="+a.String()+"&b="+b.String(), nil)
+		w := httptest.NewRecorder()
+		handler.HeadToHead(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		lookup.AssertExpectations(t)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		handler := NewMatchHandler(new(MockMatchRepository), new(MockMatchCache), log)
+		handler.SetHeadToHead(new(MockHeadToHeadLookup))
+
+		for _, query := range []string{
+			"b=" + b.String(),
+			"a=" + a.String() + "&b=invalid",
+			"a=" + a.String() + "&b=" + a.String(),
+			"a=" + a.String() + "&b=" + b.String() + "&tournament=invalid",
+		} {
+			w := httptest.NewRecorder()
+			handler.HeadToHead(w, httptest.NewRequest(http.MethodGet, "/api/v1/matches/head-to-head?"+query, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
+
+func TestMatchHandler_Reprioritize(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(matchID, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/matches/"+matchID+"/priority", strings.NewReader(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", matchID)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("updates DB and queue", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		mockQueue := new(MockMatchQueueManager)
+		handler := NewMatchHandlerFull(mockRepo, new(MockMatchCache), nil, mockQueue, log)
+
+		matchID := uuid.New()
+		mockRepo.On("UpdatePriority", mock.Anything, matchID, domain.PriorityHigh).Return(nil)
+		mockQueue.On("Reprioritize", mock.Anything, matchID, domain.PriorityHigh).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.Reprioritize(w, newRequest(matchID.String(), `{"priority":"high"}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+		mockQueue.AssertExpectations(t)
+	})
+
+	t.Run("match not in queue yet", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		mockQueue := new(MockMatchQueueManager)
+		handler := NewMatchHandlerFull(mockRepo, new(MockMatchCache), nil, mockQueue, log)
+
+		matchID := uuid.New()
+		mockRepo.On("UpdatePriority", mock.Anything, matchID, domain.PriorityLow).Return(nil)
+		mockQueue.On("Reprioritize", mock.Anything, matchID, domain.PriorityLow).Return(queue.ErrMatchNotQueued)
+
+		w := httptest.NewRecorder()
+		handler.Reprioritize(w, newRequest(matchID.String(), `{"priority":"low"}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("match is not pending", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		mockQueue := new(MockMatchQueueManager)
+		handler := NewMatchHandlerFull(mockRepo, new(MockMatchCache), nil, mockQueue, log)
+
+		matchID := uuid.New()
+		mockRepo.On("UpdatePriority", mock.Anything, matchID, domain.PriorityHigh).Return(errors.ErrConflict)
+
+		w := httptest.NewRecorder()
+		handler.Reprioritize(w, newRequest(matchID.String(), `{"priority":"high"}`))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockQueue.AssertNotCalled(t, "Reprioritize", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid priority", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		handler := NewMatchHandler(mockRepo, new(MockMatchCache), log)
+
+		w := httptest.NewRecorder()
+		handler.Reprioritize(w, newRequest(uuid.New().String(), `{"priority":"urgent"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "UpdatePriority", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// MockMatchResultAdjuster mocks manual match result corrections
+type MockMatchResultAdjuster struct {
+	mock.Mock
+}
+
+func (m *MockMatchResultAdjuster) AdjustMatchResult(ctx context.Context, matchID, adminID uuid.UUID, req *tournament.AdjustMatchResultRequest) (*domain.Match, error) {
+	args := m.Called(ctx, matchID, adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Match), args.Error(1)
+}
+
+func (m *MockMatchResultAdjuster) AnnulMatchResult(ctx context.Context, matchID, adminID uuid.UUID, req *tournament.AnnulMatchResultRequest) (*domain.Match, error) {
+	args := m.Called(ctx, matchID, adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Match), args.Error(1)
+}
+
+func (m *MockMatchResultAdjuster) GetMatchResultAdjustments(ctx context.Context, matchID uuid.UUID) ([]*domain.MatchResultAdjustment, error) {
+	args := m.Called(ctx, matchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MatchResultAdjustment), args.Error(1)
+}
+
+func TestMatchHandler_AdjustResult(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	adminID := uuid.New()
+
+	newRequest := func(method, matchID, body string) *http.Request {
+		req := httptest.NewRequest(method, "/api/v1/matches/"+matchID+"/result", strings.NewReader(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", matchID)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		return req.WithContext(context.WithValue(ctx, middleware.UserIDKey, adminID))
+	}
+
+	t.Run("edit replaces the result and drops the cached match", func(t *testing.T) {
+		mockCache := new(MockMatchCache)
+		results := new(MockMatchResultAdjuster)
+		handler := NewMatchHandler(new(MockMatchRepository), mockCache, log)
+		handler.SetResultAdjuster(results)
+
+		matchID := uuid.New()
+		score1, score2, winner := 0, 5, 2
+		results.On("AdjustMatchResult", mock.Anything, matchID, adminID, mock.MatchedBy(func(req *tournament.AdjustMatchResultRequest) bool {
+			return *req.Score1 == 0 && *req.Score2 == 5 && *req.Winner == 2 && req.Reason == "scorer bug"
+		})).Return(&domain.Match{ID: matchID, Status: domain.MatchCompleted, Score1: &score1, Score2: &score2, Winner: &winner}, nil)
+		mockCache.On("Delete", mock.Anything, matchID).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.AdjustResult(w, newRequest(http.MethodPut, matchID.String(), `{"score1":0,"score2":5,"winner":2,"reason":"scorer bug"}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var match domain.Match
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &match))
+		assert.Equal(t, 2, *match.Winner)
+		results.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("annul passes requeue", func(t *testing.T) {
+		mockCache := new(MockMatchCache)
+		results := new(MockMatchResultAdjuster)
+		handler := NewMatchHandler(new(MockMatchRepository), mockCache, log)
+		handler.SetResultAdjuster(results)
+
+		matchID := uuid.New()
+		results.On("AnnulMatchResult", mock.Anything, matchID, adminID, &tournament.AnnulMatchResultRequest{Reason: "worker crash", Requeue: true}).
+			Return(&domain.Match{ID: matchID, Status: domain.MatchPending}, nil)
+		mockCache.On("Delete", mock.Anything, matchID).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.AnnulResult(w, newRequest(http.MethodDelete, matchID.String(), `{"reason":"worker crash","requeue":true}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		results.AssertExpectations(t)
+	})
+
+	t.Run("match without result", func(t *testing.T) {
+		results := new(MockMatchResultAdjuster)
+		handler := NewMatchHandler(new(MockMatchRepository), new(MockMatchCache), log)
+		handler.SetResultAdjuster(results)
+
+		matchID := uuid.New()
+		results.On("AnnulMatchResult", mock.Anything, matchID, adminID, mock.Anything).
+			Return(nil, errors.ErrConflict.WithMessage("match has no result to adjust"))
+
+		w := httptest.NewRecorder()
+		handler.AnnulResult(w, newRequest(http.MethodDelete, matchID.String(), `{"reason":"duplicate"}`))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		handler := NewMatchHandler(new(MockMatchRepository), new(MockMatchCache), log)
+
+		w := httptest.NewRecorder()
+		handler.AdjustResult(w, newRequest(http.MethodPut, uuid.New().String(), `{"reason":"x"}`))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestMatchHandler_ListWithCursor(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	matches := []*domain.Match{
+		{ID: uuid.New(), RoundNumber: 2, Status: domain.MatchCompleted},
+		{ID: uuid.New(), RoundNumber: 1, Status: domain.MatchCompleted},
+	}
+
+	t.Run("forward page returns keyset cursors", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		handler := NewMatchHandler(mockRepo, new(MockMatchCache), log)
+
+		after := pagination.KeysetCursor{Round: 3, ID: uuid.New()}.Encode()
+		mockRepo.On("ListWithCursor", mock.Anything, mock.Anything, mock.MatchedBy(func(p *pagination.PageRequest) bool {
+			return p.First != nil && *p.First == 2 && p.After != nil && *p.After == after
+		})).Return(matches, true, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches?first=2&after="+after, nil)
+		w := httptest.NewRecorder()
+		handler.List(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page pagination.KeysetPage[domain.Match]
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		require.Len(t, page.Items, 2)
+		assert.True(t, page.PageInfo.HasNextPage)
+		assert.True(t, page.PageInfo.HasPreviousPage)
+
+		require.NotNil(t, page.PageInfo.EndCursor)
+		end, err := pagination.DecodeKeysetCursor(*page.PageInfo.EndCursor)
+		require.NoError(t, err)
+		assert.Equal(t, pagination.KeysetCursor{Round: 1, ID: matches[1].ID}, *end)
+		mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+
+	t.Run("before defaults to backward page", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		handler := NewMatchHandler(mockRepo, new(MockMatchCache), log)
+
+		before := pagination.KeysetCursor{Round: 1, ID: uuid.New()}.Encode()
+		mockRepo.On("ListWithCursor", mock.Anything, mock.Anything, mock.MatchedBy(func(p *pagination.PageRequest) bool {
+			return p.IsBackward() && p.Last != nil && *p.Last == 20
+		})).Return(matches, false, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches?before="+before, nil)
+		w := httptest.NewRecorder()
+		handler.List(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page pagination.KeysetPage[domain.Match]
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		assert.False(t, page.PageInfo.HasPreviousPage)
+		assert.True(t, page.PageInfo.HasNextPage)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		handler := NewMatchHandler(mockRepo, new(MockMatchCache), log)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/matches?first=10&after=not-a-cursor", nil)
+		w := httptest.NewRecorder()
+		handler.List(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "ListWithCursor", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+type MockTestMatchCreator struct {
+	mock.Mock
+}
+
+func (m *MockTestMatchCreator) CreateTestMatch(ctx context.Context, req *tournament.TestMatchRequest) (*domain.Match, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Match), args.Error(1)
+}
+
+type MockTestMatchLimiter struct {
+	mock.Mock
+}
+
+func (m *MockTestMatchLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	args := m.Called(ctx, key, limit, window)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestMatchHandler_CreateTestMatch(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	userID := uuid.New()
+	program1ID, program2ID := uuid.New(), uuid.New()
+	body := `{"program1_id":"` + program1ID.String() + `","program2_id":"` + program2ID.String() + `","game_type":"tictactoe"}`
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/matches/test", strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+	}
+
+	t.Run("creates test match", func(t *testing.T) {
+		creator := new(MockTestMatchCreator)
+		limiter := new(MockTestMatchLimiter)
+		handler := NewMatchHandler(new(MockMatchRepository), new(MockMatchCache), log)
+		handler.SetTestMatches(creator, limiter, 5)
+
+		matchID := uuid.New()
+		limiter.On("Allow", mock.Anything, "ratelimit:test_match:"+userID.String(), 5, time.Hour).Return(true, nil)
+		creator.On("CreateTestMatch", mock.Anything, &tournament.TestMatchRequest{
+			Program1ID: program1ID,
+			Program2ID: program2ID,
+			GameType:   "tictactoe",
+			UserID:     userID,
+		}).Return(&domain.Match{ID: matchID, IsTest: true, Priority: domain.PriorityHigh}, nil)
+
+		w := httptest.NewRecorder()
+		handler.CreateTestMatch(w, newRequest())
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response domain.Match
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, matchID, response.ID)
+		assert.True(t, response.IsTest)
+		creator.AssertExpectations(t)
+	})
+
+	t.Run("limit exceeded", func(t *testing.T) {
+		creator := new(MockTestMatchCreator)
+		limiter := new(MockTestMatchLimiter)
+		handler := NewMatchHandler(new(MockMatchRepository), new(MockMatchCache), log)
+		handler.SetTestMatches(creator, limiter, 5)
+
+		limiter.On("Allow", mock.Anything, mock.Anything, 5, time.Hour).Return(false, nil)
+
+		w := httptest.NewRecorder()
+		handler.CreateTestMatch(w, newRequest())
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		creator.AssertNotCalled(t, "CreateTestMatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		handler := NewMatchHandler(new(MockMatchRepository), new(MockMatchCache), log)
+
+		w := httptest.NewRecorder()
+		handler.CreateTestMatch(w, newRequest())
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestMatchHandler_PeekQueue(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	t.Run("includes current status from DB", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		mockQueue := new(MockMatchQueueManager)
+		handler := NewMatchHandlerFull(mockRepo, new(MockMatchCache), nil, mockQueue, log)
+
+		running := &domain.Match{ID: uuid.New(), Status: domain.MatchPending, Priority: domain.PriorityHigh}
+		deleted := &domain.Match{ID: uuid.New(), Status: domain.MatchPending, Priority: domain.PriorityHigh}
+		mockQueue.On("GetPeekByPriority", mock.Anything, domain.PriorityHigh, 5).Return([]*domain.Match{running, deleted}, nil)
+		mockRepo.On("GetByIDs", mock.Anything, []uuid.UUID{running.ID, deleted.ID}).
+			Return([]*domain.Match{{ID: running.ID, Status: domain.MatchRunning}}, nil)
+
+		w := httptest.NewRecorder()
+		handler.PeekQueue(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/queue/peek?priority=high&n=5", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Priority string `json:"priority"`
+			Matches  []struct {
+				ID            uuid.UUID `json:"id"`
+				Status        string    `json:"status"`
+				CurrentStatus *string   `json:"current_status"`
+			} `json:"matches"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "high", resp.Priority)
+		require.Len(t, resp.Matches, 2)
+		assert.Equal(t, running.ID, resp.Matches[0].ID)
+		assert.Equal(t, "pending", resp.Matches[0].Status)
+		require.NotNil(t, resp.Matches[0].CurrentStatus)
+		assert.Equal(t, "running", *resp.Matches[0].CurrentStatus)
+		assert.Nil(t, resp.Matches[1].CurrentStatus)
+	})
+
+	t.Run("defaults n", func(t *testing.T) {
+		mockRepo := new(MockMatchRepository)
+		mockQueue := new(MockMatchQueueManager)
+		handler := NewMatchHandlerFull(mockRepo, new(MockMatchCache), nil, mockQueue, log)
+
+		mockQueue.On("GetPeekByPriority", mock.Anything, domain.PriorityLow, defaultPeekSize).Return([]*domain.Match{}, nil)
+		mockRepo.On("GetByIDs", mock.Anything, []uuid.UUID{}).Return([]*domain.Match{}, nil)
+
+		w := httptest.NewRecorder()
+		handler.PeekQueue(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/queue/peek?priority=low", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"priority":"low","matches":[]}`, w.Body.String())
+	})
+
+	t.Run("validates query", func(t *testing.T) {
+		handler := NewMatchHandlerFull(new(MockMatchRepository), new(MockMatchCache), nil, new(MockMatchQueueManager), log)
+
+		for _, query := range []string{"", "?priority=urgent", "?priority=high&n=0", "?priority=high&n=abc"} {
+			w := httptest.NewRecorder()
+			handler.PeekQueue(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/queue/peek"+query, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// NotificationService интерфейс для управления подписками на уведомления
+type NotificationService interface {
+	GetPreferences(ctx context.Context, userID uuid.UUID) (domain.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, prefs domain.NotificationPreferences) (domain.NotificationPreferences, error)
+}
+
+// NotificationHandler обрабатывает запросы подписок на уведомления
+type NotificationHandler struct {
+	notificationService NotificationService
+	log                 *logger.Logger
+}
+
+// NewNotificationHandler создаёт новый notification handler
+func NewNotificationHandler(notificationService NotificationService, log *logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		log:                 log,
+	}
+}
+
+// GetPreferences возвращает подписки текущего пользователя по всем типам событий
+// GET /api/v1/users/me/notifications
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	prefs, err := h.notificationService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		h.log.LogError("Failed to get notification preferences", err)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// UpdatePreferences включает и выключает уведомления о событиях.
+// Тело - {"<event>": true|false}, события, не указанные в запросе, не меняются
+// PUT /api/v1/users/me/notifications
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var prefs domain.NotificationPreferences
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
+		h.log.Info("Invalid request body", zap.Error(err))
+		writeError(w, errors.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	updated, err := h.notificationService.UpdatePreferences(r.Context(), userID, prefs)
+	if err != nil {
+		h.log.LogError("Failed to update notification preferences", err)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) (domain.NotificationPreferences, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(domain.NotificationPreferences), args.Error(1)
+}
+
+func (m *MockNotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, prefs domain.NotificationPreferences) (domain.NotificationPreferences, error) {
+	args := m.Called(ctx, userID, prefs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(domain.NotificationPreferences), args.Error(1)
+}
+
+func newNotificationRequest(method, body string, userID *uuid.UUID) *http.Request {
+	req := httptest.NewRequest(method, "/api/v1/users/me/notifications", strings.NewReader(body))
+	if userID == nil {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, *userID))
+}
+
+func TestNotificationHandler_GetPreferences(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	userID := uuid.New()
+
+	t.Run("returns effective preferences", func(t *testing.T) {
+		prefs := domain.NotificationPreferences{}.Effective()
+		prefs[domain.NotificationMatchesCompleted] = false
+
+		service := new(MockNotificationService)
+		service.On("GetPreferences", mock.Anything, userID).Return(prefs, nil)
+
+		w := httptest.NewRecorder()
+		NewNotificationHandler(service, log).GetPreferences(w, newNotificationRequest(http.MethodGet, "", &userID))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]bool
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.False(t, resp["matches_completed"])
+		assert.True(t, resp["tournament_started"])
+		assert.Len(t, resp, len(domain.NotificationEvents))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		service := new(MockNotificationService)
+
+		w := httptest.NewRecorder()
+		NewNotificationHandler(service, log).GetPreferences(w, newNotificationRequest(http.MethodGet, "", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		service.AssertNotCalled(t, "GetPreferences", mock.Anything, mock.Anything)
+	})
+}
+
+func TestNotificationHandler_UpdatePreferences(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	userID := uuid.New()
+
+	t.Run("passes only given events", func(t *testing.T) {
+		given := domain.NotificationPreferences{domain.NotificationCompileFailed: false}
+		updated := given.Effective()
+
+		service := new(MockNotificationService)
+		service.On("UpdatePreferences", mock.Anything, userID, given).Return(updated, nil)
+
+		w := httptest.NewRecorder()
+		NewNotificationHandler(service, log).UpdatePreferences(w,
+			newNotificationRequest(http.MethodPut, `{"compile_failed": false}`, &userID))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]bool
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.False(t, resp["compile_failed"])
+		assert.True(t, resp["tournament_completed"])
+		service.AssertExpectations(t)
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		service := new(MockNotificationService)
+		service.On("UpdatePreferences", mock.Anything, userID, mock.Anything).
+			Return(nil, errors.ErrValidation.WithMessage("unknown notification event: spam"))
+
+		w := httptest.NewRecorder()
+		NewNotificationHandler(service, log).UpdatePreferences(w,
+			newNotificationRequest(http.MethodPut, `{"spam": true}`, &userID))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		service := new(MockNotificationService)
+
+		w := httptest.NewRecorder()
+		NewNotificationHandler(service, log).UpdatePreferences(w,
+			newNotificationRequest(http.MethodPut, `{"compile_failed": "no"}`, &userID))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		service.AssertNotCalled(t, "UpdatePreferences", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -2,14 +2,19 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
@@ -24,23 +29,29 @@ import (
 type ProgramRepository interface {
 	Create(ctx context.Context, program *domain.Program) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Program, error)
-	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Program, error)
+	ListByUserFiltered(ctx context.Context, userID uuid.UUID, filter domain.ProgramFilter) ([]*domain.Program, error)
 	Update(ctx context.Context, program *domain.Program) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	CheckOwnership(ctx context.Context, programID, userID uuid.UUID) (bool, error)
 	GetLatestVersion(ctx context.Context, teamID, gameID uuid.UUID) (int, error)
+	GetLatestByTeamAndGame(ctx context.Context, teamID, gameID uuid.UUID) (*domain.Program, error)
+	GetLatestVersionCreatedAt(ctx context.Context, teamID, gameID uuid.UUID) (*time.Time, error)
 	GetAllVersionsByTeamAndGame(ctx context.Context, teamID, gameID uuid.UUID) ([]*domain.Program, error)
+	DeleteIfUnused(ctx context.Context, id uuid.UUID) (bool, error)
 	ClearErrorMessages(ctx context.Context, tournamentID uuid.UUID) (int64, error)
 }
 
-// TournamentParticipantAdder интерфейс для добавления участников в турнир
+// TournamentParticipantAdder интерфейс для добавления участников в турнир и проверки банов команд
 type TournamentParticipantAdder interface {
 	AddParticipant(ctx context.Context, participant *domain.TournamentParticipant) error
+	IsTeamBanned(ctx context.Context, tournamentID, teamID uuid.UUID) (bool, error)
 }
 
 // MatchScheduler интерфейс для создания матчей
 type MatchScheduler interface {
 	ScheduleNewProgramMatches(ctx context.Context, tournamentID, gameID, newProgramID, teamID uuid.UUID) error
+	ScheduleCalibrationMatches(ctx context.Context, tournamentID, gameID, programID uuid.UUID) error
+	ScheduleValidationMatch(ctx context.Context, tournamentID, gameID, programID uuid.UUID) (*domain.Match, error)
 }
 
 // GameLookup интерфейс для получения информации об игре
@@ -52,29 +63,68 @@ type GameLookup interface {
 type MatchExistenceChecker interface {
 	HasStartedMatches(ctx context.Context, tournamentID uuid.UUID, gameType string) (bool, error)
 	HasAnyRunningMatches(ctx context.Context, tournamentID uuid.UUID) (bool, error)
+	HasRunningMatchesForGame(ctx context.Context, tournamentID uuid.UUID, gameType string) (bool, error)
 	GetActiveGameType(ctx context.Context, tournamentID uuid.UUID) (string, error)
 }
 
-// RoundCompletionChecker интерфейс для проверки завершения раунда игры
+// RoundCompletionChecker интерфейс для проверки состояния раунда игры
 type RoundCompletionChecker interface {
 	IsRoundCompleted(ctx context.Context, tournamentID, gameID uuid.UUID) (bool, error)
+	IsRoundActive(ctx context.Context, tournamentID, gameID uuid.UUID) (bool, error)
 }
 
+// UploadTournamentLookup интерфейс для получения настроек турнира при загрузке программы
+type UploadTournamentLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error)
+}
+
+// ProgramRankLookup интерфейс для получения места программы в таблице лидеров турнира
+type ProgramRankLookup interface {
+	GetRank(ctx context.Context, tournamentID, programID uuid.UUID) (int64, error)
+}
+
+// ProgramStatsLookup интерфейс для получения статистики матчей программы
+type ProgramStatsLookup interface {
+	GetProgramStats(ctx context.Context, programID uuid.UUID) (*domain.ProgramStats, error)
+}
+
+// Размер страницы списка программ: без limit возвращается не больше defaultProgramsLimit
+const (
+	defaultProgramsLimit = 50
+	maxProgramsLimit     = 500
+)
+
+// defaultUploadCooldown интервал между загрузками версий программы командой по умолчанию
+const defaultUploadCooldown = 5 * time.Minute
+
 // ProgramHandler обрабатывает запросы программ
 type ProgramHandler struct {
-	programRepo    ProgramRepository
-	tournamentRepo TournamentParticipantAdder
-	matchScheduler MatchScheduler
-	gameLookup     GameLookup
-	matchChecker   MatchExistenceChecker
-	roundChecker   RoundCompletionChecker
-	uploadDir      string
-	maxFileSize    int64
-	log            *logger.Logger
-}
-
-// NewProgramHandler создаёт новый program handler
-func NewProgramHandler(programRepo ProgramRepository, tournamentRepo TournamentParticipantAdder, matchScheduler MatchScheduler, log *logger.Logger) *ProgramHandler {
+	programRepo      ProgramRepository
+	tournamentRepo   TournamentParticipantAdder
+	matchScheduler   MatchScheduler
+	gameLookup       GameLookup
+	matchChecker     MatchExistenceChecker
+	roundChecker     RoundCompletionChecker
+	tournamentLookup UploadTournamentLookup
+	rankLookup       ProgramRankLookup
+	statsLookup      ProgramStatsLookup
+	matchHistory     MatchExportSource
+	programInfo      ProgramInfoLookup
+	referenceBots    ReferenceBotRepository
+	validationBots   GameValidationBotRepository
+	uploadLimiter    *TeamUploadRateLimiter
+	uploadDir        string
+	maxFileSize      int64
+	uploadCooldown   time.Duration
+	maxVersions      int
+	pruneVersions    bool
+	languageVersions domain.LanguageVersions
+	log              *logger.Logger
+}
+
+// NewProgramHandler создаёт новый program handler.
+// rateLimiter ограничивает частоту загрузок программ командой (nil - без ограничения)
+func NewProgramHandler(programRepo ProgramRepository, tournamentRepo TournamentParticipantAdder, matchScheduler MatchScheduler, rateLimiter middleware.RateLimiter, log *logger.Logger) *ProgramHandler {
 	// Создаём директорию для загрузок (используем PROGRAMS_PATH для согласованности с worker)
 	uploadDir := os.Getenv("PROGRAMS_PATH")
 	if uploadDir == "" {
@@ -85,14 +135,19 @@ func NewProgramHandler(programRepo ProgramRepository, tournamentRepo TournamentP
 		log.Error("Failed to create upload directory", zap.Error(err))
 	}
 
-	return &ProgramHandler{
+	h := &ProgramHandler{
 		programRepo:    programRepo,
 		tournamentRepo: tournamentRepo,
 		matchScheduler: matchScheduler,
 		uploadDir:      uploadDir,
 		maxFileSize:    10 * 1024 * 1024, // 10MB
+		uploadCooldown: defaultUploadCooldown,
 		log:            log,
 	}
+	if rateLimiter != nil {
+		h.uploadLimiter = NewTeamUploadRateLimiter(rateLimiter)
+	}
+	return h
 }
 
 // SetGameLookup устанавливает GameLookup для проверки игр
@@ -110,6 +165,16 @@ func (h *ProgramHandler) SetRoundChecker(roundChecker RoundCompletionChecker) {
 	h.roundChecker = roundChecker
 }
 
+// SetRankLookup устанавливает источник мест программ в таблице лидеров
+func (h *ProgramHandler) SetRankLookup(rankLookup ProgramRankLookup) {
+	h.rankLookup = rankLookup
+}
+
+// SetStatsLookup устанавливает источник статистики матчей программ
+func (h *ProgramHandler) SetStatsLookup(statsLookup ProgramStatsLookup) {
+	h.statsLookup = statsLookup
+}
+
 // detectLanguage определяет язык программирования по расширению файла
 func detectLanguage(filename string) string {
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -157,6 +222,284 @@ func getShebang(language string) string {
 	}
 }
 
+// hashUpload вычисляет SHA-256 содержимого программы в том виде, в котором она будет сохранена:
+// с добавленным shebang, если он нужен. Позиция чтения файла возвращается в начало
+func hashUpload(file io.ReadSeeker, shebang string) (hash string, addShebang bool, err error) {
+	if shebang != "" {
+		firstBytes := make([]byte, 2)
+		n, _ := io.ReadFull(file, firstBytes)
+		addShebang = n < 2 || string(firstBytes) != "#!"
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return "", false, err
+		}
+	}
+
+	hasher := sha256.New()
+	if addShebang {
+		hasher.Write([]byte(shebang))
+	}
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", false, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", false, err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), addShebang, nil
+}
+
+// saveProgramFile сохраняет загруженную программу как исполняемый файл,
+// при необходимости добавляя shebang. Частично записанный файл удаляется
+func (h *ProgramHandler) saveProgramFile(filePath string, file io.Reader, shebang string, addShebang bool) error {
+	dst, err := os.Create(filePath)
+	if err != nil {
+		h.log.Error("Failed to create file", zap.Error(err), zap.String("path", filePath))
+		return errors.ErrInternal.WithMessage("failed to save file")
+	}
+	defer dst.Close()
+
+	// Добавляем shebang для интерпретируемых языков (если его нет)
+	if addShebang {
+		if _, err := dst.WriteString(shebang); err != nil {
+			h.log.Error("Failed to write shebang", zap.Error(err))
+			os.Remove(filePath)
+			return errors.ErrInternal.WithMessage("failed to save file")
+		}
+	}
+
+	if _, err := io.Copy(dst, file); err != nil {
+		h.log.Error("Failed to write file", zap.Error(err))
+		// Удаляем частично записанный файл
+		os.Remove(filePath)
+		return errors.ErrInternal.WithMessage("failed to save file")
+	}
+
+	// Делаем файл исполняемым
+	if err := os.Chmod(filePath, 0755); err != nil {
+		h.log.Warn("Failed to make file executable", zap.Error(err), zap.String("path", filePath))
+	}
+
+	return nil
+}
+
+// duplicateUploadResponse ответ на повторную загрузку идентичной программы
+type duplicateUploadResponse struct {
+	*domain.Program
+	Duplicate bool `json:"duplicate"`
+}
+
+// SetUploadCooldown устанавливает интервал между загрузками версий программы командой
+// lookup используется для переопределения интервала через метаданные турнира (может быть nil)
+func (h *ProgramHandler) SetUploadCooldown(cooldown time.Duration, lookup UploadTournamentLookup) {
+	h.uploadCooldown = cooldown
+	h.tournamentLookup = lookup
+}
+
+// SetVersionQuota ограничивает число версий программы команды для игры (0 - без ограничения).
+// prune - при достижении лимита удалять самую старую версию без матчей вместо отказа
+func (h *ProgramHandler) SetVersionQuota(maxVersions int, prune bool) {
+	h.maxVersions = maxVersions
+	h.pruneVersions = prune
+}
+
+// SetLanguageVersions устанавливает допустимые версии языков для загрузки программ
+func (h *ProgramHandler) SetLanguageVersions(versions domain.LanguageVersions) {
+	h.languageVersions = versions
+}
+
+// versionQuota состояние лимита версий команды для игры
+type versionQuota struct {
+	MaxVersions    int   `json:"max_versions"`
+	Versions       int   `json:"versions"`
+	Remaining      int   `json:"remaining"`
+	PrunedVersions []int `json:"pruned_versions,omitempty"`
+}
+
+// uploadResponse ответ на загрузку новой версии программы
+type uploadResponse struct {
+	*domain.Program
+	Quota *versionQuota `json:"quota,omitempty"`
+}
+
+// enforceVersionQuota проверяет, есть ли место для новой версии программы команды.
+// С pruneVersions освобождает место, удаляя самые старые версии без матчей; последняя
+// версия не удаляется никогда. Возвращает квоту без учёта новой версии (nil - лимита нет)
+func (h *ProgramHandler) enforceVersionQuota(ctx context.Context, teamID, gameID uuid.UUID) (*versionQuota, error) {
+	if h.maxVersions <= 0 {
+		return nil, nil
+	}
+
+	versions, err := h.programRepo.GetAllVersionsByTeamAndGame(ctx, teamID, gameID)
+	if err != nil {
+		return nil, err
+	}
+	quota := &versionQuota{MaxVersions: h.maxVersions, Versions: len(versions)}
+
+	// Версии отсортированы от новой к старой
+	if h.pruneVersions {
+		for i := len(versions) - 1; i > 0 && quota.Versions >= h.maxVersions; i-- {
+			deleted, err := h.programRepo.DeleteIfUnused(ctx, versions[i].ID)
+			if err != nil {
+				return nil, err
+			}
+			if !deleted {
+				continue
+			}
+			h.removeProgramFile(versions[i])
+			quota.Versions--
+			quota.PrunedVersions = append(quota.PrunedVersions, versions[i].Version)
+			h.log.Info("Old program version pruned",
+				zap.String("program_id", versions[i].ID.String()),
+				zap.String("team_id", teamID.String()),
+				zap.Int("version", versions[i].Version),
+			)
+		}
+	}
+
+	if quota.Versions >= h.maxVersions {
+		message := fmt.Sprintf("достигнут лимит версий программы для игры (%d): удалите старые версии", h.maxVersions)
+		if h.pruneVersions {
+			message = fmt.Sprintf("достигнут лимит версий программы для игры (%d): все старые версии уже сыграли матчи", h.maxVersions)
+		}
+		return quota, errors.ErrConflict.WithMessage(message)
+	}
+	return quota, nil
+}
+
+// removeProgramFile удаляет файл программы (если есть)
+func (h *ProgramHandler) removeProgramFile(program *domain.Program) {
+	if program.FilePath == nil || *program.FilePath == "" {
+		return
+	}
+	if err := os.Remove(*program.FilePath); err != nil {
+		h.log.Warn("Failed to delete program file", zap.Error(err), zap.String("path", *program.FilePath))
+	}
+}
+
+// uploadCooldownFor возвращает интервал между загрузками для турнира
+func (h *ProgramHandler) uploadCooldownFor(ctx context.Context, tournamentID uuid.UUID) time.Duration {
+	if h.tournamentLookup == nil {
+		return h.uploadCooldown
+	}
+
+	tournament, err := h.tournamentLookup.GetByID(ctx, tournamentID)
+	if err != nil {
+		h.log.LogError("Failed to get tournament for upload cooldown", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		return h.uploadCooldown
+	}
+
+	return tournament.UploadCooldown(h.uploadCooldown)
+}
+
+// checkUploadCooldown проверяет, прошёл ли интервал с загрузки последней версии команды для игры
+// Возвращает оставшееся время ожидания (0 - загрузка разрешена)
+func (h *ProgramHandler) checkUploadCooldown(ctx context.Context, tournamentID, teamID, gameID uuid.UUID) (time.Duration, error) {
+	cooldown := h.uploadCooldownFor(ctx, tournamentID)
+	if cooldown <= 0 {
+		return 0, nil
+	}
+
+	lastUpload, err := h.programRepo.GetLatestVersionCreatedAt(ctx, teamID, gameID)
+	if err != nil {
+		return 0, err
+	}
+	if lastUpload == nil {
+		return 0, nil
+	}
+
+	if remaining := time.Until(lastUpload.Add(cooldown)); remaining > 0 {
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+// roundLockFor возвращает режим блокировки загрузок во время раунда для турнира
+func (h *ProgramHandler) roundLockFor(ctx context.Context, tournamentID uuid.UUID) domain.RoundLockMode {
+	// Без справочника игр нельзя определить раунд конкретной игры
+	if h.gameLookup == nil {
+		return domain.RoundLockTournament
+	}
+	if h.tournamentLookup == nil {
+		return domain.RoundLockGame
+	}
+
+	tournament, err := h.tournamentLookup.GetByID(ctx, tournamentID)
+	if err != nil {
+		h.log.LogError("Failed to get tournament for round lock", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		return domain.RoundLockGame
+	}
+
+	return tournament.RoundLock()
+}
+
+// checkRoundLock проверяет, не блокирует ли идущий раунд загрузку программы для игры
+func (h *ProgramHandler) checkRoundLock(ctx context.Context, tournamentID, gameID uuid.UUID) error {
+	if h.matchChecker == nil {
+		return nil
+	}
+
+	if h.roundLockFor(ctx, tournamentID) == domain.RoundLockTournament {
+		hasRunning, err := h.matchChecker.HasAnyRunningMatches(ctx, tournamentID)
+		if err != nil {
+			h.log.LogError("Failed to check running matches", err)
+			return errors.ErrInternal.WithMessage("failed to verify match status")
+		}
+		if !hasRunning {
+			return nil
+		}
+
+		// Получаем название активной игры для информативного сообщения
+		activeGame, _ := h.matchChecker.GetActiveGameType(ctx, tournamentID)
+		h.log.Info("Upload blocked: matches running in tournament",
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("game_id", gameID.String()),
+			zap.String("active_game", activeGame),
+		)
+		if activeGame != "" {
+			return errors.ErrForbidden.WithMessage(fmt.Sprintf("загрузка программ запрещена: выполняется раунд игры '%s'", activeGame))
+		}
+		return errors.ErrForbidden.WithMessage("загрузка программ запрещена: выполняется раунд")
+	}
+
+	game, err := h.gameLookup.GetByID(ctx, gameID)
+	if err != nil {
+		h.log.LogError("Failed to get game for round lock", err,
+			zap.String("game_id", gameID.String()),
+		)
+		return err
+	}
+
+	roundActive := false
+	if h.roundChecker != nil {
+		roundActive, err = h.roundChecker.IsRoundActive(ctx, tournamentID, gameID)
+		if err != nil {
+			h.log.LogError("Failed to check active round", err)
+			return errors.ErrInternal.WithMessage("failed to verify match status")
+		}
+	}
+
+	hasRunning, err := h.matchChecker.HasRunningMatchesForGame(ctx, tournamentID, game.Name)
+	if err != nil {
+		h.log.LogError("Failed to check running matches", err)
+		return errors.ErrInternal.WithMessage("failed to verify match status")
+	}
+
+	if !roundActive && !hasRunning {
+		return nil
+	}
+
+	h.log.Info("Upload blocked: game round in progress",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.String("game_id", gameID.String()),
+		zap.Bool("round_active", roundActive),
+	)
+	return errors.ErrForbidden.WithMessage(fmt.Sprintf("загрузка программ запрещена: выполняется раунд игры '%s'", game.Name))
+}
+
 // Create обрабатывает создание программы (с загрузкой файла)
 // POST /api/v1/programs
 func (h *ProgramHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -180,54 +523,112 @@ func (h *ProgramHandler) Create(w http.ResponseWriter, r *http.Request) {
 	h.handleJSONCreate(w, r, userID)
 }
 
-// handleFileUpload обрабатывает загрузку файла
-func (h *ProgramHandler) handleFileUpload(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
-	// Ограничиваем размер файла
-	r.Body = http.MaxBytesReader(w, r.Body, h.maxFileSize)
-
-	// Парсим multipart form
-	if err := r.ParseMultipartForm(h.maxFileSize); err != nil {
-		h.log.Info("Failed to parse multipart form", zap.Error(err))
-		writeError(w, errors.ErrInvalidInput.WithMessage("file too large or invalid form"))
-		return
+// checkTeamUpload учитывает загрузку в лимите команды. При превышении ставит Retry-After
+// и возвращает 429. Админы не ограничены; при ошибке лимитера загрузка пропускается
+func (h *ProgramHandler) checkTeamUpload(w http.ResponseWriter, r *http.Request, teamID uuid.UUID) error {
+	if h.uploadLimiter == nil {
+		return nil
+	}
+	if role, _ := r.Context().Value(middleware.RoleKey).(domain.Role); role == domain.RoleAdmin {
+		return nil
 	}
 
-	// Получаем файл
-	file, header, err := r.FormFile("file")
+	key := teamUploadKey(teamID)
+	allowed, err := h.uploadLimiter.Allow(r.Context(), key)
 	if err != nil {
-		h.log.Info("Failed to get file from form", zap.Error(err))
-		writeError(w, errors.ErrInvalidInput.WithMessage("file is required"))
-		return
+		h.log.LogError("Team upload rate limit check failed", err,
+			zap.String("team_id", teamID.String()),
+		)
+		return nil
+	}
+	if allowed {
+		return nil
 	}
-	defer file.Close()
 
-	// Получаем остальные поля
-	teamIDStr := r.FormValue("team_id")
-	tournamentIDStr := r.FormValue("tournament_id")
-	gameIDStr := r.FormValue("game_id")
-	name := r.FormValue("name")
+	seconds := int(math.Ceil(h.uploadLimiter.RetryAfter(r.Context(), key).Seconds()))
+	h.log.Info("Upload blocked: team rate limit",
+		zap.String("team_id", teamID.String()),
+		zap.Int("retry_after", seconds),
+	)
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	return errors.ErrRateLimitExceeded.WithMessage(fmt.Sprintf(
+		"превышен лимит загрузок команды: %d за %s, повторите через %d с", h.uploadLimiter.limit, h.uploadLimiter.window, seconds))
+}
 
-	// Валидация обязательных полей
-	if teamIDStr == "" || tournamentIDStr == "" || gameIDStr == "" {
-		writeError(w, errors.ErrInvalidInput.WithMessage("team_id, tournament_id and game_id are required"))
-		return
+// checkTeamBan запрещает загрузку команде, забаненной в турнире:
+// иначе новая версия программы снова попала бы в участники
+func (h *ProgramHandler) checkTeamBan(ctx context.Context, tournamentID, teamID uuid.UUID) error {
+	if h.tournamentRepo == nil {
+		return nil
 	}
 
-	teamID, err := uuid.Parse(teamIDStr)
+	banned, err := h.tournamentRepo.IsTeamBanned(ctx, tournamentID, teamID)
 	if err != nil {
-		writeError(w, errors.ErrInvalidInput.WithMessage("invalid team_id"))
-		return
+		h.log.LogError("Failed to check team tournament ban", err,
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("team_id", teamID.String()),
+		)
+		return errors.ErrInternal.WithMessage("failed to check tournament ban")
+	}
+	if banned {
+		h.log.Info("Upload blocked: team is banned",
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("team_id", teamID.String()),
+		)
+		return errors.ErrForbidden.WithMessage("team is banned from this tournament")
 	}
 
-	tournamentID, err := uuid.Parse(tournamentIDStr)
+	return nil
+}
+
+// parseUploadTarget разбирает обязательные поля загрузки: команду, турнир и игру.
+// Они должны идти в форме до файла
+func parseUploadTarget(form *uploadForm) (teamID, tournamentID, gameID uuid.UUID, err error) {
+	if form.value("team_id") == "" || form.value("tournament_id") == "" || form.value("game_id") == "" {
+		return teamID, tournamentID, gameID, errors.ErrInvalidInput.WithMessage("team_id, tournament_id and game_id are required before file")
+	}
+
+	if teamID, err = uuid.Parse(form.value("team_id")); err != nil {
+		return teamID, tournamentID, gameID, errors.ErrInvalidInput.WithMessage("invalid team_id")
+	}
+	if tournamentID, err = uuid.Parse(form.value("tournament_id")); err != nil {
+		return teamID, tournamentID, gameID, errors.ErrInvalidInput.WithMessage("invalid tournament_id")
+	}
+	if gameID, err = uuid.Parse(form.value("game_id")); err != nil {
+		return teamID, tournamentID, gameID, errors.ErrInvalidInput.WithMessage("invalid game_id")
+	}
+	return teamID, tournamentID, gameID, nil
+}
+
+// handleFileUpload обрабатывает загрузку файла
+func (h *ProgramHandler) handleFileUpload(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	// Файл пишется на диск потоком, без буферизации всей формы.
+	// Бан команды и лимит её загрузок проверяются по полям до файла - до записи файла на диск
+	var teamID, tournamentID, gameID uuid.UUID
+	form, err := h.readUploadForm(w, r, func(form *uploadForm) error {
+		var err error
+		if teamID, tournamentID, gameID, err = parseUploadTarget(form); err != nil {
+			return err
+		}
+		if err := h.checkTeamBan(r.Context(), tournamentID, teamID); err != nil {
+			return err
+		}
+		return h.checkTeamUpload(w, r, teamID)
+	})
 	if err != nil {
-		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament_id"))
+		writeError(w, err)
 		return
 	}
+	defer form.discard()
 
-	gameID, err := uuid.Parse(gameIDStr)
-	if err != nil {
-		writeError(w, errors.ErrInvalidInput.WithMessage("invalid game_id"))
+	name := form.value("name")
+
+	// Язык определён по расширению при записи файла.
+	// Без явной версии программа запускается на последней доступной версии языка
+	language := form.language
+	languageVersion, ok := h.languageVersions.Resolve(language, form.value("language_version"))
+	if !ok {
+		writeError(w, errors.ErrInvalidInput.WithMessage(fmt.Sprintf("unsupported language_version %q for %s", form.value("language_version"), language)))
 		return
 	}
 
@@ -250,41 +651,81 @@ func (h *ProgramHandler) handleFileUpload(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	// Проверяем, не выполняются ли матчи для ЛЮБОЙ игры в турнире
-	// Пока идёт раунд одной игры, загрузка программ для всех игр заблокирована
-	if h.matchChecker != nil {
-		hasRunning, err := h.matchChecker.HasAnyRunningMatches(r.Context(), tournamentID)
+	// Пока идёт раунд игры, загрузка программ для неё заблокирована.
+	// В режиме round_lock=tournament раунд любой игры блокирует загрузку для всех игр
+	if err := h.checkRoundLock(r.Context(), tournamentID, gameID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Если имя не указано, используем имя файла
+	if name == "" {
+		name = form.fileName
+	}
+
+	contentHash := form.contentHash
+
+	// Повторная загрузка того же файла не создаёт новую версию (и новые матчи),
+	// если только команда явно не попросила force=true
+	if r.URL.Query().Get("force") != "true" {
+		latest, err := h.programRepo.GetLatestByTeamAndGame(r.Context(), teamID, gameID)
+		if err != nil && !errors.IsNotFound(err) {
+			h.log.LogError("Failed to get latest program version", err,
+				zap.String("team_id", teamID.String()),
+				zap.String("game_id", gameID.String()),
+			)
+			// Продолжаем: проверка дубликатов не должна блокировать загрузку
+		} else if latest != nil && latest.ContentHash != nil && *latest.ContentHash == contentHash &&
+			latest.LanguageVersion == languageVersion {
+			h.log.Info("Duplicate upload skipped",
+				zap.String("program_id", latest.ID.String()),
+				zap.String("team_id", teamID.String()),
+				zap.Int("version", latest.Version),
+			)
+			writeJSON(w, http.StatusOK, duplicateUploadResponse{Program: latest, Duplicate: true})
+			return
+		}
+	}
+
+	// Ограничиваем частоту загрузок: каждая версия создаёт новые матчи в очереди
+	// Админы не ограничены
+	if role, _ := r.Context().Value(middleware.RoleKey).(domain.Role); role != domain.RoleAdmin {
+		retryAfter, err := h.checkUploadCooldown(r.Context(), tournamentID, teamID, gameID)
 		if err != nil {
-			h.log.LogError("Failed to check running matches", err)
-			writeError(w, errors.ErrInternal.WithMessage("failed to verify match status"))
+			h.log.LogError("Failed to check upload cooldown", err,
+				zap.String("team_id", teamID.String()),
+				zap.String("game_id", gameID.String()),
+			)
+			writeError(w, errors.ErrInternal.WithMessage("failed to verify upload cooldown"))
 			return
 		}
 
-		if hasRunning {
-			// Получаем название активной игры для информативного сообщения
-			activeGame, _ := h.matchChecker.GetActiveGameType(r.Context(), tournamentID)
-			h.log.Info("Upload blocked: matches running for another game",
-				zap.String("tournament_id", tournamentID.String()),
+		if retryAfter > 0 {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			h.log.Info("Upload blocked: cooldown",
+				zap.String("team_id", teamID.String()),
 				zap.String("game_id", gameID.String()),
-				zap.String("active_game", activeGame),
+				zap.Int("retry_after", seconds),
 			)
-			if activeGame != "" {
-				writeError(w, errors.ErrForbidden.WithMessage(fmt.Sprintf("загрузка программ запрещена: выполняется раунд игры '%s'", activeGame)))
-			} else {
-				writeError(w, errors.ErrForbidden.WithMessage("загрузка программ запрещена: выполняется раунд"))
-			}
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			writeError(w, errors.ErrRateLimitExceeded.WithMessage(fmt.Sprintf("новую версию программы можно загрузить через %d с", seconds)))
 			return
 		}
 	}
 
-	// Если имя не указано, используем имя файла
-	if name == "" {
-		name = header.Filename
+	// Лимит версий команды для игры: место освобождается до переноса файла
+	quota, err := h.enforceVersionQuota(r.Context(), teamID, gameID)
+	if err != nil {
+		if quota == nil {
+			h.log.LogError("Failed to check program version quota", err,
+				zap.String("team_id", teamID.String()),
+				zap.String("game_id", gameID.String()),
+			)
+		}
+		writeError(w, err)
+		return
 	}
 
-	// Определяем язык по расширению
-	language := detectLanguage(header.Filename)
-
 	// Получаем последнюю версию программы для этой команды и игры
 	version := 1
 	if latestVersion, err := h.programRepo.GetLatestVersion(r.Context(), teamID, gameID); err == nil {
@@ -293,51 +734,17 @@ func (h *ProgramHandler) handleFileUpload(w http.ResponseWriter, r *http.Request
 
 	// Создаём уникальный путь для файла
 	programID := uuid.New()
-	ext := filepath.Ext(header.Filename)
+	ext := filepath.Ext(form.fileName)
 	fileName := fmt.Sprintf("%s_%s_%s_v%d%s", teamID.String()[:8], gameID.String()[:8], programID.String()[:8], version, ext)
 	filePath := filepath.Join(h.uploadDir, fileName)
 
-	// Сохраняем файл
-	dst, err := os.Create(filePath)
-	if err != nil {
-		h.log.Error("Failed to create file", zap.Error(err), zap.String("path", filePath))
-		writeError(w, errors.ErrInternal.WithMessage("failed to save file"))
-		return
-	}
-	defer dst.Close()
-
-	// Добавляем shebang для интерпретируемых языков (если его нет)
-	shebang := getShebang(language)
-	if shebang != "" {
-		// Читаем первые байты чтобы проверить наличие shebang
-		firstBytes := make([]byte, 2)
-		n, _ := file.Read(firstBytes)
-		_, _ = file.Seek(0, 0) // Возвращаемся в начало
-
-		// Если файл не начинается с #!, добавляем shebang
-		if n < 2 || string(firstBytes) != "#!" {
-			if _, err := dst.WriteString(shebang); err != nil {
-				h.log.Error("Failed to write shebang", zap.Error(err))
-				os.Remove(filePath)
-				writeError(w, errors.ErrInternal.WithMessage("failed to save file"))
-				return
-			}
-		}
-	}
-
-	if _, err := io.Copy(dst, file); err != nil {
-		h.log.Error("Failed to write file", zap.Error(err))
-		// Удаляем частично записанный файл
-		os.Remove(filePath)
+	// Переносим загруженный файл на место
+	if err := form.keep(filePath); err != nil {
+		h.log.Error("Failed to save file", zap.Error(err), zap.String("path", filePath))
 		writeError(w, errors.ErrInternal.WithMessage("failed to save file"))
 		return
 	}
 
-	// Делаем файл исполняемым
-	if err := os.Chmod(filePath, 0755); err != nil {
-		h.log.Warn("Failed to make file executable", zap.Error(err), zap.String("path", filePath))
-	}
-
 	// Проверяем синтаксис для всех поддерживаемых языков
 	var syntaxError *string
 	if errMsg := validateSyntax(language, filePath); errMsg != "" {
@@ -363,6 +770,9 @@ func (h *ProgramHandler) handleFileUpload(w http.ResponseWriter, r *http.Request
 		Language:     language,
 		ErrorMessage: syntaxError,
 		Version:      version,
+		ContentHash:  &contentHash,
+
+		LanguageVersion: languageVersion,
 	}
 
 	if err := h.programRepo.Create(r.Context(), program); err != nil {
@@ -397,19 +807,48 @@ func (h *ProgramHandler) handleFileUpload(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	// ВАЖНО: Матчи НЕ создаются автоматически при загрузке программы!
+	// ВАЖНО: Соревновательные матчи НЕ создаются автоматически при загрузке программы!
 	// Администратор должен вручную запустить матчи через кнопку "Run All Matches"
 	// POST /api/v1/tournaments/{id}/run-matches
+	// Исключение - калибровочные матчи с эталонными ботами, если они включены в турнире
+	if h.matchScheduler != nil && syntaxError == nil {
+		if err := h.matchScheduler.ScheduleCalibrationMatches(r.Context(), tournamentID, gameID, programID); err != nil {
+			h.log.LogError("Failed to schedule calibration matches", err,
+				zap.String("program_id", programID.String()),
+				zap.String("tournament_id", tournamentID.String()),
+			)
+			// Не возвращаем ошибку - программа уже загружена
+		}
+	}
+
+	// Проверочный матч с эталонным ботом игры. Ответ не ждёт матча:
+	// итог появится в validation_status программы (GET /api/v1/programs/{id})
+	if h.matchScheduler != nil && syntaxError == nil {
+		match, err := h.matchScheduler.ScheduleValidationMatch(r.Context(), tournamentID, gameID, programID)
+		if err != nil {
+			h.log.LogError("Failed to schedule validation match", err,
+				zap.String("program_id", programID.String()),
+				zap.String("game_id", gameID.String()),
+			)
+			// Не возвращаем ошибку - программа уже загружена
+		} else if match != nil {
+			program.ValidationStatus = domain.ValidationPending
+		}
+	}
 
 	h.log.Info("Program uploaded",
 		zap.String("program_id", program.ID.String()),
 		zap.String("user_id", userID.String()),
 		zap.String("team_id", teamID.String()),
-		zap.String("file", header.Filename),
+		zap.String("file", form.fileName),
 		zap.Int("version", version),
 	)
 
-	writeJSON(w, http.StatusCreated, program)
+	if quota != nil {
+		quota.Versions++
+		quota.Remaining = quota.MaxVersions - quota.Versions
+	}
+	writeJSON(w, http.StatusCreated, uploadResponse{Program: program, Quota: quota})
 }
 
 // handleJSONCreate обрабатывает JSON запрос (обратная совместимость)
@@ -457,8 +896,8 @@ func (h *ProgramHandler) handleJSONCreate(w http.ResponseWriter, r *http.Request
 	writeJSON(w, http.StatusCreated, program)
 }
 
-// List обрабатывает получение списка программ текущего пользователя
-// GET /api/v1/programs
+// List обрабатывает получение списка программ текущего пользователя (сначала новые)
+// GET /api/v1/programs?tournament_id=&game_id=&language=&limit=&offset=
 func (h *ProgramHandler) List(w http.ResponseWriter, r *http.Request) {
 	userID, err := middleware.RequireUserID(r.Context())
 	if err != nil {
@@ -466,7 +905,13 @@ func (h *ProgramHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	programs, err := h.programRepo.GetByUserID(r.Context(), userID)
+	filter, err := parseProgramFilter(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	programs, err := h.programRepo.ListByUserFiltered(r.Context(), userID, filter)
 	if err != nil {
 		h.log.LogError("Failed to get programs", err,
 			zap.String("user_id", userID.String()),
@@ -478,6 +923,49 @@ func (h *ProgramHandler) List(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, programs)
 }
 
+// parseProgramFilter читает фильтры и пагинацию списка программ
+func parseProgramFilter(r *http.Request) (domain.ProgramFilter, error) {
+	query := r.URL.Query()
+	filter := domain.ProgramFilter{
+		Language: query.Get("language"),
+		Limit:    defaultProgramsLimit,
+	}
+
+	if raw := query.Get("tournament_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, errors.ErrInvalidInput.WithMessage("invalid tournament_id")
+		}
+		filter.TournamentID = &id
+	}
+
+	if raw := query.Get("game_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, errors.ErrInvalidInput.WithMessage("invalid game_id")
+		}
+		filter.GameID = &id
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxProgramsLimit {
+			return filter, errors.ErrInvalidInput.WithMessage(fmt.Sprintf("limit must be between 1 and %d", maxProgramsLimit))
+		}
+		filter.Limit = limit
+	}
+
+	if raw := query.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return filter, errors.ErrInvalidInput.WithMessage("offset must be non-negative")
+		}
+		filter.Offset = offset
+	}
+
+	return filter, nil
+}
+
 // Get обрабатывает получение программы
 // GET /api/v1/programs/:id
 func (h *ProgramHandler) Get(w http.ResponseWriter, r *http.Request) {
@@ -500,6 +988,82 @@ func (h *ProgramHandler) Get(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, program)
 }
 
+// programRankResponse место программы в таблице лидеров турнира (0 - программа не участвует)
+type programRankResponse struct {
+	ProgramID    uuid.UUID `json:"program_id"`
+	TournamentID uuid.UUID `json:"tournament_id"`
+	Rank         int64     `json:"rank"`
+}
+
+// GetRank обрабатывает получение места программы в таблице лидеров без загрузки всей таблицы
+// GET /api/v1/programs/:id/rank?tournament_id=
+func (h *ProgramHandler) GetRank(w http.ResponseWriter, r *http.Request) {
+	if h.rankLookup == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("rank lookup is not available"))
+		return
+	}
+
+	programID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid program ID"))
+		return
+	}
+
+	tournamentID, err := uuid.Parse(r.URL.Query().Get("tournament_id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament_id"))
+		return
+	}
+
+	rank, err := h.rankLookup.GetRank(r.Context(), tournamentID, programID)
+	if err != nil {
+		h.log.LogError("Failed to get program rank", err,
+			zap.String("program_id", programID.String()),
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, programRankResponse{
+		ProgramID:    programID,
+		TournamentID: tournamentID,
+		Rank:         rank,
+	})
+}
+
+// GetStats обрабатывает получение сводной статистики матчей программы
+// GET /api/v1/programs/:id/stats
+func (h *ProgramHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	if h.statsLookup == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("program stats are not available"))
+		return
+	}
+
+	programID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid program ID"))
+		return
+	}
+
+	// Статистика несуществующей программы - 404, а не нули
+	if _, err := h.programRepo.GetByID(r.Context(), programID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	stats, err := h.statsLookup.GetProgramStats(r.Context(), programID)
+	if err != nil {
+		h.log.LogError("Failed to get program stats", err,
+			zap.String("program_id", programID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
 // Update обрабатывает обновление программы
 // PUT /api/v1/programs/:id
 func (h *ProgramHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -694,12 +1258,7 @@ func (h *ProgramHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Удаляем файл (если есть)
-	if program.FilePath != nil && *program.FilePath != "" {
-		if err := os.Remove(*program.FilePath); err != nil {
-			h.log.Warn("Failed to delete program file", zap.Error(err), zap.String("path", *program.FilePath))
-		}
-	}
+	h.removeProgramFile(program)
 
 	h.log.Info("Program deleted",
 		zap.String("program_id", id.String()),
@@ -800,6 +1359,12 @@ func (h *ProgramHandler) ClearProgramErrors(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// SupportedLanguages возвращает языки с выбором версии и их допустимые версии
+// GET /api/v1/executor/supported-languages
+func (h *ProgramHandler) SupportedLanguages(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.languageVersions.Supported())
+}
+
 // validatePythonSyntax проверяет синтаксис Python файла с помощью py_compile
 // Возвращает сообщение об ошибке или пустую строку, если синтаксис корректен
 func validatePythonSyntax(filePath string) string {
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// defaultProgramMatchesLimit размер страницы истории матчей программы по умолчанию
+const defaultProgramMatchesLimit = 20
+
+// SetMatchHistory устанавливает источник матчей и названий программ для истории матчей программы
+func (h *ProgramHandler) SetMatchHistory(matches MatchExportSource, programs ProgramInfoLookup) {
+	h.matchHistory = matches
+	h.programInfo = programs
+}
+
+// programMatchItem матч из истории программы с названиями обеих программ
+type programMatchItem struct {
+	*domain.Match
+	Program1Name string `json:"program1_name"`
+	Program2Name string `json:"program2_name"`
+}
+
+// GetMatches обрабатывает получение всех матчей, в которых участвовала программа (первой или второй).
+// Владелец программы и админ видят матчи в любом статусе, остальные - только завершённые
+// GET /api/v1/programs/:id/matches?status=&limit=&cursor=
+func (h *ProgramHandler) GetMatches(w http.ResponseWriter, r *http.Request) {
+	if h.matchHistory == nil || h.programInfo == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("program match history is not available"))
+		return
+	}
+
+	userID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	programID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid program ID"))
+		return
+	}
+
+	pageReq, err := parseProgramMatchesPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status := domain.MatchStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", domain.MatchPending, domain.MatchRunning, domain.MatchCompleted, domain.MatchFailed, domain.MatchCancelled:
+	default:
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid status"))
+		return
+	}
+
+	if _, err := h.programRepo.GetByID(r.Context(), programID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if role, _ := r.Context().Value(middleware.RoleKey).(domain.Role); role != domain.RoleAdmin {
+		isOwner, err := h.programRepo.CheckOwnership(r.Context(), programID, userID)
+		if err != nil {
+			h.log.LogError("Failed to check ownership", err)
+			writeError(w, err)
+			return
+		}
+		if !isOwner {
+			if status != "" && status != domain.MatchCompleted {
+				writeError(w, errors.ErrForbidden.WithMessage("only completed matches of other programs are visible"))
+				return
+			}
+			status = domain.MatchCompleted
+		}
+	}
+
+	matches, hasMore, err := h.matchHistory.ListWithCursor(r.Context(), domain.MatchFilter{
+		ProgramID: &programID,
+		Status:    status,
+	}, pageReq)
+	if err != nil {
+		h.log.LogError("Failed to get program matches", err,
+			zap.String("program_id", programID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	items, err := h.withProgramNames(r, matches)
+	if err != nil {
+		h.log.LogError("Failed to get program names", err,
+			zap.String("program_id", programID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pagination.NewKeysetPage(items, func(item programMatchItem) pagination.KeysetCursor {
+		return db.MatchKeysetCursor(item.Match)
+	}, pageReq, hasMore))
+}
+
+// withProgramNames дополняет матчи названиями программ одним запросом на страницу
+func (h *ProgramHandler) withProgramNames(r *http.Request, matches []*domain.Match) ([]programMatchItem, error) {
+	items := make([]programMatchItem, 0, len(matches))
+	if len(matches) == 0 {
+		return items, nil
+	}
+
+	seen := make(map[uuid.UUID]struct{}, len(matches)+1)
+	ids := make([]uuid.UUID, 0, len(matches)+1)
+	for _, m := range matches {
+		for _, id := range []uuid.UUID{m.Program1ID, m.Program2ID} {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	infos, err := h.programInfo.GetInfoByIDs(r.Context(), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	name := func(id uuid.UUID) string {
+		if info, ok := infos[id]; ok {
+			return info.ProgramName
+		}
+		return ""
+	}
+	for _, m := range matches {
+		items = append(items, programMatchItem{
+			Match:        m,
+			Program1Name: name(m.Program1ID),
+			Program2Name: name(m.Program2ID),
+		})
+	}
+
+	return items, nil
+}
+
+// parseProgramMatchesPage читает limit и cursor истории матчей программы
+func parseProgramMatchesPage(r *http.Request) (*pagination.PageRequest, error) {
+	query := r.URL.Query()
+
+	limit := defaultProgramMatchesLimit
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.ErrInvalidInput.WithMessage("invalid limit")
+		}
+		limit = n
+	}
+
+	pageReq := &pagination.PageRequest{First: &limit}
+	if cursor := query.Get("cursor"); cursor != "" {
+		pageReq.After = &cursor
+	}
+
+	if err := pageReq.Validate(); err != nil {
+		return nil, errors.ErrInvalidInput.WithMessage(err.Error())
+	}
+	if _, err := pageReq.GetKeysetCursor(); err != nil {
+		return nil, errors.ErrInvalidInput.WithMessage("invalid cursor")
+	}
+
+	return pageReq, nil
+}
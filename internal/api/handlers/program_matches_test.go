@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgramHandler_GetMatches(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	programID, opponentID := uuid.New(), uuid.New()
+	ownerID := uuid.New()
+
+	// The program plays on both sides
+	matches := []*domain.Match{
+		{ID: uuid.New(), Program1ID: programID, Program2ID: opponentID, Status: domain.MatchCompleted, RoundNumber: 2},
+		{ID: uuid.New(), Program1ID: opponentID, Program2ID: programID, Status: domain.MatchCompleted, RoundNumber: 1},
+	}
+	infos := map[uuid.UUID]*domain.ProgramInfo{
+		programID:  {ProgramID: programID, ProgramName: "mine"},
+		opponentID: {ProgramID: opponentID, ProgramName: "theirs"},
+	}
+
+	newRequest := func(query string, userID uuid.UUID, role domain.Role) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/programs/"+programID.String()+"/matches?"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", programID.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+		ctx = context.WithValue(ctx, middleware.RoleKey, role)
+		return req.WithContext(ctx)
+	}
+
+	setup := func() (*ProgramHandler, *MockProgramRepository, *MockMatchExportSource, *MockProgramInfoLookup) {
+		repo := new(MockProgramRepository)
+		source := new(MockMatchExportSource)
+		programs := new(MockProgramInfoLookup)
+		repo.On("GetByID", mock.Anything, programID).Return(&domain.Program{ID: programID}, nil)
+		repo.On("CheckOwnership", mock.Anything, programID, ownerID).Return(true, nil).Maybe()
+		repo.On("CheckOwnership", mock.Anything, programID, mock.Anything).Return(false, nil).Maybe()
+
+		handler := NewProgramHandler(repo, nil, nil, nil, log)
+		handler.SetMatchHistory(source, programs)
+		return handler, repo, source, programs
+	}
+
+	t.Run("owner sees matches on both sides with program names", func(t *testing.T) {
+		handler, _, source, programs := setup()
+		source.On("ListWithCursor", mock.Anything, mock.MatchedBy(func(f domain.MatchFilter) bool {
+			return *f.ProgramID == programID && f.Status == "" && f.TournamentID == nil
+		}), mock.MatchedBy(func(p *pagination.PageRequest) bool {
+			return *p.First == 2 && p.After == nil
+		})).Return(matches, true, nil).Once()
+		programs.On("GetInfoByIDs", mock.Anything, []uuid.UUID{programID, opponentID}).Return(infos, nil).Once()
+
+		w := httptest.NewRecorder()
+		handler.GetMatches(w, newRequest("limit=2", ownerID, domain.RoleUser))
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var page struct {
+			Items []struct {
+				ID           uuid.UUID `json:"id"`
+				Program1Name string    `json:"program1_name"`
+				Program2Name string    `json:"program2_name"`
+			} `json:"items"`
+			PageInfo pagination.PageInfo `json:"page_info"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		require.Len(t, page.Items, 2)
+		assert.Equal(t, "mine", page.Items[0].Program1Name)
+		assert.Equal(t, "theirs", page.Items[0].Program2Name)
+		assert.Equal(t, "theirs", page.Items[1].Program1Name)
+		assert.Equal(t, "mine", page.Items[1].Program2Name)
+		assert.True(t, page.PageInfo.HasNextPage)
+		require.NotNil(t, page.PageInfo.EndCursor)
+		assert.Equal(t, db.GetMatchKeysetCursor(matches[1]), *page.PageInfo.EndCursor)
+		source.AssertExpectations(t)
+	})
+
+	t.Run("cursor continues from the previous page", func(t *testing.T) {
+		handler, _, source, programs := setup()
+		cursor := db.GetMatchKeysetCursor(matches[0])
+		source.On("ListWithCursor", mock.Anything, mock.Anything, mock.MatchedBy(func(p *pagination.PageRequest) bool {
+			return p.After != nil && *p.After == cursor && *p.First == defaultProgramMatchesLimit
+		})).Return(matches[1:], false, nil).Once()
+		programs.On("GetInfoByIDs", mock.Anything, mock.Anything).Return(infos, nil).Once()
+
+		w := httptest.NewRecorder()
+		handler.GetMatches(w, newRequest("cursor="+cursor, ownerID, domain.RoleUser))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		source.AssertExpectations(t)
+	})
+
+	t.Run("other users see only completed matches", func(t *testing.T) {
+		handler, _, source, programs := setup()
+		source.On("ListWithCursor", mock.Anything, mock.MatchedBy(func(f domain.MatchFilter) bool {
+			return f.Status == domain.MatchCompleted
+		}), mock.Anything).Return([]*domain.Match{}, false, nil).Once()
+
+		w := httptest.NewRecorder()
+		handler.GetMatches(w, newRequest("", uuid.New(), domain.RoleUser))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		source.AssertExpectations(t)
+		programs.AssertNotCalled(t, "GetInfoByIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("other users cannot request unfinished matches", func(t *testing.T) {
+		handler, _, source, _ := setup()
+
+		w := httptest.NewRecorder()
+		handler.GetMatches(w, newRequest("status=failed", uuid.New(), domain.RoleUser))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		source.AssertNotCalled(t, "ListWithCursor", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("admin filters by any status", func(t *testing.T) {
+		handler, repo, source, _ := setup()
+		source.On("ListWithCursor", mock.Anything, mock.MatchedBy(func(f domain.MatchFilter) bool {
+			return f.Status == domain.MatchFailed
+		}), mock.Anything).Return([]*domain.Match{}, false, nil).Once()
+
+		w := httptest.NewRecorder()
+		handler.GetMatches(w, newRequest("status=failed", uuid.New(), domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		repo.AssertNotCalled(t, "CheckOwnership", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"status=unknown", "limit=0", "limit=abc", "limit=1000", "cursor=not-a-cursor"} {
+			handler, _, _, _ := setup()
+
+			w := httptest.NewRecorder()
+			handler.GetMatches(w, newRequest(query, ownerID, domain.RoleUser))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
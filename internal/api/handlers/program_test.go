@@ -3,10 +3,19 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
@@ -37,8 +46,8 @@ func (m *MockProgramRepository) GetByID(ctx context.Context, id uuid.UUID) (*dom
 	return args.Get(0).(*domain.Program), args.Error(1)
 }
 
-func (m *MockProgramRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Program, error) {
-	args := m.Called(ctx, userID)
+func (m *MockProgramRepository) ListByUserFiltered(ctx context.Context, userID uuid.UUID, filter domain.ProgramFilter) ([]*domain.Program, error) {
+	args := m.Called(ctx, userID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -65,6 +74,22 @@ func (m *MockProgramRepository) GetLatestVersion(ctx context.Context, teamID, ga
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockProgramRepository) GetLatestByTeamAndGame(ctx context.Context, teamID, gameID uuid.UUID) (*domain.Program, error) {
+	args := m.Called(ctx, teamID, gameID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Program), args.Error(1)
+}
+
+func (m *MockProgramRepository) GetLatestVersionCreatedAt(ctx context.Context, teamID, gameID uuid.UUID) (*time.Time, error) {
+	args := m.Called(ctx, teamID, gameID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
 func (m *MockProgramRepository) GetAllVersionsByTeamAndGame(ctx context.Context, teamID, gameID uuid.UUID) ([]*domain.Program, error) {
 	args := m.Called(ctx, teamID, gameID)
 	if args.Get(0) == nil {
@@ -73,6 +98,11 @@ func (m *MockProgramRepository) GetAllVersionsByTeamAndGame(ctx context.Context,
 	return args.Get(0).([]*domain.Program), args.Error(1)
 }
 
+func (m *MockProgramRepository) DeleteIfUnused(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockProgramRepository) ClearErrorMessages(ctx context.Context, tournamentID uuid.UUID) (int64, error) {
 	args := m.Called(ctx, tournamentID)
 	return args.Get(0).(int64), args.Error(1)
@@ -83,7 +113,7 @@ func TestProgramHandler_Create(t *testing.T) {
 
 	t.Run("successfully create program", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		userID := uuid.New()
 		reqBody := map[string]string{
@@ -122,7 +152,7 @@ func TestProgramHandler_Create(t *testing.T) {
 
 	t.Run("missing user ID in context", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		reqBody := map[string]string{
 			"name":      "My Chess AI",
@@ -144,7 +174,7 @@ func TestProgramHandler_Create(t *testing.T) {
 
 	t.Run("invalid request body", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		userID := uuid.New()
 
@@ -163,7 +193,7 @@ func TestProgramHandler_Create(t *testing.T) {
 
 	t.Run("validation error - empty name", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		userID := uuid.New()
 		reqBody := map[string]string{
@@ -188,12 +218,604 @@ func TestProgramHandler_Create(t *testing.T) {
 	})
 }
 
+// newUploadRequest builds a multipart program upload request
+func newUploadRequest(t *testing.T, userID, teamID, tournamentID, gameID uuid.UUID, role domain.Role) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	require.NoError(t, writer.WriteField("team_id", teamID.String()))
+	require.NoError(t, writer.WriteField("tournament_id", tournamentID.String()))
+	require.NoError(t, writer.WriteField("game_id", gameID.String()))
+	part, err := writer.CreateFormFile("file", "bot.bin")
+	require.NoError(t, err)
+	_, _ = part.Write([]byte("binary"))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/programs", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	ctx := context.WithValue(req.Context(), middleware.UserIDKey, userID)
+	ctx = context.WithValue(ctx, middleware.RoleKey, role)
+	return req.WithContext(ctx)
+}
+
+// banningTournamentRepo keeps participants and team bans in memory; a ban covers the team
+// of the banned program, like TournamentRepository.AddBan
+type banningTournamentRepo struct {
+	participants map[uuid.UUID]bool
+	bannedTeams  map[uuid.UUID]bool
+}
+
+func (r *banningTournamentRepo) AddParticipant(_ context.Context, participant *domain.TournamentParticipant) error {
+	r.participants[participant.ProgramID] = true
+	return nil
+}
+
+func (r *banningTournamentRepo) IsTeamBanned(_ context.Context, _, teamID uuid.UUID) (bool, error) {
+	return r.bannedTeams[teamID], nil
+}
+
+func (r *banningTournamentRepo) ban(program *domain.Program) {
+	delete(r.participants, program.ID)
+	r.bannedTeams[*program.TeamID] = true
+}
+
+func TestProgramHandler_BannedTeamUpload(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	dir := t.TempDir()
+	t.Setenv("PROGRAMS_PATH", dir)
+
+	userID := uuid.New()
+	teamID := uuid.New()
+	tournamentID := uuid.New()
+	gameID := uuid.New()
+
+	repo := &banningTournamentRepo{participants: map[uuid.UUID]bool{}, bannedTeams: map[uuid.UUID]bool{}}
+
+	var uploaded *domain.Program
+	mockRepo := new(MockProgramRepository)
+	mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(nil, errors.ErrProgramNotFound)
+	mockRepo.On("GetLatestVersionCreatedAt", mock.Anything, teamID, gameID).Return(nil, nil)
+	mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(0, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).
+		Run(func(args mock.Arguments) { uploaded = args.Get(1).(*domain.Program) }).
+		Return(nil).Once()
+
+	handler := NewProgramHandler(mockRepo, repo, nil, nil, log)
+
+	w := httptest.NewRecorder()
+	handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, uploaded)
+	assert.True(t, repo.participants[uploaded.ID])
+
+	repo.ban(uploaded)
+
+	// A new version from the banned team is refused before it is written or joins the tournament
+	w = httptest.NewRecorder()
+	handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, repo.participants)
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+
+	written, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, written, 1, "only the first upload is stored")
+}
+
+func TestProgramHandler_UploadCooldown(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	t.Setenv("PROGRAMS_PATH", t.TempDir())
+
+	userID := uuid.New()
+	teamID := uuid.New()
+	tournamentID := uuid.New()
+	gameID := uuid.New()
+
+	t.Run("rejects upload inside cooldown with retry-after", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(nil, errors.ErrProgramNotFound)
+
+		lastUpload := time.Now().Add(-time.Minute)
+		mockRepo.On("GetLatestVersionCreatedAt", mock.Anything, teamID, gameID).Return(&lastUpload, nil)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.InDelta(t, 240, retryAfter, 2)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("allows upload after cooldown", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(nil, errors.ErrProgramNotFound)
+
+		lastUpload := time.Now().Add(-6 * time.Minute)
+		mockRepo.On("GetLatestVersionCreatedAt", mock.Anything, teamID, gameID).Return(&lastUpload, nil)
+		mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(1, nil)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("admin is exempt", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(nil, errors.ErrProgramNotFound)
+
+		mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(1, nil)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockRepo.AssertNotCalled(t, "GetLatestVersionCreatedAt", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("tournament metadata overrides cooldown", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		mockTournaments := new(MockTournamentService)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		handler.SetUploadCooldown(5*time.Minute, mockTournaments)
+		mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(nil, errors.ErrProgramNotFound)
+
+		mockTournaments.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{
+			ID:       tournamentID,
+			Metadata: map[string]interface{}{domain.MetaUploadCooldownSeconds: float64(30)},
+		}, nil)
+		lastUpload := time.Now().Add(-time.Minute)
+		mockRepo.On("GetLatestVersionCreatedAt", mock.Anything, teamID, gameID).Return(&lastUpload, nil)
+		mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(1, nil)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+}
+
+func TestProgramHandler_VersionQuota(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	dir := t.TempDir()
+	t.Setenv("PROGRAMS_PATH", dir)
+
+	userID := uuid.New()
+	teamID := uuid.New()
+	tournamentID := uuid.New()
+	gameID := uuid.New()
+
+	// Three versions, newest first as returned by the repository
+	versions := func() []*domain.Program {
+		list := make([]*domain.Program, 3)
+		for i := range list {
+			path := filepath.Join(dir, fmt.Sprintf("v%d.bin", 3-i))
+			require.NoError(t, os.WriteFile(path, []byte("bot"), 0644))
+			list[i] = &domain.Program{ID: uuid.New(), Version: 3 - i, FilePath: &path}
+		}
+		return list
+	}
+
+	newRepo := func(existing []*domain.Program) *MockProgramRepository {
+		mockRepo := new(MockProgramRepository)
+		mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(nil, errors.ErrProgramNotFound)
+		mockRepo.On("GetAllVersionsByTeamAndGame", mock.Anything, teamID, gameID).Return(existing, nil)
+		return mockRepo
+	}
+
+	t.Run("rejects upload when quota is reached", func(t *testing.T) {
+		mockRepo := newRepo(versions())
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		handler.SetVersionQuota(3, false)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "лимит версий")
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "DeleteIfUnused", mock.Anything, mock.Anything)
+	})
+
+	t.Run("prunes the oldest unused version", func(t *testing.T) {
+		existing := versions()
+		mockRepo := newRepo(existing)
+		// The oldest version has matches, the next one is free
+		mockRepo.On("DeleteIfUnused", mock.Anything, existing[2].ID).Return(false, nil)
+		mockRepo.On("DeleteIfUnused", mock.Anything, existing[1].ID).Return(true, nil)
+		mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(3, nil)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		handler.SetVersionQuota(3, true)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleAdmin))
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		var response struct {
+			Version int          `json:"version"`
+			Quota   versionQuota `json:"quota"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, 4, response.Version)
+		assert.Equal(t, versionQuota{MaxVersions: 3, Versions: 3, Remaining: 0, PrunedVersions: []int{2}}, response.Quota)
+
+		assert.NoFileExists(t, *existing[1].FilePath)
+		assert.FileExists(t, *existing[2].FilePath)
+		mockRepo.AssertNotCalled(t, "DeleteIfUnused", mock.Anything, existing[0].ID)
+	})
+
+	t.Run("rejects when nothing can be pruned", func(t *testing.T) {
+		existing := versions()
+		mockRepo := newRepo(existing)
+		mockRepo.On("DeleteIfUnused", mock.Anything, mock.Anything).Return(false, nil)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		handler.SetVersionQuota(3, true)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockRepo.AssertNumberOfCalls(t, "DeleteIfUnused", 2)
+	})
+
+	t.Run("reports remaining slots", func(t *testing.T) {
+		mockRepo := newRepo(versions()[:1])
+		mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(1, nil)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		handler.SetVersionQuota(3, false)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleAdmin))
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"quota":{"max_versions":3,"versions":2,"remaining":1}`)
+	})
+}
+
+type MockMatchExistenceChecker struct {
+	mock.Mock
+}
+
+func (m *MockMatchExistenceChecker) HasStartedMatches(ctx context.Context, tournamentID uuid.UUID, gameType string) (bool, error) {
+	args := m.Called(ctx, tournamentID, gameType)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMatchExistenceChecker) HasAnyRunningMatches(ctx context.Context, tournamentID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, tournamentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMatchExistenceChecker) HasRunningMatchesForGame(ctx context.Context, tournamentID uuid.UUID, gameType string) (bool, error) {
+	args := m.Called(ctx, tournamentID, gameType)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMatchExistenceChecker) GetActiveGameType(ctx context.Context, tournamentID uuid.UUID) (string, error) {
+	args := m.Called(ctx, tournamentID)
+	return args.String(0), args.Error(1)
+}
+
+// staticRoundChecker reports the same round state for every game
+type staticRoundChecker struct {
+	completed bool
+	active    bool
+}
+
+func (c staticRoundChecker) IsRoundCompleted(_ context.Context, _, _ uuid.UUID) (bool, error) {
+	return c.completed, nil
+}
+
+func (c staticRoundChecker) IsRoundActive(_ context.Context, _, _ uuid.UUID) (bool, error) {
+	return c.active, nil
+}
+
+// staticGameLookup resolves any game ID to the same game
+type staticGameLookup struct {
+	game *domain.Game
+}
+
+func (l staticGameLookup) GetByID(_ context.Context, _ uuid.UUID) (*domain.Game, error) {
+	return l.game, nil
+}
+
+func TestProgramHandler_RoundLock(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	t.Setenv("PROGRAMS_PATH", t.TempDir())
+
+	userID := uuid.New()
+	teamID := uuid.New()
+	tournamentID := uuid.New()
+	gameID := uuid.New()
+	game := &domain.Game{ID: gameID, Name: "tictactoe"}
+
+	newHandler := func(checker *MockMatchExistenceChecker, rounds staticRoundChecker, tournament *domain.Tournament) (*ProgramHandler, *MockProgramRepository) {
+		mockRepo := new(MockProgramRepository)
+		mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(nil, errors.ErrProgramNotFound)
+		mockRepo.On("GetLatestVersionCreatedAt", mock.Anything, teamID, gameID).Return(nil, nil)
+		mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(1, nil)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+
+		mockTournaments := new(MockTournamentService)
+		mockTournaments.On("GetByID", mock.Anything, tournamentID).Return(tournament, nil)
+
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		handler.SetGameLookup(staticGameLookup{game: game})
+		handler.SetMatchChecker(checker)
+		handler.SetRoundChecker(rounds)
+		handler.SetUploadCooldown(0, mockTournaments)
+		return handler, mockRepo
+	}
+
+	t.Run("round of another game does not block upload", func(t *testing.T) {
+		checker := new(MockMatchExistenceChecker)
+		checker.On("HasRunningMatchesForGame", mock.Anything, tournamentID, "tictactoe").Return(false, nil)
+		handler, mockRepo := newHandler(checker, staticRoundChecker{}, &domain.Tournament{ID: tournamentID})
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockRepo.AssertCalled(t, "Create", mock.Anything, mock.Anything)
+		checker.AssertNotCalled(t, "HasAnyRunningMatches", mock.Anything, mock.Anything)
+	})
+
+	t.Run("active round of the game blocks upload", func(t *testing.T) {
+		checker := new(MockMatchExistenceChecker)
+		checker.On("HasRunningMatchesForGame", mock.Anything, tournamentID, "tictactoe").Return(false, nil)
+		handler, mockRepo := newHandler(checker, staticRoundChecker{active: true}, &domain.Tournament{ID: tournamentID})
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "tictactoe")
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("running matches of the game block upload", func(t *testing.T) {
+		checker := new(MockMatchExistenceChecker)
+		checker.On("HasRunningMatchesForGame", mock.Anything, tournamentID, "tictactoe").Return(true, nil)
+		handler, mockRepo := newHandler(checker, staticRoundChecker{}, &domain.Tournament{ID: tournamentID})
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("tournament lock blocks upload during any round", func(t *testing.T) {
+		checker := new(MockMatchExistenceChecker)
+		checker.On("HasAnyRunningMatches", mock.Anything, tournamentID).Return(true, nil)
+		checker.On("GetActiveGameType", mock.Anything, tournamentID).Return("dilemma", nil)
+		handler, mockRepo := newHandler(checker, staticRoundChecker{}, &domain.Tournament{
+			ID:       tournamentID,
+			Metadata: map[string]interface{}{domain.MetaRoundLock: string(domain.RoundLockTournament)},
+		})
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "dilemma")
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		checker.AssertNotCalled(t, "HasRunningMatchesForGame", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestProgramHandler_DuplicateUpload(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	t.Setenv("PROGRAMS_PATH", t.TempDir())
+
+	userID := uuid.New()
+	teamID := uuid.New()
+	tournamentID := uuid.New()
+	gameID := uuid.New()
+
+	// Hash of the "binary" payload built by newUploadRequest
+	sum := sha256.Sum256([]byte("binary"))
+	sameHash := hex.EncodeToString(sum[:])
+	otherHash := strings.Repeat("0", 64)
+
+	latestWithHash := func(hash string) *domain.Program {
+		return &domain.Program{ID: uuid.New(), UserID: userID, TeamID: &teamID, GameID: &gameID, Version: 3, ContentHash: &hash}
+	}
+
+	t.Run("returns existing program for identical content", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+
+		latest := latestWithHash(sameHash)
+		mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(latest, nil)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, true, response["duplicate"])
+		assert.Equal(t, latest.ID.String(), response["id"])
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("creates new version for changed content", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+
+		mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(latestWithHash(otherHash), nil)
+		mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(3, nil)
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Program) bool {
+			return p.Version == 4 && p.ContentHash != nil && *p.ContentHash == sameHash
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("force creates new version of identical content", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+
+		mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(3, nil)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+
+		req := newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleAdmin)
+		req.URL.RawQuery = "force=true"
+
+		w := httptest.NewRecorder()
+		handler.Create(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockRepo.AssertNotCalled(t, "GetLatestByTeamAndGame", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestProgramHandler_LanguageVersion(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	t.Setenv("PROGRAMS_PATH", t.TempDir())
+
+	userID := uuid.New()
+	teamID := uuid.New()
+	tournamentID := uuid.New()
+	gameID := uuid.New()
+	versions := domain.LanguageVersions{"python": {"3.9", "3.10", "3.11", "3.12"}}
+
+	newVersionedUpload := func(t *testing.T, fileName, version string) *http.Request {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		require.NoError(t, writer.WriteField("team_id", teamID.String()))
+		require.NoError(t, writer.WriteField("tournament_id", tournamentID.String()))
+		require.NoError(t, writer.WriteField("game_id", gameID.String()))
+		if version != "" {
+			require.NoError(t, writer.WriteField("language_version", version))
+		}
+		part, err := writer.CreateFormFile("file", fileName)
+		require.NoError(t, err)
+		_, _ = part.Write([]byte("print(1)\n"))
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/programs?force=true", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		ctx := context.WithValue(req.Context(), middleware.UserIDKey, userID)
+		ctx = context.WithValue(ctx, middleware.RoleKey, domain.RoleAdmin)
+		return req.WithContext(ctx)
+	}
+
+	t.Run("rejects unsupported version", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		handler.SetLanguageVersions(versions)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newVersionedUpload(t, "bot.py", "2.7"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects version for language without versions", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		handler.SetLanguageVersions(versions)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newVersionedUpload(t, "bot.bin", "3.12"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("stores requested version", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		handler.SetLanguageVersions(versions)
+
+		mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(0, errors.ErrProgramNotFound)
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Program) bool {
+			return p.Language == "python" && p.LanguageVersion == "3.10"
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newVersionedUpload(t, "bot.py", "3.10"))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("defaults to latest version", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+		handler.SetLanguageVersions(versions)
+
+		mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(0, errors.ErrProgramNotFound)
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Program) bool {
+			return p.LanguageVersion == "3.12"
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newVersionedUpload(t, "bot.py", ""))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestProgramHandler_SupportedLanguages(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	t.Setenv("PROGRAMS_PATH", t.TempDir())
+
+	handler := NewProgramHandler(new(MockProgramRepository), nil, nil, nil, log)
+	handler.SetLanguageVersions(domain.LanguageVersions{"python": {"3.11", "3.12"}})
+
+	w := httptest.NewRecorder()
+	handler.SupportedLanguages(w, httptest.NewRequest(http.MethodGet, "/api/v1/executor/supported-languages", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []domain.SupportedLanguage
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, []domain.SupportedLanguage{
+		{Language: "python", Versions: []string{"3.11", "3.12"}, DefaultVersion: "3.12"},
+	}, response)
+}
+
+func TestHashUpload(t *testing.T) {
+	shebang := getShebang("python")
+
+	hash, addShebang, err := hashUpload(strings.NewReader("print(1)\n"), shebang)
+	require.NoError(t, err)
+	assert.True(t, addShebang)
+
+	// Uploading the normalized file yields the same hash
+	normalized, addShebang, err := hashUpload(strings.NewReader(shebang+"print(1)\n"), shebang)
+	require.NoError(t, err)
+	assert.False(t, addShebang)
+	assert.Equal(t, hash, normalized)
+}
+
 func TestProgramHandler_List(t *testing.T) {
 	log, _ := logger.New("error", "json")
 
 	t.Run("successfully list programs", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		userID := uuid.New()
 		expectedPrograms := []*domain.Program{
@@ -213,7 +835,7 @@ func TestProgramHandler_List(t *testing.T) {
 			},
 		}
 
-		mockRepo.On("GetByUserID", mock.Anything, userID).Return(expectedPrograms, nil)
+		mockRepo.On("ListByUserFiltered", mock.Anything, userID, domain.ProgramFilter{Limit: defaultProgramsLimit}).Return(expectedPrograms, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/api/v1/programs", nil)
 
@@ -237,7 +859,7 @@ func TestProgramHandler_List(t *testing.T) {
 
 	t.Run("missing user ID in context", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		req := httptest.NewRequest(http.MethodGet, "/api/v1/programs", nil)
 		w := httptest.NewRecorder()
@@ -249,11 +871,11 @@ func TestProgramHandler_List(t *testing.T) {
 
 	t.Run("repository error", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		userID := uuid.New()
 
-		mockRepo.On("GetByUserID", mock.Anything, userID).Return(nil, errors.ErrInternal.WithMessage("database error"))
+		mockRepo.On("ListByUserFiltered", mock.Anything, userID, mock.Anything).Return(nil, errors.ErrInternal.WithMessage("database error"))
 
 		req := httptest.NewRequest(http.MethodGet, "/api/v1/programs", nil)
 
@@ -268,6 +890,50 @@ func TestProgramHandler_List(t *testing.T) {
 
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("filters and pagination", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+
+		userID := uuid.New()
+		tournamentID := uuid.New()
+		gameID := uuid.New()
+
+		mockRepo.On("ListByUserFiltered", mock.Anything, userID, domain.ProgramFilter{
+			TournamentID: &tournamentID,
+			GameID:       &gameID,
+			Language:     "go",
+			Limit:        20,
+			Offset:       40,
+		}).Return([]*domain.Program{}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf(
+			"/api/v1/programs?tournament_id=%s&game_id=%s&language=go&limit=20&offset=40", tournamentID, gameID), nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+		w := httptest.NewRecorder()
+
+		handler.List(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		userID := uuid.New()
+		for _, query := range []string{"limit=0", "limit=100000", "offset=-1", "tournament_id=bad", "game_id=bad"} {
+			mockRepo := new(MockProgramRepository)
+			handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/programs?"+query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+			w := httptest.NewRecorder()
+
+			handler.List(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+			mockRepo.AssertNotCalled(t, "ListByUserFiltered", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
 }
 
 func TestProgramHandler_Get(t *testing.T) {
@@ -275,7 +941,7 @@ func TestProgramHandler_Get(t *testing.T) {
 
 	t.Run("successfully get program", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		programID := uuid.New()
 		expectedProgram := &domain.Program{
@@ -310,7 +976,7 @@ func TestProgramHandler_Get(t *testing.T) {
 
 	t.Run("invalid UUID", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		req := httptest.NewRequest(http.MethodGet, "/api/v1/programs/invalid-uuid", nil)
 
@@ -327,7 +993,7 @@ func TestProgramHandler_Get(t *testing.T) {
 
 	t.Run("program not found", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		programID := uuid.New()
 
@@ -349,12 +1015,172 @@ func TestProgramHandler_Get(t *testing.T) {
 	})
 }
 
+type MockProgramRankLookup struct {
+	mock.Mock
+}
+
+func (m *MockProgramRankLookup) GetRank(ctx context.Context, tournamentID, programID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, tournamentID, programID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestProgramHandler_GetRank(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	programID := uuid.New()
+	tournamentID := uuid.New()
+
+	newRequest := func(id, query string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/programs/"+id+"/rank?"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("returns rank", func(t *testing.T) {
+		lookup := new(MockProgramRankLookup)
+		handler := NewProgramHandler(new(MockProgramRepository), nil, nil, nil, log)
+		handler.SetRankLookup(lookup)
+
+		lookup.On("GetRank", mock.Anything, tournamentID, programID).Return(int64(3), nil)
+
+		w := httptest.NewRecorder()
+		handler.GetRank(w, newRequest(programID.String(), "tournament_id="+tournamentID.String()))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response programRankResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, programRankResponse{ProgramID: programID, TournamentID: tournamentID, Rank: 3}, response)
+		lookup.AssertExpectations(t)
+	})
+
+	t.Run("program outside tournament has rank 0", func(t *testing.T) {
+		lookup := new(MockProgramRankLookup)
+		handler := NewProgramHandler(new(MockProgramRepository), nil, nil, nil, log)
+		handler.SetRankLookup(lookup)
+
+		lookup.On("GetRank", mock.Anything, tournamentID, programID).Return(int64(0), nil)
+
+		w := httptest.NewRecorder()
+		handler.GetRank(w, newRequest(programID.String(), "tournament_id="+tournamentID.String()))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response programRankResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, int64(0), response.Rank)
+	})
+
+	t.Run("tournament_id is required", func(t *testing.T) {
+		lookup := new(MockProgramRankLookup)
+		handler := NewProgramHandler(new(MockProgramRepository), nil, nil, nil, log)
+		handler.SetRankLookup(lookup)
+
+		w := httptest.NewRecorder()
+		handler.GetRank(w, newRequest(programID.String(), ""))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		lookup.AssertNotCalled(t, "GetRank", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid program ID", func(t *testing.T) {
+		handler := NewProgramHandler(new(MockProgramRepository), nil, nil, nil, log)
+		handler.SetRankLookup(new(MockProgramRankLookup))
+
+		w := httptest.NewRecorder()
+		handler.GetRank(w, newRequest("invalid-uuid", "tournament_id="+tournamentID.String()))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+type MockProgramStatsLookup struct {
+	mock.Mock
+}
+
+func (m *MockProgramStatsLookup) GetProgramStats(ctx context.Context, programID uuid.UUID) (*domain.ProgramStats, error) {
+	args := m.Called(ctx, programID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ProgramStats), args.Error(1)
+}
+
+func TestProgramHandler_GetStats(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	programID := uuid.New()
+
+	newRequest := func(id string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/programs/"+id+"/stats", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("returns stats", func(t *testing.T) {
+		repo := new(MockProgramRepository)
+		lookup := new(MockProgramStatsLookup)
+		handler := NewProgramHandler(repo, nil, nil, nil, log)
+		handler.SetStatsLookup(lookup)
+
+		stats := &domain.ProgramStats{
+			ProgramID:               programID,
+			TotalMatches:            10,
+			Wins:                    5,
+			Losses:                  3,
+			Draws:                   1,
+			Failed:                  1,
+			AvgScore:                42.5,
+			TournamentsParticipated: 2,
+		}
+		repo.On("GetByID", mock.Anything, programID).Return(&domain.Program{ID: programID}, nil)
+		lookup.On("GetProgramStats", mock.Anything, programID).Return(stats, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetStats(w, newRequest(programID.String()))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, float64(10), response["total_matches"])
+		assert.Equal(t, float64(5), response["wins"])
+		assert.Equal(t, float64(3), response["losses"])
+		assert.Equal(t, float64(1), response["draws"])
+		assert.Equal(t, float64(1), response["failed"])
+		assert.Equal(t, 42.5, response["avg_score"])
+		assert.Equal(t, float64(2), response["tournaments_participated"])
+	})
+
+	t.Run("unknown program", func(t *testing.T) {
+		repo := new(MockProgramRepository)
+		lookup := new(MockProgramStatsLookup)
+		handler := NewProgramHandler(repo, nil, nil, nil, log)
+		handler.SetStatsLookup(lookup)
+
+		repo.On("GetByID", mock.Anything, programID).Return(nil, errors.ErrProgramNotFound)
+
+		w := httptest.NewRecorder()
+		handler.GetStats(w, newRequest(programID.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		lookup.AssertNotCalled(t, "GetProgramStats", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		handler := NewProgramHandler(new(MockProgramRepository), nil, nil, nil, log)
+		handler.SetStatsLookup(new(MockProgramStatsLookup))
+
+		w := httptest.NewRecorder()
+		handler.GetStats(w, newRequest("not-a-uuid"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 func TestProgramHandler_Update(t *testing.T) {
 	log, _ := logger.New("error", "json")
 
 	t.Run("successfully update program", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		userID := uuid.New()
 		programID := uuid.New()
@@ -406,7 +1232,7 @@ func TestProgramHandler_Update(t *testing.T) {
 
 	t.Run("not the owner", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		userID := uuid.New()
 		programID := uuid.New()
@@ -444,7 +1270,7 @@ func TestProgramHandler_Delete(t *testing.T) {
 
 	t.Run("successfully delete program", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		userID := uuid.New()
 		programID := uuid.New()
@@ -479,7 +1305,7 @@ func TestProgramHandler_Delete(t *testing.T) {
 
 	t.Run("not the owner", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		userID := uuid.New()
 		programID := uuid.New()
@@ -505,7 +1331,7 @@ func TestProgramHandler_Delete(t *testing.T) {
 
 	t.Run("invalid UUID", func(t *testing.T) {
 		mockRepo := new(MockProgramRepository)
-		handler := NewProgramHandler(mockRepo, nil, nil, log)
+		handler := NewProgramHandler(mockRepo, nil, nil, nil, log)
 
 		userID := uuid.New()
 
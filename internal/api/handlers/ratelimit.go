@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+)
+
+// RateLimitStatusSource интерфейс для получения состояния окна rate limiting
+type RateLimitStatusSource interface {
+	GetStatus(ctx context.Context, key string) (*cache.RateLimitStatus, error)
+}
+
+// RateLimitHandler отдаёт клиенту состояние его лимита запросов
+type RateLimitHandler struct {
+	limiter RateLimitStatusSource
+	limit   int
+	window  time.Duration
+	log     *logger.Logger
+}
+
+// NewRateLimitHandler создаёт новый rate limit handler
+func NewRateLimitHandler(limiter RateLimitStatusSource, limit int, window time.Duration, log *logger.Logger) *RateLimitHandler {
+	return &RateLimitHandler{
+		limiter: limiter,
+		limit:   limit,
+		window:  window,
+		log:     log,
+	}
+}
+
+// GetStatus возвращает лимит, остаток запросов и время сброса окна для клиента
+// GET /api/v1/rate-limit
+func (h *RateLimitHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.limiter.GetStatus(r.Context(), middleware.RateLimitKey(r))
+	if err != nil {
+		h.log.LogError("Failed to get rate limit status", err)
+		writeError(w, err)
+		return
+	}
+
+	// Запросов в текущем окне ещё не было
+	if status == nil {
+		status = &cache.RateLimitStatus{
+			Limit:     h.limit,
+			Remaining: h.limit,
+			ResetAt:   time.Now().Add(h.window),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Допустимый рейтинг эталонного бота
+const (
+	minReferenceRating = 100
+	maxReferenceRating = 4000
+)
+
+// ReferenceBotRepository интерфейс для работы с эталонными ботами игр
+type ReferenceBotRepository interface {
+	Create(ctx context.Context, program *domain.Program) error
+	ListReferenceByGame(ctx context.Context, gameID uuid.UUID) ([]*domain.Program, error)
+	RetireReference(ctx context.Context, gameID, programID uuid.UUID) error
+}
+
+// SetReferenceBots устанавливает репозиторий эталонных ботов
+func (h *ProgramHandler) SetReferenceBots(referenceBots ReferenceBotRepository) {
+	h.referenceBots = referenceBots
+}
+
+// GameValidationBotRepository интерфейс для назначения проверочного бота игры
+type GameValidationBotRepository interface {
+	SetValidationBot(ctx context.Context, gameID uuid.UUID, programID *uuid.UUID) error
+}
+
+// SetValidationBots включает PUT /games/{id}/validation-bot (требует SetReferenceBots)
+func (h *ProgramHandler) SetValidationBots(validationBots GameValidationBotRepository) {
+	h.validationBots = validationBots
+}
+
+// CreateReferenceBot регистрирует эталонного бота игры (multipart: file, name, rating)
+// POST /api/v1/games/:id/reference-bots
+func (h *ProgramHandler) CreateReferenceBot(w http.ResponseWriter, r *http.Request) {
+	if h.referenceBots == nil || h.gameLookup == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("reference bots are not available"))
+		return
+	}
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid game ID"))
+		return
+	}
+
+	game, err := h.gameLookup.GetByID(r.Context(), gameID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxFileSize)
+	if err := r.ParseMultipartForm(h.maxFileSize); err != nil {
+		h.log.Info("Failed to parse multipart form", zap.Error(err))
+		writeError(w, errors.ErrInvalidInput.WithMessage("file too large or invalid form"))
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("file is required"))
+		return
+	}
+	defer file.Close()
+
+	name := r.FormValue("name")
+	if name == "" {
+		name = header.Filename
+	}
+
+	rating := domain.DefaultReferenceRating
+	if raw := r.FormValue("rating"); raw != "" {
+		rating, err = strconv.Atoi(raw)
+		if err != nil || rating < minReferenceRating || rating > maxReferenceRating {
+			writeError(w, errors.ErrValidation.WithMessage(
+				fmt.Sprintf("rating must be between %d and %d", minReferenceRating, maxReferenceRating)))
+			return
+		}
+	}
+
+	language := detectLanguage(header.Filename)
+	shebang := getShebang(language)
+	contentHash, addShebang, err := hashUpload(file, shebang)
+	if err != nil {
+		h.log.Error("Failed to hash uploaded file", zap.Error(err))
+		writeError(w, errors.ErrInternal.WithMessage("failed to read file"))
+		return
+	}
+
+	programID := uuid.New()
+	fileName := fmt.Sprintf("reference_%s_%s%s", gameID.String()[:8], programID.String()[:8], filepath.Ext(header.Filename))
+	filePath := filepath.Join(h.uploadDir, fileName)
+	if err := h.saveProgramFile(filePath, file, shebang, addShebang); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Бот с ошибкой синтаксиса проиграл бы все калибровочные матчи
+	if errMsg := validateSyntax(language, filePath); errMsg != "" {
+		os.Remove(filePath)
+		writeError(w, errors.ErrValidation.WithMessage("syntax error: "+errMsg))
+		return
+	}
+
+	program := &domain.Program{
+		ID:              programID,
+		UserID:          domain.SystemUserID,
+		GameID:          &gameID,
+		Name:            name,
+		GameType:        game.Name,
+		CodePath:        filePath,
+		FilePath:        &filePath,
+		Language:        language,
+		Version:         1,
+		ContentHash:     &contentHash,
+		IsReference:     true,
+		ReferenceRating: &rating,
+	}
+
+	if err := h.referenceBots.Create(r.Context(), program); err != nil {
+		h.log.LogError("Failed to create reference bot", err)
+		os.Remove(filePath)
+		writeError(w, err)
+		return
+	}
+
+	h.log.Info("Reference bot registered",
+		zap.String("program_id", programID.String()),
+		zap.String("game", game.Name),
+		zap.Int("rating", rating),
+	)
+
+	writeJSON(w, http.StatusCreated, program)
+}
+
+// ListReferenceBots возвращает действующие эталонные боты игры
+// GET /api/v1/games/:id/reference-bots
+func (h *ProgramHandler) ListReferenceBots(w http.ResponseWriter, r *http.Request) {
+	if h.referenceBots == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("reference bots are not available"))
+		return
+	}
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid game ID"))
+		return
+	}
+
+	bots, err := h.referenceBots.ListReferenceByGame(r.Context(), gameID)
+	if err != nil {
+		h.log.LogError("Failed to list reference bots", err, zap.String("game_id", gameID.String()))
+		writeError(w, err)
+		return
+	}
+	if bots == nil {
+		bots = []*domain.Program{}
+	}
+
+	writeJSON(w, http.StatusOK, bots)
+}
+
+// DeleteReferenceBot выводит эталонного бота из калибровки. Сыгранные матчи сохраняются
+// DELETE /api/v1/games/:id/reference-bots/:programId
+func (h *ProgramHandler) DeleteReferenceBot(w http.ResponseWriter, r *http.Request) {
+	if h.referenceBots == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("reference bots are not available"))
+		return
+	}
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid game ID"))
+		return
+	}
+
+	programID, err := uuid.Parse(chi.URLParam(r, "programId"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid program ID"))
+		return
+	}
+
+	if err := h.referenceBots.RetireReference(r.Context(), gameID, programID); err != nil {
+		h.log.LogError("Failed to retire reference bot", err, zap.String("program_id", programID.String()))
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateValidationBot назначает эталонного бота, с которым каждая новая программа игры
+// играет проверочный матч при загрузке. {"program_id": null} отключает проверку
+// PUT /api/v1/games/:id/validation-bot
+func (h *ProgramHandler) UpdateValidationBot(w http.ResponseWriter, r *http.Request) {
+	if h.referenceBots == nil || h.validationBots == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("validation bots are not available"))
+		return
+	}
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid game ID"))
+		return
+	}
+
+	var req struct {
+		ProgramID *uuid.UUID `json:"program_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	// Проверочным может быть только действующий эталонный бот этой игры
+	if req.ProgramID != nil {
+		bots, err := h.referenceBots.ListReferenceByGame(r.Context(), gameID)
+		if err != nil {
+			h.log.LogError("Failed to list reference bots", err, zap.String("game_id", gameID.String()))
+			writeError(w, err)
+			return
+		}
+		found := false
+		for _, bot := range bots {
+			if bot.ID == *req.ProgramID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			writeError(w, errors.ErrValidation.WithMessage("program_id must be an active reference bot of this game"))
+			return
+		}
+	}
+
+	if err := h.validationBots.SetValidationBot(r.Context(), gameID, req.ProgramID); err != nil {
+		h.log.LogError("Failed to set validation bot", err, zap.String("game_id", gameID.String()))
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"game_id":           gameID,
+		"validation_bot_id": req.ProgramID,
+	})
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryReferenceBots keeps reference bots in memory
+type memoryReferenceBots struct {
+	bots    []*domain.Program
+	retired []uuid.UUID
+}
+
+func (r *memoryReferenceBots) Create(_ context.Context, program *domain.Program) error {
+	r.bots = append(r.bots, program)
+	return nil
+}
+
+func (r *memoryReferenceBots) ListReferenceByGame(_ context.Context, gameID uuid.UUID) ([]*domain.Program, error) {
+	var bots []*domain.Program
+	for _, bot := range r.bots {
+		if *bot.GameID == gameID {
+			bots = append(bots, bot)
+		}
+	}
+	return bots, nil
+}
+
+func (r *memoryReferenceBots) RetireReference(_ context.Context, _, programID uuid.UUID) error {
+	for _, bot := range r.bots {
+		if bot.ID == programID {
+			r.retired = append(r.retired, programID)
+			return nil
+		}
+	}
+	return errors.ErrNotFound.WithMessage("reference bot not found")
+}
+
+// recordingScheduler records calibration and validation requests
+type recordingScheduler struct {
+	calibrated []uuid.UUID
+	validated  []uuid.UUID
+	// validationBot: the game has a validation bot, so a match is created
+	validationBot bool
+}
+
+func (s *recordingScheduler) ScheduleNewProgramMatches(_ context.Context, _, _, _, _ uuid.UUID) error {
+	return nil
+}
+
+func (s *recordingScheduler) ScheduleCalibrationMatches(_ context.Context, _, _, programID uuid.UUID) error {
+	s.calibrated = append(s.calibrated, programID)
+	return nil
+}
+
+func (s *recordingScheduler) ScheduleValidationMatch(_ context.Context, _, _, programID uuid.UUID) (*domain.Match, error) {
+	s.validated = append(s.validated, programID)
+	if !s.validationBot {
+		return nil, nil
+	}
+	return &domain.Match{ID: uuid.New(), Program1ID: programID, IsTest: true, IsValidation: true}, nil
+}
+
+// memoryValidationBots keeps validation bots of games in memory
+type memoryValidationBots struct {
+	bots map[uuid.UUID]*uuid.UUID
+}
+
+func (r *memoryValidationBots) SetValidationBot(_ context.Context, gameID uuid.UUID, programID *uuid.UUID) error {
+	r.bots[gameID] = programID
+	return nil
+}
+
+func newReferenceBotRequest(t *testing.T, gameID uuid.UUID, fields map[string]string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "always_defect.bin")
+	require.NoError(t, err)
+	_, _ = part.Write([]byte("binary"))
+	for key, value := range fields {
+		require.NoError(t, writer.WriteField(key, value))
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/games/"+gameID.String()+"/reference-bots", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", gameID.String())
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestProgramHandler_CreateReferenceBot(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	t.Setenv("PROGRAMS_PATH", t.TempDir())
+
+	gameID := uuid.New()
+	game := &domain.Game{ID: gameID, Name: "prisoners_dilemma"}
+
+	newHandler := func(bots *memoryReferenceBots) *ProgramHandler {
+		handler := NewProgramHandler(new(MockProgramRepository), nil, nil, nil, log)
+		handler.SetGameLookup(staticGameLookup{game: game})
+		handler.SetReferenceBots(bots)
+		return handler
+	}
+
+	t.Run("bot is owned by the system user", func(t *testing.T) {
+		bots := &memoryReferenceBots{}
+		w := httptest.NewRecorder()
+		newHandler(bots).CreateReferenceBot(w, newReferenceBotRequest(t, gameID, map[string]string{"name": "Always-Defect", "rating": "1400"}))
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Len(t, bots.bots, 1)
+		bot := bots.bots[0]
+		assert.True(t, bot.IsReference)
+		assert.Equal(t, domain.SystemUserID, bot.UserID)
+		assert.Nil(t, bot.TeamID)
+		assert.Nil(t, bot.TournamentID)
+		assert.Equal(t, "prisoners_dilemma", bot.GameType)
+		assert.Equal(t, 1400, *bot.ReferenceRating)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "Always-Defect", resp["name"])
+		assert.Equal(t, true, resp["is_reference"])
+	})
+
+	t.Run("default rating", func(t *testing.T) {
+		bots := &memoryReferenceBots{}
+		w := httptest.NewRecorder()
+		newHandler(bots).CreateReferenceBot(w, newReferenceBotRequest(t, gameID, nil))
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "always_defect.bin", bots.bots[0].Name)
+		assert.Equal(t, domain.DefaultReferenceRating, *bots.bots[0].ReferenceRating)
+	})
+
+	t.Run("invalid rating", func(t *testing.T) {
+		bots := &memoryReferenceBots{}
+		w := httptest.NewRecorder()
+		newHandler(bots).CreateReferenceBot(w, newReferenceBotRequest(t, gameID, map[string]string{"rating": "50"}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, bots.bots)
+	})
+}
+
+func TestProgramHandler_DeleteReferenceBot(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	gameID := uuid.New()
+	bot := &domain.Program{ID: uuid.New(), GameID: &gameID, IsReference: true}
+
+	deleteRequest := func(programID uuid.UUID) *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/games/"+gameID.String()+"/reference-bots/"+programID.String(), nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", gameID.String())
+		rctx.URLParams.Add("programId", programID.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	bots := &memoryReferenceBots{bots: []*domain.Program{bot}}
+	handler := NewProgramHandler(new(MockProgramRepository), nil, nil, nil, log)
+	handler.SetReferenceBots(bots)
+
+	w := httptest.NewRecorder()
+	handler.DeleteReferenceBot(w, deleteRequest(bot.ID))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []uuid.UUID{bot.ID}, bots.retired)
+
+	w = httptest.NewRecorder()
+	handler.DeleteReferenceBot(w, deleteRequest(uuid.New()))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProgramHandler_UploadSchedulesCalibration(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	t.Setenv("PROGRAMS_PATH", t.TempDir())
+
+	userID := uuid.New()
+	teamID := uuid.New()
+	tournamentID := uuid.New()
+	gameID := uuid.New()
+
+	mockRepo := new(MockProgramRepository)
+	mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(nil, errors.ErrProgramNotFound)
+	mockRepo.On("GetLatestVersionCreatedAt", mock.Anything, teamID, gameID).Return(nil, nil)
+	mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(1, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+
+	scheduler := &recordingScheduler{}
+	handler := NewProgramHandler(mockRepo, nil, scheduler, nil, log)
+
+	w := httptest.NewRecorder()
+	handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var program domain.Program
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&program))
+	assert.Equal(t, []uuid.UUID{program.ID}, scheduler.calibrated)
+	assert.Equal(t, []uuid.UUID{program.ID}, scheduler.validated)
+	assert.Empty(t, program.ValidationStatus, "game without a validation bot does not validate")
+}
+
+func TestProgramHandler_UploadSchedulesValidation(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	t.Setenv("PROGRAMS_PATH", t.TempDir())
+
+	userID, teamID, tournamentID, gameID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	mockRepo := new(MockProgramRepository)
+	mockRepo.On("GetLatestByTeamAndGame", mock.Anything, teamID, gameID).Return(nil, errors.ErrProgramNotFound)
+	mockRepo.On("GetLatestVersionCreatedAt", mock.Anything, teamID, gameID).Return(nil, nil)
+	mockRepo.On("GetLatestVersion", mock.Anything, teamID, gameID).Return(1, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+
+	scheduler := &recordingScheduler{validationBot: true}
+	handler := NewProgramHandler(mockRepo, nil, scheduler, nil, log)
+
+	w := httptest.NewRecorder()
+	handler.Create(w, newUploadRequest(t, userID, teamID, tournamentID, gameID, domain.RoleUser))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// The response does not wait for the match: the program comes back pending
+	var program domain.Program
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&program))
+	assert.Equal(t, []uuid.UUID{program.ID}, scheduler.validated)
+	assert.Equal(t, domain.ValidationPending, program.ValidationStatus)
+}
+
+func TestProgramHandler_UpdateValidationBot(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	t.Setenv("PROGRAMS_PATH", t.TempDir())
+
+	gameID := uuid.New()
+	bot := &domain.Program{ID: uuid.New(), GameID: &gameID, IsReference: true}
+	bots := &memoryReferenceBots{bots: []*domain.Program{bot}}
+	validation := &memoryValidationBots{bots: map[uuid.UUID]*uuid.UUID{}}
+
+	handler := NewProgramHandler(new(MockProgramRepository), nil, nil, nil, log)
+	handler.SetReferenceBots(bots)
+	handler.SetValidationBots(validation)
+
+	request := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/games/"+gameID.String()+"/validation-bot", bytes.NewBufferString(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", gameID.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	w := httptest.NewRecorder()
+	handler.UpdateValidationBot(w, request(`{"program_id": "`+bot.ID.String()+`"}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, validation.bots[gameID])
+	assert.Equal(t, bot.ID, *validation.bots[gameID])
+
+	// A regular program cannot be the validation bot
+	w = httptest.NewRecorder()
+	handler.UpdateValidationBot(w, request(`{"program_id": "`+uuid.New().String()+`"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, bot.ID, *validation.bots[gameID])
+
+	w = httptest.NewRecorder()
+	handler.UpdateValidationBot(w, request(`{"program_id": null}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, validation.bots[gameID])
+}
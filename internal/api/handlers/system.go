@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"os"
 	"runtime"
 	"time"
 
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/shirou/gopsutil/v3/host"
@@ -74,9 +80,27 @@ type TemperatureInfo struct {
 	Temperature float64 `json:"temperature"`
 }
 
+// LeaderboardRefresher forces a refresh of the leaderboard materialized views
+type LeaderboardRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// CrossGameStatsRebuilder rebuilds the cross-game leaderboard aggregate of a tournament from its matches
+type CrossGameStatsRebuilder interface {
+	RebuildCrossGameStats(ctx context.Context, tournamentID uuid.UUID) error
+}
+
+// QueryExplainer runs EXPLAIN ANALYZE for a read-only query and returns the JSON plan
+type QueryExplainer interface {
+	ExplainAnalyze(ctx context.Context, query string, args ...interface{}) (string, error)
+}
+
 // SystemHandler handles system-related API requests
 type SystemHandler struct {
-	log *logger.Logger
+	log                  *logger.Logger
+	leaderboardRefresher LeaderboardRefresher
+	crossGameRebuilder   CrossGameStatsRebuilder
+	queryExplainer       QueryExplainer
 }
 
 // NewSystemHandler creates a new system handler
@@ -86,6 +110,21 @@ func NewSystemHandler(log *logger.Logger) *SystemHandler {
 	}
 }
 
+// SetLeaderboardRefresher sets the refresher used by RefreshLeaderboard
+func (h *SystemHandler) SetLeaderboardRefresher(refresher LeaderboardRefresher) {
+	h.leaderboardRefresher = refresher
+}
+
+// SetCrossGameStatsRebuilder sets the rebuilder used by RebuildCrossGameStats
+func (h *SystemHandler) SetCrossGameStatsRebuilder(rebuilder CrossGameStatsRebuilder) {
+	h.crossGameRebuilder = rebuilder
+}
+
+// SetQueryExplainer sets the explainer used by ExplainQuery
+func (h *SystemHandler) SetQueryExplainer(explainer QueryExplainer) {
+	h.queryExplainer = explainer
+}
+
 // GetMetrics returns system metrics
 // GET /api/v1/system/metrics
 func (h *SystemHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
@@ -200,3 +239,101 @@ func (h *SystemHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, health)
 }
+
+// RefreshLeaderboard forces an immediate refresh of the leaderboard materialized views,
+// e.g. right before announcing winners. Returns 409 if a refresh is already running
+// POST /api/v1/admin/leaderboard/refresh
+func (h *SystemHandler) RefreshLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if h.leaderboardRefresher == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("leaderboard refresh is not configured"))
+		return
+	}
+
+	startTime := time.Now()
+	if err := h.leaderboardRefresher.Refresh(r.Context()); err != nil {
+		if !errors.IsConflict(err) {
+			h.log.LogError("Manual leaderboard refresh failed", err)
+		}
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":      "refreshed",
+		"duration_ms": time.Since(startTime).Milliseconds(),
+	})
+}
+
+// RebuildCrossGameStats rebuilds the cross-game leaderboard aggregate of a tournament
+// from the matches table, e.g. after matches were edited directly in the database
+// POST /api/v1/admin/tournaments/{id}/cross-game-stats/rebuild
+func (h *SystemHandler) RebuildCrossGameStats(w http.ResponseWriter, r *http.Request) {
+	if h.crossGameRebuilder == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("cross-game stats rebuild is not configured"))
+		return
+	}
+
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	startTime := time.Now()
+	if err := h.crossGameRebuilder.RebuildCrossGameStats(r.Context(), tournamentID); err != nil {
+		h.log.LogError("Cross-game stats rebuild failed", err)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":      "rebuilt",
+		"duration_ms": time.Since(startTime).Milliseconds(),
+	})
+}
+
+// ExplainQueryRequest is the body of ExplainQuery
+type ExplainQueryRequest struct {
+	Query string        `json:"query"`
+	Args  []interface{} `json:"args"`
+}
+
+// ExplainQuery runs EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) for a SELECT query so slow
+// queries can be debugged without a direct database connection. Only SELECT is allowed,
+// execution is limited to db.ExplainTimeout
+// POST /api/v1/admin/db/explain
+func (h *SystemHandler) ExplainQuery(w http.ResponseWriter, r *http.Request) {
+	if h.queryExplainer == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("query explain is not configured"))
+		return
+	}
+
+	var req ExplainQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.ErrInvalidInput.WithError(err))
+		return
+	}
+	if err := db.ValidateExplainQuery(req.Query); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	plan, err := h.queryExplainer.ExplainAnalyze(r.Context(), req.Query, req.Args...)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	timings, err := db.ParseExplainTimings(plan)
+	if err != nil {
+		h.log.LogError("Failed to parse explain plan", err)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"plan":              json.RawMessage(plan),
+		"planning_time_ms":  timings.PlanningTimeMs,
+		"execution_time_ms": timings.ExecutionTimeMs,
+	})
+}
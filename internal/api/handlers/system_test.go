@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubLeaderboardRefresher struct {
+	err   error
+	calls int
+}
+
+func (s *stubLeaderboardRefresher) Refresh(context.Context) error {
+	s.calls++
+	return s.err
+}
+
+type stubCrossGameRebuilder struct {
+	rebuilt []uuid.UUID
+}
+
+func (s *stubCrossGameRebuilder) RebuildCrossGameStats(_ context.Context, tournamentID uuid.UUID) error {
+	s.rebuilt = append(s.rebuilt, tournamentID)
+	return nil
+}
+
+func TestSystemHandler_RefreshLeaderboard(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	tests := []struct {
+		name       string
+		refresher  *stubLeaderboardRefresher
+		wantStatus int
+	}{
+		{name: "refreshed", refresher: &stubLeaderboardRefresher{}, wantStatus: http.StatusOK},
+		{name: "already running", refresher: &stubLeaderboardRefresher{err: db.ErrLeaderboardRefreshInProgress}, wantStatus: http.StatusConflict},
+		{name: "not configured", wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSystemHandler(log)
+			if tt.refresher != nil {
+				handler.SetLeaderboardRefresher(tt.refresher)
+			}
+
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/admin/leaderboard/refresh", nil)
+			w := httptest.NewRecorder()
+			handler.RefreshLeaderboard(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.refresher != nil {
+				assert.Equal(t, 1, tt.refresher.calls)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, "refreshed", body["status"])
+			}
+		})
+	}
+}
+
+func TestSystemHandler_RebuildCrossGameStats(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+
+	tests := []struct {
+		name       string
+		rebuilder  *stubCrossGameRebuilder
+		id         string
+		wantStatus int
+	}{
+		{name: "rebuilt", rebuilder: &stubCrossGameRebuilder{}, id: tournamentID.String(), wantStatus: http.StatusOK},
+		{name: "invalid id", rebuilder: &stubCrossGameRebuilder{}, id: "not-a-uuid", wantStatus: http.StatusBadRequest},
+		{name: "not configured", id: tournamentID.String(), wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSystemHandler(log)
+			if tt.rebuilder != nil {
+				handler.SetCrossGameStatsRebuilder(tt.rebuilder)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/tournaments/"+tt.id+"/cross-game-stats/rebuild", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+			handler.RebuildCrossGameStats(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, []uuid.UUID{tournamentID}, tt.rebuilder.rebuilt)
+			} else if tt.rebuilder != nil {
+				assert.Empty(t, tt.rebuilder.rebuilt)
+			}
+		})
+	}
+}
+
+type stubQueryExplainer struct {
+	plan    string
+	queries []string
+}
+
+func (s *stubQueryExplainer) ExplainAnalyze(_ context.Context, query string, _ ...interface{}) (string, error) {
+	s.queries = append(s.queries, query)
+	return s.plan, nil
+}
+
+func TestSystemHandler_ExplainQuery(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	plan := `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "matches"}, "Planning Time": 0.125, "Execution Time": 3.5}]`
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "select", body: `{"query": "SELECT * FROM matches WHERE status = $1", "args": ["pending"]}`, wantStatus: http.StatusOK},
+		{name: "insert", body: `{"query": "INSERT INTO matches (id) VALUES ($1)", "args": ["x"]}`, wantStatus: http.StatusBadRequest},
+		{name: "update", body: `{"query": "update matches set status = 'failed'"}`, wantStatus: http.StatusBadRequest},
+		{name: "delete", body: `{"query": "  DELETE FROM matches"}`, wantStatus: http.StatusBadRequest},
+		{name: "stacked statements", body: `{"query": "SELECT 1; DELETE FROM matches"}`, wantStatus: http.StatusBadRequest},
+		{name: "empty query", body: `{"query": ""}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			explainer := &stubQueryExplainer{plan: plan}
+			handler := NewSystemHandler(log)
+			handler.SetQueryExplainer(explainer)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/db/explain", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.ExplainQuery(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				assert.Empty(t, explainer.queries, "rejected query must not reach the database")
+				return
+			}
+
+			var body struct {
+				Plan            json.RawMessage `json:"plan"`
+				PlanningTimeMs  float64         `json:"planning_time_ms"`
+				ExecutionTimeMs float64         `json:"execution_time_ms"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.True(t, json.Valid(body.Plan))
+			assert.JSONEq(t, plan, string(body.Plan))
+			assert.Equal(t, 0.125, body.PlanningTimeMs)
+			assert.Equal(t, 3.5, body.ExecutionTimeMs)
+		})
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/db/explain", strings.NewReader(`{"query": "SELECT 1"}`))
+		w := httptest.NewRecorder()
+		NewSystemHandler(log).ExplainQuery(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
@@ -33,9 +33,10 @@ type TeamService interface {
 
 // TeamHandler обрабатывает запросы команд
 type TeamHandler struct {
-	teamService TeamService
-	baseURL     string
-	log         *logger.Logger
+	teamService      TeamService
+	baseURL          string
+	tournamentAccess *TournamentAccess
+	log              *logger.Logger
 }
 
 // NewTeamHandler создаёт новый team handler
@@ -47,6 +48,11 @@ func NewTeamHandler(teamService TeamService, baseURL string, log *logger.Logger)
 	}
 }
 
+// SetTournamentAccess включает проверку доступа к приватным турнирам на публичных маршрутах
+func (h *TeamHandler) SetTournamentAccess(access *TournamentAccess) {
+	h.tournamentAccess = access
+}
+
 // CreateTeamRequest запрос на создание команды
 type CreateTeamRequest struct {
 	TournamentID uuid.UUID `json:"tournament_id"`
@@ -334,6 +340,11 @@ func (h *TeamHandler) GetTournamentTeams(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := checkTournamentAccess(r, h.tournamentAccess, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	teams, err := h.teamService.GetTeamsByTournament(r.Context(), tournamentID)
 	if err != nil {
 		h.log.LogError("Failed to get tournament teams", err)
@@ -3,14 +3,19 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/internal/domain/tournament"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -19,25 +24,50 @@ import (
 // TournamentService интерфейс для tournament service
 type TournamentService interface {
 	Create(ctx context.Context, req *tournament.CreateRequest) (*domain.Tournament, error)
+	Update(ctx context.Context, tournamentID uuid.UUID, req *tournament.UpdateRequest) (*domain.Tournament, error)
+	CloneTournament(ctx context.Context, sourceID uuid.UUID, req tournament.CreateRequest) (*domain.Tournament, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error)
 	List(ctx context.Context, filter domain.TournamentFilter) ([]*domain.Tournament, error)
+	CountWithFilter(ctx context.Context, filter domain.TournamentFilter) (int, error)
+	ListWithCursor(ctx context.Context, filter domain.TournamentFilter, pageReq *pagination.PageRequest) ([]*domain.Tournament, bool, error)
 	Join(ctx context.Context, req *tournament.JoinRequest) error
 	Start(ctx context.Context, tournamentID uuid.UUID) error
 	Complete(ctx context.Context, tournamentID uuid.UUID) error
+	ForceComplete(ctx context.Context, tournamentID uuid.UUID, cancelPending bool) (*tournament.ForceCompleteResult, error)
+	Cancel(ctx context.Context, tournamentID uuid.UUID) (int64, error)
 	Delete(ctx context.Context, tournamentID uuid.UUID) error
+	Restore(ctx context.Context, tournamentID uuid.UUID) (*domain.Tournament, error)
+	Purge(ctx context.Context, tournamentID uuid.UUID) error
 	GetLeaderboard(ctx context.Context, tournamentID uuid.UUID, limit int) ([]*domain.LeaderboardEntry, error)
+	GetReferenceLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.LeaderboardEntry, error)
 	GetCrossGameLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error)
+	GetTeamLeaderboard(ctx context.Context, tournamentID uuid.UUID, normalize bool) ([]*domain.TeamRating, error)
 	CreateMatch(ctx context.Context, tournamentID, program1ID, program2ID uuid.UUID, priority domain.MatchPriority) (*domain.Match, error)
 	GetMatches(ctx context.Context, tournamentID uuid.UUID, limit, offset int) ([]*domain.Match, error)
+	GetParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) ([]*domain.ParticipantEntry, error)
+	CountParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) (int, error)
+	GetMatchesWithCursor(ctx context.Context, tournamentID uuid.UUID, pageReq *pagination.PageRequest) ([]*domain.Match, bool, error)
 	GetMatchesByRounds(ctx context.Context, tournamentID uuid.UUID) ([]*domain.MatchRound, error)
+	GetRoundMatches(ctx context.Context, tournamentID uuid.UUID, roundNumber, limit, offset int) (*domain.RoundMatches, error)
 	RunAllMatches(ctx context.Context, tournamentID uuid.UUID) (int, error)
-	RunGameMatches(ctx context.Context, tournamentID uuid.UUID, gameType string) (int, error)
+	ScheduleRound(ctx context.Context, tournamentID uuid.UUID, at time.Time) (int, error)
+	RunGameMatches(ctx context.Context, tournamentID uuid.UUID, gameType string, overrideWindow bool) (int, error)
+	RegenerateGameRound(ctx context.Context, tournamentID, gameID uuid.UUID) (*tournament.RegenerateRoundResult, error)
 	RetryFailedMatches(ctx context.Context, tournamentID uuid.UUID) (int, error)
+	KickParticipant(ctx context.Context, tournamentID, programID uuid.UUID, reason string) error
+	BanProgram(ctx context.Context, tournamentID, programID uuid.UUID, reason string) error
+	ImportParticipants(ctx context.Context, tournamentID uuid.UUID, req *tournament.ImportParticipantsRequest) ([]*domain.ImportedProgram, error)
 }
 
 // TournamentHandler обрабатывает запросы турниров
 type TournamentHandler struct {
 	tournamentService TournamentService
+	matchExport       MatchExportSource
+	programInfo       ProgramInfoLookup
+	stats             TournamentStatsSource
+	bracket           BracketReader
+	headToHead        TeamHeadToHeadLookup
+	access            *TournamentAccess
 	log               *logger.Logger
 }
 
@@ -45,6 +75,7 @@ type TournamentHandler struct {
 func NewTournamentHandler(tournamentService TournamentService, log *logger.Logger) *TournamentHandler {
 	return &TournamentHandler{
 		tournamentService: tournamentService,
+		access:            NewTournamentAccess(tournamentService, log),
 		log:               log,
 	}
 }
@@ -80,8 +111,107 @@ func (h *TournamentHandler) Create(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, t)
 }
 
-// List обрабатывает получение списка турниров
-// GET /api/v1/tournaments
+// Clone создаёт копию турнира с теми же настройками и играми
+// POST /api/v1/tournaments/:id/clone
+func (h *TournamentHandler) Clone(w http.ResponseWriter, r *http.Request) {
+	sourceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	var body struct {
+		Name        string                      `json:"name"`
+		Description string                      `json:"description"`
+		Visibility  domain.TournamentVisibility `json:"visibility"`
+		CreatorID   *uuid.UUID                  `json:"creator_id"`
+	}
+	// Тело необязательно - без него копируются все настройки исходного турнира
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.log.Info("Invalid request body", zap.Error(err))
+			writeError(w, errors.ErrInvalidInput.WithError(err))
+			return
+		}
+	}
+
+	if err := h.checkManageAccess(r, sourceID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	req := tournament.CreateRequest{
+		Name:        body.Name,
+		Description: body.Description,
+		Visibility:  body.Visibility,
+	}
+
+	// Назначить копию другому пользователю может только админ
+	if body.CreatorID != nil {
+		if role, _ := r.Context().Value(middleware.RoleKey).(domain.Role); role != domain.RoleAdmin {
+			writeError(w, errors.ErrForbidden.WithMessage("only admins can assign clone to another user"))
+			return
+		}
+		req.CreatorID = body.CreatorID
+	} else if userID, ok := r.Context().Value(middleware.UserIDKey).(uuid.UUID); ok {
+		req.CreatorID = &userID
+	}
+
+	t, err := h.tournamentService.CloneTournament(r.Context(), sourceID, req)
+	if err != nil {
+		h.log.LogError("Failed to clone tournament", err,
+			zap.String("source_id", sourceID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	h.log.Info("Tournament cloned",
+		zap.String("source_id", sourceID.String()),
+		zap.String("tournament_id", t.ID.String()),
+	)
+
+	writeJSON(w, http.StatusCreated, t)
+}
+
+// Update изменяет настройки турнира (сейчас - видимость). Доступно админам и создателю турнира
+// PATCH /api/v1/tournaments/:id
+func (h *TournamentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	var req tournament.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Info("Invalid request body", zap.Error(err))
+		writeError(w, errors.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	if err := h.checkManageAccess(r, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	t, err := h.tournamentService.Update(r.Context(), id, &req)
+	if err != nil {
+		h.log.LogError("Failed to update tournament", err,
+			zap.String("tournament_id", id.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newTournamentDetailResponse(t))
+}
+
+// List обрабатывает получение списка турниров.
+// Предпочтительна курсорная пагинация (first/after, last/before) с page_info в ответе;
+// limit/offset поддерживаются для обратной совместимости
+// С include_total=true ответ limit/offset - объект {items, total, limit, offset}
+// GET /api/v1/tournaments?status=&game_type=&include=stats&include_total=&first=&after=
 func (h *TournamentHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Получаем параметры фильтрации
 	filter := domain.TournamentFilter{}
@@ -94,7 +224,37 @@ func (h *TournamentHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Game type filter
 	filter.GameType = r.URL.Query().Get("game_type")
 
-	// Pagination
+	// Админам видны все турниры, остальным - публичные и свои приватные
+	if role, _ := r.Context().Value(middleware.RoleKey).(domain.Role); role == domain.RoleAdmin {
+		filter.AllVisibilities = true
+	} else if userID, ok := middleware.GetUserID(r.Context()); ok {
+		filter.ViewerID = &userID
+	}
+
+	// Удалённые турниры видны только админам
+	if r.URL.Query().Get("include_deleted") == "true" {
+		if role, _ := r.Context().Value(middleware.RoleKey).(domain.Role); role != domain.RoleAdmin {
+			writeError(w, errors.ErrForbidden.WithMessage("only admins can list deleted tournaments"))
+			return
+		}
+		filter.IncludeDeleted = true
+	}
+
+	// Курсорная пагинация (first/after или last/before) предпочтительнее limit/offset:
+	// турниры, созданные между запросами страниц, не приводят к повторам и пропускам
+	if pageReq, ok, err := parsePageRequest(r); err != nil {
+		writeError(w, err)
+		return
+	} else if ok {
+		if _, err := pageReq.GetCursor(); err != nil {
+			writeError(w, errors.ErrInvalidInput.WithMessage("invalid cursor"))
+			return
+		}
+		h.listWithCursor(w, r, filter, pageReq)
+		return
+	}
+
+	// Pagination (limit/offset оставлены для обратной совместимости)
 	limit := 50
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
@@ -119,9 +279,130 @@ func (h *TournamentHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Общее число турниров запрашивается явно: по умолчанию ответ остаётся массивом
+	total := -1
+	if includeTotal(r) {
+		total, err = h.tournamentService.CountWithFilter(r.Context(), filter)
+		if err != nil {
+			h.log.LogError("Failed to count tournaments", err)
+			writeError(w, err)
+			return
+		}
+	}
+
+	// Счётчики запрашиваются явно: обычный список не делает лишних запросов
+	if r.URL.Query().Get("include") == "stats" {
+		items, err := h.withStats(r.Context(), tournaments)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if total >= 0 {
+			writeJSON(w, http.StatusOK, pagination.NewOffsetPage(items, total, tournament.ListLimit(limit), offset))
+			return
+		}
+		writeJSON(w, http.StatusOK, items)
+		return
+	}
+
+	if total >= 0 {
+		writeJSON(w, http.StatusOK, pagination.NewOffsetPage(tournaments, total, tournament.ListLimit(limit), offset))
+		return
+	}
 	writeJSON(w, http.StatusOK, tournaments)
 }
 
+// listWithCursor отдаёт страницу турниров в формате connection с курсорами (created_at, id)
+func (h *TournamentHandler) listWithCursor(w http.ResponseWriter, r *http.Request, filter domain.TournamentFilter, pageReq *pagination.PageRequest) {
+	tournaments, hasMore, err := h.tournamentService.ListWithCursor(r.Context(), filter, pageReq)
+	if err != nil {
+		h.log.LogError("Failed to get tournaments page", err)
+		writeError(w, err)
+		return
+	}
+
+	if r.URL.Query().Get("include") == "stats" {
+		items, err := h.withStats(r.Context(), tournaments)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		conn, err := pagination.NewConnection(items, func(item tournamentListItem) (*pagination.Cursor, error) {
+			return db.GetTournamentCursor(item.Tournament)
+		}, pageReq, hasMore)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, conn)
+		return
+	}
+
+	conn, err := pagination.NewConnection(tournaments, db.GetTournamentCursor, pageReq, hasMore)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, conn)
+}
+
+// TournamentStatsSource интерфейс для пакетного подсчёта участников и матчей турниров
+type TournamentStatsSource interface {
+	GetParticipantCountsByTournamentIDs(ctx context.Context, tournamentIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	GetMatchStatsByTournamentIDs(ctx context.Context, tournamentIDs []uuid.UUID) (map[uuid.UUID]*domain.TournamentMatchStats, error)
+}
+
+// SetStatsSource устанавливает источник счётчиков для списка турниров (?include=stats)
+func (h *TournamentHandler) SetStatsSource(stats TournamentStatsSource) {
+	h.stats = stats
+}
+
+// tournamentListItem - турнир списка со счётчиками участников и матчей
+type tournamentListItem struct {
+	*domain.Tournament
+	ParticipantsCount int                         `json:"participants_count"`
+	Matches           domain.TournamentMatchStats `json:"matches"`
+	CompletionPercent float64                     `json:"completion_percent"`
+}
+
+// withStats дополняет страницу турниров счётчиками: по одному запросу на всю страницу
+func (h *TournamentHandler) withStats(ctx context.Context, tournaments []*domain.Tournament) ([]tournamentListItem, error) {
+	if h.stats == nil {
+		return nil, errors.ErrServiceUnavailable.WithMessage("tournament stats are not available")
+	}
+
+	ids := make([]uuid.UUID, len(tournaments))
+	for i, t := range tournaments {
+		ids[i] = t.ID
+	}
+
+	participants, err := h.stats.GetParticipantCountsByTournamentIDs(ctx, ids)
+	if err != nil {
+		h.log.LogError("Failed to count tournament participants", err)
+		return nil, err
+	}
+
+	matchStats, err := h.stats.GetMatchStatsByTournamentIDs(ctx, ids)
+	if err != nil {
+		h.log.LogError("Failed to get tournament match stats", err)
+		return nil, err
+	}
+
+	items := make([]tournamentListItem, len(tournaments))
+	for i, t := range tournaments {
+		items[i] = tournamentListItem{
+			Tournament:        t,
+			ParticipantsCount: participants[t.ID],
+		}
+		if stats, ok := matchStats[t.ID]; ok {
+			items[i].Matches = *stats
+		}
+		items[i].CompletionPercent = items[i].Matches.CompletionPercent()
+	}
+
+	return items, nil
+}
+
 // Get обрабатывает получение турнира
 // GET /api/v1/tournaments/:id
 func (h *TournamentHandler) Get(w http.ResponseWriter, r *http.Request) {
@@ -134,16 +415,35 @@ func (h *TournamentHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Получаем турнир
-	t, err := h.tournamentService.GetByID(r.Context(), id)
+	t, err := h.authorizeView(r, id)
 	if err != nil {
-		h.log.LogError("Failed to get tournament", err,
-			zap.String("tournament_id", id.String()),
-		)
+		if !errors.IsNotFound(err) {
+			h.log.LogError("Failed to get tournament", err,
+				zap.String("tournament_id", id.String()),
+			)
+		}
 		writeError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, t)
+	writeJSON(w, http.StatusOK, newTournamentDetailResponse(t))
+}
+
+// tournamentDetailResponse - детали турнира с состоянием автостарта
+type tournamentDetailResponse struct {
+	*domain.Tournament
+	ScheduledStart *time.Time `json:"scheduled_start,omitempty"`  // Запланированное время автостарта
+	AutoStartError *string    `json:"auto_start_error,omitempty"` // Причина последнего неудачного автостарта
+}
+
+// newTournamentDetailResponse формирует ответ; состояние автостарта актуально только до старта
+func newTournamentDetailResponse(t *domain.Tournament) *tournamentDetailResponse {
+	resp := &tournamentDetailResponse{Tournament: t}
+	if t.Status == domain.TournamentPending {
+		resp.ScheduledStart = t.StartTime
+		resp.AutoStartError = t.AutoStartError()
+	}
+	return resp
 }
 
 // Join обрабатывает присоединение к турниру
@@ -244,6 +544,84 @@ func (h *TournamentHandler) Complete(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "completed"})
 }
 
+// forceCompleteRequest тело запроса принудительного завершения
+type forceCompleteRequest struct {
+	CancelPending bool `json:"cancel_pending"`
+}
+
+// ForceComplete принудительно завершает турнир с несыгранными матчами
+// POST /api/v1/tournaments/:id/force-complete
+func (h *TournamentHandler) ForceComplete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	// Тело необязательно: без него незавершённые матчи не отменяются
+	var req forceCompleteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.log.Info("Invalid request body", zap.Error(err))
+			writeError(w, errors.ErrInvalidInput.WithError(err))
+			return
+		}
+	}
+
+	result, err := h.tournamentService.ForceComplete(r.Context(), id, req.CancelPending)
+	if err != nil {
+		h.log.LogError("Failed to force-complete tournament", err,
+			zap.String("tournament_id", id.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	adminID, _ := middleware.GetUserID(r.Context())
+	h.log.Info("Tournament force-completed by admin",
+		zap.Bool("audit", true),
+		zap.String("tournament_id", id.String()),
+		zap.String("admin_id", adminID.String()),
+		zap.Int64("cancelled_matches", result.CancelledMatches),
+	)
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// Cancel отменяет ожидающий или активный турнир вместе с несыгранными матчами
+// POST /api/v1/tournaments/:id/cancel
+func (h *TournamentHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	cancelled, err := h.tournamentService.Cancel(r.Context(), id)
+	if err != nil {
+		if !errors.IsConflict(err) && !errors.IsNotFound(err) {
+			h.log.LogError("Failed to cancel tournament", err,
+				zap.String("tournament_id", id.String()),
+			)
+		}
+		writeError(w, err)
+		return
+	}
+
+	adminID, _ := middleware.GetUserID(r.Context())
+	h.log.Info("Tournament cancelled by admin",
+		zap.Bool("audit", true),
+		zap.String("tournament_id", id.String()),
+		zap.String("admin_id", adminID.String()),
+		zap.Int64("cancelled_matches", cancelled),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":            domain.TournamentCancelled,
+		"cancelled_matches": cancelled,
+	})
+}
+
 // Delete обрабатывает удаление турнира
 // DELETE /api/v1/tournaments/:id
 func (h *TournamentHandler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -271,6 +649,55 @@ func (h *TournamentHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Restore восстанавливает мягко удалённый турнир
+// POST /api/v1/tournaments/:id/restore
+func (h *TournamentHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	t, err := h.tournamentService.Restore(r.Context(), id)
+	if err != nil {
+		h.log.LogError("Failed to restore tournament", err,
+			zap.String("tournament_id", id.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	h.log.Info("Tournament restored",
+		zap.String("tournament_id", id.String()),
+	)
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+// Purge окончательно удаляет турнир с матчами и участниками
+// DELETE /api/v1/tournaments/:id/purge
+func (h *TournamentHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	if err := h.tournamentService.Purge(r.Context(), id); err != nil {
+		h.log.LogError("Failed to purge tournament", err,
+			zap.String("tournament_id", id.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	h.log.Info("Tournament purged",
+		zap.String("tournament_id", id.String()),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // GetLeaderboard обрабатывает получение таблицы лидеров
 // GET /api/v1/tournaments/:id/leaderboard
 func (h *TournamentHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
@@ -290,6 +717,11 @@ func (h *TournamentHandler) GetLeaderboard(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
+	if _, err := h.authorizeView(r, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	// Получаем leaderboard
 	leaderboard, err := h.tournamentService.GetLeaderboard(r.Context(), id, limit)
 	if err != nil {
@@ -303,6 +735,35 @@ func (h *TournamentHandler) GetLeaderboard(w http.ResponseWriter, r *http.Reques
 	writeJSON(w, http.StatusOK, leaderboard)
 }
 
+// GetReferenceLeaderboard обрабатывает получение результатов эталонных ботов турнира
+// GET /api/v1/tournaments/:id/leaderboard/reference
+func (h *TournamentHandler) GetReferenceLeaderboard(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	if _, err := h.authorizeView(r, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	leaderboard, err := h.tournamentService.GetReferenceLeaderboard(r.Context(), id)
+	if err != nil {
+		h.log.LogError("Failed to get reference leaderboard", err,
+			zap.String("tournament_id", id.String()),
+		)
+		writeError(w, err)
+		return
+	}
+	if leaderboard == nil {
+		leaderboard = []*domain.LeaderboardEntry{}
+	}
+
+	writeJSON(w, http.StatusOK, leaderboard)
+}
+
 // CreateMatch обрабатывает создание матча
 // POST /api/v1/tournaments/:id/matches
 func (h *TournamentHandler) CreateMatch(w http.ResponseWriter, r *http.Request) {
@@ -372,6 +833,11 @@ func (h *TournamentHandler) GetCrossGameLeaderboard(w http.ResponseWriter, r *ht
 		return
 	}
 
+	if _, err := h.authorizeView(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	// Получаем кросс-игровой рейтинг
 	entries, err := h.tournamentService.GetCrossGameLeaderboard(r.Context(), tournamentID)
 	if err != nil {
@@ -385,8 +851,39 @@ func (h *TournamentHandler) GetCrossGameLeaderboard(w http.ResponseWriter, r *ht
 	writeJSON(w, http.StatusOK, entries)
 }
 
-// GetMatches обрабатывает получение списка матчей турнира
-// GET /api/v1/tournaments/:id/matches
+// GetTeamLeaderboard возвращает рейтинг команд по всем играм турнира.
+// normalize=true усредняет нормированные по каждой игре очки вместо суммы
+// GET /api/v1/tournaments/:id/team-leaderboard?normalize=true
+func (h *TournamentHandler) GetTeamLeaderboard(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	if _, err := h.authorizeView(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	normalize := r.URL.Query().Get("normalize") == "true"
+
+	ratings, err := h.tournamentService.GetTeamLeaderboard(r.Context(), tournamentID, normalize)
+	if err != nil {
+		h.log.LogError("Failed to get team leaderboard", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ratings)
+}
+
+// GetMatches обрабатывает получение списка матчей турнира.
+// Предпочтительна курсорная пагинация (first/after, last/before) с page_info в ответе;
+// limit/offset поддерживаются для обратной совместимости
+// GET /api/v1/tournaments/:id/matches?first=&after=
 func (h *TournamentHandler) GetMatches(w http.ResponseWriter, r *http.Request) {
 	// Извлекаем ID турнира из URL
 	idStr := chi.URLParam(r, "id")
@@ -396,7 +893,29 @@ func (h *TournamentHandler) GetMatches(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Получаем параметры пагинации
+	if _, err := h.authorizeView(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Курсорная пагинация предпочтительнее limit/offset: матчи новых раундов не сдвигают страницы
+	if pageReq, ok, err := parseKeysetPageRequest(r); err != nil {
+		writeError(w, err)
+		return
+	} else if ok {
+		matches, hasMore, err := h.tournamentService.GetMatchesWithCursor(r.Context(), tournamentID, pageReq)
+		if err != nil {
+			h.log.LogError("Failed to get matches page", err,
+				zap.String("tournament_id", tournamentID.String()),
+			)
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, pagination.NewKeysetPage(matches, db.MatchKeysetCursor, pageReq, hasMore))
+		return
+	}
+
+	// Получаем параметры пагинации (limit/offset оставлены для обратной совместимости)
 	limit := 50
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
@@ -435,6 +954,11 @@ func (h *TournamentHandler) GetMatchesByRounds(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if _, err := h.authorizeView(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	// Получаем матчи по раундам
 	rounds, err := h.tournamentService.GetMatchesByRounds(r.Context(), tournamentID)
 	if err != nil {
@@ -448,6 +972,131 @@ func (h *TournamentHandler) GetMatchesByRounds(w http.ResponseWriter, r *http.Re
 	writeJSON(w, http.StatusOK, rounds)
 }
 
+// GetRoundMatches обрабатывает получение матчей одного раунда со сводкой по раунду
+// GET /api/v1/tournaments/:id/matches/rounds/:round?limit=&offset=
+func (h *TournamentHandler) GetRoundMatches(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	roundNumber, err := strconv.Atoi(chi.URLParam(r, "round"))
+	if err != nil || roundNumber < 1 {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid round number"))
+		return
+	}
+
+	// Получаем параметры пагинации
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+
+	offset := 0
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	if _, err := h.authorizeView(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	round, err := h.tournamentService.GetRoundMatches(r.Context(), tournamentID, roundNumber, limit, offset)
+	if err != nil {
+		h.log.LogError("Failed to get round matches", err,
+			zap.String("tournament_id", tournamentID.String()),
+			zap.Int("round", roundNumber),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, round)
+}
+
+// ListParticipants возвращает участников турнира в порядке регистрации с данными программы,
+// команды и игры. По умолчанию - только последние версии программ (latest_only=true)
+// GET /api/v1/tournaments/:id/participants?game_id=&latest_only=&limit=&offset=&include_total=
+func (h *TournamentHandler) ListParticipants(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	filter := domain.ParticipantFilter{LatestOnly: true}
+
+	if gameIDStr := r.URL.Query().Get("game_id"); gameIDStr != "" {
+		gameID, err := uuid.Parse(gameIDStr)
+		if err != nil {
+			writeError(w, errors.ErrInvalidInput.WithMessage("invalid game ID"))
+			return
+		}
+		filter.GameID = &gameID
+	}
+
+	if latestStr := r.URL.Query().Get("latest_only"); latestStr != "" {
+		latest, err := strconv.ParseBool(latestStr)
+		if err != nil {
+			writeError(w, errors.ErrInvalidInput.WithMessage("latest_only must be true or false"))
+			return
+		}
+		filter.LatestOnly = latest
+	}
+
+	// Получаем параметры пагинации
+	filter.Limit = 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+			filter.Limit = l
+		}
+	}
+
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			filter.Offset = o
+		}
+	}
+
+	if _, err := h.authorizeView(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	participants, err := h.tournamentService.GetParticipants(r.Context(), tournamentID, filter)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			h.log.LogError("Failed to get participants", err,
+				zap.String("tournament_id", tournamentID.String()),
+			)
+		}
+		writeError(w, err)
+		return
+	}
+
+	// Общее число участников запрашивается явно: по умолчанию ответ остаётся массивом
+	if includeTotal(r) {
+		total, err := h.tournamentService.CountParticipants(r.Context(), tournamentID, filter)
+		if err != nil {
+			h.log.LogError("Failed to count participants", err,
+				zap.String("tournament_id", tournamentID.String()),
+			)
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, pagination.NewOffsetPage(participants, total, filter.Limit, filter.Offset))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, participants)
+}
+
 // RunAllMatches запускает все ожидающие матчи турнира
 // POST /api/v1/tournaments/:id/run-matches
 func (h *TournamentHandler) RunAllMatches(w http.ResponseWriter, r *http.Request) {
@@ -480,7 +1129,53 @@ func (h *TournamentHandler) RunAllMatches(w http.ResponseWriter, r *http.Request
 	})
 }
 
-// RunGameMatches запускает матчи для конкретной игры в турнире
+// ScheduleRound создаёт раунд матчей, который начнёт выполняться в указанное время
+// POST /api/v1/tournaments/:id/schedule-round
+func (h *TournamentHandler) ScheduleRound(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	var req struct {
+		ScheduledAt *time.Time `json:"scheduled_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Info("Invalid request body", zap.Error(err))
+		writeError(w, errors.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	if req.ScheduledAt == nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("scheduled_at is required"))
+		return
+	}
+
+	created, err := h.tournamentService.ScheduleRound(r.Context(), tournamentID, *req.ScheduledAt)
+	if err != nil {
+		h.log.LogError("Failed to schedule round", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	h.log.Info("Round scheduled",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.Time("scheduled_at", *req.ScheduledAt),
+		zap.Int("matches", created),
+	)
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":       "scheduled",
+		"matches":      created,
+		"scheduled_at": req.ScheduledAt.UTC(),
+	})
+}
+
+// RunGameMatches запускает матчи для конкретной игры в турнире.
+// override_window запускает матчи вне окна игры
 // POST /api/v1/tournaments/:id/games/:gameId/run-matches
 func (h *TournamentHandler) RunGameMatches(w http.ResponseWriter, r *http.Request) {
 	tournamentIDStr := chi.URLParam(r, "id")
@@ -492,7 +1187,8 @@ func (h *TournamentHandler) RunGameMatches(w http.ResponseWriter, r *http.Reques
 
 	// Декодируем тело запроса для получения game_type
 	var req struct {
-		GameType string `json:"game_type"`
+		GameType       string `json:"game_type"`
+		OverrideWindow bool   `json:"override_window"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.log.Info("Invalid request body", zap.Error(err))
@@ -506,7 +1202,7 @@ func (h *TournamentHandler) RunGameMatches(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Запускаем матчи для игры
-	enqueued, err := h.tournamentService.RunGameMatches(r.Context(), tournamentID, req.GameType)
+	enqueued, err := h.tournamentService.RunGameMatches(r.Context(), tournamentID, req.GameType, req.OverrideWindow)
 	if err != nil {
 		h.log.LogError("Failed to run game matches", err,
 			zap.String("tournament_id", tournamentID.String()),
@@ -558,3 +1254,223 @@ func (h *TournamentHandler) RetryFailedMatches(w http.ResponseWriter, r *http.Re
 		"enqueued": enqueued,
 	})
 }
+
+// maxReasonLength максимальная длина причины исключения/бана
+const maxReasonLength = 500
+
+// checkManageAccess проверяет, что пользователь - админ или создатель турнира
+func (h *TournamentHandler) checkManageAccess(r *http.Request, tournamentID uuid.UUID) error {
+	userID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		return err
+	}
+
+	if role, _ := r.Context().Value(middleware.RoleKey).(domain.Role); role == domain.RoleAdmin {
+		return nil
+	}
+
+	t, err := h.tournamentService.GetByID(r.Context(), tournamentID)
+	if err != nil {
+		return err
+	}
+
+	if t.CreatorID == nil || *t.CreatorID != userID {
+		return errors.ErrForbidden.WithMessage("only admins or tournament creator can manage participants")
+	}
+
+	return nil
+}
+
+// normalizeReason проверяет и нормализует причину исключения/бана
+func normalizeReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", errors.ErrInvalidInput.WithMessage("reason is required")
+	}
+	if len(reason) > maxReasonLength {
+		return "", errors.ErrInvalidInput.WithMessage(fmt.Sprintf("reason must be at most %d characters", maxReasonLength))
+	}
+	return reason, nil
+}
+
+// KickParticipant исключает программу из турнира
+// DELETE /api/v1/tournaments/:id/participants/:programID
+func (h *TournamentHandler) KickParticipant(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	programID, err := uuid.Parse(chi.URLParam(r, "programID"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid program ID"))
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Info("Invalid request body", zap.Error(err))
+		writeError(w, errors.ErrInvalidInput.WithMessage("reason is required"))
+		return
+	}
+
+	reason, err := normalizeReason(req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.checkManageAccess(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.tournamentService.KickParticipant(r.Context(), tournamentID, programID, reason); err != nil {
+		h.log.LogError("Failed to kick participant", err,
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("program_id", programID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	h.log.Info("Participant kicked",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.String("program_id", programID.String()),
+		zap.String("reason", reason),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BanProgram банит программу в турнире
+// POST /api/v1/tournaments/:id/bans
+func (h *TournamentHandler) BanProgram(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	var req struct {
+		ProgramID uuid.UUID `json:"program_id"`
+		Reason    string    `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Info("Invalid request body", zap.Error(err))
+		writeError(w, errors.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	if req.ProgramID == uuid.Nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("program_id is required"))
+		return
+	}
+
+	reason, err := normalizeReason(req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.checkManageAccess(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.tournamentService.BanProgram(r.Context(), tournamentID, req.ProgramID, reason); err != nil {
+		h.log.LogError("Failed to ban program", err,
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("program_id", req.ProgramID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	h.log.Info("Program banned",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.String("program_id", req.ProgramID.String()),
+		zap.String("reason", reason),
+	)
+
+	writeJSON(w, http.StatusCreated, map[string]string{"status": "banned"})
+}
+
+// ImportParticipants переносит программы лучших команд другого турнира (например, отборочного).
+// Доступно админам или создателю обоих турниров
+// POST /api/v1/tournaments/:id/import-from
+func (h *TournamentHandler) ImportParticipants(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	var req tournament.ImportParticipantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Info("Invalid request body", zap.Error(err))
+		writeError(w, errors.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	if err := h.checkManageAccess(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.SourceTournamentID != uuid.Nil {
+		if err := h.checkManageAccess(r, req.SourceTournamentID); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	imported, err := h.tournamentService.ImportParticipants(r.Context(), tournamentID, &req)
+	if err != nil {
+		h.log.LogError("Failed to import participants", err,
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("source_tournament_id", req.SourceTournamentID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"imported": imported,
+	})
+}
+
+// RegenerateGameRound отбрасывает несыгранные матчи текущего раунда игры и создаёт раунд заново
+// POST /api/v1/tournaments/:id/games/:gameId/regenerate-round
+func (h *TournamentHandler) RegenerateGameRound(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	gameID, err := uuid.Parse(chi.URLParam(r, "gameId"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid game ID"))
+		return
+	}
+
+	if err := h.checkManageAccess(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	result, err := h.tournamentService.RegenerateGameRound(r.Context(), tournamentID, gameID)
+	if err != nil {
+		h.log.LogError("Failed to regenerate game round", err,
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("game_id", gameID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// BracketReader интерфейс для чтения сетки турнира на выбывание
+type BracketReader interface {
+	Get(ctx context.Context, tournamentID uuid.UUID) (*domain.Bracket, error)
+}
+
+// SetBracketReader устанавливает источник сеток турниров на выбывание
+func (h *TournamentHandler) SetBracketReader(bracket BracketReader) {
+	h.bracket = bracket
+}
+
+// GetBracket возвращает сетку турнира на выбывание по раундам
+// GET /api/v1/tournaments/{id}/bracket
+func (h *TournamentHandler) GetBracket(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	if h.bracket == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("brackets are not available"))
+		return
+	}
+
+	if _, err := h.authorizeView(r, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	bracket, err := h.bracket.Get(r.Context(), id)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			h.log.LogError("Failed to get bracket", err,
+				zap.String("tournament_id", id.String()),
+			)
+		}
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bracket)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockBracketReader struct {
+	mock.Mock
+}
+
+func (m *MockBracketReader) Get(ctx context.Context, tournamentID uuid.UUID) (*domain.Bracket, error) {
+	args := m.Called(ctx, tournamentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Bracket), args.Error(1)
+}
+
+func newBracketRequest(tournamentID string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments/"+tournamentID+"/bracket", nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", tournamentID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestTournamentHandler_GetBracket(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+
+	t.Run("returns rounds", func(t *testing.T) {
+		p1, p2 := uuid.New(), uuid.New()
+		seed1, seed2 := 1, 2
+		reader := new(MockBracketReader)
+		reader.On("Get", mock.Anything, tournamentID).Return(&domain.Bracket{
+			TournamentID: tournamentID,
+			Size:         2,
+			Seeding:      domain.SeedingRating,
+			DrawRule:     domain.DrawHigherSeed,
+			ChampionID:   &p1,
+			Rounds: [][]*domain.BracketSlot{{
+				{Round: 1, Position: 0, Program1ID: &p1, Program2ID: &p2, Seed1: &seed1, Seed2: &seed2, WinnerID: &p1},
+			}},
+		}, nil)
+
+		handler := NewTournamentHandler(newPublicTournamentService(), log)
+		handler.SetBracketReader(reader)
+
+		w := httptest.NewRecorder()
+		handler.GetBracket(w, newBracketRequest(tournamentID.String()))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body domain.Bracket
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		assert.Equal(t, 2, body.Size)
+		require.NotNil(t, body.ChampionID)
+		assert.Equal(t, p1, *body.ChampionID)
+		require.Len(t, body.Rounds, 1)
+		assert.Equal(t, p2, *body.Rounds[0][0].Program2ID)
+		reader.AssertExpectations(t)
+	})
+
+	t.Run("not seeded", func(t *testing.T) {
+		reader := new(MockBracketReader)
+		reader.On("Get", mock.Anything, tournamentID).Return(nil, errors.ErrNotFound.WithMessage("bracket not found"))
+
+		handler := NewTournamentHandler(newPublicTournamentService(), log)
+		handler.SetBracketReader(reader)
+
+		w := httptest.NewRecorder()
+		handler.GetBracket(w, newBracketRequest(tournamentID.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		handler := NewTournamentHandler(newPublicTournamentService(), log)
+		handler.SetBracketReader(new(MockBracketReader))
+
+		w := httptest.NewRecorder()
+		handler.GetBracket(w, newBracketRequest("not-a-uuid"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		handler := NewTournamentHandler(newPublicTournamentService(), log)
+
+		w := httptest.NewRecorder()
+		handler.GetBracket(w, newBracketRequest(tournamentID.String()))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
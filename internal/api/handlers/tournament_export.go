@@ -0,0 +1,570 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// exportPageSize размер страницы при потоковом экспорте матчей
+const exportPageSize = 100
+
+// MatchExportSource интерфейс для постраничного чтения матчей (экспорт турнира, история программы)
+type MatchExportSource interface {
+	ListWithCursor(ctx context.Context, filter domain.MatchFilter, pageReq *pagination.PageRequest) ([]*domain.Match, bool, error)
+}
+
+// ProgramInfoLookup интерфейс для получения названий программ и команд
+type ProgramInfoLookup interface {
+	GetInfoByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*domain.ProgramInfo, error)
+}
+
+// SetMatchExporter устанавливает зависимости для экспорта матчей
+func (h *TournamentHandler) SetMatchExporter(matches MatchExportSource, programs ProgramInfoLookup) {
+	h.matchExport = matches
+	h.programInfo = programs
+}
+
+// matchExportRow строка экспорта матча
+type matchExportRow struct {
+	ID           uuid.UUID  `json:"id"`
+	RoundNumber  int        `json:"round_number"`
+	GameType     string     `json:"game_type"`
+	Program1ID   uuid.UUID  `json:"program1_id"`
+	Program1Name string     `json:"program1_name"`
+	Team1Name    string     `json:"team1_name"`
+	Program2ID   uuid.UUID  `json:"program2_id"`
+	Program2Name string     `json:"program2_name"`
+	Team2Name    string     `json:"team2_name"`
+	Score1       *int       `json:"score1"`
+	Score2       *int       `json:"score2"`
+	Winner       *int       `json:"winner"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+}
+
+// matchExportHeader заголовок CSV, порядок совпадает с matchExportRow.csvRecord
+var matchExportHeader = []string{
+	"id", "round_number", "game_type",
+	"program1_id", "program1_name", "team1_name",
+	"program2_id", "program2_name", "team2_name",
+	"score1", "score2", "winner", "status",
+	"created_at", "started_at", "completed_at",
+}
+
+// csvRecord возвращает строку для CSV
+func (row *matchExportRow) csvRecord() []string {
+	optInt := func(v *int) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	}
+	optTime := func(v *time.Time) string {
+		if v == nil {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		row.ID.String(), strconv.Itoa(row.RoundNumber), row.GameType,
+		row.Program1ID.String(), row.Program1Name, row.Team1Name,
+		row.Program2ID.String(), row.Program2Name, row.Team2Name,
+		optInt(row.Score1), optInt(row.Score2), optInt(row.Winner), row.Status,
+		row.CreatedAt.UTC().Format(time.RFC3339), optTime(row.StartedAt), optTime(row.CompletedAt),
+	}
+}
+
+// matchRowWriter пишет строки экспорта в выбранном формате
+type matchRowWriter interface {
+	writeHeader() error
+	writeRow(row *matchExportRow) error
+	flush() error
+}
+
+// csvMatchWriter пишет матчи в CSV
+type csvMatchWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvMatchWriter) writeHeader() error {
+	return c.w.Write(matchExportHeader)
+}
+
+func (c *csvMatchWriter) writeRow(row *matchExportRow) error {
+	return c.w.Write(row.csvRecord())
+}
+
+func (c *csvMatchWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlMatchWriter пишет матчи в JSON Lines (одна JSON запись на строку)
+type jsonlMatchWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlMatchWriter) writeHeader() error {
+	return nil
+}
+
+func (j *jsonlMatchWriter) writeRow(row *matchExportRow) error {
+	return j.enc.Encode(row)
+}
+
+func (j *jsonlMatchWriter) flush() error {
+	return nil
+}
+
+// newMatchRowWriter создаёт writer для формата экспорта
+func newMatchRowWriter(format string, w io.Writer) matchRowWriter {
+	if format == "jsonl" {
+		return &jsonlMatchWriter{enc: json.NewEncoder(w)}
+	}
+	return &csvMatchWriter{w: csv.NewWriter(w)}
+}
+
+// ExportMatches выгружает все матчи турнира потоком в CSV или JSON Lines
+// GET /api/v1/tournaments/:id/matches/export?format=csv|jsonl&game_type=xxx
+func (h *TournamentHandler) ExportMatches(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	var contentType string
+	switch format {
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+	case "jsonl":
+		contentType = "application/x-ndjson"
+	default:
+		writeError(w, errors.ErrInvalidInput.WithMessage("format must be csv or jsonl"))
+		return
+	}
+
+	if h.matchExport == nil || h.programInfo == nil {
+		writeError(w, errors.ErrInternal.WithMessage("match export is not configured"))
+		return
+	}
+
+	if err := h.checkManageAccess(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	filter := domain.MatchFilter{
+		TournamentID: &tournamentID,
+		GameType:     r.URL.Query().Get("game_type"),
+	}
+
+	exporter := &matchExporter{
+		source:   h.matchExport,
+		programs: h.programInfo,
+		filter:   filter,
+		infos:    make(map[uuid.UUID]*domain.ProgramInfo),
+	}
+
+	// Первую страницу читаем до записи заголовков, чтобы вернуть ошибку в JSON
+	rows, err := exporter.next(r.Context())
+	if err != nil {
+		h.log.LogError("Failed to export matches", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"tournament_%s_matches.%s\"", tournamentID.String(), format))
+	w.WriteHeader(http.StatusOK)
+
+	out := newMatchRowWriter(format, w)
+	flusher, _ := w.(http.Flusher)
+
+	exported, err := streamMatchRows(r.Context(), exporter, out, flusher, rows)
+	if err != nil {
+		// Заголовки уже отправлены - можем только прервать поток
+		h.log.LogError("Match export interrupted", err,
+			zap.String("tournament_id", tournamentID.String()),
+			zap.Int("exported", exported),
+		)
+		return
+	}
+
+	h.log.Info("Matches exported",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.String("format", format),
+		zap.Int("count", exported),
+	)
+}
+
+// streamMatchRows пишет все страницы матчей, начиная с уже прочитанной первой
+func streamMatchRows(ctx context.Context, exporter *matchExporter, out matchRowWriter, flusher http.Flusher, rows []*matchExportRow) (int, error) {
+	if err := out.writeHeader(); err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	for {
+		for _, row := range rows {
+			if err := out.writeRow(row); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		if err := out.flush(); err != nil {
+			return exported, err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if exporter.done {
+			return exported, nil
+		}
+
+		var err error
+		rows, err = exporter.next(ctx)
+		if err != nil {
+			return exported, err
+		}
+	}
+}
+
+// matchExporter читает матчи страницами по курсору и дополняет их названиями программ
+type matchExporter struct {
+	source   MatchExportSource
+	programs ProgramInfoLookup
+	filter   domain.MatchFilter
+	after    *string
+	done     bool
+	infos    map[uuid.UUID]*domain.ProgramInfo
+}
+
+// next читает следующую страницу матчей
+func (e *matchExporter) next(ctx context.Context) ([]*matchExportRow, error) {
+	first := exportPageSize
+	matches, hasMore, err := e.source.ListWithCursor(ctx, e.filter, &pagination.PageRequest{
+		First: &first,
+		After: e.after,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasMore || len(matches) == 0 {
+		e.done = true
+	} else {
+		cursor := db.GetMatchKeysetCursor(matches[len(matches)-1])
+		e.after = &cursor
+	}
+
+	// Догружаем только неизвестные программы - их число ограничено участниками турнира
+	var missing []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, m := range matches {
+		for _, id := range []uuid.UUID{m.Program1ID, m.Program2ID} {
+			if _, ok := e.infos[id]; !ok && !seen[id] {
+				seen[id] = true
+				missing = append(missing, id)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		infos, err := e.programs.GetInfoByIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range missing {
+			e.infos[id] = infos[id]
+		}
+	}
+
+	rows := make([]*matchExportRow, 0, len(matches))
+	for _, m := range matches {
+		row := &matchExportRow{
+			ID:          m.ID,
+			RoundNumber: m.RoundNumber,
+			GameType:    m.GameType,
+			Program1ID:  m.Program1ID,
+			Program2ID:  m.Program2ID,
+			Score1:      m.Score1,
+			Score2:      m.Score2,
+			Winner:      m.Winner,
+			Status:      string(m.Status),
+			CreatedAt:   m.CreatedAt,
+			StartedAt:   m.StartedAt,
+			CompletedAt: m.CompletedAt,
+		}
+		row.Program1Name, row.Team1Name = programLabels(e.infos[m.Program1ID])
+		row.Program2Name, row.Team2Name = programLabels(e.infos[m.Program2ID])
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// programLabels возвращает название программы и команды (пустые строки, если неизвестны)
+func programLabels(info *domain.ProgramInfo) (string, string) {
+	if info == nil {
+		return "", ""
+	}
+	if info.TeamName == nil {
+		return info.ProgramName, ""
+	}
+	return info.ProgramName, *info.TeamName
+}
+
+// resultsExportLeaderboardLimit максимум записей при экспорте рейтинга программ
+const resultsExportLeaderboardLimit = 1000
+
+// resultsTable итоговая таблица для экспорта: заголовок CSV и построчная сериализация
+type resultsTable struct {
+	header []string
+	size   int
+	record func(i int) []string
+	item   func(i int) interface{}
+}
+
+// ExportResults выгружает итоговую таблицу турнира потоком в CSV или JSON
+// По умолчанию выгружается кросс-игровой рейтинг (с рейтингами по каждой игре),
+// type=leaderboard - рейтинг программ турнира. Доступ совпадает с доступом к рейтингу
+// GET /api/v1/tournaments/:id/export?format=csv|json&type=cross-game|leaderboard
+func (h *TournamentHandler) ExportResults(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		writeError(w, errors.ErrInvalidInput.WithMessage("format must be csv or json"))
+		return
+	}
+
+	exportType := r.URL.Query().Get("type")
+	if exportType == "" {
+		exportType = "cross-game"
+	}
+
+	if _, err := h.authorizeView(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var table *resultsTable
+	switch exportType {
+	case "cross-game":
+		entries, err := h.tournamentService.GetCrossGameLeaderboard(r.Context(), tournamentID)
+		if err != nil {
+			h.log.LogError("Failed to export results", err,
+				zap.String("tournament_id", tournamentID.String()),
+			)
+			writeError(w, err)
+			return
+		}
+		table = crossGameResultsTable(entries)
+	case "leaderboard":
+		entries, err := h.tournamentService.GetLeaderboard(r.Context(), tournamentID, resultsExportLeaderboardLimit)
+		if err != nil {
+			h.log.LogError("Failed to export results", err,
+				zap.String("tournament_id", tournamentID.String()),
+			)
+			writeError(w, err)
+			return
+		}
+		table = leaderboardResultsTable(entries)
+	default:
+		writeError(w, errors.ErrInvalidInput.WithMessage("type must be cross-game or leaderboard"))
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == "json" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"tournament_%s_results.%s\"", tournamentID.String(), format))
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	if format == "json" {
+		err = streamResultsJSON(w, flusher, table)
+	} else {
+		err = streamResultsCSV(w, flusher, table)
+	}
+	if err != nil {
+		// Заголовки уже отправлены - можем только прервать поток
+		h.log.LogError("Results export interrupted", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		return
+	}
+
+	h.log.Info("Results exported",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.String("format", format),
+		zap.String("type", exportType),
+		zap.Int("count", table.size),
+	)
+}
+
+// crossGameResultsTable таблица кросс-игрового рейтинга с колонкой рейтинга для каждой игры
+// Колонки игр упорядочены по названию, чтобы файл был одинаковым между выгрузками
+func crossGameResultsTable(entries []*domain.CrossGameLeaderboardEntry) *resultsTable {
+	gameSet := make(map[string]bool)
+	for _, e := range entries {
+		for _, info := range e.GameRatings {
+			gameSet[info.GameName] = true
+		}
+	}
+	games := make([]string, 0, len(gameSet))
+	for name := range gameSet {
+		games = append(games, name)
+	}
+	sort.Strings(games)
+
+	header := []string{"rank", "team", "program"}
+	for _, game := range games {
+		header = append(header, game+"_rating")
+	}
+	header = append(header, "wins", "losses", "draws", "total_games", "total_rating")
+
+	return &resultsTable{
+		header: header,
+		size:   len(entries),
+		item:   func(i int) interface{} { return entries[i] },
+		record: func(i int) []string {
+			e := entries[i]
+			ratings := make(map[string]domain.GameRatingInfo, len(e.GameRatings))
+			draws := 0
+			for _, info := range e.GameRatings {
+				ratings[info.GameName] = info
+				draws += info.Draws
+			}
+
+			record := []string{strconv.Itoa(e.Rank), e.TeamName, e.ProgramName}
+			for _, game := range games {
+				if info, ok := ratings[game]; ok {
+					record = append(record, strconv.Itoa(info.Rating))
+				} else {
+					record = append(record, "")
+				}
+			}
+			return append(record,
+				strconv.Itoa(e.TotalWins),
+				strconv.Itoa(e.TotalLosses),
+				strconv.Itoa(draws),
+				strconv.Itoa(e.TotalGames),
+				strconv.Itoa(e.TotalRating),
+			)
+		},
+	}
+}
+
+// leaderboardResultsTable таблица рейтинга программ турнира
+func leaderboardResultsTable(entries []*domain.LeaderboardEntry) *resultsTable {
+	return &resultsTable{
+		header: []string{"rank", "team", "program", "wins", "losses", "draws", "total_games", "total_rating"},
+		size:   len(entries),
+		item:   func(i int) interface{} { return entries[i] },
+		record: func(i int) []string {
+			e := entries[i]
+			team := ""
+			if e.TeamName != nil {
+				team = *e.TeamName
+			}
+			return []string{
+				strconv.Itoa(e.Rank), team, e.ProgramName,
+				strconv.Itoa(e.Wins), strconv.Itoa(e.Losses), strconv.Itoa(e.Draws),
+				strconv.Itoa(e.TotalGames), strconv.Itoa(e.Rating),
+			}
+		},
+	}
+}
+
+// streamResultsCSV пишет таблицу построчно, сбрасывая буфер клиенту каждые exportPageSize строк
+func streamResultsCSV(w io.Writer, flusher http.Flusher, table *resultsTable) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(table.header); err != nil {
+		return err
+	}
+
+	for i := 0; i < table.size; i++ {
+		if err := cw.Write(table.record(i)); err != nil {
+			return err
+		}
+		if (i+1)%exportPageSize == 0 {
+			cw.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+
+	cw.Flush()
+	if flusher != nil {
+		flusher.Flush()
+	}
+	return cw.Error()
+}
+
+// streamResultsJSON пишет таблицу как JSON массив поэлементно
+func streamResultsJSON(w io.Writer, flusher http.Flusher, table *resultsTable) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for i := 0; i < table.size; i++ {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(table.item(i)); err != nil {
+			return err
+		}
+		if flusher != nil && (i+1)%exportPageSize == 0 {
+			flusher.Flush()
+		}
+	}
+
+	if _, err := io.WriteString(w, "]\n"); err != nil {
+		return err
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+	return nil
+}
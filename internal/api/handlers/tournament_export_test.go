@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockMatchExportSource struct {
+	mock.Mock
+}
+
+func (m *MockMatchExportSource) ListWithCursor(ctx context.Context, filter domain.MatchFilter, pageReq *pagination.PageRequest) ([]*domain.Match, bool, error) {
+	args := m.Called(ctx, filter, pageReq)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Match), args.Bool(1), args.Error(2)
+}
+
+type MockProgramInfoLookup struct {
+	mock.Mock
+}
+
+func (m *MockProgramInfoLookup) GetInfoByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*domain.ProgramInfo, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*domain.ProgramInfo), args.Error(1)
+}
+
+func newExportRequest(tournamentID uuid.UUID, query string, role domain.Role) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments/"+tournamentID.String()+"/matches/export?"+query, nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", tournamentID.String())
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserIDKey, uuid.New())
+	ctx = context.WithValue(ctx, middleware.RoleKey, role)
+	return req.WithContext(ctx)
+}
+
+func TestTournamentHandler_ExportMatches(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	tournamentID := uuid.New()
+	p1, p2 := uuid.New(), uuid.New()
+	teamName := "Team A"
+	score1, score2, winner := 10, 5, 1
+
+	page1 := []*domain.Match{{
+		ID: uuid.New(), TournamentID: tournamentID, Program1ID: p1, Program2ID: p2,
+		GameType: "dilemma", Status: domain.MatchCompleted, RoundNumber: 2,
+		Score1: &score1, Score2: &score2, Winner: &winner, CreatedAt: time.Now(),
+	}}
+	page2 := []*domain.Match{{
+		ID: uuid.New(), TournamentID: tournamentID, Program1ID: p2, Program2ID: p1,
+		GameType: "dilemma", Status: domain.MatchPending, RoundNumber: 1, CreatedAt: time.Now(),
+	}}
+	infos := map[uuid.UUID]*domain.ProgramInfo{
+		p1: {ProgramID: p1, ProgramName: "bot1", TeamName: &teamName},
+		p2: {ProgramID: p2, ProgramName: "bot2"},
+	}
+
+	setup := func() (*MockMatchExportSource, *MockProgramInfoLookup) {
+		source := new(MockMatchExportSource)
+		programs := new(MockProgramInfoLookup)
+
+		source.On("ListWithCursor", mock.Anything, mock.MatchedBy(func(f domain.MatchFilter) bool {
+			return *f.TournamentID == tournamentID && f.GameType == "dilemma"
+		}), mock.MatchedBy(func(p *pagination.PageRequest) bool { return p.After == nil })).Return(page1, true, nil).Once()
+		source.On("ListWithCursor", mock.Anything, mock.Anything, mock.MatchedBy(func(p *pagination.PageRequest) bool {
+			return p.After != nil
+		})).Return(page2, false, nil).Once()
+		programs.On("GetInfoByIDs", mock.Anything, []uuid.UUID{p1, p2}).Return(infos, nil).Once()
+
+		return source, programs
+	}
+
+	t.Run("streams all pages as CSV", func(t *testing.T) {
+		source, programs := setup()
+		handler := NewTournamentHandler(new(MockTournamentService), log)
+		handler.SetMatchExporter(source, programs)
+
+		w := httptest.NewRecorder()
+		handler.ExportMatches(w, newExportRequest(tournamentID, "format=csv&game_type=dilemma", domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, matchExportHeader, records[0])
+		assert.Equal(t, []string{"bot1", "Team A"}, records[1][4:6])
+		assert.Equal(t, "10", records[1][9])
+		assert.Equal(t, "", records[2][9])
+
+		source.AssertExpectations(t)
+		programs.AssertExpectations(t)
+	})
+
+	t.Run("streams JSON lines", func(t *testing.T) {
+		source, programs := setup()
+		handler := NewTournamentHandler(new(MockTournamentService), log)
+		handler.SetMatchExporter(source, programs)
+
+		w := httptest.NewRecorder()
+		handler.ExportMatches(w, newExportRequest(tournamentID, "format=jsonl&game_type=dilemma", domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var lines int
+		scanner := bufio.NewScanner(strings.NewReader(w.Body.String()))
+		for scanner.Scan() {
+			var row matchExportRow
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+			lines++
+		}
+		assert.Equal(t, 2, lines)
+	})
+
+	t.Run("rejects unknown format", func(t *testing.T) {
+		handler := NewTournamentHandler(new(MockTournamentService), log)
+		handler.SetMatchExporter(new(MockMatchExportSource), new(MockProgramInfoLookup))
+
+		w := httptest.NewRecorder()
+		handler.ExportMatches(w, newExportRequest(tournamentID, "format=xlsx", domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("forbidden for non-creator", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		creatorID := uuid.New()
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID, CreatorID: &creatorID}, nil)
+
+		source := new(MockMatchExportSource)
+		handler := NewTournamentHandler(mockService, log)
+		handler.SetMatchExporter(source, new(MockProgramInfoLookup))
+
+		w := httptest.NewRecorder()
+		handler.ExportMatches(w, newExportRequest(tournamentID, "", domain.RoleUser))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		source.AssertNotCalled(t, "ListWithCursor", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTournamentHandler_ExportResults(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	tournamentID := uuid.New()
+	teamID := uuid.New()
+	entries := []*domain.CrossGameLeaderboardEntry{
+		{
+			Rank: 1, TeamID: &teamID, TeamName: "Team A", ProgramName: "bot1",
+			GameRatings: map[string]domain.GameRatingInfo{
+				"g1": {GameName: "dilemma", Rating: 30, Wins: 3, Draws: 1},
+				"g2": {GameName: "auction", Rating: 12, Wins: 1, Draws: 2},
+			},
+			TotalRating: 42, TotalWins: 4, TotalLosses: 1, TotalGames: 8,
+		},
+		{
+			Rank: 2, TeamName: "Team B", ProgramName: "bot2",
+			GameRatings: map[string]domain.GameRatingInfo{
+				"g1": {GameName: "dilemma", Rating: 10, Losses: 3},
+			},
+			TotalRating: 10, TotalLosses: 3, TotalGames: 3,
+		},
+	}
+
+	newRequest := func(query string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments/"+tournamentID.String()+"/export?"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("csv with per-game columns", func(t *testing.T) {
+		mockService := newPublicTournamentService()
+		mockService.On("GetCrossGameLeaderboard", mock.Anything, tournamentID).Return(entries, nil)
+		handler := NewTournamentHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.ExportResults(w, newRequest("format=csv"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "results.csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, []string{"rank", "team", "program", "auction_rating", "dilemma_rating", "wins", "losses", "draws", "total_games", "total_rating"}, records[0])
+		assert.Equal(t, []string{"1", "Team A", "bot1", "12", "30", "4", "1", "3", "8", "42"}, records[1])
+		assert.Equal(t, []string{"2", "Team B", "bot2", "", "10", "0", "3", "0", "3", "10"}, records[2])
+	})
+
+	t.Run("json array", func(t *testing.T) {
+		mockService := newPublicTournamentService()
+		mockService.On("GetCrossGameLeaderboard", mock.Anything, tournamentID).Return(entries, nil)
+		handler := NewTournamentHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.ExportResults(w, newRequest("format=json"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var decoded []domain.CrossGameLeaderboardEntry
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decoded))
+		require.Len(t, decoded, 2)
+		assert.Equal(t, "Team A", decoded[0].TeamName)
+		assert.Equal(t, 42, decoded[0].TotalRating)
+	})
+
+	t.Run("program leaderboard", func(t *testing.T) {
+		teamName := "Team A"
+		mockService := newPublicTournamentService()
+		mockService.On("GetLeaderboard", mock.Anything, tournamentID, 1000).Return([]*domain.LeaderboardEntry{
+			{Rank: 1, ProgramName: "bot1", TeamName: &teamName, Rating: 1600, Wins: 5, Losses: 1, Draws: 2, TotalGames: 8},
+		}, nil)
+		handler := NewTournamentHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.ExportResults(w, newRequest("type=leaderboard"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, []string{"1", "Team A", "bot1", "5", "1", "2", "8", "1600"}, records[1])
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		handler := NewTournamentHandler(newPublicTournamentService(), log)
+
+		w := httptest.NewRecorder()
+		handler.ExportResults(w, newRequest("format=xml"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
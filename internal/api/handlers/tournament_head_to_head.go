@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TeamHeadToHeadLookup интерфейс для получения личных встреч двух команд турнира
+type TeamHeadToHeadLookup interface {
+	GetTeamHeadToHead(ctx context.Context, tournamentID, a, b uuid.UUID, gameType string) (*domain.TeamHeadToHead, error)
+}
+
+// SetHeadToHead включает GET /tournaments/{id}/head-to-head
+func (h *TournamentHandler) SetHeadToHead(headToHead TeamHeadToHeadLookup) {
+	h.headToHead = headToHead
+}
+
+// HeadToHead возвращает личные встречи команд team_a и team_b в турнире (со стороны team_a):
+// общий счёт и счёт с последними матчами по каждой игре
+// GET /api/v1/tournaments/{id}/head-to-head?team_a=<id>&team_b=<id>&game_type=
+func (h *TournamentHandler) HeadToHead(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid tournament ID"))
+		return
+	}
+
+	if h.headToHead == nil {
+		writeError(w, errors.ErrServiceUnavailable.WithMessage("head-to-head is not available"))
+		return
+	}
+
+	teamA, err := uuid.Parse(r.URL.Query().Get("team_a"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid team_a"))
+		return
+	}
+	teamB, err := uuid.Parse(r.URL.Query().Get("team_b"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid team_b"))
+		return
+	}
+	if teamA == teamB {
+		writeError(w, errors.ErrInvalidInput.WithMessage("teams team_a and team_b must differ"))
+		return
+	}
+
+	if _, err := h.authorizeView(r, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	record, err := h.headToHead.GetTeamHeadToHead(r.Context(), tournamentID, teamA, teamB, r.URL.Query().Get("game_type"))
+	if err != nil {
+		h.log.LogError("Failed to get team head-to-head", err,
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("team_a", teamA.String()),
+			zap.String("team_b", teamB.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, record)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTeamHeadToHeadLookup struct {
+	mock.Mock
+}
+
+func (m *MockTeamHeadToHeadLookup) GetTeamHeadToHead(ctx context.Context, tournamentID, a, b uuid.UUID, gameType string) (*domain.TeamHeadToHead, error) {
+	args := m.Called(ctx, tournamentID, a, b, gameType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TeamHeadToHead), args.Error(1)
+}
+
+func newTeamHeadToHeadRequest(tournamentID, query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments/"+tournamentID+"/head-to-head?"+query, nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", tournamentID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestTournamentHandler_HeadToHead(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+	teamA, teamB := uuid.New(), uuid.New()
+
+	t.Run("returns record grouped by game", func(t *testing.T) {
+		lookup := new(MockTeamHeadToHeadLookup)
+		lookup.On("GetTeamHeadToHead", mock.Anything, tournamentID, teamA, teamB, "dilemma").Return(&domain.TeamHeadToHead{
+			TournamentID: tournamentID,
+			TeamA:        teamA,
+			TeamB:        teamB,
+			Totals:       domain.HeadToHeadTotals{Matches: 2, Wins: 1, Losses: 1, ScoreFor: 13, ScoreAgainst: 11},
+			Games: []*domain.GameHeadToHead{{
+				GameType:         "dilemma",
+				HeadToHeadTotals: domain.HeadToHeadTotals{Matches: 2, Wins: 1, Losses: 1, ScoreFor: 13, ScoreAgainst: 11},
+				RecentMatches:    []*domain.Match{{ID: uuid.New(), GameType: "dilemma"}},
+			}},
+		}, nil)
+
+		handler := NewTournamentHandler(newPublicTournamentService(), log)
+		handler.SetHeadToHead(lookup)
+
+		w := httptest.NewRecorder()
+		handler.HeadToHead(w, newTeamHeadToHeadRequest(tournamentID.String(),
+			"team_a="+teamA.String()+"&team_b="+teamB.String()+"&game_type=dilemma"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		totals := body["totals"].(map[string]interface{})
+		assert.Equal(t, float64(13), totals["score_for"])
+		games := body["games"].([]interface{})
+		require.Len(t, games, 1)
+		game := games[0].(map[string]interface{})
+		assert.Equal(t, "dilemma", game["game_type"])
+		assert.Equal(t, float64(1), game["wins"])
+		assert.Len(t, game["recent_matches"], 1)
+		lookup.AssertExpectations(t)
+	})
+
+	t.Run("invalid team", func(t *testing.T) {
+		lookup := new(MockTeamHeadToHeadLookup)
+		handler := NewTournamentHandler(newPublicTournamentService(), log)
+		handler.SetHeadToHead(lookup)
+
+		w := httptest.NewRecorder()
+		handler.HeadToHead(w, newTeamHeadToHeadRequest(tournamentID.String(), "team_a="+teamA.String()+"&team_b=bad"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		lookup.AssertNotCalled(t, "GetTeamHeadToHead", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("same team", func(t *testing.T) {
+		handler := NewTournamentHandler(newPublicTournamentService(), log)
+		handler.SetHeadToHead(new(MockTeamHeadToHeadLookup))
+
+		w := httptest.NewRecorder()
+		handler.HeadToHead(w, newTeamHeadToHeadRequest(tournamentID.String(), "team_a="+teamA.String()+"&team_b="+teamA.String()))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		handler := NewTournamentHandler(newPublicTournamentService(), log)
+
+		w := httptest.NewRecorder()
+		handler.HeadToHead(w, newTeamHeadToHeadRequest(tournamentID.String(), "team_a="+teamA.String()+"&team_b="+teamB.String()))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
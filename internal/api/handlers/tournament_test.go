@@ -4,14 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/internal/domain/tournament"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +38,22 @@ func (m *MockTournamentService) Create(ctx context.Context, req *tournament.Crea
 	return args.Get(0).(*domain.Tournament), args.Error(1)
 }
 
+func (m *MockTournamentService) CloneTournament(ctx context.Context, sourceID uuid.UUID, req tournament.CreateRequest) (*domain.Tournament, error) {
+	args := m.Called(ctx, sourceID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Tournament), args.Error(1)
+}
+
+func (m *MockTournamentService) Update(ctx context.Context, tournamentID uuid.UUID, req *tournament.UpdateRequest) (*domain.Tournament, error) {
+	args := m.Called(ctx, tournamentID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Tournament), args.Error(1)
+}
+
 func (m *MockTournamentService) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -48,6 +70,19 @@ func (m *MockTournamentService) List(ctx context.Context, filter domain.Tourname
 	return args.Get(0).([]*domain.Tournament), args.Error(1)
 }
 
+func (m *MockTournamentService) CountWithFilter(ctx context.Context, filter domain.TournamentFilter) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTournamentService) ListWithCursor(ctx context.Context, filter domain.TournamentFilter, pageReq *pagination.PageRequest) ([]*domain.Tournament, bool, error) {
+	args := m.Called(ctx, filter, pageReq)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Tournament), args.Bool(1), args.Error(2)
+}
+
 func (m *MockTournamentService) Join(ctx context.Context, req *tournament.JoinRequest) error {
 	args := m.Called(ctx, req)
 	return args.Error(0)
@@ -63,6 +98,19 @@ func (m *MockTournamentService) Complete(ctx context.Context, tournamentID uuid.
 	return args.Error(0)
 }
 
+func (m *MockTournamentService) Cancel(ctx context.Context, tournamentID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, tournamentID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTournamentService) ForceComplete(ctx context.Context, tournamentID uuid.UUID, cancelPending bool) (*tournament.ForceCompleteResult, error) {
+	args := m.Called(ctx, tournamentID, cancelPending)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tournament.ForceCompleteResult), args.Error(1)
+}
+
 func (m *MockTournamentService) GetLeaderboard(ctx context.Context, tournamentID uuid.UUID, limit int) ([]*domain.LeaderboardEntry, error) {
 	args := m.Called(ctx, tournamentID, limit)
 	if args.Get(0) == nil {
@@ -71,6 +119,14 @@ func (m *MockTournamentService) GetLeaderboard(ctx context.Context, tournamentID
 	return args.Get(0).([]*domain.LeaderboardEntry), args.Error(1)
 }
 
+func (m *MockTournamentService) GetReferenceLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.LeaderboardEntry, error) {
+	args := m.Called(ctx, tournamentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LeaderboardEntry), args.Error(1)
+}
+
 func (m *MockTournamentService) CreateMatch(ctx context.Context, tournamentID, program1ID, program2ID uuid.UUID, priority domain.MatchPriority) (*domain.Match, error) {
 	args := m.Called(ctx, tournamentID, program1ID, program2ID, priority)
 	if args.Get(0) == nil {
@@ -87,11 +143,32 @@ func (m *MockTournamentService) GetMatches(ctx context.Context, tournamentID uui
 	return args.Get(0).([]*domain.Match), args.Error(1)
 }
 
+func (m *MockTournamentService) GetMatchesWithCursor(ctx context.Context, tournamentID uuid.UUID, pageReq *pagination.PageRequest) ([]*domain.Match, bool, error) {
+	args := m.Called(ctx, tournamentID, pageReq)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Match), args.Bool(1), args.Error(2)
+}
+
 func (m *MockTournamentService) Delete(ctx context.Context, tournamentID uuid.UUID) error {
 	args := m.Called(ctx, tournamentID)
 	return args.Error(0)
 }
 
+func (m *MockTournamentService) Restore(ctx context.Context, tournamentID uuid.UUID) (*domain.Tournament, error) {
+	args := m.Called(ctx, tournamentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Tournament), args.Error(1)
+}
+
+func (m *MockTournamentService) Purge(ctx context.Context, tournamentID uuid.UUID) error {
+	args := m.Called(ctx, tournamentID)
+	return args.Error(0)
+}
+
 func (m *MockTournamentService) GetCrossGameLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error) {
 	args := m.Called(ctx, tournamentID)
 	if args.Get(0) == nil {
@@ -100,11 +177,24 @@ func (m *MockTournamentService) GetCrossGameLeaderboard(ctx context.Context, tou
 	return args.Get(0).([]*domain.CrossGameLeaderboardEntry), args.Error(1)
 }
 
+func (m *MockTournamentService) GetTeamLeaderboard(ctx context.Context, tournamentID uuid.UUID, normalize bool) ([]*domain.TeamRating, error) {
+	args := m.Called(ctx, tournamentID, normalize)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.TeamRating), args.Error(1)
+}
+
 func (m *MockTournamentService) RunAllMatches(ctx context.Context, tournamentID uuid.UUID) (int, error) {
 	args := m.Called(ctx, tournamentID)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockTournamentService) ScheduleRound(ctx context.Context, tournamentID uuid.UUID, at time.Time) (int, error) {
+	args := m.Called(ctx, tournamentID, at)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockTournamentService) RetryFailedMatches(ctx context.Context, tournamentID uuid.UUID) (int, error) {
 	args := m.Called(ctx, tournamentID)
 	return args.Int(0), args.Error(1)
@@ -118,11 +208,87 @@ func (m *MockTournamentService) GetMatchesByRounds(ctx context.Context, tourname
 	return args.Get(0).([]*domain.MatchRound), args.Error(1)
 }
 
-func (m *MockTournamentService) RunGameMatches(ctx context.Context, tournamentID uuid.UUID, gameType string) (int, error) {
-	args := m.Called(ctx, tournamentID, gameType)
+func (m *MockTournamentService) GetParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) ([]*domain.ParticipantEntry, error) {
+	args := m.Called(ctx, tournamentID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ParticipantEntry), args.Error(1)
+}
+
+func (m *MockTournamentService) CountParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) (int, error) {
+	args := m.Called(ctx, tournamentID, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTournamentService) GetRoundMatches(ctx context.Context, tournamentID uuid.UUID, roundNumber, limit, offset int) (*domain.RoundMatches, error) {
+	args := m.Called(ctx, tournamentID, roundNumber, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RoundMatches), args.Error(1)
+}
+
+func (m *MockTournamentService) RunGameMatches(ctx context.Context, tournamentID uuid.UUID, gameType string, overrideWindow bool) (int, error) {
+	args := m.Called(ctx, tournamentID, gameType, overrideWindow)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockTournamentService) RegenerateGameRound(ctx context.Context, tournamentID, gameID uuid.UUID) (*tournament.RegenerateRoundResult, error) {
+	args := m.Called(ctx, tournamentID, gameID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tournament.RegenerateRoundResult), args.Error(1)
+}
+
+func (m *MockTournamentService) KickParticipant(ctx context.Context, tournamentID, programID uuid.UUID, reason string) error {
+	args := m.Called(ctx, tournamentID, programID, reason)
+	return args.Error(0)
+}
+
+func (m *MockTournamentService) BanProgram(ctx context.Context, tournamentID, programID uuid.UUID, reason string) error {
+	args := m.Called(ctx, tournamentID, programID, reason)
+	return args.Error(0)
+}
+
+func (m *MockTournamentService) ImportParticipants(ctx context.Context, tournamentID uuid.UUID, req *tournament.ImportParticipantsRequest) ([]*domain.ImportedProgram, error) {
+	args := m.Called(ctx, tournamentID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ImportedProgram), args.Error(1)
+}
+
+// newPublicTournamentService returns a service mock that serves every tournament as public
+func newPublicTournamentService() *MockTournamentService {
+	m := new(MockTournamentService)
+	m.On("GetByID", mock.Anything, mock.Anything).
+		Return(&domain.Tournament{Visibility: domain.VisibilityPublic}, nil).Maybe()
+	return m
+}
+
+// MockTournamentStatsSource mocks batched tournament counters
+type MockTournamentStatsSource struct {
+	mock.Mock
+}
+
+func (m *MockTournamentStatsSource) GetParticipantCountsByTournamentIDs(ctx context.Context, tournamentIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	args := m.Called(ctx, tournamentIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]int), args.Error(1)
+}
+
+func (m *MockTournamentStatsSource) GetMatchStatsByTournamentIDs(ctx context.Context, tournamentIDs []uuid.UUID) (map[uuid.UUID]*domain.TournamentMatchStats, error) {
+	args := m.Called(ctx, tournamentIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*domain.TournamentMatchStats), args.Error(1)
+}
+
 func TestTournamentHandler_Create(t *testing.T) {
 	log, _ := logger.New("error", "json")
 
@@ -227,6 +393,45 @@ func TestTournamentHandler_Get(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("exposes auto-start state for pending tournament", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		startTime := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{
+			ID:        tournamentID,
+			Status:    domain.TournamentPending,
+			StartTime: &startTime,
+			Metadata: map[string]interface{}{
+				domain.MetaAutoStartError:    "not enough participants: 1 of 2 required",
+				domain.MetaAutoStartAttempts: 2,
+			},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments/"+tournamentID.String(), nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		w := httptest.NewRecorder()
+		handler.Get(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			ID             uuid.UUID  `json:"id"`
+			ScheduledStart *time.Time `json:"scheduled_start"`
+			AutoStartError *string    `json:"auto_start_error"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, tournamentID, response.ID)
+		require.NotNil(t, response.ScheduledStart)
+		assert.True(t, startTime.Equal(*response.ScheduledStart))
+		require.NotNil(t, response.AutoStartError)
+		assert.Equal(t, "not enough participants: 1 of 2 required", *response.AutoStartError)
+	})
+
 	t.Run("tournament not found", func(t *testing.T) {
 		mockService := new(MockTournamentService)
 		handler := NewTournamentHandler(mockService, log)
@@ -307,6 +512,33 @@ func TestTournamentHandler_List(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("include_total wraps page with total", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		mockService.On("List", mock.Anything, mock.AnythingOfType("domain.TournamentFilter")).Return([]*domain.Tournament{}, nil)
+		mockService.On("CountWithFilter", mock.Anything, mock.MatchedBy(func(filter domain.TournamentFilter) bool {
+			return filter.Status == domain.TournamentActive
+		})).Return(120, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments?status=active&limit=500&offset=100&include_total=true", nil)
+		w := httptest.NewRecorder()
+
+		handler.List(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page pagination.OffsetPage[domain.Tournament]
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		assert.NotNil(t, page.Items)
+		assert.Empty(t, page.Items)
+		assert.Equal(t, 120, page.Total)
+		// The service caps the page size, and the envelope reports the effective limit
+		assert.Equal(t, 100, page.Limit)
+		assert.Equal(t, 100, page.Offset)
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("list with filters", func(t *testing.T) {
 		mockService := new(MockTournamentService)
 		handler := NewTournamentHandler(mockService, log)
@@ -333,6 +565,109 @@ func TestTournamentHandler_List(t *testing.T) {
 
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("admin lists deleted tournaments", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		mockService.On("List", mock.Anything, mock.MatchedBy(func(filter domain.TournamentFilter) bool {
+			return filter.IncludeDeleted
+		})).Return([]*domain.Tournament{}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments?include_deleted=true", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.RoleKey, domain.RoleAdmin))
+		w := httptest.NewRecorder()
+
+		handler.List(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("plain list does not query stats", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		stats := new(MockTournamentStatsSource)
+		handler := NewTournamentHandler(mockService, log)
+		handler.SetStatsSource(stats)
+
+		tournaments := []*domain.Tournament{{ID: uuid.New(), Name: "Tournament 1"}}
+		mockService.On("List", mock.Anything, mock.Anything).Return(tournaments, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments", nil)
+		w := httptest.NewRecorder()
+
+		handler.List(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		expected, err := json.Marshal(tournaments)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(expected), w.Body.String())
+		assert.NotContains(t, w.Body.String(), "participants_count")
+		stats.AssertNotCalled(t, "GetParticipantCountsByTournamentIDs", mock.Anything, mock.Anything)
+		stats.AssertNotCalled(t, "GetMatchStatsByTournamentIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("include=stats batches counts for the page", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		stats := new(MockTournamentStatsSource)
+		handler := NewTournamentHandler(mockService, log)
+		handler.SetStatsSource(stats)
+
+		withMatches := &domain.Tournament{ID: uuid.New(), Name: "Tournament 1"}
+		empty := &domain.Tournament{ID: uuid.New(), Name: "Tournament 2"}
+		ids := []uuid.UUID{withMatches.ID, empty.ID}
+
+		mockService.On("List", mock.Anything, mock.Anything).Return([]*domain.Tournament{withMatches, empty}, nil)
+		stats.On("GetParticipantCountsByTournamentIDs", mock.Anything, ids).
+			Return(map[uuid.UUID]int{withMatches.ID: 12}, nil).Once()
+		stats.On("GetMatchStatsByTournamentIDs", mock.Anything, ids).
+			Return(map[uuid.UUID]*domain.TournamentMatchStats{
+				withMatches.ID: {Total: 264, Completed: 200, Failed: 10, Cancelled: 1, Pending: 50, Running: 3},
+			}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments?include=stats", nil)
+		w := httptest.NewRecorder()
+
+		handler.List(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response []struct {
+			ID                uuid.UUID                   `json:"id"`
+			Name              string                      `json:"name"`
+			ParticipantsCount int                         `json:"participants_count"`
+			Matches           domain.TournamentMatchStats `json:"matches"`
+			CompletionPercent float64                     `json:"completion_percent"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		require.Len(t, response, 2)
+
+		assert.Equal(t, withMatches.ID, response[0].ID)
+		assert.Equal(t, "Tournament 1", response[0].Name)
+		assert.Equal(t, 12, response[0].ParticipantsCount)
+		assert.Equal(t, 264, response[0].Matches.Total)
+		assert.Equal(t, 79.9, response[0].CompletionPercent)
+
+		assert.Equal(t, 0, response[1].ParticipantsCount)
+		assert.Equal(t, 0, response[1].Matches.Total)
+		assert.Equal(t, 0.0, response[1].CompletionPercent)
+
+		stats.AssertExpectations(t)
+	})
+
+	t.Run("include_deleted is forbidden for non-admins", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments?include_deleted=true", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.RoleKey, domain.RoleUser))
+		w := httptest.NewRecorder()
+
+		handler.List(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
 }
 
 func TestTournamentHandler_Join(t *testing.T) {
@@ -503,7 +838,7 @@ func TestTournamentHandler_GetLeaderboard(t *testing.T) {
 	log, _ := logger.New("error", "json")
 
 	t.Run("successfully get leaderboard", func(t *testing.T) {
-		mockService := new(MockTournamentService)
+		mockService := newPublicTournamentService()
 		handler := NewTournamentHandler(mockService, log)
 
 		tournamentID := uuid.New()
@@ -548,7 +883,7 @@ func TestTournamentHandler_GetLeaderboard(t *testing.T) {
 	})
 
 	t.Run("get leaderboard with custom limit", func(t *testing.T) {
-		mockService := new(MockTournamentService)
+		mockService := newPublicTournamentService()
 		handler := NewTournamentHandler(mockService, log)
 
 		tournamentID := uuid.New()
@@ -576,3 +911,955 @@ func TestTournamentHandler_GetLeaderboard(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestTournamentHandler_KickParticipant(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newKickRequest := func(tournamentID, programID uuid.UUID, userID uuid.UUID, role domain.Role, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/tournaments/"+tournamentID.String()+"/participants/"+programID.String(), bytes.NewBufferString(body))
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		rctx.URLParams.Add("programID", programID.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+		ctx = context.WithValue(ctx, middleware.RoleKey, role)
+		return req.WithContext(ctx)
+	}
+
+	t.Run("creator kicks participant", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		programID := uuid.New()
+		creatorID := uuid.New()
+
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID, CreatorID: &creatorID}, nil)
+		mockService.On("KickParticipant", mock.Anything, tournamentID, programID, "crashes opponents").Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.KickParticipant(w, newKickRequest(tournamentID, programID, creatorID, domain.RoleUser, `{"reason":"  crashes opponents "}`))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("admin kicks participant without ownership check", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		programID := uuid.New()
+
+		mockService.On("KickParticipant", mock.Anything, tournamentID, programID, "spam").Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.KickParticipant(w, newKickRequest(tournamentID, programID, uuid.New(), domain.RoleAdmin, `{"reason":"spam"}`))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("forbidden for non-creator", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		creatorID := uuid.New()
+
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID, CreatorID: &creatorID}, nil)
+
+		w := httptest.NewRecorder()
+		handler.KickParticipant(w, newKickRequest(tournamentID, uuid.New(), uuid.New(), domain.RoleUser, `{"reason":"spam"}`))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "KickParticipant", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reason is required", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.KickParticipant(w, newKickRequest(uuid.New(), uuid.New(), uuid.New(), domain.RoleAdmin, `{"reason":"   "}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestTournamentHandler_BanProgram(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	t.Run("admin bans program", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		programID := uuid.New()
+
+		mockService.On("BanProgram", mock.Anything, tournamentID, programID, "cheating").Return(nil)
+
+		body, _ := json.Marshal(map[string]interface{}{"program_id": programID, "reason": "cheating"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tournaments/"+tournamentID.String()+"/bans", bytes.NewBuffer(body))
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, uuid.New())
+		ctx = context.WithValue(ctx, middleware.RoleKey, domain.RoleAdmin)
+		req = req.WithContext(ctx)
+
+		w := httptest.NewRecorder()
+		handler.BanProgram(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("program_id is required", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tournaments/"+tournamentID.String()+"/bans", bytes.NewBufferString(`{"reason":"cheating"}`))
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		w := httptest.NewRecorder()
+		handler.BanProgram(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestTournamentHandler_GetTeamLeaderboard(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(tournamentID uuid.UUID, query string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments/"+tournamentID.String()+"/team-leaderboard"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	for _, tc := range []struct {
+		query     string
+		normalize bool
+	}{
+		{"", false},
+		{"?normalize=true", true},
+		{"?normalize=1", false},
+	} {
+		t.Run("query "+tc.query, func(t *testing.T) {
+			mockService := newPublicTournamentService()
+			handler := NewTournamentHandler(mockService, log)
+
+			tournamentID := uuid.New()
+			ratings := []*domain.TeamRating{{TeamID: uuid.New(), TeamName: "Alpha", TotalRating: 100, GamesPlayed: 1, GameBreakdown: map[string]float64{"dilemma": 100}}}
+			mockService.On("GetTeamLeaderboard", mock.Anything, tournamentID, tc.normalize).Return(ratings, nil)
+
+			w := httptest.NewRecorder()
+			handler.GetTeamLeaderboard(w, newRequest(tournamentID, tc.query))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response []*domain.TeamRating
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, ratings, response)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTournamentHandler_ImportParticipants(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newImportRequest := func(tournamentID, userID uuid.UUID, role domain.Role, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tournaments/"+tournamentID.String()+"/import-from", bytes.NewBufferString(body))
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+		ctx = context.WithValue(ctx, middleware.RoleKey, role)
+		return req.WithContext(ctx)
+	}
+
+	t.Run("creator imports from own tournament", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID, sourceID, creatorID := uuid.New(), uuid.New(), uuid.New()
+		imported := []*domain.ImportedProgram{{SourceProgramID: uuid.New(), ProgramID: uuid.New(), TeamID: uuid.New(), TeamName: "Alpha"}}
+
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID, CreatorID: &creatorID}, nil)
+		mockService.On("GetByID", mock.Anything, sourceID).Return(&domain.Tournament{ID: sourceID, CreatorID: &creatorID}, nil)
+		mockService.On("ImportParticipants", mock.Anything, tournamentID, &tournament.ImportParticipantsRequest{
+			SourceTournamentID: sourceID,
+			Count:              8,
+		}).Return(imported, nil)
+
+		w := httptest.NewRecorder()
+		handler.ImportParticipants(w, newImportRequest(tournamentID, creatorID, domain.RoleUser,
+			`{"source_tournament_id":"`+sourceID.String()+`","count":8}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Imported []*domain.ImportedProgram `json:"imported"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, imported, response.Imported)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("forbidden for foreign source tournament", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID, sourceID, creatorID := uuid.New(), uuid.New(), uuid.New()
+		otherID := uuid.New()
+
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID, CreatorID: &creatorID}, nil)
+		mockService.On("GetByID", mock.Anything, sourceID).Return(&domain.Tournament{ID: sourceID, CreatorID: &otherID}, nil)
+
+		w := httptest.NewRecorder()
+		handler.ImportParticipants(w, newImportRequest(tournamentID, creatorID, domain.RoleUser,
+			`{"source_tournament_id":"`+sourceID.String()+`","count":8}`))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "ImportParticipants", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.ImportParticipants(w, newImportRequest(uuid.New(), uuid.New(), domain.RoleAdmin, `{"count":"many"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ImportParticipants", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTournamentHandler_ScheduleRound(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(tournamentID uuid.UUID, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tournaments/"+tournamentID.String()+"/schedule-round", bytes.NewBufferString(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("schedules round", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		at := time.Date(2030, 5, 1, 18, 0, 0, 0, time.UTC)
+
+		mockService.On("ScheduleRound", mock.Anything, tournamentID, mock.MatchedBy(func(got time.Time) bool {
+			return got.Equal(at)
+		})).Return(6, nil)
+
+		w := httptest.NewRecorder()
+		handler.ScheduleRound(w, newRequest(tournamentID, `{"scheduled_at":"2030-05-01T18:00:00Z"}`))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(6), response["matches"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("scheduled_at is required", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.ScheduleRound(w, newRequest(uuid.New(), `{}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ScheduleRound", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTournamentHandler_Clone(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(tournamentID, userID uuid.UUID, role domain.Role, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tournaments/"+tournamentID.String()+"/clone", bytes.NewBufferString(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+		ctx = context.WithValue(ctx, middleware.RoleKey, role)
+		return req.WithContext(ctx)
+	}
+
+	t.Run("creator clones with name override", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		sourceID := uuid.New()
+		creatorID := uuid.New()
+
+		mockService.On("GetByID", mock.Anything, sourceID).Return(&domain.Tournament{ID: sourceID, CreatorID: &creatorID}, nil)
+		mockService.On("CloneTournament", mock.Anything, sourceID, mock.MatchedBy(func(req tournament.CreateRequest) bool {
+			return req.Name == "Cup #2" && req.CreatorID != nil && *req.CreatorID == creatorID
+		})).Return(&domain.Tournament{ID: uuid.New(), Name: "Cup #2"}, nil)
+
+		w := httptest.NewRecorder()
+		handler.Clone(w, newRequest(sourceID, creatorID, domain.RoleUser, `{"name":"Cup #2"}`))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("admin assigns clone to another user without body name", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		sourceID := uuid.New()
+		ownerID := uuid.New()
+
+		mockService.On("CloneTournament", mock.Anything, sourceID, tournament.CreateRequest{CreatorID: &ownerID}).
+			Return(&domain.Tournament{ID: uuid.New()}, nil)
+
+		w := httptest.NewRecorder()
+		handler.Clone(w, newRequest(sourceID, uuid.New(), domain.RoleAdmin, `{"creator_id":"`+ownerID.String()+`"}`))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("non-admin cannot assign another owner", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		sourceID := uuid.New()
+		creatorID := uuid.New()
+
+		mockService.On("GetByID", mock.Anything, sourceID).Return(&domain.Tournament{ID: sourceID, CreatorID: &creatorID}, nil)
+
+		w := httptest.NewRecorder()
+		handler.Clone(w, newRequest(sourceID, creatorID, domain.RoleUser, `{"creator_id":"`+uuid.New().String()+`"}`))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "CloneTournament", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("forbidden for non-creator", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		sourceID := uuid.New()
+		creatorID := uuid.New()
+
+		mockService.On("GetByID", mock.Anything, sourceID).Return(&domain.Tournament{ID: sourceID, CreatorID: &creatorID}, nil)
+
+		w := httptest.NewRecorder()
+		handler.Clone(w, newRequest(sourceID, uuid.New(), domain.RoleUser, ""))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "CloneTournament", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTournamentHandler_RestoreAndPurge(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(method, path string, tournamentID uuid.UUID) *http.Request {
+		req := httptest.NewRequest(method, "/api/v1/tournaments/"+tournamentID.String()+path, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("restores deleted tournament", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		mockService.On("Restore", mock.Anything, tournamentID).
+			Return(&domain.Tournament{ID: tournamentID, Status: domain.TournamentCompleted}, nil)
+
+		w := httptest.NewRecorder()
+		handler.Restore(w, newRequest(http.MethodPost, "/restore", tournamentID))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response domain.Tournament
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, tournamentID, response.ID)
+		assert.Nil(t, response.DeletedAt)
+	})
+
+	t.Run("restore of unknown tournament returns not found", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		mockService.On("Restore", mock.Anything, tournamentID).
+			Return(nil, errors.ErrNotFound.WithMessage("deleted tournament not found"))
+
+		w := httptest.NewRecorder()
+		handler.Restore(w, newRequest(http.MethodPost, "/restore", tournamentID))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("purges tournament", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		mockService.On("Purge", mock.Anything, tournamentID).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.Purge(w, newRequest(http.MethodDelete, "/purge", tournamentID))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("purge before retention returns conflict", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID := uuid.New()
+		mockService.On("Purge", mock.Anything, tournamentID).
+			Return(errors.ErrConflict.WithMessage("retention period for deleted tournament has not expired"))
+
+		w := httptest.NewRecorder()
+		handler.Purge(w, newRequest(http.MethodDelete, "/purge", tournamentID))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestTournamentHandler_ForceComplete(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(tournamentID uuid.UUID, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tournaments/"+tournamentID.String()+"/force-complete", strings.NewReader(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("cancels pending matches when asked", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+		mockService.On("ForceComplete", mock.Anything, tournamentID, true).Return(&tournament.ForceCompleteResult{
+			CancelledMatches: 3,
+			Standings:        []*domain.LeaderboardEntry{{Rank: 1, ProgramName: "winner"}},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		handler.ForceComplete(w, newRequest(tournamentID, `{"cancel_pending":true}`))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response tournament.ForceCompleteResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(3), response.CancelledMatches)
+		require.Len(t, response.Standings, 1)
+		assert.Equal(t, "winner", response.Standings[0].ProgramName)
+	})
+
+	t.Run("body is optional", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+		mockService.On("ForceComplete", mock.Anything, tournamentID, false).
+			Return(&tournament.ForceCompleteResult{Standings: []*domain.LeaderboardEntry{}}, nil)
+
+		w := httptest.NewRecorder()
+		handler.ForceComplete(w, newRequest(tournamentID, ""))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("tournament not active", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+		mockService.On("ForceComplete", mock.Anything, tournamentID, false).
+			Return(nil, errors.ErrConflict.WithMessage("tournament is not active"))
+
+		w := httptest.NewRecorder()
+		handler.ForceComplete(w, newRequest(tournamentID, ""))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestTournamentHandler_GetRoundMatches(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(tournamentID uuid.UUID, round, query string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments/"+tournamentID.String()+"/matches/rounds/"+round+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		rctx.URLParams.Add("round", round)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("non-existent round returns empty list", func(t *testing.T) {
+		mockService := newPublicTournamentService()
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+
+		mockService.On("GetRoundMatches", mock.Anything, tournamentID, 99, 50, 0).Return(&domain.RoundMatches{
+			RoundNumber: 99,
+			Matches:     []*domain.Match{},
+			Limit:       50,
+		}, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetRoundMatches(w, newRequest(tournamentID, "99", ""))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.JSONEq(t, `[]`, string(response["matches"]))
+		assert.JSONEq(t, `{"total":0,"pending":0,"running":0,"completed":0,"failed":0,"cancelled":0}`, string(response["summary"]))
+	})
+
+	t.Run("passes pagination to the service", func(t *testing.T) {
+		mockService := newPublicTournamentService()
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+
+		mockService.On("GetRoundMatches", mock.Anything, tournamentID, 3, 20, 40).Return(&domain.RoundMatches{
+			RoundNumber: 3,
+			Summary:     domain.TournamentMatchStats{Total: 45, Completed: 45},
+			Matches:     []*domain.Match{{ID: uuid.New(), RoundNumber: 3}},
+			Limit:       20,
+			Offset:      40,
+		}, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetRoundMatches(w, newRequest(tournamentID, "3", "?limit=20&offset=40"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response domain.RoundMatches
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 45, response.Summary.Total)
+		assert.Len(t, response.Matches, 1)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid round number", func(t *testing.T) {
+		mockService := newPublicTournamentService()
+		handler := NewTournamentHandler(mockService, log)
+
+		for _, round := range []string{"abc", "0", "-1"} {
+			w := httptest.NewRecorder()
+			handler.GetRoundMatches(w, newRequest(uuid.New(), round, ""))
+			assert.Equal(t, http.StatusBadRequest, w.Code, round)
+		}
+		mockService.AssertNotCalled(t, "GetRoundMatches", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// pagedTournamentService serves cursor pages from memory with the same ordering as the repositories:
+// tournaments by (created_at, id) DESC, matches by (round_number, id) DESC
+type pagedTournamentService struct {
+	*MockTournamentService
+	tournaments []*domain.Tournament
+	matches     []*domain.Match
+}
+
+func (s *pagedTournamentService) ListWithCursor(_ context.Context, _ domain.TournamentFilter, pageReq *pagination.PageRequest) ([]*domain.Tournament, bool, error) {
+	sorted := append([]*domain.Tournament(nil), s.tournaments...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+		}
+		return bytes.Compare(sorted[i].ID[:], sorted[j].ID[:]) > 0
+	})
+
+	cursor, err := pageReq.GetCursor()
+	if err != nil {
+		return nil, false, err
+	}
+	var page []*domain.Tournament
+	for _, t := range sorted {
+		if cursor == nil || t.CreatedAt.Before(*cursor.Timestamp) ||
+			(t.CreatedAt.Equal(*cursor.Timestamp) && bytes.Compare(t.ID[:], cursor.ID[:]) < 0) {
+			page = append(page, t)
+		}
+	}
+
+	limit := pageReq.GetLimit()
+	if len(page) > limit {
+		return page[:limit], true, nil
+	}
+	return page, false, nil
+}
+
+func (s *pagedTournamentService) GetMatchesWithCursor(_ context.Context, _ uuid.UUID, pageReq *pagination.PageRequest) ([]*domain.Match, bool, error) {
+	sorted := append([]*domain.Match(nil), s.matches...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].RoundNumber != sorted[j].RoundNumber {
+			return sorted[i].RoundNumber > sorted[j].RoundNumber
+		}
+		return bytes.Compare(sorted[i].ID[:], sorted[j].ID[:]) > 0
+	})
+
+	cursor, err := pageReq.GetKeysetCursor()
+	if err != nil {
+		return nil, false, err
+	}
+	var page []*domain.Match
+	for _, m := range sorted {
+		if cursor == nil || m.RoundNumber < cursor.Round ||
+			(m.RoundNumber == cursor.Round && bytes.Compare(m.ID[:], cursor.ID[:]) < 0) {
+			page = append(page, m)
+		}
+	}
+
+	limit := pageReq.GetLimit()
+	if len(page) > limit {
+		return page[:limit], true, nil
+	}
+	return page, false, nil
+}
+
+func TestTournamentHandler_List_CursorIsStableAcrossInserts(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	service := &pagedTournamentService{MockTournamentService: new(MockTournamentService)}
+	for i := 0; i < 5; i++ {
+		service.tournaments = append(service.tournaments, &domain.Tournament{
+			ID: uuid.New(), Name: fmt.Sprintf("t%d", i), CreatedAt: base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	// Two tournaments share created_at: the id tie-break keeps them on distinct positions
+	service.tournaments[3].CreatedAt = service.tournaments[2].CreatedAt
+	handler := NewTournamentHandler(service, log)
+
+	fetch := func(query string) pagination.Connection[domain.Tournament] {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments?"+query, nil)
+		w := httptest.NewRecorder()
+		handler.List(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var conn pagination.Connection[domain.Tournament]
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&conn))
+		return conn
+	}
+
+	seen := make(map[uuid.UUID]int)
+	query := "first=2"
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10, "pagination did not terminate")
+		conn := fetch(query)
+		for _, edge := range conn.Edges {
+			seen[edge.Node.ID]++
+		}
+
+		// A new tournament appears between pages: with offsets it would shift the rest of the list
+		service.tournaments = append(service.tournaments, &domain.Tournament{
+			ID: uuid.New(), Name: "late", CreatedAt: base.Add(time.Duration(100+pages) * time.Hour),
+		})
+
+		if !conn.PageInfo.HasNextPage {
+			break
+		}
+		require.NotNil(t, conn.PageInfo.EndCursor)
+		query = "first=2&after=" + *conn.PageInfo.EndCursor
+	}
+
+	require.Len(t, seen, 5)
+	for _, original := range service.tournaments[:5] {
+		assert.Equal(t, 1, seen[original.ID], original.Name)
+	}
+	service.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestTournamentHandler_List_InvalidCursor(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	mockService := new(MockTournamentService)
+	handler := NewTournamentHandler(mockService, log)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments?first=10&after=not-a-cursor", nil)
+	w := httptest.NewRecorder()
+	handler.List(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ListWithCursor", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTournamentHandler_GetMatches_CursorIsStableAcrossInserts(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+
+	service := &pagedTournamentService{MockTournamentService: newPublicTournamentService()}
+	for round := 1; round <= 3; round++ {
+		for i := 0; i < 2; i++ {
+			service.matches = append(service.matches, &domain.Match{
+				ID: uuid.New(), TournamentID: tournamentID, RoundNumber: round, Status: domain.MatchCompleted,
+			})
+		}
+	}
+	original := append([]*domain.Match(nil), service.matches...)
+	handler := NewTournamentHandler(service, log)
+
+	fetch := func(query string) pagination.KeysetPage[domain.Match] {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments/"+tournamentID.String()+"/matches?"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		handler.GetMatches(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page pagination.KeysetPage[domain.Match]
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		return page
+	}
+
+	seen := make(map[uuid.UUID]int)
+	query := "first=4"
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10, "pagination did not terminate")
+		page := fetch(query)
+		for _, m := range page.Items {
+			seen[m.ID]++
+		}
+
+		// The next round is scheduled between pages and lands at the head of the list
+		service.matches = append(service.matches, &domain.Match{
+			ID: uuid.New(), TournamentID: tournamentID, RoundNumber: 10 + pages, Status: domain.MatchPending,
+		})
+
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		require.NotNil(t, page.PageInfo.EndCursor)
+		query = "first=4&after=" + *page.PageInfo.EndCursor
+	}
+
+	require.Len(t, seen, len(original))
+	for _, m := range original {
+		assert.Equal(t, 1, seen[m.ID])
+	}
+	service.AssertNotCalled(t, "GetMatches", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTournamentHandler_ListParticipants(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(tournamentID uuid.UUID, query string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tournaments/"+tournamentID.String()+"/participants"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("returns a page without authentication", func(t *testing.T) {
+		mockService := newPublicTournamentService()
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+		teamName := "Team A"
+		joinedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+		gameName := "dilemma"
+		filter := domain.ParticipantFilter{LatestOnly: true, Limit: 10, Offset: 20}
+		mockService.On("GetParticipants", mock.Anything, tournamentID, filter).Return([]*domain.ParticipantEntry{
+			{ProgramID: uuid.New(), ProgramName: "bot", ProgramVersion: 2, TeamName: &teamName, GameName: &gameName, Rating: 1510, Wins: 3, Losses: 1, Draws: 2, JoinedAt: joinedAt},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		handler.ListParticipants(w, newRequest(tournamentID, "?limit=10&offset=20"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response []map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response, 1)
+		assert.Equal(t, "bot", response[0]["program_name"])
+		assert.Equal(t, float64(2), response[0]["program_version"])
+		assert.Equal(t, "Team A", response[0]["team_name"])
+		assert.Equal(t, "dilemma", response[0]["game_name"])
+		assert.Equal(t, "2026-03-01T10:00:00Z", response[0]["joined_at"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid pagination falls back to defaults", func(t *testing.T) {
+		mockService := newPublicTournamentService()
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+
+		mockService.On("GetParticipants", mock.Anything, tournamentID, domain.ParticipantFilter{LatestOnly: true, Limit: 50}).Return([]*domain.ParticipantEntry{}, nil)
+
+		w := httptest.NewRecorder()
+		handler.ListParticipants(w, newRequest(tournamentID, "?limit=-1&offset=abc"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("unknown tournament", func(t *testing.T) {
+		mockService := newPublicTournamentService()
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+
+		mockService.On("GetParticipants", mock.Anything, tournamentID, domain.ParticipantFilter{LatestOnly: true, Limit: 50}).Return(nil, errors.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		handler.ListParticipants(w, newRequest(tournamentID, ""))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("game filter, all versions and total", func(t *testing.T) {
+		mockService := newPublicTournamentService()
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+		gameID := uuid.New()
+
+		filter := domain.ParticipantFilter{GameID: &gameID, LatestOnly: false, Limit: 2}
+		mockService.On("GetParticipants", mock.Anything, tournamentID, filter).Return([]*domain.ParticipantEntry{
+			{ProgramID: uuid.New(), ProgramName: "bot", ProgramVersion: 1},
+			{ProgramID: uuid.New(), ProgramName: "bot", ProgramVersion: 2},
+		}, nil)
+		mockService.On("CountParticipants", mock.Anything, tournamentID, filter).Return(5, nil)
+
+		w := httptest.NewRecorder()
+		handler.ListParticipants(w, newRequest(tournamentID, "?game_id="+gameID.String()+"&latest_only=false&limit=2&include_total=true"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var page struct {
+			Items []map[string]interface{} `json:"items"`
+			Total int                      `json:"total"`
+			Limit int                      `json:"limit"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, 5, page.Total)
+		assert.Equal(t, 2, page.Limit)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		for _, query := range []string{"?game_id=abc", "?latest_only=maybe"} {
+			mockService := newPublicTournamentService()
+			handler := NewTournamentHandler(mockService, log)
+
+			w := httptest.NewRecorder()
+			handler.ListParticipants(w, newRequest(uuid.New(), query))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+			mockService.AssertNotCalled(t, "GetParticipants", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func TestTournamentHandler_Cancel(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRequest := func(tournamentID uuid.UUID) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tournaments/"+tournamentID.String()+"/cancel", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("reports cancelled matches", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+		mockService.On("Cancel", mock.Anything, tournamentID).Return(int64(4), nil)
+
+		w := httptest.NewRecorder()
+		handler.Cancel(w, newRequest(tournamentID))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"cancelled","cancelled_matches":4}`, w.Body.String())
+	})
+
+	t.Run("finished tournament", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+		tournamentID := uuid.New()
+		mockService.On("Cancel", mock.Anything, tournamentID).
+			Return(int64(0), errors.ErrConflict.WithMessage("tournament is already completed or cancelled"))
+
+		w := httptest.NewRecorder()
+		handler.Cancel(w, newRequest(tournamentID))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestTournamentHandler_RegenerateGameRound(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newRegenerateRequest := func(tournamentID uuid.UUID, gameID string, userID uuid.UUID, role domain.Role) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tournaments/"+tournamentID.String()+"/games/"+gameID+"/regenerate-round", nil)
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tournamentID.String())
+		rctx.URLParams.Add("gameId", gameID)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+		ctx = context.WithValue(ctx, middleware.RoleKey, role)
+		return req.WithContext(ctx)
+	}
+
+	t.Run("creator regenerates round", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID, gameID, creatorID := uuid.New(), uuid.New(), uuid.New()
+
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID, CreatorID: &creatorID}, nil)
+		mockService.On("RegenerateGameRound", mock.Anything, tournamentID, gameID).Return(&tournament.RegenerateRoundResult{
+			GameType: "dilemma", RoundNumber: 3, MatchesDeleted: 4, MatchesCreated: 6, Enqueued: 6,
+		}, nil)
+
+		w := httptest.NewRecorder()
+		handler.RegenerateGameRound(w, newRegenerateRequest(tournamentID, gameID.String(), creatorID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var result tournament.RegenerateRoundResult
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+		assert.Equal(t, 3, result.RoundNumber)
+		assert.Equal(t, int64(4), result.MatchesDeleted)
+		assert.Equal(t, 6, result.MatchesCreated)
+	})
+
+	t.Run("forbidden for non-creator", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		tournamentID, creatorID := uuid.New(), uuid.New()
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID, CreatorID: &creatorID}, nil)
+
+		w := httptest.NewRecorder()
+		handler.RegenerateGameRound(w, newRegenerateRequest(tournamentID, uuid.New().String(), uuid.New(), domain.RoleUser))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "RegenerateGameRound", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid game ID", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		handler := NewTournamentHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.RegenerateGameRound(w, newRegenerateRequest(uuid.New(), "not-a-uuid", uuid.New(), domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TournamentMembership интерфейс для проверки участия пользователя в командах турнира
+type TournamentMembership interface {
+	IsUserInAnyTeamInTournament(ctx context.Context, tournamentID, userID uuid.UUID) (bool, error)
+}
+
+// TournamentAccessLookup интерфейс для загрузки турнира при проверке доступа
+type TournamentAccessLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error)
+}
+
+// TournamentAccess проверяет, может ли пользователь видеть данные турнира.
+// Общая проверка для всех маршрутов, отдающих данные турнира, и для подписок WebSocket
+type TournamentAccess struct {
+	tournaments TournamentAccessLookup
+	membership  TournamentMembership
+	log         *logger.Logger
+}
+
+// NewTournamentAccess создаёт проверку доступа к турнирам
+func NewTournamentAccess(tournaments TournamentAccessLookup, log *logger.Logger) *TournamentAccess {
+	return &TournamentAccess{
+		tournaments: tournaments,
+		log:         log,
+	}
+}
+
+// SetMembership устанавливает проверку участия в командах для доступа к приватным турнирам
+func (a *TournamentAccess) SetMembership(membership TournamentMembership) {
+	a.membership = membership
+}
+
+// Authorize загружает турнир и проверяет, что пользователь из контекста может видеть его данные.
+// Публичные и unlisted турниры доступны всем, приватные - участникам команд турнира,
+// создателю и админам. Для остальных приватный турнир не отличается от несуществующего
+func (a *TournamentAccess) Authorize(ctx context.Context, tournamentID uuid.UUID) (*domain.Tournament, error) {
+	t, err := a.tournaments.GetByID(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsPrivate() {
+		return t, nil
+	}
+
+	if role, _ := ctx.Value(middleware.RoleKey).(domain.Role); role == domain.RoleAdmin {
+		return t, nil
+	}
+
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, errors.ErrNotFound.WithMessage("tournament not found")
+	}
+	if t.CreatorID != nil && *t.CreatorID == userID {
+		return t, nil
+	}
+
+	if a.membership == nil {
+		return nil, errors.ErrServiceUnavailable.WithMessage("private tournaments are not available")
+	}
+	member, err := a.membership.IsUserInAnyTeamInTournament(ctx, tournamentID, userID)
+	if err != nil {
+		a.log.LogError("Failed to check tournament membership", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		return nil, err
+	}
+	if !member {
+		return nil, errors.ErrNotFound.WithMessage("tournament not found")
+	}
+
+	return t, nil
+}
+
+// checkTournamentAccess проверяет доступ к турниру, если проверка настроена (nil - без проверки)
+func checkTournamentAccess(r *http.Request, access *TournamentAccess, tournamentID uuid.UUID) error {
+	if access == nil {
+		return nil
+	}
+	_, err := access.Authorize(r.Context(), tournamentID)
+	return err
+}
+
+// SetAccess заменяет проверку доступа, чтобы все handlers использовали одну политику
+func (h *TournamentHandler) SetAccess(access *TournamentAccess) {
+	h.access = access
+}
+
+// SetMembership устанавливает проверку участия в командах для доступа к приватным турнирам
+func (h *TournamentHandler) SetMembership(membership TournamentMembership) {
+	h.access.SetMembership(membership)
+}
+
+// authorizeView загружает турнир и проверяет, что пользователь может видеть его данные
+func (h *TournamentHandler) authorizeView(r *http.Request, tournamentID uuid.UUID) (*domain.Tournament, error) {
+	return h.access.Authorize(r.Context(), tournamentID)
+}
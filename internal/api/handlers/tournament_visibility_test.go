@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/domain/tournament"
+	"github.com/bmstu-itstech/tjudge/internal/websocket"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTournamentMembership mocks the TournamentMembership interface
+type MockTournamentMembership struct {
+	mock.Mock
+}
+
+func (m *MockTournamentMembership) IsUserInAnyTeamInTournament(ctx context.Context, tournamentID, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, tournamentID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestTournamentHandler_Visibility(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	tournamentID := uuid.New()
+	creatorID, memberID, outsiderID, adminID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	viewers := []viewer{
+		{name: "anonymous"},
+		{name: "non-member", userID: &outsiderID, role: domain.RoleUser},
+		{name: "member", userID: &memberID, role: domain.RoleUser},
+		{name: "creator", userID: &creatorID, role: domain.RoleUser},
+		{name: "admin", userID: &adminID, role: domain.RoleAdmin},
+	}
+
+	// Expected status for each viewer by visibility
+	expected := map[domain.TournamentVisibility]map[string]int{
+		domain.VisibilityPublic: {
+			"anonymous": http.StatusOK, "non-member": http.StatusOK, "member": http.StatusOK,
+			"creator": http.StatusOK, "admin": http.StatusOK,
+		},
+		domain.VisibilityUnlisted: {
+			"anonymous": http.StatusOK, "non-member": http.StatusOK, "member": http.StatusOK,
+			"creator": http.StatusOK, "admin": http.StatusOK,
+		},
+		domain.VisibilityPrivate: {
+			"anonymous": http.StatusNotFound, "non-member": http.StatusNotFound, "member": http.StatusOK,
+			"creator": http.StatusOK, "admin": http.StatusOK,
+		},
+	}
+
+	endpoints := []struct {
+		name   string
+		target string
+		call   func(h *TournamentHandler, w http.ResponseWriter, r *http.Request)
+	}{
+		{"get", "/api/v1/tournaments/" + tournamentID.String(), (*TournamentHandler).Get},
+		{"leaderboard", "/api/v1/tournaments/" + tournamentID.String() + "/leaderboard", (*TournamentHandler).GetLeaderboard},
+		{"matches", "/api/v1/tournaments/" + tournamentID.String() + "/matches", (*TournamentHandler).GetMatches},
+		{"participants", "/api/v1/tournaments/" + tournamentID.String() + "/participants", (*TournamentHandler).ListParticipants},
+	}
+
+	for visibility, statuses := range expected {
+		for _, endpoint := range endpoints {
+			for _, v := range viewers {
+				t.Run(string(visibility)+"/"+endpoint.name+"/"+v.name, func(t *testing.T) {
+					mockService := new(MockTournamentService)
+					mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{
+						ID:         tournamentID,
+						Name:       "Cup",
+						Status:     domain.TournamentActive,
+						Visibility: visibility,
+						CreatorID:  &creatorID,
+					}, nil)
+					mockService.On("GetLeaderboard", mock.Anything, tournamentID, 100).Return([]*domain.LeaderboardEntry{}, nil).Maybe()
+					mockService.On("GetMatches", mock.Anything, tournamentID, 50, 0).Return([]*domain.Match{}, nil).Maybe()
+					mockService.On("GetParticipants", mock.Anything, tournamentID, mock.Anything).Return([]*domain.ParticipantEntry{}, nil).Maybe()
+
+					membership := new(MockTournamentMembership)
+					membership.On("IsUserInAnyTeamInTournament", mock.Anything, tournamentID, memberID).Return(true, nil).Maybe()
+					membership.On("IsUserInAnyTeamInTournament", mock.Anything, tournamentID, outsiderID).Return(false, nil).Maybe()
+
+					handler := NewTournamentHandler(mockService, log)
+					handler.SetMembership(membership)
+
+					w := httptest.NewRecorder()
+					endpoint.call(handler, w, newRouteRequest(http.MethodGet, endpoint.target, "", urlParams{"id": tournamentID.String()}, v))
+
+					require.Equal(t, statuses[v.name], w.Code, w.Body.String())
+					if w.Code == http.StatusNotFound {
+						// A hidden private tournament must not leak its data
+						mockService.AssertNotCalled(t, "GetLeaderboard", mock.Anything, mock.Anything, mock.Anything)
+						mockService.AssertNotCalled(t, "GetMatches", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+						mockService.AssertNotCalled(t, "GetParticipants", mock.Anything, mock.Anything, mock.Anything)
+					}
+				})
+			}
+		}
+	}
+
+	t.Run("private without membership source", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{
+			ID: tournamentID, Visibility: domain.VisibilityPrivate, CreatorID: &creatorID,
+		}, nil)
+		handler := NewTournamentHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.Get(w, newRouteRequest(http.MethodGet, "/", "", urlParams{"id": tournamentID.String()}, viewers[2]))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestTournamentHandler_List_Visibility(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		viewer  viewer
+		matches func(domain.TournamentFilter) bool
+	}{
+		{
+			name:   "anonymous sees public only",
+			viewer: viewer{},
+			matches: func(f domain.TournamentFilter) bool {
+				return f.ViewerID == nil && !f.AllVisibilities
+			},
+		},
+		{
+			name:   "user sees public and own private",
+			viewer: viewer{userID: &userID, role: domain.RoleUser},
+			matches: func(f domain.TournamentFilter) bool {
+				return f.ViewerID != nil && *f.ViewerID == userID && !f.AllVisibilities
+			},
+		},
+		{
+			name:   "admin sees all",
+			viewer: viewer{userID: &userID, role: domain.RoleAdmin},
+			matches: func(f domain.TournamentFilter) bool {
+				return f.AllVisibilities
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTournamentService)
+			mockService.On("List", mock.Anything, mock.MatchedBy(tt.matches)).Return([]*domain.Tournament{}, nil).Once()
+			handler := NewTournamentHandler(mockService, log)
+
+			w := httptest.NewRecorder()
+			handler.List(w, newRouteRequest(http.MethodGet, "/api/v1/tournaments", "", nil, tt.viewer))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTournamentHandler_Update(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+	creatorID := uuid.New()
+	private := domain.VisibilityPrivate
+
+	newRequest := func(body string, v viewer) *http.Request {
+		return newRouteRequest(http.MethodPatch, "/api/v1/tournaments/"+tournamentID.String(), body,
+			urlParams{"id": tournamentID.String()}, v)
+	}
+
+	t.Run("creator changes visibility", func(t *testing.T) {
+		mockService := new(MockTournamentService)
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID, CreatorID: &creatorID}, nil)
+		mockService.On("Update", mock.Anything, tournamentID, &tournament.UpdateRequest{Visibility: &private}).
+			Return(&domain.Tournament{ID: tournamentID, Visibility: private}, nil).Once()
+		handler := NewTournamentHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.Update(w, newRequest(`{"visibility":"private"}`, viewer{userID: &creatorID, role: domain.RoleUser}))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"visibility":"private"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("other users are forbidden", func(t *testing.T) {
+		otherID := uuid.New()
+		mockService := new(MockTournamentService)
+		mockService.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID, CreatorID: &creatorID}, nil)
+		handler := NewTournamentHandler(mockService, log)
+
+		w := httptest.NewRecorder()
+		handler.Update(w, newRequest(`{"visibility":"private"}`, viewer{userID: &otherID, role: domain.RoleUser}))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTournamentAccess_PublicRoutes(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	tournamentID, gameID := uuid.New(), uuid.New()
+	creatorID, outsiderID := uuid.New(), uuid.New()
+	outsider := viewer{name: "non-member", userID: &outsiderID, role: domain.RoleUser}
+
+	service := new(MockTournamentService)
+	service.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{
+		ID: tournamentID, Visibility: domain.VisibilityPrivate, CreatorID: &creatorID,
+	}, nil)
+	membership := new(MockTournamentMembership)
+	membership.On("IsUserInAnyTeamInTournament", mock.Anything, tournamentID, outsiderID).Return(false, nil)
+	access := NewTournamentAccess(service, log)
+	access.SetMembership(membership)
+
+	// A denied request must stop before the data sources, so the handlers have none
+	gameHandler := NewGameHandler(nil, log)
+	gameHandler.SetTournamentAccess(access)
+	teamHandler := NewTeamHandler(nil, "", log)
+	teamHandler.SetTournamentAccess(access)
+	wsHandler := NewWebSocketHandler(websocket.NewHub(log), log)
+	wsHandler.SetTournamentAccess(access)
+
+	match := &domain.Match{ID: uuid.New(), TournamentID: tournamentID, Status: domain.MatchCompleted}
+	matchRepo := new(MockMatchRepository)
+	matchRepo.On("GetByID", mock.Anything, match.ID).Return(match, nil)
+	matchCache := new(MockMatchCache)
+	matchCache.On("GetMatch", mock.Anything, match.ID).Return(match, nil)
+	matchHandler := NewMatchHandler(matchRepo, matchCache, log)
+	matchHandler.SetLiveFeed(&fakeLiveFeed{})
+	matchHandler.SetTournamentAccess(access)
+
+	tournamentPath := "/api/v1/tournaments/" + tournamentID.String()
+	gamePath := tournamentPath + "/games/" + gameID.String()
+	routes := []struct {
+		name   string
+		target string
+		id     uuid.UUID
+		call   http.HandlerFunc
+	}{
+		{"games", tournamentPath + "/games", tournamentID, gameHandler.GetTournamentGames},
+		{"teams", tournamentPath + "/teams", tournamentID, teamHandler.GetTournamentTeams},
+		{"games status", tournamentPath + "/games/status", tournamentID, gameHandler.GetTournamentGamesWithStatus},
+		{"active game", tournamentPath + "/active-game", tournamentID, gameHandler.GetActiveGame},
+		{"game leaderboard", gamePath + "/leaderboard", tournamentID, gameHandler.GetGameLeaderboard},
+		{"game matches", gamePath + "/matches", tournamentID, gameHandler.GetGameMatches},
+		{"match list", "/api/v1/matches?tournament_id=" + tournamentID.String(), uuid.Nil, matchHandler.List},
+		{"match", "/api/v1/matches/" + match.ID.String(), match.ID, matchHandler.Get},
+		{"live match", "/api/v1/matches/" + match.ID.String() + "/live", match.ID, matchHandler.Live},
+		{"tournament websocket", "/api/v1/ws/tournaments/" + tournamentID.String(), tournamentID, wsHandler.HandleTournament},
+	}
+
+	for _, route := range routes {
+		t.Run(route.name, func(t *testing.T) {
+			params := urlParams{"id": route.id.String(), "gameId": gameID.String()}
+
+			w := httptest.NewRecorder()
+			route.call(w, newRouteRequest(http.MethodGet, route.target, "", params, outsider))
+
+			assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+		})
+	}
+
+	t.Run("match list without tournament hides private tournaments", func(t *testing.T) {
+		repo := new(MockMatchRepository)
+		repo.On("List", mock.Anything, mock.MatchedBy(func(f domain.MatchFilter) bool {
+			return f.HidePrivate && f.ViewerID != nil && *f.ViewerID == outsiderID
+		})).Return([]*domain.Match{}, nil).Once()
+		handler := NewMatchHandler(repo, new(MockMatchCache), log)
+		handler.SetTournamentAccess(access)
+
+		w := httptest.NewRecorder()
+		handler.List(w, newRouteRequest(http.MethodGet, "/api/v1/matches", "", nil, outsider))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		repo.AssertExpectations(t)
+	})
+}
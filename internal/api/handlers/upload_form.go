@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Запас на поля формы и заголовки частей сверх размера самого файла
+const (
+	uploadFormOverhead = 64 << 10
+	uploadFieldLimit   = 4 << 10
+)
+
+// uploadForm - разобранная форма загрузки программы. Файл уже записан
+// во временный файл в директории загрузок и переносится на место через keep
+type uploadForm struct {
+	fields      map[string]string
+	fileName    string
+	language    string
+	contentHash string
+	tempPath    string
+}
+
+// value возвращает значение поля формы (первое, как r.FormValue)
+func (f *uploadForm) value(name string) string {
+	return f.fields[name]
+}
+
+// keep переносит загруженный файл в filePath и делает его исполняемым
+func (f *uploadForm) keep(filePath string) error {
+	if err := os.Rename(f.tempPath, filePath); err != nil {
+		return err
+	}
+	f.tempPath = ""
+	return os.Chmod(filePath, 0755)
+}
+
+// discard удаляет временный файл, если он не был перенесён
+func (f *uploadForm) discard() {
+	if f.tempPath != "" {
+		os.Remove(f.tempPath)
+		f.tempPath = ""
+	}
+}
+
+// readUploadForm читает multipart-форму потоком: файл сразу пишется на диск
+// (с shebang для интерпретируемых языков) и хэшируется по ходу записи.
+// beforeFile вызывается с уже прочитанными полями, когда начинается часть файла:
+// его ошибка прерывает чтение формы до записи файла на диск
+func (h *ProgramHandler) readUploadForm(w http.ResponseWriter, r *http.Request, beforeFile func(*uploadForm) error) (*uploadForm, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxFileSize+uploadFormOverhead)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		h.log.Info("Failed to read multipart form", zap.Error(err))
+		return nil, errors.ErrInvalidInput.WithMessage("file too large or invalid form")
+	}
+
+	form := &uploadForm{fields: make(map[string]string)}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			form.discard()
+			h.log.Info("Failed to read multipart form", zap.Error(err))
+			return nil, errors.ErrInvalidInput.WithMessage("file too large or invalid form")
+		}
+
+		name := part.FormName()
+		switch {
+		case name == "file" && part.FileName() != "" && form.tempPath == "":
+			if beforeFile != nil {
+				err = beforeFile(form)
+			}
+			if err == nil {
+				err = h.streamUpload(form, part)
+			}
+		case name != "" && part.FileName() == "":
+			err = form.readField(name, part)
+		}
+		part.Close()
+		if err != nil {
+			form.discard()
+			return nil, err
+		}
+	}
+
+	if form.tempPath == "" {
+		return nil, errors.ErrInvalidInput.WithMessage("file is required")
+	}
+	return form, nil
+}
+
+// readField запоминает значение поля формы; повторные значения игнорируются
+func (f *uploadForm) readField(name string, part io.Reader) error {
+	value, err := io.ReadAll(io.LimitReader(part, uploadFieldLimit+1))
+	if err != nil || len(value) > uploadFieldLimit {
+		return errors.ErrInvalidInput.WithMessage("invalid form field " + name)
+	}
+	if _, ok := f.fields[name]; !ok {
+		f.fields[name] = string(value)
+	}
+	return nil
+}
+
+// streamUpload записывает файл из части формы во временный файл, вычисляя
+// SHA-256 содержимого в том виде, в котором программа будет сохранена
+func (h *ProgramHandler) streamUpload(form *uploadForm, part *multipart.Part) error {
+	dst, err := os.CreateTemp(h.uploadDir, ".upload-*")
+	if err != nil {
+		h.log.Error("Failed to create file", zap.Error(err), zap.String("dir", h.uploadDir))
+		return errors.ErrInternal.WithMessage("failed to save file")
+	}
+	defer dst.Close()
+	form.tempPath = dst.Name()
+	form.fileName = part.FileName()
+	form.language = detectLanguage(form.fileName)
+
+	hasher := sha256.New()
+	out := io.MultiWriter(dst, hasher)
+	src := bufio.NewReader(io.LimitReader(part, h.maxFileSize+1))
+
+	// Добавляем shebang для интерпретируемых языков (если его нет)
+	if shebang := getShebang(form.language); shebang != "" {
+		if first, _ := src.Peek(2); string(first) != "#!" {
+			if _, err := io.WriteString(out, shebang); err != nil {
+				h.log.Error("Failed to write shebang", zap.Error(err))
+				return errors.ErrInternal.WithMessage("failed to save file")
+			}
+		}
+	}
+
+	n, err := io.Copy(out, src)
+	if err != nil {
+		h.log.Info("Failed to read uploaded file", zap.Error(err))
+		return errors.ErrInvalidInput.WithMessage("file too large or invalid form")
+	}
+	if n > h.maxFileSize {
+		return errors.ErrInvalidInput.WithMessage("file too large or invalid form")
+	}
+
+	form.contentHash = hex.EncodeToString(hasher.Sum(nil))
+	return nil
+}
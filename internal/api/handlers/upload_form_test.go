@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFormRequest builds a multipart request; parts are written in the given order
+func newFormRequest(t *testing.T, parts func(w *multipart.Writer)) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	parts(writer)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/programs", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func writeFile(t *testing.T, w *multipart.Writer, name, content string) {
+	part, err := w.CreateFormFile("file", name)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+}
+
+func TestProgramHandler_ReadUploadForm(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	dir := t.TempDir()
+	t.Setenv("PROGRAMS_PATH", dir)
+	handler := NewProgramHandler(nil, nil, nil, nil, log)
+
+	t.Run("fields around the file and shebang added on the fly", func(t *testing.T) {
+		req := newFormRequest(t, func(w *multipart.Writer) {
+			require.NoError(t, w.WriteField("team_id", "before"))
+			writeFile(t, w, "bot.py", "print(1)\n")
+			require.NoError(t, w.WriteField("game_id", "after"))
+		})
+
+		form, err := handler.readUploadForm(httptest.NewRecorder(), req, nil)
+		require.NoError(t, err)
+		defer form.discard()
+
+		assert.Equal(t, "before", form.value("team_id"))
+		assert.Equal(t, "after", form.value("game_id"))
+		assert.Equal(t, "bot.py", form.fileName)
+		assert.Equal(t, "python", form.language)
+
+		saved, err := os.ReadFile(form.tempPath)
+		require.NoError(t, err)
+		assert.Equal(t, getShebang("python")+"print(1)\n", string(saved))
+
+		// The streamed hash matches the hash of the stored content
+		expected, _, err := hashUpload(strings.NewReader("print(1)\n"), getShebang("python"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, form.contentHash)
+	})
+
+	t.Run("existing shebang is kept", func(t *testing.T) {
+		req := newFormRequest(t, func(w *multipart.Writer) {
+			writeFile(t, w, "bot.py", "#!/usr/bin/python3\nprint(1)\n")
+		})
+
+		form, err := handler.readUploadForm(httptest.NewRecorder(), req, nil)
+		require.NoError(t, err)
+		defer form.discard()
+
+		saved, err := os.ReadFile(form.tempPath)
+		require.NoError(t, err)
+		assert.Equal(t, "#!/usr/bin/python3\nprint(1)\n", string(saved))
+	})
+
+	t.Run("kept file becomes executable", func(t *testing.T) {
+		req := newFormRequest(t, func(w *multipart.Writer) {
+			writeFile(t, w, "bot.bin", "binary")
+		})
+
+		form, err := handler.readUploadForm(httptest.NewRecorder(), req, nil)
+		require.NoError(t, err)
+
+		target := dir + "/kept.bin"
+		require.NoError(t, form.keep(target))
+		form.discard()
+
+		info, err := os.Stat(target)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	})
+
+	t.Run("oversized file is rejected and removed", func(t *testing.T) {
+		small := NewProgramHandler(nil, nil, nil, nil, log)
+		small.maxFileSize = 8
+
+		req := newFormRequest(t, func(w *multipart.Writer) {
+			writeFile(t, w, "bot.bin", "0123456789")
+		})
+
+		_, err := small.readUploadForm(httptest.NewRecorder(), req, nil)
+		require.Error(t, err)
+
+		leftovers, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		for _, entry := range leftovers {
+			assert.False(t, strings.HasPrefix(entry.Name(), ".upload-"), "temporary file must be removed")
+		}
+	})
+
+	t.Run("beforeFile sees earlier fields and stops before writing", func(t *testing.T) {
+		req := newFormRequest(t, func(w *multipart.Writer) {
+			require.NoError(t, w.WriteField("team_id", "before"))
+			writeFile(t, w, "bot.bin", "binary")
+			require.NoError(t, w.WriteField("game_id", "after"))
+		})
+
+		var seen map[string]string
+		_, err := handler.readUploadForm(httptest.NewRecorder(), req, func(form *uploadForm) error {
+			seen = map[string]string{"team_id": form.value("team_id"), "game_id": form.value("game_id")}
+			return errors.ErrRateLimitExceeded
+		})
+		require.ErrorIs(t, err, errors.ErrRateLimitExceeded)
+		assert.Equal(t, map[string]string{"team_id": "before", "game_id": ""}, seen)
+
+		leftovers, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		for _, entry := range leftovers {
+			assert.False(t, strings.HasPrefix(entry.Name(), ".upload-"), "file must not be written")
+		}
+	})
+
+	t.Run("file is required", func(t *testing.T) {
+		req := newFormRequest(t, func(w *multipart.Writer) {
+			require.NoError(t, w.WriteField("team_id", "x"))
+		})
+
+		_, err := handler.readUploadForm(httptest.NewRecorder(), req, nil)
+		assert.ErrorContains(t, err, "file is required")
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/google/uuid"
+)
+
+// Лимит загрузок программ одной командой: каждая загрузка ставит в очередь новые матчи
+const (
+	teamUploadLimit  = 10
+	teamUploadWindow = time.Hour
+)
+
+// TeamUploadRateLimiter ограничивает число загрузок программ команды за окно времени
+type TeamUploadRateLimiter struct {
+	limiter middleware.RateLimiter
+	limit   int
+	window  time.Duration
+}
+
+// NewTeamUploadRateLimiter создаёт лимит загрузок: teamUploadLimit загрузок за teamUploadWindow
+func NewTeamUploadRateLimiter(limiter middleware.RateLimiter) *TeamUploadRateLimiter {
+	return &TeamUploadRateLimiter{
+		limiter: limiter,
+		limit:   teamUploadLimit,
+		window:  teamUploadWindow,
+	}
+}
+
+// teamUploadKey ключ лимита загрузок команды
+func teamUploadKey(teamID uuid.UUID) string {
+	return "program_upload:" + teamID.String()
+}
+
+// Allow учитывает загрузку и проверяет, не превышен ли лимит для ключа
+func (l *TeamUploadRateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	return l.limiter.Allow(ctx, key, l.limit, l.window)
+}
+
+// RetryAfter возвращает время до сброса окна для ключа (всё окно, если его не удалось прочитать)
+func (l *TeamUploadRateLimiter) RetryAfter(ctx context.Context, key string) time.Duration {
+	status, err := l.limiter.GetStatus(ctx, key)
+	if err != nil || status == nil {
+		return l.window
+	}
+	if until := time.Until(status.ResetAt); until > 0 {
+		return until
+	}
+	return 0
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedWindowLimiter is an in-memory fixed window limiter with a controllable clock
+type fixedWindowLimiter struct {
+	mu      sync.Mutex
+	now     time.Time
+	windows map[string]*cache.RateLimitStatus
+	counts  map[string]int
+}
+
+func newFixedWindowLimiter() *fixedWindowLimiter {
+	return &fixedWindowLimiter{
+		now:     time.Now(),
+		windows: make(map[string]*cache.RateLimitStatus),
+		counts:  make(map[string]int),
+	}
+}
+
+func (l *fixedWindowLimiter) advance(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = l.now.Add(d)
+}
+
+func (l *fixedWindowLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	status, ok := l.windows[key]
+	if !ok || !status.ResetAt.After(l.now) {
+		l.windows[key] = &cache.RateLimitStatus{Limit: limit, Remaining: limit - 1, ResetAt: l.now.Add(window)}
+		l.counts[key] = 1
+		return true, nil
+	}
+	if l.counts[key] >= limit {
+		return false, nil
+	}
+	l.counts[key]++
+	status.Remaining = limit - l.counts[key]
+	return true, nil
+}
+
+func (l *fixedWindowLimiter) GetStatus(_ context.Context, key string) (*cache.RateLimitStatus, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.windows[key], nil
+}
+
+func TestTeamUploadRateLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("limit resets after the window", func(t *testing.T) {
+		base := newFixedWindowLimiter()
+		limiter := NewTeamUploadRateLimiter(base)
+		key := teamUploadKey(uuid.New())
+
+		for i := 0; i < teamUploadLimit; i++ {
+			allowed, err := limiter.Allow(ctx, key)
+			require.NoError(t, err)
+			require.True(t, allowed)
+		}
+		allowed, err := limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+		assert.InDelta(t, teamUploadWindow.Seconds(), limiter.RetryAfter(ctx, key).Seconds(), 5)
+
+		base.advance(teamUploadWindow + time.Second)
+		allowed, err = limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("teams have independent limits", func(t *testing.T) {
+		limiter := NewTeamUploadRateLimiter(newFixedWindowLimiter())
+		first, second := teamUploadKey(uuid.New()), teamUploadKey(uuid.New())
+
+		for i := 0; i < teamUploadLimit; i++ {
+			allowed, err := limiter.Allow(ctx, first)
+			require.NoError(t, err)
+			require.True(t, allowed)
+		}
+		allowed, err := limiter.Allow(ctx, first)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		allowed, err = limiter.Allow(ctx, second)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	})
+}
+
+func TestProgramHandler_TeamUploadRateLimit(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	dir := t.TempDir()
+	t.Setenv("PROGRAMS_PATH", dir)
+
+	userID := uuid.New()
+	tournamentID := uuid.New()
+	gameID := uuid.New()
+	limitedTeam := uuid.New()
+	otherTeam := uuid.New()
+
+	base := newFixedWindowLimiter()
+	for i := 0; i < teamUploadLimit; i++ {
+		_, _ = base.Allow(context.Background(), teamUploadKey(limitedTeam), teamUploadLimit, teamUploadWindow)
+	}
+
+	t.Run("exceeded limit returns 429 before touching the file", func(t *testing.T) {
+		// No repository expectations: any call past the limiter would fail the test
+		mockRepo := new(MockProgramRepository)
+		handler := NewProgramHandler(mockRepo, nil, nil, base, log)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, limitedTeam, tournamentID, gameID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.InDelta(t, teamUploadWindow.Seconds(), retryAfter, 5)
+		mockRepo.AssertExpectations(t)
+
+		written, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, written, "file must not be written past the limit")
+	})
+
+	t.Run("admins are not limited", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		mockRepo.On("GetLatestByTeamAndGame", mock.Anything, limitedTeam, gameID).Return(nil, errors.ErrProgramNotFound)
+		mockRepo.On("GetLatestVersion", mock.Anything, limitedTeam, gameID).Return(1, nil)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+		handler := NewProgramHandler(mockRepo, nil, nil, base, log)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, limitedTeam, tournamentID, gameID, domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("other team can still upload", func(t *testing.T) {
+		mockRepo := new(MockProgramRepository)
+		mockRepo.On("GetLatestByTeamAndGame", mock.Anything, otherTeam, gameID).Return(nil, errors.ErrProgramNotFound)
+		mockRepo.On("GetLatestVersionCreatedAt", mock.Anything, otherTeam, gameID).Return(nil, nil)
+		mockRepo.On("GetLatestVersion", mock.Anything, otherTeam, gameID).Return(1, nil)
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Program")).Return(nil)
+		handler := NewProgramHandler(mockRepo, nil, nil, base, log)
+
+		w := httptest.NewRecorder()
+		handler.Create(w, newUploadRequest(t, userID, otherTeam, tournamentID, gameID, domain.RoleUser))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+}
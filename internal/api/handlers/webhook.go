@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/domain/webhook"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 200
+)
+
+// WebhookService интерфейс управления webhook'ами турниров
+type WebhookService interface {
+	Create(ctx context.Context, tournamentID, createdBy uuid.UUID, req *webhook.CreateRequest) (*domain.Webhook, error)
+	List(ctx context.Context, tournamentID uuid.UUID) ([]*domain.Webhook, error)
+	Delete(ctx context.Context, tournamentID, webhookID uuid.UUID) error
+	Deliveries(ctx context.Context, tournamentID, webhookID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error)
+}
+
+// WebhookTournamentLookup интерфейс для проверки владельца турнира
+type WebhookTournamentLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error)
+}
+
+// WebhookHandler обрабатывает запросы webhook'ов турниров.
+// Управлять webhook'ами могут админы и создатель турнира
+type WebhookHandler struct {
+	webhookService WebhookService
+	tournaments    WebhookTournamentLookup
+	log            *logger.Logger
+}
+
+// NewWebhookHandler создаёт новый webhook handler
+func NewWebhookHandler(webhookService WebhookService, tournaments WebhookTournamentLookup, log *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		tournaments:    tournaments,
+		log:            log,
+	}
+}
+
+// createWebhookResponse webhook с секретом: он возвращается только при создании
+type createWebhookResponse struct {
+	*domain.Webhook
+	Secret string `json:"secret"`
+}
+
+// Create регистрирует webhook турнира
+// POST /api/v1/tournaments/:id/webhooks
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	tournamentID, userID, err := h.authorize(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req webhook.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Info("Invalid request body", zap.Error(err))
+		writeError(w, errors.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	created, err := h.webhookService.Create(r.Context(), tournamentID, userID, &req)
+	if err != nil {
+		h.log.LogError("Failed to create webhook", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createWebhookResponse{Webhook: created, Secret: created.Secret})
+}
+
+// List возвращает webhook'и турнира без секретов
+// GET /api/v1/tournaments/:id/webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	tournamentID, _, err := h.authorize(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	webhooks, err := h.webhookService.List(r.Context(), tournamentID)
+	if err != nil {
+		h.log.LogError("Failed to list webhooks", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+// Delete удаляет webhook турнира
+// DELETE /api/v1/tournaments/:id/webhooks/:webhookId
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	tournamentID, _, err := h.authorize(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid webhook ID"))
+		return
+	}
+
+	if err := h.webhookService.Delete(r.Context(), tournamentID, webhookID); err != nil {
+		h.log.LogError("Failed to delete webhook", err,
+			zap.String("webhook_id", webhookID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries возвращает последние доставки webhook'а со статусом и ошибкой последней попытки
+// GET /api/v1/tournaments/:id/webhooks/:webhookId/deliveries?limit=50
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	tournamentID, _, err := h.authorize(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		writeError(w, errors.ErrInvalidInput.WithMessage("invalid webhook ID"))
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxDeliveriesLimit {
+			writeError(w, errors.ErrInvalidInput.WithMessage(fmt.Sprintf("limit must be between 1 and %d", maxDeliveriesLimit)))
+			return
+		}
+	}
+
+	deliveries, err := h.webhookService.Deliveries(r.Context(), tournamentID, webhookID, limit)
+	if err != nil {
+		h.log.LogError("Failed to list webhook deliveries", err,
+			zap.String("webhook_id", webhookID.String()),
+		)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// authorize возвращает ID турнира и пользователя, если пользователь - админ или создатель турнира
+func (h *WebhookHandler) authorize(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.ErrInvalidInput.WithMessage("invalid tournament ID")
+	}
+
+	userID, err := middleware.RequireUserID(r.Context())
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	tournament, err := h.tournaments.GetByID(r.Context(), tournamentID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	role, _ := r.Context().Value(middleware.RoleKey).(domain.Role)
+	if role != domain.RoleAdmin && (tournament.CreatorID == nil || *tournament.CreatorID != userID) {
+		return uuid.Nil, uuid.Nil, errors.ErrForbidden.WithMessage("only admins or tournament creator can manage webhooks")
+	}
+
+	return tournamentID, userID, nil
+}
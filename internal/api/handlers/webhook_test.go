@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/domain/webhook"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) Create(ctx context.Context, tournamentID, createdBy uuid.UUID, req *webhook.CreateRequest) (*domain.Webhook, error) {
+	args := m.Called(ctx, tournamentID, createdBy, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) List(ctx context.Context, tournamentID uuid.UUID) ([]*domain.Webhook, error) {
+	args := m.Called(ctx, tournamentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) Delete(ctx context.Context, tournamentID, webhookID uuid.UUID) error {
+	return m.Called(ctx, tournamentID, webhookID).Error(0)
+}
+
+func (m *MockWebhookService) Deliveries(ctx context.Context, tournamentID, webhookID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, tournamentID, webhookID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+// staticTournamentLookup returns a tournament owned by creatorID
+type staticTournamentLookup struct {
+	creatorID uuid.UUID
+}
+
+func (l staticTournamentLookup) GetByID(_ context.Context, id uuid.UUID) (*domain.Tournament, error) {
+	return &domain.Tournament{ID: id, CreatorID: &l.creatorID}, nil
+}
+
+func newWebhookRequest(method, body string, tournamentID, userID uuid.UUID, role domain.Role, params map[string]string) *http.Request {
+	req := httptest.NewRequest(method, "/api/v1/tournaments/"+tournamentID.String()+"/webhooks", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", tournamentID.String())
+	for key, value := range params {
+		rctx.URLParams.Add(key, value)
+	}
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+	ctx = context.WithValue(ctx, middleware.RoleKey, role)
+	return req.WithContext(ctx)
+}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+	creatorID := uuid.New()
+
+	t.Run("creator gets the secret once", func(t *testing.T) {
+		created := &domain.Webhook{ID: uuid.New(), TournamentID: tournamentID, URL: "https://lms.example.com/hook",
+			Secret: "generated-secret", Events: domain.WebhookEvents}
+		service := new(MockWebhookService)
+		service.On("Create", mock.Anything, tournamentID, creatorID, &webhook.CreateRequest{URL: "https://lms.example.com/hook"}).
+			Return(created, nil)
+
+		w := httptest.NewRecorder()
+		NewWebhookHandler(service, staticTournamentLookup{creatorID}, log).Create(w,
+			newWebhookRequest(http.MethodPost, `{"url":"https://lms.example.com/hook"}`, tournamentID, creatorID, domain.RoleUser, nil))
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		var resp map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "generated-secret", resp["secret"])
+		assert.Equal(t, created.ID.String(), resp["id"])
+		assert.Len(t, resp["events"], len(domain.WebhookEvents))
+	})
+
+	t.Run("list hides secrets", func(t *testing.T) {
+		service := new(MockWebhookService)
+		service.On("List", mock.Anything, tournamentID).
+			Return([]*domain.Webhook{{ID: uuid.New(), Secret: "generated-secret"}}, nil)
+
+		w := httptest.NewRecorder()
+		NewWebhookHandler(service, staticTournamentLookup{creatorID}, log).List(w,
+			newWebhookRequest(http.MethodGet, "", tournamentID, creatorID, domain.RoleUser, nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "generated-secret")
+	})
+
+	t.Run("other users are forbidden", func(t *testing.T) {
+		service := new(MockWebhookService)
+
+		w := httptest.NewRecorder()
+		NewWebhookHandler(service, staticTournamentLookup{creatorID}, log).Create(w,
+			newWebhookRequest(http.MethodPost, `{"url":"https://lms.example.com/hook"}`, tournamentID, uuid.New(), domain.RoleUser, nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		service.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("admins manage any tournament", func(t *testing.T) {
+		adminID := uuid.New()
+		service := new(MockWebhookService)
+		service.On("List", mock.Anything, tournamentID).Return([]*domain.Webhook{}, nil)
+
+		w := httptest.NewRecorder()
+		NewWebhookHandler(service, staticTournamentLookup{creatorID}, log).List(w,
+			newWebhookRequest(http.MethodGet, "", tournamentID, adminID, domain.RoleAdmin, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestWebhookHandler_Deliveries(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+	creatorID := uuid.New()
+	webhookID := uuid.New()
+	params := map[string]string{"webhookId": webhookID.String()}
+
+	t.Run("returns failed attempts", func(t *testing.T) {
+		lastError := "webhook responded with 500: oops"
+		service := new(MockWebhookService)
+		service.On("Deliveries", mock.Anything, tournamentID, webhookID, defaultDeliveriesLimit).
+			Return([]*domain.WebhookDelivery{{ID: 7, Event: domain.WebhookRoundCompleted, Status: domain.NotificationFailed,
+				Attempts: 8, LastError: &lastError, Payload: json.RawMessage(`{"event":"round.completed"}`)}}, nil)
+
+		w := httptest.NewRecorder()
+		NewWebhookHandler(service, staticTournamentLookup{creatorID}, log).Deliveries(w,
+			newWebhookRequest(http.MethodGet, "", tournamentID, creatorID, domain.RoleUser, params))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp []map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp, 1)
+		assert.Equal(t, "failed", resp[0]["status"])
+		assert.Equal(t, lastError, resp[0]["last_error"])
+		assert.Equal(t, map[string]interface{}{"event": "round.completed"}, resp[0]["payload"])
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		service := new(MockWebhookService)
+		req := newWebhookRequest(http.MethodGet, "", tournamentID, creatorID, domain.RoleUser, params)
+		req.URL.RawQuery = "limit=1000"
+
+		w := httptest.NewRecorder()
+		NewWebhookHandler(service, staticTournamentLookup{creatorID}, log).Deliveries(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		service.AssertNotCalled(t, "Deliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
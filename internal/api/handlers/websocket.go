@@ -1,9 +1,10 @@
 package handlers
 
 import (
+	"context"
+	stderrors "errors"
 	"net/http"
-	"os"
-	"strings"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/websocket"
@@ -15,41 +16,61 @@ import (
 	"go.uber.org/zap"
 )
 
-var upgrader = ws.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		allowedOrigins := os.Getenv("WEBSOCKET_ALLOWED_ORIGINS")
-		if allowedOrigins == "" {
-			return true
-		}
-
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			return true
-		}
-
-		for _, allowed := range strings.Split(allowedOrigins, ",") {
-			if strings.TrimSpace(allowed) == origin {
-				return true
-			}
-		}
-		return false
-	},
-}
-
 // WebSocketHandler обрабатывает WebSocket подключения
 type WebSocketHandler struct {
-	hub *websocket.Hub
-	log *logger.Logger
+	hub      *websocket.Hub
+	access   *TournamentAccess
+	log      *logger.Logger
+	upgrader ws.Upgrader
 }
 
+// subscribeCheckTimeout ограничивает проверку доступа при подписке на турнир
+const subscribeCheckTimeout = 5 * time.Second
+
 // NewWebSocketHandler создаёт новый WebSocket handler
 func NewWebSocketHandler(hub *websocket.Hub, log *logger.Logger) *WebSocketHandler {
 	return &WebSocketHandler{
 		hub: hub,
 		log: log,
+		upgrader: ws.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// SetAllowedOrigins ограничивает источники, с которых браузер может открыть подключение.
+// Запросы без Origin (не из браузера) пропускаются
+func (h *WebSocketHandler) SetAllowedOrigins(origins []string) {
+	matcher := middleware.NewOriginMatcher(origins)
+	h.upgrader.CheckOrigin = func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || matcher.Allow(origin)
+	}
+}
+
+// SetTournamentAccess включает проверку доступа к приватным турнирам при подключении
+// к турниру и при подписке сообщением subscribe
+func (h *WebSocketHandler) SetTournamentAccess(access *TournamentAccess) {
+	h.access = access
+}
+
+// authorizeSubscriptions проверяет доступ к турниру для каждого сообщения subscribe.
+// Контекст запроса отменяется после upgrade, поэтому проверки идут в его копии без отмены
+func (h *WebSocketHandler) authorizeSubscriptions(r *http.Request, client *websocket.Client) {
+	if h.access == nil {
+		return
 	}
+	base := context.WithoutCancel(r.Context())
+	client.SetAuthorizer(func(tournamentID uuid.UUID) error {
+		ctx, cancel := context.WithTimeout(base, subscribeCheckTimeout)
+		defer cancel()
+		if _, err := h.access.Authorize(ctx, tournamentID); err != nil {
+			return stderrors.New(errors.ToAppError(err).Message)
+		}
+		return nil
+	})
 }
 
 // HandleTournament обрабатывает подключение к турниру
@@ -70,8 +91,14 @@ func (h *WebSocketHandler) HandleTournament(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Приватный турнир проверяется до upgrade: отказ приходит обычным HTTP-ответом
+	if err := checkTournamentAccess(r, h.access, tournamentID); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	// Upgrade HTTP соединения в WebSocket
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		h.log.LogError("Failed to upgrade connection", err,
 			zap.String("tournament_id", tournamentID.String()),
@@ -85,17 +112,49 @@ func (h *WebSocketHandler) HandleTournament(w http.ResponseWriter, r *http.Reque
 		zap.String("user_id", userID.String()),
 	)
 
-	// Создаём клиента
-	client := websocket.NewClient(h.hub, conn, tournamentID, userID, h.log)
-
-	// Регистрируем клиента в hub
+	// Создаём клиента, подписанного на турнир из URL
+	client := websocket.NewClient(h.hub, conn, userID, h.log)
+	h.authorizeSubscriptions(r, client)
 	client.Register()
+	client.Subscribe(tournamentID)
 
 	// Запускаем горутины для чтения и записи
 	go client.WritePump()
 	go client.ReadPump()
 }
 
+// Handle обрабатывает подключение без подписок: клиент сам подписывается
+// на турниры сообщениями {"type":"subscribe","tournament_id":"..."} и
+// отписывается {"type":"unsubscribe","tournament_id":"..."}
+// WS /api/v1/ws
+func (h *WebSocketHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	// Извлекаем user ID из контекста (должен быть установлен auth middleware)
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, errors.ErrUnauthorized.WithMessage("authentication required"))
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.log.LogError("Failed to upgrade connection", err,
+			zap.String("user_id", userID.String()),
+		)
+		return
+	}
+
+	h.log.Info("WebSocket connection established",
+		zap.String("user_id", userID.String()),
+	)
+
+	client := websocket.NewClient(h.hub, conn, userID, h.log)
+	h.authorizeSubscriptions(r, client)
+	client.Register()
+
+	go client.WritePump()
+	go client.ReadPump()
+}
+
 // GetStats возвращает статистику WebSocket подключений
 // GET /api/v1/ws/stats
 func (h *WebSocketHandler) GetStats(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/websocket"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocketHandler_RejectsUnauthenticated(t *testing.T) {
+	log, _ := logger.New("debug", "json")
+	handler := NewWebSocketHandler(websocket.NewHub(log), log)
+
+	r := chi.NewRouter()
+	r.Get("/ws", handler.Handle)
+	r.Get("/ws/tournaments/{id}", handler.HandleTournament)
+
+	for _, path := range []string{"/ws", "/ws/tournaments/" + uuid.New().String()} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, path, nil)
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
+
+func TestWebSocketHandler_SubscribeChecksTournamentAccess(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	hub := websocket.NewHub(log)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	privateID, publicID := uuid.New(), uuid.New()
+	outsiderID := uuid.New()
+	service := new(MockTournamentService)
+	service.On("GetByID", mock.Anything, privateID).Return(&domain.Tournament{ID: privateID, Visibility: domain.VisibilityPrivate}, nil)
+	service.On("GetByID", mock.Anything, publicID).Return(&domain.Tournament{ID: publicID, Visibility: domain.VisibilityPublic}, nil)
+	membership := new(MockTournamentMembership)
+	membership.On("IsUserInAnyTeamInTournament", mock.Anything, privateID, outsiderID).Return(false, nil)
+	access := NewTournamentAccess(service, log)
+	access.SetMembership(membership)
+
+	handler := NewWebSocketHandler(hub, log)
+	handler.SetTournamentAccess(access)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.UserIDKey, outsiderID)
+		ctx = context.WithValue(ctx, middleware.RoleKey, domain.RoleUser)
+		handler.Handle(w, r.WithContext(ctx))
+	}))
+	defer server.Close()
+
+	conn, _, err := ws.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	subscribe := func(tournamentID uuid.UUID) websocket.Message {
+		require.NoError(t, conn.WriteJSON(websocket.Message{TournamentID: tournamentID, Type: websocket.MessageTypeSubscribe}))
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		var reply websocket.Message
+		require.NoError(t, conn.ReadJSON(&reply))
+		return reply
+	}
+
+	denied := subscribe(privateID)
+	assert.Equal(t, websocket.MessageTypeError, denied.Type)
+	assert.Equal(t, map[string]interface{}{"error": "tournament not found"}, denied.Payload)
+	assert.Zero(t, hub.GetStats()["subscriptions"])
+
+	assert.Equal(t, websocket.MessageTypeSubscribed, subscribe(publicID).Type)
+}
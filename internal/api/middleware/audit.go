@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+// maxAuditIPLength ограничение длины ip_address: X-Forwarded-For может содержать цепочку адресов
+const maxAuditIPLength = 255
+
+// AuditRecorder сохраняет записи журнала действий администраторов
+type AuditRecorder interface {
+	Record(ctx context.Context, entry *domain.AuditLogEntry) error
+}
+
+// auditTargetTypes тип объекта по сегменту пути перед его ID
+var auditTargetTypes = map[string]string{
+	"tournaments": "tournament",
+	"games":       "game",
+	"matches":     "match",
+	"programs":    "program",
+	"teams":       "team",
+	"users":       "user",
+	"impersonate": "user",
+}
+
+// AuditLogger middleware записывает в журнал успешные изменяющие запросы администраторов.
+// Ставится после Auth: запросы пользователей без роли admin и безопасные методы не записываются.
+// Вместо тела запроса сохраняется его SHA-256, параметры маршрута и строки запроса - как есть
+func AuditLogger(recorder AuditRecorder, log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if recorder == nil || isSafeMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if role, err := RequireRoleValue(r.Context()); err != nil || role != domain.RoleAdmin {
+				next.ServeHTTP(w, r)
+				return
+			}
+			userID, ok := GetUserID(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			startedAt := time.Now()
+			bodyHash, err := hashRequestBody(r)
+			if err != nil {
+				writeError(w, errors.ErrInvalidInput.WithMessage("failed to read request body"))
+				return
+			}
+
+			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			// Отклонённый запрос ничего не изменил
+			if status := ww.Status(); status >= http.StatusBadRequest {
+				return
+			}
+
+			entry := &domain.AuditLogEntry{
+				UserID:          userID,
+				Action:          auditAction(r),
+				IPAddress:       truncate(getClientIP(r), maxAuditIPLength),
+				UserAgent:       r.UserAgent(),
+				RequestBodyHash: bodyHash,
+				CreatedAt:       startedAt,
+			}
+			if adminID, ok := GetImpersonatedBy(r.Context()); ok {
+				entry.ImpersonatedBy = &adminID
+			}
+			entry.TargetType, entry.TargetID = auditTarget(chi.RouteContext(r.Context()))
+			entry.Params = auditParams(chi.RouteContext(r.Context()), r.URL.Query())
+
+			// Действие уже выполнено: запись не должна теряться из-за отключившегося клиента
+			if err := recorder.Record(context.WithoutCancel(r.Context()), entry); err != nil {
+				log.LogError("Failed to write audit log entry", err,
+					zap.Bool("audit", true),
+					zap.String("user_id", userID.String()),
+					zap.String("action", entry.Action),
+				)
+			}
+		})
+	}
+}
+
+// hashRequestBody возвращает SHA-256 тела запроса и восстанавливает тело для handler.
+// Для пустого тела возвращает nil
+func hashRequestBody(r *http.Request) (*string, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+	return &hash, nil
+}
+
+// auditAction действие - метод и шаблон маршрута, например "POST /api/v1/tournaments/{id}/start"
+func auditAction(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return r.Method + " " + pattern
+		}
+	}
+	return r.Method + " " + r.URL.Path
+}
+
+// auditTarget возвращает тип и ID объекта по первому параметру маршрута
+func auditTarget(rctx *chi.Context) (*string, *string) {
+	if rctx == nil {
+		return nil, nil
+	}
+
+	segments := strings.Split(strings.Trim(rctx.RoutePattern(), "/"), "/")
+	for i := 1; i < len(segments); i++ {
+		segment := segments[i]
+		if !strings.HasPrefix(segment, "{") {
+			continue
+		}
+
+		// {id} или {id:[0-9]+}
+		key := strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		key, _, _ = strings.Cut(key, ":")
+		targetID := rctx.URLParam(key)
+
+		targetType, ok := auditTargetTypes[segments[i-1]]
+		if !ok {
+			targetType = segments[i-1]
+		}
+		return &targetType, &targetID
+	}
+	return nil, nil
+}
+
+// auditParams собирает параметры маршрута и строки запроса в JSON. Без параметров - nil
+func auditParams(rctx *chi.Context, query url.Values) json.RawMessage {
+	params := make(map[string]string)
+	if rctx != nil {
+		for i, key := range rctx.URLParams.Keys {
+			// "*" - остаток пути у вложенных роутеров, не параметр
+			if key != "*" && i < len(rctx.URLParams.Values) {
+				params[key] = rctx.URLParams.Values[i]
+			}
+		}
+	}
+	for key, values := range query {
+		if len(values) > 0 {
+			params[key] = strings.Join(values, ",")
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+
+	// Маршалинг map[string]string не может завершиться ошибкой
+	data, _ := json.Marshal(params)
+	return data
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
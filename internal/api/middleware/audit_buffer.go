@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// auditWriteTimeout максимальное время записи одной записи журнала
+const auditWriteTimeout = 5 * time.Second
+
+// AuditBuffer асинхронно записывает журнал действий администраторов: запрос не ждёт INSERT.
+// При переполненном буфере или после Stop запись выполняется синхронно, поэтому записи не теряются
+type AuditBuffer struct {
+	recorder AuditRecorder
+	log      *logger.Logger
+	entries  chan *domain.AuditLogEntry
+	mu       sync.RWMutex
+	stopped  bool
+	doneCh   chan struct{}
+}
+
+// NewAuditBuffer создаёт буфер на size записей
+func NewAuditBuffer(recorder AuditRecorder, size int, log *logger.Logger) *AuditBuffer {
+	if size <= 0 {
+		size = 1
+	}
+	return &AuditBuffer{
+		recorder: recorder,
+		log:      log,
+		entries:  make(chan *domain.AuditLogEntry, size),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// Start запускает фоновую запись
+func (b *AuditBuffer) Start() {
+	go b.run()
+}
+
+// Stop прекращает приём записей и дожидается записи оставшихся в буфере
+func (b *AuditBuffer) Stop() {
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		return
+	}
+	b.stopped = true
+	close(b.entries)
+	b.mu.Unlock()
+
+	<-b.doneCh
+	b.log.Info("Audit log buffer flushed")
+}
+
+// Record ставит запись в буфер. Ошибку возвращает только синхронная запись
+func (b *AuditBuffer) Record(ctx context.Context, entry *domain.AuditLogEntry) error {
+	b.mu.RLock()
+	if !b.stopped {
+		select {
+		case b.entries <- entry:
+			b.mu.RUnlock()
+			return nil
+		default:
+		}
+	}
+	b.mu.RUnlock()
+
+	return b.recorder.Record(ctx, entry)
+}
+
+// run записывает записи из буфера, пока он не закрыт и не опустошён
+func (b *AuditBuffer) run() {
+	defer close(b.doneCh)
+
+	for entry := range b.entries {
+		ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+		if err := b.recorder.Record(ctx, entry); err != nil {
+			b.log.LogError("Failed to write audit log entry", err,
+				zap.Bool("audit", true),
+				zap.String("user_id", entry.UserID.String()),
+				zap.String("action", entry.Action),
+			)
+		}
+		cancel()
+	}
+}
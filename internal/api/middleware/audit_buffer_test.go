@@ -0,0 +1,69 @@
+package middleware_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingAuditRecorder holds writes until release is closed
+type blockingAuditRecorder struct {
+	mu      sync.Mutex
+	entries []*domain.AuditLogEntry
+	release chan struct{}
+}
+
+func (b *blockingAuditRecorder) Record(ctx context.Context, entry *domain.AuditLogEntry) error {
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries = append(b.entries, entry)
+	return nil
+}
+
+func (b *blockingAuditRecorder) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+func TestAuditBuffer_StopFlushesEntries(t *testing.T) {
+	recorder := &blockingAuditRecorder{release: make(chan struct{})}
+	buffer := middleware.NewAuditBuffer(recorder, 10, newTestLogger())
+	buffer.Start()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, buffer.Record(context.Background(), &domain.AuditLogEntry{UserID: uuid.New(), Action: "POST /x"}))
+	}
+	// Record returns before the entry reaches the database
+	assert.Equal(t, 0, recorder.count())
+
+	close(recorder.release)
+	buffer.Stop()
+	assert.Equal(t, 5, recorder.count())
+
+	// After Stop entries are written synchronously
+	require.NoError(t, buffer.Record(context.Background(), &domain.AuditLogEntry{UserID: uuid.New(), Action: "POST /y"}))
+	assert.Equal(t, 6, recorder.count())
+}
+
+func TestAuditBuffer_FullBufferWritesSynchronously(t *testing.T) {
+	recorder := &blockingAuditRecorder{release: make(chan struct{})}
+	// Not started: nothing drains the buffer
+	buffer := middleware.NewAuditBuffer(recorder, 1, newTestLogger())
+	require.NoError(t, buffer.Record(context.Background(), &domain.AuditLogEntry{UserID: uuid.New()}))
+
+	close(recorder.release)
+	require.NoError(t, buffer.Record(context.Background(), &domain.AuditLogEntry{UserID: uuid.New()}))
+	assert.Equal(t, 1, recorder.count())
+
+	buffer.Start()
+	buffer.Stop()
+	assert.Equal(t, 2, recorder.count())
+}
@@ -0,0 +1,141 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryAuditRecorder struct {
+	entries []*domain.AuditLogEntry
+}
+
+func (m *memoryAuditRecorder) Record(ctx context.Context, entry *domain.AuditLogEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// newAuditRouter mounts the audit middleware after a fake auth step setting the given role
+func newAuditRouter(recorder middleware.AuditRecorder, userID uuid.UUID, role domain.Role, status int) (*chi.Mux, *string) {
+	var received string
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.UserIDKey, userID)
+			next.ServeHTTP(w, r.WithContext(middleware.WithRole(ctx, role)))
+		})
+	})
+	r.Use(middleware.AuditLogger(recorder, newTestLogger()))
+	r.Post("/api/v1/tournaments/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(status)
+	})
+	r.Post("/api/v1/tournaments/{id}/games/{gameId}/complete-round", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+	r.Get("/api/v1/admin/audit-log", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return r, &received
+}
+
+func serveAudit(router http.Handler, method, path, body string) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("User-Agent", "audit-test")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestAuditLogger_RecordsAdminAction(t *testing.T) {
+	recorder := &memoryAuditRecorder{}
+	adminID := uuid.New()
+	tournamentID := uuid.New()
+	router, received := newAuditRouter(recorder, adminID, domain.RoleAdmin, http.StatusOK)
+
+	serveAudit(router, http.MethodPost, "/api/v1/tournaments/"+tournamentID.String()+"/start?notify=false", `{"force":true}`)
+
+	// The handler still receives the original body
+	assert.Equal(t, `{"force":true}`, *received)
+	require.Len(t, recorder.entries, 1)
+	entry := recorder.entries[0]
+	assert.Equal(t, adminID, entry.UserID)
+	assert.Equal(t, "POST /api/v1/tournaments/{id}/start", entry.Action)
+	assert.Equal(t, "tournament", *entry.TargetType)
+	assert.Equal(t, tournamentID.String(), *entry.TargetID)
+	assert.Equal(t, "audit-test", entry.UserAgent)
+	assert.NotEmpty(t, entry.IPAddress)
+	require.NotNil(t, entry.RequestBodyHash)
+	assert.Len(t, *entry.RequestBodyHash, 64)
+	assert.NotContains(t, *entry.RequestBodyHash, "force")
+	assert.JSONEq(t, `{"id":"`+tournamentID.String()+`","notify":"false"}`, string(entry.Params))
+	assert.False(t, entry.CreatedAt.IsZero())
+}
+
+func TestAuditLogger_NestedResourceTargetsTournament(t *testing.T) {
+	recorder := &memoryAuditRecorder{}
+	tournamentID := uuid.New()
+	router, _ := newAuditRouter(recorder, uuid.New(), domain.RoleAdmin, http.StatusOK)
+
+	serveAudit(router, http.MethodPost, "/api/v1/tournaments/"+tournamentID.String()+"/games/"+uuid.New().String()+"/complete-round", "")
+
+	require.Len(t, recorder.entries, 1)
+	entry := recorder.entries[0]
+	assert.Equal(t, "POST /api/v1/tournaments/{id}/games/{gameId}/complete-round", entry.Action)
+	assert.Equal(t, "tournament", *entry.TargetType)
+	assert.Equal(t, tournamentID.String(), *entry.TargetID)
+	assert.Nil(t, entry.RequestBodyHash)
+}
+
+func TestAuditLogger_BodyHashIsConsistent(t *testing.T) {
+	recorder := &memoryAuditRecorder{}
+	router, _ := newAuditRouter(recorder, uuid.New(), domain.RoleAdmin, http.StatusOK)
+	path := "/api/v1/tournaments/" + uuid.New().String() + "/start"
+
+	serveAudit(router, http.MethodPost, path, `{"force":true}`)
+	serveAudit(router, http.MethodPost, path, `{"force":true}`)
+	serveAudit(router, http.MethodPost, path, `{"force":false}`)
+
+	require.Len(t, recorder.entries, 3)
+	assert.Equal(t, *recorder.entries[0].RequestBodyHash, *recorder.entries[1].RequestBodyHash)
+	assert.NotEqual(t, *recorder.entries[0].RequestBodyHash, *recorder.entries[2].RequestBodyHash)
+}
+
+func TestAuditLogger_SkipsNonAdminRequests(t *testing.T) {
+	recorder := &memoryAuditRecorder{}
+	router, received := newAuditRouter(recorder, uuid.New(), domain.RoleUser, http.StatusOK)
+
+	serveAudit(router, http.MethodPost, "/api/v1/tournaments/"+uuid.New().String()+"/start", `{}`)
+
+	assert.Equal(t, `{}`, *received)
+	assert.Empty(t, recorder.entries)
+}
+
+func TestAuditLogger_SkipsReadsAndFailures(t *testing.T) {
+	t.Run("safe method", func(t *testing.T) {
+		recorder := &memoryAuditRecorder{}
+		router, _ := newAuditRouter(recorder, uuid.New(), domain.RoleAdmin, http.StatusOK)
+
+		serveAudit(router, http.MethodGet, "/api/v1/admin/audit-log", "")
+
+		assert.Empty(t, recorder.entries)
+	})
+
+	t.Run("rejected request", func(t *testing.T) {
+		recorder := &memoryAuditRecorder{}
+		router, _ := newAuditRouter(recorder, uuid.New(), domain.RoleAdmin, http.StatusConflict)
+
+		serveAudit(router, http.MethodPost, "/api/v1/tournaments/"+uuid.New().String()+"/start", `{}`)
+
+		assert.Empty(t, recorder.entries)
+	})
+}
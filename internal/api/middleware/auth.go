@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strings"
 
@@ -23,20 +24,40 @@ const (
 	UserKey ContextKey = "user"
 	// RoleKey ключ для роли в контексте
 	RoleKey ContextKey = "user_role"
+	// ImpersonatedByKey ключ для ID админа, действующего от имени пользователя
+	ImpersonatedByKey ContextKey = "impersonated_by"
+	// APIKeyScopeKey ключ для scope API ключа, если запрос выполнен по ключу
+	APIKeyScopeKey ContextKey = "api_key_scope"
 )
 
+// apiKeyScheme схема заголовка Authorization для API ключей: "ApiKey <key>"
+const apiKeyScheme = "ApiKey"
+
 // AuthService интерфейс для работы с аутентификацией
 type AuthService interface {
 	ValidateToken(tokenString string) (*auth.Claims, error)
 	GetUserByToken(ctx context.Context, tokenString string) (*domain.User, error)
 	GetUserFromToken(ctx context.Context, tokenString string) (*domain.User, error)
 	IsTokenBlacklisted(ctx context.Context, token string) (bool, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (*domain.APIKeyIdentity, error)
 }
 
-// Auth middleware для проверки JWT токена
+// Auth middleware для проверки JWT токена или API ключа
 func Auth(authService AuthService, log *logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// API ключи не отзываются через logout, поэтому чёрный список к ним не применяется
+			if key, ok := extractAPIKey(r); ok {
+				ctx, err := apiKeyContext(r, authService, key)
+				if err != nil {
+					log.Info("API key rejected", zap.Error(err))
+					writeError(w, err)
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			var token string
 
 			// Сначала проверяем заголовок Authorization
@@ -92,6 +113,7 @@ func Auth(authService AuthService, log *logger.Logger) func(http.Handler) http.H
 			// Добавляем user ID и роль в контекст
 			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 			ctx = context.WithValue(ctx, RoleKey, user.Role)
+			ctx = withImpersonation(ctx, r, claims, log)
 
 			// Передаём управление следующему обработчику
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -104,6 +126,16 @@ func Auth(authService AuthService, log *logger.Logger) func(http.Handler) http.H
 func OptionalAuth(authService AuthService, log *logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key, ok := extractAPIKey(r); ok {
+				ctx, err := apiKeyContext(r, authService, key)
+				if err != nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
 				next.ServeHTTP(w, r)
@@ -143,11 +175,63 @@ func OptionalAuth(authService AuthService, log *logger.Logger) func(http.Handler
 			// Добавляем user ID и роль в контекст
 			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 			ctx = context.WithValue(ctx, RoleKey, user.Role)
+			ctx = withImpersonation(ctx, r, claims, log)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// apiKeyContext проверяет API ключ и добавляет его пользователя в контекст.
+// Ключ со scope read допускает только безопасные методы
+func apiKeyContext(r *http.Request, authService AuthService, key string) (context.Context, error) {
+	identity, err := authService.AuthenticateAPIKey(r.Context(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity.Scope != domain.APIKeyScopeReadWrite && !isSafeMethod(r.Method) {
+		return nil, errors.ErrForbidden.WithMessage("api key is read-only")
+	}
+
+	ctx := context.WithValue(r.Context(), UserIDKey, identity.UserID)
+	ctx = context.WithValue(ctx, RoleKey, identity.Role)
+	ctx = context.WithValue(ctx, APIKeyScopeKey, identity.Scope)
+	return ctx, nil
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// GetAPIKeyScope возвращает scope API ключа, если запрос выполнен по ключу, а не по JWT
+func GetAPIKeyScope(ctx context.Context) (domain.APIKeyScope, bool) {
+	scope, ok := ctx.Value(APIKeyScopeKey).(domain.APIKeyScope)
+	return scope, ok
+}
+
+// withImpersonation добавляет в контекст ID админа, если запрос выполняется
+// от имени пользователя, и пишет audit-запись о каждом таком запросе
+func withImpersonation(ctx context.Context, r *http.Request, claims *auth.Claims, log *logger.Logger) context.Context {
+	if !claims.IsImpersonation() {
+		return ctx
+	}
+
+	log.Info("Impersonated request",
+		zap.Bool("audit", true),
+		zap.String("user_id", claims.UserID.String()),
+		zap.String("impersonated_by", claims.ImpersonatedBy.String()),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+	)
+	return context.WithValue(ctx, ImpersonatedByKey, *claims.ImpersonatedBy)
+}
+
+// GetImpersonatedBy возвращает ID админа, если запрос выполняется от имени пользователя
+func GetImpersonatedBy(ctx context.Context) (uuid.UUID, bool) {
+	adminID, ok := ctx.Value(ImpersonatedByKey).(uuid.UUID)
+	return adminID, ok
+}
+
 // GetUserID извлекает user ID из контекста
 func GetUserID(ctx context.Context) (uuid.UUID, bool) {
 	userID, ok := ctx.Value(UserIDKey).(uuid.UUID)
@@ -178,10 +262,19 @@ func extractToken(r *http.Request) string {
 	return parts[1]
 }
 
-// writeError пишет ошибку в ответ
+// extractAPIKey извлекает API ключ из заголовка "Authorization: ApiKey <key>"
+func extractAPIKey(r *http.Request) (string, bool) {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != apiKeyScheme || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// writeError пишет ошибку в ответ: {"error": {"code": "not_found", "message": "..."}}
 func writeError(w http.ResponseWriter, err error) {
 	appErr := errors.ToAppError(err)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(appErr.Code)
-	_, _ = w.Write([]byte(`{"error":"` + appErr.Message + `"}`))
+	_ = json.NewEncoder(w).Encode(appErr.ToResponse())
 }
@@ -14,6 +14,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // MockAuthService implements middleware.AuthService for testing
@@ -50,6 +52,14 @@ func (m *MockAuthService) IsTokenBlacklisted(ctx context.Context, token string)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockAuthService) AuthenticateAPIKey(ctx context.Context, key string) (*domain.APIKeyIdentity, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKeyIdentity), args.Error(1)
+}
+
 func newTestLogger() *logger.Logger {
 	log, _ := logger.New("error", "json")
 	return log
@@ -319,3 +329,128 @@ func TestRequireUserID(t *testing.T) {
 	_, err = middleware.RequireUserID(emptyCtx)
 	assert.Error(t, err)
 }
+
+func TestAuth_ImpersonationIsAudited(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	core, logs := observer.New(zap.InfoLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+
+	userID, adminID := uuid.New(), uuid.New()
+	claims := &auth.Claims{UserID: userID, ImpersonatedBy: &adminID}
+	user := &domain.User{ID: userID, Role: domain.RoleUser}
+
+	mockAuth.On("ValidateToken", "impersonation-token").Return(claims, nil)
+	mockAuth.On("IsTokenBlacklisted", mock.Anything, "impersonation-token").Return(false, nil)
+	mockAuth.On("GetUserFromToken", mock.Anything, "impersonation-token").Return(user, nil)
+
+	var capturedUserID, capturedAdminID uuid.UUID
+	handler := middleware.Auth(mockAuth, log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedUserID, _ = middleware.GetUserID(r.Context())
+		capturedAdminID, _ = middleware.GetImpersonatedBy(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/programs", nil)
+	req.Header.Set("Authorization", "Bearer impersonation-token")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, userID, capturedUserID)
+	assert.Equal(t, adminID, capturedAdminID)
+
+	entries := logs.FilterMessage("Impersonated request").All()
+	if assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, true, fields["audit"])
+		assert.Equal(t, adminID.String(), fields["impersonated_by"])
+		assert.Equal(t, userID.String(), fields["user_id"])
+		assert.Equal(t, "/api/v1/programs", fields["path"])
+	}
+}
+
+func TestAuth_RegularTokenIsNotImpersonation(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	userID := uuid.New()
+
+	mockAuth.On("ValidateToken", "valid-token").Return(&auth.Claims{UserID: userID}, nil)
+	mockAuth.On("IsTokenBlacklisted", mock.Anything, "valid-token").Return(false, nil)
+	mockAuth.On("GetUserFromToken", mock.Anything, "valid-token").Return(&domain.User{ID: userID, Role: domain.RoleUser}, nil)
+
+	impersonated := true
+	handler := middleware.Auth(mockAuth, newTestLogger())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, impersonated = middleware.GetImpersonatedBy(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.False(t, impersonated)
+}
+
+func TestAuth_APIKey(t *testing.T) {
+	userID := uuid.New()
+	readKey := &domain.APIKeyIdentity{KeyID: uuid.New(), UserID: userID, Role: domain.RoleUser, Scope: domain.APIKeyScopeRead}
+	writeKey := &domain.APIKeyIdentity{KeyID: uuid.New(), UserID: userID, Role: domain.RoleUser, Scope: domain.APIKeyScopeReadWrite}
+
+	tests := []struct {
+		name       string
+		method     string
+		identity   *domain.APIKeyIdentity
+		wantStatus int
+	}{
+		{"read key allows GET", http.MethodGet, readKey, http.StatusOK},
+		{"read key rejects POST", http.MethodPost, readKey, http.StatusForbidden},
+		{"read key rejects DELETE", http.MethodDelete, readKey, http.StatusForbidden},
+		{"read_write key allows POST", http.MethodPost, writeKey, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuth := new(MockAuthService)
+			mockAuth.On("AuthenticateAPIKey", mock.Anything, "tjk_key").Return(tt.identity, nil)
+
+			var capturedUserID uuid.UUID
+			var capturedScope domain.APIKeyScope
+			handler := middleware.Auth(mockAuth, newTestLogger())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedUserID, _ = middleware.GetUserID(r.Context())
+				capturedScope, _ = middleware.GetAPIKeyScope(r.Context())
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			req.Header.Set("Authorization", "ApiKey tjk_key")
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, userID, capturedUserID)
+				assert.Equal(t, tt.identity.Scope, capturedScope)
+			}
+			// API keys bypass JWT validation and the token blacklist
+			mockAuth.AssertNotCalled(t, "ValidateToken", mock.Anything)
+			mockAuth.AssertNotCalled(t, "IsTokenBlacklisted", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuth_InvalidAPIKey(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	mockAuth.On("AuthenticateAPIKey", mock.Anything, "tjk_revoked").Return(nil, errors.ErrInvalidToken)
+
+	handler := middleware.Auth(mockAuth, newTestLogger())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Handler should not be called")
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "ApiKey tjk_revoked")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
@@ -1,13 +1,22 @@
 package middleware
 
 import (
+	"bufio"
 	"compress/gzip"
+	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 )
 
+// minCompressSize ответы меньше одного TCP сегмента не сжимаются:
+// выигрыш в размере не окупает заголовок gzip и CPU
+const minCompressSize = 1400
+
 // gzipWriterPool пул gzip writers для переиспользования
 var gzipWriterPool = sync.Pool{
 	New: func() interface{} {
@@ -15,18 +24,65 @@ var gzipWriterPool = sync.Pool{
 	},
 }
 
-// gzipResponseWriter обёртка над http.ResponseWriter с gzip сжатием
+// compressibleTypes типы содержимого, которые имеет смысл сжимать.
+// Файлы программ (application/octet-stream) отдаются как есть
+var compressibleTypes = map[string]bool{
+	"application/json":       true,
+	"application/javascript": true,
+	"application/xml":        true,
+	"image/svg+xml":          true,
+}
+
+// isCompressible проверяет, стоит ли сжимать ответ с данным Content-Type
+func isCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		compressibleTypes[mediaType]
+}
+
+// acceptsGzip проверяет Accept-Encoding клиента с учётом gzip;q=0
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			return err == nil && q > 0
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter буферизует начало ответа и решает, сжимать ли его,
+// когда набрано minCompressSize байт или обработчик завершился
 type gzipResponseWriter struct {
-	io.Writer
 	http.ResponseWriter
+	pool *sync.Pool
+	gz   *gzip.Writer
+
+	buf         []byte
+	status      int
 	wroteHeader bool
+	started     bool
 }
 
 func (w *gzipResponseWriter) WriteHeader(status int) {
-	if !w.wroteHeader {
-		w.ResponseWriter.Header().Del("Content-Length")
-		w.ResponseWriter.WriteHeader(status)
-		w.wroteHeader = true
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+
+	// У этих ответов нет тела
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		_ = w.start(false)
 	}
 }
 
@@ -34,75 +90,144 @@ func (w *gzipResponseWriter) Write(b []byte) (int, error) {
 	if !w.wroteHeader {
 		w.WriteHeader(http.StatusOK)
 	}
-	return w.Writer.Write(b)
+
+	if w.started {
+		if w.gz != nil {
+			return w.gz.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= minCompressSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
 }
 
-// Compress middleware для gzip сжатия ответов
-func Compress() func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Проверяем, поддерживает ли клиент gzip
-			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-				next.ServeHTTP(w, r)
-				return
-			}
+// start отправляет заголовки и накопленный буфер, сжимая его при необходимости
+func (w *gzipResponseWriter) start(compress bool) error {
+	w.started = true
 
-			// Получаем gzip writer из пула
-			gz := gzipWriterPool.Get().(*gzip.Writer)
-			defer gzipWriterPool.Put(gz)
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		// Определяем тип по несжатым данным, иначе net/http определит его по gzip
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
 
-			gz.Reset(w)
-			defer gz.Close()
+	if compress && h.Get("Content-Encoding") == "" && isCompressible(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
 
-			// Устанавливаем заголовки для gzip
-			w.Header().Set("Content-Encoding", "gzip")
-			w.Header().Set("Vary", "Accept-Encoding")
+		w.gz = w.pool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
 
-			// Оборачиваем ResponseWriter
-			gzw := &gzipResponseWriter{
-				Writer:         gz,
-				ResponseWriter: w,
-			}
+	w.ResponseWriter.WriteHeader(w.status)
 
-			next.ServeHTTP(gzw, r)
-		})
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	if w.gz != nil {
+		_, err := w.gz.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// close завершает ответ: маленький ответ уходит несжатым
+func (w *gzipResponseWriter) close() {
+	if !w.started && w.wroteHeader {
+		_ = w.start(false)
+	}
+	if w.gz != nil {
+		_ = w.gz.Close()
+		w.pool.Put(w.gz)
+		w.gz = nil
 	}
 }
 
-// CompressWithLevel middleware для gzip сжатия с указанным уровнем
+// Flush нужен для потоковых ответов (экспорт результатов):
+// явный сброс означает, что тело будет большим, поэтому оно сжимается
+func (w *gzipResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.started {
+		_ = w.start(true)
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack пробрасывается для WebSocket соединений
+func (w *gzipResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap позволяет http.ResponseController добраться до исходного writer
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// CompressionMiddleware сжимает ответы gzip, если клиент это поддерживает.
+// Ответы меньше minCompressSize, уже сжатые и бинарные (скачивание файлов) не сжимаются
+func CompressionMiddleware() func(http.Handler) http.Handler {
+	return compression(&gzipWriterPool)
+}
+
+// CompressWithLevel то же, что CompressionMiddleware, но с указанным уровнем сжатия
 func CompressWithLevel(level int) func(http.Handler) http.Handler {
 	// Создаём отдельный пул для конкретного уровня сжатия
 	writerPool := &sync.Pool{
 		New: func() interface{} {
-			w, _ := gzip.NewWriterLevel(io.Discard, level)
+			w, err := gzip.NewWriterLevel(io.Discard, level)
+			if err != nil {
+				return gzip.NewWriter(io.Discard)
+			}
 			return w
 		},
 	}
 
+	return compression(writerPool)
+}
+
+func compression(pool *sync.Pool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Проверяем, поддерживает ли клиент gzip
-			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			// WebSocket upgrade не сжимаем
+			if r.Header.Get("Upgrade") != "" {
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			// Получаем gzip writer из пула
-			gz := writerPool.Get().(*gzip.Writer)
-			defer writerPool.Put(gz)
+			w.Header().Add("Vary", "Accept-Encoding")
 
-			gz.Reset(w)
-			defer gz.Close()
-
-			// Устанавливаем заголовки для gzip
-			w.Header().Set("Content-Encoding", "gzip")
-			w.Header().Set("Vary", "Accept-Encoding")
+			// Проверяем, поддерживает ли клиент gzip
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
 
-			// Оборачиваем ResponseWriter
 			gzw := &gzipResponseWriter{
-				Writer:         gz,
 				ResponseWriter: w,
+				pool:           pool,
+				status:         http.StatusOK,
 			}
+			defer gzw.close()
 
 			next.ServeHTTP(gzw, r)
 		})
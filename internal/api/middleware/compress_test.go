@@ -0,0 +1,109 @@
+package middleware_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveCompressed(t *testing.T, acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	middleware.CompressionMiddleware()(handler).ServeHTTP(rec, req)
+	return rec
+}
+
+func jsonHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	largeBody := `{"items":"` + strings.Repeat("leaderboard ", 500) + `"}`
+
+	t.Run("compresses large JSON response", func(t *testing.T) {
+		rec := serveCompressed(t, "gzip, deflate, br", jsonHandler(largeBody))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		assert.Less(t, rec.Body.Len(), len(largeBody))
+
+		gz, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, largeBody, string(decoded))
+	})
+
+	t.Run("skips small response", func(t *testing.T) {
+		rec := serveCompressed(t, "gzip", jsonHandler(`{"ok":true}`))
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `{"ok":true}`, rec.Body.String())
+	})
+
+	t.Run("skips client without gzip", func(t *testing.T) {
+		for _, acceptEncoding := range []string{"", "br", "gzip;q=0"} {
+			rec := serveCompressed(t, acceptEncoding, jsonHandler(largeBody))
+
+			assert.Empty(t, rec.Header().Get("Content-Encoding"), acceptEncoding)
+			assert.Equal(t, largeBody, rec.Body.String(), acceptEncoding)
+		}
+	})
+
+	t.Run("keeps file downloads as is", func(t *testing.T) {
+		content := strings.Repeat("\x7fELF binary", 500)
+		rec := serveCompressed(t, "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Disposition", `attachment; filename="bot"`)
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = io.WriteString(w, content)
+		})
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, content, rec.Body.String())
+	})
+
+	t.Run("compresses flushed stream", func(t *testing.T) {
+		rec := serveCompressed(t, "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			_, _ = io.WriteString(w, "rank,team\n")
+			w.(http.Flusher).Flush()
+			_, _ = io.WriteString(w, "1,alpha\n")
+		})
+
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.True(t, rec.Flushed)
+
+		gz, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, "rank,team\n1,alpha\n", string(decoded))
+	})
+
+	t.Run("no body for 204", func(t *testing.T) {
+		rec := serveCompressed(t, "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Zero(t, rec.Body.Len())
+	})
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/cors"
+)
+
+// CORS применяет политику CORS. Preflight-запрос, не прошедший проверку
+// (чужой источник, метод или заголовки), получает 403 вместо пустого 200,
+// чтобы ошибка конфигурации фронтенда была видна сразу
+func CORS(options cors.Options) func(http.Handler) http.Handler {
+	options.OptionsPassthrough = true
+	c := cors.New(options)
+
+	return func(next http.Handler) http.Handler {
+		return c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isPreflight(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// cors выставляет Allow-Origin только для разрешённого preflight
+			if w.Header().Get("Access-Control-Allow-Origin") == "" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+}
+
+// isPreflight проверяет, что запрос - CORS preflight
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// OriginMatcher проверяет источник по списку разрешённых с той же семантикой, что и CORS:
+// "*" - любой, точный scheme://host[:port] или поддомены scheme://*.host[:port].
+// Нужен там, где браузер не делает preflight, например при WebSocket upgrade
+type OriginMatcher struct {
+	any       bool
+	exact     map[string]struct{}
+	wildcards []originWildcard
+}
+
+// originWildcard источник вида prefix*suffix
+type originWildcard struct {
+	prefix string
+	suffix string
+}
+
+// NewOriginMatcher создаёт проверку источников. Пустой список не разрешает ни одного
+func NewOriginMatcher(origins []string) *OriginMatcher {
+	m := &OriginMatcher{exact: make(map[string]struct{})}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		if origin == "*" {
+			m.any = true
+			continue
+		}
+		if prefix, suffix, ok := strings.Cut(origin, "*"); ok {
+			m.wildcards = append(m.wildcards, originWildcard{prefix: prefix, suffix: suffix})
+			continue
+		}
+		m.exact[origin] = struct{}{}
+	}
+	return m
+}
+
+// Allow проверяет, что источник разрешён
+func (m *OriginMatcher) Allow(origin string) bool {
+	if m.any {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if _, ok := m.exact[origin]; ok {
+		return true
+	}
+	for _, w := range m.wildcards {
+		if w.match(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// match проверяет, что вместо * стоит непустое имя поддомена, а не путь, порт или учётные данные
+func (w originWildcard) match(origin string) bool {
+	if len(origin) <= len(w.prefix)+len(w.suffix) ||
+		!strings.HasPrefix(origin, w.prefix) || !strings.HasSuffix(origin, w.suffix) {
+		return false
+	}
+	label := origin[len(w.prefix) : len(origin)-len(w.suffix)]
+	return !strings.ContainsAny(label, "/:@?#") && !strings.HasPrefix(label, ".")
+}
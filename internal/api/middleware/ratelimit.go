@@ -3,10 +3,12 @@ package middleware
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
 	"go.uber.org/zap"
@@ -15,6 +17,47 @@ import (
 // RateLimiter интерфейс для rate limiting
 type RateLimiter interface {
 	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+	GetStatus(ctx context.Context, key string) (*cache.RateLimitStatus, error)
+}
+
+// RateLimitKey возвращает ключ rate limiting для клиента запроса
+func RateLimitKey(r *http.Request) string {
+	return fmt.Sprintf("ratelimit:%s", getClientIP(r))
+}
+
+// RateLimitMiddleware добавляет заголовки X-RateLimit-* с состоянием окна клиента
+// Заголовки пишутся до вызова handler, поэтому присутствуют в любом ответе
+func RateLimitMiddleware(limiter RateLimiter, limit int, window time.Duration, log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status, err := limiter.GetStatus(r.Context(), RateLimitKey(r))
+			if err != nil {
+				log.LogError("Rate limit status check failed", err,
+					zap.String("ip", getClientIP(r)),
+				)
+			}
+
+			// Окно ещё не открыто - доступен весь лимит
+			if status == nil {
+				status = &cache.RateLimitStatus{
+					Limit:     limit,
+					Remaining: limit,
+					ResetAt:   time.Now().Add(window),
+				}
+			}
+
+			writeRateLimitHeaders(w, status)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeRateLimitHeaders записывает состояние окна в заголовки ответа
+// X-RateLimit-Reset - unix время (в секундах) окончания окна
+func writeRateLimitHeaders(w http.ResponseWriter, status *cache.RateLimitStatus) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(status.ResetAt.Unix(), 10))
 }
 
 // RateLimit middleware для ограничения количества запросов
@@ -30,7 +73,7 @@ func RateLimit(limiter RateLimiter, limit int, window time.Duration, log *logger
 				return
 			}
 
-			key := fmt.Sprintf("ratelimit:%s", ip)
+			key := RateLimitKey(r)
 
 			// Проверяем лимит
 			allowed, err := limiter.Allow(r.Context(), key, limit, window)
@@ -49,9 +92,19 @@ func RateLimit(limiter RateLimiter, limit int, window time.Duration, log *logger
 					zap.String("path", r.URL.Path),
 				)
 
-				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+				retryAfter := window
+				status, err := limiter.GetStatus(r.Context(), key)
+				if err == nil && status != nil {
+					writeRateLimitHeaders(w, status)
+					if until := time.Until(status.ResetAt); until > 0 {
+						retryAfter = until
+					}
+				} else {
+					w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+					w.Header().Set("X-RateLimit-Remaining", "0")
+				}
 				w.Header().Set("X-RateLimit-Window", window.String())
-				w.Header().Set("Retry-After", strconv.Itoa(int(window.Seconds())))
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
 
 				writeError(w, errors.ErrRateLimitExceeded)
 				return
@@ -4,12 +4,15 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockRateLimiter implements middleware.RateLimiter for testing
@@ -22,6 +25,45 @@ func (m *MockRateLimiter) Allow(ctx context.Context, key string, limit int, wind
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockRateLimiter) GetStatus(ctx context.Context, key string) (*cache.RateLimitStatus, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cache.RateLimitStatus), args.Error(1)
+}
+
+// countingLimiter is an in-memory fixed window limiter for header tests
+type countingLimiter struct {
+	counts  map[string]int
+	resetAt map[string]time.Time
+	limit   int
+}
+
+func newCountingLimiter() *countingLimiter {
+	return &countingLimiter{counts: make(map[string]int), resetAt: make(map[string]time.Time)}
+}
+
+func (l *countingLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, error) {
+	l.limit = limit
+	if _, ok := l.resetAt[key]; !ok {
+		l.resetAt[key] = time.Now().Add(window)
+	}
+	if l.counts[key] >= limit {
+		return false, nil
+	}
+	l.counts[key]++
+	return true, nil
+}
+
+func (l *countingLimiter) GetStatus(_ context.Context, key string) (*cache.RateLimitStatus, error) {
+	resetAt, ok := l.resetAt[key]
+	if !ok {
+		return nil, nil
+	}
+	return &cache.RateLimitStatus{Limit: l.limit, Remaining: l.limit - l.counts[key], ResetAt: resetAt}, nil
+}
+
 func TestRateLimit_AllowedRequest(t *testing.T) {
 	mockLimiter := new(MockRateLimiter)
 	log := newTestLogger()
@@ -47,6 +89,7 @@ func TestRateLimit_ExceededLimit(t *testing.T) {
 	log := newTestLogger()
 
 	mockLimiter.On("Allow", mock.Anything, "ratelimit:192.168.1.1:12345", 100, time.Minute).Return(false, nil)
+	mockLimiter.On("GetStatus", mock.Anything, "ratelimit:192.168.1.1:12345").Return(nil, nil)
 
 	handler := middleware.RateLimit(mockLimiter, 100, time.Minute, log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Error("Handler should not be called when rate limit exceeded")
@@ -60,6 +103,7 @@ func TestRateLimit_ExceededLimit(t *testing.T) {
 
 	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
 	assert.Equal(t, "100", rr.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
 	assert.Equal(t, "1m0s", rr.Header().Get("X-RateLimit-Window"))
 	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
 	mockLimiter.AssertExpectations(t)
@@ -198,3 +242,63 @@ func TestRateLimit_DifferentWindows(t *testing.T) {
 		})
 	}
 }
+
+func TestRateLimitMiddleware_Headers(t *testing.T) {
+	log := newTestLogger()
+	limiter := newCountingLimiter()
+	const limit = 3
+
+	handler := middleware.RateLimit(limiter, limit, time.Minute, log)(
+		middleware.RateLimitMiddleware(limiter, limit, time.Minute, log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})),
+	)
+
+	doRequest := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/tournaments", nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	start := time.Now()
+	for i := 1; i <= limit; i++ {
+		rr := doRequest()
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, strconv.Itoa(limit), rr.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(limit-i), rr.Header().Get("X-RateLimit-Remaining"))
+
+		reset, err := strconv.ParseInt(rr.Header().Get("X-RateLimit-Reset"), 10, 64)
+		require.NoError(t, err)
+		assert.InDelta(t, start.Add(time.Minute).Unix(), reset, 2)
+	}
+
+	rr := doRequest()
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, rr.Header().Get("X-RateLimit-Reset"))
+}
+
+func TestRateLimitMiddleware_NoWindowYet(t *testing.T) {
+	mockLimiter := new(MockRateLimiter)
+	log := newTestLogger()
+
+	mockLimiter.On("GetStatus", mock.Anything, "ratelimit:127.0.0.1:12345").Return(nil, nil)
+
+	handler := middleware.RateLimitMiddleware(mockLimiter, 100, time.Minute, log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Headers are written before the handler runs
+		assert.Equal(t, "100", w.Header().Get("X-RateLimit-Remaining"))
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "100", rr.Header().Get("X-RateLimit-Limit"))
+	assert.NotEmpty(t, rr.Header().Get("X-RateLimit-Reset"))
+}
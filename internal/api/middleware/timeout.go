@@ -67,15 +67,16 @@ func getTimeoutForRequest(r *http.Request, config TimeoutConfig) time.Duration {
 	path := r.URL.Path
 	method := r.Method
 
-	// WebSocket соединения
-	if strings.Contains(path, "/ws/") {
+	// WebSocket соединения и трансляции матчей (SSE)
+	if strings.Contains(path, "/ws/") || strings.HasSuffix(path, "/live") {
 		return config.WebSocket
 	}
 
 	// Тяжёлые операции
 	if strings.Contains(path, "/leaderboard") ||
 		strings.Contains(path, "/statistics") ||
-		strings.Contains(path, "/stats") {
+		strings.Contains(path, "/stats") ||
+		strings.Contains(path, "/export") {
 		return config.Heavy
 	}
 
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing создаёт корневой спан для каждого HTTP запроса
+// Входящий traceparent продолжает трассировку клиента, имя спана - шаблон маршрута chi
+func Tracing() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Возвращаем traceparent клиенту для корреляции с логами сервера
+			otel.GetTextMapPropagator().Inject(r.Context(), propagation.HeaderCarrier(w.Header()))
+
+			next.ServeHTTP(w, r)
+
+			// Шаблон маршрута известен только после роутинга
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if pattern := rctx.RoutePattern(); pattern != "" {
+					trace.SpanFromContext(r.Context()).SetName(r.Method + " " + pattern)
+				}
+			}
+		})
+
+		return otelhttp.NewHandler(named, "http.server",
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return "HTTP " + r.Method
+			}),
+			// Не создаём спаны для health check и метрик
+			otelhttp.WithFilter(func(r *http.Request) bool {
+				return r.URL.Path != "/health" && r.URL.Path != "/metrics"
+			}),
+		)
+	}
+}
@@ -16,20 +16,24 @@ import (
 
 // Server представляет HTTP сервер
 type Server struct {
-	router            *chi.Mux
-	authHandler       *handlers.AuthHandler
-	tournamentHandler *handlers.TournamentHandler
-	programHandler    *handlers.ProgramHandler
-	matchHandler      *handlers.MatchHandler
-	gameHandler       *handlers.GameHandler
-	teamHandler       *handlers.TeamHandler
-	wsHandler         *handlers.WebSocketHandler
-	systemHandler     *handlers.SystemHandler
-	authService       middleware.AuthService
-	rateLimiter       middleware.RateLimiter
-	corsConfig        config.CORSConfig
-	rateLimitConfig   config.RateLimitConfig
-	log               *logger.Logger
+	router              *chi.Mux
+	authHandler         *handlers.AuthHandler
+	tournamentHandler   *handlers.TournamentHandler
+	programHandler      *handlers.ProgramHandler
+	matchHandler        *handlers.MatchHandler
+	gameHandler         *handlers.GameHandler
+	teamHandler         *handlers.TeamHandler
+	wsHandler           *handlers.WebSocketHandler
+	systemHandler       *handlers.SystemHandler
+	auditHandler        *handlers.AuditHandler
+	notificationHandler *handlers.NotificationHandler
+	webhookHandler      *handlers.WebhookHandler
+	auditRecorder       middleware.AuditRecorder
+	authService         middleware.AuthService
+	rateLimiter         middleware.RateLimiter
+	corsConfig          config.CORSConfig
+	rateLimitConfig     config.RateLimitConfig
+	log                 *logger.Logger
 }
 
 // NewServer создаёт новый HTTP сервер
@@ -42,6 +46,10 @@ func NewServer(
 	teamHandler *handlers.TeamHandler,
 	wsHandler *handlers.WebSocketHandler,
 	systemHandler *handlers.SystemHandler,
+	auditHandler *handlers.AuditHandler,
+	notificationHandler *handlers.NotificationHandler,
+	webhookHandler *handlers.WebhookHandler,
+	auditRecorder middleware.AuditRecorder,
 	authService middleware.AuthService,
 	rateLimiter middleware.RateLimiter,
 	corsConfig config.CORSConfig,
@@ -49,20 +57,24 @@ func NewServer(
 	log *logger.Logger,
 ) *Server {
 	s := &Server{
-		router:            chi.NewRouter(),
-		authHandler:       authHandler,
-		tournamentHandler: tournamentHandler,
-		programHandler:    programHandler,
-		matchHandler:      matchHandler,
-		gameHandler:       gameHandler,
-		teamHandler:       teamHandler,
-		wsHandler:         wsHandler,
-		systemHandler:     systemHandler,
-		authService:       authService,
-		rateLimiter:       rateLimiter,
-		corsConfig:        corsConfig,
-		rateLimitConfig:   rateLimitConfig,
-		log:               log,
+		router:              chi.NewRouter(),
+		authHandler:         authHandler,
+		tournamentHandler:   tournamentHandler,
+		programHandler:      programHandler,
+		matchHandler:        matchHandler,
+		gameHandler:         gameHandler,
+		teamHandler:         teamHandler,
+		wsHandler:           wsHandler,
+		systemHandler:       systemHandler,
+		auditHandler:        auditHandler,
+		notificationHandler: notificationHandler,
+		webhookHandler:      webhookHandler,
+		auditRecorder:       auditRecorder,
+		authService:         authService,
+		rateLimiter:         rateLimiter,
+		corsConfig:          corsConfig,
+		rateLimitConfig:     rateLimitConfig,
+		log:                 log,
 	}
 
 	s.setupMiddleware()
@@ -74,6 +86,7 @@ func NewServer(
 // setupMiddleware настраивает middleware
 func (s *Server) setupMiddleware() {
 	// Базовые middleware
+	s.router.Use(middleware.Tracing())
 	s.router.Use(chiMiddleware.RequestID)
 	s.router.Use(chiMiddleware.RealIP)
 	s.router.Use(chiMiddleware.Logger)
@@ -83,7 +96,7 @@ func (s *Server) setupMiddleware() {
 	s.router.Use(middleware.SecureHeaders())
 
 	// Response compression (gzip)
-	s.router.Use(middleware.Compress())
+	s.router.Use(middleware.CompressionMiddleware())
 
 	// Smart timeout с контекст cancellation для разных типов операций
 	s.router.Use(middleware.SmartTimeout(middleware.DefaultTimeoutConfig()))
@@ -96,16 +109,22 @@ func (s *Server) setupMiddleware() {
 			time.Minute,
 			s.log,
 		))
+		s.router.Use(middleware.RateLimitMiddleware(
+			s.rateLimiter,
+			s.rateLimitConfig.RequestsPerMinute,
+			time.Minute,
+			s.log,
+		))
 	}
 
 	// CORS с настройками из конфига
-	s.router.Use(cors.Handler(cors.Options{
+	s.router.Use(middleware.CORS(cors.Options{
 		AllowedOrigins:   s.corsConfig.AllowedOrigins,
 		AllowedMethods:   s.corsConfig.AllowedMethods,
 		AllowedHeaders:   s.corsConfig.AllowedHeaders,
-		ExposedHeaders:   []string{"Link", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
-		AllowCredentials: true,
-		MaxAge:           s.corsConfig.MaxAge,
+		ExposedHeaders:   s.corsConfig.ExposedHeaders,
+		AllowCredentials: s.corsConfig.AllowCredentials,
+		MaxAge:           s.corsConfig.MaxAgeSec,
 	}))
 }
 
@@ -119,6 +138,12 @@ func (s *Server) setupRoutes() {
 
 	// API v1
 	s.router.Route("/api/v1", func(r chi.Router) {
+		// Состояние лимита запросов клиента
+		if s.rateLimitConfig.Enabled {
+			rateLimitHandler := handlers.NewRateLimitHandler(s.rateLimiter, s.rateLimitConfig.RequestsPerMinute, time.Minute, s.log)
+			r.Get("/rate-limit", rateLimitHandler.GetStatus)
+		}
+
 		// Auth routes (публичные)
 		r.Route("/auth", func(r chi.Router) {
 			r.Post("/register", s.authHandler.Register)
@@ -126,32 +151,59 @@ func (s *Server) setupRoutes() {
 			r.Post("/refresh", s.authHandler.Refresh)
 			r.Post("/logout", s.authHandler.Logout)
 			r.Get("/me", s.authHandler.Me)
-			r.Put("/profile", s.authHandler.UpdateProfile)
+			r.With(middleware.Auth(s.authService, s.log)).Put("/profile", s.authHandler.UpdateProfile)
+			r.With(middleware.Auth(s.authService, s.log)).Post("/revoke-all", s.authHandler.RevokeAllSessions)
+
+			// API ключи для скриптов
+			r.Route("/api-keys", func(r chi.Router) {
+				r.Use(middleware.Auth(s.authService, s.log))
+				r.Post("/", s.authHandler.CreateAPIKey)
+				r.Get("/", s.authHandler.ListAPIKeys)
+				r.Delete("/{id}", s.authHandler.RevokeAPIKey)
+			})
 		})
 
 		// Tournament routes
 		r.Route("/tournaments", func(r chi.Router) {
 			// Публичные маршруты
-			r.Get("/", s.tournamentHandler.List)
-			r.Get("/{id}", s.tournamentHandler.Get)
-			r.Get("/{id}/leaderboard", s.tournamentHandler.GetLeaderboard)
-			r.Get("/{id}/cross-game-leaderboard", s.tournamentHandler.GetCrossGameLeaderboard)
-			r.Get("/{id}/matches", s.tournamentHandler.GetMatches)
-			r.Get("/{id}/matches/rounds", s.tournamentHandler.GetMatchesByRounds)
-			r.Get("/{id}/games", s.gameHandler.GetTournamentGames)
-			r.Get("/{id}/teams", s.teamHandler.GetTournamentTeams)
-
-			// Эндпоинты для конкретной игры в турнире
-			r.Get("/{id}/games/{gameId}/leaderboard", s.gameHandler.GetGameLeaderboard)
-			r.Get("/{id}/games/{gameId}/matches", s.gameHandler.GetGameMatches)
-			r.Get("/{id}/games/status", s.gameHandler.GetTournamentGamesWithStatus)
-			r.Get("/{id}/active-game", s.gameHandler.GetActiveGame)
+			// Токен необязателен: админам доступен include_deleted, а участникам команд,
+			// создателю и админам - приватные турниры
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.OptionalAuth(s.authService, s.log))
+				r.Get("/", s.tournamentHandler.List)
+				r.Get("/{id}", s.tournamentHandler.Get)
+				r.Get("/{id}/leaderboard", s.tournamentHandler.GetLeaderboard)
+				r.Get("/{id}/leaderboard/reference", s.tournamentHandler.GetReferenceLeaderboard)
+				r.Get("/{id}/cross-game-leaderboard", s.tournamentHandler.GetCrossGameLeaderboard)
+				r.Get("/{id}/team-leaderboard", s.tournamentHandler.GetTeamLeaderboard)
+				r.Get("/{id}/participants", s.tournamentHandler.ListParticipants)
+				r.Get("/{id}/matches", s.tournamentHandler.GetMatches)
+				r.Get("/{id}/matches/rounds", s.tournamentHandler.GetMatchesByRounds)
+				r.Get("/{id}/matches/rounds/{round}", s.tournamentHandler.GetRoundMatches)
+				r.Get("/{id}/bracket", s.tournamentHandler.GetBracket)
+				r.Get("/{id}/export", s.tournamentHandler.ExportResults)
+				r.Get("/{id}/head-to-head", s.tournamentHandler.HeadToHead)
+				r.Get("/{id}/games", s.gameHandler.GetTournamentGames)
+				r.Get("/{id}/teams", s.teamHandler.GetTournamentTeams)
+
+				// Эндпоинты для конкретной игры в турнире
+				r.Get("/{id}/games/{gameId}/leaderboard", s.gameHandler.GetGameLeaderboard)
+				r.Get("/{id}/games/{gameId}/matches", s.gameHandler.GetGameMatches)
+				r.Get("/{id}/games/{gameId}/progress", s.gameHandler.GetGameRoundProgress)
+				r.Get("/{id}/games/status", s.gameHandler.GetTournamentGamesWithStatus)
+				r.Get("/{id}/active-game", s.gameHandler.GetActiveGame)
+			})
 
 			// Защищённые маршруты
 			r.Group(func(r chi.Router) {
 				r.Use(middleware.Auth(s.authService, s.log))
+				// Изменения, сделанные администраторами, попадают в журнал действий
+				r.Use(s.audit())
 
 				r.Post("/", s.tournamentHandler.Create)
+				// Изменение настроек доступно админам или создателю турнира (проверка в handler)
+				r.Patch("/{id}", s.tournamentHandler.Update)
+				r.Post("/{id}/clone", s.tournamentHandler.Clone)
 				r.Post("/{id}/join", s.tournamentHandler.Join)
 				r.Post("/{id}/start", s.tournamentHandler.Start)
 				r.Post("/{id}/complete", s.tournamentHandler.Complete)
@@ -161,17 +213,42 @@ func (s *Server) setupRoutes() {
 				// Добавление игры доступно админам или создателю турнира (проверка в handler)
 				r.Post("/{id}/games", s.gameHandler.AddGameToTournament)
 
+				// Исключение и бан участников доступны админам или создателю турнира (проверка в handler)
+				r.Delete("/{id}/participants/{programID}", s.tournamentHandler.KickParticipant)
+				r.Post("/{id}/bans", s.tournamentHandler.BanProgram)
+
+				// Импорт лучших команд другого турнира доступен админам или создателю турнира (проверка в handler)
+				r.Post("/{id}/import-from", s.tournamentHandler.ImportParticipants)
+
+				// Пересоздание раунда игры доступно админам или создателю турнира (проверка в handler)
+				r.Post("/{id}/games/{gameId}/regenerate-round", s.tournamentHandler.RegenerateGameRound)
+
+				// Экспорт матчей доступен админам или создателю турнира (проверка в handler)
+				r.Get("/{id}/matches/export", s.tournamentHandler.ExportMatches)
+
+				// Webhook'и событий турнира доступны админам или создателю турнира (проверка в handler)
+				r.Post("/{id}/webhooks", s.webhookHandler.Create)
+				r.Get("/{id}/webhooks", s.webhookHandler.List)
+				r.Delete("/{id}/webhooks/{webhookId}", s.webhookHandler.Delete)
+				r.Get("/{id}/webhooks/{webhookId}/deliveries", s.webhookHandler.Deliveries)
+
 				// Админские маршруты для турниров
 				r.Group(func(r chi.Router) {
 					r.Use(middleware.RequireAdmin())
 					r.Delete("/{id}", s.tournamentHandler.Delete)
+					r.Post("/{id}/restore", s.tournamentHandler.Restore)
+					r.Delete("/{id}/purge", s.tournamentHandler.Purge)
 					r.Delete("/{id}/games/{gameId}", s.gameHandler.RemoveGameFromTournament)
 					r.Get("/{id}/games/{gameId}/programs", s.gameHandler.GetGamePrograms)
 					r.Post("/{id}/games/{gameId}/complete-round", s.gameHandler.MarkGameRoundCompleted)
 					r.Post("/{id}/games/{gameId}/reset-round", s.gameHandler.ResetGameRound)
+					r.Put("/{id}/games/{gameId}/window", s.gameHandler.SetGameWindow)
 					r.Post("/{id}/active-game", s.gameHandler.SetActiveGame)
 					r.Post("/{id}/games/deactivate-all", s.gameHandler.DeactivateAllGames)
+					r.Post("/{id}/force-complete", s.tournamentHandler.ForceComplete)
+					r.Post("/{id}/cancel", s.tournamentHandler.Cancel)
 					r.Post("/{id}/run-matches", s.tournamentHandler.RunAllMatches)
+					r.Post("/{id}/schedule-round", s.tournamentHandler.ScheduleRound)
 					r.Post("/{id}/run-game-matches", s.tournamentHandler.RunGameMatches)
 					r.Post("/{id}/retry-matches", s.tournamentHandler.RetryFailedMatches)
 					r.Post("/{id}/programs/clear-errors", s.programHandler.ClearProgramErrors)
@@ -185,21 +262,38 @@ func (s *Server) setupRoutes() {
 			r.Get("/", s.gameHandler.List)
 			r.Get("/{id}", s.gameHandler.Get)
 			r.Get("/name/{name}", s.gameHandler.GetByName)
+			r.Get("/{id}/reference-bots", s.programHandler.ListReferenceBots)
 
 			// Админские маршруты
 			r.Group(func(r chi.Router) {
 				r.Use(middleware.Auth(s.authService, s.log))
 				r.Use(middleware.RequireAdmin())
+				r.Use(s.audit())
 
 				r.Post("/", s.gameHandler.Create)
 				r.Put("/{id}", s.gameHandler.Update)
 				r.Delete("/{id}", s.gameHandler.Delete)
+
+				// Эталонные боты для калибровочных матчей
+				r.Post("/{id}/reference-bots", s.programHandler.CreateReferenceBot)
+				r.Delete("/{id}/reference-bots/{programId}", s.programHandler.DeleteReferenceBot)
+				r.Put("/{id}/validation-bot", s.programHandler.UpdateValidationBot)
 			})
 		})
 
+		// User routes
+		r.Route("/users/me", func(r chi.Router) {
+			r.Use(middleware.Auth(s.authService, s.log))
+
+			r.Get("/notifications", s.notificationHandler.GetPreferences)
+			r.Put("/notifications", s.notificationHandler.UpdatePreferences)
+		})
+
 		// Team routes
 		r.Route("/teams", func(r chi.Router) {
 			r.Use(middleware.Auth(s.authService, s.log))
+			// Админ может менять чужие команды: такие изменения попадают в журнал
+			r.Use(s.audit())
 
 			r.Post("/", s.teamHandler.Create)
 			r.Post("/join", s.teamHandler.JoinByCode)
@@ -220,16 +314,24 @@ func (s *Server) setupRoutes() {
 		// Program routes (все требуют аутентификации)
 		r.Route("/programs", func(r chi.Router) {
 			r.Use(middleware.Auth(s.authService, s.log))
+			// Админ может менять и удалять чужие программы: такие изменения попадают в журнал
+			r.Use(s.audit())
 
 			r.Post("/", s.programHandler.Create)
 			r.Get("/", s.programHandler.List)
 			r.Get("/versions", s.programHandler.GetVersions) // Список версий программ команды
 			r.Get("/{id}", s.programHandler.Get)
+			r.Get("/{id}/rank", s.programHandler.GetRank)
+			r.Get("/{id}/stats", s.programHandler.GetStats)
+			r.Get("/{id}/matches", s.programHandler.GetMatches)
 			r.Get("/{id}/download", s.programHandler.Download)
 			r.Put("/{id}", s.programHandler.Update)
 			r.Delete("/{id}", s.programHandler.Delete)
 		})
 
+		// Версии языков, доступные для загрузки программ (публичный)
+		r.Get("/executor/supported-languages", s.programHandler.SupportedLanguages)
+
 		// Match routes
 		r.Route("/matches", func(r chi.Router) {
 			// Публичные маршруты с опциональной аутентификацией
@@ -238,17 +340,26 @@ func (s *Server) setupRoutes() {
 				r.Use(middleware.OptionalAuth(s.authService, s.log))
 				r.Get("/", s.matchHandler.List)
 				r.Get("/statistics", s.matchHandler.GetStatistics)
+				r.Get("/head-to-head", s.matchHandler.HeadToHead)
 				r.Get("/{id}", s.matchHandler.Get)
+				r.Get("/{id}/live", s.matchHandler.Live)
 			})
 
+			r.With(middleware.Auth(s.authService, s.log)).Post("/test", s.matchHandler.CreateTestMatch)
+
 			// Админские маршруты для управления очередью матчей
 			r.Group(func(r chi.Router) {
 				r.Use(middleware.Auth(s.authService, s.log))
 				r.Use(middleware.RequireAdmin())
+				r.Use(s.audit())
 
 				r.Get("/queue/stats", s.matchHandler.GetQueueStats)
 				r.Post("/queue/clear", s.matchHandler.ClearQueue)
 				r.Post("/queue/purge", s.matchHandler.PurgeInvalidMatches)
+				r.Post("/{id}/priority", s.matchHandler.Reprioritize)
+				r.Put("/{id}/result", s.matchHandler.AdjustResult)
+				r.Delete("/{id}/result", s.matchHandler.AnnulResult)
+				r.Get("/{id}/result/adjustments", s.matchHandler.ResultAdjustments)
 			})
 		})
 
@@ -256,6 +367,7 @@ func (s *Server) setupRoutes() {
 		r.Route("/ws", func(r chi.Router) {
 			r.Use(middleware.Auth(s.authService, s.log))
 
+			r.Get("/", s.wsHandler.Handle)
 			r.Get("/tournaments/{id}", s.wsHandler.HandleTournament)
 			r.Get("/stats", s.wsHandler.GetStats)
 		})
@@ -268,12 +380,33 @@ func (s *Server) setupRoutes() {
 			r.Get("/metrics", s.systemHandler.GetMetrics)
 			r.Get("/health", s.systemHandler.GetHealth)
 		})
+
+		// Admin routes (только для админов)
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(middleware.Auth(s.authService, s.log))
+			r.Use(middleware.RequireAdmin())
+			r.Use(s.audit())
+
+			r.Get("/audit-log", s.auditHandler.List)
+			r.Get("/audit", s.auditHandler.List)
+			r.Post("/leaderboard/refresh", s.systemHandler.RefreshLeaderboard)
+			r.Get("/queue/peek", s.matchHandler.PeekQueue)
+			r.Post("/db/explain", s.systemHandler.ExplainQuery)
+			r.Post("/tournaments/{id}/cross-game-stats/rebuild", s.systemHandler.RebuildCrossGameStats)
+			r.Post("/impersonate/{userId}", s.authHandler.Impersonate)
+			r.Post("/users/{id}/revoke-sessions", s.authHandler.RevokeUserSessions)
+		})
 	})
 
 	// Serve frontend static files (SPA with fallback to index.html)
 	s.router.Handle("/*", web.Handler())
 }
 
+// audit возвращает middleware журнала действий администраторов
+func (s *Server) audit() func(http.Handler) http.Handler {
+	return middleware.AuditLogger(s.auditRecorder, s.log)
+}
+
 // Handler возвращает HTTP handler
 func (s *Server) Handler() http.Handler {
 	return s.router
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -22,22 +23,30 @@ type Config struct {
 	Metrics   MetricsConfig   `yaml:"metrics"`
 	CORS      CORSConfig      `yaml:"cors"`
 	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+
+	Notifications NotificationsConfig `yaml:"notifications"`
 }
 
 // StorageConfig - конфигурация хранения файлов
 type StorageConfig struct {
-	ProgramsPath     string `yaml:"programs_path"`
-	HostProgramsPath string `yaml:"host_programs_path"` // Путь на хосте для Docker-in-Docker
-	MaxFileSize      int64  `yaml:"max_file_size"`      // В байтах
+	ProgramsPath     string        `yaml:"programs_path"`
+	HostProgramsPath string        `yaml:"host_programs_path"` // Путь на хосте для Docker-in-Docker
+	MaxFileSize      int64         `yaml:"max_file_size"`      // В байтах
+	UploadCooldown   time.Duration `yaml:"upload_cooldown"`    // Интервал между загрузками версий командой (metadata турнира может переопределить)
+	PurgeRetention   time.Duration `yaml:"purge_retention"`    // Срок хранения удалённого турнира до окончательного удаления
+	MaxVersions      int           `yaml:"max_versions"`       // Максимум версий программы команды для игры (0 - без ограничения)
+	PruneVersions    bool          `yaml:"prune_versions"`     // При достижении max_versions удалять самую старую неиспользуемую версию вместо отказа
 }
 
 // ServerConfig - конфигурация HTTP сервера
 type ServerConfig struct {
-	Port            int           `yaml:"port"`
-	ReadTimeout     time.Duration `yaml:"read_timeout"`
-	WriteTimeout    time.Duration `yaml:"write_timeout"`
-	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
-	BaseURL         string        `yaml:"base_url"` // Базовый URL для ссылок (например, для приглашений в команду)
+	Port              int           `yaml:"port"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	BaseURL           string        `yaml:"base_url"`            // Базовый URL для ссылок (например, для приглашений в команду)
+	TimeBudgetWarning time.Duration `yaml:"time_budget_warning"` // За сколько до истечения бюджета времени турнира рассылается предупреждение (0 - не рассылать)
 }
 
 // DatabaseConfig - конфигурация PostgreSQL
@@ -50,14 +59,25 @@ type DatabaseConfig struct {
 	MaxConnections int           `yaml:"max_connections"`
 	MaxIdle        int           `yaml:"max_idle"`
 	MaxLifetime    time.Duration `yaml:"max_lifetime"`
+	// StatementTimeout ограничивает любой запрос на стороне PostgreSQL, даже если
+	// контекст вызывающего кода без дедлайна (0 - без ограничения)
+	StatementTimeout time.Duration `yaml:"statement_timeout"`
+	// CrossGameCheck сверяет кросс-игровой рейтинг из агрегата team_game_stats
+	// с расчётом по всем матчам на каждом запросе (медленно, для диагностики)
+	CrossGameCheck bool `yaml:"cross_game_check"`
 }
 
-// DSN возвращает строку подключения к PostgreSQL (формат key=value)
+// DSN возвращает строку подключения к PostgreSQL (формат key=value).
+// statement_timeout передаётся как параметр сессии каждого соединения пула
 func (c DatabaseConfig) DSN() string {
-	return fmt.Sprintf(
+	dsn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		c.Host, c.Port, c.User, c.Password, c.Name,
 	)
+	if c.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", c.StatementTimeout.Milliseconds())
+	}
+	return dsn
 }
 
 // DSNURL возвращает строку подключения в URL формате (для golang-migrate)
@@ -90,6 +110,17 @@ type WorkerConfig struct {
 	Timeout       time.Duration `yaml:"timeout"`
 	RetryAttempts int           `yaml:"retry_attempts"`
 	RetryDelay    time.Duration `yaml:"retry_delay"`
+	PriorityAging time.Duration `yaml:"priority_aging"` // Ожидание, за которое матч поднимается на ступень приоритета (0 - без старения)
+	BatchSize     int           `yaml:"batch_size"`     // Сколько матчей воркер выполняет одновременно (1 - по одному)
+
+	ResultBatchSize   int           `yaml:"result_batch_size"`    // Результатов матчей в одном пакете записи (1 - каждый отдельно)
+	ResultBatchMaxAge time.Duration `yaml:"result_batch_max_age"` // Сколько результат ждёт заполнения пакета
+
+	DrainTimeout time.Duration `yaml:"drain_timeout"` // Сколько при остановке ждать текущие матчи (0 - без ограничения)
+
+	PriorityEscalationAge time.Duration `yaml:"priority_escalation_age"` // Возраст pending матча, после которого его приоритет в БД повышается (0 - отключено)
+
+	LeaderboardRefreshInterval time.Duration `yaml:"leaderboard_refresh_interval"` // Период обновления materialized views leaderboards
 }
 
 // ExecutorConfig - конфигурация исполнителя матчей
@@ -106,6 +137,20 @@ type ExecutorConfig struct {
 	SeccompProfile    string        `yaml:"seccomp_profile"`    // Путь к seccomp профилю
 	AppArmorProfile   string        `yaml:"apparmor_profile"`   // Имя AppArmor профиля
 	CPUSetCPUs        string        `yaml:"cpuset_cpus"`        // Привязка к ядрам CPU (например "0-3")
+	SandboxProfile    string        `yaml:"sandbox_profile"`    // Профиль изоляции по умолчанию (strict/relaxed)
+	CompileTimeout    time.Duration `yaml:"compile_timeout"`    // Таймаут компиляции программы
+	SkipWarmup        bool          `yaml:"skip_warmup"`        // Не загружать образ при старте worker (тесты)
+	WarmupTimeout     time.Duration `yaml:"warmup_timeout"`     // Таймаут загрузки образа при старте
+	WarmupWarnOnly    bool          `yaml:"warmup_warn_only"`   // Продолжать работу, если образ недоступен
+	WarmPoolSize      int           `yaml:"warm_pool_size"`     // Прогретых контейнеров на worker (0 - контейнер на матч)
+	WarmPoolMaxUses   int           `yaml:"warm_pool_max_uses"` // Матчей в одном прогретом контейнере до пересоздания
+	RetryAttempts     int           `yaml:"retry_attempts"`     // Повторов матча при временном сбое инфраструктуры
+	RetryBackoff      time.Duration `yaml:"retry_backoff"`      // Пауза перед первым повтором (удваивается)
+	RetryMaxBackoff   time.Duration `yaml:"retry_max_backoff"`  // Предел паузы между повторами
+
+	// Версии языков, доступные в образе tjudge-cli: язык -> версии от старой к новой.
+	// Последняя версия используется по умолчанию
+	LanguageVersions map[string][]string `yaml:"language_versions"`
 }
 
 // JWTConfig - конфигурация JWT токенов
@@ -113,6 +158,9 @@ type JWTConfig struct {
 	Secret     string        `yaml:"secret"`
 	AccessTTL  time.Duration `yaml:"access_ttl"`
 	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+
+	ImpersonationEnabled bool          `yaml:"impersonation_enabled"` // Разрешить админам действовать от имени пользователей
+	ImpersonationTTL     time.Duration `yaml:"impersonation_ttl"`     // Время жизни токена impersonation
 }
 
 // LoggingConfig - конфигурация логирования
@@ -132,10 +180,112 @@ type MetricsConfig struct {
 
 // CORSConfig - конфигурация CORS
 type CORSConfig struct {
-	AllowedOrigins []string `yaml:"allowed_origins"`
-	AllowedMethods []string `yaml:"allowed_methods"`
-	AllowedHeaders []string `yaml:"allowed_headers"`
-	MaxAge         int      `yaml:"max_age"`
+	AllowedOrigins   []string `yaml:"allowed_origins"`
+	AllowedMethods   []string `yaml:"allowed_methods"`
+	AllowedHeaders   []string `yaml:"allowed_headers"`
+	ExposedHeaders   []string `yaml:"exposed_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	MaxAgeSec        int      `yaml:"max_age_sec"` // Время кэширования preflight в браузере
+	// Источники для WebSocket: браузер не делает preflight для upgrade, источник проверяет сервер.
+	// Пусто - те же, что AllowedOrigins
+	WebSocketOrigins []string `yaml:"websocket_origins"`
+}
+
+// maxCORSMaxAge дольше сутки preflight не кэширует ни один браузер
+const maxCORSMaxAge = 86400
+
+// corsExposedHeaders заголовки ответа, доступные скриптам на другом источнике
+var corsExposedHeaders = []string{"Link", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+// DevelopmentCORS открывает CORS для любых источников. Credentials выключены:
+// браузеры не принимают их вместе с Access-Control-Allow-Origin: *
+func DevelopmentCORS() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"*"},
+		ExposedHeaders: corsExposedHeaders,
+		MaxAgeSec:      3600,
+	}
+}
+
+// ProductionCORS разрешает запросы с credentials только с перечисленных доменов
+func ProductionCORS(origins []string) CORSConfig {
+	return CORSConfig{
+		AllowedOrigins:   origins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-API-Key", "X-CSRF-Token"},
+		ExposedHeaders:   corsExposedHeaders,
+		AllowCredentials: true,
+		MaxAgeSec:        3600,
+	}
+}
+
+// corsFromEnv выбирает пресет по ENVIRONMENT и применяет переопределения из CORS_*.
+// Без CORS_ALLOWED_ORIGINS production разрешает только источник baseURL
+func corsFromEnv(baseURL string) CORSConfig {
+	cors := DevelopmentCORS()
+	if isProduction() {
+		cors = ProductionCORS([]string{strings.TrimSuffix(baseURL, "/")})
+	}
+
+	cors.AllowedOrigins = getEnvList("CORS_ALLOWED_ORIGINS", cors.AllowedOrigins)
+	cors.AllowedMethods = getEnvList("CORS_ALLOWED_METHODS", cors.AllowedMethods)
+	cors.AllowedHeaders = getEnvList("CORS_ALLOWED_HEADERS", cors.AllowedHeaders)
+	cors.ExposedHeaders = getEnvList("CORS_EXPOSED_HEADERS", cors.ExposedHeaders)
+	cors.AllowCredentials = getEnvBool("CORS_ALLOW_CREDENTIALS", cors.AllowCredentials)
+	cors.MaxAgeSec = getEnvInt("CORS_MAX_AGE", cors.MaxAgeSec)
+	cors.WebSocketOrigins = getEnvList("WEBSOCKET_ALLOWED_ORIGINS", cors.WebSocketOrigins)
+	return cors
+}
+
+// WebSocketAllowedOrigins возвращает источники, с которых разрешено подключение по WebSocket
+func (c CORSConfig) WebSocketAllowedOrigins() []string {
+	if len(c.WebSocketOrigins) > 0 {
+		return c.WebSocketOrigins
+	}
+	return c.AllowedOrigins
+}
+
+// Validate проверяет политику CORS. Источник - "*", точный scheme://host[:port]
+// или поддомены scheme://*.host[:port]. Любой источник ("*") несовместим с credentials:
+// браузер отвергнет такой ответ, а отражать любой источник небезопасно
+func (c CORSConfig) Validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if err := validateOrigin(origin); err != nil {
+			return fmt.Errorf("cors allowed_origins: %w", err)
+		}
+		if origin == "*" && c.AllowCredentials {
+			return fmt.Errorf("cors allowed_origins must not contain the \"*\" wildcard when allow_credentials is enabled, list the origins or use https://*.example.com")
+		}
+	}
+	for _, origin := range c.WebSocketOrigins {
+		if err := validateOrigin(origin); err != nil {
+			return fmt.Errorf("cors websocket_origins: %w", err)
+		}
+	}
+	if c.MaxAgeSec < 0 || c.MaxAgeSec > maxCORSMaxAge {
+		return fmt.Errorf("cors max_age_sec must be between 0 and %d", maxCORSMaxAge)
+	}
+	return nil
+}
+
+// validateOrigin проверяет формат разрешённого источника
+func validateOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" || u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid origin %q: expected scheme://host[:port] without path", origin)
+	}
+
+	host := strings.TrimPrefix(u.Hostname(), "*.")
+	if host == "" || strings.Contains(host, "*") {
+		return fmt.Errorf("invalid origin %q: wildcard is only allowed as the first label of the host (https://*.example.com)", origin)
+	}
+	return nil
 }
 
 // RateLimitConfig - конфигурация rate limiting
@@ -143,6 +293,52 @@ type RateLimitConfig struct {
 	Enabled           bool `yaml:"enabled"`
 	RequestsPerMinute int  `yaml:"requests_per_minute"`
 	Burst             int  `yaml:"burst"`
+	// Тестовых матчей на пользователя в час, действует и при Enabled=false
+	TestMatchesPerHour int `yaml:"test_matches_per_hour"`
+}
+
+// TracingConfig - конфигурация OpenTelemetry трассировки
+type TracingConfig struct {
+	Endpoint    string `yaml:"endpoint"`     // OTLP gRPC коллектор (host:port), пусто - экспорт выключен
+	ServiceName string `yaml:"service_name"` // Если пусто, используется имя по умолчанию для сервиса
+}
+
+// ServiceNameOr возвращает имя сервиса или значение по умолчанию
+func (c TracingConfig) ServiceNameOr(defaultName string) string {
+	if c.ServiceName == "" {
+		return defaultName
+	}
+	return c.ServiceName
+}
+
+// NotificationsConfig - конфигурация уведомлений по email и webhook'ам турниров
+type NotificationsConfig struct {
+	SMTP                 SMTPConfig    `yaml:"smtp"`
+	PollInterval         time.Duration `yaml:"poll_interval"`          // Период проверки outbox
+	BatchSize            int           `yaml:"batch_size"`             // Уведомлений за одну проверку
+	MaxAttempts          int           `yaml:"max_attempts"`           // Попыток доставки до пометки failed
+	RetryDelay           time.Duration `yaml:"retry_delay"`            // Задержка после первой неудачи, далее удваивается
+	MaxDelay             time.Duration `yaml:"max_delay"`              // Максимальная задержка между попытками
+	AllowPrivateWebhooks bool          `yaml:"allow_private_webhooks"` // Разрешить webhook'и на адреса внутренней сети
+}
+
+// Enabled проверяет, настроен ли SMTP сервер. Без него email-события не записываются в outbox
+func (c NotificationsConfig) Enabled() bool {
+	return c.SMTP.Host != ""
+}
+
+// SMTPConfig - конфигурация SMTP сервера
+type SMTPConfig struct {
+	Host     string `yaml:"host"` // Пусто - уведомления выключены
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// Address возвращает адрес SMTP сервера
+func (c SMTPConfig) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
 // Validate валидирует конфигурацию
@@ -151,6 +347,9 @@ func (c *Config) Validate() error {
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
+	if c.Server.TimeBudgetWarning < 0 {
+		return fmt.Errorf("server time_budget_warning must not be negative")
+	}
 
 	// Валидация Database
 	if c.Database.Host == "" {
@@ -168,6 +367,9 @@ func (c *Config) Validate() error {
 	if c.Database.MaxConnections < 1 {
 		return fmt.Errorf("database max_connections must be positive")
 	}
+	if c.Database.StatementTimeout < 0 {
+		return fmt.Errorf("database statement_timeout must not be negative")
+	}
 
 	// Валидация Redis
 	if c.Redis.Host == "" {
@@ -187,18 +389,86 @@ func (c *Config) Validate() error {
 	if c.Worker.QueueSize < 1 {
 		return fmt.Errorf("worker queue_size must be positive")
 	}
+	if c.Worker.LeaderboardRefreshInterval < time.Second {
+		return fmt.Errorf("worker leaderboard_refresh_interval must be at least 1s")
+	}
+	if c.Worker.PriorityAging < 0 {
+		return fmt.Errorf("worker priority_aging must not be negative")
+	}
+	if c.Worker.BatchSize < 1 {
+		return fmt.Errorf("worker batch_size must be positive")
+	}
+	if c.Worker.ResultBatchSize < 1 {
+		return fmt.Errorf("worker result_batch_size must be positive")
+	}
+	if c.Worker.ResultBatchMaxAge < 0 {
+		return fmt.Errorf("worker result_batch_max_age must not be negative")
+	}
+	if c.Worker.PriorityEscalationAge < 0 {
+		return fmt.Errorf("worker priority_escalation_age must not be negative")
+	}
+	if c.Worker.DrainTimeout < 0 {
+		return fmt.Errorf("worker drain_timeout must not be negative")
+	}
+
+	// Валидация Storage
+	if c.Storage.MaxVersions < 0 {
+		return fmt.Errorf("storage max_versions must not be negative")
+	}
+
+	// Валидация Executor
+	if c.Executor.SandboxProfile != "strict" && c.Executor.SandboxProfile != "relaxed" {
+		return fmt.Errorf("invalid executor sandbox_profile: %s", c.Executor.SandboxProfile)
+	}
+	if c.Executor.WarmPoolSize < 0 {
+		return fmt.Errorf("executor warm_pool_size must not be negative")
+	}
+	if c.Executor.WarmPoolSize > 0 && c.Executor.WarmPoolMaxUses < 1 {
+		return fmt.Errorf("executor warm_pool_max_uses must be positive")
+	}
+	if c.Executor.RetryAttempts < 0 || c.Executor.RetryBackoff < 0 || c.Executor.RetryMaxBackoff < 0 {
+		return fmt.Errorf("executor retry settings must not be negative")
+	}
+	for language, versions := range c.Executor.LanguageVersions {
+		seen := make(map[string]bool, len(versions))
+		for _, version := range versions {
+			if version == "" || len(version) > 16 || seen[version] {
+				return fmt.Errorf("invalid executor language version for %s: %q", language, version)
+			}
+			seen[version] = true
+		}
+	}
 
 	// Валидация JWT
 	if c.JWT.Secret == "" || c.JWT.Secret == "change-this-secret-in-production" {
 		// В production это должно быть ошибкой
-		env := os.Getenv("ENVIRONMENT")
-		if env == "production" || env == "prod" {
+		if isProduction() {
 			return fmt.Errorf("JWT secret must be changed in production")
 		}
 	}
 	if c.JWT.AccessTTL < 1*time.Minute {
 		return fmt.Errorf("JWT access_ttl is too short")
 	}
+	if c.JWT.ImpersonationEnabled && (c.JWT.ImpersonationTTL < time.Minute || c.JWT.ImpersonationTTL > c.JWT.AccessTTL) {
+		return fmt.Errorf("JWT impersonation_ttl must be between 1m and access_ttl")
+	}
+
+	// Валидация CORS. Пустой список источников cors трактует как "любой источник"
+	if err := c.CORS.Validate(); err != nil {
+		return err
+	}
+	if isProduction() && len(c.CORS.AllowedOrigins) == 0 {
+		return fmt.Errorf("cors allowed_origins are required in production")
+	}
+
+	// Валидация уведомлений
+	if c.Notifications.Enabled() && c.Notifications.SMTP.From == "" {
+		return fmt.Errorf("smtp from address is required when notifications are enabled")
+	}
+	// Webhook'и доставляются и без SMTP
+	if c.Notifications.MaxAttempts < 1 {
+		return fmt.Errorf("notifications max_attempts must be at least 1")
+	}
 
 	// Валидация Logging
 	validLevels := []string{"debug", "info", "warn", "error"}
@@ -221,13 +491,15 @@ func Load() (*Config, error) {
 	// Загружаем .env файл если существует (игнорируем ошибку если файла нет)
 	_ = godotenv.Load()
 
+	baseURL := getEnv("BASE_URL", "http://localhost:8080")
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:            getEnvInt("API_PORT", 8080),
-			ReadTimeout:     getEnvDuration("READ_TIMEOUT", 30*time.Second),
-			WriteTimeout:    getEnvDuration("WRITE_TIMEOUT", 30*time.Second),
-			ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
-			BaseURL:         getEnv("BASE_URL", "http://localhost:8080"),
+			Port:              getEnvInt("API_PORT", 8080),
+			ReadTimeout:       getEnvDuration("READ_TIMEOUT", 30*time.Second),
+			WriteTimeout:      getEnvDuration("WRITE_TIMEOUT", 30*time.Second),
+			ShutdownTimeout:   getEnvDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
+			BaseURL:           baseURL,
+			TimeBudgetWarning: getEnvDuration("TOURNAMENT_TIME_BUDGET_WARNING", 5*time.Minute),
 		},
 		Database: DatabaseConfig{
 			Host:           getEnv("DB_HOST", "localhost"),
@@ -238,6 +510,9 @@ func Load() (*Config, error) {
 			MaxConnections: getEnvInt("DB_MAX_CONNECTIONS", 50),
 			MaxIdle:        getEnvInt("DB_MAX_IDLE", 10),
 			MaxLifetime:    getEnvDuration("DB_MAX_LIFETIME", 1*time.Hour),
+			// Больше самого длинного HTTP таймаута (heavy, 30s), чтобы запрос отменял контекст запроса
+			StatementTimeout: getEnvDuration("DB_STATEMENT_TIMEOUT", 60*time.Second),
+			CrossGameCheck:   getEnvBool("DB_CROSS_GAME_CHECK", false),
 		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -253,6 +528,17 @@ func Load() (*Config, error) {
 			Timeout:       getEnvDuration("WORKER_TIMEOUT", 30*time.Second),
 			RetryAttempts: getEnvInt("WORKER_RETRY_ATTEMPTS", 3),
 			RetryDelay:    getEnvDuration("WORKER_RETRY_DELAY", 5*time.Second),
+			PriorityAging: getEnvDuration("WORKER_PRIORITY_AGING", 10*time.Minute),
+			BatchSize:     getEnvInt("WORKER_BATCH_SIZE", 1),
+
+			ResultBatchSize:   getEnvInt("WORKER_RESULT_BATCH_SIZE", 1),
+			ResultBatchMaxAge: getEnvDuration("WORKER_RESULT_BATCH_MAX_AGE", 100*time.Millisecond),
+
+			DrainTimeout: getEnvDuration("WORKER_DRAIN_TIMEOUT", 120*time.Second),
+
+			PriorityEscalationAge: getEnvDuration("WORKER_PRIORITY_ESCALATION_AGE", 30*time.Minute),
+
+			LeaderboardRefreshInterval: getEnvDuration("WORKER_LEADERBOARD_REFRESH_INTERVAL", 30*time.Second),
 		},
 		Executor: ExecutorConfig{
 			TJudgePath:        getEnv("TJUDGE_PATH", "tjudge-cli"),
@@ -267,16 +553,36 @@ func Load() (*Config, error) {
 			SeccompProfile:    getEnv("EXECUTOR_SECCOMP_PROFILE", ""),
 			AppArmorProfile:   getEnv("EXECUTOR_APPARMOR_PROFILE", ""),
 			CPUSetCPUs:        getEnv("EXECUTOR_CPUSET_CPUS", ""),
+			SandboxProfile:    getEnv("EXECUTOR_SANDBOX_PROFILE", "strict"),
+			CompileTimeout:    getEnvDuration("EXECUTOR_COMPILE_TIMEOUT", 2*time.Minute),
+			SkipWarmup:        getEnvBool("EXECUTOR_SKIP_WARMUP", false),
+			WarmupTimeout:     getEnvDuration("EXECUTOR_WARMUP_TIMEOUT", 60*time.Second),
+			WarmupWarnOnly:    getEnvBool("EXECUTOR_WARMUP_WARN_ONLY", false),
+			WarmPoolSize:      getEnvInt("EXECUTOR_WARM_POOL_SIZE", 0),
+			WarmPoolMaxUses:   getEnvInt("EXECUTOR_WARM_POOL_MAX_USES", 50),
+			RetryAttempts:     getEnvInt("EXECUTOR_RETRY_ATTEMPTS", 2),
+			RetryBackoff:      getEnvDuration("EXECUTOR_RETRY_BACKOFF", time.Second),
+			RetryMaxBackoff:   getEnvDuration("EXECUTOR_RETRY_MAX_BACKOFF", 10*time.Second),
+			LanguageVersions: map[string][]string{
+				"python": getEnvList("EXECUTOR_PYTHON_VERSIONS", []string{"3.9", "3.10", "3.11", "3.12"}),
+			},
 		},
 		Storage: StorageConfig{
 			ProgramsPath:     getEnv("PROGRAMS_PATH", "/data/programs"),
 			HostProgramsPath: getEnv("HOST_PROGRAMS_PATH", ""),            // Если пусто, используется ProgramsPath
 			MaxFileSize:      int64(getEnvInt("MAX_FILE_SIZE", 10485760)), // 10MB
+			UploadCooldown:   getEnvDuration("PROGRAM_UPLOAD_COOLDOWN", 5*time.Minute),
+			PurgeRetention:   getEnvDuration("TOURNAMENT_PURGE_RETENTION", 30*24*time.Hour),
+			MaxVersions:      getEnvInt("PROGRAM_MAX_VERSIONS", 0),
+			PruneVersions:    getEnvBool("PROGRAM_PRUNE_VERSIONS", false),
 		},
 		JWT: JWTConfig{
 			Secret:     getEnvOrFile("JWT_SECRET", "change-this-secret-in-production"), // Поддержка Docker secrets
 			AccessTTL:  getEnvDuration("JWT_ACCESS_TTL", 1*time.Hour),                  // 1 час активной сессии
 			RefreshTTL: getEnvDuration("JWT_REFRESH_TTL", 72*time.Hour),                // 3 дня неактивности
+
+			ImpersonationEnabled: getEnvBool("JWT_IMPERSONATION_ENABLED", true),
+			ImpersonationTTL:     getEnvDuration("JWT_IMPERSONATION_TTL", 15*time.Minute),
 		},
 		Logging: LoggingConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
@@ -289,16 +595,32 @@ func Load() (*Config, error) {
 			Port:    getEnvInt("METRICS_PORT", 9090),
 			Path:    getEnv("METRICS_PATH", "/metrics"),
 		},
-		CORS: CORSConfig{
-			AllowedOrigins: []string{getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000")},
-			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowedHeaders: []string{"Content-Type", "Authorization"},
-			MaxAge:         getEnvInt("CORS_MAX_AGE", 3600),
-		},
+		CORS: corsFromEnv(baseURL),
 		RateLimit: RateLimitConfig{
-			Enabled:           getEnvBool("RATE_LIMIT_ENABLED", false), // Disabled by default for development
-			RequestsPerMinute: getEnvInt("RATE_LIMIT_RPM", 100),
-			Burst:             getEnvInt("RATE_LIMIT_BURST", 200),
+			Enabled:            getEnvBool("RATE_LIMIT_ENABLED", false), // Disabled by default for development
+			RequestsPerMinute:  getEnvInt("RATE_LIMIT_RPM", 100),
+			Burst:              getEnvInt("RATE_LIMIT_BURST", 200),
+			TestMatchesPerHour: getEnvInt("RATE_LIMIT_TEST_MATCHES_PER_HOUR", 10),
+		},
+		Tracing: TracingConfig{
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			ServiceName: getEnv("OTEL_SERVICE_NAME", ""),
+		},
+		Notifications: NotificationsConfig{
+			SMTP: SMTPConfig{
+				Host:     getEnv("SMTP_HOST", ""),
+				Port:     getEnvInt("SMTP_PORT", 587),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnvOrFile("SMTP_PASSWORD", ""), // Поддержка Docker secrets
+				From:     getEnv("SMTP_FROM", ""),
+			},
+			PollInterval: getEnvDuration("NOTIFICATIONS_POLL_INTERVAL", 10*time.Second),
+			BatchSize:    getEnvInt("NOTIFICATIONS_BATCH_SIZE", 50),
+			MaxAttempts:  getEnvInt("NOTIFICATIONS_MAX_ATTEMPTS", 8),
+			RetryDelay:   getEnvDuration("NOTIFICATIONS_RETRY_DELAY", 30*time.Second),
+			MaxDelay:     getEnvDuration("NOTIFICATIONS_MAX_DELAY", 1*time.Hour),
+
+			AllowPrivateWebhooks: getEnvBool("WEBHOOKS_ALLOW_PRIVATE", false),
 		},
 	}
 
@@ -345,6 +667,28 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getEnvList читает список значений, разделённых запятыми
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// isProduction проверяет, что приложение запущено в production (ENVIRONMENT)
+func isProduction() bool {
+	env := os.Getenv("ENVIRONMENT")
+	return env == "production" || env == "prod"
+}
+
 // getEnvOrFile читает значение из переменной окружения или из файла
 // Сначала проверяет KEY, затем KEY_FILE
 // Это поддерживает Docker secrets
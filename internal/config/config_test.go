@@ -0,0 +1,151 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORSConfig_Validate(t *testing.T) {
+	assert.NoError(t, DevelopmentCORS().Validate())
+	assert.NoError(t, ProductionCORS([]string{"https://tjudge.example.com"}).Validate())
+
+	// Credentials must never be combined with the any-origin wildcard
+	cors := ProductionCORS([]string{"https://tjudge.example.com", "*"})
+	assert.Error(t, cors.Validate())
+
+	// A subdomain wildcard names a concrete set of origins and is fine with credentials
+	cors.AllowedOrigins = []string{"https://tjudge.example.com", "https://*.contest.bmstu.ru", "http://localhost:3000"}
+	assert.NoError(t, cors.Validate())
+
+	for _, origin := range []string{
+		"tjudge.example.com",
+		"https://tjudge.example.com/",
+		"https://tjudge.example.com/app",
+		"https://contest.*.ru",
+		"https://*.*.example.com",
+		"https://*",
+	} {
+		cors.AllowedOrigins = []string{origin}
+		assert.Error(t, cors.Validate(), origin)
+	}
+
+	cors.AllowedOrigins = []string{"https://tjudge.example.com"}
+	cors.WebSocketOrigins = []string{"wss://*"}
+	assert.Error(t, cors.Validate())
+
+	cors.WebSocketOrigins = nil
+	cors.MaxAgeSec = 90000
+	assert.Error(t, cors.Validate())
+}
+
+func TestCORSConfig_WebSocketAllowedOrigins(t *testing.T) {
+	cors := ProductionCORS([]string{"https://tjudge.example.com"})
+	assert.Equal(t, []string{"https://tjudge.example.com"}, cors.WebSocketAllowedOrigins())
+
+	cors.WebSocketOrigins = []string{"https://*.contest.bmstu.ru"}
+	assert.Equal(t, []string{"https://*.contest.bmstu.ru"}, cors.WebSocketAllowedOrigins())
+}
+
+func TestCORSFromEnv(t *testing.T) {
+	t.Run("development preset is open", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "development")
+
+		cors := corsFromEnv("http://localhost:8080")
+		assert.Equal(t, []string{"*"}, cors.AllowedOrigins)
+		assert.False(t, cors.AllowCredentials)
+	})
+
+	t.Run("production preset allows configured domains", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "production")
+		t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+		t.Setenv("CORS_MAX_AGE", "600")
+
+		cors := corsFromEnv("https://tjudge.example.com")
+		assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cors.AllowedOrigins)
+		assert.True(t, cors.AllowCredentials)
+		assert.Equal(t, 600, cors.MaxAgeSec)
+	})
+
+	t.Run("production defaults to the base URL", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "production")
+		t.Setenv("CORS_ALLOWED_ORIGINS", "")
+
+		cors := corsFromEnv("https://tjudge.example.com/")
+		assert.Equal(t, []string{"https://tjudge.example.com"}, cors.AllowedOrigins)
+	})
+
+	t.Run("load rejects wildcard with credentials", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "development")
+		t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+		t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+
+		_, err := Load()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "wildcard")
+	})
+
+	t.Run("load requires origins in production", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "production")
+		t.Setenv("JWT_SECRET", "integration-secret")
+		t.Setenv("CORS_ALLOWED_ORIGINS", " , ")
+
+		_, err := Load()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "allowed_origins")
+	})
+}
+
+func TestNotificationsConfig(t *testing.T) {
+	t.Run("disabled without smtp host", func(t *testing.T) {
+		t.Setenv("SMTP_HOST", "")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+		assert.False(t, cfg.Notifications.Enabled())
+	})
+
+	t.Run("smtp from env", func(t *testing.T) {
+		t.Setenv("SMTP_HOST", "smtp.example.com")
+		t.Setenv("SMTP_PORT", "2525")
+		t.Setenv("SMTP_FROM", "judge@example.com")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+		assert.True(t, cfg.Notifications.Enabled())
+		assert.Equal(t, "smtp.example.com:2525", cfg.Notifications.SMTP.Address())
+		assert.Equal(t, 8, cfg.Notifications.MaxAttempts)
+	})
+
+	t.Run("from address is required", func(t *testing.T) {
+		t.Setenv("SMTP_HOST", "smtp.example.com")
+		t.Setenv("SMTP_FROM", "")
+
+		_, err := Load()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "smtp from")
+	})
+}
+
+func TestDatabaseConfig_DSN(t *testing.T) {
+	cfg := DatabaseConfig{Host: "db", Port: 5432, User: "tjudge", Password: "secret", Name: "tjudge"}
+	assert.Equal(t, "host=db port=5432 user=tjudge password=secret dbname=tjudge sslmode=disable", cfg.DSN())
+
+	cfg.StatementTimeout = 1500 * time.Millisecond
+	assert.Equal(t, "host=db port=5432 user=tjudge password=secret dbname=tjudge sslmode=disable statement_timeout=1500", cfg.DSN())
+}
+
+func TestDatabaseStatementTimeoutFromEnv(t *testing.T) {
+	t.Setenv("DB_STATEMENT_TIMEOUT", "5s")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, cfg.Database.StatementTimeout)
+
+	t.Setenv("DB_STATEMENT_TIMEOUT", "-1s")
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "statement_timeout")
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLogEntry запись журнала действий администраторов.
+// Тело запроса не хранится, только его SHA-256: по хэшу можно сверить запрос с предъявленным
+type AuditLogEntry struct {
+	ID              int64           `json:"id" db:"id"`
+	UserID          uuid.UUID       `json:"user_id" db:"user_id"`
+	ImpersonatedBy  *uuid.UUID      `json:"impersonated_by,omitempty" db:"impersonated_by"`
+	Action          string          `json:"action" db:"action"`
+	TargetType      *string         `json:"target_type,omitempty" db:"target_type"`
+	TargetID        *string         `json:"target_id,omitempty" db:"target_id"`
+	IPAddress       string          `json:"ip_address" db:"ip_address"`
+	UserAgent       string          `json:"user_agent" db:"user_agent"`
+	RequestBodyHash *string         `json:"request_body_hash,omitempty" db:"request_body_hash"`
+	Params          json.RawMessage `json:"params,omitempty" db:"params"` // Параметры маршрута и строки запроса
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditLogFilter фильтр журнала действий
+type AuditLogFilter struct {
+	UserID     *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	After      *time.Time
+	Before     *AuditLogCursor // Записи старше курсора: следующая страница
+	Limit      int
+}
+
+// AuditLogCursor позиция записи в журнале, отсортированном по (created_at, id) по убыванию
+type AuditLogCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
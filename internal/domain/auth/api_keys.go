@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// APIKeyPrefix начало каждого ключа: позволяет отличить ключ от JWT и найти его в утёкших логах
+	APIKeyPrefix = "tjk_"
+
+	apiKeyBytes        = 32
+	apiKeyDisplayChars = 8 // Символов ключа после APIKeyPrefix, показываемых в списке
+	maxAPIKeyName      = 100
+	maxAPIKeysPerUser  = 20
+)
+
+// APIKeyRepository интерфейс для хранения API ключей
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	Revoke(ctx context.Context, id, userID uuid.UUID) (string, error)
+	RevokeAllByUser(ctx context.Context, userID uuid.UUID) ([]string, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// APIKeyCache интерфейс кэша пользователей API ключей
+type APIKeyCache interface {
+	Get(ctx context.Context, keyHash string) (*domain.APIKeyIdentity, error)
+	Set(ctx context.Context, keyHash string, identity *domain.APIKeyIdentity) error
+	Delete(ctx context.Context, keyHash string) error
+}
+
+// SetAPIKeys включает API ключи. Без них запросы с ключами отклоняются
+func (s *Service) SetAPIKeys(repo APIKeyRepository, cache APIKeyCache) {
+	s.apiKeyRepo = repo
+	s.apiKeyCache = cache
+}
+
+// CreateAPIKeyRequest - запрос на создание API ключа
+type CreateAPIKeyRequest struct {
+	Name  string             `json:"name"`
+	Scope domain.APIKeyScope `json:"scope"`
+}
+
+// CreatedAPIKey - созданный ключ. Key возвращается только один раз
+type CreatedAPIKey struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKey выпускает пользователю новый API ключ. В БД сохраняется только хэш
+func (s *Service) CreateAPIKey(ctx context.Context, userID uuid.UUID, req *CreateAPIKeyRequest) (*CreatedAPIKey, error) {
+	if s.apiKeyRepo == nil {
+		return nil, errors.ErrServiceUnavailable.WithMessage("api keys are not configured")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPIKeyName {
+		return nil, errors.ErrValidation.WithMessage(fmt.Sprintf("name must be 1-%d characters", maxAPIKeyName))
+	}
+	if !req.Scope.IsValid() {
+		return nil, errors.ErrValidation.WithMessage("scope must be read or read_write")
+	}
+
+	keys, err := s.apiKeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	if len(keys) >= maxAPIKeysPerUser {
+		return nil, errors.ErrConflict.WithMessage(fmt.Sprintf("at most %d api keys per user", maxAPIKeysPerUser))
+	}
+
+	raw := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	apiKey := &domain.APIKey{
+		ID:      uuid.New(),
+		UserID:  userID,
+		Name:    name,
+		KeyHash: hashAPIKey(key),
+		Prefix:  key[:len(APIKeyPrefix)+apiKeyDisplayChars],
+		Scope:   req.Scope,
+	}
+	if err := s.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	s.log.Info("API key created",
+		zap.Bool("audit", true),
+		zap.String("user_id", userID.String()),
+		zap.String("key_id", apiKey.ID.String()),
+		zap.String("scope", string(apiKey.Scope)),
+	)
+
+	return &CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// ListAPIKeys возвращает действующие ключи пользователя
+func (s *Service) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	if s.apiKeyRepo == nil {
+		return nil, errors.ErrServiceUnavailable.WithMessage("api keys are not configured")
+	}
+
+	keys, err := s.apiKeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	if keys == nil {
+		keys = []*domain.APIKey{}
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает ключ пользователя и сразу убирает его из кэша
+func (s *Service) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	if s.apiKeyRepo == nil {
+		return errors.ErrServiceUnavailable.WithMessage("api keys are not configured")
+	}
+
+	keyHash, err := s.apiKeyRepo.Revoke(ctx, keyID, userID)
+	if err != nil {
+		return err
+	}
+
+	// При ошибке ключ продолжит работать до истечения TTL кэша
+	if err := s.apiKeyCache.Delete(ctx, keyHash); err != nil {
+		s.log.LogError("Failed to drop revoked api key from cache", err, zap.String("key_id", keyID.String()))
+	}
+
+	s.log.Info("API key revoked",
+		zap.Bool("audit", true),
+		zap.String("user_id", userID.String()),
+		zap.String("key_id", keyID.String()),
+	)
+	return nil
+}
+
+// revokeAllAPIKeys отзывает все ключи пользователя и убирает их из кэша.
+// Вызывается при отзыве всех сессий: скриптовый доступ скомпрометированного аккаунта тоже прекращается
+func (s *Service) revokeAllAPIKeys(ctx context.Context, userID uuid.UUID) error {
+	if s.apiKeyRepo == nil {
+		return nil
+	}
+
+	hashes, err := s.apiKeyRepo.RevokeAllByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api keys: %w", err)
+	}
+
+	// При ошибке ключ продолжит работать до истечения TTL кэша
+	for _, keyHash := range hashes {
+		if err := s.apiKeyCache.Delete(ctx, keyHash); err != nil {
+			s.log.LogError("Failed to drop revoked api key from cache", err, zap.String("user_id", userID.String()))
+		}
+	}
+
+	if len(hashes) > 0 {
+		s.log.Info("All user API keys revoked",
+			zap.Bool("audit", true),
+			zap.String("user_id", userID.String()),
+			zap.Int("keys", len(hashes)),
+		)
+	}
+	return nil
+}
+
+// AuthenticateAPIKey возвращает пользователя ключа. Результат кэшируется на короткое время,
+// поэтому БД читается и last_used_at обновляется не чаще раза за TTL кэша
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (*domain.APIKeyIdentity, error) {
+	if s.apiKeyRepo == nil {
+		return nil, errors.ErrUnauthorized.WithMessage("api keys are not enabled")
+	}
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, errors.ErrInvalidToken.WithMessage("invalid api key")
+	}
+
+	keyHash := hashAPIKey(key)
+
+	identity, err := s.apiKeyCache.Get(ctx, keyHash)
+	if err != nil {
+		// Redis недоступен: проверяем ключ по БД
+		s.log.LogError("Failed to get api key from cache", err)
+	}
+	if identity != nil {
+		return identity, nil
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByHash(ctx, keyHash)
+	if err != nil {
+		if errors.IsAppError(err) && errors.GetAppError(err).Code == http.StatusNotFound {
+			return nil, errors.ErrInvalidToken.WithMessage("invalid api key")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key owner: %w", err)
+	}
+
+	identity = &domain.APIKeyIdentity{
+		KeyID:  apiKey.ID,
+		UserID: user.ID,
+		Role:   user.Role,
+		Scope:  apiKey.Scope,
+	}
+
+	if err := s.apiKeyCache.Set(ctx, keyHash, identity); err != nil {
+		s.log.LogError("Failed to cache api key", err)
+	}
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, time.Now()); err != nil {
+		s.log.LogError("Failed to update api key last used time", err, zap.String("key_id", apiKey.ID.String()))
+	}
+
+	return identity, nil
+}
+
+// hashAPIKey возвращает SHA-256 ключа. Ключ случайный и длинный, поэтому медленный хэш не нужен
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
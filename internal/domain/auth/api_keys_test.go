@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAPIKeyRepository keeps keys in memory and counts lookups
+type memoryAPIKeyRepository struct {
+	keys    map[string]*domain.APIKey
+	lookups int
+	touched int
+}
+
+func newMemoryAPIKeyRepository() *memoryAPIKeyRepository {
+	return &memoryAPIKeyRepository{keys: make(map[string]*domain.APIKey)}
+}
+
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	key.CreatedAt = time.Now()
+	r.keys[key.KeyHash] = key
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	for _, key := range r.keys {
+		if key.UserID == userID && key.RevokedAt == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *memoryAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	r.lookups++
+	key, ok := r.keys[keyHash]
+	if !ok || key.RevokedAt != nil {
+		return nil, errors.ErrNotFound
+	}
+	return key, nil
+}
+
+func (r *memoryAPIKeyRepository) Revoke(ctx context.Context, id, userID uuid.UUID) (string, error) {
+	for hash, key := range r.keys {
+		if key.ID == id && key.UserID == userID && key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+			return hash, nil
+		}
+	}
+	return "", errors.ErrNotFound
+}
+
+func (r *memoryAPIKeyRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var hashes []string
+	for hash, key := range r.keys {
+		if key.UserID == userID && key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes, nil
+}
+
+func (r *memoryAPIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.touched++
+	return nil
+}
+
+type memoryAPIKeyCache map[string]*domain.APIKeyIdentity
+
+func (c memoryAPIKeyCache) Get(ctx context.Context, keyHash string) (*domain.APIKeyIdentity, error) {
+	return c[keyHash], nil
+}
+
+func (c memoryAPIKeyCache) Set(ctx context.Context, keyHash string, identity *domain.APIKeyIdentity) error {
+	c[keyHash] = identity
+	return nil
+}
+
+func (c memoryAPIKeyCache) Delete(ctx context.Context, keyHash string) error {
+	delete(c, keyHash)
+	return nil
+}
+
+func newAPIKeyTestService(t *testing.T) (*Service, *MockUserRepository, *memoryAPIKeyRepository) {
+	service, userRepo, _ := newTestService(t)
+	repo := newMemoryAPIKeyRepository()
+	service.SetAPIKeys(repo, memoryAPIKeyCache{})
+	return service, userRepo, repo
+}
+
+func TestService_CreateAPIKey(t *testing.T) {
+	service, _, repo := newAPIKeyTestService(t)
+	userID := uuid.New()
+
+	created, err := service.CreateAPIKey(context.Background(), userID, &CreateAPIKeyRequest{Name: " ci ", Scope: domain.APIKeyScopeRead})
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	assert.Equal(t, "ci", created.Name)
+	// Only the hash is stored
+	require.Len(t, repo.keys, 1)
+	assert.Equal(t, hashAPIKey(created.Key), created.KeyHash)
+	assert.NotContains(t, repo.keys, created.Key)
+
+	_, err = service.CreateAPIKey(context.Background(), userID, &CreateAPIKeyRequest{Name: "ci", Scope: "admin"})
+	assert.Equal(t, http.StatusBadRequest, errors.GetAppError(err).Code)
+}
+
+func TestService_AuthenticateAPIKey(t *testing.T) {
+	service, userRepo, repo := newAPIKeyTestService(t)
+	user := &domain.User{ID: uuid.New(), Role: domain.RoleUser}
+	userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	ctx := context.Background()
+
+	created, err := service.CreateAPIKey(ctx, user.ID, &CreateAPIKeyRequest{Name: "ci", Scope: domain.APIKeyScopeReadWrite})
+	require.NoError(t, err)
+
+	t.Run("resolves the owner and caches the lookup", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			identity, err := service.AuthenticateAPIKey(ctx, created.Key)
+			require.NoError(t, err)
+			assert.Equal(t, user.ID, identity.UserID)
+			assert.Equal(t, domain.APIKeyScopeReadWrite, identity.Scope)
+		}
+		assert.Equal(t, 1, repo.lookups)
+		assert.Equal(t, 1, repo.touched)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := service.AuthenticateAPIKey(ctx, APIKeyPrefix+"unknown")
+		assert.Equal(t, http.StatusUnauthorized, errors.GetAppError(err).Code)
+	})
+
+	t.Run("revoked key stops working immediately", func(t *testing.T) {
+		require.NoError(t, service.RevokeAPIKey(ctx, user.ID, created.ID))
+
+		_, err := service.AuthenticateAPIKey(ctx, created.Key)
+		assert.Equal(t, http.StatusUnauthorized, errors.GetAppError(err).Code)
+	})
+
+	t.Run("key of another user cannot be revoked", func(t *testing.T) {
+		other, err := service.CreateAPIKey(ctx, user.ID, &CreateAPIKeyRequest{Name: "other", Scope: domain.APIKeyScopeRead})
+		require.NoError(t, err)
+
+		err = service.RevokeAPIKey(ctx, uuid.New(), other.ID)
+		assert.Equal(t, http.StatusNotFound, errors.GetAppError(err).Code)
+	})
+}
+
+func TestService_RevokeAllSessionsRevokesAPIKeys(t *testing.T) {
+	service, userRepo, blacklist := newTestService(t)
+	repo := newMemoryAPIKeyRepository()
+	cache := memoryAPIKeyCache{}
+	service.SetAPIKeys(repo, cache)
+
+	user := &domain.User{ID: uuid.New(), Role: domain.RoleUser}
+	other := &domain.User{ID: uuid.New(), Role: domain.RoleUser}
+	userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	userRepo.On("GetByID", mock.Anything, other.ID).Return(other, nil)
+	blacklist.On("RevokeUserTokens", mock.Anything, user.ID, mock.AnythingOfType("time.Time"), mock.Anything).Return(nil)
+	ctx := context.Background()
+
+	readWrite, err := service.CreateAPIKey(ctx, user.ID, &CreateAPIKeyRequest{Name: "ci", Scope: domain.APIKeyScopeReadWrite})
+	require.NoError(t, err)
+	read, err := service.CreateAPIKey(ctx, user.ID, &CreateAPIKeyRequest{Name: "poll", Scope: domain.APIKeyScopeRead})
+	require.NoError(t, err)
+	kept, err := service.CreateAPIKey(ctx, other.ID, &CreateAPIKeyRequest{Name: "other", Scope: domain.APIKeyScopeRead})
+	require.NoError(t, err)
+
+	// Warm the cache so revocation has to drop the cached identities too
+	for _, key := range []string{readWrite.Key, read.Key} {
+		_, err := service.AuthenticateAPIKey(ctx, key)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, service.RevokeAllSessions(ctx, user.ID))
+
+	for _, key := range []string{readWrite.Key, read.Key} {
+		_, err := service.AuthenticateAPIKey(ctx, key)
+		assert.Equal(t, http.StatusUnauthorized, errors.GetAppError(err).Code)
+	}
+	assert.Empty(t, cache)
+
+	identity, err := service.AuthenticateAPIKey(ctx, kept.Key)
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, identity.UserID, "other users keep their keys")
+}
+
+func TestService_APIKeysDisabled(t *testing.T) {
+	service, _, _ := newTestService(t)
+
+	_, err := service.AuthenticateAPIKey(context.Background(), APIKeyPrefix+"key")
+	assert.Equal(t, http.StatusUnauthorized, errors.GetAppError(err).Code)
+
+	_, err = service.CreateAPIKey(context.Background(), uuid.New(), &CreateAPIKeyRequest{Name: "ci", Scope: domain.APIKeyScopeRead})
+	assert.Equal(t, http.StatusServiceUnavailable, errors.GetAppError(err).Code)
+}
@@ -10,11 +10,30 @@ import (
 
 // Claims - JWT claims с дополнительными полями
 type Claims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Username string    `json:"username"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Username       string     `json:"username"`
+	ImpersonatedBy *uuid.UUID `json:"impersonated_by,omitempty"` // Админ, выпустивший токен от имени пользователя
+	IssuedAtNano   int64      `json:"iat_ns,omitempty"`          // Время выпуска в наносекундах: iat хранит только секунды
 	jwt.RegisteredClaims
 }
 
+// Issued возвращает точное время выпуска токена.
+// У токенов без iat_ns - время с точностью до секунды, без времени выпуска - нулевое время
+func (c *Claims) Issued() time.Time {
+	if c.IssuedAtNano != 0 {
+		return time.Unix(0, c.IssuedAtNano)
+	}
+	if c.IssuedAt != nil {
+		return c.IssuedAt.Time
+	}
+	return time.Time{}
+}
+
+// IsImpersonation проверяет, что токен выпущен админом от имени пользователя
+func (c *Claims) IsImpersonation() bool {
+	return c.ImpersonatedBy != nil
+}
+
 // JWTManager управляет JWT токенами
 type JWTManager struct {
 	secretKey  []byte
@@ -35,8 +54,9 @@ func NewJWTManager(secretKey string, accessTTL, refreshTTL time.Duration) *JWTMa
 func (jm *JWTManager) GenerateAccessToken(userID uuid.UUID, username string) (string, error) {
 	now := time.Now()
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:       userID,
+		Username:     username,
+		IssuedAtNano: now.UnixNano(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(jm.accessTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -49,15 +69,45 @@ func (jm *JWTManager) GenerateAccessToken(userID uuid.UUID, username string) (st
 	return token.SignedString(jm.secretKey)
 }
 
+// GenerateImpersonationToken генерирует короткоживущий access token пользователя userID
+// для админа adminID. Refresh token для такого токена не выпускается
+func (jm *JWTManager) GenerateImpersonationToken(userID uuid.UUID, username string, adminID uuid.UUID, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := &Claims{
+		UserID:         userID,
+		Username:       username,
+		ImpersonatedBy: &adminID,
+		IssuedAtNano:   now.UnixNano(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   userID.String(),
+			ID:        uuid.New().String(), // Уникальный ID, чтобы каждый токен можно было отозвать отдельно
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(jm.secretKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
 // GenerateRefreshToken генерирует refresh token
 func (jm *JWTManager) GenerateRefreshToken(userID uuid.UUID) (string, error) {
 	now := time.Now()
-	claims := &jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(now.Add(jm.refreshTTL)),
-		IssuedAt:  jwt.NewNumericDate(now),
-		NotBefore: jwt.NewNumericDate(now),
-		Subject:   userID.String(),
-		ID:        uuid.New().String(), // Уникальный ID для refresh token
+	claims := &Claims{
+		IssuedAtNano: now.UnixNano(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(jm.refreshTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   userID.String(),
+			ID:        uuid.New().String(), // Уникальный ID для refresh token
+		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -88,7 +138,7 @@ func (jm *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 
 // ValidateRefreshToken валидирует refresh token
 func (jm *JWTManager) ValidateRefreshToken(tokenString string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
@@ -99,11 +149,16 @@ func (jm *JWTManager) ValidateRefreshToken(tokenString string) (uuid.UUID, error
 		return uuid.Nil, fmt.Errorf("invalid refresh token: %w", err)
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
 		return uuid.Nil, fmt.Errorf("invalid refresh token claims")
 	}
 
+	// Сессия от имени пользователя не продлевается
+	if claims.IsImpersonation() {
+		return uuid.Nil, fmt.Errorf("impersonation token cannot be refreshed")
+	}
+
 	userID, err := uuid.Parse(claims.Subject)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("invalid user id in token: %w", err)
@@ -127,6 +182,21 @@ func (jm *JWTManager) ExtractUserID(tokenString string) (uuid.UUID, error) {
 	return claims.UserID, nil
 }
 
+// ExtractIssuedAt извлекает время выпуска токена без полной валидации
+func (jm *JWTManager) ExtractIssuedAt(tokenString string) (time.Time, error) {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid token claims")
+	}
+
+	return claims.Issued(), nil
+}
+
 // RefreshTokenTTL возвращает время жизни refresh token
 func (jm *JWTManager) RefreshTokenTTL() time.Duration {
 	return jm.refreshTTL
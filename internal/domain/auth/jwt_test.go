@@ -227,10 +227,15 @@ func TestJWTManager_TokensSameUserSameSecond(t *testing.T) {
 	token2, err := manager.GenerateAccessToken(userID, "testuser")
 	require.NoError(t, err)
 
-	// Tokens generated in the same second with same user data will be identical
-	// This is expected behavior for JWT - uniqueness comes from refresh tokens
-	// which have unique JTI (JWT ID)
-	assert.Equal(t, token1, token2)
+	// iat_ns keeps the exact issue time, so tokens from the same second still differ
+	// and revoking sessions can tell them apart from ones issued before the revocation
+	assert.NotEqual(t, token1, token2)
+
+	claims1, err := manager.ValidateToken(token1)
+	require.NoError(t, err)
+	claims2, err := manager.ValidateToken(token2)
+	require.NoError(t, err)
+	assert.True(t, claims1.Issued().Before(claims2.Issued()))
 }
 
 func TestJWTManager_RefreshTokensHaveUniqueJTI(t *testing.T) {
@@ -268,3 +273,34 @@ func TestJWTManager_DifferentUsers(t *testing.T) {
 	assert.NotEqual(t, claims1.UserID, claims2.UserID)
 	assert.NotEqual(t, claims1.Username, claims2.Username)
 }
+
+func TestJWTManager_ImpersonationToken(t *testing.T) {
+	manager := NewJWTManager("test-secret", 15*time.Minute, 7*24*time.Hour)
+	userID, adminID := uuid.New(), uuid.New()
+
+	token, expiresAt, err := manager.GenerateImpersonationToken(userID, "student", adminID, 5*time.Minute)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), expiresAt, time.Second)
+
+	claims, err := manager.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, userID, claims.UserID)
+	assert.True(t, claims.IsImpersonation())
+	assert.Equal(t, adminID, *claims.ImpersonatedBy)
+	assert.NotEmpty(t, claims.ID)
+
+	// Impersonation tokens must not be usable to obtain a new session
+	_, err = manager.ValidateRefreshToken(token)
+	assert.Error(t, err)
+}
+
+func TestJWTManager_AccessTokenIsNotImpersonation(t *testing.T) {
+	manager := NewJWTManager("test-secret", 15*time.Minute, 7*24*time.Hour)
+
+	token, err := manager.GenerateAccessToken(uuid.New(), "student")
+	require.NoError(t, err)
+
+	claims, err := manager.ValidateToken(token)
+	require.NoError(t, err)
+	assert.False(t, claims.IsImpersonation())
+}
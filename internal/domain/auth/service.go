@@ -27,6 +27,8 @@ type UserRepository interface {
 type TokenBlacklist interface {
 	Add(ctx context.Context, token string, ttl time.Duration) error
 	IsBlacklisted(ctx context.Context, token string) (bool, error)
+	RevokeUserTokens(ctx context.Context, userID uuid.UUID, issuedBefore time.Time, ttl time.Duration) error
+	UserTokensRevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, bool, error)
 }
 
 // Service - сервис аутентификации
@@ -35,6 +37,12 @@ type Service struct {
 	jwtManager     *JWTManager
 	tokenBlacklist TokenBlacklist
 	log            *logger.Logger
+
+	apiKeyRepo  APIKeyRepository
+	apiKeyCache APIKeyCache
+
+	impersonationEnabled bool
+	impersonationTTL     time.Duration
 }
 
 // NewService создаёт новый сервис аутентификации
@@ -47,6 +55,13 @@ func NewService(userRepo UserRepository, jwtManager *JWTManager, tokenBlacklist
 	}
 }
 
+// SetImpersonation включает выпуск админами токенов от имени пользователей.
+// Пока выключено, ранее выпущенные токены impersonation не принимаются
+func (s *Service) SetImpersonation(enabled bool, ttl time.Duration) {
+	s.impersonationEnabled = enabled
+	s.impersonationTTL = ttl
+}
+
 // RegisterRequest - запрос на регистрацию
 type RegisterRequest struct {
 	Username string `json:"username"`
@@ -217,6 +232,21 @@ func (s *Service) RefreshTokens(ctx context.Context, refreshToken string) (*Auth
 		return nil, errors.ErrInvalidToken.WithError(err)
 	}
 
+	// Токен мог быть выпущен до отзыва всех сессий пользователя
+	issuedAt, err := s.jwtManager.ExtractIssuedAt(refreshToken)
+	if err != nil {
+		return nil, errors.ErrInvalidToken.WithError(err)
+	}
+	revoked, err := s.sessionRevoked(ctx, userID, issuedAt)
+	if err != nil {
+		s.log.LogError("Failed to check user sessions revocation", err)
+		// Продолжаем, но логируем ошибку
+	}
+	if revoked {
+		s.log.Warn("Attempt to use refresh token of revoked session", zap.String("user_id", userID.String()))
+		return nil, errors.ErrInvalidToken.WithMessage("refresh token has been revoked")
+	}
+
 	// Получаем пользователя
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
@@ -288,6 +318,57 @@ func (s *Service) Logout(ctx context.Context, accessToken, refreshToken string)
 	return nil
 }
 
+// ImpersonationResponse - токен для действий от имени пользователя
+type ImpersonationResponse struct {
+	AccessToken    string       `json:"access_token"`
+	ExpiresAt      time.Time    `json:"expires_at"`
+	ImpersonatedBy uuid.UUID    `json:"impersonated_by"`
+	User           *domain.User `json:"user"`
+}
+
+// Impersonate выпускает админу короткоживущий access token от имени пользователя
+// для воспроизведения его проблем. Токен нельзя продлить, отзывается через logout
+func (s *Service) Impersonate(ctx context.Context, adminID, userID uuid.UUID) (*ImpersonationResponse, error) {
+	if !s.impersonationEnabled {
+		return nil, errors.ErrForbidden.WithMessage("impersonation is disabled")
+	}
+	if adminID == userID {
+		return nil, errors.ErrInvalidInput.WithMessage("cannot impersonate yourself")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Токен от имени админа дал бы доступ к админским действиям без аудита их автора
+	if user.Role == domain.RoleAdmin {
+		return nil, errors.ErrForbidden.WithMessage("cannot impersonate an admin")
+	}
+
+	token, expiresAt, err := s.jwtManager.GenerateImpersonationToken(user.ID, user.Username, adminID, s.impersonationTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate impersonation token: %w", err)
+	}
+
+	s.log.Info("Impersonation token issued",
+		zap.Bool("audit", true),
+		zap.String("user_id", user.ID.String()),
+		zap.String("impersonated_by", adminID.String()),
+		zap.Time("expires_at", expiresAt),
+	)
+
+	// Скрываем пароль
+	user.PasswordHash = ""
+
+	return &ImpersonationResponse{
+		AccessToken:    token,
+		ExpiresAt:      expiresAt,
+		ImpersonatedBy: adminID,
+		User:           user,
+	}, nil
+}
+
 // UpdateProfile обновляет профиль пользователя
 func (s *Service) UpdateProfile(ctx context.Context, userID string, req *UpdateProfileRequest) (*domain.User, error) {
 	id, err := uuid.Parse(userID)
@@ -334,19 +415,78 @@ func (s *Service) UpdateProfile(ctx context.Context, userID string, req *UpdateP
 	return user, nil
 }
 
+// RevokeAllSessions отзывает все выпущенные пользователю токены и API ключи, например при компрометации
+// аккаунта. Время выпуска сравнивается с точностью до наносекунды, поэтому токены входа
+// сразу после отзыва действительны
+func (s *Service) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	// Метка должна пережить самый долгоживущий из уже выпущенных токенов
+	ttl := s.jwtManager.RefreshTokenTTL()
+	if access := s.jwtManager.AccessTokenTTL(); access > ttl {
+		ttl = access
+	}
+
+	if err := s.tokenBlacklist.RevokeUserTokens(ctx, userID, time.Now(), ttl); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := s.revokeAllAPIKeys(ctx, userID); err != nil {
+		return err
+	}
+
+	s.log.Info("All user sessions revoked",
+		zap.Bool("audit", true),
+		zap.String("user_id", userID.String()),
+	)
+
+	return nil
+}
+
 // IsTokenBlacklisted проверяет, находится ли токен в чёрном списке
+// или выпущен до отзыва всех сессий пользователя
 func (s *Service) IsTokenBlacklisted(ctx context.Context, token string) (bool, error) {
-	return s.tokenBlacklist.IsBlacklisted(ctx, token)
+	blacklisted, err := s.tokenBlacklist.IsBlacklisted(ctx, token)
+	if err != nil || blacklisted {
+		return blacklisted, err
+	}
+
+	claims, err := s.jwtManager.ValidateToken(token)
+	if err != nil {
+		// Невалидный токен отклоняется при валидации, здесь проверяется только отзыв
+		return false, nil
+	}
+
+	return s.sessionRevoked(ctx, claims.UserID, claims.Issued())
+}
+
+// sessionRevoked проверяет, выпущен ли токен пользователя до отзыва всех его сессий.
+// Токен без времени выпуска считается отозванным
+func (s *Service) sessionRevoked(ctx context.Context, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	revokedAt, ok, err := s.tokenBlacklist.UserTokensRevokedBefore(ctx, userID)
+	if err != nil || !ok {
+		return false, err
+	}
+	return issuedAt.Before(revokedAt), nil
 }
 
 // ValidateToken валидирует access token
 func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
-	return s.jwtManager.ValidateToken(tokenString)
+	claims, err := s.jwtManager.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.IsImpersonation() && !s.impersonationEnabled {
+		return nil, fmt.Errorf("impersonation is disabled")
+	}
+	return claims, nil
 }
 
 // GetUserByToken получает пользователя по токену
 func (s *Service) GetUserByToken(ctx context.Context, tokenString string) (*domain.User, error) {
-	claims, err := s.jwtManager.ValidateToken(tokenString)
+	claims, err := s.ValidateToken(tokenString)
 	if err != nil {
 		return nil, errors.ErrInvalidToken.WithError(err)
 	}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -74,6 +75,16 @@ func (m *MockTokenBlacklist) IsBlacklisted(ctx context.Context, token string) (b
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockTokenBlacklist) RevokeUserTokens(ctx context.Context, userID uuid.UUID, issuedBefore time.Time, ttl time.Duration) error {
+	args := m.Called(ctx, userID, issuedBefore, ttl)
+	return args.Error(0)
+}
+
+func (m *MockTokenBlacklist) UserTokensRevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(time.Time), args.Bool(1), args.Error(2)
+}
+
 func newTestService(t *testing.T) (*Service, *MockUserRepository, *MockTokenBlacklist) {
 	userRepo := new(MockUserRepository)
 	blacklist := new(MockTokenBlacklist)
@@ -245,6 +256,7 @@ func TestService_RefreshTokens_Success(t *testing.T) {
 	require.NoError(t, err)
 
 	blacklist.On("IsBlacklisted", ctx, refreshToken).Return(false, nil)
+	blacklist.On("UserTokensRevokedBefore", ctx, userID).Return(time.Time{}, false, nil)
 	userRepo.On("GetByID", ctx, userID).Return(user, nil)
 	blacklist.On("Add", ctx, refreshToken, mock.AnythingOfType("time.Duration")).Return(nil)
 
@@ -478,3 +490,146 @@ func TestService_comparePassword(t *testing.T) {
 	err = service.comparePassword(string(hash), "wrongpassword")
 	assert.Error(t, err)
 }
+
+func TestService_Impersonate(t *testing.T) {
+	adminID := uuid.New()
+	student := &domain.User{ID: uuid.New(), Username: "student", Role: domain.RoleUser, PasswordHash: "hash"}
+
+	t.Run("issues a short-lived token", func(t *testing.T) {
+		service, userRepo, _ := newTestService(t)
+		service.SetImpersonation(true, 10*time.Minute)
+		userRepo.On("GetByID", mock.Anything, student.ID).Return(&domain.User{
+			ID: student.ID, Username: student.Username, Role: student.Role, PasswordHash: student.PasswordHash,
+		}, nil)
+
+		resp, err := service.Impersonate(context.Background(), adminID, student.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, adminID, resp.ImpersonatedBy)
+		assert.Empty(t, resp.User.PasswordHash)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), resp.ExpiresAt, time.Second)
+
+		claims, err := service.ValidateToken(resp.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, student.ID, claims.UserID)
+		assert.Equal(t, adminID, *claims.ImpersonatedBy)
+	})
+
+	t.Run("disabled by config", func(t *testing.T) {
+		service, userRepo, _ := newTestService(t)
+
+		_, err := service.Impersonate(context.Background(), adminID, student.ID)
+
+		assert.Equal(t, http.StatusForbidden, errors.GetAppError(err).Code)
+		userRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("admins cannot be impersonated", func(t *testing.T) {
+		service, userRepo, _ := newTestService(t)
+		service.SetImpersonation(true, 10*time.Minute)
+		otherAdmin := &domain.User{ID: uuid.New(), Role: domain.RoleAdmin}
+		userRepo.On("GetByID", mock.Anything, otherAdmin.ID).Return(otherAdmin, nil)
+
+		_, err := service.Impersonate(context.Background(), adminID, otherAdmin.ID)
+
+		assert.Equal(t, http.StatusForbidden, errors.GetAppError(err).Code)
+	})
+
+	t.Run("issued tokens stop working when the feature is disabled", func(t *testing.T) {
+		service, _, _ := newTestService(t)
+		token, _, err := service.jwtManager.GenerateImpersonationToken(student.ID, student.Username, adminID, time.Minute)
+		require.NoError(t, err)
+
+		_, err = service.ValidateToken(token)
+		assert.Error(t, err)
+	})
+
+	t.Run("cannot be refreshed", func(t *testing.T) {
+		service, _, blacklist := newTestService(t)
+		service.SetImpersonation(true, 10*time.Minute)
+		token, _, err := service.jwtManager.GenerateImpersonationToken(student.ID, student.Username, adminID, time.Minute)
+		require.NoError(t, err)
+		blacklist.On("IsBlacklisted", mock.Anything, token).Return(false, nil)
+
+		_, err = service.RefreshTokens(context.Background(), token)
+
+		assert.Error(t, err)
+		blacklist.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_RevokeAllSessions(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Username: "student", Role: domain.RoleUser}
+
+	t.Run("tokens issued before revocation are rejected", func(t *testing.T) {
+		service, userRepo, blacklist := newTestService(t)
+		ctx := context.Background()
+
+		accessToken, err := service.jwtManager.GenerateAccessToken(user.ID, user.Username)
+		require.NoError(t, err)
+		refreshToken, err := service.jwtManager.GenerateRefreshToken(user.ID)
+		require.NoError(t, err)
+
+		var revokedAt time.Time
+		userRepo.On("GetByID", ctx, user.ID).Return(user, nil)
+		blacklist.On("RevokeUserTokens", ctx, user.ID, mock.AnythingOfType("time.Time"), 7*24*time.Hour).
+			Run(func(args mock.Arguments) { revokedAt = args.Get(2).(time.Time) }).
+			Return(nil)
+
+		require.NoError(t, service.RevokeAllSessions(ctx, user.ID))
+
+		blacklist.On("IsBlacklisted", ctx, mock.Anything).Return(false, nil)
+		blacklist.On("UserTokensRevokedBefore", ctx, user.ID).Return(revokedAt, true, nil)
+
+		revoked, err := service.IsTokenBlacklisted(ctx, accessToken)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		_, err = service.RefreshTokens(ctx, refreshToken)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "revoked")
+		blacklist.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("tokens issued right after revocation are accepted", func(t *testing.T) {
+		service, userRepo, blacklist := newTestService(t)
+		ctx := context.Background()
+
+		var revokedAt time.Time
+		userRepo.On("GetByID", ctx, user.ID).Return(&domain.User{ID: user.ID, Username: user.Username}, nil)
+		blacklist.On("RevokeUserTokens", ctx, user.ID, mock.AnythingOfType("time.Time"), 7*24*time.Hour).
+			Run(func(args mock.Arguments) { revokedAt = args.Get(2).(time.Time) }).
+			Return(nil)
+
+		require.NoError(t, service.RevokeAllSessions(ctx, user.ID))
+
+		blacklist.On("IsBlacklisted", ctx, mock.Anything).Return(false, nil)
+		blacklist.On("UserTokensRevokedBefore", ctx, user.ID).Return(revokedAt, true, nil)
+		blacklist.On("Add", ctx, mock.Anything, mock.Anything).Return(nil)
+
+		// The user logs in again straight away, within the same second as the revocation
+		accessToken, err := service.jwtManager.GenerateAccessToken(user.ID, user.Username)
+		require.NoError(t, err)
+		refreshToken, err := service.jwtManager.GenerateRefreshToken(user.ID)
+		require.NoError(t, err)
+
+		revoked, err := service.IsTokenBlacklisted(ctx, accessToken)
+		require.NoError(t, err)
+		assert.False(t, revoked)
+
+		resp, err := service.RefreshTokens(ctx, refreshToken)
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		service, userRepo, blacklist := newTestService(t)
+		userID := uuid.New()
+		userRepo.On("GetByID", mock.Anything, userID).Return(nil, errors.ErrNotFound)
+
+		err := service.RevokeAllSessions(context.Background(), userID)
+
+		assert.True(t, errors.IsNotFound(err))
+		blacklist.AssertNotCalled(t, "RevokeUserTokens", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
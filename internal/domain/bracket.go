@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/bmstu-itstech/tjudge/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// TournamentFormat - формат проведения турнира
+type TournamentFormat string
+
+const (
+	FormatRoundRobin  TournamentFormat = "round_robin" // Каждый с каждым (по умолчанию)
+	FormatElimination TournamentFormat = "elimination" // Олимпийская система: проигравший выбывает
+)
+
+// BracketSeeding - способ посева участников в сетку
+type BracketSeeding string
+
+const (
+	SeedingRating BracketSeeding = "rating" // По текущему рейтингу (по умолчанию)
+	SeedingRandom BracketSeeding = "random" // Случайно
+)
+
+// BracketDrawRule - правило для ничьей в матче сетки
+type BracketDrawRule string
+
+const (
+	DrawHigherSeed BracketDrawRule = "higher_seed" // Проходит участник с более высоким посевом (по умолчанию)
+	DrawRematch    BracketDrawRule = "rematch"     // Переигровка, после MaxBracketRematches - по посеву
+)
+
+// MaxBracketRematches максимальное число переигровок одной пары
+const MaxBracketRematches = 3
+
+// Ключи метаданных турнира для формата на выбывание
+const (
+	MetaFormat         = "format"
+	MetaBracketSeeding = "bracket_seeding"
+	MetaBracketDraw    = "bracket_draw"
+)
+
+// Format возвращает формат турнира из метаданных
+func (t *Tournament) Format() TournamentFormat {
+	if format, ok := t.Metadata[MetaFormat].(string); ok && format != "" {
+		return TournamentFormat(format)
+	}
+	return FormatRoundRobin
+}
+
+// IsElimination проверяет, что турнир проводится по олимпийской системе
+func (t *Tournament) IsElimination() bool {
+	return t.Format() == FormatElimination
+}
+
+// BracketSeeding возвращает способ посева из метаданных
+func (t *Tournament) BracketSeeding() BracketSeeding {
+	if seeding, ok := t.Metadata[MetaBracketSeeding].(string); ok && seeding != "" {
+		return BracketSeeding(seeding)
+	}
+	return SeedingRating
+}
+
+// BracketDrawRule возвращает правило для ничьей из метаданных
+func (t *Tournament) BracketDrawRule() BracketDrawRule {
+	if rule, ok := t.Metadata[MetaBracketDraw].(string); ok && rule != "" {
+		return BracketDrawRule(rule)
+	}
+	return DrawHigherSeed
+}
+
+// validateBracketSettings проверяет настройки формата в метаданных
+func (t *Tournament) validateBracketSettings(errs *validator.ValidationErrors) {
+	check := func(key string, allowed ...string) {
+		value, ok := t.Metadata[key]
+		if !ok || value == nil {
+			return
+		}
+		s, isString := value.(string)
+		if !isString {
+			errs.Add("metadata."+key, fmt.Sprintf("%s must be a string", key))
+			return
+		}
+		for _, a := range allowed {
+			if s == a {
+				return
+			}
+		}
+		errs.Add("metadata."+key, fmt.Sprintf("unknown %s: %s", key, s))
+	}
+
+	check(MetaFormat, string(FormatRoundRobin), string(FormatElimination))
+	check(MetaBracketSeeding, string(SeedingRating), string(SeedingRandom))
+	check(MetaBracketDraw, string(DrawHigherSeed), string(DrawRematch))
+}
+
+// BracketSlot - позиция сетки: пара участников раунда и её текущий матч.
+// Победитель позиции position раунда round выходит в позицию position/2 раунда round+1
+// (чётные позиции - первым участником, нечётные - вторым)
+type BracketSlot struct {
+	TournamentID uuid.UUID  `json:"-" db:"tournament_id"`
+	Round        int        `json:"round" db:"round"`
+	Position     int        `json:"position" db:"position"`
+	Program1ID   *uuid.UUID `json:"program1_id,omitempty" db:"program1_id"`
+	Program2ID   *uuid.UUID `json:"program2_id,omitempty" db:"program2_id"`
+	Seed1        *int       `json:"seed1,omitempty" db:"seed1"`
+	Seed2        *int       `json:"seed2,omitempty" db:"seed2"`
+	MatchID      *uuid.UUID `json:"match_id,omitempty" db:"match_id"`
+	WinnerID     *uuid.UUID `json:"winner_id,omitempty" db:"winner_id"`
+	Rematches    int        `json:"rematches" db:"rematches"`
+	IsBye        bool       `json:"is_bye" db:"is_bye"`
+
+	// Состояние текущего матча (только для чтения)
+	MatchStatus *MatchStatus `json:"match_status,omitempty" db:"match_status"`
+	Score1      *int         `json:"score1,omitempty" db:"score1"`
+	Score2      *int         `json:"score2,omitempty" db:"score2"`
+}
+
+// IsReady проверяет, что оба участника известны, а матч ещё не создан
+func (s *BracketSlot) IsReady() bool {
+	return s.Program1ID != nil && s.Program2ID != nil && s.MatchID == nil && s.WinnerID == nil
+}
+
+// Entrant возвращает программу и посев участника side (1 или 2)
+func (s *BracketSlot) Entrant(side int) (*uuid.UUID, *int) {
+	if side == 1 {
+		return s.Program1ID, s.Seed1
+	}
+	return s.Program2ID, s.Seed2
+}
+
+// Bracket - сетка турнира на выбывание для отображения
+type Bracket struct {
+	TournamentID uuid.UUID        `json:"tournament_id"`
+	Size         int              `json:"size"` // Число мест в первом раунде (степень двойки)
+	Seeding      BracketSeeding   `json:"seeding"`
+	DrawRule     BracketDrawRule  `json:"draw_rule"`
+	ChampionID   *uuid.UUID       `json:"champion_id,omitempty"`
+	Rounds       [][]*BracketSlot `json:"rounds"` // Rounds[0] - первый раунд, последний - финал
+}
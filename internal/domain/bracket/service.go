@@ -0,0 +1,456 @@
+package bracket
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Repository интерфейс для хранения сеток
+type Repository interface {
+	CreateSlots(ctx context.Context, slots []*domain.BracketSlot) error
+	GetByTournament(ctx context.Context, tournamentID uuid.UUID) ([]*domain.BracketSlot, error)
+	GetByMatchID(ctx context.Context, matchID uuid.UUID) (*domain.BracketSlot, error)
+	SetMatch(ctx context.Context, tournamentID uuid.UUID, round, position int, previous *uuid.UUID, matchID uuid.UUID) error
+	SetWinner(ctx context.Context, tournamentID uuid.UUID, round, position int, matchID, winnerID uuid.UUID) (bool, error)
+	SetEntrant(ctx context.Context, tournamentID uuid.UUID, round, position, side int, programID uuid.UUID, seed *int) (*domain.BracketSlot, error)
+}
+
+// TournamentRepository интерфейс для работы с турнирами
+type TournamentRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error)
+	Update(ctx context.Context, tournament *domain.Tournament) error
+	GetLatestParticipants(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TournamentParticipant, error)
+}
+
+// MatchRepository интерфейс для создания матчей сетки
+type MatchRepository interface {
+	CreateBatch(ctx context.Context, matches []*domain.Match) error
+}
+
+// QueueManager интерфейс для постановки матчей в очередь
+type QueueManager interface {
+	Enqueue(ctx context.Context, match *domain.Match) error
+}
+
+// TournamentCache интерфейс для сброса кэша турнира после определения чемпиона
+type TournamentCache interface {
+	Invalidate(ctx context.Context, tournamentID uuid.UUID) error
+}
+
+// Notifier интерфейс уведомлений о завершении турнира (участникам по email, на webhook'и турнира)
+type Notifier interface {
+	TournamentCompleted(ctx context.Context, tournament *domain.Tournament) error
+}
+
+// Service - сервис сеток турниров на выбывание
+type Service struct {
+	repo            Repository
+	tournamentRepo  TournamentRepository
+	matchRepo       MatchRepository
+	queueManager    QueueManager
+	tournamentCache TournamentCache
+	notifiers       []Notifier
+	log             *logger.Logger
+}
+
+// NewService создаёт новый сервис сеток
+func NewService(
+	repo Repository,
+	tournamentRepo TournamentRepository,
+	matchRepo MatchRepository,
+	queueManager QueueManager,
+	tournamentCache TournamentCache,
+	log *logger.Logger,
+) *Service {
+	return &Service{
+		repo:            repo,
+		tournamentRepo:  tournamentRepo,
+		matchRepo:       matchRepo,
+		queueManager:    queueManager,
+		tournamentCache: tournamentCache,
+		log:             log,
+	}
+}
+
+// AddNotifier добавляет получателя события о завершении турнира после финала
+func (s *Service) AddNotifier(notifier Notifier) {
+	s.notifiers = append(s.notifiers, notifier)
+}
+
+// Seed рассаживает участников турнира по сетке и создаёт матчи первого раунда.
+// Участники без соперника (если их число не степень двойки) проходят дальше без игры.
+// Возвращает число созданных матчей
+func (s *Service) Seed(ctx context.Context, tournament *domain.Tournament) (int, error) {
+	existing, err := s.repo.GetByTournament(ctx, tournament.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) > 0 {
+		return 0, errors.ErrConflict.WithMessage("bracket already exists")
+	}
+
+	participants, err := s.tournamentRepo.GetLatestParticipants(ctx, tournament.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(participants) < 2 {
+		return 0, errors.ErrValidation.WithMessage("elimination tournament requires at least 2 participants")
+	}
+
+	programs := seedParticipants(participants, tournament.BracketSeeding())
+	slots := buildSlots(tournament.ID, programs)
+
+	var matches []*domain.Match
+	for _, slot := range slots {
+		if slot.IsReady() {
+			match := newMatch(tournament, slot)
+			slot.MatchID = &match.ID
+			matches = append(matches, match)
+		}
+	}
+
+	// Сначала позиции: воркер, завершивший матч, должен найти его в сетке
+	if err := s.repo.CreateSlots(ctx, slots); err != nil {
+		return 0, err
+	}
+	if err := s.matchRepo.CreateBatch(ctx, matches); err != nil {
+		return 0, err
+	}
+	s.enqueue(ctx, matches...)
+
+	s.log.Info("Bracket seeded",
+		zap.String("tournament_id", tournament.ID.String()),
+		zap.Int("participants", len(programs)),
+		zap.Int("size", roundSize(slots, 1)*2),
+		zap.Int("matches", len(matches)),
+	)
+
+	return len(matches), nil
+}
+
+// Advance продвигает победителя завершённого матча сетки в следующий раунд.
+// Матчи вне сетки игнорируются. Ничья решается правилом турнира: переигровкой
+// или проходом участника с более высоким посевом. Матч, завершившийся ошибкой без
+// победителя, ждёт повторного запуска
+func (s *Service) Advance(ctx context.Context, match *domain.Match, result *domain.MatchResult) error {
+	slot, err := s.repo.GetByMatchID(ctx, match.ID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	tournament, err := s.tournamentRepo.GetByID(ctx, match.TournamentID)
+	if err != nil {
+		return err
+	}
+
+	var winnerSide int
+	switch {
+	case result.Winner == 1 || result.Winner == 2:
+		winnerSide = result.Winner
+	case result.ErrorCode != 0:
+		s.log.Warn("Bracket match failed without a winner, waiting for retry",
+			zap.String("match_id", match.ID.String()),
+			zap.Int("error_code", result.ErrorCode),
+		)
+		return nil
+	case tournament.BracketDrawRule() == domain.DrawRematch && slot.Rematches < domain.MaxBracketRematches:
+		return s.rematch(ctx, tournament, slot)
+	default:
+		winnerSide = higherSeed(slot)
+	}
+
+	winnerID, seed := slot.Entrant(winnerSide)
+	if winnerID == nil {
+		return errors.ErrInternal.WithMessage("bracket slot has no entrant for the winner")
+	}
+
+	ok, err := s.repo.SetWinner(ctx, tournament.ID, slot.Round, slot.Position, match.ID, *winnerID)
+	if err != nil || !ok {
+		// Победитель уже записан повторным выполнением матча
+		return err
+	}
+
+	s.log.Info("Bracket winner advanced",
+		zap.String("tournament_id", tournament.ID.String()),
+		zap.String("match_id", match.ID.String()),
+		zap.Int("round", slot.Round),
+		zap.Int("position", slot.Position),
+		zap.String("winner_id", winnerID.String()),
+	)
+
+	return s.promote(ctx, tournament, slot, *winnerID, seed)
+}
+
+// promote выводит победителя позиции в следующий раунд, а победителя финала объявляет чемпионом
+func (s *Service) promote(ctx context.Context, tournament *domain.Tournament, slot *domain.BracketSlot, winnerID uuid.UUID, seed *int) error {
+	next, err := s.repo.SetEntrant(ctx, tournament.ID, slot.Round+1, slot.Position/2, slot.Position%2+1, winnerID, seed)
+	if errors.IsNotFound(err) {
+		return s.crown(ctx, tournament, winnerID)
+	}
+	if err != nil {
+		return err
+	}
+	if !next.IsReady() {
+		return nil
+	}
+
+	match := newMatch(tournament, next)
+	if err := s.repo.SetMatch(ctx, tournament.ID, next.Round, next.Position, nil, match.ID); err != nil {
+		if errors.IsConflict(err) {
+			return nil
+		}
+		return err
+	}
+	return s.createMatches(ctx, match)
+}
+
+// rematch назначает паре новый матч после ничьей
+func (s *Service) rematch(ctx context.Context, tournament *domain.Tournament, slot *domain.BracketSlot) error {
+	match := newMatch(tournament, slot)
+	if err := s.repo.SetMatch(ctx, tournament.ID, slot.Round, slot.Position, slot.MatchID, match.ID); err != nil {
+		if errors.IsConflict(err) {
+			return nil
+		}
+		return err
+	}
+
+	s.log.Info("Bracket match drawn, rematch scheduled",
+		zap.String("tournament_id", tournament.ID.String()),
+		zap.Int("round", slot.Round),
+		zap.Int("position", slot.Position),
+		zap.Int("rematch", slot.Rematches+1),
+	)
+
+	return s.createMatches(ctx, match)
+}
+
+// crown завершает турнир после финала
+func (s *Service) crown(ctx context.Context, tournament *domain.Tournament, championID uuid.UUID) error {
+	s.log.Info("Bracket champion determined",
+		zap.String("tournament_id", tournament.ID.String()),
+		zap.String("champion_id", championID.String()),
+	)
+
+	if tournament.Status != domain.TournamentActive {
+		return nil
+	}
+
+	now := time.Now()
+	tournament.Status = domain.TournamentCompleted
+	tournament.EndTime = &now
+	if err := s.tournamentRepo.Update(ctx, tournament); err != nil {
+		return err
+	}
+
+	if s.tournamentCache != nil {
+		_ = s.tournamentCache.Invalidate(ctx, tournament.ID)
+	}
+
+	// Турнир уже завершён, поэтому ошибки уведомлений только логируются
+	for _, notifier := range s.notifiers {
+		if err := notifier.TournamentCompleted(ctx, tournament); err != nil {
+			s.log.LogError("Failed to enqueue tournament notification", err,
+				zap.String("tournament_id", tournament.ID.String()),
+			)
+		}
+	}
+	return nil
+}
+
+// Get возвращает сетку турнира по раундам
+func (s *Service) Get(ctx context.Context, tournamentID uuid.UUID) (*domain.Bracket, error) {
+	tournament, err := s.tournamentRepo.GetByID(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.repo.GetByTournament(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(slots) == 0 {
+		return nil, errors.ErrNotFound.WithMessage("bracket not found")
+	}
+
+	bracket := &domain.Bracket{
+		TournamentID: tournamentID,
+		Size:         roundSize(slots, 1) * 2,
+		Seeding:      tournament.BracketSeeding(),
+		DrawRule:     tournament.BracketDrawRule(),
+	}
+	for _, slot := range slots {
+		for len(bracket.Rounds) < slot.Round {
+			bracket.Rounds = append(bracket.Rounds, nil)
+		}
+		bracket.Rounds[slot.Round-1] = append(bracket.Rounds[slot.Round-1], slot)
+	}
+
+	if final := bracket.Rounds[len(bracket.Rounds)-1]; len(final) == 1 {
+		bracket.ChampionID = final[0].WinnerID
+	}
+
+	return bracket, nil
+}
+
+// createMatches сохраняет матчи и ставит их в очередь
+func (s *Service) createMatches(ctx context.Context, matches ...*domain.Match) error {
+	if err := s.matchRepo.CreateBatch(ctx, matches); err != nil {
+		return err
+	}
+	s.enqueue(ctx, matches...)
+	return nil
+}
+
+// enqueue ставит матчи в очередь. Не попавшие в очередь матчи подберёт recovery
+func (s *Service) enqueue(ctx context.Context, matches ...*domain.Match) {
+	for _, match := range matches {
+		if err := s.queueManager.Enqueue(ctx, match); err != nil {
+			s.log.LogError("Failed to enqueue bracket match", err,
+				zap.String("match_id", match.ID.String()),
+			)
+		}
+	}
+}
+
+// newMatch создаёт матч пары позиции. Номер раунда матча совпадает с раундом сетки
+func newMatch(tournament *domain.Tournament, slot *domain.BracketSlot) *domain.Match {
+	return &domain.Match{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		Program1ID:   *slot.Program1ID,
+		Program2ID:   *slot.Program2ID,
+		GameType:     tournament.GameType,
+		Status:       domain.MatchPending,
+		Priority:     tournament.MatchPriority(domain.PriorityHigh),
+		IsPractice:   tournament.IsPractice,
+		RoundNumber:  slot.Round,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// higherSeed возвращает сторону участника с более высоким посевом (меньшим номером)
+func higherSeed(slot *domain.BracketSlot) int {
+	if slot.Seed2 != nil && (slot.Seed1 == nil || *slot.Seed2 < *slot.Seed1) {
+		return 2
+	}
+	return 1
+}
+
+// seedParticipants упорядочивает программы по посеву: по рейтингу (при равенстве - раньше
+// зарегистрированный) или случайно
+func seedParticipants(participants []*domain.TournamentParticipant, seeding domain.BracketSeeding) []uuid.UUID {
+	ordered := make([]*domain.TournamentParticipant, len(participants))
+	copy(ordered, participants)
+
+	if seeding == domain.SeedingRandom {
+		rand.Shuffle(len(ordered), func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] })
+	} else {
+		sort.SliceStable(ordered, func(i, j int) bool {
+			if ordered[i].Rating != ordered[j].Rating {
+				return ordered[i].Rating > ordered[j].Rating
+			}
+			return ordered[i].CreatedAt.Before(ordered[j].CreatedAt)
+		})
+	}
+
+	programs := make([]uuid.UUID, len(ordered))
+	for i, p := range ordered {
+		programs[i] = p.ProgramID
+	}
+	return programs
+}
+
+// seedOrder возвращает посевы позиций первого раунда для сетки размера size:
+// 1 и 2 встречаются только в финале, пары первого раунда - (1, size), (size/2+1, size/2), ...
+func seedOrder(size int) []int {
+	order := []int{1}
+	for n := 2; n <= size; n *= 2 {
+		next := make([]int, 0, n)
+		for _, seed := range order {
+			next = append(next, seed, n+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// buildSlots строит все позиции сетки для программ, упорядоченных по посеву.
+// Размер сетки - ближайшая степень двойки. Недостающие места - пропуски: соперник
+// пропуска проходит во второй раунд сразу
+func buildSlots(tournamentID uuid.UUID, programs []uuid.UUID) []*domain.BracketSlot {
+	size := 2
+	for size < len(programs) {
+		size *= 2
+	}
+
+	var slots []*domain.BracketSlot
+	rounds := make([][]*domain.BracketSlot, 0)
+	for round, count := 1, size/2; count >= 1; round, count = round+1, count/2 {
+		level := make([]*domain.BracketSlot, count)
+		for position := range level {
+			level[position] = &domain.BracketSlot{TournamentID: tournamentID, Round: round, Position: position}
+		}
+		rounds = append(rounds, level)
+		slots = append(slots, level...)
+	}
+
+	order := seedOrder(size)
+	for position, slot := range rounds[0] {
+		for side := 1; side <= 2; side++ {
+			seed := order[position*2+side-1]
+			if seed > len(programs) {
+				continue
+			}
+			program := programs[seed-1]
+			if side == 1 {
+				slot.Program1ID, slot.Seed1 = &program, &seed
+			} else {
+				slot.Program2ID, slot.Seed2 = &program, &seed
+			}
+		}
+
+		// Пропуск: единственный участник пары проходит дальше без матча
+		if slot.Program1ID == nil || slot.Program2ID == nil {
+			side := 1
+			if slot.Program1ID == nil {
+				side = 2
+			}
+			winner, seed := slot.Entrant(side)
+			slot.IsBye = true
+			slot.WinnerID = winner
+
+			if len(rounds) > 1 {
+				next := rounds[1][position/2]
+				if position%2 == 0 {
+					next.Program1ID, next.Seed1 = winner, seed
+				} else {
+					next.Program2ID, next.Seed2 = winner, seed
+				}
+			}
+		}
+	}
+
+	return slots
+}
+
+// roundSize возвращает число позиций раунда
+func roundSize(slots []*domain.BracketSlot, round int) int {
+	count := 0
+	for _, slot := range slots {
+		if slot.Round == round {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,422 @@
+package bracket
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type slotKey struct{ round, position int }
+
+// memoryBracketRepo mirrors the conditional updates of the PostgreSQL repository
+type memoryBracketRepo struct {
+	mu    sync.Mutex
+	slots map[slotKey]*domain.BracketSlot
+}
+
+func newMemoryBracketRepo() *memoryBracketRepo {
+	return &memoryBracketRepo{slots: make(map[slotKey]*domain.BracketSlot)}
+}
+
+func (r *memoryBracketRepo) CreateSlots(_ context.Context, slots []*domain.BracketSlot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, slot := range slots {
+		copied := *slot
+		r.slots[slotKey{slot.Round, slot.Position}] = &copied
+	}
+	return nil
+}
+
+func (r *memoryBracketRepo) GetByTournament(_ context.Context, _ uuid.UUID) ([]*domain.BracketSlot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var slots []*domain.BracketSlot
+	for round := 1; ; round++ {
+		found := false
+		for position := 0; ; position++ {
+			slot, ok := r.slots[slotKey{round, position}]
+			if !ok {
+				break
+			}
+			copied := *slot
+			slots = append(slots, &copied)
+			found = true
+		}
+		if !found {
+			return slots, nil
+		}
+	}
+}
+
+func (r *memoryBracketRepo) GetByMatchID(_ context.Context, matchID uuid.UUID) (*domain.BracketSlot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, slot := range r.slots {
+		if slot.MatchID != nil && *slot.MatchID == matchID {
+			copied := *slot
+			return &copied, nil
+		}
+	}
+	return nil, errors.ErrNotFound
+}
+
+func (r *memoryBracketRepo) SetMatch(_ context.Context, _ uuid.UUID, round, position int, previous *uuid.UUID, matchID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	slot := r.slots[slotKey{round, position}]
+	same := (slot.MatchID == nil && previous == nil) ||
+		(slot.MatchID != nil && previous != nil && *slot.MatchID == *previous)
+	if !same || slot.WinnerID != nil {
+		return errors.ErrConflict
+	}
+	if slot.MatchID != nil {
+		slot.Rematches++
+	}
+	slot.MatchID = &matchID
+	return nil
+}
+
+func (r *memoryBracketRepo) SetWinner(_ context.Context, _ uuid.UUID, round, position int, matchID, winnerID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	slot := r.slots[slotKey{round, position}]
+	if slot.MatchID == nil || *slot.MatchID != matchID || slot.WinnerID != nil {
+		return false, nil
+	}
+	slot.WinnerID = &winnerID
+	return true, nil
+}
+
+func (r *memoryBracketRepo) SetEntrant(_ context.Context, _ uuid.UUID, round, position, side int, programID uuid.UUID, seed *int) (*domain.BracketSlot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	slot, ok := r.slots[slotKey{round, position}]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	if side == 1 {
+		slot.Program1ID, slot.Seed1 = &programID, seed
+	} else {
+		slot.Program2ID, slot.Seed2 = &programID, seed
+	}
+	copied := *slot
+	return &copied, nil
+}
+
+type memoryTournamentRepo struct {
+	tournament   *domain.Tournament
+	participants []*domain.TournamentParticipant
+	updates      int
+}
+
+func (r *memoryTournamentRepo) GetByID(_ context.Context, _ uuid.UUID) (*domain.Tournament, error) {
+	copied := *r.tournament
+	return &copied, nil
+}
+
+func (r *memoryTournamentRepo) Update(_ context.Context, tournament *domain.Tournament) error {
+	r.updates++
+	r.tournament = tournament
+	return nil
+}
+
+func (r *memoryTournamentRepo) GetLatestParticipants(_ context.Context, _ uuid.UUID) ([]*domain.TournamentParticipant, error) {
+	return r.participants, nil
+}
+
+type memoryMatchRepo struct {
+	matches []*domain.Match
+}
+
+func (r *memoryMatchRepo) CreateBatch(_ context.Context, matches []*domain.Match) error {
+	r.matches = append(r.matches, matches...)
+	return nil
+}
+
+type memoryQueue struct {
+	enqueued []*domain.Match
+}
+
+func (q *memoryQueue) Enqueue(_ context.Context, match *domain.Match) error {
+	q.enqueued = append(q.enqueued, match)
+	return nil
+}
+
+type bracketFixture struct {
+	service    *Service
+	repo       *memoryBracketRepo
+	tournament *memoryTournamentRepo
+	matches    *memoryMatchRepo
+	queue      *memoryQueue
+	// programs in seed order: programs[0] has the highest rating
+	programs []uuid.UUID
+}
+
+func newBracketFixture(t *testing.T, participants int, metadata map[string]interface{}) *bracketFixture {
+	t.Helper()
+
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata[domain.MetaFormat] = string(domain.FormatElimination)
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		GameType: "dilemma",
+		Status:   domain.TournamentActive,
+		Metadata: metadata,
+	}
+
+	f := &bracketFixture{
+		repo:       newMemoryBracketRepo(),
+		tournament: &memoryTournamentRepo{tournament: tournament},
+		matches:    &memoryMatchRepo{},
+		queue:      &memoryQueue{},
+	}
+	now := time.Now()
+	for i := 0; i < participants; i++ {
+		programID := uuid.New()
+		f.programs = append(f.programs, programID)
+		// Registered in reverse order so that sorting by rating is observable
+		f.tournament.participants = append([]*domain.TournamentParticipant{{
+			TournamentID: tournament.ID,
+			ProgramID:    programID,
+			Rating:       2000 - i*10,
+			CreatedAt:    now.Add(time.Duration(i) * time.Minute),
+		}}, f.tournament.participants...)
+	}
+
+	log, _ := logger.New("error", "json")
+	f.service = NewService(f.repo, f.tournament, f.matches, f.queue, nil, log)
+	return f
+}
+
+// play completes the pending match of the slot with the given winner side (0 is a draw)
+func (f *bracketFixture) play(t *testing.T, round, position, winner int) *domain.Match {
+	t.Helper()
+
+	slot := f.repo.slots[slotKey{round, position}]
+	require.NotNil(t, slot.MatchID, "slot %d/%d has no match", round, position)
+
+	var match *domain.Match
+	for _, m := range f.matches.matches {
+		if m.ID == *slot.MatchID {
+			match = m
+		}
+	}
+	require.NotNil(t, match)
+
+	err := f.service.Advance(context.Background(), match, &domain.MatchResult{MatchID: match.ID, Winner: winner})
+	require.NoError(t, err)
+	return match
+}
+
+func TestSeedOrder(t *testing.T) {
+	assert.Equal(t, []int{1, 2}, seedOrder(2))
+	assert.Equal(t, []int{1, 4, 2, 3}, seedOrder(4))
+	assert.Equal(t, []int{1, 8, 4, 5, 2, 7, 3, 6}, seedOrder(8))
+}
+
+func TestSeed_ByRatingWithByes(t *testing.T) {
+	f := newBracketFixture(t, 5, nil)
+
+	created, err := f.service.Seed(context.Background(), f.tournament.tournament)
+	require.NoError(t, err)
+
+	// 8-slot bracket: seeds 1-3 get byes. Only 4 vs 5 plays in round 1,
+	// and seeds 2 and 3 already meet in round 2
+	assert.Equal(t, 2, created)
+	require.Len(t, f.matches.matches, 2)
+	assert.Len(t, f.queue.enqueued, 2)
+
+	first := f.matches.matches[0]
+	assert.Equal(t, f.programs[3], first.Program1ID)
+	assert.Equal(t, f.programs[4], first.Program2ID)
+	assert.Equal(t, 1, first.RoundNumber)
+	assert.Equal(t, "dilemma", first.GameType)
+
+	semi := f.matches.matches[1]
+	assert.Equal(t, f.programs[1], semi.Program1ID)
+	assert.Equal(t, f.programs[2], semi.Program2ID)
+	assert.Equal(t, 2, semi.RoundNumber)
+
+	bracket, err := f.service.Get(context.Background(), f.tournament.tournament.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 8, bracket.Size)
+	require.Len(t, bracket.Rounds, 3)
+	assert.Len(t, bracket.Rounds[0], 4)
+	assert.Len(t, bracket.Rounds[1], 2)
+	assert.Len(t, bracket.Rounds[2], 1)
+
+	byes := 0
+	for _, slot := range bracket.Rounds[0] {
+		if slot.IsBye {
+			byes++
+			assert.NotNil(t, slot.WinnerID)
+			assert.Nil(t, slot.MatchID)
+		}
+	}
+	assert.Equal(t, 3, byes)
+
+	// Seed 1 waits for the winner of 4 vs 5
+	waiting := bracket.Rounds[1][0]
+	assert.Equal(t, f.programs[0], *waiting.Program1ID)
+	assert.Nil(t, waiting.Program2ID)
+	assert.Nil(t, waiting.MatchID)
+	assert.Equal(t, semi.ID, *bracket.Rounds[1][1].MatchID)
+}
+
+func TestSeed_Rejects(t *testing.T) {
+	t.Run("single participant", func(t *testing.T) {
+		f := newBracketFixture(t, 1, nil)
+		_, err := f.service.Seed(context.Background(), f.tournament.tournament)
+		require.True(t, errors.IsAppError(err))
+		assert.Equal(t, errors.ErrValidation.Code, errors.GetAppError(err).Code)
+	})
+
+	t.Run("already seeded", func(t *testing.T) {
+		f := newBracketFixture(t, 4, nil)
+		_, err := f.service.Seed(context.Background(), f.tournament.tournament)
+		require.NoError(t, err)
+
+		_, err = f.service.Seed(context.Background(), f.tournament.tournament)
+		assert.True(t, errors.IsConflict(err))
+		assert.Len(t, f.matches.matches, 2)
+	})
+}
+
+// countingNotifier counts tournament completion notifications
+type countingNotifier struct {
+	completed int
+}
+
+func (n *countingNotifier) TournamentCompleted(_ context.Context, _ *domain.Tournament) error {
+	n.completed++
+	return nil
+}
+
+func TestAdvance_ToChampion(t *testing.T) {
+	f := newBracketFixture(t, 4, nil)
+	notifier := &countingNotifier{}
+	f.service.AddNotifier(notifier)
+	ctx := context.Background()
+
+	_, err := f.service.Seed(ctx, f.tournament.tournament)
+	require.NoError(t, err)
+	require.Len(t, f.matches.matches, 2)
+
+	// 1 vs 4: the underdog wins; 2 vs 3: the favourite wins
+	f.play(t, 1, 0, 2)
+	assert.Len(t, f.matches.matches, 2, "final waits for the second semifinal")
+	f.play(t, 1, 1, 1)
+
+	require.Len(t, f.matches.matches, 3)
+	final := f.matches.matches[2]
+	assert.Equal(t, f.programs[3], final.Program1ID)
+	assert.Equal(t, f.programs[1], final.Program2ID)
+	assert.Equal(t, 2, final.RoundNumber)
+	assert.Len(t, f.queue.enqueued, 3)
+
+	f.play(t, 2, 0, 2)
+
+	bracket, err := f.service.Get(ctx, f.tournament.tournament.ID)
+	require.NoError(t, err)
+	require.NotNil(t, bracket.ChampionID)
+	assert.Equal(t, f.programs[1], *bracket.ChampionID)
+
+	assert.Equal(t, domain.TournamentCompleted, f.tournament.tournament.Status)
+	assert.NotNil(t, f.tournament.tournament.EndTime)
+	assert.Equal(t, 1, notifier.completed)
+}
+
+func TestAdvance_Idempotent(t *testing.T) {
+	f := newBracketFixture(t, 4, nil)
+	ctx := context.Background()
+
+	_, err := f.service.Seed(ctx, f.tournament.tournament)
+	require.NoError(t, err)
+
+	match := f.play(t, 1, 0, 1)
+	f.play(t, 1, 1, 1)
+	require.Len(t, f.matches.matches, 3)
+
+	// A re-executed match must not create a second final
+	require.NoError(t, f.service.Advance(ctx, match, &domain.MatchResult{MatchID: match.ID, Winner: 1}))
+	assert.Len(t, f.matches.matches, 3)
+}
+
+func TestAdvance_IgnoresForeignMatches(t *testing.T) {
+	f := newBracketFixture(t, 4, nil)
+
+	match := &domain.Match{ID: uuid.New(), TournamentID: f.tournament.tournament.ID}
+	err := f.service.Advance(context.Background(), match, &domain.MatchResult{MatchID: match.ID, Winner: 1})
+	require.NoError(t, err)
+	assert.Empty(t, f.matches.matches)
+}
+
+func TestAdvance_FailedMatchWaitsForRetry(t *testing.T) {
+	f := newBracketFixture(t, 2, nil)
+	ctx := context.Background()
+
+	_, err := f.service.Seed(ctx, f.tournament.tournament)
+	require.NoError(t, err)
+
+	match := f.matches.matches[0]
+	err = f.service.Advance(ctx, match, &domain.MatchResult{MatchID: match.ID, ErrorCode: 1})
+	require.NoError(t, err)
+
+	assert.Nil(t, f.repo.slots[slotKey{1, 0}].WinnerID)
+	assert.Equal(t, domain.TournamentActive, f.tournament.tournament.Status)
+}
+
+func TestAdvance_Draw(t *testing.T) {
+	t.Run("higher seed advances", func(t *testing.T) {
+		f := newBracketFixture(t, 2, nil)
+		_, err := f.service.Seed(context.Background(), f.tournament.tournament)
+		require.NoError(t, err)
+
+		f.play(t, 1, 0, 0)
+
+		winner := f.repo.slots[slotKey{1, 0}].WinnerID
+		require.NotNil(t, winner)
+		assert.Equal(t, f.programs[0], *winner)
+		assert.Len(t, f.matches.matches, 1)
+	})
+
+	t.Run("rematch until limit", func(t *testing.T) {
+		f := newBracketFixture(t, 2, map[string]interface{}{
+			domain.MetaBracketDraw: string(domain.DrawRematch),
+		})
+		_, err := f.service.Seed(context.Background(), f.tournament.tournament)
+		require.NoError(t, err)
+
+		for i := 1; i <= domain.MaxBracketRematches; i++ {
+			f.play(t, 1, 0, 0)
+			assert.Len(t, f.matches.matches, 1+i)
+			assert.Equal(t, i, f.repo.slots[slotKey{1, 0}].Rematches)
+			assert.Nil(t, f.repo.slots[slotKey{1, 0}].WinnerID)
+		}
+
+		// After the last rematch the higher seed advances
+		f.play(t, 1, 0, 0)
+		assert.Len(t, f.matches.matches, 1+domain.MaxBracketRematches)
+		winner := f.repo.slots[slotKey{1, 0}].WinnerID
+		require.NotNil(t, winner)
+		assert.Equal(t, f.programs[0], *winner)
+	})
+}
+
+func TestGet_NotSeeded(t *testing.T) {
+	f := newBracketFixture(t, 2, nil)
+
+	_, err := f.service.Get(context.Background(), f.tournament.tournament.ID)
+	assert.True(t, errors.IsNotFound(err))
+}
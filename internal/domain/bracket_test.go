@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTournament_BracketSettings(t *testing.T) {
+	t.Run("defaults when not set", func(t *testing.T) {
+		tournament := &Tournament{}
+		assert.Equal(t, FormatRoundRobin, tournament.Format())
+		assert.False(t, tournament.IsElimination())
+		assert.Equal(t, SeedingRating, tournament.BracketSeeding())
+		assert.Equal(t, DrawHigherSeed, tournament.BracketDrawRule())
+	})
+
+	t.Run("parsed from metadata", func(t *testing.T) {
+		tournament := &Tournament{Metadata: map[string]interface{}{
+			MetaFormat:         "elimination",
+			MetaBracketSeeding: "random",
+			MetaBracketDraw:    "rematch",
+		}}
+		assert.True(t, tournament.IsElimination())
+		assert.Equal(t, SeedingRandom, tournament.BracketSeeding())
+		assert.Equal(t, DrawRematch, tournament.BracketDrawRule())
+	})
+
+	t.Run("unknown values fail validation", func(t *testing.T) {
+		for _, metadata := range []map[string]interface{}{
+			{MetaFormat: "swiss"},
+			{MetaBracketSeeding: "alphabet"},
+			{MetaBracketDraw: "coin_flip"},
+			{MetaFormat: 1},
+		} {
+			tournament := &Tournament{
+				Name:     "Cup",
+				GameType: "chess",
+				Status:   TournamentPending,
+				Metadata: metadata,
+			}
+			assert.Error(t, tournament.Validate(), "metadata %v", metadata)
+		}
+	})
+}
+
+func TestBracketSlot_IsReady(t *testing.T) {
+	p1, p2, match := uuid.New(), uuid.New(), uuid.New()
+
+	assert.False(t, (&BracketSlot{Program1ID: &p1}).IsReady())
+	assert.True(t, (&BracketSlot{Program1ID: &p1, Program2ID: &p2}).IsReady())
+	assert.False(t, (&BracketSlot{Program1ID: &p1, Program2ID: &p2, MatchID: &match}).IsReady())
+	assert.False(t, (&BracketSlot{Program1ID: &p1, Program2ID: &p2, WinnerID: &p1}).IsReady())
+}
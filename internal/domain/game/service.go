@@ -27,28 +27,42 @@ type GameRepository interface {
 
 // CreateRequest - запрос на создание игры
 type CreateRequest struct {
-	Name        string `json:"name" validate:"required,min=1,max=50"`
-	DisplayName string `json:"display_name" validate:"required,min=1,max=255"`
-	Rules       string `json:"rules"`
+	Name           string                `json:"name" validate:"required,min=1,max=50"`
+	DisplayName    string                `json:"display_name" validate:"required,min=1,max=255"`
+	Rules          string                `json:"rules"`
+	SandboxProfile domain.SandboxProfile `json:"sandbox_profile"` // Пусто - профиль по умолчанию
 }
 
 // UpdateRequest - запрос на обновление игры
 type UpdateRequest struct {
-	DisplayName string `json:"display_name" validate:"required,min=1,max=255"`
-	Rules       string `json:"rules"`
+	DisplayName    string                `json:"display_name" validate:"required,min=1,max=255"`
+	Rules          string                `json:"rules"`
+	SandboxProfile domain.SandboxProfile `json:"sandbox_profile"` // Пусто - без изменений
 }
 
 // Service предоставляет бизнес-логику для работы с играми
 type Service struct {
 	gameRepo GameRepository
 	log      *logger.Logger
+
+	// defaultSandbox профиль изоляции игр, создаваемых без sandbox_profile
+	defaultSandbox domain.SandboxProfile
 }
 
 // NewService создаёт новый сервис игр
 func NewService(gameRepo GameRepository, log *logger.Logger) *Service {
 	return &Service{
-		gameRepo: gameRepo,
-		log:      log,
+		gameRepo:       gameRepo,
+		log:            log,
+		defaultSandbox: domain.SandboxStrict,
+	}
+}
+
+// SetDefaultSandboxProfile задаёт профиль изоляции для игр, создаваемых без sandbox_profile
+// (EXECUTOR_SANDBOX_PROFILE). Пустой или неизвестный профиль оставляет strict
+func (s *Service) SetDefaultSandboxProfile(profile domain.SandboxProfile) {
+	if profile.IsValid() {
+		s.defaultSandbox = profile
 	}
 }
 
@@ -62,6 +76,14 @@ func (s *Service) Create(ctx context.Context, req *CreateRequest) (*domain.Game,
 		return nil, errors.ErrValidation.WithMessage("game name must contain only lowercase letters, digits and underscores")
 	}
 
+	sandbox := req.SandboxProfile
+	if sandbox == "" {
+		sandbox = s.defaultSandbox
+	}
+	if !sandbox.IsValid() {
+		return nil, errors.ErrValidation.WithMessage("sandbox_profile must be strict or relaxed")
+	}
+
 	// Проверяем уникальность имени
 	exists, err := s.gameRepo.Exists(ctx, req.Name)
 	if err != nil {
@@ -72,10 +94,11 @@ func (s *Service) Create(ctx context.Context, req *CreateRequest) (*domain.Game,
 	}
 
 	game := &domain.Game{
-		ID:          uuid.New(),
-		Name:        req.Name,
-		DisplayName: req.DisplayName,
-		Rules:       req.Rules,
+		ID:             uuid.New(),
+		Name:           req.Name,
+		DisplayName:    req.DisplayName,
+		Rules:          req.Rules,
+		SandboxProfile: sandbox,
 	}
 
 	if err := s.gameRepo.Create(ctx, game); err != nil {
@@ -122,6 +145,10 @@ func (s *Service) List(ctx context.Context, filter domain.GameFilter) ([]*domain
 
 // Update обновляет игру
 func (s *Service) Update(ctx context.Context, id uuid.UUID, req *UpdateRequest) (*domain.Game, error) {
+	if req.SandboxProfile != "" && !req.SandboxProfile.IsValid() {
+		return nil, errors.ErrValidation.WithMessage("sandbox_profile must be strict or relaxed")
+	}
+
 	game, err := s.gameRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -129,12 +156,19 @@ func (s *Service) Update(ctx context.Context, id uuid.UUID, req *UpdateRequest)
 
 	game.DisplayName = req.DisplayName
 	game.Rules = req.Rules
+	if req.SandboxProfile != "" {
+		game.SandboxProfile = req.SandboxProfile
+	}
 
 	if err := s.gameRepo.Update(ctx, game); err != nil {
 		return nil, errors.Wrap(err, "failed to update game")
 	}
 
-	s.log.Info("Game updated", zap.String("game_id", game.ID.String()), zap.String("name", game.Name))
+	s.log.Info("Game updated",
+		zap.String("game_id", game.ID.String()),
+		zap.String("name", game.Name),
+		zap.String("sandbox_profile", string(game.SandboxProfile)),
+	)
 
 	return game, nil
 }
@@ -0,0 +1,120 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockGameRepository struct {
+	mock.Mock
+}
+
+func (m *MockGameRepository) Create(ctx context.Context, game *domain.Game) error {
+	args := m.Called(ctx, game)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Game, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Game), args.Error(1)
+}
+
+func (m *MockGameRepository) GetByName(ctx context.Context, name string) (*domain.Game, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Game), args.Error(1)
+}
+
+func (m *MockGameRepository) List(ctx context.Context, filter domain.GameFilter) ([]*domain.Game, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Game), args.Error(1)
+}
+
+func (m *MockGameRepository) Update(ctx context.Context, game *domain.Game) error {
+	args := m.Called(ctx, game)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) GetByTournamentID(ctx context.Context, tournamentID uuid.UUID) ([]*domain.Game, error) {
+	args := m.Called(ctx, tournamentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Game), args.Error(1)
+}
+
+func (m *MockGameRepository) AddToTournament(ctx context.Context, tournamentID, gameID uuid.UUID) error {
+	args := m.Called(ctx, tournamentID, gameID)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) RemoveFromTournament(ctx context.Context, tournamentID, gameID uuid.UUID) error {
+	args := m.Called(ctx, tournamentID, gameID)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) Exists(ctx context.Context, name string) (bool, error) {
+	args := m.Called(ctx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestService_Create_SandboxProfile(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	create := func(t *testing.T, configure func(s *Service), requested domain.SandboxProfile) *domain.Game {
+		repo := new(MockGameRepository)
+		repo.On("Exists", mock.Anything, "dilemma").Return(false, nil)
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Game")).Return(nil)
+
+		s := NewService(repo, log)
+		configure(s)
+
+		game, err := s.Create(context.Background(), &CreateRequest{
+			Name:           "dilemma",
+			DisplayName:    "Prisoner's Dilemma",
+			SandboxProfile: requested,
+		})
+		require.NoError(t, err)
+		return game
+	}
+
+	t.Run("strict without configuration", func(t *testing.T) {
+		game := create(t, func(s *Service) {}, "")
+		assert.Equal(t, domain.SandboxStrict, game.SandboxProfile)
+	})
+
+	t.Run("configured default applies to games without a profile", func(t *testing.T) {
+		game := create(t, func(s *Service) { s.SetDefaultSandboxProfile(domain.SandboxRelaxed) }, "")
+		assert.Equal(t, domain.SandboxRelaxed, game.SandboxProfile)
+	})
+
+	t.Run("requested profile overrides the default", func(t *testing.T) {
+		game := create(t, func(s *Service) { s.SetDefaultSandboxProfile(domain.SandboxRelaxed) }, domain.SandboxStrict)
+		assert.Equal(t, domain.SandboxStrict, game.SandboxProfile)
+	})
+
+	t.Run("invalid default keeps strict", func(t *testing.T) {
+		game := create(t, func(s *Service) { s.SetDefaultSandboxProfile("permissive") }, "")
+		assert.Equal(t, domain.SandboxStrict, game.SandboxProfile)
+	})
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "sort"
+
+// LanguageVersions допустимые версии языков: язык -> версии от старой к новой
+type LanguageVersions map[string][]string
+
+// SupportedLanguage язык с выбором версии
+type SupportedLanguage struct {
+	Language       string   `json:"language"`
+	Versions       []string `json:"versions"`
+	DefaultVersion string   `json:"default_version"`
+}
+
+// Default возвращает версию по умолчанию - последнюю в списке.
+// Пусто - для языка версия не выбирается
+func (lv LanguageVersions) Default(language string) string {
+	versions := lv[language]
+	if len(versions) == 0 {
+		return ""
+	}
+	return versions[len(versions)-1]
+}
+
+// Resolve проверяет версию языка. Пустая версия заменяется версией по умолчанию.
+// Для языков без выбора версии допустима только пустая версия
+func (lv LanguageVersions) Resolve(language, version string) (string, bool) {
+	if version == "" {
+		return lv.Default(language), true
+	}
+	for _, v := range lv[language] {
+		if v == version {
+			return version, true
+		}
+	}
+	return "", false
+}
+
+// Supported возвращает языки с выбором версии, упорядоченные по названию
+func (lv LanguageVersions) Supported() []SupportedLanguage {
+	languages := make([]SupportedLanguage, 0, len(lv))
+	for language, versions := range lv {
+		if len(versions) == 0 {
+			continue
+		}
+		languages = append(languages, SupportedLanguage{
+			Language:       language,
+			Versions:       versions,
+			DefaultVersion: lv.Default(language),
+		})
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		return languages[i].Language < languages[j].Language
+	})
+	return languages
+}
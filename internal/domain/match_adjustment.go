@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MatchAdjustmentAction вид ручной правки результата матча
+type MatchAdjustmentAction string
+
+const (
+	// MatchAdjustmentEdit результат матча заменён администратором
+	MatchAdjustmentEdit MatchAdjustmentAction = "edit"
+	// MatchAdjustmentAnnul результат аннулирован, матч возвращён в pending
+	MatchAdjustmentAnnul MatchAdjustmentAction = "annul"
+)
+
+// MaxAdjustmentReasonLength ограничение длины причины правки
+const MaxAdjustmentReasonLength = 1000
+
+// MatchResultAdjustment запись истории ручных правок результата матча.
+// Old* - результат до правки, New* - после (для аннулирования пустые)
+type MatchResultAdjustment struct {
+	ID         int64                 `json:"id" db:"id"`
+	MatchID    uuid.UUID             `json:"match_id" db:"match_id"`
+	Action     MatchAdjustmentAction `json:"action" db:"action"`
+	OldStatus  MatchStatus           `json:"old_status" db:"old_status"`
+	OldScore1  *int                  `json:"old_score1,omitempty" db:"old_score1"`
+	OldScore2  *int                  `json:"old_score2,omitempty" db:"old_score2"`
+	OldWinner  *int                  `json:"old_winner,omitempty" db:"old_winner"`
+	NewScore1  *int                  `json:"new_score1,omitempty" db:"new_score1"`
+	NewScore2  *int                  `json:"new_score2,omitempty" db:"new_score2"`
+	NewWinner  *int                  `json:"new_winner,omitempty" db:"new_winner"`
+	Reason     string                `json:"reason" db:"reason"`
+	AdjustedBy *uuid.UUID            `json:"adjusted_by,omitempty" db:"adjusted_by"`
+	CreatedAt  time.Time             `json:"created_at" db:"created_at"`
+}
+
+// Validate проверяет правку перед сохранением
+func (a *MatchResultAdjustment) Validate() error {
+	reason := strings.TrimSpace(a.Reason)
+	if reason == "" {
+		return errors.New("reason is required")
+	}
+	if len(reason) > MaxAdjustmentReasonLength {
+		return errors.New("reason is too long")
+	}
+
+	switch a.Action {
+	case MatchAdjustmentEdit:
+		if a.NewScore1 == nil || a.NewScore2 == nil || a.NewWinner == nil {
+			return errors.New("score1, score2 and winner are required")
+		}
+		if *a.NewWinner < 0 || *a.NewWinner > 2 {
+			return errors.New("winner must be 0 (draw), 1 or 2")
+		}
+	case MatchAdjustmentAnnul:
+	default:
+		return errors.New("unknown adjustment action")
+	}
+	return nil
+}
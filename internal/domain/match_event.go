@@ -0,0 +1,18 @@
+package domain
+
+// MatchEventType тип события трансляции идущего матча
+type MatchEventType string
+
+const (
+	MatchEventStart  MatchEventType = "start"  // Контейнер матча запускается; при повторном выполнении трансляция начинается заново
+	MatchEventOutput MatchEventType = "output" // Строка вывода tjudge-cli (ходы итерации в verbose режиме)
+	MatchEventEnd    MatchEventType = "end"    // Результат матча сохранён
+)
+
+// MatchEvent событие трансляции матча. Seq возрастает в пределах матча,
+// в том числе между повторными выполнениями
+type MatchEvent struct {
+	Seq  int64          `json:"seq"`
+	Type MatchEventType `json:"type"`
+	Line string         `json:"line,omitempty"`
+}
@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"encoding/binary"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,6 +28,40 @@ type User struct {
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// APIKeyScope - права API ключа
+type APIKeyScope string
+
+const (
+	APIKeyScopeRead      APIKeyScope = "read"       // Только чтение: GET, HEAD, OPTIONS
+	APIKeyScopeReadWrite APIKeyScope = "read_write" // Все запросы пользователя
+)
+
+// IsValid проверяет, что scope известен
+func (s APIKeyScope) IsValid() bool {
+	return s == APIKeyScopeRead || s == APIKeyScopeReadWrite
+}
+
+// APIKey представляет ключ пользователя для скриптов. Хранится только хэш ключа
+type APIKey struct {
+	ID         uuid.UUID   `json:"id" db:"id"`
+	UserID     uuid.UUID   `json:"user_id" db:"user_id"`
+	Name       string      `json:"name" db:"name"`
+	KeyHash    string      `json:"-" db:"key_hash"`
+	Prefix     string      `json:"prefix" db:"prefix"`
+	Scope      APIKeyScope `json:"scope" db:"scope"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// APIKeyIdentity пользователь, от имени которого действует API ключ
+type APIKeyIdentity struct {
+	KeyID  uuid.UUID   `json:"key_id"`
+	UserID uuid.UUID   `json:"user_id"`
+	Role   Role        `json:"role"`
+	Scope  APIKeyScope `json:"scope"`
+}
+
 // Program представляет программу-бота пользователя
 type Program struct {
 	ID           uuid.UUID  `json:"id" db:"id"`
@@ -39,18 +76,77 @@ type Program struct {
 	FilePath     *string    `json:"file_path,omitempty" db:"file_path"`
 	ErrorMessage *string    `json:"error_message,omitempty" db:"error_message"`
 	Version      int        `json:"version" db:"version"`
+	ContentHash  *string    `json:"content_hash,omitempty" db:"content_hash"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Версия языка, под которую загружена программа (например "3.11" для Python).
+	// Пусто - язык без выбора версии
+	LanguageVersion string `json:"language_version,omitempty" db:"language_version"`
+
+	// Эталонный бот игры: принадлежит SystemUserID, не участвует в турнирах
+	// и играет калибровочные матчи с фиксированным рейтингом ReferenceRating
+	IsReference     bool `json:"is_reference,omitempty" db:"is_reference"`
+	ReferenceRating *int `json:"reference_rating,omitempty" db:"reference_rating"`
+
+	// Итог проверочного матча с эталонным ботом игры после загрузки.
+	// Пусто - программа не проверялась (у игры нет проверочного бота)
+	ValidationStatus ProgramValidationStatus `json:"validation_status,omitempty" db:"validation_status"`
+	ValidationError  *string                 `json:"validation_error,omitempty" db:"validation_error"`
+}
+
+// ProgramValidationStatus статус проверки программы матчем с эталонным ботом
+type ProgramValidationStatus string
+
+const (
+	ValidationPending ProgramValidationStatus = "pending"
+	ValidationPassed  ProgramValidationStatus = "passed"
+	ValidationFailed  ProgramValidationStatus = "failed"
+)
+
+// SystemUserID владелец эталонных ботов (создаётся миграцией)
+var SystemUserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// DefaultReferenceRating рейтинг эталонного бота, если админ его не указал
+const DefaultReferenceRating = 1500
+
+// ProgramFilter фильтр списка программ пользователя
+type ProgramFilter struct {
+	TournamentID *uuid.UUID
+	GameID       *uuid.UUID
+	Language     string
+	Limit        int
+	Offset       int
+}
+
+// IsRunnable проверяет, что программа прошла проверку при загрузке и может играть матчи
+func (p *Program) IsRunnable() bool {
+	return p.ErrorMessage == nil || *p.ErrorMessage == ""
+}
+
+// SandboxProfile - профиль изоляции контейнера при запуске программ
+type SandboxProfile string
+
+const (
+	SandboxStrict  SandboxProfile = "strict"  // Без сети, root FS только для чтения, запись только в tmpfs
+	SandboxRelaxed SandboxProfile = "relaxed" // Root FS доступна для записи, сеть разрешена конфигурацией executor
+)
+
+// IsValid проверяет, что профиль известен
+func (p SandboxProfile) IsValid() bool {
+	return p == SandboxStrict || p == SandboxRelaxed
 }
 
 // Game представляет игру в системе
 type Game struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`                 // Уникальное название [a-z0-9_]+
-	DisplayName string    `json:"display_name" db:"display_name"` // Название для отображения
-	Rules       string    `json:"rules" db:"rules"`               // Правила в формате Markdown
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID              uuid.UUID      `json:"id" db:"id"`
+	Name            string         `json:"name" db:"name"`                                     // Уникальное название [a-z0-9_]+
+	DisplayName     string         `json:"display_name" db:"display_name"`                     // Название для отображения
+	Rules           string         `json:"rules" db:"rules"`                                   // Правила в формате Markdown
+	SandboxProfile  SandboxProfile `json:"sandbox_profile" db:"sandbox_profile"`               // Профиль изоляции для программ этой игры
+	ValidationBotID *uuid.UUID     `json:"validation_bot_id,omitempty" db:"validation_bot_id"` // Эталонный бот для проверочных матчей при загрузке
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
 }
 
 // Team представляет команду в турнире
@@ -84,11 +180,37 @@ type TournamentGame struct {
 	GameID           uuid.UUID  `json:"game_id" db:"game_id"`
 	IsActive         bool       `json:"is_active" db:"is_active"`
 	RoundCompleted   bool       `json:"round_completed" db:"round_completed"`
+	RoundActive      bool       `json:"round_active" db:"round_active"`
 	RoundCompletedAt *time.Time `json:"round_completed_at,omitempty" db:"round_completed_at"`
 	CurrentRound     int        `json:"current_round" db:"current_round"`
+	WindowStart      *time.Time `json:"window_start,omitempty" db:"window_start"` // Начало окна, в которое выполняются матчи игры
+	WindowEnd        *time.Time `json:"window_end,omitempty" db:"window_end"`
 	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
 }
 
+// HasWindow проверяет, ограничено ли выполнение матчей игры окном времени
+func (tg *TournamentGame) HasWindow() bool {
+	return tg.WindowStart != nil && tg.WindowEnd != nil
+}
+
+// WindowOpen проверяет, можно ли выполнять матчи игры в момент now.
+// Игра без окна открыта всегда
+func (tg *TournamentGame) WindowOpen(now time.Time) bool {
+	if !tg.HasWindow() {
+		return true
+	}
+	return !now.Before(*tg.WindowStart) && now.Before(*tg.WindowEnd)
+}
+
+// GameWindow окно игры турнира, для которого пора запустить раунд
+type GameWindow struct {
+	TournamentID uuid.UUID `db:"tournament_id"`
+	GameID       uuid.UUID `db:"game_id"`
+	GameType     string    `db:"game_type"`
+	Start        time.Time `db:"window_start"`
+	End          time.Time `db:"window_end"`
+}
+
 // TournamentStatus - статус турнира
 type TournamentStatus string
 
@@ -99,6 +221,18 @@ const (
 	TournamentCancelled TournamentStatus = "cancelled"
 )
 
+// TournamentVisibility - видимость турнира
+type TournamentVisibility string
+
+const (
+	// VisibilityPublic турнир в списках, его данные видны всем
+	VisibilityPublic TournamentVisibility = "public"
+	// VisibilityUnlisted турнир не попадает в списки, но доступен всем по ID или коду
+	VisibilityUnlisted TournamentVisibility = "unlisted"
+	// VisibilityPrivate турнир и его данные видны только участникам команд, создателю и админам
+	VisibilityPrivate TournamentVisibility = "private"
+)
+
 // Tournament представляет турнир
 type Tournament struct {
 	ID              uuid.UUID              `json:"id" db:"id"`
@@ -110,6 +244,8 @@ type Tournament struct {
 	MaxParticipants *int                   `json:"max_participants,omitempty" db:"max_participants"`
 	MaxTeamSize     int                    `json:"max_team_size" db:"max_team_size"`
 	IsPermanent     bool                   `json:"is_permanent" db:"is_permanent"`
+	IsPractice      bool                   `json:"is_practice" db:"is_practice"` // Тренировочный турнир: вне глобального рейтинга, матчи с низким приоритетом
+	Visibility      TournamentVisibility   `json:"visibility" db:"visibility"`
 	CreatorID       *uuid.UUID             `json:"creator_id,omitempty" db:"creator_id"`
 	StartTime       *time.Time             `json:"start_time,omitempty" db:"start_time"`
 	EndTime         *time.Time             `json:"end_time,omitempty" db:"end_time"`
@@ -117,6 +253,145 @@ type Tournament struct {
 	Version         int                    `json:"version" db:"version"`
 	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time              `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time             `json:"deleted_at,omitempty" db:"deleted_at"` // Мягкое удаление
+}
+
+// IsPrivate сообщает, что данные турнира видны не всем. Турниры без видимости
+// (созданные до её появления) считаются публичными
+func (t *Tournament) IsPrivate() bool {
+	return t.Visibility == VisibilityPrivate
+}
+
+// Ключи метаданных турнира для автостарта
+const (
+	MetaAutoStartError    = "auto_start_error"    // Причина последнего неудачного автостарта
+	MetaAutoStartAttempts = "auto_start_attempts" // Количество неудачных попыток автостарта
+)
+
+// AutoStartError возвращает причину неудачного автостарта из метаданных
+func (t *Tournament) AutoStartError() *string {
+	if reason, ok := t.Metadata[MetaAutoStartError].(string); ok && reason != "" {
+		return &reason
+	}
+	return nil
+}
+
+// AutoStartAttempts возвращает количество неудачных попыток автостарта из метаданных
+func (t *Tournament) AutoStartAttempts() int {
+	attempts, _ := t.Metadata[MetaAutoStartAttempts].(float64)
+	return int(attempts)
+}
+
+// MetaUploadCooldownSeconds ключ метаданных турнира: минимальный интервал между загрузками
+// новых версий программы одной командой для одной игры (в секундах, 0 - без ограничения)
+const MetaUploadCooldownSeconds = "upload_cooldown_seconds"
+
+// UploadCooldown возвращает интервал между загрузками программ из метаданных
+// Если значение не задано или некорректно, возвращает defaultCooldown
+func (t *Tournament) UploadCooldown(defaultCooldown time.Duration) time.Duration {
+	seconds, ok := t.Metadata[MetaUploadCooldownSeconds].(float64)
+	if !ok || seconds < 0 {
+		return defaultCooldown
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Ключи метаданных турнира для бюджета времени
+const (
+	// MetaTimeBudget длительность турнира от старта (строка time.Duration, например "3h30m"),
+	// по истечении которой турнир принудительно завершается
+	MetaTimeBudget = "time_budget"
+	// MetaTimeBudgetWarned отметка о разосланном предупреждении о скором истечении бюджета
+	MetaTimeBudgetWarned = "time_budget_warned"
+)
+
+// parseTimeBudget разбирает бюджет времени из метаданных. nil - бюджет не задан
+func parseTimeBudget(value interface{}) (time.Duration, error) {
+	if value == nil {
+		return 0, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("time_budget must be a duration string, e.g. \"3h\"")
+	}
+	budget, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time_budget: %w", err)
+	}
+	if budget <= 0 {
+		return 0, fmt.Errorf("time_budget must be positive")
+	}
+	return budget, nil
+}
+
+// TimeBudget возвращает бюджет времени турнира (0 - не задан или некорректен)
+func (t *Tournament) TimeBudget() time.Duration {
+	budget, err := parseTimeBudget(t.Metadata[MetaTimeBudget])
+	if err != nil {
+		return 0
+	}
+	return budget
+}
+
+// Deadline возвращает момент истечения бюджета времени: StartTime + TimeBudget.
+// nil, если бюджет не задан или турнир ещё не стартовал
+func (t *Tournament) Deadline() *time.Time {
+	budget := t.TimeBudget()
+	if budget == 0 || t.StartTime == nil {
+		return nil
+	}
+	deadline := t.StartTime.Add(budget)
+	return &deadline
+}
+
+// RoundLockMode определяет, какие загрузки программ блокирует идущий раунд игры
+type RoundLockMode string
+
+const (
+	// RoundLockGame раунд блокирует загрузку только для своей игры (по умолчанию)
+	RoundLockGame RoundLockMode = "game"
+	// RoundLockTournament раунд любой игры блокирует загрузку для всех игр турнира
+	RoundLockTournament RoundLockMode = "tournament"
+)
+
+// MetaRoundLock ключ метаданных турнира: режим блокировки загрузок во время раунда
+const MetaRoundLock = "round_lock"
+
+// RoundLock возвращает режим блокировки загрузок из метаданных
+func (t *Tournament) RoundLock() RoundLockMode {
+	if mode, ok := t.Metadata[MetaRoundLock].(string); ok && RoundLockMode(mode) == RoundLockTournament {
+		return RoundLockTournament
+	}
+	return RoundLockGame
+}
+
+// MetaReferenceCalibration ключ метаданных турнира: каждая новая программа
+// сначала играет калибровочные матчи со всеми эталонными ботами своей игры
+const MetaReferenceCalibration = "reference_calibration"
+
+// ReferenceCalibration возвращает, включена ли калибровка по эталонным ботам
+func (t *Tournament) ReferenceCalibration() bool {
+	enabled, _ := t.Metadata[MetaReferenceCalibration].(bool)
+	return enabled
+}
+
+// MetaAutoCompleteRounds ключ метаданных турнира: раунд игры автоматически
+// отмечается завершённым после окончания его последнего матча
+const MetaAutoCompleteRounds = "auto_complete_rounds"
+
+// AutoCompleteRounds возвращает, завершаются ли раунды автоматически
+func (t *Tournament) AutoCompleteRounds() bool {
+	enabled, _ := t.Metadata[MetaAutoCompleteRounds].(bool)
+	return enabled
+}
+
+// MatchPriority возвращает приоритет нового матча турнира: матчи тренировочного
+// турнира всегда low, чтобы не задерживать соревновательные
+func (t *Tournament) MatchPriority(priority MatchPriority) MatchPriority {
+	if t.IsPractice {
+		return PriorityLow
+	}
+	return priority
 }
 
 // TournamentWithGames - турнир с играми для API ответов
@@ -137,12 +412,63 @@ type TournamentParticipant struct {
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
 
+// ParticipantEntry участник турнира с данными программы, команды и игры
+type ParticipantEntry struct {
+	ProgramID      uuid.UUID  `json:"program_id" db:"program_id"`
+	ProgramName    string     `json:"program_name" db:"program_name"`
+	ProgramVersion int        `json:"program_version" db:"program_version"`
+	TeamID         *uuid.UUID `json:"team_id" db:"team_id"`
+	TeamName       *string    `json:"team_name" db:"team_name"`
+	GameID         *uuid.UUID `json:"game_id" db:"game_id"`
+	GameName       *string    `json:"game_name" db:"game_name"`
+	Rating         int        `json:"rating" db:"rating"`
+	Wins           int        `json:"wins" db:"wins"`
+	Losses         int        `json:"losses" db:"losses"`
+	Draws          int        `json:"draws" db:"draws"`
+	JoinedAt       time.Time  `json:"joined_at" db:"joined_at"`
+}
+
+// ParticipantFilter фильтры списка участников турнира
+type ParticipantFilter struct {
+	GameID     *uuid.UUID
+	LatestOnly bool // Только последняя версия программы команды в каждой игре (как GetLatestParticipants)
+	Limit      int
+	Offset     int
+}
+
+// ImportedProgram программа команды, перенесённая из другого турнира (например, из отборочного в финал).
+// В целевом турнире создаётся копия команды и новая версия программы с тем же файлом
+type ImportedProgram struct {
+	SourceProgramID uuid.UUID  `json:"source_program_id"`
+	ProgramID       uuid.UUID  `json:"program_id"`
+	TeamID          uuid.UUID  `json:"team_id"`
+	TeamName        string     `json:"team_name"`
+	GameID          *uuid.UUID `json:"game_id,omitempty"`
+	Skipped         bool       `json:"skipped,omitempty"` // Та же программа уже есть у команды в целевом турнире
+}
+
+// TournamentBan представляет бан программы и её команды в турнире
+type TournamentBan struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	TournamentID uuid.UUID  `json:"tournament_id" db:"tournament_id"`
+	ProgramID    uuid.UUID  `json:"program_id" db:"program_id"`
+	TeamID       *uuid.UUID `json:"team_id,omitempty" db:"team_id"` // Бан действует на все программы команды
+	Reason       string     `json:"reason" db:"reason"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
 // TournamentFilter фильтр для списка турниров
 type TournamentFilter struct {
-	Status   TournamentStatus
-	GameType string
-	Limit    int
-	Offset   int
+	Status         TournamentStatus
+	GameType       string
+	IncludeDeleted bool // Включать мягко удалённые турниры (только для админов)
+
+	// Видимость: без AllVisibilities в список попадают публичные турниры,
+	// а также приватные турниры, где ViewerID состоит в команде или является создателем
+	ViewerID        *uuid.UUID
+	AllVisibilities bool // Все турниры, включая unlisted и чужие private (только для админов)
+	Limit           int
+	Offset          int
 }
 
 // MatchFilter фильтр для списка матчей
@@ -151,6 +477,9 @@ type MatchFilter struct {
 	ProgramID    *uuid.UUID
 	Status       MatchStatus
 	GameType     string
+	ExcludeTest  bool // Без тестовых матчей команд
+	HidePrivate  bool // Без матчей приватных турниров, кроме доступных ViewerID
+	ViewerID     *uuid.UUID
 	Limit        int
 	Offset       int
 }
@@ -163,6 +492,7 @@ const (
 	MatchRunning   MatchStatus = "running"
 	MatchCompleted MatchStatus = "completed"
 	MatchFailed    MatchStatus = "failed"
+	MatchCancelled MatchStatus = "cancelled"
 )
 
 // MatchPriority - приоритет матча
@@ -184,14 +514,82 @@ type Match struct {
 	Status       MatchStatus   `json:"status" db:"status"`
 	Priority     MatchPriority `json:"priority" db:"priority"`
 	RoundNumber  int           `json:"round_number" db:"round_number"` // Номер раунда для группировки
+	Seed         int64         `json:"seed" db:"seed"`                 // Сид случайных чисел для воспроизведения матча
 	Score1       *int          `json:"score1,omitempty" db:"score1"`
 	Score2       *int          `json:"score2,omitempty" db:"score2"`
 	Winner       *int          `json:"winner,omitempty" db:"winner"`
 	ErrorCode    *int          `json:"error_code,omitempty" db:"error_code"`
 	ErrorMessage *string       `json:"error_message,omitempty" db:"error_message"`
+	ScheduledAt  *time.Time    `json:"scheduled_at,omitempty" db:"scheduled_at"` // Не запускать раньше этого времени
 	StartedAt    *time.Time    `json:"started_at,omitempty" db:"started_at"`
 	CompletedAt  *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
 	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+	IsTest       bool          `json:"is_test" db:"is_test"`                       // Тестовый матч: не влияет на рейтинги и таблицы лидеров
+	IsValidation bool          `json:"is_validation,omitempty" db:"is_validation"` // Проверочный матч загруженной программы (всегда и тестовый)
+	IsPractice   bool          `json:"is_practice,omitempty" db:"is_practice"`     // Матч тренировочного турнира: не стареет в очереди
+	EnqueuedAt   *time.Time    `json:"enqueued_at,omitempty" db:"-"`               // Время постановки в очередь (только в payload очереди)
+	DequeuedAt   *time.Time    `json:"-" db:"-"`                                   // Время извлечения из очереди воркером
+	Timing       *MatchTiming  `json:"timing,omitempty" db:"timing"`               // Длительности этапов обработки (только в деталях матча)
+
+	// WindowOverride матч запущен администратором вне окна игры (только в payload очереди)
+	WindowOverride bool `json:"window_override,omitempty" db:"-"`
+}
+
+// WalkoverMessage сообщение матча, засчитанного без игры: программа соперника не запускается
+const WalkoverMessage = "walkover: opponent program failed validation"
+
+// SetWalkover засчитывает матч победой winner (1 или 2) без запуска программ
+func (m *Match) SetWalkover(winner int, at time.Time) {
+	score1, score2 := 0, 0
+	message := WalkoverMessage
+	m.Status = MatchCompleted
+	m.Winner = &winner
+	m.Score1 = &score1
+	m.Score2 = &score2
+	m.ErrorMessage = &message
+	m.CompletedAt = &at
+}
+
+// IsWalkover проверяет, засчитан ли матч без игры
+func (m *Match) IsWalkover() bool {
+	return m.ErrorMessage != nil && *m.ErrorMessage == WalkoverMessage
+}
+
+// QueueWait возвращает время ожидания матча в очереди к моменту now.
+// Для payload без enqueued_at (поставлены до обновления) отсчёт идёт от CreatedAt;
+// запланированный матч ждёт с момента ScheduledAt
+func (m *Match) QueueWait(now time.Time) time.Duration {
+	var since time.Time
+	switch {
+	case m.EnqueuedAt != nil:
+		since = *m.EnqueuedAt
+	case !m.CreatedAt.IsZero():
+		since = m.CreatedAt
+	default:
+		return 0
+	}
+	if m.ScheduledAt != nil && m.ScheduledAt.After(since) {
+		since = *m.ScheduledAt
+	}
+
+	if wait := now.Sub(since); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// SeedFromID возвращает детерминированный сид матча по его ID (первые 4 байта UUID)
+// Совпадает с заполнением в миграции 000025
+func SeedFromID(id uuid.UUID) int64 {
+	return int64(binary.BigEndian.Uint32(id[:4]))
+}
+
+// EffectiveSeed возвращает сид матча, вычисляя его из ID, если он не задан
+func (m *Match) EffectiveSeed() int64 {
+	if m.Seed != 0 {
+		return m.Seed
+	}
+	return SeedFromID(m.ID)
 }
 
 // MatchRound представляет группу матчей одного раунда для конкретной игры
@@ -207,6 +605,110 @@ type MatchRound struct {
 	CreatedAt      time.Time `json:"created_at"`
 }
 
+// GameRoundProgress прогресс текущего (последнего) раунда игры турнира.
+// RoundNumber = 0, если раундов у игры ещё не было
+type GameRoundProgress struct {
+	TournamentID   uuid.UUID `json:"tournament_id"`
+	GameID         uuid.UUID `json:"game_id"`
+	GameType       string    `json:"game_type"`
+	RoundNumber    int       `json:"round_number"`
+	TotalMatches   int       `json:"total_matches"`
+	CompletedCount int       `json:"completed_count"`
+	PendingCount   int       `json:"pending_count"`
+	RunningCount   int       `json:"running_count"`
+	FailedCount    int       `json:"failed_count"`
+	Percent        float64   `json:"percent"` // Доля завершённых (completed + failed) матчей, 0-100
+}
+
+// RoundMatches страница матчей одного раунда со сводкой по всему раунду
+type RoundMatches struct {
+	RoundNumber int                  `json:"round_number"`
+	Summary     TournamentMatchStats `json:"summary"`
+	Matches     []*Match             `json:"matches"`
+	Limit       int                  `json:"limit"`
+	Offset      int                  `json:"offset"`
+}
+
+// TournamentMatchStats количество матчей турнира по статусам
+type TournamentMatchStats struct {
+	Total     int `json:"total" db:"total"`
+	Pending   int `json:"pending" db:"pending"`
+	Running   int `json:"running" db:"running"`
+	Completed int `json:"completed" db:"completed"`
+	Failed    int `json:"failed" db:"failed"`
+	Cancelled int `json:"cancelled" db:"cancelled"`
+}
+
+// CompletionPercent доля завершённых матчей (включая упавшие и отменённые) в процентах
+func (s TournamentMatchStats) CompletionPercent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	finished := s.Completed + s.Failed + s.Cancelled
+	return math.Round(float64(finished)*1000/float64(s.Total)) / 10
+}
+
+// ProgramStats сводная статистика сыгранных матчей программы во всех турнирах.
+// TotalMatches = Wins + Losses + Draws + Failed
+type ProgramStats struct {
+	ProgramID               uuid.UUID `json:"program_id" db:"program_id"`
+	TotalMatches            int64     `json:"total_matches" db:"total_matches"`
+	Wins                    int64     `json:"wins" db:"wins"`
+	Losses                  int64     `json:"losses" db:"losses"`
+	Draws                   int64     `json:"draws" db:"draws"`
+	Failed                  int64     `json:"failed" db:"failed"`
+	AvgScore                float64   `json:"avg_score" db:"avg_score"`
+	TournamentsParticipated int64     `json:"tournaments_participated" db:"tournaments_participated"`
+}
+
+// HeadToHeadRecord итог личных встреч двух программ со стороны программы A
+type HeadToHeadRecord struct {
+	ProgramA     uuid.UUID  `json:"program_a"`
+	ProgramB     uuid.UUID  `json:"program_b"`
+	TournamentID *uuid.UUID `json:"tournament_id,omitempty"`
+	Wins         int64      `json:"wins"`
+	Losses       int64      `json:"losses"`
+	Draws        int64      `json:"draws"`
+	Matches      []*Match   `json:"matches"`
+}
+
+// HeadToHeadTotals счёт личных встреч со стороны команды A
+type HeadToHeadTotals struct {
+	Matches      int64 `json:"matches"`
+	Wins         int64 `json:"wins"`
+	Losses       int64 `json:"losses"`
+	Draws        int64 `json:"draws"`
+	ScoreFor     int64 `json:"score_for"`
+	ScoreAgainst int64 `json:"score_against"`
+}
+
+// Add добавляет к счёту счёт other
+func (t *HeadToHeadTotals) Add(other HeadToHeadTotals) {
+	t.Matches += other.Matches
+	t.Wins += other.Wins
+	t.Losses += other.Losses
+	t.Draws += other.Draws
+	t.ScoreFor += other.ScoreFor
+	t.ScoreAgainst += other.ScoreAgainst
+}
+
+// GameHeadToHead личные встречи команд в одной игре: счёт и последние матчи
+type GameHeadToHead struct {
+	GameType string `json:"game_type"`
+	HeadToHeadTotals
+	RecentMatches []*Match `json:"recent_matches"`
+}
+
+// TeamHeadToHead итог личных встреч двух команд в турнире со стороны команды A.
+// Учитываются все версии программ команд; Totals - сумма по играм
+type TeamHeadToHead struct {
+	TournamentID uuid.UUID         `json:"tournament_id"`
+	TeamA        uuid.UUID         `json:"team_a"`
+	TeamB        uuid.UUID         `json:"team_b"`
+	Totals       HeadToHeadTotals  `json:"totals"`
+	Games        []*GameHeadToHead `json:"games"`
+}
+
 // RatingHistory представляет историю изменения рейтинга
 type RatingHistory struct {
 	ID           uuid.UUID  `json:"id" db:"id"`
@@ -228,6 +730,34 @@ type MatchResult struct {
 	ErrorCode    int // exit code от tjudge-cli
 	ErrorMessage string
 	Duration     time.Duration
+	Timing       ExecutionTiming // Метки запуска контейнера
+}
+
+// MatchErrorOutOfMemory код ошибки матча, контейнер которого остановлен OOM killer
+// (128 + SIGKILL, как код выхода такого контейнера)
+const MatchErrorOutOfMemory = 137
+
+// IsOutOfMemory проверяет, что матч остановлен из-за превышения лимита памяти
+func (r *MatchResult) IsOutOfMemory() bool {
+	return r.ErrorCode == MatchErrorOutOfMemory
+}
+
+// MatchErrorInfrastructure код ошибки матча, не сыгранного из-за временного сбоя
+// инфраструктуры (Docker, загрузка образа) после всех повторов. Программы в нём не виноваты.
+// Совпадает с кодом выхода docker run при ошибке самого Docker
+const MatchErrorInfrastructure = 125
+
+// IsInfrastructureFailure проверяет, что матч не сыгран из-за сбоя инфраструктуры
+func (r *MatchResult) IsInfrastructureFailure() bool {
+	return r.ErrorCode == MatchErrorInfrastructure
+}
+
+// ProgramInfo краткая информация о программе и её команде (для экспорта и отчётов)
+type ProgramInfo struct {
+	ProgramID   uuid.UUID  `json:"program_id" db:"program_id"`
+	ProgramName string     `json:"program_name" db:"program_name"`
+	TeamID      *uuid.UUID `json:"team_id,omitempty" db:"team_id"`
+	TeamName    *string    `json:"team_name,omitempty" db:"team_name"`
 }
 
 // LeaderboardEntry - запись в таблице лидеров
@@ -242,6 +772,8 @@ type LeaderboardEntry struct {
 	Losses      int        `json:"losses" db:"losses"`
 	Draws       int        `json:"draws" db:"draws"`
 	TotalGames  int        `json:"total_games" db:"total_games"`
+
+	RegisteredAt time.Time `json:"-" db:"registered_at"` // Время регистрации, для tie-break
 }
 
 // TeamLeaderboardEntry - запись в таблице лидеров для команд
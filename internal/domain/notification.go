@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationEvent тип события, о котором уведомляется пользователь
+type NotificationEvent string
+
+const (
+	NotificationMatchesCompleted    NotificationEvent = "matches_completed"    // Сыграны все матчи программы команды
+	NotificationTournamentStarted   NotificationEvent = "tournament_started"   // Турнир команды запущен
+	NotificationTournamentCompleted NotificationEvent = "tournament_completed" // Турнир команды завершён
+	NotificationTournamentCancelled NotificationEvent = "tournament_cancelled" // Турнир команды отменён
+	NotificationCompileFailed       NotificationEvent = "compile_failed"       // Программа команды не скомпилировалась
+)
+
+// NotificationEvents все типы событий уведомлений
+var NotificationEvents = []NotificationEvent{
+	NotificationMatchesCompleted,
+	NotificationTournamentStarted,
+	NotificationTournamentCompleted,
+	NotificationTournamentCancelled,
+	NotificationCompileFailed,
+}
+
+// IsValid проверяет, что тип события известен
+func (e NotificationEvent) IsValid() bool {
+	for _, event := range NotificationEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationChannel канал доставки уведомлений
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail   NotificationChannel = "email"
+	NotificationChannelWebhook NotificationChannel = "webhook" // События турнира на webhook
+)
+
+// NotificationStatus статус уведомления в outbox
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed" // Попытки доставки исчерпаны
+)
+
+// NotificationPreferences подписки пользователя на события.
+// Событие, отсутствующее в map, включено
+type NotificationPreferences map[NotificationEvent]bool
+
+// Enabled проверяет, подписан ли пользователь на событие
+func (p NotificationPreferences) Enabled(event NotificationEvent) bool {
+	enabled, ok := p[event]
+	return !ok || enabled
+}
+
+// Effective возвращает подписки по всем типам событий с учётом значений по умолчанию
+func (p NotificationPreferences) Effective() NotificationPreferences {
+	effective := make(NotificationPreferences, len(NotificationEvents))
+	for _, event := range NotificationEvents {
+		effective[event] = p.Enabled(event)
+	}
+	return effective
+}
+
+// Validate проверяет, что в подписках только известные типы событий
+func (p NotificationPreferences) Validate() error {
+	for event := range p {
+		if !event.IsValid() {
+			return fmt.Errorf("unknown notification event: %s", event)
+		}
+	}
+	return nil
+}
+
+// NotificationMessage содержимое уведомления о событии. DedupeKey исключает повторную
+// отправку одного и того же события пользователю
+type NotificationMessage struct {
+	Event     NotificationEvent
+	Subject   string
+	Body      string
+	DedupeKey string
+}
+
+// Notification уведомление в outbox, ожидающее доставки.
+// Адресовано либо пользователю (UserID), либо webhook'у турнира (WebhookID)
+type Notification struct {
+	ID            int64               `json:"id" db:"id"`
+	UserID        *uuid.UUID          `json:"user_id,omitempty" db:"user_id"`
+	WebhookID     *uuid.UUID          `json:"webhook_id,omitempty" db:"webhook_id"`
+	Channel       NotificationChannel `json:"channel" db:"channel"`
+	Recipient     string              `json:"recipient" db:"recipient"`
+	Event         NotificationEvent   `json:"event" db:"event"`
+	Subject       string              `json:"subject" db:"subject"`
+	Body          string              `json:"body" db:"body"`
+	DedupeKey     string              `json:"dedupe_key" db:"dedupe_key"`
+	Status        NotificationStatus  `json:"status" db:"status"`
+	Attempts      int                 `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time           `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     *string             `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time           `json:"created_at" db:"created_at"`
+	SentAt        *time.Time          `json:"sent_at,omitempty" db:"sent_at"`
+}
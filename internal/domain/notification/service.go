@@ -0,0 +1,187 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Repository интерфейс outbox уведомлений
+type Repository interface {
+	EnqueueForProgram(ctx context.Context, programID uuid.UUID, msg *domain.NotificationMessage) (int64, error)
+	EnqueueForTournament(ctx context.Context, tournamentID uuid.UUID, msg *domain.NotificationMessage) (int64, error)
+	GetProgramMatchProgress(ctx context.Context, programID uuid.UUID) (unfinished, total int, err error)
+}
+
+// PreferencesRepository интерфейс хранения подписок пользователей
+type PreferencesRepository interface {
+	GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (domain.NotificationPreferences, error)
+	UpdateNotificationPreferences(ctx context.Context, userID uuid.UUID, prefs domain.NotificationPreferences) (domain.NotificationPreferences, error)
+}
+
+// Service - сервис уведомлений. События записываются в outbox вместе с получателями,
+// подписанными на них; доставкой занимается notify.Dispatcher
+type Service struct {
+	repo    Repository
+	prefs   PreferencesRepository
+	baseURL string
+	log     *logger.Logger
+}
+
+// NewService создаёт новый сервис уведомлений
+func NewService(repo Repository, prefs PreferencesRepository, log *logger.Logger) *Service {
+	return &Service{
+		repo:  repo,
+		prefs: prefs,
+		log:   log,
+	}
+}
+
+// SetBaseURL задаёт адрес веб-интерфейса для ссылок в уведомлениях
+func (s *Service) SetBaseURL(baseURL string) {
+	s.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// GetPreferences возвращает подписки пользователя по всем типам событий
+func (s *Service) GetPreferences(ctx context.Context, userID uuid.UUID) (domain.NotificationPreferences, error) {
+	prefs, err := s.prefs.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return prefs.Effective(), nil
+}
+
+// UpdatePreferences меняет подписки на переданные события, остальные остаются прежними
+func (s *Service) UpdatePreferences(ctx context.Context, userID uuid.UUID, prefs domain.NotificationPreferences) (domain.NotificationPreferences, error) {
+	if len(prefs) == 0 {
+		return nil, errors.ErrValidation.WithMessage("no notification events given")
+	}
+	if err := prefs.Validate(); err != nil {
+		return nil, errors.ErrValidation.WithMessage(err.Error())
+	}
+
+	updated, err := s.prefs.UpdateNotificationPreferences(ctx, userID, prefs)
+	if err != nil {
+		return nil, err
+	}
+	return updated.Effective(), nil
+}
+
+// TournamentStarted уведомляет участников о старте турнира
+func (s *Service) TournamentStarted(ctx context.Context, tournament *domain.Tournament) error {
+	msg := &domain.NotificationMessage{
+		Event:     domain.NotificationTournamentStarted,
+		Subject:   fmt.Sprintf("Турнир «%s» начался", tournament.Name),
+		Body:      s.withLink(fmt.Sprintf("Турнир «%s» запущен, матчи скоро начнутся.", tournament.Name), tournament.ID),
+		DedupeKey: fmt.Sprintf("tournament_started:%s", tournament.ID),
+	}
+	return s.enqueueForTournament(ctx, tournament.ID, msg)
+}
+
+// TournamentCompleted уведомляет участников о завершении турнира
+func (s *Service) TournamentCompleted(ctx context.Context, tournament *domain.Tournament) error {
+	msg := &domain.NotificationMessage{
+		Event:     domain.NotificationTournamentCompleted,
+		Subject:   fmt.Sprintf("Турнир «%s» завершён", tournament.Name),
+		Body:      s.withLink(fmt.Sprintf("Турнир «%s» завершён. Итоговая таблица доступна на странице турнира.", tournament.Name), tournament.ID),
+		DedupeKey: fmt.Sprintf("tournament_completed:%s", tournament.ID),
+	}
+	return s.enqueueForTournament(ctx, tournament.ID, msg)
+}
+
+// TournamentCancelled уведомляет участников об отмене турнира
+func (s *Service) TournamentCancelled(ctx context.Context, tournament *domain.Tournament) error {
+	msg := &domain.NotificationMessage{
+		Event:     domain.NotificationTournamentCancelled,
+		Subject:   fmt.Sprintf("Турнир «%s» отменён", tournament.Name),
+		Body:      s.withLink(fmt.Sprintf("Турнир «%s» отменён организаторами, несыгранные матчи не состоятся.", tournament.Name), tournament.ID),
+		DedupeKey: fmt.Sprintf("tournament_cancelled:%s", tournament.ID),
+	}
+	return s.enqueueForTournament(ctx, tournament.ID, msg)
+}
+
+// CompileFailed уведомляет команду о том, что её программа не скомпилировалась
+func (s *Service) CompileFailed(ctx context.Context, program *domain.Program, message string) error {
+	body := fmt.Sprintf("Программа «%s» (версия %d) не скомпилировалась, её матчи засчитаны как поражения.\n\n%s",
+		program.Name, program.Version, message)
+	if program.TournamentID != nil {
+		body = s.withLink(body, *program.TournamentID)
+	}
+
+	msg := &domain.NotificationMessage{
+		Event:     domain.NotificationCompileFailed,
+		Subject:   fmt.Sprintf("Ошибка компиляции программы «%s»", program.Name),
+		Body:      body,
+		DedupeKey: fmt.Sprintf("compile_failed:%s", program.ID),
+	}
+	return s.enqueueForProgram(ctx, program.ID, msg)
+}
+
+// ProgramMatchesCompleted уведомляет команду, если сыграны все матчи её программы.
+// Новые соперники добавляют программе матчи, поэтому ключ включает их общее число:
+// о каждой следующей завершённой серии приходит отдельное уведомление
+func (s *Service) ProgramMatchesCompleted(ctx context.Context, program *domain.Program) error {
+	unfinished, total, err := s.repo.GetProgramMatchProgress(ctx, program.ID)
+	if err != nil {
+		return err
+	}
+	if unfinished > 0 || total == 0 {
+		return nil
+	}
+
+	body := fmt.Sprintf("Все матчи программы «%s» (версия %d) сыграны: %d.", program.Name, program.Version, total)
+	if program.TournamentID != nil {
+		body = s.withLink(body, *program.TournamentID)
+	}
+
+	msg := &domain.NotificationMessage{
+		Event:     domain.NotificationMatchesCompleted,
+		Subject:   fmt.Sprintf("Матчи программы «%s» завершены", program.Name),
+		Body:      body,
+		DedupeKey: fmt.Sprintf("matches_completed:%s:%d", program.ID, total),
+	}
+	return s.enqueueForProgram(ctx, program.ID, msg)
+}
+
+func (s *Service) enqueueForTournament(ctx context.Context, tournamentID uuid.UUID, msg *domain.NotificationMessage) error {
+	enqueued, err := s.repo.EnqueueForTournament(ctx, tournamentID, msg)
+	if err != nil {
+		return err
+	}
+	s.logEnqueued(msg, enqueued, zap.String("tournament_id", tournamentID.String()))
+	return nil
+}
+
+func (s *Service) enqueueForProgram(ctx context.Context, programID uuid.UUID, msg *domain.NotificationMessage) error {
+	enqueued, err := s.repo.EnqueueForProgram(ctx, programID, msg)
+	if err != nil {
+		return err
+	}
+	s.logEnqueued(msg, enqueued, zap.String("program_id", programID.String()))
+	return nil
+}
+
+func (s *Service) logEnqueued(msg *domain.NotificationMessage, enqueued int64, field zap.Field) {
+	if enqueued == 0 {
+		return
+	}
+	s.log.Info("Notifications enqueued",
+		zap.String("event", string(msg.Event)),
+		zap.Int64("recipients", enqueued),
+		field,
+	)
+}
+
+// withLink добавляет к тексту ссылку на страницу турнира
+func (s *Service) withLink(body string, tournamentID uuid.UUID) string {
+	if s.baseURL == "" {
+		return body
+	}
+	return fmt.Sprintf("%s\n\n%s/tournaments/%s", body, s.baseURL, tournamentID)
+}
@@ -0,0 +1,163 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type enqueued struct {
+	targetID uuid.UUID
+	msg      *domain.NotificationMessage
+}
+
+// memoryOutbox records enqueued messages and reports a fixed match progress
+type memoryOutbox struct {
+	byProgram    []enqueued
+	byTournament []enqueued
+	unfinished   int
+	total        int
+}
+
+func (r *memoryOutbox) EnqueueForProgram(_ context.Context, programID uuid.UUID, msg *domain.NotificationMessage) (int64, error) {
+	r.byProgram = append(r.byProgram, enqueued{programID, msg})
+	return 1, nil
+}
+
+func (r *memoryOutbox) EnqueueForTournament(_ context.Context, tournamentID uuid.UUID, msg *domain.NotificationMessage) (int64, error) {
+	r.byTournament = append(r.byTournament, enqueued{tournamentID, msg})
+	return 2, nil
+}
+
+func (r *memoryOutbox) GetProgramMatchProgress(_ context.Context, _ uuid.UUID) (int, int, error) {
+	return r.unfinished, r.total, nil
+}
+
+// memoryPreferences merges updates like the PostgreSQL jsonb || operator
+type memoryPreferences struct {
+	prefs domain.NotificationPreferences
+}
+
+func (r *memoryPreferences) GetNotificationPreferences(_ context.Context, _ uuid.UUID) (domain.NotificationPreferences, error) {
+	return r.prefs, nil
+}
+
+func (r *memoryPreferences) UpdateNotificationPreferences(_ context.Context, _ uuid.UUID, prefs domain.NotificationPreferences) (domain.NotificationPreferences, error) {
+	for event, enabled := range prefs {
+		r.prefs[event] = enabled
+	}
+	return r.prefs, nil
+}
+
+func newTestService(outbox *memoryOutbox) *Service {
+	log, _ := logger.New("error", "json")
+	return NewService(outbox, &memoryPreferences{prefs: domain.NotificationPreferences{}}, log)
+}
+
+func TestService_ProgramMatchesCompleted(t *testing.T) {
+	tournamentID := uuid.New()
+	program := &domain.Program{ID: uuid.New(), Name: "defector", Version: 3, TournamentID: &tournamentID}
+
+	t.Run("waits for unfinished matches", func(t *testing.T) {
+		outbox := &memoryOutbox{unfinished: 2, total: 10}
+		require.NoError(t, newTestService(outbox).ProgramMatchesCompleted(context.Background(), program))
+		assert.Empty(t, outbox.byProgram)
+	})
+
+	t.Run("no matches yet", func(t *testing.T) {
+		outbox := &memoryOutbox{}
+		require.NoError(t, newTestService(outbox).ProgramMatchesCompleted(context.Background(), program))
+		assert.Empty(t, outbox.byProgram)
+	})
+
+	t.Run("enqueues once all matches are played", func(t *testing.T) {
+		outbox := &memoryOutbox{total: 10}
+		service := newTestService(outbox)
+		service.SetBaseURL("https://judge.example.com/")
+
+		require.NoError(t, service.ProgramMatchesCompleted(context.Background(), program))
+		require.Len(t, outbox.byProgram, 1)
+		sent := outbox.byProgram[0]
+		assert.Equal(t, program.ID, sent.targetID)
+		assert.Equal(t, domain.NotificationMatchesCompleted, sent.msg.Event)
+		assert.Contains(t, sent.msg.Subject, "defector")
+		assert.Contains(t, sent.msg.Body, "https://judge.example.com/tournaments/"+tournamentID.String())
+		assert.Equal(t, "matches_completed:"+program.ID.String()+":10", sent.msg.DedupeKey)
+	})
+
+	t.Run("new opponents give a new dedupe key", func(t *testing.T) {
+		outbox := &memoryOutbox{total: 10}
+		service := newTestService(outbox)
+		require.NoError(t, service.ProgramMatchesCompleted(context.Background(), program))
+		outbox.total = 12
+		require.NoError(t, service.ProgramMatchesCompleted(context.Background(), program))
+
+		require.Len(t, outbox.byProgram, 2)
+		assert.NotEqual(t, outbox.byProgram[0].msg.DedupeKey, outbox.byProgram[1].msg.DedupeKey)
+	})
+}
+
+func TestService_TournamentEvents(t *testing.T) {
+	tournament := &domain.Tournament{ID: uuid.New(), Name: "Spring Cup"}
+	outbox := &memoryOutbox{}
+	service := newTestService(outbox)
+
+	require.NoError(t, service.TournamentStarted(context.Background(), tournament))
+	require.NoError(t, service.TournamentCompleted(context.Background(), tournament))
+	require.NoError(t, service.TournamentCancelled(context.Background(), tournament))
+
+	require.Len(t, outbox.byTournament, 3)
+	assert.Equal(t, domain.NotificationTournamentStarted, outbox.byTournament[0].msg.Event)
+	assert.Equal(t, domain.NotificationTournamentCompleted, outbox.byTournament[1].msg.Event)
+	assert.Equal(t, domain.NotificationTournamentCancelled, outbox.byTournament[2].msg.Event)
+	for _, sent := range outbox.byTournament {
+		assert.Equal(t, tournament.ID, sent.targetID)
+		assert.Contains(t, sent.msg.Subject, "Spring Cup")
+		// No base URL configured: no link
+		assert.NotContains(t, sent.msg.Body, "/tournaments/")
+	}
+}
+
+func TestService_CompileFailed(t *testing.T) {
+	program := &domain.Program{ID: uuid.New(), Name: "bot", Version: 1}
+	outbox := &memoryOutbox{}
+
+	require.NoError(t, newTestService(outbox).CompileFailed(context.Background(), program, "main.go:3: undefined: x"))
+
+	require.Len(t, outbox.byProgram, 1)
+	msg := outbox.byProgram[0].msg
+	assert.Equal(t, domain.NotificationCompileFailed, msg.Event)
+	assert.Contains(t, msg.Body, "main.go:3: undefined: x")
+	assert.Equal(t, "compile_failed:"+program.ID.String(), msg.DedupeKey)
+}
+
+func TestService_Preferences(t *testing.T) {
+	userID := uuid.New()
+	service := newTestService(&memoryOutbox{})
+
+	prefs, err := service.GetPreferences(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Len(t, prefs, len(domain.NotificationEvents))
+	for _, event := range domain.NotificationEvents {
+		assert.True(t, prefs[event], event)
+	}
+
+	prefs, err = service.UpdatePreferences(context.Background(), userID,
+		domain.NotificationPreferences{domain.NotificationMatchesCompleted: false})
+	require.NoError(t, err)
+	assert.False(t, prefs[domain.NotificationMatchesCompleted])
+	assert.True(t, prefs[domain.NotificationCompileFailed])
+
+	_, err = service.UpdatePreferences(context.Background(), userID,
+		domain.NotificationPreferences{"spam": true})
+	assert.Equal(t, errors.ErrValidation.Code, errors.GetAppError(err).Code)
+
+	_, err = service.UpdatePreferences(context.Background(), userID, domain.NotificationPreferences{})
+	assert.Equal(t, errors.ErrValidation.Code, errors.GetAppError(err).Code)
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationPreferences(t *testing.T) {
+	prefs := NotificationPreferences{NotificationMatchesCompleted: false}
+
+	// Events missing from the map are enabled by default
+	assert.False(t, prefs.Enabled(NotificationMatchesCompleted))
+	assert.True(t, prefs.Enabled(NotificationTournamentStarted))
+
+	effective := prefs.Effective()
+	assert.Len(t, effective, len(NotificationEvents))
+	assert.False(t, effective[NotificationMatchesCompleted])
+	assert.True(t, effective[NotificationCompileFailed])
+
+	assert.NoError(t, prefs.Validate())
+	assert.Error(t, NotificationPreferences{"newsletter": true}.Validate())
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// MetaPointsScoring ключ метаданных турнира: таблица лидеров по очкам за исход матча
+// вместо суммы очков игры. true - очки по умолчанию (3/1/0),
+// объект {"win": 3, "draw": 1, "loss": 0} - свои значения, false или отсутствие - выключено
+const MetaPointsScoring = "points_scoring"
+
+// PointsScoring очки за победу, ничью и поражение
+type PointsScoring struct {
+	Win  int `json:"win"`
+	Draw int `json:"draw"`
+	Loss int `json:"loss"`
+}
+
+// DefaultPointsScoring очки по умолчанию: победа 3, ничья 1, поражение 0
+var DefaultPointsScoring = PointsScoring{Win: 3, Draw: 1, Loss: 0}
+
+// Points возвращает очки участника по количеству побед, ничьих и поражений
+func (s PointsScoring) Points(wins, draws, losses int) int {
+	return wins*s.Win + draws*s.Draw + losses*s.Loss
+}
+
+// PointsScoring возвращает очки за исход матча из метаданных.
+// nil - таблица лидеров считается по сумме очков игры
+func (t *Tournament) PointsScoring() *PointsScoring {
+	scoring, err := parsePointsScoring(t.Metadata[MetaPointsScoring])
+	if err != nil {
+		return nil
+	}
+	return scoring
+}
+
+// parsePointsScoring разбирает значение метаданных: после JSON это bool или map[string]interface{}.
+// Не указанные в объекте исходы получают очки по умолчанию
+func parsePointsScoring(value interface{}) (*PointsScoring, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		if !v {
+			return nil, nil
+		}
+		scoring := DefaultPointsScoring
+		return &scoring, nil
+	case map[string]interface{}:
+		scoring := DefaultPointsScoring
+		for key, raw := range v {
+			var target *int
+			switch key {
+			case "win":
+				target = &scoring.Win
+			case "draw":
+				target = &scoring.Draw
+			case "loss":
+				target = &scoring.Loss
+			default:
+				return nil, fmt.Errorf("unknown points_scoring outcome: %s", key)
+			}
+
+			points, ok := raw.(float64)
+			if !ok || points != math.Trunc(points) {
+				return nil, fmt.Errorf("points for %s must be an integer", key)
+			}
+			*target = int(points)
+		}
+		return &scoring, nil
+	default:
+		return nil, fmt.Errorf("points_scoring must be a boolean or an object, got %T", value)
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTournament_PointsScoring(t *testing.T) {
+	t.Run("disabled when not set", func(t *testing.T) {
+		assert.Nil(t, (&Tournament{}).PointsScoring())
+		assert.Nil(t, (&Tournament{Metadata: map[string]interface{}{MetaPointsScoring: false}}).PointsScoring())
+	})
+
+	t.Run("true enables default points", func(t *testing.T) {
+		tournament := &Tournament{Metadata: map[string]interface{}{MetaPointsScoring: true}}
+		require.NotNil(t, tournament.PointsScoring())
+		assert.Equal(t, DefaultPointsScoring, *tournament.PointsScoring())
+	})
+
+	t.Run("custom points from JSON metadata", func(t *testing.T) {
+		tournament := &Tournament{Metadata: map[string]interface{}{
+			MetaPointsScoring: map[string]interface{}{"win": float64(2), "loss": float64(-1)},
+		}}
+		scoring := tournament.PointsScoring()
+		require.NotNil(t, scoring)
+		assert.Equal(t, PointsScoring{Win: 2, Draw: 1, Loss: -1}, *scoring)
+		assert.Equal(t, 2*5+1*2-1*3, scoring.Points(5, 2, 3))
+	})
+
+	t.Run("invalid values fail validation", func(t *testing.T) {
+		for _, value := range []interface{}{
+			"3/1/0",
+			map[string]interface{}{"win": 2.5},
+			map[string]interface{}{"bye": float64(1)},
+		} {
+			tournament := &Tournament{
+				Name:     "Cup",
+				GameType: "chess",
+				Status:   TournamentPending,
+				Metadata: map[string]interface{}{MetaPointsScoring: value},
+			}
+			assert.Error(t, tournament.Validate(), "%v", value)
+			assert.Nil(t, tournament.PointsScoring())
+		}
+	})
+}
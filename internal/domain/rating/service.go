@@ -82,6 +82,43 @@ func (s *Service) ProcessMatchResult(ctx context.Context, match *domain.Match, r
 	return nil
 }
 
+// ProcessReferenceMatchResult обрабатывает калибровочный матч с эталонным ботом.
+// Рейтинг бота фиксирован, поэтому обновляется только рейтинг программы-участника.
+// reference - сторона эталонного бота в матче (1 или 2)
+func (s *Service) ProcessReferenceMatchResult(ctx context.Context, match *domain.Match, rating1, rating2, reference int) error {
+	winner := *match.Winner
+	newRating1, newRating2, change1, change2 := s.calculator.ProcessMatch(rating1, rating2, winner)
+
+	programID, oldRating, newRating, change, won := match.Program1ID, rating1, newRating1, change1, winner == 1
+	if reference == 1 {
+		programID, oldRating, newRating, change, won = match.Program2ID, rating2, newRating2, change2, winner == 2
+	}
+
+	s.log.Info("Processing calibration match result",
+		zap.String("match_id", match.ID.String()),
+		zap.String("program_id", programID.String()),
+		zap.Int("rating_old", oldRating),
+		zap.Int("rating_new", newRating),
+		zap.Int("rating_change", change),
+	)
+
+	if err := s.updateParticipantRating(ctx, match, programID, oldRating, newRating, change); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateParticipantStats(ctx, match.TournamentID, programID, won, winner == 0); err != nil {
+		s.log.LogError("Failed to update match stats", err,
+			zap.String("match_id", match.ID.String()),
+		)
+	}
+
+	if err := s.leaderboardCache.UpdateRating(ctx, match.TournamentID, programID, newRating); err != nil {
+		s.log.LogError("Failed to update leaderboard cache", err)
+	}
+
+	return nil
+}
+
 // updateParticipantRating обновляет рейтинг участника в БД
 func (s *Service) updateParticipantRating(
 	ctx context.Context,
@@ -3,6 +3,7 @@ package team
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
@@ -36,6 +37,11 @@ type TournamentRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error)
 }
 
+// DistributedLock интерфейс для распределённых блокировок
+type DistributedLock interface {
+	WithLock(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) error
+}
+
 // CreateTeamRequest - запрос на создание команды
 type CreateTeamRequest struct {
 	TournamentID uuid.UUID `json:"tournament_id" validate:"required"`
@@ -56,17 +62,19 @@ type UpdateTeamRequest struct {
 
 // Service предоставляет бизнес-логику для работы с командами
 type Service struct {
-	teamRepo       TeamRepository
-	tournamentRepo TournamentRepository
-	log            *logger.Logger
+	teamRepo        TeamRepository
+	tournamentRepo  TournamentRepository
+	distributedLock DistributedLock
+	log             *logger.Logger
 }
 
 // NewService создаёт новый сервис команд
-func NewService(teamRepo TeamRepository, tournamentRepo TournamentRepository, log *logger.Logger) *Service {
+func NewService(teamRepo TeamRepository, tournamentRepo TournamentRepository, distributedLock DistributedLock, log *logger.Logger) *Service {
 	return &Service{
-		teamRepo:       teamRepo,
-		tournamentRepo: tournamentRepo,
-		log:            log,
+		teamRepo:        teamRepo,
+		tournamentRepo:  tournamentRepo,
+		distributedLock: distributedLock,
+		log:             log,
 	}
 }
 
@@ -143,34 +151,45 @@ func (s *Service) JoinTeamByCode(ctx context.Context, req *JoinTeamRequest) (*do
 		return nil, errors.ErrBadRequest.WithMessage("cannot join team in active or completed tournament")
 	}
 
-	// Проверяем лимит участников команды
-	memberCount, err := s.teamRepo.GetMemberCount(ctx, team.ID)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get member count")
-	}
+	// Проверка лимита и добавление выполняются под блокировкой команды,
+	// иначе параллельные вступления могут превысить MaxTeamSize
+	lockKey := fmt.Sprintf("team:join:%s", team.ID.String())
 
-	if tournament.MaxTeamSize > 0 && memberCount >= tournament.MaxTeamSize {
-		return nil, errors.ErrBadRequest.WithMessage("team is full")
-	}
+	err = s.distributedLock.WithLock(ctx, lockKey, 5*time.Second, func(ctx context.Context) error {
+		// Перечитываем число участников внутри блокировки
+		memberCount, err := s.teamRepo.GetMemberCount(ctx, team.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get member count")
+		}
 
-	// Проверяем что пользователь не состоит в другой команде
-	inTeam, err := s.teamRepo.IsUserInAnyTeamInTournament(ctx, team.TournamentID, req.UserID)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to check user team membership")
-	}
-	if inTeam {
-		return nil, errors.ErrConflict.WithMessage("user already in a team in this tournament")
-	}
+		if tournament.MaxTeamSize > 0 && memberCount >= tournament.MaxTeamSize {
+			return errors.ErrTeamFull
+		}
 
-	// Добавляем пользователя в команду
-	member := &domain.TeamMember{
-		ID:     uuid.New(),
-		TeamID: team.ID,
-		UserID: req.UserID,
-	}
+		// Проверяем что пользователь не состоит в другой команде
+		inTeam, err := s.teamRepo.IsUserInAnyTeamInTournament(ctx, team.TournamentID, req.UserID)
+		if err != nil {
+			return errors.Wrap(err, "failed to check user team membership")
+		}
+		if inTeam {
+			return errors.ErrConflict.WithMessage("user already in a team in this tournament")
+		}
 
-	if err := s.teamRepo.AddMember(ctx, member); err != nil {
-		return nil, errors.Wrap(err, "failed to add team member")
+		// Добавляем пользователя в команду
+		member := &domain.TeamMember{
+			ID:     uuid.New(),
+			TeamID: team.ID,
+			UserID: req.UserID,
+		}
+
+		if err := s.teamRepo.AddMember(ctx, member); err != nil {
+			return errors.Wrap(err, "failed to add team member")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	s.log.Info("User joined team", zap.String("team_id", team.ID.String()), zap.String("user_id", req.UserID.String()))
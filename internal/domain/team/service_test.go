@@ -0,0 +1,150 @@
+package team
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTeamRepo keeps members of a single team in memory.
+// Unused TeamRepository methods panic through the embedded nil interface.
+type memoryTeamRepo struct {
+	TeamRepository
+
+	team    *domain.Team
+	mu      sync.Mutex
+	members map[uuid.UUID]bool
+}
+
+func (r *memoryTeamRepo) GetByCode(_ context.Context, code string) (*domain.Team, error) {
+	if code != r.team.Code {
+		return nil, errors.ErrNotFound
+	}
+	return r.team, nil
+}
+
+func (r *memoryTeamRepo) GetMemberCount(_ context.Context, _ uuid.UUID) (int, error) {
+	r.mu.Lock()
+	count := len(r.members)
+	r.mu.Unlock()
+
+	// Widen the window between the check and the insert
+	time.Sleep(time.Millisecond)
+	return count, nil
+}
+
+func (r *memoryTeamRepo) IsUserInAnyTeamInTournament(_ context.Context, _, userID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.members[userID], nil
+}
+
+func (r *memoryTeamRepo) AddMember(_ context.Context, member *domain.TeamMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.members[member.UserID] = true
+	return nil
+}
+
+type staticTournamentRepo struct {
+	tournament *domain.Tournament
+}
+
+func (r staticTournamentRepo) GetByID(_ context.Context, _ uuid.UUID) (*domain.Tournament, error) {
+	return r.tournament, nil
+}
+
+// mutexLock emulates the distributed lock with a local mutex per key
+type mutexLock struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+	calls atomic.Int32
+}
+
+func (l *mutexLock) WithLock(ctx context.Context, key string, _ time.Duration, fn func(ctx context.Context) error) error {
+	l.calls.Add(1)
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*sync.Mutex)
+	}
+	keyLock, ok := l.locks[key]
+	if !ok {
+		keyLock = &sync.Mutex{}
+		l.locks[key] = keyLock
+	}
+	l.mu.Unlock()
+
+	keyLock.Lock()
+	defer keyLock.Unlock()
+	return fn(ctx)
+}
+
+// TestConcurrentTeamJoin tests that simultaneous joins don't exceed max team size
+func TestConcurrentTeamJoin(t *testing.T) {
+	const (
+		maxTeamSize = 4
+		joiners     = 50
+	)
+
+	tournament := &domain.Tournament{
+		ID:          uuid.New(),
+		Status:      domain.TournamentPending,
+		MaxTeamSize: maxTeamSize,
+	}
+	leaderID := uuid.New()
+	team := &domain.Team{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		Code:         "TEAM42",
+		LeaderID:     leaderID,
+	}
+
+	repo := &memoryTeamRepo{team: team, members: map[uuid.UUID]bool{leaderID: true}}
+	lock := &mutexLock{}
+
+	log, _ := logger.New("error", "json")
+	service := NewService(repo, staticTournamentRepo{tournament: tournament}, lock, log)
+
+	var (
+		wg      sync.WaitGroup
+		joined  atomic.Int32
+		full    atomic.Int32
+		unknown atomic.Int32
+	)
+	start := make(chan struct{})
+	for i := 0; i < joiners; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			_, err := service.JoinTeamByCode(context.Background(), &JoinTeamRequest{Code: team.Code, UserID: uuid.New()})
+			switch {
+			case err == nil:
+				joined.Add(1)
+			case errors.GetAppError(err) == errors.ErrTeamFull:
+				full.Add(1)
+			default:
+				unknown.Add(1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	require.Equal(t, int32(0), unknown.Load())
+	assert.Equal(t, int32(maxTeamSize-1), joined.Load())
+	assert.Equal(t, int32(joiners-maxTeamSize+1), full.Load())
+	assert.Len(t, repo.members, maxTeamSize)
+	assert.Equal(t, int32(joiners), lock.calls.Load())
+}
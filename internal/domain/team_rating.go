@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// TeamRating - рейтинг команды по всем играм турнира
+type TeamRating struct {
+	TeamID        uuid.UUID          `json:"team_id"`
+	TeamName      string             `json:"team_name"`
+	TotalRating   float64            `json:"total_rating"`
+	GamesPlayed   int                `json:"games_played"`   // Игры, в которых у команды есть сыгранные матчи
+	GameBreakdown map[string]float64 `json:"game_breakdown"` // Имя игры -> очки команды в ней
+}
+
+// normalizedGameScale очки лучшей команды игры после нормализации
+const normalizedGameScale = 100
+
+// NormalizeTeamRatings пересчитывает рейтинги команд так, чтобы игры с разным масштабом очков
+// и число сыгранных игр не влияли на место: очки в каждой игре переводятся в долю
+// от лучшего результата игры (лучшая команда получает 100), а итог - среднее по сыгранным играм.
+// Исходные рейтинги не изменяются, результат отсортирован по убыванию итога
+func NormalizeTeamRatings(ratings []*TeamRating) []*TeamRating {
+	best := make(map[string]float64)
+	for _, r := range ratings {
+		for game, score := range r.GameBreakdown {
+			if score > best[game] {
+				best[game] = score
+			}
+		}
+	}
+
+	normalized := make([]*TeamRating, 0, len(ratings))
+	for _, r := range ratings {
+		n := &TeamRating{
+			TeamID:        r.TeamID,
+			TeamName:      r.TeamName,
+			GamesPlayed:   r.GamesPlayed,
+			GameBreakdown: make(map[string]float64, len(r.GameBreakdown)),
+		}
+
+		var sum float64
+		for game, score := range r.GameBreakdown {
+			var value float64
+			if best[game] > 0 {
+				value = score / best[game] * normalizedGameScale
+			}
+			n.GameBreakdown[game] = value
+			sum += value
+		}
+		if r.GamesPlayed > 0 {
+			n.TotalRating = sum / float64(r.GamesPlayed)
+		}
+
+		normalized = append(normalized, n)
+	}
+
+	SortTeamRatings(normalized)
+	return normalized
+}
+
+// SortTeamRatings сортирует команды по убыванию рейтинга, при равенстве - по названию
+func SortTeamRatings(ratings []*TeamRating) {
+	sort.SliceStable(ratings, func(i, j int) bool {
+		if ratings[i].TotalRating != ratings[j].TotalRating {
+			return ratings[i].TotalRating > ratings[j].TotalRating
+		}
+		return ratings[i].TeamName < ratings[j].TeamName
+	})
+}
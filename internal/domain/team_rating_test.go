@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTeamRating(name string, breakdown map[string]float64) *TeamRating {
+	var total float64
+	for _, score := range breakdown {
+		total += score
+	}
+	return &TeamRating{
+		TeamID:        uuid.New(),
+		TeamName:      name,
+		TotalRating:   total,
+		GamesPlayed:   len(breakdown),
+		GameBreakdown: breakdown,
+	}
+}
+
+func teamNames(ratings []*TeamRating) []string {
+	result := make([]string, len(ratings))
+	for i, r := range ratings {
+		result[i] = r.TeamName
+	}
+	return result
+}
+
+func TestNormalizeTeamRatings(t *testing.T) {
+	t.Run("single-game team is not penalized", func(t *testing.T) {
+		// Specialist wins the only game it plays, allrounder wins both games
+		ratings := []*TeamRating{
+			newTeamRating("allrounder", map[string]float64{"dilemma": 500, "tug_of_war": 40}),
+			newTeamRating("specialist", map[string]float64{"dilemma": 500}),
+			newTeamRating("weak", map[string]float64{"dilemma": 250, "tug_of_war": 20}),
+		}
+
+		normalized := NormalizeTeamRatings(ratings)
+
+		require.Len(t, normalized, 3)
+		assert.Equal(t, 100.0, normalized[0].TotalRating)
+		assert.Equal(t, 100.0, normalized[1].TotalRating)
+		assert.Equal(t, []string{"allrounder", "specialist", "weak"}, teamNames(normalized))
+		assert.Equal(t, 50.0, normalized[2].TotalRating)
+		assert.Equal(t, map[string]float64{"dilemma": 50, "tug_of_war": 50}, normalized[2].GameBreakdown)
+
+		// Raw sums keep favouring the team that plays more games
+		assert.Equal(t, 540.0, ratings[0].TotalRating)
+		assert.Equal(t, 500.0, ratings[1].TotalRating)
+	})
+
+	t.Run("game scale does not dominate", func(t *testing.T) {
+		// Big-score game would decide the raw ranking on its own
+		ratings := []*TeamRating{
+			newTeamRating("a", map[string]float64{"big": 1000, "small": 1}),
+			newTeamRating("b", map[string]float64{"big": 900, "small": 10}),
+		}
+
+		normalized := NormalizeTeamRatings(ratings)
+
+		assert.Equal(t, []string{"b", "a"}, teamNames(normalized))
+		assert.Equal(t, 95.0, normalized[0].TotalRating)
+	})
+
+	t.Run("teams without played games and zero-score games", func(t *testing.T) {
+		idle := &TeamRating{TeamID: uuid.New(), TeamName: "idle", GameBreakdown: map[string]float64{"dilemma": 0}}
+		ratings := []*TeamRating{
+			idle,
+			newTeamRating("zero", map[string]float64{"dilemma": 0}),
+		}
+
+		normalized := NormalizeTeamRatings(ratings)
+
+		for _, r := range normalized {
+			assert.Zero(t, r.TotalRating)
+		}
+		assert.Equal(t, []string{"idle", "zero"}, teamNames(normalized))
+	})
+}
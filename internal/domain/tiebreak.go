@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// TieBreakRule - правило разрешения равенства очков в таблице лидеров
+type TieBreakRule string
+
+const (
+	TieBreakWins                TieBreakRule = "wins"                 // Больше побед
+	TieBreakFewerLosses         TieBreakRule = "fewer_losses"         // Меньше поражений
+	TieBreakHeadToHead          TieBreakRule = "head_to_head"         // Больше побед в личных встречах
+	TieBreakEarlierRegistration TieBreakRule = "earlier_registration" // Раньше зарегистрирован
+)
+
+// MetaTieBreak ключ метаданных турнира: цепочка tie-break правил,
+// например ["head_to_head", "fewer_losses", "earlier_registration"]
+const MetaTieBreak = "tie_break"
+
+// DefaultTieBreakRules правила по умолчанию: при равенстве очков выше тот, у кого больше побед
+var DefaultTieBreakRules = []TieBreakRule{TieBreakWins}
+
+// IsValid проверяет, что правило известно
+func (r TieBreakRule) IsValid() bool {
+	switch r {
+	case TieBreakWins, TieBreakFewerLosses, TieBreakHeadToHead, TieBreakEarlierRegistration:
+		return true
+	}
+	return false
+}
+
+// TieBreakRules возвращает цепочку tie-break правил из метаданных.
+// Если цепочка не задана, возвращает DefaultTieBreakRules
+func (t *Tournament) TieBreakRules() []TieBreakRule {
+	rules, err := parseTieBreakRules(t.Metadata[MetaTieBreak])
+	if err != nil || len(rules) == 0 {
+		return DefaultTieBreakRules
+	}
+	return rules
+}
+
+// parseTieBreakRules разбирает значение метаданных: после JSON это []interface{}
+func parseTieBreakRules(value interface{}) ([]TieBreakRule, error) {
+	var names []string
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		names = v
+	case []interface{}:
+		for _, item := range v {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("tie-break rule must be a string, got %T", item)
+			}
+			names = append(names, name)
+		}
+	default:
+		return nil, fmt.Errorf("tie-break rules must be a list, got %T", value)
+	}
+
+	rules := make([]TieBreakRule, 0, len(names))
+	for _, name := range names {
+		rule := TieBreakRule(name)
+		if !rule.IsValid() {
+			return nil, fmt.Errorf("unknown tie-break rule: %s", name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// HeadToHead результаты личных встреч: wins[a][b] - число побед a над b
+type HeadToHead map[uuid.UUID]map[uuid.UUID]int
+
+// AddWin учитывает победу winner над loser
+func (h HeadToHead) AddWin(winner, loser uuid.UUID) {
+	if h[winner] == nil {
+		h[winner] = make(map[uuid.UUID]int)
+	}
+	h[winner][loser]++
+}
+
+// SortLeaderboard упорядочивает записи по очкам и цепочке tie-break правил и проставляет места.
+// key возвращает идентификатор участника в h2h (программа или команда).
+// Личные встречи учитываются только между участниками, равными по предыдущим критериям:
+// так результат не зависит от порядка сравнения при цикле A > B > C > A.
+// Последние критерии - время регистрации и ID, поэтому порядок всегда однозначен
+func SortLeaderboard(entries []*LeaderboardEntry, rules []TieBreakRule, h2h HeadToHead, key func(*LeaderboardEntry) uuid.UUID) {
+	less := []func(a, b *LeaderboardEntry) int{
+		func(a, b *LeaderboardEntry) int { return b.Rating - a.Rating },
+	}
+
+	sortBy := func() {
+		sort.SliceStable(entries, func(i, j int) bool {
+			for _, cmp := range less {
+				if c := cmp(entries[i], entries[j]); c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+	sortBy()
+
+	for _, rule := range rules {
+		switch rule {
+		case TieBreakWins:
+			less = append(less, func(a, b *LeaderboardEntry) int { return b.Wins - a.Wins })
+		case TieBreakFewerLosses:
+			less = append(less, func(a, b *LeaderboardEntry) int { return a.Losses - b.Losses })
+		case TieBreakEarlierRegistration:
+			less = append(less, compareRegistration)
+		case TieBreakHeadToHead:
+			points := headToHeadPoints(entries, less, h2h, key)
+			less = append(less, func(a, b *LeaderboardEntry) int { return points[b] - points[a] })
+		default:
+			continue
+		}
+		sortBy()
+	}
+
+	less = append(less, compareRegistration, func(a, b *LeaderboardEntry) int {
+		return compareUUID(key(a), key(b))
+	})
+	sortBy()
+
+	for i, entry := range entries {
+		entry.Rank = i + 1
+	}
+}
+
+// headToHeadPoints считает победы каждого участника над остальными участниками
+// его группы - подряд идущих записей, равных по уже применённым критериям
+func headToHeadPoints(entries []*LeaderboardEntry, less []func(a, b *LeaderboardEntry) int, h2h HeadToHead, key func(*LeaderboardEntry) uuid.UUID) map[*LeaderboardEntry]int {
+	equal := func(a, b *LeaderboardEntry) bool {
+		for _, cmp := range less {
+			if cmp(a, b) != 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	points := make(map[*LeaderboardEntry]int, len(entries))
+	for start := 0; start < len(entries); {
+		end := start + 1
+		for end < len(entries) && equal(entries[start], entries[end]) {
+			end++
+		}
+
+		group := entries[start:end]
+		for _, a := range group {
+			for _, b := range group {
+				if a != b {
+					points[a] += h2h[key(a)][key(b)]
+				}
+			}
+		}
+		start = end
+	}
+	return points
+}
+
+// compareRegistration - раньше зарегистрированный выше; неизвестное время - в конце
+func compareRegistration(a, b *LeaderboardEntry) int {
+	switch {
+	case a.RegisteredAt.Equal(b.RegisteredAt):
+		return 0
+	case a.RegisteredAt.IsZero():
+		return 1
+	case b.RegisteredAt.IsZero():
+		return -1
+	case a.RegisteredAt.Before(b.RegisteredAt):
+		return -1
+	default:
+		return 1
+	}
+}
+
+func compareUUID(a, b uuid.UUID) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return int(a[i]) - int(b[i])
+		}
+	}
+	return 0
+}
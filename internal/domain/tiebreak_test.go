@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEntry(name string, rating, wins, losses int, registeredAt time.Time) *LeaderboardEntry {
+	return &LeaderboardEntry{
+		ProgramID:    uuid.New(),
+		ProgramName:  name,
+		Rating:       rating,
+		Wins:         wins,
+		Losses:       losses,
+		RegisteredAt: registeredAt,
+	}
+}
+
+func programKey(e *LeaderboardEntry) uuid.UUID { return e.ProgramID }
+
+func names(entries []*LeaderboardEntry) []string {
+	result := make([]string, len(entries))
+	for i, e := range entries {
+		result[i] = e.ProgramName
+	}
+	return result
+}
+
+func TestSortLeaderboard_DefaultRules(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []*LeaderboardEntry{
+		newEntry("c", 10, 1, 0, base),
+		newEntry("a", 20, 2, 0, base),
+		newEntry("b", 10, 3, 0, base),
+	}
+
+	SortLeaderboard(entries, DefaultTieBreakRules, nil, programKey)
+
+	assert.Equal(t, []string{"a", "b", "c"}, names(entries))
+	for i, e := range entries {
+		assert.Equal(t, i+1, e.Rank)
+	}
+}
+
+func TestSortLeaderboard_RuleChain(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	early := newEntry("early", 10, 2, 3, base)
+	late := newEntry("late", 10, 2, 3, base.Add(time.Hour))
+	fewerLosses := newEntry("fewer_losses", 10, 1, 1, base.Add(2*time.Hour))
+
+	entries := []*LeaderboardEntry{late, fewerLosses, early}
+	rules := []TieBreakRule{TieBreakFewerLosses, TieBreakEarlierRegistration}
+
+	SortLeaderboard(entries, rules, nil, programKey)
+
+	assert.Equal(t, []string{"fewer_losses", "early", "late"}, names(entries))
+}
+
+func TestSortLeaderboard_HeadToHead(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	leader := newEntry("leader", 30, 5, 0, base)
+	a := newEntry("a", 10, 2, 2, base)
+	b := newEntry("b", 10, 2, 2, base)
+
+	h2h := HeadToHead{}
+	h2h.AddWin(b.ProgramID, a.ProgramID)
+	// Wins against a participant outside the tie must not count
+	h2h.AddWin(a.ProgramID, leader.ProgramID)
+	h2h.AddWin(a.ProgramID, leader.ProgramID)
+
+	entries := []*LeaderboardEntry{a, leader, b}
+	SortLeaderboard(entries, []TieBreakRule{TieBreakHeadToHead}, h2h, programKey)
+
+	assert.Equal(t, []string{"leader", "b", "a"}, names(entries))
+}
+
+func TestSortLeaderboard_IsDeterministic(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []*LeaderboardEntry{
+		newEntry("x", 10, 1, 1, base),
+		newEntry("y", 10, 1, 1, base),
+		newEntry("z", 10, 1, 1, base),
+	}
+	reversed := []*LeaderboardEntry{entries[2], entries[1], entries[0]}
+
+	SortLeaderboard(entries, DefaultTieBreakRules, nil, programKey)
+	SortLeaderboard(reversed, DefaultTieBreakRules, nil, programKey)
+
+	assert.Equal(t, names(entries), names(reversed))
+}
+
+func TestTournament_TieBreakRules(t *testing.T) {
+	t.Run("defaults when not set", func(t *testing.T) {
+		tournament := &Tournament{}
+		assert.Equal(t, DefaultTieBreakRules, tournament.TieBreakRules())
+	})
+
+	t.Run("parsed from JSON metadata", func(t *testing.T) {
+		tournament := &Tournament{Metadata: map[string]interface{}{
+			MetaTieBreak: []interface{}{"head_to_head", "fewer_losses"},
+		}}
+		assert.Equal(t, []TieBreakRule{TieBreakHeadToHead, TieBreakFewerLosses}, tournament.TieBreakRules())
+	})
+
+	t.Run("unknown rule fails validation", func(t *testing.T) {
+		tournament := &Tournament{
+			Name:     "Cup",
+			GameType: "chess",
+			Status:   TournamentPending,
+			Metadata: map[string]interface{}{MetaTieBreak: []interface{}{"coin_flip"}},
+		}
+		assert.Error(t, tournament.Validate())
+		assert.Equal(t, DefaultTieBreakRules, tournament.TieBreakRules())
+	})
+}
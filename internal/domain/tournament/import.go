@@ -0,0 +1,212 @@
+package tournament
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxImportCount максимальное число команд, переносимых за один импорт
+const maxImportCount = 100
+
+// ParticipantImporter интерфейс переноса программ команд между турнирами
+type ParticipantImporter interface {
+	GetLatestTeamPrograms(ctx context.Context, tournamentID uuid.UUID, teamIDs []uuid.UUID) ([]*domain.Program, error)
+	ImportPrograms(ctx context.Context, tournamentID uuid.UUID, programs []*domain.Program) ([]*domain.ImportedProgram, error)
+}
+
+// SetParticipantImporter включает импорт участников из другого турнира
+func (s *Service) SetParticipantImporter(importer ParticipantImporter) {
+	s.importer = importer
+}
+
+// ImportParticipantsRequest - запрос на импорт лучших команд другого турнира
+type ImportParticipantsRequest struct {
+	SourceTournamentID uuid.UUID `json:"source_tournament_id"`
+	Count              int       `json:"count"`
+}
+
+// ImportParticipants переносит в турнир последние программы Count лучших команд турнира-источника
+// (по его таблице лидеров). Все программы должны относиться к играм целевого турнира
+func (s *Service) ImportParticipants(ctx context.Context, tournamentID uuid.UUID, req *ImportParticipantsRequest) ([]*domain.ImportedProgram, error) {
+	if s.importer == nil {
+		return nil, errors.ErrServiceUnavailable.WithMessage("participant import is not configured")
+	}
+	if req.SourceTournamentID == uuid.Nil {
+		return nil, errors.ErrInvalidInput.WithMessage("source_tournament_id is required")
+	}
+	if req.SourceTournamentID == tournamentID {
+		return nil, errors.ErrInvalidInput.WithMessage("cannot import participants from the same tournament")
+	}
+	if req.Count < 1 || req.Count > maxImportCount {
+		return nil, errors.ErrInvalidInput.WithMessage(fmt.Sprintf("count must be between 1 and %d", maxImportCount))
+	}
+
+	var imported []*domain.ImportedProgram
+
+	lockKey := fmt.Sprintf("tournament:join:%s", tournamentID.String())
+	err := s.distributedLock.WithLock(ctx, lockKey, 5*time.Second, func(ctx context.Context) error {
+		target, err := s.tournamentRepo.GetByID(ctx, tournamentID)
+		if err != nil {
+			return err
+		}
+		if target.Status != domain.TournamentPending && target.Status != domain.TournamentActive {
+			return errors.ErrConflict.WithMessage("participants can only be imported into a pending or active tournament")
+		}
+
+		source, err := s.tournamentRepo.GetByID(ctx, req.SourceTournamentID)
+		if err != nil {
+			return err
+		}
+
+		programs, err := s.topTeamPrograms(ctx, source.ID, req.Count)
+		if err != nil {
+			return err
+		}
+		if len(programs) == 0 {
+			return errors.ErrValidation.WithMessage("source tournament has no team programs to import")
+		}
+
+		if err := s.checkImportGames(ctx, source, target, programs); err != nil {
+			return err
+		}
+
+		if target.MaxParticipants != nil {
+			count, err := s.tournamentRepo.GetParticipantsCount(ctx, tournamentID)
+			if err != nil {
+				return fmt.Errorf("failed to get participants count: %w", err)
+			}
+			if count+len(programs) > *target.MaxParticipants {
+				return errors.ErrTournamentFull
+			}
+		}
+
+		imported, err = s.importer.ImportPrograms(ctx, tournamentID, programs)
+		if err != nil {
+			return err
+		}
+
+		if s.tournamentCache != nil {
+			_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+		}
+
+		added := 0
+		for _, p := range imported {
+			if p.Skipped {
+				continue
+			}
+			added++
+			if s.leaderboardCache == nil {
+				continue
+			}
+			if err := s.leaderboardCache.UpdateRating(ctx, tournamentID, p.ProgramID, 1500); err != nil {
+				s.log.Error("Failed to update leaderboard cache", zap.Error(err))
+			}
+		}
+
+		s.log.Info("Participants imported",
+			zap.Bool("audit", true),
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("source_tournament_id", source.ID.String()),
+			zap.Int("count", req.Count),
+			zap.Int("imported", added),
+			zap.Int("skipped", len(imported)-added),
+		)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return imported, nil
+}
+
+// topTeamPrograms возвращает последние программы count лучших команд турнира в порядке их мест
+func (s *Service) topTeamPrograms(ctx context.Context, tournamentID uuid.UUID, count int) ([]*domain.Program, error) {
+	entries, err := s.tournamentRepo.GetLeaderboard(ctx, tournamentID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source leaderboard: %w", err)
+	}
+
+	// Команда занимает место своей лучшей программы
+	rank := make(map[uuid.UUID]int, count)
+	teamIDs := make([]uuid.UUID, 0, count)
+	for _, entry := range entries {
+		if len(teamIDs) == count {
+			break
+		}
+		if entry.TeamID == nil {
+			continue
+		}
+		if _, ok := rank[*entry.TeamID]; ok {
+			continue
+		}
+		rank[*entry.TeamID] = len(teamIDs)
+		teamIDs = append(teamIDs, *entry.TeamID)
+	}
+	if len(teamIDs) == 0 {
+		return nil, nil
+	}
+
+	programs, err := s.importer.GetLatestTeamPrograms(ctx, tournamentID, teamIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(programs, func(i, j int) bool {
+		return rank[*programs[i].TeamID] < rank[*programs[j].TeamID]
+	})
+
+	return programs, nil
+}
+
+// checkImportGames проверяет, что целевой турнир проводит игры всех импортируемых программ
+func (s *Service) checkImportGames(ctx context.Context, source, target *domain.Tournament, programs []*domain.Program) error {
+	targetGames, err := s.gameRepo.GetTournamentGames(ctx, target.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get tournament games: %w", err)
+	}
+
+	supported := make(map[uuid.UUID]bool, len(targetGames))
+	for _, g := range targetGames {
+		supported[g.GameID] = true
+	}
+
+	var missing []string
+	seen := make(map[uuid.UUID]bool)
+	for _, p := range programs {
+		if p.GameID == nil {
+			// Программы однотипных турниров без игр привязаны только к типу турнира
+			if source.GameType != target.GameType {
+				return errors.ErrValidation.WithMessage(fmt.Sprintf(
+					"source game type %q does not match tournament game type %q", source.GameType, target.GameType))
+			}
+			continue
+		}
+		if supported[*p.GameID] || seen[*p.GameID] {
+			continue
+		}
+		seen[*p.GameID] = true
+
+		name := p.GameID.String()
+		if game, err := s.gameRepo.GetByID(ctx, *p.GameID); err == nil {
+			name = game.Name
+		}
+		missing = append(missing, name)
+	}
+
+	if len(missing) > 0 {
+		return errors.ErrValidation.WithMessage(fmt.Sprintf(
+			"tournament does not include games of imported programs: %s", strings.Join(missing, ", ")))
+	}
+
+	return nil
+}
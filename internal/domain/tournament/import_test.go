@@ -0,0 +1,200 @@
+package tournament
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockParticipantImporter struct {
+	mock.Mock
+}
+
+func (m *MockParticipantImporter) GetLatestTeamPrograms(ctx context.Context, tournamentID uuid.UUID, teamIDs []uuid.UUID) ([]*domain.Program, error) {
+	args := m.Called(ctx, tournamentID, teamIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Program), args.Error(1)
+}
+
+func (m *MockParticipantImporter) ImportPrograms(ctx context.Context, tournamentID uuid.UUID, programs []*domain.Program) ([]*domain.ImportedProgram, error) {
+	args := m.Called(ctx, tournamentID, programs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ImportedProgram), args.Error(1)
+}
+
+func TestService_ImportParticipants(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	gameID := uuid.New()
+	otherGameID := uuid.New()
+
+	source := &domain.Tournament{ID: uuid.New(), GameType: "dilemma", Status: domain.TournamentCompleted}
+	newTarget := func() *domain.Tournament {
+		return &domain.Tournament{ID: uuid.New(), GameType: "dilemma", Status: domain.TournamentPending}
+	}
+
+	teamA, teamB, teamC := uuid.New(), uuid.New(), uuid.New()
+	// Team A holds first and third place with two program versions
+	leaderboard := []*domain.LeaderboardEntry{
+		{Rank: 1, ProgramID: uuid.New(), TeamID: &teamA},
+		{Rank: 2, ProgramID: uuid.New(), TeamID: &teamB},
+		{Rank: 3, ProgramID: uuid.New(), TeamID: &teamA},
+		{Rank: 4, ProgramID: uuid.New()},
+		{Rank: 5, ProgramID: uuid.New(), TeamID: &teamC},
+	}
+	program := func(team uuid.UUID, game uuid.UUID) *domain.Program {
+		return &domain.Program{ID: uuid.New(), TeamID: &team, TournamentID: &source.ID, GameID: &game}
+	}
+
+	newService := func(tournamentRepo *MockTournamentRepository, gameRepo *MockGameRepository, importer ParticipantImporter) *Service {
+		lock := new(MockDistributedLock)
+		lock.On("WithLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		service := NewService(tournamentRepo, nil, nil, gameRepo, nil, nil, nil, lock, log)
+		if importer != nil {
+			service.SetParticipantImporter(importer)
+		}
+		return service
+	}
+
+	t.Run("imports top teams in leaderboard order", func(t *testing.T) {
+		target := newTarget()
+		tournamentRepo := new(MockTournamentRepository)
+		tournamentRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+		tournamentRepo.On("GetByID", mock.Anything, source.ID).Return(source, nil)
+		tournamentRepo.On("GetLeaderboard", mock.Anything, source.ID, 0).Return(leaderboard, nil)
+
+		gameRepo := new(MockGameRepository)
+		gameRepo.On("GetTournamentGames", mock.Anything, target.ID).Return([]*domain.TournamentGame{{GameID: gameID}}, nil)
+
+		programB, programA := program(teamB, gameID), program(teamA, gameID)
+		importer := new(MockParticipantImporter)
+		importer.On("GetLatestTeamPrograms", mock.Anything, source.ID, []uuid.UUID{teamA, teamB}).
+			Return([]*domain.Program{programB, programA}, nil)
+		imported := []*domain.ImportedProgram{
+			{SourceProgramID: programA.ID, ProgramID: uuid.New()},
+			{SourceProgramID: programB.ID, ProgramID: uuid.New(), Skipped: true},
+		}
+		importer.On("ImportPrograms", mock.Anything, target.ID, []*domain.Program{programA, programB}).Return(imported, nil)
+
+		service := newService(tournamentRepo, gameRepo, importer)
+		result, err := service.ImportParticipants(context.Background(), target.ID, &ImportParticipantsRequest{
+			SourceTournamentID: source.ID,
+			Count:              2,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, imported, result)
+		importer.AssertExpectations(t)
+	})
+
+	t.Run("rejects programs of games outside the tournament", func(t *testing.T) {
+		target := newTarget()
+		tournamentRepo := new(MockTournamentRepository)
+		tournamentRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+		tournamentRepo.On("GetByID", mock.Anything, source.ID).Return(source, nil)
+		tournamentRepo.On("GetLeaderboard", mock.Anything, source.ID, 0).Return(leaderboard, nil)
+
+		gameRepo := new(MockGameRepository)
+		gameRepo.On("GetTournamentGames", mock.Anything, target.ID).Return([]*domain.TournamentGame{{GameID: gameID}}, nil)
+		gameRepo.On("GetByID", mock.Anything, otherGameID).Return(&domain.Game{ID: otherGameID, Name: "tictactoe"}, nil)
+
+		importer := new(MockParticipantImporter)
+		importer.On("GetLatestTeamPrograms", mock.Anything, source.ID, mock.Anything).
+			Return([]*domain.Program{program(teamA, gameID), program(teamA, otherGameID)}, nil)
+
+		service := newService(tournamentRepo, gameRepo, importer)
+		_, err := service.ImportParticipants(context.Background(), target.ID, &ImportParticipantsRequest{
+			SourceTournamentID: source.ID,
+			Count:              1,
+		})
+
+		appErr := errors.GetAppError(err)
+		require.NotNil(t, appErr)
+		assert.Equal(t, errors.ErrValidation.Code, appErr.Code)
+		assert.Contains(t, appErr.Message, "tictactoe")
+		importer.AssertNotCalled(t, "ImportPrograms", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("respects participant limit", func(t *testing.T) {
+		target := newTarget()
+		limit := 3
+		target.MaxParticipants = &limit
+		tournamentRepo := new(MockTournamentRepository)
+		tournamentRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+		tournamentRepo.On("GetByID", mock.Anything, source.ID).Return(source, nil)
+		tournamentRepo.On("GetLeaderboard", mock.Anything, source.ID, 0).Return(leaderboard, nil)
+		tournamentRepo.On("GetParticipantsCount", mock.Anything, target.ID).Return(2, nil)
+
+		gameRepo := new(MockGameRepository)
+		gameRepo.On("GetTournamentGames", mock.Anything, target.ID).Return([]*domain.TournamentGame{{GameID: gameID}}, nil)
+
+		importer := new(MockParticipantImporter)
+		importer.On("GetLatestTeamPrograms", mock.Anything, source.ID, mock.Anything).
+			Return([]*domain.Program{program(teamA, gameID), program(teamB, gameID)}, nil)
+
+		service := newService(tournamentRepo, gameRepo, importer)
+		_, err := service.ImportParticipants(context.Background(), target.ID, &ImportParticipantsRequest{
+			SourceTournamentID: source.ID,
+			Count:              2,
+		})
+
+		assert.ErrorIs(t, err, errors.ErrTournamentFull)
+		importer.AssertNotCalled(t, "ImportPrograms", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects finished target tournament", func(t *testing.T) {
+		target := newTarget()
+		target.Status = domain.TournamentCompleted
+		tournamentRepo := new(MockTournamentRepository)
+		tournamentRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+
+		service := newService(tournamentRepo, nil, new(MockParticipantImporter))
+		_, err := service.ImportParticipants(context.Background(), target.ID, &ImportParticipantsRequest{
+			SourceTournamentID: source.ID,
+			Count:              1,
+		})
+
+		appErr := errors.GetAppError(err)
+		require.NotNil(t, appErr)
+		assert.Equal(t, errors.ErrConflict.Code, appErr.Code)
+	})
+
+	t.Run("validates request", func(t *testing.T) {
+		targetID := uuid.New()
+		service := newService(new(MockTournamentRepository), nil, new(MockParticipantImporter))
+
+		for _, req := range []*ImportParticipantsRequest{
+			{SourceTournamentID: source.ID, Count: 0},
+			{SourceTournamentID: source.ID, Count: maxImportCount + 1},
+			{SourceTournamentID: targetID, Count: 1},
+			{Count: 1},
+		} {
+			_, err := service.ImportParticipants(context.Background(), targetID, req)
+			appErr := errors.GetAppError(err)
+			require.NotNil(t, appErr)
+			assert.Equal(t, errors.ErrInvalidInput.Code, appErr.Code)
+		}
+	})
+
+	t.Run("unavailable without importer", func(t *testing.T) {
+		service := newService(new(MockTournamentRepository), nil, nil)
+		_, err := service.ImportParticipants(context.Background(), uuid.New(), &ImportParticipantsRequest{
+			SourceTournamentID: source.ID,
+			Count:              1,
+		})
+
+		appErr := errors.GetAppError(err)
+		require.NotNil(t, appErr)
+		assert.Equal(t, errors.ErrServiceUnavailable.Code, appErr.Code)
+	})
+}
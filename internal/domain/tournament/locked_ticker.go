@@ -0,0 +1,101 @@
+package tournament
+
+import (
+	"context"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// lockedTicker периодически выполняет проверку под распределённой блокировкой,
+// чтобы при нескольких репликах каждую проверку выполняла только одна из них.
+// Общий цикл для планировщиков турниров: автостарта, окон игр и бюджета времени
+type lockedTicker struct {
+	name     string // название планировщика для логов
+	lockKey  string
+	lock     DistributedLock
+	interval time.Duration
+	check    func(ctx context.Context) error
+	log      *logger.Logger
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// newLockedTicker создаёт цикл, вызывающий check раз в interval под блокировкой lockKey
+func newLockedTicker(
+	name string,
+	lockKey string,
+	lock DistributedLock,
+	interval time.Duration,
+	log *logger.Logger,
+	check func(ctx context.Context) error,
+) *lockedTicker {
+	return &lockedTicker{
+		name:     name,
+		lockKey:  lockKey,
+		lock:     lock,
+		interval: interval,
+		check:    check,
+		log:      log,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// start запускает цикл; fields дополняют запись о запуске в логе
+func (t *lockedTicker) start(fields ...zap.Field) {
+	t.log.Info("Starting "+t.name,
+		append([]zap.Field{zap.Duration("interval", t.interval)}, fields...)...,
+	)
+
+	go t.run()
+}
+
+// stop останавливает цикл и ждёт завершения текущей проверки
+func (t *lockedTicker) stop() {
+	t.log.Info("Stopping " + t.name)
+	close(t.stopCh)
+	<-t.doneCh
+	t.log.Info("Stopped " + t.name)
+}
+
+// run основной цикл проверки
+func (t *lockedTicker) run() {
+	defer close(t.doneCh)
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.tick()
+		case <-t.stopCh:
+			return
+		}
+	}
+}
+
+// tick выполняет одну проверку под распределённой блокировкой
+func (t *lockedTicker) tick() {
+	ctx, cancel := context.WithTimeout(context.Background(), t.interval)
+	defer cancel()
+
+	err := t.lock.WithLock(ctx, t.lockKey, t.interval, t.check)
+	if err == nil {
+		return
+	}
+
+	// Блокировку держит другая реплика - это нормально
+	if errors.IsConflict(err) {
+		t.log.Debug("Skipping "+t.name+" check, lock is held by another replica", zap.Error(err))
+		return
+	}
+
+	// Недоступный Redis или ошибка самой проверки: проверка на этом тике не выполнена
+	t.log.LogError("Failed to run "+t.name+" check", err,
+		zap.String("lock_key", t.lockKey),
+	)
+}
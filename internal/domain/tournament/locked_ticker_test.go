@@ -0,0 +1,85 @@
+package tournament
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLockedTicker_Tick(t *testing.T) {
+	const key = "tournament:test"
+
+	newTicker := func(lock DistributedLock, check func(ctx context.Context) error) (*lockedTicker, *observer.ObservedLogs) {
+		core, logs := observer.New(zap.DebugLevel)
+		log := &logger.Logger{Logger: zap.New(core)}
+		return newLockedTicker("test scheduler", key, lock, time.Minute, log, check), logs
+	}
+
+	t.Run("runs the check under the lock", func(t *testing.T) {
+		lock := new(MockDistributedLock)
+		lock.On("WithLock", mock.Anything, key, time.Minute, mock.Anything).Return(nil)
+
+		calls := 0
+		ticker, logs := newTicker(lock, func(ctx context.Context) error {
+			calls++
+			return nil
+		})
+		ticker.tick()
+
+		assert.Equal(t, 1, calls)
+		assert.Zero(t, logs.Len())
+		lock.AssertExpectations(t)
+	})
+
+	t.Run("lock held by another replica is logged at debug", func(t *testing.T) {
+		// The Redis lock wraps the conflict on its way out
+		held := fmt.Errorf("failed to acquire lock: %w", errors.ErrConflict.WithMessage("lock already held"))
+		lock := new(MockDistributedLock)
+		lock.On("WithLock", mock.Anything, key, time.Minute, mock.Anything).Return(held)
+
+		ticker, logs := newTicker(lock, func(ctx context.Context) error {
+			t.Fatal("check must not run without the lock")
+			return nil
+		})
+		ticker.tick()
+
+		require.Equal(t, 1, logs.Len())
+		assert.Equal(t, zapcore.DebugLevel, logs.All()[0].Level)
+	})
+
+	t.Run("lock failure is logged as an error", func(t *testing.T) {
+		lock := new(MockDistributedLock)
+		lock.On("WithLock", mock.Anything, key, time.Minute, mock.Anything).
+			Return(fmt.Errorf("failed to acquire lock: %w", stderrors.New("connection refused")))
+
+		ticker, logs := newTicker(lock, func(ctx context.Context) error { return nil })
+		ticker.tick()
+
+		require.Equal(t, 1, logs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, logs.All()[0].Level)
+	})
+
+	t.Run("check failure is logged as an error", func(t *testing.T) {
+		lock := new(MockDistributedLock)
+		lock.On("WithLock", mock.Anything, key, time.Minute, mock.Anything).Return(nil)
+
+		ticker, logs := newTicker(lock, func(ctx context.Context) error {
+			return stderrors.New("database is down")
+		})
+		ticker.tick()
+
+		require.Equal(t, 1, logs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, logs.All()[0].Level)
+	})
+}
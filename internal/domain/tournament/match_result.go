@@ -0,0 +1,120 @@
+package tournament
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AdjustMatchResultRequest ручная правка результата матча администратором
+type AdjustMatchResultRequest struct {
+	Score1 *int   `json:"score1"`
+	Score2 *int   `json:"score2"`
+	Winner *int   `json:"winner"` // 0 - ничья, 1 или 2 - победившая сторона
+	Reason string `json:"reason"`
+}
+
+// AnnulMatchResultRequest аннулирование результата матча администратором
+type AnnulMatchResultRequest struct {
+	Reason  string `json:"reason"`
+	Requeue bool   `json:"requeue,omitempty"` // Сразу поставить матч в очередь
+}
+
+// AdjustMatchResult заменяет результат матча, не перезапуская его. Прежний результат
+// и причина сохраняются в истории правок, таблица лидеров турнира пересчитывается
+func (s *Service) AdjustMatchResult(ctx context.Context, matchID, adminID uuid.UUID, req *AdjustMatchResultRequest) (*domain.Match, error) {
+	return s.adjustMatchResult(ctx, &domain.MatchResultAdjustment{
+		MatchID:    matchID,
+		Action:     domain.MatchAdjustmentEdit,
+		NewScore1:  req.Score1,
+		NewScore2:  req.Score2,
+		NewWinner:  req.Winner,
+		Reason:     req.Reason,
+		AdjustedBy: &adminID,
+	}, false)
+}
+
+// AnnulMatchResult сбрасывает результат матча и возвращает его в pending.
+// Без requeue матч поставит в очередь восстановление при следующем запуске worker'а
+func (s *Service) AnnulMatchResult(ctx context.Context, matchID, adminID uuid.UUID, req *AnnulMatchResultRequest) (*domain.Match, error) {
+	return s.adjustMatchResult(ctx, &domain.MatchResultAdjustment{
+		MatchID:    matchID,
+		Action:     domain.MatchAdjustmentAnnul,
+		Reason:     req.Reason,
+		AdjustedBy: &adminID,
+	}, req.Requeue)
+}
+
+// GetMatchResultAdjustments возвращает историю ручных правок результата матча
+func (s *Service) GetMatchResultAdjustments(ctx context.Context, matchID uuid.UUID) ([]*domain.MatchResultAdjustment, error) {
+	return s.matchRepo.GetResultAdjustments(ctx, matchID)
+}
+
+func (s *Service) adjustMatchResult(ctx context.Context, adjustment *domain.MatchResultAdjustment, requeue bool) (*domain.Match, error) {
+	if err := adjustment.Validate(); err != nil {
+		return nil, errors.ErrValidation.WithError(err)
+	}
+	adjustment.Reason = strings.TrimSpace(adjustment.Reason)
+
+	match, err := s.matchRepo.GetByID(ctx, adjustment.MatchID)
+	if err != nil {
+		return nil, err
+	}
+
+	tournament, err := s.GetByID(ctx, match.TournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tournament: %w", err)
+	}
+	// Победитель матча сетки уже прошёл в следующий раунд
+	if tournament.IsElimination() {
+		return nil, errors.ErrConflict.WithMessage("elimination match results cannot be adjusted")
+	}
+
+	if err := s.matchRepo.AdjustResult(ctx, adjustment); err != nil {
+		return nil, err
+	}
+
+	match, err = s.matchRepo.GetByID(ctx, adjustment.MatchID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Match result adjusted by admin",
+		zap.String("match_id", match.ID.String()),
+		zap.String("tournament_id", match.TournamentID.String()),
+		zap.String("action", string(adjustment.Action)),
+		zap.String("old_status", string(adjustment.OldStatus)),
+		zap.String("reason", adjustment.Reason),
+	)
+
+	// Таблица лидеров считается по матчам: после сброса кэша она строится заново
+	if err := s.leaderboardCache.Clear(ctx, match.TournamentID); err != nil {
+		s.log.LogError("Failed to clear leaderboard cache", err,
+			zap.String("tournament_id", match.TournamentID.String()),
+		)
+	}
+
+	if requeue {
+		if err := s.queueManager.Enqueue(ctx, match); err != nil {
+			s.log.LogError("Failed to enqueue annulled match", err,
+				zap.String("match_id", match.ID.String()),
+			)
+		}
+	}
+
+	s.broadcaster.Broadcast(match.TournamentID, "match_update", map[string]interface{}{
+		"match_id":   match.ID,
+		"status":     match.Status,
+		"score1":     match.Score1,
+		"score2":     match.Score2,
+		"winner":     match.Winner,
+		"adjustment": adjustment.Action,
+	})
+
+	return match, nil
+}
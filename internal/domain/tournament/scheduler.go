@@ -0,0 +1,226 @@
+package tournament
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// minAutoStartParticipants минимальное число участников для автостарта
+const minAutoStartParticipants = 2
+
+// maxAutoStartAttempts после стольких неудачных попыток автостарт прекращается
+// Организатор может запустить турнир вручную
+const maxAutoStartAttempts = 10
+
+// autoStartLockKey ключ блокировки, чтобы автостарт выполняла только одна реплика
+const autoStartLockKey = "tournament:autostart"
+
+// SchedulerRepository интерфейс для поиска турниров, готовых к автостарту
+type SchedulerRepository interface {
+	GetDueToStart(ctx context.Context) ([]*domain.Tournament, error)
+	GetParticipantsCount(ctx context.Context, tournamentID uuid.UUID) (int, error)
+}
+
+// TournamentStarter интерфейс для запуска турнира и учёта неудачных попыток
+type TournamentStarter interface {
+	Start(ctx context.Context, tournamentID uuid.UUID) error
+	RecordAutoStartFailure(ctx context.Context, tournamentID uuid.UUID, reason string) (int, error)
+	RecordAutoStartDelay(ctx context.Context, tournamentID uuid.UUID, reason string) error
+	ClearAutoStartFailure(ctx context.Context, tournamentID uuid.UUID) error
+}
+
+// TournamentScheduler периодически запускает турниры, у которых наступило StartTime
+type TournamentScheduler struct {
+	repo            SchedulerRepository
+	starter         TournamentStarter
+	broadcaster     Broadcaster
+	distributedLock DistributedLock
+	interval        time.Duration
+	log             *logger.Logger
+	ticker          *lockedTicker
+}
+
+// NewTournamentScheduler создаёт новый планировщик автостарта турниров
+func NewTournamentScheduler(
+	repo SchedulerRepository,
+	starter TournamentStarter,
+	broadcaster Broadcaster,
+	distributedLock DistributedLock,
+	interval time.Duration,
+	log *logger.Logger,
+) *TournamentScheduler {
+	s := &TournamentScheduler{
+		repo:            repo,
+		starter:         starter,
+		broadcaster:     broadcaster,
+		distributedLock: distributedLock,
+		interval:        interval,
+		log:             log,
+	}
+	s.ticker = newLockedTicker(
+		"tournament auto-starter", autoStartLockKey, distributedLock, interval, log,
+		func(ctx context.Context) error {
+			s.StartDue(ctx)
+			return nil
+		},
+	)
+
+	return s
+}
+
+// Start запускает периодическую проверку
+func (s *TournamentScheduler) Start() {
+	s.ticker.start()
+}
+
+// Stop останавливает планировщик
+func (s *TournamentScheduler) Stop() {
+	s.ticker.stop()
+}
+
+// StartDue запускает все турниры, у которых наступило время старта
+// Неудачные попытки записываются в метаданные турнира и повторяются на следующей проверке
+// Возвращает количество запущенных турниров
+func (s *TournamentScheduler) StartDue(ctx context.Context) int {
+	tournaments, err := s.repo.GetDueToStart(ctx)
+	if err != nil {
+		s.log.LogError("Failed to get tournaments due for start", err)
+		return 0
+	}
+
+	started := 0
+	for _, t := range tournaments {
+		// Исчерпавшие попытки ждут ручного запуска организатором
+		if t.AutoStartAttempts() >= maxAutoStartAttempts {
+			continue
+		}
+
+		// Общая блокировка проверки истекает через interval, а запуск турнира может
+		// затянуться: каждую попытку защищает собственная блокировка
+		lockKey := fmt.Sprintf("scheduler:start:%s", t.ID.String())
+		err := s.distributedLock.WithLock(ctx, lockKey, s.interval, func(ctx context.Context) error {
+			if s.startOne(ctx, t) {
+				started++
+			}
+			return nil
+		})
+		if errors.IsConflict(err) {
+			s.log.Debug("Skipping tournament auto-start, another replica is starting it",
+				zap.String("tournament_id", t.ID.String()),
+			)
+		} else if err != nil {
+			s.log.LogError("Failed to lock tournament auto-start", err,
+				zap.String("tournament_id", t.ID.String()),
+			)
+		}
+	}
+
+	return started
+}
+
+// startOne запускает один турнир. Возвращает true, если турнир запущен
+func (s *TournamentScheduler) startOne(ctx context.Context, t *domain.Tournament) bool {
+	count, err := s.repo.GetParticipantsCount(ctx, t.ID)
+	if err != nil {
+		s.log.LogError("Failed to get participants count", err,
+			zap.String("tournament_id", t.ID.String()),
+		)
+		return false
+	}
+
+	// Нехватка участников - ожидание, а не сбой: участники могут присоединиться позже,
+	// поэтому попытка не засчитывается в maxAutoStartAttempts
+	if count < minAutoStartParticipants {
+		s.recordDelay(ctx, t, fmt.Sprintf("not enough participants: %d of %d required", count, minAutoStartParticipants))
+		return false
+	}
+
+	if err := s.starter.Start(ctx, t.ID); err != nil {
+		// Турнир запустили вручную после выборки - автостарт больше не нужен
+		if errors.IsConflict(err) {
+			s.log.Debug("Tournament already started, skipping auto-start",
+				zap.String("tournament_id", t.ID.String()),
+			)
+			return false
+		}
+		s.log.Warn("Failed to auto-start tournament",
+			zap.String("tournament_id", t.ID.String()),
+			zap.Error(err),
+		)
+		s.recordFailure(ctx, t, err.Error())
+		return false
+	}
+
+	if t.AutoStartError() != nil {
+		if err := s.starter.ClearAutoStartFailure(ctx, t.ID); err != nil {
+			s.log.LogError("Failed to clear auto-start failure", err,
+				zap.String("tournament_id", t.ID.String()),
+			)
+		}
+	}
+
+	s.log.Info("Tournament auto-started",
+		zap.String("tournament_id", t.ID.String()),
+		zap.Time("scheduled_start", *t.StartTime),
+		zap.Int("participants", count),
+	)
+
+	s.broadcaster.Broadcast(t.ID, "tournament_auto_started", map[string]interface{}{
+		"scheduled_start": t.StartTime,
+		"participants":    count,
+	})
+
+	return true
+}
+
+// recordFailure сохраняет причину неудачного автостарта
+func (s *TournamentScheduler) recordFailure(ctx context.Context, t *domain.Tournament, reason string) {
+	attempts, err := s.starter.RecordAutoStartFailure(ctx, t.ID, reason)
+	if err != nil {
+		s.log.LogError("Failed to record auto-start failure", err,
+			zap.String("tournament_id", t.ID.String()),
+		)
+		return
+	}
+
+	if attempts >= maxAutoStartAttempts {
+		s.log.Warn("Giving up auto-start, manual start required",
+			zap.String("tournament_id", t.ID.String()),
+			zap.String("reason", reason),
+			zap.Int("attempts", attempts),
+		)
+		return
+	}
+
+	s.log.Debug("Auto-start failed, will retry",
+		zap.String("tournament_id", t.ID.String()),
+		zap.String("reason", reason),
+		zap.Int("attempts", attempts),
+	)
+}
+
+// recordDelay сохраняет причину отложенного автостарта, если она изменилась
+func (s *TournamentScheduler) recordDelay(ctx context.Context, t *domain.Tournament, reason string) {
+	if current := t.AutoStartError(); current != nil && *current == reason {
+		return
+	}
+
+	if err := s.starter.RecordAutoStartDelay(ctx, t.ID, reason); err != nil {
+		s.log.LogError("Failed to record auto-start delay", err,
+			zap.String("tournament_id", t.ID.String()),
+		)
+		return
+	}
+
+	s.log.Debug("Auto-start delayed, will retry",
+		zap.String("tournament_id", t.ID.String()),
+		zap.String("reason", reason),
+	)
+}
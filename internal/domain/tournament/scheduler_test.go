@@ -0,0 +1,248 @@
+package tournament
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSchedulerRepository struct {
+	mock.Mock
+}
+
+func (m *MockSchedulerRepository) GetDueToStart(ctx context.Context) ([]*domain.Tournament, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Tournament), args.Error(1)
+}
+
+func (m *MockSchedulerRepository) GetParticipantsCount(ctx context.Context, tournamentID uuid.UUID) (int, error) {
+	args := m.Called(ctx, tournamentID)
+	return args.Int(0), args.Error(1)
+}
+
+type MockTournamentStarter struct {
+	mock.Mock
+}
+
+func (m *MockTournamentStarter) Start(ctx context.Context, tournamentID uuid.UUID) error {
+	args := m.Called(ctx, tournamentID)
+	return args.Error(0)
+}
+
+func (m *MockTournamentStarter) RecordAutoStartFailure(ctx context.Context, tournamentID uuid.UUID, reason string) (int, error) {
+	args := m.Called(ctx, tournamentID, reason)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTournamentStarter) RecordAutoStartDelay(ctx context.Context, tournamentID uuid.UUID, reason string) error {
+	args := m.Called(ctx, tournamentID, reason)
+	return args.Error(0)
+}
+
+func (m *MockTournamentStarter) ClearAutoStartFailure(ctx context.Context, tournamentID uuid.UUID) error {
+	args := m.Called(ctx, tournamentID)
+	return args.Error(0)
+}
+
+// unlockedLock grants every lock and runs the guarded function
+func unlockedLock() *MockDistributedLock {
+	lock := new(MockDistributedLock)
+	lock.On("WithLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return lock
+}
+
+func TestTournamentScheduler_StartDue(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	scheduled := time.Now().Add(-time.Minute)
+
+	t.Run("starts due tournaments and broadcasts", func(t *testing.T) {
+		repo := new(MockSchedulerRepository)
+		starter := new(MockTournamentStarter)
+		broadcaster := new(MockBroadcaster)
+		lock := unlockedLock()
+
+		ready := &domain.Tournament{ID: uuid.New(), Status: domain.TournamentPending, StartTime: &scheduled}
+
+		repo.On("GetDueToStart", mock.Anything).Return([]*domain.Tournament{ready}, nil)
+		repo.On("GetParticipantsCount", mock.Anything, ready.ID).Return(3, nil)
+		starter.On("Start", mock.Anything, ready.ID).Return(nil)
+		broadcaster.On("Broadcast", ready.ID, "tournament_auto_started", mock.Anything).Return()
+
+		s := NewTournamentScheduler(repo, starter, broadcaster, lock, time.Minute, log)
+
+		assert.Equal(t, 1, s.StartDue(context.Background()))
+		starter.AssertExpectations(t)
+		broadcaster.AssertExpectations(t)
+	})
+
+	t.Run("delays tournaments with fewer than 2 participants without counting an attempt", func(t *testing.T) {
+		repo := new(MockSchedulerRepository)
+		starter := new(MockTournamentStarter)
+		broadcaster := new(MockBroadcaster)
+		lock := unlockedLock()
+
+		lonely := &domain.Tournament{ID: uuid.New(), Status: domain.TournamentPending, StartTime: &scheduled}
+
+		repo.On("GetDueToStart", mock.Anything).Return([]*domain.Tournament{lonely}, nil)
+		repo.On("GetParticipantsCount", mock.Anything, lonely.ID).Return(1, nil)
+		starter.On("RecordAutoStartDelay", mock.Anything, lonely.ID, "not enough participants: 1 of 2 required").Return(nil)
+
+		s := NewTournamentScheduler(repo, starter, broadcaster, lock, time.Minute, log)
+
+		assert.Equal(t, 0, s.StartDue(context.Background()))
+		starter.AssertNotCalled(t, "Start", mock.Anything, mock.Anything)
+		starter.AssertNotCalled(t, "RecordAutoStartFailure", mock.Anything, mock.Anything, mock.Anything)
+		starter.AssertExpectations(t)
+		broadcaster.AssertNotCalled(t, "Broadcast", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unchanged delay reason is not written again", func(t *testing.T) {
+		repo := new(MockSchedulerRepository)
+		starter := new(MockTournamentStarter)
+		broadcaster := new(MockBroadcaster)
+		lock := unlockedLock()
+
+		// Still waiting for participants since the previous check
+		waiting := &domain.Tournament{
+			ID: uuid.New(), Status: domain.TournamentPending, StartTime: &scheduled,
+			Metadata: map[string]interface{}{
+				domain.MetaAutoStartError: "not enough participants: 1 of 2 required",
+			},
+		}
+
+		repo.On("GetDueToStart", mock.Anything).Return([]*domain.Tournament{waiting}, nil)
+		repo.On("GetParticipantsCount", mock.Anything, waiting.ID).Return(1, nil)
+
+		s := NewTournamentScheduler(repo, starter, broadcaster, lock, time.Minute, log)
+
+		assert.Equal(t, 0, s.StartDue(context.Background()))
+		starter.AssertNotCalled(t, "RecordAutoStartDelay", mock.Anything, mock.Anything, mock.Anything)
+		starter.AssertNotCalled(t, "RecordAutoStartFailure", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("clears previous failure after successful start", func(t *testing.T) {
+		repo := new(MockSchedulerRepository)
+		starter := new(MockTournamentStarter)
+		broadcaster := new(MockBroadcaster)
+		lock := unlockedLock()
+
+		retried := &domain.Tournament{
+			ID: uuid.New(), Status: domain.TournamentPending, StartTime: &scheduled,
+			Metadata: map[string]interface{}{
+				domain.MetaAutoStartError:    "not enough participants: 1 of 2 required",
+				domain.MetaAutoStartAttempts: float64(3),
+			},
+		}
+
+		repo.On("GetDueToStart", mock.Anything).Return([]*domain.Tournament{retried}, nil)
+		repo.On("GetParticipantsCount", mock.Anything, retried.ID).Return(2, nil)
+		starter.On("Start", mock.Anything, retried.ID).Return(nil)
+		starter.On("ClearAutoStartFailure", mock.Anything, retried.ID).Return(nil)
+		broadcaster.On("Broadcast", retried.ID, "tournament_auto_started", mock.Anything).Return()
+
+		s := NewTournamentScheduler(repo, starter, broadcaster, lock, time.Minute, log)
+
+		assert.Equal(t, 1, s.StartDue(context.Background()))
+		starter.AssertExpectations(t)
+	})
+
+	t.Run("skips tournaments that exhausted auto-start attempts", func(t *testing.T) {
+		repo := new(MockSchedulerRepository)
+		starter := new(MockTournamentStarter)
+		broadcaster := new(MockBroadcaster)
+		lock := new(MockDistributedLock)
+
+		exhausted := &domain.Tournament{
+			ID: uuid.New(), Status: domain.TournamentPending, StartTime: &scheduled,
+			Metadata: map[string]interface{}{
+				domain.MetaAutoStartError:    "elimination format is not available",
+				domain.MetaAutoStartAttempts: float64(maxAutoStartAttempts),
+			},
+		}
+
+		repo.On("GetDueToStart", mock.Anything).Return([]*domain.Tournament{exhausted}, nil)
+
+		s := NewTournamentScheduler(repo, starter, broadcaster, lock, time.Minute, log)
+
+		assert.Equal(t, 0, s.StartDue(context.Background()))
+		lock.AssertNotCalled(t, "WithLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		starter.AssertNotCalled(t, "Start", mock.Anything, mock.Anything)
+	})
+
+	t.Run("continues after start failure", func(t *testing.T) {
+		repo := new(MockSchedulerRepository)
+		starter := new(MockTournamentStarter)
+		broadcaster := new(MockBroadcaster)
+		lock := unlockedLock()
+
+		failing := &domain.Tournament{ID: uuid.New(), Status: domain.TournamentPending, StartTime: &scheduled}
+		ok := &domain.Tournament{ID: uuid.New(), Status: domain.TournamentPending, StartTime: &scheduled}
+
+		repo.On("GetDueToStart", mock.Anything).Return([]*domain.Tournament{failing, ok}, nil)
+		repo.On("GetParticipantsCount", mock.Anything, mock.Anything).Return(2, nil)
+		starter.On("Start", mock.Anything, failing.ID).Return(errors.ErrServiceUnavailable.WithMessage("elimination format is not available"))
+		starter.On("RecordAutoStartFailure", mock.Anything, failing.ID, mock.Anything).Return(1, nil)
+		starter.On("Start", mock.Anything, ok.ID).Return(nil)
+		broadcaster.On("Broadcast", ok.ID, "tournament_auto_started", mock.Anything).Return()
+
+		s := NewTournamentScheduler(repo, starter, broadcaster, lock, time.Minute, log)
+
+		assert.Equal(t, 1, s.StartDue(context.Background()))
+		broadcaster.AssertNotCalled(t, "Broadcast", failing.ID, mock.Anything, mock.Anything)
+	})
+
+	t.Run("locks each start attempt", func(t *testing.T) {
+		repo := new(MockSchedulerRepository)
+		starter := new(MockTournamentStarter)
+		broadcaster := new(MockBroadcaster)
+		lock := new(MockDistributedLock)
+
+		ready := &domain.Tournament{ID: uuid.New(), Status: domain.TournamentPending, StartTime: &scheduled}
+		busy := &domain.Tournament{ID: uuid.New(), Status: domain.TournamentPending, StartTime: &scheduled}
+
+		repo.On("GetDueToStart", mock.Anything).Return([]*domain.Tournament{ready, busy}, nil)
+		repo.On("GetParticipantsCount", mock.Anything, ready.ID).Return(2, nil)
+		lock.On("WithLock", mock.Anything, "scheduler:start:"+ready.ID.String(), time.Minute, mock.Anything).Return(nil)
+		lock.On("WithLock", mock.Anything, "scheduler:start:"+busy.ID.String(), time.Minute, mock.Anything).
+			Return(errors.ErrConflict.WithMessage("lock already held"))
+		starter.On("Start", mock.Anything, ready.ID).Return(nil)
+		broadcaster.On("Broadcast", ready.ID, "tournament_auto_started", mock.Anything).Return()
+
+		s := NewTournamentScheduler(repo, starter, broadcaster, lock, time.Minute, log)
+
+		assert.Equal(t, 1, s.StartDue(context.Background()))
+		lock.AssertExpectations(t)
+		starter.AssertNotCalled(t, "Start", mock.Anything, busy.ID)
+		starter.AssertNotCalled(t, "RecordAutoStartFailure", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("manually started tournament is a no-op", func(t *testing.T) {
+		repo := new(MockSchedulerRepository)
+		starter := new(MockTournamentStarter)
+		broadcaster := new(MockBroadcaster)
+		lock := unlockedLock()
+
+		// Fetched as pending, then started by the organizer before the scheduler got to it
+		started := &domain.Tournament{ID: uuid.New(), Status: domain.TournamentPending, StartTime: &scheduled}
+
+		repo.On("GetDueToStart", mock.Anything).Return([]*domain.Tournament{started}, nil)
+		repo.On("GetParticipantsCount", mock.Anything, started.ID).Return(2, nil)
+		starter.On("Start", mock.Anything, started.ID).Return(errors.ErrConflict.WithMessage("tournament already started or completed"))
+
+		s := NewTournamentScheduler(repo, starter, broadcaster, lock, time.Minute, log)
+
+		assert.Equal(t, 0, s.StartDue(context.Background()))
+		starter.AssertNotCalled(t, "RecordAutoStartFailure", mock.Anything, mock.Anything, mock.Anything)
+		broadcaster.AssertNotCalled(t, "Broadcast", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -16,19 +17,35 @@ import (
 // TournamentRepository интерфейс для работы с турнирами
 type TournamentRepository interface {
 	Create(ctx context.Context, tournament *domain.Tournament) error
+	CreateWithGames(ctx context.Context, tournament *domain.Tournament, gameIDs []uuid.UUID) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error)
 	List(ctx context.Context, filter domain.TournamentFilter) ([]*domain.Tournament, error)
+	CountWithFilter(ctx context.Context, filter domain.TournamentFilter) (int, error)
+	GetActiveTournamentIDs(ctx context.Context) ([]uuid.UUID, error)
+	ListWithCursor(ctx context.Context, filter domain.TournamentFilter, pageReq *pagination.PageRequest) ([]*domain.Tournament, bool, error)
 	Update(ctx context.Context, tournament *domain.Tournament) error
+	UpdateVisibility(ctx context.Context, id uuid.UUID, visibility domain.TournamentVisibility) error
 	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.TournamentStatus) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	Purge(ctx context.Context, id uuid.UUID, deletedBefore time.Time) error
 	GetParticipantsCount(ctx context.Context, tournamentID uuid.UUID) (int, error)
-	GetParticipants(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TournamentParticipant, error)
+	GetParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) ([]*domain.ParticipantEntry, error)
+	CountParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) (int, error)
 	GetLatestParticipants(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TournamentParticipant, error)
 	GetLatestParticipantsGroupedByGame(ctx context.Context, tournamentID uuid.UUID) (map[string][]*domain.TournamentParticipant, error)
 	GetLatestParticipantsByGame(ctx context.Context, tournamentID uuid.UUID, gameType string) ([]*domain.TournamentParticipant, error)
 	AddParticipant(ctx context.Context, participant *domain.TournamentParticipant) error
+	RemoveParticipant(ctx context.Context, tournamentID, programID uuid.UUID) error
+	AddBan(ctx context.Context, ban *domain.TournamentBan) error
+	IsBanned(ctx context.Context, tournamentID, programID uuid.UUID) (bool, error)
+	RecordAutoStartFailure(ctx context.Context, id uuid.UUID, reason string) (int, error)
+	RecordAutoStartDelay(ctx context.Context, id uuid.UUID, reason string) error
+	ClearAutoStartFailure(ctx context.Context, id uuid.UUID) error
 	GetLeaderboard(ctx context.Context, tournamentID uuid.UUID, limit int) ([]*domain.LeaderboardEntry, error)
+	GetReferenceLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.LeaderboardEntry, error)
 	GetCrossGameLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error)
+	GetTeamRatings(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TeamRating, error)
 }
 
 // MatchRepository интерфейс для работы с матчами
@@ -36,12 +53,21 @@ type MatchRepository interface {
 	Create(ctx context.Context, match *domain.Match) error
 	CreateBatch(ctx context.Context, matches []*domain.Match) error
 	GetByTournamentID(ctx context.Context, tournamentID uuid.UUID, limit, offset int) ([]*domain.Match, error)
+	ListWithCursor(ctx context.Context, filter domain.MatchFilter, pageReq *pagination.PageRequest) ([]*domain.Match, bool, error)
 	GetPendingByTournamentID(ctx context.Context, tournamentID uuid.UUID) ([]*domain.Match, error)
 	GetPendingByTournamentAndGame(ctx context.Context, tournamentID uuid.UUID, gameType string) ([]*domain.Match, error)
 	ResetFailedMatches(ctx context.Context, tournamentID uuid.UUID) (int64, error)
 	GetNextRoundNumber(ctx context.Context, tournamentID uuid.UUID) (int, error)
 	GetNextRoundNumberByGame(ctx context.Context, tournamentID uuid.UUID, gameType string) (int, error)
 	GetMatchesByRounds(ctx context.Context, tournamentID uuid.UUID) ([]*domain.MatchRound, error)
+	GetByRound(ctx context.Context, tournamentID uuid.UUID, roundNumber, limit, offset int) ([]*domain.Match, error)
+	GetRoundStats(ctx context.Context, tournamentID uuid.UUID, roundNumber int) (*domain.TournamentMatchStats, error)
+	CancelPendingByProgram(ctx context.Context, tournamentID, programID uuid.UUID) (int64, error)
+	CancelUnfinishedByTournament(ctx context.Context, tournamentID uuid.UUID) (int64, error)
+	DiscardUnfinishedByGame(ctx context.Context, tournamentID uuid.UUID, gameType string) (deleted, cancelled int64, err error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Match, error)
+	AdjustResult(ctx context.Context, adjustment *domain.MatchResultAdjustment) error
+	GetResultAdjustments(ctx context.Context, matchID uuid.UUID) ([]*domain.MatchResultAdjustment, error)
 }
 
 // QueueManager интерфейс для работы с очередями
@@ -61,8 +87,47 @@ type DistributedLock interface {
 
 // GameRepository интерфейс для работы с играми в турнире
 type GameRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Game, error)
+	GetTournamentGame(ctx context.Context, tournamentID, gameID uuid.UUID) (*domain.TournamentGame, error)
 	GetTournamentGames(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TournamentGame, error)
 	SetActiveGame(ctx context.Context, tournamentID, gameID uuid.UUID) error
+	AddToTournament(ctx context.Context, tournamentID, gameID uuid.UUID) error
+	MarkRoundActive(ctx context.Context, tournamentID uuid.UUID, gameType string) error
+	GetGameWindow(ctx context.Context, tournamentID uuid.UUID, gameType string) (*domain.TournamentGame, error)
+}
+
+// LeaderboardRefresher интерфейс принудительного обновления materialized views таблиц лидеров
+type LeaderboardRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// ProgramLookup интерфейс для проверки программ участников перед генерацией матчей
+type ProgramLookup interface {
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Program, error)
+}
+
+// ActiveIDsCache кэш ID активных турниров (промах - nil)
+type ActiveIDsCache interface {
+	GetActiveIDs(ctx context.Context) ([]uuid.UUID, error)
+	SetActiveIDs(ctx context.Context, ids []uuid.UUID) error
+	InvalidateActiveIDs(ctx context.Context) error
+}
+
+// CrossGameChecker пересчитывает кросс-игровой рейтинг по матчам, минуя агрегат
+type CrossGameChecker interface {
+	GetCrossGameLeaderboardFromMatches(ctx context.Context, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error)
+}
+
+// BracketSeeder интерфейс для рассадки участников турнира на выбывание
+type BracketSeeder interface {
+	Seed(ctx context.Context, tournament *domain.Tournament) (int, error)
+}
+
+// Notifier интерфейс уведомлений о событиях турнира (участникам по email, на webhook'и турнира)
+type Notifier interface {
+	TournamentStarted(ctx context.Context, tournament *domain.Tournament) error
+	TournamentCompleted(ctx context.Context, tournament *domain.Tournament) error
+	TournamentCancelled(ctx context.Context, tournament *domain.Tournament) error
 }
 
 // Service - сервис управления турнирами
@@ -75,9 +140,21 @@ type Service struct {
 	leaderboardCache *cache.LeaderboardCache
 	broadcaster      Broadcaster
 	distributedLock  DistributedLock
+	purgeRetention   time.Duration
+	uploadPriority   UploadPriorityPolicy
+	programLookup    ProgramLookup
+	activeIDs        ActiveIDsCache
+	crossGameCheck   CrossGameChecker
+	refresher        LeaderboardRefresher
+	bracket          BracketSeeder
+	importer         ParticipantImporter
+	notifiers        []Notifier
 	log              *logger.Logger
 }
 
+// defaultPurgeRetention срок хранения удалённого турнира до окончательного удаления
+const defaultPurgeRetention = 30 * 24 * time.Hour
+
 // NewService создаёт новый сервис турниров
 func NewService(
 	tournamentRepo TournamentRepository,
@@ -99,21 +176,92 @@ func NewService(
 		leaderboardCache: leaderboardCache,
 		broadcaster:      broadcaster,
 		distributedLock:  distributedLock,
+		purgeRetention:   defaultPurgeRetention,
+		uploadPriority:   DefaultUploadPriorityPolicy,
 		log:              log,
 	}
 }
 
+// SetPurgeRetention задаёт, сколько удалённый турнир хранится до окончательного удаления
+func (s *Service) SetPurgeRetention(retention time.Duration) {
+	if retention > 0 {
+		s.purgeRetention = retention
+	}
+}
+
+// UploadPriorityPolicy определяет приоритет матчей новой программы
+// по числу других загрузок за последнее окно времени
+type UploadPriorityPolicy struct {
+	Window      time.Duration
+	HighLimit   int
+	MediumLimit int
+}
+
+// DefaultUploadPriorityPolicy политика по умолчанию
+var DefaultUploadPriorityPolicy = UploadPriorityPolicy{
+	Window:      10 * time.Minute,
+	HighLimit:   3,
+	MediumLimit: 10,
+}
+
+// SetUploadPriorityPolicy задаёт политику приоритета матчей новых программ
+func (s *Service) SetUploadPriorityPolicy(policy UploadPriorityPolicy) {
+	if policy.Window > 0 {
+		s.uploadPriority = policy
+	}
+}
+
+// SetProgramLookup включает технические победы над участниками,
+// чьи программы не прошли проверку при загрузке
+func (s *Service) SetProgramLookup(lookup ProgramLookup) {
+	s.programLookup = lookup
+}
+
+// SetActiveIDsCache включает кэширование списка ID активных турниров
+func (s *Service) SetActiveIDsCache(activeIDs ActiveIDsCache) {
+	s.activeIDs = activeIDs
+}
+
+// SetCrossGameChecker включает проверку согласованности кросс-игрового рейтинга:
+// каждый запрос дополнительно считается по матчам, расхождения пишутся в лог
+func (s *Service) SetCrossGameChecker(checker CrossGameChecker) {
+	s.crossGameCheck = checker
+}
+
+// SetBracket включает формат на выбывание: при старте участники рассаживаются по сетке
+func (s *Service) SetBracket(bracket BracketSeeder) {
+	s.bracket = bracket
+}
+
+// AddNotifier добавляет получателя событий о старте и завершении турниров
+func (s *Service) AddNotifier(notifier Notifier) {
+	s.notifiers = append(s.notifiers, notifier)
+}
+
+// notify записывает уведомления о событии турнира. Турнир уже обновлён, поэтому ошибки только логируются
+func (s *Service) notify(ctx context.Context, tournament *domain.Tournament, send func(Notifier, context.Context, *domain.Tournament) error) {
+	for _, notifier := range s.notifiers {
+		if err := send(notifier, ctx, tournament); err != nil {
+			s.log.LogError("Failed to enqueue tournament notification", err,
+				zap.String("tournament_id", tournament.ID.String()),
+			)
+		}
+	}
+}
+
 // CreateRequest - запрос на создание турнира
 type CreateRequest struct {
-	Name            string                 `json:"name"`
-	Description     string                 `json:"description,omitempty"`
-	GameType        string                 `json:"game_type"`
-	MaxParticipants *int                   `json:"max_participants,omitempty"`
-	MaxTeamSize     int                    `json:"max_team_size,omitempty"`
-	IsPermanent     bool                   `json:"is_permanent,omitempty"`
-	StartTime       *time.Time             `json:"start_time,omitempty"`
-	Metadata        map[string]interface{} `json:"metadata,omitempty"`
-	CreatorID       *uuid.UUID             `json:"-"` // Устанавливается из контекста, не из JSON
+	Name            string                      `json:"name"`
+	Description     string                      `json:"description,omitempty"`
+	GameType        string                      `json:"game_type"`
+	MaxParticipants *int                        `json:"max_participants,omitempty"`
+	MaxTeamSize     int                         `json:"max_team_size,omitempty"`
+	IsPermanent     bool                        `json:"is_permanent,omitempty"`
+	IsPractice      bool                        `json:"is_practice,omitempty"`
+	Visibility      domain.TournamentVisibility `json:"visibility,omitempty"` // public (по умолчанию), unlisted или private
+	StartTime       *time.Time                  `json:"start_time,omitempty"`
+	Metadata        map[string]interface{}      `json:"metadata,omitempty"`
+	CreatorID       *uuid.UUID                  `json:"-"` // Устанавливается из контекста (при клонировании - владелец копии), не из JSON
 }
 
 // generateCode генерирует уникальный код турнира (6-8 символов)
@@ -133,6 +281,10 @@ func (s *Service) Create(ctx context.Context, req *CreateRequest) (*domain.Tourn
 	if maxTeamSize <= 0 {
 		maxTeamSize = 1
 	}
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = domain.VisibilityPublic
+	}
 
 	tournament := &domain.Tournament{
 		ID:              uuid.New(),
@@ -144,6 +296,8 @@ func (s *Service) Create(ctx context.Context, req *CreateRequest) (*domain.Tourn
 		MaxParticipants: req.MaxParticipants,
 		MaxTeamSize:     maxTeamSize,
 		IsPermanent:     req.IsPermanent,
+		IsPractice:      req.IsPractice,
+		Visibility:      visibility,
 		StartTime:       req.StartTime,
 		Metadata:        req.Metadata,
 		CreatorID:       req.CreatorID,
@@ -173,6 +327,93 @@ func (s *Service) Create(ctx context.Context, req *CreateRequest) (*domain.Tourn
 	return tournament, nil
 }
 
+// cloneNameSuffix добавляется к имени копии, если новое имя не задано
+const cloneNameSuffix = " (copy)"
+
+// CloneTournament создаёт копию турнира с теми же настройками и набором игр
+// Участники, программы и матчи не копируются, копия создаётся в статусе pending с новым кодом.
+// Из req используются только переопределения: Name, Description, Visibility и CreatorID.
+// Турнир и его игры создаются в одной транзакции
+func (s *Service) CloneTournament(ctx context.Context, sourceID uuid.UUID, req CreateRequest) (*domain.Tournament, error) {
+	source, err := s.tournamentRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := &domain.Tournament{
+		ID:              uuid.New(),
+		Code:            generateCode(),
+		Name:            source.Name + cloneNameSuffix,
+		Description:     source.Description,
+		GameType:        source.GameType,
+		Status:          domain.TournamentPending,
+		MaxParticipants: source.MaxParticipants,
+		MaxTeamSize:     source.MaxTeamSize,
+		IsPermanent:     source.IsPermanent,
+		IsPractice:      source.IsPractice,
+		Visibility:      source.Visibility,
+		Metadata:        cloneMetadata(source.Metadata),
+		CreatorID:       source.CreatorID,
+	}
+	if req.Name != "" {
+		clone.Name = req.Name
+	}
+	if req.Description != "" {
+		clone.Description = req.Description
+	}
+	if req.CreatorID != nil {
+		clone.CreatorID = req.CreatorID
+	}
+	if req.Visibility != "" {
+		clone.Visibility = req.Visibility
+	}
+	if clone.Visibility == "" {
+		clone.Visibility = domain.VisibilityPublic
+	}
+
+	if err := clone.Validate(); err != nil {
+		return nil, errors.ErrValidation.WithError(err)
+	}
+
+	var gameIDs []uuid.UUID
+	if s.gameRepo != nil {
+		games, err := s.gameRepo.GetTournamentGames(ctx, sourceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tournament games: %w", err)
+		}
+		for _, game := range games {
+			gameIDs = append(gameIDs, game.GameID)
+		}
+	}
+
+	if err := s.tournamentRepo.CreateWithGames(ctx, clone, gameIDs); err != nil {
+		return nil, fmt.Errorf("failed to create tournament clone: %w", err)
+	}
+
+	s.log.Info("Tournament cloned",
+		zap.String("source_id", sourceID.String()),
+		zap.String("tournament_id", clone.ID.String()),
+		zap.Int("games", len(gameIDs)),
+	)
+
+	return clone, nil
+}
+
+// cloneMetadata копирует метаданные, чтобы копия турнира не разделяла map с исходным.
+// Отметка о предупреждении по бюджету времени относится к прошлому запуску и не копируется
+func cloneMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+
+	copied := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	delete(copied, domain.MetaTimeBudgetWarned)
+	return copied
+}
+
 // GetByID получает турнир по ID
 func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error) {
 	// Проверяем кэш
@@ -197,13 +438,7 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament
 
 // List получает список турниров с фильтрацией
 func (s *Service) List(ctx context.Context, filter domain.TournamentFilter) ([]*domain.Tournament, error) {
-	// Устанавливаем лимит по умолчанию
-	if filter.Limit <= 0 {
-		filter.Limit = 50
-	}
-	if filter.Limit > 100 {
-		filter.Limit = 100
-	}
+	filter.Limit = ListLimit(filter.Limit)
 
 	// Получаем из БД
 	tournaments, err := s.tournamentRepo.List(ctx, filter)
@@ -214,6 +449,64 @@ func (s *Service) List(ctx context.Context, filter domain.TournamentFilter) ([]*
 	return tournaments, nil
 }
 
+// ListLimit приводит размер страницы списка турниров к допустимому: по умолчанию 50, не больше 100
+func ListLimit(limit int) int {
+	if limit <= 0 {
+		return 50
+	}
+	if limit > 100 {
+		return 100
+	}
+	return limit
+}
+
+// CountWithFilter считает турниры по фильтрам списка без учёта limit/offset
+func (s *Service) CountWithFilter(ctx context.Context, filter domain.TournamentFilter) (int, error) {
+	return s.tournamentRepo.CountWithFilter(ctx, filter)
+}
+
+// GetActiveTournamentIDs возвращает ID активных турниров (для опроса таблиц лидеров и прогрева кэша).
+// Список берётся из кэша; ошибка кэша не мешает прочитать его из БД
+func (s *Service) GetActiveTournamentIDs(ctx context.Context) ([]uuid.UUID, error) {
+	if s.activeIDs != nil {
+		ids, err := s.activeIDs.GetActiveIDs(ctx)
+		if err != nil {
+			s.log.Warn("Failed to get active tournament ids from cache", zap.Error(err))
+		} else if ids != nil {
+			return ids, nil
+		}
+	}
+
+	ids, err := s.tournamentRepo.GetActiveTournamentIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active tournament ids: %w", err)
+	}
+
+	if s.activeIDs != nil {
+		if err := s.activeIDs.SetActiveIDs(ctx, ids); err != nil {
+			s.log.Warn("Failed to cache active tournament ids", zap.Error(err))
+		}
+	}
+
+	return ids, nil
+}
+
+// invalidateActiveIDs сбрасывает кэш ID активных турниров после смены статуса турнира
+func (s *Service) invalidateActiveIDs(ctx context.Context) {
+	if s.activeIDs == nil {
+		return
+	}
+	if err := s.activeIDs.InvalidateActiveIDs(ctx); err != nil {
+		s.log.Warn("Failed to invalidate active tournament ids", zap.Error(err))
+	}
+}
+
+// ListWithCursor получает страницу турниров в порядке (created_at, id) от новых к старым.
+// В отличие от offset, новые турниры не сдвигают следующие страницы
+func (s *Service) ListWithCursor(ctx context.Context, filter domain.TournamentFilter, pageReq *pagination.PageRequest) ([]*domain.Tournament, bool, error) {
+	return s.tournamentRepo.ListWithCursor(ctx, filter, pageReq)
+}
+
 // JoinRequest - запрос на участие в турнире
 type JoinRequest struct {
 	TournamentID uuid.UUID `json:"tournament_id"`
@@ -238,6 +531,15 @@ func (s *Service) Join(ctx context.Context, req *JoinRequest) error {
 			return errors.ErrTournamentStarted
 		}
 
+		// Проверяем, не забанена ли программа
+		banned, err := s.tournamentRepo.IsBanned(ctx, req.TournamentID, req.ProgramID)
+		if err != nil {
+			return fmt.Errorf("failed to check ban: %w", err)
+		}
+		if banned {
+			return errors.ErrForbidden.WithMessage("program is banned from this tournament")
+		}
+
 		// Проверяем лимит участников
 		if tournament.MaxParticipants != nil {
 			count, err := s.tournamentRepo.GetParticipantsCount(ctx, req.TournamentID)
@@ -279,6 +581,75 @@ func (s *Service) Join(ctx context.Context, req *JoinRequest) error {
 	})
 }
 
+// KickParticipant исключает программу из турнира и отменяет её ожидающие матчи
+func (s *Service) KickParticipant(ctx context.Context, tournamentID, programID uuid.UUID, reason string) error {
+	lockKey := fmt.Sprintf("tournament:join:%s", tournamentID.String())
+
+	return s.distributedLock.WithLock(ctx, lockKey, 5*time.Second, func(ctx context.Context) error {
+		if err := s.tournamentRepo.RemoveParticipant(ctx, tournamentID, programID); err != nil {
+			return err
+		}
+
+		return s.cancelParticipantMatches(ctx, tournamentID, programID, reason, "kicked")
+	})
+}
+
+// BanProgram исключает программу из турнира и запрещает повторное присоединение
+func (s *Service) BanProgram(ctx context.Context, tournamentID, programID uuid.UUID, reason string) error {
+	lockKey := fmt.Sprintf("tournament:join:%s", tournamentID.String())
+
+	return s.distributedLock.WithLock(ctx, lockKey, 5*time.Second, func(ctx context.Context) error {
+		ban := &domain.TournamentBan{
+			ID:           uuid.New(),
+			TournamentID: tournamentID,
+			ProgramID:    programID,
+			Reason:       reason,
+		}
+
+		if err := s.tournamentRepo.AddBan(ctx, ban); err != nil {
+			return fmt.Errorf("failed to ban program: %w", err)
+		}
+
+		// Программа может ещё не участвовать в турнире - бан всё равно действует
+		if err := s.tournamentRepo.RemoveParticipant(ctx, tournamentID, programID); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		return s.cancelParticipantMatches(ctx, tournamentID, programID, reason, "banned")
+	})
+}
+
+// cancelParticipantMatches отменяет ожидающие матчи исключённой программы и обновляет кэши
+func (s *Service) cancelParticipantMatches(ctx context.Context, tournamentID, programID uuid.UUID, reason, action string) error {
+	cancelled, err := s.matchRepo.CancelPendingByProgram(ctx, tournamentID, programID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel pending matches: %w", err)
+	}
+
+	s.log.Info("Participant removed from tournament",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.String("program_id", programID.String()),
+		zap.String("action", action),
+		zap.String("reason", reason),
+		zap.Int64("cancelled_matches", cancelled),
+	)
+
+	_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+
+	if err := s.leaderboardCache.Remove(ctx, tournamentID, programID); err != nil {
+		s.log.Error("Failed to remove program from leaderboard cache", zap.Error(err))
+	}
+
+	s.broadcaster.Broadcast(tournamentID, "participant_removed", map[string]interface{}{
+		"program_id":        programID,
+		"action":            action,
+		"reason":            reason,
+		"cancelled_matches": cancelled,
+	})
+
+	return nil
+}
+
 // Start запускает турнир (меняет статус на active и активирует первую игру)
 // Матчи НЕ генерируются автоматически - запускаются вручную администратором
 func (s *Service) Start(ctx context.Context, tournamentID uuid.UUID) error {
@@ -298,6 +669,10 @@ func (s *Service) Start(ctx context.Context, tournamentID uuid.UUID) error {
 			return errors.ErrConflict.WithMessage("tournament already started or completed")
 		}
 
+		if tournament.IsElimination() && s.bracket == nil {
+			return errors.ErrServiceUnavailable.WithMessage("elimination format is not available")
+		}
+
 		// Обновляем статус турнира
 		now := time.Now()
 		tournament.Status = domain.TournamentActive
@@ -312,6 +687,24 @@ func (s *Service) Start(ctx context.Context, tournamentID uuid.UUID) error {
 			zap.String("tournament_id", tournamentID.String()),
 		)
 
+		// Турнир на выбывание стартует с первым раундом сетки
+		payload := map[string]interface{}{
+			"status":     tournament.Status,
+			"start_time": tournament.StartTime,
+		}
+		if tournament.IsElimination() {
+			created, err := s.bracket.Seed(ctx, tournament)
+			if err != nil {
+				if revertErr := s.tournamentRepo.UpdateStatus(ctx, tournamentID, domain.TournamentPending); revertErr != nil {
+					s.log.LogError("Failed to revert tournament status after bracket seeding error", revertErr,
+						zap.String("tournament_id", tournamentID.String()),
+					)
+				}
+				return err
+			}
+			payload["matches_created"] = created
+		}
+
 		// Активируем первую игру (если есть)
 		if s.gameRepo != nil {
 			games, err := s.gameRepo.GetTournamentGames(ctx, tournamentID)
@@ -332,12 +725,12 @@ func (s *Service) Start(ctx context.Context, tournamentID uuid.UUID) error {
 
 		// Инвалидируем кэш
 		_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+		s.invalidateActiveIDs(ctx)
 
 		// Отправляем broadcast обновление
-		s.broadcaster.Broadcast(tournamentID, "tournament_update", map[string]interface{}{
-			"status":     tournament.Status,
-			"start_time": tournament.StartTime,
-		})
+		s.broadcaster.Broadcast(tournamentID, "tournament_update", payload)
+
+		s.notify(ctx, tournament, Notifier.TournamentStarted)
 
 		return nil
 	})
@@ -353,10 +746,47 @@ func (s *Service) Start(ctx context.Context, tournamentID uuid.UUID) error {
 	return nil
 }
 
+// RecordAutoStartFailure сохраняет причину неудачного автостарта в метаданных турнира
+// Возвращает количество неудачных попыток
+func (s *Service) RecordAutoStartFailure(ctx context.Context, tournamentID uuid.UUID, reason string) (int, error) {
+	attempts, err := s.tournamentRepo.RecordAutoStartFailure(ctx, tournamentID, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	// Организатор видит ошибку в деталях турнира
+	_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+
+	return attempts, nil
+}
+
+// RecordAutoStartDelay сохраняет причину, по которой автостарт откладывается
+// В отличие от неудачной попытки не приближает отказ от автостарта
+func (s *Service) RecordAutoStartDelay(ctx context.Context, tournamentID uuid.UUID, reason string) error {
+	if err := s.tournamentRepo.RecordAutoStartDelay(ctx, tournamentID, reason); err != nil {
+		return err
+	}
+
+	_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+
+	return nil
+}
+
+// ClearAutoStartFailure удаляет сведения о неудачном автостарте после успешного запуска
+func (s *Service) ClearAutoStartFailure(ctx context.Context, tournamentID uuid.UUID) error {
+	if err := s.tournamentRepo.ClearAutoStartFailure(ctx, tournamentID); err != nil {
+		return err
+	}
+
+	_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+
+	return nil
+}
+
 // generateRoundRobinMatches генерирует матчи по системе round-robin (каждый с каждым)
 // Каждая пара играет 2 матча (AB и BA), итерации выполняются внутри tjudge-cli через параметр -i
 // Рейтинг = сумма очков из всех матчей
-func (s *Service) generateRoundRobinMatches(tournament *domain.Tournament, participants []*domain.TournamentParticipant, roundNumber int) ([]*domain.Match, error) {
+func (s *Service) generateRoundRobinMatches(tournament *domain.Tournament, participants []*domain.TournamentParticipant, roundNumber int, unrunnable map[uuid.UUID]bool) ([]*domain.Match, error) {
 	var matches []*domain.Match
 	now := time.Now()
 
@@ -375,7 +805,8 @@ func (s *Service) generateRoundRobinMatches(tournament *domain.Tournament, parti
 				Program2ID:   participants[j].ProgramID,
 				GameType:     tournament.GameType,
 				Status:       domain.MatchPending,
-				Priority:     domain.PriorityMedium,
+				Priority:     tournament.MatchPriority(domain.PriorityMedium),
+				IsPractice:   tournament.IsPractice,
 				RoundNumber:  roundNumber,
 				CreatedAt:    now,
 			}
@@ -384,6 +815,10 @@ func (s *Service) generateRoundRobinMatches(tournament *domain.Tournament, parti
 				return nil, fmt.Errorf("invalid match generated: %w", err)
 			}
 
+			if !applyWalkover(match, unrunnable, now) {
+				continue
+			}
+
 			matches = append(matches, match)
 		}
 	}
@@ -415,6 +850,7 @@ func (s *Service) Complete(ctx context.Context, tournamentID uuid.UUID) error {
 	)
 
 	_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+	s.invalidateActiveIDs(ctx)
 
 	// Отправляем broadcast обновление
 	s.broadcaster.Broadcast(tournamentID, "tournament_update", map[string]interface{}{
@@ -422,9 +858,137 @@ func (s *Service) Complete(ctx context.Context, tournamentID uuid.UUID) error {
 		"end_time": tournament.EndTime,
 	})
 
+	s.notify(ctx, tournament, Notifier.TournamentCompleted)
+
 	return nil
 }
 
+// Cancel отменяет ожидающий или активный турнир. Незавершённые матчи отменяются,
+// сыгранные остаются в истории. Возвращает число отменённых матчей
+func (s *Service) Cancel(ctx context.Context, tournamentID uuid.UUID) (int64, error) {
+	tournament, err := s.tournamentRepo.GetByID(ctx, tournamentID)
+	if err != nil {
+		return 0, err
+	}
+
+	if tournament.Status != domain.TournamentPending && tournament.Status != domain.TournamentActive {
+		return 0, errors.ErrConflict.WithMessage("tournament is already completed or cancelled")
+	}
+
+	cancelled, err := s.matchRepo.CancelUnfinishedByTournament(ctx, tournamentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel unfinished matches: %w", err)
+	}
+
+	now := time.Now()
+	tournament.Status = domain.TournamentCancelled
+	tournament.EndTime = &now
+
+	if err := s.tournamentRepo.Update(ctx, tournament); err != nil {
+		return 0, fmt.Errorf("failed to cancel tournament: %w", err)
+	}
+
+	_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+
+	s.log.Info("Tournament cancelled",
+		zap.Bool("audit", true),
+		zap.String("tournament_id", tournamentID.String()),
+		zap.Int64("cancelled_matches", cancelled),
+	)
+
+	s.broadcaster.Broadcast(tournamentID, "tournament_update", map[string]interface{}{
+		"status":            tournament.Status,
+		"end_time":          tournament.EndTime,
+		"cancelled_matches": cancelled,
+	})
+
+	s.notify(ctx, tournament, Notifier.TournamentCancelled)
+
+	return cancelled, nil
+}
+
+// finalStandingsLimit число мест в итоговой таблице, рассылаемой при принудительном завершении
+const finalStandingsLimit = 100
+
+// SetLeaderboardRefresher задаёт обновление таблиц лидеров перед принудительным завершением
+func (s *Service) SetLeaderboardRefresher(refresher LeaderboardRefresher) {
+	s.refresher = refresher
+}
+
+// ForceCompleteResult итог принудительного завершения турнира
+type ForceCompleteResult struct {
+	CancelledMatches int64                      `json:"cancelled_matches"`
+	Standings        []*domain.LeaderboardEntry `json:"standings"`
+}
+
+// ForceComplete завершает активный турнир, даже если часть матчей не может быть сыграна
+// (например, из-за сломанного бота). При cancelPending оставшиеся ожидающие и выполняющиеся
+// матчи отменяются. Перед сменой статуса обновляются таблицы лидеров, итоговые места рассылаются
+func (s *Service) ForceComplete(ctx context.Context, tournamentID uuid.UUID, cancelPending bool) (*ForceCompleteResult, error) {
+	tournament, err := s.tournamentRepo.GetByID(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tournament.Status != domain.TournamentActive {
+		return nil, errors.ErrConflict.WithMessage("tournament is not active")
+	}
+
+	result := &ForceCompleteResult{}
+	if cancelPending {
+		result.CancelledMatches, err = s.matchRepo.CancelUnfinishedByTournament(ctx, tournamentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel unfinished matches: %w", err)
+		}
+	}
+
+	// Итоговые места считаются по обновлённым views. Обновление, уже запущенное другим
+	// процессом, тоже подходит: отменённые матчи не влияют на очки
+	if s.refresher != nil {
+		if err := s.refresher.Refresh(ctx); err != nil && !errors.IsConflict(err) {
+			return nil, fmt.Errorf("failed to refresh leaderboard: %w", err)
+		}
+	}
+
+	now := time.Now()
+	tournament.Status = domain.TournamentCompleted
+	tournament.EndTime = &now
+
+	if err := s.tournamentRepo.Update(ctx, tournament); err != nil {
+		return nil, fmt.Errorf("failed to complete tournament: %w", err)
+	}
+
+	_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+
+	result.Standings, err = s.tournamentRepo.GetLeaderboard(ctx, tournamentID, finalStandingsLimit)
+	if err != nil {
+		// Турнир уже завершён: итоговые места доступны через таблицу лидеров
+		s.log.LogError("Failed to get final standings", err, zap.String("tournament_id", tournamentID.String()))
+	}
+	if result.Standings == nil {
+		result.Standings = []*domain.LeaderboardEntry{}
+	}
+
+	s.log.Info("Tournament force-completed",
+		zap.Bool("audit", true),
+		zap.String("tournament_id", tournamentID.String()),
+		zap.Bool("cancel_pending", cancelPending),
+		zap.Int64("cancelled_matches", result.CancelledMatches),
+	)
+
+	s.broadcaster.Broadcast(tournamentID, "tournament_update", map[string]interface{}{
+		"status":            tournament.Status,
+		"end_time":          tournament.EndTime,
+		"forced":            true,
+		"cancelled_matches": result.CancelledMatches,
+		"standings":         result.Standings,
+	})
+
+	s.notify(ctx, tournament, Notifier.TournamentCompleted)
+
+	return result, nil
+}
+
 // Delete удаляет турнир
 func (s *Service) Delete(ctx context.Context, tournamentID uuid.UUID) error {
 	// Получаем турнир для проверки
@@ -438,7 +1002,7 @@ func (s *Service) Delete(ctx context.Context, tournamentID uuid.UUID) error {
 		return errors.ErrConflict.WithMessage("cannot delete active tournament")
 	}
 
-	// Удаляем из БД
+	// Мягкое удаление: матчи и участники сохраняются для истории
 	if err := s.tournamentRepo.Delete(ctx, tournamentID); err != nil {
 		return fmt.Errorf("failed to delete tournament: %w", err)
 	}
@@ -450,14 +1014,60 @@ func (s *Service) Delete(ctx context.Context, tournamentID uuid.UUID) error {
 	// Инвалидируем кэш
 	_ = s.tournamentCache.Invalidate(ctx, tournamentID)
 
+	s.broadcaster.Broadcast(tournamentID, "tournament_deleted", map[string]interface{}{
+		"tournament_id": tournamentID,
+	})
+
 	return nil
 }
 
-// GetLeaderboard получает таблицу лидеров турнира
-func (s *Service) GetLeaderboard(ctx context.Context, tournamentID uuid.UUID, limit int) ([]*domain.LeaderboardEntry, error) {
-	// Проверяем кэш
-	cached, err := s.leaderboardCache.GetTop(ctx, tournamentID, limit)
-	if err == nil && cached != nil && len(cached) > 0 {
+// Restore восстанавливает мягко удалённый турнир
+func (s *Service) Restore(ctx context.Context, tournamentID uuid.UUID) (*domain.Tournament, error) {
+	if err := s.tournamentRepo.Restore(ctx, tournamentID); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Tournament restored",
+		zap.String("tournament_id", tournamentID.String()),
+	)
+
+	_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+
+	tournament, err := s.GetByID(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcaster.Broadcast(tournamentID, "tournament_update", map[string]interface{}{
+		"status":   tournament.Status,
+		"restored": true,
+	})
+
+	return tournament, nil
+}
+
+// Purge окончательно удаляет турнир со всеми матчами и участниками
+// Доступно только для турниров, удалённых раньше срока хранения
+func (s *Service) Purge(ctx context.Context, tournamentID uuid.UUID) error {
+	if err := s.tournamentRepo.Purge(ctx, tournamentID, time.Now().Add(-s.purgeRetention)); err != nil {
+		return err
+	}
+
+	s.log.Info("Tournament purged",
+		zap.String("tournament_id", tournamentID.String()),
+	)
+
+	_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+	_ = s.leaderboardCache.Clear(ctx, tournamentID)
+
+	return nil
+}
+
+// GetLeaderboard получает таблицу лидеров турнира
+func (s *Service) GetLeaderboard(ctx context.Context, tournamentID uuid.UUID, limit int) ([]*domain.LeaderboardEntry, error) {
+	// Проверяем кэш
+	cached, err := s.leaderboardCache.GetTop(ctx, tournamentID, limit)
+	if err == nil && cached != nil && len(cached) > 0 {
 		return cached, nil
 	}
 
@@ -477,6 +1087,11 @@ func (s *Service) GetLeaderboard(ctx context.Context, tournamentID uuid.UUID, li
 	return leaderboard, nil
 }
 
+// GetReferenceLeaderboard получает результаты эталонных ботов в калибровочных матчах турнира
+func (s *Service) GetReferenceLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.LeaderboardEntry, error) {
+	return s.tournamentRepo.GetReferenceLeaderboard(ctx, tournamentID)
+}
+
 // CreateMatch создаёт матч и добавляет в очередь
 func (s *Service) CreateMatch(ctx context.Context, tournamentID, program1ID, program2ID uuid.UUID, priority domain.MatchPriority) (*domain.Match, error) {
 	// Получаем турнир для game_type
@@ -492,7 +1107,8 @@ func (s *Service) CreateMatch(ctx context.Context, tournamentID, program1ID, pro
 		Program2ID:   program2ID,
 		GameType:     tournament.GameType,
 		Status:       domain.MatchPending,
-		Priority:     priority,
+		Priority:     tournament.MatchPriority(priority),
+		IsPractice:   tournament.IsPractice,
 		CreatedAt:    time.Now(),
 	}
 
@@ -525,19 +1141,152 @@ func (s *Service) CreateMatch(ctx context.Context, tournamentID, program1ID, pro
 	return match, nil
 }
 
+// TestMatchRequest - запрос на тестовый матч двух программ пользователя
+type TestMatchRequest struct {
+	Program1ID uuid.UUID `json:"program1_id"`
+	Program2ID uuid.UUID `json:"program2_id"`
+	GameType   string    `json:"game_type"`
+	UserID     uuid.UUID `json:"-"` // Устанавливается из контекста
+}
+
+// CreateTestMatch создаёт матч двух программ пользователя вне очереди турнира.
+// Матч ставится с высоким приоритетом и не влияет на рейтинги и таблицы лидеров
+func (s *Service) CreateTestMatch(ctx context.Context, req *TestMatchRequest) (*domain.Match, error) {
+	if s.programLookup == nil {
+		return nil, errors.ErrServiceUnavailable.WithMessage("test matches are not available")
+	}
+	if req.Program1ID == uuid.Nil || req.Program2ID == uuid.Nil {
+		return nil, errors.ErrValidation.WithMessage("program1_id and program2_id are required")
+	}
+
+	programs, err := s.programLookup.GetByIDs(ctx, []uuid.UUID{req.Program1ID, req.Program2ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get programs: %w", err)
+	}
+	byID := make(map[uuid.UUID]*domain.Program, len(programs))
+	for _, program := range programs {
+		byID[program.ID] = program
+	}
+
+	program1, program2 := byID[req.Program1ID], byID[req.Program2ID]
+	if program1 == nil || program2 == nil {
+		return nil, errors.ErrNotFound.WithMessage("program not found")
+	}
+	if program1.UserID != req.UserID || program2.UserID != req.UserID {
+		return nil, errors.ErrForbidden.WithMessage("test matches are only allowed between your own programs")
+	}
+
+	gameType := req.GameType
+	if gameType == "" {
+		gameType = program1.GameType
+	}
+	if program1.GameType != gameType || program2.GameType != gameType {
+		return nil, errors.ErrValidation.WithMessage("both programs must be written for game " + gameType)
+	}
+
+	// Матч хранится в турнире первой программы: так он доступен по обычным ссылкам на матчи
+	if program1.TournamentID == nil {
+		return nil, errors.ErrValidation.WithMessage("program is not uploaded to a tournament")
+	}
+
+	match := &domain.Match{
+		ID:           uuid.New(),
+		TournamentID: *program1.TournamentID,
+		Program1ID:   program1.ID,
+		Program2ID:   program2.ID,
+		GameType:     gameType,
+		Status:       domain.MatchPending,
+		Priority:     domain.PriorityHigh,
+		IsTest:       true,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := s.matchRepo.Create(ctx, match); err != nil {
+		return nil, fmt.Errorf("failed to create test match: %w", err)
+	}
+
+	if err := s.queueManager.Enqueue(ctx, match); err != nil {
+		s.log.Error("Failed to enqueue test match",
+			zap.Error(err),
+			zap.String("match_id", match.ID.String()),
+		)
+		// Не возвращаем ошибку, матч всё равно создан
+	}
+
+	s.log.Info("Test match created",
+		zap.String("match_id", match.ID.String()),
+		zap.String("user_id", req.UserID.String()),
+		zap.String("game_type", gameType),
+	)
+
+	return match, nil
+}
+
 // GetMatches получает матчи турнира
 func (s *Service) GetMatches(ctx context.Context, tournamentID uuid.UUID, limit, offset int) ([]*domain.Match, error) {
 	return s.matchRepo.GetByTournamentID(ctx, tournamentID, limit, offset)
 }
 
+// GetParticipants получает страницу участников турнира в порядке регистрации.
+// Несуществующий турнир - ErrNotFound, а не пустой список
+func (s *Service) GetParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) ([]*domain.ParticipantEntry, error) {
+	if _, err := s.GetByID(ctx, tournamentID); err != nil {
+		return nil, err
+	}
+	return s.tournamentRepo.GetParticipants(ctx, tournamentID, filter)
+}
+
+// CountParticipants считает участников турнира по фильтрам GetParticipants без limit/offset
+func (s *Service) CountParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) (int, error) {
+	return s.tournamentRepo.CountParticipants(ctx, tournamentID, filter)
+}
+
+// GetMatchesWithCursor получает страницу матчей турнира в порядке (round_number, id).
+// Тестовые матчи команд, как и в GetMatches, не показываются
+func (s *Service) GetMatchesWithCursor(ctx context.Context, tournamentID uuid.UUID, pageReq *pagination.PageRequest) ([]*domain.Match, bool, error) {
+	filter := domain.MatchFilter{TournamentID: &tournamentID, ExcludeTest: true}
+	return s.matchRepo.ListWithCursor(ctx, filter, pageReq)
+}
+
 // GetMatchesByRounds получает матчи турнира сгруппированные по раундам
 func (s *Service) GetMatchesByRounds(ctx context.Context, tournamentID uuid.UUID) ([]*domain.MatchRound, error) {
 	return s.matchRepo.GetMatchesByRounds(ctx, tournamentID)
 }
 
+// GetRoundMatches получает страницу матчей раунда и сводку по всему раунду.
+// Несуществующий раунд - пустой список, а не ошибка: раунд мог ещё не начаться
+func (s *Service) GetRoundMatches(ctx context.Context, tournamentID uuid.UUID, roundNumber, limit, offset int) (*domain.RoundMatches, error) {
+	if roundNumber < 1 {
+		return nil, errors.ErrInvalidInput.WithMessage("round number must be positive")
+	}
+
+	summary, err := s.matchRepo.GetRoundStats(ctx, tournamentID, roundNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.matchRepo.GetByRound(ctx, tournamentID, roundNumber, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if matches == nil {
+		matches = []*domain.Match{}
+	}
+
+	return &domain.RoundMatches{
+		RoundNumber: roundNumber,
+		Summary:     *summary,
+		Matches:     matches,
+		Limit:       limit,
+		Offset:      offset,
+	}, nil
+}
+
 // ProgramRepository интерфейс для работы с программами (для оптимизированного round-robin)
 type ProgramRepository interface {
 	GetByTournamentAndGame(ctx context.Context, tournamentID, gameID uuid.UUID) ([]*domain.Program, error)
+	ListReferenceByGame(ctx context.Context, gameID uuid.UUID) ([]*domain.Program, error)
+	SetValidationResult(ctx context.Context, id uuid.UUID, status domain.ProgramValidationStatus, message string) error
 }
 
 // ScheduleNewProgramMatchesRequest запрос на создание матчей для новой программы
@@ -546,11 +1295,42 @@ type ScheduleNewProgramMatchesRequest struct {
 	GameID       uuid.UUID
 	NewProgramID uuid.UUID
 	TeamID       uuid.UUID
+	// Priority переопределяет приоритет, вычисленный по UploadPriorityPolicy
+	Priority domain.MatchPriority
+}
+
+// priorityFor вычисляет приоритет матчей новой программы: чем больше других
+// программ загружено за окно, тем ниже приоритет, чтобы серия загрузок
+// не вытесняла уже ожидающие матчи
+func (p UploadPriorityPolicy) priorityFor(programs []*domain.Program, newProgramID uuid.UUID, now time.Time) domain.MatchPriority {
+	recent := 0
+	for _, prog := range programs {
+		if prog.ID == newProgramID {
+			continue
+		}
+		uploadedAt := prog.CreatedAt
+		if prog.UpdatedAt.After(uploadedAt) {
+			uploadedAt = prog.UpdatedAt
+		}
+		if now.Sub(uploadedAt) < p.Window {
+			recent++
+		}
+	}
+
+	switch {
+	case recent < p.HighLimit:
+		return domain.PriorityHigh
+	case recent < p.MediumLimit:
+		return domain.PriorityMedium
+	default:
+		return domain.PriorityLow
+	}
 }
 
 // ScheduleNewProgramMatches создаёт матчи для новой программы против всех существующих
 // Это оптимизированный round-robin - вместо генерации всех матчей заново,
-// создаются только матчи с новой программой
+// создаются только матчи с новой программой. Если в турнире включена калибровка,
+// первыми в очередь встают матчи с эталонными ботами игры
 func (s *Service) ScheduleNewProgramMatches(ctx context.Context, req *ScheduleNewProgramMatchesRequest, programRepo ProgramRepository) error {
 	// Используем distributed lock для предотвращения гонок при создании матчей
 	lockKey := fmt.Sprintf("tournament:schedule:%s:%s", req.TournamentID.String(), req.GameID.String())
@@ -567,16 +1347,32 @@ func (s *Service) ScheduleNewProgramMatches(ctx context.Context, req *ScheduleNe
 			return errors.ErrConflict.WithMessage("cannot schedule matches for completed tournament")
 		}
 
+		// В турнире на выбывание пары определяет сетка
+		if tournament.IsElimination() {
+			return nil
+		}
+
 		// Получаем все программы в турнире для данной игры
 		programs, err := programRepo.GetByTournamentAndGame(ctx, req.TournamentID, req.GameID)
 		if err != nil {
 			return fmt.Errorf("failed to get programs: %w", err)
 		}
 
+		// Калибровочные матчи идут первыми
+		matches, err := s.referenceMatches(ctx, tournament, req.GameID, req.NewProgramID, programRepo)
+		if err != nil {
+			return err
+		}
+
 		// Создаём матчи только против других программ (не своей команды)
-		var matches []*domain.Match
 		now := time.Now()
 
+		priority := req.Priority
+		if priority == "" {
+			priority = s.uploadPriority.priorityFor(programs, req.NewProgramID, now)
+		}
+		priority = tournament.MatchPriority(priority)
+
 		for _, prog := range programs {
 			// Пропускаем свою программу и программы своей команды
 			if prog.ID == req.NewProgramID {
@@ -593,7 +1389,8 @@ func (s *Service) ScheduleNewProgramMatches(ctx context.Context, req *ScheduleNe
 				Program2ID:   prog.ID,
 				GameType:     tournament.GameType,
 				Status:       domain.MatchPending,
-				Priority:     domain.PriorityHigh, // Новые матчи с высоким приоритетом
+				Priority:     priority,
+				IsPractice:   tournament.IsPractice,
 				CreatedAt:    now,
 			}
 
@@ -636,6 +1433,7 @@ func (s *Service) ScheduleNewProgramMatches(ctx context.Context, req *ScheduleNe
 			zap.String("tournament_id", req.TournamentID.String()),
 			zap.String("program_id", req.NewProgramID.String()),
 			zap.Int("matches_created", len(matches)),
+			zap.String("priority", string(priority)),
 		)
 
 		// Отправляем broadcast обновление
@@ -648,6 +1446,143 @@ func (s *Service) ScheduleNewProgramMatches(ctx context.Context, req *ScheduleNe
 	})
 }
 
+// ScheduleCalibrationMatches создаёт только калибровочные матчи новой программы
+// со всеми эталонными ботами игры. Вызывается при загрузке программы и ничего не делает,
+// если калибровка в турнире выключена
+func (s *Service) ScheduleCalibrationMatches(ctx context.Context, tournamentID, gameID, programID uuid.UUID, programRepo ProgramRepository) error {
+	tournament, err := s.GetByID(ctx, tournamentID)
+	if err != nil {
+		return err
+	}
+
+	if !tournament.ReferenceCalibration() {
+		return nil
+	}
+	if tournament.Status != domain.TournamentActive && tournament.Status != domain.TournamentPending {
+		return errors.ErrConflict.WithMessage("cannot schedule matches for completed tournament")
+	}
+
+	matches, err := s.referenceMatches(ctx, tournament, gameID, programID, programRepo)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	if err := s.matchRepo.CreateBatch(ctx, matches); err != nil {
+		return fmt.Errorf("failed to create calibration matches: %w", err)
+	}
+
+	for _, match := range matches {
+		if err := s.queueManager.Enqueue(ctx, match); err != nil {
+			s.log.Error("Failed to enqueue calibration match",
+				zap.Error(err),
+				zap.String("match_id", match.ID.String()),
+			)
+		}
+	}
+
+	s.log.Info("Calibration matches scheduled",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.String("program_id", programID.String()),
+		zap.Int("matches_created", len(matches)),
+	)
+
+	s.broadcaster.Broadcast(tournamentID, "matches_created", map[string]interface{}{
+		"program_id":    programID.String(),
+		"matches_count": len(matches),
+	})
+
+	return nil
+}
+
+// referenceMatches возвращает калибровочные матчи программы с эталонными ботами игры.
+// Калибровка идёт до соревновательных матчей, поэтому приоритет высокий (кроме тренировочных турниров)
+func (s *Service) referenceMatches(ctx context.Context, tournament *domain.Tournament, gameID, programID uuid.UUID, programRepo ProgramRepository) ([]*domain.Match, error) {
+	if !tournament.ReferenceCalibration() {
+		return nil, nil
+	}
+
+	bots, err := programRepo.ListReferenceByGame(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reference programs: %w", err)
+	}
+
+	now := time.Now()
+	matches := make([]*domain.Match, 0, len(bots))
+	for _, bot := range bots {
+		matches = append(matches, &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   programID,
+			Program2ID:   bot.ID,
+			GameType:     bot.GameType,
+			Status:       domain.MatchPending,
+			Priority:     tournament.MatchPriority(domain.PriorityHigh),
+			IsPractice:   tournament.IsPractice,
+			CreatedAt:    now,
+		})
+	}
+
+	return matches, nil
+}
+
+// ScheduleValidationMatch ставит проверочный матч загруженной программы с эталонным ботом игры.
+// Матч тестовый и с низким приоритетом: его итог сохраняется в validation_status программы,
+// а не в рейтингах. Возвращает nil без ошибки, если у игры нет проверочного бота
+func (s *Service) ScheduleValidationMatch(ctx context.Context, tournamentID, gameID, programID uuid.UUID, programRepo ProgramRepository) (*domain.Match, error) {
+	game, err := s.gameRepo.GetByID(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game: %w", err)
+	}
+	if game.ValidationBotID == nil {
+		return nil, nil
+	}
+
+	match := newValidationMatch(tournamentID, game, programID, time.Now())
+	if err := s.matchRepo.Create(ctx, match); err != nil {
+		return nil, fmt.Errorf("failed to create validation match: %w", err)
+	}
+
+	// Статус выставляется до постановки в очередь, чтобы не затереть итог быстрого матча
+	if err := programRepo.SetValidationResult(ctx, programID, domain.ValidationPending, ""); err != nil {
+		return nil, fmt.Errorf("failed to mark program validation pending: %w", err)
+	}
+
+	if err := s.queueManager.Enqueue(ctx, match); err != nil {
+		s.log.Error("Failed to enqueue validation match",
+			zap.Error(err),
+			zap.String("match_id", match.ID.String()),
+		)
+		// Не возвращаем ошибку, матч всё равно создан
+	}
+
+	s.log.Info("Validation match scheduled",
+		zap.String("match_id", match.ID.String()),
+		zap.String("program_id", programID.String()),
+		zap.String("validation_bot_id", game.ValidationBotID.String()),
+	)
+
+	return match, nil
+}
+
+// newValidationMatch создаёт проверочный матч программы (первый игрок) с проверочным ботом игры
+func newValidationMatch(tournamentID uuid.UUID, game *domain.Game, programID uuid.UUID, now time.Time) *domain.Match {
+	return &domain.Match{
+		ID:           uuid.New(),
+		TournamentID: tournamentID,
+		Program1ID:   programID,
+		Program2ID:   *game.ValidationBotID,
+		GameType:     game.Name,
+		Status:       domain.MatchPending,
+		Priority:     domain.PriorityLow,
+		IsTest:       true,
+		IsValidation: true,
+		CreatedAt:    now,
+	}
+}
+
 // GetCrossGameLeaderboard возвращает кросс-игровой рейтинг турнира
 // (команда — рейтинг игры 1 — … — рейтинг игры N — позиция в турнире)
 func (s *Service) GetCrossGameLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error) {
@@ -657,9 +1592,111 @@ func (s *Service) GetCrossGameLeaderboard(ctx context.Context, tournamentID uuid
 		return nil, fmt.Errorf("failed to get cross-game leaderboard: %w", err)
 	}
 
+	if s.crossGameCheck != nil {
+		s.checkCrossGameLeaderboard(ctx, tournamentID, entries)
+	}
+
 	return entries, nil
 }
 
+// GetTeamLeaderboard возвращает рейтинг команд по сумме очков во всех играх турнира.
+// С normalize очки каждой игры переводятся в долю от лучшего результата игры и усредняются,
+// чтобы команды, играющие не во всех играх, не проигрывали из-за числа игр
+func (s *Service) GetTeamLeaderboard(ctx context.Context, tournamentID uuid.UUID, normalize bool) ([]*domain.TeamRating, error) {
+	var ratings []*domain.TeamRating
+	if s.leaderboardCache != nil {
+		cached, err := s.leaderboardCache.GetTeamRatings(ctx, tournamentID)
+		if err == nil {
+			ratings = cached
+		}
+	}
+
+	if ratings == nil {
+		var err error
+		ratings, err = s.tournamentRepo.GetTeamRatings(ctx, tournamentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get team ratings: %w", err)
+		}
+
+		if s.leaderboardCache != nil {
+			if err := s.leaderboardCache.SetTeamRatings(ctx, tournamentID, ratings); err != nil {
+				s.log.Error("Failed to cache team leaderboard", zap.Error(err))
+			}
+		}
+	}
+
+	if normalize {
+		return domain.NormalizeTeamRatings(ratings), nil
+	}
+	return ratings, nil
+}
+
+// checkCrossGameLeaderboard сравнивает рейтинг из агрегата с рейтингом, посчитанным
+// по матчам. Расхождение означает, что агрегат нужно пересобрать
+func (s *Service) checkCrossGameLeaderboard(ctx context.Context, tournamentID uuid.UUID, entries []*domain.CrossGameLeaderboardEntry) {
+	expected, err := s.crossGameCheck.GetCrossGameLeaderboardFromMatches(ctx, tournamentID)
+	if err != nil {
+		s.log.LogError("Failed to check cross-game leaderboard", err,
+			zap.String("tournament_id", tournamentID.String()),
+		)
+		return
+	}
+
+	mismatched := crossGameMismatches(entries, expected)
+	if len(mismatched) > 0 {
+		s.log.Warn("Cross-game leaderboard differs from match history, rebuild team game stats",
+			zap.String("tournament_id", tournamentID.String()),
+			zap.Strings("team_ids", mismatched),
+		)
+	}
+}
+
+// crossGameMismatches возвращает команды, статистика которых в двух рейтингах различается
+func crossGameMismatches(actual, expected []*domain.CrossGameLeaderboardEntry) []string {
+	byTeam := make(map[string]*domain.CrossGameLeaderboardEntry, len(expected))
+	for _, entry := range expected {
+		byTeam[crossGameTeamKey(entry)] = entry
+	}
+
+	var mismatched []string
+	for _, entry := range actual {
+		key := crossGameTeamKey(entry)
+		want, ok := byTeam[key]
+		delete(byTeam, key)
+		if !ok || !sameCrossGameStats(entry, want) {
+			mismatched = append(mismatched, key)
+		}
+	}
+	for key := range byTeam {
+		mismatched = append(mismatched, key)
+	}
+	return mismatched
+}
+
+// crossGameTeamKey ключ команды в кросс-игровом рейтинге
+func crossGameTeamKey(entry *domain.CrossGameLeaderboardEntry) string {
+	if entry.TeamID == nil {
+		return ""
+	}
+	return entry.TeamID.String()
+}
+
+// sameCrossGameStats сравнивает статистику команды без учёта позиции в рейтинге:
+// при равных очках порядок строк двух запросов может отличаться
+func sameCrossGameStats(a, b *domain.CrossGameLeaderboardEntry) bool {
+	if a.TotalRating != b.TotalRating || a.TotalWins != b.TotalWins ||
+		a.TotalLosses != b.TotalLosses || a.TotalGames != b.TotalGames ||
+		len(a.GameRatings) != len(b.GameRatings) {
+		return false
+	}
+	for gameID, rating := range a.GameRatings {
+		if other, ok := b.GameRatings[gameID]; !ok || other != rating {
+			return false
+		}
+	}
+	return true
+}
+
 // RunAllMatches запускает все pending матчи турнира (для админа)
 // Если нет pending матчей, создаёт новый раунд round-robin матчей
 func (s *Service) RunAllMatches(ctx context.Context, tournamentID uuid.UUID) (int, error) {
@@ -686,6 +1723,10 @@ func (s *Service) RunAllMatches(ctx context.Context, tournamentID uuid.UUID) (in
 			return 0, errors.ErrConflict.WithMessage("tournament is not active")
 		}
 
+		if tournament.IsElimination() {
+			return 0, errors.ErrConflict.WithMessage("elimination tournament rounds are generated by the bracket")
+		}
+
 		// Получаем участников (только последние версии программ каждой команды)
 		participants, err := s.tournamentRepo.GetLatestParticipants(ctx, tournamentID)
 		if err != nil {
@@ -706,7 +1747,7 @@ func (s *Service) RunAllMatches(ctx context.Context, tournamentID uuid.UUID) (in
 		}
 
 		// Генерируем новый раунд матчей
-		matches, err = s.generateRoundRobinMatches(tournament, participants, roundNumber)
+		matches, err = s.generateRoundRobinMatches(tournament, participants, roundNumber, s.unrunnablePrograms(ctx, participants))
 		if err != nil {
 			return 0, fmt.Errorf("failed to generate matches: %w", err)
 		}
@@ -720,12 +1761,17 @@ func (s *Service) RunAllMatches(ctx context.Context, tournamentID uuid.UUID) (in
 			zap.String("tournament_id", tournamentID.String()),
 			zap.Int("round_number", roundNumber),
 			zap.Int("matches_count", len(matches)),
+			zap.Int("walkovers", countWalkovers(matches)),
 		)
 	}
 
 	// Добавляем все матчи в очередь
 	enqueued := 0
 	for _, match := range matches {
+		// Технические победы уже завершены и в очередь не попадают
+		if match.Status != domain.MatchPending {
+			continue
+		}
 		if err := s.queueManager.Enqueue(ctx, match); err != nil {
 			s.log.Error("Failed to enqueue match",
 				zap.Error(err),
@@ -745,8 +1791,96 @@ func (s *Service) RunAllMatches(ctx context.Context, tournamentID uuid.UUID) (in
 	return enqueued, nil
 }
 
-// RunGameMatches запускает матчи для конкретной игры в турнире
-func (s *Service) RunGameMatches(ctx context.Context, tournamentID uuid.UUID, gameType string) (int, error) {
+// ScheduleRound создаёт новый раунд round-robin матчей, которые начнут выполняться не раньше at
+// Матчи сразу попадают в очередь, но worker не извлечёт их до наступления ScheduledAt
+func (s *Service) ScheduleRound(ctx context.Context, tournamentID uuid.UUID, at time.Time) (int, error) {
+	if !at.After(time.Now()) {
+		return 0, errors.ErrValidation.WithMessage("scheduled time must be in the future")
+	}
+
+	tournament, err := s.GetByID(ctx, tournamentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tournament: %w", err)
+	}
+
+	if tournament.Status != domain.TournamentActive {
+		return 0, errors.ErrConflict.WithMessage("tournament is not active")
+	}
+
+	if tournament.IsElimination() {
+		return 0, errors.ErrConflict.WithMessage("elimination tournament rounds are generated by the bracket")
+	}
+
+	participants, err := s.tournamentRepo.GetLatestParticipants(ctx, tournamentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get participants: %w", err)
+	}
+
+	if len(participants) < 2 {
+		return 0, errors.ErrValidation.WithMessage("need at least 2 participants to schedule a round")
+	}
+
+	roundNumber, err := s.matchRepo.GetNextRoundNumber(ctx, tournamentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next round number: %w", err)
+	}
+
+	matches, err := s.generateRoundRobinMatches(tournament, participants, roundNumber, s.unrunnablePrograms(ctx, participants))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate matches: %w", err)
+	}
+
+	scheduledAt := at.UTC()
+	for _, match := range matches {
+		if match.Status != domain.MatchPending {
+			continue
+		}
+		match.ScheduledAt = &scheduledAt
+	}
+
+	if err := s.matchRepo.CreateBatch(ctx, matches); err != nil {
+		return 0, fmt.Errorf("failed to create matches: %w", err)
+	}
+
+	for _, match := range matches {
+		// Технические победы уже завершены и в очередь не попадают
+		if match.Status != domain.MatchPending {
+			continue
+		}
+		if err := s.queueManager.Enqueue(ctx, match); err != nil {
+			s.log.Error("Failed to enqueue scheduled match",
+				zap.Error(err),
+				zap.String("match_id", match.ID.String()),
+			)
+		}
+	}
+
+	s.log.Info("Scheduled round of matches",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.Int("round_number", roundNumber),
+		zap.Int("matches_count", len(matches)),
+		zap.Time("scheduled_at", scheduledAt),
+	)
+
+	s.broadcaster.Broadcast(tournamentID, "round_scheduled", map[string]interface{}{
+		"round_number":  roundNumber,
+		"matches_count": len(matches),
+		"scheduled_at":  scheduledAt,
+	})
+
+	return len(matches), nil
+}
+
+// RunGameMatches запускает матчи для конкретной игры в турнире.
+// Вне окна игры запуск отклоняется, если администратор не передал overrideWindow:
+// тогда матчи выполняются независимо от окна
+func (s *Service) RunGameMatches(ctx context.Context, tournamentID uuid.UUID, gameType string, overrideWindow bool) (int, error) {
+	if !overrideWindow {
+		if err := s.checkGameWindow(ctx, tournamentID, gameType); err != nil {
+			return 0, err
+		}
+	}
+
 	// Получаем pending матчи для конкретной игры
 	matches, err := s.matchRepo.GetPendingByTournamentAndGame(ctx, tournamentID, gameType)
 	if err != nil {
@@ -771,6 +1905,10 @@ func (s *Service) RunGameMatches(ctx context.Context, tournamentID uuid.UUID, ga
 			return 0, errors.ErrConflict.WithMessage("tournament is not active")
 		}
 
+		if tournament.IsElimination() {
+			return 0, errors.ErrConflict.WithMessage("elimination tournament rounds are generated by the bracket")
+		}
+
 		// Получаем участников (только последние версии программ каждой команды для этой игры)
 		participants, err := s.getLatestParticipantsByGame(ctx, tournamentID, gameType)
 		if err != nil {
@@ -791,7 +1929,7 @@ func (s *Service) RunGameMatches(ctx context.Context, tournamentID uuid.UUID, ga
 		}
 
 		// Генерируем матчи для этой игры с высоким приоритетом (ручной запуск)
-		matches, err = s.generateRoundRobinMatchesForGame(tournament, participants, gameType, roundNumber, domain.PriorityHigh)
+		matches, err = s.generateRoundRobinMatchesForGame(tournament, participants, gameType, roundNumber, domain.PriorityHigh, s.unrunnablePrograms(ctx, participants))
 		if err != nil {
 			return 0, fmt.Errorf("failed to generate matches: %w", err)
 		}
@@ -806,12 +1944,22 @@ func (s *Service) RunGameMatches(ctx context.Context, tournamentID uuid.UUID, ga
 			zap.String("game_type", gameType),
 			zap.Int("round_number", roundNumber),
 			zap.Int("matches_count", len(matches)),
+			zap.Int("walkovers", countWalkovers(matches)),
 		)
 	}
 
+	// Раунд отмечается до постановки в очередь: иначе worker может доиграть матчи
+	// и снять отметку раньше, чем она будет поставлена
+	s.markRoundActive(ctx, tournamentID, gameType, matches)
+
 	// Добавляем все матчи в очередь
 	enqueued := 0
 	for _, match := range matches {
+		// Технические победы уже завершены и в очередь не попадают
+		if match.Status != domain.MatchPending {
+			continue
+		}
+		match.WindowOverride = overrideWindow
 		if err := s.queueManager.Enqueue(ctx, match); err != nil {
 			s.log.Error("Failed to enqueue match",
 				zap.Error(err),
@@ -827,18 +1975,174 @@ func (s *Service) RunGameMatches(ctx context.Context, tournamentID uuid.UUID, ga
 		zap.String("game_type", gameType),
 		zap.Int("total_pending", len(matches)),
 		zap.Int("enqueued", enqueued),
+		zap.Bool("override_window", overrideWindow),
 	)
 
 	return enqueued, nil
 }
 
+// RegenerateRoundResult итог пересоздания раунда игры
+type RegenerateRoundResult struct {
+	GameType         string `json:"game_type"`
+	RoundNumber      int    `json:"round_number"`
+	MatchesDeleted   int64  `json:"matches_deleted"`
+	MatchesCancelled int64  `json:"matches_cancelled"`
+	MatchesCreated   int    `json:"matches_created"`
+	Enqueued         int    `json:"enqueued"`
+}
+
+// RegenerateGameRound отбрасывает несыгранные матчи текущего раунда игры и создаёт раунд заново
+// (например, после исправления ошибки в игре). Ожидающие матчи удаляются, выполняющиеся отменяются,
+// завершённые матчи и их результаты остаются. Выполняется под той же блокировкой,
+// что и создание матчей для новых программ игры
+func (s *Service) RegenerateGameRound(ctx context.Context, tournamentID, gameID uuid.UUID) (*RegenerateRoundResult, error) {
+	if s.gameRepo == nil {
+		return nil, errors.ErrServiceUnavailable.WithMessage("game repository is not configured")
+	}
+
+	var result *RegenerateRoundResult
+	lockKey := fmt.Sprintf("tournament:schedule:%s:%s", tournamentID.String(), gameID.String())
+	err := s.distributedLock.WithLock(ctx, lockKey, 30*time.Second, func(ctx context.Context) error {
+		tournament, err := s.tournamentRepo.GetByID(ctx, tournamentID)
+		if err != nil {
+			return err
+		}
+		if tournament.Status != domain.TournamentActive {
+			return errors.ErrConflict.WithMessage("tournament is not active")
+		}
+		if tournament.IsElimination() {
+			return errors.ErrConflict.WithMessage("elimination tournament rounds are generated by the bracket")
+		}
+
+		if _, err := s.gameRepo.GetTournamentGame(ctx, tournamentID, gameID); err != nil {
+			return err
+		}
+		game, err := s.gameRepo.GetByID(ctx, gameID)
+		if err != nil {
+			return err
+		}
+
+		// Участники проверяются до удаления матчей: раунд не должен пропасть без замены
+		participants, err := s.getLatestParticipantsByGame(ctx, tournamentID, game.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get participants: %w", err)
+		}
+		if len(participants) < 2 {
+			return errors.ErrValidation.WithMessage("need at least 2 participants with programs for this game")
+		}
+
+		deleted, cancelled, err := s.matchRepo.DiscardUnfinishedByGame(ctx, tournamentID, game.Name)
+		if err != nil {
+			return fmt.Errorf("failed to discard unfinished matches: %w", err)
+		}
+
+		roundNumber, err := s.matchRepo.GetNextRoundNumberByGame(ctx, tournamentID, game.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get next round number: %w", err)
+		}
+
+		matches, err := s.generateRoundRobinMatchesForGame(tournament, participants, game.Name, roundNumber, domain.PriorityHigh, s.unrunnablePrograms(ctx, participants))
+		if err != nil {
+			return fmt.Errorf("failed to generate matches: %w", err)
+		}
+		if err := s.matchRepo.CreateBatch(ctx, matches); err != nil {
+			return fmt.Errorf("failed to create matches: %w", err)
+		}
+
+		s.markRoundActive(ctx, tournamentID, game.Name, matches)
+
+		enqueued := 0
+		for _, match := range matches {
+			if match.Status != domain.MatchPending {
+				continue
+			}
+			if err := s.queueManager.Enqueue(ctx, match); err != nil {
+				s.log.Error("Failed to enqueue match",
+					zap.Error(err),
+					zap.String("match_id", match.ID.String()),
+				)
+				continue
+			}
+			enqueued++
+		}
+
+		result = &RegenerateRoundResult{
+			GameType:         game.Name,
+			RoundNumber:      roundNumber,
+			MatchesDeleted:   deleted,
+			MatchesCancelled: cancelled,
+			MatchesCreated:   len(matches),
+			Enqueued:         enqueued,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Game round regenerated",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.String("game_type", result.GameType),
+		zap.Int("round_number", result.RoundNumber),
+		zap.Int64("matches_deleted", result.MatchesDeleted),
+		zap.Int64("matches_cancelled", result.MatchesCancelled),
+		zap.Int("matches_created", result.MatchesCreated),
+	)
+
+	return result, nil
+}
+
+// checkGameWindow возвращает конфликт, если окно игры задано и сейчас закрыто
+func (s *Service) checkGameWindow(ctx context.Context, tournamentID uuid.UUID, gameType string) error {
+	if s.gameRepo == nil {
+		return nil
+	}
+
+	tg, err := s.gameRepo.GetGameWindow(ctx, tournamentID, gameType)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get game window: %w", err)
+	}
+
+	if !tg.WindowOpen(time.Now()) {
+		return errors.ErrConflict.WithMessage(fmt.Sprintf(
+			"game window is closed (%s - %s), pass override_window to run matches anyway",
+			tg.WindowStart.UTC().Format(time.RFC3339), tg.WindowEnd.UTC().Format(time.RFC3339)))
+	}
+
+	return nil
+}
+
+// markRoundActive отмечает идущий раунд игры, если среди матчей есть несыгранные.
+// Отметка влияет только на блокировку загрузок, поэтому ошибка логируется
+func (s *Service) markRoundActive(ctx context.Context, tournamentID uuid.UUID, gameType string, matches []*domain.Match) {
+	if s.gameRepo == nil {
+		return
+	}
+	for _, match := range matches {
+		if match.Status != domain.MatchPending {
+			continue
+		}
+		if err := s.gameRepo.MarkRoundActive(ctx, tournamentID, gameType); err != nil {
+			s.log.LogError("Failed to mark game round active", err,
+				zap.String("tournament_id", tournamentID.String()),
+				zap.String("game_type", gameType),
+			)
+		}
+		return
+	}
+}
+
 // getLatestParticipantsByGame получает последние версии программ участников для конкретной игры
 func (s *Service) getLatestParticipantsByGame(ctx context.Context, tournamentID uuid.UUID, gameType string) ([]*domain.TournamentParticipant, error) {
 	return s.tournamentRepo.GetLatestParticipantsByGame(ctx, tournamentID, gameType)
 }
 
 // generateRoundRobinMatchesForGame генерирует матчи для конкретной игры
-func (s *Service) generateRoundRobinMatchesForGame(tournament *domain.Tournament, participants []*domain.TournamentParticipant, gameType string, roundNumber int, priority domain.MatchPriority) ([]*domain.Match, error) {
+// Пары с программой, не прошедшей проверку, засчитываются технической победой соперника
+func (s *Service) generateRoundRobinMatchesForGame(tournament *domain.Tournament, participants []*domain.TournamentParticipant, gameType string, roundNumber int, priority domain.MatchPriority, unrunnable map[uuid.UUID]bool) ([]*domain.Match, error) {
 	var matches []*domain.Match
 	now := time.Now()
 
@@ -857,7 +2161,8 @@ func (s *Service) generateRoundRobinMatchesForGame(tournament *domain.Tournament
 				Program2ID:   participants[j].ProgramID,
 				GameType:     gameType,
 				Status:       domain.MatchPending,
-				Priority:     priority,
+				Priority:     tournament.MatchPriority(priority),
+				IsPractice:   tournament.IsPractice,
 				RoundNumber:  roundNumber,
 				CreatedAt:    now,
 			}
@@ -866,6 +2171,10 @@ func (s *Service) generateRoundRobinMatchesForGame(tournament *domain.Tournament
 				return nil, fmt.Errorf("invalid match generated: %w", err)
 			}
 
+			if !applyWalkover(match, unrunnable, now) {
+				continue
+			}
+
 			matches = append(matches, match)
 		}
 	}
@@ -873,6 +2182,61 @@ func (s *Service) generateRoundRobinMatchesForGame(tournament *domain.Tournament
 	return matches, nil
 }
 
+// applyWalkover засчитывает матч победой соперника, если одна из программ не запускается.
+// Возвращает false, если не запускаются обе программы: такой матч не создаётся
+func applyWalkover(match *domain.Match, unrunnable map[uuid.UUID]bool, now time.Time) bool {
+	broken1, broken2 := unrunnable[match.Program1ID], unrunnable[match.Program2ID]
+	switch {
+	case broken1 && broken2:
+		return false
+	case broken1:
+		match.SetWalkover(2, now)
+	case broken2:
+		match.SetWalkover(1, now)
+	}
+	return true
+}
+
+// unrunnablePrograms возвращает программы участников с ошибкой проверки при загрузке
+// Без ProgramLookup или при ошибке чтения все программы считаются рабочими
+func (s *Service) unrunnablePrograms(ctx context.Context, participants []*domain.TournamentParticipant) map[uuid.UUID]bool {
+	if s.programLookup == nil {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(participants))
+	for _, p := range participants {
+		ids = append(ids, p.ProgramID)
+	}
+
+	programs, err := s.programLookup.GetByIDs(ctx, ids)
+	if err != nil {
+		s.log.Warn("Failed to check participant programs, scheduling all matches",
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	unrunnable := make(map[uuid.UUID]bool)
+	for _, program := range programs {
+		if !program.IsRunnable() {
+			unrunnable[program.ID] = true
+		}
+	}
+	return unrunnable
+}
+
+// countWalkovers считает матчи, засчитанные без игры
+func countWalkovers(matches []*domain.Match) int {
+	count := 0
+	for _, match := range matches {
+		if match.IsWalkover() {
+			count++
+		}
+	}
+	return count
+}
+
 // RetryFailedMatches сбрасывает failed матчи в pending и ставит их в очередь
 func (s *Service) RetryFailedMatches(ctx context.Context, tournamentID uuid.UUID) (int, error) {
 	// Сбрасываем все failed матчи в pending
@@ -911,3 +2275,35 @@ func (s *Service) RetryFailedMatches(ctx context.Context, tournamentID uuid.UUID
 
 	return enqueued, nil
 }
+
+// UpdateRequest - частичное изменение настроек турнира: пустые поля не меняются
+type UpdateRequest struct {
+	Visibility *domain.TournamentVisibility `json:"visibility,omitempty"`
+}
+
+// Update применяет изменения настроек турнира
+func (s *Service) Update(ctx context.Context, tournamentID uuid.UUID, req *UpdateRequest) (*domain.Tournament, error) {
+	tournament, err := s.tournamentRepo.GetByID(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Visibility != nil && *req.Visibility != tournament.Visibility {
+		tournament.Visibility = *req.Visibility
+		if err := tournament.Validate(); err != nil {
+			return nil, errors.ErrValidation.WithError(err)
+		}
+
+		if err := s.tournamentRepo.UpdateVisibility(ctx, tournamentID, tournament.Visibility); err != nil {
+			return nil, fmt.Errorf("failed to update tournament visibility: %w", err)
+		}
+
+		s.log.Info("Tournament visibility changed",
+			zap.String("tournament_id", tournamentID.String()),
+			zap.String("visibility", string(tournament.Visibility)),
+		)
+		_ = s.tournamentCache.Invalidate(ctx, tournamentID)
+	}
+
+	return tournament, nil
+}
@@ -2,6 +2,8 @@ package tournament
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -11,9 +13,11 @@ import (
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock implementations
@@ -26,6 +30,11 @@ func (m *MockTournamentRepository) Create(ctx context.Context, tournament *domai
 	return args.Error(0)
 }
 
+func (m *MockTournamentRepository) CreateWithGames(ctx context.Context, tournament *domain.Tournament, gameIDs []uuid.UUID) error {
+	args := m.Called(ctx, tournament, gameIDs)
+	return args.Error(0)
+}
+
 func (m *MockTournamentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -39,11 +48,37 @@ func (m *MockTournamentRepository) List(ctx context.Context, filter domain.Tourn
 	return args.Get(0).([]*domain.Tournament), args.Error(1)
 }
 
+func (m *MockTournamentRepository) CountWithFilter(ctx context.Context, filter domain.TournamentFilter) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTournamentRepository) GetActiveTournamentIDs(ctx context.Context) ([]uuid.UUID, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockTournamentRepository) ListWithCursor(ctx context.Context, filter domain.TournamentFilter, pageReq *pagination.PageRequest) ([]*domain.Tournament, bool, error) {
+	args := m.Called(ctx, filter, pageReq)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Tournament), args.Bool(1), args.Error(2)
+}
+
 func (m *MockTournamentRepository) Update(ctx context.Context, tournament *domain.Tournament) error {
 	args := m.Called(ctx, tournament)
 	return args.Error(0)
 }
 
+func (m *MockTournamentRepository) UpdateVisibility(ctx context.Context, id uuid.UUID, visibility domain.TournamentVisibility) error {
+	args := m.Called(ctx, id, visibility)
+	return args.Error(0)
+}
+
 func (m *MockTournamentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.TournamentStatus) error {
 	args := m.Called(ctx, id, status)
 	return args.Error(0)
@@ -54,12 +89,17 @@ func (m *MockTournamentRepository) GetParticipantsCount(ctx context.Context, tou
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockTournamentRepository) GetParticipants(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TournamentParticipant, error) {
-	args := m.Called(ctx, tournamentID)
+func (m *MockTournamentRepository) GetParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) ([]*domain.ParticipantEntry, error) {
+	args := m.Called(ctx, tournamentID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*domain.TournamentParticipant), args.Error(1)
+	return args.Get(0).([]*domain.ParticipantEntry), args.Error(1)
+}
+
+func (m *MockTournamentRepository) CountParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) (int, error) {
+	args := m.Called(ctx, tournamentID, filter)
+	return args.Int(0), args.Error(1)
 }
 
 func (m *MockTournamentRepository) AddParticipant(ctx context.Context, participant *domain.TournamentParticipant) error {
@@ -75,11 +115,29 @@ func (m *MockTournamentRepository) GetLeaderboard(ctx context.Context, tournamen
 	return args.Get(0).([]*domain.LeaderboardEntry), args.Error(1)
 }
 
+func (m *MockTournamentRepository) GetReferenceLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.LeaderboardEntry, error) {
+	args := m.Called(ctx, tournamentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LeaderboardEntry), args.Error(1)
+}
+
 func (m *MockTournamentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockTournamentRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTournamentRepository) Purge(ctx context.Context, id uuid.UUID, deletedBefore time.Time) error {
+	args := m.Called(ctx, id, deletedBefore)
+	return args.Error(0)
+}
+
 func (m *MockTournamentRepository) GetCrossGameLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error) {
 	args := m.Called(ctx, tournamentID)
 	if args.Get(0) == nil {
@@ -88,6 +146,14 @@ func (m *MockTournamentRepository) GetCrossGameLeaderboard(ctx context.Context,
 	return args.Get(0).([]*domain.CrossGameLeaderboardEntry), args.Error(1)
 }
 
+func (m *MockTournamentRepository) GetTeamRatings(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TeamRating, error) {
+	args := m.Called(ctx, tournamentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.TeamRating), args.Error(1)
+}
+
 func (m *MockTournamentRepository) GetLatestParticipants(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TournamentParticipant, error) {
 	args := m.Called(ctx, tournamentID)
 	if args.Get(0) == nil {
@@ -112,6 +178,36 @@ func (m *MockTournamentRepository) GetLatestParticipantsByGame(ctx context.Conte
 	return args.Get(0).([]*domain.TournamentParticipant), args.Error(1)
 }
 
+func (m *MockTournamentRepository) RemoveParticipant(ctx context.Context, tournamentID, programID uuid.UUID) error {
+	args := m.Called(ctx, tournamentID, programID)
+	return args.Error(0)
+}
+
+func (m *MockTournamentRepository) AddBan(ctx context.Context, ban *domain.TournamentBan) error {
+	args := m.Called(ctx, ban)
+	return args.Error(0)
+}
+
+func (m *MockTournamentRepository) IsBanned(ctx context.Context, tournamentID, programID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, tournamentID, programID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTournamentRepository) RecordAutoStartFailure(ctx context.Context, id uuid.UUID, reason string) (int, error) {
+	args := m.Called(ctx, id, reason)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTournamentRepository) RecordAutoStartDelay(ctx context.Context, id uuid.UUID, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+func (m *MockTournamentRepository) ClearAutoStartFailure(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 type MockMatchRepository struct {
 	mock.Mock
 }
@@ -134,6 +230,14 @@ func (m *MockMatchRepository) GetByTournamentID(ctx context.Context, tournamentI
 	return args.Get(0).([]*domain.Match), args.Error(1)
 }
 
+func (m *MockMatchRepository) ListWithCursor(ctx context.Context, filter domain.MatchFilter, pageReq *pagination.PageRequest) ([]*domain.Match, bool, error) {
+	args := m.Called(ctx, filter, pageReq)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Match), args.Bool(1), args.Error(2)
+}
+
 func (m *MockMatchRepository) GetPendingByTournamentID(ctx context.Context, tournamentID uuid.UUID) ([]*domain.Match, error) {
 	args := m.Called(ctx, tournamentID)
 	if args.Get(0) == nil {
@@ -163,6 +267,22 @@ func (m *MockMatchRepository) GetMatchesByRounds(ctx context.Context, tournament
 	return args.Get(0).([]*domain.MatchRound), args.Error(1)
 }
 
+func (m *MockMatchRepository) GetByRound(ctx context.Context, tournamentID uuid.UUID, roundNumber, limit, offset int) ([]*domain.Match, error) {
+	args := m.Called(ctx, tournamentID, roundNumber, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Match), args.Error(1)
+}
+
+func (m *MockMatchRepository) GetRoundStats(ctx context.Context, tournamentID uuid.UUID, roundNumber int) (*domain.TournamentMatchStats, error) {
+	args := m.Called(ctx, tournamentID, roundNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TournamentMatchStats), args.Error(1)
+}
+
 func (m *MockMatchRepository) GetNextRoundNumber(ctx context.Context, tournamentID uuid.UUID) (int, error) {
 	args := m.Called(ctx, tournamentID)
 	return args.Int(0), args.Error(1)
@@ -181,6 +301,42 @@ func (m *MockMatchRepository) GetPendingByTournamentAndGame(ctx context.Context,
 	return args.Get(0).([]*domain.Match), args.Error(1)
 }
 
+func (m *MockMatchRepository) CancelPendingByProgram(ctx context.Context, tournamentID, programID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, tournamentID, programID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMatchRepository) CancelUnfinishedByTournament(ctx context.Context, tournamentID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, tournamentID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMatchRepository) DiscardUnfinishedByGame(ctx context.Context, tournamentID uuid.UUID, gameType string) (int64, int64, error) {
+	args := m.Called(ctx, tournamentID, gameType)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockMatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Match, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Match), args.Error(1)
+}
+
+func (m *MockMatchRepository) AdjustResult(ctx context.Context, adjustment *domain.MatchResultAdjustment) error {
+	args := m.Called(ctx, adjustment)
+	return args.Error(0)
+}
+
+func (m *MockMatchRepository) GetResultAdjustments(ctx context.Context, matchID uuid.UUID) ([]*domain.MatchResultAdjustment, error) {
+	args := m.Called(ctx, matchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MatchResultAdjustment), args.Error(1)
+}
+
 type MockQueueManager struct {
 	mock.Mock
 }
@@ -216,6 +372,22 @@ type MockGameRepository struct {
 	mock.Mock
 }
 
+func (m *MockGameRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Game, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Game), args.Error(1)
+}
+
+func (m *MockGameRepository) GetTournamentGame(ctx context.Context, tournamentID, gameID uuid.UUID) (*domain.TournamentGame, error) {
+	args := m.Called(ctx, tournamentID, gameID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TournamentGame), args.Error(1)
+}
+
 func (m *MockGameRepository) GetTournamentGames(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TournamentGame, error) {
 	args := m.Called(ctx, tournamentID)
 	if args.Get(0) == nil {
@@ -229,6 +401,24 @@ func (m *MockGameRepository) SetActiveGame(ctx context.Context, tournamentID, ga
 	return args.Error(0)
 }
 
+func (m *MockGameRepository) AddToTournament(ctx context.Context, tournamentID, gameID uuid.UUID) error {
+	args := m.Called(ctx, tournamentID, gameID)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) MarkRoundActive(ctx context.Context, tournamentID uuid.UUID, gameType string) error {
+	args := m.Called(ctx, tournamentID, gameType)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) GetGameWindow(ctx context.Context, tournamentID uuid.UUID, gameType string) (*domain.TournamentGame, error) {
+	args := m.Called(ctx, tournamentID, gameType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TournamentGame), args.Error(1)
+}
+
 // TestConcurrentJoin tests that concurrent join operations don't exceed max participants
 func TestConcurrentJoin(t *testing.T) {
 	t.Run("prevents exceeding max participants with distributed lock", func(t *testing.T) {
@@ -254,6 +444,8 @@ func TestConcurrentJoin(t *testing.T) {
 		// Mock tournament retrieval
 		tournamentRepo.On("GetByID", mock.Anything, tournamentID).Return(tournament, nil)
 
+		tournamentRepo.On("IsBanned", mock.Anything, tournamentID, mock.Anything).Return(false, nil)
+
 		// Mock participants count - uses atomic counter
 		tournamentRepo.On("GetParticipantsCount", mock.Anything, tournamentID).Return(
 			func(ctx context.Context, id uuid.UUID) int {
@@ -441,6 +633,70 @@ func TestConcurrentStart(t *testing.T) {
 	})
 }
 
+// stubBracket seeds brackets with a fixed outcome
+type stubBracket struct {
+	created int
+	err     error
+	calls   int
+}
+
+func (b *stubBracket) Seed(_ context.Context, _ *domain.Tournament) (int, error) {
+	b.calls++
+	return b.created, b.err
+}
+
+func TestStartElimination(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	newTournament := func() *domain.Tournament {
+		return &domain.Tournament{
+			ID:       uuid.New(),
+			Name:     "Cup",
+			GameType: "chess",
+			Status:   domain.TournamentPending,
+			Metadata: map[string]interface{}{domain.MetaFormat: string(domain.FormatElimination)},
+		}
+	}
+
+	t.Run("unavailable without bracket", func(t *testing.T) {
+		tournament := newTournament()
+		tournamentRepo := new(MockTournamentRepository)
+		tournamentRepo.On("GetByID", mock.Anything, tournament.ID).Return(tournament, nil)
+		lock := new(MockDistributedLock)
+		lock.On("WithLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		service := NewService(tournamentRepo, nil, nil, nil, nil, nil, nil, lock, log)
+		err := service.Start(context.Background(), tournament.ID)
+
+		appErr := errors.GetAppError(err)
+		require.NotNil(t, appErr)
+		assert.Equal(t, errors.ErrServiceUnavailable.Code, appErr.Code)
+		tournamentRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("seeding failure reverts to pending", func(t *testing.T) {
+		tournament := newTournament()
+		tournamentRepo := new(MockTournamentRepository)
+		tournamentRepo.On("GetByID", mock.Anything, tournament.ID).Return(tournament, nil)
+		tournamentRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		tournamentRepo.On("UpdateStatus", mock.Anything, tournament.ID, domain.TournamentPending).Return(nil).Once()
+		lock := new(MockDistributedLock)
+		lock.On("WithLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		bracket := &stubBracket{err: errors.ErrValidation.WithMessage("elimination tournament requires at least 2 participants")}
+		service := NewService(tournamentRepo, nil, nil, nil, nil, nil, nil, lock, log)
+		service.SetBracket(bracket)
+
+		err := service.Start(context.Background(), tournament.ID)
+
+		appErr := errors.GetAppError(err)
+		require.NotNil(t, appErr)
+		assert.Equal(t, errors.ErrValidation.Code, appErr.Code)
+		assert.Equal(t, 1, bracket.calls)
+		tournamentRepo.AssertExpectations(t)
+	})
+}
+
 // TestRaceConditionInJoin tests for race conditions without distributed lock
 func TestRaceConditionInJoin(t *testing.T) {
 	t.Run("detects race condition when lock fails", func(t *testing.T) {
@@ -497,10 +753,1335 @@ func TestRaceConditionInJoin(t *testing.T) {
 	})
 }
 
-// setupTestRedisCache creates a test Redis cache
-// For integration tests, use real Redis or testcontainers
-func setupTestRedisCache(t *testing.T) *cache.Cache {
-	// Skip if no Redis available
-	t.Skip("Implement setupTestRedisCache with real Redis or testcontainers")
-	return nil
+// TestKickParticipant tests removing a participant from a tournament
+func TestKickParticipant(t *testing.T) {
+	t.Run("returns not found when program is not a participant", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		distributedLock := new(MockDistributedLock)
+
+		tournamentID := uuid.New()
+		programID := uuid.New()
+
+		distributedLock.On("WithLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		tournamentRepo.On("RemoveParticipant", mock.Anything, tournamentID, programID).
+			Return(errors.ErrNotFound.WithMessage("participant not found"))
+
+		log, _ := logger.New("error", "json")
+		service := NewService(tournamentRepo, matchRepo, nil, nil, nil, nil, nil, distributedLock, log)
+
+		err := service.KickParticipant(context.Background(), tournamentID, programID, "crashes opponents")
+		assert.True(t, errors.IsNotFound(err))
+		matchRepo.AssertNotCalled(t, "CancelPendingByProgram", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("cancels pending matches of kicked program", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		broadcaster := new(MockBroadcaster)
+		distributedLock := new(MockDistributedLock)
+
+		tournamentID := uuid.New()
+		programID := uuid.New()
+
+		distributedLock.On("WithLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		tournamentRepo.On("RemoveParticipant", mock.Anything, tournamentID, programID).Return(nil)
+		matchRepo.On("CancelPendingByProgram", mock.Anything, tournamentID, programID).Return(int64(4), nil)
+		broadcaster.On("Broadcast", tournamentID, "participant_removed", mock.Anything).Return()
+
+		testCache := setupTestRedisCache(t)
+		defer testCache.Close()
+
+		log, _ := logger.New("error", "json")
+		service := NewService(
+			tournamentRepo,
+			matchRepo,
+			nil,
+			nil,
+			cache.NewTournamentCache(testCache),
+			cache.NewLeaderboardCache(testCache),
+			broadcaster,
+			distributedLock,
+			log,
+		)
+
+		err := service.KickParticipant(context.Background(), tournamentID, programID, "crashes opponents")
+		assert.NoError(t, err)
+		matchRepo.AssertExpectations(t)
+		broadcaster.AssertExpectations(t)
+	})
+}
+
+// TestBanProgram tests banning a program from a tournament
+func TestBanProgram(t *testing.T) {
+	t.Run("does not remove participant when ban fails", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		distributedLock := new(MockDistributedLock)
+
+		tournamentID := uuid.New()
+		programID := uuid.New()
+
+		distributedLock.On("WithLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		tournamentRepo.On("AddBan", mock.Anything, mock.AnythingOfType("*domain.TournamentBan")).
+			Return(errors.ErrInternal)
+
+		log, _ := logger.New("error", "json")
+		service := NewService(tournamentRepo, matchRepo, nil, nil, nil, nil, nil, distributedLock, log)
+
+		err := service.BanProgram(context.Background(), tournamentID, programID, "cheating")
+		assert.Error(t, err)
+		tournamentRepo.AssertNotCalled(t, "RemoveParticipant", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("bans program that is not a participant", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		broadcaster := new(MockBroadcaster)
+		distributedLock := new(MockDistributedLock)
+
+		tournamentID := uuid.New()
+		programID := uuid.New()
+
+		distributedLock.On("WithLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		tournamentRepo.On("AddBan", mock.Anything, mock.MatchedBy(func(b *domain.TournamentBan) bool {
+			return b.TournamentID == tournamentID && b.ProgramID == programID && b.Reason == "cheating"
+		})).Return(nil)
+		tournamentRepo.On("RemoveParticipant", mock.Anything, tournamentID, programID).
+			Return(errors.ErrNotFound.WithMessage("participant not found"))
+		matchRepo.On("CancelPendingByProgram", mock.Anything, tournamentID, programID).Return(int64(0), nil)
+		broadcaster.On("Broadcast", tournamentID, "participant_removed", mock.Anything).Return()
+
+		testCache := setupTestRedisCache(t)
+		defer testCache.Close()
+
+		log, _ := logger.New("error", "json")
+		service := NewService(
+			tournamentRepo,
+			matchRepo,
+			nil,
+			nil,
+			cache.NewTournamentCache(testCache),
+			cache.NewLeaderboardCache(testCache),
+			broadcaster,
+			distributedLock,
+			log,
+		)
+
+		err := service.BanProgram(context.Background(), tournamentID, programID, "cheating")
+		assert.NoError(t, err)
+		tournamentRepo.AssertExpectations(t)
+	})
+}
+
+// TestJoinBannedProgram tests that banned programs cannot re-join
+func TestJoinBannedProgram(t *testing.T) {
+	tournamentRepo := new(MockTournamentRepository)
+	distributedLock := new(MockDistributedLock)
+
+	tournamentID := uuid.New()
+	programID := uuid.New()
+	tournament := &domain.Tournament{
+		ID:       tournamentID,
+		Name:     "Test Tournament",
+		GameType: "chess",
+		Status:   domain.TournamentPending,
+	}
+
+	distributedLock.On("WithLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	tournamentRepo.On("GetByID", mock.Anything, tournamentID).Return(tournament, nil)
+	tournamentRepo.On("IsBanned", mock.Anything, tournamentID, programID).Return(true, nil)
+
+	testCache := setupTestRedisCache(t)
+	defer testCache.Close()
+
+	log, _ := logger.New("error", "json")
+	service := NewService(
+		tournamentRepo,
+		new(MockMatchRepository),
+		nil,
+		nil,
+		cache.NewTournamentCache(testCache),
+		cache.NewLeaderboardCache(testCache),
+		nil,
+		distributedLock,
+		log,
+	)
+
+	err := service.Join(context.Background(), &JoinRequest{TournamentID: tournamentID, ProgramID: programID})
+	appErr := errors.GetAppError(err)
+	if assert.NotNil(t, appErr) {
+		assert.Equal(t, errors.ErrForbidden.Code, appErr.Code)
+	}
+	tournamentRepo.AssertNotCalled(t, "AddParticipant", mock.Anything, mock.Anything)
+}
+
+// TestScheduleRound tests scheduling a round of matches for a future time
+func TestScheduleRound(t *testing.T) {
+	t.Run("rejects time in the past", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+
+		log, _ := logger.New("error", "json")
+		service := NewService(tournamentRepo, matchRepo, nil, nil, nil, nil, nil, nil, log)
+
+		_, err := service.ScheduleRound(context.Background(), uuid.New(), time.Now().Add(-time.Minute))
+		appErr := errors.GetAppError(err)
+		if assert.NotNil(t, appErr) {
+			assert.Equal(t, errors.ErrValidation.Code, appErr.Code)
+		}
+		matchRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("creates and enqueues matches with scheduled time", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		queueManager := new(MockQueueManager)
+		broadcaster := new(MockBroadcaster)
+
+		tournamentID := uuid.New()
+		at := time.Now().Add(time.Hour)
+		tournament := &domain.Tournament{
+			ID:       tournamentID,
+			Name:     "Scheduled",
+			GameType: "chess",
+			Status:   domain.TournamentActive,
+		}
+		participants := []*domain.TournamentParticipant{
+			{TournamentID: tournamentID, ProgramID: uuid.New()},
+			{TournamentID: tournamentID, ProgramID: uuid.New()},
+		}
+
+		tournamentRepo.On("GetByID", mock.Anything, tournamentID).Return(tournament, nil)
+		tournamentRepo.On("GetLatestParticipants", mock.Anything, tournamentID).Return(participants, nil)
+		matchRepo.On("GetNextRoundNumber", mock.Anything, tournamentID).Return(3, nil)
+		matchRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(matches []*domain.Match) bool {
+			for _, m := range matches {
+				if m.ScheduledAt == nil || !m.ScheduledAt.Equal(at) || m.RoundNumber != 3 {
+					return false
+				}
+			}
+			return len(matches) == 2
+		})).Return(nil)
+		queueManager.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Match")).Return(nil).Times(2)
+		broadcaster.On("Broadcast", tournamentID, "round_scheduled", mock.Anything).Return()
+
+		testCache := setupTestRedisCache(t)
+		defer testCache.Close()
+
+		log, _ := logger.New("error", "json")
+		service := NewService(
+			tournamentRepo,
+			matchRepo,
+			queueManager,
+			nil,
+			cache.NewTournamentCache(testCache),
+			cache.NewLeaderboardCache(testCache),
+			broadcaster,
+			nil,
+			log,
+		)
+
+		count, err := service.ScheduleRound(context.Background(), tournamentID, at)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		matchRepo.AssertExpectations(t)
+		queueManager.AssertExpectations(t)
+	})
+}
+
+// TestCloneTournament tests duplicating tournament settings and games
+func TestCloneTournament(t *testing.T) {
+	newSource := func() *domain.Tournament {
+		maxParticipants := 32
+		creatorID := uuid.New()
+		return &domain.Tournament{
+			ID:              uuid.New(),
+			Code:            "SRC123",
+			Name:            "Weekly Cup",
+			GameType:        "chess",
+			Status:          domain.TournamentCompleted,
+			MaxParticipants: &maxParticipants,
+			MaxTeamSize:     3,
+			IsPermanent:     true,
+			Metadata:        map[string]interface{}{"upload_cooldown_seconds": float64(60)},
+			CreatorID:       &creatorID,
+		}
+	}
+
+	t.Run("copies settings and all games", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		gameRepo := new(MockGameRepository)
+
+		source := newSource()
+		sourceCreator := *source.CreatorID
+		games := []*domain.TournamentGame{
+			{TournamentID: source.ID, GameID: uuid.New(), IsActive: true, CurrentRound: 4},
+			{TournamentID: source.ID, GameID: uuid.New()},
+			{TournamentID: source.ID, GameID: uuid.New(), RoundCompleted: true},
+		}
+
+		var clone *domain.Tournament
+		copied := make(map[uuid.UUID]bool)
+		tournamentRepo.On("GetByID", mock.Anything, source.ID).Return(source, nil)
+		tournamentRepo.On("CreateWithGames", mock.Anything, mock.AnythingOfType("*domain.Tournament"), mock.Anything).
+			Run(func(args mock.Arguments) {
+				clone = args.Get(1).(*domain.Tournament)
+				for _, id := range args.Get(2).([]uuid.UUID) {
+					copied[id] = true
+				}
+			}).
+			Return(nil)
+		gameRepo.On("GetTournamentGames", mock.Anything, source.ID).Return(games, nil)
+
+		log, _ := logger.New("error", "json")
+		service := NewService(tournamentRepo, new(MockMatchRepository), nil, gameRepo, nil, nil, nil, nil, log)
+
+		newOwner := uuid.New()
+		result, err := service.CloneTournament(context.Background(), source.ID, CreateRequest{
+			Name:      "Weekly Cup #2",
+			CreatorID: &newOwner,
+		})
+		assert.NoError(t, err)
+		assert.Same(t, clone, result)
+
+		assert.NotEqual(t, source.ID, result.ID)
+		assert.NotEqual(t, source.Code, result.Code)
+		assert.Equal(t, "Weekly Cup #2", result.Name)
+		assert.Equal(t, domain.TournamentPending, result.Status)
+		assert.Equal(t, source.GameType, result.GameType)
+		assert.Equal(t, 32, *result.MaxParticipants)
+		assert.Equal(t, 3, result.MaxTeamSize)
+		assert.True(t, result.IsPermanent)
+		assert.Equal(t, source.Metadata, result.Metadata)
+		assert.Equal(t, newOwner, *result.CreatorID)
+
+		assert.Len(t, copied, len(games))
+		for _, g := range games {
+			assert.True(t, copied[g.GameID], "game %s must be copied", g.GameID)
+		}
+
+		// Source tournament is untouched
+		result.Metadata["upload_cooldown_seconds"] = float64(0)
+		assert.Equal(t, float64(60), source.Metadata["upload_cooldown_seconds"])
+		assert.Equal(t, domain.TournamentCompleted, source.Status)
+		assert.Equal(t, "Weekly Cup", source.Name)
+		assert.Equal(t, sourceCreator, *source.CreatorID)
+		tournamentRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		tournamentRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+		gameRepo.AssertNotCalled(t, "AddToTournament", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("marks name as copy and keeps creator without overrides", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		gameRepo := new(MockGameRepository)
+
+		source := newSource()
+		tournamentRepo.On("GetByID", mock.Anything, source.ID).Return(source, nil)
+		tournamentRepo.On("CreateWithGames", mock.Anything, mock.AnythingOfType("*domain.Tournament"), []uuid.UUID(nil)).Return(nil)
+		gameRepo.On("GetTournamentGames", mock.Anything, source.ID).Return([]*domain.TournamentGame{}, nil)
+
+		log, _ := logger.New("error", "json")
+		service := NewService(tournamentRepo, new(MockMatchRepository), nil, gameRepo, nil, nil, nil, nil, log)
+
+		result, err := service.CloneTournament(context.Background(), source.ID, CreateRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "Weekly Cup (copy)", result.Name)
+		assert.Equal(t, *source.CreatorID, *result.CreatorID)
+	})
+
+	t.Run("returns error when transactional create fails", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		gameRepo := new(MockGameRepository)
+
+		source := newSource()
+		tournamentRepo.On("GetByID", mock.Anything, source.ID).Return(source, nil)
+		tournamentRepo.On("CreateWithGames", mock.Anything, mock.Anything, mock.Anything).Return(errors.ErrInternal)
+		gameRepo.On("GetTournamentGames", mock.Anything, source.ID).
+			Return([]*domain.TournamentGame{{TournamentID: source.ID, GameID: uuid.New()}}, nil)
+
+		log, _ := logger.New("error", "json")
+		service := NewService(tournamentRepo, new(MockMatchRepository), nil, gameRepo, nil, nil, nil, nil, log)
+
+		_, err := service.CloneTournament(context.Background(), source.ID, CreateRequest{})
+		assert.Error(t, err)
+		// The repository rolls back itself, no compensating delete is needed
+		tournamentRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		tournamentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns not found for unknown source", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		sourceID := uuid.New()
+		tournamentRepo.On("GetByID", mock.Anything, sourceID).Return(nil, errors.ErrNotFound)
+
+		log, _ := logger.New("error", "json")
+		service := NewService(tournamentRepo, new(MockMatchRepository), nil, nil, nil, nil, nil, nil, log)
+
+		_, err := service.CloneTournament(context.Background(), sourceID, CreateRequest{})
+		assert.True(t, errors.IsNotFound(err))
+		tournamentRepo.AssertNotCalled(t, "CreateWithGames", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+type stubRefresher struct {
+	err   error
+	calls int
+}
+
+func (r *stubRefresher) Refresh(ctx context.Context) error {
+	r.calls++
+	return r.err
+}
+
+func TestForceComplete(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	t.Run("requires an active tournament", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		tournamentID := uuid.New()
+		tournamentRepo.On("GetByID", mock.Anything, tournamentID).
+			Return(&domain.Tournament{ID: tournamentID, Status: domain.TournamentCompleted}, nil)
+
+		service := NewService(tournamentRepo, matchRepo, nil, nil, nil, nil, nil, nil, log)
+		_, err := service.ForceComplete(context.Background(), tournamentID, true)
+
+		assert.True(t, errors.IsConflict(err))
+		matchRepo.AssertNotCalled(t, "CancelUnfinishedByTournament", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed refresh keeps the tournament active", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		tournamentID := uuid.New()
+		tournamentRepo.On("GetByID", mock.Anything, tournamentID).
+			Return(&domain.Tournament{ID: tournamentID, Status: domain.TournamentActive}, nil)
+		matchRepo.On("CancelUnfinishedByTournament", mock.Anything, tournamentID).Return(int64(2), nil)
+
+		service := NewService(tournamentRepo, matchRepo, nil, nil, nil, nil, nil, nil, log)
+		service.SetLeaderboardRefresher(&stubRefresher{err: fmt.Errorf("connection reset")})
+		_, err := service.ForceComplete(context.Background(), tournamentID, true)
+
+		assert.Error(t, err)
+		tournamentRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cancels unfinished matches and broadcasts final standings", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		broadcaster := new(MockBroadcaster)
+		tournamentID := uuid.New()
+		standings := []*domain.LeaderboardEntry{{Rank: 1, ProgramName: "winner"}}
+
+		tournamentRepo.On("GetByID", mock.Anything, tournamentID).
+			Return(&domain.Tournament{ID: tournamentID, Status: domain.TournamentActive}, nil)
+		matchRepo.On("CancelUnfinishedByTournament", mock.Anything, tournamentID).Return(int64(2), nil)
+		tournamentRepo.On("Update", mock.Anything, mock.MatchedBy(func(t *domain.Tournament) bool {
+			return t.Status == domain.TournamentCompleted && t.EndTime != nil
+		})).Return(nil)
+		tournamentRepo.On("GetLeaderboard", mock.Anything, tournamentID, finalStandingsLimit).Return(standings, nil)
+		broadcaster.On("Broadcast", tournamentID, "tournament_update", mock.MatchedBy(func(payload map[string]interface{}) bool {
+			return payload["forced"] == true && payload["cancelled_matches"] == int64(2)
+		})).Return()
+
+		testCache := setupTestRedisCache(t)
+		defer testCache.Close()
+
+		// A refresh already running elsewhere is good enough
+		refresher := &stubRefresher{err: errors.ErrConflict}
+		service := NewService(tournamentRepo, matchRepo, nil, nil,
+			cache.NewTournamentCache(testCache), cache.NewLeaderboardCache(testCache), broadcaster, nil, log)
+		service.SetLeaderboardRefresher(refresher)
+
+		result, err := service.ForceComplete(context.Background(), tournamentID, true)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), result.CancelledMatches)
+		assert.Equal(t, standings, result.Standings)
+		assert.Equal(t, 1, refresher.calls)
+		tournamentRepo.AssertExpectations(t)
+		broadcaster.AssertExpectations(t)
+	})
+}
+
+func TestCancel(t *testing.T) {
+	log, _ := logger.New("error", "json")
+
+	t.Run("finished tournament cannot be cancelled", func(t *testing.T) {
+		for _, status := range []domain.TournamentStatus{domain.TournamentCompleted, domain.TournamentCancelled} {
+			tournamentRepo := new(MockTournamentRepository)
+			matchRepo := new(MockMatchRepository)
+			tournamentID := uuid.New()
+			tournamentRepo.On("GetByID", mock.Anything, tournamentID).
+				Return(&domain.Tournament{ID: tournamentID, Status: status}, nil)
+
+			service := NewService(tournamentRepo, matchRepo, nil, nil, nil, nil, nil, nil, log)
+			_, err := service.Cancel(context.Background(), tournamentID)
+
+			assert.True(t, errors.IsConflict(err), status)
+			matchRepo.AssertNotCalled(t, "CancelUnfinishedByTournament", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("cancels unfinished matches and notifies", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		broadcaster := new(MockBroadcaster)
+		tournamentID := uuid.New()
+
+		tournamentRepo.On("GetByID", mock.Anything, tournamentID).
+			Return(&domain.Tournament{ID: tournamentID, Status: domain.TournamentPending}, nil)
+		matchRepo.On("CancelUnfinishedByTournament", mock.Anything, tournamentID).Return(int64(3), nil)
+		tournamentRepo.On("Update", mock.Anything, mock.MatchedBy(func(t *domain.Tournament) bool {
+			return t.Status == domain.TournamentCancelled && t.EndTime != nil
+		})).Return(nil)
+		broadcaster.On("Broadcast", tournamentID, "tournament_update", mock.Anything).Return()
+
+		testCache := setupTestRedisCache(t)
+		defer testCache.Close()
+
+		notifier := &statusNotifier{}
+		service := NewService(tournamentRepo, matchRepo, nil, nil,
+			cache.NewTournamentCache(testCache), cache.NewLeaderboardCache(testCache), broadcaster, nil, log)
+		service.AddNotifier(notifier)
+
+		cancelled, err := service.Cancel(context.Background(), tournamentID)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), cancelled)
+		assert.Equal(t, []domain.TournamentStatus{domain.TournamentCancelled}, notifier.cancelled)
+		tournamentRepo.AssertExpectations(t)
+	})
+}
+
+// statusNotifier records tournament state transitions
+type statusNotifier struct {
+	cancelled []domain.TournamentStatus
+}
+
+func (n *statusNotifier) TournamentStarted(_ context.Context, _ *domain.Tournament) error { return nil }
+func (n *statusNotifier) TournamentCompleted(_ context.Context, _ *domain.Tournament) error {
+	return nil
+}
+func (n *statusNotifier) TournamentCancelled(_ context.Context, tournament *domain.Tournament) error {
+	n.cancelled = append(n.cancelled, tournament.Status)
+	return nil
+}
+
+// setupTestRedisCache creates a test Redis cache
+// For integration tests, use real Redis or testcontainers
+func setupTestRedisCache(t *testing.T) *cache.Cache {
+	// Skip if no Redis available
+	t.Skip("Implement setupTestRedisCache with real Redis or testcontainers")
+	return nil
+}
+
+func TestUploadPriorityPolicy(t *testing.T) {
+	now := time.Now()
+	newProgramID := uuid.New()
+	policy := UploadPriorityPolicy{Window: 10 * time.Minute, HighLimit: 2, MediumLimit: 4}
+
+	programsUploaded := func(recent, old int) []*domain.Program {
+		programs := []*domain.Program{{ID: newProgramID, CreatedAt: now, UpdatedAt: now}}
+		for i := 0; i < recent; i++ {
+			programs = append(programs, &domain.Program{ID: uuid.New(), CreatedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Minute)})
+		}
+		for i := 0; i < old; i++ {
+			programs = append(programs, &domain.Program{ID: uuid.New(), CreatedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Hour)})
+		}
+		return programs
+	}
+
+	tests := []struct {
+		name     string
+		recent   int
+		old      int
+		expected domain.MatchPriority
+	}{
+		{"quiet tournament", 1, 10, domain.PriorityHigh},
+		{"some recent uploads", 2, 0, domain.PriorityMedium},
+		{"upload burst", 4, 0, domain.PriorityLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			programs := programsUploaded(tt.recent, tt.old)
+			assert.Equal(t, tt.expected, policy.priorityFor(programs, newProgramID, now))
+		})
+	}
+}
+
+type staticProgramLookup struct {
+	programs []*domain.Program
+}
+
+func (l staticProgramLookup) GetByIDs(_ context.Context, _ []uuid.UUID) ([]*domain.Program, error) {
+	return l.programs, nil
+}
+
+func TestGenerateRoundRobinMatchesForGame_Walkover(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	service := NewService(nil, nil, nil, nil, nil, nil, nil, nil, log)
+
+	syntaxError := "SyntaxError: invalid syntax"
+	good1 := &domain.Program{ID: uuid.New()}
+	good2 := &domain.Program{ID: uuid.New()}
+	broken1 := &domain.Program{ID: uuid.New(), ErrorMessage: &syntaxError}
+	broken2 := &domain.Program{ID: uuid.New(), ErrorMessage: &syntaxError}
+	service.SetProgramLookup(staticProgramLookup{programs: []*domain.Program{good1, good2, broken1, broken2}})
+
+	var participants []*domain.TournamentParticipant
+	for _, p := range []*domain.Program{good1, good2, broken1, broken2} {
+		participants = append(participants, &domain.TournamentParticipant{ID: uuid.New(), ProgramID: p.ID})
+	}
+
+	tournament := &domain.Tournament{ID: uuid.New(), GameType: "tictactoe"}
+	unrunnable := service.unrunnablePrograms(context.Background(), participants)
+	assert.Equal(t, map[uuid.UUID]bool{broken1.ID: true, broken2.ID: true}, unrunnable)
+
+	matches, err := service.generateRoundRobinMatchesForGame(tournament, participants, "tictactoe", 1, domain.PriorityHigh, unrunnable)
+	assert.NoError(t, err)
+
+	// 12 ordered pairs minus the 2 between broken programs
+	assert.Len(t, matches, 10)
+
+	pending := 0
+	for _, m := range matches {
+		assert.False(t, unrunnable[m.Program1ID] && unrunnable[m.Program2ID], "broken programs must not play each other")
+
+		switch {
+		case unrunnable[m.Program1ID]:
+			assert.True(t, m.IsWalkover())
+			assert.Equal(t, domain.MatchCompleted, m.Status)
+			assert.Equal(t, 2, *m.Winner)
+			assert.NotNil(t, m.CompletedAt)
+		case unrunnable[m.Program2ID]:
+			assert.True(t, m.IsWalkover())
+			assert.Equal(t, 1, *m.Winner)
+		default:
+			assert.Equal(t, domain.MatchPending, m.Status)
+			assert.Nil(t, m.Winner)
+			pending++
+		}
+	}
+	assert.Equal(t, 2, pending)
+	assert.Equal(t, 8, countWalkovers(matches))
+}
+
+func TestGenerateRoundRobinMatchesForGame_Practice(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	service := NewService(nil, nil, nil, nil, nil, nil, nil, nil, log)
+
+	participants := []*domain.TournamentParticipant{
+		{ID: uuid.New(), ProgramID: uuid.New()},
+		{ID: uuid.New(), ProgramID: uuid.New()},
+	}
+	tournament := &domain.Tournament{ID: uuid.New(), GameType: "tictactoe", IsPractice: true}
+
+	matches, err := service.generateRoundRobinMatchesForGame(tournament, participants, "tictactoe", 1, domain.PriorityHigh, nil)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+
+	// Practice matches never compete with real ones for workers
+	for _, m := range matches {
+		assert.True(t, m.IsPractice)
+		assert.Equal(t, domain.PriorityLow, m.Priority)
+	}
+}
+
+func TestUnrunnablePrograms_WithoutLookup(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	service := NewService(nil, nil, nil, nil, nil, nil, nil, nil, log)
+
+	participants := []*domain.TournamentParticipant{{ProgramID: uuid.New()}, {ProgramID: uuid.New()}}
+	assert.Empty(t, service.unrunnablePrograms(context.Background(), participants))
+}
+
+func TestGetRoundMatches(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+
+	t.Run("unknown round is an empty page", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		service := NewService(new(MockTournamentRepository), matchRepo, nil, nil, nil, nil, nil, nil, log)
+
+		matchRepo.On("GetRoundStats", mock.Anything, tournamentID, 42).Return(&domain.TournamentMatchStats{}, nil)
+		matchRepo.On("GetByRound", mock.Anything, tournamentID, 42, 50, 0).Return(nil, nil)
+
+		round, err := service.GetRoundMatches(context.Background(), tournamentID, 42, 50, 0)
+
+		require.NoError(t, err)
+		assert.NotNil(t, round.Matches, "empty round must serialize as [] rather than null")
+		assert.Empty(t, round.Matches)
+		assert.Equal(t, 0, round.Summary.Total)
+	})
+
+	t.Run("pages share the round summary", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		service := NewService(new(MockTournamentRepository), matchRepo, nil, nil, nil, nil, nil, nil, log)
+
+		all := make([]*domain.Match, 5)
+		for i := range all {
+			all[i] = &domain.Match{ID: uuid.New(), TournamentID: tournamentID, RoundNumber: 3}
+		}
+		summary := &domain.TournamentMatchStats{Total: 5, Completed: 3, Failed: 1, Pending: 1}
+
+		matchRepo.On("GetRoundStats", mock.Anything, tournamentID, 3).Return(summary, nil)
+		matchRepo.On("GetByRound", mock.Anything, tournamentID, 3, 2, 0).Return(all[0:2], nil)
+		matchRepo.On("GetByRound", mock.Anything, tournamentID, 3, 2, 2).Return(all[2:4], nil)
+		matchRepo.On("GetByRound", mock.Anything, tournamentID, 3, 2, 4).Return(all[4:], nil)
+
+		seen := make(map[uuid.UUID]bool)
+		for offset := 0; offset < 5; offset += 2 {
+			page, err := service.GetRoundMatches(context.Background(), tournamentID, 3, 2, offset)
+			require.NoError(t, err)
+
+			assert.Equal(t, *summary, page.Summary)
+			assert.Equal(t, offset, page.Offset)
+			assert.Equal(t, 2, page.Limit)
+			for _, m := range page.Matches {
+				assert.False(t, seen[m.ID], "match returned on two pages")
+				seen[m.ID] = true
+			}
+		}
+		assert.Len(t, seen, 5)
+	})
+
+	t.Run("rejects non-positive round", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		service := NewService(new(MockTournamentRepository), matchRepo, nil, nil, nil, nil, nil, nil, log)
+
+		_, err := service.GetRoundMatches(context.Background(), tournamentID, 0, 50, 0)
+
+		assert.Error(t, err)
+		matchRepo.AssertNotCalled(t, "GetByRound", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_CreateTestMatch(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	userID := uuid.New()
+	tournamentID := uuid.New()
+
+	newProgram := func(owner uuid.UUID, gameType string) *domain.Program {
+		return &domain.Program{ID: uuid.New(), UserID: owner, GameType: gameType, TournamentID: &tournamentID}
+	}
+
+	t.Run("creates high priority test match", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		queueManager := new(MockQueueManager)
+		service := NewService(nil, matchRepo, queueManager, nil, nil, nil, nil, nil, log)
+		program1, program2 := newProgram(userID, "tictactoe"), newProgram(userID, "tictactoe")
+		service.SetProgramLookup(staticProgramLookup{programs: []*domain.Program{program1, program2}})
+
+		matchRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Match")).Return(nil)
+		queueManager.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Match")).Return(nil)
+
+		match, err := service.CreateTestMatch(context.Background(), &TestMatchRequest{
+			Program1ID: program1.ID,
+			Program2ID: program2.ID,
+			UserID:     userID,
+		})
+
+		require.NoError(t, err)
+		assert.True(t, match.IsTest)
+		assert.Equal(t, domain.PriorityHigh, match.Priority)
+		assert.Equal(t, tournamentID, match.TournamentID)
+		assert.Equal(t, "tictactoe", match.GameType)
+		matchRepo.AssertExpectations(t)
+		queueManager.AssertExpectations(t)
+	})
+
+	t.Run("rejects programs of another user", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		service := NewService(nil, matchRepo, nil, nil, nil, nil, nil, nil, log)
+		own, foreign := newProgram(userID, "tictactoe"), newProgram(uuid.New(), "tictactoe")
+		service.SetProgramLookup(staticProgramLookup{programs: []*domain.Program{own, foreign}})
+
+		_, err := service.CreateTestMatch(context.Background(), &TestMatchRequest{
+			Program1ID: own.ID,
+			Program2ID: foreign.ID,
+			UserID:     userID,
+		})
+
+		assert.Equal(t, http.StatusForbidden, errors.GetAppError(err).Code)
+		matchRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects programs of another game", func(t *testing.T) {
+		service := NewService(nil, nil, nil, nil, nil, nil, nil, nil, log)
+		program1, program2 := newProgram(userID, "tictactoe"), newProgram(userID, "chess")
+		service.SetProgramLookup(staticProgramLookup{programs: []*domain.Program{program1, program2}})
+
+		_, err := service.CreateTestMatch(context.Background(), &TestMatchRequest{
+			Program1ID: program1.ID,
+			Program2ID: program2.ID,
+			GameType:   "tictactoe",
+			UserID:     userID,
+		})
+
+		assert.Equal(t, http.StatusBadRequest, errors.GetAppError(err).Code)
+	})
+
+	t.Run("unknown program", func(t *testing.T) {
+		service := NewService(nil, nil, nil, nil, nil, nil, nil, nil, log)
+		program1 := newProgram(userID, "tictactoe")
+		service.SetProgramLookup(staticProgramLookup{programs: []*domain.Program{program1}})
+
+		_, err := service.CreateTestMatch(context.Background(), &TestMatchRequest{
+			Program1ID: program1.ID,
+			Program2ID: uuid.New(),
+			UserID:     userID,
+		})
+
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
+
+func TestRunGameMatches_MarksRoundActive(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+
+	t.Run("pending matches start the game round", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		queueManager := new(MockQueueManager)
+		gameRepo := new(MockGameRepository)
+		service := NewService(nil, matchRepo, queueManager, gameRepo, nil, nil, nil, nil, log)
+
+		pending := []*domain.Match{
+			{ID: uuid.New(), TournamentID: tournamentID, GameType: "tictactoe", Status: domain.MatchPending},
+			{ID: uuid.New(), TournamentID: tournamentID, GameType: "tictactoe", Status: domain.MatchPending},
+		}
+		matchRepo.On("GetPendingByTournamentAndGame", mock.Anything, tournamentID, "tictactoe").Return(pending, nil)
+		gameRepo.On("GetGameWindow", mock.Anything, tournamentID, "tictactoe").Return(nil, errors.ErrNotFound)
+		gameRepo.On("MarkRoundActive", mock.Anything, tournamentID, "tictactoe").Return(nil).Once()
+		queueManager.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Match")).Return(nil)
+
+		enqueued, err := service.RunGameMatches(context.Background(), tournamentID, "tictactoe", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, enqueued)
+		gameRepo.AssertExpectations(t)
+	})
+
+	t.Run("marking failure does not stop the round", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		queueManager := new(MockQueueManager)
+		gameRepo := new(MockGameRepository)
+		service := NewService(nil, matchRepo, queueManager, gameRepo, nil, nil, nil, nil, log)
+
+		pending := []*domain.Match{{ID: uuid.New(), TournamentID: tournamentID, GameType: "tictactoe", Status: domain.MatchPending}}
+		matchRepo.On("GetPendingByTournamentAndGame", mock.Anything, tournamentID, "tictactoe").Return(pending, nil)
+		gameRepo.On("GetGameWindow", mock.Anything, tournamentID, "tictactoe").Return(nil, errors.ErrNotFound)
+		gameRepo.On("MarkRoundActive", mock.Anything, tournamentID, "tictactoe").
+			Return(errors.ErrNotFound.WithMessage("tournament game not found"))
+		queueManager.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Match")).Return(nil)
+
+		enqueued, err := service.RunGameMatches(context.Background(), tournamentID, "tictactoe", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, enqueued)
+	})
+}
+
+func TestRunGameMatches_GameWindow(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+	start := time.Now().Add(time.Hour)
+	end := start.Add(time.Hour)
+	closed := &domain.TournamentGame{TournamentID: tournamentID, WindowStart: &start, WindowEnd: &end}
+
+	t.Run("closed window rejects the run", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		gameRepo := new(MockGameRepository)
+		service := NewService(nil, matchRepo, new(MockQueueManager), gameRepo, nil, nil, nil, nil, log)
+
+		gameRepo.On("GetGameWindow", mock.Anything, tournamentID, "tictactoe").Return(closed, nil)
+
+		_, err := service.RunGameMatches(context.Background(), tournamentID, "tictactoe", false)
+
+		assert.True(t, errors.IsConflict(err))
+		matchRepo.AssertNotCalled(t, "GetPendingByTournamentAndGame", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("admin override marks enqueued matches", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		queueManager := new(MockQueueManager)
+		gameRepo := new(MockGameRepository)
+		service := NewService(nil, matchRepo, queueManager, gameRepo, nil, nil, nil, nil, log)
+
+		pending := []*domain.Match{{ID: uuid.New(), TournamentID: tournamentID, GameType: "tictactoe", Status: domain.MatchPending}}
+		matchRepo.On("GetPendingByTournamentAndGame", mock.Anything, tournamentID, "tictactoe").Return(pending, nil)
+		gameRepo.On("MarkRoundActive", mock.Anything, tournamentID, "tictactoe").Return(nil)
+		queueManager.On("Enqueue", mock.Anything, mock.MatchedBy(func(m *domain.Match) bool { return m.WindowOverride })).Return(nil).Once()
+
+		enqueued, err := service.RunGameMatches(context.Background(), tournamentID, "tictactoe", true)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, enqueued)
+		queueManager.AssertExpectations(t)
+		gameRepo.AssertNotCalled(t, "GetGameWindow", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// staticReferenceBots returns the same reference bots for any game
+type staticReferenceBots struct {
+	bots []*domain.Program
+}
+
+func (r staticReferenceBots) GetByTournamentAndGame(_ context.Context, _, _ uuid.UUID) ([]*domain.Program, error) {
+	return nil, nil
+}
+
+func (r staticReferenceBots) ListReferenceByGame(_ context.Context, _ uuid.UUID) ([]*domain.Program, error) {
+	return r.bots, nil
+}
+
+func (r staticReferenceBots) SetValidationResult(_ context.Context, _ uuid.UUID, _ domain.ProgramValidationStatus, _ string) error {
+	return nil
+}
+
+func TestReferenceMatches(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	service := NewService(nil, nil, nil, nil, nil, nil, nil, nil, log)
+
+	programID := uuid.New()
+	bots := staticReferenceBots{bots: []*domain.Program{
+		{ID: uuid.New(), GameType: "prisoners_dilemma", IsReference: true},
+		{ID: uuid.New(), GameType: "prisoners_dilemma", IsReference: true},
+	}}
+
+	t.Run("calibration disabled", func(t *testing.T) {
+		tournament := &domain.Tournament{ID: uuid.New(), Metadata: map[string]interface{}{}}
+
+		matches, err := service.referenceMatches(context.Background(), tournament, uuid.New(), programID, bots)
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+
+	t.Run("new program plays every reference bot first", func(t *testing.T) {
+		tournament := &domain.Tournament{ID: uuid.New(), Metadata: map[string]interface{}{domain.MetaReferenceCalibration: true}}
+
+		matches, err := service.referenceMatches(context.Background(), tournament, uuid.New(), programID, bots)
+		require.NoError(t, err)
+		require.Len(t, matches, 2)
+		for i, match := range matches {
+			assert.Equal(t, tournament.ID, match.TournamentID)
+			assert.Equal(t, programID, match.Program1ID)
+			assert.Equal(t, bots.bots[i].ID, match.Program2ID)
+			assert.Equal(t, "prisoners_dilemma", match.GameType)
+			assert.Equal(t, domain.PriorityHigh, match.Priority)
+			assert.NoError(t, match.Validate())
+		}
+	})
+
+	t.Run("practice tournament calibrates at low priority", func(t *testing.T) {
+		tournament := &domain.Tournament{ID: uuid.New(), IsPractice: true, Metadata: map[string]interface{}{domain.MetaReferenceCalibration: true}}
+
+		matches, err := service.referenceMatches(context.Background(), tournament, uuid.New(), programID, bots)
+		require.NoError(t, err)
+		require.Len(t, matches, 2)
+		for _, match := range matches {
+			assert.True(t, match.IsPractice)
+			assert.Equal(t, domain.PriorityLow, match.Priority)
+		}
+	})
+}
+
+// memoryActiveIDs caches active tournament IDs in memory
+type memoryActiveIDs struct {
+	ids []uuid.UUID
+}
+
+func (c *memoryActiveIDs) GetActiveIDs(_ context.Context) ([]uuid.UUID, error) {
+	return c.ids, nil
+}
+
+func (c *memoryActiveIDs) SetActiveIDs(_ context.Context, ids []uuid.UUID) error {
+	c.ids = ids
+	return nil
+}
+
+func (c *memoryActiveIDs) InvalidateActiveIDs(_ context.Context) error {
+	c.ids = nil
+	return nil
+}
+
+func TestGetActiveTournamentIDs(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	ctx := context.Background()
+
+	t.Run("second call is served from the cache", func(t *testing.T) {
+		active := []uuid.UUID{uuid.New(), uuid.New()}
+		repo := new(MockTournamentRepository)
+		repo.On("GetActiveTournamentIDs", mock.Anything).Return(active, nil).Once()
+		activeIDs := &memoryActiveIDs{}
+
+		service := NewService(repo, nil, nil, nil, nil, nil, nil, nil, log)
+		service.SetActiveIDsCache(activeIDs)
+
+		ids, err := service.GetActiveTournamentIDs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, active, ids)
+		assert.Equal(t, active, activeIDs.ids)
+
+		ids, err = service.GetActiveTournamentIDs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, active, ids)
+		repo.AssertNumberOfCalls(t, "GetActiveTournamentIDs", 1)
+
+		// After a status change the list is read again
+		service.invalidateActiveIDs(ctx)
+		repo.On("GetActiveTournamentIDs", mock.Anything).Return(active[:1], nil).Once()
+		ids, err = service.GetActiveTournamentIDs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, active[:1], ids)
+		repo.AssertNumberOfCalls(t, "GetActiveTournamentIDs", 2)
+	})
+
+	t.Run("empty result is cached too", func(t *testing.T) {
+		repo := new(MockTournamentRepository)
+		repo.On("GetActiveTournamentIDs", mock.Anything).Return([]uuid.UUID{}, nil).Once()
+
+		service := NewService(repo, nil, nil, nil, nil, nil, nil, nil, log)
+		service.SetActiveIDsCache(&memoryActiveIDs{})
+
+		for i := 0; i < 2; i++ {
+			ids, err := service.GetActiveTournamentIDs(ctx)
+			require.NoError(t, err)
+			assert.NotNil(t, ids)
+			assert.Empty(t, ids)
+		}
+		repo.AssertNumberOfCalls(t, "GetActiveTournamentIDs", 1)
+	})
+
+	t.Run("without a cache every call reads the repository", func(t *testing.T) {
+		repo := new(MockTournamentRepository)
+		repo.On("GetActiveTournamentIDs", mock.Anything).Return([]uuid.UUID{}, nil)
+
+		service := NewService(repo, nil, nil, nil, nil, nil, nil, nil, log)
+		for i := 0; i < 2; i++ {
+			_, err := service.GetActiveTournamentIDs(ctx)
+			require.NoError(t, err)
+		}
+		repo.AssertNumberOfCalls(t, "GetActiveTournamentIDs", 2)
+	})
+}
+
+// validationPrograms records validation statuses of programs
+type validationPrograms struct {
+	staticReferenceBots
+	statuses map[uuid.UUID]domain.ProgramValidationStatus
+}
+
+func (r *validationPrograms) SetValidationResult(_ context.Context, id uuid.UUID, status domain.ProgramValidationStatus, _ string) error {
+	r.statuses[id] = status
+	return nil
+}
+
+func TestScheduleValidationMatch(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID, gameID, programID := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("game without a validation bot", func(t *testing.T) {
+		gameRepo, matchRepo := new(MockGameRepository), new(MockMatchRepository)
+		gameRepo.On("GetByID", mock.Anything, gameID).Return(&domain.Game{ID: gameID, Name: "dilemma"}, nil)
+		programs := &validationPrograms{statuses: map[uuid.UUID]domain.ProgramValidationStatus{}}
+
+		service := NewService(nil, matchRepo, nil, gameRepo, nil, nil, nil, nil, log)
+		match, err := service.ScheduleValidationMatch(context.Background(), tournamentID, gameID, programID, programs)
+
+		require.NoError(t, err)
+		assert.Nil(t, match)
+		assert.Empty(t, programs.statuses)
+		matchRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("low priority test match against the validation bot", func(t *testing.T) {
+		botID := uuid.New()
+		gameRepo, matchRepo, queue := new(MockGameRepository), new(MockMatchRepository), new(MockQueueManager)
+		gameRepo.On("GetByID", mock.Anything, gameID).Return(&domain.Game{ID: gameID, Name: "dilemma", ValidationBotID: &botID}, nil)
+		matchRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Match")).Return(nil)
+		queue.On("Enqueue", mock.Anything, mock.AnythingOfType("*domain.Match")).Return(nil)
+		programs := &validationPrograms{statuses: map[uuid.UUID]domain.ProgramValidationStatus{}}
+
+		service := NewService(nil, matchRepo, queue, gameRepo, nil, nil, nil, nil, log)
+		match, err := service.ScheduleValidationMatch(context.Background(), tournamentID, gameID, programID, programs)
+
+		require.NoError(t, err)
+		require.NotNil(t, match)
+		assert.Equal(t, tournamentID, match.TournamentID)
+		assert.Equal(t, programID, match.Program1ID)
+		assert.Equal(t, botID, match.Program2ID)
+		assert.Equal(t, "dilemma", match.GameType)
+		assert.Equal(t, domain.PriorityLow, match.Priority)
+		assert.True(t, match.IsTest, "validation match must not affect leaderboards")
+		assert.True(t, match.IsValidation)
+		assert.Equal(t, domain.ValidationPending, programs.statuses[programID])
+		queue.AssertExpectations(t)
+	})
+}
+
+func TestRegenerateGameRound(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID, gameID := uuid.New(), uuid.New()
+
+	newService := func(tournamentRepo *MockTournamentRepository, matchRepo *MockMatchRepository, queue *MockQueueManager, gameRepo *MockGameRepository) *Service {
+		lock := new(MockDistributedLock)
+		lock.On("WithLock", mock.Anything, "tournament:schedule:"+tournamentID.String()+":"+gameID.String(), mock.Anything, mock.Anything).Return(nil)
+		tournamentRepo.On("GetByID", mock.Anything, tournamentID).
+			Return(&domain.Tournament{ID: tournamentID, Status: domain.TournamentActive}, nil)
+		return NewService(tournamentRepo, matchRepo, queue, gameRepo, nil, nil, nil, lock, log)
+	}
+
+	t.Run("replaces unfinished matches with a fresh round", func(t *testing.T) {
+		tournamentRepo, matchRepo, queue, gameRepo := new(MockTournamentRepository), new(MockMatchRepository), new(MockQueueManager), new(MockGameRepository)
+		participants := []*domain.TournamentParticipant{
+			{ProgramID: uuid.New()}, {ProgramID: uuid.New()}, {ProgramID: uuid.New()},
+		}
+
+		gameRepo.On("GetTournamentGame", mock.Anything, tournamentID, gameID).Return(&domain.TournamentGame{GameID: gameID}, nil)
+		gameRepo.On("GetByID", mock.Anything, gameID).Return(&domain.Game{ID: gameID, Name: "dilemma"}, nil)
+		tournamentRepo.On("GetLatestParticipantsByGame", mock.Anything, tournamentID, "dilemma").Return(participants, nil)
+		matchRepo.On("DiscardUnfinishedByGame", mock.Anything, tournamentID, "dilemma").Return(int64(4), int64(1), nil)
+		matchRepo.On("GetNextRoundNumberByGame", mock.Anything, tournamentID, "dilemma").Return(3, nil)
+		matchRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(matches []*domain.Match) bool {
+			for _, m := range matches {
+				if m.RoundNumber != 3 || m.GameType != "dilemma" || m.Priority != domain.PriorityHigh {
+					return false
+				}
+			}
+			return len(matches) == 6
+		})).Return(nil)
+		gameRepo.On("MarkRoundActive", mock.Anything, tournamentID, "dilemma").Return(nil)
+		queue.On("Enqueue", mock.Anything, mock.Anything).Return(nil)
+
+		result, err := newService(tournamentRepo, matchRepo, queue, gameRepo).RegenerateGameRound(context.Background(), tournamentID, gameID)
+		require.NoError(t, err)
+		assert.Equal(t, &RegenerateRoundResult{
+			GameType: "dilemma", RoundNumber: 3,
+			MatchesDeleted: 4, MatchesCancelled: 1, MatchesCreated: 6, Enqueued: 6,
+		}, result)
+		matchRepo.AssertExpectations(t)
+		queue.AssertNumberOfCalls(t, "Enqueue", 6)
+	})
+
+	t.Run("keeps the round when there are too few participants", func(t *testing.T) {
+		tournamentRepo, matchRepo, gameRepo := new(MockTournamentRepository), new(MockMatchRepository), new(MockGameRepository)
+
+		gameRepo.On("GetTournamentGame", mock.Anything, tournamentID, gameID).Return(&domain.TournamentGame{GameID: gameID}, nil)
+		gameRepo.On("GetByID", mock.Anything, gameID).Return(&domain.Game{ID: gameID, Name: "dilemma"}, nil)
+		tournamentRepo.On("GetLatestParticipantsByGame", mock.Anything, tournamentID, "dilemma").
+			Return([]*domain.TournamentParticipant{{ProgramID: uuid.New()}}, nil)
+
+		_, err := newService(tournamentRepo, matchRepo, nil, gameRepo).RegenerateGameRound(context.Background(), tournamentID, gameID)
+		assert.True(t, errors.IsAppError(err))
+		matchRepo.AssertNotCalled(t, "DiscardUnfinishedByGame", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("game must belong to the tournament", func(t *testing.T) {
+		tournamentRepo, matchRepo, gameRepo := new(MockTournamentRepository), new(MockMatchRepository), new(MockGameRepository)
+
+		gameRepo.On("GetTournamentGame", mock.Anything, tournamentID, gameID).Return(nil, errors.ErrNotFound)
+
+		_, err := newService(tournamentRepo, matchRepo, nil, gameRepo).RegenerateGameRound(context.Background(), tournamentID, gameID)
+		assert.True(t, errors.IsNotFound(err))
+		matchRepo.AssertNotCalled(t, "DiscardUnfinishedByGame", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// staticCrossGameChecker returns a fixed leaderboard computed "from matches"
+type staticCrossGameChecker struct {
+	entries []*domain.CrossGameLeaderboardEntry
+	calls   int
+}
+
+func (c *staticCrossGameChecker) GetCrossGameLeaderboardFromMatches(ctx context.Context, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error) {
+	c.calls++
+	return c.entries, nil
+}
+
+func TestGetCrossGameLeaderboard_ConsistencyCheck(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+	teamA, teamB := uuid.New(), uuid.New()
+	gameID := uuid.New()
+
+	entry := func(team uuid.UUID, rating, wins int) *domain.CrossGameLeaderboardEntry {
+		return &domain.CrossGameLeaderboardEntry{
+			TeamID:      &team,
+			TotalRating: rating,
+			TotalWins:   wins,
+			TotalGames:  wins,
+			GameRatings: map[string]domain.GameRatingInfo{
+				gameID.String(): {GameID: gameID, Rating: rating, Wins: wins, TotalGames: wins},
+			},
+		}
+	}
+	aggregated := []*domain.CrossGameLeaderboardEntry{entry(teamA, 30, 3), entry(teamB, 30, 3)}
+
+	repo := new(MockTournamentRepository)
+	repo.On("GetCrossGameLeaderboard", mock.Anything, tournamentID).Return(aggregated, nil)
+
+	// Without the checker the match history is not read
+	service := NewService(repo, nil, nil, nil, nil, nil, nil, nil, log)
+	entries, err := service.GetCrossGameLeaderboard(context.Background(), tournamentID)
+	require.NoError(t, err)
+	assert.Equal(t, aggregated, entries)
+
+	checker := &staticCrossGameChecker{entries: []*domain.CrossGameLeaderboardEntry{entry(teamB, 30, 3), entry(teamA, 30, 3)}}
+	service.SetCrossGameChecker(checker)
+	entries, err = service.GetCrossGameLeaderboard(context.Background(), tournamentID)
+	require.NoError(t, err)
+	assert.Equal(t, aggregated, entries, "the checked leaderboard is returned unchanged")
+	assert.Equal(t, 1, checker.calls)
+
+	// Rows are matched by team: the order of tied teams does not matter
+	assert.Empty(t, crossGameMismatches(aggregated, checker.entries))
+
+	drifted := []*domain.CrossGameLeaderboardEntry{entry(teamA, 30, 3), entry(teamB, 20, 2)}
+	assert.Equal(t, []string{teamB.String()}, crossGameMismatches(drifted, checker.entries))
+
+	missing := []*domain.CrossGameLeaderboardEntry{entry(teamA, 30, 3)}
+	assert.Equal(t, []string{teamB.String()}, crossGameMismatches(missing, checker.entries))
+}
+
+func TestAdjustMatchResult(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	adminID := uuid.New()
+	tournamentID := uuid.New()
+	matchID := uuid.New()
+	score1, score2, winner := 3, 1, 1
+
+	t.Run("reason is required", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		service := NewService(nil, matchRepo, nil, nil, nil, nil, nil, nil, log)
+
+		_, err := service.AdjustMatchResult(context.Background(), matchID, adminID, &AdjustMatchResultRequest{
+			Score1: &score1, Score2: &score2, Winner: &winner, Reason: "  ",
+		})
+		assertValidationError(t, err)
+
+		_, err = service.AnnulMatchResult(context.Background(), matchID, adminID, &AnnulMatchResultRequest{})
+		assertValidationError(t, err)
+		matchRepo.AssertNotCalled(t, "AdjustResult", mock.Anything, mock.Anything)
+	})
+
+	t.Run("winner must be a side or a draw", func(t *testing.T) {
+		matchRepo := new(MockMatchRepository)
+		service := NewService(nil, matchRepo, nil, nil, nil, nil, nil, nil, log)
+
+		invalid := 3
+		_, err := service.AdjustMatchResult(context.Background(), matchID, adminID, &AdjustMatchResultRequest{
+			Score1: &score1, Score2: &score2, Winner: &invalid, Reason: "scorer bug",
+		})
+		assertValidationError(t, err)
+		matchRepo.AssertNotCalled(t, "AdjustResult", mock.Anything, mock.Anything)
+	})
+
+	t.Run("edit records the reason and notifies subscribers", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		broadcaster := new(MockBroadcaster)
+
+		tournamentRepo.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID}, nil)
+		matchRepo.On("GetByID", mock.Anything, matchID).
+			Return(&domain.Match{ID: matchID, TournamentID: tournamentID, Status: domain.MatchCompleted, Score1: &score1, Score2: &score2, Winner: &winner}, nil)
+		matchRepo.On("AdjustResult", mock.Anything, mock.MatchedBy(func(a *domain.MatchResultAdjustment) bool {
+			return a.Action == domain.MatchAdjustmentEdit && a.Reason == "scorer bug" && *a.AdjustedBy == adminID
+		})).Return(nil)
+		broadcaster.On("Broadcast", tournamentID, "match_update", mock.MatchedBy(func(payload map[string]interface{}) bool {
+			return payload["adjustment"] == domain.MatchAdjustmentEdit
+		})).Return()
+
+		testCache := setupTestRedisCache(t)
+		defer testCache.Close()
+
+		service := NewService(tournamentRepo, matchRepo, nil, nil,
+			cache.NewTournamentCache(testCache), cache.NewLeaderboardCache(testCache), broadcaster, nil, log)
+
+		match, err := service.AdjustMatchResult(context.Background(), matchID, adminID, &AdjustMatchResultRequest{
+			Score1: &score1, Score2: &score2, Winner: &winner, Reason: " scorer bug ",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, matchID, match.ID)
+		matchRepo.AssertExpectations(t)
+		broadcaster.AssertExpectations(t)
+	})
+
+	t.Run("annul re-enqueues on request", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		matchRepo := new(MockMatchRepository)
+		queueManager := new(MockQueueManager)
+		broadcaster := new(MockBroadcaster)
+
+		pending := &domain.Match{ID: matchID, TournamentID: tournamentID, Status: domain.MatchPending}
+		tournamentRepo.On("GetByID", mock.Anything, tournamentID).Return(&domain.Tournament{ID: tournamentID}, nil)
+		matchRepo.On("GetByID", mock.Anything, matchID).Return(pending, nil)
+		matchRepo.On("AdjustResult", mock.Anything, mock.Anything).Return(nil)
+		queueManager.On("Enqueue", mock.Anything, pending).Return(nil)
+		broadcaster.On("Broadcast", tournamentID, "match_update", mock.Anything).Return()
+
+		testCache := setupTestRedisCache(t)
+		defer testCache.Close()
+
+		service := NewService(tournamentRepo, matchRepo, queueManager, nil,
+			cache.NewTournamentCache(testCache), cache.NewLeaderboardCache(testCache), broadcaster, nil, log)
+
+		_, err := service.AnnulMatchResult(context.Background(), matchID, adminID, &AnnulMatchResultRequest{Reason: "worker crash", Requeue: true})
+
+		require.NoError(t, err)
+		queueManager.AssertExpectations(t)
+	})
+}
+
+func assertValidationError(t *testing.T, err error) {
+	t.Helper()
+	appErr := errors.GetAppError(err)
+	if assert.NotNil(t, appErr) {
+		assert.Equal(t, errors.ErrValidation.Code, appErr.Code)
+	}
+}
+
+func TestService_GetTeamLeaderboard(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	tournamentID := uuid.New()
+
+	ratings := func() []*domain.TeamRating {
+		return []*domain.TeamRating{
+			{TeamID: uuid.New(), TeamName: "allrounder", TotalRating: 700, GamesPlayed: 2, GameBreakdown: map[string]float64{"dilemma": 400, "tug_of_war": 300}},
+			{TeamID: uuid.New(), TeamName: "specialist", TotalRating: 500, GamesPlayed: 1, GameBreakdown: map[string]float64{"dilemma": 500}},
+		}
+	}
+
+	t.Run("raw sums favour teams in more games", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		tournamentRepo.On("GetTeamRatings", mock.Anything, tournamentID).Return(ratings(), nil)
+
+		service := NewService(tournamentRepo, nil, nil, nil, nil, nil, nil, nil, log)
+		result, err := service.GetTeamLeaderboard(context.Background(), tournamentID, false)
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, "allrounder", result[0].TeamName)
+		assert.Equal(t, 700.0, result[0].TotalRating)
+	})
+
+	t.Run("normalization does not penalize single-game teams", func(t *testing.T) {
+		tournamentRepo := new(MockTournamentRepository)
+		tournamentRepo.On("GetTeamRatings", mock.Anything, tournamentID).Return(ratings(), nil)
+
+		service := NewService(tournamentRepo, nil, nil, nil, nil, nil, nil, nil, log)
+		result, err := service.GetTeamLeaderboard(context.Background(), tournamentID, true)
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		// The specialist tops its only game, the allrounder is best in one of two
+		assert.Equal(t, "specialist", result[0].TeamName)
+		assert.Equal(t, 100.0, result[0].TotalRating)
+		assert.Equal(t, "allrounder", result[1].TeamName)
+		assert.Equal(t, 90.0, result[1].TotalRating)
+	})
 }
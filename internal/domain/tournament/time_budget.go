@@ -0,0 +1,168 @@
+package tournament
+
+import (
+	"context"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// timeBudgetLockKey ключ блокировки, чтобы бюджет времени проверяла только одна реплика
+const timeBudgetLockKey = "tournament:time_budget"
+
+// TimeBudgetRepository интерфейс для поиска турниров с бюджетом времени
+type TimeBudgetRepository interface {
+	GetActiveWithTimeBudget(ctx context.Context) ([]*domain.Tournament, error)
+	ClaimTimeBudgetWarning(ctx context.Context, tournamentID uuid.UUID) (bool, error)
+}
+
+// TournamentCompleter интерфейс для принудительного завершения турнира
+type TournamentCompleter interface {
+	ForceComplete(ctx context.Context, tournamentID uuid.UUID, cancelPending bool) (*ForceCompleteResult, error)
+}
+
+// BudgetEnforcer периодически завершает активные турниры, исчерпавшие бюджет времени
+// (metadata.time_budget от StartTime), и заранее предупреждает об этом участников
+type BudgetEnforcer struct {
+	repo        TimeBudgetRepository
+	completer   TournamentCompleter
+	broadcaster Broadcaster
+	warning     time.Duration
+	log         *logger.Logger
+	ticker      *lockedTicker
+}
+
+// NewBudgetEnforcer создаёт новый планировщик бюджета времени турниров.
+// warning - за сколько до истечения бюджета рассылается предупреждение (0 - не рассылать)
+func NewBudgetEnforcer(
+	repo TimeBudgetRepository,
+	completer TournamentCompleter,
+	broadcaster Broadcaster,
+	distributedLock DistributedLock,
+	interval time.Duration,
+	warning time.Duration,
+	log *logger.Logger,
+) *BudgetEnforcer {
+	b := &BudgetEnforcer{
+		repo:        repo,
+		completer:   completer,
+		broadcaster: broadcaster,
+		warning:     warning,
+		log:         log,
+	}
+	b.ticker = newLockedTicker(
+		"tournament time budget enforcer", timeBudgetLockKey, distributedLock, interval, log,
+		func(ctx context.Context) error {
+			b.EnforceDue(ctx, time.Now())
+			return nil
+		},
+	)
+
+	return b
+}
+
+// Start запускает периодическую проверку
+func (b *BudgetEnforcer) Start() {
+	b.ticker.start(zap.Duration("warning", b.warning))
+}
+
+// Stop останавливает планировщик
+func (b *BudgetEnforcer) Stop() {
+	b.ticker.stop()
+}
+
+// EnforceDue завершает турниры, бюджет времени которых истёк к моменту now: оставшиеся матчи
+// отменяются, таблица лидеров фиксируется. Турнирам, у которых до конца бюджета осталось
+// не больше warning, один раз рассылается предупреждение. Возвращает количество завершённых турниров
+func (b *BudgetEnforcer) EnforceDue(ctx context.Context, now time.Time) int {
+	tournaments, err := b.repo.GetActiveWithTimeBudget(ctx)
+	if err != nil {
+		b.log.LogError("Failed to get tournaments with time budget", err)
+		return 0
+	}
+
+	completed := 0
+	for _, t := range tournaments {
+		deadline := t.Deadline()
+		if deadline == nil {
+			b.log.Warn("Ignoring invalid tournament time budget",
+				zap.String("tournament_id", t.ID.String()),
+				zap.Any("time_budget", t.Metadata[domain.MetaTimeBudget]),
+			)
+			continue
+		}
+
+		if !now.Before(*deadline) {
+			if b.complete(ctx, t, *deadline) {
+				completed++
+			}
+			continue
+		}
+
+		if b.warning > 0 && !now.Before(deadline.Add(-b.warning)) {
+			b.warn(ctx, t, *deadline, now)
+		}
+	}
+
+	return completed
+}
+
+// complete принудительно завершает турнир с истёкшим бюджетом времени
+func (b *BudgetEnforcer) complete(ctx context.Context, t *domain.Tournament, deadline time.Time) bool {
+	result, err := b.completer.ForceComplete(ctx, t.ID, true)
+	if err != nil {
+		// Турнир успели завершить вручную
+		if errors.IsConflict(err) {
+			return false
+		}
+		b.log.LogError("Failed to complete tournament after time budget", err,
+			zap.String("tournament_id", t.ID.String()),
+		)
+		return false
+	}
+
+	b.log.Info("Tournament time budget exceeded, tournament completed",
+		zap.Bool("audit", true),
+		zap.String("tournament_id", t.ID.String()),
+		zap.Duration("time_budget", t.TimeBudget()),
+		zap.Time("deadline", deadline),
+		zap.Int64("cancelled_matches", result.CancelledMatches),
+	)
+
+	b.broadcaster.Broadcast(t.ID, "time_budget_exceeded", map[string]interface{}{
+		"deadline":          deadline,
+		"cancelled_matches": result.CancelledMatches,
+	})
+
+	return true
+}
+
+// warn рассылает предупреждение о скором истечении бюджета времени, если оно ещё не отправлялось
+func (b *BudgetEnforcer) warn(ctx context.Context, t *domain.Tournament, deadline, now time.Time) {
+	claimed, err := b.repo.ClaimTimeBudgetWarning(ctx, t.ID)
+	if err != nil {
+		b.log.LogError("Failed to claim time budget warning", err,
+			zap.String("tournament_id", t.ID.String()),
+		)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	remaining := deadline.Sub(now)
+	b.log.Info("Tournament time budget is running out",
+		zap.String("tournament_id", t.ID.String()),
+		zap.Time("deadline", deadline),
+		zap.Duration("remaining", remaining),
+	)
+
+	b.broadcaster.Broadcast(t.ID, "time_budget_warning", map[string]interface{}{
+		"deadline":          deadline,
+		"remaining_seconds": int64(remaining.Seconds()),
+	})
+}
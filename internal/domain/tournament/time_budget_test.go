@@ -0,0 +1,145 @@
+package tournament
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTimeBudgetRepository struct {
+	mock.Mock
+}
+
+func (m *MockTimeBudgetRepository) GetActiveWithTimeBudget(ctx context.Context) ([]*domain.Tournament, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Tournament), args.Error(1)
+}
+
+func (m *MockTimeBudgetRepository) ClaimTimeBudgetWarning(ctx context.Context, tournamentID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, tournamentID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockTournamentCompleter struct {
+	mock.Mock
+}
+
+func (m *MockTournamentCompleter) ForceComplete(ctx context.Context, tournamentID uuid.UUID, cancelPending bool) (*ForceCompleteResult, error) {
+	args := m.Called(ctx, tournamentID, cancelPending)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ForceCompleteResult), args.Error(1)
+}
+
+func TestBudgetEnforcer_EnforceDue(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	now := time.Now()
+
+	// budgeted returns an active tournament started `elapsed` ago with a 2h budget
+	budgeted := func(elapsed time.Duration) *domain.Tournament {
+		started := now.Add(-elapsed)
+		return &domain.Tournament{
+			ID:        uuid.New(),
+			Status:    domain.TournamentActive,
+			StartTime: &started,
+			Metadata:  map[string]interface{}{domain.MetaTimeBudget: "2h"},
+		}
+	}
+
+	t.Run("completes tournaments past the deadline", func(t *testing.T) {
+		repo := new(MockTimeBudgetRepository)
+		completer := new(MockTournamentCompleter)
+		broadcaster := new(MockBroadcaster)
+
+		overdue := budgeted(2*time.Hour + time.Second)
+		repo.On("GetActiveWithTimeBudget", mock.Anything).Return([]*domain.Tournament{overdue}, nil)
+		completer.On("ForceComplete", mock.Anything, overdue.ID, true).Return(&ForceCompleteResult{CancelledMatches: 4}, nil)
+		broadcaster.On("Broadcast", overdue.ID, "time_budget_exceeded", mock.MatchedBy(func(p map[string]interface{}) bool {
+			return p["cancelled_matches"] == int64(4) && p["deadline"] == overdue.StartTime.Add(2*time.Hour)
+		})).Return()
+
+		b := NewBudgetEnforcer(repo, completer, broadcaster, new(MockDistributedLock), time.Minute, 10*time.Minute, log)
+
+		assert.Equal(t, 1, b.EnforceDue(context.Background(), now))
+		completer.AssertExpectations(t)
+		broadcaster.AssertExpectations(t)
+		repo.AssertNotCalled(t, "ClaimTimeBudgetWarning", mock.Anything, mock.Anything)
+	})
+
+	t.Run("warns once within the warning interval", func(t *testing.T) {
+		repo := new(MockTimeBudgetRepository)
+		completer := new(MockTournamentCompleter)
+		broadcaster := new(MockBroadcaster)
+
+		closing := budgeted(2*time.Hour - 5*time.Minute)
+		repo.On("GetActiveWithTimeBudget", mock.Anything).Return([]*domain.Tournament{closing}, nil)
+		repo.On("ClaimTimeBudgetWarning", mock.Anything, closing.ID).Return(true, nil).Once()
+		repo.On("ClaimTimeBudgetWarning", mock.Anything, closing.ID).Return(false, nil)
+		broadcaster.On("Broadcast", closing.ID, "time_budget_warning", mock.MatchedBy(func(p map[string]interface{}) bool {
+			return p["remaining_seconds"] == int64(300)
+		})).Return().Once()
+
+		b := NewBudgetEnforcer(repo, completer, broadcaster, new(MockDistributedLock), time.Minute, 10*time.Minute, log)
+
+		assert.Equal(t, 0, b.EnforceDue(context.Background(), now))
+		// The next check must not repeat the warning
+		assert.Equal(t, 0, b.EnforceDue(context.Background(), now))
+		broadcaster.AssertExpectations(t)
+		completer.AssertNotCalled(t, "ForceComplete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("leaves tournaments with time left alone", func(t *testing.T) {
+		repo := new(MockTimeBudgetRepository)
+		completer := new(MockTournamentCompleter)
+		broadcaster := new(MockBroadcaster)
+
+		early := budgeted(time.Hour)
+		repo.On("GetActiveWithTimeBudget", mock.Anything).Return([]*domain.Tournament{early}, nil)
+
+		b := NewBudgetEnforcer(repo, completer, broadcaster, new(MockDistributedLock), time.Minute, 10*time.Minute, log)
+
+		assert.Equal(t, 0, b.EnforceDue(context.Background(), now))
+		repo.AssertNotCalled(t, "ClaimTimeBudgetWarning", mock.Anything, mock.Anything)
+		broadcaster.AssertNotCalled(t, "Broadcast", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("warning disabled", func(t *testing.T) {
+		repo := new(MockTimeBudgetRepository)
+		closing := budgeted(2*time.Hour - time.Minute)
+		repo.On("GetActiveWithTimeBudget", mock.Anything).Return([]*domain.Tournament{closing}, nil)
+
+		b := NewBudgetEnforcer(repo, new(MockTournamentCompleter), new(MockBroadcaster), new(MockDistributedLock), time.Minute, 0, log)
+
+		assert.Equal(t, 0, b.EnforceDue(context.Background(), now))
+		repo.AssertNotCalled(t, "ClaimTimeBudgetWarning", mock.Anything, mock.Anything)
+	})
+
+	t.Run("continues after completion failure", func(t *testing.T) {
+		repo := new(MockTimeBudgetRepository)
+		completer := new(MockTournamentCompleter)
+		broadcaster := new(MockBroadcaster)
+
+		alreadyDone := budgeted(3 * time.Hour)
+		overdue := budgeted(3 * time.Hour)
+		repo.On("GetActiveWithTimeBudget", mock.Anything).Return([]*domain.Tournament{alreadyDone, overdue}, nil)
+		completer.On("ForceComplete", mock.Anything, alreadyDone.ID, true).Return(nil, errors.ErrConflict.WithMessage("tournament is not active"))
+		completer.On("ForceComplete", mock.Anything, overdue.ID, true).Return(&ForceCompleteResult{}, nil)
+		broadcaster.On("Broadcast", overdue.ID, "time_budget_exceeded", mock.Anything).Return()
+
+		b := NewBudgetEnforcer(repo, completer, broadcaster, new(MockDistributedLock), time.Minute, 10*time.Minute, log)
+
+		assert.Equal(t, 1, b.EnforceDue(context.Background(), now))
+		broadcaster.AssertNotCalled(t, "Broadcast", alreadyDone.ID, mock.Anything, mock.Anything)
+	})
+}
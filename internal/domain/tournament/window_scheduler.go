@@ -0,0 +1,123 @@
+package tournament
+
+import (
+	"context"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// windowSchedulerLockKey ключ блокировки, чтобы окна запускала только одна реплика
+const windowSchedulerLockKey = "tournament:game_windows"
+
+// GameWindowRepository интерфейс для поиска и отметки окон игр турниров
+type GameWindowRepository interface {
+	GetDueGameWindows(ctx context.Context, now time.Time) ([]*domain.GameWindow, error)
+	ClaimGameWindow(ctx context.Context, tournamentID, gameID uuid.UUID, at time.Time) (bool, error)
+}
+
+// GameRoundRunner интерфейс для запуска раунда игры
+type GameRoundRunner interface {
+	RunGameMatches(ctx context.Context, tournamentID uuid.UUID, gameType string, overrideWindow bool) (int, error)
+}
+
+// WindowScheduler периодически запускает раунды игр, у которых открылось окно
+type WindowScheduler struct {
+	repo        GameWindowRepository
+	runner      GameRoundRunner
+	broadcaster Broadcaster
+	log         *logger.Logger
+	ticker      *lockedTicker
+}
+
+// NewWindowScheduler создаёт новый планировщик окон игр
+func NewWindowScheduler(
+	repo GameWindowRepository,
+	runner GameRoundRunner,
+	broadcaster Broadcaster,
+	distributedLock DistributedLock,
+	interval time.Duration,
+	log *logger.Logger,
+) *WindowScheduler {
+	ws := &WindowScheduler{
+		repo:        repo,
+		runner:      runner,
+		broadcaster: broadcaster,
+		log:         log,
+	}
+	ws.ticker = newLockedTicker(
+		"game window scheduler", windowSchedulerLockKey, distributedLock, interval, log,
+		func(ctx context.Context) error {
+			ws.RunDue(ctx, time.Now())
+			return nil
+		},
+	)
+
+	return ws
+}
+
+// Start запускает периодическую проверку
+func (ws *WindowScheduler) Start() {
+	ws.ticker.start()
+}
+
+// Stop останавливает планировщик
+func (ws *WindowScheduler) Stop() {
+	ws.ticker.stop()
+}
+
+// RunDue запускает раунды игр, окно которых открыто к моменту now.
+// Каждое окно запускается один раз: при ошибке раунд запускает администратор вручную.
+// Возвращает количество запущенных окон
+func (ws *WindowScheduler) RunDue(ctx context.Context, now time.Time) int {
+	windows, err := ws.repo.GetDueGameWindows(ctx, now)
+	if err != nil {
+		ws.log.LogError("Failed to get due game windows", err)
+		return 0
+	}
+
+	started := 0
+	for _, w := range windows {
+		claimed, err := ws.repo.ClaimGameWindow(ctx, w.TournamentID, w.GameID, now)
+		if err != nil {
+			ws.log.LogError("Failed to claim game window", err,
+				zap.String("tournament_id", w.TournamentID.String()),
+				zap.String("game_type", w.GameType),
+			)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		enqueued, err := ws.runner.RunGameMatches(ctx, w.TournamentID, w.GameType, false)
+		if err != nil {
+			ws.log.LogError("Failed to run game round for window", err,
+				zap.String("tournament_id", w.TournamentID.String()),
+				zap.String("game_type", w.GameType),
+			)
+			continue
+		}
+
+		ws.log.Info("Game window opened",
+			zap.String("tournament_id", w.TournamentID.String()),
+			zap.String("game_type", w.GameType),
+			zap.Time("window_end", w.End),
+			zap.Int("enqueued", enqueued),
+		)
+
+		ws.broadcaster.Broadcast(w.TournamentID, "game_window_opened", map[string]interface{}{
+			"game_type":    w.GameType,
+			"window_start": w.Start,
+			"window_end":   w.End,
+			"enqueued":     enqueued,
+		})
+
+		started++
+	}
+
+	return started
+}
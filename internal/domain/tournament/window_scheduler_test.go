@@ -0,0 +1,98 @@
+package tournament
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGameWindowRepository struct {
+	mock.Mock
+}
+
+func (m *MockGameWindowRepository) GetDueGameWindows(ctx context.Context, now time.Time) ([]*domain.GameWindow, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.GameWindow), args.Error(1)
+}
+
+func (m *MockGameWindowRepository) ClaimGameWindow(ctx context.Context, tournamentID, gameID uuid.UUID, at time.Time) (bool, error) {
+	args := m.Called(ctx, tournamentID, gameID, at)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockGameRoundRunner struct {
+	mock.Mock
+}
+
+func (m *MockGameRoundRunner) RunGameMatches(ctx context.Context, tournamentID uuid.UUID, gameType string, overrideWindow bool) (int, error) {
+	args := m.Called(ctx, tournamentID, gameType, overrideWindow)
+	return args.Int(0), args.Error(1)
+}
+
+func TestWindowScheduler_RunDue(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	now := time.Now()
+	window := &domain.GameWindow{
+		TournamentID: uuid.New(),
+		GameID:       uuid.New(),
+		GameType:     "prisoners_dilemma",
+		Start:        now.Add(-time.Minute),
+		End:          now.Add(time.Hour),
+	}
+
+	t.Run("runs the round and broadcasts", func(t *testing.T) {
+		repo := new(MockGameWindowRepository)
+		runner := new(MockGameRoundRunner)
+		broadcaster := new(MockBroadcaster)
+
+		repo.On("GetDueGameWindows", mock.Anything, now).Return([]*domain.GameWindow{window}, nil)
+		repo.On("ClaimGameWindow", mock.Anything, window.TournamentID, window.GameID, now).Return(true, nil)
+		runner.On("RunGameMatches", mock.Anything, window.TournamentID, "prisoners_dilemma", false).Return(6, nil)
+		broadcaster.On("Broadcast", window.TournamentID, "game_window_opened", mock.Anything).Return()
+
+		ws := NewWindowScheduler(repo, runner, broadcaster, new(MockDistributedLock), time.Minute, log)
+
+		assert.Equal(t, 1, ws.RunDue(context.Background(), now))
+		runner.AssertExpectations(t)
+		broadcaster.AssertExpectations(t)
+	})
+
+	t.Run("window claimed by another replica is skipped", func(t *testing.T) {
+		repo := new(MockGameWindowRepository)
+		runner := new(MockGameRoundRunner)
+
+		repo.On("GetDueGameWindows", mock.Anything, now).Return([]*domain.GameWindow{window}, nil)
+		repo.On("ClaimGameWindow", mock.Anything, window.TournamentID, window.GameID, now).Return(false, nil)
+
+		ws := NewWindowScheduler(repo, runner, new(MockBroadcaster), new(MockDistributedLock), time.Minute, log)
+
+		assert.Equal(t, 0, ws.RunDue(context.Background(), now))
+		runner.AssertNotCalled(t, "RunGameMatches", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed round is not broadcast", func(t *testing.T) {
+		repo := new(MockGameWindowRepository)
+		runner := new(MockGameRoundRunner)
+		broadcaster := new(MockBroadcaster)
+
+		repo.On("GetDueGameWindows", mock.Anything, now).Return([]*domain.GameWindow{window}, nil)
+		repo.On("ClaimGameWindow", mock.Anything, window.TournamentID, window.GameID, now).Return(true, nil)
+		runner.On("RunGameMatches", mock.Anything, window.TournamentID, "prisoners_dilemma", false).
+			Return(0, errors.ErrValidation.WithMessage("need at least 2 participants with programs for this game"))
+
+		ws := NewWindowScheduler(repo, runner, broadcaster, new(MockDistributedLock), time.Minute, log)
+
+		assert.Equal(t, 0, ws.RunDue(context.Background(), now))
+		broadcaster.AssertNotCalled(t, "Broadcast", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,92 @@
+package domain
+
+import "time"
+
+// ExecutionTiming временные метки запуска матча в контейнере, заполняемые executor
+type ExecutionTiming struct {
+	ContainerCreatedAt time.Time
+	StartedAt          time.Time
+	FinishedAt         time.Time
+}
+
+// MatchTrace временные метки этапов обработки матча воркером.
+// Незаполненные метки (например, матч не дошёл до контейнера) остаются нулевыми
+type MatchTrace struct {
+	DequeuedAt          time.Time `json:"dequeued_at"`
+	ContainerCreatedAt  time.Time `json:"container_created_at"`
+	ExecutionStartedAt  time.Time `json:"execution_started_at"`
+	ExecutionFinishedAt time.Time `json:"execution_finished_at"`
+	ResultPersistedAt   time.Time `json:"result_persisted_at"`
+}
+
+// Этапы обработки матча
+const (
+	TracePhasePrepare   = "prepare"   // От извлечения из очереди до создания контейнера: программы, компиляция
+	TracePhaseStart     = "start"     // Запуск контейнера
+	TracePhaseExecution = "execution" // Игра программ в контейнере
+	TracePhasePersist   = "persist"   // Сохранение результата в БД
+)
+
+// TracePhase длительность этапа обработки матча
+type TracePhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// SetExecution копирует в трассу метки, полученные от executor
+func (t *MatchTrace) SetExecution(timing ExecutionTiming) {
+	t.ContainerCreatedAt = timing.ContainerCreatedAt
+	t.ExecutionStartedAt = timing.StartedAt
+	t.ExecutionFinishedAt = timing.FinishedAt
+}
+
+// Phases возвращает длительности этапов, у которых известны обе границы
+func (t *MatchTrace) Phases() []TracePhase {
+	bounds := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{TracePhasePrepare, t.DequeuedAt, t.ContainerCreatedAt},
+		{TracePhaseStart, t.ContainerCreatedAt, t.ExecutionStartedAt},
+		{TracePhaseExecution, t.ExecutionStartedAt, t.ExecutionFinishedAt},
+		{TracePhasePersist, t.ExecutionFinishedAt, t.ResultPersistedAt},
+	}
+
+	phases := make([]TracePhase, 0, len(bounds))
+	for _, b := range bounds {
+		if b.start.IsZero() || b.end.IsZero() || b.end.Before(b.start) {
+			continue
+		}
+		phases = append(phases, TracePhase{Name: b.name, Duration: b.end.Sub(b.start)})
+	}
+	return phases
+}
+
+// MatchTiming длительности этапов обработки матча в миллисекундах: показывает,
+// ждал ли матч в очереди или долго выполнялся. Неизвестные этапы равны нулю
+type MatchTiming struct {
+	QueueWaitMs int64 `json:"queue_wait_ms"` // От постановки в очередь до извлечения воркером
+	PrepareMs   int64 `json:"prepare_ms"`    // Получение и компиляция программ
+	StartMs     int64 `json:"start_ms"`      // Запуск контейнера
+	ExecutionMs int64 `json:"execution_ms"`  // Игра программ в контейнере
+	PersistMs   int64 `json:"persist_ms"`    // Сохранение результата
+}
+
+// Timing сводит трассу и ожидание в очереди в длительности этапов
+func (t *MatchTrace) Timing(queueWait time.Duration) *MatchTiming {
+	timing := &MatchTiming{QueueWaitMs: queueWait.Milliseconds()}
+	for _, phase := range t.Phases() {
+		ms := phase.Duration.Milliseconds()
+		switch phase.Name {
+		case TracePhasePrepare:
+			timing.PrepareMs = ms
+		case TracePhaseStart:
+			timing.StartMs = ms
+		case TracePhaseExecution:
+			timing.ExecutionMs = ms
+		case TracePhasePersist:
+			timing.PersistMs = ms
+		}
+	}
+	return timing
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchTrace_Phases(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	trace := MatchTrace{DequeuedAt: base}
+	trace.SetExecution(ExecutionTiming{
+		ContainerCreatedAt: base.Add(2 * time.Second),
+		StartedAt:          base.Add(2500 * time.Millisecond),
+		FinishedAt:         base.Add(10 * time.Second),
+	})
+	trace.ResultPersistedAt = base.Add(10*time.Second + 30*time.Millisecond)
+
+	assert.Equal(t, []TracePhase{
+		{TracePhasePrepare, 2 * time.Second},
+		{TracePhaseStart, 500 * time.Millisecond},
+		{TracePhaseExecution, 7500 * time.Millisecond},
+		{TracePhasePersist, 30 * time.Millisecond},
+	}, trace.Phases())
+}
+
+func TestMatchTrace_PhasesSkipUnknownBounds(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	// The executor reported no timing: only the phases it does not bound are missing
+	trace := MatchTrace{DequeuedAt: base, ResultPersistedAt: base.Add(time.Second)}
+
+	assert.Empty(t, trace.Phases())
+}
+
+func TestMatchTrace_Timing(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	trace := MatchTrace{DequeuedAt: base}
+	trace.SetExecution(ExecutionTiming{
+		ContainerCreatedAt: base.Add(2 * time.Second),
+		StartedAt:          base.Add(2500 * time.Millisecond),
+		FinishedAt:         base.Add(10 * time.Second),
+	})
+	trace.ResultPersistedAt = base.Add(10*time.Second + 30*time.Millisecond)
+
+	assert.Equal(t, &MatchTiming{
+		QueueWaitMs: 45000,
+		PrepareMs:   2000,
+		StartMs:     500,
+		ExecutionMs: 7500,
+		PersistMs:   30,
+	}, trace.Timing(45*time.Second))
+
+	// A match that never reached the container only reports its queue wait
+	failed := MatchTrace{DequeuedAt: base, ResultPersistedAt: base.Add(time.Second)}
+	assert.Equal(t, &MatchTiming{QueueWaitMs: 1000}, failed.Timing(time.Second))
+}
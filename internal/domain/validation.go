@@ -86,11 +86,34 @@ func (t *Tournament) Validate() error {
 		errs = append(errs, err.(*validator.ValidationError))
 	}
 
+	// Валидация видимости
+	validVisibilities := []string{
+		string(VisibilityPublic),
+		string(VisibilityUnlisted),
+		string(VisibilityPrivate),
+	}
+	if err := validator.ValidateEnum("visibility", string(t.Visibility), validVisibilities); err != nil {
+		errs = append(errs, err.(*validator.ValidationError))
+	}
+
 	// Валидация max_participants
 	if t.MaxParticipants != nil && *t.MaxParticipants <= 0 {
 		errs.Add("max_participants", "max_participants must be positive")
 	}
 
+	if _, err := parseTimeBudget(t.Metadata[MetaTimeBudget]); err != nil {
+		errs.Add("metadata."+MetaTimeBudget, err.Error())
+	}
+
+	if _, err := parseTieBreakRules(t.Metadata[MetaTieBreak]); err != nil {
+		errs.Add("metadata."+MetaTieBreak, err.Error())
+	}
+
+	if _, err := parsePointsScoring(t.Metadata[MetaPointsScoring]); err != nil {
+		errs.Add("metadata."+MetaPointsScoring, err.Error())
+	}
+	t.validateBracketSettings(&errs)
+
 	if errs.HasErrors() {
 		return errs
 	}
@@ -107,6 +130,7 @@ func (m *Match) Validate() error {
 		string(MatchRunning),
 		string(MatchCompleted),
 		string(MatchFailed),
+		string(MatchCancelled),
 	}
 	if err := validator.ValidateEnum("status", string(m.Status), validStatuses); err != nil {
 		errs = append(errs, err.(*validator.ValidationError))
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEvent событие турнира, о котором сообщается внешней системе
+type WebhookEvent string
+
+const (
+	WebhookTournamentStarted   WebhookEvent = "tournament.started"   // Турнир запущен
+	WebhookTournamentCompleted WebhookEvent = "tournament.completed" // Турнир завершён, в событии итоговая таблица
+	WebhookTournamentCancelled WebhookEvent = "tournament.cancelled" // Турнир отменён
+	WebhookRoundCompleted      WebhookEvent = "round.completed"      // Сыграны все матчи раунда игры
+)
+
+// WebhookEvents все события webhook'ов
+var WebhookEvents = []WebhookEvent{
+	WebhookTournamentStarted,
+	WebhookTournamentCompleted,
+	WebhookTournamentCancelled,
+	WebhookRoundCompleted,
+}
+
+// IsValid проверяет, что событие известно
+func (e WebhookEvent) IsValid() bool {
+	for _, event := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook адрес, на который отправляются события турнира.
+// Secret подписывает тело запроса и показывается только при создании
+type Webhook struct {
+	ID           uuid.UUID      `json:"id"`
+	TournamentID uuid.UUID      `json:"tournament_id"`
+	URL          string         `json:"url"`
+	Secret       string         `json:"-"`
+	Events       []WebhookEvent `json:"events"`
+	CreatedBy    *uuid.UUID     `json:"created_by,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// WebhookDelivery доставка события на webhook (запись outbox уведомлений)
+type WebhookDelivery struct {
+	ID            int64              `json:"id" db:"id"`
+	Event         WebhookEvent       `json:"event" db:"event"`
+	Status        NotificationStatus `json:"status" db:"status"`
+	Attempts      int                `json:"attempts" db:"attempts"`
+	NextAttemptAt *time.Time         `json:"next_attempt_at,omitempty" db:"next_attempt_at"` // Только для pending
+	LastError     *string            `json:"last_error,omitempty" db:"last_error"`
+	Payload       json.RawMessage    `json:"payload" db:"payload"`
+	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
+	SentAt        *time.Time         `json:"sent_at,omitempty" db:"sent_at"`
+}
@@ -0,0 +1,302 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxWebhooksPerTournament = 10
+	maxURLLength             = 2048
+	minSecretLength          = 16
+	maxSecretLength          = 255
+	leaderboardLimit         = 100 // Мест итоговой таблицы в tournament.completed
+)
+
+// Repository интерфейс хранения webhook'ов и их доставок
+type Repository interface {
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error)
+	ListByTournament(ctx context.Context, tournamentID uuid.UUID) ([]*domain.Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	HasSubscribers(ctx context.Context, tournamentID uuid.UUID, event domain.WebhookEvent) (bool, error)
+	EnqueueDeliveries(ctx context.Context, tournamentID uuid.UUID, event domain.WebhookEvent, payload []byte, dedupeKey string) (int64, error)
+	ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error)
+}
+
+// TournamentRepository интерфейс данных турнира для событий
+type TournamentRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tournament, error)
+	GetLeaderboard(ctx context.Context, tournamentID uuid.UUID, limit int) ([]*domain.LeaderboardEntry, error)
+}
+
+// Service - сервис webhook'ов турниров. События записываются в outbox уведомлений,
+// доставку с подписью и повторами выполняет notify.Dispatcher в worker'е
+type Service struct {
+	repo        Repository
+	tournaments TournamentRepository
+	log         *logger.Logger
+}
+
+// NewService создаёт новый сервис webhook'ов
+func NewService(repo Repository, tournaments TournamentRepository, log *logger.Logger) *Service {
+	return &Service{
+		repo:        repo,
+		tournaments: tournaments,
+		log:         log,
+	}
+}
+
+// CreateRequest - запрос на регистрацию webhook'а.
+// Без Secret он генерируется, без Events webhook подписывается на все события
+type CreateRequest struct {
+	URL    string                `json:"url"`
+	Secret string                `json:"secret,omitempty"`
+	Events []domain.WebhookEvent `json:"events,omitempty"`
+}
+
+// Create регистрирует webhook турнира. Возвращённый webhook содержит секрет,
+// больше он нигде не показывается
+func (s *Service) Create(ctx context.Context, tournamentID, createdBy uuid.UUID, req *CreateRequest) (*domain.Webhook, error) {
+	if err := validateURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+	} else if len(secret) < minSecretLength || len(secret) > maxSecretLength {
+		return nil, errors.ErrValidation.WithMessage(
+			fmt.Sprintf("secret must be from %d to %d characters", minSecretLength, maxSecretLength))
+	}
+
+	existing, err := s.repo.ListByTournament(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxWebhooksPerTournament {
+		return nil, errors.ErrConflict.WithMessage(
+			fmt.Sprintf("tournament already has %d webhooks", maxWebhooksPerTournament))
+	}
+
+	webhook := &domain.Webhook{
+		ID:           uuid.New(),
+		TournamentID: tournamentID,
+		URL:          req.URL,
+		Secret:       secret,
+		Events:       events,
+		CreatedBy:    &createdBy,
+	}
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Webhook registered",
+		zap.String("tournament_id", tournamentID.String()),
+		zap.String("webhook_id", webhook.ID.String()),
+	)
+
+	return webhook, nil
+}
+
+// List возвращает webhook'и турнира
+func (s *Service) List(ctx context.Context, tournamentID uuid.UUID) ([]*domain.Webhook, error) {
+	return s.repo.ListByTournament(ctx, tournamentID)
+}
+
+// Delete удаляет webhook турнира
+func (s *Service) Delete(ctx context.Context, tournamentID, webhookID uuid.UUID) error {
+	if _, err := s.get(ctx, tournamentID, webhookID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, webhookID)
+}
+
+// Deliveries возвращает последние доставки webhook'а турнира с ошибками попыток
+func (s *Service) Deliveries(ctx context.Context, tournamentID, webhookID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	if _, err := s.get(ctx, tournamentID, webhookID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, webhookID, limit)
+}
+
+// get возвращает webhook, только если он принадлежит турниру
+func (s *Service) get(ctx context.Context, tournamentID, webhookID uuid.UUID) (*domain.Webhook, error) {
+	webhook, err := s.repo.GetByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.TournamentID != tournamentID {
+		return nil, errors.ErrNotFound.WithMessage("webhook not found")
+	}
+	return webhook, nil
+}
+
+// Payload тело запроса, отправляемого на webhook
+type Payload struct {
+	Event       domain.WebhookEvent        `json:"event"`
+	OccurredAt  time.Time                  `json:"occurred_at"`
+	Tournament  TournamentInfo             `json:"tournament"`
+	GameType    string                     `json:"game_type,omitempty"`   // Для round.completed
+	Leaderboard []*domain.LeaderboardEntry `json:"leaderboard,omitempty"` // Для tournament.completed
+}
+
+// TournamentInfo сведения о турнире в событии
+type TournamentInfo struct {
+	ID       uuid.UUID               `json:"id"`
+	Code     string                  `json:"code"`
+	Name     string                  `json:"name"`
+	GameType string                  `json:"game_type"`
+	Status   domain.TournamentStatus `json:"status"`
+}
+
+// TournamentStarted сообщает webhook'ам о старте турнира
+func (s *Service) TournamentStarted(ctx context.Context, tournament *domain.Tournament) error {
+	payload := s.newPayload(domain.WebhookTournamentStarted, tournament)
+	return s.enqueue(ctx, payload, fmt.Sprintf("%s:%s", payload.Event, tournament.ID))
+}
+
+// TournamentCompleted сообщает webhook'ам о завершении турнира с итоговой таблицей
+func (s *Service) TournamentCompleted(ctx context.Context, tournament *domain.Tournament) error {
+	event := domain.WebhookTournamentCompleted
+	subscribed, err := s.repo.HasSubscribers(ctx, tournament.ID, event)
+	if err != nil || !subscribed {
+		return err
+	}
+
+	leaderboard, err := s.tournaments.GetLeaderboard(ctx, tournament.ID, leaderboardLimit)
+	if err != nil {
+		return err
+	}
+
+	payload := s.newPayload(event, tournament)
+	payload.Leaderboard = leaderboard
+	return s.enqueue(ctx, payload, fmt.Sprintf("%s:%s", event, tournament.ID))
+}
+
+// TournamentCancelled сообщает webhook'ам об отмене турнира
+func (s *Service) TournamentCancelled(ctx context.Context, tournament *domain.Tournament) error {
+	payload := s.newPayload(domain.WebhookTournamentCancelled, tournament)
+	return s.enqueue(ctx, payload, fmt.Sprintf("%s:%s", payload.Event, tournament.ID))
+}
+
+// RoundCompleted сообщает webhook'ам о том, что сыграны все матчи раунда игры
+func (s *Service) RoundCompleted(ctx context.Context, tournamentID uuid.UUID, gameType string) error {
+	event := domain.WebhookRoundCompleted
+	subscribed, err := s.repo.HasSubscribers(ctx, tournamentID, event)
+	if err != nil || !subscribed {
+		return err
+	}
+
+	tournament, err := s.tournaments.GetByID(ctx, tournamentID)
+	if err != nil {
+		return err
+	}
+
+	payload := s.newPayload(event, tournament)
+	payload.GameType = gameType
+	// Раунд игры завершается один раз, а номера раундов сбрасываются вместе с игрой,
+	// поэтому событие различается по времени завершения
+	dedupeKey := fmt.Sprintf("%s:%s:%s:%d", event, tournamentID, gameType, payload.OccurredAt.UnixNano())
+	return s.enqueue(ctx, payload, dedupeKey)
+}
+
+func (s *Service) newPayload(event domain.WebhookEvent, tournament *domain.Tournament) *Payload {
+	return &Payload{
+		Event:      event,
+		OccurredAt: time.Now().UTC(),
+		Tournament: TournamentInfo{
+			ID:       tournament.ID,
+			Code:     tournament.Code,
+			Name:     tournament.Name,
+			GameType: tournament.GameType,
+			Status:   tournament.Status,
+		},
+	}
+}
+
+func (s *Service) enqueue(ctx context.Context, payload *Payload, dedupeKey string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	enqueued, err := s.repo.EnqueueDeliveries(ctx, payload.Tournament.ID, payload.Event, body, dedupeKey)
+	if err != nil {
+		return err
+	}
+	if enqueued > 0 {
+		s.log.Info("Webhook deliveries enqueued",
+			zap.String("event", string(payload.Event)),
+			zap.Int64("webhooks", enqueued),
+			zap.String("tournament_id", payload.Tournament.ID.String()),
+		)
+	}
+	return nil
+}
+
+// validateURL допускает только абсолютные http(s) адреса
+func validateURL(raw string) error {
+	if raw == "" {
+		return errors.ErrValidation.WithMessage("url is required")
+	}
+	if len(raw) > maxURLLength {
+		return errors.ErrValidation.WithMessage(fmt.Sprintf("url must be at most %d characters", maxURLLength))
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.ErrValidation.WithMessage("url must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return errors.ErrValidation.WithMessage("url must not contain credentials")
+	}
+	return nil
+}
+
+// normalizeEvents проверяет события и убирает повторы. Пустой список - все события
+func normalizeEvents(events []domain.WebhookEvent) ([]domain.WebhookEvent, error) {
+	if len(events) == 0 {
+		return append([]domain.WebhookEvent(nil), domain.WebhookEvents...), nil
+	}
+
+	normalized := make([]domain.WebhookEvent, 0, len(events))
+	seen := make(map[domain.WebhookEvent]bool, len(events))
+	for _, event := range events {
+		if !event.IsValid() {
+			return nil, errors.ErrValidation.WithMessage(fmt.Sprintf("unknown webhook event: %s", event))
+		}
+		if !seen[event] {
+			seen[event] = true
+			normalized = append(normalized, event)
+		}
+	}
+	return normalized, nil
+}
+
+// generateSecret генерирует случайный секрет подписи
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+)
+
+// apiKeyTTL время жизни записи: отозванный ключ перестаёт работать не позже чем через TTL,
+// даже если сброс кэша при отзыве не удался
+const apiKeyTTL = 1 * time.Minute
+
+// APIKeyCache кэширует пользователя API ключа, чтобы не ходить в БД на каждый запрос
+type APIKeyCache struct {
+	cache *Cache
+	ttl   time.Duration
+}
+
+// NewAPIKeyCache создаёт новый кэш API ключей
+func NewAPIKeyCache(cache *Cache) *APIKeyCache {
+	return &APIKeyCache{
+		cache: cache,
+		ttl:   apiKeyTTL,
+	}
+}
+
+// getKey возвращает ключ кэша по хэшу API ключа
+func (ac *APIKeyCache) getKey(keyHash string) string {
+	return fmt.Sprintf("apikey:%s", keyHash)
+}
+
+// Get возвращает пользователя ключа или nil при промахе
+func (ac *APIKeyCache) Get(ctx context.Context, keyHash string) (*domain.APIKeyIdentity, error) {
+	data, err := ac.cache.Get(ctx, ac.getKey(keyHash))
+	if err != nil {
+		return nil, err
+	}
+
+	if data == "" {
+		return nil, nil // кэш промах
+	}
+
+	var identity domain.APIKeyIdentity
+	if err := json.Unmarshal([]byte(data), &identity); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal api key identity: %w", err)
+	}
+
+	return &identity, nil
+}
+
+// Set сохраняет пользователя ключа в кэш
+func (ac *APIKeyCache) Set(ctx context.Context, keyHash string, identity *domain.APIKeyIdentity) error {
+	data, err := json.Marshal(identity)
+	if err != nil {
+		return fmt.Errorf("failed to marshal api key identity: %w", err)
+	}
+
+	return ac.cache.Set(ctx, ac.getKey(keyHash), data, ac.ttl)
+}
+
+// Delete удаляет ключ из кэша при отзыве
+func (ac *APIKeyCache) Delete(ctx context.Context, keyHash string) error {
+	return ac.cache.Del(ctx, ac.getKey(keyHash))
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/config"
@@ -36,6 +37,8 @@ func New(cfg *config.RedisConfig, log *logger.Logger, m *metrics.Metrics) (*Cach
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
+	client.AddHook(tracingHook{})
+
 	log.Info("Redis connected successfully",
 		zap.String("addr", cfg.Address()),
 		zap.Int("db", cfg.DB),
@@ -138,6 +141,20 @@ func (c *Cache) ZRevRangeWithScores(ctx context.Context, key string, start, stop
 	return result, nil
 }
 
+// ZRevRank возвращает позицию элемента в sorted set по убыванию score (с нуля).
+// found = false, если элемента нет
+func (c *Cache) ZRevRank(ctx context.Context, key, member string) (int64, bool, error) {
+	rank, err := c.client.ZRevRank(ctx, key, member).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		c.log.LogError("Redis ZREVRANK failed", err, zap.String("key", key))
+		return 0, false, err
+	}
+	return rank, true, nil
+}
+
 // ZIncrBy увеличивает score элемента в sorted set
 func (c *Cache) ZIncrBy(ctx context.Context, key string, increment float64, member string) error {
 	err := c.client.ZIncrBy(ctx, key, increment, member).Err()
@@ -158,6 +175,56 @@ func (c *Cache) ZRem(ctx context.Context, key string, members ...string) error {
 	return nil
 }
 
+// ZRangeByScore получает до limit элементов sorted set со score в диапазоне [min, max]
+func (c *Cache) ZRangeByScore(ctx context.Context, key string, min, max float64, limit int64) ([]string, error) {
+	result, err := c.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min:   strconv.FormatFloat(min, 'f', -1, 64),
+		Max:   strconv.FormatFloat(max, 'f', -1, 64),
+		Count: limit,
+	}).Result()
+
+	if err != nil && err != redis.Nil {
+		c.log.LogError("Redis ZRANGEBYSCORE failed", err, zap.String("key", key))
+		return nil, err
+	}
+	return result, nil
+}
+
+// ZRemWithCount удаляет элементы из sorted set и возвращает количество удалённых
+// Позволяет нескольким конкурентам безопасно "забрать" элемент: удалит его только один
+func (c *Cache) ZRemWithCount(ctx context.Context, key string, members ...string) (int64, error) {
+	removed, err := c.client.ZRem(ctx, key, members).Result()
+	if err != nil {
+		c.log.LogError("Redis ZREM failed", err, zap.String("key", key))
+		return 0, err
+	}
+	return removed, nil
+}
+
+// zMoveToListScript атомарно переносит элемент из sorted set в начало списка
+// Элемент попадает в список, только если этот вызов удалил его из sorted set
+var zMoveToListScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('LPUSH', KEYS[2], ARGV[1])
+return 1
+`)
+
+// ZMoveToList атомарно переносит элемент из sorted set в начало списка
+// Возвращает false, если элемента уже нет в sorted set (его забрал другой конкурент)
+func (c *Cache) ZMoveToList(ctx context.Context, zsetKey, member, listKey string) (bool, error) {
+	moved, err := zMoveToListScript.Run(ctx, c.client, []string{zsetKey, listKey}, member).Int()
+	if err != nil {
+		c.log.LogError("Redis ZREM+LPUSH script failed", err,
+			zap.String("zset_key", zsetKey),
+			zap.String("list_key", listKey),
+		)
+		return false, err
+	}
+	return moved == 1, nil
+}
+
 // LPush добавляет элемент в начало списка
 func (c *Cache) LPush(ctx context.Context, key string, values ...interface{}) error {
 	err := c.client.LPush(ctx, key, values...).Err()
@@ -168,6 +235,46 @@ func (c *Cache) LPush(ctx context.Context, key string, values ...interface{}) er
 	return nil
 }
 
+// RPush добавляет элемент в конец списка
+func (c *Cache) RPush(ctx context.Context, key string, values ...interface{}) error {
+	err := c.client.RPush(ctx, key, values...).Err()
+	if err != nil {
+		c.log.LogError("Redis RPUSH failed", err, zap.String("key", key))
+		return err
+	}
+	return nil
+}
+
+// LTrim оставляет в списке только элементы в диапазоне [start, stop]
+func (c *Cache) LTrim(ctx context.Context, key string, start, stop int64) error {
+	err := c.client.LTrim(ctx, key, start, stop).Err()
+	if err != nil {
+		c.log.LogError("Redis LTRIM failed", err, zap.String("key", key))
+		return err
+	}
+	return nil
+}
+
+// Incr увеличивает счётчик на 1 и возвращает новое значение
+func (c *Cache) Incr(ctx context.Context, key string) (int64, error) {
+	val, err := c.client.Incr(ctx, key).Result()
+	if err != nil {
+		c.log.LogError("Redis INCR failed", err, zap.String("key", key))
+		return 0, err
+	}
+	return val, nil
+}
+
+// LRem удаляет до count вхождений value из списка и возвращает число удалённых
+func (c *Cache) LRem(ctx context.Context, key string, count int64, value string) (int64, error) {
+	removed, err := c.client.LRem(ctx, key, count, value).Result()
+	if err != nil {
+		c.log.LogError("Redis LREM failed", err, zap.String("key", key))
+		return 0, err
+	}
+	return removed, nil
+}
+
 // RPop удаляет и возвращает последний элемент списка
 func (c *Cache) RPop(ctx context.Context, key string) (string, error) {
 	val, err := c.client.RPop(ctx, key).Result()
@@ -2,13 +2,19 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/pkg/metrics"
 	"github.com/google/uuid"
 )
 
+// teamLeaderboardTTL время жизни рейтинга команд: он пересчитывается целиком,
+// поэтому не обновляется по матчам, а просто устаревает
+const teamLeaderboardTTL = 60 * time.Second
+
 // LeaderboardCache - кэш для таблицы лидеров
 type LeaderboardCache struct {
 	cache   *Cache
@@ -84,6 +90,19 @@ func (lc *LeaderboardCache) GetTop(ctx context.Context, tournamentID uuid.UUID,
 	return entries, nil
 }
 
+// GetRank возвращает место программы в leaderboard (с единицы) за O(log N) без чтения всей таблицы.
+// Место определяется только рейтингом; 0 - программы нет в leaderboard турнира
+func (lc *LeaderboardCache) GetRank(ctx context.Context, tournamentID, programID uuid.UUID) (int64, error) {
+	rank, found, err := lc.cache.ZRevRank(ctx, lc.getKey(tournamentID), programID.String())
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, nil
+	}
+	return rank + 1, nil
+}
+
 // Remove удаляет программу из leaderboard
 func (lc *LeaderboardCache) Remove(ctx context.Context, tournamentID, programID uuid.UUID) error {
 	key := lc.getKey(tournamentID)
@@ -95,3 +114,44 @@ func (lc *LeaderboardCache) Clear(ctx context.Context, tournamentID uuid.UUID) e
 	key := lc.getKey(tournamentID)
 	return lc.cache.Del(ctx, key)
 }
+
+// getTeamKey возвращает ключ для рейтинга команд турнира
+func (lc *LeaderboardCache) getTeamKey(tournamentID uuid.UUID) string {
+	return fmt.Sprintf("team_leaderboard:%s", tournamentID.String())
+}
+
+// SetTeamRatings сохраняет рейтинг команд турнира
+func (lc *LeaderboardCache) SetTeamRatings(ctx context.Context, tournamentID uuid.UUID, ratings []*domain.TeamRating) error {
+	data, err := json.Marshal(ratings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal team ratings: %w", err)
+	}
+
+	return lc.cache.Set(ctx, lc.getTeamKey(tournamentID), data, teamLeaderboardTTL)
+}
+
+// GetTeamRatings получает рейтинг команд турнира из кэша (промах - nil)
+func (lc *LeaderboardCache) GetTeamRatings(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TeamRating, error) {
+	data, err := lc.cache.Get(ctx, lc.getTeamKey(tournamentID))
+	if err != nil {
+		return nil, err
+	}
+
+	if data == "" {
+		if lc.metrics != nil {
+			lc.metrics.RecordCacheMiss("team_leaderboard")
+		}
+		return nil, nil
+	}
+
+	if lc.metrics != nil {
+		lc.metrics.RecordCacheHit("team_leaderboard")
+	}
+
+	var ratings []*domain.TeamRating
+	if err := json.Unmarshal([]byte(data), &ratings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal team ratings: %w", err)
+	}
+
+	return ratings, nil
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	// liveLogTTL сколько хранится журнал трансляции после последнего события
+	liveLogTTL = 24 * time.Hour
+	// liveLogLimit сколько последних событий матча хранится для повтора
+	liveLogLimit = 5000
+)
+
+// LiveMatchFeed - трансляция идущих матчей через Redis pub/sub.
+// События дублируются в журнал: подключившийся позже зритель получает
+// пропущенное из него, а после завершения матча - весь матч
+type LiveMatchFeed struct {
+	cache *Cache
+}
+
+// NewLiveMatchFeed создаёт трансляцию матчей
+func NewLiveMatchFeed(cache *Cache) *LiveMatchFeed {
+	return &LiveMatchFeed{cache: cache}
+}
+
+func (f *LiveMatchFeed) channel(matchID uuid.UUID) string {
+	return fmt.Sprintf("match:live:%s", matchID.String())
+}
+
+func (f *LiveMatchFeed) logKey(matchID uuid.UUID) string {
+	return fmt.Sprintf("match:live:%s:log", matchID.String())
+}
+
+func (f *LiveMatchFeed) seqKey(matchID uuid.UUID) string {
+	return fmt.Sprintf("match:live:%s:seq", matchID.String())
+}
+
+// PublishMatchEvent назначает событию номер, записывает его в журнал и рассылает подписчикам.
+// Событие start очищает журнал: повторное выполнение матча транслируется заново
+func (f *LiveMatchFeed) PublishMatchEvent(ctx context.Context, matchID uuid.UUID, event *domain.MatchEvent) error {
+	logKey := f.logKey(matchID)
+	if event.Type == domain.MatchEventStart {
+		if err := f.cache.Del(ctx, logKey); err != nil {
+			return err
+		}
+	}
+
+	seq, err := f.cache.Incr(ctx, f.seqKey(matchID))
+	if err != nil {
+		return err
+	}
+	if err := f.cache.Expire(ctx, f.seqKey(matchID), liveLogTTL); err != nil {
+		return err
+	}
+	event.Seq = seq
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal match event: %w", err)
+	}
+
+	if err := f.cache.RPush(ctx, logKey, data); err != nil {
+		return err
+	}
+	if err := f.cache.LTrim(ctx, logKey, -liveLogLimit, -1); err != nil {
+		return err
+	}
+	if err := f.cache.Expire(ctx, logKey, liveLogTTL); err != nil {
+		return err
+	}
+
+	return f.cache.Publish(ctx, f.channel(matchID), data)
+}
+
+// Log возвращает журнал трансляции матча. Пустой журнал - матч не транслировался или журнал истёк
+func (f *LiveMatchFeed) Log(ctx context.Context, matchID uuid.UUID) ([]*domain.MatchEvent, error) {
+	items, err := f.cache.LRange(ctx, f.logKey(matchID), 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*domain.MatchEvent, 0, len(items))
+	for _, item := range items {
+		var event domain.MatchEvent
+		if err := json.Unmarshal([]byte(item), &event); err != nil {
+			continue
+		}
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+// Subscribe подписывается на события матча. Подписка активна к моменту возврата,
+// поэтому журнал, прочитанный после неё, не пропускает событий.
+// Если подписчик не успевает читать и буфер из buffer событий заполнен, канал закрывается:
+// клиент переподключается и получает пропущенное из журнала
+func (f *LiveMatchFeed) Subscribe(ctx context.Context, matchID uuid.UUID, buffer int) (<-chan *domain.MatchEvent, func(), error) {
+	pubsub := f.cache.Subscribe(ctx, f.channel(matchID))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, nil, fmt.Errorf("failed to subscribe to match events: %w", err)
+	}
+
+	events := make(chan *domain.MatchEvent, buffer)
+	go func() {
+		defer close(events)
+		for msg := range pubsub.Channel() {
+			var event domain.MatchEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			select {
+			case events <- &event:
+			default:
+				_ = pubsub.Close()
+				return
+			}
+		}
+	}()
+
+	return events, func() { _ = pubsub.Close() }, nil
+}
@@ -18,44 +18,109 @@ func NewRateLimiter(cache *Cache) *RateLimiter {
 	}
 }
 
+// RateLimitStatus - состояние текущего окна rate limiting для ключа
+type RateLimitStatus struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// windowKey ключ, в котором хранятся лимит и время окончания окна ("limit:reset_unix_nano")
+func windowKey(key string) string {
+	return key + ":window"
+}
+
 // Allow проверяет, разрешён ли запрос для данного ключа
-// Использует алгоритм fixed window counter
+// Использует алгоритм fixed window counter: окно начинается с первого запроса и не продлевается
 func (rl *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
-	// Получаем текущее количество запросов
-	current, err := rl.cache.Get(ctx, key)
+	status, count, err := rl.getStatus(ctx, key)
 	if err != nil {
-		return false, fmt.Errorf("failed to get rate limit counter: %w", err)
+		return false, err
 	}
 
-	// Если ключа нет, это первый запрос
-	if current == "" {
-		// Устанавливаем счётчик в 1 с TTL равным окну
+	// Окна нет или оно истекло - это первый запрос нового окна
+	now := time.Now()
+	if status == nil || !status.ResetAt.After(now) {
+		resetAt := now.Add(window)
 		if err := rl.cache.Set(ctx, key, 1, window); err != nil {
 			return false, fmt.Errorf("failed to set rate limit counter: %w", err)
 		}
+		if err := rl.cache.Set(ctx, windowKey(key), formatWindow(limit, resetAt), window); err != nil {
+			return false, fmt.Errorf("failed to set rate limit window: %w", err)
+		}
 		return true, nil
 	}
 
-	// Парсим текущее значение
-	count := 0
-	if current != "" {
-		_, _ = fmt.Sscanf(current, "%d", &count)
-	}
-
 	// Проверяем лимит
 	if count >= limit {
 		return false, nil
 	}
 
-	// Увеличиваем счётчик
-	newCount := count + 1
-	if err := rl.cache.Set(ctx, key, newCount, window); err != nil {
+	// Увеличиваем счётчик, сохраняя время окончания окна
+	ttl := status.ResetAt.Sub(now)
+	if err := rl.cache.Set(ctx, key, count+1, ttl); err != nil {
 		return false, fmt.Errorf("failed to increment rate limit counter: %w", err)
 	}
+	if status.Limit != limit {
+		if err := rl.cache.Set(ctx, windowKey(key), formatWindow(limit, status.ResetAt), ttl); err != nil {
+			return false, fmt.Errorf("failed to update rate limit window: %w", err)
+		}
+	}
 
 	return true, nil
 }
 
+// GetStatus возвращает состояние текущего окна для ключа
+// Возвращает nil, если окно не открыто (запросов ещё не было или окно истекло)
+func (rl *RateLimiter) GetStatus(ctx context.Context, key string) (*RateLimitStatus, error) {
+	status, _, err := rl.getStatus(ctx, key)
+	return status, err
+}
+
+// getStatus читает окно и счётчик запросов
+func (rl *RateLimiter) getStatus(ctx context.Context, key string) (*RateLimitStatus, int, error) {
+	meta, err := rl.cache.Get(ctx, windowKey(key))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get rate limit window: %w", err)
+	}
+	if meta == "" {
+		return nil, 0, nil
+	}
+
+	var limit int
+	var resetAt int64
+	if _, err := fmt.Sscanf(meta, "%d:%d", &limit, &resetAt); err != nil {
+		// Повреждённое значение - считаем, что окна нет, и открываем новое
+		return nil, 0, nil
+	}
+
+	current, err := rl.cache.Get(ctx, key)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get rate limit counter: %w", err)
+	}
+
+	count := 0
+	if current != "" {
+		_, _ = fmt.Sscanf(current, "%d", &count)
+	}
+
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &RateLimitStatus{
+		Limit:     limit,
+		Remaining: remaining,
+		ResetAt:   time.Unix(0, resetAt),
+	}, count, nil
+}
+
+// formatWindow сериализует лимит и время окончания окна
+func formatWindow(limit int, resetAt time.Time) string {
+	return fmt.Sprintf("%d:%d", limit, resetAt.UnixNano())
+}
+
 // AllowWithIncr проверяет лимит используя Redis INCR (более эффективно)
 func (rl *RateLimiter) AllowWithIncr(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
 	// Используем SetNX для установки начального значения
@@ -64,11 +129,14 @@ func (rl *RateLimiter) AllowWithIncr(ctx context.Context, key string, limit int,
 		return false, fmt.Errorf("failed to init rate limit counter: %w", err)
 	}
 
-	// Если ключ был установлен, устанавливаем TTL
+	// Если ключ был установлен, устанавливаем TTL и запоминаем окно
 	if set {
 		if err := rl.cache.Expire(ctx, key, window); err != nil {
 			return false, fmt.Errorf("failed to set rate limit TTL: %w", err)
 		}
+		if err := rl.cache.Set(ctx, windowKey(key), formatWindow(limit, time.Now().Add(window)), window); err != nil {
+			return false, fmt.Errorf("failed to set rate limit window: %w", err)
+		}
 	}
 
 	// Получаем текущее значение
@@ -98,5 +166,5 @@ func (rl *RateLimiter) AllowWithIncr(ctx context.Context, key string, limit int,
 
 // Reset сбрасывает счётчик для ключа
 func (rl *RateLimiter) Reset(ctx context.Context, key string) error {
-	return rl.cache.Del(ctx, key)
+	return rl.cache.Del(ctx, key, windowKey(key))
 }
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_GetStatus(t *testing.T) {
+	cache := setupTestCache(t)
+	defer cache.Close()
+
+	limiter := NewRateLimiter(cache)
+	ctx := context.Background()
+
+	t.Run("no window before first request", func(t *testing.T) {
+		key := "ratelimit:test-status-empty"
+		defer func() { _ = limiter.Reset(ctx, key) }()
+
+		status, err := limiter.GetStatus(ctx, key)
+		require.NoError(t, err)
+		assert.Nil(t, status)
+	})
+
+	t.Run("remaining reaches zero after limit requests", func(t *testing.T) {
+		key := "ratelimit:test-status"
+		defer func() { _ = limiter.Reset(ctx, key) }()
+
+		const limit = 5
+		window := time.Minute
+		start := time.Now()
+
+		for i := 1; i <= limit; i++ {
+			allowed, err := limiter.Allow(ctx, key, limit, window)
+			require.NoError(t, err)
+			require.True(t, allowed)
+
+			status, err := limiter.GetStatus(ctx, key)
+			require.NoError(t, err)
+			require.NotNil(t, status)
+			assert.Equal(t, limit, status.Limit)
+			assert.Equal(t, limit-i, status.Remaining)
+		}
+
+		allowed, err := limiter.Allow(ctx, key, limit, window)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		status, err := limiter.GetStatus(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, 0, status.Remaining)
+		assert.WithinDuration(t, start.Add(window), status.ResetAt, time.Second)
+	})
+
+	t.Run("window is not extended by requests", func(t *testing.T) {
+		key := "ratelimit:test-status-fixed"
+		defer func() { _ = limiter.Reset(ctx, key) }()
+
+		_, err := limiter.Allow(ctx, key, 10, time.Minute)
+		require.NoError(t, err)
+		first, err := limiter.GetStatus(ctx, key)
+		require.NoError(t, err)
+
+		time.Sleep(20 * time.Millisecond)
+		_, err = limiter.Allow(ctx, key, 10, time.Minute)
+		require.NoError(t, err)
+		second, err := limiter.GetStatus(ctx, key)
+		require.NoError(t, err)
+
+		assert.True(t, first.ResetAt.Equal(second.ResetAt))
+	})
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/google/uuid"
+)
+
+// roundProgressTTL время жизни прогресса раунда: зрители опрашивают его часто,
+// а отставание на несколько секунд для полосы прогресса незаметно
+const roundProgressTTL = 5 * time.Second
+
+// RoundProgressCache кэширует прогресс текущего раунда игры турнира
+type RoundProgressCache struct {
+	cache *Cache
+	ttl   time.Duration
+}
+
+// NewRoundProgressCache создаёт новый кэш прогресса раундов
+func NewRoundProgressCache(cache *Cache) *RoundProgressCache {
+	return &RoundProgressCache{
+		cache: cache,
+		ttl:   roundProgressTTL,
+	}
+}
+
+// getKey возвращает ключ прогресса раунда игры турнира
+func (rc *RoundProgressCache) getKey(tournamentID, gameID uuid.UUID) string {
+	return fmt.Sprintf("tournament:%s:game:%s:round_progress", tournamentID.String(), gameID.String())
+}
+
+// Get возвращает прогресс раунда или nil при промахе
+func (rc *RoundProgressCache) Get(ctx context.Context, tournamentID, gameID uuid.UUID) (*domain.GameRoundProgress, error) {
+	data, err := rc.cache.Get(ctx, rc.getKey(tournamentID, gameID))
+	if err != nil {
+		return nil, err
+	}
+
+	if data == "" {
+		return nil, nil // кэш промах
+	}
+
+	var progress domain.GameRoundProgress
+	if err := json.Unmarshal([]byte(data), &progress); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal round progress: %w", err)
+	}
+
+	return &progress, nil
+}
+
+// Set сохраняет прогресс раунда в кэш
+func (rc *RoundProgressCache) Set(ctx context.Context, progress *domain.GameRoundProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal round progress: %w", err)
+	}
+
+	return rc.cache.Set(ctx, rc.getKey(progress.TournamentID, progress.GameID), data, rc.ttl)
+}
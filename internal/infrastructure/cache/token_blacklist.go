@@ -3,7 +3,10 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // TokenBlacklistCache управляет чёрным списком токенов
@@ -53,3 +56,44 @@ func (tbc *TokenBlacklistCache) Remove(ctx context.Context, token string) error
 
 	return nil
 }
+
+// RevokeUserTokens отзывает все токены пользователя, выпущенные раньше issuedBefore.
+// Время хранится с точностью до наносекунды, чтобы токены, выпущенные сразу после отзыва, оставались действительны.
+// Хранится одна метка на пользователя: повторный отзыв перезаписывает её более поздним временем.
+// ttl должен быть не меньше времени жизни самого долгоживущего токена (refresh)
+func (tbc *TokenBlacklistCache) RevokeUserTokens(ctx context.Context, userID uuid.UUID, issuedBefore time.Time, ttl time.Duration) error {
+	key := fmt.Sprintf("user:revoked:%s", userID)
+
+	err := tbc.cache.Set(ctx, key, issuedBefore.UTC().Format(time.RFC3339Nano), ttl)
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	return nil
+}
+
+// UserTokensRevokedBefore возвращает время последнего отзыва токенов пользователя
+func (tbc *TokenBlacklistCache) UserTokensRevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, bool, error) {
+	key := fmt.Sprintf("user:revoked:%s", userID)
+
+	val, err := tbc.cache.Get(ctx, key)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to check user token revocation: %w", err)
+	}
+	if val == "" {
+		return time.Time{}, false, nil
+	}
+
+	if revokedAt, err := time.Parse(time.RFC3339Nano, val); err == nil {
+		return revokedAt, true, nil
+	}
+
+	// Метки, записанные до перехода на точное время, хранят Unix-секунды:
+	// отзываем и токены, выпущенные в ту же секунду
+	unix, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid user token revocation value: %w", err)
+	}
+
+	return time.Unix(unix+1, 0), true, nil
+}
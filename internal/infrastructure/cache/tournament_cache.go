@@ -10,6 +10,13 @@ import (
 	"github.com/google/uuid"
 )
 
+// Список ID активных турниров опрашивается часто, а меняется только при старте
+// и завершении турниров, поэтому кэшируется отдельным ключом с коротким TTL
+const (
+	activeIDsKey = "tournaments:active_ids"
+	activeIDsTTL = 60 * time.Second
+)
+
 // TournamentCache - кэш для турниров
 type TournamentCache struct {
 	cache *Cache
@@ -207,3 +214,41 @@ func (tc *TournamentCache) InvalidateList(ctx context.Context) error {
 
 	return nil
 }
+
+// SetActiveIDs кэширует ID активных турниров на activeIDsTTL
+func (tc *TournamentCache) SetActiveIDs(ctx context.Context, ids []uuid.UUID) error {
+	if ids == nil {
+		ids = []uuid.UUID{}
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return fmt.Errorf("failed to marshal active tournament ids: %w", err)
+	}
+
+	return tc.cache.Set(ctx, activeIDsKey, data, activeIDsTTL)
+}
+
+// GetActiveIDs получает ID активных турниров из кэша.
+// Промах - nil, закэшированный пустой список - пустой срез
+func (tc *TournamentCache) GetActiveIDs(ctx context.Context) ([]uuid.UUID, error) {
+	data, err := tc.cache.Get(ctx, activeIDsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == "" {
+		return nil, nil // кэш промах
+	}
+
+	ids := []uuid.UUID{}
+	if err := json.Unmarshal([]byte(data), &ids); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal active tournament ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+// InvalidateActiveIDs сбрасывает кэш ID активных турниров (при смене статуса турнира)
+func (tc *TournamentCache) InvalidateActiveIDs(ctx context.Context) error {
+	return tc.cache.Del(ctx, activeIDsKey)
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// tournamentEventsChannel канал Redis pub/sub для событий турниров
+const tournamentEventsChannel = "tournament:events"
+
+// TournamentBroadcaster рассылает событие подписчикам турнира (websocket hub)
+type TournamentBroadcaster interface {
+	Broadcast(tournamentID uuid.UUID, messageType string, payload interface{})
+}
+
+// tournamentEvent - событие турнира в канале Redis
+type tournamentEvent struct {
+	TournamentID uuid.UUID       `json:"tournament_id"`
+	Type         string          `json:"type"`
+	Payload      json.RawMessage `json:"payload"`
+}
+
+// TournamentEvents - доставка событий турниров из других процессов (worker)
+// в websocket hub API через Redis pub/sub
+type TournamentEvents struct {
+	cache *Cache
+}
+
+// NewTournamentEvents создаёт канал событий турниров
+func NewTournamentEvents(cache *Cache) *TournamentEvents {
+	return &TournamentEvents{cache: cache}
+}
+
+// PublishTournamentEvent публикует событие турнира для всех экземпляров API
+func (e *TournamentEvents) PublishTournamentEvent(ctx context.Context, tournamentID uuid.UUID, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tournament event payload: %w", err)
+	}
+
+	message, err := json.Marshal(&tournamentEvent{
+		TournamentID: tournamentID,
+		Type:         eventType,
+		Payload:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal tournament event: %w", err)
+	}
+
+	return e.cache.Publish(ctx, tournamentEventsChannel, message)
+}
+
+// Forward пересылает опубликованные события турниров в broadcaster до отмены контекста.
+// События, опубликованные без подписчиков, не сохраняются
+func (e *TournamentEvents) Forward(ctx context.Context, broadcaster TournamentBroadcaster) {
+	pubsub := e.cache.Subscribe(ctx, tournamentEventsChannel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event tournamentEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				e.cache.log.LogError("Invalid tournament event", err)
+				continue
+			}
+			broadcaster.Broadcast(event.TournamentID, event.Type, event.Payload)
+		}
+	}
+}
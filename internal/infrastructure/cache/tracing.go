@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/bmstu-itstech/tjudge/pkg/tracing"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tracingHook создаёт спан для каждой команды Redis
+// Подключается к клиенту, поэтому покрывает все методы кэша, очереди и блокировок
+type tracingHook struct{}
+
+func (tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := tracing.StartSpan(ctx, "redis."+cmd.Name(),
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation", cmd.Name()),
+		)
+
+		err := next(ctx, cmd)
+
+		// redis.Nil означает отсутствие ключа (cache miss), а не ошибку
+		if err == redis.Nil {
+			tracing.EndSpan(span, nil)
+		} else {
+			tracing.EndSpan(span, err)
+		}
+		return err
+	}
+}
+
+func (tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := tracing.StartSpan(ctx, "redis.pipeline",
+			attribute.String("db.system", "redis"),
+			attribute.Int("db.redis.pipeline_length", len(cmds)),
+		)
+
+		err := next(ctx, cmds)
+		if err == redis.Nil {
+			tracing.EndSpan(span, nil)
+		} else {
+			tracing.EndSpan(span, err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// APIKeyRepository - репозиторий для работы с API ключами
+type APIKeyRepository struct {
+	db *DB
+}
+
+// NewAPIKeyRepository создаёт новый репозиторий API ключей
+func NewAPIKeyRepository(db *DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create сохраняет новый API ключ
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, user_id, name, key_hash, prefix, scope)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		key.ID,
+		key.UserID,
+		key.Name,
+		key.KeyHash,
+		key.Prefix,
+		key.Scope,
+	).Scan(&key.CreatedAt)
+
+	if err != nil {
+		return errors.Wrap(err, "failed to create api key")
+	}
+
+	return nil
+}
+
+// ListByUser возвращает действующие ключи пользователя, новые первыми
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+
+	query := `
+		SELECT id, user_id, name, key_hash, prefix, scope, last_used_at, created_at, revoked_at
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC, id DESC
+	`
+
+	if err := r.db.QueryWithMetrics(ctx, "api_key_list_by_user", &keys, query, userID); err != nil {
+		return nil, errors.Wrap(err, "failed to list api keys")
+	}
+
+	return keys, nil
+}
+
+// GetByHash получает действующий ключ по хэшу
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+
+	query := `
+		SELECT id, user_id, name, key_hash, prefix, scope, last_used_at, created_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`
+
+	err := r.db.QueryRowWithMetrics(ctx, "api_key_get_by_hash", &key, query, keyHash)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound.WithMessage("api key not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get api key")
+	}
+
+	return &key, nil
+}
+
+// Revoke отзывает ключ пользователя и возвращает его хэш для сброса кэша
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, userID uuid.UUID) (string, error) {
+	var keyHash string
+
+	query := `
+		UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+		RETURNING key_hash
+	`
+
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(&keyHash)
+	if err == sql.ErrNoRows {
+		return "", errors.ErrNotFound.WithMessage("api key not found")
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to revoke api key")
+	}
+
+	return keyHash, nil
+}
+
+// RevokeAllByUser отзывает все действующие ключи пользователя и возвращает их хэши для сброса кэша
+func (r *APIKeyRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+		RETURNING key_hash
+	`
+
+	var hashes []string
+	if err := r.db.QueryWithMetrics(ctx, "api_key_revoke_all", &hashes, query, userID); err != nil {
+		return nil, errors.Wrap(err, "failed to revoke user api keys")
+	}
+
+	return hashes, nil
+}
+
+// TouchLastUsed обновляет время последнего использования ключа
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`
+
+	if _, err := r.db.ExecWithMetrics(ctx, "api_key_touch", query, id, at); err != nil {
+		return errors.Wrap(err, "failed to update api key last used time")
+	}
+
+	return nil
+}
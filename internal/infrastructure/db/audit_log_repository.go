@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+)
+
+// AuditLogRepository - репозиторий журнала действий администраторов
+type AuditLogRepository struct {
+	db *DB
+}
+
+// NewAuditLogRepository создаёт новый репозиторий журнала действий
+func NewAuditLogRepository(db *DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Record сохраняет запись журнала. Время действия берётся из записи, если оно задано:
+// запись может сохраняться позже самого действия
+func (r *AuditLogRepository) Record(ctx context.Context, entry *domain.AuditLogEntry) error {
+	query := `
+		INSERT INTO audit_log (user_id, impersonated_by, action, target_type, target_id,
+		                       ip_address, user_agent, request_body_hash, params, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, NOW()))
+		RETURNING id, created_at
+	`
+
+	var params, createdAt interface{}
+	if len(entry.Params) > 0 {
+		params = string(entry.Params)
+	}
+	if !entry.CreatedAt.IsZero() {
+		createdAt = entry.CreatedAt
+	}
+
+	err := r.db.QueryRowContext(ctx, query,
+		entry.UserID,
+		entry.ImpersonatedBy,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.IPAddress,
+		entry.UserAgent,
+		entry.RequestBodyHash,
+		params,
+		createdAt,
+	).Scan(&entry.ID, &entry.CreatedAt)
+
+	if err != nil {
+		return errors.Wrap(err, "failed to record audit log entry")
+	}
+
+	return nil
+}
+
+// List возвращает записи журнала по фильтру, новые первыми
+func (r *AuditLogRepository) List(ctx context.Context, filter domain.AuditLogFilter) ([]*domain.AuditLogEntry, error) {
+	query := `
+		SELECT id, user_id, impersonated_by, action, target_type, target_id,
+		       ip_address, user_agent, request_body_hash, params, created_at
+		FROM audit_log
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argCount := 1
+
+	if filter.UserID != nil {
+		query += fmt.Sprintf(" AND user_id = $%d", argCount)
+		args = append(args, *filter.UserID)
+		argCount++
+	}
+
+	if filter.Action != "" {
+		query += fmt.Sprintf(" AND action = $%d", argCount)
+		args = append(args, filter.Action)
+		argCount++
+	}
+
+	if filter.TargetType != "" {
+		query += fmt.Sprintf(" AND target_type = $%d", argCount)
+		args = append(args, filter.TargetType)
+		argCount++
+	}
+
+	if filter.TargetID != "" {
+		query += fmt.Sprintf(" AND target_id = $%d", argCount)
+		args = append(args, filter.TargetID)
+		argCount++
+	}
+
+	if filter.After != nil {
+		query += fmt.Sprintf(" AND created_at > $%d", argCount)
+		args = append(args, *filter.After)
+		argCount++
+	}
+
+	if filter.Before != nil {
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argCount, argCount+1)
+		args = append(args, filter.Before.CreatedAt, filter.Before.ID)
+		argCount += 2
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, filter.Limit)
+	}
+
+	entries := make([]*domain.AuditLogEntry, 0)
+	if err := r.db.QueryWithMetrics(ctx, "audit_log_list", &entries, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to list audit log")
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// bracketSlotColumns колонки позиции сетки без состояния матча
+const bracketSlotColumns = `tournament_id, round, position, program1_id, program2_id, seed1, seed2,
+	match_id, winner_id, rematches, is_bye`
+
+// BracketRepository - репозиторий сеток турниров на выбывание
+type BracketRepository struct {
+	db *DB
+}
+
+// NewBracketRepository создаёт новый репозиторий сеток
+func NewBracketRepository(db *DB) *BracketRepository {
+	return &BracketRepository{db: db}
+}
+
+// CreateSlots сохраняет все позиции сетки одной транзакцией
+func (r *BracketRepository) CreateSlots(ctx context.Context, slots []*domain.BracketSlot) error {
+	if len(slots) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO bracket_slots (` + bracketSlotColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	return r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return errors.Wrap(err, "failed to prepare statement")
+		}
+		defer stmt.Close()
+
+		for _, slot := range slots {
+			_, err := stmt.ExecContext(ctx,
+				slot.TournamentID,
+				slot.Round,
+				slot.Position,
+				slot.Program1ID,
+				slot.Program2ID,
+				slot.Seed1,
+				slot.Seed2,
+				slot.MatchID,
+				slot.WinnerID,
+				slot.Rematches,
+				slot.IsBye,
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to insert bracket slot")
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetByTournament возвращает позиции сетки с состоянием текущих матчей по раундам и позициям
+func (r *BracketRepository) GetByTournament(ctx context.Context, tournamentID uuid.UUID) ([]*domain.BracketSlot, error) {
+	slots := make([]*domain.BracketSlot, 0)
+
+	query := `
+		SELECT s.tournament_id, s.round, s.position, s.program1_id, s.program2_id, s.seed1, s.seed2,
+		       s.match_id, s.winner_id, s.rematches, s.is_bye,
+		       m.status AS match_status, m.score1, m.score2
+		FROM bracket_slots s
+		LEFT JOIN matches m ON m.id = s.match_id
+		WHERE s.tournament_id = $1
+		ORDER BY s.round, s.position
+	`
+
+	if err := r.db.QueryWithMetrics(ctx, "bracket_get_by_tournament", &slots, query, tournamentID); err != nil {
+		return nil, errors.Wrap(err, "failed to get bracket")
+	}
+
+	return slots, nil
+}
+
+// GetByMatchID возвращает позицию сетки, текущим матчем которой является matchID
+func (r *BracketRepository) GetByMatchID(ctx context.Context, matchID uuid.UUID) (*domain.BracketSlot, error) {
+	var slot domain.BracketSlot
+
+	query := `SELECT ` + bracketSlotColumns + ` FROM bracket_slots WHERE match_id = $1`
+
+	err := r.db.QueryRowWithMetrics(ctx, "bracket_get_by_match", &slot, query, matchID)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound.WithMessage("bracket slot not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get bracket slot")
+	}
+
+	return &slot, nil
+}
+
+// SetMatch назначает позиции новый матч, если её текущий матч равен previous (nil - матча ещё не было).
+// Переигровка увеличивает счётчик rematches. Если позицию уже занял другой матч, возвращает ErrConflict
+func (r *BracketRepository) SetMatch(ctx context.Context, tournamentID uuid.UUID, round, position int, previous *uuid.UUID, matchID uuid.UUID) error {
+	query := `
+		UPDATE bracket_slots
+		SET match_id = $4,
+		    rematches = rematches + CASE WHEN match_id IS NULL THEN 0 ELSE 1 END,
+		    updated_at = NOW()
+		WHERE tournament_id = $1 AND round = $2 AND position = $3
+		  AND match_id IS NOT DISTINCT FROM $5 AND winner_id IS NULL
+	`
+
+	result, err := r.db.ExecWithMetrics(ctx, "bracket_set_match", query, tournamentID, round, position, matchID, previous)
+	if err != nil {
+		return errors.Wrap(err, "failed to set bracket match")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+	if rows == 0 {
+		return errors.ErrConflict.WithMessage("bracket slot already has another match")
+	}
+
+	return nil
+}
+
+// SetWinner записывает победителя позиции по результату её текущего матча.
+// Возвращает false, если победитель уже записан или матч позиции сменился
+func (r *BracketRepository) SetWinner(ctx context.Context, tournamentID uuid.UUID, round, position int, matchID, winnerID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE bracket_slots
+		SET winner_id = $5, updated_at = NOW()
+		WHERE tournament_id = $1 AND round = $2 AND position = $3
+		  AND match_id = $4 AND winner_id IS NULL
+	`
+
+	result, err := r.db.ExecWithMetrics(ctx, "bracket_set_winner", query, tournamentID, round, position, matchID, winnerID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to set bracket winner")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get rows affected")
+	}
+
+	return rows > 0, nil
+}
+
+// SetEntrant выводит участника в позицию следующего раунда на место side (1 или 2)
+// и возвращает позицию после обновления. Обновление строки сериализуется PostgreSQL,
+// поэтому оба участника пары видны только тому, кто записал второго
+func (r *BracketRepository) SetEntrant(ctx context.Context, tournamentID uuid.UUID, round, position, side int, programID uuid.UUID, seed *int) (*domain.BracketSlot, error) {
+	if side != 1 && side != 2 {
+		return nil, fmt.Errorf("invalid bracket side: %d", side)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE bracket_slots
+		SET program%[1]d_id = $4, seed%[1]d = $5, updated_at = NOW()
+		WHERE tournament_id = $1 AND round = $2 AND position = $3
+		RETURNING %[2]s
+	`, side, bracketSlotColumns)
+
+	var slot domain.BracketSlot
+	err := r.db.QueryRowWithMetrics(ctx, "bracket_set_entrant", &slot, query, tournamentID, round, position, programID, seed)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound.WithMessage("bracket slot not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set bracket entrant")
+	}
+
+	return &slot, nil
+}
@@ -9,8 +9,11 @@ import (
 	"github.com/bmstu-itstech/tjudge/internal/config"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
 	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/bmstu-itstech/tjudge/pkg/tracing"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -76,9 +79,11 @@ func (db *DB) monitorConnectionPool() {
 
 // ExecWithMetrics выполняет запрос с записью метрик
 func (db *DB) ExecWithMetrics(ctx context.Context, queryType string, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startDBSpan(ctx, "exec", queryType, query)
 	start := time.Now()
 	result, err := db.ExecContext(ctx, query, args...)
 	db.metrics.RecordDBQuery(queryType, time.Since(start))
+	endDBSpan(span, err)
 
 	if err != nil {
 		db.log.LogError("Database exec failed", err,
@@ -91,9 +96,11 @@ func (db *DB) ExecWithMetrics(ctx context.Context, queryType string, query strin
 
 // QueryWithMetrics выполняет запрос с записью метрик
 func (db *DB) QueryWithMetrics(ctx context.Context, queryType string, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := startDBSpan(ctx, "query", queryType, query)
 	start := time.Now()
 	err := db.SelectContext(ctx, dest, query, args...)
 	db.metrics.RecordDBQuery(queryType, time.Since(start))
+	endDBSpan(span, err)
 
 	if err != nil && err != sql.ErrNoRows {
 		db.log.LogError("Database query failed", err,
@@ -106,9 +113,11 @@ func (db *DB) QueryWithMetrics(ctx context.Context, queryType string, dest inter
 
 // QueryRowWithMetrics выполняет запрос одной строки с записью метрик
 func (db *DB) QueryRowWithMetrics(ctx context.Context, queryType string, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := startDBSpan(ctx, "query_row", queryType, query)
 	start := time.Now()
 	err := db.GetContext(ctx, dest, query, args...)
 	db.metrics.RecordDBQuery(queryType, time.Since(start))
+	endDBSpan(span, err)
 
 	if err != nil && err != sql.ErrNoRows {
 		db.log.LogError("Database query row failed", err,
@@ -119,6 +128,23 @@ func (db *DB) QueryRowWithMetrics(ctx context.Context, queryType string, dest in
 	return err
 }
 
+// startDBSpan начинает спан запроса к базе данных
+func startDBSpan(ctx context.Context, operation, queryType, query string) (context.Context, trace.Span) {
+	return tracing.StartSpan(ctx, "db."+operation+" "+queryType,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", queryType),
+		attribute.String("db.statement", query),
+	)
+}
+
+// endDBSpan завершает спан, не считая sql.ErrNoRows ошибкой
+func endDBSpan(span trace.Span, err error) {
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	tracing.EndSpan(span, err)
+}
+
 // BeginTx начинает транзакцию
 func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
 	return db.DB.BeginTxx(ctx, opts)
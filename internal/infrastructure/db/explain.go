@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// ExplainTimeout жёсткий предел выполнения EXPLAIN ANALYZE: запрос действительно выполняется
+const ExplainTimeout = 5 * time.Second
+
+// explainAllowedPrefixes разрешённые начала запросов для ExplainAnalyze
+var explainAllowedPrefixes = []string{"select"}
+
+// ExplainTimings время планирования и выполнения из JSON плана EXPLAIN ANALYZE, в миллисекундах
+type ExplainTimings struct {
+	PlanningTimeMs  float64 `json:"planning_time_ms"`
+	ExecutionTimeMs float64 `json:"execution_time_ms"`
+}
+
+// ValidateExplainQuery проверяет, что запрос можно передать в ExplainAnalyze:
+// один оператор, начинающийся с разрешённого префикса (только SELECT)
+func ValidateExplainQuery(query string) error {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	if query == "" {
+		return errors.ErrInvalidInput.WithMessage("query is required")
+	}
+	if strings.Contains(query, ";") {
+		return errors.ErrInvalidInput.WithMessage("only a single statement is allowed")
+	}
+
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '('
+	})
+	for _, prefix := range explainAllowedPrefixes {
+		if len(words) > 0 && strings.ToLower(words[0]) == prefix {
+			return nil
+		}
+	}
+	return errors.ErrInvalidInput.WithMessage("only SELECT queries can be explained")
+}
+
+// ExplainAnalyze выполняет EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) для запроса и возвращает
+// JSON план. Запрос выполняется в транзакции READ ONLY, которая всегда откатывается,
+// и прерывается через ExplainTimeout
+func (db *DB) ExplainAnalyze(ctx context.Context, query string, args ...interface{}) (string, error) {
+	if err := ValidateExplainQuery(query); err != nil {
+		return "", err
+	}
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+
+	ctx, cancel := context.WithTimeout(ctx, ExplainTimeout)
+	defer cancel()
+
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// statement_timeout дублирует таймаут контекста на стороне сервера
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", ExplainTimeout.Milliseconds())); err != nil {
+		return "", errors.Wrap(err, "failed to set statement timeout")
+	}
+
+	start := time.Now()
+	var plan string
+	err = tx.QueryRowContext(ctx, "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) "+query, args...).Scan(&plan)
+	db.metrics.RecordDBQuery("explain_analyze", time.Since(start))
+	if err != nil {
+		// Ошибки в самом запросе (синтаксис, несуществующая таблица, запись в READ ONLY) - ошибка ввода
+		var sqlErr interface{ SQLState() string }
+		if stderrors.As(err, &sqlErr) && isQueryInputState(sqlErr.SQLState()) {
+			return "", errors.ErrInvalidInput.WithMessage(err.Error())
+		}
+		db.log.LogError("Explain analyze failed", err, zap.Duration("timeout", ExplainTimeout))
+		return "", err
+	}
+
+	return plan, nil
+}
+
+// isQueryInputState проверяет класс SQLSTATE: 42 - синтаксис и доступ, 22 - данные,
+// 25 - состояние транзакции (попытка записи в READ ONLY)
+func isQueryInputState(state string) bool {
+	if len(state) < 2 {
+		return false
+	}
+	switch state[:2] {
+	case "42", "22", "25":
+		return true
+	}
+	return false
+}
+
+// ParseExplainTimings извлекает время планирования и выполнения из JSON плана ExplainAnalyze
+func ParseExplainTimings(plan string) (ExplainTimings, error) {
+	var entries []struct {
+		PlanningTime  float64 `json:"Planning Time"`
+		ExecutionTime float64 `json:"Execution Time"`
+	}
+	if err := json.Unmarshal([]byte(plan), &entries); err != nil {
+		return ExplainTimings{}, errors.Wrap(err, "failed to parse explain plan")
+	}
+	if len(entries) == 0 {
+		return ExplainTimings{}, fmt.Errorf("explain plan is empty")
+	}
+
+	return ExplainTimings{
+		PlanningTimeMs:  entries[0].PlanningTime,
+		ExecutionTimeMs: entries[0].ExecutionTime,
+	}, nil
+}
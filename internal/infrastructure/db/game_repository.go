@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
@@ -23,8 +24,8 @@ func NewGameRepository(db *DB) *GameRepository {
 // Create создаёт новую игру
 func (r *GameRepository) Create(ctx context.Context, game *domain.Game) error {
 	query := `
-		INSERT INTO games (id, name, display_name, rules)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO games (id, name, display_name, rules, sandbox_profile)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING created_at, updated_at
 	`
 
@@ -33,6 +34,7 @@ func (r *GameRepository) Create(ctx context.Context, game *domain.Game) error {
 		game.Name,
 		game.DisplayName,
 		game.Rules,
+		game.SandboxProfile,
 	).Scan(&game.CreatedAt, &game.UpdatedAt)
 
 	if err != nil {
@@ -47,7 +49,7 @@ func (r *GameRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Gam
 	var game domain.Game
 
 	query := `
-		SELECT id, name, display_name, rules, created_at, updated_at
+		SELECT id, name, display_name, rules, sandbox_profile, validation_bot_id, created_at, updated_at
 		FROM games
 		WHERE id = $1
 	`
@@ -57,6 +59,8 @@ func (r *GameRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Gam
 		&game.Name,
 		&game.DisplayName,
 		&game.Rules,
+		&game.SandboxProfile,
+		&game.ValidationBotID,
 		&game.CreatedAt,
 		&game.UpdatedAt,
 	)
@@ -76,7 +80,7 @@ func (r *GameRepository) GetByName(ctx context.Context, name string) (*domain.Ga
 	var game domain.Game
 
 	query := `
-		SELECT id, name, display_name, rules, created_at, updated_at
+		SELECT id, name, display_name, rules, sandbox_profile, validation_bot_id, created_at, updated_at
 		FROM games
 		WHERE name = $1
 	`
@@ -86,6 +90,8 @@ func (r *GameRepository) GetByName(ctx context.Context, name string) (*domain.Ga
 		&game.Name,
 		&game.DisplayName,
 		&game.Rules,
+		&game.SandboxProfile,
+		&game.ValidationBotID,
 		&game.CreatedAt,
 		&game.UpdatedAt,
 	)
@@ -103,7 +109,7 @@ func (r *GameRepository) GetByName(ctx context.Context, name string) (*domain.Ga
 // List получает список всех игр
 func (r *GameRepository) List(ctx context.Context, filter domain.GameFilter) ([]*domain.Game, error) {
 	query := `
-		SELECT id, name, display_name, rules, created_at, updated_at
+		SELECT id, name, display_name, rules, sandbox_profile, validation_bot_id, created_at, updated_at
 		FROM games
 		WHERE 1=1
 	`
@@ -146,6 +152,8 @@ func (r *GameRepository) List(ctx context.Context, filter domain.GameFilter) ([]
 			&game.Name,
 			&game.DisplayName,
 			&game.Rules,
+			&game.SandboxProfile,
+			&game.ValidationBotID,
 			&game.CreatedAt,
 			&game.UpdatedAt,
 		)
@@ -163,7 +171,7 @@ func (r *GameRepository) List(ctx context.Context, filter domain.GameFilter) ([]
 func (r *GameRepository) Update(ctx context.Context, game *domain.Game) error {
 	query := `
 		UPDATE games
-		SET display_name = $2, rules = $3
+		SET display_name = $2, rules = $3, sandbox_profile = $4
 		WHERE id = $1
 		RETURNING updated_at
 	`
@@ -172,6 +180,7 @@ func (r *GameRepository) Update(ctx context.Context, game *domain.Game) error {
 		game.ID,
 		game.DisplayName,
 		game.Rules,
+		game.SandboxProfile,
 	).Scan(&game.UpdatedAt)
 
 	if err == sql.ErrNoRows {
@@ -184,6 +193,26 @@ func (r *GameRepository) Update(ctx context.Context, game *domain.Game) error {
 	return nil
 }
 
+// SetValidationBot назначает эталонного бота для проверочных матчей игры (nil - без проверки)
+func (r *GameRepository) SetValidationBot(ctx context.Context, gameID uuid.UUID, programID *uuid.UUID) error {
+	query := `UPDATE games SET validation_bot_id = $2 WHERE id = $1`
+
+	result, err := r.db.ExecWithMetrics(ctx, "game_set_validation_bot", query, gameID, programID)
+	if err != nil {
+		return errors.Wrap(err, "failed to set game validation bot")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+	if rows == 0 {
+		return errors.ErrNotFound.WithMessage("game not found")
+	}
+
+	return nil
+}
+
 // Delete удаляет игру
 func (r *GameRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM games WHERE id = $1`
@@ -208,7 +237,7 @@ func (r *GameRepository) Delete(ctx context.Context, id uuid.UUID) error {
 // GetByTournamentID получает игры, связанные с турниром
 func (r *GameRepository) GetByTournamentID(ctx context.Context, tournamentID uuid.UUID) ([]*domain.Game, error) {
 	query := `
-		SELECT g.id, g.name, g.display_name, g.rules, g.created_at, g.updated_at
+		SELECT g.id, g.name, g.display_name, g.rules, g.sandbox_profile, g.validation_bot_id, g.created_at, g.updated_at
 		FROM games g
 		INNER JOIN tournament_games tg ON g.id = tg.game_id
 		WHERE tg.tournament_id = $1
@@ -230,6 +259,8 @@ func (r *GameRepository) GetByTournamentID(ctx context.Context, tournamentID uui
 			&game.Name,
 			&game.DisplayName,
 			&game.Rules,
+			&game.SandboxProfile,
+			&game.ValidationBotID,
 			&game.CreatedAt,
 			&game.UpdatedAt,
 		)
@@ -298,7 +329,7 @@ func (r *GameRepository) GetTournamentGame(ctx context.Context, tournamentID, ga
 	var tg domain.TournamentGame
 
 	query := `
-		SELECT tournament_id, game_id, COALESCE(is_active, false), COALESCE(round_completed, false), round_completed_at, COALESCE(current_round, 0), created_at
+		SELECT tournament_id, game_id, COALESCE(is_active, false), COALESCE(round_completed, false), round_active, round_completed_at, COALESCE(current_round, 0), window_start, window_end, created_at
 		FROM tournament_games
 		WHERE tournament_id = $1 AND game_id = $2
 	`
@@ -308,8 +339,11 @@ func (r *GameRepository) GetTournamentGame(ctx context.Context, tournamentID, ga
 		&tg.GameID,
 		&tg.IsActive,
 		&tg.RoundCompleted,
+		&tg.RoundActive,
 		&tg.RoundCompletedAt,
 		&tg.CurrentRound,
+		&tg.WindowStart,
+		&tg.WindowEnd,
 		&tg.CreatedAt,
 	)
 
@@ -326,7 +360,7 @@ func (r *GameRepository) GetTournamentGame(ctx context.Context, tournamentID, ga
 // GetTournamentGames получает все связи турнира с играми
 func (r *GameRepository) GetTournamentGames(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TournamentGame, error) {
 	query := `
-		SELECT tg.tournament_id, tg.game_id, COALESCE(tg.is_active, false), COALESCE(tg.round_completed, false), tg.round_completed_at, COALESCE(tg.current_round, 0), tg.created_at
+		SELECT tg.tournament_id, tg.game_id, COALESCE(tg.is_active, false), COALESCE(tg.round_completed, false), tg.round_active, tg.round_completed_at, COALESCE(tg.current_round, 0), tg.window_start, tg.window_end, tg.created_at
 		FROM tournament_games tg
 		WHERE tg.tournament_id = $1
 		ORDER BY tg.created_at ASC
@@ -347,8 +381,11 @@ func (r *GameRepository) GetTournamentGames(ctx context.Context, tournamentID uu
 			&tg.GameID,
 			&tg.IsActive,
 			&tg.RoundCompleted,
+			&tg.RoundActive,
 			&tg.RoundCompletedAt,
 			&tg.CurrentRound,
+			&tg.WindowStart,
+			&tg.WindowEnd,
 			&tg.CreatedAt,
 		)
 		if err != nil {
@@ -365,7 +402,7 @@ func (r *GameRepository) GetTournamentGames(ctx context.Context, tournamentID uu
 func (r *GameRepository) MarkRoundCompleted(ctx context.Context, tournamentID, gameID uuid.UUID) error {
 	query := `
 		UPDATE tournament_games
-		SET round_completed = true, round_completed_at = NOW()
+		SET round_completed = true, round_completed_at = NOW(), round_active = false
 		WHERE tournament_id = $1 AND game_id = $2
 	`
 
@@ -407,6 +444,208 @@ func (r *GameRepository) IsRoundCompleted(ctx context.Context, tournamentID, gam
 	return completed, nil
 }
 
+// MarkRoundActive отмечает, что для игры турнира идёт раунд.
+// Игра задаётся именем, как в matches.game_type
+func (r *GameRepository) MarkRoundActive(ctx context.Context, tournamentID uuid.UUID, gameType string) error {
+	query := `
+		UPDATE tournament_games tg
+		SET round_active = true
+		FROM games g
+		WHERE g.id = tg.game_id AND tg.tournament_id = $1 AND g.name = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tournamentID, gameType)
+	if err != nil {
+		return errors.Wrap(err, "failed to mark round active")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rows == 0 {
+		return errors.ErrNotFound.WithMessage("tournament game not found")
+	}
+
+	return nil
+}
+
+// FinishRoundIfIdle снимает отметку идущего раунда, если у игры не осталось pending и running матчей
+// (тестовые матчи раунд не задерживают). Если в турнире включено автозавершение раундов,
+// в том же обновлении раунд отмечается завершённым и счётчик раундов увеличивается.
+// Возвращает состояние игры после завершения раунда этим вызовом или nil, если раунд не завершён
+func (r *GameRepository) FinishRoundIfIdle(ctx context.Context, tournamentID uuid.UUID, gameType string) (*domain.TournamentGame, error) {
+	query := `
+		UPDATE tournament_games tg
+		SET round_active = false,
+			round_completed = CASE WHEN t.metadata->'auto_complete_rounds' = 'true'::jsonb
+				THEN true ELSE tg.round_completed END,
+			round_completed_at = CASE WHEN t.metadata->'auto_complete_rounds' = 'true'::jsonb
+				THEN NOW() ELSE tg.round_completed_at END,
+			current_round = CASE WHEN t.metadata->'auto_complete_rounds' = 'true'::jsonb
+				THEN COALESCE(tg.current_round, 0) + 1 ELSE tg.current_round END
+		FROM games g, tournaments t
+		WHERE g.id = tg.game_id AND t.id = tg.tournament_id AND tg.tournament_id = $1 AND g.name = $2
+		AND tg.round_active
+		AND NOT EXISTS (
+			SELECT 1 FROM matches m
+			WHERE m.tournament_id = $1 AND m.game_type = $2
+			AND m.status IN ($3, $4) AND NOT m.is_test
+		)
+		RETURNING tg.tournament_id, tg.game_id, COALESCE(tg.is_active, false), COALESCE(tg.round_completed, false), tg.round_active,
+			tg.round_completed_at, COALESCE(tg.current_round, 0), tg.window_start, tg.window_end, tg.created_at
+	`
+
+	var tg domain.TournamentGame
+	err := r.db.QueryRowContext(ctx, query, tournamentID, gameType, domain.MatchPending, domain.MatchRunning).Scan(
+		&tg.TournamentID,
+		&tg.GameID,
+		&tg.IsActive,
+		&tg.RoundCompleted,
+		&tg.RoundActive,
+		&tg.RoundCompletedAt,
+		&tg.CurrentRound,
+		&tg.WindowStart,
+		&tg.WindowEnd,
+		&tg.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to finish round")
+	}
+
+	return &tg, nil
+}
+
+// IsRoundActive проверяет, идёт ли раунд для игры в турнире
+func (r *GameRepository) IsRoundActive(ctx context.Context, tournamentID, gameID uuid.UUID) (bool, error) {
+	var active bool
+	query := `
+		SELECT round_active
+		FROM tournament_games
+		WHERE tournament_id = $1 AND game_id = $2
+	`
+
+	err := r.db.QueryRowContext(ctx, query, tournamentID, gameID).Scan(&active)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check round active status")
+	}
+
+	return active, nil
+}
+
+// SetGameWindow задаёт окно времени игры турнира (nil - снять окно).
+// Отметка о запуске сбрасывается, чтобы новое окно запустило раунд
+func (r *GameRepository) SetGameWindow(ctx context.Context, tournamentID, gameID uuid.UUID, start, end *time.Time) error {
+	query := `
+		UPDATE tournament_games
+		SET window_start = $3, window_end = $4, window_triggered_at = NULL
+		WHERE tournament_id = $1 AND game_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tournamentID, gameID, start, end)
+	if err != nil {
+		return errors.Wrap(err, "failed to set game window")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rows == 0 {
+		return errors.ErrNotFound.WithMessage("tournament game not found")
+	}
+
+	return nil
+}
+
+// GetGameWindow получает связь турнира с игрой по имени игры, как в matches.game_type
+func (r *GameRepository) GetGameWindow(ctx context.Context, tournamentID uuid.UUID, gameType string) (*domain.TournamentGame, error) {
+	var tg domain.TournamentGame
+
+	query := `
+		SELECT tg.tournament_id, tg.game_id, COALESCE(tg.is_active, false), COALESCE(tg.round_completed, false), tg.round_active, tg.round_completed_at, COALESCE(tg.current_round, 0), tg.window_start, tg.window_end, tg.created_at
+		FROM tournament_games tg
+		JOIN games g ON g.id = tg.game_id
+		WHERE tg.tournament_id = $1 AND g.name = $2
+	`
+
+	err := r.db.QueryRowContext(ctx, query, tournamentID, gameType).Scan(
+		&tg.TournamentID,
+		&tg.GameID,
+		&tg.IsActive,
+		&tg.RoundCompleted,
+		&tg.RoundActive,
+		&tg.RoundCompletedAt,
+		&tg.CurrentRound,
+		&tg.WindowStart,
+		&tg.WindowEnd,
+		&tg.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound.WithMessage("tournament game not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get game window")
+	}
+
+	return &tg, nil
+}
+
+// GetDueGameWindows возвращает открытые к моменту now окна игр активных турниров,
+// для которых раунд ещё не запускался
+func (r *GameRepository) GetDueGameWindows(ctx context.Context, now time.Time) ([]*domain.GameWindow, error) {
+	query := `
+		SELECT tg.tournament_id, tg.game_id, g.name AS game_type, tg.window_start, tg.window_end
+		FROM tournament_games tg
+		JOIN games g ON g.id = tg.game_id
+		JOIN tournaments t ON t.id = tg.tournament_id
+		WHERE tg.window_start <= $1 AND tg.window_end > $1
+		AND (tg.window_triggered_at IS NULL OR tg.window_triggered_at < tg.window_start)
+		AND t.status = $2 AND t.deleted_at IS NULL
+		ORDER BY tg.window_start ASC
+	`
+
+	var windows []*domain.GameWindow
+	if err := r.db.QueryWithMetrics(ctx, "game_due_windows", &windows, query, now, domain.TournamentActive); err != nil {
+		return nil, errors.Wrap(err, "failed to get due game windows")
+	}
+
+	return windows, nil
+}
+
+// ClaimGameWindow отмечает окно игры как запущенное.
+// Возвращает false, если раунд этого окна уже запущен
+func (r *GameRepository) ClaimGameWindow(ctx context.Context, tournamentID, gameID uuid.UUID, at time.Time) (bool, error) {
+	query := `
+		UPDATE tournament_games
+		SET window_triggered_at = $3
+		WHERE tournament_id = $1 AND game_id = $2
+		AND window_start IS NOT NULL
+		AND (window_triggered_at IS NULL OR window_triggered_at < window_start)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tournamentID, gameID, at)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim game window")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get rows affected")
+	}
+
+	return rows > 0, nil
+}
+
 // IncrementCurrentRound увеличивает номер текущего раунда
 func (r *GameRepository) IncrementCurrentRound(ctx context.Context, tournamentID, gameID uuid.UUID) (int, error) {
 	var newRound int
@@ -490,7 +729,7 @@ func (r *GameRepository) GetActiveGame(ctx context.Context, tournamentID uuid.UU
 	var tg domain.TournamentGame
 
 	query := `
-		SELECT tournament_id, game_id, COALESCE(is_active, false), COALESCE(round_completed, false), round_completed_at, COALESCE(current_round, 0), created_at
+		SELECT tournament_id, game_id, COALESCE(is_active, false), COALESCE(round_completed, false), round_active, round_completed_at, COALESCE(current_round, 0), window_start, window_end, created_at
 		FROM tournament_games
 		WHERE tournament_id = $1 AND is_active = true
 	`
@@ -500,8 +739,11 @@ func (r *GameRepository) GetActiveGame(ctx context.Context, tournamentID uuid.UU
 		&tg.GameID,
 		&tg.IsActive,
 		&tg.RoundCompleted,
+		&tg.RoundActive,
 		&tg.RoundCompletedAt,
 		&tg.CurrentRound,
+		&tg.WindowStart,
+		&tg.WindowEnd,
 		&tg.CreatedAt,
 	)
 
@@ -539,7 +781,7 @@ func (r *GameRepository) IsGameActive(ctx context.Context, tournamentID, gameID
 func (r *GameRepository) ResetGameRound(ctx context.Context, tournamentID, gameID uuid.UUID) error {
 	query := `
 		UPDATE tournament_games
-		SET current_round = 0, round_completed = false, round_completed_at = NULL
+		SET current_round = 0, round_completed = false, round_completed_at = NULL, round_active = false
 		WHERE tournament_id = $1 AND game_id = $2
 	`
 
@@ -3,17 +3,39 @@ package db
 import (
 	"context"
 	"database/sql"
+	stderrors "errors"
+	"sync"
 	"time"
 
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
 	"go.uber.org/zap"
 )
 
+const (
+	// leaderboardRefreshLockKey ключ распределённой блокировки обновления leaderboards
+	leaderboardRefreshLockKey = "leaderboard:refresh"
+	// leaderboardRefreshTimeout максимальное время одного обновления
+	leaderboardRefreshTimeout = 30 * time.Second
+)
+
+// ErrLeaderboardRefreshInProgress обновление уже выполняется другим процессом
+var ErrLeaderboardRefreshInProgress = errors.ErrConflict.WithMessage("leaderboard refresh already in progress")
+
+// RefreshLocker распределённая блокировка, исключающая одновременное обновление
+// leaderboards из worker'ов и API (реализуется cache.DistributedLock)
+type RefreshLocker interface {
+	Lock(ctx context.Context, key string, ttl time.Duration) (string, error)
+	Unlock(ctx context.Context, key string, token string) error
+}
+
 // LeaderboardRefresher периодически обновляет materialized views для leaderboard
 type LeaderboardRefresher struct {
 	db       *DB
 	interval time.Duration
 	log      *logger.Logger
+	lock     RefreshLocker
+	mu       sync.Mutex
 	stopCh   chan struct{}
 	doneCh   chan struct{}
 }
@@ -29,6 +51,11 @@ func NewLeaderboardRefresher(db *DB, interval time.Duration, log *logger.Logger)
 	}
 }
 
+// SetLock устанавливает распределённую блокировку обновления
+func (r *LeaderboardRefresher) SetLock(lock RefreshLocker) {
+	r.lock = lock
+}
+
 // Start запускает периодическое обновление leaderboards
 func (r *LeaderboardRefresher) Start() {
 	r.log.Info("Starting leaderboard refresher",
@@ -51,7 +78,7 @@ func (r *LeaderboardRefresher) run() {
 	defer close(r.doneCh)
 
 	// Сразу обновляем при старте
-	r.refresh()
+	r.refreshPeriodic()
 
 	ticker := time.NewTicker(r.interval)
 	defer ticker.Stop()
@@ -59,18 +86,57 @@ func (r *LeaderboardRefresher) run() {
 	for {
 		select {
 		case <-ticker.C:
-			r.refresh()
+			r.refreshPeriodic()
 		case <-r.stopCh:
 			return
 		}
 	}
 }
 
-// refresh обновляет materialized views
-func (r *LeaderboardRefresher) refresh() {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// refreshPeriodic плановое обновление: если обновление уже идёт, пропускаем его
+func (r *LeaderboardRefresher) refreshPeriodic() {
+	ctx, cancel := context.WithTimeout(context.Background(), leaderboardRefreshTimeout)
 	defer cancel()
 
+	if err := r.Refresh(ctx); err != nil {
+		if stderrors.Is(err, ErrLeaderboardRefreshInProgress) {
+			r.log.Debug("Leaderboard refresh already in progress, skipping")
+			return
+		}
+		r.log.LogError("Failed to refresh leaderboards", err)
+	}
+}
+
+// Refresh немедленно обновляет materialized views (например, перед объявлением победителей).
+// Возвращает ErrLeaderboardRefreshInProgress, если обновление уже выполняется
+// в этом или другом процессе
+func (r *LeaderboardRefresher) Refresh(ctx context.Context) error {
+	if !r.mu.TryLock() {
+		return ErrLeaderboardRefreshInProgress
+	}
+	defer r.mu.Unlock()
+
+	if r.lock != nil {
+		token, err := r.lock.Lock(ctx, leaderboardRefreshLockKey, 2*leaderboardRefreshTimeout)
+		if err != nil {
+			if errors.IsConflict(err) {
+				return ErrLeaderboardRefreshInProgress
+			}
+			return errors.Wrap(err, "failed to acquire leaderboard refresh lock")
+		}
+		defer func() {
+			// Снимаем блокировку даже если ctx запроса уже отменён
+			if err := r.lock.Unlock(context.Background(), leaderboardRefreshLockKey, token); err != nil {
+				r.log.LogError("Failed to release leaderboard refresh lock", err)
+			}
+		}()
+	}
+
+	return r.refresh(ctx)
+}
+
+// refresh обновляет materialized views
+func (r *LeaderboardRefresher) refresh(ctx context.Context) error {
 	startTime := time.Now()
 
 	// Вызываем функцию refresh_leaderboards(), которая обновляет оба view конкурентно
@@ -79,23 +145,16 @@ func (r *LeaderboardRefresher) refresh() {
 		// Проверяем, существует ли функция
 		if err == sql.ErrNoRows || isUndefinedFunctionError(err) {
 			r.log.Info("Leaderboard materialized views not yet created, skipping refresh")
-			return
+			return nil
 		}
-
-		r.log.LogError("Failed to refresh leaderboards", err)
-		return
+		return errors.Wrap(err, "failed to refresh leaderboards")
 	}
 
 	duration := time.Since(startTime)
 	r.log.Info("Leaderboard materialized views refreshed",
 		zap.Duration("duration", duration),
 	)
-}
-
-// RefreshNow выполняет немедленное обновление (для использования после важных событий)
-func (r *LeaderboardRefresher) RefreshNow() {
-	r.log.Info("Manual leaderboard refresh requested")
-	r.refresh()
+	return nil
 }
 
 // isUndefinedFunctionError проверяет, является ли ошибка "undefined function"
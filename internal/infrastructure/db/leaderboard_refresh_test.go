@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRefreshLock - in-memory аналог cache.DistributedLock
+type fakeRefreshLock struct {
+	mu       sync.Mutex
+	held     map[string]string
+	unlocked int
+}
+
+func (l *fakeRefreshLock) Lock(_ context.Context, key string, _ time.Duration) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.held[key]; ok {
+		return "", errors.ErrConflict.WithMessage("lock already held")
+	}
+	l.held[key] = "token"
+	return "token", nil
+}
+
+func (l *fakeRefreshLock) Unlock(_ context.Context, key string, token string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[key] != token {
+		return errors.ErrConflict.WithMessage("lock token mismatch")
+	}
+	delete(l.held, key)
+	l.unlocked++
+	return nil
+}
+
+func newTestRefresher(t *testing.T) (*LeaderboardRefresher, *fakeRefreshLock) {
+	log, _ := logger.New("error", "json")
+	conn, err := sql.Open("tracing-stub", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	database := &DB{DB: sqlx.NewDb(conn, "postgres"), log: log}
+	lock := &fakeRefreshLock{held: make(map[string]string)}
+
+	refresher := NewLeaderboardRefresher(database, time.Minute, log)
+	refresher.SetLock(lock)
+	return refresher, lock
+}
+
+func TestLeaderboardRefresher_RefreshReleasesLock(t *testing.T) {
+	refresher, lock := newTestRefresher(t)
+
+	require.NoError(t, refresher.Refresh(context.Background()))
+	require.NoError(t, refresher.Refresh(context.Background()))
+
+	assert.Equal(t, 2, lock.unlocked)
+	assert.Empty(t, lock.held)
+}
+
+func TestLeaderboardRefresher_RefreshSkipsWhenLockHeld(t *testing.T) {
+	refresher, lock := newTestRefresher(t)
+
+	// Another process is refreshing right now
+	token, err := lock.Lock(context.Background(), leaderboardRefreshLockKey, time.Minute)
+	require.NoError(t, err)
+
+	err = refresher.Refresh(context.Background())
+	assert.ErrorIs(t, err, ErrLeaderboardRefreshInProgress)
+	assert.True(t, errors.IsConflict(err))
+
+	require.NoError(t, lock.Unlock(context.Background(), leaderboardRefreshLockKey, token))
+	assert.NoError(t, refresher.Refresh(context.Background()))
+}
+
+func TestLeaderboardRefresher_RefreshSkipsWhenRunningLocally(t *testing.T) {
+	refresher, _ := newTestRefresher(t)
+
+	refresher.mu.Lock()
+	err := refresher.Refresh(context.Background())
+	refresher.mu.Unlock()
+
+	assert.ErrorIs(t, err, ErrLeaderboardRefreshInProgress)
+}
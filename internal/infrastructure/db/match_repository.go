@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -26,10 +27,13 @@ func NewMatchRepository(db *DB) *MatchRepository {
 // Create создаёт новый матч
 func (r *MatchRepository) Create(ctx context.Context, match *domain.Match) error {
 	query := `
-		INSERT INTO matches (id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO matches (id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed, scheduled_at, created_at, is_test, is_validation, is_practice)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
+	// Сид сохраняется, чтобы матч можно было воспроизвести локально
+	match.Seed = match.EffectiveSeed()
+
 	_, err := r.db.ExecContext(ctx, query,
 		match.ID,
 		match.TournamentID,
@@ -39,7 +43,12 @@ func (r *MatchRepository) Create(ctx context.Context, match *domain.Match) error
 		match.Status,
 		match.Priority,
 		match.RoundNumber,
+		match.Seed,
+		match.ScheduledAt,
 		match.CreatedAt,
+		match.IsTest,
+		match.IsValidation,
+		match.IsPractice,
 	)
 
 	if err != nil {
@@ -54,12 +63,14 @@ func (r *MatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Ma
 	var match domain.Match
 
 	query := `
-		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number,
-		       score1, score2, winner, error_code, error_message, started_at, completed_at, created_at
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice,
+		       timing
 		FROM matches
 		WHERE id = $1
 	`
 
+	var timingJSON []byte
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&match.ID,
 		&match.TournamentID,
@@ -69,14 +80,20 @@ func (r *MatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Ma
 		&match.Status,
 		&match.Priority,
 		&match.RoundNumber,
+		&match.Seed,
 		&match.Score1,
 		&match.Score2,
 		&match.Winner,
 		&match.ErrorCode,
 		&match.ErrorMessage,
+		&match.ScheduledAt,
 		&match.StartedAt,
 		&match.CompletedAt,
 		&match.CreatedAt,
+		&match.IsTest,
+		&match.IsValidation,
+		&match.IsPractice,
+		&timingJSON,
 	)
 
 	if err == sql.ErrNoRows {
@@ -86,6 +103,13 @@ func (r *MatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Ma
 		return nil, errors.Wrap(err, "failed to get match by id")
 	}
 
+	if timingJSON != nil {
+		match.Timing = &domain.MatchTiming{}
+		if err := json.Unmarshal(timingJSON, match.Timing); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal match timing")
+		}
+	}
+
 	return &match, nil
 }
 
@@ -94,10 +118,10 @@ func (r *MatchRepository) GetByTournamentID(ctx context.Context, tournamentID uu
 	var matches []*domain.Match
 
 	query := `
-		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number,
-		       score1, score2, winner, error_code, error_message, started_at, completed_at, created_at
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
 		FROM matches
-		WHERE tournament_id = $1
+		WHERE tournament_id = $1 AND NOT is_test
 		ORDER BY round_number DESC, created_at DESC
 		LIMIT $2 OFFSET $3
 	`
@@ -119,14 +143,19 @@ func (r *MatchRepository) GetByTournamentID(ctx context.Context, tournamentID uu
 			&match.Status,
 			&match.Priority,
 			&match.RoundNumber,
+			&match.Seed,
 			&match.Score1,
 			&match.Score2,
 			&match.Winner,
 			&match.ErrorCode,
 			&match.ErrorMessage,
+			&match.ScheduledAt,
 			&match.StartedAt,
 			&match.CompletedAt,
 			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan match")
@@ -142,18 +171,11 @@ func (r *MatchRepository) GetPendingByTournamentID(ctx context.Context, tourname
 	var matches []*domain.Match
 
 	query := `
-		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number,
-		       score1, score2, winner, error_code, error_message, started_at, completed_at, created_at
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
 		FROM matches
 		WHERE tournament_id = $1 AND status = $2
-		ORDER BY
-			CASE priority
-				WHEN 'high' THEN 1
-				WHEN 'medium' THEN 2
-				WHEN 'low' THEN 3
-			END,
-			created_at ASC
-	`
+		ORDER BY ` + pendingOrder
 
 	rows, err := r.db.QueryContext(ctx, query, tournamentID, domain.MatchPending)
 	if err != nil {
@@ -172,14 +194,19 @@ func (r *MatchRepository) GetPendingByTournamentID(ctx context.Context, tourname
 			&match.Status,
 			&match.Priority,
 			&match.RoundNumber,
+			&match.Seed,
 			&match.Score1,
 			&match.Score2,
 			&match.Winner,
 			&match.ErrorCode,
 			&match.ErrorMessage,
+			&match.ScheduledAt,
 			&match.StartedAt,
 			&match.CompletedAt,
 			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan match")
@@ -195,18 +222,11 @@ func (r *MatchRepository) GetPendingByTournamentAndGame(ctx context.Context, tou
 	var matches []*domain.Match
 
 	query := `
-		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number,
-		       score1, score2, winner, error_code, error_message, started_at, completed_at, created_at
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
 		FROM matches
 		WHERE tournament_id = $1 AND game_type = $2 AND status = $3
-		ORDER BY
-			CASE priority
-				WHEN 'high' THEN 1
-				WHEN 'medium' THEN 2
-				WHEN 'low' THEN 3
-			END,
-			created_at ASC
-	`
+		ORDER BY ` + pendingOrder
 
 	rows, err := r.db.QueryContext(ctx, query, tournamentID, gameType, domain.MatchPending)
 	if err != nil {
@@ -225,14 +245,19 @@ func (r *MatchRepository) GetPendingByTournamentAndGame(ctx context.Context, tou
 			&match.Status,
 			&match.Priority,
 			&match.RoundNumber,
+			&match.Seed,
 			&match.Score1,
 			&match.Score2,
 			&match.Winner,
 			&match.ErrorCode,
 			&match.ErrorMessage,
+			&match.ScheduledAt,
 			&match.StartedAt,
 			&match.CompletedAt,
 			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan match")
@@ -260,18 +285,76 @@ func (r *MatchRepository) GetNextRoundNumberByGame(ctx context.Context, tourname
 	return nextRound, nil
 }
 
-// ResetFailedMatches сбрасывает все failed матчи турнира в pending
+// ResetFailedMatches сбрасывает все failed матчи турнира в pending.
+// Вклад сброшенных матчей вычитается из статистики команд
 func (r *MatchRepository) ResetFailedMatches(ctx context.Context, tournamentID uuid.UUID) (int64, error) {
+	var reset int64
+	err := r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		ids, err := lockMatchIDs(ctx, tx, `
+			SELECT id FROM matches WHERE tournament_id = $1 AND status = $2 FOR UPDATE
+		`, tournamentID, domain.MatchFailed)
+		if err != nil {
+			return err
+		}
+		if err := applyTeamGameStats(ctx, tx, ids, -1); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			UPDATE matches
+			SET status = $1, error_code = NULL, error_message = NULL, started_at = NULL, completed_at = NULL,
+			    score1 = NULL, score2 = NULL, winner = NULL
+			WHERE id = ANY($2)
+		`, domain.MatchPending, pq.Array(ids))
+		if err != nil {
+			return errors.Wrap(err, "failed to reset failed matches")
+		}
+
+		if reset, err = result.RowsAffected(); err != nil {
+			return errors.Wrap(err, "failed to get rows affected")
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return reset, nil
+}
+
+// lockMatchIDs блокирует отобранные запросом матчи до конца транзакции и возвращает их ID
+func lockMatchIDs(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]uuid.UUID, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock matches")
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "failed to scan match id")
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to lock matches")
+	}
+	return ids, nil
+}
+
+// CancelPendingByProgram отменяет все ожидающие матчи программы в турнире
+func (r *MatchRepository) CancelPendingByProgram(ctx context.Context, tournamentID, programID uuid.UUID) (int64, error) {
 	query := `
 		UPDATE matches
-		SET status = $1, error_code = NULL, error_message = NULL, started_at = NULL, completed_at = NULL,
-		    score1 = NULL, score2 = NULL, winner = NULL
-		WHERE tournament_id = $2 AND status = $3
+		SET status = $1, completed_at = NOW()
+		WHERE tournament_id = $2 AND (program1_id = $3 OR program2_id = $3) AND status = $4
 	`
 
-	result, err := r.db.ExecContext(ctx, query, domain.MatchPending, tournamentID, domain.MatchFailed)
+	result, err := r.db.ExecContext(ctx, query, domain.MatchCancelled, tournamentID, programID, domain.MatchPending)
 	if err != nil {
-		return 0, errors.Wrap(err, "failed to reset failed matches")
+		return 0, errors.Wrap(err, "failed to cancel pending matches")
 	}
 
 	rows, err := result.RowsAffected()
@@ -282,22 +365,72 @@ func (r *MatchRepository) ResetFailedMatches(ctx context.Context, tournamentID u
 	return rows, nil
 }
 
-// GetPending получает ожидающие матчи по приоритету
+// CancelUnfinishedByTournament отменяет все ожидающие и выполняющиеся матчи турнира.
+// Результат выполняющегося матча потом не запишется: UpdateResult не трогает отменённые матчи
+func (r *MatchRepository) CancelUnfinishedByTournament(ctx context.Context, tournamentID uuid.UUID) (int64, error) {
+	query := `
+		UPDATE matches
+		SET status = $1, completed_at = NOW()
+		WHERE tournament_id = $2 AND status IN ($3, $4)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, domain.MatchCancelled, tournamentID, domain.MatchPending, domain.MatchRunning)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to cancel unfinished matches")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get rows affected")
+	}
+
+	return rows, nil
+}
+
+// DiscardUnfinishedByGame отбрасывает несыгранные матчи игры в турнире: ожидающие удаляются,
+// выполняющиеся отменяются (их результат не запишется). Завершённые матчи не затрагиваются.
+// Удалённые матчи, оставшиеся в очереди, worker пропускает
+func (r *MatchRepository) DiscardUnfinishedByGame(ctx context.Context, tournamentID uuid.UUID, gameType string) (deleted, cancelled int64, err error) {
+	err = r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			DELETE FROM matches
+			WHERE tournament_id = $1 AND game_type = $2 AND status = $3
+		`, tournamentID, gameType, domain.MatchPending)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete pending matches")
+		}
+		if deleted, err = result.RowsAffected(); err != nil {
+			return errors.Wrap(err, "failed to get rows affected")
+		}
+
+		result, err = tx.ExecContext(ctx, `
+			UPDATE matches
+			SET status = $1, completed_at = NOW()
+			WHERE tournament_id = $2 AND game_type = $3 AND status = $4
+		`, domain.MatchCancelled, tournamentID, gameType, domain.MatchRunning)
+		if err != nil {
+			return errors.Wrap(err, "failed to cancel running matches")
+		}
+		if cancelled, err = result.RowsAffected(); err != nil {
+			return errors.Wrap(err, "failed to get rows affected")
+		}
+
+		return nil
+	})
+	return deleted, cancelled, err
+}
+
+// GetPending получает ожидающие матчи по приоритету.
+// Матчи игр с закрытым окном пропускаются: их поставит в очередь запуск следующего окна
 func (r *MatchRepository) GetPending(ctx context.Context, limit int) ([]*domain.Match, error) {
 	var matches []*domain.Match
 
 	query := `
-		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number,
-		       score1, score2, winner, error_code, error_message, started_at, completed_at, created_at
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
 		FROM matches
-		WHERE status = $1
-		ORDER BY
-			CASE priority
-				WHEN 'high' THEN 1
-				WHEN 'medium' THEN 2
-				WHEN 'low' THEN 3
-			END,
-			created_at ASC
+		WHERE status = $1 AND ` + notDeletedTournament + ` AND ` + insideGameWindow + `
+		ORDER BY ` + pendingOrder + `
 		LIMIT $2
 	`
 
@@ -318,14 +451,19 @@ func (r *MatchRepository) GetPending(ctx context.Context, limit int) ([]*domain.
 			&match.Status,
 			&match.Priority,
 			&match.RoundNumber,
+			&match.Seed,
 			&match.Score1,
 			&match.Score2,
 			&match.Winner,
 			&match.ErrorCode,
 			&match.ErrorMessage,
+			&match.ScheduledAt,
 			&match.StartedAt,
 			&match.CompletedAt,
 			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan match")
@@ -336,15 +474,69 @@ func (r *MatchRepository) GetPending(ctx context.Context, limit int) ([]*domain.
 	return matches, nil
 }
 
+// pendingOrder порядок выполнения ожидающих матчей: по текущему приоритету
+// (с учётом изменений через UpdatePriority), затем по времени создания
+const pendingOrder = `
+	CASE priority
+		WHEN 'high' THEN 1
+		WHEN 'medium' THEN 2
+		WHEN 'low' THEN 3
+	END,
+	created_at ASC`
+
+// UpdatePriority изменяет приоритет ожидающего матча.
+// Повышение по возрасту отсчитывается заново от момента изменения
+func (r *MatchRepository) UpdatePriority(ctx context.Context, id uuid.UUID, priority domain.MatchPriority) error {
+	query := `UPDATE matches SET priority = $2, priority_changed_at = $4 WHERE id = $1 AND status = $3`
+
+	result, err := r.db.ExecWithMetrics(ctx, "match_update_priority", query, id, priority, domain.MatchPending, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to update match priority")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rows == 0 {
+		var status domain.MatchStatus
+		err := r.db.QueryRowContext(ctx, `SELECT status FROM matches WHERE id = $1`, id).Scan(&status)
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound.WithMessage("match not found")
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to get match status")
+		}
+		return errors.ErrConflict.WithMessage(fmt.Sprintf("only pending matches can be reprioritized (status %s)", status))
+	}
+
+	return nil
+}
+
+// notDeletedTournament исключает матчи мягко удалённых турниров
+const notDeletedTournament = `tournament_id NOT IN (SELECT id FROM tournaments WHERE deleted_at IS NOT NULL)`
+
+// insideGameWindow исключает матчи игр, окно которых сейчас закрыто
+const insideGameWindow = `NOT EXISTS (
+	SELECT 1 FROM tournament_games tg
+	JOIN games g ON g.id = tg.game_id
+	WHERE tg.tournament_id = matches.tournament_id AND g.name = matches.game_type
+	AND tg.window_start IS NOT NULL
+	AND NOT (NOW() >= tg.window_start AND NOW() < tg.window_end)
+)`
+
 // UpdateStatus обновляет статус матча
 func (r *MatchRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.MatchStatus) error {
 	var query string
 
 	if status == domain.MatchRunning {
+		// Отменённые матчи могут оставаться в очереди - не запускаем их,
+		// завершённые не перезапускаем, чтобы не потерять записанный результат
 		query = `
 			UPDATE matches
 			SET status = $2, started_at = NOW()
-			WHERE id = $1
+			WHERE id = $1 AND status NOT IN ('cancelled', 'completed', 'failed') AND ` + notDeletedTournament + `
 		`
 	} else {
 		query = `
@@ -365,6 +557,9 @@ func (r *MatchRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status
 	}
 
 	if rows == 0 {
+		if status == domain.MatchRunning {
+			return r.resultConflict(ctx, id)
+		}
 		return errors.ErrNotFound.WithMessage("match not found")
 	}
 
@@ -373,11 +568,13 @@ func (r *MatchRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status
 
 // UpdateResult обновляет результат матча
 func (r *MatchRepository) UpdateResult(ctx context.Context, id uuid.UUID, result *domain.MatchResult) error {
+	// Результат записывается только один раз: повторное выполнение матча
+	// (например, после восстановления) не должно перезаписать уже сохранённый результат
 	query := `
 		UPDATE matches
 		SET status = $2, score1 = $3, score2 = $4, winner = $5,
 		    error_code = $6, error_message = $7, completed_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND status IN ($8, $9)
 	`
 
 	status := domain.MatchCompleted
@@ -395,23 +592,80 @@ func (r *MatchRepository) UpdateResult(ctx context.Context, id uuid.UUID, result
 		errorMsg = &result.ErrorMessage
 	}
 
-	_, err := r.db.ExecWithMetrics(ctx, "match_update_result", query,
-		id,
-		status,
-		result.Score1,
-		result.Score2,
-		result.Winner,
-		errorCode,
-		errorMsg,
-	)
+	// Статистика команд для кросс-игрового рейтинга обновляется в той же транзакции
+	updated := false
+	start := time.Now()
+	err := r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, query,
+			id,
+			status,
+			result.Score1,
+			result.Score2,
+			result.Winner,
+			errorCode,
+			errorMsg,
+			domain.MatchRunning,
+			domain.MatchPending,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to update match result")
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "failed to get affected rows")
+		}
+		if rows == 0 {
+			return nil
+		}
+
+		updated = true
+		return applyTeamGameStats(ctx, tx, []uuid.UUID{id}, 1)
+	})
+	r.db.metrics.RecordDBQuery("match_update_result", time.Since(start))
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return r.resultConflict(ctx, id)
+	}
+
+	return nil
+}
 
+// SaveTiming сохраняет длительности этапов обработки матча
+func (r *MatchRepository) SaveTiming(ctx context.Context, id uuid.UUID, timing *domain.MatchTiming) error {
+	data, err := json.Marshal(timing)
 	if err != nil {
-		return errors.Wrap(err, "failed to update match result")
+		return errors.Wrap(err, "failed to marshal match timing")
 	}
 
+	if _, err := r.db.ExecContext(ctx, `UPDATE matches SET timing = $2 WHERE id = $1`, id, data); err != nil {
+		return errors.Wrap(err, "failed to save match timing")
+	}
 	return nil
 }
 
+// resultConflict определяет, почему матч не обновлён: его результат уже записан
+// либо матча нет (удалён, отменён или его турнир удалён)
+func (r *MatchRepository) resultConflict(ctx context.Context, id uuid.UUID) error {
+	// Матчи удалённых турниров не обновляются независимо от статуса - для них матча нет
+	var status domain.MatchStatus
+	err := r.db.QueryRowContext(ctx,
+		`SELECT status FROM matches WHERE id = $1 AND `+notDeletedTournament, id,
+	).Scan(&status)
+	if err != nil && err != sql.ErrNoRows {
+		return errors.Wrap(err, "failed to get match status")
+	}
+
+	if status == domain.MatchCompleted || status == domain.MatchFailed {
+		return errors.ErrConflict.WithMessage(fmt.Sprintf("match result already recorded (status %s)", status))
+	}
+
+	return errors.ErrNotFound.WithMessage("match not found")
+}
+
 // HasStartedMatches проверяет, есть ли запущенные или завершённые матчи для турнира и игры
 // Возвращает true, если есть матчи со статусом running или completed
 func (r *MatchRepository) HasStartedMatches(ctx context.Context, tournamentID uuid.UUID, gameType string) (bool, error) {
@@ -433,14 +687,22 @@ func (r *MatchRepository) HasStartedMatches(ctx context.Context, tournamentID uu
 	return exists, nil
 }
 
+// notReferenceMatch условие на матч m: ни одна из программ не эталонный бот
+const notReferenceMatch = `NOT EXISTS (
+				SELECT 1 FROM programs p
+				WHERE p.id IN (m.program1_id, m.program2_id) AND p.is_reference
+			)`
+
 // HasAnyRunningMatches проверяет, есть ли запущенные (running) или ожидающие (pending) матчи
 // для любой игры в турнире. Используется для блокировки загрузки программ когда раунд активен.
+// Калибровочные матчи с эталонными ботами раундом не считаются
 func (r *MatchRepository) HasAnyRunningMatches(ctx context.Context, tournamentID uuid.UUID) (bool, error) {
 	query := `
 		SELECT EXISTS(
-			SELECT 1 FROM matches
-			WHERE tournament_id = $1
-			AND status IN ($2, $3)
+			SELECT 1 FROM matches m
+			WHERE m.tournament_id = $1
+			AND m.status IN ($2, $3)
+			AND ` + notReferenceMatch + `
 		)
 	`
 
@@ -453,6 +715,28 @@ func (r *MatchRepository) HasAnyRunningMatches(ctx context.Context, tournamentID
 	return exists, nil
 }
 
+// HasRunningMatchesForGame проверяет, есть ли running или pending матчи для игры в турнире.
+// Используется для блокировки загрузки программ этой игры, пока её матчи не сыграны
+func (r *MatchRepository) HasRunningMatchesForGame(ctx context.Context, tournamentID uuid.UUID, gameType string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM matches m
+			WHERE m.tournament_id = $1
+			AND m.game_type = $2
+			AND m.status IN ($3, $4)
+			AND ` + notReferenceMatch + `
+		)
+	`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, tournamentID, gameType, domain.MatchRunning, domain.MatchPending).Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check running matches for game")
+	}
+
+	return exists, nil
+}
+
 // GetActiveGameType возвращает тип игры, для которой сейчас выполняются матчи.
 // Возвращает пустую строку, если нет активных матчей.
 func (r *MatchRepository) GetActiveGameType(ctx context.Context, tournamentID uuid.UUID) (string, error) {
@@ -518,86 +802,140 @@ func (r *MatchRepository) CreateBatch(ctx context.Context, matches []*domain.Mat
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction")
-	}
-	defer func() { _ = tx.Rollback() }()
-
 	query := `
-		INSERT INTO matches (id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO matches (id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed, scheduled_at,
+		                     score1, score2, winner, error_message, completed_at, created_at, is_test, is_validation, is_practice)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
 
-	stmt, err := tx.PrepareContext(ctx, query)
-	if err != nil {
-		return errors.Wrap(err, "failed to prepare statement")
-	}
-	defer stmt.Close()
-
-	for _, match := range matches {
-		_, err := stmt.ExecContext(ctx,
-			match.ID,
-			match.TournamentID,
-			match.Program1ID,
-			match.Program2ID,
-			match.GameType,
-			match.Status,
-			match.Priority,
-			match.RoundNumber,
-			match.CreatedAt,
-		)
+	return r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
 		if err != nil {
-			return errors.Wrap(err, "failed to insert match")
+			return errors.Wrap(err, "failed to prepare statement")
+		}
+		defer stmt.Close()
+
+		for _, match := range matches {
+			match.Seed = match.EffectiveSeed()
+			_, err := stmt.ExecContext(ctx,
+				match.ID,
+				match.TournamentID,
+				match.Program1ID,
+				match.Program2ID,
+				match.GameType,
+				match.Status,
+				match.Priority,
+				match.RoundNumber,
+				match.Seed,
+				match.ScheduledAt,
+				match.Score1,
+				match.Score2,
+				match.Winner,
+				match.ErrorMessage,
+				match.CompletedAt,
+				match.CreatedAt,
+				match.IsTest,
+				match.IsValidation,
+				match.IsPractice,
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to insert match")
+			}
 		}
-	}
-
-	if err := tx.Commit(); err != nil {
-		return errors.Wrap(err, "failed to commit transaction")
-	}
 
-	return nil
+		return nil
+	})
 }
 
-// List получает список матчей с фильтрацией и пагинацией
-func (r *MatchRepository) List(ctx context.Context, filter domain.MatchFilter) ([]*domain.Match, error) {
-	query := `
-		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number,
-		       score1, score2, winner, error_code, error_message, started_at, completed_at, created_at
-		FROM matches
-		WHERE 1=1
-	`
+// matchFilterConditions формирует условия WHERE списка матчей (без пагинации).
+// Возвращает условия, их аргументы и номер следующего аргумента
+func matchFilterConditions(filter domain.MatchFilter) (string, []interface{}, int) {
+	conditions := notDeletedTournament
 	args := []interface{}{}
 	argCount := 1
 
 	// Фильтр по турниру
 	if filter.TournamentID != nil {
-		query += fmt.Sprintf(" AND tournament_id = $%d", argCount)
+		conditions += fmt.Sprintf(" AND tournament_id = $%d", argCount)
 		args = append(args, *filter.TournamentID)
 		argCount++
 	}
 
 	// Фильтр по программе (участвует как program1 или program2)
 	if filter.ProgramID != nil {
-		query += fmt.Sprintf(" AND (program1_id = $%d OR program2_id = $%d)", argCount, argCount)
+		conditions += fmt.Sprintf(" AND (program1_id = $%d OR program2_id = $%d)", argCount, argCount)
 		args = append(args, *filter.ProgramID)
 		argCount++
 	}
 
 	// Фильтр по статусу
 	if filter.Status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argCount)
+		conditions += fmt.Sprintf(" AND status = $%d", argCount)
 		args = append(args, filter.Status)
 		argCount++
 	}
 
 	// Фильтр по типу игры
 	if filter.GameType != "" {
-		query += fmt.Sprintf(" AND game_type = $%d", argCount)
+		conditions += fmt.Sprintf(" AND game_type = $%d", argCount)
 		args = append(args, filter.GameType)
 		argCount++
 	}
 
+	if filter.ExcludeTest {
+		conditions += " AND NOT is_test"
+	}
+
+	if filter.HidePrivate {
+		condition, visibilityArgs, next := hiddenPrivateTournaments(filter.ViewerID, argCount)
+		conditions += condition
+		args = append(args, visibilityArgs...)
+		argCount = next
+	}
+
+	return conditions, args, argCount
+}
+
+// hiddenPrivateTournaments исключает матчи приватных турниров, которые viewer не видит:
+// турнир виден создателю и участникам его команд, анонимным пользователям - ни один
+func hiddenPrivateTournaments(viewerID *uuid.UUID, argCount int) (string, []interface{}, int) {
+	if viewerID == nil {
+		return fmt.Sprintf(" AND tournament_id NOT IN (SELECT id FROM tournaments WHERE visibility = $%d)", argCount),
+			[]interface{}{domain.VisibilityPrivate}, argCount + 1
+	}
+
+	condition := fmt.Sprintf(` AND tournament_id NOT IN (
+		SELECT t.id FROM tournaments t
+		WHERE t.visibility = $%d AND t.creator_id IS DISTINCT FROM $%d AND NOT EXISTS (
+			SELECT 1 FROM team_members tm
+			INNER JOIN teams tt ON tm.team_id = tt.id
+			WHERE tt.tournament_id = t.id AND tm.user_id = $%d
+		)
+	)`, argCount, argCount+1, argCount+1)
+	return condition, []interface{}{domain.VisibilityPrivate, *viewerID}, argCount + 2
+}
+
+// CountWithFilter считает матчи по тем же фильтрам, что и List, без учёта limit/offset
+func (r *MatchRepository) CountWithFilter(ctx context.Context, filter domain.MatchFilter) (int, error) {
+	conditions, args, _ := matchFilterConditions(filter)
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM matches WHERE "+conditions, args...).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, "failed to count matches")
+	}
+
+	return count, nil
+}
+
+// List получает список матчей с фильтрацией и пагинацией
+func (r *MatchRepository) List(ctx context.Context, filter domain.MatchFilter) ([]*domain.Match, error) {
+	conditions, args, argCount := matchFilterConditions(filter)
+	query := `
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
+		FROM matches
+		WHERE ` + conditions
+
 	// Сортировка (по умолчанию - сначала новые раунды)
 	query += " ORDER BY round_number DESC, created_at DESC"
 
@@ -630,14 +968,19 @@ func (r *MatchRepository) List(ctx context.Context, filter domain.MatchFilter) (
 			&match.Status,
 			&match.Priority,
 			&match.RoundNumber,
+			&match.Seed,
 			&match.Score1,
 			&match.Score2,
 			&match.Winner,
 			&match.ErrorCode,
 			&match.ErrorMessage,
+			&match.ScheduledAt,
 			&match.StartedAt,
 			&match.CompletedAt,
 			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan match")
@@ -655,8 +998,8 @@ func (r *MatchRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*dom
 	}
 
 	query := `
-		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number,
-		       score1, score2, winner, error_code, error_message, started_at, completed_at, created_at
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
 		FROM matches
 		WHERE id = ANY($1)
 		ORDER BY round_number DESC, created_at DESC
@@ -680,14 +1023,19 @@ func (r *MatchRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*dom
 			&match.Status,
 			&match.Priority,
 			&match.RoundNumber,
+			&match.Seed,
 			&match.Score1,
 			&match.Score2,
 			&match.Winner,
 			&match.ErrorCode,
 			&match.ErrorMessage,
+			&match.ScheduledAt,
 			&match.StartedAt,
 			&match.CompletedAt,
 			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan match")
@@ -708,12 +1056,6 @@ func (r *MatchRepository) BatchUpdateStatus(ctx context.Context, matchIDs []uuid
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction")
-	}
-	defer func() { _ = tx.Rollback() }()
-
 	var query string
 	if status == domain.MatchRunning {
 		query = `
@@ -729,78 +1071,89 @@ func (r *MatchRepository) BatchUpdateStatus(ctx context.Context, matchIDs []uuid
 		`
 	}
 
-	_, err = tx.ExecContext(ctx, query, status, pq.Array(matchIDs))
-	if err != nil {
-		return errors.Wrap(err, "failed to batch update match status")
-	}
-
-	if err := tx.Commit(); err != nil {
-		return errors.Wrap(err, "failed to commit transaction")
-	}
-
-	return nil
+	return r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, status, pq.Array(matchIDs)); err != nil {
+			return errors.Wrap(err, "failed to batch update match status")
+		}
+		return nil
+	})
 }
 
 // BatchUpdateResults обновляет результаты для нескольких матчей одновременно
-func (r *MatchRepository) BatchUpdateResults(ctx context.Context, results map[uuid.UUID]*domain.MatchResult) error {
+// Возвращает ID матчей, результат которых уже был записан ранее (они не изменяются)
+func (r *MatchRepository) BatchUpdateResults(ctx context.Context, results map[uuid.UUID]*domain.MatchResult) ([]uuid.UUID, error) {
 	if len(results) == 0 {
-		return nil
-	}
-
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction")
+		return nil, nil
 	}
-	defer func() { _ = tx.Rollback() }()
 
+	// Как и в UpdateResult, завершённые матчи не перезаписываются
 	query := `
 		UPDATE matches
 		SET status = $2, score1 = $3, score2 = $4, winner = $5,
 		    error_code = $6, error_message = $7, completed_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND status IN ($8, $9)
 	`
 
-	stmt, err := tx.PrepareContext(ctx, query)
-	if err != nil {
-		return errors.Wrap(err, "failed to prepare statement")
-	}
-	defer stmt.Close()
-
-	for matchID, result := range results {
-		status := domain.MatchCompleted
-		if result.ErrorCode != 0 {
-			status = domain.MatchFailed
+	var conflicts []uuid.UUID
+	err := r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		updated := make([]uuid.UUID, 0, len(results))
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return errors.Wrap(err, "failed to prepare statement")
 		}
+		defer stmt.Close()
 
-		var errorCode *int
-		if result.ErrorCode != 0 {
-			errorCode = &result.ErrorCode
-		}
+		for matchID, result := range results {
+			status := domain.MatchCompleted
+			if result.ErrorCode != 0 {
+				status = domain.MatchFailed
+			}
 
-		var errorMsg *string
-		if result.ErrorMessage != "" {
-			errorMsg = &result.ErrorMessage
-		}
+			var errorCode *int
+			if result.ErrorCode != 0 {
+				errorCode = &result.ErrorCode
+			}
 
-		_, err := stmt.ExecContext(ctx,
-			matchID,
-			status,
-			result.Score1,
-			result.Score2,
-			result.Winner,
-			errorCode,
-			errorMsg,
-		)
-		if err != nil {
-			return errors.Wrap(err, "failed to update match result in batch")
+			var errorMsg *string
+			if result.ErrorMessage != "" {
+				errorMsg = &result.ErrorMessage
+			}
+
+			res, err := stmt.ExecContext(ctx,
+				matchID,
+				status,
+				result.Score1,
+				result.Score2,
+				result.Winner,
+				errorCode,
+				errorMsg,
+				domain.MatchRunning,
+				domain.MatchPending,
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to update match result in batch")
+			}
+
+			rows, err := res.RowsAffected()
+			if err != nil {
+				return errors.Wrap(err, "failed to get affected rows")
+			}
+
+			// Матч уже завершён (или удалён) - пропускаем его, остальные результаты сохраняем
+			if rows == 0 {
+				conflicts = append(conflicts, matchID)
+				continue
+			}
+			updated = append(updated, matchID)
 		}
-	}
 
-	if err := tx.Commit(); err != nil {
-		return errors.Wrap(err, "failed to commit transaction")
+		return applyTeamGameStats(ctx, tx, updated, 1)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return conflicts, nil
 }
 
 // ListWithCursor получает список матчей с cursor-based пагинацией
@@ -811,65 +1164,37 @@ func (r *MatchRepository) ListWithCursor(ctx context.Context, filter domain.Matc
 	}
 
 	// Получаем курсор
-	cursor, err := pageReq.GetCursor()
+	cursor, err := pageReq.GetKeysetCursor()
 	if err != nil {
-		return nil, false, errors.Wrap(err, "failed to decode cursor")
+		return nil, false, errors.ErrInvalidInput.WithMessage("invalid cursor")
 	}
 
-	// Базовый запрос
+	// Базовый запрос с теми же фильтрами, что и List
+	conditions, args, argCount := matchFilterConditions(filter)
 	query := `
-		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number,
-		       score1, score2, winner, error_code, error_message, started_at, completed_at, created_at
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
 		FROM matches
-		WHERE 1=1
+		WHERE ` + conditions + `
 	`
-	args := []interface{}{}
-	argCount := 1
-
-	// Фильтр по турниру
-	if filter.TournamentID != nil {
-		query += fmt.Sprintf(" AND tournament_id = $%d", argCount)
-		args = append(args, *filter.TournamentID)
-		argCount++
-	}
-
-	// Фильтр по программе
-	if filter.ProgramID != nil {
-		query += fmt.Sprintf(" AND (program1_id = $%d OR program2_id = $%d)", argCount, argCount)
-		args = append(args, *filter.ProgramID)
-		argCount++
-	}
-
-	// Фильтр по статусу
-	if filter.Status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argCount)
-		args = append(args, filter.Status)
-		argCount++
-	}
 
-	// Фильтр по типу игры
-	if filter.GameType != "" {
-		query += fmt.Sprintf(" AND game_type = $%d", argCount)
-		args = append(args, filter.GameType)
-		argCount++
-	}
-
-	// Применяем курсор для пагинации
-	if cursor != nil && cursor.Type == pagination.CursorTypeTimestamp && cursor.Timestamp != nil {
-		if pageReq.IsForward() {
-			query += fmt.Sprintf(" AND created_at < $%d", argCount)
-		} else {
-			query += fmt.Sprintf(" AND created_at > $%d", argCount)
+	// Применяем курсор: сравнение строк (round_number, id) задаёт строгий порядок,
+	// поэтому матчи, вставленные между запросами страниц, не сдвигают уже выданные
+	if cursor != nil {
+		op := "<"
+		if pageReq.IsBackward() {
+			op = ">"
 		}
-		args = append(args, *cursor.Timestamp)
-		argCount++
+		query += fmt.Sprintf(" AND (round_number, id) %s ($%d, $%d)", op, argCount, argCount+1)
+		args = append(args, cursor.Round, cursor.ID)
+		argCount += 2
 	}
 
 	// Сортировка
 	if pageReq.IsBackward() {
-		query += " ORDER BY round_number ASC, created_at ASC"
+		query += " ORDER BY round_number ASC, id ASC"
 	} else {
-		query += " ORDER BY round_number DESC, created_at DESC"
+		query += " ORDER BY round_number DESC, id DESC"
 	}
 
 	// Добавляем +1 к лимиту для определения hasNextPage
@@ -895,14 +1220,19 @@ func (r *MatchRepository) ListWithCursor(ctx context.Context, filter domain.Matc
 			&match.Status,
 			&match.Priority,
 			&match.RoundNumber,
+			&match.Seed,
 			&match.Score1,
 			&match.Score2,
 			&match.Winner,
 			&match.ErrorCode,
 			&match.ErrorMessage,
+			&match.ScheduledAt,
 			&match.StartedAt,
 			&match.CompletedAt,
 			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
 		)
 		if err != nil {
 			return nil, false, errors.Wrap(err, "failed to scan match")
@@ -926,9 +1256,14 @@ func (r *MatchRepository) ListWithCursor(ctx context.Context, filter domain.Matc
 	return matches, hasMore, nil
 }
 
-// GetMatchCursor возвращает курсор для матча (для использования с pagination.NewConnection)
-func GetMatchCursor(match *domain.Match) (*pagination.Cursor, error) {
-	return pagination.NewTimestampCursor(match.CreatedAt), nil
+// GetMatchKeysetCursor возвращает закодированный курсор матча для ListWithCursor
+func GetMatchKeysetCursor(match *domain.Match) string {
+	return MatchKeysetCursor(match).Encode()
+}
+
+// MatchKeysetCursor возвращает позицию матча в порядке (round_number, id)
+func MatchKeysetCursor(match *domain.Match) pagination.KeysetCursor {
+	return pagination.KeysetCursor{Round: match.RoundNumber, ID: match.ID}
 }
 
 // GetStuckRunning получает матчи, застрявшие в статусе running дольше указанного времени
@@ -936,10 +1271,10 @@ func (r *MatchRepository) GetStuckRunning(ctx context.Context, stuckDuration tim
 	var matches []*domain.Match
 
 	query := `
-		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number,
-		       score1, score2, winner, error_code, error_message, started_at, completed_at, created_at
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
 		FROM matches
-		WHERE status = $1 AND started_at < $2
+		WHERE status = $1 AND started_at < $2 AND ` + notDeletedTournament + `
 		ORDER BY started_at ASC
 		LIMIT $3
 	`
@@ -963,14 +1298,87 @@ func (r *MatchRepository) GetStuckRunning(ctx context.Context, stuckDuration tim
 			&match.Status,
 			&match.Priority,
 			&match.RoundNumber,
+			&match.Seed,
+			&match.Score1,
+			&match.Score2,
+			&match.Winner,
+			&match.ErrorCode,
+			&match.ErrorMessage,
+			&match.ScheduledAt,
+			&match.StartedAt,
+			&match.CompletedAt,
+			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan match")
+		}
+		matches = append(matches, &match)
+	}
+
+	return matches, nil
+}
+
+// EscalateOldPendingMatches поднимает на ступень приоритет (low -> medium, medium -> high)
+// pending матчей, приоритет которых не менялся дольше maxAge (с момента создания или
+// прошлого повышения), и возвращает их с новым приоритетом. Каждая ступень ждёт полный maxAge.
+// Матчи тренировочных турниров не повышаются, как и при старении в очереди.
+// Строки, заблокированные другим worker'ом, пропускаются
+func (r *MatchRepository) EscalateOldPendingMatches(ctx context.Context, maxAge time.Duration, batchSize int) ([]*domain.Match, error) {
+	query := `
+		UPDATE matches m
+		SET priority = CASE m.priority WHEN 'low' THEN 'medium' ELSE 'high' END,
+		    priority_changed_at = $4
+		FROM (
+			SELECT id FROM matches
+			WHERE status = $1 AND priority IN ('low', 'medium') AND NOT is_practice
+			  AND COALESCE(priority_changed_at, created_at) < $2
+			ORDER BY COALESCE(priority_changed_at, created_at) ASC
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		) stale
+		WHERE m.id = stale.id
+		RETURNING m.id, m.tournament_id, m.program1_id, m.program2_id, m.game_type, m.status, m.priority, m.round_number, m.seed,
+		          m.score1, m.score2, m.winner, m.error_code, m.error_message, m.scheduled_at, m.started_at, m.completed_at, m.created_at,
+		          m.is_test, m.is_validation, m.is_practice
+	`
+
+	now := time.Now()
+	threshold := now.Add(-maxAge)
+
+	rows, err := r.db.QueryContext(ctx, query, domain.MatchPending, threshold, batchSize, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to escalate pending matches")
+	}
+	defer rows.Close()
+
+	var matches []*domain.Match
+	for rows.Next() {
+		var match domain.Match
+		err := rows.Scan(
+			&match.ID,
+			&match.TournamentID,
+			&match.Program1ID,
+			&match.Program2ID,
+			&match.GameType,
+			&match.Status,
+			&match.Priority,
+			&match.RoundNumber,
+			&match.Seed,
 			&match.Score1,
 			&match.Score2,
 			&match.Winner,
 			&match.ErrorCode,
 			&match.ErrorMessage,
+			&match.ScheduledAt,
 			&match.StartedAt,
 			&match.CompletedAt,
 			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan match")
@@ -978,6 +1386,10 @@ func (r *MatchRepository) GetStuckRunning(ctx context.Context, stuckDuration tim
 		matches = append(matches, &match)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows iteration error")
+	}
+
 	return matches, nil
 }
 
@@ -1012,7 +1424,7 @@ func (r *MatchRepository) GetMatchesByRounds(ctx context.Context, tournamentID u
 			COUNT(*) FILTER (WHERE status = 'failed') as failed_count,
 			MIN(created_at) as created_at
 		FROM matches
-		WHERE tournament_id = $1
+		WHERE tournament_id = $1 AND NOT is_test
 		GROUP BY round_number, game_type
 		ORDER BY MIN(created_at) DESC
 	`
@@ -1045,10 +1457,10 @@ func (r *MatchRepository) GetMatchesByRounds(ctx context.Context, tournamentID u
 	// Теперь получаем матчи для каждого раунда и игры
 	for _, round := range rounds {
 		matchQuery := `
-			SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number,
-			       score1, score2, winner, error_code, error_message, started_at, completed_at, created_at
+			SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+			       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
 			FROM matches
-			WHERE tournament_id = $1 AND round_number = $2 AND game_type = $3
+			WHERE tournament_id = $1 AND round_number = $2 AND game_type = $3 AND NOT is_test
 			ORDER BY created_at ASC
 		`
 
@@ -1068,14 +1480,19 @@ func (r *MatchRepository) GetMatchesByRounds(ctx context.Context, tournamentID u
 				&match.Status,
 				&match.Priority,
 				&match.RoundNumber,
+				&match.Seed,
 				&match.Score1,
 				&match.Score2,
 				&match.Winner,
 				&match.ErrorCode,
 				&match.ErrorMessage,
+				&match.ScheduledAt,
 				&match.StartedAt,
 				&match.CompletedAt,
 				&match.CreatedAt,
+				&match.IsTest,
+				&match.IsValidation,
+				&match.IsPractice,
 			)
 			if err != nil {
 				matchRows.Close()
@@ -1089,6 +1506,325 @@ func (r *MatchRepository) GetMatchesByRounds(ctx context.Context, tournamentID u
 	return rounds, nil
 }
 
+// GetByRound получает страницу матчей одного раунда турнира.
+// Для несуществующего раунда возвращает пустой список
+func (r *MatchRepository) GetByRound(ctx context.Context, tournamentID uuid.UUID, roundNumber, limit, offset int) ([]*domain.Match, error) {
+	// id в сортировке делает порядок стабильным между страницами при одинаковом created_at
+	query := `
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
+		FROM matches
+		WHERE tournament_id = $1 AND round_number = $2
+		ORDER BY created_at ASC, id ASC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tournamentID, roundNumber, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get matches by round")
+	}
+	defer rows.Close()
+
+	matches := make([]*domain.Match, 0)
+	for rows.Next() {
+		var match domain.Match
+		err := rows.Scan(
+			&match.ID,
+			&match.TournamentID,
+			&match.Program1ID,
+			&match.Program2ID,
+			&match.GameType,
+			&match.Status,
+			&match.Priority,
+			&match.RoundNumber,
+			&match.Seed,
+			&match.Score1,
+			&match.Score2,
+			&match.Winner,
+			&match.ErrorCode,
+			&match.ErrorMessage,
+			&match.ScheduledAt,
+			&match.StartedAt,
+			&match.CompletedAt,
+			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan match")
+		}
+		matches = append(matches, &match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows iteration error")
+	}
+
+	return matches, nil
+}
+
+// GetRoundStats считает матчи раунда по статусам
+func (r *MatchRepository) GetRoundStats(ctx context.Context, tournamentID uuid.UUID, roundNumber int) (*domain.TournamentMatchStats, error) {
+	query := `
+		SELECT
+			COUNT(*) as total,
+			COUNT(*) FILTER (WHERE status = 'pending') as pending,
+			COUNT(*) FILTER (WHERE status = 'running') as running,
+			COUNT(*) FILTER (WHERE status = 'completed') as completed,
+			COUNT(*) FILTER (WHERE status = 'failed') as failed,
+			COUNT(*) FILTER (WHERE status = 'cancelled') as cancelled
+		FROM matches
+		WHERE tournament_id = $1 AND round_number = $2
+	`
+
+	var stats domain.TournamentMatchStats
+	err := r.db.QueryRowContext(ctx, query, tournamentID, roundNumber).Scan(
+		&stats.Total,
+		&stats.Pending,
+		&stats.Running,
+		&stats.Completed,
+		&stats.Failed,
+		&stats.Cancelled,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get round statistics")
+	}
+
+	return &stats, nil
+}
+
+// GetMatchCountByProgram возвращает количество матчей программы (с любой стороны) в статусе status
+func (r *MatchRepository) GetMatchCountByProgram(ctx context.Context, programID uuid.UUID, status domain.MatchStatus) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM matches
+		WHERE (program1_id = $1 OR program2_id = $1) AND status = $2
+	`
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query, programID, status).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, "failed to count program matches")
+	}
+
+	return count, nil
+}
+
+// GetProgramStats возвращает статистику сыгранных матчей программы одним запросом.
+// Результат считается со стороны программы: победа program2 - поражение, если программа играла первой.
+// Ничья - только завершённый матч с winner = 0; упавшие матчи (даже с технической победой) считаются отдельно
+func (r *MatchRepository) GetProgramStats(ctx context.Context, programID uuid.UUID) (*domain.ProgramStats, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status IN ('completed', 'failed')) AS total_matches,
+			COUNT(*) FILTER (WHERE status = 'completed' AND winner = CASE WHEN program1_id = $1 THEN 1 ELSE 2 END) AS wins,
+			COUNT(*) FILTER (WHERE status = 'completed' AND winner = CASE WHEN program1_id = $1 THEN 2 ELSE 1 END) AS losses,
+			COUNT(*) FILTER (WHERE status = 'completed' AND winner = 0) AS draws,
+			COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+			COALESCE(AVG(CASE WHEN program1_id = $1 THEN score1 ELSE score2 END) FILTER (WHERE status = 'completed'), 0) AS avg_score,
+			(SELECT COUNT(DISTINCT tournament_id) FROM tournament_participants WHERE program_id = $1) AS tournaments_participated
+		FROM matches
+		WHERE program1_id = $1 OR program2_id = $1
+	`
+
+	stats := domain.ProgramStats{ProgramID: programID}
+	err := r.db.QueryRowContext(ctx, query, programID).Scan(
+		&stats.TotalMatches,
+		&stats.Wins,
+		&stats.Losses,
+		&stats.Draws,
+		&stats.Failed,
+		&stats.AvgScore,
+		&stats.TournamentsParticipated,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get program statistics")
+	}
+
+	return &stats, nil
+}
+
+// headToHeadMatchesLimit сколько последних личных встреч возвращается списком (счёт учитывает все)
+const headToHeadMatchesLimit = 100
+
+// GetHeadToHead возвращает личные встречи программ a и b: счёт со стороны a и последние матчи.
+// Программа может быть как program1, так и program2. tournamentID = nil - все турниры.
+// Тестовые матчи не учитываются
+func (r *MatchRepository) GetHeadToHead(ctx context.Context, a, b uuid.UUID, tournamentID *uuid.UUID) (*domain.HeadToHeadRecord, error) {
+	const between = `
+		((program1_id = $1 AND program2_id = $2) OR (program1_id = $2 AND program2_id = $1))
+		AND ($3::uuid IS NULL OR tournament_id = $3)
+		AND NOT is_test
+	`
+
+	record := &domain.HeadToHeadRecord{ProgramA: a, ProgramB: b, TournamentID: tournamentID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE winner = CASE WHEN program1_id = $1 THEN 1 ELSE 2 END),
+			COUNT(*) FILTER (WHERE winner = CASE WHEN program1_id = $1 THEN 2 ELSE 1 END),
+			COUNT(*) FILTER (WHERE winner = 0)
+		FROM matches
+		WHERE status = 'completed' AND `+between, a, b, tournamentID).Scan(
+		&record.Wins,
+		&record.Losses,
+		&record.Draws,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get head-to-head record")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
+		FROM matches
+		WHERE `+between+`
+		ORDER BY created_at DESC
+		LIMIT $4
+	`, a, b, tournamentID, headToHeadMatchesLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get head-to-head matches")
+	}
+	defer rows.Close()
+
+	record.Matches = []*domain.Match{}
+	for rows.Next() {
+		var match domain.Match
+		err := rows.Scan(
+			&match.ID,
+			&match.TournamentID,
+			&match.Program1ID,
+			&match.Program2ID,
+			&match.GameType,
+			&match.Status,
+			&match.Priority,
+			&match.RoundNumber,
+			&match.Seed,
+			&match.Score1,
+			&match.Score2,
+			&match.Winner,
+			&match.ErrorCode,
+			&match.ErrorMessage,
+			&match.ScheduledAt,
+			&match.StartedAt,
+			&match.CompletedAt,
+			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan match")
+		}
+		record.Matches = append(record.Matches, &match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows iteration error")
+	}
+
+	return record, nil
+}
+
+// GetTeamHeadToHead возвращает личные встречи команд a и b в турнире со стороны a,
+// сгруппированные по играм, со счётом и последними матчами каждой игры.
+// Команде принадлежат все версии её программ. gameType = "" - все игры.
+// Тестовые матчи не учитываются
+func (r *MatchRepository) GetTeamHeadToHead(ctx context.Context, tournamentID, a, b uuid.UUID, gameType string) (*domain.TeamHeadToHead, error) {
+	// Счёт игры считается оконными функциями по всем матчам, а строками
+	// возвращаются только последние headToHeadMatchesLimit матчей игры
+	query := `
+		WITH h2h AS (
+			SELECT m.*, CASE WHEN pa.team_id = $2 THEN 1 ELSE 2 END AS side
+			FROM matches m
+			JOIN programs pa ON pa.id = m.program1_id
+			JOIN programs pb ON pb.id = m.program2_id
+			WHERE m.tournament_id = $1
+			  AND ((pa.team_id = $2 AND pb.team_id = $3) OR (pa.team_id = $3 AND pb.team_id = $2))
+			  AND ($4::text = '' OR m.game_type = $4)
+			  AND NOT m.is_test
+		),
+		ranked AS (
+			SELECT h2h.*,
+			       COUNT(*) FILTER (WHERE status = 'completed') OVER game AS played,
+			       COUNT(*) FILTER (WHERE status = 'completed' AND winner = side) OVER game AS wins,
+			       COUNT(*) FILTER (WHERE status = 'completed' AND winner = 3 - side) OVER game AS losses,
+			       COUNT(*) FILTER (WHERE status = 'completed' AND winner = 0) OVER game AS draws,
+			       COALESCE(SUM(CASE WHEN side = 1 THEN score1 ELSE score2 END) FILTER (WHERE status = 'completed') OVER game, 0) AS score_for,
+			       COALESCE(SUM(CASE WHEN side = 1 THEN score2 ELSE score1 END) FILTER (WHERE status = 'completed') OVER game, 0) AS score_against,
+			       ROW_NUMBER() OVER (PARTITION BY game_type ORDER BY created_at DESC, id) AS rn
+			FROM h2h
+			WINDOW game AS (PARTITION BY game_type)
+		)
+		SELECT played, wins, losses, draws, score_for, score_against,
+		       id, tournament_id, program1_id, program2_id, game_type, status, priority, round_number, seed,
+		       score1, score2, winner, error_code, error_message, scheduled_at, started_at, completed_at, created_at, is_test, is_validation, is_practice
+		FROM ranked
+		WHERE rn <= $5
+		ORDER BY game_type, created_at DESC, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tournamentID, a, b, gameType, headToHeadMatchesLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get team head-to-head")
+	}
+	defer rows.Close()
+
+	record := &domain.TeamHeadToHead{TournamentID: tournamentID, TeamA: a, TeamB: b, Games: []*domain.GameHeadToHead{}}
+	var game *domain.GameHeadToHead
+	for rows.Next() {
+		var (
+			totals domain.HeadToHeadTotals
+			match  domain.Match
+		)
+		err := rows.Scan(
+			&totals.Matches,
+			&totals.Wins,
+			&totals.Losses,
+			&totals.Draws,
+			&totals.ScoreFor,
+			&totals.ScoreAgainst,
+			&match.ID,
+			&match.TournamentID,
+			&match.Program1ID,
+			&match.Program2ID,
+			&match.GameType,
+			&match.Status,
+			&match.Priority,
+			&match.RoundNumber,
+			&match.Seed,
+			&match.Score1,
+			&match.Score2,
+			&match.Winner,
+			&match.ErrorCode,
+			&match.ErrorMessage,
+			&match.ScheduledAt,
+			&match.StartedAt,
+			&match.CompletedAt,
+			&match.CreatedAt,
+			&match.IsTest,
+			&match.IsValidation,
+			&match.IsPractice,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan head-to-head match")
+		}
+
+		// Строки упорядочены по игре: новая игра начинается со смены game_type
+		if game == nil || game.GameType != match.GameType {
+			game = &domain.GameHeadToHead{GameType: match.GameType, HeadToHeadTotals: totals}
+			record.Games = append(record.Games, game)
+			record.Totals.Add(totals)
+		}
+		game.RecentMatches = append(game.RecentMatches, &match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows iteration error")
+	}
+
+	return record, nil
+}
+
 // MatchStatistics - статистика матчей
 type MatchStatistics struct {
 	Total     int `json:"total"`
@@ -1098,19 +1834,35 @@ type MatchStatistics struct {
 	Failed    int `json:"failed"`
 }
 
-// DeleteMatchesForGame удаляет все матчи турнира для определённой игры
+// DeleteMatchesForGame удаляет все матчи турнира для определённой игры.
+// Вклад удалённых матчей вычитается из статистики команд
 func (r *MatchRepository) DeleteMatchesForGame(ctx context.Context, tournamentID uuid.UUID, gameType string) (int64, error) {
-	query := `DELETE FROM matches WHERE tournament_id = $1 AND game_type = $2`
+	var deleted int64
+	err := r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		ids, err := lockMatchIDs(ctx, tx, `
+			SELECT id FROM matches WHERE tournament_id = $1 AND game_type = $2 FOR UPDATE
+		`, tournamentID, gameType)
+		if err != nil {
+			return err
+		}
+		if err := applyTeamGameStats(ctx, tx, ids, -1); err != nil {
+			return err
+		}
 
-	result, err := r.db.ExecContext(ctx, query, tournamentID, gameType)
-	if err != nil {
-		return 0, errors.Wrap(err, "failed to delete matches for game")
-	}
+		// Матчи, созданные после блокировки, ещё не сыграны и в статистику не входят
+		result, err := tx.ExecContext(ctx, `DELETE FROM matches WHERE tournament_id = $1 AND game_type = $2`, tournamentID, gameType)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete matches for game")
+		}
 
-	rows, err := result.RowsAffected()
+		if deleted, err = result.RowsAffected(); err != nil {
+			return errors.Wrap(err, "failed to get rows affected")
+		}
+		return nil
+	})
 	if err != nil {
-		return 0, errors.Wrap(err, "failed to get rows affected")
+		return 0, err
 	}
 
-	return rows, nil
+	return deleted, nil
 }
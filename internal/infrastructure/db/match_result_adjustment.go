@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// AdjustResult применяет ручную правку результата матча и записывает её в историю.
+// Правка возможна только у матча с результатом (completed или failed). Прежний
+// результат заполняется в adjustment; статистика команд пересчитывается в той же транзакции
+func (r *MatchRepository) AdjustResult(ctx context.Context, adjustment *domain.MatchResultAdjustment) error {
+	start := time.Now()
+	err := r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			SELECT status, score1, score2, winner FROM matches WHERE id = $1 FOR UPDATE
+		`, adjustment.MatchID).Scan(&adjustment.OldStatus, &adjustment.OldScore1, &adjustment.OldScore2, &adjustment.OldWinner)
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return errors.ErrNotFound.WithMessage("match not found")
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to lock match")
+		}
+
+		if adjustment.OldStatus != domain.MatchCompleted && adjustment.OldStatus != domain.MatchFailed {
+			return errors.ErrConflict.WithMessage("match has no result to adjust")
+		}
+
+		ids := []uuid.UUID{adjustment.MatchID}
+		if err := applyTeamGameStats(ctx, tx, ids, -1); err != nil {
+			return err
+		}
+
+		switch adjustment.Action {
+		case domain.MatchAdjustmentEdit:
+			_, err = tx.ExecContext(ctx, `
+				UPDATE matches
+				SET status = $2, score1 = $3, score2 = $4, winner = $5, error_code = NULL, error_message = NULL,
+				    completed_at = COALESCE(completed_at, NOW())
+				WHERE id = $1
+			`, adjustment.MatchID, domain.MatchCompleted, adjustment.NewScore1, adjustment.NewScore2, adjustment.NewWinner)
+			if err != nil {
+				return errors.Wrap(err, "failed to update match result")
+			}
+			if err := applyTeamGameStats(ctx, tx, ids, 1); err != nil {
+				return err
+			}
+		case domain.MatchAdjustmentAnnul:
+			_, err = tx.ExecContext(ctx, `
+				UPDATE matches
+				SET status = $2, error_code = NULL, error_message = NULL, started_at = NULL, completed_at = NULL,
+				    score1 = NULL, score2 = NULL, winner = NULL
+				WHERE id = $1
+			`, adjustment.MatchID, domain.MatchPending)
+			if err != nil {
+				return errors.Wrap(err, "failed to annul match result")
+			}
+		default:
+			return errors.ErrValidation.WithMessage("unknown adjustment action")
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO match_result_adjustments (match_id, action, old_status, old_score1, old_score2, old_winner,
+			                                      new_score1, new_score2, new_winner, reason, adjusted_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING id, created_at
+		`,
+			adjustment.MatchID,
+			adjustment.Action,
+			adjustment.OldStatus,
+			adjustment.OldScore1,
+			adjustment.OldScore2,
+			adjustment.OldWinner,
+			adjustment.NewScore1,
+			adjustment.NewScore2,
+			adjustment.NewWinner,
+			adjustment.Reason,
+			adjustment.AdjustedBy,
+		).Scan(&adjustment.ID, &adjustment.CreatedAt)
+		if err != nil {
+			return errors.Wrap(err, "failed to record match result adjustment")
+		}
+		return nil
+	})
+	r.db.metrics.RecordDBQuery("match_adjust_result", time.Since(start))
+	return err
+}
+
+// GetResultAdjustments возвращает историю ручных правок результата матча, новые первыми
+func (r *MatchRepository) GetResultAdjustments(ctx context.Context, matchID uuid.UUID) ([]*domain.MatchResultAdjustment, error) {
+	query := `
+		SELECT id, match_id, action, old_status, old_score1, old_score2, old_winner,
+		       new_score1, new_score2, new_winner, reason, adjusted_by, created_at
+		FROM match_result_adjustments
+		WHERE match_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	adjustments := []*domain.MatchResultAdjustment{}
+	if err := r.db.QueryWithMetrics(ctx, "match_get_result_adjustments", &adjustments, query, matchID); err != nil {
+		return nil, errors.Wrap(err, "failed to get match result adjustments")
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// NotificationRepository - репозиторий outbox уведомлений
+type NotificationRepository struct {
+	db *DB
+}
+
+// NewNotificationRepository создаёт новый репозиторий уведомлений
+func NewNotificationRepository(db *DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// notificationColumns колонки outbox в порядке полей domain.Notification
+const notificationColumns = `id, user_id, webhook_id, channel, recipient, event, subject, body, dedupe_key,
+	       status, attempts, next_attempt_at, last_error, created_at, sent_at`
+
+// enqueueNotificationQuery записывает уведомление каждому получателю из подзапроса recipients,
+// подписанному на событие. Повторное событие с тем же dedupe_key пропускается
+const enqueueNotificationQuery = `
+	INSERT INTO notification_outbox (user_id, channel, recipient, event, subject, body, dedupe_key)
+	SELECT u.id, 'email', u.email, $2::text, $3, $4, $5
+	FROM users u
+	WHERE u.id IN (%s)
+	  AND COALESCE((u.notification_preferences->>($2::text))::boolean, TRUE)
+	ON CONFLICT (user_id, channel, dedupe_key) DO NOTHING
+`
+
+// EnqueueForProgram ставит уведомление в outbox участникам команды программы
+// (или её автору, если программа загружена без команды). Возвращает число записанных уведомлений
+func (r *NotificationRepository) EnqueueForProgram(ctx context.Context, programID uuid.UUID, msg *domain.NotificationMessage) (int64, error) {
+	recipients := `
+		SELECT tm.user_id FROM programs p JOIN team_members tm ON tm.team_id = p.team_id WHERE p.id = $1
+		UNION
+		SELECT p.user_id FROM programs p WHERE p.id = $1 AND p.team_id IS NULL
+	`
+	return r.enqueue(ctx, "notification_enqueue_program", recipients, programID, msg)
+}
+
+// EnqueueForTournament ставит уведомление в outbox участникам всех команд турнира.
+// Возвращает число записанных уведомлений
+func (r *NotificationRepository) EnqueueForTournament(ctx context.Context, tournamentID uuid.UUID, msg *domain.NotificationMessage) (int64, error) {
+	recipients := `
+		SELECT tm.user_id FROM teams t JOIN team_members tm ON tm.team_id = t.id WHERE t.tournament_id = $1
+	`
+	return r.enqueue(ctx, "notification_enqueue_tournament", recipients, tournamentID, msg)
+}
+
+func (r *NotificationRepository) enqueue(ctx context.Context, queryType, recipients string, id uuid.UUID, msg *domain.NotificationMessage) (int64, error) {
+	query := fmt.Sprintf(enqueueNotificationQuery, recipients)
+
+	result, err := r.db.ExecWithMetrics(ctx, queryType, query,
+		id,
+		string(msg.Event),
+		msg.Subject,
+		msg.Body,
+		msg.DedupeKey,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to enqueue notification")
+	}
+
+	enqueued, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get enqueued notifications count")
+	}
+
+	return enqueued, nil
+}
+
+// GetProgramMatchProgress возвращает число несыгранных и всех матчей программы
+func (r *NotificationRepository) GetProgramMatchProgress(ctx context.Context, programID uuid.UUID) (unfinished, total int, err error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE status IN ('pending', 'running')), COUNT(*)
+		FROM matches
+		WHERE (program1_id = $1 OR program2_id = $1) AND status != 'cancelled' AND NOT is_test
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, programID).Scan(&unfinished, &total); err != nil {
+		return 0, 0, errors.Wrap(err, "failed to get program match progress")
+	}
+
+	return unfinished, total, nil
+}
+
+// ClaimDue забирает до limit уведомлений, срок доставки которых наступил.
+// Следующая попытка сразу откладывается на lease, чтобы уведомление не забрал другой worker,
+// а после падения процесса оно было доставлено повторно
+func (r *NotificationRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.Notification, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM notification_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE notification_outbox o
+		SET attempts = o.attempts + 1,
+		    next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due
+		WHERE o.id = due.id
+		RETURNING ` + notificationColumns
+
+	notifications := make([]*domain.Notification, 0)
+	if err := r.db.QueryWithMetrics(ctx, "notification_claim_due", &notifications, query, limit, lease.Seconds()); err != nil {
+		return nil, errors.Wrap(err, "failed to claim due notifications")
+	}
+
+	return notifications, nil
+}
+
+// MarkSent отмечает уведомление доставленным
+func (r *NotificationRepository) MarkSent(ctx context.Context, id int64) error {
+	query := `
+		UPDATE notification_outbox
+		SET status = 'sent', sent_at = NOW(), last_error = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecWithMetrics(ctx, "notification_mark_sent", query, id); err != nil {
+		return errors.Wrap(err, "failed to mark notification sent")
+	}
+
+	return nil
+}
+
+// MarkRetry сохраняет ошибку доставки и откладывает следующую попытку на delay
+func (r *NotificationRepository) MarkRetry(ctx context.Context, id int64, lastError string, delay time.Duration) error {
+	query := `
+		UPDATE notification_outbox
+		SET last_error = $2, next_attempt_at = NOW() + make_interval(secs => $3)
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecWithMetrics(ctx, "notification_mark_retry", query, id, lastError, delay.Seconds()); err != nil {
+		return errors.Wrap(err, "failed to schedule notification retry")
+	}
+
+	return nil
+}
+
+// MarkFailed отмечает уведомление недоставленным после исчерпания попыток
+func (r *NotificationRepository) MarkFailed(ctx context.Context, id int64, lastError string) error {
+	query := `
+		UPDATE notification_outbox
+		SET status = 'failed', last_error = $2
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecWithMetrics(ctx, "notification_mark_failed", query, id, lastError); err != nil {
+		return errors.Wrap(err, "failed to mark notification failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// maxTeamCodeAttempts попыток подобрать свободный код копии команды
+const maxTeamCodeAttempts = 10
+
+// GetLatestTeamPrograms получает последние версии программ команд турнира по каждой игре
+func (r *TournamentRepository) GetLatestTeamPrograms(ctx context.Context, tournamentID uuid.UUID, teamIDs []uuid.UUID) ([]*domain.Program, error) {
+	if len(teamIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT DISTINCT ON (team_id, game_id)
+		       id, user_id, team_id, tournament_id, game_id, name, game_type,
+		       code_path, file_path, language, language_version, validation_status, validation_error, error_message, version, content_hash, created_at, updated_at,
+		       is_reference, reference_rating
+		FROM programs
+		WHERE tournament_id = $1 AND team_id = ANY($2)
+		ORDER BY team_id, game_id, version DESC
+	`
+
+	var programs []*domain.Program
+	if err := r.db.QueryWithMetrics(ctx, "tournament_latest_team_programs", &programs, query, tournamentID, pq.Array(teamIDs)); err != nil {
+		return nil, errors.Wrap(err, "failed to get latest team programs")
+	}
+
+	return programs, nil
+}
+
+// ImportPrograms переносит программы команд другого турнира в турнир tournamentID одной транзакцией.
+// Команда программы переходит в турнир копией (название, лидер и участники, ещё не состоящие
+// в других командах турнира); если лидер уже состоит в команде турнира, используется она.
+// Программа добавляется новой версией команды со ссылкой на тот же файл и регистрируется
+// участником. Программа с тем же содержимым, что и последняя версия команды, пропускается
+func (r *TournamentRepository) ImportPrograms(ctx context.Context, tournamentID uuid.UUID, programs []*domain.Program) ([]*domain.ImportedProgram, error) {
+	imported := make([]*domain.ImportedProgram, 0, len(programs))
+
+	err := r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		teams := make(map[uuid.UUID]*domain.Team)
+
+		for _, program := range programs {
+			if program.TeamID == nil {
+				return errors.ErrValidation.WithMessage("program without team cannot be imported")
+			}
+
+			team, ok := teams[*program.TeamID]
+			if !ok {
+				var err error
+				team, err = importTeam(ctx, tx, tournamentID, *program.TeamID)
+				if err != nil {
+					return err
+				}
+				teams[*program.TeamID] = team
+			}
+
+			result := &domain.ImportedProgram{
+				SourceProgramID: program.ID,
+				TeamID:          team.ID,
+				TeamName:        team.Name,
+				GameID:          program.GameID,
+			}
+
+			var latestID uuid.UUID
+			var latestVersion int
+			var latestHash *string
+			err := tx.QueryRowContext(ctx, `
+				SELECT id, version, content_hash
+				FROM programs
+				WHERE team_id = $1 AND game_id IS NOT DISTINCT FROM $2
+				ORDER BY version DESC
+				LIMIT 1
+			`, team.ID, program.GameID).Scan(&latestID, &latestVersion, &latestHash)
+			if err != nil && !stderrors.Is(err, sql.ErrNoRows) {
+				return errors.Wrap(err, "failed to get latest team program")
+			}
+
+			if latestHash != nil && program.ContentHash != nil && *latestHash == *program.ContentHash {
+				result.ProgramID = latestID
+				result.Skipped = true
+				imported = append(imported, result)
+				continue
+			}
+
+			result.ProgramID = uuid.New()
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO programs (id, user_id, team_id, tournament_id, game_id, name, game_type, code_path, file_path,
+				                      language, language_version, error_message, version, content_hash, validation_status, validation_error)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			`,
+				result.ProgramID,
+				program.UserID,
+				team.ID,
+				tournamentID,
+				program.GameID,
+				program.Name,
+				program.GameType,
+				program.CodePath,
+				program.FilePath,
+				program.Language,
+				program.LanguageVersion,
+				program.ErrorMessage,
+				latestVersion+1,
+				program.ContentHash,
+				program.ValidationStatus,
+				program.ValidationError,
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to copy program")
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO tournament_participants (tournament_id, program_id, rating)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (tournament_id, program_id) DO NOTHING
+			`, tournamentID, result.ProgramID, 1500)
+			if err != nil {
+				return errors.Wrap(err, "failed to add participant")
+			}
+
+			imported = append(imported, result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return imported, nil
+}
+
+// importTeam находит команду лидера sourceTeamID в турнире или создаёт её копию
+func importTeam(ctx context.Context, tx *sql.Tx, tournamentID, sourceTeamID uuid.UUID) (*domain.Team, error) {
+	var team domain.Team
+	err := tx.QueryRowContext(ctx, `
+		SELECT t.id, t.name
+		FROM teams t
+		JOIN team_members tm ON tm.team_id = t.id
+		WHERE t.tournament_id = $1
+		  AND tm.user_id = (SELECT leader_id FROM teams WHERE id = $2)
+		LIMIT 1
+	`, tournamentID, sourceTeamID).Scan(&team.ID, &team.Name)
+	if err == nil {
+		return &team, nil
+	}
+	if !stderrors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "failed to find imported team")
+	}
+
+	// Код команды уникален глобально: при совпадении подбирается другой
+	for attempt := 0; attempt < maxTeamCodeAttempts; attempt++ {
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO teams (tournament_id, name, code, leader_id)
+			SELECT $1, name, generate_unique_code(6), leader_id
+			FROM teams
+			WHERE id = $2
+			ON CONFLICT (code) DO NOTHING
+			RETURNING id, name
+		`, tournamentID, sourceTeamID).Scan(&team.ID, &team.Name)
+		if err == nil {
+			break
+		}
+		if !stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(err, "failed to copy team")
+		}
+	}
+	if err != nil {
+		return nil, errors.ErrInternal.WithMessage("failed to generate unique team code")
+	}
+
+	// Участники, уже играющие за другую команду турнира, в копию не переносятся
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO team_members (team_id, user_id)
+		SELECT $1, tm.user_id
+		FROM team_members tm
+		WHERE tm.team_id = $2
+		  AND NOT EXISTS (
+		      SELECT 1
+		      FROM team_members other
+		      JOIN teams t ON t.id = other.team_id
+		      WHERE t.tournament_id = $3 AND other.user_id = tm.user_id
+		  )
+	`, team.ID, sourceTeamID, tournamentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to copy team members")
+	}
+
+	return &team, nil
+}
@@ -3,10 +3,13 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // ProgramRepository - репозиторий для работы с программами
@@ -22,8 +25,9 @@ func NewProgramRepository(db *DB) *ProgramRepository {
 // Create создаёт новую программу
 func (r *ProgramRepository) Create(ctx context.Context, program *domain.Program) error {
 	query := `
-		INSERT INTO programs (id, user_id, team_id, tournament_id, game_id, name, game_type, code_path, file_path, language, error_message, version)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		INSERT INTO programs (id, user_id, team_id, tournament_id, game_id, name, game_type, code_path, file_path, language, language_version, error_message, version, content_hash,
+		                      is_reference, reference_rating)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING created_at, updated_at
 	`
 
@@ -38,8 +42,12 @@ func (r *ProgramRepository) Create(ctx context.Context, program *domain.Program)
 		program.CodePath,
 		program.FilePath,
 		program.Language,
+		program.LanguageVersion,
 		program.ErrorMessage,
 		program.Version,
+		program.ContentHash,
+		program.IsReference,
+		program.ReferenceRating,
 	).Scan(&program.CreatedAt, &program.UpdatedAt)
 
 	if err != nil {
@@ -55,7 +63,8 @@ func (r *ProgramRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 
 	query := `
 		SELECT id, user_id, team_id, tournament_id, game_id, name, game_type,
-		       code_path, file_path, language, error_message, version, created_at, updated_at
+		       code_path, file_path, language, language_version, validation_status, validation_error, error_message, version, content_hash, created_at, updated_at,
+		       is_reference, reference_rating
 		FROM programs
 		WHERE id = $1
 	`
@@ -71,10 +80,16 @@ func (r *ProgramRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 		&program.CodePath,
 		&program.FilePath,
 		&program.Language,
+		&program.LanguageVersion,
+		&program.ValidationStatus,
+		&program.ValidationError,
 		&program.ErrorMessage,
 		&program.Version,
+		&program.ContentHash,
 		&program.CreatedAt,
 		&program.UpdatedAt,
+		&program.IsReference,
+		&program.ReferenceRating,
 	)
 	if err == sql.ErrNoRows {
 		return nil, errors.ErrProgramNotFound
@@ -86,11 +101,59 @@ func (r *ProgramRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 	return &program, nil
 }
 
+// GetByIDs получает программы по списку ID одним запросом
+func (r *ProgramRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Program, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, user_id, team_id, tournament_id, game_id, name, game_type,
+		       code_path, file_path, language, language_version, validation_status, validation_error, error_message, version, content_hash, created_at, updated_at,
+		       is_reference, reference_rating
+		FROM programs
+		WHERE id = ANY($1)
+	`
+
+	var programs []*domain.Program
+	if err := r.db.QueryWithMetrics(ctx, "program_get_by_ids", &programs, query, pq.Array(ids)); err != nil {
+		return nil, errors.Wrap(err, "failed to get programs by ids")
+	}
+
+	return programs, nil
+}
+
+// GetInfoByIDs получает названия программ и их команд одним запросом
+func (r *ProgramRepository) GetInfoByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*domain.ProgramInfo, error) {
+	result := make(map[uuid.UUID]*domain.ProgramInfo, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT p.id as program_id, p.name as program_name, t.id as team_id, t.name as team_name
+		FROM programs p
+		LEFT JOIN teams t ON p.team_id = t.id
+		WHERE p.id = ANY($1)
+	`
+
+	var infos []*domain.ProgramInfo
+	if err := r.db.QueryWithMetrics(ctx, "program_get_info_by_ids", &infos, query, pq.Array(ids)); err != nil {
+		return nil, errors.Wrap(err, "failed to get programs info")
+	}
+
+	for _, info := range infos {
+		result[info.ProgramID] = info
+	}
+
+	return result, nil
+}
+
 // GetByUserID получает все программы пользователя
 func (r *ProgramRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Program, error) {
 	query := `
 		SELECT id, user_id, team_id, tournament_id, game_id, name, game_type,
-		       code_path, file_path, language, error_message, version, created_at, updated_at
+		       code_path, file_path, language, language_version, validation_status, validation_error, error_message, version, created_at, updated_at
 		FROM programs
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -116,6 +179,9 @@ func (r *ProgramRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (
 			&p.CodePath,
 			&p.FilePath,
 			&p.Language,
+			&p.LanguageVersion,
+			&p.ValidationStatus,
+			&p.ValidationError,
 			&p.ErrorMessage,
 			&p.Version,
 			&p.CreatedAt,
@@ -130,11 +196,91 @@ func (r *ProgramRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (
 	return programs, nil
 }
 
+// ListByUserFiltered получает страницу программ пользователя с фильтрами.
+// Сначала новые; id разрешает равенство created_at, поэтому порядок между страницами стабилен
+func (r *ProgramRepository) ListByUserFiltered(ctx context.Context, userID uuid.UUID, filter domain.ProgramFilter) ([]*domain.Program, error) {
+	query := `
+		SELECT id, user_id, team_id, tournament_id, game_id, name, game_type,
+		       code_path, file_path, language, language_version, validation_status, validation_error, error_message, version, created_at, updated_at
+		FROM programs
+		WHERE user_id = $1
+	`
+	args := []interface{}{userID}
+	argCount := 2
+
+	if filter.TournamentID != nil {
+		query += fmt.Sprintf(" AND tournament_id = $%d", argCount)
+		args = append(args, *filter.TournamentID)
+		argCount++
+	}
+
+	if filter.GameID != nil {
+		query += fmt.Sprintf(" AND game_id = $%d", argCount)
+		args = append(args, *filter.GameID)
+		argCount++
+	}
+
+	if filter.Language != "" {
+		query += fmt.Sprintf(" AND language = $%d", argCount)
+		args = append(args, filter.Language)
+		argCount++
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, filter.Limit)
+		argCount++
+	}
+
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list programs by user id")
+	}
+	defer rows.Close()
+
+	programs := make([]*domain.Program, 0)
+	for rows.Next() {
+		var p domain.Program
+		err := rows.Scan(
+			&p.ID,
+			&p.UserID,
+			&p.TeamID,
+			&p.TournamentID,
+			&p.GameID,
+			&p.Name,
+			&p.GameType,
+			&p.CodePath,
+			&p.FilePath,
+			&p.Language,
+			&p.LanguageVersion,
+			&p.ValidationStatus,
+			&p.ValidationError,
+			&p.ErrorMessage,
+			&p.Version,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan program")
+		}
+		programs = append(programs, &p)
+	}
+
+	return programs, rows.Err()
+}
+
 // GetByUserIDAndGameType получает программы пользователя по типу игры
 func (r *ProgramRepository) GetByUserIDAndGameType(ctx context.Context, userID uuid.UUID, gameType string) ([]*domain.Program, error) {
 	query := `
 		SELECT id, user_id, team_id, tournament_id, game_id, name, game_type,
-		       code_path, file_path, language, error_message, version, created_at, updated_at
+		       code_path, file_path, language, language_version, validation_status, validation_error, error_message, version, created_at, updated_at
 		FROM programs
 		WHERE user_id = $1 AND game_type = $2
 		ORDER BY created_at DESC
@@ -160,6 +306,9 @@ func (r *ProgramRepository) GetByUserIDAndGameType(ctx context.Context, userID u
 			&p.CodePath,
 			&p.FilePath,
 			&p.Language,
+			&p.LanguageVersion,
+			&p.ValidationStatus,
+			&p.ValidationError,
 			&p.ErrorMessage,
 			&p.Version,
 			&p.CreatedAt,
@@ -222,6 +371,28 @@ func (r *ProgramRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// DeleteIfUnused удаляет программу, если она не участвовала ни в одном матче.
+// Возвращает false, если у программы есть матчи или её уже нет
+func (r *ProgramRepository) DeleteIfUnused(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		DELETE FROM programs
+		WHERE id = $1
+		  AND NOT EXISTS (SELECT 1 FROM matches WHERE program1_id = $1 OR program2_id = $1)
+	`
+
+	result, err := r.db.ExecWithMetrics(ctx, "program_delete_unused", query, id)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete program")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get rows affected")
+	}
+
+	return rows > 0, nil
+}
+
 // CheckOwnership проверяет, принадлежит ли программа пользователю
 func (r *ProgramRepository) CheckOwnership(ctx context.Context, programID, userID uuid.UUID) (bool, error) {
 	var exists bool
@@ -241,6 +412,47 @@ func (r *ProgramRepository) CheckOwnership(ctx context.Context, programID, userI
 	return exists, nil
 }
 
+// SetErrorMessage сохраняет ошибку программы (например, компиляции).
+// Программа с ошибкой не участвует в новых матчах
+func (r *ProgramRepository) SetErrorMessage(ctx context.Context, id uuid.UUID, message string) error {
+	query := `UPDATE programs SET error_message = $2 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, message)
+	if err != nil {
+		return errors.Wrap(err, "failed to set program error message")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+	if rows == 0 {
+		return errors.ErrProgramNotFound
+	}
+
+	return nil
+}
+
+// SetValidationResult сохраняет статус проверочного матча программы (message пусто - без ошибки)
+func (r *ProgramRepository) SetValidationResult(ctx context.Context, id uuid.UUID, status domain.ProgramValidationStatus, message string) error {
+	query := `UPDATE programs SET validation_status = $2, validation_error = NULLIF($3, '') WHERE id = $1`
+
+	result, err := r.db.ExecWithMetrics(ctx, "program_set_validation", query, id, status, message)
+	if err != nil {
+		return errors.Wrap(err, "failed to set program validation result")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+	if rows == 0 {
+		return errors.ErrProgramNotFound
+	}
+
+	return nil
+}
+
 // ClearErrorMessages очищает error_message для всех программ в турнире
 func (r *ProgramRepository) ClearErrorMessages(ctx context.Context, tournamentID uuid.UUID) (int64, error) {
 	query := `
@@ -273,13 +485,80 @@ func (r *ProgramRepository) GetLatestVersion(ctx context.Context, teamID, gameID
 	return version, nil
 }
 
+// GetLatestVersionCreatedAt возвращает время загрузки последней версии программы команды для игры
+// Возвращает nil, если команда ещё не загружала программ для этой игры
+func (r *ProgramRepository) GetLatestVersionCreatedAt(ctx context.Context, teamID, gameID uuid.UUID) (*time.Time, error) {
+	var createdAt sql.NullTime
+
+	query := `
+		SELECT MAX(created_at)
+		FROM programs
+		WHERE team_id = $1 AND game_id = $2
+	`
+
+	err := r.db.QueryRowContext(ctx, query, teamID, gameID).Scan(&createdAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest version time")
+	}
+
+	if !createdAt.Valid {
+		return nil, nil
+	}
+
+	return &createdAt.Time, nil
+}
+
+// GetLatestByTeamAndGame получает последнюю версию программы команды для игры
+// вместе с хэшем содержимого
+func (r *ProgramRepository) GetLatestByTeamAndGame(ctx context.Context, teamID, gameID uuid.UUID) (*domain.Program, error) {
+	var program domain.Program
+
+	query := `
+		SELECT id, user_id, team_id, tournament_id, game_id, name, game_type,
+		       code_path, file_path, language, language_version, validation_status, validation_error, error_message, version, content_hash, created_at, updated_at
+		FROM programs
+		WHERE team_id = $1 AND game_id = $2
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, teamID, gameID).Scan(
+		&program.ID,
+		&program.UserID,
+		&program.TeamID,
+		&program.TournamentID,
+		&program.GameID,
+		&program.Name,
+		&program.GameType,
+		&program.CodePath,
+		&program.FilePath,
+		&program.Language,
+		&program.LanguageVersion,
+		&program.ValidationStatus,
+		&program.ValidationError,
+		&program.ErrorMessage,
+		&program.Version,
+		&program.ContentHash,
+		&program.CreatedAt,
+		&program.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrProgramNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest program version")
+	}
+
+	return &program, nil
+}
+
 // GetByTournamentAndGame получает только ПОСЛЕДНИЕ версии программ для каждой команды в турнире
 func (r *ProgramRepository) GetByTournamentAndGame(ctx context.Context, tournamentID, gameID uuid.UUID) ([]*domain.Program, error) {
 	// Используем DISTINCT ON для получения только последней версии программы для каждой команды
 	query := `
 		SELECT DISTINCT ON (team_id)
 		       id, user_id, team_id, tournament_id, game_id, name, game_type,
-		       code_path, file_path, language, error_message, version, created_at, updated_at
+		       code_path, file_path, language, language_version, validation_status, validation_error, error_message, version, created_at, updated_at
 		FROM programs
 		WHERE tournament_id = $1 AND game_id = $2 AND team_id IS NOT NULL
 		ORDER BY team_id, version DESC
@@ -305,6 +584,9 @@ func (r *ProgramRepository) GetByTournamentAndGame(ctx context.Context, tourname
 			&p.CodePath,
 			&p.FilePath,
 			&p.Language,
+			&p.LanguageVersion,
+			&p.ValidationStatus,
+			&p.ValidationError,
 			&p.ErrorMessage,
 			&p.Version,
 			&p.CreatedAt,
@@ -323,7 +605,7 @@ func (r *ProgramRepository) GetByTournamentAndGame(ctx context.Context, tourname
 func (r *ProgramRepository) GetAllVersionsByTeamAndGame(ctx context.Context, teamID, gameID uuid.UUID) ([]*domain.Program, error) {
 	query := `
 		SELECT id, user_id, team_id, tournament_id, game_id, name, game_type,
-		       code_path, file_path, language, error_message, version, created_at, updated_at
+		       code_path, file_path, language, language_version, validation_status, validation_error, error_message, version, content_hash, created_at, updated_at
 		FROM programs
 		WHERE team_id = $1 AND game_id = $2
 		ORDER BY version DESC
@@ -349,8 +631,12 @@ func (r *ProgramRepository) GetAllVersionsByTeamAndGame(ctx context.Context, tea
 			&p.CodePath,
 			&p.FilePath,
 			&p.Language,
+			&p.LanguageVersion,
+			&p.ValidationStatus,
+			&p.ValidationError,
 			&p.ErrorMessage,
 			&p.Version,
+			&p.ContentHash,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		)
@@ -362,3 +648,51 @@ func (r *ProgramRepository) GetAllVersionsByTeamAndGame(ctx context.Context, tea
 
 	return programs, nil
 }
+
+// ListReferenceByGame получает действующие эталонные боты игры
+func (r *ProgramRepository) ListReferenceByGame(ctx context.Context, gameID uuid.UUID) ([]*domain.Program, error) {
+	query := `
+		SELECT id, user_id, team_id, tournament_id, game_id, name, game_type,
+		       code_path, file_path, language, language_version, validation_status, validation_error, error_message, version, content_hash, created_at, updated_at,
+		       is_reference, reference_rating
+		FROM programs
+		WHERE game_id = $1 AND is_reference AND reference_retired_at IS NULL
+		ORDER BY name
+	`
+
+	var programs []*domain.Program
+	if err := r.db.QueryWithMetrics(ctx, "program_list_reference", &programs, query, gameID); err != nil {
+		return nil, errors.Wrap(err, "failed to list reference programs")
+	}
+
+	return programs, nil
+}
+
+// RetireReference выводит эталонного бота игры из калибровки и из проверочных матчей.
+// Программа остаётся в БД: на неё ссылаются уже сыгранные матчи
+func (r *ProgramRepository) RetireReference(ctx context.Context, gameID, programID uuid.UUID) error {
+	query := `
+		WITH retired AS (
+			UPDATE programs
+			SET reference_retired_at = NOW()
+			WHERE id = $1 AND game_id = $2 AND is_reference AND reference_retired_at IS NULL
+			RETURNING id
+		), unset AS (
+			UPDATE games
+			SET validation_bot_id = NULL
+			WHERE validation_bot_id IN (SELECT id FROM retired)
+		)
+		SELECT COUNT(*) FROM retired
+	`
+
+	var rows int
+	if err := r.db.QueryRowContext(ctx, query, programID, gameID).Scan(&rows); err != nil {
+		return errors.Wrap(err, "failed to retire reference program")
+	}
+
+	if rows == 0 {
+		return errors.ErrNotFound.WithMessage("reference bot not found")
+	}
+
+	return nil
+}
@@ -173,29 +173,33 @@ func (r *RatingRepository) GetParticipantRating(ctx context.Context, tournamentI
 
 // GetParticipantRatings получает рейтинги обоих участников матча
 func (r *RatingRepository) GetParticipantRatings(ctx context.Context, tournamentID, program1ID, program2ID uuid.UUID) (rating1, rating2 int, err error) {
+	// Эталонный бот не участник турнира: его рейтинг фиксирован в programs
 	query := `
-		SELECT rating
-		FROM tournament_participants
-		WHERE tournament_id = $1 AND program_id = $2
+		SELECT COALESCE(
+			(SELECT rating FROM tournament_participants WHERE tournament_id = $1 AND program_id = $2),
+			(SELECT reference_rating FROM programs WHERE id = $2 AND is_reference)
+		)
 	`
 
+	var rating sql.NullInt64
+
 	// Получаем рейтинг первого участника
-	err = r.db.QueryRowContext(ctx, query, tournamentID, program1ID).Scan(&rating1)
-	if err == sql.ErrNoRows {
-		return 0, 0, errors.ErrNotFound.WithMessage("program1 not found in tournament")
-	}
-	if err != nil {
+	if err := r.db.QueryRowContext(ctx, query, tournamentID, program1ID).Scan(&rating); err != nil {
 		return 0, 0, errors.Wrap(err, "failed to get program1 rating")
 	}
+	if !rating.Valid {
+		return 0, 0, errors.ErrNotFound.WithMessage("program1 not found in tournament")
+	}
+	rating1 = int(rating.Int64)
 
 	// Получаем рейтинг второго участника
-	err = r.db.QueryRowContext(ctx, query, tournamentID, program2ID).Scan(&rating2)
-	if err == sql.ErrNoRows {
-		return 0, 0, errors.ErrNotFound.WithMessage("program2 not found in tournament")
-	}
-	if err != nil {
+	if err := r.db.QueryRowContext(ctx, query, tournamentID, program2ID).Scan(&rating); err != nil {
 		return 0, 0, errors.Wrap(err, "failed to get program2 rating")
 	}
+	if !rating.Valid {
+		return 0, 0, errors.ErrNotFound.WithMessage("program2 not found in tournament")
+	}
+	rating2 = int(rating.Int64)
 
 	return rating1, rating2, nil
 }
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// teamGameStatsUpsert добавляет к team_game_stats вклад отобранных матчей.
+// $1 - знак вклада (1 - результат записан, -1 - результат сбрасывается),
+// %s - условие отбора матчей с параметром $2.
+// Учитываются те же матчи, что и в crossGameLeaderboardFromMatchesQuery
+const teamGameStatsUpsert = `
+	INSERT INTO team_game_stats AS s (tournament_id, team_id, game_id, wins, losses, draws, total_games, score)
+	SELECT m.tournament_id, p.team_id, g.id,
+	       $1::int * COUNT(*) FILTER (WHERE (m.program1_id = p.id AND m.winner = 1) OR (m.program2_id = p.id AND m.winner = 2)),
+	       $1::int * COUNT(*) FILTER (WHERE (m.program1_id = p.id AND m.winner = 2) OR (m.program2_id = p.id AND m.winner = 1)),
+	       $1::int * COUNT(*) FILTER (WHERE m.winner = 0 AND m.status = 'completed'),
+	       $1::int * COUNT(*) FILTER (WHERE m.status = 'completed'),
+	       $1::int * COALESCE(SUM(CASE WHEN m.program1_id = p.id THEN COALESCE(m.score1, 0) ELSE COALESCE(m.score2, 0) END), 0)
+	FROM matches m
+	JOIN programs p ON p.id IN (m.program1_id, m.program2_id)
+	JOIN games g ON g.name = m.game_type
+	WHERE %s
+	  AND m.tournament_id IS NOT NULL
+	  AND m.status IN ('completed', 'failed')
+	  AND NOT m.is_test
+	  AND p.team_id IS NOT NULL
+	GROUP BY m.tournament_id, p.team_id, g.id
+	ON CONFLICT (tournament_id, team_id, game_id) DO UPDATE SET
+		wins = s.wins + EXCLUDED.wins,
+		losses = s.losses + EXCLUDED.losses,
+		draws = s.draws + EXCLUDED.draws,
+		total_games = s.total_games + EXCLUDED.total_games,
+		score = s.score + EXCLUDED.score,
+		updated_at = NOW()
+`
+
+// applyTeamGameStats добавляет (sign = 1) или вычитает (sign = -1) вклад матчей
+// в статистику команд. Вызывается в транзакции, изменяющей результаты этих матчей:
+// при добавлении - после записи результата, при вычитании - до его сброса
+func applyTeamGameStats(ctx context.Context, tx *sql.Tx, matchIDs []uuid.UUID, sign int) error {
+	if len(matchIDs) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(teamGameStatsUpsert, "m.id = ANY($2)")
+	if _, err := tx.ExecContext(ctx, query, sign, pq.Array(matchIDs)); err != nil {
+		return errors.Wrap(err, "failed to update team game stats")
+	}
+	return nil
+}
+
+// RebuildCrossGameStats пересчитывает статистику команд турнира по таблице матчей.
+// Нужен после ручных правок матчей в БД и для проверки расхождений с агрегатом
+func (r *TournamentRepository) RebuildCrossGameStats(ctx context.Context, tournamentID uuid.UUID) error {
+	return r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		// Блокировка ждёт транзакции worker, уже обновляющие агрегат, и не пускает новые
+		// до commit: иначе результат, записанный во время пересчёта, учёлся бы дважды
+		if _, err := tx.ExecContext(ctx, `LOCK TABLE team_game_stats IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return errors.Wrap(err, "failed to lock team game stats")
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM team_game_stats WHERE tournament_id = $1`, tournamentID); err != nil {
+			return errors.Wrap(err, "failed to clear team game stats")
+		}
+
+		query := fmt.Sprintf(teamGameStatsUpsert, "m.tournament_id = $2")
+		if _, err := tx.ExecContext(ctx, query, 1, tournamentID); err != nil {
+			return errors.Wrap(err, "failed to rebuild team game stats")
+		}
+		return nil
+	})
+}
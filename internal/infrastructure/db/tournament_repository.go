@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/pagination"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // TournamentRepository - репозиторий для работы с турнирами
@@ -22,20 +24,54 @@ func NewTournamentRepository(db *DB) *TournamentRepository {
 	return &TournamentRepository{db: db}
 }
 
+// createTournamentQuery вставляет турнир, возвращая поля, заполняемые БД
+const createTournamentQuery = `
+	INSERT INTO tournaments (id, code, name, description, game_type, status, max_participants, max_team_size, is_permanent, is_practice, visibility, creator_id, start_time, end_time, metadata)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	RETURNING created_at, updated_at, version
+`
+
+// rowQuerier выполняет запрос одной строки - *DB или *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 // Create создаёт новый турнир
 func (r *TournamentRepository) Create(ctx context.Context, tournament *domain.Tournament) error {
+	return insertTournament(ctx, r.db, tournament)
+}
+
+// CreateWithGames создаёт турнир вместе с набором игр в одной транзакции:
+// турнир без части игр не может появиться даже при ошибке
+func (r *TournamentRepository) CreateWithGames(ctx context.Context, tournament *domain.Tournament, gameIDs []uuid.UUID) error {
+	return r.db.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if err := insertTournament(ctx, tx, tournament); err != nil {
+			return err
+		}
+
+		for _, gameID := range gameIDs {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO tournament_games (tournament_id, game_id)
+				VALUES ($1, $2)
+				ON CONFLICT (tournament_id, game_id) DO NOTHING
+			`, tournament.ID, gameID)
+			if err != nil {
+				return errors.Wrap(err, "failed to add game to tournament")
+			}
+		}
+
+		return nil
+	})
+}
+
+// insertTournament вставляет турнир через q
+func insertTournament(ctx context.Context, q rowQuerier, tournament *domain.Tournament) error {
 	metadata, err := json.Marshal(tournament.Metadata)
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal metadata")
 	}
 
-	query := `
-		INSERT INTO tournaments (id, code, name, description, game_type, status, max_participants, max_team_size, is_permanent, creator_id, start_time, end_time, metadata)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
-		RETURNING created_at, updated_at, version
-	`
-
-	err = r.db.QueryRowContext(ctx, query,
+	err = q.QueryRowContext(ctx, createTournamentQuery,
 		tournament.ID,
 		tournament.Code,
 		tournament.Name,
@@ -45,6 +81,8 @@ func (r *TournamentRepository) Create(ctx context.Context, tournament *domain.To
 		tournament.MaxParticipants,
 		tournament.MaxTeamSize,
 		tournament.IsPermanent,
+		tournament.IsPractice,
+		tournament.Visibility,
 		tournament.CreatorID,
 		tournament.StartTime,
 		tournament.EndTime,
@@ -64,10 +102,10 @@ func (r *TournamentRepository) GetByID(ctx context.Context, id uuid.UUID) (*doma
 	var metadataJSON []byte
 
 	query := `
-		SELECT id, code, name, description, game_type, status, max_participants, max_team_size, is_permanent, creator_id, start_time, end_time,
-		       metadata, version, created_at, updated_at
+		SELECT id, code, name, description, game_type, status, max_participants, max_team_size, is_permanent, is_practice, visibility, creator_id, start_time, end_time,
+		       metadata, version, created_at, updated_at, deleted_at
 		FROM tournaments
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
@@ -80,6 +118,8 @@ func (r *TournamentRepository) GetByID(ctx context.Context, id uuid.UUID) (*doma
 		&tournament.MaxParticipants,
 		&tournament.MaxTeamSize,
 		&tournament.IsPermanent,
+		&tournament.IsPractice,
+		&tournament.Visibility,
 		&tournament.CreatorID,
 		&tournament.StartTime,
 		&tournament.EndTime,
@@ -87,6 +127,7 @@ func (r *TournamentRepository) GetByID(ctx context.Context, id uuid.UUID) (*doma
 		&tournament.Version,
 		&tournament.CreatedAt,
 		&tournament.UpdatedAt,
+		&tournament.DeletedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -105,31 +146,92 @@ func (r *TournamentRepository) GetByID(ctx context.Context, id uuid.UUID) (*doma
 	return &tournament, nil
 }
 
-// List получает список турниров с фильтрацией и пагинацией
-func (r *TournamentRepository) List(ctx context.Context, filter domain.TournamentFilter) ([]*domain.Tournament, error) {
-	query := `
-		SELECT id, code, name, description, game_type, status, max_participants, max_team_size, is_permanent, creator_id, start_time, end_time,
-		       metadata, version, created_at, updated_at
-		FROM tournaments
-		WHERE 1=1
-	`
+// tournamentFilterConditions формирует условия WHERE списка турниров (без пагинации).
+// Возвращает условия, их аргументы и номер следующего аргумента
+func tournamentFilterConditions(filter domain.TournamentFilter) (string, []interface{}, int) {
+	conditions := "1=1"
 	args := []interface{}{}
 	argCount := 1
 
+	// Удалённые турниры показываются только по явному запросу
+	if !filter.IncludeDeleted {
+		conditions += " AND deleted_at IS NULL"
+	}
+
 	// Фильтр по статусу
 	if filter.Status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argCount)
+		conditions += fmt.Sprintf(" AND status = $%d", argCount)
 		args = append(args, filter.Status)
 		argCount++
 	}
 
 	// Фильтр по типу игры
 	if filter.GameType != "" {
-		query += fmt.Sprintf(" AND game_type = $%d", argCount)
+		conditions += fmt.Sprintf(" AND game_type = $%d", argCount)
 		args = append(args, filter.GameType)
 		argCount++
 	}
 
+	visibility, visibilityArgs, argCount := tournamentVisibilityCondition(filter, argCount)
+	conditions += visibility
+	args = append(args, visibilityArgs...)
+
+	return conditions, args, argCount
+}
+
+// tournamentVisibilityCondition ограничивает список турниров видимыми пользователю:
+// публичными и приватными, где он состоит в команде или является создателем.
+// Unlisted турниры в списки не попадают; админам (AllVisibilities) видны все
+func tournamentVisibilityCondition(filter domain.TournamentFilter, argCount int) (string, []interface{}, int) {
+	if filter.AllVisibilities {
+		return "", nil, argCount
+	}
+	if filter.ViewerID == nil {
+		return fmt.Sprintf(" AND visibility = $%d", argCount), []interface{}{domain.VisibilityPublic}, argCount + 1
+	}
+
+	condition := fmt.Sprintf(` AND (visibility = $%d OR (visibility = $%d AND (creator_id = $%d OR EXISTS (
+		SELECT 1 FROM team_members tm
+		INNER JOIN teams t ON tm.team_id = t.id
+		WHERE t.tournament_id = tournaments.id AND tm.user_id = $%d
+	))))`, argCount, argCount+1, argCount+2, argCount+2)
+	args := []interface{}{domain.VisibilityPublic, domain.VisibilityPrivate, *filter.ViewerID}
+	return condition, args, argCount + 3
+}
+
+// CountWithFilter считает турниры по тем же фильтрам, что и List, без учёта limit/offset
+func (r *TournamentRepository) CountWithFilter(ctx context.Context, filter domain.TournamentFilter) (int, error) {
+	conditions, args, _ := tournamentFilterConditions(filter)
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM tournaments WHERE "+conditions, args...).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, "failed to count tournaments")
+	}
+
+	return count, nil
+}
+
+// GetActiveTournamentIDs получает ID всех активных (не удалённых) турниров
+func (r *TournamentRepository) GetActiveTournamentIDs(ctx context.Context) ([]uuid.UUID, error) {
+	query := `SELECT id FROM tournaments WHERE status = $1 AND deleted_at IS NULL ORDER BY id`
+
+	ids := []uuid.UUID{}
+	if err := r.db.QueryWithMetrics(ctx, "tournament_active_ids", &ids, query, domain.TournamentActive); err != nil {
+		return nil, errors.Wrap(err, "failed to get active tournament ids")
+	}
+
+	return ids, nil
+}
+
+// List получает список турниров с фильтрацией и пагинацией
+func (r *TournamentRepository) List(ctx context.Context, filter domain.TournamentFilter) ([]*domain.Tournament, error) {
+	conditions, args, argCount := tournamentFilterConditions(filter)
+	query := `
+		SELECT id, code, name, description, game_type, status, max_participants, max_team_size, is_permanent, is_practice, visibility, creator_id, start_time, end_time,
+		       metadata, version, created_at, updated_at, deleted_at
+		FROM tournaments
+		WHERE ` + conditions
+
 	// Сортировка
 	query += " ORDER BY created_at DESC"
 
@@ -165,6 +267,8 @@ func (r *TournamentRepository) List(ctx context.Context, filter domain.Tournamen
 			&tournament.MaxParticipants,
 			&tournament.MaxTeamSize,
 			&tournament.IsPermanent,
+			&tournament.IsPractice,
+			&tournament.Visibility,
 			&tournament.CreatorID,
 			&tournament.StartTime,
 			&tournament.EndTime,
@@ -172,6 +276,7 @@ func (r *TournamentRepository) List(ctx context.Context, filter domain.Tournamen
 			&tournament.Version,
 			&tournament.CreatedAt,
 			&tournament.UpdatedAt,
+			&tournament.DeletedAt,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan tournament")
@@ -189,6 +294,173 @@ func (r *TournamentRepository) List(ctx context.Context, filter domain.Tournamen
 	return tournaments, nil
 }
 
+// GetDueToStart получает ожидающие турниры, у которых наступило запланированное время старта
+func (r *TournamentRepository) GetDueToStart(ctx context.Context) ([]*domain.Tournament, error) {
+	query := `
+		SELECT id, code, name, description, game_type, status, max_participants, max_team_size, is_permanent, is_practice, visibility, creator_id, start_time, end_time,
+		       metadata, version, created_at, updated_at, deleted_at
+		FROM tournaments
+		WHERE status = $1 AND start_time IS NOT NULL AND start_time <= NOW() AND deleted_at IS NULL
+		ORDER BY start_time ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.TournamentPending)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tournaments due for start")
+	}
+	defer rows.Close()
+
+	return scanTournaments(rows)
+}
+
+// GetActiveWithTimeBudget получает активные турниры с заданным бюджетом времени
+func (r *TournamentRepository) GetActiveWithTimeBudget(ctx context.Context) ([]*domain.Tournament, error) {
+	query := `
+		SELECT id, code, name, description, game_type, status, max_participants, max_team_size, is_permanent, is_practice, visibility, creator_id, start_time, end_time,
+		       metadata, version, created_at, updated_at, deleted_at
+		FROM tournaments
+		WHERE status = $1 AND start_time IS NOT NULL AND deleted_at IS NULL
+		  AND metadata ? 'time_budget'
+		ORDER BY start_time ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.TournamentActive)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tournaments with time budget")
+	}
+	defer rows.Close()
+
+	return scanTournaments(rows)
+}
+
+// ClaimTimeBudgetWarning отмечает, что предупреждение об истечении бюджета времени разослано.
+// Возвращает false, если предупреждение уже было отправлено
+func (r *TournamentRepository) ClaimTimeBudgetWarning(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE tournaments
+		SET metadata = metadata || jsonb_build_object('time_budget_warned', true)
+		WHERE id = $1 AND metadata IS NOT NULL AND NOT metadata ? 'time_budget_warned'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim time budget warning")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get rows affected")
+	}
+
+	return rows > 0, nil
+}
+
+// scanTournaments читает строки турниров в порядке колонок GetDueToStart
+func scanTournaments(rows *sql.Rows) ([]*domain.Tournament, error) {
+	var tournaments []*domain.Tournament
+	for rows.Next() {
+		var tournament domain.Tournament
+		var metadataJSON []byte
+
+		err := rows.Scan(
+			&tournament.ID,
+			&tournament.Code,
+			&tournament.Name,
+			&tournament.Description,
+			&tournament.GameType,
+			&tournament.Status,
+			&tournament.MaxParticipants,
+			&tournament.MaxTeamSize,
+			&tournament.IsPermanent,
+			&tournament.IsPractice,
+			&tournament.Visibility,
+			&tournament.CreatorID,
+			&tournament.StartTime,
+			&tournament.EndTime,
+			&metadataJSON,
+			&tournament.Version,
+			&tournament.CreatedAt,
+			&tournament.UpdatedAt,
+			&tournament.DeletedAt,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan tournament")
+		}
+
+		if metadataJSON != nil {
+			if err := json.Unmarshal(metadataJSON, &tournament.Metadata); err != nil {
+				return nil, errors.Wrap(err, "failed to unmarshal metadata")
+			}
+		}
+
+		tournaments = append(tournaments, &tournament)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "error iterating tournaments")
+	}
+
+	return tournaments, nil
+}
+
+// RecordAutoStartFailure сохраняет причину неудачного автостарта и увеличивает счётчик попыток
+// Возвращает номер попытки
+func (r *TournamentRepository) RecordAutoStartFailure(ctx context.Context, id uuid.UUID, reason string) (int, error) {
+	query := `
+		UPDATE tournaments
+		SET metadata = COALESCE(metadata, '{}'::jsonb) || jsonb_build_object(
+			'auto_start_error', $2::text,
+			'auto_start_attempts', COALESCE((metadata->>'auto_start_attempts')::int, 0) + 1
+		), version = version + 1
+		WHERE id = $1
+		RETURNING (metadata->>'auto_start_attempts')::int
+	`
+
+	var attempts int
+	err := r.db.QueryRowContext(ctx, query, id, reason).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return 0, errors.ErrNotFound.WithMessage("tournament not found")
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to record auto-start failure")
+	}
+
+	return attempts, nil
+}
+
+// RecordAutoStartDelay сохраняет причину, по которой автостарт откладывается, не увеличивая
+// счётчик попыток. Запись выполняется только при изменении причины, чтобы ожидание
+// участников не меняло version турнира на каждой проверке
+func (r *TournamentRepository) RecordAutoStartDelay(ctx context.Context, id uuid.UUID, reason string) error {
+	query := `
+		UPDATE tournaments
+		SET metadata = COALESCE(metadata, '{}'::jsonb) || jsonb_build_object('auto_start_error', $2::text),
+		    version = version + 1
+		WHERE id = $1 AND metadata->>'auto_start_error' IS DISTINCT FROM $2::text
+	`
+
+	if _, err := r.db.ExecWithMetrics(ctx, "tournament_record_auto_start_delay", query, id, reason); err != nil {
+		return errors.Wrap(err, "failed to record auto-start delay")
+	}
+
+	return nil
+}
+
+// ClearAutoStartFailure удаляет сведения о неудачном автостарте из метаданных
+func (r *TournamentRepository) ClearAutoStartFailure(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE tournaments
+		SET metadata = metadata - 'auto_start_error' - 'auto_start_attempts', version = version + 1
+		WHERE id = $1 AND metadata IS NOT NULL
+	`
+
+	if _, err := r.db.ExecWithMetrics(ctx, "tournament_clear_auto_start", query, id); err != nil {
+		return errors.Wrap(err, "failed to clear auto-start failure")
+	}
+
+	return nil
+}
+
 // Update обновляет турнир с optimistic locking
 func (r *TournamentRepository) Update(ctx context.Context, tournament *domain.Tournament) error {
 	metadata, err := json.Marshal(tournament.Metadata)
@@ -225,6 +497,30 @@ func (r *TournamentRepository) Update(ctx context.Context, tournament *domain.To
 	return nil
 }
 
+// UpdateVisibility обновляет видимость турнира
+func (r *TournamentRepository) UpdateVisibility(ctx context.Context, id uuid.UUID, visibility domain.TournamentVisibility) error {
+	query := `
+		UPDATE tournaments
+		SET visibility = $2, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecWithMetrics(ctx, "tournament_update_visibility", query, id, visibility)
+	if err != nil {
+		return errors.Wrap(err, "failed to update tournament visibility")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+	if rows == 0 {
+		return errors.ErrNotFound.WithMessage("tournament not found")
+	}
+
+	return nil
+}
+
 // UpdateStatus обновляет только статус турнира
 func (r *TournamentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.TournamentStatus) error {
 	query := `
@@ -250,9 +546,9 @@ func (r *TournamentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, s
 	return nil
 }
 
-// Delete удаляет турнир
+// Delete мягко удаляет турнир: он скрывается из выборок, но матчи и участники сохраняются
 func (r *TournamentRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM tournaments WHERE id = $1`
+	query := `UPDATE tournaments SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
 	result, err := r.db.ExecWithMetrics(ctx, "tournament_delete", query, id)
 	if err != nil {
@@ -271,6 +567,62 @@ func (r *TournamentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// Restore восстанавливает мягко удалённый турнир
+func (r *TournamentRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE tournaments SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	result, err := r.db.ExecWithMetrics(ctx, "tournament_restore", query, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to restore tournament")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rows == 0 {
+		return errors.ErrNotFound.WithMessage("deleted tournament not found")
+	}
+
+	return nil
+}
+
+// Purge окончательно удаляет турнир вместе с матчами и участниками (ON DELETE CASCADE)
+// Удаляются только турниры, мягко удалённые не позже deletedBefore
+func (r *TournamentRepository) Purge(ctx context.Context, id uuid.UUID, deletedBefore time.Time) error {
+	query := `DELETE FROM tournaments WHERE id = $1 AND deleted_at IS NOT NULL AND deleted_at <= $2`
+
+	result, err := r.db.ExecWithMetrics(ctx, "tournament_purge", query, id, deletedBefore)
+	if err != nil {
+		return errors.Wrap(err, "failed to purge tournament")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rows > 0 {
+		return nil
+	}
+
+	// Определяем причину отказа
+	var deletedAt *time.Time
+	err = r.db.QueryRowContext(ctx, `SELECT deleted_at FROM tournaments WHERE id = $1`, id).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		return errors.ErrNotFound.WithMessage("tournament not found")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get tournament deletion time")
+	}
+
+	if deletedAt == nil {
+		return errors.ErrConflict.WithMessage("tournament must be deleted before purge")
+	}
+	return errors.ErrConflict.WithMessage("retention period for deleted tournament has not expired")
+}
+
 // GetParticipantsCount получает количество участников турнира
 func (r *TournamentRepository) GetParticipantsCount(ctx context.Context, tournamentID uuid.UUID) (int, error) {
 	var count int
@@ -307,44 +659,153 @@ func (r *TournamentRepository) AddParticipant(ctx context.Context, participant *
 	return nil
 }
 
-// GetParticipants получает список участников турнира
-func (r *TournamentRepository) GetParticipants(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TournamentParticipant, error) {
-	var participants []*domain.TournamentParticipant
+// RemoveParticipant удаляет программу из участников турнира
+func (r *TournamentRepository) RemoveParticipant(ctx context.Context, tournamentID, programID uuid.UUID) error {
+	query := `DELETE FROM tournament_participants WHERE tournament_id = $1 AND program_id = $2`
+
+	result, err := r.db.ExecWithMetrics(ctx, "tournament_remove_participant", query, tournamentID, programID)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove tournament participant")
+	}
 
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rows == 0 {
+		return errors.ErrNotFound.WithMessage("participant not found")
+	}
+
+	return nil
+}
+
+// AddBan добавляет бан программы в турнире. Бан распространяется на команду программы:
+// новые версии, загруженные командой, тоже забанены.
+// Повторный бан той же программы обновляет причину
+func (r *TournamentRepository) AddBan(ctx context.Context, ban *domain.TournamentBan) error {
 	query := `
-		SELECT id, tournament_id, program_id, rating, wins, losses, draws, created_at
-		FROM tournament_participants
-		WHERE tournament_id = $1
-		ORDER BY created_at ASC
+		INSERT INTO tournament_bans (id, tournament_id, program_id, team_id, reason)
+		VALUES ($1, $2, $3, (SELECT team_id FROM programs WHERE id = $3), $4)
+		ON CONFLICT (tournament_id, program_id) DO UPDATE SET reason = EXCLUDED.reason
+		RETURNING id, team_id, created_at
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tournamentID)
+	err := r.db.QueryRowContext(ctx, query,
+		ban.ID,
+		ban.TournamentID,
+		ban.ProgramID,
+		ban.Reason,
+	).Scan(&ban.ID, &ban.TeamID, &ban.CreatedAt)
+
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get tournament participants")
+		return errors.Wrap(err, "failed to add tournament ban")
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var p domain.TournamentParticipant
-		err := rows.Scan(
-			&p.ID,
-			&p.TournamentID,
-			&p.ProgramID,
-			&p.Rating,
-			&p.Wins,
-			&p.Losses,
-			&p.Draws,
-			&p.CreatedAt,
+	return nil
+}
+
+// IsBanned проверяет, забанена ли программа в турнире: сама или через бан своей команды
+func (r *TournamentRepository) IsBanned(ctx context.Context, tournamentID, programID uuid.UUID) (bool, error) {
+	var exists bool
+
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM tournament_bans
+			WHERE tournament_id = $1
+			  AND (program_id = $2 OR team_id = (SELECT team_id FROM programs WHERE id = $2))
 		)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to scan participant")
-		}
-		participants = append(participants, &p)
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, tournamentID, programID).Scan(&exists); err != nil {
+		return false, errors.Wrap(err, "failed to check tournament ban")
+	}
+
+	return exists, nil
+}
+
+// IsTeamBanned проверяет, забанена ли команда в турнире (бан любой её программы)
+func (r *TournamentRepository) IsTeamBanned(ctx context.Context, tournamentID, teamID uuid.UUID) (bool, error) {
+	var exists bool
+
+	query := `SELECT EXISTS(SELECT 1 FROM tournament_bans WHERE tournament_id = $1 AND team_id = $2)`
+
+	if err := r.db.QueryRowContext(ctx, query, tournamentID, teamID).Scan(&exists); err != nil {
+		return false, errors.Wrap(err, "failed to check team tournament ban")
+	}
+
+	return exists, nil
+}
+
+// participantFilterConditions строит условия WHERE списка участников (алиасы tp и p)
+func participantFilterConditions(tournamentID uuid.UUID, filter domain.ParticipantFilter) (string, []interface{}, int) {
+	conditions := "tp.tournament_id = $1"
+	args := []interface{}{tournamentID}
+	argCount := 2
+
+	if filter.GameID != nil {
+		conditions += fmt.Sprintf(" AND p.game_id = $%d", argCount)
+		args = append(args, *filter.GameID)
+		argCount++
+	}
+
+	if filter.LatestOnly {
+		conditions += `
+		  AND p.version = (
+		      SELECT MAX(p2.version)
+		      FROM programs p2
+		      WHERE p2.team_id = p.team_id
+		        AND p2.game_id = p.game_id
+		        AND p2.tournament_id = p.tournament_id
+		  )`
+	}
+
+	return conditions, args, argCount
+}
+
+// GetParticipants получает страницу участников турнира в порядке регистрации.
+// Программа, команда и игра подтягиваются одним запросом
+func (r *TournamentRepository) GetParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) ([]*domain.ParticipantEntry, error) {
+	conditions, args, argCount := participantFilterConditions(tournamentID, filter)
+	query := fmt.Sprintf(`
+		SELECT tp.program_id, p.name AS program_name, p.version AS program_version,
+		       p.team_id, t.name AS team_name, p.game_id, g.name AS game_name,
+		       tp.rating, tp.wins, tp.losses, tp.draws, tp.created_at AS joined_at
+		FROM tournament_participants tp
+		JOIN programs p ON tp.program_id = p.id
+		LEFT JOIN teams t ON p.team_id = t.id
+		LEFT JOIN games g ON p.game_id = g.id
+		WHERE %s
+		ORDER BY tp.created_at ASC, tp.id ASC
+		LIMIT $%d OFFSET $%d
+	`, conditions, argCount, argCount+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	participants := make([]*domain.ParticipantEntry, 0)
+	if err := r.db.SelectContext(ctx, &participants, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to get tournament participants")
 	}
 
 	return participants, nil
 }
 
+// CountParticipants считает участников турнира по тем же фильтрам, что GetParticipants, без limit/offset
+func (r *TournamentRepository) CountParticipants(ctx context.Context, tournamentID uuid.UUID, filter domain.ParticipantFilter) (int, error) {
+	conditions, args, _ := participantFilterConditions(tournamentID, filter)
+	query := `
+		SELECT COUNT(*)
+		FROM tournament_participants tp
+		JOIN programs p ON tp.program_id = p.id
+		WHERE ` + conditions
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, "failed to count tournament participants")
+	}
+
+	return count, nil
+}
+
 // GetLatestParticipants получает список участников турнира, но только с последней версией программы каждой команды
 func (r *TournamentRepository) GetLatestParticipants(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TournamentParticipant, error) {
 	var participants []*domain.TournamentParticipant
@@ -505,8 +966,21 @@ func (r *TournamentRepository) GetLeaderboard(ctx context.Context, tournamentID
 }
 
 // getLeaderboardFallback - fallback метод для получения leaderboard без materialized view
-// Рейтинг = сумма всех очков из всех матчей
+// Рейтинг = сумма всех очков из всех матчей, либо очки за победы, ничьи и поражения,
+// если в метаданных включён points_scoring. Равенство разрешается tie-break правилами турнира
 func (r *TournamentRepository) getLeaderboardFallback(ctx context.Context, tournamentID uuid.UUID, limit int) ([]*domain.LeaderboardEntry, error) {
+	settings, err := r.getLeaderboardSettings(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Без points_scoring рейтинг - сумма очков игры ($2 = false)
+	scoring := settings.PointsScoring()
+	usePoints := scoring != nil
+	if scoring == nil {
+		scoring = &domain.PointsScoring{}
+	}
+
 	query := `
 		WITH program_stats AS (
 			SELECT
@@ -530,42 +1004,184 @@ func (r *TournamentRepository) getLeaderboardFallback(ctx context.Context, tourn
 						WHEN m.program2_id = p.id THEN COALESCE(m.score2, 0)
 						ELSE 0
 					END
-				), 0) as total_score
+				), 0) as total_score,
+				MIN(tp.created_at) as registered_at
 			FROM tournament_participants tp
 			JOIN programs p ON tp.program_id = p.id
 			LEFT JOIN teams t ON p.team_id = t.id
 			LEFT JOIN matches m ON (m.program1_id = p.id OR m.program2_id = p.id)
 				AND m.tournament_id = $1
 				AND m.status = 'completed'
+				AND NOT m.is_test
 			WHERE tp.tournament_id = $1
 			GROUP BY p.id, p.name, t.id, t.name
 		)
 		SELECT
-			ROW_NUMBER() OVER (ORDER BY total_score DESC, wins DESC) as rank,
 			program_id,
 			program_name,
 			team_id,
 			team_name,
-			total_score as rating,
+			CASE
+				WHEN $2::boolean THEN wins * $3 + draws * $4 + losses * $5
+				ELSE total_score
+			END as rating,
 			wins,
 			losses,
 			draws,
-			total_games
+			total_games,
+			registered_at
 		FROM program_stats
-		ORDER BY total_score DESC, wins DESC
-		LIMIT $2
+		ORDER BY rating DESC, wins DESC
 	`
 
 	var leaderboard []*domain.LeaderboardEntry
 
-	err := r.db.QueryWithMetrics(ctx, "tournament_leaderboard_fallback", &leaderboard, query, tournamentID, limit)
+	err = r.db.QueryWithMetrics(ctx, "tournament_leaderboard_fallback", &leaderboard, query,
+		tournamentID, usePoints, scoring.Win, scoring.Draw, scoring.Loss)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get tournament leaderboard")
 	}
 
+	// Места считаются после tie-break, поэтому LIMIT применяется к отсортированному списку
+	rules := settings.TieBreakRules()
+
+	var h2h domain.HeadToHead
+	if hasHeadToHead(rules) {
+		h2h, err = r.getHeadToHead(ctx, "tournament_head_to_head", `
+			SELECT program1_id, program2_id, winner
+			FROM matches
+			WHERE tournament_id = $1 AND status = 'completed' AND winner IN (1, 2) AND NOT is_test
+		`, tournamentID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	domain.SortLeaderboard(leaderboard, rules, h2h, func(e *domain.LeaderboardEntry) uuid.UUID { return e.ProgramID })
+	return limitLeaderboard(leaderboard, limit), nil
+}
+
+// GetReferenceLeaderboard получает результаты эталонных ботов в калибровочных матчах турнира.
+// Боты не участники турнира, поэтому в основную таблицу лидеров не попадают
+func (r *TournamentRepository) GetReferenceLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.LeaderboardEntry, error) {
+	query := `
+		SELECT
+			p.id as program_id,
+			p.name as program_name,
+			COUNT(*) FILTER (WHERE
+				(m.program1_id = p.id AND m.winner = 1) OR
+				(m.program2_id = p.id AND m.winner = 2)
+			) as wins,
+			COUNT(*) FILTER (WHERE
+				(m.program1_id = p.id AND m.winner = 2) OR
+				(m.program2_id = p.id AND m.winner = 1)
+			) as losses,
+			COUNT(*) FILTER (WHERE m.winner = 0) as draws,
+			COUNT(*) as total_games,
+			COALESCE(SUM(
+				CASE
+					WHEN m.program1_id = p.id THEN COALESCE(m.score1, 0)
+					ELSE COALESCE(m.score2, 0)
+				END
+			), 0) as rating,
+			p.created_at as registered_at
+		FROM programs p
+		JOIN matches m ON (m.program1_id = p.id OR m.program2_id = p.id)
+			AND m.tournament_id = $1
+			AND m.status = 'completed'
+			AND NOT m.is_test
+		WHERE p.is_reference
+		GROUP BY p.id, p.name, p.created_at
+	`
+
+	var leaderboard []*domain.LeaderboardEntry
+	if err := r.db.QueryWithMetrics(ctx, "tournament_reference_leaderboard", &leaderboard, query, tournamentID); err != nil {
+		return nil, errors.Wrap(err, "failed to get reference leaderboard")
+	}
+
+	domain.SortLeaderboard(leaderboard, domain.DefaultTieBreakRules, nil, func(e *domain.LeaderboardEntry) uuid.UUID { return e.ProgramID })
 	return leaderboard, nil
 }
 
+// getTieBreakRules получает цепочку tie-break правил из метаданных турнира
+func (r *TournamentRepository) getTieBreakRules(ctx context.Context, tournamentID uuid.UUID) ([]domain.TieBreakRule, error) {
+	settings, err := r.getLeaderboardSettings(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return settings.TieBreakRules(), nil
+}
+
+// getLeaderboardSettings получает турнир только с метаданными (tie-break правила, points_scoring).
+// Для несуществующего турнира возвращает турнир без метаданных - настройки по умолчанию
+func (r *TournamentRepository) getLeaderboardSettings(ctx context.Context, tournamentID uuid.UUID) (*domain.Tournament, error) {
+	tournament := &domain.Tournament{ID: tournamentID}
+
+	var metadataJSON []byte
+	err := r.db.QueryRowContext(ctx, `SELECT metadata FROM tournaments WHERE id = $1`, tournamentID).Scan(&metadataJSON)
+	if err == sql.ErrNoRows {
+		return tournament, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tournament leaderboard settings")
+	}
+
+	if metadataJSON != nil {
+		if err := json.Unmarshal(metadataJSON, &tournament.Metadata); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal metadata")
+		}
+	}
+	return tournament, nil
+}
+
+// getHeadToHead получает победы в личных встречах.
+// Запрос должен возвращать (участник 1, участник 2, winner) для матчей с победителем
+func (r *TournamentRepository) getHeadToHead(ctx context.Context, queryName, query string, args ...interface{}) (domain.HeadToHead, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get "+queryName)
+	}
+	defer rows.Close()
+
+	h2h := domain.HeadToHead{}
+	for rows.Next() {
+		var first, second uuid.UUID
+		var winner int
+		if err := rows.Scan(&first, &second, &winner); err != nil {
+			return nil, errors.Wrap(err, "failed to scan head-to-head result")
+		}
+		if winner == 1 {
+			h2h.AddWin(first, second)
+		} else {
+			h2h.AddWin(second, first)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows iteration error")
+	}
+
+	return h2h, nil
+}
+
+// hasHeadToHead проверяет, нужны ли результаты личных встреч
+func hasHeadToHead(rules []domain.TieBreakRule) bool {
+	for _, rule := range rules {
+		if rule == domain.TieBreakHeadToHead {
+			return true
+		}
+	}
+	return false
+}
+
+// limitLeaderboard обрезает отсортированный leaderboard до limit записей
+func limitLeaderboard(leaderboard []*domain.LeaderboardEntry, limit int) []*domain.LeaderboardEntry {
+	if limit > 0 && len(leaderboard) > limit {
+		return leaderboard[:limit]
+	}
+	return leaderboard
+}
+
 // GetParticipantsByTournamentIDs получает участников для нескольких турниров одним запросом
 // Это предотвращает N+1 проблему при загрузке списка турниров с участниками
 func (r *TournamentRepository) GetParticipantsByTournamentIDs(ctx context.Context, tournamentIDs []uuid.UUID) (map[uuid.UUID][]*domain.TournamentParticipant, error) {
@@ -614,6 +1230,94 @@ func (r *TournamentRepository) GetParticipantsByTournamentIDs(ctx context.Contex
 	return result, nil
 }
 
+// GetParticipantCountsByTournamentIDs считает участников нескольких турниров одним запросом
+func (r *TournamentRepository) GetParticipantCountsByTournamentIDs(ctx context.Context, tournamentIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	result := make(map[uuid.UUID]int, len(tournamentIDs))
+	if len(tournamentIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT tournament_id, COUNT(*)
+		FROM tournament_participants
+		WHERE tournament_id = ANY($1)
+		GROUP BY tournament_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(tournamentIDs))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to count participants by tournament IDs")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tournamentID uuid.UUID
+		var count int
+		if err := rows.Scan(&tournamentID, &count); err != nil {
+			return nil, errors.Wrap(err, "failed to scan participant count")
+		}
+		result[tournamentID] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows iteration error")
+	}
+
+	return result, nil
+}
+
+// GetMatchStatsByTournamentIDs считает матчи нескольких турниров по статусам одним запросом.
+// Турниры без матчей в результат не попадают
+func (r *TournamentRepository) GetMatchStatsByTournamentIDs(ctx context.Context, tournamentIDs []uuid.UUID) (map[uuid.UUID]*domain.TournamentMatchStats, error) {
+	result := make(map[uuid.UUID]*domain.TournamentMatchStats, len(tournamentIDs))
+	if len(tournamentIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT tournament_id,
+		       COUNT(*) AS total,
+		       COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+		       COUNT(*) FILTER (WHERE status = 'running') AS running,
+		       COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+		       COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+		       COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled
+		FROM matches
+		WHERE tournament_id = ANY($1) AND NOT is_test
+		GROUP BY tournament_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(tournamentIDs))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get match stats by tournament IDs")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tournamentID uuid.UUID
+		var stats domain.TournamentMatchStats
+		err := rows.Scan(
+			&tournamentID,
+			&stats.Total,
+			&stats.Pending,
+			&stats.Running,
+			&stats.Completed,
+			&stats.Failed,
+			&stats.Cancelled,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan match stats")
+		}
+		result[tournamentID] = &stats
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows iteration error")
+	}
+
+	return result, nil
+}
+
 // ListWithCursor получает список турниров с cursor-based пагинацией
 func (r *TournamentRepository) ListWithCursor(ctx context.Context, filter domain.TournamentFilter, pageReq *pagination.PageRequest) ([]*domain.Tournament, bool, error) {
 	// Валидация запроса пагинации
@@ -629,14 +1333,19 @@ func (r *TournamentRepository) ListWithCursor(ctx context.Context, filter domain
 
 	// Базовый запрос
 	query := `
-		SELECT id, code, name, description, game_type, status, max_participants, max_team_size, is_permanent, creator_id, start_time, end_time,
-		       metadata, version, created_at, updated_at
+		SELECT id, code, name, description, game_type, status, max_participants, max_team_size, is_permanent, is_practice, visibility, creator_id, start_time, end_time,
+		       metadata, version, created_at, updated_at, deleted_at
 		FROM tournaments
 		WHERE 1=1
 	`
 	args := []interface{}{}
 	argCount := 1
 
+	// Удалённые турниры показываются только по явному запросу
+	if !filter.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+
 	// Фильтр по статусу
 	if filter.Status != "" {
 		query += fmt.Sprintf(" AND status = $%d", argCount)
@@ -651,8 +1360,21 @@ func (r *TournamentRepository) ListWithCursor(ctx context.Context, filter domain
 		argCount++
 	}
 
-	// Применяем курсор для пагинации
-	if cursor != nil && cursor.Type == pagination.CursorTypeTimestamp && cursor.Timestamp != nil {
+	visibility, visibilityArgs, argCount := tournamentVisibilityCondition(filter, argCount)
+	query += visibility
+	args = append(args, visibilityArgs...)
+
+	// Применяем курсор для пагинации. С ID сравнивается пара (created_at, id):
+	// турниры, созданные в одну и ту же микросекунду, не теряются на границе страниц
+	if cursor != nil && cursor.Type == pagination.CursorTypeTimestamp && cursor.Timestamp != nil && cursor.ID != nil {
+		op := "<"
+		if pageReq.IsBackward() {
+			op = ">"
+		}
+		query += fmt.Sprintf(" AND (created_at, id) %s ($%d, $%d)", op, argCount, argCount+1)
+		args = append(args, *cursor.Timestamp, *cursor.ID)
+		argCount += 2
+	} else if cursor != nil && cursor.Type == pagination.CursorTypeTimestamp && cursor.Timestamp != nil {
 		if pageReq.IsForward() {
 			// Forward pagination: получаем записи после курсора
 			query += fmt.Sprintf(" AND created_at < $%d", argCount)
@@ -666,9 +1388,9 @@ func (r *TournamentRepository) ListWithCursor(ctx context.Context, filter domain
 
 	// Сортировка (по умолчанию - от новых к старым)
 	if pageReq.IsBackward() {
-		query += " ORDER BY created_at ASC" // Обратный порядок для backward pagination
+		query += " ORDER BY created_at ASC, id ASC" // Обратный порядок для backward pagination
 	} else {
-		query += " ORDER BY created_at DESC"
+		query += " ORDER BY created_at DESC, id DESC"
 	}
 
 	// Добавляем +1 к лимиту для определения hasNextPage
@@ -697,6 +1419,8 @@ func (r *TournamentRepository) ListWithCursor(ctx context.Context, filter domain
 			&tournament.MaxParticipants,
 			&tournament.MaxTeamSize,
 			&tournament.IsPermanent,
+			&tournament.IsPractice,
+			&tournament.Visibility,
 			&tournament.CreatorID,
 			&tournament.StartTime,
 			&tournament.EndTime,
@@ -704,6 +1428,7 @@ func (r *TournamentRepository) ListWithCursor(ctx context.Context, filter domain
 			&tournament.Version,
 			&tournament.CreatedAt,
 			&tournament.UpdatedAt,
+			&tournament.DeletedAt,
 		)
 		if err != nil {
 			return nil, false, errors.Wrap(err, "failed to scan tournament")
@@ -737,15 +1462,92 @@ func (r *TournamentRepository) ListWithCursor(ctx context.Context, filter domain
 
 // GetTournamentCursor возвращает курсор для турнира (для использования с pagination.NewConnection)
 func GetTournamentCursor(tournament *domain.Tournament) (*pagination.Cursor, error) {
-	return pagination.NewTimestampCursor(tournament.CreatedAt), nil
+	cursor := pagination.NewTimestampCursor(tournament.CreatedAt)
+	id := tournament.ID
+	cursor.ID = &id
+	return cursor, nil
 }
 
-// GetCrossGameLeaderboard получает кросс-игровой рейтинг турнира
-// Рейтинг = сумма всех очков из всех матчей
-func (r *TournamentRepository) GetCrossGameLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error) {
-	// Получаем все команды и программы в турнире со статистикой по каждой игре
-	// Используем team_id для связи матчей (чтобы учитывать все версии программ команды)
-	query := `
+// crossGameLeaderboardQuery кросс-игровой рейтинг по агрегату team_game_stats:
+// индексное чтение статистики команд вместо агрегации всех матчей турнира
+const crossGameLeaderboardQuery = `
+		WITH latest_programs AS (
+			-- Получаем последние версии программ для отображения имени
+			SELECT DISTINCT ON (p.team_id, p.game_id)
+				p.id as program_id,
+				p.name as program_name,
+				p.team_id,
+				t.name as team_name,
+				p.game_id,
+				g.name as game_name,
+				g.score_multiplier
+			FROM programs p
+			LEFT JOIN teams t ON p.team_id = t.id
+			LEFT JOIN games g ON p.game_id = g.id
+			WHERE p.tournament_id = $1 AND p.team_id IS NOT NULL
+			ORDER BY p.team_id, p.game_id, p.version DESC
+		),
+		game_stats AS (
+			-- Очки умножаются на score_multiplier игры для балансировки между играми
+			SELECT
+				lp.team_id,
+				COALESCE(lp.team_name, '') as team_name,
+				lp.program_id,
+				COALESCE(lp.program_name, '') as program_name,
+				lp.game_id,
+				lp.game_name,
+				COALESCE(s.wins, 0) as wins,
+				COALESCE(s.losses, 0) as losses,
+				COALESCE(s.draws, 0) as draws,
+				COALESCE(s.total_games, 0) as total_games,
+				COALESCE((s.score * COALESCE(lp.score_multiplier, 1.0))::bigint, 0) as total_score
+			FROM latest_programs lp
+			LEFT JOIN team_game_stats s
+				ON s.tournament_id = $1 AND s.team_id = lp.team_id AND s.game_id = lp.game_id
+		),
+		aggregated AS (
+			SELECT
+				team_id,
+				MAX(team_name) as team_name,
+				(array_agg(program_id ORDER BY program_name))[1] as program_id,
+				MAX(program_name) as program_name,
+				json_object_agg(
+					COALESCE(game_id::text, 'unknown'),
+					json_build_object(
+						'game_id', game_id,
+						'game_name', game_name,
+						'rating', total_score,
+						'wins', wins,
+						'losses', losses,
+						'draws', draws,
+						'total_games', total_games
+					)
+				) as game_ratings,
+				SUM(wins) as total_wins,
+				SUM(losses) as total_losses,
+				SUM(total_games) as total_games,
+				SUM(total_score) as total_rating
+			FROM game_stats
+			GROUP BY team_id
+		)
+		SELECT
+			ROW_NUMBER() OVER (ORDER BY total_rating DESC, total_wins DESC) as rank,
+			team_id,
+			team_name,
+			program_id,
+			program_name,
+			game_ratings,
+			total_rating,
+			total_wins,
+			total_losses,
+			total_games
+		FROM aggregated
+		ORDER BY total_rating DESC, total_wins DESC
+	`
+
+// crossGameLeaderboardFromMatchesQuery прежний расчёт кросс-игрового рейтинга по всем
+// матчам турнира. Используется для проверки согласованности агрегата team_game_stats
+const crossGameLeaderboardFromMatchesQuery = `
 		WITH latest_programs AS (
 			-- Получаем последние версии программ для отображения имени
 			SELECT DISTINCT ON (p.team_id, p.game_id)
@@ -790,6 +1592,7 @@ func (r *TournamentRepository) GetCrossGameLeaderboard(ctx context.Context, tour
 			JOIN games g ON m.game_type = g.name
 			WHERE m.tournament_id = $1
 			  AND m.status IN ('completed', 'failed')
+			  AND NOT m.is_test
 			  AND p.team_id IS NOT NULL
 			GROUP BY p.team_id, g.id, g.name
 		),
@@ -850,6 +1653,20 @@ func (r *TournamentRepository) GetCrossGameLeaderboard(ctx context.Context, tour
 		ORDER BY total_rating DESC, total_wins DESC
 	`
 
+// GetCrossGameLeaderboard получает кросс-игровой рейтинг турнира
+// Рейтинг = сумма всех очков из всех матчей (по агрегату team_game_stats)
+func (r *TournamentRepository) GetCrossGameLeaderboard(ctx context.Context, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error) {
+	return r.queryCrossGameLeaderboard(ctx, crossGameLeaderboardQuery, tournamentID)
+}
+
+// GetCrossGameLeaderboardFromMatches считает кросс-игровой рейтинг заново по таблице
+// матчей, минуя агрегат. Медленно: только для проверки согласованности
+func (r *TournamentRepository) GetCrossGameLeaderboardFromMatches(ctx context.Context, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error) {
+	return r.queryCrossGameLeaderboard(ctx, crossGameLeaderboardFromMatchesQuery, tournamentID)
+}
+
+// queryCrossGameLeaderboard выполняет запрос кросс-игрового рейтинга и разбирает строки
+func (r *TournamentRepository) queryCrossGameLeaderboard(ctx context.Context, query string, tournamentID uuid.UUID) ([]*domain.CrossGameLeaderboardEntry, error) {
 	rows, err := r.db.QueryContext(ctx, query, tournamentID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get cross-game leaderboard")
@@ -892,9 +1709,71 @@ func (r *TournamentRepository) GetCrossGameLeaderboard(ctx context.Context, tour
 	return entries, nil
 }
 
+// GetTeamRatings получает рейтинг команд турнира: очки команды (с учётом score_multiplier)
+// суммируются по всем играм, в которых у неё есть программа
+func (r *TournamentRepository) GetTeamRatings(ctx context.Context, tournamentID uuid.UUID) ([]*domain.TeamRating, error) {
+	query := `
+		WITH team_games AS (
+			SELECT DISTINCT p.team_id, p.game_id
+			FROM programs p
+			WHERE p.tournament_id = $1 AND p.team_id IS NOT NULL
+		),
+		game_scores AS (
+			SELECT
+				tg.team_id,
+				t.name as team_name,
+				COALESCE(g.name, 'unknown') as game_name,
+				COALESCE(s.total_games, 0) as total_games,
+				COALESCE((s.score * COALESCE(g.score_multiplier, 1.0))::bigint, 0) as total_score
+			FROM team_games tg
+			JOIN teams t ON t.id = tg.team_id
+			LEFT JOIN games g ON g.id = tg.game_id
+			LEFT JOIN team_game_stats s
+				ON s.tournament_id = $1 AND s.team_id = tg.team_id AND s.game_id = tg.game_id
+		)
+		SELECT
+			team_id,
+			MAX(team_name) as team_name,
+			SUM(total_score) as total_rating,
+			COUNT(*) FILTER (WHERE total_games > 0) as games_played,
+			json_object_agg(game_name, total_score) as game_breakdown
+		FROM game_scores
+		GROUP BY team_id
+		ORDER BY total_rating DESC, team_name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tournamentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get team ratings")
+	}
+	defer rows.Close()
+
+	ratings := make([]*domain.TeamRating, 0)
+	for rows.Next() {
+		var rating domain.TeamRating
+		var breakdownJSON []byte
+
+		if err := rows.Scan(&rating.TeamID, &rating.TeamName, &rating.TotalRating, &rating.GamesPlayed, &breakdownJSON); err != nil {
+			return nil, errors.Wrap(err, "failed to scan team rating")
+		}
+
+		rating.GameBreakdown = make(map[string]float64)
+		if err := json.Unmarshal(breakdownJSON, &rating.GameBreakdown); err != nil {
+			return nil, errors.Wrap(err, "failed to parse team game breakdown")
+		}
+
+		ratings = append(ratings, &rating)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate team ratings")
+	}
+
+	return ratings, nil
+}
+
 // GetLeaderboardByGameType получает таблицу лидеров для конкретной игры в турнире
 // gameType - имя игры (game.name), используется для фильтрации матчей
-// Рейтинг = сумма всех очков из всех матчей
+// Рейтинг = сумма всех очков из всех матчей, равенство очков разрешается tie-break правилами турнира
 func (r *TournamentRepository) GetLeaderboardByGameType(ctx context.Context, tournamentID uuid.UUID, gameType string, limit int) ([]*domain.LeaderboardEntry, error) {
 	// Получаем рейтинг на основе результатов матчей для конкретной игры
 	// Используем team_id для агрегации (чтобы учитывать все версии программ команды)
@@ -905,7 +1784,9 @@ func (r *TournamentRepository) GetLeaderboardByGameType(ctx context.Context, tou
 				p.id as program_id,
 				p.name as program_name,
 				p.team_id,
-				t.name as team_name
+				t.name as team_name,
+				-- Регистрация команды в игре - загрузка первой версии программы
+				MIN(p.created_at) OVER (PARTITION BY p.team_id) as registered_at
 			FROM programs p
 			LEFT JOIN teams t ON p.team_id = t.id
 			JOIN games g ON p.game_id = g.id
@@ -940,6 +1821,7 @@ func (r *TournamentRepository) GetLeaderboardByGameType(ctx context.Context, tou
 			WHERE m.tournament_id = $1
 			  AND m.game_type = $2
 			  AND m.status IN ('completed', 'failed')
+			  AND NOT m.is_test
 			  AND p.team_id IS NOT NULL
 			GROUP BY p.team_id
 		),
@@ -953,12 +1835,12 @@ func (r *TournamentRepository) GetLeaderboardByGameType(ctx context.Context, tou
 				COALESCE(ms.losses, 0) as losses,
 				COALESCE(ms.draws, 0) as draws,
 				COALESCE(ms.total_games, 0) as total_games,
-				COALESCE(ms.total_score, 0) as total_score
+				COALESCE(ms.total_score, 0) as total_score,
+				lp.registered_at
 			FROM latest_programs lp
 			LEFT JOIN match_stats ms ON lp.team_id = ms.team_id
 		)
 		SELECT
-			ROW_NUMBER() OVER (ORDER BY total_score DESC, wins DESC) as rank,
 			program_id,
 			program_name,
 			team_id,
@@ -967,13 +1849,13 @@ func (r *TournamentRepository) GetLeaderboardByGameType(ctx context.Context, tou
 			wins,
 			losses,
 			draws,
-			total_games
+			total_games,
+			registered_at
 		FROM combined
 		ORDER BY total_score DESC, wins DESC
-		LIMIT $3
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tournamentID, gameType, limit)
+	rows, err := r.db.QueryContext(ctx, query, tournamentID, gameType)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get leaderboard by game type")
 	}
@@ -983,7 +1865,6 @@ func (r *TournamentRepository) GetLeaderboardByGameType(ctx context.Context, tou
 	for rows.Next() {
 		var entry domain.LeaderboardEntry
 		err := rows.Scan(
-			&entry.Rank,
 			&entry.ProgramID,
 			&entry.ProgramName,
 			&entry.TeamID,
@@ -993,6 +1874,7 @@ func (r *TournamentRepository) GetLeaderboardByGameType(ctx context.Context, tou
 			&entry.Losses,
 			&entry.Draws,
 			&entry.TotalGames,
+			&entry.RegisteredAt,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan leaderboard entry")
@@ -1004,5 +1886,37 @@ func (r *TournamentRepository) GetLeaderboardByGameType(ctx context.Context, tou
 		return nil, errors.Wrap(err, "rows iteration error")
 	}
 
-	return leaderboard, nil
+	rules, err := r.getTieBreakRules(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Личные встречи считаются между командами: у команды может быть несколько версий программы
+	var h2h domain.HeadToHead
+	if hasHeadToHead(rules) {
+		h2h, err = r.getHeadToHead(ctx, "game_head_to_head", `
+			SELECT p1.team_id, p2.team_id, m.winner
+			FROM matches m
+			JOIN programs p1 ON m.program1_id = p1.id
+			JOIN programs p2 ON m.program2_id = p2.id
+			WHERE m.tournament_id = $1
+			  AND m.game_type = $2
+			  AND m.status IN ('completed', 'failed')
+			  AND m.winner IN (1, 2)
+			  AND NOT m.is_test
+			  AND p1.team_id IS NOT NULL
+			  AND p2.team_id IS NOT NULL
+		`, tournamentID, gameType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	domain.SortLeaderboard(leaderboard, rules, h2h, func(e *domain.LeaderboardEntry) uuid.UUID {
+		if e.TeamID != nil {
+			return *e.TeamID
+		}
+		return e.ProgramID
+	})
+	return limitLeaderboard(leaderboard, limit), nil
 }
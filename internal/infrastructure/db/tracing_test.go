@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// stubDriver - драйвер без реальной БД: любой запрос успешен и не возвращает строк
+type stubDriver struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) { return stubConn{}, nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(string) (driver.Stmt, error) { return stubStmt{}, nil }
+func (stubConn) Close() error                        { return nil }
+func (stubConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type stubStmt struct{}
+
+func (stubStmt) Close() error                               { return nil }
+func (stubStmt) NumInput() int                              { return -1 }
+func (stubStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (stubStmt) Query([]driver.Value) (driver.Rows, error)  { return stubRows{}, nil }
+
+type stubRows struct{}
+
+func (stubRows) Columns() []string         { return []string{"id"} }
+func (stubRows) Close() error              { return nil }
+func (stubRows) Next([]driver.Value) error { return io.EOF }
+
+func init() {
+	sql.Register("tracing-stub", stubDriver{})
+}
+
+// setupTracingTest подменяет глобальный провайдер на записывающий спаны
+func setupTracingTest(t *testing.T) (*DB, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prevProvider := otel.GetTracerProvider()
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	log, _ := logger.New("error", "json")
+	conn, err := sql.Open("tracing-stub", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &DB{DB: sqlx.NewDb(conn, "postgres"), log: log, metrics: metrics.New()}, recorder
+}
+
+func TestExecWithMetrics_CreatesSpan(t *testing.T) {
+	database, recorder := setupTracingTest(t)
+
+	_, err := database.ExecWithMetrics(context.Background(), "update_tournament", "UPDATE tournaments SET name = $1", "x")
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "db.exec update_tournament", spans[0].Name())
+
+	attrs := make(map[string]string)
+	for _, kv := range spans[0].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.AsString()
+	}
+	assert.Equal(t, "postgresql", attrs["db.system"])
+	assert.Equal(t, "UPDATE tournaments SET name = $1", attrs["db.statement"])
+}
+
+func TestTracing_RequestPathParentChild(t *testing.T) {
+	database, recorder := setupTracingTest(t)
+
+	router := chi.NewRouter()
+	router.Use(middleware.Tracing())
+	router.Put("/api/v1/tournaments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, err := database.ExecWithMetrics(r.Context(), "update_tournament", "UPDATE tournaments SET name = $1", "x")
+		require.NoError(t, err)
+
+		var ids []string
+		require.NoError(t, database.QueryWithMetrics(r.Context(), "get_tournament", &ids, "SELECT id FROM tournaments"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// Клиент передаёт свой traceparent - трассировка должна продолжиться
+	const clientTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/tournaments/42", nil)
+	req.Header.Set("traceparent", "00-"+clientTraceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range spans {
+		byName[s.Name()] = s
+	}
+
+	root, ok := byName["PUT /api/v1/tournaments/{id}"]
+	require.True(t, ok, "HTTP span must be named after the route pattern")
+	assert.Equal(t, clientTraceID, root.SpanContext().TraceID().String())
+	assert.True(t, root.Parent().IsRemote())
+
+	for _, name := range []string{"db.exec update_tournament", "db.query get_tournament"} {
+		child, ok := byName[name]
+		require.True(t, ok, name)
+		assert.Equal(t, root.SpanContext().TraceID(), child.SpanContext().TraceID())
+		assert.Equal(t, root.SpanContext().SpanID(), child.Parent().SpanID())
+	}
+
+	// traceparent возвращается клиенту
+	assert.Contains(t, w.Header().Get("traceparent"), clientTraceID)
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+)
+
+// ErrNestedTransaction возвращается при попытке открыть транзакцию внутри WithTransaction.
+// Вложенные транзакции (savepoints) не поддерживаются, а молча использовать внешнюю
+// транзакцию нельзя: её commit/rollback уже не под контролем вложенного кода
+var ErrNestedTransaction = stderrors.New("nested transactions are not supported")
+
+// txContextKey помечает контекст, переданный в функцию транзакции
+type txContextKey struct{}
+
+// TxFunc функция, выполняемая в транзакции.
+// ctx - контекст транзакции: его следует передавать в запросы и вложенные вызовы
+type TxFunc func(ctx context.Context, tx *sql.Tx) error
+
+// WithTransaction выполняет fn в транзакции: commit, если fn вернула nil, иначе rollback.
+// При панике в fn транзакция откатывается, а паника пробрасывается дальше.
+// Вызов с контекстом транзакции возвращает ErrNestedTransaction
+func (db *DB) WithTransaction(ctx context.Context, fn TxFunc) error {
+	return db.withTx(ctx, nil, fn)
+}
+
+// WithReadOnlyTransaction выполняет fn в транзакции READ ONLY - для согласованного
+// чтения несколькими запросами. Отдельная реплика не настроена, поэтому транзакция
+// открывается на основной БД
+func (db *DB) WithReadOnlyTransaction(ctx context.Context, fn TxFunc) error {
+	return db.withTx(ctx, &sql.TxOptions{ReadOnly: true}, fn)
+}
+
+func (db *DB) withTx(ctx context.Context, opts *sql.TxOptions, fn TxFunc) error {
+	if ctx.Value(txContextKey{}) != nil {
+		return ErrNestedTransaction
+	}
+
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	// Откатываем при ошибке и при панике (defer выполняется при раскрутке стека)
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, true), tx); err != nil {
+		return err
+	}
+
+	committed = true
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
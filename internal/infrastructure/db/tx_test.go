@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// txRecorder - драйвер, запоминающий исход каждой транзакции
+type txRecorder struct {
+	mu        sync.Mutex
+	begins    []driver.TxOptions
+	commits   int
+	rollbacks int
+}
+
+func (r *txRecorder) Open(string) (driver.Conn, error) { return &txConn{recorder: r}, nil }
+
+type txConn struct {
+	stubConn
+	recorder *txRecorder
+}
+
+func (c *txConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.recorder.mu.Lock()
+	defer c.recorder.mu.Unlock()
+	c.recorder.begins = append(c.recorder.begins, opts)
+	return &recordedTx{recorder: c.recorder}, nil
+}
+
+type recordedTx struct{ recorder *txRecorder }
+
+func (t *recordedTx) Commit() error {
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+	t.recorder.commits++
+	return nil
+}
+
+func (t *recordedTx) Rollback() error {
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+	t.recorder.rollbacks++
+	return nil
+}
+
+var (
+	txDriver     = &txRecorder{}
+	registerOnce sync.Once
+)
+
+// setupTxTest возвращает DB поверх записывающего драйвера и сбрасывает счётчики
+func setupTxTest(t *testing.T) (*DB, *txRecorder) {
+	registerOnce.Do(func() { sql.Register("tx-stub", txDriver) })
+
+	txDriver.mu.Lock()
+	txDriver.begins, txDriver.commits, txDriver.rollbacks = nil, 0, 0
+	txDriver.mu.Unlock()
+
+	log, _ := logger.New("error", "json")
+	conn, err := sql.Open("tx-stub", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &DB{DB: sqlx.NewDb(conn, "postgres"), log: log}, txDriver
+}
+
+func TestWithTransaction_CommitsOnSuccess(t *testing.T) {
+	database, recorder := setupTxTest(t)
+
+	err := database.WithTransaction(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "UPDATE matches SET status = $1", "completed")
+		return err
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, recorder.commits)
+	assert.Equal(t, 0, recorder.rollbacks)
+}
+
+func TestWithTransaction_RollsBackOnError(t *testing.T) {
+	database, recorder := setupTxTest(t)
+	fnErr := errors.New("insert failed")
+
+	err := database.WithTransaction(context.Background(), func(context.Context, *sql.Tx) error {
+		return fnErr
+	})
+
+	assert.ErrorIs(t, err, fnErr)
+	assert.Equal(t, 0, recorder.commits)
+	assert.Equal(t, 1, recorder.rollbacks)
+}
+
+func TestWithTransaction_RollsBackOnPanic(t *testing.T) {
+	database, recorder := setupTxTest(t)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = database.WithTransaction(context.Background(), func(context.Context, *sql.Tx) error {
+			panic("boom")
+		})
+	})
+
+	assert.Equal(t, 0, recorder.commits, "panic must not commit")
+	assert.Equal(t, 1, recorder.rollbacks)
+}
+
+func TestWithTransaction_NestedCallIsRejected(t *testing.T) {
+	database, recorder := setupTxTest(t)
+
+	var nestedErr error
+	err := database.WithTransaction(context.Background(), func(ctx context.Context, _ *sql.Tx) error {
+		nestedErr = database.WithTransaction(ctx, func(context.Context, *sql.Tx) error {
+			t.Fatal("nested function must not run")
+			return nil
+		})
+		return nestedErr
+	})
+
+	assert.ErrorIs(t, nestedErr, ErrNestedTransaction)
+	assert.ErrorIs(t, err, ErrNestedTransaction)
+	assert.Len(t, recorder.begins, 1, "nested call must not open a second transaction")
+	assert.Equal(t, 1, recorder.rollbacks)
+
+	// Read-only variant shares the same rule
+	err = database.WithTransaction(context.Background(), func(ctx context.Context, _ *sql.Tx) error {
+		return database.WithReadOnlyTransaction(ctx, func(context.Context, *sql.Tx) error { return nil })
+	})
+	assert.ErrorIs(t, err, ErrNestedTransaction)
+}
+
+func TestWithReadOnlyTransaction(t *testing.T) {
+	database, recorder := setupTxTest(t)
+
+	err := database.WithReadOnlyTransaction(context.Background(), func(context.Context, *sql.Tx) error {
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, recorder.begins, 1)
+	assert.True(t, recorder.begins[0].ReadOnly)
+	assert.Equal(t, 1, recorder.commits)
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
@@ -169,3 +170,57 @@ func (r *UserRepository) Exists(ctx context.Context, username, email string) (bo
 
 	return exists, nil
 }
+
+// GetNotificationPreferences возвращает подписки пользователя на уведомления
+func (r *UserRepository) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (domain.NotificationPreferences, error) {
+	var raw []byte
+
+	query := `SELECT notification_preferences FROM users WHERE id = $1`
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound.WithMessage("user not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get notification preferences")
+	}
+
+	prefs := domain.NotificationPreferences{}
+	if err := json.Unmarshal(raw, &prefs); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal notification preferences")
+	}
+
+	return prefs, nil
+}
+
+// UpdateNotificationPreferences объединяет переданные подписки с сохранёнными
+// и возвращает результат
+func (r *UserRepository) UpdateNotificationPreferences(ctx context.Context, userID uuid.UUID, prefs domain.NotificationPreferences) (domain.NotificationPreferences, error) {
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal notification preferences")
+	}
+
+	query := `
+		UPDATE users
+		SET notification_preferences = notification_preferences || $2::jsonb
+		WHERE id = $1
+		RETURNING notification_preferences
+	`
+
+	var raw []byte
+	err = r.db.QueryRowContext(ctx, query, userID, data).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound.WithMessage("user not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update notification preferences")
+	}
+
+	updated := domain.NotificationPreferences{}
+	if err := json.Unmarshal(raw, &updated); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal notification preferences")
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,222 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// WebhookRepository - репозиторий webhook'ов турниров.
+// Доставки событий хранятся в outbox уведомлений с каналом webhook
+type WebhookRepository struct {
+	db *DB
+}
+
+// NewWebhookRepository создаёт новый репозиторий webhook'ов
+func NewWebhookRepository(db *DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// webhookColumns колонки tournament_webhooks в порядке scanWebhook
+const webhookColumns = `id, tournament_id, url, secret, events, created_by, created_at`
+
+// Create сохраняет новый webhook
+func (r *WebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	query := `
+		INSERT INTO tournament_webhooks (id, tournament_id, url, secret, events, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+
+	events := make([]string, len(webhook.Events))
+	for i, event := range webhook.Events {
+		events[i] = string(event)
+	}
+
+	err := r.db.QueryRowContext(ctx, query,
+		webhook.ID,
+		webhook.TournamentID,
+		webhook.URL,
+		webhook.Secret,
+		pq.Array(events),
+		webhook.CreatedBy,
+	).Scan(&webhook.CreatedAt)
+
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook")
+	}
+
+	return nil
+}
+
+// GetByID получает webhook по ID
+func (r *WebhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM tournament_webhooks WHERE id = $1`
+
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrNotFound.WithMessage("webhook not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook")
+	}
+
+	return webhook, nil
+}
+
+// ListByTournament возвращает webhook'и турнира в порядке создания
+func (r *WebhookRepository) ListByTournament(ctx context.Context, tournamentID uuid.UUID) ([]*domain.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM tournament_webhooks
+		WHERE tournament_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tournamentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list webhooks")
+	}
+	defer rows.Close()
+
+	webhooks := make([]*domain.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan webhook")
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to list webhooks")
+	}
+
+	return webhooks, nil
+}
+
+// Delete удаляет webhook вместе с историей его доставок
+func (r *WebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tournament_webhooks WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete webhook")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+	if rows == 0 {
+		return errors.ErrNotFound.WithMessage("webhook not found")
+	}
+
+	return nil
+}
+
+// GetWebhookSecret возвращает секрет для подписи доставок webhook'а
+func (r *WebhookRepository) GetWebhookSecret(ctx context.Context, id uuid.UUID) (string, error) {
+	var secret string
+
+	err := r.db.QueryRowContext(ctx, `SELECT secret FROM tournament_webhooks WHERE id = $1`, id).Scan(&secret)
+	if err == sql.ErrNoRows {
+		return "", errors.ErrNotFound.WithMessage("webhook not found")
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get webhook secret")
+	}
+
+	return secret, nil
+}
+
+// HasSubscribers проверяет, подписан ли на событие хотя бы один webhook турнира
+func (r *WebhookRepository) HasSubscribers(ctx context.Context, tournamentID uuid.UUID, event domain.WebhookEvent) (bool, error) {
+	var exists bool
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM tournament_webhooks WHERE tournament_id = $1 AND $2 = ANY(events)
+		)
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, tournamentID, string(event)).Scan(&exists); err != nil {
+		return false, errors.Wrap(err, "failed to check webhook subscribers")
+	}
+
+	return exists, nil
+}
+
+// EnqueueDeliveries ставит событие в outbox каждому webhook'у турнира, подписанному на него.
+// Повторное событие с тем же dedupe_key пропускается. Возвращает число записанных доставок
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, tournamentID uuid.UUID, event domain.WebhookEvent, payload []byte, dedupeKey string) (int64, error) {
+	query := `
+		INSERT INTO notification_outbox (webhook_id, channel, recipient, event, subject, body, dedupe_key)
+		SELECT w.id, 'webhook', w.url, $2::text, $2::text, $3, $4
+		FROM tournament_webhooks w
+		WHERE w.tournament_id = $1 AND $2::text = ANY(w.events)
+		ON CONFLICT (webhook_id, dedupe_key) WHERE webhook_id IS NOT NULL DO NOTHING
+	`
+
+	result, err := r.db.ExecWithMetrics(ctx, "webhook_enqueue", query, tournamentID, string(event), string(payload), dedupeKey)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to enqueue webhook deliveries")
+	}
+
+	enqueued, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get enqueued deliveries count")
+	}
+
+	return enqueued, nil
+}
+
+// ListDeliveries возвращает последние доставки webhook'а, новые первыми
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT id, event, status, attempts,
+		       CASE WHEN status = 'pending' THEN next_attempt_at END AS next_attempt_at,
+		       last_error, body AS payload, created_at, sent_at
+		FROM notification_outbox
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	deliveries := make([]*domain.WebhookDelivery, 0)
+	if err := r.db.QueryWithMetrics(ctx, "webhook_list_deliveries", &deliveries, query, webhookID, limit); err != nil {
+		return nil, errors.Wrap(err, "failed to list webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// scanWebhook читает строку tournament_webhooks с колонками webhookColumns
+func scanWebhook(row interface{ Scan(...any) error }) (*domain.Webhook, error) {
+	var (
+		webhook domain.Webhook
+		events  []string
+	)
+
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.TournamentID,
+		&webhook.URL,
+		&webhook.Secret,
+		pq.Array(&events),
+		&webhook.CreatedBy,
+		&webhook.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Events = make([]domain.WebhookEvent, len(events))
+	for i, event := range events {
+		webhook.Events[i] = domain.WebhookEvent(event)
+	}
+
+	return &webhook, nil
+}
@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"golang.org/x/sync/errgroup"
+)
+
+// MatchRunner выполняет один матч (Executor или его обёртка)
+type MatchRunner interface {
+	Execute(ctx context.Context, match *domain.Match, program1Path, program2Path string, opts RunOptions) (*domain.MatchResult, error)
+}
+
+// BatchRun - матч пакета с путями к программам и параметрами запуска
+type BatchRun struct {
+	Match        *domain.Match
+	Program1Path string
+	Program2Path string
+	Options      RunOptions
+}
+
+// BatchResult - результат выполнения матча пакета: результат либо ошибка
+type BatchResult struct {
+	Result *domain.MatchResult
+	Err    error
+}
+
+// RunBatch выполняет матчи пакета параллельно через runner.
+// Ошибка одного матча не прерывает остальные: результаты возвращаются в порядке runs
+func RunBatch(ctx context.Context, runner MatchRunner, runs []BatchRun) []BatchResult {
+	results := make([]BatchResult, len(runs))
+
+	var g errgroup.Group
+	for i, run := range runs {
+		g.Go(func() error {
+			result, err := runner.Execute(ctx, run.Match, run.Program1Path, run.Program2Path, run.Options)
+			results[i] = BatchResult{Result: result, Err: err}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return results
+}
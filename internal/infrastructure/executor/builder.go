@@ -0,0 +1,208 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// buildDir директория со скомпилированными программами рядом с исходниками
+const buildDir = ".build"
+
+// Builder подготавливает программы к запуску: компилирует программы на компилируемых
+// языках и кэширует бинарники по ID программы и хэшу содержимого.
+// Ошибки компиляции тоже кэшируются, чтобы не пересобирать программу для каждого матча.
+// Новая версия программы имеет новый ID и хэш, поэтому собирается заново; при смене
+// содержимого программы с тем же ID старые бинарники удаляются
+type Builder struct {
+	compilers map[string]Compiler
+	log       *logger.Logger
+	metrics   *metrics.Metrics
+
+	mu       sync.Mutex
+	locks    map[string]*sync.Mutex
+	failures map[string]*CompileError
+}
+
+// NewBuilder создаёт сборщик программ с компиляторами по языкам
+func NewBuilder(compilers map[string]Compiler, log *logger.Logger) *Builder {
+	return &Builder{
+		compilers: compilers,
+		log:       log,
+		locks:     make(map[string]*sync.Mutex),
+		failures:  make(map[string]*CompileError),
+	}
+}
+
+// SetMetrics устанавливает метрики попаданий в кэш сборок
+func (b *Builder) SetMetrics(m *metrics.Metrics) {
+	b.metrics = m
+}
+
+// Build возвращает путь к исполняемому файлу программы.
+// Для интерпретируемых языков это исходный файл, для компилируемых - бинарник
+// в .build рядом с исходником, собранный при первом обращении
+func (b *Builder) Build(ctx context.Context, program *domain.Program) (string, error) {
+	if !IsCompiledLanguage(program.Language) {
+		return program.CodePath, nil
+	}
+
+	compiler, ok := b.compilers[program.Language]
+	if !ok {
+		return "", &CompileError{Output: fmt.Sprintf("language %s is not supported", program.Language)}
+	}
+
+	hash, err := programHash(program)
+	if err != nil {
+		return "", err
+	}
+	key := buildKey(program.ID, hash)
+	output := filepath.Join(filepath.Dir(program.CodePath), buildDir, key)
+
+	// Одну программу собирает только один worker этого процесса
+	lock := b.lock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if failure := b.failure(key); failure != nil {
+		b.recordCache(true)
+		return "", failure
+	}
+	if _, err := os.Stat(output); err == nil {
+		b.recordCache(true)
+		return output, nil
+	}
+	b.recordCache(false)
+	b.evictStale(program.ID, key, filepath.Dir(output))
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return "", fmt.Errorf("failed to create build directory: %w", err)
+	}
+
+	b.log.Info("Compiling program",
+		zap.String("program_id", program.ID.String()),
+		zap.String("language", program.Language),
+	)
+
+	// Компилируем во временный файл: другие процессы не должны увидеть недописанный бинарник
+	tmp := output + ".tmp-" + uuid.NewString()[:8]
+	if err := compiler.Compile(ctx, program.CodePath, tmp); err != nil {
+		_ = os.Remove(tmp)
+		var compileErr *CompileError
+		if errors.As(err, &compileErr) {
+			b.mu.Lock()
+			b.failures[key] = compileErr
+			b.mu.Unlock()
+		}
+		return "", err
+	}
+
+	if err := os.Chmod(tmp, 0755); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("failed to make binary executable: %w", err)
+	}
+	if err := os.Rename(tmp, output); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("failed to store binary: %w", err)
+	}
+
+	return output, nil
+}
+
+// recordCache записывает попадание или промах кэша сборок
+func (b *Builder) recordCache(hit bool) {
+	if b.metrics == nil {
+		return
+	}
+	if hit {
+		b.metrics.RecordCacheHit("compile")
+	} else {
+		b.metrics.RecordCacheMiss("compile")
+	}
+}
+
+// evictStale удаляет бинарники и ошибки сборки прежнего содержимого программы
+func (b *Builder) evictStale(programID uuid.UUID, key, dir string) {
+	prefix := programID.String() + "-"
+
+	b.mu.Lock()
+	for stale := range b.failures {
+		if stale != key && strings.HasPrefix(stale, prefix) {
+			delete(b.failures, stale)
+		}
+	}
+	b.mu.Unlock()
+
+	stale, _ := filepath.Glob(filepath.Join(dir, prefix+"*"))
+	for _, path := range stale {
+		name := filepath.Base(path)
+		// Временные файлы текущей сборки других процессов не трогаем
+		if name == key || strings.HasPrefix(name, key+".tmp-") {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			b.log.Warn("Failed to remove stale build", zap.Error(err), zap.String("path", path))
+		}
+	}
+}
+
+// lock возвращает mutex сборки программы
+func (b *Builder) lock(key string) *sync.Mutex {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lock, ok := b.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		b.locks[key] = lock
+	}
+	return lock
+}
+
+// failure возвращает закэшированную ошибку компиляции программы
+func (b *Builder) failure(key string) *CompileError {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures[key]
+}
+
+// buildKey ключ кэша: содержимое программы могло быть заменено при том же ID
+func buildKey(programID uuid.UUID, hash string) string {
+	if len(hash) > 16 {
+		hash = hash[:16]
+	}
+	return programID.String() + "-" + hash
+}
+
+// programHash возвращает хэш содержимого программы: сохранённый при загрузке
+// или вычисленный по файлу для программ, загруженных до появления content_hash
+func programHash(program *domain.Program) (string, error) {
+	if program.ContentHash != nil && *program.ContentHash != "" {
+		return *program.ContentHash, nil
+	}
+
+	file, err := os.Open(program.CodePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open program source: %w", err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("failed to hash program source: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,173 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCompiler copies the source into the output, or fails with the configured output
+type fakeCompiler struct {
+	calls atomic.Int32
+	fail  string
+}
+
+func (c *fakeCompiler) Compile(_ context.Context, source, output string) error {
+	c.calls.Add(1)
+	if c.fail != "" {
+		return &CompileError{Output: c.fail}
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, append([]byte("binary:"), data...), 0644)
+}
+
+func newTestProgram(t *testing.T, language, source string) *domain.Program {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "program.src")
+	require.NoError(t, os.WriteFile(path, []byte(source), 0644))
+	return &domain.Program{ID: uuid.New(), Language: language, CodePath: path}
+}
+
+func newTestBuilder(compilers map[string]Compiler) *Builder {
+	log, _ := logger.New("debug", "json")
+	return NewBuilder(compilers, log)
+}
+
+func TestBuilder_InterpretedLanguageRunsAsIs(t *testing.T) {
+	compiler := &fakeCompiler{}
+	builder := newTestBuilder(map[string]Compiler{"cpp": compiler})
+	program := newTestProgram(t, "python", "print(1)")
+
+	path, err := builder.Build(context.Background(), program)
+
+	require.NoError(t, err)
+	assert.Equal(t, program.CodePath, path)
+	assert.Equal(t, int32(0), compiler.calls.Load())
+}
+
+func TestBuilder_CompilesOnceAndCaches(t *testing.T) {
+	compiler := &fakeCompiler{}
+	builder := newTestBuilder(map[string]Compiler{"cpp": compiler})
+	program := newTestProgram(t, "cpp", "int main() {}")
+
+	var wg sync.WaitGroup
+	paths := make([]string, 4)
+	for i := range paths {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path, err := builder.Build(context.Background(), program)
+			assert.NoError(t, err)
+			paths[i] = path
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), compiler.calls.Load())
+	for _, path := range paths {
+		assert.Equal(t, paths[0], path)
+	}
+
+	assert.Equal(t, filepath.Join(filepath.Dir(program.CodePath), buildDir), filepath.Dir(paths[0]))
+	data, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, "binary:int main() {}", string(data))
+
+	info, err := os.Stat(paths[0])
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0100, "binary must be executable")
+
+	// A fresh builder (worker restart) reuses the binary on disk
+	restarted := newTestBuilder(map[string]Compiler{"cpp": compiler})
+	path, err := restarted.Build(context.Background(), program)
+	require.NoError(t, err)
+	assert.Equal(t, paths[0], path)
+	assert.Equal(t, int32(1), compiler.calls.Load())
+}
+
+func TestBuilder_ChangedContentIsRebuilt(t *testing.T) {
+	compiler := &fakeCompiler{}
+	builder := newTestBuilder(map[string]Compiler{"go": compiler})
+	program := newTestProgram(t, "go", "package main")
+
+	first, err := builder.Build(context.Background(), program)
+	require.NoError(t, err)
+
+	hash := "0123456789abcdef0123"
+	program.ContentHash = &hash
+	second, err := builder.Build(context.Background(), program)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, int32(2), compiler.calls.Load())
+
+	// The binary of the previous content is evicted
+	_, err = os.Stat(first)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(second)
+	assert.NoError(t, err)
+}
+
+func TestBuilder_RecordsCacheMetrics(t *testing.T) {
+	m := metrics.New()
+	hits := m.CacheHits.WithLabelValues("compile")
+	misses := m.CacheMisses.WithLabelValues("compile")
+	hitsBefore, missesBefore := testutil.ToFloat64(hits), testutil.ToFloat64(misses)
+
+	builder := newTestBuilder(map[string]Compiler{"rust": &fakeCompiler{}})
+	builder.SetMetrics(m)
+	program := newTestProgram(t, "rust", "fn main() {}")
+
+	for i := 0; i < 3; i++ {
+		_, err := builder.Build(context.Background(), program)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(hits)-hitsBefore)
+	assert.Equal(t, float64(1), testutil.ToFloat64(misses)-missesBefore)
+}
+
+func TestBuilder_CompileFailure(t *testing.T) {
+	compiler := &fakeCompiler{fail: "main.cpp:1: error: expected ';'"}
+	builder := newTestBuilder(map[string]Compiler{"cpp": compiler})
+	program := newTestProgram(t, "cpp", "int main() {")
+
+	_, err := builder.Build(context.Background(), program)
+	require.Error(t, err)
+	assert.True(t, IsCompileError(err))
+	assert.Contains(t, err.Error(), "compilation failed")
+	assert.Contains(t, err.Error(), "expected ';'")
+
+	// The failure is cached: the program is not recompiled for every match
+	_, err = builder.Build(context.Background(), program)
+	assert.True(t, IsCompileError(err))
+	assert.Equal(t, int32(1), compiler.calls.Load())
+
+	entries, err := os.ReadDir(filepath.Join(filepath.Dir(program.CodePath), buildDir))
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no partial binaries must be left behind")
+}
+
+func TestBuilder_CompiledLanguageWithoutCompiler(t *testing.T) {
+	builder := newTestBuilder(map[string]Compiler{})
+	program := newTestProgram(t, "java", "class Main {}")
+
+	_, err := builder.Build(context.Background(), program)
+
+	assert.True(t, IsCompileError(err))
+	assert.Contains(t, err.Error(), "java")
+}
@@ -0,0 +1,197 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"go.uber.org/zap"
+)
+
+// maxCompileOutput максимальная длина вывода компилятора в сообщении об ошибке
+const maxCompileOutput = 4000
+
+// Compiler компилирует исходный код программы в исполняемый файл
+type Compiler interface {
+	// Compile компилирует source в output (пути внутри worker).
+	// Ошибки в коде программы возвращаются как *CompileError
+	Compile(ctx context.Context, source, output string) error
+}
+
+// CompileError ошибка компиляции программы
+type CompileError struct {
+	Output string // Вывод компилятора
+}
+
+func (e *CompileError) Error() string {
+	if e.Output == "" {
+		return "compilation failed"
+	}
+	return "compilation failed: " + e.Output
+}
+
+// IsCompileError проверяет, что ошибка вызвана кодом программы, а не инфраструктурой
+func IsCompileError(err error) bool {
+	var compileErr *CompileError
+	return errors.As(err, &compileErr)
+}
+
+// compiledLanguages языки, программы на которых нельзя запустить без компиляции
+var compiledLanguages = map[string]bool{
+	"c":    true,
+	"cpp":  true,
+	"go":   true,
+	"rust": true,
+	"java": true,
+}
+
+// IsCompiledLanguage проверяет, нужна ли программе на языке компиляция
+func IsCompiledLanguage(language string) bool {
+	return compiledLanguages[language]
+}
+
+// Toolchain Docker образ и команда компиляции языка
+type Toolchain struct {
+	Image   string
+	Command func(source, output string) []string
+	Env     []string
+}
+
+// DefaultToolchains компиляторы по умолчанию. Бинарники собираются статически,
+// так как запускаются в образе tjudge-cli с другим набором библиотек.
+// Java не поддерживается: в образе tjudge-cli нет JVM
+var DefaultToolchains = map[string]Toolchain{
+	"c": {
+		Image: "gcc:13",
+		Command: func(source, output string) []string {
+			return []string{"gcc", "-O2", "-static", "-o", output, source, "-lm"}
+		},
+	},
+	"cpp": {
+		Image: "gcc:13",
+		Command: func(source, output string) []string {
+			return []string{"g++", "-O2", "-std=c++17", "-static", "-o", output, source}
+		},
+	},
+	"go": {
+		Image: "golang:1.24",
+		Command: func(source, output string) []string {
+			return []string{"go", "build", "-o", output, source}
+		},
+		Env: []string{"CGO_ENABLED=0", "GOCACHE=/tmp/gocache", "GOPATH=/tmp/gopath"},
+	},
+	"rust": {
+		Image: "rust:1.82",
+		Command: func(source, output string) []string {
+			return []string{"rustc", "-O", "-C", "target-feature=+crt-static", "-o", output, source}
+		},
+	},
+}
+
+// dockerCompiler компилирует программы в отдельном контейнере с toolchain языка
+type dockerCompiler struct {
+	executor  *Executor
+	toolchain Toolchain
+}
+
+// Compilers возвращает компиляторы для языков из DefaultToolchains
+func (e *Executor) Compilers() map[string]Compiler {
+	compilers := make(map[string]Compiler, len(DefaultToolchains))
+	for language, toolchain := range DefaultToolchains {
+		compilers[language] = &dockerCompiler{executor: e, toolchain: toolchain}
+	}
+	return compilers
+}
+
+// Compile запускает компилятор в контейнере без сети с директорией программ,
+// смонтированной на запись
+func (c *dockerCompiler) Compile(ctx context.Context, source, output string) error {
+	e := c.executor
+
+	parent := ctx
+	if e.config.CompileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.config.CompileTimeout)
+		defer cancel()
+	}
+
+	cmd := c.toolchain.Command(e.hostToContainerPath(source), e.hostToContainerPath(output))
+
+	containerConfig := &container.Config{
+		Image:      c.toolchain.Image,
+		Cmd:        cmd,
+		Env:        c.toolchain.Env,
+		WorkingDir: filepath.Dir(e.hostToContainerPath(source)),
+		Tty:        false,
+	}
+
+	hostConfig := &container.HostConfig{
+		Resources: container.Resources{
+			Memory:     e.config.MemoryLimit,
+			MemorySwap: e.config.MemoryLimit,
+			PidsLimit:  &e.config.PidsLimit,
+			CpusetCpus: e.config.CPUSetCPUs,
+		},
+		Binds: []string{
+			fmt.Sprintf("%s:%s", e.hostProgramsPath, e.containerPath),
+		},
+		NetworkMode: "none",
+		SecurityOpt: []string{"no-new-privileges:true"},
+		CapDrop:     []string{"ALL"},
+		Tmpfs: map[string]string{
+			"/tmp": "rw,exec,size=512m",
+		},
+	}
+
+	resp, err := e.dockerClient.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("failed to create compiler container: %w", classifyDockerError(err))
+	}
+	defer e.cleanup(resp.ID)
+
+	if err := e.dockerClient.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return fmt.Errorf("failed to start compiler container: %w", classifyDockerError(err))
+	}
+
+	statusCh, errCh := e.dockerClient.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("error waiting for compiler container: %w", classifyDockerError(err))
+		}
+		return fmt.Errorf("compiler container stopped without status")
+	case status := <-statusCh:
+		if status.StatusCode == 0 {
+			return nil
+		}
+
+		stdout, stderr, err := e.getContainerLogs(ctx, resp.ID)
+		if err != nil {
+			return fmt.Errorf("compiler exited with code %d, failed to get logs: %w", status.StatusCode, classifyDockerError(err))
+		}
+
+		e.log.Info("Compilation failed",
+			zap.String("source", source),
+			zap.Int64("exit_code", status.StatusCode),
+		)
+		return &CompileError{Output: compileOutput(stdout, stderr)}
+	case <-ctx.Done():
+		// Истёк только таймаут компиляции - программа компилируется слишком долго
+		if parent.Err() == nil {
+			return &CompileError{Output: "compilation timeout"}
+		}
+		return parent.Err()
+	}
+}
+
+// compileOutput объединяет вывод компилятора и обрезает его до maxCompileOutput
+func compileOutput(stdout, stderr string) string {
+	output := strings.TrimSpace(strings.TrimSpace(stderr) + "\n" + strings.TrimSpace(stdout))
+	if len(output) > maxCompileOutput {
+		output = strings.ToValidUTF8(output[:maxCompileOutput], "") + "..."
+	}
+	return sanitizeForDB(output)
+}
@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// matchLabel метка контейнера матча, значение - ID матча
+const matchLabel = "tjudge-match"
+
+// psEntry строка вывода docker ps --format json
+type psEntry struct {
+	ID     string `json:"ID"`
+	Labels string `json:"Labels"` // "key=value,key=value"
+}
+
+// GetContainerID возвращает ID контейнера, в котором сейчас выполняется матч.
+// Пустая строка - матч не выполняется этим executor'ом
+func (e *Executor) GetContainerID(matchID uuid.UUID) string {
+	if containerID, ok := e.containers.Load(matchID); ok {
+		return containerID.(string)
+	}
+	return ""
+}
+
+// CleanupOrphanedContainers останавливает и удаляет контейнеры матчей, которые этот executor
+// не запускал: они остались после падения worker'а и продолжают занимать CPU и память.
+// Рассчитан на один worker на Docker daemon: контейнеры соседних worker'ов тоже будут остановлены
+func (e *Executor) CleanupOrphanedContainers(ctx context.Context) error {
+	output, err := e.runner.Run(ctx, "docker", "ps", "--no-trunc", "--filter", "label="+matchLabel, "--format", "json")
+	if err != nil {
+		if isDaemonUnavailableOutput(string(output)) {
+			return fmt.Errorf("failed to list match containers: %w: %s", ErrDockerUnavailable, strings.TrimSpace(string(output)))
+		}
+		return fmt.Errorf("failed to list match containers: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	entries, err := parsePsOutput(output)
+	if err != nil {
+		return err
+	}
+
+	tracked := make(map[string]bool)
+	e.containers.Range(func(_, containerID any) bool {
+		tracked[containerID.(string)] = true
+		return true
+	})
+	if e.warm != nil {
+		for _, id := range e.warm.ids() {
+			tracked[id] = true
+		}
+	}
+
+	var failed int
+	for _, entry := range entries {
+		if tracked[entry.ID] {
+			continue
+		}
+
+		e.log.Warn("Stopping orphaned match container",
+			zap.String("container_id", entry.ID),
+			zap.String("match_id", labelValue(entry.Labels, matchLabel)),
+		)
+		if output, err := e.runner.Run(ctx, "docker", "stop", entry.ID); err != nil {
+			failed++
+			e.log.Error("Failed to stop orphaned container",
+				zap.Error(err),
+				zap.String("container_id", entry.ID),
+				zap.String("output", strings.TrimSpace(string(output))),
+			)
+			continue
+		}
+		// Контейнеры матчей создаются без автоудаления
+		if output, err := e.runner.Run(ctx, "docker", "rm", entry.ID); err != nil {
+			e.log.Warn("Failed to remove orphaned container",
+				zap.Error(err),
+				zap.String("container_id", entry.ID),
+				zap.String("output", strings.TrimSpace(string(output))),
+			)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to stop %d orphaned containers", failed)
+	}
+	return nil
+}
+
+// parsePsOutput разбирает вывод docker ps --format json: по объекту на строку
+func parsePsOutput(output []byte) ([]psEntry, error) {
+	var entries []psEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry psEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse docker ps output: %w", err)
+		}
+		if entry.ID != "" {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read docker ps output: %w", err)
+	}
+
+	return entries, nil
+}
+
+// labelValue возвращает значение метки из строки "key=value,key=value"
+func labelValue(labels, key string) string {
+	for _, label := range strings.Split(labels, ",") {
+		if k, v, ok := strings.Cut(label, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// psRunner returns canned docker ps output and records the other docker commands
+type psRunner struct {
+	psOutput string
+	psErr    error
+	calls    []string
+}
+
+func (r *psRunner) Run(_ context.Context, name string, args ...string) ([]byte, error) {
+	if len(args) > 0 && args[0] == "ps" {
+		return []byte(r.psOutput), r.psErr
+	}
+	r.calls = append(r.calls, name+" "+strings.Join(args, " "))
+	return nil, nil
+}
+
+func TestExecutor_GetContainerID(t *testing.T) {
+	e := newWarmupExecutor(&psRunner{})
+	matchID := uuid.New()
+
+	assert.Empty(t, e.GetContainerID(matchID))
+
+	e.containers.Store(matchID, "abc123")
+	assert.Equal(t, "abc123", e.GetContainerID(matchID))
+}
+
+func TestExecutor_CleanupOrphanedContainers(t *testing.T) {
+	t.Run("stops containers not tracked by executor", func(t *testing.T) {
+		running, orphaned := uuid.New(), uuid.New()
+		runner := &psRunner{psOutput: `{"ID":"running111","Labels":"tjudge-match=` + running.String() + `","Names":"a"}
+{"ID":"orphan222","Labels":"com.example=x,tjudge-match=` + orphaned.String() + `","Names":"b"}
+`}
+		e := newWarmupExecutor(runner)
+		e.containers.Store(running, "running111")
+
+		require.NoError(t, e.CleanupOrphanedContainers(context.Background()))
+		assert.Equal(t, []string{"docker stop orphan222", "docker rm orphan222"}, runner.calls)
+	})
+
+	t.Run("no match containers", func(t *testing.T) {
+		runner := &psRunner{}
+
+		require.NoError(t, newWarmupExecutor(runner).CleanupOrphanedContainers(context.Background()))
+		assert.Empty(t, runner.calls)
+	})
+
+	t.Run("unavailable daemon", func(t *testing.T) {
+		runner := &psRunner{
+			psOutput: "Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?",
+			psErr:    errors.New("exit status 1"),
+		}
+
+		err := newWarmupExecutor(runner).CleanupOrphanedContainers(context.Background())
+		require.Error(t, err)
+		assert.True(t, IsDockerUnavailable(err))
+		assert.Empty(t, runner.calls)
+	})
+
+	t.Run("malformed output", func(t *testing.T) {
+		runner := &psRunner{psOutput: "CONTAINER ID   IMAGE\n"}
+
+		err := newWarmupExecutor(runner).CleanupOrphanedContainers(context.Background())
+		require.Error(t, err)
+		assert.Empty(t, runner.calls)
+	})
+}
+
+func TestLabelValue(t *testing.T) {
+	assert.Equal(t, "42", labelValue("a=1,tjudge-match=42", matchLabel))
+	assert.Empty(t, labelValue("a=1", matchLabel))
+	assert.Empty(t, labelValue("", matchLabel))
+}
@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"syscall"
+
+	"github.com/docker/docker/client"
+)
+
+// ErrDockerUnavailable Docker daemon недоступен (перезапускается или остановлен).
+// Матч в этом случае не виноват: его нужно вернуть в очередь, а не помечать failed
+var ErrDockerUnavailable = errors.New("docker daemon unavailable")
+
+// daemonUnavailableMarkers фрагменты сообщений docker CLI и клиента при недоступном daemon
+var daemonUnavailableMarkers = []string{
+	"Cannot connect to the Docker daemon",
+	"Is the docker daemon running",
+	"connection refused",
+}
+
+// IsDockerUnavailable проверяет, что ошибка вызвана недоступностью Docker daemon
+func IsDockerUnavailable(err error) bool {
+	return errors.Is(err, ErrDockerUnavailable)
+}
+
+// classifyDockerError помечает ошибки связи с Docker daemon как ErrDockerUnavailable.
+// Остальные ошибки возвращаются без изменений
+func classifyDockerError(err error) error {
+	if err == nil || IsDockerUnavailable(err) || !isDaemonConnectionError(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrDockerUnavailable, err)
+}
+
+// isDaemonConnectionError распознаёт отказ в соединении и обрыв соединения с сокетом Docker
+func isDaemonConnectionError(err error) bool {
+	if client.IsErrConnectionFailed(err) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	return isDaemonUnavailableOutput(err.Error())
+}
+
+// isDaemonUnavailableOutput проверяет вывод docker CLI или текст ошибки клиента
+func isDaemonUnavailableOutput(output string) bool {
+	for _, marker := range daemonUnavailableMarkers {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ping проверяет доступность Docker daemon
+func (e *Executor) Ping(ctx context.Context) error {
+	if _, err := e.dockerClient.Ping(ctx); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %w", ErrDockerUnavailable, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyDockerError(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "unix", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+
+	tests := []struct {
+		name        string
+		err         error
+		unavailable bool
+	}{
+		{"connection failed", client.ErrorConnectionFailed("unix:///var/run/docker.sock"), true},
+		{"connection refused", fmt.Errorf("post containers/create: %w", refused), true},
+		{"socket closed", fmt.Errorf("wait: %w", io.EOF), true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"missing image", errors.New("No such image: tjudge-cli:latest"), false},
+		{"timeout", fmt.Errorf("create: %w", errors.New("context deadline exceeded")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyDockerError(tt.err)
+			assert.Equal(t, tt.unavailable, IsDockerUnavailable(err))
+			// The original error stays in the chain
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	assert.NoError(t, classifyDockerError(nil))
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"nil", nil, false},
+		{"daemon unavailable", fmt.Errorf("failed to create container: %w", ErrDockerUnavailable), true},
+		{"registry timeout", errors.New("error pulling image: net/http: TLS handshake timeout"), true},
+		{"rate limited", errors.New("toomanyrequests: You have reached your pull rate limit"), true},
+		{"reset", errors.New("read unix @->/var/run/docker.sock: read: connection reset by peer"), true},
+		{"match timeout", errors.New("match execution timeout"), false},
+		{"invalid output", errors.New("invalid output format: expected 2 scores, got: "), false},
+		{"missing image", errors.New("No such image: tjudge-cli:latest"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.transient, IsTransient(tt.err))
+		})
+	}
+}
@@ -1,22 +1,34 @@
 package executor
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/config"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/metrics"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const (
+	// outputDrainTimeout сколько трансляция вывода дочитывается после остановки контейнера
+	outputDrainTimeout = 2 * time.Second
+	// maxOutputLine длина строки вывода, после которой трансляция прекращается
+	maxOutputLine = 1 << 20
+)
+
 // Executor выполняет матчи в изолированных Docker контейнерах
 type Executor struct {
 	config           config.ExecutorConfig
@@ -24,6 +36,9 @@ type Executor struct {
 	programsPath     string // Путь к директории с программами внутри worker контейнера
 	hostProgramsPath string // Путь на реальном хосте для Docker-in-Docker
 	containerPath    string // Путь внутри контейнера tjudge-cli
+	runner           CommandRunner
+	warm             *warmPool // Пул прогретых контейнеров, nil - контейнер на каждый матч
+	containers       sync.Map  // ID матча -> ID его работающего контейнера
 	log              *logger.Logger
 }
 
@@ -40,23 +55,51 @@ func NewExecutor(cfg config.ExecutorConfig, programsPath, hostProgramsPath strin
 		hostProgramsPath = programsPath
 	}
 
-	return &Executor{
+	e := &Executor{
 		config:           cfg,
 		dockerClient:     cli,
 		programsPath:     programsPath,
 		hostProgramsPath: hostProgramsPath,
 		containerPath:    "/programs", // Фиксированный путь внутри контейнера
+		runner:           execRunner{},
 		log:              log,
-	}, nil
+	}
+	if cfg.WarmPoolSize > 0 {
+		e.warm = newWarmPool(cli, e.createWarmContainer, cfg.WarmPoolSize, cfg.WarmPoolMaxUses, log)
+	}
+
+	return e, nil
+}
+
+// SetMetrics устанавливает метрики пула прогретых контейнеров
+func (e *Executor) SetMetrics(m *metrics.Metrics) {
+	if e.warm != nil {
+		e.warm.metrics = m
+	}
+}
+
+// RunOptions - параметры конкретного запуска матча
+type RunOptions struct {
+	// Sandbox профиль изоляции (обычно из игры), пусто - профиль из конфигурации
+	Sandbox domain.SandboxProfile
+	// Output получает построчно вывод tjudge-cli, пока матч идёт (трансляция матча).
+	// nil - вывод читается только после завершения контейнера
+	Output func(line string)
+	// LanguageVersions версии языков программ 1 и 2, пусто - версия по умолчанию в образе
+	LanguageVersions [2]string
 }
 
 // Execute выполняет матч через tjudge-cli
-func (e *Executor) Execute(ctx context.Context, match *domain.Match, program1Path, program2Path string) (*domain.MatchResult, error) {
+func (e *Executor) Execute(ctx context.Context, match *domain.Match, program1Path, program2Path string, opts RunOptions) (*domain.MatchResult, error) {
+	sandbox := e.resolveSandbox(opts.Sandbox)
+
 	e.log.Info("Executing match",
 		zap.String("match_id", match.ID.String()),
 		zap.String("game_type", match.GameType),
 		zap.String("program1", program1Path),
 		zap.String("program2", program2Path),
+		zap.String("sandbox_profile", string(sandbox)),
+		zap.Int64("seed", match.EffectiveSeed()),
 	)
 
 	start := time.Now()
@@ -69,8 +112,12 @@ func (e *Executor) Execute(ctx context.Context, match *domain.Match, program1Pat
 	execCtx, cancel := context.WithTimeout(ctx, e.config.Timeout)
 	defer cancel()
 
-	// Запускаем матч в Docker контейнере
-	result, err := e.runInDocker(execCtx, match.GameType, containerProgram1, containerProgram2)
+	// Запускаем матч в прогретом контейнере пула, если он включён, иначе в новом контейнере
+	run := e.runInDocker
+	if e.warm != nil {
+		run = e.runInWarmContainer
+	}
+	result, err := run(execCtx, match.ID, match.GameType, containerProgram1, containerProgram2, match.EffectiveSeed(), sandbox, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run match: %w", err)
 	}
@@ -92,10 +139,10 @@ func (e *Executor) Execute(ctx context.Context, match *domain.Match, program1Pat
 }
 
 // runInDocker запускает матч в Docker контейнере
-func (e *Executor) runInDocker(ctx context.Context, gameType, program1, program2 string) (*domain.MatchResult, error) {
+func (e *Executor) runInDocker(ctx context.Context, matchID uuid.UUID, gameType, program1, program2 string, seed int64, sandbox domain.SandboxProfile, opts RunOptions) (*domain.MatchResult, error) {
 	// Формируем команду для tjudge-cli
 	// Формат: tjudge-cli <game_type> [OPTIONS] <PROGRAM1> <PROGRAM2>
-	cmd := e.buildCommand(gameType, program1, program2)
+	cmd := e.buildCommand(gameType, program1, program2, opts.LanguageVersions)
 
 	bindMount := fmt.Sprintf("%s:%s:ro", e.hostProgramsPath, e.containerPath)
 	e.log.Info("Creating container",
@@ -110,55 +157,14 @@ func (e *Executor) runInDocker(ctx context.Context, gameType, program1, program2
 	containerConfig := &container.Config{
 		Image: e.config.DockerImage,
 		Cmd:   cmd,
+		Env:   matchEnv(seed),
 		Tty:   false,
+		// По метке находятся контейнеры, оставшиеся после падения worker'а
+		Labels: map[string]string{matchLabel: matchID.String()},
 	}
 
 	// Ограничения ресурсов и безопасности
-	securityOpts := []string{
-		"no-new-privileges:true", // Запрещаем повышение привилегий
-	}
-
-	// Добавляем seccomp профиль если указан
-	if e.config.SeccompProfile != "" {
-		securityOpts = append(securityOpts, "seccomp="+e.config.SeccompProfile)
-	}
-
-	// Добавляем AppArmor профиль если указан
-	if e.config.AppArmorProfile != "" {
-		securityOpts = append(securityOpts, "apparmor="+e.config.AppArmorProfile)
-	}
-
-	hostConfig := &container.HostConfig{
-		Resources: container.Resources{
-			CPUQuota:       e.config.CPUQuota,
-			CPUPeriod:      100000, // 100ms period
-			Memory:         e.config.MemoryLimit,
-			MemorySwap:     e.config.MemoryLimit, // Запрещаем swap
-			PidsLimit:      &e.config.PidsLimit,
-			CpusetCpus:     e.config.CPUSetCPUs, // Ограничиваем ядра CPU
-			OomKillDisable: boolPtr(false),      // Разрешаем OOM killer
-			// BlkioWeight не поддерживается на macOS (cgroups v2)
-			Ulimits: []*container.Ulimit{
-				{Name: "nofile", Soft: 1024, Hard: 1024},        // Достаточно для Python + subprocess
-				{Name: "nproc", Soft: 64, Hard: 64},             // Достаточно для fork
-				{Name: "core", Soft: 0, Hard: 0},                // Без core dumps
-				{Name: "fsize", Soft: 10485760, Hard: 10485760}, // 10MB max file size
-			},
-		},
-		// Монтируем директорию с программами (только для чтения)
-		// Используем hostProgramsPath для Docker-in-Docker сценария
-		Binds: []string{
-			fmt.Sprintf("%s:%s:ro", e.hostProgramsPath, e.containerPath),
-		},
-		NetworkMode:    "none", // Отключаем сеть
-		ReadonlyRootfs: true,   // Только для чтения root filesystem
-		SecurityOpt:    securityOpts,
-		CapDrop:        []string{"ALL"}, // Убираем все capabilities
-		Tmpfs: map[string]string{
-			"/tmp": "rw,nosuid,size=64m", // Временная директория для записи (exec разрешён для Python)
-		},
-		AutoRemove: false, // Отключаем автоудаление чтобы получить логи
-	}
+	hostConfig := e.hostConfig(sandbox)
 
 	// Создаём контейнер
 	resp, err := e.dockerClient.ContainerCreate(
@@ -170,15 +176,25 @@ func (e *Executor) runInDocker(ctx context.Context, gameType, program1, program2
 		"",
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create container: %w", err)
+		return nil, fmt.Errorf("failed to create container: %w", classifyDockerError(err))
 	}
 
+	timing := domain.ExecutionTiming{ContainerCreatedAt: time.Now()}
 	containerID := resp.ID
+	e.containers.Store(matchID, containerID)
+	defer e.containers.Delete(matchID)
 	defer e.cleanup(containerID) // Удаляем контейнер после получения логов
 
 	// Запускаем контейнер
 	if err := e.dockerClient.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
-		return nil, fmt.Errorf("failed to start container: %w", err)
+		return nil, fmt.Errorf("failed to start container: %w", classifyDockerError(err))
+	}
+	timing.StartedAt = time.Now()
+
+	// Трансляция вывода завершается до удаления контейнера
+	if opts.Output != nil {
+		stop := e.followOutput(ctx, containerID, opts.Output)
+		defer stop()
 	}
 
 	// Ждём завершения
@@ -186,6 +202,10 @@ func (e *Executor) runInDocker(ctx context.Context, gameType, program1, program2
 	select {
 	case err := <-errCh:
 		if err != nil {
+			// Daemon недоступен: логи получить всё равно не удастся
+			if err := classifyDockerError(err); IsDockerUnavailable(err) {
+				return nil, fmt.Errorf("error waiting for container: %w", err)
+			}
 			// Пытаемся получить логи даже при ошибке
 			_, stderr, logErr := e.getContainerLogs(ctx, containerID)
 			if logErr == nil && stderr != "" {
@@ -194,11 +214,13 @@ func (e *Executor) runInDocker(ctx context.Context, gameType, program1, program2
 			return nil, fmt.Errorf("error waiting for container: %w", err)
 		}
 	case status := <-statusCh:
+		timing.FinishedAt = time.Now()
+
 		// Получаем логи контейнера
 		stdout, stderr, err := e.getContainerLogs(ctx, containerID)
 		if err != nil {
 			// Если не можем получить логи, возвращаем код выхода
-			return nil, fmt.Errorf("container exited with code %d, failed to get logs: %w", status.StatusCode, err)
+			return nil, fmt.Errorf("container exited with code %d, failed to get logs: %w", status.StatusCode, classifyDockerError(err))
 		}
 
 		e.log.Info("Container finished",
@@ -210,8 +232,26 @@ func (e *Executor) runInDocker(ctx context.Context, gameType, program1, program2
 			zap.Int("stderr_len", len(stderr)),
 		)
 
+		// Программа, превысившая MemoryLimit, убивается OOM killer: код выхода tjudge-cli
+		// в этом случае ничего не говорит о причине, поэтому проверяем состояние контейнера
+		if e.oomKilled(ctx, containerID) {
+			e.log.Warn("Container killed by OOM killer",
+				zap.String("container_id", containerID),
+				zap.Int64("exit_code", status.StatusCode),
+				zap.Int64("memory_limit", e.config.MemoryLimit),
+			)
+			result := e.oomResult(status.StatusCode, stderr)
+			result.Timing = timing
+			return result, nil
+		}
+
 		// Парсим результат
-		return e.parseResult(status.StatusCode, stdout, stderr)
+		result, err := e.parseResult(status.StatusCode, stdout, stderr)
+		if err != nil {
+			return nil, err
+		}
+		result.Timing = timing
+		return result, nil
 	case <-ctx.Done():
 		// Таймаут - останавливаем контейнер
 		_ = e.dockerClient.ContainerStop(context.Background(), containerID, container.StopOptions{})
@@ -221,6 +261,122 @@ func (e *Executor) runInDocker(ctx context.Context, gameType, program1, program2
 	return nil, fmt.Errorf("unexpected execution flow")
 }
 
+// hostConfig формирует ограничения ресурсов и безопасности контейнера матча
+func (e *Executor) hostConfig(sandbox domain.SandboxProfile) *container.HostConfig {
+	securityOpts := []string{
+		"no-new-privileges:true", // Запрещаем повышение привилегий
+	}
+
+	// Добавляем seccomp профиль если указан
+	if e.config.SeccompProfile != "" {
+		securityOpts = append(securityOpts, "seccomp="+e.config.SeccompProfile)
+	}
+
+	// Добавляем AppArmor профиль если указан
+	if e.config.AppArmorProfile != "" {
+		securityOpts = append(securityOpts, "apparmor="+e.config.AppArmorProfile)
+	}
+
+	hostConfig := &container.HostConfig{
+		Resources: container.Resources{
+			CPUQuota:       e.config.CPUQuota,
+			CPUPeriod:      100000, // 100ms period
+			Memory:         e.config.MemoryLimit,
+			MemorySwap:     e.config.MemoryLimit, // Запрещаем swap
+			PidsLimit:      &e.config.PidsLimit,
+			CpusetCpus:     e.config.CPUSetCPUs, // Ограничиваем ядра CPU
+			OomKillDisable: boolPtr(false),      // Разрешаем OOM killer
+			// BlkioWeight не поддерживается на macOS (cgroups v2)
+			Ulimits: []*container.Ulimit{
+				{Name: "nofile", Soft: 1024, Hard: 1024},        // Достаточно для Python + subprocess
+				{Name: "nproc", Soft: 64, Hard: 64},             // Достаточно для fork
+				{Name: "core", Soft: 0, Hard: 0},                // Без core dumps
+				{Name: "fsize", Soft: 10485760, Hard: 10485760}, // 10MB max file size
+			},
+		},
+		// Монтируем директорию с программами (только для чтения)
+		// Используем hostProgramsPath для Docker-in-Docker сценария
+		Binds: []string{
+			fmt.Sprintf("%s:%s:ro", e.hostProgramsPath, e.containerPath),
+		},
+		SecurityOpt: securityOpts,
+		CapDrop:     []string{"ALL"}, // Убираем все capabilities
+		Tmpfs: map[string]string{
+			"/tmp": "rw,nosuid,size=64m", // Временная директория для записи (exec разрешён для Python)
+		},
+		AutoRemove: false, // Отключаем автоудаление чтобы получить логи
+	}
+	e.applySandbox(hostConfig, sandbox)
+
+	return hostConfig
+}
+
+// resolveSandbox выбирает профиль изоляции: из параметров запуска, иначе из конфигурации
+// Неизвестные значения приводят к strict
+func (e *Executor) resolveSandbox(profile domain.SandboxProfile) domain.SandboxProfile {
+	if profile.IsValid() {
+		return profile
+	}
+	if p := domain.SandboxProfile(e.config.SandboxProfile); p.IsValid() {
+		return p
+	}
+	return domain.SandboxStrict
+}
+
+// applySandbox настраивает сеть и файловую систему контейнера по профилю
+func (e *Executor) applySandbox(hostConfig *container.HostConfig, sandbox domain.SandboxProfile) {
+	if sandbox == domain.SandboxRelaxed {
+		// Сеть открывается только если она не запрещена глобально (EXECUTOR_NETWORK_DISABLED)
+		if e.config.NetworkDisabled {
+			hostConfig.NetworkMode = "none"
+		} else {
+			hostConfig.NetworkMode = "bridge"
+		}
+		hostConfig.ReadonlyRootfs = false
+		return
+	}
+
+	hostConfig.NetworkMode = "none"  // Отключаем сеть
+	hostConfig.ReadonlyRootfs = true // Только для чтения root filesystem, запись только в tmpfs
+}
+
+// oomKilled проверяет, что процесс в контейнере был остановлен OOM killer.
+// Ошибка inspect не должна терять результат матча, поэтому считается отсутствием OOM
+func (e *Executor) oomKilled(ctx context.Context, containerID string) bool {
+	info, err := e.dockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		e.log.Warn("Failed to inspect container", zap.Error(err), zap.String("container_id", containerID))
+		return false
+	}
+	return info.State != nil && info.State.OOMKilled
+}
+
+// oomResult формирует результат матча, остановленного из-за превышения лимита памяти.
+// Если tjudge-cli успел сообщить, какая программа упала (код 1 или 2), она проигрывает
+func (e *Executor) oomResult(exitCode int64, stderr string) *domain.MatchResult {
+	result := &domain.MatchResult{ErrorCode: domain.MatchErrorOutOfMemory}
+	limit := e.config.MemoryLimit / (1024 * 1024)
+
+	var message string
+	switch exitCode {
+	case 1:
+		message = fmt.Sprintf("❌ Программа 1 превысила лимит памяти (%d МБ) и была остановлена", limit)
+		result.Winner = 2
+	case 2:
+		message = fmt.Sprintf("❌ Программа 2 превысила лимит памяти (%d МБ) и была остановлена", limit)
+		result.Winner = 1
+	default:
+		message = fmt.Sprintf("❌ Превышен лимит памяти (%d МБ): матч остановлен", limit)
+	}
+
+	if stderrClean := strings.TrimSpace(stderr); stderrClean != "" {
+		message += "\n--- stderr ---\n" + stderrClean
+	}
+	result.ErrorMessage = sanitizeForDB(message)
+
+	return result
+}
+
 // getContainerLogs получает логи контейнера
 func (e *Executor) getContainerLogs(ctx context.Context, containerID string) (string, string, error) {
 	options := container.LogsOptions{
@@ -245,6 +401,55 @@ func (e *Executor) getContainerLogs(ctx context.Context, containerID string) (st
 	return stdout.String(), stderr.String(), nil
 }
 
+// followOutput построчно передаёт в output вывод работающего контейнера.
+// Возвращённая функция ждёт, пока поток дочитается после остановки контейнера,
+// но не дольше outputDrainTimeout, и прерывает его
+func (e *Executor) followOutput(ctx context.Context, containerID string, output func(line string)) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		logs, err := e.dockerClient.ContainerLogs(ctx, containerID, container.LogsOptions{
+			ShowStdout: true,
+			ShowStderr: true,
+			Follow:     true,
+		})
+		if err != nil {
+			e.log.Warn("Failed to follow container output",
+				zap.String("container_id", containerID),
+				zap.Error(err),
+			)
+			return
+		}
+		defer logs.Close()
+
+		// stdout и stderr демультиплексируются в один поток строк
+		reader, writer := io.Pipe()
+		go func() {
+			_, err := stdcopy.StdCopy(writer, writer, logs)
+			writer.CloseWithError(err)
+		}()
+		defer reader.Close()
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64<<10), maxOutputLine)
+		for scanner.Scan() {
+			output(scanner.Text())
+		}
+	}()
+
+	return func() {
+		select {
+		case <-done:
+		case <-time.After(outputDrainTimeout):
+		}
+		cancel()
+		<-done
+	}
+}
+
 // sanitizeForDB очищает строку от символов, недопустимых в PostgreSQL (null bytes)
 func sanitizeForDB(s string) string {
 	return strings.ReplaceAll(s, "\x00", "")
@@ -364,7 +569,8 @@ func (e *Executor) hostToContainerPath(hostPath string) string {
 // Контейнер уже имеет ENTRYPOINT ["tjudge-cli"], поэтому cmd содержит только аргументы
 // Формат: <game_type> [OPTIONS] <PROGRAM1> <PROGRAM2>
 // Поддерживаемые игры: dilemma, tug_of_war (см. https://github.com/bmstu-itstech/tjudge-cli)
-func (e *Executor) buildCommand(gameType, program1, program2 string) []string {
+// Версии языков передаются флагом --language-version для каждой программы по порядку
+func (e *Executor) buildCommand(gameType, program1, program2 string, languageVersions [2]string) []string {
 	// Не включаем TJudgePath так как контейнер имеет ENTRYPOINT
 	cmd := []string{gameType}
 
@@ -378,14 +584,28 @@ func (e *Executor) buildCommand(gameType, program1, program2 string) []string {
 		cmd = append(cmd, "-v")
 	}
 
+	// Версии языков указываются только если выбрана хотя бы одна
+	if languageVersions[0] != "" || languageVersions[1] != "" {
+		cmd = append(cmd, "--language-version", languageVersions[0], "--language-version", languageVersions[1])
+	}
+
 	// Добавляем пути к программам
 	cmd = append(cmd, program1, program2)
 
 	return cmd
 }
 
-// Close закрывает Docker клиент
+// matchEnv формирует переменные окружения контейнера
+// MATCH_SEED наследуется программами и позволяет воспроизвести матч локально
+func matchEnv(seed int64) []string {
+	return []string{"MATCH_SEED=" + strconv.FormatInt(seed, 10)}
+}
+
+// Close удаляет контейнеры пула и закрывает Docker клиент
 func (e *Executor) Close() error {
+	if e.warm != nil {
+		e.warm.close()
+	}
 	if e.dockerClient != nil {
 		return e.dockerClient.Close()
 	}
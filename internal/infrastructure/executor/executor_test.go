@@ -0,0 +1,148 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/config"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/docker/docker/api/types/container"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutor_ResolveSandbox(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		profile  domain.SandboxProfile
+		expected domain.SandboxProfile
+	}{
+		{"run option wins", "strict", domain.SandboxRelaxed, domain.SandboxRelaxed},
+		{"falls back to config", "relaxed", "", domain.SandboxRelaxed},
+		{"unknown option uses config", "strict", "open", domain.SandboxStrict},
+		{"empty config defaults to strict", "", "", domain.SandboxStrict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Executor{config: config.ExecutorConfig{SandboxProfile: tt.config}}
+			assert.Equal(t, tt.expected, e.resolveSandbox(tt.profile))
+		})
+	}
+}
+
+func TestExecutor_ApplySandbox(t *testing.T) {
+	t.Run("strict disables network and writes", func(t *testing.T) {
+		e := &Executor{config: config.ExecutorConfig{NetworkDisabled: false}}
+		hc := &container.HostConfig{}
+
+		e.applySandbox(hc, domain.SandboxStrict)
+
+		assert.Equal(t, container.NetworkMode("none"), hc.NetworkMode)
+		assert.True(t, hc.ReadonlyRootfs)
+	})
+
+	t.Run("relaxed enables network when allowed", func(t *testing.T) {
+		e := &Executor{config: config.ExecutorConfig{NetworkDisabled: false}}
+		hc := &container.HostConfig{}
+
+		e.applySandbox(hc, domain.SandboxRelaxed)
+
+		assert.Equal(t, container.NetworkMode("bridge"), hc.NetworkMode)
+		assert.False(t, hc.ReadonlyRootfs)
+	})
+
+	t.Run("relaxed keeps network off when disabled globally", func(t *testing.T) {
+		e := &Executor{config: config.ExecutorConfig{NetworkDisabled: true}}
+		hc := &container.HostConfig{}
+
+		e.applySandbox(hc, domain.SandboxRelaxed)
+
+		assert.Equal(t, container.NetworkMode("none"), hc.NetworkMode)
+		assert.False(t, hc.ReadonlyRootfs)
+	})
+}
+
+func TestExecutor_BuildCommandLanguageVersions(t *testing.T) {
+	e := &Executor{config: config.ExecutorConfig{DefaultIterations: 10}}
+
+	t.Run("omits flag without versions", func(t *testing.T) {
+		cmd := e.buildCommand("dilemma", "/programs/a", "/programs/b", [2]string{})
+		assert.Equal(t, []string{"dilemma", "-i", "10", "/programs/a", "/programs/b"}, cmd)
+	})
+
+	t.Run("passes version per program", func(t *testing.T) {
+		cmd := e.buildCommand("dilemma", "/programs/a.py", "/programs/b", [2]string{"3.9", ""})
+		assert.Equal(t, []string{
+			"dilemma", "-i", "10",
+			"--language-version", "3.9", "--language-version", "",
+			"/programs/a.py", "/programs/b",
+		}, cmd)
+	})
+}
+
+func TestMatchEnv_Seed(t *testing.T) {
+	id := uuid.MustParse("0000002a-0000-4000-8000-000000000000")
+
+	t.Run("derived from match ID when not set", func(t *testing.T) {
+		match := &domain.Match{ID: id}
+		assert.Equal(t, []string{"MATCH_SEED=42"}, matchEnv(match.EffectiveSeed()))
+	})
+
+	t.Run("explicit seed wins", func(t *testing.T) {
+		match := &domain.Match{ID: id, Seed: 7}
+		assert.Equal(t, []string{"MATCH_SEED=7"}, matchEnv(match.EffectiveSeed()))
+	})
+}
+
+func TestExecutor_OOMResult(t *testing.T) {
+	e := &Executor{config: config.ExecutorConfig{MemoryLimit: 256 * 1024 * 1024}}
+
+	t.Run("failed program loses", func(t *testing.T) {
+		result := e.oomResult(1, "Killed\n")
+
+		assert.True(t, result.IsOutOfMemory())
+		assert.Equal(t, 2, result.Winner)
+		assert.Contains(t, result.ErrorMessage, "Программа 1 превысила лимит памяти (256 МБ)")
+		assert.Contains(t, result.ErrorMessage, "Killed")
+	})
+
+	t.Run("whole container killed", func(t *testing.T) {
+		result := e.oomResult(137, "")
+
+		assert.Equal(t, domain.MatchErrorOutOfMemory, result.ErrorCode)
+		assert.Equal(t, 0, result.Winner)
+		assert.Equal(t, "❌ Превышен лимит памяти (256 МБ): матч остановлен", result.ErrorMessage)
+	})
+}
+
+// batchRunner fails matches of the "broken" game and wins the rest for program 1
+type batchRunner struct{}
+
+func (batchRunner) Execute(_ context.Context, match *domain.Match, _, _ string, _ RunOptions) (*domain.MatchResult, error) {
+	if match.GameType == "broken" {
+		return nil, fmt.Errorf("runner crashed")
+	}
+	return &domain.MatchResult{MatchID: match.ID, Winner: 1}, nil
+}
+
+func TestRunBatch(t *testing.T) {
+	runs := []BatchRun{
+		{Match: &domain.Match{ID: uuid.New(), GameType: "prisoners_dilemma"}},
+		{Match: &domain.Match{ID: uuid.New(), GameType: "broken"}},
+		{Match: &domain.Match{ID: uuid.New(), GameType: "prisoners_dilemma"}},
+	}
+
+	results := RunBatch(context.Background(), batchRunner{}, runs)
+
+	// A failed element does not cancel the others and results keep the input order
+	assert.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, runs[0].Match.ID, results[0].Result.MatchID)
+	assert.Error(t, results[1].Err)
+	assert.Nil(t, results[1].Result)
+	assert.NoError(t, results[2].Err)
+	assert.Equal(t, runs[2].Match.ID, results[2].Result.MatchID)
+}
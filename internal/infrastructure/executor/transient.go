@@ -0,0 +1,38 @@
+package executor
+
+import "strings"
+
+// transientErrorMarkers фрагменты сообщений Docker о временных сбоях инфраструктуры:
+// сеть до registry, загрузка образа, занятые ресурсы daemon. Повтор выполнения
+// матча с такой ошибкой обычно проходит, программы участников в ней не виноваты
+var transientErrorMarkers = []string{
+	"TLS handshake timeout",
+	"i/o timeout",
+	"connection reset by peer",
+	"net/http: request canceled",
+	"Client.Timeout exceeded",
+	"toomanyrequests",
+	"error pulling image",
+	"device or resource busy",
+	"is already in progress",
+}
+
+// IsTransient проверяет, что ошибка выполнения матча - временный сбой инфраструктуры
+// (включая недоступность Docker daemon), после которого матч стоит повторить.
+// Ошибки программ участников и таймауты матча временными не считаются
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsDockerUnavailable(err) {
+		return true
+	}
+
+	message := err.Error()
+	for _, marker := range transientErrorMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
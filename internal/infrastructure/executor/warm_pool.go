@@ -0,0 +1,566 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// warmLabel метка прогретого контейнера, значение - профиль изоляции
+	warmLabel = "tjudge-warm"
+	// warmWorkDir префикс рабочей директории матча в прогретом контейнере (tmpfs)
+	warmWorkDir = "/tmp/match-"
+	// warmStartTimeout таймаут запуска контейнера взамен пересозданного
+	warmStartTimeout = time.Minute
+	// warmExitPollTimeout сколько ждать, пока Docker зафиксирует код выхода exec
+	warmExitPollTimeout = time.Second
+
+	// warmExecScript запускает tjudge-cli в новой рабочей директории ($0), затем завершает
+	// оставшиеся процессы программ и очищает /tmp, чтобы следующий матч начинал с чистого листа.
+	// Код выхода tjudge-cli сохраняется, 125 - не удалось подготовить директорию
+	warmExecScript = `mkdir -p "$0" && cd "$0" || exit 125
+"$@"
+code=$?
+kill -KILL -1 2>/dev/null
+cd / && find /tmp -mindepth 1 -delete 2>/dev/null
+exit $code`
+)
+
+// errWarmPoolClosed пул остановлен вместе с executor
+var errWarmPoolClosed = errors.New("warm container pool is closed")
+
+// dockerAPI часть Docker API, через которую пул работает с прогретыми контейнерами. В тестах подменяется
+type dockerAPI interface {
+	ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error
+	ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error
+	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ContainerExecCreate(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error)
+	ContainerExecAttach(ctx context.Context, execID string, options container.ExecAttachOptions) (types.HijackedResponse, error)
+	ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error)
+}
+
+// warmContainer запущенный контейнер пула
+type warmContainer struct {
+	id      string
+	sandbox domain.SandboxProfile
+	uses    int // Сколько матчей уже выполнено в контейнере
+}
+
+// warmRun результат выполнения матча в прогретом контейнере
+type warmRun struct {
+	stdout    string
+	stderr    string
+	exitCode  int64
+	oomKilled bool
+	timing    domain.ExecutionTiming
+}
+
+// warmPool держит запущенные контейнеры tjudge-cli, в которых матчи выполняются через exec:
+// создание и запуск контейнера не попадают во время матча. Ограничения ресурсов контейнера
+// действуют на каждый exec, т.к. матчи в одном контейнере выполняются по очереди.
+// Контейнер пересоздаётся после maxUses матчей и после любой ошибки
+type warmPool struct {
+	api     dockerAPI
+	create  func(ctx context.Context, sandbox domain.SandboxProfile) (string, error)
+	size    int
+	maxUses int
+	metrics *metrics.Metrics
+	log     *logger.Logger
+
+	mu         sync.Mutex
+	idle       map[domain.SandboxProfile][]*warmContainer
+	containers map[string]*warmContainer // Все контейнеры пула: свободные и занятые матчами
+	closed     bool
+	background sync.WaitGroup // Фоновые запуск и удаление контейнеров
+}
+
+// newWarmPool создаёт пул на size свободных контейнеров. create создаёт (не запуская) контейнер профиля
+func newWarmPool(
+	api dockerAPI,
+	create func(ctx context.Context, sandbox domain.SandboxProfile) (string, error),
+	size, maxUses int,
+	log *logger.Logger,
+) *warmPool {
+	return &warmPool{
+		api:        api,
+		create:     create,
+		size:       size,
+		maxUses:    maxUses,
+		log:        log,
+		idle:       make(map[domain.SandboxProfile][]*warmContainer),
+		containers: make(map[string]*warmContainer),
+	}
+}
+
+// fill запускает недостающие до size свободные контейнеры профиля
+func (p *warmPool) fill(ctx context.Context, sandbox domain.SandboxProfile) error {
+	for {
+		p.mu.Lock()
+		missing := p.size - p.idleCount()
+		p.mu.Unlock()
+		if missing <= 0 {
+			return nil
+		}
+
+		c, err := p.start(ctx, sandbox)
+		if err != nil {
+			return err
+		}
+		if !p.put(c) {
+			p.recycle(c, "overflow")
+			return nil
+		}
+	}
+}
+
+// acquire выдаёт свободный контейнер профиля, а если его нет - запускает новый
+func (p *warmPool) acquire(ctx context.Context, sandbox domain.SandboxProfile) (*warmContainer, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errWarmPoolClosed
+	}
+	if idle := p.idle[sandbox]; len(idle) > 0 {
+		c := idle[len(idle)-1]
+		p.idle[sandbox] = idle[:len(idle)-1]
+		p.mu.Unlock()
+
+		p.recordAcquire(true)
+		return c, nil
+	}
+	p.mu.Unlock()
+
+	p.recordAcquire(false)
+	return p.start(ctx, sandbox)
+}
+
+// release возвращает контейнер в пул после матча. Контейнер удаляется, если матч завершился
+// ошибкой (healthy=false), исчерпан лимит матчей или свободных контейнеров уже достаточно
+func (p *warmPool) release(c *warmContainer, healthy bool) {
+	c.uses++
+
+	var reason string
+	switch {
+	case !healthy:
+		reason = "error"
+	case c.uses >= p.maxUses:
+		reason = "max_uses"
+	}
+
+	if reason == "" && p.put(c) {
+		return
+	}
+	if reason == "" {
+		reason = "overflow"
+	}
+	p.recycle(c, reason)
+}
+
+// put делает контейнер свободным, если в пуле есть место
+func (p *warmPool) put(c *warmContainer) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.idleCount() >= p.size {
+		return false
+	}
+	p.idle[c.sandbox] = append(p.idle[c.sandbox], c)
+	return true
+}
+
+// recycle удаляет контейнер из пула. Взамен удалённого по ошибке или лимиту матчей
+// в фоне запускается новый
+func (p *warmPool) recycle(c *warmContainer, reason string) {
+	p.mu.Lock()
+	delete(p.containers, c.id)
+
+	// После остановки пула контейнер удаляется сразу: ждать фоновые задачи уже некому
+	if p.closed {
+		p.mu.Unlock()
+		p.remove(c.id)
+		return
+	}
+	replace := reason != "overflow"
+	p.background.Add(1)
+	if replace {
+		p.background.Add(1)
+	}
+	p.mu.Unlock()
+
+	p.log.Debug("Recycling warm container",
+		zap.String("container_id", c.id),
+		zap.String("reason", reason),
+		zap.Int("uses", c.uses),
+	)
+	if p.metrics != nil {
+		p.metrics.RecordWarmRecycle(reason)
+	}
+
+	go func() {
+		defer p.background.Done()
+		p.remove(c.id)
+	}()
+	if replace {
+		go func() {
+			defer p.background.Done()
+			p.replace(c.sandbox)
+		}()
+	}
+}
+
+// replace запускает контейнер взамен удалённого, если свободных контейнеров не хватает
+func (p *warmPool) replace(sandbox domain.SandboxProfile) {
+	p.mu.Lock()
+	full := p.closed || p.idleCount() >= p.size
+	p.mu.Unlock()
+	if full {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), warmStartTimeout)
+	defer cancel()
+
+	c, err := p.start(ctx, sandbox)
+	if err != nil {
+		if !errors.Is(err, errWarmPoolClosed) {
+			p.log.Warn("Failed to start warm container", zap.Error(err), zap.String("sandbox_profile", string(sandbox)))
+		}
+		return
+	}
+	if !p.put(c) {
+		p.recycle(c, "overflow")
+	}
+}
+
+// start создаёт и запускает новый контейнер пула
+func (p *warmPool) start(ctx context.Context, sandbox domain.SandboxProfile) (*warmContainer, error) {
+	id, err := p.create(ctx, sandbox)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create warm container: %w", classifyDockerError(err))
+	}
+
+	c := &warmContainer{id: id, sandbox: sandbox}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.remove(id)
+		return nil, errWarmPoolClosed
+	}
+	p.containers[id] = c
+	p.mu.Unlock()
+
+	if err := p.api.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
+		p.mu.Lock()
+		delete(p.containers, id)
+		p.mu.Unlock()
+		p.remove(id)
+		return nil, fmt.Errorf("failed to start warm container: %w", classifyDockerError(err))
+	}
+
+	return c, nil
+}
+
+// exec выполняет tjudge-cli с аргументами cmd в новой рабочей директории контейнера.
+// output, если задан, построчно получает вывод, пока матч идёт
+func (p *warmPool) exec(ctx context.Context, c *warmContainer, matchID uuid.UUID, cmd, env []string, output func(line string)) (*warmRun, error) {
+	args := append([]string{"sh", "-c", warmExecScript, warmWorkDir + matchID.String(), "tjudge-cli"}, cmd...)
+	created, err := p.api.ContainerExecCreate(ctx, c.id, container.ExecOptions{
+		Cmd:          args,
+		Env:          env,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec: %w", classifyDockerError(err))
+	}
+
+	run := &warmRun{timing: domain.ExecutionTiming{ContainerCreatedAt: time.Now()}}
+
+	// Attach запускает exec
+	attach, err := p.api.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start exec: %w", classifyDockerError(err))
+	}
+	defer attach.Close()
+	run.timing.StartedAt = time.Now()
+
+	var stdout, stderr bytes.Buffer
+	var stdoutW, stderrW io.Writer = &stdout, &stderr
+	var lines *lineWriter
+	if output != nil {
+		lines = &lineWriter{output: output}
+		stdoutW = io.MultiWriter(&stdout, lines)
+		stderrW = io.MultiWriter(&stderr, lines)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(stdoutW, stderrW, attach.Reader)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, fmt.Errorf("failed to read exec output: %w", err)
+		}
+	case <-ctx.Done():
+		// Процессы матча останавливаются вместе с контейнером при его пересоздании
+		attach.Close()
+		<-done
+		return nil, fmt.Errorf("match execution timeout")
+	}
+	run.timing.FinishedAt = time.Now()
+	if lines != nil {
+		lines.flush()
+	}
+	run.stdout, run.stderr = stdout.String(), stderr.String()
+
+	exitCode, err := p.exitCode(ctx, created.ID)
+	if err != nil {
+		return nil, err
+	}
+	run.exitCode = exitCode
+
+	// Программу, превысившую лимит памяти, OOM killer убивает внутри контейнера,
+	// сам контейнер продолжает работать
+	if exitCode != 0 {
+		run.oomKilled = p.oomKilled(ctx, c.id)
+	}
+
+	return run, nil
+}
+
+// exitCode получает код выхода exec. Поток вывода закрывается немного раньше,
+// чем Docker фиксирует завершение процесса, поэтому состояние опрашивается
+func (p *warmPool) exitCode(ctx context.Context, execID string) (int64, error) {
+	deadline := time.Now().Add(warmExitPollTimeout)
+	for {
+		inspect, err := p.api.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to inspect exec: %w", classifyDockerError(err))
+		}
+		if !inspect.Running {
+			return int64(inspect.ExitCode), nil
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("exec %s is still running after output closed", execID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// oomKilled проверяет, срабатывал ли OOM killer в контейнере
+func (p *warmPool) oomKilled(ctx context.Context, containerID string) bool {
+	info, err := p.api.ContainerInspect(ctx, containerID)
+	if err != nil {
+		p.log.Warn("Failed to inspect container", zap.Error(err), zap.String("container_id", containerID))
+		return false
+	}
+	return info.State != nil && info.State.OOMKilled
+}
+
+// remove принудительно удаляет контейнер вместе с оставшимися процессами
+func (p *warmPool) remove(containerID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.api.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true}); err != nil {
+		p.log.Error("Failed to remove warm container",
+			zap.Error(err),
+			zap.String("container_id", containerID),
+		)
+	}
+}
+
+// ids возвращает ID всех контейнеров пула
+func (p *warmPool) ids() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := make([]string, 0, len(p.containers))
+	for id := range p.containers {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// idleCount считает свободные контейнеры всех профилей. Вызывается под mu
+func (p *warmPool) idleCount() int {
+	n := 0
+	for _, idle := range p.idle {
+		n += len(idle)
+	}
+	return n
+}
+
+// close останавливает пул и удаляет свободные контейнеры. Занятые контейнеры
+// удаляются, когда матч в них завершится
+func (p *warmPool) close() {
+	p.mu.Lock()
+	p.closed = true
+	var idle []*warmContainer
+	for sandbox, containers := range p.idle {
+		idle = append(idle, containers...)
+		delete(p.idle, sandbox)
+	}
+	for _, c := range idle {
+		delete(p.containers, c.id)
+	}
+	p.mu.Unlock()
+
+	p.background.Wait()
+	for _, c := range idle {
+		p.remove(c.id)
+	}
+}
+
+// recordAcquire записывает попадание в пул или его промах
+func (p *warmPool) recordAcquire(hit bool) {
+	if p.metrics != nil {
+		p.metrics.RecordWarmContainer(hit)
+	}
+}
+
+// lineWriter построчно передаёт записанный вывод в output. Строка длиннее maxOutputLine
+// прекращает трансляцию, как и при чтении логов контейнера
+type lineWriter struct {
+	output  func(line string)
+	buf     []byte
+	stopped bool
+}
+
+func (w *lineWriter) Write(b []byte) (int, error) {
+	if w.stopped {
+		return len(b), nil
+	}
+
+	w.buf = append(w.buf, b...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.output(strings.TrimSuffix(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) > maxOutputLine {
+		w.stopped = true
+		w.buf = nil
+	}
+	return len(b), nil
+}
+
+// flush передаёт последнюю строку без перевода строки
+func (w *lineWriter) flush() {
+	if !w.stopped && len(w.buf) > 0 {
+		w.output(string(w.buf))
+	}
+	w.buf = nil
+}
+
+// warmContainerConfig конфигурация контейнера пула: вместо tjudge-cli запускается
+// бесконечный sleep, матчи выполняются через exec
+func (e *Executor) warmContainerConfig(sandbox domain.SandboxProfile) *container.Config {
+	return &container.Config{
+		Image:      e.config.DockerImage,
+		Entrypoint: []string{"sleep"},
+		Cmd:        []string{"infinity"},
+		// По метке матчей контейнеры пула тоже удаляются после падения worker'а
+		Labels: map[string]string{matchLabel: "", warmLabel: string(sandbox)},
+	}
+}
+
+// createWarmContainer создаёт контейнер пула с теми же ограничениями, что и контейнер матча
+func (e *Executor) createWarmContainer(ctx context.Context, sandbox domain.SandboxProfile) (string, error) {
+	resp, err := e.dockerClient.ContainerCreate(ctx, e.warmContainerConfig(sandbox), e.hostConfig(sandbox), nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// Prewarm запускает контейнеры пула для профиля изоляции по умолчанию, чтобы первые
+// матчи после старта worker'а не ждали их создания. Без пула ничего не делает
+func (e *Executor) Prewarm(ctx context.Context) error {
+	if e.warm == nil {
+		return nil
+	}
+
+	sandbox := e.resolveSandbox("")
+	if err := e.warm.fill(ctx, sandbox); err != nil {
+		return err
+	}
+
+	e.log.Info("Warm containers ready",
+		zap.Int("size", e.config.WarmPoolSize),
+		zap.String("sandbox_profile", string(sandbox)),
+	)
+	return nil
+}
+
+// runInWarmContainer выполняет матч через exec в контейнере пула
+func (e *Executor) runInWarmContainer(ctx context.Context, matchID uuid.UUID, gameType, program1, program2 string, seed int64, sandbox domain.SandboxProfile, opts RunOptions) (*domain.MatchResult, error) {
+	cmd := e.buildCommand(gameType, program1, program2, opts.LanguageVersions)
+
+	c, err := e.warm.acquire(ctx, sandbox)
+	if err != nil {
+		return nil, err
+	}
+	e.containers.Store(matchID, c.id)
+	defer e.containers.Delete(matchID)
+
+	e.log.Info("Running match in warm container",
+		zap.Strings("cmd", cmd),
+		zap.String("container_id", c.id),
+		zap.Int("uses", c.uses),
+	)
+
+	run, err := e.warm.exec(ctx, c, matchID, cmd, matchEnv(seed), opts.Output)
+	e.warm.release(c, err == nil && run.exitCode == 0)
+	if err != nil {
+		return nil, err
+	}
+
+	e.log.Info("Container finished",
+		zap.String("container_id", c.id),
+		zap.Int64("exit_code", run.exitCode),
+		zap.String("stdout", run.stdout),
+		zap.String("stderr", run.stderr),
+		zap.Int("stdout_len", len(run.stdout)),
+		zap.Int("stderr_len", len(run.stderr)),
+	)
+
+	if run.oomKilled {
+		e.log.Warn("Container killed by OOM killer",
+			zap.String("container_id", c.id),
+			zap.Int64("exit_code", run.exitCode),
+			zap.Int64("memory_limit", e.config.MemoryLimit),
+		)
+		result := e.oomResult(run.exitCode, run.stderr)
+		result.Timing = run.timing
+		return result, nil
+	}
+
+	result, err := e.parseResult(run.exitCode, run.stdout, run.stderr)
+	if err != nil {
+		return nil, err
+	}
+	result.Timing = run.timing
+	return result, nil
+}
@@ -0,0 +1,295 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/config"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDocker emulates the container and exec API used by the warm pool.
+// Every exec prints stdout/stderr and exits with exitCode, or hangs until detached
+type fakeDocker struct {
+	mu       sync.Mutex
+	created  int
+	started  []string
+	removed  []string
+	execs    []string // container ID of every exec
+	lastExec container.ExecOptions
+
+	stdout, stderr string
+	exitCode       int
+	hang           bool
+	oomKilled      bool
+}
+
+func (f *fakeDocker) create(_ context.Context, _ domain.SandboxProfile) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created++
+	return fmt.Sprintf("warm%d", f.created), nil
+}
+
+func (f *fakeDocker) ContainerStart(_ context.Context, containerID string, _ container.StartOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started = append(f.started, containerID)
+	return nil
+}
+
+func (f *fakeDocker) ContainerRemove(_ context.Context, containerID string, _ container.RemoveOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.removed = append(f.removed, containerID)
+	return nil
+}
+
+func (f *fakeDocker) ContainerInspect(_ context.Context, containerID string) (container.InspectResponse, error) {
+	return container.InspectResponse{ContainerJSONBase: &container.ContainerJSONBase{
+		ID:    containerID,
+		State: &container.State{Running: true, OOMKilled: f.oomKilled},
+	}}, nil
+}
+
+func (f *fakeDocker) ContainerExecCreate(_ context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs = append(f.execs, containerID)
+	f.lastExec = options
+	return container.ExecCreateResponse{ID: fmt.Sprintf("exec%d", len(f.execs))}, nil
+}
+
+func (f *fakeDocker) ContainerExecAttach(_ context.Context, _ string, _ container.ExecAttachOptions) (types.HijackedResponse, error) {
+	client, server := net.Pipe()
+	if !f.hang {
+		go func() {
+			_, _ = stdcopy.NewStdWriter(server, stdcopy.Stdout).Write([]byte(f.stdout))
+			_, _ = stdcopy.NewStdWriter(server, stdcopy.Stderr).Write([]byte(f.stderr))
+			server.Close()
+		}()
+	}
+	return types.HijackedResponse{Conn: client, Reader: bufio.NewReader(client)}, nil
+}
+
+func (f *fakeDocker) ContainerExecInspect(_ context.Context, execID string) (container.ExecInspect, error) {
+	return container.ExecInspect{ExecID: execID, ExitCode: f.exitCode}, nil
+}
+
+// removedIDs returns removed container IDs once background work is done
+func (f *fakeDocker) removedIDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := append([]string(nil), f.removed...)
+	sort.Strings(ids)
+	return ids
+}
+
+func newTestWarmPool(docker *fakeDocker, size, maxUses int) *warmPool {
+	log, _ := logger.New("error", "json")
+	return newWarmPool(docker, docker.create, size, maxUses, log)
+}
+
+func TestWarmPool_Reuse(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reuses idle container until max uses", func(t *testing.T) {
+		docker := &fakeDocker{}
+		pool := newTestWarmPool(docker, 1, 2)
+		require.NoError(t, pool.fill(ctx, domain.SandboxStrict))
+
+		first, err := pool.acquire(ctx, domain.SandboxStrict)
+		require.NoError(t, err)
+		assert.Equal(t, "warm1", first.id)
+		pool.release(first, true)
+
+		second, err := pool.acquire(ctx, domain.SandboxStrict)
+		require.NoError(t, err)
+		assert.Equal(t, "warm1", second.id)
+
+		// The second match exhausts the container: it is replaced in the background
+		pool.release(second, true)
+		pool.background.Wait()
+
+		third, err := pool.acquire(ctx, domain.SandboxStrict)
+		require.NoError(t, err)
+		assert.Equal(t, "warm2", third.id)
+		assert.Equal(t, []string{"warm1"}, docker.removedIDs())
+	})
+
+	t.Run("failed match recycles container", func(t *testing.T) {
+		docker := &fakeDocker{}
+		pool := newTestWarmPool(docker, 1, 10)
+		require.NoError(t, pool.fill(ctx, domain.SandboxStrict))
+
+		c, err := pool.acquire(ctx, domain.SandboxStrict)
+		require.NoError(t, err)
+		pool.release(c, false)
+		pool.background.Wait()
+
+		assert.Equal(t, []string{"warm1"}, docker.removedIDs())
+		assert.ElementsMatch(t, []string{"warm2"}, pool.ids())
+	})
+
+	t.Run("profiles do not share containers", func(t *testing.T) {
+		docker := &fakeDocker{}
+		pool := newTestWarmPool(docker, 2, 10)
+		require.NoError(t, pool.fill(ctx, domain.SandboxStrict))
+
+		c, err := pool.acquire(ctx, domain.SandboxRelaxed)
+		require.NoError(t, err)
+		assert.Equal(t, "warm3", c.id)
+		assert.Equal(t, domain.SandboxRelaxed, c.sandbox)
+
+		// The pool is already full of strict containers
+		pool.release(c, true)
+		pool.background.Wait()
+		assert.Equal(t, []string{"warm3"}, docker.removedIDs())
+	})
+
+	t.Run("close removes idle containers", func(t *testing.T) {
+		docker := &fakeDocker{}
+		pool := newTestWarmPool(docker, 2, 10)
+		require.NoError(t, pool.fill(ctx, domain.SandboxStrict))
+
+		busy, err := pool.acquire(ctx, domain.SandboxStrict)
+		require.NoError(t, err)
+		require.Equal(t, "warm2", busy.id)
+
+		pool.close()
+		assert.Equal(t, []string{"warm1"}, docker.removedIDs())
+
+		_, err = pool.acquire(ctx, domain.SandboxStrict)
+		assert.ErrorIs(t, err, errWarmPoolClosed)
+
+		// A match that outlives the pool removes its container on release
+		pool.release(busy, true)
+		assert.Equal(t, []string{"warm1", "warm2"}, docker.removedIDs())
+		assert.Empty(t, pool.ids())
+	})
+}
+
+func TestWarmPool_Exec(t *testing.T) {
+	ctx := context.Background()
+	matchID := uuid.New()
+
+	t.Run("runs tjudge-cli in a fresh directory", func(t *testing.T) {
+		docker := &fakeDocker{stdout: "10 15\n", stderr: "round 1\nround 2"}
+		pool := newTestWarmPool(docker, 1, 10)
+		c, err := pool.acquire(ctx, domain.SandboxStrict)
+		require.NoError(t, err)
+
+		var lines []string
+		run, err := pool.exec(ctx, c, matchID, []string{"dilemma", "/programs/a", "/programs/b"}, matchEnv(7), func(line string) {
+			lines = append(lines, line)
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "10 15\n", run.stdout)
+		assert.Equal(t, "round 1\nround 2", run.stderr)
+		assert.Zero(t, run.exitCode)
+		assert.Equal(t, []string{"10 15", "round 1", "round 2"}, lines)
+		assert.False(t, run.timing.StartedAt.IsZero())
+		assert.False(t, run.timing.FinishedAt.Before(run.timing.StartedAt))
+
+		assert.Equal(t, []string{
+			"sh", "-c", warmExecScript, "/tmp/match-" + matchID.String(),
+			"tjudge-cli", "dilemma", "/programs/a", "/programs/b",
+		}, docker.lastExec.Cmd)
+		assert.Equal(t, []string{"MATCH_SEED=7"}, docker.lastExec.Env)
+	})
+
+	t.Run("reports exit code and OOM kill", func(t *testing.T) {
+		docker := &fakeDocker{stderr: "Killed", exitCode: 1, oomKilled: true}
+		pool := newTestWarmPool(docker, 1, 10)
+		c, err := pool.acquire(ctx, domain.SandboxStrict)
+		require.NoError(t, err)
+
+		run, err := pool.exec(ctx, c, matchID, []string{"dilemma"}, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), run.exitCode)
+		assert.True(t, run.oomKilled)
+	})
+
+	t.Run("timeout detaches from exec", func(t *testing.T) {
+		docker := &fakeDocker{hang: true}
+		pool := newTestWarmPool(docker, 1, 10)
+		c, err := pool.acquire(ctx, domain.SandboxStrict)
+		require.NoError(t, err)
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		_, err = pool.exec(timeoutCtx, c, matchID, []string{"dilemma"}, nil, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timeout")
+	})
+}
+
+func TestExecutor_RunInWarmContainer(t *testing.T) {
+	log, _ := logger.New("error", "json")
+	newExecutor := func(docker *fakeDocker) *Executor {
+		e := &Executor{config: config.ExecutorConfig{DefaultIterations: 10}, log: log}
+		e.warm = newTestWarmPool(docker, 1, 10)
+		return e
+	}
+
+	t.Run("parses scores and keeps container", func(t *testing.T) {
+		docker := &fakeDocker{stdout: "10 15\n"}
+		e := newExecutor(docker)
+
+		result, err := e.runInWarmContainer(context.Background(), uuid.New(), "dilemma", "/programs/a", "/programs/b", 1, domain.SandboxStrict, RunOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Winner)
+		assert.Equal(t, []string{"warm1"}, e.warm.ids())
+	})
+
+	t.Run("program failure recycles container", func(t *testing.T) {
+		docker := &fakeDocker{stderr: "Traceback", exitCode: 1}
+		e := newExecutor(docker)
+
+		result, err := e.runInWarmContainer(context.Background(), uuid.New(), "dilemma", "/programs/a", "/programs/b", 1, domain.SandboxStrict, RunOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Winner)
+
+		e.warm.background.Wait()
+		assert.Equal(t, []string{"warm1"}, docker.removedIDs())
+	})
+}
+
+func TestExecutor_CleanupSkipsWarmContainers(t *testing.T) {
+	docker := &fakeDocker{}
+	runner := &psRunner{psOutput: `{"ID":"warm1","Labels":"tjudge-match=,tjudge-warm=strict"}
+{"ID":"stale","Labels":"tjudge-match=,tjudge-warm=strict"}
+`}
+	e := newWarmupExecutor(runner)
+	e.warm = newTestWarmPool(docker, 1, 10)
+	require.NoError(t, e.warm.fill(context.Background(), domain.SandboxStrict))
+
+	require.NoError(t, e.CleanupOrphanedContainers(context.Background()))
+	assert.Equal(t, []string{"docker stop stale", "docker rm stale"}, runner.calls)
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &lineWriter{output: func(line string) { lines = append(lines, line) }}
+
+	_, _ = w.Write([]byte("first\r\nsec"))
+	_, _ = w.Write([]byte("ond\nthird"))
+	w.flush()
+
+	assert.Equal(t, []string{"first", "second", "third"}, lines)
+}
@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// CommandRunner выполняет внешние команды (docker CLI). В тестах подменяется
+type CommandRunner interface {
+	// Run выполняет команду и возвращает её объединённый вывод stdout и stderr
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// execRunner выполняет команды через os/exec
+type execRunner struct{}
+
+func (execRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+// SetCommandRunner устанавливает исполнитель внешних команд
+func (e *Executor) SetCommandRunner(runner CommandRunner) {
+	e.runner = runner
+}
+
+// WarmUp заранее загружает слои образа tjudge-cli, чтобы первый матч после
+// деплоя не ждал docker pull. Образ, собранный локально и отсутствующий
+// в registry, считается доступным
+func (e *Executor) WarmUp(ctx context.Context) error {
+	image := e.config.DockerImage
+
+	e.log.Info("Pulling executor image", zap.String("image", image))
+
+	output, pullErr := e.runner.Run(ctx, "docker", "pull", image)
+	if pullErr == nil {
+		e.log.Info("Executor image ready", zap.String("image", image))
+		return nil
+	}
+
+	// Таймаут прогрева: локальную проверку делать уже поздно
+	if ctx.Err() != nil {
+		return fmt.Errorf("failed to pull image %s: %w", image, ctx.Err())
+	}
+
+	// Daemon недоступен: проверять локальный образ бессмысленно
+	if isDaemonUnavailableOutput(string(output)) {
+		return fmt.Errorf("failed to pull image %s: %w: %s", image, ErrDockerUnavailable, strings.TrimSpace(string(output)))
+	}
+
+	if _, err := e.runner.Run(ctx, "docker", "image", "inspect", image); err == nil {
+		e.log.Warn("Failed to pull executor image, using local image",
+			zap.String("image", image),
+			zap.String("output", strings.TrimSpace(string(output))),
+		)
+		return nil
+	}
+
+	return fmt.Errorf("image %s is unavailable (run 'docker compose build tjudge-cli'): %w: %s",
+		image, pullErr, strings.TrimSpace(string(output)))
+}
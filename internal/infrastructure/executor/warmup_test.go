@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/config"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner answers docker commands from a table keyed by the joined arguments
+type fakeRunner struct {
+	failures map[string]string
+	calls    []string
+}
+
+func (r *fakeRunner) Run(_ context.Context, name string, args ...string) ([]byte, error) {
+	call := name + " " + strings.Join(args, " ")
+	r.calls = append(r.calls, call)
+	if output, ok := r.failures[call]; ok {
+		return []byte(output), errors.New("exit status 1")
+	}
+	return nil, nil
+}
+
+func newWarmupExecutor(runner CommandRunner) *Executor {
+	log, _ := logger.New("debug", "json")
+	e := &Executor{config: config.ExecutorConfig{DockerImage: "tjudge-cli:latest"}, log: log}
+	e.SetCommandRunner(runner)
+	return e
+}
+
+func TestExecutor_WarmUp(t *testing.T) {
+	const pull = "docker pull tjudge-cli:latest"
+	const inspect = "docker image inspect tjudge-cli:latest"
+
+	t.Run("pulls configured image", func(t *testing.T) {
+		runner := &fakeRunner{}
+
+		require.NoError(t, newWarmupExecutor(runner).WarmUp(context.Background()))
+		assert.Equal(t, []string{pull}, runner.calls)
+	})
+
+	t.Run("locally built image is enough", func(t *testing.T) {
+		runner := &fakeRunner{failures: map[string]string{pull: "pull access denied"}}
+
+		require.NoError(t, newWarmupExecutor(runner).WarmUp(context.Background()))
+		assert.Equal(t, []string{pull, inspect}, runner.calls)
+	})
+
+	t.Run("missing image is an error", func(t *testing.T) {
+		runner := &fakeRunner{failures: map[string]string{
+			pull:    "pull access denied for tjudge-cli",
+			inspect: "No such image",
+		}}
+
+		err := newWarmupExecutor(runner).WarmUp(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tjudge-cli:latest is unavailable")
+		assert.Contains(t, err.Error(), "pull access denied")
+	})
+
+	t.Run("unavailable daemon skips local check", func(t *testing.T) {
+		runner := &fakeRunner{failures: map[string]string{
+			pull: "Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?",
+		}}
+
+		err := newWarmupExecutor(runner).WarmUp(context.Background())
+		require.Error(t, err)
+		assert.True(t, IsDockerUnavailable(err))
+		assert.Equal(t, []string{pull}, runner.calls)
+	})
+
+	t.Run("timeout skips local check", func(t *testing.T) {
+		runner := &fakeRunner{failures: map[string]string{pull: "context deadline exceeded"}}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := newWarmupExecutor(runner).WarmUp(ctx)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []string{pull}, runner.calls)
+	})
+}
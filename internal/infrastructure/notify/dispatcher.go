@@ -0,0 +1,190 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// sendTimeout максимальное время доставки одного уведомления
+const sendTimeout = 30 * time.Second
+
+// Sender доставляет уведомление по своему каналу (email, webhook)
+type Sender interface {
+	Send(ctx context.Context, n *domain.Notification) error
+}
+
+// Repository интерфейс outbox уведомлений
+type Repository interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.Notification, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkRetry(ctx context.Context, id int64, lastError string, delay time.Duration) error
+	MarkFailed(ctx context.Context, id int64, lastError string) error
+}
+
+// Config параметры доставки
+type Config struct {
+	PollInterval time.Duration // Период проверки outbox
+	BatchSize    int           // Уведомлений за одну проверку
+	MaxAttempts  int           // Попыток доставки до пометки failed
+	RetryDelay   time.Duration // Задержка после первой неудачи, далее удваивается
+	MaxDelay     time.Duration // Максимальная задержка между попытками
+}
+
+// Dispatcher периодически забирает уведомления из outbox и доставляет их.
+// Неудачная попытка повторяется с экспоненциальной задержкой, пока не исчерпан MaxAttempts
+type Dispatcher struct {
+	repo    Repository
+	senders map[domain.NotificationChannel]Sender
+	cfg     Config
+	log     *logger.Logger
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+// NewDispatcher создаёт новый dispatcher
+func NewDispatcher(repo Repository, cfg Config, log *logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:    repo,
+		senders: make(map[domain.NotificationChannel]Sender),
+		cfg:     cfg,
+		log:     log,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+}
+
+// SetSender задаёт отправителя для канала
+func (d *Dispatcher) SetSender(channel domain.NotificationChannel, sender Sender) {
+	d.senders[channel] = sender
+}
+
+// Start запускает периодическую доставку
+func (d *Dispatcher) Start() {
+	d.log.Info("Starting notification dispatcher",
+		zap.Duration("interval", d.cfg.PollInterval),
+	)
+
+	go d.run()
+}
+
+// Stop останавливает dispatcher, дожидаясь текущей доставки
+func (d *Dispatcher) Stop() {
+	d.log.Info("Stopping notification dispatcher")
+	close(d.stopCh)
+	<-d.doneCh
+	d.log.Info("Notification dispatcher stopped")
+}
+
+// run основной цикл доставки
+func (d *Dispatcher) run() {
+	defer close(d.doneCh)
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Пока забирается полная пачка, в outbox могут оставаться уведомления
+			for {
+				sent, err := d.dispatch(context.Background())
+				if err != nil {
+					d.log.LogError("Failed to dispatch notifications", err)
+				}
+				if err != nil || sent < d.cfg.BatchSize {
+					break
+				}
+				select {
+				case <-d.stopCh:
+					return
+				default:
+				}
+			}
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+// dispatch доставляет одну пачку уведомлений. Возвращает число забранных уведомлений
+func (d *Dispatcher) dispatch(ctx context.Context) (int, error) {
+	// Уведомление пачки не должно перейти к другому dispatcher, пока эта пачка доставляется
+	lease := time.Duration(d.cfg.BatchSize) * sendTimeout
+	notifications, err := d.repo.ClaimDue(ctx, d.cfg.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, n := range notifications {
+		d.deliver(ctx, n)
+	}
+
+	return len(notifications), nil
+}
+
+// deliver доставляет уведомление и записывает результат в outbox.
+// n.Attempts уже учитывает текущую попытку
+func (d *Dispatcher) deliver(ctx context.Context, n *domain.Notification) {
+	sender, ok := d.senders[n.Channel]
+	if !ok {
+		d.markFailed(ctx, n, fmt.Sprintf("no sender for channel %s", n.Channel))
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	err := sender.Send(sendCtx, n)
+	cancel()
+
+	if err == nil {
+		if err := d.repo.MarkSent(ctx, n.ID); err != nil {
+			d.log.LogError("Failed to mark notification sent", err, zap.Int64("notification_id", n.ID))
+		}
+		return
+	}
+
+	if n.Attempts >= d.cfg.MaxAttempts {
+		d.markFailed(ctx, n, err.Error())
+		return
+	}
+
+	delay := d.backoff(n.Attempts)
+	d.log.Warn("Notification delivery failed, will retry",
+		zap.Int64("notification_id", n.ID),
+		zap.String("event", string(n.Event)),
+		zap.Int("attempt", n.Attempts),
+		zap.Duration("retry_in", delay),
+		zap.Error(err),
+	)
+	if err := d.repo.MarkRetry(ctx, n.ID, err.Error(), delay); err != nil {
+		d.log.LogError("Failed to schedule notification retry", err, zap.Int64("notification_id", n.ID))
+	}
+}
+
+func (d *Dispatcher) markFailed(ctx context.Context, n *domain.Notification, reason string) {
+	d.log.Error("Notification delivery failed permanently",
+		zap.Int64("notification_id", n.ID),
+		zap.String("event", string(n.Event)),
+		zap.Int("attempts", n.Attempts),
+		zap.String("error", reason),
+	)
+	if err := d.repo.MarkFailed(ctx, n.ID, reason); err != nil {
+		d.log.LogError("Failed to mark notification failed", err, zap.Int64("notification_id", n.ID))
+	}
+}
+
+// backoff задержка перед следующей попыткой: RetryDelay, 2*RetryDelay, 4*RetryDelay... до MaxDelay
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.RetryDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= d.cfg.MaxDelay {
+			return d.cfg.MaxDelay
+		}
+	}
+	return min(delay, d.cfg.MaxDelay)
+}
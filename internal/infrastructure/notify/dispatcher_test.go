@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutbox hands out pending notifications and records delivery outcomes
+type memoryOutbox struct {
+	mu      sync.Mutex
+	pending []*domain.Notification
+	sent    []int64
+	retries map[int64]time.Duration
+	failed  map[int64]string
+}
+
+func newMemoryOutbox(notifications ...*domain.Notification) *memoryOutbox {
+	return &memoryOutbox{
+		pending: notifications,
+		retries: make(map[int64]time.Duration),
+		failed:  make(map[int64]string),
+	}
+}
+
+func (r *memoryOutbox) ClaimDue(_ context.Context, limit int, _ time.Duration) ([]*domain.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := min(limit, len(r.pending))
+	claimed := r.pending[:n]
+	r.pending = r.pending[n:]
+	for _, notification := range claimed {
+		notification.Attempts++
+	}
+	return claimed, nil
+}
+
+func (r *memoryOutbox) MarkSent(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, id)
+	return nil
+}
+
+func (r *memoryOutbox) MarkRetry(_ context.Context, id int64, _ string, delay time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retries[id] = delay
+	return nil
+}
+
+func (r *memoryOutbox) MarkFailed(_ context.Context, id int64, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed[id] = lastError
+	return nil
+}
+
+// fakeSender fails for recipients listed in failFor
+type fakeSender struct {
+	mu        sync.Mutex
+	delivered []string
+	failFor   map[string]bool
+}
+
+func (s *fakeSender) Send(_ context.Context, n *domain.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failFor[n.Recipient] {
+		return fmt.Errorf("connection refused")
+	}
+	s.delivered = append(s.delivered, n.Recipient)
+	return nil
+}
+
+var testConfig = Config{
+	PollInterval: 10 * time.Millisecond,
+	BatchSize:    10,
+	MaxAttempts:  3,
+	RetryDelay:   30 * time.Second,
+	MaxDelay:     time.Hour,
+}
+
+func newTestDispatcher(outbox *memoryOutbox, sender Sender) *Dispatcher {
+	log, _ := logger.New("error", "json")
+	d := NewDispatcher(outbox, testConfig, log)
+	if sender != nil {
+		d.SetSender(domain.NotificationChannelEmail, sender)
+	}
+	return d
+}
+
+func emailNotification(id int64, recipient string, attempts int) *domain.Notification {
+	return &domain.Notification{
+		ID:        id,
+		Channel:   domain.NotificationChannelEmail,
+		Recipient: recipient,
+		Event:     domain.NotificationTournamentStarted,
+		Attempts:  attempts,
+	}
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	t.Run("delivers and marks sent", func(t *testing.T) {
+		outbox := newMemoryOutbox(emailNotification(1, "a@example.com", 0), emailNotification(2, "b@example.com", 0))
+		sender := &fakeSender{}
+
+		claimed, err := newTestDispatcher(outbox, sender).dispatch(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, claimed)
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, sender.delivered)
+		assert.Equal(t, []int64{1, 2}, outbox.sent)
+	})
+
+	t.Run("failed delivery is retried with backoff", func(t *testing.T) {
+		outbox := newMemoryOutbox(emailNotification(1, "down@example.com", 1))
+		sender := &fakeSender{failFor: map[string]bool{"down@example.com": true}}
+
+		_, err := newTestDispatcher(outbox, sender).dispatch(context.Background())
+		require.NoError(t, err)
+
+		// Second attempt failed: the third one is scheduled after 2*RetryDelay
+		assert.Equal(t, 60*time.Second, outbox.retries[1])
+		assert.Empty(t, outbox.sent)
+		assert.Empty(t, outbox.failed)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		outbox := newMemoryOutbox(emailNotification(1, "down@example.com", testConfig.MaxAttempts-1))
+		sender := &fakeSender{failFor: map[string]bool{"down@example.com": true}}
+
+		_, err := newTestDispatcher(outbox, sender).dispatch(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, "connection refused", outbox.failed[1])
+		assert.Empty(t, outbox.retries)
+	})
+
+	t.Run("channel without sender fails", func(t *testing.T) {
+		notification := emailNotification(1, "a@example.com", 0)
+		notification.Channel = "webhook"
+		outbox := newMemoryOutbox(notification)
+
+		_, err := newTestDispatcher(outbox, &fakeSender{}).dispatch(context.Background())
+		require.NoError(t, err)
+
+		assert.Contains(t, outbox.failed[1], "no sender")
+	})
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := newTestDispatcher(newMemoryOutbox(), nil)
+
+	assert.Equal(t, 30*time.Second, d.backoff(1))
+	assert.Equal(t, time.Minute, d.backoff(2))
+	assert.Equal(t, 2*time.Minute, d.backoff(3))
+	assert.Equal(t, time.Hour, d.backoff(20))
+}
+
+func TestDispatcher_StartStop(t *testing.T) {
+	outbox := newMemoryOutbox(emailNotification(1, "a@example.com", 0))
+	sender := &fakeSender{}
+	d := newTestDispatcher(outbox, sender)
+
+	d.Start()
+	assert.Eventually(t, func() bool {
+		outbox.mu.Lock()
+		defer outbox.mu.Unlock()
+		return len(outbox.sent) == 1
+	}, time.Second, 5*time.Millisecond)
+	d.Stop()
+}
+
+func TestBuildMessage(t *testing.T) {
+	n := emailNotification(1, "team@example.com", 0)
+	n.Subject = "Турнир «Cup»\r\nBcc: victim@example.com"
+	n.Body = "Турнир запущен."
+
+	msg := string(buildMessage("judge@example.com", n, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	headers, body, found := strings.Cut(msg, "\r\n\r\n")
+	require.True(t, found)
+	assert.Contains(t, headers, "From: judge@example.com\r\n")
+	assert.Contains(t, headers, "To: team@example.com\r\n")
+	assert.Contains(t, headers, "Subject: =?utf-8?q?")
+	assert.Contains(t, headers, "Content-Type: text/plain; charset=utf-8")
+	// A newline in the subject must not start a new header
+	assert.NotContains(t, headers, "\r\nBcc:")
+	assert.Contains(t, body, "=D0=A2") // "Т" in quoted-printable
+}
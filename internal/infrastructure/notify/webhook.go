@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+const (
+	// SignatureHeader заголовок с HMAC-SHA256 тела запроса: "sha256=<hex>"
+	SignatureHeader = "X-TJudge-Signature"
+	// EventHeader заголовок с типом события
+	EventHeader = "X-TJudge-Event"
+	// DeliveryHeader заголовок с ID доставки, одинаковым для всех её попыток
+	DeliveryHeader = "X-TJudge-Delivery"
+
+	// maxErrorBody сколько байт ответа сохраняется в ошибке доставки
+	maxErrorBody = 512
+)
+
+// WebhookSecrets интерфейс получения секрета подписи webhook'а
+type WebhookSecrets interface {
+	GetWebhookSecret(ctx context.Context, id uuid.UUID) (string, error)
+}
+
+// WebhookSender доставляет события турниров на webhook'и. Тело подписывается
+// секретом webhook'а, ответ не из 2xx считается ошибкой и доставка повторяется.
+// Без allowPrivate запросы во внутреннюю сеть (loopback, частные адреса) запрещены
+type WebhookSender struct {
+	secrets WebhookSecrets
+	client  *http.Client
+}
+
+// NewWebhookSender создаёт отправителя webhook'ов
+func NewWebhookSender(secrets WebhookSecrets, allowPrivate bool) *WebhookSender {
+	dialer := &net.Dialer{}
+	if !allowPrivate {
+		// Адрес проверяется после разрешения имени, так что DNS не обходит запрет
+		dialer.Control = denyPrivateAddress
+	}
+
+	return &WebhookSender{
+		secrets: secrets,
+		client: &http.Client{
+			// Трассировка продолжается у получателя через заголовок traceparent
+			Transport: tracing.Transport(&http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: sendTimeout,
+			}),
+			// Перенаправление могло бы увести запрос на адрес, не прошедший проверку
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send отправляет событие. Ограничение по времени берётся из ctx
+func (s *WebhookSender) Send(ctx context.Context, n *domain.Notification) error {
+	if n.WebhookID == nil {
+		return fmt.Errorf("notification %d has no webhook", n.ID)
+	}
+
+	secret, err := s.secrets.GetWebhookSecret(ctx, *n.WebhookID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook secret: %w", err)
+	}
+
+	body := []byte(n.Body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.Recipient, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TJudge-Webhook/1.0")
+	req.Header.Set(EventHeader, string(n.Event))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(n.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+
+	return nil
+}
+
+// Sign возвращает значение заголовка подписи для тела запроса
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// denyPrivateAddress запрещает соединения с адресами, не доступными из интернета
+func denyPrivateAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// staticSecrets knows a single webhook
+type staticSecrets struct {
+	id     uuid.UUID
+	secret string
+}
+
+func (s staticSecrets) GetWebhookSecret(_ context.Context, id uuid.UUID) (string, error) {
+	if id != s.id {
+		return "", errors.ErrNotFound.WithMessage("webhook not found")
+	}
+	return s.secret, nil
+}
+
+func webhookNotification(id int64, webhookID uuid.UUID, url string) *domain.Notification {
+	return &domain.Notification{
+		ID:        id,
+		WebhookID: &webhookID,
+		Channel:   domain.NotificationChannelWebhook,
+		Recipient: url,
+		Event:     domain.NotificationEvent(domain.WebhookTournamentCompleted),
+		Body:      `{"event":"tournament.completed"}`,
+	}
+}
+
+func TestWebhookSender_Send(t *testing.T) {
+	secrets := staticSecrets{id: uuid.New(), secret: "0123456789abcdef"}
+
+	t.Run("signs the body", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		n := webhookNotification(42, secrets.id, server.URL)
+		require.NoError(t, NewWebhookSender(secrets, true).Send(context.Background(), n))
+
+		// Receivers verify the signature the same way
+		mac := hmac.New(sha256.New, []byte(secrets.secret))
+		mac.Write(body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), received.Header.Get(SignatureHeader))
+		assert.Equal(t, n.Body, string(body))
+		assert.Equal(t, "tournament.completed", received.Header.Get(EventHeader))
+		assert.Equal(t, "42", received.Header.Get(DeliveryHeader))
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	})
+
+	t.Run("non-2xx response fails with its body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		err := NewWebhookSender(secrets, true).Send(context.Background(), webhookNotification(1, secrets.id, server.URL))
+		assert.ErrorContains(t, err, "401: bad signature")
+	})
+
+	t.Run("redirect is not followed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+		}))
+		defer server.Close()
+
+		err := NewWebhookSender(secrets, true).Send(context.Background(), webhookNotification(1, secrets.id, server.URL))
+		assert.ErrorContains(t, err, "302")
+	})
+
+	t.Run("private address is rejected by default", func(t *testing.T) {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		err := NewWebhookSender(secrets, false).Send(context.Background(), webhookNotification(1, secrets.id, server.URL))
+		assert.ErrorContains(t, err, "not public")
+		assert.False(t, called)
+	})
+
+	t.Run("deleted webhook fails", func(t *testing.T) {
+		err := NewWebhookSender(secrets, true).Send(context.Background(), webhookNotification(1, uuid.New(), "https://lms.example.com/hook"))
+		assert.ErrorContains(t, err, "webhook secret")
+	})
+}
+
+func TestWebhookSender_PropagatesTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prevProvider := otel.GetTracerProvider()
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	secrets := staticSecrets{id: uuid.New(), secret: "0123456789abcdef"}
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "dispatch")
+	err := NewWebhookSender(secrets, true).Send(ctx, webhookNotification(1, secrets.id, server.URL))
+	parent.End()
+	require.NoError(t, err)
+
+	// The receiver continues the sender's trace: traceparent is "00-<trace id>-<span id>-<flags>"
+	traceID := parent.SpanContext().TraceID().String()
+	assert.Contains(t, traceparent, "00-"+traceID+"-")
+
+	var client sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() != "dispatch" {
+			client = span
+		}
+	}
+	require.NotNil(t, client, "outbound request must have a client span")
+	assert.Equal(t, parent.SpanContext().SpanID(), client.Parent().SpanID())
+	assert.Contains(t, traceparent, client.SpanContext().SpanID().String())
+}
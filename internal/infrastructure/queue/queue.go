@@ -3,21 +3,66 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
 	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/bmstu-itstech/tjudge/pkg/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
+// scheduledQueueKey sorted set запланированных матчей (score - ScheduledAt в UnixNano)
+const scheduledQueueKey = "queue:scheduled"
+
+// promoteBatchSize максимальное число запланированных матчей, переносимых в очередь за раз
+const promoteBatchSize = 100
+
+// agingScanSize число самых старых матчей очереди, проверяемых на старение за один Dequeue
+const agingScanSize = 100
+
+// maxPeekSize максимальное число матчей, возвращаемых GetPeekByPriority
+const maxPeekSize = 100
+
+// priorityLevels приоритеты в порядке возрастания
+var priorityLevels = []domain.MatchPriority{domain.PriorityLow, domain.PriorityMedium, domain.PriorityHigh}
+
+// ErrMatchNotQueued возвращается, когда матча нет ни в одной очереди
+// (уже взят worker'ом или ещё не добавлен)
+var ErrMatchNotQueued = errors.New("match not found in queue")
+
+// queueStore операции Redis, используемые очередью (реализуется cache.Cache)
+type queueStore interface {
+	LPush(ctx context.Context, key string, values ...interface{}) error
+	RPush(ctx context.Context, key string, values ...interface{}) error
+	LRem(ctx context.Context, key string, count int64, value string) (int64, error)
+	BRPop(ctx context.Context, timeout time.Duration, keys ...string) ([]string, error)
+	RPop(ctx context.Context, key string) (string, error)
+	LLen(ctx context.Context, key string) (int64, error)
+	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+	Del(ctx context.Context, keys ...string) error
+	ZAdd(ctx context.Context, key string, score float64, member string) error
+	ZRangeByScore(ctx context.Context, key string, min, max float64, limit int64) ([]string, error)
+	ZRemWithCount(ctx context.Context, key string, members ...string) (int64, error)
+	ZMoveToList(ctx context.Context, zsetKey, member, listKey string) (bool, error)
+}
+
 // QueueManager управляет очередями матчей с приоритетами
 type QueueManager struct {
-	cache   *cache.Cache
+	cache   queueStore
 	log     *logger.Logger
 	metrics *metrics.Metrics
+	now     func() time.Time
+
+	// priorityAging интервал ожидания, за который матч поднимается на одну ступень приоритета (0 - без старения)
+	priorityAging time.Duration
 }
 
 // NewQueueManager создаёт новый менеджер очередей
@@ -26,22 +71,53 @@ func NewQueueManager(cache *cache.Cache, log *logger.Logger, m *metrics.Metrics)
 		cache:   cache,
 		log:     log,
 		metrics: m,
+		now:     time.Now,
 	}
 }
 
+// SetPriorityAging включает старение приоритетов: за каждый interval ожидания в очереди
+// матч поднимается на одну ступень (low -> medium -> high), чтобы поток матчей с высоким
+// приоритетом не откладывал старые матчи бесконечно. 0 отключает старение
+func (qm *QueueManager) SetPriorityAging(interval time.Duration) {
+	qm.priorityAging = interval
+}
+
 // getQueueKey возвращает ключ для очереди по приоритету
 func (qm *QueueManager) getQueueKey(priority domain.MatchPriority) string {
 	return fmt.Sprintf("queue:%s", priority)
 }
 
 // Enqueue добавляет матч в очередь с учётом приоритета
-func (qm *QueueManager) Enqueue(ctx context.Context, match *domain.Match) error {
-	// Сериализуем матч
-	data, err := json.Marshal(match)
+func (qm *QueueManager) Enqueue(ctx context.Context, match *domain.Match) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "queue.Enqueue",
+		attribute.String("match.id", match.ID.String()),
+		attribute.String("match.priority", string(match.Priority)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// Сериализуем матч с временем постановки для метрики ожидания
+	queued := *match
+	enqueuedAt := qm.now()
+	queued.EnqueuedAt = &enqueuedAt
+
+	data, err := json.Marshal(&queued)
 	if err != nil {
 		return fmt.Errorf("failed to marshal match: %w", err)
 	}
 
+	// Запланированные на будущее матчи ждут в sorted set до наступления ScheduledAt
+	if match.ScheduledAt != nil && match.ScheduledAt.After(qm.now()) {
+		if err := qm.cache.ZAdd(ctx, scheduledQueueKey, float64(match.ScheduledAt.UnixNano()), string(data)); err != nil {
+			return fmt.Errorf("failed to schedule match: %w", err)
+		}
+
+		qm.log.Info("Match scheduled",
+			zap.String("match_id", match.ID.String()),
+			zap.Time("scheduled_at", *match.ScheduledAt),
+		)
+		return nil
+	}
+
 	// Добавляем в соответствующую очередь
 	queueKey := qm.getQueueKey(match.Priority)
 	if err := qm.cache.LPush(ctx, queueKey, data); err != nil {
@@ -61,7 +137,20 @@ func (qm *QueueManager) Enqueue(ctx context.Context, match *domain.Match) error
 
 // Dequeue извлекает матч из очереди с учётом приоритета
 // Проверяет очереди в порядке: HIGH -> MEDIUM -> LOW
-func (qm *QueueManager) Dequeue(ctx context.Context) (*domain.Match, error) {
+func (qm *QueueManager) Dequeue(ctx context.Context) (_ *domain.Match, err error) {
+	ctx, span := tracing.StartSpan(ctx, "queue.Dequeue")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// Переносим в очереди матчи, время которых наступило
+	if err := qm.promoteDue(ctx); err != nil {
+		qm.log.LogError("Failed to promote scheduled matches", err)
+	}
+
+	// Поднимаем приоритет матчей, которые ждут слишком долго
+	if err := qm.promoteAged(ctx); err != nil {
+		qm.log.LogError("Failed to promote aged matches", err)
+	}
+
 	// Используем multi-key BRPOP для эффективного ожидания на всех очередях
 	// Redis вернёт первый доступный элемент из любой очереди (в порядке приоритета)
 	queueKeys := []string{
@@ -99,6 +188,330 @@ func (qm *QueueManager) Dequeue(ctx context.Context) (*domain.Match, error) {
 	return &match, nil
 }
 
+// DequeueBatch извлекает до limit матчей. Первый матч ожидается как в Dequeue,
+// остальные берутся без ожидания из уже непустых очередей в порядке HIGH -> MEDIUM -> LOW
+func (qm *QueueManager) DequeueBatch(ctx context.Context, limit int) ([]*domain.Match, error) {
+	first, err := qm.Dequeue(ctx)
+	if err != nil || first == nil {
+		return nil, err
+	}
+
+	matches := []*domain.Match{first}
+	for len(matches) < limit {
+		item, err := qm.popHighest(ctx)
+		if err != nil {
+			// Уже извлечённые матчи не теряем: пакет просто будет меньше
+			qm.log.LogError("Failed to dequeue match for batch", err)
+			break
+		}
+		if item == "" {
+			break
+		}
+
+		var match domain.Match
+		if err := json.Unmarshal([]byte(item), &match); err != nil {
+			qm.log.LogError("Failed to unmarshal match", err)
+			continue
+		}
+		matches = append(matches, &match)
+	}
+
+	if len(matches) > 1 {
+		qm.updateQueueSizeMetrics(ctx)
+		qm.log.Info("Match batch dequeued", zap.Int("size", len(matches)))
+	}
+
+	return matches, nil
+}
+
+// popHighest извлекает без ожидания матч из очереди с наивысшим приоритетом.
+// Пустая строка - все очереди пусты
+func (qm *QueueManager) popHighest(ctx context.Context) (string, error) {
+	for _, priority := range []domain.MatchPriority{domain.PriorityHigh, domain.PriorityMedium, domain.PriorityLow} {
+		item, err := qm.cache.RPop(ctx, qm.getQueueKey(priority))
+		if err != nil {
+			return "", fmt.Errorf("failed to dequeue match: %w", err)
+		}
+		if item != "" {
+			return item, nil
+		}
+	}
+	return "", nil
+}
+
+// promoteDue переносит запланированные матчи с ScheduledAt <= now в очереди по приоритету
+// Удаление из sorted set и добавление в очередь выполняются одной операцией Redis:
+// матч переносит только один worker, и сбой между шагами не может его потерять
+func (qm *QueueManager) promoteDue(ctx context.Context) error {
+	due, err := qm.cache.ZRangeByScore(ctx, scheduledQueueKey, math.Inf(-1), float64(qm.now().UnixNano()), promoteBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range due {
+		var match domain.Match
+		if err := json.Unmarshal([]byte(item), &match); err != nil {
+			// Повреждённую запись нельзя поставить в очередь, иначе она будет читаться на каждом Dequeue
+			qm.log.LogError("Failed to unmarshal scheduled match", err)
+			if _, err := qm.cache.ZRemWithCount(ctx, scheduledQueueKey, item); err != nil {
+				return err
+			}
+			continue
+		}
+
+		moved, err := qm.cache.ZMoveToList(ctx, scheduledQueueKey, item, qm.getQueueKey(match.Priority))
+		if err != nil {
+			return fmt.Errorf("failed to promote scheduled match: %w", err)
+		}
+		if !moved {
+			// Забрал другой worker
+			continue
+		}
+
+		qm.log.Debug("Scheduled match is due",
+			zap.String("match_id", match.ID.String()),
+		)
+	}
+
+	return nil
+}
+
+// effectivePriority возвращает приоритет матча с учётом старения: базовый приоритет
+// плюс одна ступень за каждый интервал ожидания, но не выше high.
+// Матчи тренировочных турниров не стареют и не обгоняют соревновательные
+func effectivePriority(match *domain.Match, now time.Time, interval time.Duration) domain.MatchPriority {
+	rank := slices.Index(priorityLevels, match.Priority)
+	if rank < 0 || interval <= 0 || match.IsPractice {
+		return match.Priority
+	}
+	steps := int(match.QueueWait(now) / interval)
+	return priorityLevels[min(rank+steps, len(priorityLevels)-1)]
+}
+
+// promoteAged переносит состарившиеся матчи в очередь их эффективного приоритета.
+// Базовый приоритет матча не меняется. Матч переносит только тот worker, которому
+// удалось удалить его из очереди
+func (qm *QueueManager) promoteAged(ctx context.Context) error {
+	if qm.priorityAging <= 0 {
+		return nil
+	}
+
+	now := qm.now()
+	// high уже наивысший приоритет
+	for _, priority := range []domain.MatchPriority{domain.PriorityMedium, domain.PriorityLow} {
+		queueKey := qm.getQueueKey(priority)
+		rank := slices.Index(priorityLevels, priority)
+
+		// Dequeue берёт матчи с конца списка, там же самые старые
+		items, err := qm.cache.LRange(ctx, queueKey, -agingScanSize, -1)
+		if err != nil {
+			return fmt.Errorf("failed to get queue items: %w", err)
+		}
+
+		// Переносим от более новых к более старым: RPush ставит каждый следующий
+		// перед предыдущими, и самый старый матч будет взят первым
+		for _, item := range items {
+			var match domain.Match
+			if err := json.Unmarshal([]byte(item), &match); err != nil {
+				continue
+			}
+			target := effectivePriority(&match, now, qm.priorityAging)
+			if slices.Index(priorityLevels, target) <= rank {
+				continue
+			}
+
+			removed, err := qm.cache.LRem(ctx, queueKey, 1, item)
+			if err != nil {
+				return fmt.Errorf("failed to remove match from queue: %w", err)
+			}
+			if removed == 0 {
+				// Забрал другой worker
+				continue
+			}
+			if err := qm.cache.RPush(ctx, qm.getQueueKey(target), item); err != nil {
+				return fmt.Errorf("failed to promote aged match: %w", err)
+			}
+
+			qm.log.Info("Match priority aged",
+				zap.String("match_id", match.ID.String()),
+				zap.String("priority", string(match.Priority)),
+				zap.String("effective_priority", string(target)),
+				zap.Duration("wait", match.QueueWait(now)),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Reprioritize переносит ожидающий матч в очередь с новым приоритетом
+// Матч ставится первым на выполнение среди матчей нового приоритета.
+// Запланированный матч остаётся в sorted set, но получит новый приоритет при переносе в очередь
+func (qm *QueueManager) Reprioritize(ctx context.Context, matchID uuid.UUID, newPriority domain.MatchPriority) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "queue.Reprioritize",
+		attribute.String("match.id", matchID.String()),
+		attribute.String("match.priority", string(newPriority)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	for _, priority := range []domain.MatchPriority{domain.PriorityHigh, domain.PriorityMedium, domain.PriorityLow} {
+		queueKey := qm.getQueueKey(priority)
+
+		items, err := qm.cache.LRange(ctx, queueKey, 0, -1)
+		if err != nil {
+			return fmt.Errorf("failed to get queue items: %w", err)
+		}
+
+		item, match := findQueuedMatch(items, matchID)
+		if match == nil {
+			continue
+		}
+
+		// Матч переносит только тот, кому удалось удалить его из очереди -
+		// иначе его уже забрал worker
+		removed, err := qm.cache.LRem(ctx, queueKey, 1, item)
+		if err != nil {
+			return fmt.Errorf("failed to remove match from queue: %w", err)
+		}
+		if removed == 0 {
+			return ErrMatchNotQueued
+		}
+
+		match.Priority = newPriority
+		data, err := json.Marshal(match)
+		if err != nil {
+			return fmt.Errorf("failed to marshal match: %w", err)
+		}
+
+		// Dequeue берёт матчи с конца списка (BRPOP)
+		if err := qm.cache.RPush(ctx, qm.getQueueKey(newPriority), data); err != nil {
+			return fmt.Errorf("failed to enqueue match: %w", err)
+		}
+
+		qm.updateQueueSizeMetrics(ctx)
+
+		qm.log.Info("Match reprioritized",
+			zap.String("match_id", matchID.String()),
+			zap.String("old_priority", string(priority)),
+			zap.String("new_priority", string(newPriority)),
+		)
+		return nil
+	}
+
+	return qm.reprioritizeScheduled(ctx, matchID, newPriority)
+}
+
+// reprioritizeScheduled обновляет приоритет матча в sorted set запланированных матчей
+func (qm *QueueManager) reprioritizeScheduled(ctx context.Context, matchID uuid.UUID, newPriority domain.MatchPriority) error {
+	items, err := qm.cache.ZRangeByScore(ctx, scheduledQueueKey, math.Inf(-1), math.Inf(1), 0)
+	if err != nil {
+		return fmt.Errorf("failed to get scheduled matches: %w", err)
+	}
+
+	item, match := findQueuedMatch(items, matchID)
+	if match == nil || match.ScheduledAt == nil {
+		return ErrMatchNotQueued
+	}
+
+	removed, err := qm.cache.ZRemWithCount(ctx, scheduledQueueKey, item)
+	if err != nil {
+		return fmt.Errorf("failed to remove scheduled match: %w", err)
+	}
+	if removed == 0 {
+		return ErrMatchNotQueued
+	}
+
+	match.Priority = newPriority
+	data, err := json.Marshal(match)
+	if err != nil {
+		return fmt.Errorf("failed to marshal match: %w", err)
+	}
+
+	if err := qm.cache.ZAdd(ctx, scheduledQueueKey, float64(match.ScheduledAt.UnixNano()), string(data)); err != nil {
+		return fmt.Errorf("failed to schedule match: %w", err)
+	}
+
+	qm.log.Info("Scheduled match reprioritized",
+		zap.String("match_id", matchID.String()),
+		zap.String("new_priority", string(newPriority)),
+	)
+	return nil
+}
+
+// findQueuedMatch ищет матч по ID среди сериализованных элементов очереди
+func findQueuedMatch(items []string, matchID uuid.UUID) (string, *domain.Match) {
+	for _, item := range items {
+		var match domain.Match
+		if err := json.Unmarshal([]byte(item), &match); err != nil {
+			continue
+		}
+		if match.ID == matchID {
+			return item, &match
+		}
+	}
+	return "", nil
+}
+
+// GetPeekByPriority возвращает до n матчей очереди в порядке, в котором их возьмут воркеры,
+// не извлекая их. n ограничивается maxPeekSize; записи, которые не удалось разобрать, пропускаются
+func (qm *QueueManager) GetPeekByPriority(ctx context.Context, priority domain.MatchPriority, n int) ([]*domain.Match, error) {
+	if n > maxPeekSize {
+		n = maxPeekSize
+	}
+	if n <= 0 {
+		return []*domain.Match{}, nil
+	}
+
+	// Dequeue берёт матчи с конца списка (BRPOP)
+	items, err := qm.cache.LRange(ctx, qm.getQueueKey(priority), int64(-n), -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to peek queue: %w", err)
+	}
+
+	matches := make([]*domain.Match, 0, len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		var match domain.Match
+		if err := json.Unmarshal([]byte(items[i]), &match); err != nil {
+			continue
+		}
+		matches = append(matches, &match)
+	}
+	return matches, nil
+}
+
+// QueuedMatchIDs возвращает ID всех матчей в очередях и среди запланированных.
+// Каждая очередь читается одним запросом, записи, которые не удалось разобрать, пропускаются
+func (qm *QueueManager) QueuedMatchIDs(ctx context.Context) (map[uuid.UUID]struct{}, error) {
+	ids := make(map[uuid.UUID]struct{})
+	add := func(items []string) {
+		for _, item := range items {
+			var queued struct {
+				ID uuid.UUID `json:"id"`
+			}
+			if err := json.Unmarshal([]byte(item), &queued); err != nil {
+				continue
+			}
+			ids[queued.ID] = struct{}{}
+		}
+	}
+
+	for _, priority := range priorityLevels {
+		items, err := qm.cache.LRange(ctx, qm.getQueueKey(priority), 0, -1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read queue: %w", err)
+		}
+		add(items)
+	}
+
+	scheduled, err := qm.cache.ZRangeByScore(ctx, scheduledQueueKey, math.Inf(-1), math.Inf(1), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scheduled matches: %w", err)
+	}
+	add(scheduled)
+
+	return ids, nil
+}
+
 // GetQueueSize получает размер очереди по приоритету
 func (qm *QueueManager) GetQueueSize(ctx context.Context, priority domain.MatchPriority) (int64, error) {
 	queueKey := qm.getQueueKey(priority)
@@ -146,6 +559,24 @@ func (qm *QueueManager) updateQueueSizeMetrics(ctx context.Context) {
 	}
 }
 
+// RunMetricsSampler периодически обновляет метрики размеров очередей до отмены ctx.
+// Очереди меняются и другими процессами (API, другие worker'ы), поэтому
+// обновления на Enqueue/Dequeue этого процесса недостаточно
+func (qm *QueueManager) RunMetricsSampler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		qm.updateQueueSizeMetrics(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // Clear очищает все очереди
 func (qm *QueueManager) Clear(ctx context.Context) error {
 	priorities := []domain.MatchPriority{
@@ -161,6 +592,10 @@ func (qm *QueueManager) Clear(ctx context.Context) error {
 		}
 	}
 
+	if err := qm.cache.Del(ctx, scheduledQueueKey); err != nil {
+		return fmt.Errorf("failed to clear scheduled queue: %w", err)
+	}
+
 	qm.log.Info("All queues cleared")
 	return nil
 }
@@ -174,10 +609,36 @@ func (qm *QueueManager) Health(ctx context.Context) error {
 
 // QueueStats статистика очередей
 type QueueStats struct {
-	High   int64 `json:"high"`
-	Medium int64 `json:"medium"`
-	Low    int64 `json:"low"`
-	Total  int64 `json:"total"`
+	High       int64          `json:"high"`
+	Medium     int64          `json:"medium"`
+	Low        int64          `json:"low"`
+	Total      int64          `json:"total"`
+	OldestWait QueueWaitStats `json:"oldest_wait_seconds"`
+}
+
+// QueueWaitStats время ожидания следующего на выполнение матча каждой очереди, секунды
+type QueueWaitStats struct {
+	High   float64 `json:"high"`
+	Medium float64 `json:"medium"`
+	Low    float64 `json:"low"`
+}
+
+// oldestWait возвращает время ожидания матча, который будет взят из очереди следующим
+func (qm *QueueManager) oldestWait(ctx context.Context, priority domain.MatchPriority) (float64, error) {
+	// Dequeue берёт матчи с конца списка (BRPOP)
+	items, err := qm.cache.LRange(ctx, qm.getQueueKey(priority), -1, -1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get queue items: %w", err)
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	var match domain.Match
+	if err := json.Unmarshal([]byte(items[0]), &match); err != nil {
+		return 0, nil
+	}
+	return match.QueueWait(qm.now()).Seconds(), nil
 }
 
 // GetStats возвращает статистику всех очередей
@@ -203,6 +664,18 @@ func (qm *QueueManager) GetStats(ctx context.Context) (*QueueStats, error) {
 	stats.Low = low
 
 	stats.Total = stats.High + stats.Medium + stats.Low
+
+	waits := map[domain.MatchPriority]*float64{
+		domain.PriorityHigh:   &stats.OldestWait.High,
+		domain.PriorityMedium: &stats.OldestWait.Medium,
+		domain.PriorityLow:    &stats.OldestWait.Low,
+	}
+	for priority, wait := range waits {
+		if *wait, err = qm.oldestWait(ctx, priority); err != nil {
+			return nil, err
+		}
+	}
+
 	return stats, nil
 }
 
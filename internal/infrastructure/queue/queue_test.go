@@ -3,6 +3,8 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -157,11 +159,13 @@ func TestPriority_Order(t *testing.T) {
 // InMemoryQueue implements a simple in-memory queue for testing
 type InMemoryQueue struct {
 	queues map[string][]string
+	zsets  map[string]map[string]float64
 }
 
 func NewInMemoryQueue() *InMemoryQueue {
 	return &InMemoryQueue{
 		queues: make(map[string][]string),
+		zsets:  make(map[string]map[string]float64),
 	}
 }
 
@@ -170,11 +174,46 @@ func (q *InMemoryQueue) LPush(ctx context.Context, key string, values ...interfa
 		q.queues[key] = make([]string, 0)
 	}
 	for _, v := range values {
-		q.queues[key] = append([]string{v.(string)}, q.queues[key]...)
+		var value string
+		switch typed := v.(type) {
+		case []byte:
+			value = string(typed)
+		default:
+			value = typed.(string)
+		}
+		q.queues[key] = append([]string{value}, q.queues[key]...)
+	}
+	return nil
+}
+
+func (q *InMemoryQueue) RPush(ctx context.Context, key string, values ...interface{}) error {
+	for _, v := range values {
+		var value string
+		switch typed := v.(type) {
+		case []byte:
+			value = string(typed)
+		default:
+			value = typed.(string)
+		}
+		q.queues[key] = append(q.queues[key], value)
 	}
 	return nil
 }
 
+func (q *InMemoryQueue) LRem(ctx context.Context, key string, count int64, value string) (int64, error) {
+	var removed int64
+	kept := make([]string, 0, len(q.queues[key]))
+	for _, item := range q.queues[key] {
+		if item == value && (count == 0 || removed < count) {
+			removed++
+			continue
+		}
+		kept = append(kept, item)
+	}
+	q.queues[key] = kept
+	return removed, nil
+}
+
 func (q *InMemoryQueue) BRPop(ctx context.Context, timeout time.Duration, keys ...string) ([]string, error) {
 	for _, key := range keys {
 		if queue, exists := q.queues[key]; exists && len(queue) > 0 {
@@ -186,6 +225,16 @@ func (q *InMemoryQueue) BRPop(ctx context.Context, timeout time.Duration, keys .
 	return nil, nil
 }
 
+func (q *InMemoryQueue) RPop(ctx context.Context, key string) (string, error) {
+	queue := q.queues[key]
+	if len(queue) == 0 {
+		return "", nil
+	}
+	value := queue[len(queue)-1]
+	q.queues[key] = queue[:len(queue)-1]
+	return value, nil
+}
+
 func (q *InMemoryQueue) LLen(ctx context.Context, key string) (int64, error) {
 	if queue, exists := q.queues[key]; exists {
 		return int64(len(queue)), nil
@@ -193,13 +242,479 @@ func (q *InMemoryQueue) LLen(ctx context.Context, key string) (int64, error) {
 	return 0, nil
 }
 
+func (q *InMemoryQueue) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	queue := q.queues[key]
+	n := int64(len(queue))
+	// Negative indexes count from the tail, as in Redis
+	if start < 0 {
+		start = max(start+n, 0)
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return []string{}, nil
+	}
+	return append([]string(nil), queue[start:stop+1]...), nil
+}
+
 func (q *InMemoryQueue) Del(ctx context.Context, keys ...string) error {
 	for _, key := range keys {
 		delete(q.queues, key)
+		delete(q.zsets, key)
+	}
+	return nil
+}
+
+func (q *InMemoryQueue) ZAdd(ctx context.Context, key string, score float64, member string) error {
+	if q.zsets[key] == nil {
+		q.zsets[key] = make(map[string]float64)
 	}
+	q.zsets[key][member] = score
 	return nil
 }
 
+func (q *InMemoryQueue) ZRangeByScore(ctx context.Context, key string, min, max float64, limit int64) ([]string, error) {
+	result := make([]string, 0)
+	for member, score := range q.zsets[key] {
+		if score >= min && score <= max {
+			result = append(result, member)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return q.zsets[key][result[i]] < q.zsets[key][result[j]]
+	})
+	if limit > 0 && int64(len(result)) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (q *InMemoryQueue) ZRemWithCount(ctx context.Context, key string, members ...string) (int64, error) {
+	var removed int64
+	for _, member := range members {
+		if _, ok := q.zsets[key][member]; ok {
+			delete(q.zsets[key], member)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (q *InMemoryQueue) ZMoveToList(ctx context.Context, zsetKey, member, listKey string) (bool, error) {
+	if _, ok := q.zsets[zsetKey][member]; !ok {
+		return false, nil
+	}
+	delete(q.zsets[zsetKey], member)
+	return true, q.LPush(ctx, listKey, member)
+}
+
+// failingMoveQueue is an InMemoryQueue whose atomic move fails, as when Redis is unreachable
+type failingMoveQueue struct {
+	*InMemoryQueue
+}
+
+func (q *failingMoveQueue) ZMoveToList(ctx context.Context, zsetKey, member, listKey string) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+// newScheduledTestQueue creates a QueueManager backed by InMemoryQueue with a controllable clock
+func newScheduledTestQueue(now *time.Time) (*QueueManager, *InMemoryQueue) {
+	store := NewInMemoryQueue()
+	qm := &QueueManager{
+		cache:   store,
+		log:     testLogger(),
+		metrics: testMetrics(),
+		now:     func() time.Time { return *now },
+	}
+	return qm, store
+}
+
+func TestQueueManager_ScheduledMatch(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("not dequeued before scheduled time", func(t *testing.T) {
+		now := start
+		qm, store := newScheduledTestQueue(&now)
+
+		scheduledAt := start.Add(time.Minute)
+		match := testMatch(domain.PriorityMedium)
+		match.ScheduledAt = &scheduledAt
+
+		require.NoError(t, qm.Enqueue(ctx, match))
+
+		size, err := store.LLen(ctx, "queue:medium")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), size, "scheduled match must not be in the priority queue")
+
+		now = start.Add(59 * time.Second)
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("dequeued at and after scheduled time", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		scheduledAt := start.Add(time.Minute)
+		match := testMatch(domain.PriorityHigh)
+		match.ScheduledAt = &scheduledAt
+		require.NoError(t, qm.Enqueue(ctx, match))
+
+		now = scheduledAt
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, match.ID, got.ID)
+		require.NotNil(t, got.ScheduledAt)
+		assert.False(t, now.Before(*got.ScheduledAt))
+
+		// Matches are promoted only once
+		got, err = qm.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("due matches are dequeued in schedule order", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		later := start.Add(2 * time.Minute)
+		earlier := start.Add(time.Minute)
+		second := testMatch(domain.PriorityLow)
+		second.ScheduledAt = &later
+		first := testMatch(domain.PriorityLow)
+		first.ScheduledAt = &earlier
+		require.NoError(t, qm.Enqueue(ctx, second))
+		require.NoError(t, qm.Enqueue(ctx, first))
+
+		now = start.Add(time.Hour)
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, first.ID, got.ID)
+
+		got, err = qm.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, second.ID, got.ID)
+	})
+
+	t.Run("failed promotion keeps the match scheduled", func(t *testing.T) {
+		now := start
+		qm, store := newScheduledTestQueue(&now)
+
+		scheduledAt := start.Add(time.Minute)
+		match := testMatch(domain.PriorityMedium)
+		match.ScheduledAt = &scheduledAt
+		require.NoError(t, qm.Enqueue(ctx, match))
+
+		now = scheduledAt
+		qm.cache = &failingMoveQueue{InMemoryQueue: store}
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		// The match is still scheduled and is promoted once Redis recovers
+		qm.cache = store
+		got, err = qm.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, match.ID, got.ID)
+	})
+
+	t.Run("past scheduled time goes straight to queue", func(t *testing.T) {
+		now := start
+		qm, store := newScheduledTestQueue(&now)
+
+		past := start.Add(-time.Minute)
+		match := testMatch(domain.PriorityMedium)
+		match.ScheduledAt = &past
+		require.NoError(t, qm.Enqueue(ctx, match))
+
+		size, err := store.LLen(ctx, "queue:medium")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), size)
+	})
+}
+
+func TestQueueManager_Reprioritize(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("bumped match is dequeued first", func(t *testing.T) {
+		now := start
+		qm, store := newScheduledTestQueue(&now)
+
+		high := testMatch(domain.PriorityHigh)
+		older := testMatch(domain.PriorityLow)
+		bumped := testMatch(domain.PriorityLow)
+		require.NoError(t, qm.Enqueue(ctx, high))
+		require.NoError(t, qm.Enqueue(ctx, older))
+		require.NoError(t, qm.Enqueue(ctx, bumped))
+
+		require.NoError(t, qm.Reprioritize(ctx, bumped.ID, domain.PriorityHigh))
+
+		size, err := store.LLen(ctx, "queue:low")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), size)
+
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, bumped.ID, got.ID)
+		assert.Equal(t, domain.PriorityHigh, got.Priority)
+
+		got, err = qm.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, high.ID, got.ID)
+
+		got, err = qm.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, older.ID, got.ID)
+	})
+
+	t.Run("scheduled match keeps its time", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		scheduledAt := start.Add(time.Minute)
+		match := testMatch(domain.PriorityLow)
+		match.ScheduledAt = &scheduledAt
+		require.NoError(t, qm.Enqueue(ctx, match))
+
+		require.NoError(t, qm.Reprioritize(ctx, match.ID, domain.PriorityHigh))
+
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, got, "scheduled match must still wait")
+
+		now = scheduledAt
+		got, err = qm.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, match.ID, got.ID)
+		assert.Equal(t, domain.PriorityHigh, got.Priority)
+	})
+
+	t.Run("unknown match", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		require.NoError(t, qm.Enqueue(ctx, testMatch(domain.PriorityLow)))
+		err := qm.Reprioritize(ctx, uuid.New(), domain.PriorityHigh)
+		assert.ErrorIs(t, err, ErrMatchNotQueued)
+	})
+}
+
+func TestQueueManager_GetPeekByPriority(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("returns matches in dequeue order without removing them", func(t *testing.T) {
+		now := start
+		qm, store := newScheduledTestQueue(&now)
+
+		first := testMatch(domain.PriorityHigh)
+		second := testMatch(domain.PriorityHigh)
+		third := testMatch(domain.PriorityHigh)
+		for _, m := range []*domain.Match{first, second, third} {
+			require.NoError(t, qm.Enqueue(ctx, m))
+		}
+		require.NoError(t, qm.Enqueue(ctx, testMatch(domain.PriorityLow)))
+
+		peeked, err := qm.GetPeekByPriority(ctx, domain.PriorityHigh, 2)
+		require.NoError(t, err)
+		require.Len(t, peeked, 2)
+		assert.Equal(t, first.ID, peeked[0].ID)
+		assert.Equal(t, second.ID, peeked[1].ID)
+
+		size, err := store.LLen(ctx, "queue:high")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), size)
+
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, got.ID)
+	})
+
+	t.Run("caps n", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		for i := 0; i < maxPeekSize+20; i++ {
+			require.NoError(t, qm.Enqueue(ctx, testMatch(domain.PriorityMedium)))
+		}
+
+		peeked, err := qm.GetPeekByPriority(ctx, domain.PriorityMedium, 500)
+		require.NoError(t, err)
+		assert.Len(t, peeked, maxPeekSize)
+	})
+
+	t.Run("empty queue", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		peeked, err := qm.GetPeekByPriority(ctx, domain.PriorityLow, 10)
+		require.NoError(t, err)
+		assert.NotNil(t, peeked)
+		assert.Empty(t, peeked)
+	})
+}
+
+func TestQueueManager_QueuedMatchIDs(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	qm, store := newScheduledTestQueue(&now)
+
+	high := testMatch(domain.PriorityHigh)
+	low := testMatch(domain.PriorityLow)
+	scheduled := testMatch(domain.PriorityMedium)
+	later := now.Add(time.Hour)
+	scheduled.ScheduledAt = &later
+	for _, m := range []*domain.Match{high, low, scheduled} {
+		require.NoError(t, qm.Enqueue(ctx, m))
+	}
+	require.NoError(t, store.LPush(ctx, "queue:medium", "not json"))
+
+	ids, err := qm.QueuedMatchIDs(ctx)
+	require.NoError(t, err)
+	assert.Len(t, ids, 3)
+	for _, m := range []*domain.Match{high, low, scheduled} {
+		assert.Contains(t, ids, m.ID)
+	}
+}
+
+func TestQueueManager_QueueWait(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("enqueue stamps enqueued_at", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		match := testMatch(domain.PriorityHigh)
+		require.NoError(t, qm.Enqueue(ctx, match))
+		assert.Nil(t, match.EnqueuedAt, "caller's match must not be modified")
+
+		now = start.Add(3 * time.Second)
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		require.NotNil(t, got.EnqueuedAt)
+		assert.True(t, start.Equal(*got.EnqueuedAt))
+		assert.Equal(t, 3*time.Second, got.QueueWait(now))
+	})
+
+	t.Run("legacy payload falls back to created_at", func(t *testing.T) {
+		now := start
+		qm, store := newScheduledTestQueue(&now)
+
+		// Payload written before enqueued_at was introduced
+		legacy := testMatch(domain.PriorityLow)
+		legacy.CreatedAt = start.Add(-time.Minute)
+		data, err := json.Marshal(legacy)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "enqueued_at")
+		require.NoError(t, store.LPush(ctx, "queue:low", data))
+
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Nil(t, got.EnqueuedAt)
+		assert.Equal(t, time.Minute, got.QueueWait(now))
+	})
+
+	t.Run("scheduled match waits from scheduled time", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		scheduledAt := start.Add(time.Minute)
+		match := testMatch(domain.PriorityMedium)
+		match.ScheduledAt = &scheduledAt
+		require.NoError(t, qm.Enqueue(ctx, match))
+
+		now = scheduledAt.Add(5 * time.Second)
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, 5*time.Second, got.QueueWait(now))
+	})
+
+	t.Run("stats report wait of next match per priority", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		require.NoError(t, qm.Enqueue(ctx, testMatch(domain.PriorityHigh)))
+		now = start.Add(10 * time.Second)
+		require.NoError(t, qm.Enqueue(ctx, testMatch(domain.PriorityHigh)))
+		require.NoError(t, qm.Enqueue(ctx, testMatch(domain.PriorityLow)))
+
+		now = start.Add(30 * time.Second)
+		stats, err := qm.GetStats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), stats.High)
+		assert.Equal(t, int64(1), stats.Low)
+		assert.Equal(t, int64(3), stats.Total)
+		assert.Equal(t, 30.0, stats.OldestWait.High)
+		assert.Equal(t, 0.0, stats.OldestWait.Medium)
+		assert.Equal(t, 20.0, stats.OldestWait.Low)
+	})
+}
+
+func TestQueueManager_DequeueBatch(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("takes up to limit in priority order", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		low := testMatch(domain.PriorityLow)
+		high := testMatch(domain.PriorityHigh)
+		medium := testMatch(domain.PriorityMedium)
+		for _, m := range []*domain.Match{low, high, medium, testMatch(domain.PriorityLow)} {
+			require.NoError(t, qm.Enqueue(ctx, m))
+		}
+
+		batch, err := qm.DequeueBatch(ctx, 3)
+		require.NoError(t, err)
+		require.Len(t, batch, 3)
+		assert.Equal(t, high.ID, batch[0].ID)
+		assert.Equal(t, medium.ID, batch[1].ID)
+		assert.Equal(t, low.ID, batch[2].ID)
+
+		size, err := qm.GetTotalQueueSize(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), size)
+	})
+
+	t.Run("returns what is available", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		require.NoError(t, qm.Enqueue(ctx, testMatch(domain.PriorityMedium)))
+
+		batch, err := qm.DequeueBatch(ctx, 4)
+		require.NoError(t, err)
+		assert.Len(t, batch, 1)
+	})
+
+	t.Run("empty queue", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		batch, err := qm.DequeueBatch(ctx, 4)
+		require.NoError(t, err)
+		assert.Empty(t, batch)
+	})
+}
+
 func TestInMemoryQueue_Operations(t *testing.T) {
 	q := NewInMemoryQueue()
 	ctx := context.Background()
@@ -347,3 +862,122 @@ func BenchmarkInMemoryQueue_BRPop(b *testing.B) {
 		_, _ = q.BRPop(ctx, time.Second, "test")
 	}
 }
+
+func TestEffectivePriority(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	interval := 10 * time.Minute
+
+	tests := []struct {
+		name     string
+		priority domain.MatchPriority
+		practice bool
+		wait     time.Duration
+		aging    time.Duration
+		expected domain.MatchPriority
+	}{
+		{"fresh low", domain.PriorityLow, false, time.Minute, interval, domain.PriorityLow},
+		{"low after one interval", domain.PriorityLow, false, interval, interval, domain.PriorityMedium},
+		{"low after two intervals", domain.PriorityLow, false, 2 * interval, interval, domain.PriorityHigh},
+		{"capped at high", domain.PriorityMedium, false, 5 * interval, interval, domain.PriorityHigh},
+		{"aging disabled", domain.PriorityLow, false, time.Hour, 0, domain.PriorityLow},
+		{"unknown priority", domain.MatchPriority("urgent"), false, time.Hour, interval, domain.MatchPriority("urgent")},
+		{"practice never ages", domain.PriorityLow, true, 5 * interval, interval, domain.PriorityLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := testMatch(tt.priority)
+			match.IsPractice = tt.practice
+			enqueuedAt := start
+			match.EnqueuedAt = &enqueuedAt
+			assert.Equal(t, tt.expected, effectivePriority(match, start.Add(tt.wait), tt.aging))
+		})
+	}
+}
+
+func TestQueueManager_PriorityAging(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dequeueIDs := func(t *testing.T, qm *QueueManager, n int) []uuid.UUID {
+		ids := make([]uuid.UUID, 0, n)
+		for i := 0; i < n; i++ {
+			got, err := qm.Dequeue(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			ids = append(ids, got.ID)
+		}
+		return ids
+	}
+
+	t.Run("old low match is not starved by new high matches", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+		qm.SetPriorityAging(10 * time.Minute)
+
+		old := testMatch(domain.PriorityLow)
+		require.NoError(t, qm.Enqueue(ctx, old))
+
+		now = start.Add(25 * time.Minute)
+		flood := []*domain.Match{testMatch(domain.PriorityHigh), testMatch(domain.PriorityHigh)}
+		for _, match := range flood {
+			require.NoError(t, qm.Enqueue(ctx, match))
+		}
+
+		got, err := qm.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, old.ID, got.ID)
+		// Aging does not change the stored priority
+		assert.Equal(t, domain.PriorityLow, got.Priority)
+	})
+
+	t.Run("one interval lifts low to medium", func(t *testing.T) {
+		now := start
+		qm, store := newScheduledTestQueue(&now)
+		qm.SetPriorityAging(10 * time.Minute)
+
+		low := testMatch(domain.PriorityLow)
+		require.NoError(t, qm.Enqueue(ctx, low))
+		now = start.Add(5 * time.Minute)
+		medium := testMatch(domain.PriorityMedium)
+		high := testMatch(domain.PriorityHigh)
+		require.NoError(t, qm.Enqueue(ctx, medium))
+		require.NoError(t, qm.Enqueue(ctx, high))
+
+		now = start.Add(12 * time.Minute)
+		assert.Equal(t, []uuid.UUID{high.ID, low.ID, medium.ID}, dequeueIDs(t, qm, 3))
+
+		size, err := store.LLen(ctx, "queue:low")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), size)
+	})
+
+	t.Run("aged matches keep their relative order", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+		qm.SetPriorityAging(10 * time.Minute)
+
+		first := testMatch(domain.PriorityLow)
+		require.NoError(t, qm.Enqueue(ctx, first))
+		now = start.Add(time.Minute)
+		second := testMatch(domain.PriorityLow)
+		require.NoError(t, qm.Enqueue(ctx, second))
+
+		now = start.Add(time.Hour)
+		assert.Equal(t, []uuid.UUID{first.ID, second.ID}, dequeueIDs(t, qm, 2))
+	})
+
+	t.Run("disabled aging keeps strict priority order", func(t *testing.T) {
+		now := start
+		qm, _ := newScheduledTestQueue(&now)
+
+		low := testMatch(domain.PriorityLow)
+		require.NoError(t, qm.Enqueue(ctx, low))
+		now = start.Add(time.Hour)
+		high := testMatch(domain.PriorityHigh)
+		require.NoError(t, qm.Enqueue(ctx, high))
+
+		assert.Equal(t, []uuid.UUID{high.ID, low.ID}, dequeueIDs(t, qm, 2))
+	})
+}
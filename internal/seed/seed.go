@@ -0,0 +1,429 @@
+// Package seed наполняет БД демонстрационными данными для локальной разработки:
+// пользователи, команды, встроенные игры, турниры и боты из примеров стратегий.
+// Все записи создаются через репозитории, повторный запуск ничего не дублирует
+package seed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/domain/auth"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// AdminUsername имя администратора демо-данных
+	AdminUsername = "demo_admin"
+	// DefaultPassword пароль демо-пользователей по умолчанию
+	DefaultPassword = "Demo1234!"
+
+	// MaxTeams ограничение на число команд в демо-турнирах
+	MaxTeams = 32
+
+	userPrefix      = "demo_user_"
+	initialRating   = 1500
+	programFileMode = 0755
+	programsDirMode = 0755
+)
+
+// namespace пространство имён для детерминированных ID демо-записей:
+// по ним повторный запуск находит уже созданные турниры и команды
+var namespace = uuid.MustParse("6f1d3c2e-7a4b-4e0f-9c51-2d8e5b7a9f10")
+
+// BuiltinGame - встроенная игра (создаётся миграциями, при отсутствии - сидером)
+type BuiltinGame struct {
+	Name        string
+	DisplayName string
+}
+
+// BuiltinGames встроенные игры в порядке подключения к демо-турнирам
+var BuiltinGames = []BuiltinGame{
+	{Name: "dilemma", DisplayName: "Дилемма заключённого"},
+	{Name: "tug_of_war", DisplayName: "Перетягивание каната"},
+}
+
+// demoTournament - описание демо-турнира
+type demoTournament struct {
+	key    string
+	code   string
+	name   string
+	status domain.TournamentStatus
+}
+
+var demoTournaments = []demoTournament{
+	{key: "pending", code: "DEMOPEND", name: "Demo: регистрация открыта", status: domain.TournamentPending},
+	{key: "active", code: "DEMOACTV", name: "Demo: идёт турнир", status: domain.TournamentActive},
+}
+
+// Options параметры наполнения
+type Options struct {
+	Teams       int    // Число команд (и обычных пользователей) в каждом турнире
+	Games       int    // Число встроенных игр в турнирах
+	Password    string // Пароль создаваемых пользователей
+	ProgramsDir string // Каталог файлов программ (PROGRAMS_PATH)
+	Enqueue     bool   // Поставить раунд матчей активного турнира в очередь
+}
+
+// Validate проверяет параметры наполнения
+func (o *Options) Validate() error {
+	if o.Teams < 2 || o.Teams > MaxTeams {
+		return errors.ErrInvalidInput.WithMessage(fmt.Sprintf("teams must be between 2 and %d", MaxTeams))
+	}
+	if o.Games < 1 || o.Games > len(BuiltinGames) {
+		return errors.ErrInvalidInput.WithMessage(fmt.Sprintf("games must be between 1 and %d", len(BuiltinGames)))
+	}
+	if o.ProgramsDir == "" {
+		return errors.ErrInvalidInput.WithMessage("programs directory is required")
+	}
+	if err := domain.ValidatePassword(o.Password); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MatchRunner запускает раунд матчей игры турнира
+type MatchRunner interface {
+	RunGameMatches(ctx context.Context, tournamentID uuid.UUID, gameType string, overrideWindow bool) (int, error)
+}
+
+// Credential - учётные данные демо-пользователя
+type Credential struct {
+	Username string
+	Password string // Пусто, если пользователь уже существовал (пароль не менялся)
+	Role     domain.Role
+}
+
+// Result итог наполнения
+type Result struct {
+	Credentials     []Credential
+	Tournaments     []*domain.Tournament
+	ProgramsCreated int
+	MatchesEnqueued int
+}
+
+// Seeder создаёт демо-данные через репозитории
+type Seeder struct {
+	userRepo       *db.UserRepository
+	teamRepo       *db.TeamRepository
+	gameRepo       *db.GameRepository
+	tournamentRepo *db.TournamentRepository
+	programRepo    *db.ProgramRepository
+	matchRepo      *db.MatchRepository
+	matchRunner    MatchRunner
+	log            *logger.Logger
+}
+
+// NewSeeder создаёт сидер поверх подключения к БД
+func NewSeeder(database *db.DB, log *logger.Logger) *Seeder {
+	return &Seeder{
+		userRepo:       db.NewUserRepository(database),
+		teamRepo:       db.NewTeamRepository(database),
+		gameRepo:       db.NewGameRepository(database),
+		tournamentRepo: db.NewTournamentRepository(database),
+		programRepo:    db.NewProgramRepository(database),
+		matchRepo:      db.NewMatchRepository(database),
+		log:            log,
+	}
+}
+
+// SetMatchRunner подключает запуск матчей. Без него Options.Enqueue не поддерживается
+func (s *Seeder) SetMatchRunner(runner MatchRunner) {
+	s.matchRunner = runner
+}
+
+// Run наполняет БД демо-данными. Уже существующие записи не изменяются
+func (s *Seeder) Run(ctx context.Context, opts Options) (*Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Enqueue && s.matchRunner == nil {
+		return nil, errors.ErrInternal.WithMessage("match runner is not configured")
+	}
+	if err := os.MkdirAll(opts.ProgramsDir, programsDirMode); err != nil {
+		return nil, fmt.Errorf("failed to create programs directory: %w", err)
+	}
+
+	result := &Result{}
+
+	admin, err := s.ensureUser(ctx, AdminUsername, domain.RoleAdmin, opts.Password, result)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*domain.User, opts.Teams)
+	for i := range users {
+		users[i], err = s.ensureUser(ctx, fmt.Sprintf("%s%d", userPrefix, i+1), domain.RoleUser, opts.Password, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	games := make([]*domain.Game, opts.Games)
+	for i := range games {
+		games[i], err = s.ensureGame(ctx, BuiltinGames[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, demo := range demoTournaments {
+		tournament, err := s.ensureTournament(ctx, demo, admin, games)
+		if err != nil {
+			return nil, err
+		}
+		result.Tournaments = append(result.Tournaments, tournament)
+
+		for i, user := range users {
+			team, err := s.ensureTeam(ctx, tournament, i, user)
+			if err != nil {
+				return nil, err
+			}
+			for _, game := range games {
+				created, err := s.ensureProgram(ctx, tournament, team, user, game, i, opts.ProgramsDir)
+				if err != nil {
+					return nil, err
+				}
+				if created {
+					result.ProgramsCreated++
+				}
+			}
+		}
+
+		if opts.Enqueue && tournament.Status == domain.TournamentActive {
+			for _, game := range games {
+				enqueued, err := s.enqueueRound(ctx, tournament, game)
+				if err != nil {
+					return nil, err
+				}
+				result.MatchesEnqueued += enqueued
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ensureUser находит пользователя по имени или создаёт его
+func (s *Seeder) ensureUser(ctx context.Context, username string, role domain.Role, password string, result *Result) (*domain.User, error) {
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err == nil {
+		result.Credentials = append(result.Credentials, Credential{Username: username, Role: user.Role})
+		return user, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), auth.BcryptCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user = &domain.User{
+		ID:           uuid.New(),
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: string(hash),
+		Role:         role,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Demo user created", zap.String("username", username))
+	result.Credentials = append(result.Credentials, Credential{Username: username, Password: password, Role: role})
+	return user, nil
+}
+
+// ensureGame находит встроенную игру или создаёт её, если миграции её не добавили
+func (s *Seeder) ensureGame(ctx context.Context, builtin BuiltinGame) (*domain.Game, error) {
+	game, err := s.gameRepo.GetByName(ctx, builtin.Name)
+	if err == nil {
+		return game, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	game = &domain.Game{
+		ID:             uuid.New(),
+		Name:           builtin.Name,
+		DisplayName:    builtin.DisplayName,
+		Rules:          "# " + builtin.DisplayName,
+		SandboxProfile: domain.SandboxStrict,
+	}
+	if err := s.gameRepo.Create(ctx, game); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Built-in game created", zap.String("game", builtin.Name))
+	return game, nil
+}
+
+// ensureTournament находит демо-турнир по детерминированному ID или создаёт его с играми
+func (s *Seeder) ensureTournament(ctx context.Context, demo demoTournament, admin *domain.User, games []*domain.Game) (*domain.Tournament, error) {
+	id := uuid.NewSHA1(namespace, []byte("tournament/"+demo.key))
+
+	tournament, err := s.tournamentRepo.GetByID(ctx, id)
+	if err == nil {
+		// Игры, добавленные повторным запуском с большим -games
+		for _, game := range games {
+			if err := s.gameRepo.AddToTournament(ctx, tournament.ID, game.ID); err != nil {
+				return nil, err
+			}
+		}
+		return tournament, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	gameIDs := make([]uuid.UUID, len(games))
+	for i, game := range games {
+		gameIDs[i] = game.ID
+	}
+
+	tournament = &domain.Tournament{
+		ID:          id,
+		Name:        demo.name,
+		Code:        demo.code,
+		Description: "Демонстрационный турнир для локальной разработки",
+		GameType:    games[0].Name,
+		Status:      demo.status,
+		MaxTeamSize: 1,
+		Visibility:  domain.VisibilityPublic,
+		CreatorID:   &admin.ID,
+		Metadata:    map[string]interface{}{},
+	}
+	if err := s.tournamentRepo.CreateWithGames(ctx, tournament, gameIDs); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Demo tournament created",
+		zap.String("tournament_id", tournament.ID.String()),
+		zap.String("status", string(tournament.Status)),
+	)
+	return tournament, nil
+}
+
+// ensureTeam находит команду пользователя в турнире или создаёт её с пользователем-лидером
+func (s *Seeder) ensureTeam(ctx context.Context, tournament *domain.Tournament, index int, leader *domain.User) (*domain.Team, error) {
+	id := uuid.NewSHA1(namespace, []byte(fmt.Sprintf("team/%s/%d", tournament.ID, index)))
+
+	team, err := s.teamRepo.GetByID(ctx, id)
+	if err == nil {
+		return team, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	code, err := s.teamRepo.GenerateUniqueCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	team = &domain.Team{
+		ID:           id,
+		TournamentID: tournament.ID,
+		Name:         fmt.Sprintf("Demo Team %d", index+1),
+		Code:         code,
+		LeaderID:     leader.ID,
+	}
+	if err := s.teamRepo.Create(ctx, team); err != nil {
+		return nil, err
+	}
+
+	member := &domain.TeamMember{
+		ID:     uuid.New(),
+		TeamID: team.ID,
+		UserID: leader.ID,
+	}
+	if err := s.teamRepo.AddMember(ctx, member); err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+// ensureProgram загружает команде бот из примеров стратегий игры, если у неё ещё нет программы.
+// Возвращает true, если программа создана
+func (s *Seeder) ensureProgram(ctx context.Context, tournament *domain.Tournament, team *domain.Team, user *domain.User, game *domain.Game, index int, programsDir string) (bool, error) {
+	version, err := s.programRepo.GetLatestVersion(ctx, team.ID, game.ID)
+	if err != nil {
+		return false, err
+	}
+	if version > 0 {
+		return false, nil
+	}
+
+	strategies, err := Strategies(game.Name)
+	if err != nil {
+		return false, err
+	}
+	strategy := strategies[index%len(strategies)]
+
+	programID := uuid.New()
+	fileName := fmt.Sprintf("%s_%s_%s_v%d%s", team.ID.String()[:8], game.ID.String()[:8], programID.String()[:8], 1, ".py")
+	filePath := filepath.Join(programsDir, fileName)
+	if err := os.WriteFile(filePath, strategy.Source, programFileMode); err != nil {
+		return false, fmt.Errorf("failed to write program file: %w", err)
+	}
+
+	sum := sha256.Sum256(strategy.Source)
+	contentHash := hex.EncodeToString(sum[:])
+
+	program := &domain.Program{
+		ID:           programID,
+		UserID:       user.ID,
+		TeamID:       &team.ID,
+		TournamentID: &tournament.ID,
+		GameID:       &game.ID,
+		Name:         strategy.Name,
+		GameType:     game.Name,
+		CodePath:     filePath,
+		FilePath:     &filePath,
+		Language:     "python",
+		Version:      1,
+		ContentHash:  &contentHash,
+	}
+	if err := s.programRepo.Create(ctx, program); err != nil {
+		os.Remove(filePath)
+		return false, err
+	}
+
+	participant := &domain.TournamentParticipant{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		ProgramID:    program.ID,
+		Rating:       initialRating,
+	}
+	if err := s.tournamentRepo.AddParticipant(ctx, participant); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// enqueueRound ставит в очередь первый раунд игры. Если у игры уже есть матчи, ничего не делает
+func (s *Seeder) enqueueRound(ctx context.Context, tournament *domain.Tournament, game *domain.Game) (int, error) {
+	nextRound, err := s.matchRepo.GetNextRoundNumberByGame(ctx, tournament.ID, game.Name)
+	if err != nil {
+		return 0, err
+	}
+	if nextRound > 1 {
+		return 0, nil
+	}
+
+	return s.matchRunner.RunGameMatches(ctx, tournament.ID, game.Name, true)
+}
@@ -0,0 +1,51 @@
+package seed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategies(t *testing.T) {
+	for _, game := range BuiltinGames {
+		t.Run(game.Name, func(t *testing.T) {
+			strategies, err := Strategies(game.Name)
+			require.NoError(t, err)
+			require.NotEmpty(t, strategies)
+
+			for _, s := range strategies {
+				assert.True(t, bytes.HasPrefix(s.Source, []byte("#!/usr/bin/python3\n")), s.Name)
+			}
+		})
+	}
+
+	_, err := Strategies("unknown")
+	assert.Error(t, err)
+}
+
+func TestOptions_Validate(t *testing.T) {
+	valid := Options{Teams: 4, Games: len(BuiltinGames), Password: DefaultPassword, ProgramsDir: "/tmp/programs"}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(o *Options)
+	}{
+		{"too few teams", func(o *Options) { o.Teams = 1 }},
+		{"too many teams", func(o *Options) { o.Teams = MaxTeams + 1 }},
+		{"no games", func(o *Options) { o.Games = 0 }},
+		{"more games than built in", func(o *Options) { o.Games = len(BuiltinGames) + 1 }},
+		{"weak password", func(o *Options) { o.Password = "demo" }},
+		{"no programs dir", func(o *Options) { o.ProgramsDir = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.modify(&opts)
+			assert.Error(t, opts.Validate())
+		})
+	}
+}
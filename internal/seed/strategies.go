@@ -0,0 +1,51 @@
+package seed
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// strategiesFS примеры ботов встроенных игр (те же стратегии, что и в нагрузочных тестах)
+//
+//go:embed strategies
+var strategiesFS embed.FS
+
+// Strategy - пример бота для игры
+type Strategy struct {
+	Name   string // Имя файла без расширения, например "tit_for_tat"
+	Source []byte
+}
+
+// Strategies возвращает примеры ботов игры, отсортированные по имени
+func Strategies(game string) ([]Strategy, error) {
+	dir := path.Join("strategies", game)
+	entries, err := fs.ReadDir(strategiesFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no sample strategies for game %q", game)
+	}
+
+	strategies := make([]Strategy, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".py" {
+			continue
+		}
+		source, err := fs.ReadFile(strategiesFS, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read strategy %s: %w", entry.Name(), err)
+		}
+		strategies = append(strategies, Strategy{
+			Name:   strings.TrimSuffix(entry.Name(), ".py"),
+			Source: source,
+		})
+	}
+	if len(strategies) == 0 {
+		return nil, fmt.Errorf("no sample strategies for game %q", game)
+	}
+
+	sort.Slice(strategies, func(i, j int) bool { return strategies[i].Name < strategies[j].Name })
+	return strategies, nil
+}
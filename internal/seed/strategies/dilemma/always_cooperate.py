@@ -0,0 +1,5 @@
+#!/usr/bin/python3
+n = int(input())
+for i in range(n):
+    print("COOPERATE", flush=True)
+    input()
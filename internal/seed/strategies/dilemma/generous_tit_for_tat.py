@@ -0,0 +1,11 @@
+#!/usr/bin/python3
+import random
+next_choice = "COOPERATE"
+n = int(input())
+for i in range(n):
+    print(next_choice, flush=True)
+    opp = input().strip()
+    if opp == "DEFECT" and random.random() < 0.1:
+        next_choice = "COOPERATE"
+    else:
+        next_choice = opp
@@ -0,0 +1,11 @@
+#!/usr/bin/python3
+n = int(input())
+opponent_defected = False
+for i in range(n):
+    if opponent_defected:
+        print("DEFECT", flush=True)
+    else:
+        print("COOPERATE", flush=True)
+    opp = input().strip()
+    if opp == "DEFECT":
+        opponent_defected = True
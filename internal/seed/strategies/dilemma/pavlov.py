@@ -0,0 +1,12 @@
+#!/usr/bin/python3
+n = int(input())
+my_last = "COOPERATE"
+for i in range(n):
+    print(my_last, flush=True)
+    opp = input().strip()
+    # Win-stay: if we both cooperated or both defected, repeat
+    # Lose-shift: if mismatch, switch
+    if my_last == opp:
+        my_last = "COOPERATE"
+    else:
+        my_last = "DEFECT"
@@ -0,0 +1,6 @@
+#!/usr/bin/python3
+import random
+n = int(input())
+for i in range(n):
+    print(random.choice(["COOPERATE", "DEFECT"]), flush=True)
+    input()
@@ -0,0 +1,18 @@
+#!/usr/bin/python3
+import sys
+n = int(input())
+remaining = 100
+weights = [1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5]
+for i in range(n):
+    w = weights[i] if i < len(weights) else 5
+    total_w = sum(weights[j] if j < len(weights) else 5 for j in range(i, n))
+    spend = min(remaining, int(remaining * w / max(0.01, total_w)))
+    spend = max(0, min(remaining, spend))
+    remaining -= spend
+    print(spend, flush=True)
+    try:
+        line = input()
+        if line.strip() == '' or int(line) < 0:
+            break
+    except (ValueError, EOFError):
+        break
@@ -0,0 +1,15 @@
+#!/usr/bin/python3
+import sys
+n = int(input())
+remaining = 100
+for i in range(n):
+    rounds_left = n - i
+    spend = min(remaining, remaining // rounds_left) if rounds_left > 0 else remaining
+    remaining -= spend
+    print(max(0, spend), flush=True)
+    try:
+        line = input()
+        if line.strip() == '' or int(line) < 0:
+            break
+    except (ValueError, EOFError):
+        break
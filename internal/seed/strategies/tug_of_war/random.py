@@ -0,0 +1,20 @@
+#!/usr/bin/python3
+import random
+import sys
+n = int(input())
+remaining = 100
+for i in range(n):
+    rounds_left = n - i
+    if rounds_left <= 0 or remaining <= 0:
+        print(0, flush=True)
+    else:
+        avg = remaining // rounds_left
+        spend = min(remaining, max(0, random.randint(max(0, avg - 5), avg + 5)))
+        remaining -= spend
+        print(spend, flush=True)
+    try:
+        line = input()
+        if line.strip() == '' or int(line) < 0:
+            break
+    except (ValueError, EOFError):
+        break
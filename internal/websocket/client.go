@@ -26,31 +26,52 @@ const (
 
 // Client представляет WebSocket клиента
 type Client struct {
-	hub          *Hub
-	conn         *websocket.Conn
-	send         chan []byte
-	tournamentID uuid.UUID
-	userID       uuid.UUID
-	log          *logger.Logger
+	hub    *Hub
+	conn   *websocket.Conn
+	send   chan []byte
+	userID uuid.UUID
+	log    *logger.Logger
+
+	// Турниры, на которые подписан клиент (изменяется только hub)
+	subscriptions map[uuid.UUID]bool
+
+	// Проверка доступа к турниру при подписке (nil - без проверки)
+	authorize SubscribeAuthorizer
 }
 
-// NewClient создаёт нового WebSocket клиента
-func NewClient(hub *Hub, conn *websocket.Conn, tournamentID, userID uuid.UUID, log *logger.Logger) *Client {
+// SubscribeAuthorizer проверяет, может ли клиент подписаться на турнир.
+// Текст ошибки отправляется клиенту
+type SubscribeAuthorizer func(tournamentID uuid.UUID) error
+
+// NewClient создаёт нового WebSocket клиента без подписок
+func NewClient(hub *Hub, conn *websocket.Conn, userID uuid.UUID, log *logger.Logger) *Client {
 	return &Client{
-		hub:          hub,
-		conn:         conn,
-		send:         make(chan []byte, 256),
-		tournamentID: tournamentID,
-		userID:       userID,
-		log:          log,
+		hub:           hub,
+		conn:          conn,
+		send:          make(chan []byte, 256),
+		userID:        userID,
+		log:           log,
+		subscriptions: make(map[uuid.UUID]bool),
 	}
 }
 
+// SetAuthorizer включает проверку доступа к турниру для сообщений subscribe.
+// Вызывается до ReadPump
+func (c *Client) SetAuthorizer(authorize SubscribeAuthorizer) {
+	c.authorize = authorize
+}
+
 // Register регистрирует клиента в hub
 func (c *Client) Register() {
 	c.hub.register <- c
 }
 
+// Subscribe подписывает клиента на сообщения турнира
+// Вызывается после Register
+func (c *Client) Subscribe(tournamentID uuid.UUID) {
+	c.hub.subscriptions <- subscription{client: c, tournamentID: tournamentID, subscribe: true}
+}
+
 // ReadPump читает сообщения от клиента
 func (c *Client) ReadPump() {
 	defer func() {
@@ -69,7 +90,6 @@ func (c *Client) ReadPump() {
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				c.log.LogError("WebSocket read error", err,
-					zap.String("tournament_id", c.tournamentID.String()),
 					zap.String("user_id", c.userID.String()),
 				)
 			}
@@ -142,6 +162,30 @@ func (c *Client) handleMessage(data []byte) {
 		// Отправляем pong
 		c.sendPong()
 
+	case MessageTypeSubscribe, MessageTypeUnsubscribe:
+		if msg.TournamentID == uuid.Nil {
+			c.log.Info("Subscription message without tournament_id",
+				zap.String("user_id", c.userID.String()),
+			)
+			return
+		}
+		if msg.Type == MessageTypeSubscribe && c.authorize != nil {
+			if err := c.authorize(msg.TournamentID); err != nil {
+				c.sendMessage(&Message{
+					TournamentID: msg.TournamentID,
+					Type:         MessageTypeError,
+					Payload:      map[string]string{"error": err.Error()},
+				})
+				return
+			}
+		}
+		c.hub.subscriptions <- subscription{
+			client:       c,
+			tournamentID: msg.TournamentID,
+			subscribe:    msg.Type == MessageTypeSubscribe,
+			notify:       true,
+		}
+
 	default:
 		c.log.Info("Unknown message type",
 			zap.String("type", string(msg.Type)),
@@ -152,15 +196,17 @@ func (c *Client) handleMessage(data []byte) {
 
 // sendPong отправляет pong сообщение клиенту
 func (c *Client) sendPong() {
-	message := &Message{
-		TournamentID: c.tournamentID,
-		Type:         MessageTypePong,
-		Payload:      map[string]string{"status": "ok"},
-	}
+	c.sendMessage(&Message{
+		Type:    MessageTypePong,
+		Payload: map[string]string{"status": "ok"},
+	})
+}
 
+// sendMessage отправляет сообщение клиенту из ReadPump
+func (c *Client) sendMessage(message *Message) {
 	data, err := json.Marshal(message)
 	if err != nil {
-		c.log.LogError("Failed to marshal pong", err)
+		c.log.LogError("Failed to marshal message", err)
 		return
 	}
 
@@ -10,9 +10,17 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxSubscriptions максимальное число турниров, на которые подписан один клиент
+const maxSubscriptions = 20
+
 // Hub управляет WebSocket подключениями
+// Все изменения подписок выполняются в горутине Run, поэтому порядок
+// Register -> Subscribe -> Unregister одного клиента сохраняется
 type Hub struct {
-	// Клиенты по турнирам
+	// Подключённые клиенты
+	clients map[*Client]bool
+
+	// Подписчики по турнирам
 	tournaments map[uuid.UUID]map[*Client]bool
 
 	// Канал для регистрации клиентов
@@ -21,6 +29,9 @@ type Hub struct {
 	// Канал для отмены регистрации клиентов
 	unregister chan *Client
 
+	// Канал для подписки и отписки от турниров
+	subscriptions chan subscription
+
 	// Канал для broadcast сообщений
 	broadcast chan *Message
 
@@ -37,6 +48,15 @@ type Message struct {
 	Payload      interface{} `json:"payload"`
 }
 
+// subscription запрос на подписку клиента на турнир или отписку от него
+type subscription struct {
+	client       *Client
+	tournamentID uuid.UUID
+	subscribe    bool
+	// Отправить клиенту подтверждение (для запросов самого клиента)
+	notify bool
+}
+
 // MessageType тип сообщения
 type MessageType string
 
@@ -47,22 +67,34 @@ const (
 	MessageTypeMatchUpdate MessageType = "match_update"
 	// MessageTypeLeaderboardUpdate обновление таблицы лидеров
 	MessageTypeLeaderboardUpdate MessageType = "leaderboard_update"
+	// MessageTypeRoundCompleted раунд игры автоматически завершён после последнего матча
+	MessageTypeRoundCompleted MessageType = "round_completed"
 	// MessageTypeError ошибка
 	MessageTypeError MessageType = "error"
 	// MessageTypePing ping
 	MessageTypePing MessageType = "ping"
 	// MessageTypePong pong
 	MessageTypePong MessageType = "pong"
+	// MessageTypeSubscribe подписка на обновления турнира (от клиента)
+	MessageTypeSubscribe MessageType = "subscribe"
+	// MessageTypeUnsubscribe отписка от обновлений турнира (от клиента)
+	MessageTypeUnsubscribe MessageType = "unsubscribe"
+	// MessageTypeSubscribed подтверждение подписки
+	MessageTypeSubscribed MessageType = "subscribed"
+	// MessageTypeUnsubscribed подтверждение отписки
+	MessageTypeUnsubscribed MessageType = "unsubscribed"
 )
 
 // NewHub создаёт новый WebSocket hub
 func NewHub(log *logger.Logger) *Hub {
 	return &Hub{
-		tournaments: make(map[uuid.UUID]map[*Client]bool),
-		register:    make(chan *Client),
-		unregister:  make(chan *Client),
-		broadcast:   make(chan *Message, 256),
-		log:         log,
+		clients:       make(map[*Client]bool),
+		tournaments:   make(map[uuid.UUID]map[*Client]bool),
+		register:      make(chan *Client),
+		unregister:    make(chan *Client),
+		subscriptions: make(chan subscription),
+		broadcast:     make(chan *Message, 256),
+		log:           log,
 	}
 }
 
@@ -81,6 +113,9 @@ func (h *Hub) Run(ctx context.Context) {
 		case client := <-h.unregister:
 			h.unregisterClient(client)
 
+		case sub := <-h.subscriptions:
+			h.updateSubscription(sub)
+
 		case message := <-h.broadcast:
 			h.broadcastMessage(message)
 		}
@@ -92,14 +127,9 @@ func (h *Hub) registerClient(client *Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if h.tournaments[client.tournamentID] == nil {
-		h.tournaments[client.tournamentID] = make(map[*Client]bool)
-	}
-
-	h.tournaments[client.tournamentID][client] = true
+	h.clients[client] = true
 
 	h.log.Info("Client registered",
-		zap.String("tournament_id", client.tournamentID.String()),
 		zap.String("user_id", client.userID.String()),
 	)
 }
@@ -109,28 +139,106 @@ func (h *Hub) unregisterClient(client *Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if clients, ok := h.tournaments[client.tournamentID]; ok {
-		if _, exists := clients[client]; exists {
-			delete(clients, client)
-			close(client.send)
+	if !h.clients[client] {
+		return
+	}
+	h.removeClient(client)
 
-			// Удаляем пустую map турнира
-			if len(clients) == 0 {
-				delete(h.tournaments, client.tournamentID)
-			}
+	h.log.Info("Client unregistered",
+		zap.String("user_id", client.userID.String()),
+	)
+}
 
-			h.log.Info("Client unregistered",
-				zap.String("tournament_id", client.tournamentID.String()),
-				zap.String("user_id", client.userID.String()),
-			)
+// removeClient удаляет клиента из всех подписок и закрывает его канал.
+// Вызывается под h.mu
+func (h *Hub) removeClient(client *Client) {
+	for tournamentID := range client.subscriptions {
+		h.removeSubscriber(tournamentID, client)
+	}
+	delete(h.clients, client)
+	close(client.send)
+}
+
+// removeSubscriber удаляет клиента из подписчиков турнира. Вызывается под h.mu
+func (h *Hub) removeSubscriber(tournamentID uuid.UUID, client *Client) {
+	delete(client.subscriptions, tournamentID)
+
+	clients, ok := h.tournaments[tournamentID]
+	if !ok {
+		return
+	}
+	delete(clients, client)
+
+	// Удаляем пустую map турнира
+	if len(clients) == 0 {
+		delete(h.tournaments, tournamentID)
+	}
+}
+
+// updateSubscription подписывает клиента на турнир или отписывает от него
+func (h *Hub) updateSubscription(sub subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client := sub.client
+	// Клиент уже отключён
+	if !h.clients[client] {
+		return
+	}
+
+	replyType := MessageTypeUnsubscribed
+	if sub.subscribe {
+		if !client.subscriptions[sub.tournamentID] && len(client.subscriptions) >= maxSubscriptions {
+			h.reply(client, &Message{
+				TournamentID: sub.tournamentID,
+				Type:         MessageTypeError,
+				Payload:      map[string]string{"error": "too many subscriptions"},
+			})
+			return
 		}
+
+		if h.tournaments[sub.tournamentID] == nil {
+			h.tournaments[sub.tournamentID] = make(map[*Client]bool)
+		}
+		h.tournaments[sub.tournamentID][client] = true
+		client.subscriptions[sub.tournamentID] = true
+		replyType = MessageTypeSubscribed
+	} else {
+		h.removeSubscriber(sub.tournamentID, client)
+	}
+
+	h.log.Debug("Client subscription updated",
+		zap.String("tournament_id", sub.tournamentID.String()),
+		zap.String("user_id", client.userID.String()),
+		zap.Bool("subscribed", sub.subscribe),
+	)
+
+	if sub.notify {
+		h.reply(client, &Message{TournamentID: sub.tournamentID, Type: replyType})
 	}
 }
 
-// broadcastMessage отправляет сообщение всем клиентам турнира
+// reply отправляет сообщение одному клиенту. Вызывается под h.mu
+func (h *Hub) reply(client *Client, message *Message) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		h.log.LogError("Failed to marshal message", err)
+		return
+	}
+
+	select {
+	case client.send <- data:
+	default:
+		h.log.Info("Client send buffer full",
+			zap.String("user_id", client.userID.String()),
+		)
+	}
+}
+
+// broadcastMessage отправляет сообщение всем подписчикам турнира
 func (h *Hub) broadcastMessage(message *Message) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
 	clients, ok := h.tournaments[message.TournamentID]
 	if !ok {
@@ -144,25 +252,26 @@ func (h *Hub) broadcastMessage(message *Message) {
 		return
 	}
 
-	// Отправляем всем клиентам
+	// Отправляем всем подписчикам
+	sent := 0
 	for client := range clients {
 		select {
 		case client.send <- data:
+			sent++
 		default:
 			// Канал заблокирован, отключаем клиента
 			h.log.Info("Client send buffer full, disconnecting",
-				zap.String("tournament_id", client.tournamentID.String()),
+				zap.String("tournament_id", message.TournamentID.String()),
 				zap.String("user_id", client.userID.String()),
 			)
-			close(client.send)
-			delete(clients, client)
+			h.removeClient(client)
 		}
 	}
 
 	h.log.Debug("Broadcast message sent",
 		zap.String("tournament_id", message.TournamentID.String()),
 		zap.String("type", string(message.Type)),
-		zap.Int("clients", len(clients)),
+		zap.Int("clients", sent),
 	)
 }
 
@@ -190,12 +299,8 @@ func (h *Hub) shutdown() {
 	defer h.mu.Unlock()
 
 	// Закрываем все подключения
-	for tournamentID, clients := range h.tournaments {
-		for client := range clients {
-			close(client.send)
-			delete(clients, client)
-		}
-		delete(h.tournaments, tournamentID)
+	for client := range h.clients {
+		h.removeClient(client)
 	}
 
 	h.log.Info("WebSocket hub shutdown complete")
@@ -206,13 +311,14 @@ func (h *Hub) GetStats() map[string]interface{} {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	totalClients := 0
+	subscriptions := 0
 	for _, clients := range h.tournaments {
-		totalClients += len(clients)
+		subscriptions += len(clients)
 	}
 
 	return map[string]interface{}{
 		"tournaments":   len(h.tournaments),
-		"total_clients": totalClients,
+		"total_clients": len(h.clients),
+		"subscriptions": subscriptions,
 	}
 }
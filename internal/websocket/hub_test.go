@@ -0,0 +1,137 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestHub(t *testing.T) *Hub {
+	t.Helper()
+	log, _ := logger.New("debug", "json")
+	hub := NewHub(log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go hub.Run(ctx)
+
+	return hub
+}
+
+// newTestClient creates a registered client without a connection: the hub only uses its send channel
+func newTestClient(hub *Hub) *Client {
+	client := NewClient(hub, nil, uuid.New(), hub.log)
+	client.Register()
+	return client
+}
+
+func receive(t *testing.T, client *Client) Message {
+	t.Helper()
+	select {
+	case data := <-client.send:
+		var msg Message
+		require.NoError(t, json.Unmarshal(data, &msg))
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("expected a message")
+		return Message{}
+	}
+}
+
+func assertNoMessage(t *testing.T, client *Client) {
+	t.Helper()
+	select {
+	case data := <-client.send:
+		t.Fatalf("unexpected message: %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHub_BroadcastOnlyToSubscribers(t *testing.T) {
+	hub := startTestHub(t)
+	tournament1, tournament2 := uuid.New(), uuid.New()
+
+	subscribed := newTestClient(hub)
+	subscribed.Subscribe(tournament1)
+	other := newTestClient(hub)
+	other.Subscribe(tournament2)
+	idle := newTestClient(hub)
+
+	hub.Broadcast(tournament1, string(MessageTypeMatchUpdate), nil)
+
+	msg := receive(t, subscribed)
+	assert.Equal(t, MessageTypeMatchUpdate, msg.Type)
+	assert.Equal(t, tournament1, msg.TournamentID)
+	assertNoMessage(t, other)
+	assertNoMessage(t, idle)
+}
+
+func TestHub_SubscribeProtocol(t *testing.T) {
+	hub := startTestHub(t)
+	tournamentID := uuid.New()
+	client := newTestClient(hub)
+
+	subscribe, err := json.Marshal(Message{TournamentID: tournamentID, Type: MessageTypeSubscribe})
+	require.NoError(t, err)
+	client.handleMessage(subscribe)
+
+	ack := receive(t, client)
+	assert.Equal(t, MessageTypeSubscribed, ack.Type)
+	assert.Equal(t, tournamentID, ack.TournamentID)
+
+	hub.Broadcast(tournamentID, string(MessageTypeLeaderboardUpdate), nil)
+	assert.Equal(t, MessageTypeLeaderboardUpdate, receive(t, client).Type)
+
+	unsubscribe, err := json.Marshal(Message{TournamentID: tournamentID, Type: MessageTypeUnsubscribe})
+	require.NoError(t, err)
+	client.handleMessage(unsubscribe)
+	assert.Equal(t, MessageTypeUnsubscribed, receive(t, client).Type)
+
+	hub.Broadcast(tournamentID, string(MessageTypeLeaderboardUpdate), nil)
+	assertNoMessage(t, client)
+
+	stats := hub.GetStats()
+	assert.Equal(t, 1, stats["total_clients"])
+	assert.Equal(t, 0, stats["tournaments"])
+}
+
+func TestHub_SubscriptionLimit(t *testing.T) {
+	hub := startTestHub(t)
+	client := newTestClient(hub)
+
+	for i := 0; i < maxSubscriptions; i++ {
+		client.Subscribe(uuid.New())
+	}
+
+	extra, err := json.Marshal(Message{TournamentID: uuid.New(), Type: MessageTypeSubscribe})
+	require.NoError(t, err)
+	client.handleMessage(extra)
+
+	assert.Equal(t, MessageTypeError, receive(t, client).Type)
+	assert.Equal(t, maxSubscriptions, hub.GetStats()["subscriptions"])
+}
+
+func TestHub_UnregisterRemovesAllSubscriptions(t *testing.T) {
+	hub := startTestHub(t)
+	client := newTestClient(hub)
+	client.Subscribe(uuid.New())
+	client.Subscribe(uuid.New())
+
+	hub.unregister <- client
+	// Unregistering twice must not close the channel again
+	hub.unregister <- client
+
+	_, open := <-client.send
+	assert.False(t, open)
+
+	stats := hub.GetStats()
+	assert.Equal(t, 0, stats["total_clients"])
+	assert.Equal(t, 0, stats["tournaments"])
+	assert.Equal(t, 0, stats["subscriptions"])
+}
@@ -0,0 +1,171 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultLeaderboardPollInterval как часто проверяются таблицы лидеров активных турниров
+	DefaultLeaderboardPollInterval = 5 * time.Second
+	// leaderboardPollLimit сколько первых мест таблицы отслеживается и отправляется клиентам
+	leaderboardPollLimit = 100
+)
+
+// LeaderboardSource интерфейс для чтения верхней части таблицы лидеров из кэша
+type LeaderboardSource interface {
+	GetTop(ctx context.Context, tournamentID uuid.UUID, limit int) ([]*domain.LeaderboardEntry, error)
+}
+
+// ActiveTournamentLister интерфейс для получения турниров, таблицы которых отслеживаются
+type ActiveTournamentLister interface {
+	GetActiveTournamentIDs(ctx context.Context) ([]uuid.UUID, error)
+}
+
+// LeaderboardPoller периодически сверяет таблицы лидеров активных турниров
+// с последней отправленной версией и рассылает leaderboard_update только при изменениях.
+// Каждая реплика API опрашивает кэш сама: у каждой свой Hub и свои клиенты
+type LeaderboardPoller struct {
+	tournaments ActiveTournamentLister
+	leaderboard LeaderboardSource
+	broadcaster Broadcaster
+	interval    time.Duration
+	log         *logger.Logger
+
+	// Последняя отправленная таблица каждого турнира. Используется только в горутине опроса
+	sent map[uuid.UUID][]domain.LeaderboardEntry
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewLeaderboardPoller создаёт опрос таблиц лидеров (interval <= 0 - DefaultLeaderboardPollInterval)
+func NewLeaderboardPoller(tournaments ActiveTournamentLister, leaderboard LeaderboardSource, broadcaster Broadcaster, interval time.Duration, log *logger.Logger) *LeaderboardPoller {
+	if interval <= 0 {
+		interval = DefaultLeaderboardPollInterval
+	}
+	return &LeaderboardPoller{
+		tournaments: tournaments,
+		leaderboard: leaderboard,
+		broadcaster: broadcaster,
+		interval:    interval,
+		log:         log,
+		sent:        make(map[uuid.UUID][]domain.LeaderboardEntry),
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+}
+
+// Start запускает опрос в отдельной горутине. Опрос завершается по Stop или отмене ctx
+func (p *LeaderboardPoller) Start(ctx context.Context) {
+	p.log.Info("Starting leaderboard poller",
+		zap.Duration("interval", p.interval),
+	)
+
+	go p.run(ctx)
+}
+
+// Stop останавливает опрос и дожидается завершения горутины
+func (p *LeaderboardPoller) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+	<-p.doneCh
+	p.log.Info("Leaderboard poller stopped")
+}
+
+// run основной цикл опроса
+func (p *LeaderboardPoller) run(ctx context.Context) {
+	defer close(p.doneCh)
+
+	// Первый опрос только запоминает текущие таблицы: клиенты получают их при подписке
+	p.poll(ctx, false)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.poll(ctx, true)
+		case <-p.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// poll сверяет таблицы всех активных турниров с отправленными.
+// notify = false - только запомнить таблицы, не рассылая их
+func (p *LeaderboardPoller) poll(ctx context.Context, notify bool) {
+	pollCtx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+
+	tournamentIDs, err := p.tournaments.GetActiveTournamentIDs(pollCtx)
+	if err != nil {
+		p.log.LogError("Failed to list active tournaments", err)
+		return
+	}
+
+	active := make(map[uuid.UUID]bool, len(tournamentIDs))
+	for _, tournamentID := range tournamentIDs {
+		active[tournamentID] = true
+
+		entries, err := p.leaderboard.GetTop(pollCtx, tournamentID, leaderboardPollLimit)
+		if err != nil {
+			p.log.LogError("Failed to get leaderboard", err,
+				zap.String("tournament_id", tournamentID.String()),
+			)
+			continue
+		}
+		// Пустой кэш (ещё не заполнен или сброшен) не означает пустую таблицу
+		if len(entries) == 0 {
+			continue
+		}
+
+		snapshot := make([]domain.LeaderboardEntry, len(entries))
+		for i, entry := range entries {
+			snapshot[i] = *entry
+		}
+
+		previous, seen := p.sent[tournamentID]
+		if seen && sameLeaderboard(previous, snapshot) {
+			continue
+		}
+		p.sent[tournamentID] = snapshot
+
+		if notify {
+			p.broadcaster.Broadcast(tournamentID, string(MessageTypeLeaderboardUpdate), map[string]interface{}{
+				"entries": entries,
+			})
+		}
+	}
+
+	// Завершённые турниры больше не отслеживаются
+	for tournamentID := range p.sent {
+		if !active[tournamentID] {
+			delete(p.sent, tournamentID)
+		}
+	}
+}
+
+// sameLeaderboard сравнивает места и рейтинги двух таблиц
+func sameLeaderboard(a, b []domain.LeaderboardEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Rank != b[i].Rank || a[i].ProgramID != b[i].ProgramID || a[i].Rating != b[i].Rating {
+			return false
+		}
+	}
+	return true
+}
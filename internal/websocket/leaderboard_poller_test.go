@@ -0,0 +1,161 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLeaderboards serves active tournaments and their cached leaderboards
+type fakeLeaderboards struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID][]*domain.LeaderboardEntry
+	lists   int
+	reads   int
+}
+
+func (f *fakeLeaderboards) GetActiveTournamentIDs(ctx context.Context) ([]uuid.UUID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists++
+
+	ids := make([]uuid.UUID, 0, len(f.entries))
+	for id := range f.entries {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (f *fakeLeaderboards) GetTop(ctx context.Context, tournamentID uuid.UUID, limit int) ([]*domain.LeaderboardEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reads++
+
+	entries := make([]*domain.LeaderboardEntry, 0, len(f.entries[tournamentID]))
+	for _, entry := range f.entries[tournamentID] {
+		copied := *entry
+		entries = append(entries, &copied)
+	}
+	return entries, nil
+}
+
+func (f *fakeLeaderboards) setRating(tournamentID uuid.UUID, rank, rating int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries[tournamentID][rank-1].Rating = rating
+}
+
+func (f *fakeLeaderboards) readCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reads
+}
+
+func (f *fakeLeaderboards) listCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lists
+}
+
+// recordingBroadcaster passes broadcasts to a channel
+type recordingBroadcaster struct {
+	messages chan Message
+}
+
+func (b *recordingBroadcaster) Broadcast(tournamentID uuid.UUID, messageType string, payload interface{}) {
+	b.messages <- Message{TournamentID: tournamentID, Type: MessageType(messageType), Payload: payload}
+}
+
+func newPollerFixture(t *testing.T, interval time.Duration) (*LeaderboardPoller, *fakeLeaderboards, *recordingBroadcaster, uuid.UUID) {
+	t.Helper()
+	log, _ := logger.New("error", "json")
+
+	tournamentID := uuid.New()
+	source := &fakeLeaderboards{entries: map[uuid.UUID][]*domain.LeaderboardEntry{
+		tournamentID: {
+			{Rank: 1, ProgramID: uuid.New(), Rating: 1600},
+			{Rank: 2, ProgramID: uuid.New(), Rating: 1500},
+		},
+	}}
+	broadcaster := &recordingBroadcaster{messages: make(chan Message, 16)}
+
+	poller := NewLeaderboardPoller(source, source, broadcaster, interval, log)
+	return poller, source, broadcaster, tournamentID
+}
+
+func TestLeaderboardPoller_NoBroadcastWithoutChanges(t *testing.T) {
+	poller, source, broadcaster, _ := newPollerFixture(t, 10*time.Millisecond)
+
+	poller.Start(context.Background())
+	defer poller.Stop()
+
+	// Several polls happen, none of them sees a change
+	require.Eventually(t, func() bool { return source.listCalls() >= 5 }, time.Second, 5*time.Millisecond)
+	select {
+	case msg := <-broadcaster.messages:
+		t.Fatalf("unexpected broadcast: %+v", msg)
+	default:
+	}
+}
+
+func TestLeaderboardPoller_BroadcastsRatingChange(t *testing.T) {
+	poller, source, broadcaster, tournamentID := newPollerFixture(t, DefaultLeaderboardPollInterval)
+
+	poller.Start(context.Background())
+	defer poller.Stop()
+
+	// The first poll records the current leaderboard
+	require.Eventually(t, func() bool { return source.readCalls() >= 1 }, time.Second, 5*time.Millisecond)
+	source.setRating(tournamentID, 2, 1520)
+
+	select {
+	case msg := <-broadcaster.messages:
+		assert.Equal(t, tournamentID, msg.TournamentID)
+		assert.Equal(t, MessageTypeLeaderboardUpdate, msg.Type)
+		payload := msg.Payload.(map[string]interface{})
+		entries := payload["entries"].([]*domain.LeaderboardEntry)
+		require.Len(t, entries, 2)
+		assert.Equal(t, 1520, entries[1].Rating)
+	case <-time.After(6 * time.Second):
+		t.Fatal("expected leaderboard_update within 6 seconds")
+	}
+
+	// The same leaderboard is not sent twice
+	select {
+	case msg := <-broadcaster.messages:
+		t.Fatalf("unexpected broadcast: %+v", msg)
+	default:
+	}
+}
+
+func TestLeaderboardPoller_StopTerminatesGoroutine(t *testing.T) {
+	poller, source, _, _ := newPollerFixture(t, 10*time.Millisecond)
+
+	poller.Start(context.Background())
+	require.Eventually(t, func() bool { return source.listCalls() >= 2 }, time.Second, 5*time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		poller.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return")
+	}
+
+	// No polls after Stop; a second Stop is a no-op
+	calls := source.listCalls()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, calls, source.listCalls())
+	poller.Stop()
+}
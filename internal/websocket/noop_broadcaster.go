@@ -11,6 +11,6 @@ func NewNoopBroadcaster() *NoopBroadcaster {
 }
 
 // Broadcast ничего не делает
-func (n *NoopBroadcaster) Broadcast(tournamentID uuid.UUID, messageType MessageType, payload interface{}) {
+func (n *NoopBroadcaster) Broadcast(tournamentID uuid.UUID, messageType string, payload interface{}) {
 	// No-op
 }
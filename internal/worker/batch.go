@@ -0,0 +1,229 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/executor"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// MatchError - ошибка обработки матча пакета
+type MatchError struct {
+	MatchID uuid.UUID
+	Err     error
+}
+
+func (e MatchError) Error() string {
+	return fmt.Sprintf("match %s: %v", e.MatchID, e.Err)
+}
+
+func (e MatchError) Unwrap() error {
+	return e.Err
+}
+
+// ProcessBatch обрабатывает пакет матчей: выполняет их параллельно и сохраняет
+// результаты одним запросом. Ошибка одного матча не мешает сохранить остальные.
+// Возвращает ошибки матчей, которые не удалось обработать
+func (p *Processor) ProcessBatch(ctx context.Context, matches []*domain.Match) (failed []MatchError) {
+	ctx, span := tracing.StartSpan(ctx, "worker.ProcessBatch",
+		attribute.Int("batch.size", len(matches)),
+	)
+	defer func() {
+		var err error
+		if len(failed) > 0 {
+			err = fmt.Errorf("%d of %d matches failed", len(failed), len(matches))
+		}
+		tracing.EndSpan(span, err)
+	}()
+
+	fail := func(match *domain.Match, err error) {
+		failed = append(failed, MatchError{MatchID: match.ID, Err: err})
+	}
+
+	// Подготовка идёт по одному матчу: это запросы к БД и кэшированная компиляция
+	var runs []*matchRun
+	var batch []executor.BatchRun
+	for _, match := range matches {
+		run, err := p.prepare(ctx, match)
+		if err != nil && drainInterrupted(ctx) {
+			fail(match, p.releaseMatch(ctx, match, err))
+			continue
+		}
+		if err != nil {
+			fail(match, err)
+			continue
+		}
+		if run == nil {
+			continue
+		}
+		runs = append(runs, run)
+		batch = append(batch, executor.BatchRun{
+			Match:        match,
+			Program1Path: run.program1Path,
+			Program2Path: run.program2Path,
+			Options:      run.options,
+		})
+	}
+	if len(runs) == 0 {
+		return failed
+	}
+
+	executed := executor.RunBatch(ctx, p.executor, batch)
+
+	results := make(map[uuid.UUID]*domain.MatchResult, len(runs))
+	var stored []*matchRun
+	var execErrs []error
+	for i, run := range runs {
+		match := run.match
+		result, err := p.retryTransient(ctx, run, executed[i].Result, executed[i].Err)
+		if err != nil {
+			// Daemon недоступен или пул остановлен: матч не сыгран и не должен считаться проваленным
+			if executor.IsDockerUnavailable(err) || drainInterrupted(ctx) {
+				fail(match, p.releaseMatch(ctx, match, err))
+				continue
+			}
+			result = executionFailure(match, err)
+		} else {
+			p.observeResult(run, result)
+		}
+		results[match.ID] = result
+		stored = append(stored, run)
+		execErrs = append(execErrs, err)
+	}
+	if len(stored) == 0 {
+		return failed
+	}
+
+	// Результаты сыгранных матчей сохраняются и при остановке пула
+	ctx = context.WithoutCancel(ctx)
+
+	// Все результаты пакета сохраняются за один запрос
+	conflicts, err := p.matchRepo.BatchUpdateResults(ctx, results)
+	if err != nil {
+		for _, run := range stored {
+			fail(run.match, fmt.Errorf("failed to update match results: %w", err))
+		}
+		return failed
+	}
+	skipped := make(map[uuid.UUID]bool, len(conflicts))
+	for _, id := range conflicts {
+		skipped[id] = true
+	}
+
+	for i, run := range stored {
+		match := run.match
+		if skipped[match.ID] {
+			// Результат уже записан другим воркером: рейтинги уже обновлены
+			p.skipDuplicate(match, errors.ErrConflict)
+			continue
+		}
+		if execErrs[i] != nil {
+			p.publishEnd(ctx, match)
+			p.finishRound(ctx, match)
+			fail(match, fmt.Errorf("failed to execute match: %w", execErrs[i]))
+			continue
+		}
+		p.completeMatch(ctx, run, results[match.ID])
+	}
+
+	return failed
+}
+
+// processBatch берёт из очереди пакет матчей и обрабатывает его за один проход.
+// Матчи пакета выполняются без повторных попыток: матч с ошибкой остаётся для recovery
+func (p *Pool) processBatch(workerID int32) {
+	ctx, cancel := context.WithTimeout(p.ctx, 5*time.Second)
+	defer cancel()
+
+	dequeued, err := p.queue.DequeueBatch(ctx, p.config.BatchSize)
+	if err != nil {
+		p.log.LogError("Failed to dequeue match batch", err, zap.Int32("worker_id", workerID))
+		time.Sleep(time.Second)
+		return
+	}
+
+	// Очередь пустая
+	if len(dequeued) == 0 {
+		time.Sleep(100 * time.Millisecond)
+		return
+	}
+
+	dequeuedAt := time.Now()
+	matches := make([]*domain.Match, 0, len(dequeued))
+	for _, match := range dequeued {
+		// Матчи игры вне её окна откладываются, не считаясь обработанными
+		if p.deferOutsideWindow(workerID, match) {
+			continue
+		}
+		match.DequeuedAt = &dequeuedAt
+		p.metrics.RecordQueueWait(string(match.Priority), match.QueueWait(dequeuedAt))
+		p.metrics.RecordMatchStart()
+		matches = append(matches, match)
+	}
+	if len(matches) == 0 {
+		return
+	}
+
+	p.log.Info("Processing match batch",
+		zap.Int32("worker_id", workerID),
+		zap.Int("size", len(matches)),
+	)
+
+	start := time.Now()
+	processCtx, processCancel := context.WithTimeout(p.runCtx, p.config.Timeout)
+	defer processCancel()
+
+	failures := make(map[uuid.UUID]error)
+	for _, matchErr := range p.processor.ProcessBatch(processCtx, matches) {
+		failures[matchErr.MatchID] = matchErr.Err
+	}
+
+	duration := time.Since(start)
+	for _, match := range matches {
+		err := failures[match.ID]
+		if executor.IsDockerUnavailable(err) {
+			p.metrics.RecordMatchComplete(match.GameType, "requeued", duration)
+			p.handleDockerOutage(workerID, match, err)
+			continue
+		}
+		if err != nil && drainInterrupted(processCtx) {
+			p.metrics.RecordMatchComplete(match.GameType, "requeued", duration)
+			p.requeueInterrupted(workerID, match)
+			continue
+		}
+
+		status := "completed"
+		switch {
+		case err == ErrMatchNotFound:
+			p.log.Info("Match skipped (not found in database)",
+				zap.String("match_id", match.ID.String()),
+			)
+			p.matchesProcessed.Add(1)
+		case err != nil:
+			status = "failed"
+			p.matchesFailed.Add(1)
+			p.log.LogError("Match processing failed", err,
+				zap.Int32("worker_id", workerID),
+				zap.String("match_id", match.ID.String()),
+			)
+		default:
+			p.matchesProcessed.Add(1)
+		}
+
+		p.metrics.RecordMatchComplete(match.GameType, status, duration)
+	}
+
+	p.log.Info("Match batch processed",
+		zap.Int32("worker_id", workerID),
+		zap.Int("size", len(matches)),
+		zap.Int("failed", len(failures)),
+		zap.Duration("duration", duration),
+	)
+}
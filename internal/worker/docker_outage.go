@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// dockerProbeInitialDelay задержка перед первой проверкой daemon после сбоя
+	dockerProbeInitialDelay = time.Second
+	// dockerProbeMaxDelay максимальная задержка между проверками
+	dockerProbeMaxDelay = 30 * time.Second
+	// dockerProbeTimeout таймаут одной проверки
+	dockerProbeTimeout = 5 * time.Second
+	// requeueTimeout таймаут возврата матча в очередь
+	requeueTimeout = 5 * time.Second
+)
+
+// DockerProber проверяет доступность Docker daemon
+type DockerProber interface {
+	Ping(ctx context.Context) error
+}
+
+// SetDockerProber устанавливает проверку Docker daemon, по которой пул выходит из паузы.
+// Без неё пул возобновляет работу по истечении задержки, и проверкой служит следующий матч
+func (p *Pool) SetDockerProber(prober DockerProber) {
+	p.prober = prober
+}
+
+// waitForDocker блокирует worker, пока пул на паузе из-за недоступности Docker daemon.
+// Возвращает false, если пул остановлен
+func (p *Pool) waitForDocker() bool {
+	p.outageMu.Lock()
+	resumed := p.outage
+	p.outageMu.Unlock()
+
+	if resumed == nil {
+		return true
+	}
+
+	select {
+	case <-resumed:
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+// handleDockerOutage возвращает матч в очередь без учёта как проваленного и ставит пул на паузу
+func (p *Pool) handleDockerOutage(workerID int32, match *domain.Match, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requeueTimeout)
+	defer cancel()
+
+	if enqErr := p.queue.Enqueue(ctx, match); enqErr != nil {
+		// Матч остаётся в pending: его вернёт в очередь recovery
+		p.log.LogError("Failed to requeue match after docker outage", enqErr,
+			zap.Int32("worker_id", workerID),
+			zap.String("match_id", match.ID.String()),
+		)
+	}
+
+	p.pauseForDocker(err)
+}
+
+// pauseForDocker ставит пул на паузу и запускает проверку daemon, если пауза ещё не начата
+func (p *Pool) pauseForDocker(err error) {
+	p.outageMu.Lock()
+	defer p.outageMu.Unlock()
+
+	if p.outage != nil {
+		return
+	}
+	p.outage = make(chan struct{})
+
+	p.log.Error("DOCKER DAEMON UNAVAILABLE: worker pool paused, matches are requeued until it recovers",
+		zap.Error(err),
+	)
+	p.metrics.SetDockerUnavailable(true)
+
+	go p.probeDocker(time.Now())
+}
+
+// probeDocker проверяет daemon с экспоненциальной задержкой и снимает паузу после восстановления
+func (p *Pool) probeDocker(since time.Time) {
+	delay := p.probeDelay
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		err := p.ping()
+		if err == nil {
+			break
+		}
+
+		p.log.Warn("Docker daemon is still unavailable",
+			zap.Int("attempt", attempt),
+			zap.Duration("next_probe_in", min(delay*2, p.probeMaxDelay)),
+			zap.Error(err),
+		)
+		delay = min(delay*2, p.probeMaxDelay)
+	}
+
+	p.outageMu.Lock()
+	close(p.outage)
+	p.outage = nil
+	p.outageMu.Unlock()
+
+	p.metrics.SetDockerUnavailable(false)
+	p.log.Info("Docker daemon is available again, worker pool resumed",
+		zap.Duration("outage", time.Since(since)),
+	)
+}
+
+// ping проверяет daemon. Без prober пул просто возобновляет работу
+func (p *Pool) ping() error {
+	if p.prober == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, dockerProbeTimeout)
+	defer cancel()
+	return p.prober.Ping(ctx)
+}
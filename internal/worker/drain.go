@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ErrDrainTimeout причина отмены матчей, не завершившихся за время остановки пула
+var ErrDrainTimeout = errors.New("worker pool drain timeout exceeded")
+
+// drainInterrupted сообщает, что обработка прервана истечением времени остановки пула
+func drainInterrupted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrDrainTimeout)
+}
+
+// drain ждёт завершения текущих матчей воркеров. Если они не успели за DrainTimeout,
+// их выполнение отменяется: матчи возвращаются в pending и в очередь, а не считаются проваленными
+func (p *Pool) drain() {
+	if p.config.DrainTimeout <= 0 {
+		p.wg.Wait()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return
+	case <-time.After(p.config.DrainTimeout):
+	}
+
+	p.log.Warn("Worker pool drain timeout exceeded, interrupting running matches",
+		zap.Duration("drain_timeout", p.config.DrainTimeout),
+		zap.Int32("active_workers", p.activeWorkers.Load()),
+	)
+	p.metrics.RecordDrainTimeout()
+
+	p.abort(ErrDrainTimeout)
+	<-done
+}
+
+// requeueInterrupted возвращает в очередь матч, прерванный остановкой пула.
+// Статус pending матчу уже вернул processor; если очередь недоступна, матч поставит recovery
+func (p *Pool) requeueInterrupted(workerID int32, match *domain.Match) {
+	ctx, cancel := context.WithTimeout(context.Background(), requeueTimeout)
+	defer cancel()
+
+	if err := p.queue.Enqueue(ctx, match); err != nil {
+		p.log.LogError("Failed to requeue match interrupted by shutdown", err,
+			zap.Int32("worker_id", workerID),
+			zap.String("match_id", match.ID.String()),
+		)
+		return
+	}
+
+	p.log.Info("Match interrupted by shutdown, requeued",
+		zap.Int32("worker_id", workerID),
+		zap.String("match_id", match.ID.String()),
+	)
+}
@@ -0,0 +1,186 @@
+package worker
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/queue"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// escalationLockKey блокировка, с которой повышение выполняет один worker за интервал
+const escalationLockKey = "worker:priority_escalation"
+
+// EscalationMatchRepository интерфейс для повышения приоритета долго ожидающих матчей
+type EscalationMatchRepository interface {
+	EscalateOldPendingMatches(ctx context.Context, maxAge time.Duration, batchSize int) ([]*domain.Match, error)
+}
+
+// EscalationQueueManager интерфейс для переноса матча в очередь нового приоритета
+type EscalationQueueManager interface {
+	Reprioritize(ctx context.Context, matchID uuid.UUID, newPriority domain.MatchPriority) error
+}
+
+// EscalationLocker распределённая блокировка (реализуется cache.DistributedLock)
+type EscalationLocker interface {
+	Lock(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// PriorityEscalationConfig конфигурация повышения приоритетов
+type PriorityEscalationConfig struct {
+	MaxAge    time.Duration // Возраст pending матча, после которого он повышается
+	Interval  time.Duration // По умолчанию 5 минут
+	BatchSize int           // По умолчанию 500
+}
+
+// PriorityEscalationService периодически повышает в БД приоритет pending матчей,
+// ждущих дольше MaxAge, и переносит их в очередь нового приоритета. В отличие от
+// старения в очереди (WORKER_PRIORITY_AGING) новый приоритет сохраняется в матче
+type PriorityEscalationService struct {
+	matchRepo    EscalationMatchRepository
+	queueManager EscalationQueueManager
+	lock         EscalationLocker
+	metrics      *metrics.Metrics
+	log          *logger.Logger
+
+	maxAge    time.Duration
+	interval  time.Duration
+	batchSize int
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewPriorityEscalationService создаёт сервис повышения приоритетов
+func NewPriorityEscalationService(
+	matchRepo EscalationMatchRepository,
+	queueManager EscalationQueueManager,
+	log *logger.Logger,
+	cfg PriorityEscalationConfig,
+) *PriorityEscalationService {
+	if cfg.Interval == 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 500
+	}
+
+	return &PriorityEscalationService{
+		matchRepo:    matchRepo,
+		queueManager: queueManager,
+		log:          log,
+		maxAge:       cfg.MaxAge,
+		interval:     cfg.Interval,
+		batchSize:    cfg.BatchSize,
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}
+}
+
+// SetMetrics устанавливает метрики повышений
+func (s *PriorityEscalationService) SetMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
+// SetLock включает распределённую блокировку: за интервал матчи повышает один worker,
+// иначе несколько worker'ов подряд подняли бы матч сразу на две ступени
+func (s *PriorityEscalationService) SetLock(lock EscalationLocker) {
+	s.lock = lock
+}
+
+// Start запускает периодическое повышение в фоне
+func (s *PriorityEscalationService) Start() {
+	s.log.Info("Starting priority escalation service",
+		zap.Duration("interval", s.interval),
+		zap.Duration("max_age", s.maxAge),
+	)
+
+	go s.run()
+}
+
+// Stop останавливает повышение и ждёт завершения текущей итерации
+func (s *PriorityEscalationService) Stop() {
+	close(s.stopCh)
+	<-s.doneCh
+	s.log.Info("Priority escalation service stopped")
+}
+
+func (s *PriorityEscalationService) run() {
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.escalatePeriodic()
+		}
+	}
+}
+
+// escalatePeriodic плановое повышение: пропускается, если в этом интервале его уже выполнил другой worker
+func (s *PriorityEscalationService) escalatePeriodic() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if s.lock != nil {
+		// Блокировка не снимается: она истекает незадолго до следующего тика
+		if _, err := s.lock.Lock(ctx, escalationLockKey, s.interval*9/10); err != nil {
+			if !errors.IsConflict(err) {
+				s.log.LogError("Failed to acquire priority escalation lock", err)
+			}
+			return
+		}
+	}
+
+	if _, err := s.EscalateStaleMatches(ctx, s.maxAge); err != nil {
+		s.log.LogError("Priority escalation failed", err)
+	}
+}
+
+// EscalateStaleMatches поднимает на ступень приоритет pending матчей старше maxAge
+// (low -> medium, medium -> high) и переносит их в очередь нового приоритета.
+// Матч, которого уже нет в очереди (его взял worker), просто сохраняет новый приоритет.
+// Возвращает количество повышенных матчей
+func (s *PriorityEscalationService) EscalateStaleMatches(ctx context.Context, maxAge time.Duration) (int, error) {
+	matches, err := s.matchRepo.EscalateOldPendingMatches(ctx, maxAge, s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, match := range matches {
+		if s.metrics != nil {
+			s.metrics.RecordEscalation(string(match.Priority))
+		}
+
+		if err := s.queueManager.Reprioritize(ctx, match.ID, match.Priority); err != nil {
+			if stderrors.Is(err, queue.ErrMatchNotQueued) {
+				s.log.Debug("Escalated match is not in queue",
+					zap.String("match_id", match.ID.String()),
+				)
+				continue
+			}
+			s.log.LogError("Failed to move escalated match to new queue", err,
+				zap.String("match_id", match.ID.String()),
+			)
+		}
+	}
+
+	if len(matches) > 0 {
+		s.log.Info("Escalated priority of stale pending matches",
+			zap.Int("escalated", len(matches)),
+			zap.Duration("max_age", maxAge),
+		)
+	}
+
+	return len(matches), nil
+}
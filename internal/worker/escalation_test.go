@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/queue"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// escalatingMatchRepo bumps old pending matches one step, like EscalateOldPendingMatches:
+// a match ages from its last priority change, or from creation if it never changed
+type escalatingMatchRepo struct {
+	matches []*domain.Match
+	now     time.Time
+	changed map[uuid.UUID]time.Time
+}
+
+func (r *escalatingMatchRepo) EscalateOldPendingMatches(_ context.Context, maxAge time.Duration, batchSize int) ([]*domain.Match, error) {
+	var escalated []*domain.Match
+	for _, match := range r.matches {
+		if len(escalated) == batchSize {
+			break
+		}
+		since, ok := r.changed[match.ID]
+		if !ok {
+			since = match.CreatedAt
+		}
+		if match.Status != domain.MatchPending || match.IsPractice || !since.Before(r.now.Add(-maxAge)) {
+			continue
+		}
+		switch match.Priority {
+		case domain.PriorityLow:
+			match.Priority = domain.PriorityMedium
+		case domain.PriorityMedium:
+			match.Priority = domain.PriorityHigh
+		default:
+			continue
+		}
+		r.changed[match.ID] = r.now
+		updated := *match
+		escalated = append(escalated, &updated)
+	}
+	return escalated, nil
+}
+
+// fakeEscalationQueue records moves and reports matches missing from the queue
+type fakeEscalationQueue struct {
+	moved    map[uuid.UUID]domain.MatchPriority
+	notFound map[uuid.UUID]bool
+}
+
+func (q *fakeEscalationQueue) Reprioritize(_ context.Context, matchID uuid.UUID, newPriority domain.MatchPriority) error {
+	if q.notFound[matchID] {
+		return queue.ErrMatchNotQueued
+	}
+	q.moved[matchID] = newPriority
+	return nil
+}
+
+func TestPriorityEscalationService_EscalateStaleMatches(t *testing.T) {
+	now := time.Now()
+	pending := func(priority domain.MatchPriority, age time.Duration) *domain.Match {
+		match := testMatch()
+		match.Priority = priority
+		match.CreatedAt = now.Add(-age)
+		return match
+	}
+
+	low := pending(domain.PriorityLow, time.Hour)
+	medium := pending(domain.PriorityMedium, time.Hour)
+	high := pending(domain.PriorityHigh, time.Hour)
+	fresh := pending(domain.PriorityLow, time.Minute)
+	practice := pending(domain.PriorityLow, time.Hour)
+	practice.IsPractice = true
+	taken := pending(domain.PriorityLow, time.Hour) // already picked by a worker
+
+	repo := &escalatingMatchRepo{
+		matches: []*domain.Match{low, medium, high, fresh, practice, taken},
+		now:     now,
+		changed: map[uuid.UUID]time.Time{},
+	}
+	q := &fakeEscalationQueue{
+		moved:    map[uuid.UUID]domain.MatchPriority{},
+		notFound: map[uuid.UUID]bool{taken.ID: true},
+	}
+
+	m := testMetrics()
+	toMedium := testutil.ToFloat64(m.Escalations.WithLabelValues("medium"))
+	toHigh := testutil.ToFloat64(m.Escalations.WithLabelValues("high"))
+
+	service := NewPriorityEscalationService(repo, q, testLogger(), PriorityEscalationConfig{})
+	service.SetMetrics(m)
+
+	escalated, err := service.EscalateStaleMatches(context.Background(), 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 3, escalated)
+	assert.Equal(t, map[uuid.UUID]domain.MatchPriority{
+		low.ID:    domain.PriorityMedium,
+		medium.ID: domain.PriorityHigh,
+	}, q.moved, "missing match keeps its new priority without a queue move")
+	assert.Equal(t, domain.PriorityHigh, high.Priority, "high is the ceiling")
+	assert.Equal(t, domain.PriorityLow, fresh.Priority)
+	assert.Equal(t, domain.PriorityLow, practice.Priority)
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.Escalations.WithLabelValues("medium"))-toMedium)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.Escalations.WithLabelValues("high"))-toHigh)
+
+	// The next tick right away: the raised matches have not waited at their new priority yet
+	repo.now = now.Add(5 * time.Minute)
+	escalated, err = service.EscalateStaleMatches(context.Background(), 30*time.Minute)
+	require.NoError(t, err)
+	assert.Zero(t, escalated)
+	assert.Equal(t, domain.PriorityMedium, low.Priority)
+
+	// A full max age after the first step: medium -> high for the matches raised from low,
+	// and the fresh match has become stale in the meantime
+	repo.now = now.Add(31 * time.Minute)
+	escalated, err = service.EscalateStaleMatches(context.Background(), 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 3, escalated)
+	assert.Equal(t, domain.PriorityHigh, q.moved[low.ID])
+	assert.Equal(t, domain.PriorityHigh, taken.Priority)
+	assert.Equal(t, domain.PriorityMedium, fresh.Priority)
+
+	// The fresh match waits a full max age at medium again
+	repo.now = now.Add(time.Hour)
+	escalated, err = service.EscalateStaleMatches(context.Background(), 30*time.Minute)
+	require.NoError(t, err)
+	assert.Zero(t, escalated)
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GameWindowRepository интерфейс для получения окна времени игры турнира
+type GameWindowRepository interface {
+	GetGameWindow(ctx context.Context, tournamentID uuid.UUID, gameType string) (*domain.TournamentGame, error)
+}
+
+// SetGameWindows включает проверку окон игр: матч игры вне её окна не выполняется.
+// Без неё матчи выполняются в любое время
+func (p *Pool) SetGameWindows(windows GameWindowRepository) {
+	p.windows = windows
+}
+
+// deferOutsideWindow откладывает матч, окно игры которого сейчас закрыто.
+// До открытия окна матч ждёт в очереди запланированных, после закрытия остаётся
+// в pending до следующего окна. Возвращает true, если матч отложен
+func (p *Pool) deferOutsideWindow(workerID int32, match *domain.Match) bool {
+	if p.windows == nil || match.WindowOverride {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, requeueTimeout)
+	defer cancel()
+
+	tg, err := p.windows.GetGameWindow(ctx, match.TournamentID, match.GameType)
+	if err != nil {
+		// Без связи турнира с игрой окна нет; при ошибке матч выполняется как раньше
+		if !errors.IsNotFound(err) {
+			p.log.LogError("Failed to get game window", err,
+				zap.String("match_id", match.ID.String()),
+			)
+		}
+		return false
+	}
+
+	now := time.Now()
+	if tg.WindowOpen(now) {
+		return false
+	}
+
+	if now.Before(*tg.WindowStart) {
+		match.ScheduledAt = tg.WindowStart
+		if err := p.queue.Enqueue(ctx, match); err != nil {
+			// Матч остаётся в pending: его поставит в очередь запуск окна
+			p.log.LogError("Failed to defer match until game window", err,
+				zap.Int32("worker_id", workerID),
+				zap.String("match_id", match.ID.String()),
+			)
+		}
+		return true
+	}
+
+	p.log.Info("Game window is closed, match waits for the next window",
+		zap.Int32("worker_id", workerID),
+		zap.String("match_id", match.ID.String()),
+		zap.String("game_type", match.GameType),
+		zap.Time("window_end", *tg.WindowEnd),
+	)
+	return true
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/bmstu-itstech/tjudge/internal/config"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/executor"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
 	"github.com/bmstu-itstech/tjudge/pkg/metrics"
 	"go.uber.org/zap"
@@ -16,12 +17,15 @@ import (
 // QueueManager интерфейс для работы с очередями
 type QueueManager interface {
 	Dequeue(ctx context.Context) (*domain.Match, error)
+	DequeueBatch(ctx context.Context, limit int) ([]*domain.Match, error)
+	Enqueue(ctx context.Context, match *domain.Match) error
 	GetTotalQueueSize(ctx context.Context) (int64, error)
 }
 
 // MatchProcessor интерфейс для обработки матчей
 type MatchProcessor interface {
 	Process(ctx context.Context, match *domain.Match) error
+	ProcessBatch(ctx context.Context, matches []*domain.Match) []MatchError
 }
 
 // Pool - пул воркеров для обработки матчей
@@ -31,13 +35,25 @@ type Pool struct {
 	processor        MatchProcessor
 	log              *logger.Logger
 	metrics          *metrics.Metrics
-	ctx              context.Context
+	ctx              context.Context // отменяется при остановке: воркеры не берут новые матчи
 	cancel           context.CancelFunc
+	runCtx           context.Context // отменяется по истечении DrainTimeout: прерывает текущие матчи
+	abort            context.CancelCauseFunc
 	wg               sync.WaitGroup
 	activeWorkers    atomic.Int32
 	totalWorkers     atomic.Int32
 	matchesProcessed atomic.Int64
 	matchesFailed    atomic.Int64
+
+	// Пауза при недоступности Docker daemon
+	prober        DockerProber
+	outageMu      sync.Mutex
+	outage        chan struct{} // закрывается при восстановлении daemon, nil - daemon доступен
+	probeDelay    time.Duration
+	probeMaxDelay time.Duration
+
+	// Окна времени игр турниров
+	windows GameWindowRepository
 }
 
 // NewPool создаёт новый пул воркеров
@@ -49,6 +65,7 @@ func NewPool(
 	m *metrics.Metrics,
 ) *Pool {
 	ctx, cancel := context.WithCancel(context.Background())
+	runCtx, abort := context.WithCancelCause(context.Background())
 
 	return &Pool{
 		config:    cfg,
@@ -58,6 +75,11 @@ func NewPool(
 		metrics:   m,
 		ctx:       ctx,
 		cancel:    cancel,
+		runCtx:    runCtx,
+		abort:     abort,
+
+		probeDelay:    dockerProbeInitialDelay,
+		probeMaxDelay: dockerProbeMaxDelay,
 	}
 }
 
@@ -68,6 +90,8 @@ func (p *Pool) Start() {
 		zap.Int("max_workers", p.config.MaxWorkers),
 	)
 
+	p.metrics.SetWorkerPoolMax(p.config.MaxWorkers)
+
 	// Запускаем минимальное количество воркеров
 	for i := 0; i < p.config.MinWorkers; i++ {
 		p.spawnWorker()
@@ -84,7 +108,8 @@ func (p *Pool) Start() {
 	)
 }
 
-// Stop останавливает пул воркеров
+// Stop останавливает пул воркеров: новые матчи не берутся, текущие доигрываются
+// не дольше DrainTimeout
 func (p *Pool) Stop() {
 	p.log.Info("Stopping worker pool...")
 
@@ -92,7 +117,8 @@ func (p *Pool) Stop() {
 	p.cancel()
 
 	// Ждём завершения всех воркеров
-	p.wg.Wait()
+	p.drain()
+	p.abort(context.Canceled)
 
 	p.log.Info("Worker pool stopped",
 		zap.Int64("matches_processed", p.matchesProcessed.Load()),
@@ -127,10 +153,20 @@ func (p *Pool) spawnWorker() {
 
 // processNext обрабатывает следующий матч из очереди
 func (p *Pool) processNext(workerID int32) {
+	// Пока Docker daemon недоступен, матчи из очереди не берём
+	if !p.waitForDocker() {
+		return
+	}
+
 	// Увеличиваем счётчик активных воркеров
 	p.activeWorkers.Add(1)
 	defer p.activeWorkers.Add(-1)
 
+	if p.config.BatchSize > 1 {
+		p.processBatch(workerID)
+		return
+	}
+
 	// Получаем матч из очереди
 	ctx, cancel := context.WithTimeout(p.ctx, 5*time.Second)
 	defer cancel()
@@ -148,24 +184,47 @@ func (p *Pool) processNext(workerID int32) {
 		return
 	}
 
+	// Матчи игры вне её окна откладываются, не считаясь обработанными
+	if p.deferOutsideWindow(workerID, match) {
+		return
+	}
+
+	dequeuedAt := time.Now()
+	match.DequeuedAt = &dequeuedAt
+	wait := match.QueueWait(dequeuedAt)
+	p.metrics.RecordQueueWait(string(match.Priority), wait)
+
 	// Обрабатываем матч
 	p.log.Info("Processing match",
 		zap.Int32("worker_id", workerID),
 		zap.String("match_id", match.ID.String()),
 		zap.String("priority", string(match.Priority)),
+		zap.Duration("queue_wait", wait),
 	)
 
 	start := time.Now()
 	p.metrics.RecordMatchStart()
 
 	// Создаём контекст с таймаутом для обработки
-	processCtx, processCancel := context.WithTimeout(p.ctx, p.config.Timeout)
+	// Остановка пула не прерывает матч: его отменяет только истечение DrainTimeout
+	processCtx, processCancel := context.WithTimeout(p.runCtx, p.config.Timeout)
 	defer processCancel()
 
 	// Обрабатываем с retry
 	err = p.processWithRetry(processCtx, match)
 
 	duration := time.Since(start)
+	if executor.IsDockerUnavailable(err) {
+		p.metrics.RecordMatchComplete(match.GameType, "requeued", duration)
+		p.handleDockerOutage(workerID, match, err)
+		return
+	}
+	if err != nil && drainInterrupted(processCtx) {
+		p.metrics.RecordMatchComplete(match.GameType, "requeued", duration)
+		p.requeueInterrupted(workerID, match)
+		return
+	}
+
 	status := "completed"
 	if err != nil {
 		status = "failed"
@@ -201,7 +260,12 @@ func (p *Pool) processWithRetry(ctx context.Context, match *domain.Match) error
 			time.Sleep(p.config.RetryDelay * time.Duration(attempt))
 		}
 
-		err := p.processor.Process(ctx, match)
+		attemptCtx := ctx
+		if attempt < p.config.RetryAttempts {
+			attemptCtx = withRetryPending(ctx)
+		}
+
+		err := p.processor.Process(attemptCtx, match)
 		if err == nil {
 			return nil
 		}
@@ -215,6 +279,16 @@ func (p *Pool) processWithRetry(ctx context.Context, match *domain.Match) error
 			return nil // Возвращаем nil чтобы не считать это ошибкой
 		}
 
+		// Повторять бессмысленно, пока daemon не восстановится
+		if executor.IsDockerUnavailable(err) {
+			return err
+		}
+
+		// Пул останавливается и матч уже возвращён в pending
+		if drainInterrupted(ctx) {
+			return err
+		}
+
 		lastErr = err
 		p.log.LogError("Match processing attempt failed", err,
 			zap.String("match_id", match.ID.String()),
@@ -225,6 +299,21 @@ func (p *Pool) processWithRetry(ctx context.Context, match *domain.Match) error
 	return lastErr
 }
 
+// retryPendingKey ключ контекста попытки, после неудачи которой пул повторит матч
+type retryPendingKey struct{}
+
+// withRetryPending помечает попытку выполнения как не последнюю: процессор не сохраняет
+// ошибку выполнения, и матч остаётся в running до следующей попытки
+func withRetryPending(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryPendingKey{}, true)
+}
+
+// retryPending сообщает, что после ошибки этой попытки пул повторит матч
+func retryPending(ctx context.Context) bool {
+	pending, _ := ctx.Value(retryPendingKey{}).(bool)
+	return pending
+}
+
 // autoScaler автоматически масштабирует количество воркеров
 func (p *Pool) autoScaler() {
 	ticker := time.NewTicker(10 * time.Second)
@@ -327,7 +416,8 @@ type WorkerStats struct {
 	MatchesFailed    int64
 }
 
-// Wait ожидает завершения всех воркеров
+// Wait ожидает завершения всех воркеров. После Stop возвращается сразу:
+// Stop сам ждёт текущие матчи не дольше DrainTimeout
 func (p *Pool) Wait() {
 	p.wg.Wait()
 }
@@ -3,6 +3,7 @@ package worker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -10,11 +11,15 @@ import (
 
 	"github.com/bmstu-itstech/tjudge/internal/config"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/executor"
+	apperrors "github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
 	"github.com/bmstu-itstech/tjudge/pkg/metrics"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -46,6 +51,22 @@ func (m *MockQueueManager) Dequeue(ctx context.Context) (*domain.Match, error) {
 	return args.Get(0).(*domain.Match), args.Error(1)
 }
 
+func (m *MockQueueManager) DequeueBatch(ctx context.Context, limit int) ([]*domain.Match, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Match), args.Error(1)
+}
+
+func (m *MockQueueManager) Enqueue(ctx context.Context, match *domain.Match) error {
+	args := m.Called(ctx, match)
+	return args.Error(0)
+}
+
 func (m *MockQueueManager) GetTotalQueueSize(ctx context.Context) (int64, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
@@ -78,6 +99,17 @@ func (m *MockMatchProcessor) Process(ctx context.Context, match *domain.Match) e
 	return args.Error(0)
 }
 
+func (m *MockMatchProcessor) ProcessBatch(ctx context.Context, matches []*domain.Match) []MatchError {
+	args := m.Called(ctx, matches)
+	var failed []MatchError
+	if args.Get(0) != nil {
+		failed = args.Get(0).([]MatchError)
+	}
+	m.processedMatches.Add(int32(len(matches) - len(failed)))
+	m.failCount.Add(int32(len(failed)))
+	return failed
+}
+
 func (m *MockMatchProcessor) GetProcessedCount() int32 {
 	return m.processedMatches.Load()
 }
@@ -233,6 +265,122 @@ func TestPool_RetryOnFailure(t *testing.T) {
 	assert.Equal(t, int32(1), processor.GetProcessedCount())
 }
 
+// crashingExecutor fails the first failures executions and plays the match afterwards
+type crashingExecutor struct {
+	failures int32
+	calls    atomic.Int32
+}
+
+func (e *crashingExecutor) Execute(_ context.Context, match *domain.Match, _, _ string, _ executor.RunOptions) (*domain.MatchResult, error) {
+	if e.calls.Add(1) <= e.failures {
+		return nil, errors.New("container crashed")
+	}
+	return &domain.MatchResult{MatchID: match.ID, Winner: 1, Score1: 10}, nil
+}
+
+func TestPool_RetryAfterExecutionFailure(t *testing.T) {
+	cfg := testConfig()
+	cfg.MinWorkers = 1
+	cfg.MaxWorkers = 1
+	cfg.RetryAttempts = 3
+	cfg.RetryDelay = 10 * time.Millisecond
+
+	match := testMatch()
+	match.TournamentID = uuid.New()
+	match.Program1ID = uuid.New()
+	match.Program2ID = uuid.New()
+
+	repo := newConditionalMatchRepo(match)
+	ratings := &countingRatingService{}
+	exec := &crashingExecutor{failures: 1}
+
+	m := testMetrics()
+	duplicates := testutil.ToFloat64(m.DuplicateResults.WithLabelValues(match.GameType))
+
+	processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, ratings, exec, nil, testLogger())
+	processor.SetMetrics(m)
+
+	queue := NewMockQueueManager()
+	queue.On("Dequeue", mock.Anything).Return(match, nil).Once()
+	queue.On("Dequeue", mock.Anything).Return(nil, nil)
+	queue.On("GetTotalQueueSize", mock.Anything).Return(int64(0), nil)
+
+	pool := NewPool(cfg, queue, processor, testLogger(), m)
+	pool.Start()
+	require.Eventually(t, func() bool { return pool.GetMatchesProcessed() == 1 }, 2*time.Second, 10*time.Millisecond)
+	pool.Stop()
+
+	// The failed first attempt must not block the retry from running the match
+	assert.Equal(t, int32(2), exec.calls.Load())
+	assert.Equal(t, domain.MatchCompleted, repo.status[match.ID])
+	assert.Zero(t, repo.results[match.ID].ErrorCode)
+	assert.Equal(t, int32(1), ratings.calls.Load())
+	assert.Equal(t, duplicates, testutil.ToFloat64(m.DuplicateResults.WithLabelValues(match.GameType)))
+}
+
+func TestPool_FailureSavedAfterLastAttempt(t *testing.T) {
+	cfg := testConfig()
+	cfg.MinWorkers = 1
+	cfg.MaxWorkers = 1
+	cfg.RetryAttempts = 2
+	cfg.RetryDelay = 10 * time.Millisecond
+
+	match := testMatch()
+	repo := newConditionalMatchRepo(match)
+
+	// Every attempt crashes: only the last one records the failure
+	exec := &crashingExecutor{failures: int32(cfg.RetryAttempts)}
+	processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, exec, nil, testLogger())
+
+	queue := NewMockQueueManager()
+	queue.On("Dequeue", mock.Anything).Return(match, nil).Once()
+	queue.On("Dequeue", mock.Anything).Return(nil, nil)
+	queue.On("GetTotalQueueSize", mock.Anything).Return(int64(0), nil)
+
+	pool := NewPool(cfg, queue, processor, testLogger(), testMetrics())
+	pool.Start()
+	require.Eventually(t, func() bool { return pool.GetStats().MatchesFailed == 1 }, 2*time.Second, 10*time.Millisecond)
+	pool.Stop()
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Equal(t, domain.MatchFailed, repo.status[match.ID])
+}
+
+func TestPool_ProcessBatch(t *testing.T) {
+	cfg := testConfig()
+	cfg.MinWorkers = 1
+	cfg.MaxWorkers = 1
+	cfg.BatchSize = 3
+
+	queue := NewMockQueueManager()
+	processor := NewMockMatchProcessor()
+
+	pool := NewPool(cfg, queue, processor, testLogger(), testMetrics())
+
+	batch := []*domain.Match{testMatch(), testMatch(), testMatch()}
+
+	queue.On("DequeueBatch", mock.Anything, 3).Return(batch, nil).Once()
+	queue.On("DequeueBatch", mock.Anything, 3).Return(nil, nil)
+	queue.On("GetTotalQueueSize", mock.Anything).Return(int64(0), nil)
+
+	// One element fails, the others are still counted as processed
+	processor.On("ProcessBatch", mock.Anything, batch).
+		Return([]MatchError{{MatchID: batch[1].ID, Err: errors.New("runner crashed")}})
+
+	pool.Start()
+	time.Sleep(500 * time.Millisecond)
+	pool.Stop()
+
+	processor.AssertNotCalled(t, "Process", mock.Anything, mock.Anything)
+	stats := pool.GetStats()
+	assert.Equal(t, int64(2), stats.MatchesProcessed)
+	assert.Equal(t, int64(1), stats.MatchesFailed)
+	for _, match := range batch {
+		assert.NotNil(t, match.DequeuedAt)
+	}
+}
+
 func TestPool_GetStats(t *testing.T) {
 	cfg := testConfig()
 	queue := NewMockQueueManager()
@@ -336,6 +484,79 @@ func TestPool_GracefulShutdown(t *testing.T) {
 	assert.Equal(t, int32(1), processor.GetProcessedCount())
 }
 
+func TestPool_DrainFinishesRunningMatch(t *testing.T) {
+	cfg := testConfig()
+	cfg.MinWorkers = 1
+	cfg.MaxWorkers = 1
+	cfg.DrainTimeout = 5 * time.Second
+
+	queue := NewMockQueueManager()
+	processor := NewMockMatchProcessor()
+	pool := NewPool(cfg, queue, processor, testLogger(), testMetrics())
+
+	match := testMatch()
+	started := make(chan struct{})
+	queue.On("Dequeue", mock.Anything).Return(match, nil).Once()
+	queue.On("Dequeue", mock.Anything).Return(nil, nil)
+	queue.On("GetTotalQueueSize", mock.Anything).Return(int64(0), nil)
+
+	// The match keeps running after Stop and must not see its context cancelled
+	var cancelled atomic.Bool
+	processor.On("Process", mock.Anything, match).Run(func(args mock.Arguments) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		cancelled.Store(args.Get(0).(context.Context).Err() != nil)
+	}).Return(nil).Once()
+
+	pool.Start()
+	<-started
+	pool.Stop()
+
+	assert.False(t, cancelled.Load(), "stopping the pool must not interrupt a running match")
+	assert.Equal(t, int32(1), processor.GetProcessedCount())
+	queue.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+}
+
+func TestPool_DrainTimeoutRequeuesMatch(t *testing.T) {
+	cfg := testConfig()
+	cfg.MinWorkers = 1
+	cfg.MaxWorkers = 1
+	cfg.DrainTimeout = 50 * time.Millisecond
+
+	queue := NewMockQueueManager()
+	processor := NewMockMatchProcessor()
+	m := testMetrics()
+	pool := NewPool(cfg, queue, processor, testLogger(), m)
+
+	match := testMatch()
+	started := make(chan struct{})
+	queue.On("Dequeue", mock.Anything).Return(match, nil).Once()
+	queue.On("Dequeue", mock.Anything).Return(nil, nil)
+	queue.On("GetTotalQueueSize", mock.Anything).Return(int64(0), nil)
+	queue.On("Enqueue", mock.Anything, match).Return(nil).Once()
+
+	// The match outlives the drain timeout and is interrupted
+	var interrupted atomic.Bool
+	processor.On("Process", mock.Anything, match).Run(func(args mock.Arguments) {
+		close(started)
+		ctx := args.Get(0).(context.Context)
+		<-ctx.Done()
+		interrupted.Store(drainInterrupted(ctx))
+	}).Return(errors.New("failed to execute match: context canceled")).Once()
+
+	timeouts := testutil.ToFloat64(m.DrainTimeouts)
+
+	pool.Start()
+	<-started
+	pool.Stop()
+
+	assert.True(t, interrupted.Load())
+	queue.AssertCalled(t, "Enqueue", mock.Anything, match)
+	processor.AssertNumberOfCalls(t, "Process", 1)
+	assert.Equal(t, int64(0), pool.GetStats().MatchesFailed, "an interrupted match is not failed")
+	assert.Equal(t, timeouts+1, testutil.ToFloat64(m.DrainTimeouts))
+}
+
 func TestPool_FailedMatchCounting(t *testing.T) {
 	cfg := testConfig()
 	cfg.MinWorkers = 1
@@ -431,3 +652,130 @@ func TestPool_GetMatchesProcessed(t *testing.T) {
 
 	assert.Equal(t, int64(5), pool.GetMatchesProcessed())
 }
+
+// flakyDockerProber reports the daemon as down until it is released
+type flakyDockerProber struct {
+	healthy atomic.Bool
+	pings   atomic.Int32
+}
+
+func (p *flakyDockerProber) Ping(ctx context.Context) error {
+	p.pings.Add(1)
+	if !p.healthy.Load() {
+		return executor.ErrDockerUnavailable
+	}
+	return nil
+}
+
+func TestPool_DockerOutage(t *testing.T) {
+	cfg := testConfig()
+	cfg.MinWorkers = 1
+	cfg.MaxWorkers = 1
+	cfg.RetryAttempts = 3
+
+	queue := NewMockQueueManager()
+	processor := NewMockMatchProcessor()
+	m := testMetrics()
+	prober := &flakyDockerProber{}
+
+	pool := NewPool(cfg, queue, processor, testLogger(), m)
+	pool.SetDockerProber(prober)
+	pool.probeDelay = 10 * time.Millisecond
+	pool.probeMaxDelay = 20 * time.Millisecond
+
+	match := testMatch()
+	var dequeues atomic.Int32
+	queue.On("Dequeue", mock.Anything).Run(func(mock.Arguments) { dequeues.Add(1) }).Return(match, nil).Once()
+	queue.On("Dequeue", mock.Anything).Run(func(mock.Arguments) { dequeues.Add(1) }).Return(nil, nil)
+	queue.On("GetTotalQueueSize", mock.Anything).Return(int64(0), nil)
+	queue.On("Enqueue", mock.Anything, match).Return(nil).Once()
+
+	unavailable := fmt.Errorf("failed to execute match: %w", executor.ErrDockerUnavailable)
+	processor.On("Process", mock.Anything, match).Return(unavailable).Once()
+
+	pool.Start()
+	defer pool.Stop()
+
+	// The pool pauses after the first failure and keeps probing the daemon
+	require.Eventually(t, func() bool { return prober.pings.Load() >= 2 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.DockerUnavailable))
+	paused := dequeues.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, paused, dequeues.Load(), "no matches must be dequeued during the outage")
+
+	// The match is requeued once, without retries and without counting as failed
+	queue.AssertCalled(t, "Enqueue", mock.Anything, match)
+	processor.AssertNumberOfCalls(t, "Process", 1)
+	assert.Equal(t, int64(0), pool.GetStats().MatchesFailed)
+
+	// Once the daemon is back the pool resumes dequeueing
+	prober.healthy.Store(true)
+	require.Eventually(t, func() bool { return dequeues.Load() > paused }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.DockerUnavailable))
+}
+
+// staticGameWindows returns the same tournament game for any match
+type staticGameWindows struct {
+	tg *domain.TournamentGame
+}
+
+func (w staticGameWindows) GetGameWindow(_ context.Context, _ uuid.UUID, _ string) (*domain.TournamentGame, error) {
+	if w.tg == nil {
+		return nil, apperrors.ErrNotFound.WithMessage("tournament game not found")
+	}
+	return w.tg, nil
+}
+
+func TestPool_DeferOutsideWindow(t *testing.T) {
+	now := time.Now()
+	window := func(start, end time.Time) staticGameWindows {
+		return staticGameWindows{tg: &domain.TournamentGame{WindowStart: &start, WindowEnd: &end}}
+	}
+
+	t.Run("game without window", func(t *testing.T) {
+		pool := NewPool(testConfig(), NewMockQueueManager(), NewMockMatchProcessor(), testLogger(), testMetrics())
+		pool.SetGameWindows(staticGameWindows{})
+
+		assert.False(t, pool.deferOutsideWindow(1, testMatch()))
+	})
+
+	t.Run("open window", func(t *testing.T) {
+		pool := NewPool(testConfig(), NewMockQueueManager(), NewMockMatchProcessor(), testLogger(), testMetrics())
+		pool.SetGameWindows(window(now.Add(-time.Hour), now.Add(time.Hour)))
+
+		assert.False(t, pool.deferOutsideWindow(1, testMatch()))
+	})
+
+	t.Run("match waits for the window start", func(t *testing.T) {
+		queue := NewMockQueueManager()
+		start := now.Add(time.Hour)
+		pool := NewPool(testConfig(), queue, NewMockMatchProcessor(), testLogger(), testMetrics())
+		pool.SetGameWindows(window(start, start.Add(time.Hour)))
+
+		match := testMatch()
+		queue.On("Enqueue", mock.Anything, match).Return(nil).Once()
+
+		assert.True(t, pool.deferOutsideWindow(1, match))
+		queue.AssertExpectations(t)
+		require.NotNil(t, match.ScheduledAt)
+		assert.True(t, match.ScheduledAt.Equal(start))
+	})
+
+	t.Run("match after the window stays pending", func(t *testing.T) {
+		queue := NewMockQueueManager()
+		pool := NewPool(testConfig(), queue, NewMockMatchProcessor(), testLogger(), testMetrics())
+		pool.SetGameWindows(window(now.Add(-2*time.Hour), now.Add(-time.Hour)))
+
+		assert.True(t, pool.deferOutsideWindow(1, testMatch()))
+		queue.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("admin override runs outside the window", func(t *testing.T) {
+		pool := NewPool(testConfig(), NewMockQueueManager(), NewMockMatchProcessor(), testLogger(), testMetrics())
+		pool.SetGameWindows(window(now.Add(-2*time.Hour), now.Add(-time.Hour)))
+
+		match := testMatch()
+		match.WindowOverride = true
+		assert.False(t, pool.deferOutsideWindow(1, match))
+	})
+}
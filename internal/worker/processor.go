@@ -4,12 +4,18 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/cache"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/executor"
+	"github.com/bmstu-itstech/tjudge/internal/websocket"
 	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/bmstu-itstech/tjudge/pkg/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +27,7 @@ var ErrMatchNotFound = fmt.Errorf("match not found in database")
 type MatchRepository interface {
 	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.MatchStatus) error
 	UpdateResult(ctx context.Context, id uuid.UUID, result *domain.MatchResult) error
+	BatchUpdateResults(ctx context.Context, results map[uuid.UUID]*domain.MatchResult) ([]uuid.UUID, error)
 }
 
 // RatingRepository интерфейс для работы с рейтингами
@@ -31,16 +38,65 @@ type RatingRepository interface {
 // RatingService интерфейс для обновления рейтингов
 type RatingService interface {
 	ProcessMatchResult(ctx context.Context, match *domain.Match, rating1, rating2 int) error
+	ProcessReferenceMatchResult(ctx context.Context, match *domain.Match, rating1, rating2, reference int) error
 }
 
 // Executor интерфейс для выполнения матчей
 type Executor interface {
-	Execute(ctx context.Context, match *domain.Match, program1Path, program2Path string) (*domain.MatchResult, error)
+	Execute(ctx context.Context, match *domain.Match, program1Path, program2Path string, opts executor.RunOptions) (*domain.MatchResult, error)
+}
+
+// GameRepository интерфейс для получения настроек игры
+type GameRepository interface {
+	GetByName(ctx context.Context, name string) (*domain.Game, error)
 }
 
 // ProgramRepository интерфейс для работы с программами
 type ProgramRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Program, error)
+	SetErrorMessage(ctx context.Context, id uuid.UUID, message string) error
+	SetValidationResult(ctx context.Context, id uuid.UUID, status domain.ProgramValidationStatus, message string) error
+}
+
+// ProgramBuilder подготавливает исполняемый файл программы (компилирует при необходимости)
+type ProgramBuilder interface {
+	Build(ctx context.Context, program *domain.Program) (string, error)
+}
+
+// BracketAdvancer продвигает победителя матча по сетке турнира на выбывание
+type BracketAdvancer interface {
+	Advance(ctx context.Context, match *domain.Match, result *domain.MatchResult) error
+}
+
+// Notifier интерфейс уведомлений команд о результатах их программ
+type Notifier interface {
+	ProgramMatchesCompleted(ctx context.Context, program *domain.Program) error
+	CompileFailed(ctx context.Context, program *domain.Program, message string) error
+}
+
+// RoundTracker завершает раунд игры, когда у неё не осталось несыгранных матчей
+type RoundTracker interface {
+	FinishRoundIfIdle(ctx context.Context, tournamentID uuid.UUID, gameType string) (*domain.TournamentGame, error)
+}
+
+// RoundNotifier интерфейс уведомлений о завершении раунда игры
+type RoundNotifier interface {
+	RoundCompleted(ctx context.Context, tournamentID uuid.UUID, gameType string) error
+}
+
+// MatchTimingStore сохраняет длительности этапов обработки матча
+type MatchTimingStore interface {
+	SaveTiming(ctx context.Context, id uuid.UUID, timing *domain.MatchTiming) error
+}
+
+// TournamentEventPublisher публикует события турнира для подписчиков websocket
+type TournamentEventPublisher interface {
+	PublishTournamentEvent(ctx context.Context, tournamentID uuid.UUID, eventType string, payload interface{}) error
+}
+
+// LiveFeed трансляция идущих матчей зрителям
+type LiveFeed interface {
+	PublishMatchEvent(ctx context.Context, matchID uuid.UUID, event *domain.MatchEvent) error
 }
 
 // Processor обрабатывает матчи
@@ -50,7 +106,19 @@ type Processor struct {
 	programRepo   ProgramRepository
 	ratingService RatingService
 	executor      Executor
+	gameRepo      GameRepository
+	builder       ProgramBuilder
+	bracket       BracketAdvancer
+	notifier      Notifier
+	rounds        RoundTracker
+	roundNotifier RoundNotifier
+	events        TournamentEventPublisher
+	timings       MatchTimingStore
+	live          LiveFeed
+	retry         RetryPolicy
+	results       *ResultBuffer
 	matchCache    *cache.MatchCache
+	metrics       *metrics.Metrics
 	log           *logger.Logger
 }
 
@@ -75,13 +143,227 @@ func NewProcessor(
 	}
 }
 
+// SetGameRepository устанавливает репозиторий игр для выбора профиля изоляции
+// Без него используется профиль из конфигурации executor
+func (p *Processor) SetGameRepository(gameRepo GameRepository) {
+	p.gameRepo = gameRepo
+}
+
+// SetBuilder устанавливает сборщик программ на компилируемых языках
+// Без него программы запускаются как есть
+func (p *Processor) SetBuilder(builder ProgramBuilder) {
+	p.builder = builder
+}
+
+// SetBracketAdvancer устанавливает продвижение по сетке после матчей турниров на выбывание
+func (p *Processor) SetBracketAdvancer(bracket BracketAdvancer) {
+	p.bracket = bracket
+}
+
+// SetNotifier включает уведомления команд о сыгранных матчах и ошибках компиляции
+func (p *Processor) SetNotifier(notifier Notifier) {
+	p.notifier = notifier
+}
+
+// SetRoundTracker включает автоматическое завершение раунда игры после её последнего матча
+func (p *Processor) SetRoundTracker(rounds RoundTracker) {
+	p.rounds = rounds
+}
+
+// SetRoundNotifier включает уведомления о завершении раунда игры (требует SetRoundTracker)
+func (p *Processor) SetRoundNotifier(notifier RoundNotifier) {
+	p.roundNotifier = notifier
+}
+
+// SetEventPublisher включает публикацию событий турнира (автозавершение раундов)
+func (p *Processor) SetEventPublisher(events TournamentEventPublisher) {
+	p.events = events
+}
+
+// SetTimingStore включает сохранение длительностей этапов в матче.
+// Без него трасса матча только пишется в лог и метрики
+func (p *Processor) SetTimingStore(timings MatchTimingStore) {
+	p.timings = timings
+}
+
+// SetLiveFeed включает трансляцию вывода матчей во время выполнения
+func (p *Processor) SetLiveFeed(live LiveFeed) {
+	p.live = live
+}
+
+// SetResultBuffer включает пакетное сохранение результатов матчей
+// Без него каждый результат записывается отдельной транзакцией
+func (p *Processor) SetResultBuffer(results *ResultBuffer) {
+	p.results = results
+}
+
+// SetMetrics устанавливает метрики для учёта повторных выполнений, OOM и этапов обработки матчей
+func (p *Processor) SetMetrics(m *metrics.Metrics) {
+	p.metrics = m
+}
+
+// saveResult сохраняет результат матча через буфер пакетов, если он включён
+func (p *Processor) saveResult(ctx context.Context, matchID uuid.UUID, result *domain.MatchResult) error {
+	if p.results != nil {
+		return p.results.Store(ctx, matchID, result)
+	}
+	return p.matchRepo.UpdateResult(ctx, matchID, result)
+}
+
+// skipDuplicate логирует повторное выполнение уже завершённого матча
+func (p *Processor) skipDuplicate(match *domain.Match, err error) {
+	p.log.Warn("Match result already recorded, skipping duplicate execution",
+		zap.String("match_id", match.ID.String()),
+		zap.Error(err),
+	)
+	if p.metrics != nil {
+		p.metrics.RecordDuplicateResult(match.GameType)
+	}
+}
+
+// recordTrace пишет трассу выполнения матча одной записью лога и в гистограммы этапов
+func (p *Processor) recordTrace(match *domain.Match, trace *domain.MatchTrace) {
+	phases := trace.Phases()
+
+	fields := make([]zap.Field, 0, len(phases)+3)
+	fields = append(fields,
+		zap.String("match_id", match.ID.String()),
+		zap.String("game_type", match.GameType),
+		zap.Any("trace", trace),
+	)
+	for _, phase := range phases {
+		fields = append(fields, zap.Duration(phase.Name, phase.Duration))
+		if p.metrics != nil {
+			p.metrics.RecordMatchPhase(phase.Name, match.GameType, phase.Duration)
+		}
+	}
+
+	p.log.Info("Match trace", fields...)
+}
+
+// saveTiming сохраняет в матче длительности ожидания в очереди и этапов обработки.
+// Сохраняется до кэширования результата: кэш деталей матча не останется без них
+func (p *Processor) saveTiming(ctx context.Context, match *domain.Match, trace *domain.MatchTrace) {
+	if p.timings == nil {
+		return
+	}
+	timing := trace.Timing(match.QueueWait(trace.DequeuedAt))
+	if err := p.timings.SaveTiming(ctx, match.ID, timing); err != nil {
+		p.log.LogError("Failed to save match timing", err,
+			zap.String("match_id", match.ID.String()),
+		)
+	}
+}
+
+// sandboxProfile возвращает профиль изоляции игры матча (пусто - профиль по умолчанию)
+func (p *Processor) sandboxProfile(ctx context.Context, gameType string) domain.SandboxProfile {
+	if p.gameRepo == nil {
+		return ""
+	}
+
+	game, err := p.gameRepo.GetByName(ctx, gameType)
+	if err != nil {
+		p.log.Warn("Failed to get game sandbox profile, using default",
+			zap.String("game_type", gameType),
+			zap.Error(err),
+		)
+		return ""
+	}
+
+	return game.SandboxProfile
+}
+
 // Process обрабатывает матч
-func (p *Processor) Process(ctx context.Context, match *domain.Match) error {
+func (p *Processor) Process(ctx context.Context, match *domain.Match) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "worker.Process",
+		attribute.String("match.id", match.ID.String()),
+		attribute.String("tournament.id", match.TournamentID.String()),
+		attribute.String("game.type", match.GameType),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	run, err := p.prepare(ctx, match)
+	if err != nil && drainInterrupted(ctx) {
+		return p.releaseMatch(ctx, match, err)
+	}
+	if err != nil || run == nil {
+		return err
+	}
+
+	// Выполняем матч через executor
+	result, err := p.execute(ctx, run)
+	if err != nil {
+		// Daemon недоступен или пул остановлен: матч не сыгран и не должен считаться проваленным
+		if executor.IsDockerUnavailable(err) || drainInterrupted(ctx) {
+			return p.releaseMatch(ctx, match, err)
+		}
+
+		// Пул повторит матч: ошибка сохраняется только после последней попытки,
+		// иначе статус failed не даст следующей попытке перевести матч в running
+		if retryPending(ctx) {
+			return fmt.Errorf("failed to execute match: %w", err)
+		}
+
+		// Сохраняем ошибку в БД
+		failure := executionFailure(match, err)
+		updErr := p.saveResult(ctx, match.ID, failure)
+		if errors.IsConflict(updErr) {
+			// Результат уже записан другим воркером - ошибка этого выполнения не важна
+			p.skipDuplicate(match, updErr)
+			return nil
+		}
+		if updErr == nil {
+			p.publishEnd(ctx, match)
+			p.recordValidation(ctx, match, failure)
+			p.finishRound(ctx, match)
+		}
+		return fmt.Errorf("failed to execute match: %w", err)
+	}
+
+	p.observeResult(run, result)
+
+	// Результат сыгранного матча сохраняется и при остановке пула
+	ctx = context.WithoutCancel(ctx)
+
+	// Обновляем результат в БД
+	if err := p.saveResult(ctx, match.ID, result); err != nil {
+		// Повторное выполнение: результат уже сохранён, рейтинги уже обновлены
+		if errors.IsConflict(err) {
+			p.skipDuplicate(match, err)
+			return nil
+		}
+		return fmt.Errorf("failed to update match result: %w", err)
+	}
+
+	p.completeMatch(ctx, run, result)
+	return nil
+}
+
+// matchRun - подготовленный к выполнению матч
+type matchRun struct {
+	match        *domain.Match
+	program1     *domain.Program
+	program2     *domain.Program
+	program1Path string
+	program2Path string
+	options      executor.RunOptions
+	trace        domain.MatchTrace
+}
+
+// prepare переводит матч в running, получает и компилирует его программы.
+// Возвращает nil без ошибки, если выполнять матч не нужно: его результат уже записан
+// другим воркером либо сохранено поражение из-за ошибки компиляции
+func (p *Processor) prepare(ctx context.Context, match *domain.Match) (*matchRun, error) {
 	p.log.Info("Processing match",
 		zap.String("match_id", match.ID.String()),
 		zap.String("tournament_id", match.TournamentID.String()),
 	)
 
+	run := &matchRun{match: match, trace: domain.MatchTrace{DequeuedAt: time.Now()}}
+	if match.DequeuedAt != nil {
+		run.trace.DequeuedAt = *match.DequeuedAt
+	}
+
 	// Обновляем статус на "running"
 	if err := p.matchRepo.UpdateStatus(ctx, match.ID, domain.MatchRunning); err != nil {
 		// Проверяем, не был ли матч удалён из БД
@@ -89,65 +371,362 @@ func (p *Processor) Process(ctx context.Context, match *domain.Match) error {
 			p.log.Warn("Match not found in database, skipping (likely deleted)",
 				zap.String("match_id", match.ID.String()),
 			)
-			return ErrMatchNotFound
+			return nil, ErrMatchNotFound
 		}
-		return fmt.Errorf("failed to update match status: %w", err)
+		// Матч уже выполнен другим воркером
+		if errors.IsConflict(err) {
+			p.skipDuplicate(match, err)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update match status: %w", err)
 	}
 
 	// Получаем программы
-	program1, err := p.programRepo.GetByID(ctx, match.Program1ID)
+	var err error
+	run.program1, err = p.programRepo.GetByID(ctx, match.Program1ID)
 	if err != nil {
-		return fmt.Errorf("failed to get program1: %w", err)
+		return nil, fmt.Errorf("failed to get program1: %w", err)
 	}
 
-	program2, err := p.programRepo.GetByID(ctx, match.Program2ID)
+	run.program2, err = p.programRepo.GetByID(ctx, match.Program2ID)
 	if err != nil {
-		return fmt.Errorf("failed to get program2: %w", err)
+		return nil, fmt.Errorf("failed to get program2: %w", err)
 	}
 
-	// Выполняем матч через executor
-	result, err := p.executor.Execute(ctx, match, program1.CodePath, program2.CodePath)
+	// Компилируем программы при необходимости
+	var result *domain.MatchResult
+	run.program1Path, run.program2Path, result, err = p.buildPrograms(ctx, match, run.program1, run.program2)
 	if err != nil {
-		// Сохраняем ошибку в БД
-		errorResult := &domain.MatchResult{
-			MatchID:      match.ID,
-			ErrorCode:    1,
-			ErrorMessage: err.Error(),
+		if executor.IsDockerUnavailable(err) {
+			return nil, p.releaseMatch(ctx, match, err)
 		}
-		_ = p.matchRepo.UpdateResult(ctx, match.ID, errorResult)
-		return fmt.Errorf("failed to execute match: %w", err)
+		return nil, err
+	}
+	if result != nil {
+		return nil, p.saveBuildFailure(ctx, match, result, run.program1, run.program2)
 	}
 
-	// Обновляем результат в БД
-	if err := p.matchRepo.UpdateResult(ctx, match.ID, result); err != nil {
-		return fmt.Errorf("failed to update match result: %w", err)
+	run.options = executor.RunOptions{
+		Sandbox:          p.sandboxProfile(ctx, match.GameType),
+		LanguageVersions: [2]string{run.program1.LanguageVersion, run.program2.LanguageVersion},
 	}
+	if p.live != nil {
+		p.publishEvent(ctx, match, &domain.MatchEvent{Type: domain.MatchEventStart})
+		run.options.Output = func(line string) {
+			p.publishEvent(ctx, match, &domain.MatchEvent{Type: domain.MatchEventOutput, Line: line})
+		}
+	}
+	return run, nil
+}
+
+// publishEvent отправляет событие трансляции матча. Трансляция не влияет на результат,
+// поэтому ошибка только логируется
+func (p *Processor) publishEvent(ctx context.Context, match *domain.Match, event *domain.MatchEvent) {
+	if p.live == nil {
+		return
+	}
+	if err := p.live.PublishMatchEvent(ctx, match.ID, event); err != nil {
+		p.log.Warn("Failed to publish match event",
+			zap.String("match_id", match.ID.String()),
+			zap.String("type", string(event.Type)),
+			zap.Error(err),
+		)
+	}
+}
+
+// publishEnd сообщает зрителям, что результат матча сохранён
+func (p *Processor) publishEnd(ctx context.Context, match *domain.Match) {
+	p.publishEvent(ctx, match, &domain.MatchEvent{Type: domain.MatchEventEnd})
+}
+
+// executionFailure - результат матча, который не удалось выполнить.
+// Временный сбой инфраструктуры получает отдельный код, чтобы его не путали с ошибкой программы
+func executionFailure(match *domain.Match, err error) *domain.MatchResult {
+	code := 1
+	if executor.IsTransient(err) {
+		code = domain.MatchErrorInfrastructure
+	}
+	return &domain.MatchResult{
+		MatchID:      match.ID,
+		ErrorCode:    code,
+		ErrorMessage: err.Error(),
+	}
+}
+
+// observeResult учитывает результат выполнения до его сохранения
+func (p *Processor) observeResult(run *matchRun, result *domain.MatchResult) {
+	match := run.match
+	if result.IsOutOfMemory() {
+		p.log.Warn("Match stopped: memory limit exceeded",
+			zap.String("match_id", match.ID.String()),
+			zap.String("game_type", match.GameType),
+			zap.Int("winner", result.Winner),
+		)
+		if p.metrics != nil {
+			p.metrics.RecordOOMKill(match.GameType)
+		}
+	}
+
+	run.trace.SetExecution(result.Timing)
+}
+
+// completeMatch выполняет действия после сохранения результата матча.
+// Результат уже записан, поэтому ошибки только логируются
+func (p *Processor) completeMatch(ctx context.Context, run *matchRun, result *domain.MatchResult) {
+	match := run.match
+	run.trace.ResultPersistedAt = time.Now()
+	p.recordTrace(match, &run.trace)
+	p.saveTiming(ctx, match, &run.trace)
+	p.publishEnd(ctx, match)
 
 	// Кэшируем результат
-	if err := p.matchCache.Set(ctx, match.ID, result); err != nil {
-		p.log.LogError("Failed to cache match result", err)
+	if p.matchCache != nil {
+		if err := p.matchCache.Set(ctx, match.ID, result); err != nil {
+			p.log.LogError("Failed to cache match result", err)
+		}
 	}
 
-	// Если матч успешно завершён, обновляем рейтинги
-	if result.ErrorCode == 0 && result.Winner >= 0 {
-		if err := p.updateRatings(ctx, match, result); err != nil {
+	// Итог проверочного матча сохраняется в программе вместо рейтингов
+	p.recordValidation(ctx, match, result)
+
+	// Если матч успешно завершён, обновляем рейтинги. Тестовые матчи на рейтинги не влияют
+	if result.ErrorCode == 0 && result.Winner >= 0 && !match.IsTest {
+		if err := p.updateRatings(ctx, match, result, run.program1, run.program2); err != nil {
 			p.log.LogError("Failed to update ratings", err,
 				zap.String("match_id", match.ID.String()),
 			)
-			// Не возвращаем ошибку, так как матч уже выполнен
 		}
 	}
 
+	p.advanceBracket(ctx, match, result)
+	p.notifyMatchCompleted(ctx, match, run.program1, run.program2)
+	p.finishRound(ctx, match)
+
 	p.log.Info("Match processed successfully",
 		zap.String("match_id", match.ID.String()),
 		zap.Int("winner", result.Winner),
 	)
+}
+
+// releaseMatch возвращает в статус pending матч, не сыгранный из-за недоступности Docker daemon
+// или остановки пула. Ошибка сохраняет свой класс: пул вернёт матч в очередь
+func (p *Processor) releaseMatch(ctx context.Context, match *domain.Match, err error) error {
+	if updErr := p.matchRepo.UpdateStatus(context.WithoutCancel(ctx), match.ID, domain.MatchPending); updErr != nil {
+		p.log.LogError("Failed to reset match status to pending", updErr,
+			zap.String("match_id", match.ID.String()),
+		)
+	}
+	return fmt.Errorf("failed to execute match: %w", err)
+}
+
+// buildPrograms возвращает пути к исполняемым файлам программ матча.
+// Если программу не удалось скомпилировать, она помечается ошибкой, а вместо путей
+// возвращается результат матча с поражением этой программы
+func (p *Processor) buildPrograms(ctx context.Context, match *domain.Match, program1, program2 *domain.Program) (string, string, *domain.MatchResult, error) {
+	if p.builder == nil {
+		return program1.CodePath, program2.CodePath, nil, nil
+	}
+
+	var failures []string
+	failed := [2]bool{}
+	paths := [2]string{}
+	for i, program := range []*domain.Program{program1, program2} {
+		path, err := p.builder.Build(ctx, program)
+		if err == nil {
+			paths[i] = path
+			continue
+		}
+		if !executor.IsCompileError(err) {
+			return "", "", nil, fmt.Errorf("failed to build program%d: %w", i+1, err)
+		}
+
+		failed[i] = true
+		failures = append(failures, fmt.Sprintf("Программа %d: %s", i+1, err.Error()))
+		p.markProgramError(ctx, program, err)
+	}
+
+	if !failed[0] && !failed[1] {
+		return paths[0], paths[1], nil, nil
+	}
+
+	// Коды ошибок совпадают с кодами выхода tjudge-cli: 1 - первая программа, 2 - вторая
+	result := &domain.MatchResult{
+		MatchID:      match.ID,
+		ErrorMessage: strings.Join(failures, "\n"),
+	}
+	switch {
+	case failed[0] && failed[1]:
+		result.ErrorCode = 3
+	case failed[0]:
+		result.ErrorCode = 1
+		result.Winner = 2
+	default:
+		result.ErrorCode = 2
+		result.Winner = 1
+	}
+	return "", "", result, nil
+}
+
+// markProgramError сохраняет ошибку компиляции в программе, чтобы команда её увидела
+func (p *Processor) markProgramError(ctx context.Context, program *domain.Program, err error) {
+	if program.ErrorMessage != nil && *program.ErrorMessage == err.Error() {
+		return
+	}
+	if updErr := p.programRepo.SetErrorMessage(ctx, program.ID, err.Error()); updErr != nil {
+		p.log.LogError("Failed to save program compile error", updErr,
+			zap.String("program_id", program.ID.String()),
+		)
+		return
+	}
+
+	if p.notifier != nil {
+		if notifyErr := p.notifier.CompileFailed(ctx, program, err.Error()); notifyErr != nil {
+			p.log.LogError("Failed to enqueue compile error notification", notifyErr,
+				zap.String("program_id", program.ID.String()),
+			)
+		}
+	}
+}
+
+// saveBuildFailure сохраняет результат матча, не сыгранного из-за ошибки компиляции
+func (p *Processor) saveBuildFailure(ctx context.Context, match *domain.Match, result *domain.MatchResult, program1, program2 *domain.Program) error {
+	if err := p.saveResult(ctx, match.ID, result); err != nil {
+		if errors.IsConflict(err) {
+			p.skipDuplicate(match, err)
+			return nil
+		}
+		return fmt.Errorf("failed to update match result: %w", err)
+	}
+
+	p.log.Info("Match failed: compilation error",
+		zap.String("match_id", match.ID.String()),
+		zap.Int("error_code", result.ErrorCode),
+	)
+
+	p.publishEnd(ctx, match)
+	p.recordValidation(ctx, match, result)
 
+	// Техническая победа тоже выводит участника в следующий раунд
+	p.advanceBracket(ctx, match, result)
+	p.notifyMatchCompleted(ctx, match, program1, program2)
+	p.finishRound(ctx, match)
 	return nil
 }
 
+// recordValidation сохраняет итог проверочного матча в проверяемой программе (program1).
+// Проверка не пройдена, если матч завершился ошибкой, в которой программа не победила:
+// ошибка только эталонного бота не считается ошибкой программы.
+// Матч уже сохранён, поэтому ошибка только логируется
+func (p *Processor) recordValidation(ctx context.Context, match *domain.Match, result *domain.MatchResult) {
+	if !match.IsValidation {
+		return
+	}
+
+	status, message := domain.ValidationPassed, ""
+	if result.ErrorCode != 0 && result.Winner != 1 {
+		status, message = domain.ValidationFailed, result.ErrorMessage
+		if message == "" {
+			message = fmt.Sprintf("validation match failed with exit code %d", result.ErrorCode)
+		}
+	}
+
+	if err := p.programRepo.SetValidationResult(ctx, match.Program1ID, status, message); err != nil {
+		p.log.LogError("Failed to save program validation result", err,
+			zap.String("match_id", match.ID.String()),
+			zap.String("program_id", match.Program1ID.String()),
+		)
+		return
+	}
+
+	p.log.Info("Program validated",
+		zap.String("program_id", match.Program1ID.String()),
+		zap.String("status", string(status)),
+	)
+}
+
+// advanceBracket продвигает победителя по сетке. Матч уже сохранён, поэтому ошибка только логируется
+func (p *Processor) advanceBracket(ctx context.Context, match *domain.Match, result *domain.MatchResult) {
+	if p.bracket == nil || match.IsTest {
+		return
+	}
+	if err := p.bracket.Advance(ctx, match, result); err != nil {
+		p.log.LogError("Failed to advance bracket", err,
+			zap.String("match_id", match.ID.String()),
+			zap.String("tournament_id", match.TournamentID.String()),
+		)
+	}
+}
+
+// notifyMatchCompleted уведомляет команды, у чьих программ не осталось несыгранных матчей.
+// Матч уже сохранён, поэтому ошибка только логируется
+func (p *Processor) notifyMatchCompleted(ctx context.Context, match *domain.Match, programs ...*domain.Program) {
+	if p.notifier == nil || match.IsTest {
+		return
+	}
+	for _, program := range programs {
+		if err := p.notifier.ProgramMatchesCompleted(ctx, program); err != nil {
+			p.log.LogError("Failed to enqueue match notification", err,
+				zap.String("match_id", match.ID.String()),
+				zap.String("program_id", program.ID.String()),
+			)
+		}
+	}
+}
+
+// finishRound снимает отметку идущего раунда игры, если это был её последний матч.
+// Если турнир завершает раунды автоматически, подписчики получают событие round_completed.
+// Матч уже сохранён, поэтому ошибка только логируется
+func (p *Processor) finishRound(ctx context.Context, match *domain.Match) {
+	if p.rounds == nil || match.IsTest {
+		return
+	}
+	tg, err := p.rounds.FinishRoundIfIdle(ctx, match.TournamentID, match.GameType)
+	if err != nil {
+		p.log.LogError("Failed to finish game round", err,
+			zap.String("match_id", match.ID.String()),
+			zap.String("tournament_id", match.TournamentID.String()),
+		)
+		return
+	}
+	if tg == nil {
+		return
+	}
+
+	p.log.Info("Game round finished",
+		zap.String("tournament_id", match.TournamentID.String()),
+		zap.String("game_type", match.GameType),
+		zap.Bool("completed", tg.RoundCompleted),
+		zap.Int("round", tg.CurrentRound),
+	)
+	if p.roundNotifier != nil {
+		if err := p.roundNotifier.RoundCompleted(ctx, match.TournamentID, match.GameType); err != nil {
+			p.log.LogError("Failed to enqueue round notification", err,
+				zap.String("tournament_id", match.TournamentID.String()),
+			)
+		}
+	}
+	if tg.RoundCompleted && p.events != nil {
+		payload := map[string]interface{}{
+			"game_id":      tg.GameID,
+			"game_type":    match.GameType,
+			"round":        tg.CurrentRound,
+			"completed_at": tg.RoundCompletedAt,
+		}
+		if err := p.events.PublishTournamentEvent(ctx, match.TournamentID, string(websocket.MessageTypeRoundCompleted), payload); err != nil {
+			p.log.LogError("Failed to publish round completed event", err,
+				zap.String("tournament_id", match.TournamentID.String()),
+			)
+		}
+	}
+}
+
 // updateRatings обновляет рейтинги участников после матча
-func (p *Processor) updateRatings(ctx context.Context, match *domain.Match, result *domain.MatchResult) error {
+func (p *Processor) updateRatings(ctx context.Context, match *domain.Match, result *domain.MatchResult, program1, program2 *domain.Program) error {
+	// Матч двух эталонных ботов ничего не калибрует
+	if program1.IsReference && program2.IsReference {
+		return nil
+	}
+
 	// Получаем текущие рейтинги участников
 	rating1, rating2, err := p.ratingRepo.GetParticipantRatings(
 		ctx,
@@ -159,9 +738,17 @@ func (p *Processor) updateRatings(ctx context.Context, match *domain.Match, resu
 		return fmt.Errorf("failed to get participant ratings: %w", err)
 	}
 
-	// Обновляем рейтинги через сервис
+	// Обновляем рейтинги через сервис. Рейтинг эталонного бота не меняется
 	match.Winner = &result.Winner
-	if err := p.ratingService.ProcessMatchResult(ctx, match, rating1, rating2); err != nil {
+	switch {
+	case program1.IsReference:
+		err = p.ratingService.ProcessReferenceMatchResult(ctx, match, rating1, rating2, 1)
+	case program2.IsReference:
+		err = p.ratingService.ProcessReferenceMatchResult(ctx, match, rating1, rating2, 2)
+	default:
+		err = p.ratingService.ProcessMatchResult(ctx, match, rating1, rating2)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to process match result: %w", err)
 	}
 
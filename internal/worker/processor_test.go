@@ -0,0 +1,981 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/executor"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conditionalMatchRepo emulates the conditional result write of the DB repository
+type conditionalMatchRepo struct {
+	mu      sync.Mutex
+	status  map[uuid.UUID]domain.MatchStatus
+	results map[uuid.UUID]*domain.MatchResult
+}
+
+func newConditionalMatchRepo(match *domain.Match) *conditionalMatchRepo {
+	return &conditionalMatchRepo{
+		status:  map[uuid.UUID]domain.MatchStatus{match.ID: domain.MatchPending},
+		results: make(map[uuid.UUID]*domain.MatchResult),
+	}
+}
+
+func (r *conditionalMatchRepo) UpdateStatus(_ context.Context, id uuid.UUID, status domain.MatchStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.status[id]
+	if !ok {
+		return errors.ErrNotFound
+	}
+	if status == domain.MatchRunning && (current == domain.MatchCompleted || current == domain.MatchFailed) {
+		return errors.ErrConflict
+	}
+	r.status[id] = status
+	return nil
+}
+
+func (r *conditionalMatchRepo) UpdateResult(_ context.Context, id uuid.UUID, result *domain.MatchResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.status[id]
+	if current != domain.MatchRunning && current != domain.MatchPending {
+		return errors.ErrConflict
+	}
+	r.status[id] = domain.MatchCompleted
+	if result.ErrorCode != 0 {
+		r.status[id] = domain.MatchFailed
+	}
+	r.results[id] = result
+	return nil
+}
+
+func (r *conditionalMatchRepo) BatchUpdateResults(ctx context.Context, results map[uuid.UUID]*domain.MatchResult) ([]uuid.UUID, error) {
+	var conflicts []uuid.UUID
+	for id, result := range results {
+		if err := r.UpdateResult(ctx, id, result); err != nil {
+			conflicts = append(conflicts, id)
+		}
+	}
+	return conflicts, nil
+}
+
+type staticProgramRepo struct{}
+
+func (staticProgramRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.Program, error) {
+	return &domain.Program{ID: id, CodePath: "/programs/" + id.String()}, nil
+}
+
+func (staticProgramRepo) SetErrorMessage(_ context.Context, _ uuid.UUID, _ string) error {
+	return nil
+}
+
+func (staticProgramRepo) SetValidationResult(_ context.Context, _ uuid.UUID, _ domain.ProgramValidationStatus, _ string) error {
+	return nil
+}
+
+type staticRatingRepo struct{}
+
+func (staticRatingRepo) GetParticipantRatings(_ context.Context, _, _, _ uuid.UUID) (int, int, error) {
+	return 1500, 1500, nil
+}
+
+type countingRatingService struct {
+	calls      atomic.Int32
+	references []int
+}
+
+func (s *countingRatingService) ProcessMatchResult(_ context.Context, _ *domain.Match, _, _ int) error {
+	s.calls.Add(1)
+	return nil
+}
+
+func (s *countingRatingService) ProcessReferenceMatchResult(_ context.Context, _ *domain.Match, _, _, reference int) error {
+	s.references = append(s.references, reference)
+	return nil
+}
+
+// winnerExecutor returns a different winner for every execution
+type winnerExecutor struct {
+	started sync.WaitGroup
+	calls   atomic.Int32
+}
+
+func (e *winnerExecutor) Execute(_ context.Context, match *domain.Match, _, _ string, _ executor.RunOptions) (*domain.MatchResult, error) {
+	n := e.calls.Add(1)
+	// Both executions run before either result is written
+	e.started.Done()
+	e.started.Wait()
+	return &domain.MatchResult{MatchID: match.ID, Winner: int(n), Score1: int(n)}, nil
+}
+
+func TestProcessor_ConcurrentDuplicateExecution(t *testing.T) {
+	match := testMatch()
+	match.TournamentID = uuid.New()
+	match.Program1ID = uuid.New()
+	match.Program2ID = uuid.New()
+
+	repo := newConditionalMatchRepo(match)
+	ratings := &countingRatingService{}
+	exec := &winnerExecutor{}
+	exec.started.Add(2)
+
+	m := testMetrics()
+	processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, ratings, exec, nil, testLogger())
+	processor.SetMetrics(m)
+
+	before := testutil.ToFloat64(m.DuplicateResults.WithLabelValues(match.GameType))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Each worker gets its own copy, as after a queue redelivery
+			m := *match
+			errs[i] = processor.Process(context.Background(), &m)
+		}(i)
+	}
+	wg.Wait()
+
+	// The losing execution is a no-op success, not a failure to retry
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(2), exec.calls.Load())
+	assert.Equal(t, int32(1), ratings.calls.Load(), "ratings must be updated only once")
+	assert.Equal(t, domain.MatchCompleted, repo.status[match.ID])
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.DuplicateResults.WithLabelValues(match.GameType))-before)
+}
+
+func TestProcessor_SkipsAlreadyCompletedMatch(t *testing.T) {
+	match := testMatch()
+	repo := newConditionalMatchRepo(match)
+	repo.status[match.ID] = domain.MatchCompleted
+
+	ratings := &countingRatingService{}
+	exec := &winnerExecutor{}
+
+	processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, ratings, exec, nil, testLogger())
+
+	require.NoError(t, processor.Process(context.Background(), match))
+	assert.Equal(t, int32(0), exec.calls.Load(), "completed match must not be executed again")
+	assert.Equal(t, int32(0), ratings.calls.Load())
+}
+
+// recordingProgramRepo remembers compile errors saved for programs
+type recordingProgramRepo struct {
+	staticProgramRepo
+	mu     sync.Mutex
+	errors map[uuid.UUID]string
+}
+
+func (r *recordingProgramRepo) SetErrorMessage(_ context.Context, id uuid.UUID, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors[id] = message
+	return nil
+}
+
+// failingBuilder fails to compile the listed programs
+type failingBuilder struct {
+	failing map[uuid.UUID]bool
+}
+
+func (b failingBuilder) Build(_ context.Context, program *domain.Program) (string, error) {
+	if b.failing[program.ID] {
+		return "", &executor.CompileError{Output: "error: expected ';'"}
+	}
+	return program.CodePath + ".bin", nil
+}
+
+// pathRecordingExecutor records the program paths it was given
+type pathRecordingExecutor struct {
+	calls atomic.Int32
+	paths []string
+}
+
+func (e *pathRecordingExecutor) Execute(_ context.Context, match *domain.Match, program1Path, program2Path string, _ executor.RunOptions) (*domain.MatchResult, error) {
+	e.calls.Add(1)
+	e.paths = []string{program1Path, program2Path}
+	return &domain.MatchResult{MatchID: match.ID, Winner: 1, Score1: 1}, nil
+}
+
+func TestProcessor_CompiledPrograms(t *testing.T) {
+	newMatch := func() *domain.Match {
+		match := testMatch()
+		match.Program1ID = uuid.New()
+		match.Program2ID = uuid.New()
+		return match
+	}
+
+	t.Run("runs built binaries", func(t *testing.T) {
+		match := newMatch()
+		repo := newConditionalMatchRepo(match)
+		exec := &pathRecordingExecutor{}
+
+		processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, exec, nil, testLogger())
+		processor.SetBuilder(failingBuilder{})
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Equal(t, []string{
+			"/programs/" + match.Program1ID.String() + ".bin",
+			"/programs/" + match.Program2ID.String() + ".bin",
+		}, exec.paths)
+	})
+
+	t.Run("compile failure loses the match without running it", func(t *testing.T) {
+		match := newMatch()
+		repo := newConditionalMatchRepo(match)
+		programs := &recordingProgramRepo{errors: make(map[uuid.UUID]string)}
+		ratings := &countingRatingService{}
+		exec := &pathRecordingExecutor{}
+
+		processor := NewProcessor(repo, staticRatingRepo{}, programs, ratings, exec, nil, testLogger())
+		processor.SetBuilder(failingBuilder{failing: map[uuid.UUID]bool{match.Program1ID: true}})
+
+		require.NoError(t, processor.Process(context.Background(), match))
+
+		assert.Equal(t, int32(0), exec.calls.Load())
+		assert.Equal(t, int32(0), ratings.calls.Load())
+
+		result := repo.results[match.ID]
+		require.NotNil(t, result)
+		assert.Equal(t, 1, result.ErrorCode)
+		assert.Equal(t, 2, result.Winner)
+		assert.Contains(t, result.ErrorMessage, "compilation failed")
+
+		assert.Contains(t, programs.errors[match.Program1ID], "expected ';'")
+		assert.NotContains(t, programs.errors, match.Program2ID)
+	})
+
+	t.Run("both programs fail", func(t *testing.T) {
+		match := newMatch()
+		repo := newConditionalMatchRepo(match)
+		builder := failingBuilder{failing: map[uuid.UUID]bool{match.Program1ID: true, match.Program2ID: true}}
+
+		processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, &pathRecordingExecutor{}, nil, testLogger())
+		processor.SetBuilder(builder)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Equal(t, 3, repo.results[match.ID].ErrorCode)
+		assert.Equal(t, 0, repo.results[match.ID].Winner)
+	})
+}
+
+func TestProcessor_TestMatchDoesNotUpdateRatings(t *testing.T) {
+	match := testMatch()
+	match.IsTest = true
+	repo := newConditionalMatchRepo(match)
+
+	ratings := &countingRatingService{}
+	exec := &winnerExecutor{}
+	exec.started.Add(1)
+
+	processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, ratings, exec, nil, testLogger())
+
+	require.NoError(t, processor.Process(context.Background(), match))
+	assert.Equal(t, domain.MatchCompleted, repo.status[match.ID])
+	assert.Equal(t, int32(0), ratings.calls.Load(), "test match must not change ratings")
+}
+
+// referenceProgramRepo marks the given programs as reference bots
+type referenceProgramRepo struct {
+	staticProgramRepo
+	reference map[uuid.UUID]bool
+}
+
+func (r referenceProgramRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Program, error) {
+	program, err := r.staticProgramRepo.GetByID(ctx, id)
+	program.IsReference = r.reference[id]
+	return program, err
+}
+
+func TestProcessor_ReferenceMatchKeepsBotRating(t *testing.T) {
+	t.Run("reference bot on either side", func(t *testing.T) {
+		ratings := &countingRatingService{}
+		for side := 1; side <= 2; side++ {
+			match := testMatch()
+			match.Program1ID, match.Program2ID = uuid.New(), uuid.New()
+			bot := match.Program1ID
+			if side == 2 {
+				bot = match.Program2ID
+			}
+
+			programs := referenceProgramRepo{reference: map[uuid.UUID]bool{bot: true}}
+			processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, programs, ratings,
+				resultExecutor{result: domain.MatchResult{Winner: 1}}, nil, testLogger())
+			require.NoError(t, processor.Process(context.Background(), match))
+		}
+
+		assert.Equal(t, []int{1, 2}, ratings.references)
+		assert.Equal(t, int32(0), ratings.calls.Load())
+	})
+
+	t.Run("two reference bots", func(t *testing.T) {
+		match := testMatch()
+		match.Program1ID, match.Program2ID = uuid.New(), uuid.New()
+		ratings := &countingRatingService{}
+
+		programs := referenceProgramRepo{reference: map[uuid.UUID]bool{match.Program1ID: true, match.Program2ID: true}}
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, programs, ratings,
+			resultExecutor{result: domain.MatchResult{Winner: 1}}, nil, testLogger())
+		require.NoError(t, processor.Process(context.Background(), match))
+
+		assert.Empty(t, ratings.references)
+		assert.Equal(t, int32(0), ratings.calls.Load())
+	})
+}
+
+// recordingBracket records the winners passed to the bracket
+type recordingBracket struct {
+	winners map[uuid.UUID]int
+}
+
+func (b *recordingBracket) Advance(_ context.Context, match *domain.Match, result *domain.MatchResult) error {
+	b.winners[match.ID] = result.Winner
+	return nil
+}
+
+func TestProcessor_AdvancesBracket(t *testing.T) {
+	t.Run("played match", func(t *testing.T) {
+		match := testMatch()
+		bracket := &recordingBracket{winners: make(map[uuid.UUID]int)}
+
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			resultExecutor{result: domain.MatchResult{Winner: 2}}, nil, testLogger())
+		processor.SetBracketAdvancer(bracket)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Equal(t, map[uuid.UUID]int{match.ID: 2}, bracket.winners)
+	})
+
+	t.Run("compile failure is a technical win", func(t *testing.T) {
+		match := testMatch()
+		match.Program1ID = uuid.New()
+		bracket := &recordingBracket{winners: make(map[uuid.UUID]int)}
+
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			&pathRecordingExecutor{}, nil, testLogger())
+		processor.SetBuilder(failingBuilder{failing: map[uuid.UUID]bool{match.Program1ID: true}})
+		processor.SetBracketAdvancer(bracket)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Equal(t, map[uuid.UUID]int{match.ID: 2}, bracket.winners)
+	})
+
+	t.Run("test match is ignored", func(t *testing.T) {
+		match := testMatch()
+		match.IsTest = true
+		bracket := &recordingBracket{winners: make(map[uuid.UUID]int)}
+
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			resultExecutor{result: domain.MatchResult{Winner: 1}}, nil, testLogger())
+		processor.SetBracketAdvancer(bracket)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Empty(t, bracket.winners)
+	})
+}
+
+// recordingNotifier records notification calls by program
+type recordingNotifier struct {
+	completed     []uuid.UUID
+	compileFailed []uuid.UUID
+}
+
+func (n *recordingNotifier) ProgramMatchesCompleted(_ context.Context, program *domain.Program) error {
+	n.completed = append(n.completed, program.ID)
+	return nil
+}
+
+func (n *recordingNotifier) CompileFailed(_ context.Context, program *domain.Program, _ string) error {
+	n.compileFailed = append(n.compileFailed, program.ID)
+	return nil
+}
+
+func TestProcessor_Notifications(t *testing.T) {
+	t.Run("played match checks both programs", func(t *testing.T) {
+		match := testMatch()
+		notifier := &recordingNotifier{}
+
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			resultExecutor{result: domain.MatchResult{Winner: 1}}, nil, testLogger())
+		processor.SetNotifier(notifier)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Equal(t, []uuid.UUID{match.Program1ID, match.Program2ID}, notifier.completed)
+		assert.Empty(t, notifier.compileFailed)
+	})
+
+	t.Run("compile failure notifies the failing program", func(t *testing.T) {
+		match := testMatch()
+		match.Program1ID = uuid.New()
+		notifier := &recordingNotifier{}
+
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			&pathRecordingExecutor{}, nil, testLogger())
+		processor.SetBuilder(failingBuilder{failing: map[uuid.UUID]bool{match.Program1ID: true}})
+		processor.SetNotifier(notifier)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Equal(t, []uuid.UUID{match.Program1ID}, notifier.compileFailed)
+		assert.Equal(t, []uuid.UUID{match.Program1ID, match.Program2ID}, notifier.completed)
+	})
+
+	t.Run("test match is ignored", func(t *testing.T) {
+		match := testMatch()
+		match.IsTest = true
+		notifier := &recordingNotifier{}
+
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			resultExecutor{result: domain.MatchResult{Winner: 1}}, nil, testLogger())
+		processor.SetNotifier(notifier)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Empty(t, notifier.completed)
+	})
+}
+
+// recordingRounds records games whose round was checked for completion and
+// games whose completion was announced. A busy game still has unplayed matches,
+// autoComplete emulates a tournament with auto_complete_rounds enabled
+type recordingRounds struct {
+	busy         bool
+	autoComplete bool
+	checked      []string
+	notified     []string
+}
+
+func (r *recordingRounds) FinishRoundIfIdle(_ context.Context, tournamentID uuid.UUID, gameType string) (*domain.TournamentGame, error) {
+	r.checked = append(r.checked, gameType)
+	if r.busy {
+		return nil, nil
+	}
+	tg := &domain.TournamentGame{TournamentID: tournamentID, GameID: uuid.New()}
+	if r.autoComplete {
+		now := time.Now()
+		tg.RoundCompleted = true
+		tg.RoundCompletedAt = &now
+		tg.CurrentRound = 2
+	}
+	return tg, nil
+}
+
+// recordingEvents records published tournament events
+type recordingEvents struct {
+	events []string
+}
+
+func (e *recordingEvents) PublishTournamentEvent(_ context.Context, _ uuid.UUID, eventType string, _ interface{}) error {
+	e.events = append(e.events, eventType)
+	return nil
+}
+
+func (r *recordingRounds) RoundCompleted(_ context.Context, _ uuid.UUID, gameType string) error {
+	r.notified = append(r.notified, gameType)
+	return nil
+}
+
+func TestProcessor_FinishRound(t *testing.T) {
+	t.Run("played match checks its game round", func(t *testing.T) {
+		match := testMatch()
+		rounds := &recordingRounds{}
+
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			resultExecutor{result: domain.MatchResult{Winner: 1}}, nil, testLogger())
+		processor.SetRoundTracker(rounds)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Equal(t, []string{match.GameType}, rounds.checked)
+	})
+
+	t.Run("compile failure also counts as played", func(t *testing.T) {
+		match := testMatch()
+		match.Program1ID = uuid.New()
+		rounds := &recordingRounds{}
+
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			&pathRecordingExecutor{}, nil, testLogger())
+		processor.SetBuilder(failingBuilder{failing: map[uuid.UUID]bool{match.Program1ID: true}})
+		processor.SetRoundTracker(rounds)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Equal(t, []string{match.GameType}, rounds.checked)
+	})
+
+	t.Run("test match is ignored", func(t *testing.T) {
+		match := testMatch()
+		match.IsTest = true
+		rounds := &recordingRounds{}
+
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			resultExecutor{result: domain.MatchResult{Winner: 1}}, nil, testLogger())
+		processor.SetRoundTracker(rounds)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Empty(t, rounds.checked)
+	})
+
+	t.Run("finished round is announced once", func(t *testing.T) {
+		for _, busy := range []bool{false, true} {
+			match := testMatch()
+			rounds := &recordingRounds{busy: busy}
+
+			processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+				resultExecutor{result: domain.MatchResult{Winner: 1}}, nil, testLogger())
+			processor.SetRoundTracker(rounds)
+			processor.SetRoundNotifier(rounds)
+
+			require.NoError(t, processor.Process(context.Background(), match))
+			if busy {
+				assert.Empty(t, rounds.notified)
+			} else {
+				assert.Equal(t, []string{match.GameType}, rounds.notified)
+			}
+		}
+	})
+
+	t.Run("only auto-completed round is broadcast", func(t *testing.T) {
+		for _, autoComplete := range []bool{false, true} {
+			match := testMatch()
+			rounds := &recordingRounds{autoComplete: autoComplete}
+			events := &recordingEvents{}
+
+			processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+				resultExecutor{result: domain.MatchResult{Winner: 1}}, nil, testLogger())
+			processor.SetRoundTracker(rounds)
+			processor.SetEventPublisher(events)
+
+			require.NoError(t, processor.Process(context.Background(), match))
+			if autoComplete {
+				assert.Equal(t, []string{"round_completed"}, events.events)
+			} else {
+				assert.Empty(t, events.events)
+			}
+		}
+	})
+}
+
+// resultExecutor returns a fixed result
+type resultExecutor struct {
+	result domain.MatchResult
+}
+
+func (e resultExecutor) Execute(_ context.Context, match *domain.Match, _, _ string, _ executor.RunOptions) (*domain.MatchResult, error) {
+	result := e.result
+	result.MatchID = match.ID
+	return &result, nil
+}
+
+func TestProcessor_OutOfMemory(t *testing.T) {
+	match := testMatch()
+	repo := newConditionalMatchRepo(match)
+	ratings := &countingRatingService{}
+	exec := resultExecutor{result: domain.MatchResult{
+		ErrorCode:    domain.MatchErrorOutOfMemory,
+		ErrorMessage: "❌ Программа 1 превысила лимит памяти (512 МБ) и была остановлена",
+		Winner:       2,
+	}}
+
+	m := testMetrics()
+	before := testutil.ToFloat64(m.OOMKills.WithLabelValues(match.GameType))
+
+	processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, ratings, exec, nil, testLogger())
+	processor.SetMetrics(m)
+
+	require.NoError(t, processor.Process(context.Background(), match))
+
+	saved := repo.results[match.ID]
+	require.NotNil(t, saved)
+	assert.Equal(t, domain.MatchErrorOutOfMemory, saved.ErrorCode)
+	assert.Contains(t, saved.ErrorMessage, "лимит памяти")
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.OOMKills.WithLabelValues(match.GameType))-before)
+	assert.Equal(t, int32(0), ratings.calls.Load(), "failed match must not change ratings")
+}
+
+// validationProgramRepo remembers validation results saved for programs
+type validationProgramRepo struct {
+	staticProgramRepo
+	statuses map[uuid.UUID]domain.ProgramValidationStatus
+	messages map[uuid.UUID]string
+}
+
+func (r *validationProgramRepo) SetValidationResult(_ context.Context, id uuid.UUID, status domain.ProgramValidationStatus, message string) error {
+	r.statuses[id] = status
+	r.messages[id] = message
+	return nil
+}
+
+func TestProcessor_ValidationMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  domain.MatchResult
+		status  domain.ProgramValidationStatus
+		message string
+	}{
+		{"program loses fairly", domain.MatchResult{Winner: 2, Score1: 10, Score2: 30}, domain.ValidationPassed, ""},
+		{"program breaks the protocol", domain.MatchResult{ErrorCode: 1, Winner: 2, ErrorMessage: "Программа 1: неверный ход"}, domain.ValidationFailed, "Программа 1: неверный ход"},
+		{"reference bot fails", domain.MatchResult{ErrorCode: 2, Winner: 1}, domain.ValidationPassed, ""},
+		{"both fail without output", domain.MatchResult{ErrorCode: 3}, domain.ValidationFailed, "validation match failed with exit code 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := testMatch()
+			match.Program1ID, match.Program2ID = uuid.New(), uuid.New()
+			match.IsTest, match.IsValidation = true, true
+			repo := newConditionalMatchRepo(match)
+			programs := &validationProgramRepo{statuses: map[uuid.UUID]domain.ProgramValidationStatus{}, messages: map[uuid.UUID]string{}}
+			ratings := &countingRatingService{}
+
+			processor := NewProcessor(repo, staticRatingRepo{}, programs, ratings, resultExecutor{result: tt.result}, nil, testLogger())
+
+			require.NoError(t, processor.Process(context.Background(), match))
+			assert.Equal(t, tt.status, programs.statuses[match.Program1ID])
+			assert.Equal(t, tt.message, programs.messages[match.Program1ID])
+			assert.NotContains(t, programs.statuses, match.Program2ID, "the reference bot is not validated")
+			assert.Equal(t, int32(0), ratings.calls.Load(), "validation match must not change ratings")
+		})
+	}
+
+	t.Run("regular match leaves validation alone", func(t *testing.T) {
+		match := testMatch()
+		repo := newConditionalMatchRepo(match)
+		programs := &validationProgramRepo{statuses: map[uuid.UUID]domain.ProgramValidationStatus{}, messages: map[uuid.UUID]string{}}
+
+		processor := NewProcessor(repo, staticRatingRepo{}, programs, &countingRatingService{}, resultExecutor{result: domain.MatchResult{ErrorCode: 1, Winner: 2}}, nil, testLogger())
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Empty(t, programs.statuses)
+	})
+}
+
+// unavailableExecutor fails as if the Docker daemon were restarting
+type unavailableExecutor struct{}
+
+func (unavailableExecutor) Execute(_ context.Context, _ *domain.Match, _, _ string, _ executor.RunOptions) (*domain.MatchResult, error) {
+	return nil, fmt.Errorf("failed to run match: failed to create container: %w", executor.ErrDockerUnavailable)
+}
+
+func TestProcessor_DockerUnavailableDoesNotFailMatch(t *testing.T) {
+	match := testMatch()
+	repo := newConditionalMatchRepo(match)
+	processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, unavailableExecutor{}, nil, testLogger())
+
+	err := processor.Process(context.Background(), match)
+
+	require.Error(t, err)
+	assert.True(t, executor.IsDockerUnavailable(err))
+	assert.Empty(t, repo.results, "no error result must be saved")
+	assert.Equal(t, domain.MatchPending, repo.status[match.ID])
+}
+
+// blockingExecutor runs until its context is cancelled
+type blockingExecutor struct{}
+
+func (blockingExecutor) Execute(ctx context.Context, _ *domain.Match, _, _ string, _ executor.RunOptions) (*domain.MatchResult, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("failed to run match: %w", ctx.Err())
+}
+
+func TestProcessor_DrainTimeoutReleasesMatch(t *testing.T) {
+	match := testMatch()
+	repo := newConditionalMatchRepo(match)
+	processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, blockingExecutor{}, nil, testLogger())
+
+	ctx, abort := context.WithCancelCause(context.Background())
+	time.AfterFunc(10*time.Millisecond, func() { abort(ErrDrainTimeout) })
+
+	err := processor.Process(ctx, match)
+
+	require.Error(t, err)
+	assert.Empty(t, repo.results, "an interrupted match must not be saved as failed")
+	assert.Equal(t, domain.MatchPending, repo.status[match.ID])
+}
+
+// timedExecutor reports container timings that end at the moment of return
+type timedExecutor struct{}
+
+func (timedExecutor) Execute(_ context.Context, match *domain.Match, _, _ string, _ executor.RunOptions) (*domain.MatchResult, error) {
+	now := time.Now()
+	return &domain.MatchResult{
+		MatchID: match.ID,
+		Winner:  1,
+		Timing: domain.ExecutionTiming{
+			ContainerCreatedAt: now.Add(-3 * time.Millisecond),
+			StartedAt:          now.Add(-2 * time.Millisecond),
+			FinishedAt:         now,
+		},
+	}, nil
+}
+
+func TestProcessor_RecordsTracePhases(t *testing.T) {
+	match := testMatch()
+	dequeuedAt := time.Now().Add(-time.Second)
+	match.DequeuedAt = &dequeuedAt
+	repo := newConditionalMatchRepo(match)
+
+	m := testMetrics()
+	count := func(phase string) uint64 {
+		metric := &dto.Metric{}
+		require.NoError(t, m.MatchPhase.WithLabelValues(phase, match.GameType).(prometheus.Histogram).Write(metric))
+		return metric.GetHistogram().GetSampleCount()
+	}
+	phases := []string{domain.TracePhasePrepare, domain.TracePhaseStart, domain.TracePhaseExecution, domain.TracePhasePersist}
+	before := make(map[string]uint64)
+	for _, phase := range phases {
+		before[phase] = count(phase)
+	}
+
+	processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, timedExecutor{}, nil, testLogger())
+	processor.SetMetrics(m)
+
+	require.NoError(t, processor.Process(context.Background(), match))
+
+	for _, phase := range phases {
+		assert.Equal(t, before[phase]+1, count(phase), phase)
+	}
+}
+
+// recordingTimings records saved match timings
+type recordingTimings struct {
+	saved map[uuid.UUID]*domain.MatchTiming
+}
+
+func (r *recordingTimings) SaveTiming(_ context.Context, id uuid.UUID, timing *domain.MatchTiming) error {
+	r.saved[id] = timing
+	return nil
+}
+
+func TestProcessor_SavesTiming(t *testing.T) {
+	match := testMatch()
+	now := time.Now()
+	enqueuedAt, dequeuedAt := now.Add(-5*time.Second), now.Add(-time.Second)
+	match.EnqueuedAt = &enqueuedAt
+	match.DequeuedAt = &dequeuedAt
+
+	timings := &recordingTimings{saved: make(map[uuid.UUID]*domain.MatchTiming)}
+	processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, timedExecutor{}, nil, testLogger())
+	processor.SetTimingStore(timings)
+
+	require.NoError(t, processor.Process(context.Background(), match))
+
+	timing := timings.saved[match.ID]
+	require.NotNil(t, timing)
+	assert.Equal(t, int64(4000), timing.QueueWaitMs)
+	assert.Equal(t, int64(2), timing.ExecutionMs)
+	assert.Positive(t, timing.PrepareMs)
+}
+
+func BenchmarkProcessor_Process(b *testing.B) {
+	log, _ := logger.New("error", "json")
+	m := testMetrics()
+
+	for _, traced := range []bool{false, true} {
+		b.Run(fmt.Sprintf("metrics=%t", traced), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				match := testMatch()
+				processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, timedExecutor{}, nil, log)
+				if traced {
+					processor.SetMetrics(m)
+				}
+				if err := processor.Process(context.Background(), match); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// batchCountingRepo counts batched result writes
+type batchCountingRepo struct {
+	*conditionalMatchRepo
+	batches atomic.Int32
+}
+
+func (r *batchCountingRepo) BatchUpdateResults(ctx context.Context, results map[uuid.UUID]*domain.MatchResult) ([]uuid.UUID, error) {
+	r.batches.Add(1)
+	return r.conditionalMatchRepo.BatchUpdateResults(ctx, results)
+}
+
+// batchExecutor fails the listed matches; all executions start before any returns
+type batchExecutor struct {
+	started sync.WaitGroup
+	failing map[uuid.UUID]error
+}
+
+func (e *batchExecutor) Execute(_ context.Context, match *domain.Match, _, _ string, _ executor.RunOptions) (*domain.MatchResult, error) {
+	e.started.Done()
+	e.started.Wait()
+	if err := e.failing[match.ID]; err != nil {
+		return nil, err
+	}
+	return &domain.MatchResult{MatchID: match.ID, Winner: 1, Score1: 1}, nil
+}
+
+func TestProcessor_ProcessBatch(t *testing.T) {
+	newBatch := func() ([]*domain.Match, *batchCountingRepo) {
+		matches := []*domain.Match{testMatch(), testMatch(), testMatch()}
+		repo := &batchCountingRepo{conditionalMatchRepo: newConditionalMatchRepo(matches[0])}
+		for _, match := range matches[1:] {
+			repo.status[match.ID] = domain.MatchPending
+		}
+		return matches, repo
+	}
+
+	t.Run("failed element does not prevent storing the others", func(t *testing.T) {
+		matches, repo := newBatch()
+		ratings := &countingRatingService{}
+		exec := &batchExecutor{failing: map[uuid.UUID]error{matches[1].ID: fmt.Errorf("runner crashed")}}
+		exec.started.Add(len(matches))
+
+		processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, ratings, exec, nil, testLogger())
+
+		failed := processor.ProcessBatch(context.Background(), matches)
+
+		require.Len(t, failed, 1)
+		assert.Equal(t, matches[1].ID, failed[0].MatchID)
+		assert.ErrorContains(t, failed[0], "runner crashed")
+
+		// Every result, including the error one, is written in a single batch
+		assert.Equal(t, int32(1), repo.batches.Load())
+		require.Len(t, repo.results, 3)
+		assert.Equal(t, 1, repo.results[matches[0].ID].Winner)
+		assert.Equal(t, 1, repo.results[matches[1].ID].ErrorCode)
+		assert.Equal(t, 1, repo.results[matches[2].ID].Winner)
+		assert.Equal(t, int32(2), ratings.calls.Load(), "only played matches change ratings")
+	})
+
+	t.Run("already completed match is skipped", func(t *testing.T) {
+		matches, repo := newBatch()
+		repo.status[matches[0].ID] = domain.MatchCompleted
+		ratings := &countingRatingService{}
+		exec := &batchExecutor{}
+		exec.started.Add(len(matches) - 1)
+
+		processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, ratings, exec, nil, testLogger())
+
+		assert.Empty(t, processor.ProcessBatch(context.Background(), matches))
+		assert.Len(t, repo.results, 2)
+		assert.Equal(t, int32(2), ratings.calls.Load())
+	})
+
+	t.Run("docker outage releases only the unplayed match", func(t *testing.T) {
+		matches, repo := newBatch()
+		exec := &batchExecutor{failing: map[uuid.UUID]error{
+			matches[2].ID: fmt.Errorf("failed to create container: %w", executor.ErrDockerUnavailable),
+		}}
+		exec.started.Add(len(matches))
+
+		processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, exec, nil, testLogger())
+
+		failed := processor.ProcessBatch(context.Background(), matches)
+
+		require.Len(t, failed, 1)
+		assert.True(t, executor.IsDockerUnavailable(failed[0].Err))
+		assert.Equal(t, domain.MatchPending, repo.status[matches[2].ID])
+		assert.Len(t, repo.results, 2)
+	})
+}
+
+// recordingLiveFeed remembers published match events
+type recordingLiveFeed struct {
+	mu     sync.Mutex
+	events []domain.MatchEvent
+}
+
+func (f *recordingLiveFeed) PublishMatchEvent(_ context.Context, _ uuid.UUID, event *domain.MatchEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, *event)
+	return nil
+}
+
+// outputExecutor writes moves through the live output before returning
+type outputExecutor struct {
+	lines []string
+	err   error
+}
+
+func (e outputExecutor) Execute(_ context.Context, match *domain.Match, _, _ string, opts executor.RunOptions) (*domain.MatchResult, error) {
+	for _, line := range e.lines {
+		if opts.Output != nil {
+			opts.Output(line)
+		}
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &domain.MatchResult{MatchID: match.ID, Winner: 1}, nil
+}
+
+func TestProcessor_LiveFeed(t *testing.T) {
+	eventTypes := func(events []domain.MatchEvent) []domain.MatchEventType {
+		var types []domain.MatchEventType
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+		return types
+	}
+
+	t.Run("moves are published between start and end", func(t *testing.T) {
+		match := testMatch()
+		live := &recordingLiveFeed{}
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			outputExecutor{lines: []string{"1: C D", "2: D D"}}, nil, testLogger())
+		processor.SetLiveFeed(live)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+
+		assert.Equal(t, []domain.MatchEventType{
+			domain.MatchEventStart, domain.MatchEventOutput, domain.MatchEventOutput, domain.MatchEventEnd,
+		}, eventTypes(live.events))
+		assert.Equal(t, "2: D D", live.events[2].Line)
+	})
+
+	t.Run("failed execution still ends the stream", func(t *testing.T) {
+		match := testMatch()
+		live := &recordingLiveFeed{}
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			outputExecutor{lines: []string{"1: C D"}, err: fmt.Errorf("match execution timeout")}, nil, testLogger())
+		processor.SetLiveFeed(live)
+
+		require.Error(t, processor.Process(context.Background(), match))
+
+		assert.Equal(t, []domain.MatchEventType{
+			domain.MatchEventStart, domain.MatchEventOutput, domain.MatchEventEnd,
+		}, eventTypes(live.events))
+	})
+
+	t.Run("docker outage does not end the stream", func(t *testing.T) {
+		match := testMatch()
+		live := &recordingLiveFeed{}
+		processor := NewProcessor(newConditionalMatchRepo(match), staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{},
+			unavailableExecutor{}, nil, testLogger())
+		processor.SetLiveFeed(live)
+
+		require.Error(t, processor.Process(context.Background(), match))
+
+		// The match goes back to the queue and will be streamed again
+		assert.Equal(t, []domain.MatchEventType{domain.MatchEventStart}, eventTypes(live.events))
+	})
+}
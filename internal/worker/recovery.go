@@ -6,6 +6,7 @@ import (
 
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/metrics"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -21,18 +22,28 @@ type RecoveryMatchRepository interface {
 type RecoveryQueueManager interface {
 	Enqueue(ctx context.Context, match *domain.Match) error
 	GetTotalQueueSize(ctx context.Context) (int64, error)
+	QueuedMatchIDs(ctx context.Context) (map[uuid.UUID]struct{}, error)
+}
+
+// ContainerCleaner останавливает контейнеры матчей, оставшиеся после падения worker'а
+type ContainerCleaner interface {
+	CleanupOrphanedContainers(ctx context.Context) error
 }
 
 // RecoveryService сервис восстановления застрявших матчей
 type RecoveryService struct {
 	matchRepo    RecoveryMatchRepository
 	queueManager RecoveryQueueManager
+	containers   ContainerCleaner
+	metrics      *metrics.Metrics
 	log          *logger.Logger
+	now          func() time.Time
 
 	// Конфигурация
 	stuckDuration    time.Duration // Время, после которого running матч считается застрявшим
 	batchSize        int           // Размер батча для восстановления
 	periodicInterval time.Duration // Интервал периодической проверки
+	reconcileMinAge  time.Duration // Возраст pending матча, после которого его отсутствие в очереди - потеря
 
 	// Для graceful shutdown
 	stopCh chan struct{}
@@ -43,6 +54,7 @@ type RecoveryConfig struct {
 	StuckDuration    time.Duration // По умолчанию 10 минут
 	BatchSize        int           // По умолчанию 1000
 	PeriodicInterval time.Duration // Интервал периодической проверки (0 = отключено)
+	ReconcileMinAge  time.Duration // Минимальный возраст pending матча для сверки с очередью. По умолчанию 1 минута
 }
 
 // NewRecoveryService создаёт новый сервис восстановления
@@ -61,24 +73,48 @@ func NewRecoveryService(
 	if cfg.PeriodicInterval == 0 {
 		cfg.PeriodicInterval = 5 * time.Minute
 	}
+	if cfg.ReconcileMinAge == 0 {
+		cfg.ReconcileMinAge = time.Minute
+	}
 
 	return &RecoveryService{
 		matchRepo:        matchRepo,
 		queueManager:     queueManager,
 		log:              log,
+		now:              time.Now,
 		stuckDuration:    cfg.StuckDuration,
 		batchSize:        cfg.BatchSize,
 		periodicInterval: cfg.PeriodicInterval,
+		reconcileMinAge:  cfg.ReconcileMinAge,
 		stopCh:           make(chan struct{}),
 	}
 }
 
+// SetContainerCleaner устанавливает очистку осиротевших контейнеров при запуске
+func (s *RecoveryService) SetContainerCleaner(cleaner ContainerCleaner) {
+	s.containers = cleaner
+}
+
+// SetMetrics устанавливает метрики для учёта матчей, возвращённых в очередь при сверке
+func (s *RecoveryService) SetMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
 // RecoverOnStartup выполняет восстановление при запуске worker'а
+// 0. Останавливает контейнеры матчей, оставшиеся от упавшего worker'а
 // 1. Сбрасывает "застрявшие" running матчи в pending
 // 2. Добавляет все pending матчи в очередь Redis
 func (s *RecoveryService) RecoverOnStartup(ctx context.Context) error {
 	s.log.Info("Starting match recovery...")
 
+	// 0. Контейнеры матчей, которые будут перезапущены, не должны работать параллельно с ними
+	if s.containers != nil {
+		if err := s.containers.CleanupOrphanedContainers(ctx); err != nil {
+			s.log.LogError("Failed to clean up orphaned containers", err)
+			// Продолжаем, матчи важнее
+		}
+	}
+
 	// Проверяем текущий размер очереди
 	queueSize, err := s.queueManager.GetTotalQueueSize(ctx)
 	if err != nil {
@@ -243,5 +279,64 @@ func (s *RecoveryService) runPeriodicRecovery() {
 			zap.Int("stuck_recovered", stuckRecovered),
 			zap.Int("enqueued", enqueued),
 		)
+		return
+	}
+
+	// Очередь могла потерять матчи (Redis перезапущен без persistence)
+	if _, err := s.ReconcileQueue(ctx); err != nil {
+		s.log.LogError("Failed to reconcile queue with pending matches", err)
+	}
+}
+
+// ReconcileQueue возвращает в очередь pending матчи, которых в ней нет. Матч моложе
+// reconcileMinAge пропускается: его могли ещё не добавить в очередь или только что взять
+// воркером (running матчи в выборку не попадают). Очередь читается целиком за несколько
+// запросов, а не по запросу на матч. Возвращает количество возвращённых матчей
+func (s *RecoveryService) ReconcileQueue(ctx context.Context) (int, error) {
+	pending, err := s.matchRepo.GetPending(ctx, s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := s.now().Add(-s.reconcileMinAge)
+	var candidates []*domain.Match
+	for _, match := range pending {
+		if match.CreatedAt.Before(cutoff) {
+			candidates = append(candidates, match)
+		}
 	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	queued, err := s.queueManager.QueuedMatchIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	requeued := 0
+	for _, match := range candidates {
+		if _, ok := queued[match.ID]; ok {
+			continue
+		}
+		if err := s.queueManager.Enqueue(ctx, match); err != nil {
+			s.log.LogError("Failed to re-enqueue lost match", err,
+				zap.String("match_id", match.ID.String()),
+			)
+			continue
+		}
+		requeued++
+	}
+
+	if requeued > 0 {
+		s.log.Warn("Re-enqueued pending matches missing from queue",
+			zap.Int("requeued", requeued),
+			zap.Int("checked", len(candidates)),
+		)
+		if s.metrics != nil {
+			s.metrics.RecordQueueReconciled(requeued)
+		}
+	}
+
+	return requeued, nil
 }
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pendingMatchRepo returns a fixed set of pending matches
+type pendingMatchRepo struct {
+	pending []*domain.Match
+}
+
+func (r *pendingMatchRepo) GetPending(_ context.Context, limit int) ([]*domain.Match, error) {
+	if len(r.pending) > limit {
+		return r.pending[:limit], nil
+	}
+	return r.pending, nil
+}
+
+func (r *pendingMatchRepo) GetStuckRunning(_ context.Context, _ time.Duration, _ int) ([]*domain.Match, error) {
+	return nil, nil
+}
+
+func (r *pendingMatchRepo) BatchUpdateStatus(_ context.Context, _ []uuid.UUID, _ domain.MatchStatus) error {
+	return nil
+}
+
+// fakeRecoveryQueue holds queued match IDs and counts membership reads
+type fakeRecoveryQueue struct {
+	queued   map[uuid.UUID]struct{}
+	enqueued []uuid.UUID
+	reads    int
+}
+
+func (q *fakeRecoveryQueue) Enqueue(_ context.Context, match *domain.Match) error {
+	q.queued[match.ID] = struct{}{}
+	q.enqueued = append(q.enqueued, match.ID)
+	return nil
+}
+
+func (q *fakeRecoveryQueue) GetTotalQueueSize(_ context.Context) (int64, error) {
+	return int64(len(q.queued)), nil
+}
+
+func (q *fakeRecoveryQueue) QueuedMatchIDs(_ context.Context) (map[uuid.UUID]struct{}, error) {
+	q.reads++
+	ids := make(map[uuid.UUID]struct{}, len(q.queued))
+	for id := range q.queued {
+		ids[id] = struct{}{}
+	}
+	return ids, nil
+}
+
+func TestRecoveryService_ReconcileQueue(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	pendingAt := func(age time.Duration) *domain.Match {
+		match := testMatch()
+		match.CreatedAt = now.Add(-age)
+		return match
+	}
+
+	lost := pendingAt(5 * time.Minute)
+	queued := pendingAt(5 * time.Minute)
+	fresh := pendingAt(10 * time.Second) // may not have been enqueued yet
+	lostToo := pendingAt(2 * time.Minute)
+
+	repo := &pendingMatchRepo{pending: []*domain.Match{lost, queued, fresh, lostToo}}
+	queue := &fakeRecoveryQueue{queued: map[uuid.UUID]struct{}{queued.ID: {}}}
+
+	m := testMetrics()
+	before := testutil.ToFloat64(m.QueueReconciled)
+
+	service := NewRecoveryService(repo, queue, testLogger(), RecoveryConfig{ReconcileMinAge: time.Minute})
+	service.now = func() time.Time { return now }
+	service.SetMetrics(m)
+
+	requeued, err := service.ReconcileQueue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, requeued)
+	assert.ElementsMatch(t, []uuid.UUID{lost.ID, lostToo.ID}, queue.enqueued)
+	assert.Equal(t, 1, queue.reads, "queue membership must be read once per reconcile")
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.QueueReconciled)-before)
+
+	// Nothing is missing anymore
+	requeued, err = service.ReconcileQueue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, requeued)
+	assert.Len(t, queue.enqueued, 2)
+}
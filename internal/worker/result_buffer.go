@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/bmstu-itstech/tjudge/pkg/logger"
+	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// resultFlushTimeout максимальное время сохранения одного пакета результатов
+const resultFlushTimeout = 10 * time.Second
+
+// Причины сохранения пакета (метка trigger метрики)
+const (
+	flushTriggerSize   = "size"   // набран полный пакет
+	flushTriggerAge    = "age"    // первый результат пакета ждёт maxAge
+	flushTriggerCancel = "cancel" // контекст ожидающего воркера отменён (остановка пула)
+	flushTriggerClose  = "close"  // буфер закрыт
+)
+
+// ResultWriter сохраняет пакет результатов матчей. Возвращает ID матчей,
+// результат которых уже был записан
+type ResultWriter interface {
+	BatchUpdateResults(ctx context.Context, results map[uuid.UUID]*domain.MatchResult) ([]uuid.UUID, error)
+}
+
+// ResultBuffer накапливает результаты матчей воркеров пула и сохраняет их пакетами:
+// при maxSize результатах или через maxAge после первого из них. Store ждёт сохранения
+// своего результата, поэтому рейтинги и уведомления, как и раньше, идут после записи в БД
+type ResultBuffer struct {
+	writer  ResultWriter
+	maxSize int
+	maxAge  time.Duration
+	metrics *metrics.Metrics
+	log     *logger.Logger
+
+	mu      sync.Mutex
+	pending map[uuid.UUID]*bufferedResult
+	timer   *time.Timer
+	closed  bool
+
+	// Пакеты одного worker'а пишутся по очереди и не конкурируют за строки агрегатов
+	flushMu sync.Mutex
+}
+
+type bufferedResult struct {
+	result *domain.MatchResult
+	done   chan error
+}
+
+// NewResultBuffer создаёт буфер результатов. maxSize < 1 считается равным 1
+func NewResultBuffer(writer ResultWriter, maxSize int, maxAge time.Duration, log *logger.Logger) *ResultBuffer {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	return &ResultBuffer{
+		writer:  writer,
+		maxSize: maxSize,
+		maxAge:  maxAge,
+		log:     log,
+		pending: make(map[uuid.UUID]*bufferedResult),
+	}
+}
+
+// SetMetrics включает учёт сохранённых пакетов
+func (b *ResultBuffer) SetMetrics(m *metrics.Metrics) {
+	b.metrics = m
+}
+
+// Store добавляет результат матча в пакет и ждёт его сохранения. Возвращает ErrConflict,
+// если результат матча уже записан. Отмена ctx не теряет результат: пакет сохраняется сразу
+func (b *ResultBuffer) Store(ctx context.Context, matchID uuid.UUID, result *domain.MatchResult) error {
+	done := make(chan error, 1)
+
+	b.mu.Lock()
+	if _, ok := b.pending[matchID]; ok {
+		b.mu.Unlock()
+		return errors.ErrConflict.WithMessage("match result is already being saved")
+	}
+	b.pending[matchID] = &bufferedResult{result: result, done: done}
+
+	trigger := ""
+	switch {
+	case b.closed:
+		trigger = flushTriggerClose
+	case len(b.pending) >= b.maxSize:
+		trigger = flushTriggerSize
+	case b.timer == nil:
+		b.timer = time.AfterFunc(b.maxAge, func() { b.flush(flushTriggerAge) })
+	}
+	b.mu.Unlock()
+
+	if trigger != "" {
+		b.flush(trigger)
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		b.flush(flushTriggerCancel)
+		return <-done
+	}
+}
+
+// Close сохраняет накопленные результаты. Результаты, добавленные после Close,
+// сохраняются сразу, без ожидания пакета
+func (b *ResultBuffer) Close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	b.flush(flushTriggerClose)
+}
+
+// flush сохраняет все накопленные результаты одним запросом
+func (b *ResultBuffer) flush(trigger string) {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = make(map[uuid.UUID]*bufferedResult)
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	results := make(map[uuid.UUID]*domain.MatchResult, len(batch))
+	for id, buffered := range batch {
+		results[id] = buffered.result
+	}
+
+	// Пакет общий для нескольких воркеров: контекст одного из них не должен прерывать запись
+	ctx, cancel := context.WithTimeout(context.Background(), resultFlushTimeout)
+	defer cancel()
+
+	conflicts, err := b.writer.BatchUpdateResults(ctx, results)
+	if b.metrics != nil {
+		b.metrics.RecordResultFlush(trigger, len(batch))
+	}
+	if err != nil {
+		b.log.LogError("Failed to flush match results", err,
+			zap.String("trigger", trigger),
+			zap.Int("size", len(batch)),
+		)
+		for _, buffered := range batch {
+			buffered.done <- err
+		}
+		return
+	}
+
+	skipped := make(map[uuid.UUID]bool, len(conflicts))
+	for _, id := range conflicts {
+		skipped[id] = true
+	}
+	for id, buffered := range batch {
+		if skipped[id] {
+			buffered.done <- errors.ErrConflict.WithMessage("match result already recorded")
+			continue
+		}
+		buffered.done <- nil
+	}
+
+	b.log.Debug("Match results flushed",
+		zap.String("trigger", trigger),
+		zap.Int("size", len(batch)),
+		zap.Int("conflicts", len(conflicts)),
+	)
+}
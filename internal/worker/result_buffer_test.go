@@ -0,0 +1,158 @@
+package worker
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResultWriter records every batch it receives
+type fakeResultWriter struct {
+	mu        sync.Mutex
+	batches   []map[uuid.UUID]*domain.MatchResult
+	conflicts []uuid.UUID
+	err       error
+}
+
+func (w *fakeResultWriter) BatchUpdateResults(_ context.Context, results map[uuid.UUID]*domain.MatchResult) ([]uuid.UUID, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches = append(w.batches, results)
+	return w.conflicts, w.err
+}
+
+func (w *fakeResultWriter) batchSizes() []int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sizes := make([]int, 0, len(w.batches))
+	for _, batch := range w.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+// storeAll stores results concurrently and returns their errors in order
+func storeAll(ctx context.Context, buf *ResultBuffer, ids []uuid.UUID) []error {
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id uuid.UUID) {
+			defer wg.Done()
+			errs[i] = buf.Store(ctx, id, &domain.MatchResult{Winner: 1})
+		}(i, id)
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestResultBuffer_FlushesFullBatch(t *testing.T) {
+	writer := &fakeResultWriter{}
+	// maxAge is long enough that only the size trigger can fire
+	buf := NewResultBuffer(writer, 3, time.Hour, testLogger())
+
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for _, err := range storeAll(context.Background(), buf, ids) {
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []int{3}, writer.batchSizes())
+}
+
+func TestResultBuffer_FlushesByAge(t *testing.T) {
+	writer := &fakeResultWriter{}
+	buf := NewResultBuffer(writer, 100, 20*time.Millisecond, testLogger())
+
+	start := time.Now()
+	err := buf.Store(context.Background(), uuid.New(), &domain.MatchResult{Winner: 1})
+
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, []int{1}, writer.batchSizes())
+}
+
+func TestResultBuffer_Conflicts(t *testing.T) {
+	duplicate := uuid.New()
+	fresh := uuid.New()
+	writer := &fakeResultWriter{conflicts: []uuid.UUID{duplicate}}
+	buf := NewResultBuffer(writer, 2, time.Hour, testLogger())
+
+	errs := storeAll(context.Background(), buf, []uuid.UUID{duplicate, fresh})
+
+	assert.True(t, errors.IsConflict(errs[0]))
+	assert.NoError(t, errs[1])
+}
+
+func TestResultBuffer_WriterErrorReachesEveryWaiter(t *testing.T) {
+	writer := &fakeResultWriter{err: stderrors.New("connection reset")}
+	buf := NewResultBuffer(writer, 2, time.Hour, testLogger())
+
+	for _, err := range storeAll(context.Background(), buf, []uuid.UUID{uuid.New(), uuid.New()}) {
+		assert.Error(t, err)
+	}
+}
+
+func TestResultBuffer_SameMatchTwice(t *testing.T) {
+	writer := &fakeResultWriter{}
+	buf := NewResultBuffer(writer, 10, time.Hour, testLogger())
+	id := uuid.New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstDone := make(chan error, 1)
+	go func() { firstDone <- buf.Store(ctx, id, &domain.MatchResult{Winner: 1}) }()
+
+	require.Eventually(t, func() bool {
+		buf.mu.Lock()
+		defer buf.mu.Unlock()
+		return len(buf.pending) == 1
+	}, time.Second, time.Millisecond)
+
+	err := buf.Store(context.Background(), id, &domain.MatchResult{Winner: 2})
+	assert.True(t, errors.IsConflict(err))
+
+	cancel()
+	assert.NoError(t, <-firstDone)
+}
+
+func TestResultBuffer_CancelFlushesImmediately(t *testing.T) {
+	writer := &fakeResultWriter{}
+	buf := NewResultBuffer(writer, 10, time.Hour, testLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A cancelled worker context must not drop the result
+	err := buf.Store(ctx, uuid.New(), &domain.MatchResult{Winner: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, writer.batchSizes())
+}
+
+func TestResultBuffer_Close(t *testing.T) {
+	writer := &fakeResultWriter{}
+	buf := NewResultBuffer(writer, 10, time.Hour, testLogger())
+
+	done := make(chan error, 1)
+	go func() { done <- buf.Store(context.Background(), uuid.New(), &domain.MatchResult{Winner: 1}) }()
+
+	require.Eventually(t, func() bool {
+		buf.mu.Lock()
+		defer buf.mu.Unlock()
+		return len(buf.pending) == 1
+	}, time.Second, time.Millisecond)
+
+	buf.Close()
+	require.NoError(t, <-done)
+
+	// After Close results are written without waiting for a batch
+	require.NoError(t, buf.Store(context.Background(), uuid.New(), &domain.MatchResult{Winner: 1}))
+	assert.Equal(t, []int{1, 1}, writer.batchSizes())
+}
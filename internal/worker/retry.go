@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/executor"
+	"go.uber.org/zap"
+)
+
+// RetryPolicy - повторы выполнения матча внутри воркера при временных сбоях
+// инфраструктуры (executor.IsTransient). Ошибки программ не повторяются
+type RetryPolicy struct {
+	Attempts   int           // Повторов после первой попытки (0 - без повторов)
+	Backoff    time.Duration // Пауза перед первым повтором, удваивается с каждым следующим
+	MaxBackoff time.Duration // Предел паузы между повторами (0 - без предела)
+}
+
+// delay возвращает паузу перед повтором с номером attempt (с 1)
+func (r RetryPolicy) delay(attempt int) time.Duration {
+	delay := r.Backoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if r.MaxBackoff > 0 && delay >= r.MaxBackoff {
+			break
+		}
+	}
+	if r.MaxBackoff > 0 && delay > r.MaxBackoff {
+		return r.MaxBackoff
+	}
+	return delay
+}
+
+// SetRetryPolicy включает повторы выполнения матча при временных сбоях инфраструктуры.
+// Без неё такой матч сразу помечается failed (или возвращается в очередь, если daemon недоступен)
+func (p *Processor) SetRetryPolicy(policy RetryPolicy) {
+	p.retry = policy
+}
+
+// execute выполняет матч, повторяя попытку при временных сбоях инфраструктуры
+func (p *Processor) execute(ctx context.Context, run *matchRun) (*domain.MatchResult, error) {
+	result, err := p.executor.Execute(ctx, run.match, run.program1Path, run.program2Path, run.options)
+	return p.retryTransient(ctx, run, result, err)
+}
+
+// retryTransient повторяет выполнение матча, пока ошибка временная и повторы не исчерпаны.
+// Возвращает результат последней попытки
+func (p *Processor) retryTransient(ctx context.Context, run *matchRun, result *domain.MatchResult, err error) (*domain.MatchResult, error) {
+	match := run.match
+	for attempt := 1; attempt <= p.retry.Attempts && executor.IsTransient(err); attempt++ {
+		delay := p.retry.delay(attempt)
+		p.log.Warn("Transient executor error, retrying match",
+			zap.String("match_id", match.ID.String()),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", delay),
+			zap.Error(err),
+		)
+		if p.metrics != nil {
+			p.metrics.RecordExecutorRetry(match.GameType)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+
+		result, err = p.executor.Execute(ctx, match, run.program1Path, run.program2Path, run.options)
+	}
+	return result, err
+}
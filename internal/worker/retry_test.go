@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/executor"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{Attempts: 5, Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	assert.Equal(t, 100*time.Millisecond, policy.delay(1))
+	assert.Equal(t, 200*time.Millisecond, policy.delay(2))
+	assert.Equal(t, 300*time.Millisecond, policy.delay(3), "backoff is capped")
+	assert.Equal(t, 300*time.Millisecond, policy.delay(10))
+
+	uncapped := RetryPolicy{Backoff: time.Millisecond}
+	assert.Equal(t, 8*time.Millisecond, uncapped.delay(4))
+}
+
+// flakyExecutor fails with err for the first failures calls, then plays the match
+type flakyExecutor struct {
+	err      error
+	failures int32
+	calls    atomic.Int32
+}
+
+func (e *flakyExecutor) Execute(_ context.Context, match *domain.Match, _, _ string, _ executor.RunOptions) (*domain.MatchResult, error) {
+	if e.calls.Add(1) <= e.failures {
+		return nil, e.err
+	}
+	return &domain.MatchResult{MatchID: match.ID, Winner: 1, Score1: 3}, nil
+}
+
+func TestProcessor_RetriesTransientErrors(t *testing.T) {
+	policy := RetryPolicy{Attempts: 2, Backoff: time.Millisecond}
+	pullErr := errors.New("failed to run match: error pulling image: net/http: TLS handshake timeout")
+
+	t.Run("transient error recovers", func(t *testing.T) {
+		match := testMatch()
+		repo := newConditionalMatchRepo(match)
+		exec := &flakyExecutor{err: pullErr, failures: 2}
+
+		m := testMetrics()
+		before := testutil.ToFloat64(m.ExecutorRetries.WithLabelValues(match.GameType))
+
+		processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, exec, nil, testLogger())
+		processor.SetRetryPolicy(policy)
+		processor.SetMetrics(m)
+
+		require.NoError(t, processor.Process(context.Background(), match))
+		assert.Equal(t, int32(3), exec.calls.Load())
+		assert.Equal(t, domain.MatchCompleted, repo.status[match.ID])
+		assert.Equal(t, float64(2), testutil.ToFloat64(m.ExecutorRetries.WithLabelValues(match.GameType))-before)
+	})
+
+	t.Run("exhausted retries fail as infrastructure error", func(t *testing.T) {
+		match := testMatch()
+		repo := newConditionalMatchRepo(match)
+		exec := &flakyExecutor{err: pullErr, failures: 10}
+
+		processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, exec, nil, testLogger())
+		processor.SetRetryPolicy(policy)
+
+		require.Error(t, processor.Process(context.Background(), match))
+		assert.Equal(t, int32(3), exec.calls.Load())
+
+		saved := repo.results[match.ID]
+		require.NotNil(t, saved)
+		assert.True(t, saved.IsInfrastructureFailure())
+	})
+
+	t.Run("program error is not retried", func(t *testing.T) {
+		match := testMatch()
+		repo := newConditionalMatchRepo(match)
+		exec := &flakyExecutor{err: errors.New("invalid output format: expected 2 scores, got: "), failures: 10}
+
+		processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, exec, nil, testLogger())
+		processor.SetRetryPolicy(policy)
+
+		require.Error(t, processor.Process(context.Background(), match))
+		assert.Equal(t, int32(1), exec.calls.Load())
+
+		saved := repo.results[match.ID]
+		require.NotNil(t, saved)
+		assert.Equal(t, 1, saved.ErrorCode)
+	})
+
+	t.Run("daemon still unavailable releases match", func(t *testing.T) {
+		match := testMatch()
+		repo := newConditionalMatchRepo(match)
+		exec := &flakyExecutor{err: fmt.Errorf("failed to create container: %w", executor.ErrDockerUnavailable), failures: 10}
+
+		processor := NewProcessor(repo, staticRatingRepo{}, staticProgramRepo{}, &countingRatingService{}, exec, nil, testLogger())
+		processor.SetRetryPolicy(policy)
+
+		err := processor.Process(context.Background(), match)
+		require.Error(t, err)
+		assert.True(t, executor.IsDockerUnavailable(err))
+		assert.Equal(t, int32(3), exec.calls.Load())
+		assert.Empty(t, repo.results)
+		assert.Equal(t, domain.MatchPending, repo.status[match.ID])
+	})
+}
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_tournament_bans_tournament;
+DROP TABLE IF EXISTS tournament_bans;
+
+-- Cancelled matches were never played and must not come back as retryable failures
+-- (ResetFailedMatches would re-queue matches of kicked/banned programs), so drop them
+DELETE FROM matches WHERE status = 'cancelled';
+ALTER TABLE matches DROP CONSTRAINT IF EXISTS valid_status;
+ALTER TABLE matches ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'running', 'completed', 'failed'));
@@ -0,0 +1,16 @@
+-- Allow cancelled status for matches of kicked/banned participants
+ALTER TABLE matches DROP CONSTRAINT IF EXISTS valid_status;
+ALTER TABLE matches ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'running', 'completed', 'failed', 'cancelled'));
+
+-- Create tournament_bans table to prevent banned programs from re-joining
+CREATE TABLE IF NOT EXISTS tournament_bans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tournament_id UUID NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(tournament_id, program_id)
+);
+
+CREATE INDEX idx_tournament_bans_tournament ON tournament_bans(tournament_id);
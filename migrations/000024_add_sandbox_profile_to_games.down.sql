@@ -0,0 +1,3 @@
+-- Remove sandbox_profile from games table
+ALTER TABLE games DROP CONSTRAINT IF EXISTS valid_sandbox_profile;
+ALTER TABLE games DROP COLUMN IF EXISTS sandbox_profile;
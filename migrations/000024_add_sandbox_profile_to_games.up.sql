@@ -0,0 +1,10 @@
+-- Add sandbox_profile to games table to control container isolation per game
+-- strict: no network, read-only root filesystem, tmpfs for scratch
+-- relaxed: writable root filesystem, network if allowed by executor config
+
+ALTER TABLE games ADD COLUMN IF NOT EXISTS sandbox_profile VARCHAR(20) NOT NULL DEFAULT 'strict';
+
+ALTER TABLE games ADD CONSTRAINT valid_sandbox_profile
+    CHECK (sandbox_profile IN ('strict', 'relaxed'));
+
+COMMENT ON COLUMN games.sandbox_profile IS 'Container isolation profile for programs of this game: strict (default) or relaxed.';
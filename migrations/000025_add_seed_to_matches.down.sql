@@ -0,0 +1,2 @@
+-- Remove seed from matches table
+ALTER TABLE matches DROP COLUMN IF EXISTS seed;
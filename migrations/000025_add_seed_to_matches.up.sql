@@ -0,0 +1,14 @@
+-- Add seed to matches for reproducible randomized games
+-- The executor passes it to tjudge-cli via MATCH_SEED env var
+
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS seed BIGINT;
+
+-- Deterministic seed from the match UUID: first 4 bytes as unsigned integer
+-- Must stay in sync with domain.SeedFromID
+UPDATE matches
+SET seed = ('x' || lpad(substr(replace(id::text, '-', ''), 1, 8), 16, '0'))::bit(64)::bigint
+WHERE seed IS NULL;
+
+ALTER TABLE matches ALTER COLUMN seed SET NOT NULL;
+
+COMMENT ON COLUMN matches.seed IS 'Random seed passed to the game runner so the match can be reproduced locally.';
@@ -0,0 +1,2 @@
+-- Remove scheduled_at from matches table
+ALTER TABLE matches DROP COLUMN IF EXISTS scheduled_at;
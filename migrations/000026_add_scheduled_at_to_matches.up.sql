@@ -0,0 +1,6 @@
+-- Add scheduled_at to matches for rounds scheduled at a future time
+-- Scheduled matches wait in the queue:scheduled sorted set until this time
+
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP;
+
+COMMENT ON COLUMN matches.scheduled_at IS 'Match must not be executed before this time. NULL means run as soon as possible.';
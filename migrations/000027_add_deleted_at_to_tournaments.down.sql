@@ -0,0 +1,3 @@
+-- Remove soft delete from tournaments table
+DROP INDEX IF EXISTS idx_tournaments_deleted_at;
+ALTER TABLE tournaments DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,8 @@
+-- Soft delete for tournaments: deleted tournaments are hidden but keep their matches and participants
+-- Rows are removed for real only by the admin purge after the retention period
+
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_deleted_at ON tournaments(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN tournaments.deleted_at IS 'Soft delete time. NULL means the tournament is visible.';
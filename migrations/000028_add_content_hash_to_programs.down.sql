@@ -0,0 +1,3 @@
+-- Remove content hash from programs table
+DROP INDEX IF EXISTS idx_programs_team_game_hash;
+ALTER TABLE programs DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,9 @@
+-- SHA-256 of the uploaded program (after shebang normalization)
+-- Used to detect re-uploads of identical content for the same team and game
+
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_programs_team_game_hash ON programs(team_id, game_id, content_hash)
+    WHERE content_hash IS NOT NULL;
+
+COMMENT ON COLUMN programs.content_hash IS 'Hex SHA-256 of the stored program file. NULL for programs uploaded before hashing was added.';
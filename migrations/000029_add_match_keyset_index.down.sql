@@ -0,0 +1,2 @@
+-- Remove keyset pagination index
+DROP INDEX IF EXISTS idx_matches_tournament_round_id;
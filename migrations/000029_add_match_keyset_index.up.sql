@@ -0,0 +1,2 @@
+-- Index for keyset pagination of matches ordered by (round_number, id)
+CREATE INDEX IF NOT EXISTS idx_matches_tournament_round_id ON matches(tournament_id, round_number DESC, id DESC);
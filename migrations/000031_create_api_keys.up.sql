@@ -0,0 +1,19 @@
+-- Create api_keys table: per-user keys for scripted clients, an alternative to JWT.
+-- Only the SHA-256 hash of a key is stored; the key itself is shown once at creation
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    key_hash CHAR(64) UNIQUE NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('read', 'read_write')),
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id) WHERE revoked_at IS NULL;
+
+COMMENT ON COLUMN api_keys.prefix IS 'First characters of the key, shown in listings to tell keys apart';
+COMMENT ON COLUMN api_keys.last_used_at IS 'Updated at most once per cache TTL, not on every request';
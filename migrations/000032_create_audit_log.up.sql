@@ -0,0 +1,20 @@
+-- Create audit_log table: who performed which admin action and when.
+-- The request body is not stored, only its SHA-256 hash
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    impersonated_by UUID,
+    action VARCHAR(255) NOT NULL,
+    target_type VARCHAR(50),
+    target_id VARCHAR(100),
+    ip_address VARCHAR(255) NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    request_body_hash CHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- No foreign key on user_id: audit rows must outlive deleted users
+
+-- Create indexes
+CREATE INDEX idx_audit_log_user_created ON audit_log(user_id, created_at);
+CREATE INDEX idx_audit_log_created ON audit_log(created_at);
@@ -0,0 +1,2 @@
+-- Drop audit_log target index
+DROP INDEX IF EXISTS idx_audit_log_target;
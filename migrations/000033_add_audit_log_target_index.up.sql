@@ -0,0 +1,2 @@
+-- Look up the history of a single resource: GET /admin/audit?resource=tournament&id=...
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id, created_at);
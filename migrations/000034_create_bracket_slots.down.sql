@@ -0,0 +1,2 @@
+-- Drop bracket_slots table
+DROP TABLE IF EXISTS bracket_slots;
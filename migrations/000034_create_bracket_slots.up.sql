@@ -0,0 +1,24 @@
+-- Create bracket_slots table: single-elimination bracket of a tournament.
+-- A slot is a pair of entrants in a round; its winner moves to slot position/2 of the next round.
+-- match_id points to the current match of the pair (the latest one after a rematch)
+CREATE TABLE IF NOT EXISTS bracket_slots (
+    tournament_id UUID NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL CHECK (round >= 1),
+    position INTEGER NOT NULL CHECK (position >= 0),
+    program1_id UUID,
+    program2_id UUID,
+    seed1 INTEGER,
+    seed2 INTEGER,
+    match_id UUID,
+    winner_id UUID,
+    rematches INTEGER NOT NULL DEFAULT 0,
+    is_bye BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tournament_id, round, position)
+);
+
+-- No foreign key on match_id: matches are partitioned by created_at
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_bracket_slots_match_id ON bracket_slots(match_id) WHERE match_id IS NOT NULL;
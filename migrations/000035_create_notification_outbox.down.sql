@@ -0,0 +1,3 @@
+-- Drop notification_outbox table and notification preferences
+DROP TABLE IF EXISTS notification_outbox;
+ALTER TABLE users DROP COLUMN IF EXISTS notification_preferences;
@@ -0,0 +1,29 @@
+-- Per-user notification preferences: event type -> enabled.
+-- Events missing from the map are enabled
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notification_preferences JSONB NOT NULL DEFAULT '{}';
+
+-- Create notification_outbox table: notifications waiting for delivery.
+-- Rows are written when an event happens and delivered by the worker's dispatcher,
+-- so events are not lost while the mail server is unavailable
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL DEFAULT 'email',
+    recipient VARCHAR(255) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    dedupe_key VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP,
+    CONSTRAINT valid_notification_status CHECK (status IN ('pending', 'sent', 'failed')),
+    -- The same event is delivered to a user at most once
+    UNIQUE (user_id, channel, dedupe_key)
+);
+
+-- Create indexes
+CREATE INDEX idx_notification_outbox_due ON notification_outbox(next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,2 @@
+-- Remove round_active column from tournament_games table
+ALTER TABLE tournament_games DROP COLUMN IF EXISTS round_active;
@@ -0,0 +1,4 @@
+-- Track a running round per game so games of one tournament progress independently.
+-- Set when an admin starts a game round, cleared once the game has no pending or
+-- running matches left (or the round is marked completed / reset)
+ALTER TABLE tournament_games ADD COLUMN IF NOT EXISTS round_active BOOLEAN NOT NULL DEFAULT false;
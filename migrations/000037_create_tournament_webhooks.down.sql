@@ -0,0 +1,10 @@
+-- Drop webhook deliveries and tournament_webhooks table
+DELETE FROM notification_outbox WHERE webhook_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_notification_outbox_webhook;
+DROP INDEX IF EXISTS idx_notification_outbox_webhook_dedupe;
+ALTER TABLE notification_outbox DROP CONSTRAINT IF EXISTS notification_outbox_target;
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS webhook_id;
+ALTER TABLE notification_outbox ALTER COLUMN recipient TYPE VARCHAR(255);
+ALTER TABLE notification_outbox ALTER COLUMN user_id SET NOT NULL;
+
+DROP TABLE IF EXISTS tournament_webhooks;
@@ -0,0 +1,28 @@
+-- Create tournament_webhooks table: external URLs notified about tournament lifecycle events.
+-- The secret signs every payload (HMAC-SHA256), so it is stored as is
+CREATE TABLE IF NOT EXISTS tournament_webhooks (
+    id UUID PRIMARY KEY,
+    tournament_id UUID NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    events TEXT[] NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_tournament_webhooks_tournament ON tournament_webhooks(tournament_id);
+
+-- Webhook deliveries go through the notification outbox: a row is addressed
+-- either to a user (email) or to a webhook, and its history is the delivery log
+ALTER TABLE notification_outbox ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE notification_outbox ALTER COLUMN recipient TYPE TEXT;
+ALTER TABLE notification_outbox
+    ADD COLUMN IF NOT EXISTS webhook_id UUID REFERENCES tournament_webhooks(id) ON DELETE CASCADE;
+ALTER TABLE notification_outbox
+    ADD CONSTRAINT notification_outbox_target CHECK ((user_id IS NULL) <> (webhook_id IS NULL));
+
+-- The same event is delivered to a webhook at most once
+CREATE UNIQUE INDEX idx_notification_outbox_webhook_dedupe
+    ON notification_outbox(webhook_id, dedupe_key) WHERE webhook_id IS NOT NULL;
+CREATE INDEX idx_notification_outbox_webhook
+    ON notification_outbox(webhook_id, created_at DESC) WHERE webhook_id IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Remove reference programs and the system user that owns them
+DELETE FROM matches WHERE program1_id IN (SELECT id FROM programs WHERE is_reference)
+    OR program2_id IN (SELECT id FROM programs WHERE is_reference);
+DELETE FROM programs WHERE is_reference;
+
+DROP INDEX IF EXISTS idx_programs_reference_game;
+ALTER TABLE programs DROP CONSTRAINT IF EXISTS programs_reference_rating;
+ALTER TABLE programs DROP COLUMN IF EXISTS reference_retired_at;
+ALTER TABLE programs DROP COLUMN IF EXISTS reference_rating;
+ALTER TABLE programs DROP COLUMN IF EXISTS is_reference;
+
+DELETE FROM users WHERE id = '00000000-0000-0000-0000-000000000001';
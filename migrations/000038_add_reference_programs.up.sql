@@ -0,0 +1,19 @@
+-- Reference programs: known strategies registered by admins per game. They are
+-- owned by the system user, belong to no tournament or team and keep a fixed
+-- rating, so calibration matches against them seed the rating of a new entrant.
+-- Retired reference programs stay for their played matches but get no new ones
+INSERT INTO users (id, username, email, password_hash, role)
+VALUES ('00000000-0000-0000-0000-000000000001', 'tjudge-system', 'system@tjudge.local', '!', 'user')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS is_reference BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS reference_rating INTEGER;
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS reference_retired_at TIMESTAMP;
+ALTER TABLE programs
+    ADD CONSTRAINT programs_reference_rating CHECK (NOT is_reference OR reference_rating IS NOT NULL);
+
+CREATE INDEX idx_programs_reference_game ON programs(game_id)
+    WHERE is_reference AND reference_retired_at IS NULL;
+
+COMMENT ON COLUMN programs.is_reference IS 'Reference bot for calibration matches. Not a tournament participant.';
+COMMENT ON COLUMN programs.reference_rating IS 'Fixed rating of a reference bot used as the opponent rating in ELO updates.';
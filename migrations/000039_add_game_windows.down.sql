@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tournament_games_window_start;
+ALTER TABLE tournament_games DROP CONSTRAINT IF EXISTS tournament_games_window;
+ALTER TABLE tournament_games DROP COLUMN IF EXISTS window_triggered_at;
+ALTER TABLE tournament_games DROP COLUMN IF EXISTS window_end;
+ALTER TABLE tournament_games DROP COLUMN IF EXISTS window_start;
@@ -0,0 +1,19 @@
+-- Optional time window per tournament game: matches of the game are only executed
+-- between window_start and window_end, and the round is started at window_start.
+-- window_triggered_at records when the scheduler started the current window so it fires once
+ALTER TABLE tournament_games ADD COLUMN IF NOT EXISTS window_start TIMESTAMP;
+ALTER TABLE tournament_games ADD COLUMN IF NOT EXISTS window_end TIMESTAMP;
+ALTER TABLE tournament_games ADD COLUMN IF NOT EXISTS window_triggered_at TIMESTAMP;
+
+ALTER TABLE tournament_games ADD CONSTRAINT tournament_games_window CHECK (
+    (window_start IS NULL AND window_end IS NULL)
+    OR (window_start IS NOT NULL AND window_end IS NOT NULL AND window_end > window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_games_window_start
+ON tournament_games (window_start)
+WHERE window_start IS NOT NULL;
+
+COMMENT ON COLUMN tournament_games.window_start IS 'Start of the time slot when the game round runs (NULL - no window)';
+COMMENT ON COLUMN tournament_games.window_end IS 'End of the time slot; matches left after it wait for the next window';
+COMMENT ON COLUMN tournament_games.window_triggered_at IS 'When the scheduler started the round for the current window';
@@ -0,0 +1,3 @@
+-- Remove per-program status indexes
+DROP INDEX IF EXISTS idx_matches_program2_status;
+DROP INDEX IF EXISTS idx_matches_program1_status;
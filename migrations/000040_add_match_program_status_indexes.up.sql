@@ -0,0 +1,3 @@
+-- Per-program match counts by status: (program1_id = $1 OR program2_id = $1) AND status = $2
+CREATE INDEX IF NOT EXISTS idx_matches_program1_status ON matches(program1_id, status);
+CREATE INDEX IF NOT EXISTS idx_matches_program2_status ON matches(program2_id, status);
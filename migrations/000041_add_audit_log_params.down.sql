@@ -0,0 +1,3 @@
+-- Drop audit_log params
+DROP INDEX IF EXISTS idx_audit_log_target_id;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS params;
@@ -0,0 +1,5 @@
+-- Route and query parameters of the admin request (the body is still stored only as a hash)
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS params JSONB;
+
+-- Look up the history of an entity without knowing its type: GET /admin/audit?entity_id=...
+CREATE INDEX IF NOT EXISTS idx_audit_log_target_id ON audit_log(target_id, created_at);
@@ -0,0 +1,2 @@
+-- Drop program language version
+ALTER TABLE programs DROP COLUMN IF EXISTS language_version;
@@ -0,0 +1,3 @@
+-- Interpreter/compiler version the program was uploaded for (e.g. Python 3.11).
+-- Empty for languages without version pinning and for programs uploaded before it existed
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS language_version VARCHAR(16) NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Drop upload validation matches and program validation results
+DELETE FROM matches WHERE is_validation;
+ALTER TABLE matches DROP CONSTRAINT IF EXISTS matches_validation_is_test;
+ALTER TABLE matches DROP COLUMN IF EXISTS is_validation;
+
+ALTER TABLE programs DROP CONSTRAINT IF EXISTS programs_validation_status;
+ALTER TABLE programs DROP COLUMN IF EXISTS validation_error;
+ALTER TABLE programs DROP COLUMN IF EXISTS validation_status;
+
+ALTER TABLE games DROP COLUMN IF EXISTS validation_bot_id;
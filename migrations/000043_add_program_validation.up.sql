@@ -0,0 +1,17 @@
+-- Smoke-test validation on upload: a new program plays one low-priority match
+-- against the reference bot chosen for its game. The match is a test match
+-- (no ratings, no leaderboards), its outcome is stored on the program
+ALTER TABLE games ADD COLUMN IF NOT EXISTS validation_bot_id UUID REFERENCES programs(id) ON DELETE SET NULL;
+
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS validation_status VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS validation_error TEXT;
+ALTER TABLE programs
+    ADD CONSTRAINT programs_validation_status CHECK (validation_status IN ('', 'pending', 'passed', 'failed'));
+
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS is_validation BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE matches
+    ADD CONSTRAINT matches_validation_is_test CHECK (NOT is_validation OR is_test);
+
+COMMENT ON COLUMN games.validation_bot_id IS 'Reference bot every uploaded program plays a validation match against. NULL disables validation.';
+COMMENT ON COLUMN programs.validation_status IS 'Outcome of the upload validation match: pending, passed or failed. Empty if not validated.';
+COMMENT ON COLUMN matches.is_validation IS 'Upload validation match. Always a test match; the result goes to the program, not to ratings.';
@@ -0,0 +1,37 @@
+-- Incremental aggregate for the cross-game leaderboard: per (tournament, team, game)
+-- match statistics, updated in the same transaction that records a match result.
+-- score is the raw sum of the team's match scores; the game's score_multiplier is
+-- applied on read, so changing the multiplier does not require a rebuild
+CREATE TABLE IF NOT EXISTS team_game_stats (
+    tournament_id UUID NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    wins INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    draws INTEGER NOT NULL DEFAULT 0,
+    total_games INTEGER NOT NULL DEFAULT 0,
+    score BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tournament_id, team_id, game_id)
+);
+
+-- Backfill from the existing match history
+INSERT INTO team_game_stats (tournament_id, team_id, game_id, wins, losses, draws, total_games, score)
+SELECT m.tournament_id, p.team_id, g.id,
+       COUNT(*) FILTER (WHERE (m.program1_id = p.id AND m.winner = 1) OR (m.program2_id = p.id AND m.winner = 2)),
+       COUNT(*) FILTER (WHERE (m.program1_id = p.id AND m.winner = 2) OR (m.program2_id = p.id AND m.winner = 1)),
+       COUNT(*) FILTER (WHERE m.winner = 0 AND m.status = 'completed'),
+       COUNT(*) FILTER (WHERE m.status = 'completed'),
+       COALESCE(SUM(CASE WHEN m.program1_id = p.id THEN COALESCE(m.score1, 0) ELSE COALESCE(m.score2, 0) END), 0)
+FROM matches m
+JOIN programs p ON p.id IN (m.program1_id, m.program2_id)
+JOIN games g ON g.name = m.game_type
+WHERE m.tournament_id IS NOT NULL
+  AND m.status IN ('completed', 'failed')
+  AND NOT m.is_test
+  AND p.team_id IS NOT NULL
+GROUP BY m.tournament_id, p.team_id, g.id
+ON CONFLICT (tournament_id, team_id, game_id) DO NOTHING;
+
+COMMENT ON TABLE team_game_stats IS 'Cross-game leaderboard aggregate: match statistics of a team per tournament game. Rebuilt by POST /admin/tournaments/{id}/cross-game-stats/rebuild.';
+COMMENT ON COLUMN team_game_stats.score IS 'Sum of raw match scores; multiplied by games.score_multiplier on read.';
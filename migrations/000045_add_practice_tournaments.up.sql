@@ -0,0 +1,60 @@
+-- Practice tournaments: a sandbox where matches run at low priority and never age,
+-- and whose results stay out of the global leaderboard. The tournament's own
+-- leaderboard is its practice leaderboard
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS is_practice BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS is_practice BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN tournaments.is_practice IS 'Practice tournament. Its matches run at low priority and are excluded from the global leaderboard.';
+COMMENT ON COLUMN matches.is_practice IS 'Match of a practice tournament. Never promoted by priority aging, excluded from the global leaderboard.';
+
+DROP MATERIALIZED VIEW IF EXISTS leaderboard_global;
+
+-- Recreate global leaderboard without practice matches
+CREATE MATERIALIZED VIEW IF NOT EXISTS leaderboard_global AS
+SELECT
+    p.id AS program_id,
+    p.name AS program_name,
+    p.user_id,
+    u.username,
+    COALESCE(stats.total_score, 0) AS rating,
+    COALESCE(stats.total_matches, 0) AS total_matches,
+    COALESCE(stats.wins, 0) AS wins,
+    COALESCE(stats.losses, 0) AS losses,
+    COALESCE(stats.draws, 0) AS draws,
+    COALESCE(stats.last_match, p.created_at) AS last_updated
+FROM programs p
+INNER JOIN users u ON p.user_id = u.id
+LEFT JOIN LATERAL (
+    SELECT
+        COUNT(*) AS total_matches,
+        SUM(CASE
+            WHEN (m.program1_id = p.id AND m.winner = 1) OR (m.program2_id = p.id AND m.winner = 2)
+            THEN 1 ELSE 0
+        END) AS wins,
+        SUM(CASE
+            WHEN (m.program1_id = p.id AND m.winner = 2) OR (m.program2_id = p.id AND m.winner = 1)
+            THEN 1 ELSE 0
+        END) AS losses,
+        SUM(CASE WHEN m.winner = 0 THEN 1 ELSE 0 END) AS draws,
+        SUM(
+            CASE
+                WHEN m.program1_id = p.id THEN COALESCE(m.score1, 0)
+                WHEN m.program2_id = p.id THEN COALESCE(m.score2, 0)
+                ELSE 0
+            END
+        ) AS total_score,
+        MAX(m.completed_at) AS last_match
+    FROM matches m
+    WHERE (m.program1_id = p.id OR m.program2_id = p.id)
+      AND m.status = 'completed'
+      AND NOT m.is_test
+      AND NOT m.is_practice
+) stats ON true
+ORDER BY rating DESC, total_matches DESC;
+
+-- Create indexes on global leaderboard
+CREATE UNIQUE INDEX idx_leaderboard_global_program ON leaderboard_global(program_id);
+CREATE INDEX idx_leaderboard_global_rating ON leaderboard_global(rating DESC);
+CREATE INDEX idx_leaderboard_global_user ON leaderboard_global(user_id);
+
+GRANT SELECT ON leaderboard_global TO PUBLIC;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS match_result_adjustments;
@@ -0,0 +1,21 @@
+-- History of manual corrections of match results by admins.
+-- Each row keeps the result before the change and the mandatory reason;
+-- for an annulment the new_* columns are NULL and the match is back to pending
+CREATE TABLE IF NOT EXISTS match_result_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    match_id UUID NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('edit', 'annul')),
+    old_status VARCHAR(20) NOT NULL,
+    old_score1 INTEGER,
+    old_score2 INTEGER,
+    old_winner INTEGER,
+    new_score1 INTEGER,
+    new_score2 INTEGER,
+    new_winner INTEGER,
+    reason TEXT NOT NULL,
+    adjusted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_result_adjustments_match
+    ON match_result_adjustments (match_id, created_at DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tournaments_visibility;
+ALTER TABLE tournaments DROP COLUMN IF EXISTS visibility;
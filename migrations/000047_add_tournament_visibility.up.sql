@@ -0,0 +1,9 @@
+-- Tournament visibility: public tournaments are listed and viewable by anyone,
+-- unlisted ones are reachable only by ID or code, private ones only by team
+-- members of the tournament, its creator and admins
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private'));
+
+COMMENT ON COLUMN tournaments.visibility IS 'public - listed and viewable by anyone; unlisted - reachable by ID or code only; private - team members, creator and admins only.';
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_visibility ON tournaments(visibility) WHERE deleted_at IS NULL;
@@ -0,0 +1,5 @@
+-- Per-match timing breakdown recorded by the worker: queue wait, program preparation,
+-- container start, execution and result persistence, in milliseconds
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS timing JSONB;
+
+COMMENT ON COLUMN matches.timing IS 'Processing phase durations in ms: queue_wait_ms, prepare_ms, start_ms, execution_ms, persist_ms. NULL - not recorded.';
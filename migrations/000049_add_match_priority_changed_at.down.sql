@@ -0,0 +1 @@
+ALTER TABLE matches DROP COLUMN IF EXISTS priority_changed_at;
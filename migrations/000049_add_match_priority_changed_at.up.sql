@@ -0,0 +1,5 @@
+-- Time the match priority last changed (escalation or manual reprioritization).
+-- Escalation counts a match's age from it, so every step waits a full escalation age
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS priority_changed_at TIMESTAMP;
+
+COMMENT ON COLUMN matches.priority_changed_at IS 'Last priority change. NULL - priority unchanged since created_at.';
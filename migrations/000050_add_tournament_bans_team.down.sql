@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_tournament_bans_team;
+
+DELETE FROM tournament_bans WHERE program_id IS NULL;
+
+ALTER TABLE tournament_bans DROP CONSTRAINT IF EXISTS tournament_bans_program_id_fkey;
+ALTER TABLE tournament_bans ADD CONSTRAINT tournament_bans_program_id_fkey
+    FOREIGN KEY (program_id) REFERENCES programs(id) ON DELETE CASCADE;
+ALTER TABLE tournament_bans ALTER COLUMN program_id SET NOT NULL;
+
+ALTER TABLE tournament_bans DROP COLUMN IF EXISTS team_id;
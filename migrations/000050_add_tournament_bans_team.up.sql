@@ -0,0 +1,18 @@
+-- Bans apply to the team of the banned program: every upload creates a new program,
+-- so a program-only ban would let the team rejoin with its next version
+ALTER TABLE tournament_bans ADD COLUMN IF NOT EXISTS team_id UUID REFERENCES teams(id) ON DELETE CASCADE;
+
+UPDATE tournament_bans b
+SET team_id = p.team_id
+FROM programs p
+WHERE p.id = b.program_id AND b.team_id IS NULL;
+
+-- The ban outlives the banned program: deleting it must not lift the team ban
+ALTER TABLE tournament_bans ALTER COLUMN program_id DROP NOT NULL;
+ALTER TABLE tournament_bans DROP CONSTRAINT IF EXISTS tournament_bans_program_id_fkey;
+ALTER TABLE tournament_bans ADD CONSTRAINT tournament_bans_program_id_fkey
+    FOREIGN KEY (program_id) REFERENCES programs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tournament_bans_team ON tournament_bans(tournament_id, team_id);
+
+COMMENT ON COLUMN tournament_bans.team_id IS 'Team of the banned program; all its programs are banned. NULL - program without a team.';
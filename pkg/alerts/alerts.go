@@ -0,0 +1,106 @@
+// Package alerts генерирует правила алертинга Prometheus для критических состояний
+// очереди, воркеров и матчей. Правила строятся по метрикам из pkg/metrics
+package alerts
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// header предупреждает, что файл перезаписывается make alerts
+const header = "# Сгенерировано make alerts (cmd/alerts), не редактировать вручную\n"
+
+// RuleFile файл правил в формате Prometheus
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup группа правил
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule правило алертинга
+type Rule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// CriticalRules возвращает правила для состояний, требующих вмешательства дежурного.
+// Алерты с severity=critical Alertmanager направляет в critical-receiver.
+// Правила для dead-letter очереди нет: неуспешные матчи помечаются failed
+// и в отдельную очередь не попадают, их долю отслеживает MatchFailureRateHigh
+func CriticalRules() RuleFile {
+	return RuleFile{Groups: []RuleGroup{
+		{
+			Name: "tjudge-critical",
+			Rules: []Rule{
+				{
+					Alert: "MatchFailureRateHigh",
+					Expr: `sum(increase(tjudge_matches_total{status="failed"}[10m]))
+  / sum(increase(tjudge_matches_total{status=~"completed|failed"}[10m])) > 0.2`,
+					For:    "5m",
+					Labels: map[string]string{"severity": "critical", "service": "worker"},
+					Annotations: map[string]string{
+						"summary":     "Высокая доля неуспешных матчей",
+						"description": "За последние 10 минут с ошибкой завершилось {{ $value | humanizePercentage }} матчей (порог 20%).",
+					},
+				},
+				{
+					Alert:  "WorkerPoolAtMax",
+					Expr:   "tjudge_worker_pool_max_size > 0 and tjudge_worker_pool_size >= tjudge_worker_pool_max_size",
+					For:    "30m",
+					Labels: map[string]string{"severity": "critical", "service": "worker"},
+					Annotations: map[string]string{
+						"summary":     "Пул воркеров упёрся в максимум",
+						"description": "Пул {{ $labels.instance }} 30 минут работает на WORKER_MAX воркерах: очередь не успевает разбираться, нужен ещё worker.",
+					},
+				},
+				{
+					Alert: "LeaderboardCacheMissRateHigh",
+					Expr: `sum(rate(tjudge_cache_misses_total{cache_type="leaderboard"}[10m]))
+  / (sum(rate(tjudge_cache_hits_total{cache_type="leaderboard"}[10m])) + sum(rate(tjudge_cache_misses_total{cache_type="leaderboard"}[10m]))) > 0.5`,
+					For:    "15m",
+					Labels: map[string]string{"severity": "warning", "service": "api"},
+					Annotations: map[string]string{
+						"summary":     "Кэш таблицы лидеров почти не работает",
+						"description": "Более 50% запросов таблицы лидеров идут мимо кэша: проверьте Redis и инвалидацию.",
+					},
+				},
+			},
+		},
+	}}
+}
+
+// WritePrometheusAlertRules записывает CriticalRules в outputPath, создавая каталог при необходимости
+func WritePrometheusAlertRules(outputPath string) error {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(CriticalRules()); err != nil {
+		return fmt.Errorf("failed to encode alert rules: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode alert rules: %w", err)
+	}
+
+	if dir := filepath.Dir(outputPath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for alert rules: %w", err)
+		}
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write alert rules: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package alerts
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/prometheus/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheusAlertRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prometheus", "alerts.yaml")
+	require.NoError(t, WritePrometheusAlertRules(path))
+
+	// Load the file the same way the Prometheus rule manager does
+	loader := rules.FileLoader{}
+	groups, errs := loader.Load(path)
+	require.Empty(t, errs)
+	require.Len(t, groups.Groups, 1)
+
+	var names []string
+	for _, rule := range groups.Groups[0].Rules {
+		_, err := loader.Parse(rule.Expr.Value)
+		require.NoError(t, err, rule.Alert.Value)
+		assert.NotEmpty(t, rule.Labels["severity"], rule.Alert.Value)
+		names = append(names, rule.Alert.Value)
+	}
+	assert.ElementsMatch(t, []string{"MatchFailureRateHigh", "WorkerPoolAtMax", "LeaderboardCacheMissRateHigh"}, names)
+}
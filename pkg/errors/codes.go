@@ -0,0 +1,60 @@
+package errors
+
+import "net/http"
+
+// ErrorCode - стабильный машиночитаемый код ошибки в JSON ответах API.
+// В отличие от сообщения, код не меняется и на него могут опираться клиентские SDK
+type ErrorCode string
+
+const (
+	CodeBadRequest         ErrorCode = "bad_request"
+	CodeUnauthorized       ErrorCode = "unauthorized"
+	CodeForbidden          ErrorCode = "forbidden"
+	CodeNotFound           ErrorCode = "not_found"
+	CodeConflict           ErrorCode = "conflict"
+	CodeRateLimited        ErrorCode = "rate_limited"
+	CodeInternal           ErrorCode = "internal"
+	CodeServiceUnavailable ErrorCode = "service_unavailable"
+	CodeTimeout            ErrorCode = "timeout"
+)
+
+// statusCodes соответствие HTTP кодов и кодов ошибок: каждому HTTP коду ровно один ErrorCode
+var statusCodes = map[int]ErrorCode{
+	http.StatusBadRequest:          CodeBadRequest,
+	http.StatusUnauthorized:        CodeUnauthorized,
+	http.StatusForbidden:           CodeForbidden,
+	http.StatusNotFound:            CodeNotFound,
+	http.StatusConflict:            CodeConflict,
+	http.StatusTooManyRequests:     CodeRateLimited,
+	http.StatusInternalServerError: CodeInternal,
+	http.StatusServiceUnavailable:  CodeServiceUnavailable,
+	http.StatusGatewayTimeout:      CodeTimeout,
+}
+
+// CodeForStatus возвращает код ошибки для HTTP кода.
+// Неизвестные коды сводятся к bad_request (4xx) и internal (остальные)
+func CodeForStatus(status int) ErrorCode {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= 400 && status < 500 {
+		return CodeBadRequest
+	}
+	return CodeInternal
+}
+
+// ErrorResponse тело HTTP ответа с ошибкой: {"error": {"code": "not_found", "message": "..."}}
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody код и сообщение ошибки
+type ErrorBody struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// ToResponse возвращает тело HTTP ответа для ошибки
+func (e *AppError) ToResponse() ErrorResponse {
+	return ErrorResponse{Error: ErrorBody{Code: e.ErrorCode, Message: e.Message}}
+}
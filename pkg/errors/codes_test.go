@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusCodes_OneCodePerStatus(t *testing.T) {
+	statuses := make(map[ErrorCode]int)
+	for status, code := range statusCodes {
+		other, seen := statuses[code]
+		assert.False(t, seen, "code %s is used by statuses %d and %d", code, status, other)
+		statuses[code] = status
+		assert.Equal(t, code, CodeForStatus(status))
+	}
+}
+
+func TestSentinels_HaveCodeOfTheirStatus(t *testing.T) {
+	sentinels := []*AppError{
+		ErrUnauthorized, ErrInvalidToken, ErrTokenExpired, ErrInvalidCredentials,
+		ErrValidation, ErrInvalidInput, ErrBadRequest, ErrMissingField,
+		ErrNotFound, ErrAlreadyExists, ErrConflict,
+		ErrForbidden, ErrPermissionDenied,
+		ErrRateLimitExceeded,
+		ErrInternal, ErrServiceUnavailable, ErrTimeout,
+		ErrTournamentFull, ErrTeamFull, ErrTournamentStarted, ErrTournamentNotStarted,
+		ErrInvalidGameType, ErrMatchInProgress, ErrProgramNotFound, ErrConcurrentUpdate,
+	}
+
+	for _, err := range sentinels {
+		_, known := statusCodes[err.Code]
+		assert.True(t, known, "status %d of %q has no error code", err.Code, err.Message)
+		assert.Equal(t, CodeForStatus(err.Code), err.ErrorCode, err.Message)
+	}
+}
+
+func TestCodeForStatus_Unknown(t *testing.T) {
+	assert.Equal(t, CodeBadRequest, CodeForStatus(http.StatusTeapot))
+	assert.Equal(t, CodeInternal, CodeForStatus(http.StatusBadGateway))
+}
+
+func TestAppError_CodeSurvivesCopies(t *testing.T) {
+	err := ErrNotFound.WithMessage("tournament not found").WithError(assert.AnError)
+
+	assert.Equal(t, CodeNotFound, err.ErrorCode)
+}
+
+func TestAppError_ToResponse(t *testing.T) {
+	data, err := json.Marshal(ErrConflict.WithMessage(`name "cup" is taken`).ToResponse())
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"error":{"code":"conflict","message":"name \"cup\" is taken"}}`, string(data))
+}
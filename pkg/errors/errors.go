@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -8,9 +9,10 @@ import (
 
 // AppError - кастомная ошибка приложения с HTTP кодом
 type AppError struct {
-	Code    int    // HTTP код
-	Message string // Сообщение для пользователя
-	Err     error  // Внутренняя ошибка
+	Code      int       // HTTP код
+	ErrorCode ErrorCode // Машиночитаемый код для клиентов
+	Message   string    // Сообщение для пользователя
+	Err       error     // Внутренняя ошибка
 }
 
 // Error реализует интерфейс error
@@ -29,9 +31,10 @@ func (e *AppError) Unwrap() error {
 // New создаёт новую ошибку приложения
 func New(code int, message string, err error) *AppError {
 	return &AppError{
-		Code:    code,
-		Message: message,
-		Err:     err,
+		Code:      code,
+		ErrorCode: CodeForStatus(code),
+		Message:   message,
+		Err:       err,
 	}
 }
 
@@ -74,9 +77,11 @@ var (
 	ErrInternal           = New(http.StatusInternalServerError, "Internal server error", nil)
 	ErrServiceUnavailable = New(http.StatusServiceUnavailable, "Service unavailable", nil)
 	ErrTimeout            = New(http.StatusGatewayTimeout, "Request timeout", nil)
+	ErrQueryTimeout       = New(http.StatusServiceUnavailable, "Query timed out", nil)
 
 	// Business logic errors
 	ErrTournamentFull       = New(http.StatusConflict, "Tournament is full", nil)
+	ErrTeamFull             = New(http.StatusConflict, "Team is full", nil)
 	ErrTournamentStarted    = New(http.StatusConflict, "Tournament already started", nil)
 	ErrTournamentNotStarted = New(http.StatusConflict, "Tournament not started yet", nil)
 	ErrInvalidGameType      = New(http.StatusBadRequest, "Invalid game type", nil)
@@ -88,18 +93,20 @@ var (
 // WithMessage создаёт новую ошибку с кастомным сообщением
 func (e *AppError) WithMessage(msg string) *AppError {
 	return &AppError{
-		Code:    e.Code,
-		Message: msg,
-		Err:     e.Err,
+		Code:      e.Code,
+		ErrorCode: e.ErrorCode,
+		Message:   msg,
+		Err:       e.Err,
 	}
 }
 
 // WithError добавляет внутреннюю ошибку
 func (e *AppError) WithError(err error) *AppError {
 	return &AppError{
-		Code:    e.Code,
-		Message: e.Message,
-		Err:     err,
+		Code:      e.Code,
+		ErrorCode: e.ErrorCode,
+		Message:   e.Message,
+		Err:       err,
 	}
 }
 
@@ -128,9 +135,27 @@ func ToAppError(err error) *AppError {
 		return appErr
 	}
 
+	if IsQueryCanceled(err) {
+		return ErrQueryTimeout.WithError(err)
+	}
+
 	return ErrInternal.WithError(err)
 }
 
+// queryCanceledState SQLSTATE отменённого запроса: statement_timeout или отмена контекста
+const queryCanceledState = "57014"
+
+// IsQueryCanceled проверяет, что запрос прерван по таймауту или отмене контекста.
+// Драйвер PostgreSQL возвращает либо ошибку контекста, либо ошибку с SQLSTATE 57014
+func IsQueryCanceled(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	var sqlErr interface{ SQLState() string }
+	return errors.As(err, &sqlErr) && sqlErr.SQLState() == queryCanceledState
+}
+
 // IsNotFound проверяет, является ли ошибка типом "not found"
 func IsNotFound(err error) bool {
 	appErr := GetAppError(err)
@@ -139,3 +164,12 @@ func IsNotFound(err error) bool {
 	}
 	return false
 }
+
+// IsConflict проверяет, является ли ошибка типом "conflict"
+func IsConflict(err error) bool {
+	appErr := GetAppError(err)
+	if appErr != nil {
+		return appErr.Code == http.StatusConflict
+	}
+	return false
+}
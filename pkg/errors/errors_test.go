@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -230,6 +231,29 @@ func TestToAppError_RegularError(t *testing.T) {
 	assert.Contains(t, result.Error(), "database connection failed")
 }
 
+// sqlStateError mimics *pq.Error without importing the driver
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "pq: canceling statement due to statement timeout" }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestToAppError_QueryCanceled(t *testing.T) {
+	for _, err := range []error{
+		fmt.Errorf("failed to get leaderboard: %w", context.DeadlineExceeded),
+		fmt.Errorf("failed to get matches: %w", context.Canceled),
+		fmt.Errorf("failed to get leaderboard: %w", sqlStateError("57014")),
+	} {
+		result := ToAppError(err)
+
+		assert.Equal(t, http.StatusServiceUnavailable, result.Code, err.Error())
+		assert.Equal(t, CodeServiceUnavailable, result.ErrorCode)
+		assert.Equal(t, "Query timed out", result.Message)
+	}
+
+	// Other SQL errors stay internal
+	assert.Equal(t, http.StatusInternalServerError, ToAppError(sqlStateError("23505")).Code)
+}
+
 func TestToAppError_Nil(t *testing.T) {
 	result := ToAppError(nil)
 
@@ -19,14 +19,27 @@ type Metrics struct {
 	MatchesTotal      *prometheus.CounterVec
 	MatchDuration     *prometheus.HistogramVec
 	MatchesInProgress prometheus.Gauge
+	DuplicateResults  *prometheus.CounterVec
+	OOMKills          *prometheus.CounterVec
+	ExecutorRetries   *prometheus.CounterVec
+	MatchPhase        *prometheus.HistogramVec
 
 	// Queue метрики
-	QueueSize     *prometheus.GaugeVec
-	QueueWaitTime *prometheus.HistogramVec
+	QueueSize       *prometheus.GaugeVec
+	QueueWaitTime   *prometheus.HistogramVec
+	QueueReconciled prometheus.Counter
 
 	// Worker метрики
-	ActiveWorkers  prometheus.Gauge
-	WorkerPoolSize prometheus.Gauge
+	ActiveWorkers     prometheus.Gauge
+	WorkerPoolSize    prometheus.Gauge
+	WorkerPoolMax     prometheus.Gauge
+	DockerUnavailable prometheus.Gauge
+	ResultFlushes     *prometheus.CounterVec
+	ResultFlushSize   prometheus.Histogram
+	DrainTimeouts     prometheus.Counter
+	WarmContainers    *prometheus.CounterVec
+	WarmRecycles      *prometheus.CounterVec
+	Escalations       *prometheus.CounterVec
 
 	// HTTP метрики
 	HTTPRequestsTotal    *prometheus.CounterVec
@@ -75,6 +88,35 @@ func newMetrics() *Metrics {
 				Help: "Number of matches currently being processed",
 			},
 		),
+		DuplicateResults: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tjudge_match_duplicate_results_total",
+				Help: "Results discarded because the match was already completed by another execution",
+			},
+			[]string{"game_type"},
+		),
+		OOMKills: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tjudge_match_oom_kills_total",
+				Help: "Matches stopped because a program exceeded the memory limit",
+			},
+			[]string{"game_type"},
+		),
+		ExecutorRetries: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tjudge_executor_retries_total",
+				Help: "Match executions retried after a transient infrastructure error",
+			},
+			[]string{"game_type"},
+		),
+		MatchPhase: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "tjudge_match_phase_duration_seconds",
+				Help:    "Duration of match processing phases: prepare, start, execution, persist",
+				Buckets: prometheus.ExponentialBuckets(0.005, 2, 15), // 5ms to ~80s
+			},
+			[]string{"phase", "game_type"},
+		),
 
 		// Queue метрики
 		QueueSize: promauto.NewGaugeVec(
@@ -88,10 +130,16 @@ func newMetrics() *Metrics {
 			prometheus.HistogramOpts{
 				Name:    "tjudge_queue_wait_time_seconds",
 				Help:    "Time spent waiting in queue",
-				Buckets: prometheus.ExponentialBuckets(0.01, 2, 10),
+				Buckets: prometheus.ExponentialBuckets(0.1, 2, 16), // 0.1s to ~55m
 			},
 			[]string{"priority"},
 		),
+		QueueReconciled: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "tjudge_queue_reconciled_total",
+				Help: "Pending matches re-enqueued because they were missing from the queue",
+			},
+		),
 
 		// Worker метрики
 		ActiveWorkers: promauto.NewGauge(
@@ -106,6 +154,59 @@ func newMetrics() *Metrics {
 				Help: "Total size of worker pool",
 			},
 		),
+		WorkerPoolMax: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "tjudge_worker_pool_max_size",
+				Help: "Upper bound of the worker pool autoscaler",
+			},
+		),
+		DockerUnavailable: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "tjudge_worker_docker_unavailable",
+				Help: "1 while the worker pool is paused because the Docker daemon is unreachable",
+			},
+		),
+		ResultFlushes: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tjudge_worker_result_flushes_total",
+				Help: "Batches of buffered match results written to the database",
+			},
+			[]string{"trigger"}, // "size", "age", "cancel", "close"
+		),
+		ResultFlushSize: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "tjudge_worker_result_flush_size",
+				Help:    "Number of match results written by one flush",
+				Buckets: prometheus.ExponentialBuckets(1, 2, 8),
+			},
+		),
+		DrainTimeouts: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "tjudge_worker_drain_timeout_total",
+				Help: "Worker pool shutdowns that interrupted running matches after the drain timeout",
+			},
+		),
+		WarmContainers: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tjudge_executor_warm_containers_total",
+				Help: "Matches by whether they reused an idle warm container",
+			},
+			[]string{"result"}, // "hit", "miss"
+		),
+		WarmRecycles: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tjudge_executor_warm_recycles_total",
+				Help: "Warm containers removed from the pool",
+			},
+			[]string{"reason"}, // "max_uses", "error", "overflow"
+		),
+		Escalations: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tjudge_worker_escalations_total",
+				Help: "Pending matches whose priority was raised after waiting too long",
+			},
+			[]string{"priority"}, // новый приоритет: "medium", "high"
+		),
 
 		// HTTP метрики
 		HTTPRequestsTotal: promauto.NewCounterVec(
@@ -170,6 +271,56 @@ func (m *Metrics) RecordMatchStart() {
 	m.MatchesInProgress.Inc()
 }
 
+// RecordDuplicateResult записывает отброшенный результат повторного выполнения матча
+func (m *Metrics) RecordDuplicateResult(gameType string) {
+	m.DuplicateResults.WithLabelValues(gameType).Inc()
+}
+
+// RecordResultFlush записывает сохранение пакета результатов матчей и его размер
+func (m *Metrics) RecordResultFlush(trigger string, size int) {
+	m.ResultFlushes.WithLabelValues(trigger).Inc()
+	m.ResultFlushSize.Observe(float64(size))
+}
+
+// RecordDrainTimeout записывает остановку пула, прервавшую незавершённые матчи
+func (m *Metrics) RecordDrainTimeout() {
+	m.DrainTimeouts.Inc()
+}
+
+// RecordWarmContainer записывает, достался ли матчу прогретый контейнер из пула
+func (m *Metrics) RecordWarmContainer(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.WarmContainers.WithLabelValues(result).Inc()
+}
+
+// RecordWarmRecycle записывает удаление прогретого контейнера из пула
+func (m *Metrics) RecordWarmRecycle(reason string) {
+	m.WarmRecycles.WithLabelValues(reason).Inc()
+}
+
+// RecordEscalation записывает повышение приоритета долго ожидающего матча до priority
+func (m *Metrics) RecordEscalation(priority string) {
+	m.Escalations.WithLabelValues(priority).Inc()
+}
+
+// RecordOOMKill записывает матч, остановленный из-за превышения лимита памяти
+func (m *Metrics) RecordOOMKill(gameType string) {
+	m.OOMKills.WithLabelValues(gameType).Inc()
+}
+
+// RecordExecutorRetry записывает повтор выполнения матча после временного сбоя инфраструктуры
+func (m *Metrics) RecordExecutorRetry(gameType string) {
+	m.ExecutorRetries.WithLabelValues(gameType).Inc()
+}
+
+// RecordMatchPhase записывает длительность этапа обработки матча
+func (m *Metrics) RecordMatchPhase(phase, gameType string, duration time.Duration) {
+	m.MatchPhase.WithLabelValues(phase, gameType).Observe(duration.Seconds())
+}
+
 // RecordMatchComplete записывает завершение матча
 func (m *Metrics) RecordMatchComplete(gameType string, status string, duration time.Duration) {
 	m.MatchesInProgress.Dec()
@@ -203,6 +354,16 @@ func (m *Metrics) SetQueueSize(priority string, size int) {
 	m.QueueSize.WithLabelValues(priority).Set(float64(size))
 }
 
+// RecordQueueWait записывает время ожидания матча в очереди
+func (m *Metrics) RecordQueueWait(priority string, wait time.Duration) {
+	m.QueueWaitTime.WithLabelValues(priority).Observe(wait.Seconds())
+}
+
+// RecordQueueReconciled записывает pending матчи, возвращённые в потерявшую их очередь
+func (m *Metrics) RecordQueueReconciled(count int) {
+	m.QueueReconciled.Add(float64(count))
+}
+
 // SetActiveWorkers устанавливает количество активных воркеров
 func (m *Metrics) SetActiveWorkers(count int) {
 	m.ActiveWorkers.Set(float64(count))
@@ -213,6 +374,20 @@ func (m *Metrics) SetWorkerPoolSize(size int) {
 	m.WorkerPoolSize.Set(float64(size))
 }
 
+// SetWorkerPoolMax устанавливает верхнюю границу размера пула воркеров
+func (m *Metrics) SetWorkerPoolMax(size int) {
+	m.WorkerPoolMax.Set(float64(size))
+}
+
+// SetDockerUnavailable отмечает недоступность Docker daemon для пула воркеров
+func (m *Metrics) SetDockerUnavailable(unavailable bool) {
+	if unavailable {
+		m.DockerUnavailable.Set(1)
+		return
+	}
+	m.DockerUnavailable.Set(0)
+}
+
 // SetDBConnections устанавливает количество соединений с БД
 func (m *Metrics) SetDBConnections(inUse, idle, open int) {
 	m.DBConnections.WithLabelValues("in_use").Set(float64(inUse))
@@ -0,0 +1,89 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// KeysetCursor позиция в списке, отсортированном по (round DESC, id DESC).
+// В отличие от created_at, пара (round, id) уникальна и не меняется,
+// поэтому одновременные вставки не приводят к повторам на соседних страницах
+type KeysetCursor struct {
+	Round int       `json:"round"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Encode кодирует курсор в base64(json)
+func (c KeysetCursor) Encode() string {
+	// Маршалинг структуры из int и UUID не может завершиться ошибкой
+	data, _ := json.Marshal(c)
+	return base64.URLEncoding.EncodeToString(data)
+}
+
+// DecodeKeysetCursor декодирует курсор из base64 строки
+func DecodeKeysetCursor(encoded string) (*KeysetCursor, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	data, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+
+	var cursor KeysetCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cursor: %w", err)
+	}
+	if cursor.ID == uuid.Nil {
+		return nil, fmt.Errorf("cursor has no id")
+	}
+
+	return &cursor, nil
+}
+
+// GetKeysetCursor возвращает декодированный keyset курсор из after/before
+func (pr *PageRequest) GetKeysetCursor() (*KeysetCursor, error) {
+	if pr.After != nil {
+		return DecodeKeysetCursor(*pr.After)
+	}
+	if pr.Before != nil {
+		return DecodeKeysetCursor(*pr.Before)
+	}
+	return nil, nil
+}
+
+// KeysetPage страница списка с keyset пагинацией
+type KeysetPage[T any] struct {
+	Items    []T      `json:"items"`
+	PageInfo PageInfo `json:"page_info"`
+}
+
+// NewKeysetPage создаёт страницу из элементов, уже упорядоченных для отображения.
+// hasMore - есть ли элементы дальше в направлении запроса
+func NewKeysetPage[T any](items []T, cursorOf func(T) KeysetCursor, pageReq *PageRequest, hasMore bool) *KeysetPage[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	page := &KeysetPage[T]{Items: items}
+	if pageReq.IsBackward() {
+		page.PageInfo.HasPreviousPage = hasMore
+		page.PageInfo.HasNextPage = pageReq.Before != nil
+	} else {
+		page.PageInfo.HasNextPage = hasMore
+		page.PageInfo.HasPreviousPage = pageReq.After != nil
+	}
+
+	if len(items) > 0 {
+		start := cursorOf(items[0]).Encode()
+		end := cursorOf(items[len(items)-1]).Encode()
+		page.PageInfo.StartCursor = &start
+		page.PageInfo.EndCursor = &end
+	}
+
+	return page
+}
@@ -60,3 +60,19 @@ func NewConnectionWithTotal[T any](nodes []T, getCursor func(T) (*Cursor, error)
 	conn.Total = &total
 	return conn, nil
 }
+
+// OffsetPage страница списка с limit/offset пагинацией и общим числом элементов
+type OffsetPage[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// NewOffsetPage создаёт страницу; total - число элементов по тем же фильтрам без limit/offset
+func NewOffsetPage[T any](items []T, total, limit, offset int) *OffsetPage[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return &OffsetPage[T]{Items: items, Total: total, Limit: limit, Offset: offset}
+}
@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName имя инструментации для всех спанов приложения
+const instrumentationName = "github.com/bmstu-itstech/tjudge"
+
+// Config - конфигурация трассировки
+type Config struct {
+	Endpoint    string // Адрес OTLP gRPC коллектора (host:port), пусто - экспорт выключен
+	ServiceName string
+}
+
+// ShutdownFunc сбрасывает буферизованные спаны и останавливает экспортёр
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup настраивает глобальный TracerProvider и W3C propagator (traceparent)
+// Если endpoint не задан, спаны создаются, но никуда не экспортируются
+func Setup(ctx context.Context, cfg Config) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	if cfg.Endpoint != "" {
+		exporter, err := otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(cfg.Endpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer возвращает трейсер приложения из глобального провайдера
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Transport оборачивает транспорт исходящих HTTP запросов: каждый запрос получает
+// клиентский спан, а получатель - заголовок traceparent для продолжения трассировки
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "HTTP " + r.Method + " " + r.URL.Host
+		}),
+	)
+}
+
+// StartSpan начинает дочерний спан с атрибутами
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan записывает ошибку (если есть) и завершает спан
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/bmstu-itstech/tjudge/internal/api"
 	"github.com/bmstu-itstech/tjudge/internal/api/handlers"
+	"github.com/bmstu-itstech/tjudge/internal/api/middleware"
 	"github.com/bmstu-itstech/tjudge/internal/config"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/internal/domain/auth"
@@ -119,12 +120,12 @@ func setupTestServer(b *testing.B) {
 	)
 
 	gameService := game.NewService(gameRepo, log)
-	teamService := team.NewService(teamRepo, tournamentRepo, log)
+	teamService := team.NewService(teamRepo, tournamentRepo, distributedLock, log)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService, log)
 	tournamentHandler := handlers.NewTournamentHandler(tournamentService, log)
-	programHandler := handlers.NewProgramHandler(programRepo, tournamentRepo, nil, log)
+	programHandler := handlers.NewProgramHandler(programRepo, tournamentRepo, nil, nil, log)
 	matchHandler := handlers.NewMatchHandler(matchRepo, matchCache, log)
 	gameHandler := handlers.NewGameHandler(gameService, log)
 	teamHandler := handlers.NewTeamHandler(teamService, cfg.Server.BaseURL, log)
@@ -356,6 +357,27 @@ func BenchmarkConcurrentRequests(b *testing.B) {
 	})
 }
 
+// BenchmarkGzipMiddleware measures compression throughput for a 50 KB JSON response
+func BenchmarkGzipMiddleware(b *testing.B) {
+	payload := bytes.Repeat([]byte(`{"rank":1,"program_name":"bench","rating":1500},`), 50*1024/49)
+	handler := middleware.CompressionMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(payload)
+	}))
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			req := httptest.NewRequest("GET", "/api/v1/tournaments/leaderboard", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+		}
+	})
+}
+
 func intPtr(i int) *int {
 	return &i
 }
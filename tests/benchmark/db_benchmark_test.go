@@ -149,6 +149,18 @@ func BenchmarkTournamentListParallel(b *testing.B) {
 	})
 }
 
+// BenchmarkGetActiveTournamentIDs measures the active tournament IDs query used by pollers and cache warmup
+func BenchmarkGetActiveTournamentIDs(b *testing.B) {
+	setupDatabase(b)
+
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tournamentRepo.GetActiveTournamentIDs(ctx)
+	}
+}
+
 // BenchmarkTournamentGetByID measures single tournament fetch
 func BenchmarkTournamentGetByID(b *testing.B) {
 	setupDatabase(b)
@@ -0,0 +1,85 @@
+//go:build benchmark
+// +build benchmark
+
+package benchmark
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bmstu-itstech/tjudge/internal/config"
+	"github.com/bmstu-itstech/tjudge/internal/domain"
+	"github.com/bmstu-itstech/tjudge/internal/infrastructure/executor"
+	"github.com/google/uuid"
+)
+
+// newBenchExecutor creates an executor that runs the example bots from examples/.
+// Skips the benchmark when Docker or the tjudge-cli image is unavailable
+func newBenchExecutor(b *testing.B, warmPoolSize int) (*executor.Executor, string) {
+	programsPath, err := filepath.Abs("../../examples")
+	if err != nil {
+		b.Fatalf("Failed to resolve examples path: %v", err)
+	}
+
+	cfg := config.ExecutorConfig{
+		DockerImage:       "tjudge-cli:latest",
+		Timeout:           30 * time.Second,
+		CPUQuota:          100000,
+		MemoryLimit:       256 * 1024 * 1024,
+		PidsLimit:         100,
+		NetworkDisabled:   true,
+		DefaultIterations: 100,
+		SandboxProfile:    "strict",
+		WarmPoolSize:      warmPoolSize,
+		WarmPoolMaxUses:   1000,
+	}
+
+	exec, err := executor.NewExecutor(cfg, programsPath, "", benchLogger())
+	if err != nil {
+		b.Skipf("Docker is not available: %v", err)
+	}
+	b.Cleanup(func() { exec.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := exec.WarmUp(ctx); err != nil {
+		b.Skipf("tjudge-cli image is not available: %v", err)
+	}
+	exec.SetMetrics(benchMetricsInstance())
+	if err := exec.Prewarm(ctx); err != nil {
+		b.Fatalf("Failed to prewarm containers: %v", err)
+	}
+
+	return exec, programsPath
+}
+
+// benchmarkExecuteMatch runs one dilemma match per iteration
+func benchmarkExecuteMatch(b *testing.B, warmPoolSize int) {
+	exec, programsPath := newBenchExecutor(b, warmPoolSize)
+	program1 := filepath.Join(programsPath, "tit_for_tat.py")
+	program2 := filepath.Join(programsPath, "grudger.py")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		match := &domain.Match{ID: uuid.New(), GameType: "dilemma"}
+		result, err := exec.Execute(context.Background(), match, program1, program2, executor.RunOptions{})
+		if err != nil {
+			b.Fatalf("Match failed: %v", err)
+		}
+		if result.ErrorCode != 0 {
+			b.Fatalf("Match finished with error %d: %s", result.ErrorCode, result.ErrorMessage)
+		}
+	}
+}
+
+// BenchmarkExecutor_ColdContainer creates a container for every match
+func BenchmarkExecutor_ColdContainer(b *testing.B) {
+	benchmarkExecuteMatch(b, 0)
+}
+
+// BenchmarkExecutor_WarmContainer runs every match in a pre-started container
+func BenchmarkExecutor_WarmContainer(b *testing.B) {
+	benchmarkExecuteMatch(b, 1)
+}
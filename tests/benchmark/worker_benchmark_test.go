@@ -64,6 +64,23 @@ func (m *MockBenchQueueManager) Dequeue(ctx context.Context) (*domain.Match, err
 	}
 }
 
+func (m *MockBenchQueueManager) DequeueBatch(ctx context.Context, limit int) ([]*domain.Match, error) {
+	var batch []*domain.Match
+	for len(batch) < limit {
+		match, err := m.Dequeue(ctx)
+		if err != nil || match == nil {
+			return batch, err
+		}
+		batch = append(batch, match)
+	}
+	return batch, nil
+}
+
+func (m *MockBenchQueueManager) Enqueue(ctx context.Context, match *domain.Match) error {
+	m.matches <- match
+	return nil
+}
+
 func (m *MockBenchQueueManager) GetTotalQueueSize(ctx context.Context) (int64, error) {
 	return int64(len(m.matches)), nil
 }
@@ -89,6 +106,15 @@ func (m *MockBenchMatchProcessor) Process(ctx context.Context, match *domain.Mat
 	return nil
 }
 
+// ProcessBatch simulates matches of a batch running in parallel
+func (m *MockBenchMatchProcessor) ProcessBatch(ctx context.Context, matches []*domain.Match) []worker.MatchError {
+	if m.processingTime > 0 {
+		time.Sleep(m.processingTime)
+	}
+	m.processedCount.Add(int64(len(matches)))
+	return nil
+}
+
 func (m *MockBenchMatchProcessor) GetProcessedCount() int64 {
 	return m.processedCount.Load()
 }
@@ -141,6 +167,43 @@ func BenchmarkWorkerPool_ThroughputSmall(b *testing.B) {
 	}
 }
 
+// BenchmarkWorkerPool_ThroughputSmallBatched tests the small pool executing matches in batches
+func BenchmarkWorkerPool_ThroughputSmallBatched(b *testing.B) {
+	cfg := config.WorkerConfig{
+		MinWorkers:    2,
+		MaxWorkers:    4,
+		Timeout:       30 * time.Second,
+		RetryAttempts: 1,
+		RetryDelay:    10 * time.Millisecond,
+		BatchSize:     8,
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+
+		queue := NewMockBenchQueueManager(100)
+		processor := NewMockBenchMatchProcessor(0)
+		log := benchLogger()
+		m := benchMetricsInstance()
+
+		pool := worker.NewPool(cfg, queue, processor, log, m)
+
+		b.StartTimer()
+
+		pool.Start()
+
+		// Wait until all matches processed or timeout
+		deadline := time.Now().Add(5 * time.Second)
+		for processor.GetProcessedCount() < 100 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		pool.Stop()
+		pool.Wait()
+	}
+}
+
 // BenchmarkWorkerPool_ThroughputMedium tests throughput with medium worker pool
 func BenchmarkWorkerPool_ThroughputMedium(b *testing.B) {
 	cfg := config.WorkerConfig{
@@ -5,6 +5,7 @@ package e2e
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -676,6 +677,16 @@ func TestE2E_RateLimiting(t *testing.T) {
 
 	client := NewTestClient()
 
+	t.Run("RateLimitHeadersPresent", func(t *testing.T) {
+		resp, err := client.doRequest("GET", "/api/v1/tournaments", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.NotEmpty(t, resp.Header.Get("X-RateLimit-Limit"))
+		assert.NotEmpty(t, resp.Header.Get("X-RateLimit-Remaining"))
+		assert.NotEmpty(t, resp.Header.Get("X-RateLimit-Reset"))
+	})
+
 	// Make many requests quickly
 	t.Run("RateLimitTriggered", func(t *testing.T) {
 		hitRateLimit := false
@@ -688,6 +699,8 @@ func TestE2E_RateLimiting(t *testing.T) {
 
 			if resp.StatusCode == http.StatusTooManyRequests {
 				hitRateLimit = true
+				assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+				assert.NotEmpty(t, resp.Header.Get("Retry-After"))
 				resp.Body.Close()
 				break
 			}
@@ -704,6 +717,41 @@ func TestE2E_RateLimiting(t *testing.T) {
 // E2E Test: Error Handling
 // =============================================================================
 
+// decodeError reads an error response in either the legacy {"error": "..."} shape
+// or the current {"error": {"code": "...", "message": "..."}} shape.
+// The code is empty for legacy responses
+func decodeError(t *testing.T, resp *http.Response) (code, message string) {
+	t.Helper()
+
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.NotEmpty(t, body.Error, "response has no error field")
+
+	if err := json.Unmarshal(body.Error, &message); err == nil {
+		return "", message
+	}
+
+	var structured struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal(body.Error, &structured))
+	return structured.Code, structured.Message
+}
+
+// assertErrorCode checks the machine-readable code when the server provides one
+func assertErrorCode(t *testing.T, resp *http.Response, expected string) {
+	t.Helper()
+
+	code, message := decodeError(t, resp)
+	assert.NotEmpty(t, message)
+	if code != "" {
+		assert.Equal(t, expected, code)
+	}
+}
+
 func TestE2E_ErrorHandling(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping E2E test in short mode")
@@ -722,6 +770,7 @@ func TestE2E_ErrorHandling(t *testing.T) {
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assertErrorCode(t, resp, "unauthorized")
 	})
 
 	t.Run("MissingAuth", func(t *testing.T) {
@@ -732,6 +781,7 @@ func TestE2E_ErrorHandling(t *testing.T) {
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assertErrorCode(t, resp, "unauthorized")
 	})
 
 	t.Run("InvalidTournamentID", func(t *testing.T) {
@@ -757,5 +807,56 @@ func TestE2E_ErrorHandling(t *testing.T) {
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assertErrorCode(t, resp, "bad_request")
+	})
+}
+
+// =============================================================================
+// E2E Test: Response Compression
+// =============================================================================
+
+func TestE2E_ResponseCompression(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping E2E test in short mode")
+	}
+
+	// Setting Accept-Encoding manually disables transparent decompression in net/http
+	getGzip := func(t *testing.T, path string) (*http.Response, []byte) {
+		req, err := http.NewRequest("GET", baseURL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	t.Run("JSONResponseDecompresses", func(t *testing.T) {
+		resp, body := getGzip(t, "/api/v1/tournaments?limit=100")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Values("Vary"), "Accept-Encoding")
+
+		if resp.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(bytes.NewReader(body))
+			require.NoError(t, err)
+			body, err = io.ReadAll(gz)
+			require.NoError(t, err)
+		} else {
+			// Only responses below one TCP segment are sent uncompressed
+			assert.Less(t, len(body), 1400)
+		}
+
+		assert.True(t, json.Valid(body), "response must be valid JSON after decompression")
+	})
+
+	t.Run("SmallResponseNotCompressed", func(t *testing.T) {
+		resp, body := getGzip(t, "/health")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		assert.Equal(t, "OK", string(body))
 	})
 }
@@ -5,16 +5,23 @@ package integration
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"os"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/bmstu-itstech/tjudge/internal/config"
 	"github.com/bmstu-itstech/tjudge/internal/domain"
 	"github.com/bmstu-itstech/tjudge/internal/infrastructure/db"
+	"github.com/bmstu-itstech/tjudge/internal/seed"
+	"github.com/bmstu-itstech/tjudge/pkg/errors"
 	"github.com/bmstu-itstech/tjudge/pkg/logger"
 	"github.com/bmstu-itstech/tjudge/pkg/metrics"
+	"github.com/bmstu-itstech/tjudge/pkg/pagination"
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
@@ -25,11 +32,12 @@ import (
 // DBTestSuite is the integration test suite for database operations
 type DBTestSuite struct {
 	suite.Suite
-	db          *db.DB
-	userRepo    *db.UserRepository
-	programRepo *db.ProgramRepository
-	matchRepo   *db.MatchRepository
-	ctx         context.Context
+	db             *db.DB
+	userRepo       *db.UserRepository
+	programRepo    *db.ProgramRepository
+	matchRepo      *db.MatchRepository
+	tournamentRepo *db.TournamentRepository
+	ctx            context.Context
 }
 
 func (s *DBTestSuite) SetupSuite() {
@@ -65,6 +73,7 @@ func (s *DBTestSuite) SetupSuite() {
 	s.userRepo = db.NewUserRepository(s.db)
 	s.programRepo = db.NewProgramRepository(s.db)
 	s.matchRepo = db.NewMatchRepository(s.db)
+	s.tournamentRepo = db.NewTournamentRepository(s.db)
 }
 
 func (s *DBTestSuite) TearDownSuite() {
@@ -81,8 +90,10 @@ func (s *DBTestSuite) SetupTest() {
 func (s *DBTestSuite) cleanupTestData() {
 	// Clean up in reverse order of dependencies
 	s.db.ExecContext(s.ctx, "DELETE FROM matches WHERE game_type = 'integration_test'")
+	s.db.ExecContext(s.ctx, "DELETE FROM tournaments WHERE name LIKE 'integration_test%'")
 	s.db.ExecContext(s.ctx, "DELETE FROM programs WHERE code_path LIKE 'integration_test%'")
 	s.db.ExecContext(s.ctx, "DELETE FROM users WHERE username LIKE 'integration_test_%'")
+	s.db.ExecContext(s.ctx, "DELETE FROM audit_log WHERE action LIKE 'integration_test%'")
 }
 
 // =============================================================================
@@ -273,65 +284,3003 @@ func (s *DBTestSuite) TestProgramRepository_CRUD() {
 	assert.Error(s.T(), err)
 }
 
+func (s *DBTestSuite) TestProgramRepository_ListByUserFiltered() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	for i, language := range []string{"python", "go", "python"} {
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     fmt.Sprintf("Program %d", i),
+			Language: language,
+			CodePath: "integration_test_list_filtered",
+			GameType: "tictactoe",
+		}))
+	}
+
+	python, err := s.programRepo.ListByUserFiltered(s.ctx, user.ID, domain.ProgramFilter{Language: "python"})
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), python, 2)
+
+	// Pages are disjoint and ordered newest first
+	first, err := s.programRepo.ListByUserFiltered(s.ctx, user.ID, domain.ProgramFilter{Limit: 2})
+	require.NoError(s.T(), err)
+	rest, err := s.programRepo.ListByUserFiltered(s.ctx, user.ID, domain.ProgramFilter{Limit: 2, Offset: 2})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), first, 2)
+	require.Len(s.T(), rest, 1)
+	assert.False(s.T(), first[0].CreatedAt.Before(first[1].CreatedAt))
+	assert.NotEqual(s.T(), rest[0].ID, first[0].ID)
+	assert.NotEqual(s.T(), rest[0].ID, first[1].ID)
+}
+
+func (s *DBTestSuite) TestAPIKeyRepository_Lifecycle() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	repo := db.NewAPIKeyRepository(s.db)
+	key := &domain.APIKey{
+		ID:      uuid.New(),
+		UserID:  user.ID,
+		Name:    "ci",
+		KeyHash: fmt.Sprintf("%064x", uuid.New().ID()),
+		Prefix:  "tjk_abcdefgh",
+		Scope:   domain.APIKeyScopeRead,
+	}
+	require.NoError(s.T(), repo.Create(s.ctx, key))
+
+	found, err := repo.GetByHash(s.ctx, key.KeyHash)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), key.ID, found.ID)
+	assert.Nil(s.T(), found.LastUsedAt)
+
+	require.NoError(s.T(), repo.TouchLastUsed(s.ctx, key.ID, time.Now()))
+	keys, err := repo.ListByUser(s.ctx, user.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), keys, 1)
+	assert.NotNil(s.T(), keys[0].LastUsedAt)
+
+	// Only the owner can revoke the key
+	_, err = repo.Revoke(s.ctx, key.ID, uuid.New())
+	assert.Error(s.T(), err)
+
+	hash, err := repo.Revoke(s.ctx, key.ID, user.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), key.KeyHash, hash)
+
+	_, err = repo.GetByHash(s.ctx, key.KeyHash)
+	assert.Error(s.T(), err)
+	keys, err = repo.ListByUser(s.ctx, user.ID)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), keys)
+}
+
+func (s *DBTestSuite) TestAuditLogRepository_List() {
+	repo := db.NewAuditLogRepository(s.db)
+	adminID := uuid.New()
+	hash := fmt.Sprintf("%064x", 1)
+
+	first := &domain.AuditLogEntry{UserID: adminID, Action: "integration_test start", IPAddress: "127.0.0.1", RequestBodyHash: &hash}
+	require.NoError(s.T(), repo.Record(s.ctx, first))
+	time.Sleep(10 * time.Millisecond)
+	second := &domain.AuditLogEntry{UserID: adminID, Action: "integration_test ban", IPAddress: "127.0.0.1"}
+	require.NoError(s.T(), repo.Record(s.ctx, second))
+	other := &domain.AuditLogEntry{UserID: uuid.New(), Action: "integration_test start", IPAddress: "127.0.0.1"}
+	require.NoError(s.T(), repo.Record(s.ctx, other))
+
+	entries, err := repo.List(s.ctx, domain.AuditLogFilter{UserID: &adminID})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), entries, 2)
+	assert.Equal(s.T(), second.ID, entries[0].ID)
+	assert.Equal(s.T(), hash, *entries[1].RequestBodyHash)
+
+	entries, err = repo.List(s.ctx, domain.AuditLogFilter{UserID: &adminID, Action: "integration_test start"})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), entries, 1)
+	assert.Equal(s.T(), first.ID, entries[0].ID)
+
+	entries, err = repo.List(s.ctx, domain.AuditLogFilter{UserID: &adminID, After: &first.CreatedAt})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), entries, 1)
+	assert.Equal(s.T(), second.ID, entries[0].ID)
+
+	targetType, targetID := "tournament", uuid.New().String()
+	targeted := &domain.AuditLogEntry{UserID: adminID, Action: "integration_test run", TargetType: &targetType, TargetID: &targetID, IPAddress: "127.0.0.1"}
+	require.NoError(s.T(), repo.Record(s.ctx, targeted))
+
+	entries, err = repo.List(s.ctx, domain.AuditLogFilter{TargetType: targetType, TargetID: targetID})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), entries, 1)
+	assert.Equal(s.T(), targeted.ID, entries[0].ID)
+
+	// Params and an explicit action time are stored as given
+	actedAt := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+	withParams := &domain.AuditLogEntry{
+		UserID: adminID, Action: "integration_test cancel", TargetID: &targetID, IPAddress: "127.0.0.1",
+		Params: json.RawMessage(`{"id":"` + targetID + `"}`), CreatedAt: actedAt,
+	}
+	require.NoError(s.T(), repo.Record(s.ctx, withParams))
+
+	entries, err = repo.List(s.ctx, domain.AuditLogFilter{TargetID: targetID, Action: "integration_test cancel"})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), entries, 1)
+	assert.JSONEq(s.T(), string(withParams.Params), string(entries[0].Params))
+	assert.WithinDuration(s.T(), actedAt, entries[0].CreatedAt, time.Second)
+
+	// Cursor pagination walks the admin's entries newest first without repeats
+	page1, err := repo.List(s.ctx, domain.AuditLogFilter{UserID: &adminID, Limit: 2})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page1, 2)
+	last := page1[len(page1)-1]
+	page2, err := repo.List(s.ctx, domain.AuditLogFilter{
+		UserID: &adminID, Limit: 2,
+		Before: &domain.AuditLogCursor{CreatedAt: last.CreatedAt, ID: last.ID},
+	})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page2, 2)
+	assert.NotEqual(s.T(), page1[0].ID, page2[0].ID)
+	assert.NotEqual(s.T(), page1[1].ID, page2[0].ID)
+	assert.False(s.T(), page2[0].CreatedAt.After(last.CreatedAt))
+}
+
+// =============================================================================
+// Tournament Participant Tests
+// =============================================================================
+
+func (s *DBTestSuite) TestCancelledMatchesExcludedFromLeaderboard() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	programs := make([]*domain.Program, 2)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     "Leaderboard Program",
+			Language: "python",
+			CodePath: "integration_test_leaderboard",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_tournament",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	for _, p := range programs {
+		require.NoError(s.T(), s.tournamentRepo.AddParticipant(s.ctx, &domain.TournamentParticipant{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			ProgramID:    p.ID,
+			Rating:       1500,
+		}))
+	}
+
+	newMatch := func() *domain.Match {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   programs[0].ID,
+			Program2ID:   programs[1].ID,
+			GameType:     "integration_test",
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			CreatedAt:    time.Now(),
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		return match
+	}
+
+	completed := newMatch()
+
+	// Seed is generated from the match ID and persisted
+	stored, err := s.matchRepo.GetByID(s.ctx, completed.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.SeedFromID(completed.ID), stored.Seed)
+
+	require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, completed.ID, &domain.MatchResult{
+		MatchID: completed.ID,
+		Score1:  10,
+		Score2:  5,
+		Winner:  1,
+	}))
+
+	cancelledMatch := newMatch()
+	cancelled, err := s.matchRepo.CancelPendingByProgram(s.ctx, tournament.ID, programs[1].ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), cancelled)
+
+	// Cancelled match must not be picked up by a worker
+	err = s.matchRepo.UpdateStatus(s.ctx, cancelledMatch.ID, domain.MatchRunning)
+	assert.Error(s.T(), err)
+
+	leaderboard, err := s.tournamentRepo.GetLeaderboard(s.ctx, tournament.ID, 10)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), leaderboard, 2)
+
+	for _, entry := range leaderboard {
+		assert.Equal(s.T(), 1, entry.TotalGames)
+		if entry.ProgramID == programs[0].ID {
+			assert.Equal(s.T(), 10, entry.Rating)
+			assert.Equal(s.T(), 1, entry.Wins)
+		}
+	}
+
+	// Banned program is removed from participants
+	require.NoError(s.T(), s.tournamentRepo.AddBan(s.ctx, &domain.TournamentBan{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		ProgramID:    programs[1].ID,
+		Reason:       "integration test",
+	}))
+	require.NoError(s.T(), s.tournamentRepo.RemoveParticipant(s.ctx, tournament.ID, programs[1].ID))
+
+	banned, err := s.tournamentRepo.IsBanned(s.ctx, tournament.ID, programs[1].ID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), banned)
+
+	leaderboard, err = s.tournamentRepo.GetLeaderboard(s.ctx, tournament.ID, 10)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), leaderboard, 1)
+}
+
+func (s *DBTestSuite) TestTournamentBan_CoversTeam() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_tournament",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	team := &domain.Team{ID: uuid.New(), TournamentID: tournament.ID, Name: "integration_test_team", Code: uuid.New().String()[:8], LeaderID: user.ID}
+	require.NoError(s.T(), db.NewTeamRepository(s.db).Create(s.ctx, team))
+
+	upload := func(version int) *domain.Program {
+		program := &domain.Program{
+			ID:           uuid.New(),
+			UserID:       user.ID,
+			TeamID:       &team.ID,
+			TournamentID: &tournament.ID,
+			Name:         "bot",
+			Language:     "python",
+			CodePath:     "integration_test_team_ban",
+			GameType:     "integration_test",
+			Version:      version,
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, program))
+		return program
+	}
+
+	first := upload(1)
+	ban := &domain.TournamentBan{ID: uuid.New(), TournamentID: tournament.ID, ProgramID: first.ID, Reason: "integration test"}
+	require.NoError(s.T(), s.tournamentRepo.AddBan(s.ctx, ban))
+	require.NotNil(s.T(), ban.TeamID)
+	assert.Equal(s.T(), team.ID, *ban.TeamID)
+
+	// A re-upload is a new program of the same team
+	second := upload(2)
+	banned, err := s.tournamentRepo.IsBanned(s.ctx, tournament.ID, second.ID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), banned)
+
+	// Deleting the banned program does not lift the team ban
+	require.NoError(s.T(), s.programRepo.Delete(s.ctx, first.ID))
+	banned, err = s.tournamentRepo.IsTeamBanned(s.ctx, tournament.ID, team.ID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), banned)
+}
+
+func (s *DBTestSuite) TestLeaderboardPointsScoring() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_tournament",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+		Metadata: map[string]interface{}{
+			domain.MetaPointsScoring: map[string]interface{}{"win": 3, "draw": 1, "loss": 0},
+		},
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	programs := make([]*domain.Program, 3)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     fmt.Sprintf("Points Program %d", i),
+			Language: "python",
+			CodePath: "integration_test_points",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+		require.NoError(s.T(), s.tournamentRepo.AddParticipant(s.ctx, &domain.TournamentParticipant{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			ProgramID:    programs[i].ID,
+			Rating:       1500,
+		}))
+	}
+
+	play := func(first, second *domain.Program, score1, score2, winner int) {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   first.ID,
+			Program2ID:   second.ID,
+			GameType:     "integration_test",
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			CreatedAt:    time.Now(),
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, match.ID, &domain.MatchResult{
+			MatchID: match.ID,
+			Score1:  score1,
+			Score2:  score2,
+			Winner:  winner,
+		}))
+	}
+
+	// By summed score the draw-heavy programs would lead: 0 -> 1, 1 -> 50, 2 -> 50
+	play(programs[0], programs[1], 1, 0, 1)
+	play(programs[1], programs[2], 50, 50, 0)
+	play(programs[0], programs[2], 0, 0, 0)
+
+	leaderboard, err := s.tournamentRepo.GetLeaderboard(s.ctx, tournament.ID, 10)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), leaderboard, 3)
+
+	assert.Equal(s.T(), programs[0].ID, leaderboard[0].ProgramID)
+	assert.Equal(s.T(), 4, leaderboard[0].Rating)
+	assert.Equal(s.T(), programs[2].ID, leaderboard[1].ProgramID)
+	assert.Equal(s.T(), 2, leaderboard[1].Rating)
+	assert.Equal(s.T(), programs[1].ID, leaderboard[2].ProgramID)
+	assert.Equal(s.T(), 1, leaderboard[2].Rating)
+}
+
+func (s *DBTestSuite) TestConcurrentMatchResultWrites() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	programs := make([]*domain.Program, 2)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     "Result Program",
+			Language: "python",
+			CodePath: "integration_test_result",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_tournament",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	newMatch := func() *domain.Match {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   programs[0].ID,
+			Program2ID:   programs[1].ID,
+			GameType:     "integration_test",
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			CreatedAt:    time.Now(),
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		return match
+	}
+
+	match := newMatch()
+	require.NoError(s.T(), s.matchRepo.UpdateStatus(s.ctx, match.ID, domain.MatchRunning))
+
+	// Two executions of the same match report different winners at the same time
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = s.matchRepo.UpdateResult(s.ctx, match.ID, &domain.MatchResult{
+				MatchID: match.ID,
+				Score1:  i,
+				Winner:  i + 1,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	winner := -1
+	conflicts := 0
+	for i, err := range errs {
+		if err == nil {
+			winner = i + 1
+			continue
+		}
+		assert.True(s.T(), errors.IsConflict(err), "unexpected error: %v", err)
+		conflicts++
+	}
+	require.NotEqual(s.T(), -1, winner, "exactly one write must succeed")
+	assert.Equal(s.T(), 1, conflicts)
+
+	stored, err := s.matchRepo.GetByID(s.ctx, match.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.MatchCompleted, stored.Status)
+	require.NotNil(s.T(), stored.Winner)
+	assert.Equal(s.T(), winner, *stored.Winner)
+
+	// A completed match cannot be started again
+	err = s.matchRepo.UpdateStatus(s.ctx, match.ID, domain.MatchRunning)
+	assert.True(s.T(), errors.IsConflict(err))
+
+	// Batch write reports the completed match and saves the rest
+	pending := newMatch()
+	conflicted, err := s.matchRepo.BatchUpdateResults(s.ctx, map[uuid.UUID]*domain.MatchResult{
+		match.ID:   {MatchID: match.ID, Winner: 0},
+		pending.ID: {MatchID: pending.ID, Score1: 3, Winner: 1},
+	})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []uuid.UUID{match.ID}, conflicted)
+
+	stored, err = s.matchRepo.GetByID(s.ctx, match.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), winner, *stored.Winner)
+
+	stored, err = s.matchRepo.GetByID(s.ctx, pending.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.MatchCompleted, stored.Status)
+
+	// Matches of a deleted tournament are reported as missing, whatever their status
+	queued := newMatch()
+	require.NoError(s.T(), s.tournamentRepo.Delete(s.ctx, tournament.ID))
+
+	err = s.matchRepo.UpdateStatus(s.ctx, queued.ID, domain.MatchRunning)
+	assert.True(s.T(), errors.IsNotFound(err), "unexpected error: %v", err)
+	err = s.matchRepo.UpdateStatus(s.ctx, match.ID, domain.MatchRunning)
+	assert.True(s.T(), errors.IsNotFound(err), "unexpected error: %v", err)
+}
+
+func (s *DBTestSuite) TestMatchKeysetPagination() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	programs := make([]*domain.Program, 2)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     "Page Program",
+			Language: "python",
+			CodePath: "integration_test_page",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_tournament",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	// All matches share created_at, which used to make the order unstable
+	createdAt := time.Now()
+	insert := func(round int) uuid.UUID {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   programs[0].ID,
+			Program2ID:   programs[1].ID,
+			GameType:     "integration_test",
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  round,
+			CreatedAt:    createdAt,
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		return match.ID
+	}
+
+	original := make(map[uuid.UUID]bool)
+	for round := 1; round <= 3; round++ {
+		for i := 0; i < 4; i++ {
+			original[insert(round)] = true
+		}
+	}
+
+	filter := domain.MatchFilter{TournamentID: &tournament.ID}
+	pageSize := 5
+
+	// Forward: other writers insert matches into every round between pages
+	seen := make(map[uuid.UUID]bool)
+	var pages [][]*domain.Match
+	var after *string
+	for {
+		matches, hasMore, err := s.matchRepo.ListWithCursor(s.ctx, filter, &pagination.PageRequest{First: &pageSize, After: after})
+		require.NoError(s.T(), err)
+		pages = append(pages, matches)
+
+		for _, m := range matches {
+			assert.False(s.T(), seen[m.ID], "match %s returned twice", m.ID)
+			seen[m.ID] = true
+		}
+
+		var wg sync.WaitGroup
+		for round := 1; round <= 3; round++ {
+			wg.Add(1)
+			go func(round int) {
+				defer wg.Done()
+				insert(round)
+			}(round)
+		}
+		wg.Wait()
+
+		if !hasMore {
+			break
+		}
+		cursor := db.GetMatchKeysetCursor(matches[len(matches)-1])
+		after = &cursor
+	}
+
+	for id := range original {
+		assert.True(s.T(), seen[id], "match %s was skipped", id)
+	}
+
+	// Backward from the last page walks the same keys in the same display order
+	before := db.GetMatchKeysetCursor(pages[len(pages)-1][0])
+	backSeen := make(map[uuid.UUID]bool)
+	for {
+		matches, hasMore, err := s.matchRepo.ListWithCursor(s.ctx, filter, &pagination.PageRequest{Last: &pageSize, Before: &before})
+		require.NoError(s.T(), err)
+
+		for i, m := range matches {
+			assert.False(s.T(), backSeen[m.ID], "match %s returned twice", m.ID)
+			backSeen[m.ID] = true
+			if i > 0 {
+				previous := matches[i-1]
+				assert.True(s.T(), previous.RoundNumber > m.RoundNumber ||
+					(previous.RoundNumber == m.RoundNumber && previous.ID.String() > m.ID.String()),
+					"page must be ordered by (round_number, id) DESC")
+			}
+		}
+
+		if !hasMore || len(matches) == 0 {
+			break
+		}
+		before = db.GetMatchKeysetCursor(matches[0])
+	}
+
+	// Every match from the forward pass except the last page is reachable backwards
+	for _, page := range pages[:len(pages)-1] {
+		for _, m := range page {
+			assert.True(s.T(), backSeen[m.ID], "match %s missing from backward pass", m.ID)
+		}
+	}
+}
+
+func (s *DBTestSuite) TestTournamentSoftDelete() {
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_tournament",
+		GameType: "integration_test",
+		Status:   domain.TournamentCompleted,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	require.NoError(s.T(), s.tournamentRepo.Delete(s.ctx, tournament.ID))
+
+	// Deleted tournament is hidden from GetByID and default listing
+	_, err := s.tournamentRepo.GetByID(s.ctx, tournament.ID)
+	assert.True(s.T(), errors.IsNotFound(err))
+
+	listed := func(filter domain.TournamentFilter) bool {
+		filter.GameType = "integration_test"
+		tournaments, err := s.tournamentRepo.List(s.ctx, filter)
+		require.NoError(s.T(), err)
+		for _, t := range tournaments {
+			if t.ID == tournament.ID {
+				return true
+			}
+		}
+		return false
+	}
+	assert.False(s.T(), listed(domain.TournamentFilter{}))
+	assert.True(s.T(), listed(domain.TournamentFilter{IncludeDeleted: true}))
+
+	// Second delete does not find the tournament
+	assert.True(s.T(), errors.IsNotFound(s.tournamentRepo.Delete(s.ctx, tournament.ID)))
+
+	// Purge is refused until the retention period expires
+	err = s.tournamentRepo.Purge(s.ctx, tournament.ID, time.Now().Add(-time.Hour))
+	assert.True(s.T(), errors.IsConflict(err))
+
+	require.NoError(s.T(), s.tournamentRepo.Restore(s.ctx, tournament.ID))
+	restored, err := s.tournamentRepo.GetByID(s.ctx, tournament.ID)
+	require.NoError(s.T(), err)
+	assert.Nil(s.T(), restored.DeletedAt)
+
+	// Only deleted tournaments can be purged
+	err = s.tournamentRepo.Purge(s.ctx, tournament.ID, time.Now().Add(time.Hour))
+	assert.True(s.T(), errors.IsConflict(err))
+
+	require.NoError(s.T(), s.tournamentRepo.Delete(s.ctx, tournament.ID))
+	require.NoError(s.T(), s.tournamentRepo.Purge(s.ctx, tournament.ID, time.Now().Add(time.Hour)))
+	assert.True(s.T(), errors.IsNotFound(s.tournamentRepo.Restore(s.ctx, tournament.ID)))
+}
+
+func (s *DBTestSuite) TestBracketRepository() {
+	repo := db.NewBracketRepository(s.db)
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_bracket",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	p1, p2, p3 := uuid.New(), uuid.New(), uuid.New()
+	seed1, seed2, seed3 := 1, 2, 3
+	slots := []*domain.BracketSlot{
+		{TournamentID: tournament.ID, Round: 2, Position: 0, Program1ID: &p1, Seed1: &seed1},
+		{TournamentID: tournament.ID, Round: 1, Position: 0, Program1ID: &p1, Seed1: &seed1, WinnerID: &p1, IsBye: true},
+		{TournamentID: tournament.ID, Round: 1, Position: 1, Program1ID: &p2, Program2ID: &p3, Seed1: &seed2, Seed2: &seed3},
+	}
+	require.NoError(s.T(), repo.CreateSlots(s.ctx, slots))
+
+	stored, err := repo.GetByTournament(s.ctx, tournament.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), stored, 3)
+	assert.Equal(s.T(), []int{1, 1, 2}, []int{stored[0].Round, stored[1].Round, stored[2].Round})
+	assert.True(s.T(), stored[0].IsBye)
+
+	// A slot gets its first match once; the second claim conflicts
+	first := uuid.New()
+	require.NoError(s.T(), repo.SetMatch(s.ctx, tournament.ID, 1, 1, nil, first))
+	assert.True(s.T(), errors.IsConflict(repo.SetMatch(s.ctx, tournament.ID, 1, 1, nil, uuid.New())))
+
+	// A rematch replaces the current match and counts
+	rematch := uuid.New()
+	require.NoError(s.T(), repo.SetMatch(s.ctx, tournament.ID, 1, 1, &first, rematch))
+	slot, err := repo.GetByMatchID(s.ctx, rematch)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, slot.Rematches)
+
+	_, err = repo.GetByMatchID(s.ctx, first)
+	assert.True(s.T(), errors.IsNotFound(err))
+
+	// The result of a replaced match is ignored; the winner is recorded once
+	ok, err := repo.SetWinner(s.ctx, tournament.ID, 1, 1, first, p2)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), ok)
+	ok, err = repo.SetWinner(s.ctx, tournament.ID, 1, 1, rematch, p3)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), ok)
+	ok, err = repo.SetWinner(s.ctx, tournament.ID, 1, 1, rematch, p3)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), ok)
+
+	next, err := repo.SetEntrant(s.ctx, tournament.ID, 2, 0, 2, p3, &seed3)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), next.IsReady())
+	assert.Equal(s.T(), p3, *next.Program2ID)
+	assert.Equal(s.T(), seed3, *next.Seed2)
+
+	// There is no round after the final
+	_, err = repo.SetEntrant(s.ctx, tournament.ID, 3, 0, 1, p3, &seed3)
+	assert.True(s.T(), errors.IsNotFound(err))
+}
+
+func (s *DBTestSuite) TestNotificationOutbox() {
+	repo := db.NewNotificationRepository(s.db)
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_notifications",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	var members []*domain.User
+	for i := 0; i < 2; i++ {
+		user := &domain.User{
+			ID:           uuid.New(),
+			Username:     "integration_test_user_" + uuid.New().String()[:8],
+			Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+			PasswordHash: "hashed_password",
+		}
+		require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+		members = append(members, user)
+	}
+
+	teamID := uuid.New()
+	_, err := s.db.ExecContext(s.ctx,
+		"INSERT INTO teams (id, tournament_id, name, code, leader_id) VALUES ($1, $2, 'team', $3, $4)",
+		teamID, tournament.ID, uuid.New().String()[:8], members[0].ID)
+	require.NoError(s.T(), err)
+	for _, member := range members {
+		_, err := s.db.ExecContext(s.ctx, "INSERT INTO team_members (team_id, user_id) VALUES ($1, $2)", teamID, member.ID)
+		require.NoError(s.T(), err)
+	}
+
+	// The second member opts out; preferences are merged, not replaced
+	_, err = s.userRepo.UpdateNotificationPreferences(s.ctx, members[1].ID,
+		domain.NotificationPreferences{domain.NotificationCompileFailed: false})
+	require.NoError(s.T(), err)
+	prefs, err := s.userRepo.UpdateNotificationPreferences(s.ctx, members[1].ID,
+		domain.NotificationPreferences{domain.NotificationTournamentStarted: false})
+	require.NoError(s.T(), err)
+	assert.False(s.T(), prefs.Enabled(domain.NotificationCompileFailed))
+	assert.False(s.T(), prefs.Enabled(domain.NotificationTournamentStarted))
+
+	msg := &domain.NotificationMessage{
+		Event:     domain.NotificationTournamentStarted,
+		Subject:   "started",
+		Body:      "body",
+		DedupeKey: "tournament_started:" + tournament.ID.String(),
+	}
+	enqueued, err := repo.EnqueueForTournament(s.ctx, tournament.ID, msg)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), enqueued)
+
+	// The same event is not enqueued twice
+	enqueued, err = repo.EnqueueForTournament(s.ctx, tournament.ID, msg)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(0), enqueued)
+
+	claimed, err := repo.ClaimDue(s.ctx, 100, time.Minute)
+	require.NoError(s.T(), err)
+	var ours *domain.Notification
+	for _, n := range claimed {
+		if n.UserID != nil && *n.UserID == members[0].ID {
+			ours = n
+		}
+	}
+	require.NotNil(s.T(), ours)
+	assert.Equal(s.T(), members[0].Email, ours.Recipient)
+	assert.Equal(s.T(), 1, ours.Attempts)
+
+	// A claimed notification is leased and not handed out again
+	claimed, err = repo.ClaimDue(s.ctx, 100, time.Minute)
+	require.NoError(s.T(), err)
+	for _, n := range claimed {
+		assert.NotEqual(s.T(), ours.ID, n.ID)
+	}
+
+	require.NoError(s.T(), repo.MarkRetry(s.ctx, ours.ID, "connection refused", 0))
+	claimed, err = repo.ClaimDue(s.ctx, 100, time.Minute)
+	require.NoError(s.T(), err)
+	retried := false
+	for _, n := range claimed {
+		if n.ID == ours.ID {
+			retried = true
+			assert.Equal(s.T(), 2, n.Attempts)
+			require.NotNil(s.T(), n.LastError)
+			assert.Equal(s.T(), "connection refused", *n.LastError)
+		}
+	}
+	assert.True(s.T(), retried)
+
+	require.NoError(s.T(), repo.MarkSent(s.ctx, ours.ID))
+	var status string
+	require.NoError(s.T(), s.db.QueryRowContext(s.ctx, "SELECT status FROM notification_outbox WHERE id = $1", ours.ID).Scan(&status))
+	assert.Equal(s.T(), string(domain.NotificationSent), status)
+}
+
+func (s *DBTestSuite) TestGameRoundLifecycle() {
+	gameRepo := db.NewGameRepository(s.db)
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_rounds",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	game := &domain.Game{ID: uuid.New(), Name: "integration_test_" + uuid.New().String()[:8], DisplayName: "Rounds"}
+	require.NoError(s.T(), gameRepo.Create(s.ctx, game))
+	defer func() { _ = gameRepo.Delete(s.ctx, game.ID) }()
+	require.NoError(s.T(), gameRepo.AddToTournament(s.ctx, tournament.ID, game.ID))
+
+	active, err := gameRepo.IsRoundActive(s.ctx, tournament.ID, game.ID)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), active)
+
+	require.NoError(s.T(), gameRepo.MarkRoundActive(s.ctx, tournament.ID, game.Name))
+	tg, err := gameRepo.GetTournamentGame(s.ctx, tournament.ID, game.ID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), tg.RoundActive)
+
+	// No unfinished matches left: the round is finished once
+	finished, err := gameRepo.FinishRoundIfIdle(s.ctx, tournament.ID, game.Name)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), finished)
+	assert.False(s.T(), finished.RoundActive)
+	// Without auto_complete_rounds the round stays open for the manual endpoint
+	assert.False(s.T(), finished.RoundCompleted)
+	assert.Equal(s.T(), tg.CurrentRound, finished.CurrentRound)
+	finished, err = gameRepo.FinishRoundIfIdle(s.ctx, tournament.ID, game.Name)
+	require.NoError(s.T(), err)
+	assert.Nil(s.T(), finished)
+
+	// Marking the round completed also clears the flag
+	require.NoError(s.T(), gameRepo.MarkRoundActive(s.ctx, tournament.ID, game.Name))
+	require.NoError(s.T(), gameRepo.MarkRoundCompleted(s.ctx, tournament.ID, game.ID))
+	active, err = gameRepo.IsRoundActive(s.ctx, tournament.ID, game.ID)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), active)
+
+	err = gameRepo.MarkRoundActive(s.ctx, tournament.ID, "integration_test_missing")
+	assert.True(s.T(), errors.IsNotFound(err))
+}
+
+func (s *DBTestSuite) TestGameRoundAutoComplete() {
+	gameRepo := db.NewGameRepository(s.db)
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_auto_rounds",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+		Metadata: map[string]interface{}{domain.MetaAutoCompleteRounds: true},
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	game := &domain.Game{ID: uuid.New(), Name: "integration_test_" + uuid.New().String()[:8], DisplayName: "Auto rounds"}
+	require.NoError(s.T(), gameRepo.Create(s.ctx, game))
+	defer func() { _ = gameRepo.Delete(s.ctx, game.ID) }()
+	require.NoError(s.T(), gameRepo.AddToTournament(s.ctx, tournament.ID, game.ID))
+
+	before, err := gameRepo.GetTournamentGame(s.ctx, tournament.ID, game.ID)
+	require.NoError(s.T(), err)
+
+	require.NoError(s.T(), gameRepo.MarkRoundActive(s.ctx, tournament.ID, game.Name))
+
+	// Concurrent workers finishing the last matches complete the round exactly once
+	var wg sync.WaitGroup
+	results := make(chan *domain.TournamentGame, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tg, err := gameRepo.FinishRoundIfIdle(s.ctx, tournament.ID, game.Name)
+			assert.NoError(s.T(), err)
+			results <- tg
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var completed []*domain.TournamentGame
+	for tg := range results {
+		if tg != nil {
+			completed = append(completed, tg)
+		}
+	}
+	require.Len(s.T(), completed, 1)
+	assert.True(s.T(), completed[0].RoundCompleted)
+	assert.NotNil(s.T(), completed[0].RoundCompletedAt)
+	assert.Equal(s.T(), before.CurrentRound+1, completed[0].CurrentRound)
+
+	completedRound, err := gameRepo.IsRoundCompleted(s.ctx, tournament.ID, game.ID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), completedRound)
+}
+
+func (s *DBTestSuite) TestMatchRepository_SaveTiming() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	programs := make([]*domain.Program, 2)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     "Timing Program",
+			Language: "python",
+			CodePath: "integration_test_timing",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_tournament",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	match := &domain.Match{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		Program1ID:   programs[0].ID,
+		Program2ID:   programs[1].ID,
+		GameType:     "integration_test",
+		Status:       domain.MatchPending,
+		Priority:     domain.PriorityMedium,
+		RoundNumber:  1,
+		CreatedAt:    time.Now(),
+	}
+	require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+
+	got, err := s.matchRepo.GetByID(s.ctx, match.ID)
+	require.NoError(s.T(), err)
+	assert.Nil(s.T(), got.Timing)
+
+	timing := &domain.MatchTiming{QueueWaitMs: 4000, PrepareMs: 120, StartMs: 300, ExecutionMs: 2500, PersistMs: 15}
+	require.NoError(s.T(), s.matchRepo.SaveTiming(s.ctx, match.ID, timing))
+
+	got, err = s.matchRepo.GetByID(s.ctx, match.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), timing, got.Timing)
+}
+
+func (s *DBTestSuite) TestMatchRepository_EscalateOldPendingMatches() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	programs := make([]*domain.Program, 2)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     "Escalation Program",
+			Language: "python",
+			CodePath: "integration_test_escalation",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_tournament",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	createMatch := func(priority domain.MatchPriority, age time.Duration, practice bool) *domain.Match {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   programs[0].ID,
+			Program2ID:   programs[1].ID,
+			GameType:     "integration_test",
+			Status:       domain.MatchPending,
+			Priority:     priority,
+			RoundNumber:  1,
+			CreatedAt:    time.Now().Add(-age),
+			IsPractice:   practice,
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		return match
+	}
+
+	low := createMatch(domain.PriorityLow, 2*time.Hour, false)
+	medium := createMatch(domain.PriorityMedium, 2*time.Hour, false)
+	high := createMatch(domain.PriorityHigh, 2*time.Hour, false)
+	fresh := createMatch(domain.PriorityLow, time.Minute, false)
+	practice := createMatch(domain.PriorityLow, 2*time.Hour, true)
+
+	escalated, err := s.matchRepo.EscalateOldPendingMatches(s.ctx, time.Hour, 1000)
+	require.NoError(s.T(), err)
+
+	got := make(map[uuid.UUID]domain.MatchPriority)
+	for _, match := range escalated {
+		got[match.ID] = match.Priority
+	}
+	assert.Equal(s.T(), domain.PriorityMedium, got[low.ID])
+	assert.Equal(s.T(), domain.PriorityHigh, got[medium.ID])
+	assert.NotContains(s.T(), got, high.ID)
+	assert.NotContains(s.T(), got, fresh.ID)
+	assert.NotContains(s.T(), got, practice.ID)
+
+	stored, err := s.matchRepo.GetByID(s.ctx, low.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.PriorityMedium, stored.Priority)
+
+	// The next tick: the raised matches age from the escalation, not from creation
+	escalated, err = s.matchRepo.EscalateOldPendingMatches(s.ctx, time.Hour, 1000)
+	require.NoError(s.T(), err)
+	for _, match := range escalated {
+		assert.NotEqual(s.T(), low.ID, match.ID, "low escalated twice within one max age")
+		assert.NotEqual(s.T(), medium.ID, match.ID)
+	}
+
+	// A full max age after the escalation the next step is due
+	_, err = s.db.ExecContext(s.ctx,
+		`UPDATE matches SET priority_changed_at = priority_changed_at - INTERVAL '2 hours' WHERE id = $1`, low.ID)
+	require.NoError(s.T(), err)
+
+	escalated, err = s.matchRepo.EscalateOldPendingMatches(s.ctx, time.Hour, 1000)
+	require.NoError(s.T(), err)
+	got = make(map[uuid.UUID]domain.MatchPriority)
+	for _, match := range escalated {
+		got[match.ID] = match.Priority
+	}
+	assert.Equal(s.T(), domain.PriorityHigh, got[low.ID])
+}
+
+func (s *DBTestSuite) TestMatchSeedMigrationFormula() {
+	// The backfill in migration 000025 must produce the same seed as domain.SeedFromID
+	for i := 0; i < 10; i++ {
+		id := uuid.New()
+
+		var seed int64
+		err := s.db.QueryRowContext(s.ctx,
+			`SELECT ('x' || lpad(substr(replace($1::uuid::text, '-', ''), 1, 8), 16, '0'))::bit(64)::bigint`,
+			id,
+		).Scan(&seed)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), domain.SeedFromID(id), seed)
+	}
+}
+
+// =============================================================================
+// Concurrent Operations Tests
+// =============================================================================
+
+func (s *DBTestSuite) TestConcurrentCreates() {
+	const numUsers = 10
+	users := make([]*domain.User, numUsers)
+	errs := make(chan error, numUsers)
+
+	for i := 0; i < numUsers; i++ {
+		users[i] = &domain.User{
+			ID:           uuid.New(),
+			Username:     "integration_test_concurrent_" + uuid.New().String()[:8],
+			Email:        "concurrent_" + uuid.New().String()[:8] + "@test.com",
+			PasswordHash: "hashed_password",
+		}
+	}
+
+	// Create users concurrently
+	for i := 0; i < numUsers; i++ {
+		go func(user *domain.User) {
+			errs <- s.userRepo.Create(s.ctx, user)
+		}(users[i])
+	}
+
+	// Collect results
+	for i := 0; i < numUsers; i++ {
+		err := <-errs
+		assert.NoError(s.T(), err)
+	}
+
+	// Verify all users were created
+	for _, user := range users {
+		found, err := s.userRepo.GetByID(s.ctx, user.ID)
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), user.Username, found.Username)
+	}
+}
+
+// =============================================================================
+// Helper Functions
+// =============================================================================
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func TestDBSuite(t *testing.T) {
+	suite.Run(t, new(DBTestSuite))
+}
+
+func (s *DBTestSuite) TestWebhookDeliveries() {
+	webhookRepo := db.NewWebhookRepository(s.db)
+	outbox := db.NewNotificationRepository(s.db)
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_webhooks",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	hook := &domain.Webhook{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		URL:          "https://lms.example.com/hook",
+		Secret:       "0123456789abcdef",
+		Events:       []domain.WebhookEvent{domain.WebhookTournamentCompleted},
+	}
+	require.NoError(s.T(), webhookRepo.Create(s.ctx, hook))
+
+	webhooks, err := webhookRepo.ListByTournament(s.ctx, tournament.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), webhooks, 1)
+	assert.Equal(s.T(), hook.Events, webhooks[0].Events)
+
+	secret, err := webhookRepo.GetWebhookSecret(s.ctx, hook.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), hook.Secret, secret)
+
+	// Only subscribed events are enqueued, and each event once
+	enqueued, err := webhookRepo.EnqueueDeliveries(s.ctx, tournament.ID, domain.WebhookTournamentStarted, []byte(`{}`), "started")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(0), enqueued)
+	payload := []byte(`{"event":"tournament.completed"}`)
+	enqueued, err = webhookRepo.EnqueueDeliveries(s.ctx, tournament.ID, domain.WebhookTournamentCompleted, payload, "completed")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), enqueued)
+	enqueued, err = webhookRepo.EnqueueDeliveries(s.ctx, tournament.ID, domain.WebhookTournamentCompleted, payload, "completed")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(0), enqueued)
+
+	// The dispatcher claims webhook deliveries like any other notification
+	claimed, err := outbox.ClaimDue(s.ctx, 100, time.Minute)
+	require.NoError(s.T(), err)
+	var ours *domain.Notification
+	for _, n := range claimed {
+		if n.WebhookID != nil && *n.WebhookID == hook.ID {
+			ours = n
+		}
+	}
+	require.NotNil(s.T(), ours)
+	assert.Nil(s.T(), ours.UserID)
+	assert.Equal(s.T(), domain.NotificationChannelWebhook, ours.Channel)
+	assert.Equal(s.T(), hook.URL, ours.Recipient)
+
+	require.NoError(s.T(), outbox.MarkFailed(s.ctx, ours.ID, "webhook responded with 500"))
+	deliveries, err := webhookRepo.ListDeliveries(s.ctx, hook.ID, 10)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), deliveries, 1)
+	assert.Equal(s.T(), domain.NotificationFailed, deliveries[0].Status)
+	assert.Nil(s.T(), deliveries[0].NextAttemptAt)
+	require.NotNil(s.T(), deliveries[0].LastError)
+	assert.JSONEq(s.T(), string(payload), string(deliveries[0].Payload))
+
+	// Deleting the webhook drops its delivery log
+	require.NoError(s.T(), webhookRepo.Delete(s.ctx, hook.ID))
+	deliveries, err = webhookRepo.ListDeliveries(s.ctx, hook.ID, 10)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), deliveries)
+	assert.True(s.T(), errors.IsNotFound(webhookRepo.Delete(s.ctx, hook.ID)))
+}
+
+func (s *DBTestSuite) TestReferencePrograms() {
+	gameRepo := db.NewGameRepository(s.db)
+	ratingRepo := db.NewRatingRepository(s.db)
+
+	game := &domain.Game{ID: uuid.New(), Name: "integration_test_" + uuid.New().String()[:8], DisplayName: "Reference"}
+	require.NoError(s.T(), gameRepo.Create(s.ctx, game))
+	defer func() {
+		s.db.ExecContext(s.ctx, "DELETE FROM matches WHERE game_type = 'integration_test'")
+		s.db.ExecContext(s.ctx, "DELETE FROM programs WHERE game_id = $1", game.ID)
+		_ = gameRepo.Delete(s.ctx, game.ID)
+	}()
+
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_reference",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	entrant := &domain.Program{
+		ID:           uuid.New(),
+		UserID:       user.ID,
+		TournamentID: &tournament.ID,
+		GameID:       &game.ID,
+		Name:         "Entrant",
+		Language:     "python",
+		CodePath:     "integration_test_entrant",
+		GameType:     "integration_test",
+	}
+	require.NoError(s.T(), s.programRepo.Create(s.ctx, entrant))
+	require.NoError(s.T(), s.tournamentRepo.AddParticipant(s.ctx, &domain.TournamentParticipant{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		ProgramID:    entrant.ID,
+		Rating:       1500,
+	}))
+
+	// The system user owning reference bots is created by the migration
+	rating := 1700
+	bot := &domain.Program{
+		ID:              uuid.New(),
+		UserID:          domain.SystemUserID,
+		GameID:          &game.ID,
+		Name:            "Always-Defect",
+		Language:        "python",
+		CodePath:        "integration_test_reference",
+		GameType:        "integration_test",
+		Version:         1,
+		IsReference:     true,
+		ReferenceRating: &rating,
+	}
+	require.NoError(s.T(), s.programRepo.Create(s.ctx, bot))
+
+	bots, err := s.programRepo.ListReferenceByGame(s.ctx, game.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), bots, 1)
+	assert.True(s.T(), bots[0].IsReference)
+	assert.Equal(s.T(), rating, *bots[0].ReferenceRating)
+
+	// The bot validates new uploads; its validation match is not counted anywhere
+	require.NoError(s.T(), gameRepo.SetValidationBot(s.ctx, game.ID, &bot.ID))
+	stored, err := gameRepo.GetByID(s.ctx, game.ID)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), stored.ValidationBotID)
+	assert.Equal(s.T(), bot.ID, *stored.ValidationBotID)
+
+	validation := &domain.Match{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		Program1ID:   entrant.ID,
+		Program2ID:   bot.ID,
+		GameType:     "integration_test",
+		Status:       domain.MatchPending,
+		Priority:     domain.PriorityLow,
+		IsTest:       true,
+		IsValidation: true,
+		CreatedAt:    time.Now(),
+	}
+	require.NoError(s.T(), s.matchRepo.Create(s.ctx, validation))
+	require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, validation.ID, &domain.MatchResult{MatchID: validation.ID, Winner: 2}))
+	storedMatch, err := s.matchRepo.GetByID(s.ctx, validation.ID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), storedMatch.IsValidation)
+
+	require.NoError(s.T(), s.programRepo.SetValidationResult(s.ctx, entrant.ID, domain.ValidationFailed, "Программа 1: неверный ход"))
+	validated, err := s.programRepo.GetByID(s.ctx, entrant.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.ValidationFailed, validated.ValidationStatus)
+	require.NotNil(s.T(), validated.ValidationError)
+	assert.Equal(s.T(), "Программа 1: неверный ход", *validated.ValidationError)
+
+	require.NoError(s.T(), s.programRepo.SetValidationResult(s.ctx, entrant.ID, domain.ValidationPassed, ""))
+	validated, err = s.programRepo.GetByID(s.ctx, entrant.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.ValidationPassed, validated.ValidationStatus)
+	assert.Nil(s.T(), validated.ValidationError)
+
+	// The bot is not a participant: its fixed rating is used instead
+	rating1, rating2, err := ratingRepo.GetParticipantRatings(s.ctx, tournament.ID, entrant.ID, bot.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1500, rating1)
+	assert.Equal(s.T(), rating, rating2)
+	_, _, err = ratingRepo.GetParticipantRatings(s.ctx, tournament.ID, entrant.ID, uuid.New())
+	assert.True(s.T(), errors.IsNotFound(err))
+
+	// A pending calibration match is not a running round
+	match := &domain.Match{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		Program1ID:   entrant.ID,
+		Program2ID:   bot.ID,
+		GameType:     "integration_test",
+		Status:       domain.MatchPending,
+		Priority:     domain.PriorityHigh,
+		CreatedAt:    time.Now(),
+	}
+	require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+	running, err := s.matchRepo.HasRunningMatchesForGame(s.ctx, tournament.ID, "integration_test")
+	require.NoError(s.T(), err)
+	assert.False(s.T(), running)
+	running, err = s.matchRepo.HasAnyRunningMatches(s.ctx, tournament.ID)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), running)
+
+	require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, match.ID, &domain.MatchResult{
+		MatchID: match.ID,
+		Score1:  3,
+		Score2:  1,
+		Winner:  1,
+	}))
+
+	// The bot is listed separately from participants
+	leaderboard, err := s.tournamentRepo.GetLeaderboard(s.ctx, tournament.ID, 10)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), leaderboard, 1)
+	assert.Equal(s.T(), entrant.ID, leaderboard[0].ProgramID)
+
+	reference, err := s.tournamentRepo.GetReferenceLeaderboard(s.ctx, tournament.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), reference, 1)
+	assert.Equal(s.T(), bot.ID, reference[0].ProgramID)
+	assert.Equal(s.T(), 1, reference[0].Rank)
+	assert.Equal(s.T(), 1, reference[0].Rating)
+	assert.Equal(s.T(), 1, reference[0].Losses)
+
+	// A retired bot keeps its matches but is no longer scheduled
+	require.NoError(s.T(), s.programRepo.RetireReference(s.ctx, game.ID, bot.ID))
+	bots, err = s.programRepo.ListReferenceByGame(s.ctx, game.ID)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), bots)
+	assert.True(s.T(), errors.IsNotFound(s.programRepo.RetireReference(s.ctx, game.ID, bot.ID)))
+
+	// A retired bot no longer validates uploads
+	stored, err = gameRepo.GetByID(s.ctx, game.ID)
+	require.NoError(s.T(), err)
+	assert.Nil(s.T(), stored.ValidationBotID)
+}
+
+func (s *DBTestSuite) TestGameWindows() {
+	gameRepo := db.NewGameRepository(s.db)
+
+	game := &domain.Game{ID: uuid.New(), Name: "integration_test_" + uuid.New().String()[:8], DisplayName: "Windows"}
+	require.NoError(s.T(), gameRepo.Create(s.ctx, game))
+	defer func() { _ = gameRepo.Delete(s.ctx, game.ID) }()
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_windows",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+	require.NoError(s.T(), gameRepo.AddToTournament(s.ctx, tournament.ID, game.ID))
+
+	// Without a window the game is always open
+	tg, err := gameRepo.GetGameWindow(s.ctx, tournament.ID, game.Name)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), tg.HasWindow())
+	_, err = gameRepo.GetGameWindow(s.ctx, tournament.ID, "integration_test_missing")
+	assert.True(s.T(), errors.IsNotFound(err))
+
+	start := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	require.NoError(s.T(), gameRepo.SetGameWindow(s.ctx, tournament.ID, game.ID, &start, &end))
+
+	tg, err = gameRepo.GetGameWindow(s.ctx, tournament.ID, game.Name)
+	require.NoError(s.T(), err)
+	require.True(s.T(), tg.HasWindow())
+	assert.True(s.T(), start.Equal(*tg.WindowStart))
+	assert.False(s.T(), tg.WindowOpen(time.Now()))
+
+	dueWindows := func(now time.Time) []*domain.GameWindow {
+		windows, err := gameRepo.GetDueGameWindows(s.ctx, now)
+		require.NoError(s.T(), err)
+		var ours []*domain.GameWindow
+		for _, w := range windows {
+			if w.TournamentID == tournament.ID {
+				ours = append(ours, w)
+			}
+		}
+		return ours
+	}
+
+	assert.Empty(s.T(), dueWindows(time.Now().UTC()))
+	inside := start.Add(time.Minute)
+	due := dueWindows(inside)
+	require.Len(s.T(), due, 1)
+	assert.Equal(s.T(), game.Name, due[0].GameType)
+
+	// A window is started once
+	claimed, err := gameRepo.ClaimGameWindow(s.ctx, tournament.ID, game.ID, inside)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), claimed)
+	claimed, err = gameRepo.ClaimGameWindow(s.ctx, tournament.ID, game.ID, inside)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), claimed)
+	assert.Empty(s.T(), dueWindows(inside))
+
+	// Setting a new window re-arms the scheduler
+	require.NoError(s.T(), gameRepo.SetGameWindow(s.ctx, tournament.ID, game.ID, &start, &end))
+	assert.Len(s.T(), dueWindows(inside), 1)
+
+	// The window must end after it starts
+	assert.Error(s.T(), gameRepo.SetGameWindow(s.ctx, tournament.ID, game.ID, &end, &start))
+
+	require.NoError(s.T(), gameRepo.SetGameWindow(s.ctx, tournament.ID, game.ID, nil, nil))
+	tg, err = gameRepo.GetGameWindow(s.ctx, tournament.ID, game.Name)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), tg.HasWindow())
+	assert.True(s.T(), errors.IsNotFound(gameRepo.SetGameWindow(s.ctx, uuid.New(), game.ID, nil, nil)))
+}
+
+func (s *DBTestSuite) TestProgramStats() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	programs := make([]*domain.Program, 3)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     "Stats Program",
+			Language: "python",
+			CodePath: "integration_test_stats",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+	a, b, c := programs[0], programs[1], programs[2]
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_stats",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+	require.NoError(s.T(), s.tournamentRepo.AddParticipant(s.ctx, &domain.TournamentParticipant{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		ProgramID:    a.ID,
+		Rating:       1500,
+	}))
+
+	play := func(p1, p2 *domain.Program, result *domain.MatchResult) {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   p1.ID,
+			Program2ID:   p2.ID,
+			GameType:     "integration_test",
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			CreatedAt:    time.Now(),
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		if result != nil {
+			result.MatchID = match.ID
+			require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, match.ID, result))
+		}
+	}
+
+	// a wins once as program1 and once as program2
+	play(a, b, &domain.MatchResult{Score1: 10, Winner: 1})
+	play(b, a, &domain.MatchResult{Score2: 20, Winner: 2})
+	// a loses as program2
+	play(b, a, &domain.MatchResult{Score2: 4, Winner: 1})
+	// Completed draw
+	play(a, c, &domain.MatchResult{Score1: 6, Winner: 0})
+	// Failed match with winner = 0 is not a draw
+	play(c, a, &domain.MatchResult{Winner: 0, ErrorCode: 1, ErrorMessage: "crashed"})
+	// Not played yet
+	play(a, c, nil)
+
+	stats, err := s.matchRepo.GetProgramStats(s.ctx, a.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(5), stats.TotalMatches)
+	assert.Equal(s.T(), int64(2), stats.Wins)
+	assert.Equal(s.T(), int64(1), stats.Losses)
+	assert.Equal(s.T(), int64(1), stats.Draws)
+	assert.Equal(s.T(), int64(1), stats.Failed)
+	assert.InDelta(s.T(), 10.0, stats.AvgScore, 0.001)
+	assert.Equal(s.T(), int64(1), stats.TournamentsParticipated)
+
+	// The opponent sees the same matches mirrored
+	stats, err = s.matchRepo.GetProgramStats(s.ctx, b.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(3), stats.TotalMatches)
+	assert.Equal(s.T(), int64(1), stats.Wins)
+	assert.Equal(s.T(), int64(2), stats.Losses)
+	assert.Equal(s.T(), int64(0), stats.TournamentsParticipated)
+
+	// Counts include matches on either side
+	completed, err := s.matchRepo.GetMatchCountByProgram(s.ctx, a.ID, domain.MatchCompleted)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(4), completed)
+
+	failed, err := s.matchRepo.GetMatchCountByProgram(s.ctx, a.ID, domain.MatchFailed)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), failed)
+
+	pending, err := s.matchRepo.GetMatchCountByProgram(s.ctx, c.ID, domain.MatchPending)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), pending)
+}
+
+func (s *DBTestSuite) TestHeadToHead() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	programs := make([]*domain.Program, 3)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     "H2H Program",
+			Language: "python",
+			CodePath: "integration_test_h2h",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+	a, b, c := programs[0], programs[1], programs[2]
+
+	tournaments := make([]*domain.Tournament, 2)
+	for i := range tournaments {
+		tournaments[i] = &domain.Tournament{
+			ID:       uuid.New(),
+			Code:     uuid.New().String()[:8],
+			Name:     "integration_test_h2h",
+			GameType: "integration_test",
+			Status:   domain.TournamentActive,
+		}
+		require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournaments[i]))
+	}
+	first, second := tournaments[0], tournaments[1]
+
+	play := func(tournament *domain.Tournament, p1, p2 *domain.Program, isTest bool, result *domain.MatchResult) {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   p1.ID,
+			Program2ID:   p2.ID,
+			GameType:     "integration_test",
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			CreatedAt:    time.Now(),
+			IsTest:       isTest,
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		if result != nil {
+			result.MatchID = match.ID
+			require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, match.ID, result))
+		}
+	}
+
+	// a wins on both sides of the pairing
+	play(first, a, b, false, &domain.MatchResult{Score1: 10, Winner: 1})
+	play(first, b, a, false, &domain.MatchResult{Score2: 10, Winner: 2})
+	// a loses and draws in the second tournament
+	play(second, b, a, false, &domain.MatchResult{Score1: 10, Winner: 1})
+	play(second, a, b, false, &domain.MatchResult{Winner: 0})
+	// Pending matches are listed but not counted
+	play(second, a, b, false, nil)
+	// Test matches and other opponents are ignored
+	play(first, a, b, true, &domain.MatchResult{Score1: 10, Winner: 1})
+	play(first, a, c, false, &domain.MatchResult{Score1: 10, Winner: 1})
+
+	record, err := s.matchRepo.GetHeadToHead(s.ctx, a.ID, b.ID, nil)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(2), record.Wins)
+	assert.Equal(s.T(), int64(1), record.Losses)
+	assert.Equal(s.T(), int64(1), record.Draws)
+	assert.Len(s.T(), record.Matches, 5)
+
+	// The record is mirrored from b's side
+	record, err = s.matchRepo.GetHeadToHead(s.ctx, b.ID, a.ID, nil)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), record.Wins)
+	assert.Equal(s.T(), int64(2), record.Losses)
+	assert.Equal(s.T(), int64(1), record.Draws)
+
+	// Tournament filter
+	record, err = s.matchRepo.GetHeadToHead(s.ctx, a.ID, b.ID, &first.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(2), record.Wins)
+	assert.Equal(s.T(), int64(0), record.Losses)
+	assert.Equal(s.T(), int64(0), record.Draws)
+	assert.Len(s.T(), record.Matches, 2)
+
+	// No shared matches
+	record, err = s.matchRepo.GetHeadToHead(s.ctx, b.ID, c.ID, nil)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(0), record.Wins)
+	assert.NotNil(s.T(), record.Matches)
+	assert.Empty(s.T(), record.Matches)
+}
+
+func (s *DBTestSuite) TestProgramRepository_DeleteIfUnused() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	programs := make([]*domain.Program, 3)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     "Prune Program",
+			Language: "python",
+			CodePath: "integration_test_prune",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+	played, opponent, unused := programs[0], programs[1], programs[2]
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_prune",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+	require.NoError(s.T(), s.matchRepo.Create(s.ctx, &domain.Match{
+		ID:           uuid.New(),
+		TournamentID: tournament.ID,
+		Program1ID:   opponent.ID,
+		Program2ID:   played.ID,
+		GameType:     "integration_test",
+		Status:       domain.MatchPending,
+		Priority:     domain.PriorityMedium,
+		CreatedAt:    time.Now(),
+	}))
+
+	// A program with matches on either side is kept
+	deleted, err := s.programRepo.DeleteIfUnused(s.ctx, played.ID)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), deleted)
+	deleted, err = s.programRepo.DeleteIfUnused(s.ctx, opponent.ID)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), deleted)
+
+	deleted, err = s.programRepo.DeleteIfUnused(s.ctx, unused.ID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), deleted)
+	_, err = s.programRepo.GetByID(s.ctx, unused.ID)
+	assert.Error(s.T(), err)
+
+	// Already deleted
+	deleted, err = s.programRepo.DeleteIfUnused(s.ctx, unused.ID)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), deleted)
+}
+
+func (s *DBTestSuite) TestTournamentRepository_GetParticipants() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	newTournament := func() *domain.Tournament {
+		tournament := &domain.Tournament{
+			ID:       uuid.New(),
+			Code:     uuid.New().String()[:8],
+			Name:     "integration_test_participants",
+			GameType: "integration_test",
+			Status:   domain.TournamentPending,
+		}
+		require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+		return tournament
+	}
+	tournament, other := newTournament(), newTournament()
+
+	// Programs join in reverse creation order so the result order comes from joined_at
+	base := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	join := func(tournamentID uuid.UUID, name string, joinedAt time.Time) uuid.UUID {
+		program := &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     name,
+			Language: "python",
+			CodePath: "integration_test_participants",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, program))
+		participantID := uuid.New()
+		require.NoError(s.T(), s.tournamentRepo.AddParticipant(s.ctx, &domain.TournamentParticipant{
+			ID:           participantID,
+			TournamentID: tournamentID,
+			ProgramID:    program.ID,
+			Rating:       1500,
+		}))
+		_, err := s.db.ExecContext(s.ctx, "UPDATE tournament_participants SET created_at = $1 WHERE id = $2", joinedAt, participantID)
+		require.NoError(s.T(), err)
+		return program.ID
+	}
+	third := join(tournament.ID, "third", base.Add(3*time.Minute))
+	second := join(tournament.ID, "second", base.Add(2*time.Minute))
+	first := join(tournament.ID, "first", base.Add(time.Minute))
+	join(other.ID, "other", base)
+
+	all, err := s.tournamentRepo.GetParticipants(s.ctx, tournament.ID, domain.ParticipantFilter{Limit: 50})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), all, 3, "participants of another tournament must not be included")
+	assert.Equal(s.T(), []uuid.UUID{first, second, third}, []uuid.UUID{all[0].ProgramID, all[1].ProgramID, all[2].ProgramID})
+	assert.Equal(s.T(), "first", all[0].ProgramName)
+	assert.Nil(s.T(), all[0].TeamName)
+	assert.Equal(s.T(), 1500, all[0].Rating)
+	assert.True(s.T(), all[0].JoinedAt.Equal(base.Add(time.Minute)))
+
+	page, err := s.tournamentRepo.GetParticipants(s.ctx, tournament.ID, domain.ParticipantFilter{Limit: 2, Offset: 1})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page, 2)
+	assert.Equal(s.T(), second, page[0].ProgramID)
+	assert.Equal(s.T(), third, page[1].ProgramID)
+
+	page, err = s.tournamentRepo.GetParticipants(s.ctx, tournament.ID, domain.ParticipantFilter{Limit: 2, Offset: 3})
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), page)
+
+	total, err := s.tournamentRepo.CountParticipants(s.ctx, tournament.ID, domain.ParticipantFilter{Limit: 2, Offset: 3})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, total, "count ignores limit and offset")
+}
+
+func (s *DBTestSuite) TestTournamentRepository_GetParticipants_LatestByGame() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_participants_latest",
+		GameType: "integration_test",
+		Status:   domain.TournamentPending,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	gameRepo := db.NewGameRepository(s.db)
+	newGame := func() *domain.Game {
+		game := &domain.Game{ID: uuid.New(), Name: "integration_test_" + uuid.New().String()[:8], DisplayName: "Test"}
+		require.NoError(s.T(), gameRepo.Create(s.ctx, game))
+		s.T().Cleanup(func() { s.db.ExecContext(s.ctx, "DELETE FROM games WHERE id = $1", game.ID) })
+		return game
+	}
+	game1, game2 := newGame(), newGame()
+
+	team := &domain.Team{ID: uuid.New(), TournamentID: tournament.ID, Name: "integration_test_team", Code: uuid.New().String()[:8], LeaderID: user.ID}
+	require.NoError(s.T(), db.NewTeamRepository(s.db).Create(s.ctx, team))
+
+	join := func(game *domain.Game, version int) uuid.UUID {
+		program := &domain.Program{
+			ID:           uuid.New(),
+			UserID:       user.ID,
+			TeamID:       &team.ID,
+			TournamentID: &tournament.ID,
+			GameID:       &game.ID,
+			Name:         "bot",
+			Language:     "python",
+			CodePath:     "integration_test_participants",
+			GameType:     game.Name,
+			Version:      version,
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, program))
+		require.NoError(s.T(), s.tournamentRepo.AddParticipant(s.ctx, &domain.TournamentParticipant{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			ProgramID:    program.ID,
+			Rating:       1500,
+		}))
+		return program.ID
+	}
+	join(game1, 1)
+	latest1 := join(game1, 2)
+	latest2 := join(game2, 1)
+
+	latest, err := s.tournamentRepo.GetParticipants(s.ctx, tournament.ID, domain.ParticipantFilter{LatestOnly: true, Limit: 50})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), latest, 2)
+	assert.ElementsMatch(s.T(), []uuid.UUID{latest1, latest2}, []uuid.UUID{latest[0].ProgramID, latest[1].ProgramID})
+	for _, entry := range latest {
+		require.NotNil(s.T(), entry.TeamName)
+		assert.Equal(s.T(), team.Name, *entry.TeamName)
+		require.NotNil(s.T(), entry.GameName)
+	}
+
+	all, err := s.tournamentRepo.GetParticipants(s.ctx, tournament.ID, domain.ParticipantFilter{Limit: 50})
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), all, 3)
+
+	byGame, err := s.tournamentRepo.GetParticipants(s.ctx, tournament.ID, domain.ParticipantFilter{GameID: &game1.ID, LatestOnly: true, Limit: 50})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), byGame, 1)
+	assert.Equal(s.T(), latest1, byGame[0].ProgramID)
+	assert.Equal(s.T(), 2, byGame[0].ProgramVersion)
+	assert.Equal(s.T(), game1.Name, *byGame[0].GameName)
+
+	total, err := s.tournamentRepo.CountParticipants(s.ctx, tournament.ID, domain.ParticipantFilter{GameID: &game1.ID})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, total)
+}
+
 // =============================================================================
-// Concurrent Operations Tests
+// Query Timeout Tests
 // =============================================================================
 
-func (s *DBTestSuite) TestConcurrentCreates() {
-	const numUsers = 10
-	users := make([]*domain.User, numUsers)
-	errs := make(chan error, numUsers)
+func (s *DBTestSuite) TestStatementTimeout() {
+	log, _ := logger.New("error", "json")
+	limited, err := db.New(&config.DatabaseConfig{
+		Host:             getEnv("DB_HOST", "localhost"),
+		Port:             getEnvInt("DB_PORT", 5432),
+		User:             getEnv("DB_USER", "tjudge"),
+		Password:         getEnv("DB_PASSWORD", "secret"),
+		Name:             getEnv("DB_NAME", "tjudge_test"),
+		MaxConnections:   2,
+		MaxIdle:          1,
+		StatementTimeout: 100 * time.Millisecond,
+	}, log, metrics.New())
+	require.NoError(s.T(), err)
+	defer limited.Close()
+
+	// The server cancels the statement even though the context has no deadline
+	_, err = limited.ExecContext(s.ctx, "SELECT pg_sleep(1)")
+	require.Error(s.T(), err)
+	assert.True(s.T(), errors.IsQueryCanceled(err))
+	assert.Equal(s.T(), 503, errors.ToAppError(err).Code)
+
+	// A request deadline cancels the query on the regular pool too
+	ctx, cancel := context.WithTimeout(s.ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = s.db.ExecContext(ctx, "SELECT pg_sleep(1)")
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), 503, errors.ToAppError(err).Code)
+}
+
+func (s *DBTestSuite) TestTournamentRepository_CreateWithGames() {
+	gameRepo := db.NewGameRepository(s.db)
+
+	games := make([]uuid.UUID, 2)
+	for i := range games {
+		game := &domain.Game{ID: uuid.New(), Name: "integration_test_" + uuid.New().String()[:8], DisplayName: "Clone"}
+		require.NoError(s.T(), gameRepo.Create(s.ctx, game))
+		defer func() { _ = gameRepo.Delete(s.ctx, game.ID) }()
+		games[i] = game.ID
+	}
+
+	newTournament := func() *domain.Tournament {
+		return &domain.Tournament{
+			ID:       uuid.New(),
+			Code:     uuid.New().String()[:8],
+			Name:     "integration_test_clone",
+			GameType: "integration_test",
+			Status:   domain.TournamentPending,
+		}
+	}
+
+	tournament := newTournament()
+	require.NoError(s.T(), s.tournamentRepo.CreateWithGames(s.ctx, tournament, games))
+	assert.False(s.T(), tournament.CreatedAt.IsZero())
+
+	attached, err := gameRepo.GetTournamentGames(s.ctx, tournament.ID)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), attached, len(games))
+
+	// An unknown game violates the foreign key: neither the tournament nor its games are kept
+	failed := newTournament()
+	err = s.tournamentRepo.CreateWithGames(s.ctx, failed, []uuid.UUID{games[0], uuid.New()})
+	require.Error(s.T(), err)
+
+	_, err = s.tournamentRepo.GetByID(s.ctx, failed.ID)
+	assert.True(s.T(), errors.IsNotFound(err))
+	var count int
+	require.NoError(s.T(), s.db.QueryRowContext(s.ctx, "SELECT COUNT(*) FROM tournament_games WHERE tournament_id = $1", failed.ID).Scan(&count))
+	assert.Zero(s.T(), count)
+}
+
+func (s *DBTestSuite) TestMatchRepository_DiscardUnfinishedByGame() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	programs := make([]*domain.Program, 2)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     "Regenerate Program",
+			Language: "python",
+			CodePath: "integration_test_regenerate",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_tournament",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	newMatch := func(gameType string) *domain.Match {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   programs[0].ID,
+			Program2ID:   programs[1].ID,
+			GameType:     gameType,
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			CreatedAt:    time.Now(),
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		return match
+	}
+
+	completed := newMatch("integration_test")
+	require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, completed.ID, &domain.MatchResult{
+		MatchID: completed.ID,
+		Score1:  3,
+		Score2:  1,
+		Winner:  1,
+	}))
+	running := newMatch("integration_test")
+	require.NoError(s.T(), s.matchRepo.UpdateStatus(s.ctx, running.ID, domain.MatchRunning))
+	pending := newMatch("integration_test")
+	otherGame := newMatch("integration_test_other")
+
+	deleted, cancelled, err := s.matchRepo.DiscardUnfinishedByGame(s.ctx, tournament.ID, "integration_test")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), deleted)
+	assert.Equal(s.T(), int64(1), cancelled)
+
+	_, err = s.matchRepo.GetByID(s.ctx, pending.ID)
+	assert.True(s.T(), errors.IsNotFound(err))
+
+	stored, err := s.matchRepo.GetByID(s.ctx, running.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.MatchCancelled, stored.Status)
+
+	// Completed matches and other games are left untouched
+	stored, err = s.matchRepo.GetByID(s.ctx, completed.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.MatchCompleted, stored.Status)
+
+	stored, err = s.matchRepo.GetByID(s.ctx, otherGame.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.MatchPending, stored.Status)
+}
+
+func (s *DBTestSuite) TestTeamHeadToHead() {
+	teamRepo := db.NewTeamRepository(s.db)
+
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_team_h2h",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	teams := make([]*domain.Team, 3)
+	for i := range teams {
+		teams[i] = &domain.Team{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Name:         "H2H Team " + strconv.Itoa(i),
+			Code:         uuid.New().String()[:8],
+			LeaderID:     user.ID,
+		}
+		require.NoError(s.T(), teamRepo.Create(s.ctx, teams[i]))
+	}
+
+	program := func(team *domain.Team, version int) *domain.Program {
+		p := &domain.Program{
+			ID:           uuid.New(),
+			UserID:       user.ID,
+			TeamID:       &team.ID,
+			TournamentID: &tournament.ID,
+			Name:         "H2H Program",
+			Language:     "python",
+			CodePath:     "integration_test_team_h2h",
+			GameType:     "integration_test",
+			Version:      version,
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, p))
+		return p
+	}
+	// Team a has two program versions: both count
+	a1, a2 := program(teams[0], 1), program(teams[0], 2)
+	b := program(teams[1], 1)
+	c := program(teams[2], 1)
+
+	play := func(gameType string, p1, p2 *domain.Program, result *domain.MatchResult) {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   p1.ID,
+			Program2ID:   p2.ID,
+			GameType:     gameType,
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			CreatedAt:    time.Now(),
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		if result != nil {
+			result.MatchID = match.ID
+			require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, match.ID, result))
+		}
+	}
+
+	play("dilemma", a1, b, &domain.MatchResult{Score1: 10, Score2: 4, Winner: 1})
+	play("dilemma", b, a2, &domain.MatchResult{Score1: 7, Score2: 3, Winner: 1})
+	play("tug_of_war", a2, b, &domain.MatchResult{Score1: 5, Score2: 5, Winner: 0})
+	// Pending matches are listed but not counted
+	play("tug_of_war", b, a1, nil)
+	// Other teams are ignored
+	play("dilemma", a1, c, &domain.MatchResult{Score1: 10, Winner: 1})
+
+	record, err := s.matchRepo.GetTeamHeadToHead(s.ctx, tournament.ID, teams[0].ID, teams[1].ID, "")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.HeadToHeadTotals{Matches: 3, Wins: 1, Losses: 1, Draws: 1, ScoreFor: 18, ScoreAgainst: 16}, record.Totals)
+	require.Len(s.T(), record.Games, 2)
+	assert.Equal(s.T(), "dilemma", record.Games[0].GameType)
+	assert.Equal(s.T(), int64(13), record.Games[0].ScoreFor)
+	assert.Len(s.T(), record.Games[0].RecentMatches, 2)
+	assert.Equal(s.T(), "tug_of_war", record.Games[1].GameType)
+	assert.Equal(s.T(), int64(1), record.Games[1].Draws)
+	assert.Len(s.T(), record.Games[1].RecentMatches, 2)
+
+	// The record is mirrored from b's side and can be narrowed to one game
+	record, err = s.matchRepo.GetTeamHeadToHead(s.ctx, tournament.ID, teams[1].ID, teams[0].ID, "dilemma")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.HeadToHeadTotals{Matches: 2, Wins: 1, Losses: 1, ScoreFor: 11, ScoreAgainst: 13}, record.Totals)
+	assert.Len(s.T(), record.Games, 1)
+
+	// No shared matches
+	record, err = s.matchRepo.GetTeamHeadToHead(s.ctx, tournament.ID, teams[1].ID, teams[2].ID, "")
+	require.NoError(s.T(), err)
+	assert.NotNil(s.T(), record.Games)
+	assert.Empty(s.T(), record.Games)
+}
+
+func (s *DBTestSuite) TestCountWithFilter() {
+	gameType := "integration_count_" + uuid.New().String()[:8]
+
+	var tournamentID uuid.UUID
+	for i, status := range []domain.TournamentStatus{domain.TournamentActive, domain.TournamentActive, domain.TournamentPending} {
+		tournament := &domain.Tournament{
+			ID:       uuid.New(),
+			Code:     uuid.New().String()[:8],
+			Name:     "integration_test_count",
+			GameType: gameType,
+			Status:   status,
+		}
+		require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+		if i == 0 {
+			tournamentID = tournament.ID
+		}
+	}
+
+	count, err := s.tournamentRepo.CountWithFilter(s.ctx, domain.TournamentFilter{GameType: gameType, Status: domain.TournamentActive, Limit: 1})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, count)
+
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
 
-	for i := 0; i < numUsers; i++ {
-		users[i] = &domain.User{
+	program := &domain.Program{
+		ID:       uuid.New(),
+		UserID:   user.ID,
+		Name:     "Count Program",
+		Language: "python",
+		CodePath: "integration_test_count",
+		GameType: gameType,
+	}
+	require.NoError(s.T(), s.programRepo.Create(s.ctx, program))
+
+	for _, isTest := range []bool{false, false, true} {
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, &domain.Match{
 			ID:           uuid.New(),
-			Username:     "integration_test_concurrent_" + uuid.New().String()[:8],
-			Email:        "concurrent_" + uuid.New().String()[:8] + "@test.com",
+			TournamentID: tournamentID,
+			Program1ID:   program.ID,
+			Program2ID:   program.ID,
+			GameType:     gameType,
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			CreatedAt:    time.Now(),
+			IsTest:       isTest,
+		}))
+	}
+
+	count, err = s.matchRepo.CountWithFilter(s.ctx, domain.MatchFilter{TournamentID: &tournamentID, Limit: 1, Offset: 1})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, count)
+
+	count, err = s.matchRepo.CountWithFilter(s.ctx, domain.MatchFilter{TournamentID: &tournamentID, ExcludeTest: true})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, count)
+}
+
+func (s *DBTestSuite) TestCrossGameStats() {
+	gameRepo := db.NewGameRepository(s.db)
+	teamRepo := db.NewTeamRepository(s.db)
+
+	game := &domain.Game{ID: uuid.New(), Name: "integration_test_" + uuid.New().String()[:8], DisplayName: "Cross"}
+	require.NoError(s.T(), gameRepo.Create(s.ctx, game))
+	defer func() {
+		s.db.ExecContext(s.ctx, "DELETE FROM matches WHERE game_type = $1", game.Name)
+		s.db.ExecContext(s.ctx, "DELETE FROM programs WHERE game_id = $1", game.ID)
+		_ = gameRepo.Delete(s.ctx, game.ID)
+	}()
+
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_cross_game",
+		GameType: game.Name,
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	programs := make([]*domain.Program, 2)
+	for i := range programs {
+		team := &domain.Team{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Name:         "Cross Team " + strconv.Itoa(i),
+			Code:         uuid.New().String()[:8],
+			LeaderID:     user.ID,
+		}
+		require.NoError(s.T(), teamRepo.Create(s.ctx, team))
+
+		programs[i] = &domain.Program{
+			ID:           uuid.New(),
+			UserID:       user.ID,
+			TeamID:       &team.ID,
+			TournamentID: &tournament.ID,
+			GameID:       &game.ID,
+			Name:         "Cross Program",
+			Language:     "python",
+			CodePath:     "integration_test_cross_game",
+			GameType:     game.Name,
+			Version:      1,
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+
+	play := func(isTest bool, result *domain.MatchResult) uuid.UUID {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   programs[0].ID,
+			Program2ID:   programs[1].ID,
+			GameType:     game.Name,
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			IsTest:       isTest,
+			CreatedAt:    time.Now(),
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		result.MatchID = match.ID
+		require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, match.ID, result))
+		return match.ID
+	}
+
+	// The aggregate must match the leaderboard computed from all matches
+	assertConsistent := func() []*domain.CrossGameLeaderboardEntry {
+		entries, err := s.tournamentRepo.GetCrossGameLeaderboard(s.ctx, tournament.ID)
+		require.NoError(s.T(), err)
+		expected, err := s.tournamentRepo.GetCrossGameLeaderboardFromMatches(s.ctx, tournament.ID)
+		require.NoError(s.T(), err)
+		require.Len(s.T(), entries, len(expected))
+
+		byTeam := make(map[uuid.UUID]*domain.CrossGameLeaderboardEntry)
+		for _, entry := range expected {
+			byTeam[*entry.TeamID] = entry
+		}
+		for _, entry := range entries {
+			want := byTeam[*entry.TeamID]
+			require.NotNil(s.T(), want)
+			assert.Equal(s.T(), want.TotalRating, entry.TotalRating)
+			assert.Equal(s.T(), want.TotalWins, entry.TotalWins)
+			assert.Equal(s.T(), want.TotalLosses, entry.TotalLosses)
+			assert.Equal(s.T(), want.TotalGames, entry.TotalGames)
+			assert.Equal(s.T(), want.GameRatings, entry.GameRatings)
+		}
+		return entries
+	}
+
+	play(false, &domain.MatchResult{Score1: 10, Score2: 4, Winner: 1})
+	draw := play(false, &domain.MatchResult{Score1: 5, Score2: 5, Winner: 0})
+	play(false, &domain.MatchResult{Score1: 0, Score2: 3, Winner: 2, ErrorCode: 1, ErrorMessage: "crashed"})
+	// Test matches are not counted
+	play(true, &domain.MatchResult{Score1: 100, Winner: 1})
+
+	entries := assertConsistent()
+	require.Len(s.T(), entries, 2)
+	assert.Equal(s.T(), *programs[0].TeamID, *entries[0].TeamID)
+	assert.Equal(s.T(), 15, entries[0].TotalRating)
+	assert.Equal(s.T(), 1, entries[0].TotalWins)
+	assert.Equal(s.T(), 1, entries[0].TotalLosses)
+	// The failed match counts as a win with its score, but not as a played game
+	assert.Equal(s.T(), 2, entries[0].TotalGames)
+	assert.Equal(s.T(), 12, entries[1].TotalRating)
+	assert.Equal(s.T(), 1, entries[1].TotalWins)
+
+	// A reset failed match no longer counts
+	reset, err := s.matchRepo.ResetFailedMatches(s.ctx, tournament.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), reset)
+	entries = assertConsistent()
+	assert.Equal(s.T(), 9, entries[1].TotalRating)
+	assert.Zero(s.T(), entries[1].TotalWins)
+
+	// A drifted aggregate is restored by the rebuild
+	_, err = s.db.ExecContext(s.ctx, "UPDATE team_game_stats SET wins = 100 WHERE tournament_id = $1", tournament.ID)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.tournamentRepo.RebuildCrossGameStats(s.ctx, tournament.ID))
+	assertConsistent()
+
+	// A manual correction replaces the match's contribution
+	score1, score2, winner := 2, 7, 2
+	edit := &domain.MatchResultAdjustment{
+		MatchID:    draw,
+		Action:     domain.MatchAdjustmentEdit,
+		NewScore1:  &score1,
+		NewScore2:  &score2,
+		NewWinner:  &winner,
+		Reason:     "scorer bug",
+		AdjustedBy: &user.ID,
+	}
+	require.NoError(s.T(), s.matchRepo.AdjustResult(s.ctx, edit))
+	assert.Equal(s.T(), domain.MatchCompleted, edit.OldStatus)
+	assert.Equal(s.T(), 5, *edit.OldScore1)
+	assert.Equal(s.T(), 0, *edit.OldWinner)
+	entries = assertConsistent()
+	assert.Equal(s.T(), 12, entries[0].TotalRating)
+	assert.Equal(s.T(), 11, entries[1].TotalRating)
+	assert.Equal(s.T(), 1, entries[1].TotalWins)
+
+	// An annulled match is pending again and no longer counts
+	require.NoError(s.T(), s.matchRepo.AdjustResult(s.ctx, &domain.MatchResultAdjustment{
+		MatchID: draw, Action: domain.MatchAdjustmentAnnul, Reason: "rerun", AdjustedBy: &user.ID,
+	}))
+	match, err := s.matchRepo.GetByID(s.ctx, draw)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.MatchPending, match.Status)
+	assert.Nil(s.T(), match.Winner)
+	assertConsistent()
+
+	// Without a result there is nothing to adjust
+	err = s.matchRepo.AdjustResult(s.ctx, &domain.MatchResultAdjustment{MatchID: draw, Action: domain.MatchAdjustmentAnnul, Reason: "again"})
+	assert.True(s.T(), errors.IsConflict(err))
+
+	history, err := s.matchRepo.GetResultAdjustments(s.ctx, draw)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), history, 2)
+	assert.Equal(s.T(), domain.MatchAdjustmentAnnul, history[0].Action)
+	assert.Equal(s.T(), domain.MatchAdjustmentEdit, history[1].Action)
+	assert.Equal(s.T(), "scorer bug", history[1].Reason)
+	assert.Equal(s.T(), 2, *history[1].NewScore1)
+
+	// Deleted matches are subtracted
+	_, err = s.matchRepo.DeleteMatchesForGame(s.ctx, tournament.ID, game.Name)
+	require.NoError(s.T(), err)
+	for _, entry := range assertConsistent() {
+		assert.Zero(s.T(), entry.TotalRating)
+		assert.Zero(s.T(), entry.TotalGames)
+	}
+}
+
+func (s *DBTestSuite) TestMatchRepository_ListByProgram() {
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	programs := make([]*domain.Program, 3)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Name:     "History Program",
+			Language: "python",
+			CodePath: "integration_test_history",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
+	}
+	a, b, c := programs[0], programs[1], programs[2]
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_history",
+		GameType: "integration_test",
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	insert := func(p1, p2 *domain.Program, round int, completed bool) uuid.UUID {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   p1.ID,
+			Program2ID:   p2.ID,
+			GameType:     "integration_test",
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  round,
+			CreatedAt:    time.Now(),
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		if completed {
+			require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, match.ID, &domain.MatchResult{MatchID: match.ID, Winner: 1}))
+		}
+		return match.ID
+	}
+
+	// a plays on both sides; b vs c does not involve a
+	expected := make(map[uuid.UUID]bool)
+	completed := make(map[uuid.UUID]bool)
+	for round := 1; round <= 3; round++ {
+		id := insert(a, b, round, round != 3)
+		expected[id] = true
+		completed[id] = round != 3
+		id = insert(c, a, round, true)
+		expected[id] = true
+		completed[id] = true
+		insert(b, c, round, true)
+	}
+
+	pageSize := 2
+	collect := func(filter domain.MatchFilter) map[uuid.UUID]bool {
+		seen := make(map[uuid.UUID]bool)
+		var after *string
+		for {
+			matches, hasMore, err := s.matchRepo.ListWithCursor(s.ctx, filter, &pagination.PageRequest{First: &pageSize, After: after})
+			require.NoError(s.T(), err)
+			for _, m := range matches {
+				assert.True(s.T(), m.Program1ID == a.ID || m.Program2ID == a.ID, "match %s does not involve the program", m.ID)
+				assert.False(s.T(), seen[m.ID], "match %s returned twice", m.ID)
+				seen[m.ID] = true
+			}
+			if !hasMore {
+				return seen
+			}
+			cursor := db.GetMatchKeysetCursor(matches[len(matches)-1])
+			after = &cursor
+		}
+	}
+
+	assert.Equal(s.T(), expected, collect(domain.MatchFilter{ProgramID: &a.ID}))
+
+	onlyCompleted := collect(domain.MatchFilter{ProgramID: &a.ID, Status: domain.MatchCompleted})
+	assert.Len(s.T(), onlyCompleted, 5)
+	for id := range onlyCompleted {
+		assert.True(s.T(), completed[id], "match %s is not completed", id)
+	}
+}
+
+func (s *DBTestSuite) TestMatchRepository_HidePrivateTournaments() {
+	teamRepo := db.NewTeamRepository(s.db)
+
+	newUser := func() *domain.User {
+		user := &domain.User{
+			ID:           uuid.New(),
+			Username:     "integration_test_user_" + uuid.New().String()[:8],
+			Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
 			PasswordHash: "hashed_password",
 		}
+		require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+		return user
 	}
+	creator, member, outsider := newUser(), newUser(), newUser()
 
-	// Create users concurrently
-	for i := 0; i < numUsers; i++ {
-		go func(user *domain.User) {
-			errs <- s.userRepo.Create(s.ctx, user)
-		}(users[i])
+	newTournament := func(visibility domain.TournamentVisibility) *domain.Tournament {
+		tournament := &domain.Tournament{
+			ID:         uuid.New(),
+			Code:       uuid.New().String()[:8],
+			Name:       "integration_test_hidden_matches",
+			GameType:   "integration_test",
+			Status:     domain.TournamentActive,
+			Visibility: visibility,
+			CreatorID:  &creator.ID,
+		}
+		require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+		return tournament
 	}
+	public, private := newTournament(domain.VisibilityPublic), newTournament(domain.VisibilityPrivate)
 
-	// Collect results
-	for i := 0; i < numUsers; i++ {
-		err := <-errs
-		assert.NoError(s.T(), err)
+	team := &domain.Team{ID: uuid.New(), TournamentID: private.ID, Name: "Hidden Team", Code: uuid.New().String()[:8], LeaderID: member.ID}
+	require.NoError(s.T(), teamRepo.Create(s.ctx, team))
+	require.NoError(s.T(), teamRepo.AddMember(s.ctx, &domain.TeamMember{ID: uuid.New(), TeamID: team.ID, UserID: member.ID}))
+
+	programs := make([]*domain.Program, 2)
+	for i := range programs {
+		programs[i] = &domain.Program{
+			ID:       uuid.New(),
+			UserID:   member.ID,
+			Name:     "Hidden Program",
+			Language: "python",
+			CodePath: "integration_test_hidden",
+			GameType: "integration_test",
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, programs[i]))
 	}
 
-	// Verify all users were created
-	for _, user := range users {
-		found, err := s.userRepo.GetByID(s.ctx, user.ID)
+	matchIDs := make(map[uuid.UUID]uuid.UUID)
+	for _, tournament := range []*domain.Tournament{public, private} {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   programs[0].ID,
+			Program2ID:   programs[1].ID,
+			GameType:     "integration_test",
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			CreatedAt:    time.Now(),
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		matchIDs[tournament.ID] = match.ID
+	}
+
+	pageSize := 10
+	visible := func(viewerID *uuid.UUID) []uuid.UUID {
+		filter := domain.MatchFilter{ProgramID: &programs[0].ID, HidePrivate: true, ViewerID: viewerID, Limit: 10}
+
+		listed, err := s.matchRepo.List(s.ctx, filter)
 		require.NoError(s.T(), err)
-		assert.Equal(s.T(), user.Username, found.Username)
+		paged, _, err := s.matchRepo.ListWithCursor(s.ctx, filter, &pagination.PageRequest{First: &pageSize})
+		require.NoError(s.T(), err)
+		count, err := s.matchRepo.CountWithFilter(s.ctx, filter)
+		require.NoError(s.T(), err)
+
+		ids := make([]uuid.UUID, 0, len(listed))
+		for _, m := range listed {
+			ids = append(ids, m.ID)
+		}
+		pagedIDs := make([]uuid.UUID, 0, len(paged))
+		for _, m := range paged {
+			pagedIDs = append(pagedIDs, m.ID)
+		}
+		assert.ElementsMatch(s.T(), ids, pagedIDs)
+		assert.Equal(s.T(), len(ids), count)
+		return ids
+	}
+
+	publicOnly := []uuid.UUID{matchIDs[public.ID]}
+	both := []uuid.UUID{matchIDs[public.ID], matchIDs[private.ID]}
+	assert.ElementsMatch(s.T(), publicOnly, visible(nil), "anonymous")
+	assert.ElementsMatch(s.T(), publicOnly, visible(&outsider.ID), "non-member")
+	assert.ElementsMatch(s.T(), both, visible(&member.ID), "member")
+	assert.ElementsMatch(s.T(), both, visible(&creator.ID), "creator")
+}
+
+func (s *DBTestSuite) TestTournamentTimeBudget() {
+	started := time.Now().Add(-time.Hour)
+	newTournament := func(status domain.TournamentStatus, metadata map[string]interface{}) *domain.Tournament {
+		tournament := &domain.Tournament{
+			ID:        uuid.New(),
+			Code:      uuid.New().String()[:8],
+			Name:      "integration_test_time_budget",
+			GameType:  "integration_test",
+			Status:    status,
+			StartTime: &started,
+			Metadata:  metadata,
+		}
+		require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+		return tournament
+	}
+
+	budgeted := newTournament(domain.TournamentActive, map[string]interface{}{domain.MetaTimeBudget: "2h"})
+	unlimited := newTournament(domain.TournamentActive, map[string]interface{}{"round_lock": "game"})
+	finished := newTournament(domain.TournamentCompleted, map[string]interface{}{domain.MetaTimeBudget: "2h"})
+
+	tournaments, err := s.tournamentRepo.GetActiveWithTimeBudget(s.ctx)
+	require.NoError(s.T(), err)
+
+	found := make(map[uuid.UUID]*domain.Tournament)
+	for _, t := range tournaments {
+		found[t.ID] = t
+	}
+	require.Contains(s.T(), found, budgeted.ID)
+	assert.NotContains(s.T(), found, unlimited.ID)
+	assert.NotContains(s.T(), found, finished.ID)
+	assert.Equal(s.T(), 2*time.Hour, found[budgeted.ID].TimeBudget())
+
+	// The warning is claimed exactly once
+	claimed, err := s.tournamentRepo.ClaimTimeBudgetWarning(s.ctx, budgeted.ID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), claimed)
+
+	claimed, err = s.tournamentRepo.ClaimTimeBudgetWarning(s.ctx, budgeted.ID)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), claimed)
+
+	reloaded, err := s.tournamentRepo.GetByID(s.ctx, budgeted.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), true, reloaded.Metadata[domain.MetaTimeBudgetWarned])
+	assert.Equal(s.T(), "2h", reloaded.Metadata[domain.MetaTimeBudget])
+}
+
+func (s *DBTestSuite) TestTournamentAutoStartFailure() {
+	scheduled := time.Now().Add(-time.Minute)
+	tournament := &domain.Tournament{
+		ID:        uuid.New(),
+		Code:      uuid.New().String()[:8],
+		Name:      "integration_test_auto_start",
+		GameType:  "integration_test",
+		Status:    domain.TournamentPending,
+		StartTime: &scheduled,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	reload := func() *domain.Tournament {
+		reloaded, err := s.tournamentRepo.GetByID(s.ctx, tournament.ID)
+		require.NoError(s.T(), err)
+		return reloaded
+	}
+	version := reload().Version
+
+	// Waiting for participants keeps the attempt counter untouched
+	require.NoError(s.T(), s.tournamentRepo.RecordAutoStartDelay(s.ctx, tournament.ID, "not enough participants: 1 of 2 required"))
+	delayed := reload()
+	assert.Equal(s.T(), 0, delayed.AutoStartAttempts())
+	assert.Equal(s.T(), version+1, delayed.Version)
+
+	// The same reason is not written again
+	require.NoError(s.T(), s.tournamentRepo.RecordAutoStartDelay(s.ctx, tournament.ID, "not enough participants: 1 of 2 required"))
+	assert.Equal(s.T(), version+1, reload().Version)
+
+	attempts, err := s.tournamentRepo.RecordAutoStartFailure(s.ctx, tournament.ID, "elimination format is not available")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, attempts)
+	failed := reload()
+	assert.Equal(s.T(), version+2, failed.Version)
+
+	// A stale copy no longer overwrites the recorded failure
+	err = s.tournamentRepo.Update(s.ctx, delayed)
+	assert.True(s.T(), errors.IsConflict(err))
+
+	require.NoError(s.T(), s.tournamentRepo.ClearAutoStartFailure(s.ctx, tournament.ID))
+	cleared := reload()
+	assert.Nil(s.T(), cleared.AutoStartError())
+	assert.Equal(s.T(), version+3, cleared.Version)
+}
+
+func (s *DBTestSuite) TestTournamentRepository_ImportPrograms() {
+	teamRepo := db.NewTeamRepository(s.db)
+
+	newUser := func() *domain.User {
+		user := &domain.User{
+			ID:           uuid.New(),
+			Username:     "integration_test_user_" + uuid.New().String()[:8],
+			Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+			PasswordHash: "hashed_password",
+		}
+		require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+		return user
+	}
+	newTournament := func(name string) *domain.Tournament {
+		t := &domain.Tournament{
+			ID:       uuid.New(),
+			Code:     uuid.New().String()[:8],
+			Name:     name,
+			GameType: "integration_test",
+			Status:   domain.TournamentPending,
+		}
+		require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, t))
+		return t
+	}
+	newTeam := func(tournament *domain.Tournament, leader *domain.User, members ...*domain.User) *domain.Team {
+		team := &domain.Team{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Name:         "Import Team " + leader.Username,
+			Code:         uuid.New().String()[:8],
+			LeaderID:     leader.ID,
+		}
+		require.NoError(s.T(), teamRepo.Create(s.ctx, team))
+		for _, u := range append([]*domain.User{leader}, members...) {
+			require.NoError(s.T(), teamRepo.AddMember(s.ctx, &domain.TeamMember{ID: uuid.New(), TeamID: team.ID, UserID: u.ID}))
+		}
+		return team
+	}
+	newProgram := func(tournament *domain.Tournament, team *domain.Team, version int, hash string) *domain.Program {
+		filePath := "programs/" + hash + ".py"
+		p := &domain.Program{
+			ID:           uuid.New(),
+			UserID:       team.LeaderID,
+			TeamID:       &team.ID,
+			TournamentID: &tournament.ID,
+			Name:         "Import Program",
+			Language:     "python",
+			CodePath:     "integration_test_import",
+			FilePath:     &filePath,
+			GameType:     "integration_test",
+			Version:      version,
+			ContentHash:  &hash,
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, p))
+		return p
+	}
+
+	qualifier := newTournament("integration_test_import_qualifier")
+	final := newTournament("integration_test_import_final")
+
+	leaderA, memberA, leaderB := newUser(), newUser(), newUser()
+	teamA := newTeam(qualifier, leaderA, memberA)
+	teamB := newTeam(qualifier, leaderB)
+	newProgram(qualifier, teamA, 1, "a1")
+	latestA := newProgram(qualifier, teamA, 2, "a2")
+	latestB := newProgram(qualifier, teamB, 1, "b1")
+
+	// Team B already registered in the final with the same program
+	finalB := newTeam(final, leaderB)
+	existingB := newProgram(final, finalB, 1, "b1")
+
+	programs, err := s.tournamentRepo.GetLatestTeamPrograms(s.ctx, qualifier.ID, []uuid.UUID{teamA.ID, teamB.ID})
+	require.NoError(s.T(), err)
+	ids := make([]uuid.UUID, 0, len(programs))
+	for _, p := range programs {
+		ids = append(ids, p.ID)
+	}
+	assert.ElementsMatch(s.T(), []uuid.UUID{latestA.ID, latestB.ID}, ids)
+
+	imported, err := s.tournamentRepo.ImportPrograms(s.ctx, final.ID, []*domain.Program{latestA, latestB})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), imported, 2)
+
+	// Team A is copied with its members, the program links the same file
+	assert.False(s.T(), imported[0].Skipped)
+	assert.NotEqual(s.T(), teamA.ID, imported[0].TeamID)
+	assert.Equal(s.T(), teamA.Name, imported[0].TeamName)
+	members, err := teamRepo.GetMembers(s.ctx, imported[0].TeamID)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), members, 2)
+
+	copied, err := s.programRepo.GetByID(s.ctx, imported[0].ProgramID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), final.ID, *copied.TournamentID)
+	assert.Equal(s.T(), latestA.FilePath, copied.FilePath)
+	assert.Equal(s.T(), 1, copied.Version)
+
+	// Team B reuses its final team and the unchanged program is skipped
+	assert.True(s.T(), imported[1].Skipped)
+	assert.Equal(s.T(), finalB.ID, imported[1].TeamID)
+	assert.Equal(s.T(), existingB.ID, imported[1].ProgramID)
+
+	count, err := s.tournamentRepo.GetParticipantsCount(s.ctx, final.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, count)
+
+	// Importing again reuses the copied team
+	again, err := s.tournamentRepo.ImportPrograms(s.ctx, final.ID, []*domain.Program{latestA})
+	require.NoError(s.T(), err)
+	assert.True(s.T(), again[0].Skipped)
+	assert.Equal(s.T(), imported[0].TeamID, again[0].TeamID)
+}
+
+func (s *DBTestSuite) TestTournamentRepository_GetTeamRatings() {
+	gameRepo := db.NewGameRepository(s.db)
+	teamRepo := db.NewTeamRepository(s.db)
+
+	games := make([]*domain.Game, 2)
+	for i := range games {
+		games[i] = &domain.Game{ID: uuid.New(), Name: "integration_test_" + uuid.New().String()[:8], DisplayName: "Team Rating"}
+		require.NoError(s.T(), gameRepo.Create(s.ctx, games[i]))
+	}
+	defer func() {
+		for _, game := range games {
+			s.db.ExecContext(s.ctx, "DELETE FROM matches WHERE game_type = $1", game.Name)
+			s.db.ExecContext(s.ctx, "DELETE FROM programs WHERE game_id = $1", game.ID)
+			_ = gameRepo.Delete(s.ctx, game.ID)
+		}
+	}()
+
+	user := &domain.User{
+		ID:           uuid.New(),
+		Username:     "integration_test_user_" + uuid.New().String()[:8],
+		Email:        "integration_" + uuid.New().String()[:8] + "@test.com",
+		PasswordHash: "hashed_password",
+	}
+	require.NoError(s.T(), s.userRepo.Create(s.ctx, user))
+
+	tournament := &domain.Tournament{
+		ID:       uuid.New(),
+		Code:     uuid.New().String()[:8],
+		Name:     "integration_test_team_ratings",
+		GameType: games[0].Name,
+		Status:   domain.TournamentActive,
+	}
+	require.NoError(s.T(), s.tournamentRepo.Create(s.ctx, tournament))
+
+	newTeam := func(name string) *domain.Team {
+		team := &domain.Team{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Name:         name,
+			Code:         uuid.New().String()[:8],
+			LeaderID:     user.ID,
+		}
+		require.NoError(s.T(), teamRepo.Create(s.ctx, team))
+		return team
+	}
+	newProgram := func(team *domain.Team, game *domain.Game) *domain.Program {
+		p := &domain.Program{
+			ID:           uuid.New(),
+			UserID:       user.ID,
+			TeamID:       &team.ID,
+			TournamentID: &tournament.ID,
+			GameID:       &game.ID,
+			Name:         "Team Rating Program",
+			Language:     "python",
+			CodePath:     "integration_test_team_ratings",
+			GameType:     game.Name,
+			Version:      1,
+		}
+		require.NoError(s.T(), s.programRepo.Create(s.ctx, p))
+		return p
+	}
+	play := func(game *domain.Game, p1, p2 *domain.Program, result *domain.MatchResult) {
+		match := &domain.Match{
+			ID:           uuid.New(),
+			TournamentID: tournament.ID,
+			Program1ID:   p1.ID,
+			Program2ID:   p2.ID,
+			GameType:     game.Name,
+			Status:       domain.MatchPending,
+			Priority:     domain.PriorityMedium,
+			RoundNumber:  1,
+			CreatedAt:    time.Now(),
+		}
+		require.NoError(s.T(), s.matchRepo.Create(s.ctx, match))
+		result.MatchID = match.ID
+		require.NoError(s.T(), s.matchRepo.UpdateResult(s.ctx, match.ID, result))
 	}
+
+	allrounder, specialist, idle := newTeam("Allrounder"), newTeam("Specialist"), newTeam("Idle")
+	a1, a2 := newProgram(allrounder, games[0]), newProgram(allrounder, games[1])
+	b1 := newProgram(specialist, games[0])
+	c2 := newProgram(idle, games[1])
+
+	play(games[0], a1, b1, &domain.MatchResult{Score1: 10, Score2: 30, Winner: 2})
+	play(games[1], a2, c2, &domain.MatchResult{Score1: 20, Score2: 0, Winner: 1})
+
+	ratings, err := s.tournamentRepo.GetTeamRatings(s.ctx, tournament.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), ratings, 3)
+
+	assert.Equal(s.T(), allrounder.ID, ratings[0].TeamID)
+	assert.Equal(s.T(), 30.0, ratings[0].TotalRating)
+	assert.Equal(s.T(), 2, ratings[0].GamesPlayed)
+	assert.Equal(s.T(), map[string]float64{games[0].Name: 10, games[1].Name: 20}, ratings[0].GameBreakdown)
+
+	assert.Equal(s.T(), specialist.ID, ratings[1].TeamID)
+	assert.Equal(s.T(), 30.0, ratings[1].TotalRating)
+	assert.Equal(s.T(), 1, ratings[1].GamesPlayed)
+
+	assert.Equal(s.T(), idle.ID, ratings[2].TeamID)
+	assert.Equal(s.T(), 1, ratings[2].GamesPlayed)
+
+	// Normalized, the specialist is ahead: it tops its only game
+	normalized := domain.NormalizeTeamRatings(ratings)
+	assert.Equal(s.T(), specialist.ID, normalized[0].TeamID)
+	assert.Equal(s.T(), 100.0, normalized[0].TotalRating)
 }
 
 // =============================================================================
-// Helper Functions
+// Demo Seed Tests
 // =============================================================================
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+func (s *DBTestSuite) TestSeed_Idempotent() {
+	cleanup := func() {
+		s.db.ExecContext(s.ctx, "DELETE FROM tournaments WHERE code IN ('DEMOPEND', 'DEMOACTV')")
+		s.db.ExecContext(s.ctx, "DELETE FROM users WHERE username LIKE 'demo\\_%'")
 	}
-	return defaultValue
-}
+	cleanup()
+	defer cleanup()
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if i, err := strconv.Atoi(value); err == nil {
-			return i
+	log, _ := logger.New("error", "json")
+	seeder := seed.NewSeeder(s.db, log)
+	opts := seed.Options{
+		Teams:       3,
+		Games:       len(seed.BuiltinGames),
+		Password:    seed.DefaultPassword,
+		ProgramsDir: s.T().TempDir(),
+	}
+
+	first, err := seeder.Run(s.ctx, opts)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), first.Tournaments, 2)
+	require.Len(s.T(), first.Credentials, 4)
+	assert.Equal(s.T(), 2*3*len(seed.BuiltinGames), first.ProgramsCreated)
+	for _, c := range first.Credentials {
+		assert.Equal(s.T(), seed.DefaultPassword, c.Password)
+	}
+
+	admin, err := s.userRepo.GetByUsername(s.ctx, seed.AdminUsername)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.RoleAdmin, admin.Role)
+
+	// Re-running finds everything and creates nothing
+	second, err := seeder.Run(s.ctx, opts)
+	require.NoError(s.T(), err)
+	assert.Zero(s.T(), second.ProgramsCreated)
+	for _, c := range second.Credentials {
+		assert.Empty(s.T(), c.Password)
+	}
+
+	for i, t := range second.Tournaments {
+		assert.Equal(s.T(), first.Tournaments[i].ID, t.ID)
+
+		teams, err := db.NewTeamRepository(s.db).GetByTournamentID(s.ctx, t.ID)
+		require.NoError(s.T(), err)
+		assert.Len(s.T(), teams, 3)
+
+		for _, game := range seed.BuiltinGames {
+			participants, err := s.tournamentRepo.GetLatestParticipantsByGame(s.ctx, t.ID, game.Name)
+			require.NoError(s.T(), err)
+			assert.Len(s.T(), participants, 3)
 		}
 	}
-	return defaultValue
+
+	active := second.Tournaments[1]
+	assert.Equal(s.T(), domain.TournamentActive, active.Status)
 }
 
-func TestDBSuite(t *testing.T) {
-	suite.Run(t, new(DBTestSuite))
+func (s *DBTestSuite) TestExplainAnalyze() {
+	plan, err := s.db.ExplainAnalyze(s.ctx, "SELECT id FROM matches WHERE status = $1 LIMIT 10", "pending")
+	require.NoError(s.T(), err)
+	assert.True(s.T(), json.Valid([]byte(plan)))
+
+	timings, err := db.ParseExplainTimings(plan)
+	require.NoError(s.T(), err)
+	assert.Positive(s.T(), timings.PlanningTimeMs)
+	assert.GreaterOrEqual(s.T(), timings.ExecutionTimeMs, 0.0)
+
+	// DML is rejected before reaching the database
+	_, err = s.db.ExplainAnalyze(s.ctx, "DELETE FROM matches")
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, errors.ToAppError(err).Code)
+
+	// Writes hidden inside a SELECT fail in the read-only transaction
+	_, err = s.db.ExplainAnalyze(s.ctx, "SELECT id FROM matches FOR UPDATE")
+	require.Error(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, errors.ToAppError(err).Code)
 }
@@ -282,6 +282,39 @@ func (s *RedisTestSuite) TestLeaderboardCache_ClearTournament() {
 	assert.Len(s.T(), entries, 0)
 }
 
+func (s *RedisTestSuite) TestLeaderboardCache_GetRank() {
+	tournamentID := uuid.New()
+	leader := uuid.New()
+	second := uuid.New()
+
+	require.NoError(s.T(), s.leaderboardCache.UpdateRating(s.ctx, tournamentID, leader, 1600))
+	require.NoError(s.T(), s.leaderboardCache.UpdateRating(s.ctx, tournamentID, second, 1500))
+
+	// Ranks are 1-indexed
+	rank, err := s.leaderboardCache.GetRank(s.ctx, tournamentID, leader)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), rank)
+
+	rank, err = s.leaderboardCache.GetRank(s.ctx, tournamentID, second)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(2), rank)
+
+	// A program outside the tournament has no rank
+	rank, err = s.leaderboardCache.GetRank(s.ctx, tournamentID, uuid.New())
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(0), rank)
+
+	// The rank follows UpdateRating immediately
+	require.NoError(s.T(), s.leaderboardCache.UpdateRating(s.ctx, tournamentID, second, 1700))
+	rank, err = s.leaderboardCache.GetRank(s.ctx, tournamentID, second)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), rank)
+
+	rank, err = s.leaderboardCache.GetRank(s.ctx, tournamentID, leader)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(2), rank)
+}
+
 // =============================================================================
 // Distributed Lock Tests
 // =============================================================================
@@ -405,6 +438,45 @@ func (s *RedisTestSuite) TestSortedSet_ZAdd() {
 	assert.Len(s.T(), results, 3)
 }
 
+// =============================================================================
+// Live Match Feed Tests
+// =============================================================================
+
+func (s *RedisTestSuite) TestLiveMatchFeed() {
+	feed := cache.NewLiveMatchFeed(s.cache)
+	matchID := uuid.New()
+
+	events, unsubscribe, err := feed.Subscribe(s.ctx, matchID, 10)
+	require.NoError(s.T(), err)
+	defer unsubscribe()
+
+	require.NoError(s.T(), feed.PublishMatchEvent(s.ctx, matchID, &domain.MatchEvent{Type: domain.MatchEventStart}))
+	require.NoError(s.T(), feed.PublishMatchEvent(s.ctx, matchID, &domain.MatchEvent{Type: domain.MatchEventOutput, Line: "1: C D"}))
+
+	// Subscribers receive events in order with increasing numbers
+	for _, expected := range []domain.MatchEventType{domain.MatchEventStart, domain.MatchEventOutput} {
+		select {
+		case event := <-events:
+			assert.Equal(s.T(), expected, event.Type)
+		case <-time.After(2 * time.Second):
+			s.T().Fatal("event was not delivered")
+		}
+	}
+
+	log, err := feed.Log(s.ctx, matchID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), log, 2)
+	assert.Equal(s.T(), int64(1), log[0].Seq)
+	assert.Equal(s.T(), "1: C D", log[1].Line)
+
+	// A retried execution starts the log over but keeps numbering
+	require.NoError(s.T(), feed.PublishMatchEvent(s.ctx, matchID, &domain.MatchEvent{Type: domain.MatchEventStart}))
+	log, err = feed.Log(s.ctx, matchID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), log, 1)
+	assert.Equal(s.T(), int64(3), log[0].Seq)
+}
+
 func TestRedisSuite(t *testing.T) {
 	suite.Run(t, new(RedisTestSuite))
 }
@@ -90,8 +90,9 @@ func (s *WebSocketTestSuite) wsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := websocket.NewClient(s.hub, conn, tournamentID, userID, s.log)
+	client := websocket.NewClient(s.hub, conn, userID, s.log)
 	client.Register() // Register client with hub before starting pumps
+	client.Subscribe(tournamentID)
 	go client.WritePump()
 	go client.ReadPump()
 }
@@ -319,6 +319,13 @@ type PerformanceMetrics struct {
 	MatchesFailed        int64
 	AvgMatchDuration     time.Duration
 	RetryCount           int
+
+	// Server-side timing averaged over a sample of completed matches
+	TimingSamples int
+	AvgQueueWait  time.Duration
+	AvgPrepare    time.Duration
+	AvgExecution  time.Duration
+	AvgPersist    time.Duration
 }
 
 func (m *PerformanceMetrics) Print() {
@@ -352,6 +359,14 @@ func (m *PerformanceMetrics) Print() {
 	if m.MatchesCompleted > 0 {
 		fmt.Printf("│ Avg Match Duration             │ %34v │\n", m.AvgMatchDuration.Round(time.Millisecond))
 	}
+	if m.TimingSamples > 0 {
+		fmt.Println("├────────────────────────────────┼────────────────────────────────────┤")
+		fmt.Printf("│ Timing Samples                 │ %34d │\n", m.TimingSamples)
+		fmt.Printf("│ Avg Queue Wait                 │ %34v │\n", m.AvgQueueWait)
+		fmt.Printf("│ Avg Prepare                    │ %34v │\n", m.AvgPrepare)
+		fmt.Printf("│ Avg Execution                  │ %34v │\n", m.AvgExecution)
+		fmt.Printf("│ Avg Persist                    │ %34v │\n", m.AvgPersist)
+	}
 	fmt.Println("└────────────────────────────────┴────────────────────────────────────┘")
 
 	// Summary Table
@@ -372,6 +387,52 @@ func (m *PerformanceMetrics) Print() {
 	fmt.Println(strings.Repeat("=", 70))
 }
 
+// timingSampleSize how many completed matches are fetched for the server-side timing breakdown
+const timingSampleSize = 50
+
+// collectMatchTiming averages the worker-reported phase durations of a sample of completed
+// matches, showing whether the total match time is spent queueing or executing
+func collectMatchTiming(client *TestClient, matchIDs []string, metrics *PerformanceMetrics) {
+	if len(matchIDs) > timingSampleSize {
+		matchIDs = matchIDs[:timingSampleSize]
+	}
+
+	var queueWait, prepare, execution, persist int64
+	for _, id := range matchIDs {
+		resp, err := client.doRequest("GET", "/api/v1/matches/"+id, nil)
+		if err != nil {
+			continue
+		}
+		var match struct {
+			Timing *struct {
+				QueueWaitMs int64 `json:"queue_wait_ms"`
+				PrepareMs   int64 `json:"prepare_ms"`
+				ExecutionMs int64 `json:"execution_ms"`
+				PersistMs   int64 `json:"persist_ms"`
+			} `json:"timing"`
+		}
+		if err := client.parseResponse(resp, &match); err != nil || match.Timing == nil {
+			continue
+		}
+		queueWait += match.Timing.QueueWaitMs
+		prepare += match.Timing.PrepareMs
+		execution += match.Timing.ExecutionMs
+		persist += match.Timing.PersistMs
+		metrics.TimingSamples++
+	}
+
+	if metrics.TimingSamples == 0 {
+		return
+	}
+	avg := func(totalMs int64) time.Duration {
+		return time.Duration(totalMs/int64(metrics.TimingSamples)) * time.Millisecond
+	}
+	metrics.AvgQueueWait = avg(queueWait)
+	metrics.AvgPrepare = avg(prepare)
+	metrics.AvgExecution = avg(execution)
+	metrics.AvgPersist = avg(persist)
+}
+
 // TestPerformance_30Teams_Tournament tests tournament with 30 teams
 func TestPerformance_30Teams_Tournament(t *testing.T) {
 	if testing.Short() {
@@ -824,6 +885,7 @@ func TestPerformance_30Teams_Tournament(t *testing.T) {
 
 	var lastPending, lastCompleted, lastFailed int
 
+	var completedIDs []string
 	for {
 		if time.Since(start) > maxWaitTime {
 			fmt.Printf("   ⚠️ Timeout after %v\n", maxWaitTime)
@@ -847,12 +909,14 @@ func TestPerformance_30Teams_Tournament(t *testing.T) {
 		}
 
 		pending, completed, failed := 0, 0, 0
+		completedIDs = completedIDs[:0]
 		for _, m := range matches {
 			switch m.Status {
 			case "pending", "running":
 				pending++
 			case "completed":
 				completed++
+				completedIDs = append(completedIDs, m.ID)
 			case "failed", "error":
 				failed++
 			}
@@ -928,6 +992,7 @@ func TestPerformance_30Teams_Tournament(t *testing.T) {
 	if metrics.MatchesCompleted > 0 {
 		metrics.AvgMatchDuration = metrics.TotalMatchTime / time.Duration(metrics.MatchesCompleted)
 	}
+	collectMatchTiming(adminClient, completedIDs, metrics)
 
 	// ==========================================================================
 	// Print results table
@@ -422,8 +422,14 @@ class ApiClient {
   }
 
   // Program endpoints
-  async getPrograms(): Promise<Program[]> {
-    const { data } = await this.client.get<Program[]>('/programs');
+  async getPrograms(params?: {
+    tournament_id?: string;
+    game_id?: string;
+    language?: string;
+    limit?: number;
+    offset?: number;
+  }): Promise<Program[]> {
+    const { data } = await this.client.get<Program[]>('/programs', { params });
     return data;
   }
 
@@ -102,7 +102,7 @@ export function GameDetail() {
           setMyTeam(teamData);
 
           // Load programs for this team
-          const programsData = await api.getPrograms();
+          const programsData = await api.getPrograms({ tournament_id: tournamentId, game_id: gameId });
           const teamPrograms = programsData.filter(
             (p) => p.team_id === teamData?.id && p.game_id === gameId
           );
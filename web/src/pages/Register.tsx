@@ -4,7 +4,7 @@ import { useAuthStore } from '../store/authStore';
 import { AxiosError } from 'axios';
 
 interface ApiErrorResponse {
-  error?: string;
+  error?: string | { code: string; message: string };
   message?: string;
 }
 
@@ -79,8 +79,9 @@ export function Register() {
       navigate('/tournaments');
     } catch (err) {
       const axiosError = err as AxiosError<ApiErrorResponse>;
-      if (axiosError.response?.data?.error) {
-        const serverError = axiosError.response.data.error;
+      const errorBody = axiosError.response?.data?.error;
+      if (errorBody) {
+        const serverError = typeof errorBody === 'string' ? errorBody : errorBody.message;
         // Переводим серверные ошибки на русский
         if (serverError.includes('already exists')) {
           setError('Пользователь с таким именем или email уже существует');